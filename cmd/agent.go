@@ -0,0 +1,63 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/runatlantis/atlantis/server/agents"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/spf13/cobra"
+)
+
+// AgentCmd runs a remote agent running the terraform commands of the projects
+// of its agent pool.
+type AgentCmd struct {
+	Logger logging.SimpleLogging
+}
+
+// Init returns the runnable cobra command.
+func (a *AgentCmd) Init() *cobra.Command {
+	agent := &agents.Agent{Logger: a.Logger}
+	hostname, _ := os.Hostname()
+	homeDir, _ := os.UserHomeDir()
+	c := &cobra.Command{
+		Use:   "agent",
+		Short: "Run the terraform commands of an agent pool",
+		Long: "Run the terraform commands of the projects of an agent pool, see --agent-pools of the server." +
+			" Flags can also be set with environment variables, ex. ATLANTIS_AGENT_TOKEN for --token.",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if agent.URL == "" || agent.Token == "" {
+				return errors.New("--url and --token are required")
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			agent.Run(ctx)
+			return nil
+		},
+		SilenceUsage: true,
+	}
+	flags := c.Flags()
+	flags.StringVar(&agent.URL, "url", os.Getenv("ATLANTIS_AGENT_URL"), "URL of Atlantis.")
+	flags.StringVar(&agent.Token, "token", os.Getenv("ATLANTIS_AGENT_TOKEN"), "Token of the agent pool.")
+	flags.StringVar(&agent.Name, "name", envOr("ATLANTIS_AGENT_NAME", hostname), "Name of the agent in its pool."+
+		" The commands of a working dir are run by the same agent, so it should be stable across restarts.")
+	flags.StringVar(&agent.DataDir, "data-dir", envOr("ATLANTIS_AGENT_DATA_DIR", filepath.Join(homeDir, ".atlantis-agent")),
+		"Path to the directory keeping the working dirs and the terraform binaries and plugins.")
+	flags.StringVar(&agent.TFDownloadURL, "tf-download-url", envOr("ATLANTIS_AGENT_TF_DOWNLOAD_URL", DefaultTFDownloadURL),
+		"Base URL to download terraform versions from.")
+	return c
+}
+
+func envOr(key string, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,192 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/controllers"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/jobs"
+)
+
+// tokenHeader is the header the API secret is sent in.
+const tokenHeader = "X-Atlantis-Token" // nolint: gosec
+
+// Client talks to the Atlantis API.
+type Client struct {
+	// URL is the URL of Atlantis, ex. https://atlantis.example.com.
+	URL string
+	// Token is the API secret, see --api-secret.
+	Token string
+	HTTP  *http.Client
+}
+
+// CommandResult is the result of a plan or apply. It mirrors the fields of
+// command.Result the CLI reports, errors are reported through Failure and
+// the project errors since they're not serialized.
+type CommandResult struct {
+	Failure        string
+	ProjectResults []ProjectResult
+	PlansDeleted   bool
+}
+
+// ProjectResult is the result of a plan or apply of a project.
+type ProjectResult struct {
+	RepoRelDir  string
+	Workspace   string
+	ProjectName string
+	Failure     string
+	PlanSuccess *struct {
+		TerraformOutput string
+		LockURL         string
+	}
+	ApplySuccess string
+}
+
+// Plan runs plan for the projects in request.
+func (c *Client) Plan(request controllers.APIRequest) (CommandResult, error) {
+	return c.runCommand("/api/plan", request)
+}
+
+// Apply runs apply for the projects in request.
+func (c *Client) Apply(request controllers.APIRequest) (CommandResult, error) {
+	return c.runCommand("/api/apply", request)
+}
+
+func (c *Client) runCommand(path string, request controllers.APIRequest) (CommandResult, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return CommandResult{}, err
+	}
+	var result CommandResult
+	// Failed commands are returned with a server error, their result is
+	// still reported.
+	err = c.do("POST", path, nil, bytes.NewReader(body), &result)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && len(result.ProjectResults) > 0 {
+		return result, nil
+	}
+	return result, err
+}
+
+// ListLocks lists the project locks.
+func (c *Client) ListLocks() ([]controllers.LockDetail, error) {
+	var result controllers.ListLocksResult
+	err := c.do("GET", "/api/locks", nil, nil, &result)
+	return result.Locks, err
+}
+
+// ReleaseLock releases the lock with id, discarding its plan.
+func (c *Client) ReleaseLock(id string) error {
+	return c.do("DELETE", "/api/locks", url.Values{"id": {id}}, nil, nil)
+}
+
+// ListJobs lists the jobs of each pull request.
+func (c *Client) ListJobs() ([]jobs.PullInfoWithJobIDs, error) {
+	var result controllers.ListJobsResult
+	err := c.do("GET", "/api/jobs", nil, nil, &result)
+	return result.Jobs, err
+}
+
+// TailJobLogs writes the output of the job to out until the job completes.
+func (c *Client) TailJobLogs(jobID string, out io.Writer) error {
+	resp, err := c.request("GET", "/api/jobs/"+url.PathEscape(jobID)+"/logs", nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(out, resp.Body)
+	return err
+}
+
+// ListSummaries lists the plan summaries recently generated for the pull
+// request.
+func (c *Client) ListSummaries(repository string, pullNum int) ([]events.StoredSummary, error) {
+	var result controllers.ListSummariesResult
+	query := url.Values{"repository": {repository}, "pull": {strconv.Itoa(pullNum)}}
+	err := c.do("GET", "/api/summaries", query, nil, &result)
+	return result.Summaries, err
+}
+
+// APIError is an error response of the API.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("atlantis returned %d: %s", e.StatusCode, e.Message)
+}
+
+// do sends a request and decodes the JSON response into result, if it's not
+// nil. The response is decoded even if it's an error, ex. for failed plans.
+func (c *Client) do(method string, path string, query url.Values, body io.Reader, result any) error {
+	resp, err := c.request(method, path, query, body)
+	var apiErr *APIError
+	if err != nil && !errors.As(err, &apiErr) {
+		return err
+	}
+	if resp != nil {
+		defer resp.Body.Close()
+		if result != nil {
+			if decodeErr := json.NewDecoder(resp.Body).Decode(result); decodeErr != nil && err == nil {
+				return fmt.Errorf("decoding response: %w", decodeErr)
+			}
+		}
+	}
+	return err
+}
+
+// request sends a request and returns an *APIError along with the response if
+// the response isn't a success.
+func (c *Client) request(method string, path string, query url.Values, body io.Reader) (*http.Response, error) {
+	if c.URL == "" {
+		return nil, errors.New("the Atlantis URL is required, set --url or ATLANTIS_URL")
+	}
+	u := strings.TrimSuffix(c.URL, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(tokenHeader, c.Token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	// Errors are reported as {"error": "..."}. Read the body so it can still
+	// be decoded, ex. into the result of a failed plan.
+	contents, err := io.ReadAll(resp.Body)
+	resp.Body.Close() // nolint: errcheck
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(contents))
+	message := strings.TrimSpace(string(contents))
+	var errResponse struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(contents, &errResponse) == nil && errResponse.Error != "" {
+		message = errResponse.Error
+	}
+	return resp, &APIError{StatusCode: resp.StatusCode, Message: message}
+}
@@ -0,0 +1,253 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/controllers"
+	"github.com/spf13/cobra"
+)
+
+const (
+	urlEnv   = "ATLANTIS_URL"
+	tokenEnv = "ATLANTIS_API_SECRET" // nolint: gosec
+	// requestTimeout is the timeout of requests other than plans, applies
+	// and tailing logs, which can take as long as the commands run.
+	requestTimeout = 30 * time.Second
+)
+
+// cli holds the global flags shared by the commands.
+type cli struct {
+	out    io.Writer
+	client *Client
+	json   bool
+}
+
+// newRootCmd returns the atlantisctl command writing its output to out.
+func newRootCmd(out io.Writer) *cobra.Command {
+	c := &cli{out: out, client: &Client{HTTP: &http.Client{}}}
+	root := &cobra.Command{
+		Use:           "atlantisctl",
+		Short:         "Client for the Atlantis API",
+		Long:          "Trigger plans and applies, manage locks, tail job logs and fetch plan summaries through the Atlantis API.",
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+	root.PersistentFlags().StringVar(&c.client.URL, "url", os.Getenv(urlEnv), "URL of Atlantis, ex. https://atlantis.example.com. Defaults to "+urlEnv+".")
+	root.PersistentFlags().StringVar(&c.client.Token, "token", os.Getenv(tokenEnv), "API secret of Atlantis, see --api-secret. Defaults to "+tokenEnv+".")
+	root.PersistentFlags().BoolVar(&c.json, "json", false, "Print responses as JSON.")
+
+	root.AddCommand(c.commandCmd("plan"), c.commandCmd("apply"), c.locksCmd(), c.jobsCmd(), c.summariesCmd())
+	return root
+}
+
+// commandCmd returns the plan or apply command.
+func (c *cli) commandCmd(name string) *cobra.Command {
+	var request controllers.APIRequest
+	var dirs []string
+	var workspace string
+	cmd := &cobra.Command{
+		Use:   name,
+		Short: fmt.Sprintf("Run %s for projects of a repository", name),
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if len(request.Projects) == 0 && len(dirs) == 0 {
+				return errors.New("at least one --project or --dir is required")
+			}
+			for _, dir := range dirs {
+				request.Paths = append(request.Paths, struct {
+					Directory string
+					Workspace string
+				}{dir, workspace})
+			}
+			run := c.client.Plan
+			if name == "apply" {
+				run = c.client.Apply
+			}
+			result, err := run(request)
+			if err != nil {
+				return err
+			}
+			if c.json {
+				return c.printJSON(result)
+			}
+			return c.printResult(result)
+		},
+	}
+	cmd.Flags().StringVar(&request.Repository, "repo", "", "Full name of the repository, ex. owner/repo.")
+	cmd.Flags().StringVar(&request.Ref, "ref", "", "Git ref to run against, ex. a branch or commit.")
+	cmd.Flags().StringVar(&request.Type, "vcs", "Github", "VCS host type, ex. Github or Gitlab.")
+	cmd.Flags().IntVar(&request.PR, "pr", 0, "Pull request number, if the ref is a pull request's.")
+	cmd.Flags().StringSliceVar(&request.Projects, "project", nil, "Name of a project to run, can be repeated.")
+	cmd.Flags().StringSliceVar(&dirs, "dir", nil, "Directory of a project to run, can be repeated.")
+	cmd.Flags().StringVar(&workspace, "workspace", "default", "Workspace of the --dir projects.")
+	cmd.MarkFlagRequired("repo") // nolint: errcheck
+	cmd.MarkFlagRequired("ref")  // nolint: errcheck
+	return cmd
+}
+
+func (c *cli) locksCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "locks",
+		Short: "List and release project locks",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List the project locks",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			c.client.HTTP.Timeout = requestTimeout
+			locks, err := c.client.ListLocks()
+			if err != nil {
+				return err
+			}
+			if c.json {
+				return c.printJSON(locks)
+			}
+			w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tREPO\tPATH\tWORKSPACE\tPULL\tUSER\tLOCKED") // nolint: errcheck
+			for _, lock := range locks {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n", lock.Name, lock.ProjectRepo, lock.ProjectRepoPath, lock.Workspace, lock.PullID, lock.User, lock.Time.Format(time.RFC3339)) // nolint: errcheck
+			}
+			return w.Flush()
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "release <id>...",
+		Short: "Release locks, discarding their plans",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, ids []string) error {
+			c.client.HTTP.Timeout = requestTimeout
+			for _, id := range ids {
+				if err := c.client.ReleaseLock(id); err != nil {
+					return fmt.Errorf("releasing %s: %w", id, err)
+				}
+				fmt.Fprintf(c.out, "released %s\n", id) // nolint: errcheck
+			}
+			return nil
+		},
+	})
+	return cmd
+}
+
+func (c *cli) jobsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "List jobs and tail their logs",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List the jobs of each pull request",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			c.client.HTTP.Timeout = requestTimeout
+			pulls, err := c.client.ListJobs()
+			if err != nil {
+				return err
+			}
+			if c.json {
+				return c.printJSON(pulls)
+			}
+			w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tREPO\tPULL\tPATH\tWORKSPACE\tSTEP\tSTARTED") // nolint: errcheck
+			for _, pull := range pulls {
+				for _, job := range pull.JobIDInfos {
+					fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%s\n", job.JobID, pull.Pull.RepoFullName, pull.Pull.PullNum, pull.Pull.Path, pull.Pull.Workspace, job.JobStep, job.Time.Format(time.RFC3339)) // nolint: errcheck
+				}
+			}
+			return w.Flush()
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "logs <id>",
+		Short: "Print the logs of a job, following them until the job completes",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return c.client.TailJobLogs(args[0], c.out)
+		},
+	})
+	return cmd
+}
+
+func (c *cli) summariesCmd() *cobra.Command {
+	var repository string
+	var pullNum int
+	cmd := &cobra.Command{
+		Use:   "summaries",
+		Short: "Print the plan summaries recently generated for a pull request",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			c.client.HTTP.Timeout = requestTimeout
+			summaries, err := c.client.ListSummaries(repository, pullNum)
+			if err != nil {
+				return err
+			}
+			if c.json {
+				return c.printJSON(summaries)
+			}
+			if len(summaries) == 0 {
+				fmt.Fprintln(c.out, "no summaries, they're kept in memory so they're lost when Atlantis restarts") // nolint: errcheck
+			}
+			for i, summary := range summaries {
+				if i > 0 {
+					fmt.Fprintln(c.out) // nolint: errcheck
+				}
+				fmt.Fprintf(c.out, "# %s by %s at %s\n\n%s\n", summary.HeadCommit, summary.User, summary.CreatedAt.Format(time.RFC3339), summary.Summary) // nolint: errcheck
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&repository, "repo", "", "Full name of the repository, ex. owner/repo.")
+	cmd.Flags().IntVar(&pullNum, "pr", 0, "Pull request number.")
+	cmd.MarkFlagRequired("repo") // nolint: errcheck
+	cmd.MarkFlagRequired("pr")   // nolint: errcheck
+	return cmd
+}
+
+// printResult prints the result of each project and returns an error if any
+// failed.
+func (c *cli) printResult(result CommandResult) error {
+	if result.Failure != "" {
+		return errors.New(result.Failure)
+	}
+	failed := 0
+	for _, project := range result.ProjectResults {
+		name := fmt.Sprintf("dir: %s workspace: %s", project.RepoRelDir, project.Workspace)
+		if project.ProjectName != "" {
+			name = fmt.Sprintf("project: %s %s", project.ProjectName, name)
+		}
+		fmt.Fprintf(c.out, "## %s\n\n", name) // nolint: errcheck
+		switch {
+		case project.Failure != "":
+			failed++
+			fmt.Fprintf(c.out, "failed: %s\n\n", project.Failure) // nolint: errcheck
+		case project.PlanSuccess != nil:
+			fmt.Fprintf(c.out, "%s\n\n", strings.TrimSpace(project.PlanSuccess.TerraformOutput)) // nolint: errcheck
+		case project.ApplySuccess != "":
+			fmt.Fprintf(c.out, "%s\n\n", strings.TrimSpace(project.ApplySuccess)) // nolint: errcheck
+		default:
+			failed++
+			fmt.Fprintf(c.out, "errored, see the Atlantis logs\n\n") // nolint: errcheck
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d projects failed", failed, len(result.ProjectResults))
+	}
+	return nil
+}
+
+func (c *cli) printJSON(v any) error {
+	encoder := json.NewEncoder(c.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
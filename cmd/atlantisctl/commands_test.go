@@ -0,0 +1,122 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// fakeAtlantis starts a fake Atlantis API serving routes and returns its URL.
+func fakeAtlantis(t *testing.T, routes map[string]http.HandlerFunc) string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(tokenHeader) != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": "header X-Atlantis-Token did not match expected secret"}`)) // nolint: errcheck
+			return
+		}
+		route, ok := routes[r.Method+" "+r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		route(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func run(t *testing.T, url string, args ...string) (string, error) {
+	var out bytes.Buffer
+	cmd := newRootCmd(&out)
+	cmd.SetArgs(append([]string{"--url", url, "--token", "secret"}, args...))
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestPlan(t *testing.T) {
+	url := fakeAtlantis(t, map[string]http.HandlerFunc{
+		"POST /api/plan": func(w http.ResponseWriter, r *http.Request) {
+			var request map[string]any
+			Ok(t, json.NewDecoder(r.Body).Decode(&request))
+			Equals(t, "owner/repo", request["Repository"])
+			Equals(t, "main", request["Ref"])
+			Equals(t, "Github", request["Type"])
+			Equals(t, []any{map[string]any{"Directory": "infra", "Workspace": "staging"}}, request["Paths"])
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"ProjectResults": [
+				{"RepoRelDir": "infra", "Workspace": "staging", "PlanSuccess": {"TerraformOutput": "Plan: 1 to add"}},
+				{"RepoRelDir": "infra", "Workspace": "staging", "ProjectName": "other", "Failure": "locked"}
+			]}`)) // nolint: errcheck
+		},
+	})
+
+	out, err := run(t, url, "plan", "--repo", "owner/repo", "--ref", "main", "--dir", "infra", "--workspace", "staging")
+	ErrEquals(t, "1 of 2 projects failed", err)
+	Equals(t, "## dir: infra workspace: staging\n\nPlan: 1 to add\n\n## project: other dir: infra workspace: staging\n\nfailed: locked\n\n", out)
+
+	_, err = run(t, url, "plan", "--repo", "owner/repo", "--ref", "main")
+	ErrEquals(t, "at least one --project or --dir is required", err)
+}
+
+func TestLocks(t *testing.T) {
+	released := ""
+	url := fakeAtlantis(t, map[string]http.HandlerFunc{
+		"GET /api/locks": func(w http.ResponseWriter, _ *http.Request) {
+			w.Write([]byte(`{"Locks": [{"Name": "owner/repo/infra/default", "ProjectRepo": "owner/repo", "ProjectRepoPath": "infra",
+				"PullID": "1", "User": "jdoe", "Workspace": "default", "Time": "2025-01-02T03:04:05Z"}]}`)) // nolint: errcheck
+		},
+		"DELETE /api/locks": func(w http.ResponseWriter, r *http.Request) {
+			released = r.URL.Query().Get("id")
+			w.Write([]byte(`{"deleted": "` + released + `"}`)) // nolint: errcheck
+		},
+	})
+
+	out, err := run(t, url, "locks", "list")
+	Ok(t, err)
+	Equals(t, "ID                        REPO        PATH   WORKSPACE  PULL  USER  LOCKED\n"+
+		"owner/repo/infra/default  owner/repo  infra  default    1     jdoe  2025-01-02T03:04:05Z\n", out)
+
+	out, err = run(t, url, "locks", "release", "owner/repo/infra/default")
+	Ok(t, err)
+	Equals(t, "owner/repo/infra/default", released)
+	Equals(t, "released owner/repo/infra/default\n", out)
+
+	_, err = run(t, url, "--token", "wrong", "locks", "release", "owner/repo/infra/default")
+	ErrEquals(t, "releasing owner/repo/infra/default: atlantis returned 401: header X-Atlantis-Token did not match expected secret", err)
+}
+
+func TestJobs(t *testing.T) {
+	url := fakeAtlantis(t, map[string]http.HandlerFunc{
+		"GET /api/jobs/job-1/logs": func(w http.ResponseWriter, _ *http.Request) {
+			w.Write([]byte("Initializing...\nPlan: 1 to add\n")) // nolint: errcheck
+		},
+	})
+
+	out, err := run(t, url, "jobs", "logs", "job-1")
+	Ok(t, err)
+	Equals(t, "Initializing...\nPlan: 1 to add\n", out)
+
+	_, err = run(t, url, "jobs", "logs", "missing")
+	Assert(t, err != nil, "exp error for missing job")
+}
+
+func TestSummaries(t *testing.T) {
+	url := fakeAtlantis(t, map[string]http.HandlerFunc{
+		"GET /api/summaries": func(w http.ResponseWriter, r *http.Request) {
+			Equals(t, "owner/repo", r.URL.Query().Get("repository"))
+			Equals(t, "7", r.URL.Query().Get("pull"))
+			w.Write([]byte(`{"Summaries": [{"Summary": "**1 to add.**", "HeadCommit": "abc123", "User": "jdoe", "CreatedAt": "2025-01-02T03:04:05Z"}]}`)) // nolint: errcheck
+		},
+	})
+
+	out, err := run(t, url, "summaries", "--repo", "owner/repo", "--pr", "7")
+	Ok(t, err)
+	Equals(t, "# abc123 by jdoe at 2025-01-02T03:04:05Z\n\n**1 to add.**\n", out)
+}
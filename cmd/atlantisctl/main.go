@@ -0,0 +1,17 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package main is atlantisctl, a CLI client for the Atlantis API.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	if err := newRootCmd(os.Stdout).Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err) // nolint: errcheck
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v71/github"
+	"github.com/hashicorp/go-version"
+	"github.com/runatlantis/atlantis/server/core/config"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/core/terraform/tfclient"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/spf13/cobra"
+)
+
+// githubRemoteRegex matches the owner and name of the repo in the URL of a
+// remote, ex. git@github.com:owner/repo.git or https://github.com/owner/repo.
+var githubRemoteRegex = regexp.MustCompile(`[:/]([^/:]+)/([^/]+?)(?:\.git)?/?$`)
+
+// OnboardCmd scaffolds the atlantis.yaml of a repo, optionally proposing it as
+// a GitHub pull request.
+type OnboardCmd struct {
+	Logger logging.SimpleLogging
+}
+
+// onboardOptions are the flags of the onboard command.
+type onboardOptions struct {
+	Write       bool
+	PullRequest bool
+	GHToken     string
+	GHHostname  string
+	Repo        string
+	Branch      string
+	Base        string
+}
+
+// Init returns the runnable cobra command.
+func (o *OnboardCmd) Init() *cobra.Command {
+	var opts onboardOptions
+	c := &cobra.Command{
+		Use:   "onboard [dir]",
+		Short: "Scaffold the atlantis.yaml of a repo",
+		Long: "Scan the repo checked out in dir, the current directory if not given, for root modules, their workspaces and terraform versions," +
+			" and terragrunt stacks, and print the atlantis.yaml configuring them." +
+			" With --write the file is written to the repo, with --pull-request it's proposed as a GitHub pull request.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) == 1 {
+				dir = args[0]
+			}
+			return o.run(c.Context(), c.OutOrStdout(), dir, opts)
+		},
+		SilenceUsage: true,
+	}
+	flags := c.Flags()
+	flags.BoolVar(&opts.Write, "write", false, "Write the atlantis.yaml to the repo instead of printing it.")
+	flags.BoolVar(&opts.PullRequest, "pull-request", false, "Propose the atlantis.yaml as a GitHub pull request, see --gh-token.")
+	flags.StringVar(&opts.GHToken, "gh-token", os.Getenv("ATLANTIS_GH_TOKEN"), "GitHub token opening the pull request. Can also be specified via the ATLANTIS_GH_TOKEN environment variable.")
+	flags.StringVar(&opts.GHHostname, "gh-hostname", "github.com", "Hostname of your GitHub Enterprise installation, if not github.com.")
+	flags.StringVar(&opts.Repo, "repo", "", "owner/name of the GitHub repo, defaults to the repo of the origin remote of dir.")
+	flags.StringVar(&opts.Branch, "branch", "atlantis-onboarding", "Branch of the pull request.")
+	flags.StringVar(&opts.Base, "base", "", "Base branch of the pull request, defaults to the default branch of the repo.")
+	return c
+}
+
+func (o *OnboardCmd) run(ctx context.Context, out io.Writer, dir string, opts onboardOptions) error {
+	cfgPath := filepath.Join(dir, valid.DefaultAtlantisFile)
+	if _, err := os.Stat(cfgPath); err == nil && (opts.Write || opts.PullRequest) {
+		return fmt.Errorf("%s already exists", cfgPath)
+	}
+	generator := config.RepoCfgGenerator{
+		DetectVersion: func(projectDir string) *version.Version {
+			return tfclient.PinnedVersion(o.Logger, projectDir, "terraform")
+		},
+	}
+	data, err := generator.GenerateData(dir)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case opts.PullRequest:
+		url, err := o.openPullRequest(ctx, dir, data, opts)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "opened %s\n", url) // nolint: errcheck
+	case opts.Write:
+		if err := os.WriteFile(cfgPath, data, 0600); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "wrote %s\n", cfgPath) // nolint: errcheck
+	default:
+		_, err = out.Write(data)
+	}
+	return err
+}
+
+// openPullRequest commits data as the atlantis.yaml of a new branch of the
+// GitHub repo, opening a pull request for it, and returns its URL.
+func (o *OnboardCmd) openPullRequest(ctx context.Context, dir string, data []byte, opts onboardOptions) (string, error) {
+	if opts.GHToken == "" {
+		return "", errors.New("--gh-token must be set to open a pull request")
+	}
+	repoFullName := opts.Repo
+	if repoFullName == "" {
+		remote, err := exec.Command("git", "-C", dir, "remote", "get-url", "origin").Output() // nolint: gosec
+		if err != nil {
+			return "", fmt.Errorf("getting the origin remote of %s, set --repo: %w", dir, err)
+		}
+		match := githubRemoteRegex.FindStringSubmatch(strings.TrimSpace(string(remote)))
+		if match == nil {
+			return "", fmt.Errorf("unable to find the repo of the origin remote %q, set --repo", strings.TrimSpace(string(remote)))
+		}
+		repoFullName = match[1] + "/" + match[2]
+	}
+	owner, name, ok := strings.Cut(repoFullName, "/")
+	if !ok {
+		return "", fmt.Errorf("--repo %q must be owner/name", repoFullName)
+	}
+
+	client := github.NewClient(nil).WithAuthToken(opts.GHToken)
+	if opts.GHHostname != "github.com" {
+		var err error
+		client, err = client.WithEnterpriseURLs(fmt.Sprintf("https://%s/api/v3/", opts.GHHostname), fmt.Sprintf("https://%s/api/uploads/", opts.GHHostname))
+		if err != nil {
+			return "", err
+		}
+	}
+	base := opts.Base
+	if base == "" {
+		repo, _, err := client.Repositories.Get(ctx, owner, name)
+		if err != nil {
+			return "", fmt.Errorf("getting %s: %w", repoFullName, err)
+		}
+		base = repo.GetDefaultBranch()
+	}
+	baseRef, _, err := client.Git.GetRef(ctx, owner, name, "heads/"+base)
+	if err != nil {
+		return "", fmt.Errorf("getting branch %s: %w", base, err)
+	}
+	_, _, err = client.Git.CreateRef(ctx, owner, name, &github.Reference{
+		Ref:    github.Ptr("refs/heads/" + opts.Branch),
+		Object: &github.GitObject{SHA: baseRef.Object.SHA},
+	})
+	if err != nil {
+		return "", fmt.Errorf("creating branch %s: %w", opts.Branch, err)
+	}
+	_, _, err = client.Repositories.CreateFile(ctx, owner, name, valid.DefaultAtlantisFile, &github.RepositoryContentFileOptions{
+		Message: github.Ptr("Add atlantis.yaml"),
+		Content: data,
+		Branch:  github.Ptr(opts.Branch),
+	})
+	if err != nil {
+		return "", fmt.Errorf("committing %s: %w", valid.DefaultAtlantisFile, err)
+	}
+	pull, _, err := client.PullRequests.Create(ctx, owner, name, &github.NewPullRequest{
+		Title: github.Ptr("Configure Atlantis"),
+		Head:  github.Ptr(opts.Branch),
+		Base:  github.Ptr(base),
+		Body: github.Ptr("This pull request adds an `atlantis.yaml` generated by `atlantis onboard` from the root modules, " +
+			"workspaces, terraform versions and terragrunt stacks of the repo. Review its projects before merging it."),
+	})
+	if err != nil {
+		return "", fmt.Errorf("opening the pull request: %w", err)
+	}
+	return pull.GetHTMLURL(), nil
+}
@@ -15,11 +15,13 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/moby/patternmatcher"
@@ -27,6 +29,9 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/runatlantis/atlantis/server"
+	"github.com/runatlantis/atlantis/server/core/kubernetes"
+	"github.com/runatlantis/atlantis/server/core/logsink"
+	"github.com/runatlantis/atlantis/server/core/runtime"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketcloud"
 	"github.com/runatlantis/atlantis/server/logging"
 )
@@ -54,9 +59,18 @@ const (
 	ADTokenFlag                      = "azuredevops-token" // nolint: gosec
 	ADUserFlag                       = "azuredevops-user"
 	ADHostnameFlag                   = "azuredevops-hostname"
+	AgentPoolsFlag                   = "agent-pools"
 	AllowCommandsFlag                = "allow-commands"
 	AllowForkPRsFlag                 = "allow-fork-prs"
+	ApplyConfirmDestroysFlag         = "apply-confirm-destroys"
+	ApplyConfirmProjectsFlag         = "apply-confirm-projects"
+	ApplyReportSigningKeyFlag        = "apply-report-signing-key"
+	ApplyStateCheckFlag              = "apply-state-check"
+	ApplyStateDiffFlag               = "apply-state-diff"
+	ArtifactMaxAgeFlag               = "artifact-max-age"
+	ArtifactRepoQuotaMBFlag          = "artifact-repo-quota-mb"
 	AtlantisURLFlag                  = "atlantis-url"
+	AuditAdvisoriesFlag              = "audit-advisories"
 	AutoDiscoverModeFlag             = "autodiscover-mode"
 	AutomergeFlag                    = "automerge"
 	ParallelPlanFlag                 = "parallel-plan"
@@ -64,15 +78,26 @@ const (
 	AutoplanModules                  = "autoplan-modules"
 	AutoplanModulesFromProjects      = "autoplan-modules-from-projects"
 	AutoplanFileListFlag             = "autoplan-file-list"
+	BitbucketAccessTokenFlag         = "bitbucket-access-token"
 	BitbucketApiUserFlag             = "bitbucket-api-user"
 	BitbucketBaseURLFlag             = "bitbucket-base-url"
+	BitbucketOAuthClientIDFlag       = "bitbucket-oauth-client-id"
+	BitbucketOAuthClientSecretFlag   = "bitbucket-oauth-client-secret"
 	BitbucketTokenFlag               = "bitbucket-token"
 	BitbucketUserFlag                = "bitbucket-user"
 	BitbucketWebhookSecretFlag       = "bitbucket-webhook-secret"
 	CheckoutDepthFlag                = "checkout-depth"
 	CheckoutStrategyFlag             = "checkout-strategy"
+	CodeCommitGitPasswordFlag        = "codecommit-git-password" // nolint: gosec
+	CodeCommitGitUserFlag            = "codecommit-git-user"
+	CodeCommitRegionFlag             = "codecommit-region"
+	CodeCommitWebhookSecretFlag      = "codecommit-webhook-secret" // nolint: gosec
+	CommandAuthzPolicyFlag           = "command-authz-policy"
+	ConcurrencyGroupsFlag            = "concurrency-groups"
 	ConfigFlag                       = "config"
 	DataDirFlag                      = "data-dir"
+	DBEncryptionKeyFlag              = "db-encryption-key"
+	DBEncryptionKMSKeyIDFlag         = "db-encryption-kms-key-id"
 	DefaultTFDistributionFlag        = "default-tf-distribution"
 	DefaultTFVersionFlag             = "default-tf-version"
 	DisableApplyAllFlag              = "disable-apply-all"
@@ -83,14 +108,27 @@ const (
 	DisableGlobalApplyLockFlag       = "disable-global-apply-lock"
 	DisableUnlockLabelFlag           = "disable-unlock-label"
 	DiscardApprovalOnPlanFlag        = "discard-approval-on-plan"
+	DiskCriticalThresholdFlag        = "disk-critical-threshold"
+	DiskEvictionThresholdFlag        = "disk-eviction-threshold"
 	EmojiReaction                    = "emoji-reaction"
+	EmojiReactionFailure             = "emoji-reaction-failure"
+	EmojiReactionSuccess             = "emoji-reaction-success"
 	EnableDiffMarkdownFormat         = "enable-diff-markdown-format"
+	EnableDriftPullRequestsFlag      = "enable-drift-pull-requests"
+	EnablePlanGraphFlag              = "enable-plan-graph"
 	EnablePolicyChecksFlag           = "enable-policy-checks"
+	EnablePreviewEnvironmentsFlag    = "enable-preview-environments"
 	EnableRegExpCmdFlag              = "enable-regexp-cmd"
+	EnableRegistryCacheFlag          = "enable-registry-cache"
+	EnableStateForceUnlockFlag       = "enable-state-force-unlock"
 	EnableProfilingAPI               = "enable-profiling-api"
+	EnableProgressCommentsFlag       = "enable-progress-comments"
 	ExecutableName                   = "executable-name"
+	ExportPlanJSONFlag               = "export-plan-json"
 	FailOnPreWorkflowHookError       = "fail-on-pre-workflow-hook-error"
 	HideUnchangedPlanComments        = "hide-unchanged-plan-comments"
+	GHCodeScanningFlag               = "gh-code-scanning"
+	GHDeploymentsFlag                = "gh-deployments"
 	GHHostnameFlag                   = "gh-hostname"
 	GHTeamAllowlistFlag              = "gh-team-allowlist"
 	GHTokenFlag                      = "gh-token"
@@ -104,6 +142,11 @@ const (
 	GHOrganizationFlag               = "gh-org"
 	GHWebhookSecretFlag              = "gh-webhook-secret"               // nolint: gosec
 	GHAllowMergeableBypassApply      = "gh-allow-mergeable-bypass-apply" // nolint: gosec
+	GerritBaseURLFlag                = "gerrit-base-url"
+	GerritPasswordFlag               = "gerrit-password" // nolint: gosec
+	GerritUserFlag                   = "gerrit-user"
+	GerritVerifiedLabelFlag          = "gerrit-verified-label"
+	GerritWebhookSecretFlag          = "gerrit-webhook-secret" // nolint: gosec
 	GiteaBaseURLFlag                 = "gitea-base-url"
 	GiteaTokenFlag                   = "gitea-token"
 	GiteaUserFlag                    = "gitea-user"
@@ -116,27 +159,57 @@ const (
 	GitlabWebhookSecretFlag          = "gitlab-webhook-secret" // nolint: gosec
 	GitlabStatusRetryEnabledFlag     = "gitlab-status-retry-enabled"
 	IncludeGitUntrackedFiles         = "include-git-untracked-files"
+	APIArtifactTokensFlag            = "api-artifact-tokens" // nolint: gosec
+	APIIPAllowlistFlag               = "api-ip-allowlist"
 	APISecretFlag                    = "api-secret"
 	HidePrevPlanComments             = "hide-prev-plan-comments"
 	QuietPolicyChecks                = "quiet-policy-checks"
+	KubernetesJobDataVolumeClaimFlag = "kubernetes-job-data-volume-claim"
+	KubernetesJobImageFlag           = "kubernetes-job-image"
+	KubernetesJobNamespaceFlag       = "kubernetes-job-namespace"
+	KubernetesJobNodeSelectorFlag    = "kubernetes-job-node-selector"
+	KubernetesJobResourcesFlag       = "kubernetes-job-resources"
+	KubernetesJobServiceAccountFlag  = "kubernetes-job-service-account"
+	LeaderElectionFlag               = "leader-election"
 	LockingDBType                    = "locking-db-type"
 	LogLevelFlag                     = "log-level"
+	LogSinkJobOutputFlag             = "log-sink-job-output"
+	LogSinksFlag                     = "log-sinks"
 	MarkdownTemplateOverridesDirFlag = "markdown-template-overrides-dir"
 	MaxCommentsPerCommand            = "max-comments-per-command"
+	MaxConcurrentCommandsFlag        = "max-concurrent-commands"
+	MaxConcurrentProjectsFlag        = "max-concurrent-projects"
+	MaxConcurrentProjectsPerRepoFlag = "max-concurrent-projects-per-repo"
+	MaxPlanAgeFlag                   = "max-plan-age"
+	MaxPreviewEnvironmentsFlag       = "max-preview-environments"
+	MentionCodeOwnersFlag            = "mention-code-owners"
 	ParallelPoolSize                 = "parallel-pool-size"
 	PendingApplyStatusFlag           = "pending-apply-status"
+	PlanfileEncryptionKeyFlag        = "planfile-encryption-key"
+	PlanfileSigningKeyFlag           = "planfile-signing-key"
+	PullRateLimitFlag                = "pull-rate-limit"
 	StatsNamespace                   = "stats-namespace"
 	AllowDraftPRs                    = "allow-draft-prs"
 	PortFlag                         = "port"
+	RecordResourceChangesFlag        = "record-resource-changes"
 	RedisDB                          = "redis-db"
 	RedisHost                        = "redis-host"
 	RedisPassword                    = "redis-password"
 	RedisPort                        = "redis-port"
 	RedisTLSEnabled                  = "redis-tls-enabled"
 	RedisInsecureSkipVerify          = "redis-insecure-skip-verify"
+	RegistryCacheHostsFlag           = "registry-cache-hosts"
+	RegistryCacheMaxSizeMBFlag       = "registry-cache-max-size-mb"
 	RepoConfigFlag                   = "repo-config"
 	RepoConfigJSONFlag               = "repo-config-json"
+	ReplanOnBasePushFlag             = "replan-on-base-push"
 	RepoAllowlistFlag                = "repo-allowlist"
+	RepoRateLimitFlag                = "repo-rate-limit"
+	SecretsRefreshIntervalFlag       = "secrets-refresh-interval"
+	ServerRoleFlag                   = "server-role"
+	ServiceNowPasswordFlag           = "servicenow-password"
+	ServiceNowURLFlag                = "servicenow-url"
+	ServiceNowUserFlag               = "servicenow-user"
 	SilenceNoProjectsFlag            = "silence-no-projects"
 	SilenceForkPRErrorsFlag          = "silence-fork-pr-errors"
 	SilenceVCSStatusNoPlans          = "silence-vcs-status-no-plans"
@@ -145,23 +218,40 @@ const (
 	SkipCloneNoChanges               = "skip-clone-no-changes"
 	SlackTokenFlag                   = "slack-token"
 	SSLCertFileFlag                  = "ssl-cert-file"
+	SSLClientAuthFlag                = "ssl-client-auth"
+	SSLClientCAFileFlag              = "ssl-client-ca-file"
 	SSLKeyFileFlag                   = "ssl-key-file"
+	TenantsConfigFlag                = "tenants-config"
 	RestrictFileList                 = "restrict-file-list"
+	ReuseUnchangedPlansFlag          = "reuse-unchanged-plans"
 	TFDistributionFlag               = "tf-distribution" // deprecated for DefaultTFDistributionFlag
 	TFDownloadFlag                   = "tf-download"
 	TFDownloadURLFlag                = "tf-download-url"
 	UseTFPluginCache                 = "use-tf-plugin-cache"
+	ValidateFlag                     = "validate"
 	VarFileAllowlistFlag             = "var-file-allowlist"
 	VCSStatusName                    = "vcs-status-name"
+	WarmPoolsFlag                    = "warm-pools"
 	IgnoreVCSStatusNames             = "ignore-vcs-status-names"
 	TFEHostnameFlag                  = "tfe-hostname"
 	TFELocalExecutionModeFlag        = "tfe-local-execution-mode"
 	TFETokenFlag                     = "tfe-token"
+	WorkerConcurrencyFlag            = "worker-concurrency"
+	WorkQueueTypeFlag                = "work-queue-type"
+	WorkQueueURLFlag                 = "work-queue-url"
 	WriteGitCredsFlag                = "write-git-creds" // nolint: gosec
+	WebhookHistoryFlag               = "webhook-history"
 	WebhookHttpHeaders               = "webhook-http-headers"
+	WebhookIPAllowlistFlag           = "webhook-ip-allowlist"
 	WebBasicAuthFlag                 = "web-basic-auth"
 	WebUsernameFlag                  = "web-username"
 	WebPasswordFlag                  = "web-password"
+	WebOIDCIssuerURLFlag             = "web-oidc-issuer-url"
+	WebOIDCClientIDFlag              = "web-oidc-client-id"
+	WebOIDCClientSecretFlag          = "web-oidc-client-secret" // nolint: gosec
+	WebOIDCScopesFlag                = "web-oidc-scopes"
+	WebOIDCGroupsClaimFlag           = "web-oidc-groups-claim"
+	WebPermissionsConfigFlag         = "web-permissions-config"
 	WebsocketCheckOrigin             = "websocket-check-origin"
 
 	// NOTE: Must manually set these as defaults in the setDefaults function.
@@ -178,6 +268,7 @@ const (
 	DefaultEmojiReaction                = ""
 	DefaultExecutableName               = "atlantis"
 	DefaultMarkdownTemplateOverridesDir = "~/.markdown_templates"
+	DefaultGerritVerifiedLabel          = "Verified"
 	DefaultGHHostname                   = "github.com"
 	DefaultGiteaBaseURL                 = "https://gitea.com"
 	DefaultGiteaPageSize                = 30
@@ -187,10 +278,17 @@ const (
 	DefaultIgnoreVCSStatusNames         = ""
 	DefaultMaxCommentsPerCommand        = 100
 	DefaultParallelPoolSize             = 15
+	DefaultRegistryCacheHosts           = "registry.terraform.io,registry.opentofu.org"
+	DefaultRegistryCacheMaxSizeMB       = 10240
+	DefaultSecretsRefreshInterval       = "5m"
+	DefaultServerRole                   = server.AllServerRole
+	DefaultSSLClientAuth                = server.SSLClientAuthWebhooksAndAPI
 	DefaultStatsNamespace               = "atlantis"
 	DefaultPort                         = 4141
 	DefaultRedisDB                      = 0
 	DefaultRedisPort                    = 6379
+	DefaultWorkerConcurrency            = 4
+	DefaultWorkQueueType                = server.RedisWorkQueue
 	DefaultRedisTLSEnabled              = false
 	DefaultRedisInsecureSkipVerify      = false
 	DefaultTFDistribution               = TFDistributionTerraform
@@ -201,6 +299,8 @@ const (
 	DefaultWebBasicAuth                 = false
 	DefaultWebUsername                  = "atlantis"
 	DefaultWebPassword                  = "atlantis"
+	DefaultWebOIDCScopes                = "openid,profile,email"
+	DefaultWebOIDCGroupsClaim           = "groups"
 )
 
 var stringFlags = map[string]stringFlag{
@@ -226,13 +326,38 @@ var stringFlags = map[string]stringFlag{
 		description:  "Azure DevOps hostname to support cloud and self hosted instances.",
 		defaultValue: "dev.azure.com",
 	},
+	AgentPoolsFlag: {
+		description: "Comma separated list of pools of remote agents, name:token:repo-pattern, running the terraform commands of the projects" +
+			" whose agent_pool is the name of the pool. Only the repos matching the pattern may use the pool, supporting * wildcards ex. owner/* or */* for all repos." +
+			" Should be specified via the ATLANTIS_AGENT_POOLS environment variable.",
+	},
 	AllowCommandsFlag: {
 		description:  "Comma separated list of acceptable atlantis commands.",
 		defaultValue: DefaultAllowCommands,
 	},
+	ApplyReportSigningKeyFlag: {
+		description: "Key used to sign the reports of the applies, exported through the API as change management evidence, either a base64 encoded 256 bit HMAC key," +
+			" ex. from 'openssl rand -base64 32', or a PEM encoded Ed25519 private key. If not set, apply reports aren't recorded.",
+	},
+	ApplyStateCheckFlag: {
+		description: "Check the state didn't change since plans were generated before applying them." +
+			" Accepts 'warn' to list the resources that diverged in the apply output or 'abort' to not apply the plan." +
+			" The state isn't checked if empty.",
+	},
+	ApplyStateDiffFlag: {
+		description: "Comma separated list of resource types, supporting * wildcards ex. aws_iam_*, whose changes after applies are compared to what plans advertised." +
+			" Changes plans didn't include are listed in the apply output. Nothing is compared if empty.",
+	},
+	ArtifactMaxAgeFlag: {
+		description: "How long planfiles, exported plan JSONs and job logs are retained for after they were last written, ex. 720h." +
+			" Older artifacts are deleted in the background. If not set, artifacts are retained whatever their age.",
+	},
 	AtlantisURLFlag: {
 		description: "URL that Atlantis can be reached at. Defaults to http://$(hostname):$port where $port is from --" + PortFlag + ". Supports a base path ex. https://example.com/basepath.",
 	},
+	AuditAdvisoriesFlag: {
+		description: "Path to a YAML file of advisories for providers and modules that the audit workflow step reports, ex. known vulnerabilities. It's read on every audit so it can be updated without restarting Atlantis.",
+	},
 	AutoDiscoverModeFlag: {
 		description: "Auto discover mode controls whether projects in a repo are discovered by Atlantis. Defaults to 'auto' which " +
 			"means projects will be discovered when no explicit projects are defined in repo config. Also supports 'enabled' (always " +
@@ -253,11 +378,21 @@ var stringFlags = map[string]stringFlag{
 			" A custom Workflow that uses autoplan 'when_modified' will ignore this value.",
 		defaultValue: DefaultAutoplanFileList,
 	},
+	BitbucketAccessTokenFlag: {
+		description: "Bitbucket Cloud workspace, project or repository access token, used instead of --" + BitbucketTokenFlag + "." +
+			" Can also be specified via the ATLANTIS_BITBUCKET_ACCESS_TOKEN environment variable.",
+	},
 	BitbucketApiUserFlag: {
 		description: "Bitbucket username for API calls. If not set, defaults to bitbucket-user for backward compatibility. Can also be specified via the ATLANTIS_BITBUCKET_API_USER environment variable.",
 	},
+	BitbucketOAuthClientIDFlag: {
+		description: "Key of the Bitbucket Cloud OAuth consumer authenticating with the client credentials grant, used instead of --" + BitbucketTokenFlag + ".",
+	},
+	BitbucketOAuthClientSecretFlag: {
+		description: "Secret of the Bitbucket Cloud OAuth consumer. Can also be specified via the ATLANTIS_BITBUCKET_OAUTH_CLIENT_SECRET environment variable.",
+	},
 	BitbucketUserFlag: {
-		description: "Bitbucket username for git operations.",
+		description: "Bitbucket username for git operations. With an access token or OAuth consumer, the username Atlantis comments as.",
 	},
 	BitbucketTokenFlag: {
 		description: "Bitbucket app password of API user. Can also be specified via the ATLANTIS_BITBUCKET_TOKEN environment variable.",
@@ -283,9 +418,42 @@ var stringFlags = map[string]stringFlag{
 			" after the pull request is merged.",
 		defaultValue: "branch",
 	},
+	CodeCommitGitPasswordFlag: {
+		description: "Password of the HTTPS Git credentials of the IAM user of Atlantis, used to clone CodeCommit repos." +
+			" Can also be specified via the ATLANTIS_CODECOMMIT_GIT_PASSWORD environment variable.",
+	},
+	CodeCommitGitUserFlag: {
+		description: "Username of the HTTPS Git credentials of the IAM user of Atlantis, used to clone CodeCommit repos." +
+			" API calls are signed with the credentials of the AWS SDK's default chain, ex. AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, web identity or instance roles.",
+	},
+	CodeCommitRegionFlag: {
+		description: "AWS region of the CodeCommit repos. Defaults to the AWS_REGION environment variable.",
+	},
+	CodeCommitWebhookSecretFlag: {
+		description: "Optional secret used to validate CodeCommit events sent by EventBridge API destinations or SNS, as the password of their basic auth." +
+			" SECURITY WARNING: If not specified, Atlantis won't be able to validate that the incoming webhook call came from CodeCommit. " +
+			"This means that an attacker could spoof calls to Atlantis and cause it to perform malicious actions. " +
+			"Should be specified via the ATLANTIS_CODECOMMIT_WEBHOOK_SECRET environment variable.",
+	},
+	CommandAuthzPolicyFlag: {
+		description: "Path to a rego file, or a directory of rego files, evaluated with the opa binary to authorize every comment command." +
+			" The policy must define data.atlantis.authz.allow and can give the reasons commands are denied in data.atlantis.authz.deny.",
+	},
+	ConcurrencyGroupsFlag: {
+		description: "Comma separated list of name:limit concurrency groups, limiting how many terraform commands of the projects with their concurrency_group run at once." +
+			" With name:limit:command, ex. prod:1:apply, only the command is limited.",
+	},
 	ConfigFlag: {
 		description: "Path to yaml config file where flag values can also be set.",
 	},
+	DBEncryptionKeyFlag: {
+		description: "Base64 encoded 256 bit key, ex. from 'openssl rand -base64 32', used to encrypt the values stored in the BoltDB database at rest." +
+			" Values stored before it was set are encrypted on startup. Can't be used with --" + DBEncryptionKMSKeyIDFlag + ".",
+	},
+	DBEncryptionKMSKeyIDFlag: {
+		description: "ID, ARN or alias of an AWS KMS key used to encrypt the values stored in the BoltDB database at rest with envelope encryption." +
+			" The data key is generated with the KMS key on first start and stored encrypted by it in the database.",
+	},
 	DataDirFlag: {
 		description:  "Path to directory to store Atlantis data.",
 		defaultValue: DefaultDataDir,
@@ -302,6 +470,14 @@ var stringFlags = map[string]stringFlag{
 		description:  "Emoji Reaction to use to react to comments.",
 		defaultValue: DefaultEmojiReaction,
 	},
+	EmojiReactionFailure: {
+		description:  "Emoji Reaction to add to the command comment once the command finished with errors. Disabled if not set.",
+		defaultValue: "",
+	},
+	EmojiReactionSuccess: {
+		description:  "Emoji Reaction to add to the command comment once the command finished successfully. Disabled if not set.",
+		defaultValue: "",
+	},
 	ExecutableName: {
 		description:  "Comment command executable name.",
 		defaultValue: DefaultExecutableName,
@@ -332,11 +508,11 @@ var stringFlags = map[string]stringFlag{
 		description: "A path to a file containing the GitHub token of API user. Can also be specified via the ATLANTIS_GH_TOKEN_FILE environment variable.",
 	},
 	GHAppKeyFlag: {
-		description:  "The GitHub App's private key",
+		description:  "The GitHub App's private key. Can contain several PEM encoded private keys, the first one GitHub accepts is used.",
 		defaultValue: "",
 	},
 	GHAppKeyFileFlag: {
-		description:  "A path to a file containing the GitHub App's private key",
+		description:  "A path to a file containing the GitHub App's private key, or a comma-separated list of paths to the files of several private keys. The files are reloaded when they're modified.",
 		defaultValue: "",
 	},
 	GHAppSlugFlag: {
@@ -352,6 +528,26 @@ var stringFlags = map[string]stringFlag{
 			"This means that an attacker could spoof calls to Atlantis and cause it to perform malicious actions. " +
 			"Should be specified via the ATLANTIS_GH_WEBHOOK_SECRET environment variable.",
 	},
+	GerritBaseURLFlag: {
+		description: "Base URL of Gerrit server installation. Must include 'http://' or 'https://'.",
+	},
+	GerritPasswordFlag: {
+		description: "Gerrit HTTP password of API user. Can also be specified via the ATLANTIS_GERRIT_PASSWORD environment variable.",
+	},
+	GerritUserFlag: {
+		description:  "Gerrit username of API user.",
+		defaultValue: "",
+	},
+	GerritVerifiedLabelFlag: {
+		description:  "Gerrit label Atlantis votes on with the result of its commands, +1 once a change is applied and -1 on failures, to gate submitting changes.",
+		defaultValue: DefaultGerritVerifiedLabel,
+	},
+	GerritWebhookSecretFlag: {
+		description: "Optional secret used to validate Gerrit webhooks, as the password of their basic auth." +
+			" SECURITY WARNING: If not specified, Atlantis won't be able to validate that the incoming webhook call came from Gerrit. " +
+			"This means that an attacker could spoof calls to Atlantis and cause it to perform malicious actions. " +
+			"Should be specified via the ATLANTIS_GERRIT_WEBHOOK_SECRET environment variable.",
+	},
 	GiteaBaseURLFlag: {
 		description: "Base URL of Gitea server installation. Must include 'http://' or 'https://'.",
 	},
@@ -395,9 +591,37 @@ var stringFlags = map[string]stringFlag{
 			"This means that an attacker could spoof calls to Atlantis and cause it to perform malicious actions. " +
 			"Should be specified via the ATLANTIS_GITLAB_WEBHOOK_SECRET environment variable.",
 	},
+	APIIPAllowlistFlag: {
+		description: "Comma separated list of CIDRs and IPs requests to the /api/* endpoints are allowed from." +
+			fmt.Sprintf(" Supports %s for the ranges GitHub publishes through its meta API. Requests are allowed from anywhere if empty.", server.GithubIPRanges),
+	},
+	APIArtifactTokensFlag: {
+		description: "Comma separated list of tokens that may only download the plan artifacts of jobs through /api/jobs/{job-id}/artifacts/plan." +
+			" Tokens can be scoped to repos with token:repo-pattern, supporting * wildcards ex. token:owner/*. Should be specified via the ATLANTIS_API_ARTIFACT_TOKENS environment variable.",
+	},
 	APISecretFlag: {
 		description: "Secret used to validate requests made to the /api/* endpoints",
 	},
+	KubernetesJobDataVolumeClaimFlag: {
+		description: fmt.Sprintf("Persistent volume claim of the data dir mounted at --%s in the Kubernetes Jobs, so they run in the working dir of the pull request.", DataDirFlag) +
+			fmt.Sprintf(" Required with --%s.", KubernetesJobImageFlag),
+	},
+	KubernetesJobImageFlag: {
+		description: "Image of the Kubernetes Jobs terraform plans and applies are run as, instead of running them in the Atlantis process." +
+			" Atlantis must run in the cluster, its service account must be able to create jobs and read the logs of their pods.",
+	},
+	KubernetesJobNamespaceFlag: {
+		description: "Namespace of the Kubernetes Jobs. Defaults to the namespace of the Atlantis pod.",
+	},
+	KubernetesJobNodeSelectorFlag: {
+		description: "Comma separated list of label=value the nodes of the Kubernetes Jobs are selected with, ex. pool=terraform.",
+	},
+	KubernetesJobResourcesFlag: {
+		description: "Comma separated list of the resources of the Kubernetes Jobs, ex. requests.cpu=1,requests.memory=2Gi,limits.memory=4Gi.",
+	},
+	KubernetesJobServiceAccountFlag: {
+		description: "Service account of the Kubernetes Jobs, ex. one with the cloud credentials of terraform. Defaults to the default service account of the namespace.",
+	},
 	LockingDBType: {
 		description:  "The locking database type to use for storing plan and apply locks.",
 		defaultValue: DefaultLockingDBType,
@@ -406,10 +630,26 @@ var stringFlags = map[string]stringFlag{
 		description:  "Log level. Either debug, info, warn, or error.",
 		defaultValue: DefaultLogLevel,
 	},
+	LogSinksFlag: {
+		description: "Comma separated list of log services to ship logs to directly, in addition to stdout." +
+			" Supports loki+https://<host>, cloudwatch://<log group> and cloud-logging://projects/<project>/logs/<log>.",
+	},
 	MarkdownTemplateOverridesDirFlag: {
 		description:  "Directory for custom overrides to the markdown templates used for comments.",
 		defaultValue: DefaultMarkdownTemplateOverridesDir,
 	},
+	MaxPlanAgeFlag: {
+		description: "Maximum age of a plan, ex. 4h, before the 'fresh' apply requirement rejects applying it." +
+			" If not set, the 'fresh' requirement only checks that the base branch hasn't advanced since the plan.",
+	},
+	PlanfileEncryptionKeyFlag: {
+		description: "Base64 encoded 256 bit key, ex. from 'openssl rand -base64 32', used to encrypt planfiles at rest." +
+			" Planfiles are only decrypted while a command needs them, ex. apply. If not set, planfiles aren't encrypted.",
+	},
+	PlanfileSigningKeyFlag: {
+		description: "Key used to sign planfiles when they're generated and verify them before they're applied, either a base64 encoded 256 bit HMAC key," +
+			" ex. from 'openssl rand -base64 32', or a PEM encoded Ed25519 private key. If not set, planfiles aren't signed.",
+	},
 	StatsNamespace: {
 		description:  "Namespace for aggregating stats.",
 		defaultValue: DefaultStatsNamespace,
@@ -420,6 +660,10 @@ var stringFlags = map[string]stringFlag{
 	RedisPassword: {
 		description: "The Redis Password for when using a Locking DB type of 'redis'.",
 	},
+	RegistryCacheHostsFlag: {
+		description:  "Comma-separated hostnames of the registries whose providers and modules are cached when --" + EnableRegistryCacheFlag + " is set.",
+		defaultValue: DefaultRegistryCacheHosts,
+	},
 	RepoConfigFlag: {
 		description: "Path to a repo config file, used to customize how Atlantis runs on each repo. See runatlantis.io/docs for more details.",
 	},
@@ -430,17 +674,49 @@ var stringFlags = map[string]stringFlag{
 		description: "Comma separated list of repositories that Atlantis will operate on. " +
 			"The format is {hostname}/{owner}/{repo}, ex. github.com/runatlantis/atlantis. '*' matches any characters until the next comma. Examples: " +
 			"all repos: '*' (not secure), an entire hostname: 'internalgithub.com/*' or an organization: 'github.com/runatlantis/*'." +
+			" Prefix with '!' to deny repos, ex. '!github.com/runatlantis/sandbox', and suffix with '@{branch}' to only operate on pull requests targeting matching branches, ex. 'github.com/runatlantis/*@release/*'." +
 			" For Bitbucket Server, {owner} is the name of the project (not the key).",
 	},
+	SecretsRefreshIntervalFlag: {
+		description: "How often the secrets credentials reference in Vault (vault://), AWS Secrets Manager (aws-sm://) or Google Cloud Secret Manager (gcp-sm://) are fetched again to pick up rotated secrets." +
+			" Referencing secrets is supported by the token, webhook secret and password flags and the OPENROUTER_API_KEY environment variable.",
+		defaultValue: DefaultSecretsRefreshInterval,
+	},
+	ServerRoleFlag: {
+		description: fmt.Sprintf("Role of the server: %s handles webhooks and runs their commands, %s handles webhooks and dispatches their commands to the workers through a work queue, %s runs the commands of the work queue.",
+			server.AllServerRole, server.FrontendServerRole, server.WorkerServerRole) +
+			fmt.Sprintf(" The %s and %s roles require --%s=redis, which shares the locks and job output, see --%s for the work queue.", server.FrontendServerRole, server.WorkerServerRole, LockingDBType, WorkQueueTypeFlag),
+		defaultValue: DefaultServerRole,
+	},
+	ServiceNowPasswordFlag: {
+		description: "Password of the ServiceNow user used to manage change requests for the change_request apply requirement.",
+	},
+	ServiceNowURLFlag: {
+		description: "Base URL of the ServiceNow instance used to manage change requests for the change_request apply requirement, ex. https://acme.service-now.com.",
+	},
+	ServiceNowUserFlag: {
+		description: "ServiceNow user used to manage change requests for the change_request apply requirement.",
+	},
 	SlackTokenFlag: {
 		description: "API token for Slack notifications.",
 	},
 	SSLCertFileFlag: {
 		description: "File containing x509 Certificate used for serving HTTPS. If the cert is signed by a CA, the file should be the concatenation of the server's certificate, any intermediates, and the CA's certificate.",
 	},
+	SSLClientAuthFlag: {
+		description:  fmt.Sprintf("Which endpoints require client certificates signed by --%s: %s, the webhook and API endpoints, or %s.", SSLClientCAFileFlag, server.SSLClientAuthWebhooksAndAPI, server.SSLClientAuthAll),
+		defaultValue: DefaultSSLClientAuth,
+	},
+	SSLClientCAFileFlag: {
+		description: "File containing the x509 certificates of the CAs client certificates are verified against, enabling mutual TLS." +
+			fmt.Sprintf(" Requires --%s and --%s.", SSLCertFileFlag, SSLKeyFileFlag),
+	},
 	SSLKeyFileFlag: {
 		description: fmt.Sprintf("File containing x509 private key matching --%s.", SSLCertFileFlag),
 	},
+	TenantsConfigFlag: {
+		description: "Path to a YAML file defining tenants and the repos they own. Only repos owned by a tenant are allowed, their working dirs are kept in a data dir per tenant, tenants can have VCS credentials of their own, OIDC users only see the repos of the tenants of their groups and command metrics are tagged with their tenant.",
+	},
 	TFDistributionFlag: {
 		description: "[Deprecated for --default-tf-distribution].",
 		hidden:      true,
@@ -480,6 +756,14 @@ var stringFlags = map[string]stringFlag{
 		description:  "Name used to identify Atlantis for pull request statuses.",
 		defaultValue: DefaultVCSStatusName,
 	},
+	WarmPoolsFlag: {
+		description: "Comma separated list of owner/repo:size warm pools, keeping size working dirs of the repo cloned and initialized on each base branch to speed up the clones of its pull requests." +
+			" Only used with the merge checkout strategy.",
+	},
+	WebhookIPAllowlistFlag: {
+		description: "Comma separated list of CIDRs and IPs webhooks are allowed from, ex. 10.0.0.0/8,github." +
+			fmt.Sprintf(" Supports %s for the ranges GitHub publishes through its meta API, refreshed hourly. Webhooks are allowed from anywhere if empty.", server.GithubIPRanges),
+	},
 	WebhookHttpHeaders: {
 		description: "Additional headers added to each HTTP POST payload when using HTTP webhooks provided as a JSON string." +
 			" The map key is the header name and the value is the header value (string) or values (array of string)." +
@@ -494,6 +778,35 @@ var stringFlags = map[string]stringFlag{
 		description:  "Password used for Web Basic Authentication on Atlantis HTTP Middleware",
 		defaultValue: DefaultWebPassword,
 	},
+	WebOIDCIssuerURLFlag: {
+		description: "Issuer URL of an OIDC provider to log in to the web UI with instead of --" + WebBasicAuthFlag + ", ex. https://accounts.google.com.",
+	},
+	WebOIDCClientIDFlag: {
+		description: "Client ID of Atlantis with the --" + WebOIDCIssuerURLFlag + " provider.",
+	},
+	WebOIDCClientSecretFlag: {
+		description: "Client secret of Atlantis with the --" + WebOIDCIssuerURLFlag + " provider. It also signs the web UI sessions.",
+	},
+	WebOIDCScopesFlag: {
+		description:  "Comma-separated scopes requested from the --" + WebOIDCIssuerURLFlag + " provider, ex. to include the user's groups in the ID token.",
+		defaultValue: DefaultWebOIDCScopes,
+	},
+	WebOIDCGroupsClaimFlag: {
+		description:  "ID token claim listing the groups of users logged in with --" + WebOIDCIssuerURLFlag + ".",
+		defaultValue: DefaultWebOIDCGroupsClaim,
+	},
+	WebPermissionsConfigFlag: {
+		description: "Path to a YAML file mapping the groups of users logged in with --" + WebOIDCIssuerURLFlag + " to the web UI capabilities they're allowed, optionally scoped to repos. Users are allowed everything if it isn't set.",
+	},
+	WorkQueueTypeFlag: {
+		description: fmt.Sprintf("Broker of the work queue of the %s and %s roles: %s keeps it in a stream of the --%s=redis database, %s in the Amazon SQS queue and %s in a JetStream stream of the NATS servers at --%s.",
+			server.FrontendServerRole, server.WorkerServerRole, server.RedisWorkQueue, LockingDBType, server.SQSWorkQueue, server.NATSWorkQueue, WorkQueueURLFlag),
+		defaultValue: DefaultWorkQueueType,
+	},
+	WorkQueueURLFlag: {
+		description: fmt.Sprintf("URL of the work queue when --%s is %s or %s, ex. https://sqs.us-east-1.amazonaws.com/123456789012/atlantis or nats://nats:4222. SQS is authenticated with the default credential chain of the AWS SDK.",
+			WorkQueueTypeFlag, server.SQSWorkQueue, server.NATSWorkQueue),
+	},
 }
 
 var boolFlags = map[string]boolFlag{
@@ -501,6 +814,10 @@ var boolFlags = map[string]boolFlag{
 		description:  "Allow Atlantis to run on pull requests from forks. A security issue for public repos.",
 		defaultValue: false,
 	},
+	ApplyConfirmDestroysFlag: {
+		description:  "Require confirming applies of plans that destroy resources with 'atlantis apply --confirm <token>'.",
+		defaultValue: false,
+	},
 	AutoplanModules: {
 		description:  "Automatically plan projects that have a changed module from the local repository.",
 		defaultValue: false,
@@ -536,14 +853,42 @@ var boolFlags = map[string]boolFlag{
 		description:  "Enable Atlantis to use regular expressions on plan/apply commands when \"-p\" flag is passed with it.",
 		defaultValue: false,
 	},
+	EnableRegistryCacheFlag: {
+		description:  "Point terraform at a caching proxy of the provider and module registries served by Atlantis, so providers and modules are downloaded once and keep being installed while their registries are down.",
+		defaultValue: false,
+	},
+	EnableStateForceUnlockFlag: {
+		description:  "Enable force-unlocking the Terraform state locks plans and applies failed on from the UI. Requires --" + WebBasicAuthFlag + " or --" + WebOIDCIssuerURLFlag + ".",
+		defaultValue: false,
+	},
 	EnableProfilingAPI: {
 		description:  "Enable net/http/pprof routes in server for continuous profiling.",
 		defaultValue: false,
 	},
+	EnableProgressCommentsFlag: {
+		description:  "Comment when plan and apply comment commands start and edit the comment with their progress until they complete. Currently only GitHub is supported.",
+		defaultValue: false,
+	},
+	ExportPlanJSONFlag: {
+		description:  "Store the JSON representation of plans, from terraform show -json, so they can be fetched through the /api/plans endpoint until their pull request is closed.",
+		defaultValue: false,
+	},
+	EnablePlanGraphFlag: {
+		description:  "Graph the resources each plan changes and the resources depending on them as a Mermaid diagram in the plan comment.",
+		defaultValue: false,
+	},
 	EnableDiffMarkdownFormat: {
 		description:  "Enable Atlantis to format Terraform plan output into a markdown-diff friendly format for color-coding purposes.",
 		defaultValue: false,
 	},
+	EnableDriftPullRequestsFlag: {
+		description:  "Open a pull request reporting the drift, and remediating it where the changes to the code can be derived, when a plan that wasn't run for a pull request has changes. The pull request is assigned to the project's owners. Currently only GitHub is supported.",
+		defaultValue: false,
+	},
+	EnablePreviewEnvironmentsFlag: {
+		description:  "Deploy the preview environments of the preview projects, the projects with a preview key in the repo config, on atlantis preview and on autoplan, and destroy them once their pull request is closed or they expire. Requires preview to be in --allow-commands for atlantis preview.",
+		defaultValue: false,
+	},
 	FailOnPreWorkflowHookError: {
 		description:  "Fail and do not run the requested Atlantis command if any of the pre workflow hooks error.",
 		defaultValue: false,
@@ -552,6 +897,14 @@ var boolFlags = map[string]boolFlag{
 		description:  "Feature flag to enable functionality to allow mergeable check to ignore apply required check",
 		defaultValue: false,
 	},
+	GHCodeScanningFlag: {
+		description:  "Upload policy check findings and SARIF reports written by custom run steps to GitHub code scanning so they show up in the repo's Security tab and as annotations.",
+		defaultValue: false,
+	},
+	GHDeploymentsFlag: {
+		description:  "Create a GitHub deployment for each project apply so applies show up in the repo's environments.",
+		defaultValue: false,
+	},
 	GitlabStatusRetryEnabledFlag: {
 		description:  "Enable enhanced retry logic for GitLab pipeline status updates with exponential backoff.",
 		defaultValue: false,
@@ -565,10 +918,24 @@ var boolFlags = map[string]boolFlag{
 			"VCS support is limited to: GitHub.",
 		defaultValue: false,
 	},
+	MentionCodeOwnersFlag: {
+		description: "Mention the owners of projects in the base branch's CODEOWNERS on their plans with changes, " +
+			"unless the projects declare owners in atlantis.yaml.",
+		defaultValue: false,
+	},
 	IncludeGitUntrackedFiles: {
 		description:  "Include git untracked files in the Atlantis modified file scope.",
 		defaultValue: false,
 	},
+	LeaderElectionFlag: {
+		description: "Elect the active instance among the instances sharing the Redis database, the others standing by and rejecting webhooks until the active one fails and one of them is promoted." +
+			" Route webhooks to the instances whose /active endpoint responds 200. Requires --" + LockingDBType + "=redis.",
+		defaultValue: false,
+	},
+	LogSinkJobOutputFlag: {
+		description:  "Also ship the terraform output of jobs to the log services of --" + LogSinksFlag + ".",
+		defaultValue: false,
+	},
 	ParallelPlanFlag: {
 		description:  "Run plan operations in parallel.",
 		defaultValue: false,
@@ -638,10 +1005,22 @@ var boolFlags = map[string]boolFlag{
 		description:  "Switches on or off the Basic Authentication on the HTTP Middleware interface",
 		defaultValue: DefaultWebBasicAuth,
 	},
+	RecordResourceChangesFlag: {
+		description:  "Record the changes applies make to resources in the database so they can be queried through the /api/resource-changes endpoint.",
+		defaultValue: false,
+	},
+	ReplanOnBasePushFlag: {
+		description:  "Plan open GitHub pull requests again when a push to their base branch changes the projects they planned, discarding the stale plans. Requires the webhook to send push events.",
+		defaultValue: false,
+	},
 	RestrictFileList: {
 		description:  "Block plan requests from projects outside the files modified in the pull request.",
 		defaultValue: false,
 	},
+	ReuseUnchangedPlansFlag: {
+		description:  "Reuse the existing plans of projects whose directory and lock file didn't change on autoplan, ex. after a rebase, instead of planning them again.",
+		defaultValue: false,
+	},
 	WebsocketCheckOrigin: {
 		description:  "Enable websocket origin check",
 		defaultValue: false,
@@ -654,8 +1033,30 @@ var boolFlags = map[string]boolFlag{
 		description:  "Enable the use of the Terraform plugin cache",
 		defaultValue: true,
 	},
+	ValidateFlag: {
+		description: "Validate the configuration and exit instead of starting the server. Parses all flags and config files," +
+			" probes connectivity to the VCS hosts, the locking db and the plan summarizer provider, prints a report" +
+			" and exits non-zero if any check failed.",
+		defaultValue: false,
+	},
 }
 var intFlags = map[string]intFlag{
+	ApplyConfirmProjectsFlag: {
+		description:  "Require confirming applies of more than this many projects with 'atlantis apply --confirm <token>'. 0 means applies of any number of projects don't need confirming.",
+		defaultValue: 0,
+	},
+	ArtifactRepoQuotaMBFlag: {
+		description:  "How many megabytes of each of planfiles, exported plan JSONs and job logs are retained per repo, the oldest are deleted first in the background. 0 means there's no quota.",
+		defaultValue: 0,
+	},
+	DiskCriticalThresholdFlag: {
+		description:  "Percentage of the disk of the data dir used over which new plans are rejected with a comment, instead of failing midway. 0 means they aren't.",
+		defaultValue: 0,
+	},
+	DiskEvictionThresholdFlag: {
+		description:  "Percentage of the disk of the data dir used over which the least recently used working dirs are evicted in the background. 0 means they aren't.",
+		defaultValue: 0,
+	},
 	CheckoutDepthFlag: {
 		description: fmt.Sprintf("Used only if --%s=%s.", CheckoutStrategyFlag, CheckoutStrategyMerge) +
 			" How many commits to include in each of base and feature branches when cloning repository." +
@@ -666,6 +1067,22 @@ var intFlags = map[string]intFlag{
 		description:  "If non-zero, the maximum number of comments to split command output into before truncating.",
 		defaultValue: DefaultMaxCommentsPerCommand,
 	},
+	MaxConcurrentCommandsFlag: {
+		description:  fmt.Sprintf("How many commands run at once. Once reached, commands wait in a queue per repo and the repos take turns. Only used with --%s=%s. 0 means they aren't limited.", ServerRoleFlag, server.AllServerRole),
+		defaultValue: 0,
+	},
+	MaxConcurrentProjectsFlag: {
+		description:  "How many terraform commands of projects, ex. plans and applies, run at once. The others wait. 0 means they aren't limited.",
+		defaultValue: 0,
+	},
+	MaxConcurrentProjectsPerRepoFlag: {
+		description:  "How many terraform commands of the projects of each repo run at once. The others wait. 0 means they aren't limited.",
+		defaultValue: 0,
+	},
+	MaxPreviewEnvironmentsFlag: {
+		description:  "How many preview environments may exist at once across all repos. 0 means they aren't limited.",
+		defaultValue: 0,
+	},
 	GiteaPageSizeFlag: {
 		description:  "Optional value that specifies the number of results per page to expect from Gitea.",
 		defaultValue: DefaultGiteaPageSize,
@@ -674,6 +1091,14 @@ var intFlags = map[string]intFlag{
 		description:  "Max size of the wait group that runs parallel plans and applies (if enabled).",
 		defaultValue: DefaultParallelPoolSize,
 	},
+	PullRateLimitFlag: {
+		description:  "How many commands a pull request can run per minute, the others are rejected. 0 means they aren't limited.",
+		defaultValue: 0,
+	},
+	RepoRateLimitFlag: {
+		description:  "How many commands a repo can run per minute, the others are rejected. 0 means they aren't limited.",
+		defaultValue: 0,
+	},
 	PortFlag: {
 		description:  "Port to bind to.",
 		defaultValue: DefaultPort,
@@ -686,6 +1111,18 @@ var intFlags = map[string]intFlag{
 		description:  "The Redis Port for when using a Locking DB type of 'redis'.",
 		defaultValue: DefaultRedisPort,
 	},
+	RegistryCacheMaxSizeMBFlag: {
+		description:  "How many megabytes of provider packages and module archives the registry cache keeps when --" + EnableRegistryCacheFlag + " is set, the least recently used are evicted first.",
+		defaultValue: DefaultRegistryCacheMaxSizeMB,
+	},
+	WorkerConcurrencyFlag: {
+		description:  fmt.Sprintf("How many commands of the work queue a worker runs at once. Only used with --%s=%s.", ServerRoleFlag, server.WorkerServerRole),
+		defaultValue: DefaultWorkerConcurrency,
+	},
+	WebhookHistoryFlag: {
+		description:  "How many of the most recently received webhooks to keep, with their secrets redacted, to inspect and replay them from the UI. Requires --" + WebBasicAuthFlag + " or --" + WebOIDCIssuerURLFlag + ". 0 means webhooks aren't kept.",
+		defaultValue: 0,
+	},
 }
 
 var int64Flags = map[string]int64Flag{
@@ -751,6 +1188,8 @@ type DefaultServerCreator struct{}
 // It's an abstraction to help us test.
 type ServerStarter interface {
 	Start() error
+	// Validate runs the checks of --validate and writes a report to out.
+	Validate(out io.Writer) error
 }
 
 // NewServer returns the real Atlantis server object.
@@ -895,6 +1334,16 @@ func (s *ServerCmd) run() error {
 	if err != nil {
 		return fmt.Errorf("initializing server: %w", err)
 	}
+	if userConfig.Validate {
+		out := io.Writer(os.Stdout)
+		if s.SilenceOutput {
+			out = io.Discard
+		}
+		if err := server.Validate(out); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		return nil
+	}
 	return server.Start()
 }
 
@@ -926,6 +1375,9 @@ func (s *ServerCmd) setDefaults(c *server.UserConfig, v *viper.Viper) {
 	if c.GiteaBaseURL == "" {
 		c.GiteaBaseURL = DefaultGiteaBaseURL
 	}
+	if c.GerritVerifiedLabel == "" {
+		c.GerritVerifiedLabel = DefaultGerritVerifiedLabel
+	}
 	if c.GiteaPageSize == 0 {
 		c.GiteaPageSize = DefaultGiteaPageSize
 	}
@@ -947,6 +1399,21 @@ func (s *ServerCmd) setDefaults(c *server.UserConfig, v *viper.Viper) {
 	if c.MarkdownTemplateOverridesDir == "" {
 		c.MarkdownTemplateOverridesDir = DefaultMarkdownTemplateOverridesDir
 	}
+	if c.SecretsRefreshInterval == "" {
+		c.SecretsRefreshInterval = DefaultSecretsRefreshInterval
+	}
+	if c.ServerRole == "" {
+		c.ServerRole = DefaultServerRole
+	}
+	if c.WorkerConcurrency == 0 {
+		c.WorkerConcurrency = DefaultWorkerConcurrency
+	}
+	if c.WorkQueueType == "" {
+		c.WorkQueueType = DefaultWorkQueueType
+	}
+	if c.SSLClientAuth == "" {
+		c.SSLClientAuth = DefaultSSLClientAuth
+	}
 	if !v.IsSet("max-comments-per-command") {
 		c.MaxCommentsPerCommand = DefaultMaxCommentsPerCommand
 	}
@@ -980,6 +1447,12 @@ func (s *ServerCmd) setDefaults(c *server.UserConfig, v *viper.Viper) {
 	if c.IgnoreVCSStatusNames == "" {
 		c.IgnoreVCSStatusNames = DefaultIgnoreVCSStatusNames
 	}
+	if c.RegistryCacheHosts == "" {
+		c.RegistryCacheHosts = DefaultRegistryCacheHosts
+	}
+	if c.RegistryCacheMaxSizeMB == 0 {
+		c.RegistryCacheMaxSizeMB = DefaultRegistryCacheMaxSizeMB
+	}
 	if c.TFEHostname == "" {
 		c.TFEHostname = DefaultTFEHostname
 	}
@@ -989,6 +1462,12 @@ func (s *ServerCmd) setDefaults(c *server.UserConfig, v *viper.Viper) {
 	if c.WebPassword == "" {
 		c.WebPassword = DefaultWebPassword
 	}
+	if c.WebOIDCScopes == "" {
+		c.WebOIDCScopes = DefaultWebOIDCScopes
+	}
+	if c.WebOIDCGroupsClaim == "" {
+		c.WebOIDCGroupsClaim = DefaultWebOIDCGroupsClaim
+	}
 	if c.AutoDiscoverModeFlag == "" {
 		c.AutoDiscoverModeFlag = DefaultAutoDiscoverMode
 	}
@@ -1005,16 +1484,174 @@ func (s *ServerCmd) validate(userConfig server.UserConfig) error {
 			TFDistributionTerraform, TFDistributionOpenTofu)
 	}
 
+	if userConfig.ApplyStateCheck != "" && userConfig.ApplyStateCheck != runtime.StateCheckWarn && userConfig.ApplyStateCheck != runtime.StateCheckAbort {
+		return fmt.Errorf("invalid --%s: not one of %s or %s", ApplyStateCheckFlag, runtime.StateCheckWarn, runtime.StateCheckAbort)
+	}
+
+	if _, err := userConfig.ToAPIArtifactTokens(); err != nil {
+		return fmt.Errorf("invalid --%s: %w", APIArtifactTokensFlag, err)
+	}
+
+	for _, pattern := range userConfig.ToApplyStateDiffResourceTypes() {
+		if err := runtime.ValidStateDiffPattern(pattern); err != nil {
+			return fmt.Errorf("invalid --%s: resource type %q: %w", ApplyStateDiffFlag, pattern, err)
+		}
+	}
+
 	checkoutStrategy := userConfig.CheckoutStrategy
 	if checkoutStrategy != CheckoutStrategyBranch && checkoutStrategy != CheckoutStrategyMerge {
 		return fmt.Errorf("invalid checkout strategy: not one of %s or %s",
 			CheckoutStrategyBranch, CheckoutStrategyMerge)
 	}
 
+	if userConfig.MaxPlanAge != "" {
+		if _, err := time.ParseDuration(userConfig.MaxPlanAge); err != nil {
+			return fmt.Errorf("invalid --%s: %w", MaxPlanAgeFlag, err)
+		}
+	}
+
+	if interval, err := time.ParseDuration(userConfig.SecretsRefreshInterval); err != nil {
+		return fmt.Errorf("invalid --%s: %w", SecretsRefreshIntervalFlag, err)
+	} else if interval <= 0 {
+		return fmt.Errorf("invalid --%s: must be positive", SecretsRefreshIntervalFlag)
+	}
+
+	if userConfig.ArtifactMaxAge != "" {
+		if _, err := time.ParseDuration(userConfig.ArtifactMaxAge); err != nil {
+			return fmt.Errorf("invalid --%s: %w", ArtifactMaxAgeFlag, err)
+		}
+	}
+	if userConfig.ArtifactRepoQuotaMB < 0 {
+		return fmt.Errorf("invalid --%s: must not be negative", ArtifactRepoQuotaMBFlag)
+	}
+	for flag, value := range map[string]int{
+		DiskCriticalThresholdFlag: userConfig.DiskCriticalThreshold,
+		DiskEvictionThresholdFlag: userConfig.DiskEvictionThreshold,
+	} {
+		if value < 0 || value > 100 {
+			return fmt.Errorf("invalid --%s: must be a percentage between 0 and 100", flag)
+		}
+	}
+	if userConfig.DiskEvictionThreshold > 0 && userConfig.DiskCriticalThreshold > 0 && userConfig.DiskEvictionThreshold >= userConfig.DiskCriticalThreshold {
+		// Otherwise plans are rejected before working dirs are evicted.
+		return fmt.Errorf("--%s must be lower than --%s", DiskEvictionThresholdFlag, DiskCriticalThresholdFlag)
+	}
+
+	if userConfig.WebhookHistory < 0 {
+		return fmt.Errorf("invalid --%s: must not be negative", WebhookHistoryFlag)
+	}
+
+	if userConfig.WebOIDCIssuerURL != "" {
+		if userConfig.WebBasicAuth {
+			return fmt.Errorf("--%s and --%s can't both be set", WebBasicAuthFlag, WebOIDCIssuerURLFlag)
+		}
+		if userConfig.WebOIDCClientID == "" || userConfig.WebOIDCClientSecret == "" {
+			return fmt.Errorf("--%s requires --%s and --%s", WebOIDCIssuerURLFlag, WebOIDCClientIDFlag, WebOIDCClientSecretFlag)
+		}
+	} else if userConfig.WebPermissionsConfig != "" {
+		return fmt.Errorf("--%s requires --%s to map the groups of users", WebPermissionsConfigFlag, WebOIDCIssuerURLFlag)
+	}
+
+	if userConfig.EnableStateForceUnlock && !userConfig.WebBasicAuth && userConfig.WebOIDCIssuerURL == "" {
+		return fmt.Errorf("--%s requires --%s or --%s so force-unlocks are authorized", EnableStateForceUnlockFlag, WebBasicAuthFlag, WebOIDCIssuerURLFlag)
+	}
+
 	if (userConfig.SSLKeyFile == "") != (userConfig.SSLCertFile == "") {
 		return fmt.Errorf("--%s and --%s are both required for ssl", SSLKeyFileFlag, SSLCertFileFlag)
 	}
 
+	switch userConfig.ServerRole {
+	case server.AllServerRole:
+	case server.FrontendServerRole, server.WorkerServerRole:
+		if userConfig.LockingDBType != "redis" {
+			return fmt.Errorf("--%s=%s requires --%s=redis so the work queue and locks are shared", ServerRoleFlag, userConfig.ServerRole, LockingDBType)
+		}
+	default:
+		return fmt.Errorf("invalid --%s %q, must be one of %s, %s or %s", ServerRoleFlag, userConfig.ServerRole, server.AllServerRole, server.FrontendServerRole, server.WorkerServerRole)
+	}
+	if pools, err := userConfig.ToAgentPools(); err != nil {
+		return fmt.Errorf("invalid --%s: %w", AgentPoolsFlag, err)
+	} else if len(pools) > 0 && userConfig.ServerRole != server.AllServerRole {
+		// The jobs are queued in the memory of the server running the command,
+		// which the agents wouldn't know how to reach.
+		return fmt.Errorf("--%s requires --%s=%s", AgentPoolsFlag, ServerRoleFlag, server.AllServerRole)
+	}
+	if userConfig.WorkerConcurrency < 0 {
+		return fmt.Errorf("invalid --%s: must not be negative", WorkerConcurrencyFlag)
+	}
+	switch userConfig.WorkQueueType {
+	case server.RedisWorkQueue:
+	case server.SQSWorkQueue, server.NATSWorkQueue:
+		if userConfig.WorkQueueURL == "" {
+			return fmt.Errorf("--%s=%s requires --%s", WorkQueueTypeFlag, userConfig.WorkQueueType, WorkQueueURLFlag)
+		}
+	default:
+		return fmt.Errorf("invalid --%s %q, must be one of %s, %s or %s", WorkQueueTypeFlag, userConfig.WorkQueueType, server.RedisWorkQueue, server.SQSWorkQueue, server.NATSWorkQueue)
+	}
+	for flag, value := range map[string]int{
+		MaxConcurrentCommandsFlag:        userConfig.MaxConcurrentCommands,
+		MaxConcurrentProjectsFlag:        userConfig.MaxConcurrentProjects,
+		MaxConcurrentProjectsPerRepoFlag: userConfig.MaxConcurrentProjectsPerRepo,
+		MaxPreviewEnvironmentsFlag:       userConfig.MaxPreviewEnvironments,
+		PullRateLimitFlag:                userConfig.PullRateLimit,
+		RepoRateLimitFlag:                userConfig.RepoRateLimit,
+	} {
+		if value < 0 {
+			return fmt.Errorf("invalid --%s: must not be negative", flag)
+		}
+	}
+	if _, err := userConfig.ToConcurrencyGroups(); err != nil {
+		return fmt.Errorf("invalid --%s: %w", ConcurrencyGroupsFlag, err)
+	}
+	if _, err := userConfig.ToWarmPools(); err != nil {
+		return fmt.Errorf("invalid --%s: %w", WarmPoolsFlag, err)
+	}
+	if userConfig.LeaderElection && userConfig.LockingDBType != "redis" {
+		return fmt.Errorf("--%s requires --%s=redis so the instances share their locks and state", LeaderElectionFlag, LockingDBType)
+	}
+	if userConfig.LeaderElection && userConfig.ServerRole != server.AllServerRole {
+		// Frontends and workers scale out, the work queue spreads the
+		// commands.
+		return fmt.Errorf("--%s requires --%s=%s", LeaderElectionFlag, ServerRoleFlag, server.AllServerRole)
+	}
+	if userConfig.MaxConcurrentCommands > 0 && userConfig.ServerRole != server.AllServerRole {
+		// Frontends only dispatch the commands, workers run
+		// --worker-concurrency commands at once.
+		return fmt.Errorf("--%s requires --%s=%s", MaxConcurrentCommandsFlag, ServerRoleFlag, server.AllServerRole)
+	}
+
+	if userConfig.DBEncryptionKey != "" && userConfig.DBEncryptionKMSKeyID != "" {
+		return fmt.Errorf("only one of --%s or --%s can be set", DBEncryptionKeyFlag, DBEncryptionKMSKeyIDFlag)
+	}
+	if (userConfig.DBEncryptionKey != "" || userConfig.DBEncryptionKMSKeyID != "") && userConfig.LockingDBType != DefaultLockingDBType {
+		return fmt.Errorf("--%s and --%s are only supported with --%s=%s", DBEncryptionKeyFlag, DBEncryptionKMSKeyIDFlag, LockingDBType, DefaultLockingDBType)
+	}
+
+	if userConfig.KubernetesJobImage != "" && userConfig.KubernetesJobDataVolumeClaim == "" {
+		return fmt.Errorf("--%s must be set with --%s", KubernetesJobDataVolumeClaimFlag, KubernetesJobImageFlag)
+	}
+	if _, err := kubernetes.ParseResources(userConfig.KubernetesJobResources); err != nil {
+		return fmt.Errorf("invalid --%s: %w", KubernetesJobResourcesFlag, err)
+	}
+	if _, err := kubernetes.ParseNodeSelector(userConfig.KubernetesJobNodeSelector); err != nil {
+		return fmt.Errorf("invalid --%s: %w", KubernetesJobNodeSelectorFlag, err)
+	}
+
+	if _, err := logsink.ParseList(userConfig.LogSinks); err != nil {
+		return fmt.Errorf("invalid --%s: %w", LogSinksFlag, err)
+	}
+	if userConfig.LogSinkJobOutput && userConfig.LogSinks == "" {
+		return fmt.Errorf("--%s requires --%s", LogSinkJobOutputFlag, LogSinksFlag)
+	}
+
+	if userConfig.SSLClientCAFile != "" && userConfig.SSLCertFile == "" {
+		return fmt.Errorf("--%s requires --%s and --%s", SSLClientCAFileFlag, SSLCertFileFlag, SSLKeyFileFlag)
+	}
+
+	if userConfig.SSLClientAuth != server.SSLClientAuthWebhooksAndAPI && userConfig.SSLClientAuth != server.SSLClientAuthAll {
+		return fmt.Errorf("invalid --%s: not one of %s or %s", SSLClientAuthFlag, server.SSLClientAuthWebhooksAndAPI, server.SSLClientAuthAll)
+	}
+
 	// The following combinations are valid.
 	// 1. github user and (token or token file)
 	// 2. github app ID and (key file set or key set)
@@ -1022,12 +1659,24 @@ func (s *ServerCmd) validate(userConfig server.UserConfig) error {
 	// 4. gitlab user and token set
 	// 5. bitbucket user and token set
 	// 6. azuredevops user and token set
-	// 7. any combination of the above
-	vcsErr := fmt.Errorf("--%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s must be set", GHUserFlag, GHTokenFlag, GHUserFlag, GHTokenFileFlag, GHAppIDFlag, GHAppKeyFileFlag, GHAppIDFlag, GHAppKeyFlag, GiteaUserFlag, GiteaTokenFlag, GitlabUserFlag, GitlabTokenFlag, BitbucketUserFlag, BitbucketTokenFlag, ADUserFlag, ADTokenFlag)
+	// 7. gerrit user and password set
+	// 8. codecommit git user and password set
+	// 9. any combination of the above
+	vcsErr := fmt.Errorf("--%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s must be set", GHUserFlag, GHTokenFlag, GHUserFlag, GHTokenFileFlag, GHAppIDFlag, GHAppKeyFileFlag, GHAppIDFlag, GHAppKeyFlag, GiteaUserFlag, GiteaTokenFlag, GitlabUserFlag, GitlabTokenFlag, BitbucketUserFlag, BitbucketTokenFlag, ADUserFlag, ADTokenFlag, GerritUserFlag, GerritPasswordFlag, CodeCommitGitUserFlag, CodeCommitGitPasswordFlag)
+	// Bitbucket users authenticate with an app password, an access token or
+	// an OAuth consumer.
+	bitbucketCredentials := 0
+	for _, set := range []bool{userConfig.BitbucketToken != "", userConfig.BitbucketAccessToken != "", userConfig.BitbucketOAuthClientID != "" || userConfig.BitbucketOAuthClientSecret != ""} {
+		if set {
+			bitbucketCredentials++
+		}
+	}
 	if ((userConfig.GiteaUser == "") != (userConfig.GiteaToken == "")) ||
 		((userConfig.GitlabUser == "") != (userConfig.GitlabToken == "")) ||
-		((userConfig.BitbucketUser == "") != (userConfig.BitbucketToken == "")) ||
-		((userConfig.AzureDevopsUser == "") != (userConfig.AzureDevopsToken == "")) {
+		((userConfig.BitbucketUser == "") != (bitbucketCredentials == 0)) ||
+		((userConfig.AzureDevopsUser == "") != (userConfig.AzureDevopsToken == "")) ||
+		((userConfig.GerritUser == "") != (userConfig.GerritPassword == "")) ||
+		((userConfig.CodeCommitGitUser == "") != (userConfig.CodeCommitGitPassword == "")) {
 		return vcsErr
 	}
 	if userConfig.GithubUser != "" {
@@ -1035,6 +1684,15 @@ func (s *ServerCmd) validate(userConfig server.UserConfig) error {
 			return vcsErr
 		}
 	}
+	if bitbucketCredentials > 1 {
+		return fmt.Errorf("only one of --%s, --%s or --%s/--%s can be set", BitbucketTokenFlag, BitbucketAccessTokenFlag, BitbucketOAuthClientIDFlag, BitbucketOAuthClientSecretFlag)
+	}
+	if (userConfig.BitbucketOAuthClientID == "") != (userConfig.BitbucketOAuthClientSecret == "") {
+		return fmt.Errorf("--%s and --%s must be set together", BitbucketOAuthClientIDFlag, BitbucketOAuthClientSecretFlag)
+	}
+	if (userConfig.BitbucketAccessToken != "" || userConfig.BitbucketOAuthClientID != "") && userConfig.BitbucketBaseURL != DefaultBitbucketBaseURL {
+		return fmt.Errorf("--%s and --%s/--%s are only supported by Bitbucket Cloud", BitbucketAccessTokenFlag, BitbucketOAuthClientIDFlag, BitbucketOAuthClientSecretFlag)
+	}
 	if userConfig.GithubAppID != 0 {
 		if (userConfig.GithubAppKey == "") == (userConfig.GithubAppKeyFile == "") {
 			return vcsErr
@@ -1042,7 +1700,7 @@ func (s *ServerCmd) validate(userConfig server.UserConfig) error {
 	}
 	// At this point, we know that there can't be a single user/token without
 	// its partner, but we haven't checked if any user/token is set at all.
-	if userConfig.GithubAppID == 0 && userConfig.GithubUser == "" && userConfig.GiteaUser == "" && userConfig.GitlabUser == "" && userConfig.BitbucketUser == "" && userConfig.AzureDevopsUser == "" {
+	if userConfig.GithubAppID == 0 && userConfig.GithubUser == "" && userConfig.GiteaUser == "" && userConfig.GitlabUser == "" && userConfig.BitbucketUser == "" && userConfig.AzureDevopsUser == "" && userConfig.GerritUser == "" && userConfig.CodeCommitGitUser == "" {
 		return vcsErr
 	}
 
@@ -1069,21 +1727,39 @@ func (s *ServerCmd) validate(userConfig server.UserConfig) error {
 		return fmt.Errorf("--%s must have http:// or https://, got %q", GiteaBaseURLFlag, userConfig.GiteaBaseURL)
 	}
 
+	if userConfig.GerritUser != "" {
+		if userConfig.GerritBaseURL == "" {
+			return fmt.Errorf("--%s must be set with --%s", GerritBaseURLFlag, GerritUserFlag)
+		}
+		parsed, err = url.Parse(userConfig.GerritBaseURL)
+		if err != nil {
+			return fmt.Errorf("error parsing --%s flag value %q: %s", GerritBaseURLFlag, userConfig.GerritBaseURL, err)
+		}
+		if parsed.Scheme != "http" && parsed.Scheme != "https" {
+			return fmt.Errorf("--%s must have http:// or https://, got %q", GerritBaseURLFlag, userConfig.GerritBaseURL)
+		}
+	}
+
 	if userConfig.RepoConfig != "" && userConfig.RepoConfigJSON != "" {
 		return fmt.Errorf("cannot use --%s and --%s at the same time", RepoConfigFlag, RepoConfigJSONFlag)
 	}
 
 	// Warn if any tokens have newlines.
 	for name, token := range map[string]string{
-		GHTokenFlag:                userConfig.GithubToken,
-		GHTokenFileFlag:            userConfig.GithubTokenFile,
-		GHWebhookSecretFlag:        userConfig.GithubWebhookSecret,
-		GitlabTokenFlag:            userConfig.GitlabToken,
-		GitlabWebhookSecretFlag:    userConfig.GitlabWebhookSecret,
-		BitbucketTokenFlag:         userConfig.BitbucketToken,
-		BitbucketWebhookSecretFlag: userConfig.BitbucketWebhookSecret,
-		GiteaTokenFlag:             userConfig.GiteaToken,
-		GiteaWebhookSecretFlag:     userConfig.GiteaWebhookSecret,
+		GHTokenFlag:                 userConfig.GithubToken,
+		GHTokenFileFlag:             userConfig.GithubTokenFile,
+		GHWebhookSecretFlag:         userConfig.GithubWebhookSecret,
+		GitlabTokenFlag:             userConfig.GitlabToken,
+		GitlabWebhookSecretFlag:     userConfig.GitlabWebhookSecret,
+		BitbucketTokenFlag:          userConfig.BitbucketToken,
+		BitbucketAccessTokenFlag:    userConfig.BitbucketAccessToken,
+		BitbucketWebhookSecretFlag:  userConfig.BitbucketWebhookSecret,
+		GiteaTokenFlag:              userConfig.GiteaToken,
+		GiteaWebhookSecretFlag:      userConfig.GiteaWebhookSecret,
+		GerritPasswordFlag:          userConfig.GerritPassword,
+		GerritWebhookSecretFlag:     userConfig.GerritWebhookSecret,
+		CodeCommitGitPasswordFlag:   userConfig.CodeCommitGitPassword,
+		CodeCommitWebhookSecretFlag: userConfig.CodeCommitWebhookSecret,
 	} {
 		if strings.Contains(token, "\n") {
 			s.Logger.Warn("--%s contains a newline which is usually unintentional", name)
@@ -1107,6 +1783,15 @@ func (s *ServerCmd) validate(userConfig server.UserConfig) error {
 		return fmt.Errorf("invalid --%s: %w", WebhookHttpHeaders, err)
 	}
 
+	for flag, list := range map[string]string{
+		APIIPAllowlistFlag:     userConfig.APIIPAllowlist,
+		WebhookIPAllowlistFlag: userConfig.WebhookIPAllowlist,
+	} {
+		if _, err := server.ParseIPAllowlist(list, "", nil); err != nil {
+			return fmt.Errorf("invalid --%s: %w", flag, err)
+		}
+	}
+
 	return nil
 }
 
@@ -1185,6 +1870,7 @@ func (s *ServerCmd) trimAtSymbolFromUsers(userConfig *server.UserConfig) {
 	userConfig.GitlabUser = strings.TrimPrefix(userConfig.GitlabUser, "@")
 	userConfig.BitbucketUser = strings.TrimPrefix(userConfig.BitbucketUser, "@")
 	userConfig.AzureDevopsUser = strings.TrimPrefix(userConfig.AzureDevopsUser, "@")
+	userConfig.GerritUser = strings.TrimPrefix(userConfig.GerritUser, "@")
 }
 
 func (s *ServerCmd) securityWarnings(userConfig *server.UserConfig) {
@@ -1194,6 +1880,12 @@ func (s *ServerCmd) securityWarnings(userConfig *server.UserConfig) {
 	if userConfig.GiteaUser != "" && userConfig.GiteaWebhookSecret == "" && !s.SilenceOutput {
 		s.Logger.Warn("no Gitea webhook secret set. This could allow attackers to spoof requests from Gitea")
 	}
+	if userConfig.GerritUser != "" && userConfig.GerritWebhookSecret == "" && !s.SilenceOutput {
+		s.Logger.Warn("no Gerrit webhook secret set. This could allow attackers to spoof requests from Gerrit")
+	}
+	if userConfig.CodeCommitGitUser != "" && userConfig.CodeCommitWebhookSecret == "" && !s.SilenceOutput {
+		s.Logger.Warn("no CodeCommit webhook secret set. This could allow attackers to spoof requests from CodeCommit")
+	}
 	if userConfig.GitlabUser != "" && userConfig.GitlabWebhookSecret == "" && !s.SilenceOutput {
 		s.Logger.Warn("no GitLab webhook secret set. This could allow attackers to spoof requests from GitLab")
 	}
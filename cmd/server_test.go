@@ -17,6 +17,7 @@ import (
 	"bufio"
 	"cmp"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -39,6 +40,9 @@ import (
 // Used for testing.
 var passedConfig server.UserConfig
 
+// validated is set to true if the server was validated instead of started.
+var validated bool
+
 type ServerCreatorMock struct{}
 
 func (s *ServerCreatorMock) NewServer(userConfig server.UserConfig, _ server.Config) (ServerStarter, error) {
@@ -52,6 +56,11 @@ func (s *ServerStarterMock) Start() error {
 	return nil
 }
 
+func (s *ServerStarterMock) Validate(_ io.Writer) error {
+	validated = true
+	return nil
+}
+
 // Adding a new flag? Add it to this slice for testing in alphabetical
 // order.
 var testFlags = map[string]any{
@@ -63,20 +72,42 @@ var testFlags = map[string]any{
 	AtlantisURLFlag:                  "url",
 	AutoplanModules:                  false,
 	AutoplanModulesFromProjects:      "",
+	AgentPoolsFlag:                   "aws-prod:token:owner/*",
 	AllowCommandsFlag:                "version,plan,apply,unlock,import,approve_policies",
 	AllowForkPRsFlag:                 true,
+	ApplyConfirmDestroysFlag:         true,
+	ApplyConfirmProjectsFlag:         10,
+	ApplyReportSigningKeyFlag:        "report-signing-key",
+	ApplyStateCheckFlag:              "abort",
+	ApplyStateDiffFlag:               "aws_iam_*",
+	ArtifactMaxAgeFlag:               "720h",
+	ArtifactRepoQuotaMBFlag:          512,
+	APIArtifactTokensFlag:            "ci-token:owner/*",
+	APIIPAllowlistFlag:               "10.0.0.0/8",
 	APISecretFlag:                    "",
+	AuditAdvisoriesFlag:              "advisories.yaml",
 	AutoDiscoverModeFlag:             "auto",
 	AutomergeFlag:                    true,
 	AutoplanFileListFlag:             "**/*.tf,**/*.yml",
+	BitbucketAccessTokenFlag:         "",
 	BitbucketApiUserFlag:             "bitbucket-api-user",
 	BitbucketBaseURLFlag:             "https://bitbucket-base-url.com",
+	BitbucketOAuthClientIDFlag:       "",
+	BitbucketOAuthClientSecretFlag:   "",
 	BitbucketTokenFlag:               "bitbucket-token",
 	BitbucketUserFlag:                "bitbucket-user",
 	BitbucketWebhookSecretFlag:       "bitbucket-secret",
 	CheckoutStrategyFlag:             CheckoutStrategyMerge,
+	CodeCommitGitPasswordFlag:        "codecommit-git-password",
+	CodeCommitGitUserFlag:            "codecommit-git-user",
+	CodeCommitRegionFlag:             "us-east-1",
+	CodeCommitWebhookSecretFlag:      "codecommit-secret",
 	CheckoutDepthFlag:                0,
+	CommandAuthzPolicyFlag:           "authz.rego",
+	ConcurrencyGroupsFlag:            "prod:1:apply",
 	DataDirFlag:                      "/path",
+	DBEncryptionKeyFlag:              "",
+	DBEncryptionKMSKeyIDFlag:         "alias/atlantis",
 	DefaultTFDistributionFlag:        "terraform",
 	DefaultTFVersionFlag:             "v0.11.0",
 	DisableApplyAllFlag:              true,
@@ -84,10 +115,16 @@ var testFlags = map[string]any{
 	DisableRepoLockingFlag:           true,
 	DisableGlobalApplyLockFlag:       false,
 	DiscardApprovalOnPlanFlag:        true,
+	DiskCriticalThresholdFlag:        95,
+	DiskEvictionThresholdFlag:        85,
 	EmojiReaction:                    "eyes",
+	EmojiReactionFailure:             "confused",
+	EmojiReactionSuccess:             "rocket",
 	ExecutableName:                   "atlantis",
 	FailOnPreWorkflowHookError:       false,
 	GHAllowMergeableBypassApply:      false,
+	GHCodeScanningFlag:               true,
+	GHDeploymentsFlag:                true,
 	GHHostnameFlag:                   "ghhostname",
 	GHTeamAllowlistFlag:              "",
 	GHTokenFlag:                      "token",
@@ -100,6 +137,11 @@ var testFlags = map[string]any{
 	GHAppInstallationIDFlag:          int64(0),
 	GHOrganizationFlag:               "",
 	GHWebhookSecretFlag:              "secret",
+	GerritBaseURLFlag:                "https://gerrit.example.com",
+	GerritPasswordFlag:               "gerrit-password",
+	GerritUserFlag:                   "gerrit-user",
+	GerritVerifiedLabelFlag:          "Atlantis-Verified",
+	GerritWebhookSecretFlag:          "gerrit-secret",
 	GiteaBaseURLFlag:                 "http://localhost",
 	GiteaTokenFlag:                   "gitea-token",
 	GiteaUserFlag:                    "gitea-user",
@@ -114,10 +156,25 @@ var testFlags = map[string]any{
 	HideUnchangedPlanComments:        false,
 	HidePrevPlanComments:             false,
 	IncludeGitUntrackedFiles:         false,
+	KubernetesJobDataVolumeClaimFlag: "atlantis-data",
+	KubernetesJobImageFlag:           "ghcr.io/runatlantis/atlantis",
+	KubernetesJobNamespaceFlag:       "atlantis",
+	KubernetesJobNodeSelectorFlag:    "pool=terraform",
+	KubernetesJobResourcesFlag:       "requests.cpu=1",
+	KubernetesJobServiceAccountFlag:  "terraform",
+	LeaderElectionFlag:               false,
 	LockingDBType:                    "boltdb",
 	LogLevelFlag:                     "debug",
+	LogSinkJobOutputFlag:             true,
+	LogSinksFlag:                     "loki+https://loki.example.com",
 	MarkdownTemplateOverridesDirFlag: "/path2",
 	MaxCommentsPerCommand:            10,
+	MaxConcurrentCommandsFlag:        8,
+	MaxConcurrentProjectsFlag:        16,
+	MaxConcurrentProjectsPerRepoFlag: 4,
+	MaxPlanAgeFlag:                   "4h",
+	MaxPreviewEnvironmentsFlag:       20,
+	MentionCodeOwnersFlag:            true,
 	StatsNamespace:                   "atlantis",
 	AllowDraftPRs:                    true,
 	PortFlag:                         8181,
@@ -125,16 +182,28 @@ var testFlags = map[string]any{
 	ParallelPlanFlag:                 true,
 	ParallelApplyFlag:                true,
 	PendingApplyStatusFlag:           false,
+	PlanfileEncryptionKeyFlag:        "key",
+	PlanfileSigningKeyFlag:           "signing-key",
+	PullRateLimitFlag:                5,
 	QuietPolicyChecks:                false,
 	RedisHost:                        "",
 	RedisInsecureSkipVerify:          false,
+	RegistryCacheHostsFlag:           "registry.example.com",
+	RegistryCacheMaxSizeMBFlag:       2048,
 	RedisPassword:                    "",
 	RedisPort:                        6379,
 	RedisTLSEnabled:                  false,
 	RedisDB:                          0,
+	RecordResourceChangesFlag:        true,
+	ReplanOnBasePushFlag:             true,
 	RepoAllowlistFlag:                "github.com/runatlantis/atlantis",
+	RepoRateLimitFlag:                20,
 	RepoConfigFlag:                   "",
 	RepoConfigJSONFlag:               "",
+	ServerRoleFlag:                   "all",
+	ServiceNowPasswordFlag:           "servicenow-password",
+	ServiceNowURLFlag:                "https://acme.service-now.com",
+	ServiceNowUserFlag:               "servicenow-user",
 	SilenceNoProjectsFlag:            false,
 	SilenceVCSStatusNoProjectsFlag:   false,
 	SilenceForkPRErrorsFlag:          true,
@@ -143,8 +212,13 @@ var testFlags = map[string]any{
 	SkipCloneNoChanges:               true,
 	SlackTokenFlag:                   "slack-token",
 	SSLCertFileFlag:                  "cert-file",
+	SecretsRefreshIntervalFlag:       "10m",
+	SSLClientAuthFlag:                "all",
+	SSLClientCAFileFlag:              "ca-file",
 	SSLKeyFileFlag:                   "key-file",
+	TenantsConfigFlag:                "tenants.yaml",
 	RestrictFileList:                 false,
+	ReuseUnchangedPlansFlag:          true,
 	TFDistributionFlag:               "terraform",
 	TFDownloadFlag:                   true,
 	TFDownloadURLFlag:                "https://my-hostname.com",
@@ -152,22 +226,42 @@ var testFlags = map[string]any{
 	TFELocalExecutionModeFlag:        true,
 	TFETokenFlag:                     "my-token",
 	UseTFPluginCache:                 true,
+	ValidateFlag:                     false,
 	VarFileAllowlistFlag:             "/path",
 	VCSStatusName:                    "my-status",
+	WarmPoolsFlag:                    "owner/repo:2",
 	IgnoreVCSStatusNames:             "",
+	WebhookHistoryFlag:               50,
 	WebhookHttpHeaders:               `{"Authorization":"Bearer some-token","X-Custom-Header":["value1","value2"]}`,
+	WebhookIPAllowlistFlag:           "192.0.2.1,github",
 	WebBasicAuthFlag:                 false,
 	WebPasswordFlag:                  "atlantis",
 	WebUsernameFlag:                  "atlantis",
+	WebOIDCIssuerURLFlag:             "",
+	WebOIDCClientIDFlag:              "",
+	WebOIDCClientSecretFlag:          "",
+	WebOIDCScopesFlag:                "openid,profile,email",
+	WebOIDCGroupsClaimFlag:           "groups",
+	WebPermissionsConfigFlag:         "",
 	WebsocketCheckOrigin:             false,
+	WorkerConcurrencyFlag:            8,
+	WorkQueueTypeFlag:                "nats",
+	WorkQueueURLFlag:                 "nats://nats:4222",
 	WriteGitCredsFlag:                true,
 	DisableAutoplanFlag:              true,
 	DisableAutoplanLabelFlag:         "no-auto-plan",
 	DisableUnlockLabelFlag:           "do-not-unlock",
 	EnablePolicyChecksFlag:           false,
 	EnableRegExpCmdFlag:              false,
+	EnableRegistryCacheFlag:          true,
+	EnableStateForceUnlockFlag:       false,
 	EnableDiffMarkdownFormat:         false,
+	EnableDriftPullRequestsFlag:      true,
+	EnablePlanGraphFlag:              true,
+	EnablePreviewEnvironmentsFlag:    true,
 	EnableProfilingAPI:               false,
+	EnableProgressCommentsFlag:       false,
+	ExportPlanJSONFlag:               true,
 }
 
 func TestExecute_Defaults(t *testing.T) {
@@ -494,6 +588,225 @@ func TestExecute_ValidateCheckoutStrategy(t *testing.T) {
 	ErrEquals(t, "invalid checkout strategy: not one of branch or merge", err)
 }
 
+func TestExecute_ValidateApplyStateCheck(t *testing.T) {
+	c := setupWithDefaults(map[string]any{
+		ApplyStateCheckFlag: "invalid",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "invalid --apply-state-check: not one of warn or abort", err)
+}
+
+func TestExecute_ValidateApplyStateDiff(t *testing.T) {
+	c := setupWithDefaults(map[string]any{
+		ApplyStateDiffFlag: "aws_iam_*,aws_[",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, `invalid --apply-state-diff: resource type "aws_[": syntax error in pattern`, err)
+}
+
+func TestExecute_ValidateAPIArtifactTokens(t *testing.T) {
+	c := setupWithDefaults(map[string]any{
+		APIArtifactTokensFlag: "ci-token:owner/*,reviewer-token:owner/[",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, `invalid --api-artifact-tokens: repo pattern "owner/[": syntax error in pattern`, err)
+}
+
+func TestExecute_ValidateIPAllowlists(t *testing.T) {
+	c := setupWithDefaults(map[string]any{
+		WebhookIPAllowlistFlag: "10.0.0.0/8,gitlab",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, `invalid --webhook-ip-allowlist: "gitlab" is not a CIDR, an IP or github`, err)
+
+	c = setupWithDefaults(map[string]any{
+		APIIPAllowlistFlag: "10.0.0.0/33",
+	}, t)
+	err = c.Execute()
+	ErrEquals(t, "invalid --api-ip-allowlist: invalid CIDR address: 10.0.0.0/33", err)
+}
+
+func TestExecute_ValidateSecretsRefreshInterval(t *testing.T) {
+	c := setupWithDefaults(map[string]any{
+		SecretsRefreshIntervalFlag: "0s",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "invalid --secrets-refresh-interval: must be positive", err)
+}
+
+func TestExecute_ValidateSSLClientConfig(t *testing.T) {
+	c := setupWithDefaults(map[string]any{
+		SSLClientCAFileFlag: "ca-file",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "--ssl-client-ca-file requires --ssl-cert-file and --ssl-key-file", err)
+
+	c = setupWithDefaults(map[string]any{
+		SSLClientAuthFlag: "some",
+	}, t)
+	err = c.Execute()
+	ErrEquals(t, "invalid --ssl-client-auth: not one of webhooks-and-api or all", err)
+}
+
+func TestExecute_ValidateDBEncryption(t *testing.T) {
+	c := setupWithDefaults(map[string]any{
+		DBEncryptionKeyFlag:      "key",
+		DBEncryptionKMSKeyIDFlag: "alias/atlantis",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "only one of --db-encryption-key or --db-encryption-kms-key-id can be set", err)
+
+	c = setupWithDefaults(map[string]any{
+		DBEncryptionKeyFlag: "key",
+		LockingDBType:       "redis",
+	}, t)
+	err = c.Execute()
+	ErrEquals(t, "--db-encryption-key and --db-encryption-kms-key-id are only supported with --locking-db-type=boltdb", err)
+}
+
+func TestExecute_ValidateLeaderElection(t *testing.T) {
+	c := setupWithDefaults(map[string]any{
+		LeaderElectionFlag: true,
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "--leader-election requires --locking-db-type=redis so the instances share their locks and state", err)
+
+	c = setupWithDefaults(map[string]any{
+		LeaderElectionFlag: true,
+		LockingDBType:      "redis",
+		ServerRoleFlag:     "frontend",
+	}, t)
+	err = c.Execute()
+	ErrEquals(t, "--leader-election requires --server-role=all", err)
+}
+
+func TestExecute_ValidateServerRole(t *testing.T) {
+	c := setupWithDefaults(map[string]any{
+		ServerRoleFlag: "scheduler",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, `invalid --server-role "scheduler", must be one of all, frontend or worker`, err)
+
+	c = setupWithDefaults(map[string]any{
+		ServerRoleFlag: "worker",
+	}, t)
+	err = c.Execute()
+	ErrEquals(t, "--server-role=worker requires --locking-db-type=redis so the work queue and locks are shared", err)
+
+	c = setupWithDefaults(map[string]any{
+		ServerRoleFlag: "frontend",
+		LockingDBType:  "redis",
+	}, t)
+	Ok(t, c.Execute())
+}
+
+func TestExecute_ValidateWorkQueue(t *testing.T) {
+	c := setupWithDefaults(map[string]any{
+		WorkQueueTypeFlag: "kafka",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, `invalid --work-queue-type "kafka", must be one of redis, sqs or nats`, err)
+
+	c = setupWithDefaults(map[string]any{
+		WorkQueueTypeFlag: "sqs",
+	}, t)
+	err = c.Execute()
+	ErrEquals(t, "--work-queue-type=sqs requires --work-queue-url", err)
+}
+
+func TestExecute_ValidateAgentPools(t *testing.T) {
+	c := setupWithDefaults(map[string]any{
+		AgentPoolsFlag: "aws-prod",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "invalid --agent-pools: agent pools must be name:token:repo-pattern", err)
+
+	c = setupWithDefaults(map[string]any{
+		AgentPoolsFlag: "aws-prod:token:*/*,aws-prod:other:*/*",
+	}, t)
+	err = c.Execute()
+	ErrEquals(t, `invalid --agent-pools: agent pool "aws-prod" is configured twice`, err)
+
+	c = setupWithDefaults(map[string]any{
+		AgentPoolsFlag: "aws-prod:token:*/*",
+		ServerRoleFlag: "frontend",
+		LockingDBType:  "redis",
+	}, t)
+	err = c.Execute()
+	ErrEquals(t, "--agent-pools requires --server-role=all", err)
+}
+
+func TestExecute_ValidateDiskThresholds(t *testing.T) {
+	c := setupWithDefaults(map[string]any{
+		DiskCriticalThresholdFlag: 101,
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "invalid --disk-critical-threshold: must be a percentage between 0 and 100", err)
+
+	c = setupWithDefaults(map[string]any{
+		DiskCriticalThresholdFlag: 90,
+		DiskEvictionThresholdFlag: 90,
+	}, t)
+	err = c.Execute()
+	ErrEquals(t, "--disk-eviction-threshold must be lower than --disk-critical-threshold", err)
+}
+
+func TestExecute_ValidateCommandLimits(t *testing.T) {
+	c := setupWithDefaults(map[string]any{
+		RepoRateLimitFlag: -1,
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "invalid --repo-rate-limit: must not be negative", err)
+
+	c = setupWithDefaults(map[string]any{
+		MaxConcurrentCommandsFlag: 4,
+		ServerRoleFlag:            "frontend",
+		LockingDBType:             "redis",
+	}, t)
+	err = c.Execute()
+	ErrEquals(t, "--max-concurrent-commands requires --server-role=all", err)
+
+	c = setupWithDefaults(map[string]any{
+		ConcurrencyGroupsFlag: "prod:0",
+	}, t)
+	err = c.Execute()
+	ErrEquals(t, `invalid --concurrency-groups: limit of concurrency group "prod" must be a positive number`, err)
+}
+
+func TestExecute_ValidateKubernetesJobs(t *testing.T) {
+	c := setupWithDefaults(map[string]any{
+		KubernetesJobImageFlag: "ghcr.io/runatlantis/atlantis",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "--kubernetes-job-data-volume-claim must be set with --kubernetes-job-image", err)
+
+	c = setupWithDefaults(map[string]any{
+		KubernetesJobResourcesFlag: "cpu=1",
+	}, t)
+	err = c.Execute()
+	ErrEquals(t, `invalid --kubernetes-job-resources: invalid resource "cpu=1", must be <requests|limits>.<resource>=<quantity>`, err)
+
+	c = setupWithDefaults(map[string]any{
+		KubernetesJobNodeSelectorFlag: "pool",
+	}, t)
+	err = c.Execute()
+	ErrEquals(t, `invalid --kubernetes-job-node-selector: invalid node selector "pool", must be <label>=<value>`, err)
+}
+
+func TestExecute_ValidateLogSinks(t *testing.T) {
+	c := setupWithDefaults(map[string]any{
+		LogSinksFlag: "loki+https://loki.example.com,syslog://localhost",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, `invalid --log-sinks: unsupported log sink "syslog", must be loki+https, loki+http, cloudwatch or cloud-logging`, err)
+
+	c = setupWithDefaults(map[string]any{
+		LogSinkJobOutputFlag: true,
+	}, t)
+	err = c.Execute()
+	ErrEquals(t, "--log-sink-job-output requires --log-sinks", err)
+}
+
 func TestExecute_ValidateSSLConfig(t *testing.T) {
 	expErr := "--ssl-key-file and --ssl-cert-file are both required for ssl"
 	cases := []struct {
@@ -543,7 +856,7 @@ func TestExecute_ValidateSSLConfig(t *testing.T) {
 }
 
 func TestExecute_ValidateVCSConfig(t *testing.T) {
-	expErr := "--gh-user/--gh-token or --gh-user/--gh-token-file or --gh-app-id/--gh-app-key-file or --gh-app-id/--gh-app-key or --gitea-user/--gitea-token or --gitlab-user/--gitlab-token or --bitbucket-user/--bitbucket-token or --azuredevops-user/--azuredevops-token must be set"
+	expErr := "--gh-user/--gh-token or --gh-user/--gh-token-file or --gh-app-id/--gh-app-key-file or --gh-app-id/--gh-app-key or --gitea-user/--gitea-token or --gitlab-user/--gitlab-token or --bitbucket-user/--bitbucket-token or --azuredevops-user/--azuredevops-token or --gerrit-user/--gerrit-password or --codecommit-git-user/--codecommit-git-password must be set"
 	cases := []struct {
 		description string
 		flags       map[string]any
@@ -589,6 +902,20 @@ func TestExecute_ValidateVCSConfig(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"just gerrit password set",
+			map[string]any{
+				GerritPasswordFlag: "password",
+			},
+			true,
+		},
+		{
+			"just codecommit git password set",
+			map[string]any{
+				CodeCommitGitPasswordFlag: "password",
+			},
+			true,
+		},
 		{
 			"just github user set",
 			map[string]any{
@@ -758,6 +1085,23 @@ func TestExecute_ValidateVCSConfig(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"gerrit user and gerrit password set and should be successful",
+			map[string]any{
+				GerritUserFlag:     "user",
+				GerritPasswordFlag: "password",
+				GerritBaseURLFlag:  "https://gerrit.example.com",
+			},
+			false,
+		},
+		{
+			"codecommit git user and codecommit git password set and should be successful",
+			map[string]any{
+				CodeCommitGitUserFlag:     "user",
+				CodeCommitGitPasswordFlag: "password",
+			},
+			false,
+		},
 		{
 			"all set should be successful",
 			map[string]any{
@@ -931,6 +1275,48 @@ func TestExecute_BitbucketUser(t *testing.T) {
 	Equals(t, "user", passedConfig.BitbucketUser)
 }
 
+func TestExecute_BitbucketCredentials(t *testing.T) {
+	c := setup(map[string]any{
+		BitbucketUserFlag:        "user",
+		BitbucketAccessTokenFlag: "token",
+		RepoAllowlistFlag:        "*",
+	}, t)
+	Ok(t, c.Execute())
+	Equals(t, "token", passedConfig.BitbucketAccessToken)
+
+	c = setup(map[string]any{
+		BitbucketUserFlag:              "user",
+		BitbucketOAuthClientIDFlag:     "key",
+		BitbucketOAuthClientSecretFlag: "secret",
+		RepoAllowlistFlag:              "*",
+	}, t)
+	Ok(t, c.Execute())
+	Equals(t, "key", passedConfig.BitbucketOAuthClientID)
+
+	c = setup(map[string]any{
+		BitbucketUserFlag:        "user",
+		BitbucketTokenFlag:       "token",
+		BitbucketAccessTokenFlag: "token",
+		RepoAllowlistFlag:        "*",
+	}, t)
+	ErrEquals(t, "only one of --bitbucket-token, --bitbucket-access-token or --bitbucket-oauth-client-id/--bitbucket-oauth-client-secret can be set", c.Execute())
+
+	c = setup(map[string]any{
+		BitbucketUserFlag:          "user",
+		BitbucketOAuthClientIDFlag: "key",
+		RepoAllowlistFlag:          "*",
+	}, t)
+	ErrEquals(t, "--bitbucket-oauth-client-id and --bitbucket-oauth-client-secret must be set together", c.Execute())
+
+	c = setup(map[string]any{
+		BitbucketUserFlag:        "user",
+		BitbucketAccessTokenFlag: "token",
+		BitbucketBaseURLFlag:     "https://bitbucket.corp",
+		RepoAllowlistFlag:        "*",
+	}, t)
+	ErrEquals(t, "--bitbucket-access-token and --bitbucket-oauth-client-id/--bitbucket-oauth-client-secret are only supported by Bitbucket Cloud", c.Execute())
+}
+
 func TestExecute_ADUser(t *testing.T) {
 	t.Log("Should remove the @ from the azure devops username if it's passed.")
 	c := setup(map[string]any{
@@ -988,6 +1374,21 @@ func TestExecute_RepoCfgFlags(t *testing.T) {
 	ErrEquals(t, "cannot use --repo-config and --repo-config-json at the same time", err)
 }
 
+func TestExecute_Validate(t *testing.T) {
+	flags := map[string]any{
+		GHUserFlag:        "user",
+		GHTokenFlag:       "token",
+		RepoAllowlistFlag: "github.com",
+	}
+	validated = false
+	Ok(t, setup(flags, t).Execute())
+	Equals(t, false, validated)
+
+	flags[ValidateFlag] = true
+	Ok(t, setup(flags, t).Execute())
+	Equals(t, true, validated)
+}
+
 // Can't use both --tfe-hostname flag without --tfe-token.
 func TestExecute_TFEHostnameOnly(t *testing.T) {
 	c := setup(map[string]any{
@@ -1000,6 +1401,18 @@ func TestExecute_TFEHostnameOnly(t *testing.T) {
 	ErrEquals(t, "if setting --tfe-hostname, must set --tfe-token", err)
 }
 
+// Can't force-unlock state locks from the UI without web basic auth.
+func TestExecute_StateForceUnlockWithoutWebBasicAuth(t *testing.T) {
+	c := setup(map[string]any{
+		GHUserFlag:                 "user",
+		GHTokenFlag:                "token",
+		RepoAllowlistFlag:          "github.com",
+		EnableStateForceUnlockFlag: true,
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "--enable-state-force-unlock requires --web-basic-auth or --web-oidc-issuer-url so force-unlocks are authorized", err)
+}
+
 // Must set allow or whitelist.
 func TestExecute_AllowAndWhitelist(t *testing.T) {
 	c := setup(map[string]any{
@@ -1169,6 +1582,34 @@ func configVal(t *testing.T, u server.UserConfig, tag string) any {
 	return nil
 }
 
+// Gerrit base URL must be set and have a scheme.
+func TestExecute_GerritBaseURL(t *testing.T) {
+	c := setup(map[string]any{
+		GerritUserFlag:     "user",
+		GerritPasswordFlag: "password",
+		RepoAllowlistFlag:  "*",
+	}, t)
+	ErrEquals(t, "--gerrit-base-url must be set with --gerrit-user", c.Execute())
+
+	c = setup(map[string]any{
+		GerritUserFlag:     "user",
+		GerritPasswordFlag: "password",
+		RepoAllowlistFlag:  "*",
+		GerritBaseURLFlag:  "gerrit.example.com",
+	}, t)
+	ErrEquals(t, "--gerrit-base-url must have http:// or https://, got \"gerrit.example.com\"", c.Execute())
+
+	c = setup(map[string]any{
+		GerritUserFlag:     "@user",
+		GerritPasswordFlag: "password",
+		RepoAllowlistFlag:  "*",
+		GerritBaseURLFlag:  "https://gerrit.example.com",
+	}, t)
+	Ok(t, c.Execute())
+	Equals(t, "user", passedConfig.GerritUser)
+	Equals(t, DefaultGerritVerifiedLabel, passedConfig.GerritVerifiedLabel)
+}
+
 // Gitea base URL must have a scheme.
 func TestExecute_GiteaBaseURLScheme(t *testing.T) {
 	c := setup(map[string]any{
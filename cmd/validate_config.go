@@ -0,0 +1,96 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/runatlantis/atlantis/server/core/config"
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/spf13/cobra"
+)
+
+// ValidateConfigCmd validates repo-level atlantis.yaml files, or prints the
+// JSON Schema of atlantis.yaml.
+type ValidateConfigCmd struct{}
+
+// Init returns the runnable cobra command.
+func (v *ValidateConfigCmd) Init() *cobra.Command {
+	var repoConfig, repoID string
+	var schema bool
+	c := &cobra.Command{
+		Use:   "validate-config [file...]",
+		Short: "Validate repo-level atlantis.yaml files",
+		Long: "Validate repo-level atlantis.yaml files, atlantis.yaml if none is given, reporting every problem with its line." +
+			" With --repo-config, the files are also checked against the server-side repo config, ex. for the keys it allows to override.",
+		RunE: func(c *cobra.Command, args []string) error {
+			if schema {
+				return printSchema(c.OutOrStdout())
+			}
+			if len(args) == 0 {
+				args = []string{valid.DefaultAtlantisFile}
+			}
+			globalCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{AllowAllRepoSettings: true})
+			if repoConfig != "" {
+				var err error
+				globalCfg, err = (&config.ParserValidator{}).ParseGlobalCfg(repoConfig, valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{}))
+				if err != nil {
+					return fmt.Errorf("parsing --repo-config: %w", err)
+				}
+			}
+			return validateConfigs(c.OutOrStdout(), args, globalCfg, repoID)
+		},
+		SilenceUsage: true,
+	}
+	flags := c.Flags()
+	flags.StringVar(&repoConfig, "repo-config", "", "Path to the server-side repo config to check the files against, see --repo-config of the server.")
+	flags.StringVar(&repoID, "repo-id", "", "ID of the repo of the files in the server-side repo config, ex. github.com/runatlantis/atlantis.")
+	flags.BoolVar(&schema, "schema", false, "Print the JSON Schema of atlantis.yaml, ex. for editors, instead of validating files.")
+	return c
+}
+
+func printSchema(out io.Writer) error {
+	data, err := json.MarshalIndent(raw.RepoCfgSchema(), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
+
+// validateConfigs prints the problems of files, failing if there are any.
+func validateConfigs(out io.Writer, files []string, globalCfg valid.GlobalCfg, repoID string) error {
+	problems := 0
+	for _, file := range files {
+		data, err := os.ReadFile(file) // nolint: gosec
+		if err != nil {
+			return err
+		}
+		configErrs := (&config.ParserValidator{}).ValidateRepoCfgData(data, globalCfg, repoID)
+		for _, configErr := range configErrs {
+			location := file
+			if configErr.Line > 0 {
+				location = fmt.Sprintf("%s:%d", file, configErr.Line)
+			}
+			configErr.Line = 0
+			fmt.Fprintf(out, "%s: %s\n", location, configErr.Error()) // nolint: errcheck
+		}
+		if len(configErrs) == 0 {
+			fmt.Fprintf(out, "%s is valid\n", file) // nolint: errcheck
+		}
+		problems += len(configErrs)
+	}
+	switch {
+	case problems == 1:
+		return errors.New("found 1 problem")
+	case problems > 1:
+		return fmt.Errorf("found %d problems", problems)
+	}
+	return nil
+}
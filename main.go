@@ -60,8 +60,14 @@ func main() {
 	}
 	version := &cmd.VersionCmd{AtlantisVersion: atlantisVersion}
 	testdrive := &cmd.TestdriveCmd{}
+	agent := &cmd.AgentCmd{Logger: logger}
+	validateConfig := &cmd.ValidateConfigCmd{}
+	onboard := &cmd.OnboardCmd{Logger: logger}
 	cmd.RootCmd.AddCommand(server.Init())
 	cmd.RootCmd.AddCommand(version.Init())
 	cmd.RootCmd.AddCommand(testdrive.Init())
+	cmd.RootCmd.AddCommand(agent.Init())
+	cmd.RootCmd.AddCommand(validateConfig.Init())
+	cmd.RootCmd.AddCommand(onboard.Init())
 	cmd.Execute()
 }
@@ -37,6 +37,9 @@ const en = [
           { text: "Post Workflow Hooks", link: "/docs/post-workflow-hooks" },
           { text: "Conftest Policy Checking", link: "/docs/policy-checking" },
           { text: "Custom Workflows", link: "/docs/custom-workflows" },
+          { text: "Cost Estimation", link: "/docs/cost-estimation" },
+          { text: "Provider and Module Audit", link: "/docs/dependency-audit" },
+          { text: "Remote Agents", link: "/docs/remote-agents" },
           { text: "Repo and Project Permissions", link: "/docs/repo-and-project-permissions" },
           { text: "Repo Level atlantis.yaml", link: "/docs/repo-level-atlantis-yaml" },
           { text: "Upgrading atlantis.yaml", link: "/docs/upgrading-atlantis-yaml" },
@@ -55,6 +58,7 @@ const en = [
         items: [
           { text: "Overview", link: "/docs/using-atlantis" },
           { text: "API endpoints", link: "/docs/api-endpoints" },
+          { text: "atlantisctl", link: "/docs/atlantisctl" },
         ]
       },
       {
@@ -0,0 +1,293 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package agents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/runtime/models"
+	"github.com/runatlantis/atlantis/server/core/terraform"
+	"github.com/runatlantis/atlantis/server/core/terraform/tfclient"
+	"github.com/runatlantis/atlantis/server/events/command"
+	eventsmodels "github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/jobs"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// outputInterval is how often agents send the output of their job.
+const outputInterval = time.Second
+
+// OutputRequest is the output an agent sends for its job.
+type OutputRequest struct {
+	Lines []string
+}
+
+// CompleteRequest completes the job of an agent.
+type CompleteRequest struct {
+	// Error is why the command failed, empty if it succeeded.
+	Error string
+}
+
+// Agent polls Atlantis for the jobs of its pool and runs them.
+type Agent struct {
+	// URL is the URL of Atlantis.
+	URL string
+	// Token is the token of the pool of the agent.
+	Token string
+	// Name identifies the agent in its pool. The jobs of a working dir are run
+	// by the same agent, so it should be stable across restarts.
+	Name string
+	// DataDir is where the agent keeps the working dirs and the terraform
+	// binaries and plugins.
+	DataDir string
+	// TFDownloadURL is where terraform is downloaded from.
+	TFDownloadURL string
+	HTTPClient    *http.Client
+	Logger        logging.SimpleLogging
+	// RunTerraform runs cmd in path. It defaults to running terraform with
+	// the version downloaded to DataDir and the environment of the agent.
+	RunTerraform func(ctx command.ProjectContext, cmd tfclient.TerraformCommand, path string) <-chan models.Line
+}
+
+// Run runs the jobs of the pool until ctx is done.
+func (a *Agent) Run(ctx context.Context) {
+	a.Logger.Info("polling %s for the jobs of the pool as %s", a.URL, a.Name)
+	for ctx.Err() == nil {
+		job, err := a.next(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			a.Logger.Err("unable to poll for jobs: %s", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(5 * time.Second):
+			}
+			continue
+		}
+		if job == nil {
+			continue
+		}
+		a.runJob(*job)
+	}
+}
+
+func (a *Agent) next(ctx context.Context) (*Job, error) {
+	resp, err := a.request(ctx, "POST", "/api/agents/jobs/next", "application/json", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+	var job Job
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		return nil, fmt.Errorf("decoding job: %w", err)
+	}
+	return &job, nil
+}
+
+// runJob runs the job and reports its result. Jobs never fail the agent.
+func (a *Agent) runJob(job Job) {
+	logger := a.Logger.With("job", job.ID, "repo", job.Repo, "pull", job.Pull, "project", job.Project)
+	logger.Info("running '%s %s'", job.Command.Distribution, strings.Join(job.Command.Args, " "))
+	start := time.Now()
+	errMessage := ""
+	if err := a.run(logger, job); err != nil {
+		logger.Err("job failed: %s", err)
+		errMessage = err.Error()
+	} else {
+		logger.With("duration", time.Since(start)).Info("job succeeded")
+	}
+	body, _ := json.Marshal(CompleteRequest{Error: errMessage})
+	if err := a.post(job.ID, "complete", "application/json", bytes.NewReader(body)); err != nil {
+		logger.Err("unable to complete the job: %s", err)
+	}
+}
+
+func (a *Agent) run(logger logging.SimpleLogging, job Job) error {
+	root := filepath.Join(a.DataDir, "work", job.Key)
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return err
+	}
+	if err := a.download(job.ID, root); err != nil {
+		return fmt.Errorf("downloading the working dir: %w", err)
+	}
+	path := filepath.Join(root, filepath.FromSlash(job.Dir))
+	if path != root && !strings.HasPrefix(path, root+string(filepath.Separator)) {
+		return fmt.Errorf("dir %q is outside of the working dir", job.Dir)
+	}
+
+	ctx := command.ProjectContext{
+		Log:         logger,
+		Workspace:   job.Command.Workspace,
+		ProjectName: job.Project,
+		Pull:        eventsmodels.PullRequest{Num: job.Pull, BaseRepo: eventsmodels.Repo{FullName: job.Repo}},
+		BaseRepo:    eventsmodels.Repo{FullName: job.Repo},
+	}
+	runTerraform := a.RunTerraform
+	if runTerraform == nil {
+		runTerraform = a.runTerraform
+	}
+	outCh := runTerraform(ctx, job.Command, path)
+	runErr := a.sendOutput(logger, job.ID, outCh)
+
+	// The project dir is returned even if the command failed, ex. with the
+	// lock file of an init that failed later on.
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(WriteArchive(writer, path))
+	}()
+	if err := a.post(job.ID, "archive", "application/gzip", reader); err != nil {
+		reader.CloseWithError(err)
+		return errors.Join(runErr, fmt.Errorf("uploading the project dir: %w", err))
+	}
+	return runErr
+}
+
+// download mirrors the archive of the working dir of the job into root,
+// keeping the .terraform dirs of the previous jobs.
+func (a *Agent) download(id string, root string) error {
+	resp, err := a.request(context.Background(), "GET", "/api/agents/jobs/"+url.PathEscape(id)+"/archive", "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if err := cleanDir(root); err != nil {
+		return err
+	}
+	return ExtractArchive(resp.Body, root)
+}
+
+// sendOutput sends the lines of outCh to Atlantis every outputInterval, and
+// at least every HeartbeatInterval, until it's closed. It returns the error
+// of the command.
+func (a *Agent) sendOutput(logger logging.SimpleLogging, id string, outCh <-chan models.Line) error {
+	var mutex sync.Mutex
+	var lines []string
+	lastSent := time.Now()
+	flush := func(force bool) {
+		mutex.Lock()
+		batch := lines
+		lines = nil
+		mutex.Unlock()
+		if len(batch) == 0 && !force && time.Since(lastSent) < HeartbeatInterval {
+			return
+		}
+		body, _ := json.Marshal(OutputRequest{Lines: batch})
+		if err := a.post(id, "output", "application/json", bytes.NewReader(body)); err != nil {
+			logger.Warn("unable to send the output: %s", err)
+		}
+		lastSent = time.Now()
+	}
+
+	done := make(chan struct{})
+	flushed := make(chan struct{})
+	go func() {
+		defer close(flushed)
+		ticker := time.NewTicker(outputInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				flush(false)
+			case <-done:
+				flush(true)
+				return
+			}
+		}
+	}()
+
+	var err error
+	for line := range outCh {
+		if line.Err != nil {
+			err = line.Err
+			break
+		}
+		mutex.Lock()
+		lines = append(lines, line.Line)
+		mutex.Unlock()
+	}
+	close(done)
+	<-flushed
+	return err
+}
+
+// runTerraform runs cmd with the terraform version downloaded to the data
+// dir.
+func (a *Agent) runTerraform(ctx command.ProjectContext, cmd tfclient.TerraformCommand, path string) <-chan models.Line {
+	distribution := terraform.NewDistributionTerraform()
+	if cmd.Distribution == "tofu" {
+		distribution = terraform.NewDistributionOpenTofu()
+	}
+	binDir := filepath.Join(a.DataDir, "bin")
+	cacheDir := filepath.Join(a.DataDir, "plugin-cache")
+	for _, dir := range []string{binDir, cacheDir} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return failed(err)
+		}
+	}
+	client, err := tfclient.NewClientWithDefaultVersion(ctx.Log, distribution, binDir, cacheDir, "", "", cmd.Version, "version", a.TFDownloadURL, true, true, false, &jobs.NoopProjectOutputHandler{})
+	if err != nil {
+		return failed(err)
+	}
+	_, outCh := client.RunCommandAsync(ctx, path, cmd.Args, cmd.Env, distribution, client.DefaultVersion(), cmd.Workspace)
+	return outCh
+}
+
+func failed(err error) <-chan models.Line {
+	outCh := make(chan models.Line, 1)
+	outCh <- models.Line{Err: err}
+	close(outCh)
+	return outCh
+}
+
+func (a *Agent) post(id string, endpoint string, contentType string, body io.Reader) error {
+	resp, err := a.request(context.Background(), "POST", "/api/agents/jobs/"+url.PathEscape(id)+"/"+endpoint, contentType, body)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// request sends a request to Atlantis. The body of the response must be
+// closed.
+func (a *Agent) request(ctx context.Context, method string, path string, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(a.URL, "/")+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(TokenHeader, a.Token)
+	req.Header.Set(AgentHeader, a.Name)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	httpClient := a.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close() // nolint: errcheck
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("%s %s returned %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return resp, nil
+}
@@ -0,0 +1,210 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package agents
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// skippedDirs aren't archived. The clone's .git dir isn't needed to run
+// terraform, and the .terraform dirs stay on the agent that initialized them
+// since their providers are specific to it.
+var skippedDirs = map[string]bool{".git": true, ".terraform": true}
+
+// WriteArchive writes a gzipped tarball of the files of dir to w.
+func WriteArchive(w io.Writer, dir string) error {
+	return writeArchive(w, dir, []string{"."})
+}
+
+// writeArchive writes a gzipped tarball of the files of dirs, relative to
+// root, to w. The dirs in another one are only archived once.
+func writeArchive(w io.Writer, root string, dirs []string) error {
+	dirs = slices.Clone(dirs)
+	slices.Sort(dirs)
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	var archived []string
+	for _, dir := range dirs {
+		if slices.ContainsFunc(archived, func(parent string) bool { return parent == "." || strings.HasPrefix(dir, parent+"/") }) {
+			continue
+		}
+		archived = append(archived, dir)
+		if err := archiveDir(tw, root, filepath.Join(root, filepath.FromSlash(dir))); err != nil {
+			return fmt.Errorf("archiving %s: %w", dir, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// archiveDir writes the files of dir to tw, named relative to root.
+func archiveDir(tw *tar.Writer, root string, dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+		if entry.IsDir() && skippedDirs[entry.Name()] {
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		var link string
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		} else if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(path) // nolint: gosec
+		if err != nil {
+			return err
+		}
+		defer f.Close() // nolint: errcheck
+		_, err = io.Copy(tw, f)
+		return err
+	})
+}
+
+// ExtractArchive extracts a gzipped tarball written by WriteArchive into dir,
+// overwriting the files it contains.
+func ExtractArchive(r io.Reader, dir string) error {
+	return extractArchive(r, dir, false)
+}
+
+// extractResults extracts the archive of the project dir an agent uploads
+// after it ran a command into dir. The agent isn't trusted with the rest of
+// the clone: the archive can't contain symlinks, nor .git or .terraform
+// dirs, which would let it run code on Atlantis or swap its providers.
+func extractResults(r io.Reader, dir string) error {
+	return extractArchive(r, dir, true)
+}
+
+func extractArchive(r io.Reader, dir string, results bool) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("reading archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading archive: %w", err)
+		}
+		path := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(path, filepath.Clean(dir)+string(filepath.Separator)) {
+			return fmt.Errorf("archive entry %q is outside of the dir", header.Name)
+		}
+		if results {
+			if header.Typeflag == tar.TypeSymlink || header.Typeflag == tar.TypeLink {
+				return fmt.Errorf("archive entry %q is a link", header.Name)
+			}
+			for part := range strings.SplitSeq(filepath.ToSlash(header.Name), "/") {
+				if skippedDirs[part] {
+					return fmt.Errorf("archive entry %q is in a %s dir", header.Name, part)
+				}
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0700); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			target := filepath.Join(filepath.Dir(path), header.Linkname)
+			if filepath.IsAbs(header.Linkname) || !strings.HasPrefix(target, filepath.Clean(dir)+string(filepath.Separator)) {
+				return fmt.Errorf("archive symlink %q points outside of the dir", header.Name)
+			}
+			if err := os.RemoveAll(path); err != nil {
+				return err
+			}
+			if err := os.Symlink(header.Linkname, path); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := extractFile(tr, path, header.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func extractFile(r io.Reader, path string, perm fs.FileMode) error {
+	// Symlinks are replaced by the file rather than written through.
+	if err := os.RemoveAll(path); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm) // nolint: gosec
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil { // nolint: gosec
+		f.Close() // nolint: errcheck
+		return err
+	}
+	return f.Close()
+}
+
+// cleanDir removes the files of dir other than the ones of the dirs that
+// aren't archived, so extracting an archive into it mirrors the archived dir
+// while keeping the .terraform dirs.
+func cleanDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if !entry.IsDir() {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			continue
+		}
+		if skippedDirs[entry.Name()] {
+			continue
+		}
+		if err := cleanDir(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
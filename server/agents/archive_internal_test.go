@@ -0,0 +1,61 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package agents
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestWriteArchive_Dirs(t *testing.T) {
+	src := t.TempDir()
+	for _, file := range []string{"dir/main.tf", "dir/sub/main.tf", "modules/vpc/main.tf", "other/main.tf"} {
+		Ok(t, os.MkdirAll(filepath.Dir(filepath.Join(src, file)), 0700))
+		Ok(t, os.WriteFile(filepath.Join(src, file), []byte(file), 0600))
+	}
+
+	var archive bytes.Buffer
+	Ok(t, writeArchive(&archive, src, []string{"modules/vpc", "dir", "dir/sub"}))
+	names := readArchive(t, &archive)
+	Equals(t, []string{"dir", "dir/main.tf", "dir/sub", "dir/sub/main.tf", "modules/vpc", "modules/vpc/main.tf"}, names)
+}
+
+func TestExtractResults_Rejected(t *testing.T) {
+	for name, header := range map[string]tar.Header{
+		"is a link":          {Name: "link.tf", Typeflag: tar.TypeSymlink, Linkname: "main.tf"},
+		"is in a .git dir":   {Name: ".git/hooks/post-checkout", Typeflag: tar.TypeReg, Mode: 0700},
+		"in a .terraform":    {Name: "sub/.terraform/providers/provider", Typeflag: tar.TypeReg, Mode: 0700},
+		"outside of the dir": {Name: "../other/main.tf", Typeflag: tar.TypeReg, Mode: 0600},
+	} {
+		var archive bytes.Buffer
+		gz := gzip.NewWriter(&archive)
+		tw := tar.NewWriter(gz)
+		Ok(t, tw.WriteHeader(&header))
+		Ok(t, tw.Close())
+		Ok(t, gz.Close())
+		err := extractResults(&archive, t.TempDir())
+		ErrContains(t, name, err)
+	}
+}
+
+func readArchive(t *testing.T, archive *bytes.Buffer) []string {
+	gz, err := gzip.NewReader(archive)
+	Ok(t, err)
+	tr := tar.NewReader(gz)
+	var names []string
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, header.Name)
+	}
+	return names
+}
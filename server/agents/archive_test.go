@@ -0,0 +1,63 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package agents_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/agents"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestArchive(t *testing.T) {
+	src := t.TempDir()
+	Ok(t, os.MkdirAll(filepath.Join(src, ".git"), 0700))
+	Ok(t, os.MkdirAll(filepath.Join(src, "dir", ".terraform"), 0700))
+	Ok(t, os.WriteFile(filepath.Join(src, ".git", "HEAD"), []byte("ref"), 0600))
+	Ok(t, os.WriteFile(filepath.Join(src, "dir", ".terraform", "providers"), []byte("providers"), 0600))
+	Ok(t, os.WriteFile(filepath.Join(src, "dir", "main.tf"), []byte("main"), 0600))
+	Ok(t, os.WriteFile(filepath.Join(src, "dir", "plan.sh"), []byte("plan"), 0700))
+	Ok(t, os.Symlink("main.tf", filepath.Join(src, "dir", "link.tf")))
+
+	var archive bytes.Buffer
+	Ok(t, agents.WriteArchive(&archive, src))
+	dst := t.TempDir()
+	Ok(t, agents.ExtractArchive(&archive, dst))
+
+	main, err := os.ReadFile(filepath.Join(dst, "dir", "main.tf"))
+	Ok(t, err)
+	Equals(t, "main", string(main))
+	info, err := os.Stat(filepath.Join(dst, "dir", "plan.sh"))
+	Ok(t, err)
+	Equals(t, os.FileMode(0700), info.Mode().Perm())
+	link, err := os.Readlink(filepath.Join(dst, "dir", "link.tf"))
+	Ok(t, err)
+	Equals(t, "main.tf", link)
+	for _, skipped := range []string{".git", filepath.Join("dir", ".terraform")} {
+		_, err := os.Stat(filepath.Join(dst, skipped))
+		Assert(t, os.IsNotExist(err), "%s should have been skipped", skipped)
+	}
+}
+
+func TestExtractArchive_OutsideOfDir(t *testing.T) {
+	for _, header := range []tar.Header{
+		{Name: "../escaped", Typeflag: tar.TypeReg, Mode: 0600},
+		{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "../../etc/passwd"},
+		{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"},
+	} {
+		var archive bytes.Buffer
+		gz := gzip.NewWriter(&archive)
+		tw := tar.NewWriter(gz)
+		Ok(t, tw.WriteHeader(&header))
+		Ok(t, tw.Close())
+		Ok(t, gz.Close())
+		err := agents.ExtractArchive(&archive, t.TempDir())
+		ErrContains(t, "outside of the dir", err)
+	}
+}
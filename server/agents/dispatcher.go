@@ -0,0 +1,461 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package agents runs the terraform commands of projects on remote agents,
+// ex. one per cloud account or network zone. Atlantis keeps handling the VCS,
+// locking and orchestration, agents poll it for the commands of their pool.
+package agents
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+	"github.com/runatlantis/atlantis/server/core/runtime/models"
+	"github.com/runatlantis/atlantis/server/core/terraform/tfclient"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/jobs"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+const (
+	// TokenHeader is the header agents authenticate with the token of their
+	// pool.
+	TokenHeader = "X-Atlantis-Token" // nolint: gosec
+	// AgentHeader is the header agents name themselves with.
+	AgentHeader = "X-Atlantis-Agent"
+	// PollTimeout is how long polling for a job waits before returning
+	// without one.
+	PollTimeout = 30 * time.Second
+	// HeartbeatInterval is how often agents report to Atlantis while they
+	// run a job.
+	HeartbeatInterval = 20 * time.Second
+	// agentTimeout is how long an agent can go without reporting before its
+	// job fails and it's considered disconnected.
+	agentTimeout = 2 * time.Minute
+	// startTimeout is how long a job waits for an agent of its pool.
+	startTimeout = 10 * time.Minute
+)
+
+var (
+	// ErrUnauthorized is returned for requests that don't have the token of a
+	// pool.
+	ErrUnauthorized = errors.New("invalid agent token")
+	// ErrNotFound is returned for jobs that aren't run by the agent.
+	ErrNotFound = errors.New("job not found")
+)
+
+// Pool is a pool of agents sharing a token.
+type Pool struct {
+	Name  string
+	Token string
+	// RepoPattern matches the full names of the repos whose projects may use
+	// the pool, supporting * wildcards ex. owner/*. Since the repos pick
+	// their pool, no repo may use a pool without one.
+	RepoPattern string
+}
+
+// Allows returns whether the projects of repoFullName may use the pool.
+func (p Pool) Allows(repoFullName string) bool {
+	if p.RepoPattern == "" {
+		return false
+	}
+	matched, err := path.Match(p.RepoPattern, repoFullName)
+	return err == nil && matched
+}
+
+// Job is a terraform command run by an agent.
+type Job struct {
+	ID string
+	// Key identifies the working dir of the job. The jobs of a working dir are
+	// run by the same agent while it's connected so it keeps the .terraform
+	// dirs it initialized.
+	Key     string
+	Repo    string
+	Pull    int
+	Project string
+	// Dir is the dir the command is run in, relative to the root of the
+	// archive of the working dir. Command.Path is empty.
+	Dir     string
+	Command tfclient.TerraformCommand
+}
+
+type job struct {
+	Job
+	pool string
+	// root is the working dir the archive is made of.
+	root string
+	// dirs are the dirs of root in the archive, relative to it: the project
+	// dir and the local modules it calls.
+	dirs     []string
+	ctx      command.ProjectContext
+	queuedAt time.Time
+
+	// mutex guards the fields below and sending to outCh, which is closed
+	// once the job is finished.
+	mutex       sync.Mutex
+	outCh       chan models.Line
+	agent       string
+	lastContact time.Time
+	finished    bool
+}
+
+// Dispatcher queues the terraform commands of the projects of agent pools
+// until an agent of their pool polls for them, then relays their output and
+// working dir.
+type Dispatcher struct {
+	pools         []Pool
+	outputHandler jobs.ProjectCommandOutputHandler
+	logger        logging.SimpleLogging
+
+	mutex sync.Mutex
+	// pending are the jobs waiting for an agent, by pool.
+	pending map[string][]*job
+	// running are the jobs run by agents, by ID.
+	running map[string]*job
+	// affinity is the agent that last ran a job, by key.
+	affinity map[string]string
+	// seen is when agents last polled or reported, by pool/agent.
+	seen map[string]time.Time
+	// queued is closed when a job is queued, to wake up the polling agents.
+	queued chan struct{}
+}
+
+// NewDispatcher returns a dispatcher to the agents of pools, streaming the
+// output of their jobs to outputHandler.
+func NewDispatcher(pools []Pool, outputHandler jobs.ProjectCommandOutputHandler, logger logging.SimpleLogging) *Dispatcher {
+	return &Dispatcher{
+		pools:         pools,
+		outputHandler: outputHandler,
+		logger:        logger,
+		pending:       map[string][]*job{},
+		running:       map[string]*job{},
+		affinity:      map[string]string{},
+		seen:          map[string]time.Time{},
+		queued:        make(chan struct{}),
+	}
+}
+
+// RunCommandAsync queues cmd for an agent of the project's pool. See
+// tfclient.AgentRunner.
+func (d *Dispatcher) RunCommandAsync(ctx command.ProjectContext, cmd tfclient.TerraformCommand) (chan<- string, <-chan models.Line) {
+	inCh := make(chan string)
+	go func() {
+		for line := range inCh {
+			ctx.Log.Debug("discarding %q written to the stdin of an agent", line)
+		}
+	}()
+	outCh := make(chan models.Line)
+	j, err := d.newJob(ctx, cmd, outCh)
+	if err != nil {
+		go func() {
+			outCh <- models.Line{Err: err}
+			close(outCh)
+			close(inCh)
+		}()
+		return inCh, outCh
+	}
+	go func() {
+		<-d.watch(j)
+		close(inCh)
+	}()
+
+	d.mutex.Lock()
+	d.pending[j.pool] = append(d.pending[j.pool], j)
+	close(d.queued)
+	d.queued = make(chan struct{})
+	d.mutex.Unlock()
+	ctx.Log.Info("queued '%s %s' for agent pool %s", cmd.Distribution, cmd.Args[0], j.pool)
+	return inCh, outCh
+}
+
+func (d *Dispatcher) newJob(ctx command.ProjectContext, cmd tfclient.TerraformCommand, outCh chan models.Line) (*job, error) {
+	var pool *Pool
+	for i := range d.pools {
+		if d.pools[i].Name == ctx.AgentPool {
+			pool = &d.pools[i]
+		}
+	}
+	if pool == nil {
+		return nil, fmt.Errorf("agent pool %q isn't configured", ctx.AgentPool)
+	}
+	if !pool.Allows(ctx.BaseRepo.FullName) {
+		return nil, fmt.Errorf("repo %s isn't allowed to use agent pool %q", ctx.BaseRepo.FullName, pool.Name)
+	}
+	root := cloneRoot(cmd.Path)
+	dir, err := filepath.Rel(root, cmd.Path)
+	if err != nil {
+		return nil, err
+	}
+	dirs, err := moduleDirs(root, dir)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 8)
+	rand.Read(id) // nolint: errcheck
+	key := sha256.Sum256([]byte(root))
+	cmd.Path = ""
+	return &job{
+		Job: Job{
+			ID:      hex.EncodeToString(id),
+			Key:     hex.EncodeToString(key[:8]),
+			Repo:    ctx.BaseRepo.FullName,
+			Pull:    ctx.Pull.Num,
+			Project: ctx.ProjectName,
+			Dir:     filepath.ToSlash(dir),
+			Command: cmd,
+		},
+		pool:     pool.Name,
+		root:     root,
+		dirs:     dirs,
+		ctx:      ctx,
+		queuedAt: time.Now(),
+		outCh:    outCh,
+	}, nil
+}
+
+// cloneRoot returns the root of the clone dir is in, the dir itself if it
+// isn't in one.
+func cloneRoot(dir string) string {
+	for current := dir; ; current = filepath.Dir(current) {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return current
+		}
+		if filepath.Dir(current) == current {
+			return dir
+		}
+	}
+}
+
+// moduleDirs returns dir and the dirs of the local modules it calls,
+// recursively, relative to root. Agents only get these dirs of the clone.
+func moduleDirs(root string, dir string) ([]string, error) {
+	dirs := []string{filepath.ToSlash(dir)}
+	for i := 0; i < len(dirs); i++ {
+		module, _ := tfconfig.LoadModule(filepath.Join(root, filepath.FromSlash(dirs[i])))
+		if module == nil {
+			continue
+		}
+		for _, call := range module.ModuleCalls {
+			if !strings.HasPrefix(call.Source, "./") && !strings.HasPrefix(call.Source, "../") {
+				continue
+			}
+			moduleDir := path.Join(dirs[i], call.Source)
+			if moduleDir == ".." || strings.HasPrefix(moduleDir, "../") {
+				return nil, fmt.Errorf("module %q of %s is outside of the clone", call.Name, dirs[i])
+			}
+			if !slices.Contains(dirs, moduleDir) {
+				dirs = append(dirs, moduleDir)
+			}
+		}
+	}
+	return dirs, nil
+}
+
+// watch fails the job if no agent takes it or its agent stops reporting. The
+// returned channel is closed once the job is finished.
+func (d *Dispatcher) watch(j *job) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			j.mutex.Lock()
+			finished, agent, lastContact := j.finished, j.agent, j.lastContact
+			j.mutex.Unlock()
+			switch {
+			case finished:
+				return
+			case agent == "" && time.Since(j.queuedAt) > startTimeout:
+				d.removePending(j)
+				d.finish(j, fmt.Errorf("no agent of pool %q took the command within %s", j.pool, startTimeout))
+			case agent != "" && time.Since(lastContact) > agentTimeout:
+				d.finish(j, fmt.Errorf("agent %s of pool %q stopped responding", agent, j.pool))
+			}
+		}
+	}()
+	return done
+}
+
+func (d *Dispatcher) removePending(j *job) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	pending := d.pending[j.pool]
+	for i, queued := range pending {
+		if queued == j {
+			d.pending[j.pool] = append(pending[:i:i], pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// finish sends err, if any, and closes the output of the job.
+func (d *Dispatcher) finish(j *job, err error) {
+	j.mutex.Lock()
+	if j.finished {
+		j.mutex.Unlock()
+		return
+	}
+	j.finished = true
+	if err != nil {
+		err = fmt.Errorf("running '%s %s' on agent pool %q: %w", j.Command.Distribution, j.Command.Args[0], j.pool, err)
+		j.ctx.Log.Err(err.Error())
+		j.outCh <- models.Line{Err: err}
+	} else {
+		j.ctx.Log.Info("agent %s of pool %q ran '%s %s' in %s", j.agent, j.pool, j.Command.Distribution, j.Command.Args[0], time.Since(j.queuedAt).Round(time.Millisecond))
+	}
+	close(j.outCh)
+	j.mutex.Unlock()
+
+	d.mutex.Lock()
+	delete(d.running, j.ID)
+	d.mutex.Unlock()
+}
+
+// Next waits for a job of the pool of token for agent, until PollTimeout or
+// ctx is done in which case it returns nil.
+func (d *Dispatcher) Next(ctx context.Context, token string, agent string) (*Job, error) {
+	pool, err := d.pool(token)
+	if err != nil {
+		return nil, err
+	}
+	timeout := time.After(PollTimeout)
+	for {
+		d.mutex.Lock()
+		if _, ok := d.seen[pool+"/"+agent]; !ok {
+			d.logger.Info("agent %s of pool %q connected", agent, pool)
+		}
+		d.seen[pool+"/"+agent] = time.Now()
+		if j := d.take(pool, agent); j != nil {
+			d.mutex.Unlock()
+			j.ctx.Log.Info("agent %s of pool %q took '%s %s'", agent, pool, j.Command.Distribution, j.Command.Args[0])
+			return &j.Job, nil
+		}
+		queued := d.queued
+		d.mutex.Unlock()
+
+		select {
+		case <-queued:
+		case <-timeout:
+			return nil, nil
+		case <-ctx.Done():
+			return nil, nil
+		}
+	}
+}
+
+// take assigns the first pending job of pool agent may run to it. A job whose
+// working dir was last used by another agent waits for that agent, unless it
+// disconnected.
+func (d *Dispatcher) take(pool string, agent string) *job {
+	for i, j := range d.pending[pool] {
+		previous := d.affinity[j.Key]
+		if previous != "" && previous != agent && time.Since(d.seen[pool+"/"+previous]) < agentTimeout {
+			continue
+		}
+		d.pending[pool] = append(d.pending[pool][:i:i], d.pending[pool][i+1:]...)
+		d.affinity[j.Key] = agent
+		d.running[j.ID] = j
+		j.mutex.Lock()
+		j.agent = agent
+		j.lastContact = time.Now()
+		j.mutex.Unlock()
+		return j
+	}
+	return nil
+}
+
+// Archive writes the archive of the dirs of the working dir the job needs to
+// w.
+func (d *Dispatcher) Archive(token string, agent string, id string, w io.Writer) error {
+	j, err := d.job(token, agent, id)
+	if err != nil {
+		return err
+	}
+	return writeArchive(w, j.root, j.dirs)
+}
+
+// Output sends the lines of the output of the job to the project's output,
+// they're empty if the agent only reports it's still running the job.
+func (d *Dispatcher) Output(token string, agent string, id string, lines []string) error {
+	j, err := d.job(token, agent, id)
+	if err != nil {
+		return err
+	}
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if j.finished {
+		return ErrNotFound
+	}
+	for _, line := range lines {
+		j.outCh <- models.Line{Line: line}
+		d.outputHandler.Send(j.ctx, line, false)
+	}
+	return nil
+}
+
+// Upload extracts the archive of the project dir after the job ran into the
+// project dir, ex. with the planfile.
+func (d *Dispatcher) Upload(token string, agent string, id string, r io.Reader) error {
+	j, err := d.job(token, agent, id)
+	if err != nil {
+		return err
+	}
+	return extractResults(r, filepath.Join(j.root, filepath.FromSlash(j.Dir)))
+}
+
+// Complete finishes the job, failed with errMessage if it's not empty.
+func (d *Dispatcher) Complete(token string, agent string, id string, errMessage string) error {
+	j, err := d.job(token, agent, id)
+	if err != nil {
+		return err
+	}
+	if errMessage != "" {
+		err = errors.New(errMessage)
+	}
+	d.finish(j, err)
+	return nil
+}
+
+// job returns the running job with id, if it's run by agent, and records the
+// agent reported.
+func (d *Dispatcher) job(token string, agent string, id string) (*job, error) {
+	pool, err := d.pool(token)
+	if err != nil {
+		return nil, err
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.seen[pool+"/"+agent] = time.Now()
+	j, ok := d.running[id]
+	if !ok || j.pool != pool || j.agent != agent {
+		return nil, ErrNotFound
+	}
+	j.mutex.Lock()
+	j.lastContact = time.Now()
+	j.mutex.Unlock()
+	return j, nil
+}
+
+func (d *Dispatcher) pool(token string) (string, error) {
+	for _, p := range d.pools {
+		if subtle.ConstantTimeCompare([]byte(p.Token), []byte(token)) == 1 {
+			return p.Name, nil
+		}
+	}
+	return "", ErrUnauthorized
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	cfg "github.com/runatlantis/atlantis/server/core/config"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// configReloadPollInterval is how often the config files are checked for
+// modifications.
+const configReloadPollInterval = 10 * time.Second
+
+// ConfigReloader reloads the server-side repo config and the tenants config
+// without restarting Atlantis. Configs are swapped between commands so
+// in-progress commands complete with the config they started with.
+type ConfigReloader struct {
+	Logger          logging.SimpleLogging
+	Drainer         *events.Drainer
+	ParserValidator *cfg.ParserValidator
+	GlobalCfgArgs   valid.GlobalCfgArgs
+	// RepoConfigFile is the path of the server-side repo config, if set.
+	RepoConfigFile string
+	// GlobalCfgs are the configs of the components using the server-side
+	// repo config, they're all replaced on reload.
+	GlobalCfgs []*valid.GlobalCfg
+	// TenantsFile is the path of the tenants config, if set.
+	TenantsFile string
+	Tenants     *events.Tenants
+
+	mutex    sync.Mutex
+	modTimes map[string]time.Time
+}
+
+// Reload parses the configs and, if they're all valid, replaces the current
+// ones. It blocks until in-progress commands complete.
+func (r *ConfigReloader) Reload() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Record the modification times before parsing so changes made while
+	// parsing are picked up by the next Run.
+	modTimes := r.fileModTimes()
+
+	var globalCfg *valid.GlobalCfg
+	if r.RepoConfigFile != "" {
+		parsed, err := r.ParserValidator.ParseGlobalCfg(r.RepoConfigFile, valid.NewGlobalCfgFromArgs(r.GlobalCfgArgs))
+		if err != nil {
+			return fmt.Errorf("parsing %s file: %w", r.RepoConfigFile, err)
+		}
+		globalCfg = &parsed
+	}
+	var tenants *events.Tenants
+	if r.TenantsFile != "" {
+		var err error
+		tenants, err = events.LoadTenants(r.TenantsFile)
+		if err != nil {
+			return fmt.Errorf("loading %s: %w", r.TenantsFile, err)
+		}
+	}
+
+	r.Logger.Info("reloading config, waiting for in-progress operations to complete")
+	r.Drainer.RunExclusive(func() {
+		if globalCfg != nil {
+			for _, c := range r.GlobalCfgs {
+				*c = *globalCfg
+			}
+		}
+		if tenants != nil {
+			r.Tenants.Replace(tenants)
+		}
+	})
+	r.modTimes = modTimes
+	r.Logger.Info("reloaded config")
+	return nil
+}
+
+// Run reloads the configs if their files were modified since the last reload.
+// It's run periodically by the scheduled executor service.
+func (r *ConfigReloader) Run() {
+	r.mutex.Lock()
+	modified := false
+	for path, modTime := range r.fileModTimes() {
+		if last, ok := r.modTimes[path]; !ok || !last.Equal(modTime) {
+			modified = true
+		}
+	}
+	r.mutex.Unlock()
+	if !modified {
+		return
+	}
+	if err := r.Reload(); err != nil {
+		r.Logger.Err("unable to reload config, keeping the current config: %s", err)
+		// Don't retry until the files are modified again.
+		r.mutex.Lock()
+		r.modTimes = r.fileModTimes()
+		r.mutex.Unlock()
+	}
+}
+
+// Init records the modification times of the configs loaded at startup.
+func (r *ConfigReloader) Init() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.modTimes = r.fileModTimes()
+}
+
+func (r *ConfigReloader) fileModTimes() map[string]time.Time {
+	modTimes := make(map[string]time.Time)
+	for _, path := range []string{r.RepoConfigFile, r.TenantsFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		modTimes[path] = info.ModTime()
+	}
+	return modTimes
+}
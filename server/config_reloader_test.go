@@ -0,0 +1,66 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server"
+	"github.com/runatlantis/atlantis/server/core/config"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestConfigReloader(t *testing.T) {
+	dir := t.TempDir()
+	repoConfig := filepath.Join(dir, "repos.yaml")
+	Ok(t, os.WriteFile(repoConfig, []byte("repos:\n- id: /.*/\n  allowed_overrides: [workflow]\n"), 0600))
+	tenantsConfig := filepath.Join(dir, "tenants.yaml")
+	Ok(t, os.WriteFile(tenantsConfig, []byte("tenants:\n- name: acme\n  repo_allowlist: github.com/acme/*\n"), 0600))
+
+	tenants, err := events.LoadTenants(tenantsConfig)
+	Ok(t, err)
+	var cfgA, cfgB valid.GlobalCfg
+	reloader := &server.ConfigReloader{
+		Logger:          logging.NewNoopLogger(t),
+		Drainer:         &events.Drainer{},
+		ParserValidator: &config.ParserValidator{},
+		RepoConfigFile:  repoConfig,
+		GlobalCfgs:      []*valid.GlobalCfg{&cfgA, &cfgB},
+		TenantsFile:     tenantsConfig,
+		Tenants:         tenants,
+	}
+	reloader.Init()
+
+	t.Log("nothing is reloaded if the files weren't modified")
+	reloader.Run()
+	Equals(t, 0, len(cfgA.Repos))
+
+	t.Log("modified files are reloaded")
+	Ok(t, os.WriteFile(tenantsConfig, []byte("tenants:\n- name: globex\n  repo_allowlist: github.com/acme/*\n"), 0600))
+	modTime := time.Now().Add(time.Minute)
+	Ok(t, os.Chtimes(tenantsConfig, modTime, modTime))
+	reloader.Run()
+	Equals(t, 2, len(cfgA.Repos))
+	Equals(t, []string{"workflow"}, cfgA.Repos[1].AllowedOverrides)
+	Equals(t, cfgA, cfgB)
+	tenant, ok := tenants.TenantFor("acme/infra", "github.com")
+	Equals(t, true, ok)
+	Equals(t, "globex", tenant)
+
+	t.Log("nothing is reloaded if any config is invalid")
+	Ok(t, os.WriteFile(repoConfig, []byte("repos:\n- id: /.*/\n  allowed_overrides: [apply_requirements]\n"), 0600))
+	Ok(t, os.WriteFile(tenantsConfig, []byte("tenants: []\n"), 0600))
+	err = reloader.Reload()
+	ErrEquals(t, "loading "+tenantsConfig+": "+tenantsConfig+" doesn't define any tenants", err)
+	Equals(t, []string{"workflow"}, cfgA.Repos[1].AllowedOverrides)
+	tenant, ok = tenants.TenantFor("acme/infra", "github.com")
+	Equals(t, true, ok)
+	Equals(t, "globex", tenant)
+}
@@ -0,0 +1,141 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/runatlantis/atlantis/server/agents"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// AgentsController serves the remote agents of the agent pools. Agents
+// authenticate with the token of their pool, see agents.TokenHeader.
+type AgentsController struct {
+	// Dispatcher dispatches the jobs to the agents. It's nil if no agent
+	// pools are configured.
+	Dispatcher *agents.Dispatcher
+	Logger     logging.SimpleLogging
+}
+
+// Next responds with the next job of the agent's pool, or with 204 if there
+// isn't one within agents.PollTimeout.
+func (c *AgentsController) Next(w http.ResponseWriter, r *http.Request) {
+	if !c.enabled(w, r) {
+		return
+	}
+	job, err := c.Dispatcher.Next(r.Context(), r.Header.Get(agents.TokenHeader), r.Header.Get(agents.AgentHeader))
+	if err != nil {
+		c.respondError(w, err)
+		return
+	}
+	if job == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	response, err := json.Marshal(job)
+	if err != nil {
+		c.respondError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(response) // nolint: errcheck
+}
+
+// DownloadArchive responds with the archive of the working dir of the job.
+func (c *AgentsController) DownloadArchive(w http.ResponseWriter, r *http.Request) {
+	if !c.enabled(w, r) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/gzip")
+	// The archive is streamed, errors after the first write can only be
+	// logged and abort the response.
+	if err := c.Dispatcher.Archive(r.Header.Get(agents.TokenHeader), r.Header.Get(agents.AgentHeader), mux.Vars(r)["id"], w); err != nil {
+		c.respondError(w, err)
+	}
+}
+
+// UploadArchive extracts the archive of the working dir the job ran in into
+// the working dir.
+func (c *AgentsController) UploadArchive(w http.ResponseWriter, r *http.Request) {
+	if !c.enabled(w, r) {
+		return
+	}
+	if err := c.Dispatcher.Upload(r.Header.Get(agents.TokenHeader), r.Header.Get(agents.AgentHeader), mux.Vars(r)["id"], r.Body); err != nil {
+		c.respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Output sends the lines of the agents.OutputRequest body to the output of
+// the job.
+func (c *AgentsController) Output(w http.ResponseWriter, r *http.Request) {
+	if !c.enabled(w, r) {
+		return
+	}
+	var request agents.OutputRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		c.respondError(w, fmt.Errorf("%w: %w", errBadAgentRequest, err))
+		return
+	}
+	if err := c.Dispatcher.Output(r.Header.Get(agents.TokenHeader), r.Header.Get(agents.AgentHeader), mux.Vars(r)["id"], request.Lines); err != nil {
+		c.respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Complete completes the job with the result of the agents.CompleteRequest
+// body.
+func (c *AgentsController) Complete(w http.ResponseWriter, r *http.Request) {
+	if !c.enabled(w, r) {
+		return
+	}
+	var request agents.CompleteRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		c.respondError(w, fmt.Errorf("%w: %w", errBadAgentRequest, err))
+		return
+	}
+	if err := c.Dispatcher.Complete(r.Header.Get(agents.TokenHeader), r.Header.Get(agents.AgentHeader), mux.Vars(r)["id"], request.Error); err != nil {
+		c.respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+var errBadAgentRequest = errors.New("invalid request")
+
+// enabled returns whether agent requests are served, responding with an error
+// if they aren't or r isn't one.
+func (c *AgentsController) enabled(w http.ResponseWriter, r *http.Request) bool {
+	if c.Dispatcher == nil {
+		http.Error(w, "no agent pools are configured, see --agent-pools", http.StatusNotFound)
+		return false
+	}
+	if r.Header.Get(agents.AgentHeader) == "" {
+		http.Error(w, fmt.Sprintf("the %s header naming the agent is required", agents.AgentHeader), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func (c *AgentsController) respondError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, agents.ErrUnauthorized):
+		code = http.StatusUnauthorized
+	case errors.Is(err, agents.ErrNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, errBadAgentRequest):
+		code = http.StatusBadRequest
+	default:
+		c.Logger.Err("agent request failed: %s", err)
+	}
+	http.Error(w, err.Error(), code)
+}
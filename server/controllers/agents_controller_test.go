@@ -0,0 +1,210 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/runatlantis/atlantis/server/agents"
+	"github.com/runatlantis/atlantis/server/controllers"
+	"github.com/runatlantis/atlantis/server/core/runtime/models"
+	"github.com/runatlantis/atlantis/server/core/terraform/tfclient"
+	"github.com/runatlantis/atlantis/server/events/command"
+	eventsmodels "github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/jobs"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func newAgentsServer(t *testing.T, dispatcher *agents.Dispatcher) *httptest.Server {
+	c := &controllers.AgentsController{Dispatcher: dispatcher, Logger: logging.NewNoopLogger(t)}
+	router := mux.NewRouter()
+	router.HandleFunc("/api/agents/jobs/next", c.Next).Methods("POST")
+	router.HandleFunc("/api/agents/jobs/{id}/archive", c.DownloadArchive).Methods("GET")
+	router.HandleFunc("/api/agents/jobs/{id}/archive", c.UploadArchive).Methods("POST")
+	router.HandleFunc("/api/agents/jobs/{id}/output", c.Output).Methods("POST")
+	router.HandleFunc("/api/agents/jobs/{id}/complete", c.Complete).Methods("POST")
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// runOnAgent runs cmd in the dir of a clone on an agent running runTerraform.
+func runOnAgent(t *testing.T, clone string, cmd tfclient.TerraformCommand, runTerraform func(command.ProjectContext, tfclient.TerraformCommand, string) <-chan models.Line) (string, error) {
+	logger := logging.NewNoopLogger(t)
+	dispatcher := agents.NewDispatcher([]agents.Pool{{Name: "aws-prod", Token: "token", RepoPattern: "owner/*"}}, &jobs.NoopProjectOutputHandler{}, logger)
+	server := newAgentsServer(t, dispatcher)
+	agent := &agents.Agent{
+		URL:          server.URL,
+		Token:        "token",
+		Name:         "agent-1",
+		DataDir:      t.TempDir(),
+		Logger:       logger,
+		RunTerraform: runTerraform,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		agent.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	projectCtx := command.ProjectContext{
+		Log:         logger,
+		AgentPool:   "aws-prod",
+		ProjectName: "project",
+		BaseRepo:    eventsmodels.Repo{FullName: "owner/repo"},
+		Pull:        eventsmodels.PullRequest{Num: 1},
+	}
+	cmd.Path = filepath.Join(clone, "dir")
+	_, outCh := dispatcher.RunCommandAsync(projectCtx, cmd)
+	var lines []string
+	for line := range outCh {
+		if line.Err != nil {
+			return strings.Join(lines, "\n"), line.Err
+		}
+		lines = append(lines, line.Line)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func newClone(t *testing.T) string {
+	clone := t.TempDir()
+	Ok(t, os.MkdirAll(filepath.Join(clone, ".git"), 0700))
+	Ok(t, os.MkdirAll(filepath.Join(clone, "dir", ".terraform"), 0700))
+	Ok(t, os.MkdirAll(filepath.Join(clone, "modules", "vpc"), 0700))
+	Ok(t, os.MkdirAll(filepath.Join(clone, "other"), 0700))
+	Ok(t, os.WriteFile(filepath.Join(clone, "dir", "main.tf"), []byte(`module "vpc" { source = "../modules/vpc" }`), 0600))
+	Ok(t, os.WriteFile(filepath.Join(clone, "dir", ".terraform", "providers"), []byte("atlantis"), 0600))
+	Ok(t, os.WriteFile(filepath.Join(clone, "modules", "vpc", "main.tf"), []byte(`resource "null_resource" "this" {}`), 0600))
+	Ok(t, os.WriteFile(filepath.Join(clone, "other", "main.tf"), []byte(`resource "null_resource" "other" {}`), 0600))
+	return clone
+}
+
+func TestAgentsController_RunsCommands(t *testing.T) {
+	clone := newClone(t)
+	var ran tfclient.TerraformCommand
+	out, err := runOnAgent(t, clone, tfclient.TerraformCommand{
+		Distribution: "terraform",
+		Version:      "1.9.0",
+		Args:         []string{"plan", "-out", "default.tfplan"},
+		Env:          map[string]string{"CUSTOM": "value"},
+		Workspace:    "default",
+	}, func(ctx command.ProjectContext, cmd tfclient.TerraformCommand, path string) <-chan models.Line {
+		ran = cmd
+		outCh := make(chan models.Line, 2)
+		defer close(outCh)
+		if _, err := os.Stat(filepath.Join(path, "main.tf")); err != nil {
+			outCh <- models.Line{Err: err}
+			return outCh
+		}
+		if _, err := os.Stat(filepath.Join(path, "..", "modules", "vpc", "main.tf")); err != nil {
+			outCh <- models.Line{Err: err}
+			return outCh
+		}
+		// The other dirs of the clone aren't sent to the agent.
+		if _, err := os.Stat(filepath.Join(path, "..", "other")); !os.IsNotExist(err) {
+			outCh <- models.Line{Err: fmt.Errorf("exp other not to be sent: %v", err)}
+			return outCh
+		}
+		if _, err := os.Stat(filepath.Join(path, ".terraform")); !os.IsNotExist(err) {
+			outCh <- models.Line{Err: err}
+			return outCh
+		}
+		if err := os.WriteFile(filepath.Join(path, "default.tfplan"), []byte("plan"), 0600); err != nil {
+			outCh <- models.Line{Err: err}
+			return outCh
+		}
+		outCh <- models.Line{Line: "Planning " + ctx.BaseRepo.FullName}
+		outCh <- models.Line{Line: "Plan: 1 to add"}
+		return outCh
+	})
+	Ok(t, err)
+	Equals(t, "Planning owner/repo\nPlan: 1 to add", out)
+	Equals(t, []string{"plan", "-out", "default.tfplan"}, ran.Args)
+	Equals(t, map[string]string{"CUSTOM": "value"}, ran.Env)
+	Equals(t, "1.9.0", ran.Version)
+
+	t.Log("the planfile is synced back to the working dir, which keeps its .terraform dir")
+	plan, err := os.ReadFile(filepath.Join(clone, "dir", "default.tfplan"))
+	Ok(t, err)
+	Equals(t, "plan", string(plan))
+	providers, err := os.ReadFile(filepath.Join(clone, "dir", ".terraform", "providers"))
+	Ok(t, err)
+	Equals(t, "atlantis", string(providers))
+}
+
+func TestAgentsController_CommandFails(t *testing.T) {
+	out, err := runOnAgent(t, newClone(t), tfclient.TerraformCommand{
+		Distribution: "terraform",
+		Args:         []string{"apply"},
+	}, func(_ command.ProjectContext, _ tfclient.TerraformCommand, _ string) <-chan models.Line {
+		outCh := make(chan models.Line, 2)
+		outCh <- models.Line{Line: "Error: invalid"}
+		outCh <- models.Line{Err: os.ErrPermission}
+		close(outCh)
+		return outCh
+	})
+	ErrEquals(t, `running 'terraform apply' on agent pool "aws-prod": permission denied`, err)
+	Equals(t, "Error: invalid", out)
+}
+
+func TestAgentsController_CommandNotAllowed(t *testing.T) {
+	dispatcher := agents.NewDispatcher([]agents.Pool{{Name: "aws-prod", Token: "token", RepoPattern: "owner/*"}}, &jobs.NoopProjectOutputHandler{}, logging.NewNoopLogger(t))
+	ctx := command.ProjectContext{
+		Log:       logging.NewNoopLogger(t),
+		AgentPool: "aws-prod",
+		BaseRepo:  eventsmodels.Repo{FullName: "other/repo"},
+	}
+	_, outCh := dispatcher.RunCommandAsync(ctx, tfclient.TerraformCommand{Distribution: "terraform", Args: []string{"plan"}, Path: t.TempDir()})
+	line := <-outCh
+	ErrEquals(t, `repo other/repo isn't allowed to use agent pool "aws-prod"`, line.Err)
+
+	ctx.AgentPool = "gcp-prod"
+	_, outCh = dispatcher.RunCommandAsync(ctx, tfclient.TerraformCommand{Distribution: "terraform", Args: []string{"plan"}, Path: t.TempDir()})
+	line = <-outCh
+	ErrEquals(t, `agent pool "gcp-prod" isn't configured`, line.Err)
+}
+
+func TestAgentsController_Errors(t *testing.T) {
+	dispatcher := agents.NewDispatcher([]agents.Pool{{Name: "aws-prod", Token: "token"}}, &jobs.NoopProjectOutputHandler{}, logging.NewNoopLogger(t))
+	cases := []struct {
+		description string
+		dispatcher  *agents.Dispatcher
+		token       string
+		agent       string
+		path        string
+		code        int
+	}{
+		{"no agent pools", nil, "token", "agent-1", "/api/agents/jobs/next", http.StatusNotFound},
+		{"missing agent", dispatcher, "token", "", "/api/agents/jobs/next", http.StatusBadRequest},
+		{"invalid token", dispatcher, "other", "agent-1", "/api/agents/jobs/next", http.StatusUnauthorized},
+		{"unknown job", dispatcher, "token", "agent-1", "/api/agents/jobs/abc/complete", http.StatusNotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			server := newAgentsServer(t, c.dispatcher)
+			req, err := http.NewRequest("POST", server.URL+c.path, strings.NewReader(`{}`))
+			Ok(t, err)
+			req.Header.Set(agents.TokenHeader, c.token)
+			req.Header.Set(agents.AgentHeader, c.agent)
+			resp, err := http.DefaultClient.Do(req)
+			Ok(t, err)
+			resp.Body.Close() // nolint: errcheck
+			Equals(t, c.code, resp.StatusCode)
+		})
+	}
+}
@@ -4,19 +4,34 @@
 package controllers
 
 import (
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/runatlantis/atlantis/server/core/config"
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/core/db"
 	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/core/runtime"
 	"github.com/runatlantis/atlantis/server/events"
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/timeline"
 	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/jobs"
 	"github.com/runatlantis/atlantis/server/logging"
 	tally "github.com/uber-go/tally/v4"
 )
@@ -42,6 +57,58 @@ type APIController struct {
 	CommitStatusUpdater            events.CommitStatusUpdater            `validate:"required"`
 	// SilenceVCSStatusNoProjects is whether API should set commit status if no projects are found
 	SilenceVCSStatusNoProjects bool
+	// GlobalCfg and ParserValidator are used to inspect merged configs.
+	GlobalCfg       valid.GlobalCfg
+	ParserValidator *config.ParserValidator
+	// Drainer, if set, tracks requests as operations so they complete before
+	// shutting down or reloading config.
+	Drainer *events.Drainer
+	// ReloadConfig, if set, reloads the server-side config.
+	ReloadConfig func() error
+	// Database and DeleteLockCommand are used to release locks.
+	Database          db.Database
+	DeleteLockCommand events.DeleteLockCommand
+	// ProjectCmdOutputHandler is used to list jobs and stream their logs.
+	ProjectCmdOutputHandler jobs.ProjectCommandOutputHandler
+	// Summaries are the recently generated plan summaries.
+	Summaries *events.SummaryStore
+	// Timeline is the timeline of the pull requests. It may be nil.
+	Timeline *timeline.Store
+	// Canceller cancels the commands of pull requests.
+	Canceller *events.CancelCommandRunner
+	// CommandRunner replays the commands recorded in the timeline.
+	CommandRunner events.CommandRunner
+	// PlanJSONs are the stored JSON plans. Nil if they aren't exported.
+	PlanJSONs *events.PlanJSONStore
+	// ArtifactTokens are the tokens that may only download the plan artifacts
+	// of jobs, in addition to APISecret.
+	ArtifactTokens []APIArtifactToken
+	// PlanfileEncryptor decrypts the planfiles and saved plan outputs that are
+	// downloaded. Nil if planfiles aren't encrypted.
+	PlanfileEncryptor *runtime.PlanfileEncryptor
+	// Profiles captures and stores profiles of the server. It may be nil.
+	Profiles *events.ProfileStore
+	// DeferredCommands are the commands requested while draining, run with
+	// CommandRunner when resuming. It may be nil.
+	DeferredCommands *events.DeferredCommands
+}
+
+// APIArtifactToken is a token that may only download the plan artifacts of
+// the jobs of the repos matching RepoPattern.
+type APIArtifactToken struct {
+	Token string
+	// RepoPattern matches the full names of the repos the token is scoped to,
+	// supporting * wildcards ex. owner/*. Empty if it's scoped to all repos.
+	RepoPattern string
+}
+
+// Allows returns whether the token may download the artifacts of repoFullName.
+func (t APIArtifactToken) Allows(repoFullName string) bool {
+	if t.RepoPattern == "" {
+		return true
+	}
+	matched, err := path.Match(t.RepoPattern, repoFullName)
+	return err == nil && matched
 }
 
 type APIRequest struct {
@@ -56,164 +123,1457 @@ type APIRequest struct {
 	}
 }
 
-func (a *APIRequest) getCommands(ctx *command.Context, cmdName command.Name, cmdBuilder func(*command.Context, *events.CommentCommand) ([]command.ProjectContext, error)) ([]command.ProjectContext, []*events.CommentCommand, error) {
-	cc := make([]*events.CommentCommand, 0)
+func (a *APIRequest) getCommands(ctx *command.Context, cmdName command.Name, cmdBuilder func(*command.Context, *events.CommentCommand) ([]command.ProjectContext, error)) ([]command.ProjectContext, []*events.CommentCommand, error) {
+	cc := make([]*events.CommentCommand, 0)
+
+	for _, project := range a.Projects {
+		cc = append(cc, &events.CommentCommand{
+			Name:        cmdName,
+			ProjectName: project,
+		})
+	}
+	for _, path := range a.Paths {
+		cc = append(cc, &events.CommentCommand{
+			Name:       cmdName,
+			RepoRelDir: strings.TrimRight(path.Directory, "/"),
+			Workspace:  path.Workspace,
+		})
+	}
+
+	cmds := make([]command.ProjectContext, 0)
+	for _, commentCommand := range cc {
+		projectCmds, err := cmdBuilder(ctx, commentCommand)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to build command: %v", err)
+		}
+		cmds = append(cmds, projectCmds...)
+	}
+
+	return cmds, cc, nil
+}
+
+func (a *APIController) apiReportError(w http.ResponseWriter, code int, err error) {
+	response, _ := json.Marshal(map[string]string{
+		"error": err.Error(),
+	})
+	a.respond(w, logging.Warn, code, "%s", string(response))
+}
+
+func (a *APIController) Plan(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	request, ctx, code, err := a.apiParseAndValidate(r, models.APITokenScopePlan)
+	if err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	opDone, ok := a.startOp(w)
+	if !ok {
+		return
+	}
+	defer opDone()
+
+	err = a.apiSetup(ctx, command.Plan)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	result, err := a.apiPlan(request, ctx)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer a.Locker.UnlockByPull(ctx.HeadRepo.FullName, ctx.Pull.Num) // nolint: errcheck
+	if result.HasErrors() {
+		code = http.StatusInternalServerError
+	}
+
+	// TODO: make a better response
+	response, err := json.Marshal(result)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Warn, code, "%s", string(response))
+}
+
+func (a *APIController) Apply(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	request, ctx, code, err := a.apiParseAndValidate(r, models.APITokenScopeApply)
+	if err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	opDone, ok := a.startOp(w)
+	if !ok {
+		return
+	}
+	defer opDone()
+
+	err = a.apiSetup(ctx, command.Apply)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// We must first make the plan for all projects
+	_, err = a.apiPlan(request, ctx)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer a.Locker.UnlockByPull(ctx.HeadRepo.FullName, ctx.Pull.Num) // nolint: errcheck
+
+	// We can now prepare and run the apply step
+	result, err := a.apiApply(request, ctx)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if result.HasErrors() {
+		code = http.StatusInternalServerError
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Warn, code, "%s", string(response))
+}
+
+type LockDetail struct {
+	Name            string
+	ProjectName     string
+	ProjectRepo     string
+	ProjectRepoPath string
+	PullID          int `json:",string"`
+	PullURL         string
+	User            string
+	Workspace       string
+	Time            time.Time
+}
+
+type ListLocksResult struct {
+	Locks []LockDetail
+}
+
+type ListJobsResult struct {
+	Jobs []jobs.PullInfoWithJobIDs
+}
+
+type ListSummariesResult struct {
+	Summaries []events.StoredSummary
+}
+
+type CompareSummaryVariantsResult struct {
+	Variants []events.SummaryVariantStats
+}
+
+type ListPullEventsResult struct {
+	Events []timeline.Event
+}
+
+type ListPullCommandsResult struct {
+	Commands []models.CommandRecord
+}
+
+type ListPlansResult struct {
+	Plans []events.PlanJSON
+}
+
+type ListResourceChangesResult struct {
+	ResourceChanges []models.ResourceChange
+}
+
+type ListApplyReportsResult struct {
+	ApplyReports []models.SignedApplyReport
+}
+
+type ListProfilesResult struct {
+	Profiles []events.StoredProfile
+}
+
+type CaptureProfilesResult struct {
+	// IDs are the IDs of the profiles being captured, they can be downloaded
+	// once the capture completed.
+	IDs []string
+}
+
+type CancelResult struct {
+	// Interrupted is how many running processes were interrupted.
+	Interrupted int
+}
+
+// ReplayCommandRequest replays a comment command of a pull request.
+type ReplayCommandRequest struct {
+	// Repository is the full name of the repo, ex. runatlantis/atlantis.
+	Repository string `validate:"required"`
+	Type       string `validate:"required"`
+	PR         int    `validate:"required"`
+	// EventID is the ID of the command, see ListPullCommands, or of its
+	// event in the timeline of the pull request, see ListPullEvents.
+	EventID string `validate:"required"`
+}
+
+type ReplayCommandResult struct {
+	// CorrelationID correlates the logs, and the timeline, of the replay.
+	CorrelationID string
+	// Command is the command that is replayed.
+	Command models.CommandRecord
+}
+
+// DrainResult is the drain status of the /api/drain endpoints.
+type DrainResult struct {
+	// Draining is whether new commands are rejected, or deferred.
+	Draining bool
+	// InProgressOps is the number of operations that are still running.
+	InProgressOps int
+	// DeferredCommands is the number of commands that run once Atlantis
+	// restarts or resumes.
+	DeferredCommands int
+}
+
+// InspectConfigRequest is a dry-run merge of a repo's config.
+type InspectConfigRequest struct {
+	// Repository is the repo's id, ex. github.com/runatlantis/atlantis.
+	Repository string `validate:"required"`
+	// Branch is the base branch projects are filtered by, if set.
+	Branch string
+	// RepoConfig is the contents of the repo's atlantis.yaml. If empty, the
+	// default project config is inspected.
+	RepoConfig string
+}
+
+type InspectedProject struct {
+	Name                      string
+	Dir                       string
+	Workspace                 string
+	Workflow                  string
+	PlanRequirements          []string
+	ApplyRequirements         []string
+	ImportRequirements        []string
+	DeleteSourceBranchOnMerge bool
+	RepoLocks                 string
+	PolicyCheck               bool
+	SilencePRComments         []string
+}
+
+type InspectConfigResult struct {
+	Projects []InspectedProject
+	// Trace is where each setting was merged from.
+	Trace []string
+}
+
+// ValidateConfigRequest validates a repo's config.
+type ValidateConfigRequest struct {
+	// Repository is the repo's id, ex. github.com/runatlantis/atlantis,
+	// whose server-side repo config the repo config is checked against.
+	Repository string `validate:"required"`
+	// RepoConfig is the contents of the repo's atlantis.yaml.
+	RepoConfig string
+}
+
+type ValidateConfigResult struct {
+	Valid  bool
+	Errors []config.ConfigError
+}
+
+func (a *APIController) ListLocks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	locks, err := a.Locker.List()
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	result := ListLocksResult{}
+	for name, lock := range locks {
+		lockDetail := LockDetail{
+			name,
+			lock.Project.ProjectName,
+			lock.Project.RepoFullName,
+			lock.Project.Path,
+			lock.Pull.Num,
+			lock.Pull.URL,
+			lock.User.Username,
+			lock.Workspace,
+			lock.Time,
+		}
+		result.Locks = append(result.Locks, lockDetail)
+	}
+
+	response, err := json.Marshal(result)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Warn, http.StatusOK, "%s", string(response))
+}
+
+// DeleteLock releases the lock with the id query parameter, like discarding
+// it in the UI does.
+func (a *APIController) DeleteLock(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	caller, code, err := a.apiAuthenticate(r, models.APITokenScopeApply)
+	if err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		a.apiReportError(w, http.StatusBadRequest, errors.New("no lock id in request"))
+		return
+	}
+	if !caller.admin() {
+		locks, err := a.Locker.List()
+		if err != nil {
+			a.apiReportError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if lock, ok := locks[id]; ok && !caller.allowsRepo(lock.Project.RepoFullName) {
+			a.apiReportError(w, http.StatusForbidden, fmt.Errorf("token isn't allowed to release the locks of %s", lock.Project.RepoFullName))
+			return
+		}
+	}
+	lock, err := a.DeleteLockCommand.DeleteLock(a.Logger, id)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, fmt.Errorf("deleting lock: %w", err))
+		return
+	}
+	if lock == nil {
+		a.apiReportError(w, http.StatusNotFound, fmt.Errorf("no lock found at id %q", id))
+		return
+	}
+	discardLockedPlan(a.Logger, a.Database, a.VCSClient, lock, "the Atlantis API")
+
+	response, err := json.Marshal(map[string]string{"deleted": id})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Info, http.StatusOK, "%s", string(response))
+}
+
+// ListJobs lists the jobs of each pull request.
+func (a *APIController) ListJobs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	caller, code, err := a.apiAuthenticate(r, models.APITokenScopeReadOnly)
+	if err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	result := ListJobsResult{Jobs: []jobs.PullInfoWithJobIDs{}}
+	for _, pull := range a.ProjectCmdOutputHandler.GetPullToJobMapping() {
+		if caller.allowsRepo(pull.Pull.RepoFullName) {
+			result.Jobs = append(result.Jobs, pull)
+		}
+	}
+	response, err := json.Marshal(result)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
+// JobLogs streams the output of a job as plain text until the job completes
+// or the client disconnects.
+func (a *APIController) JobLogs(w http.ResponseWriter, r *http.Request) {
+	caller, code, err := a.apiAuthenticate(r, models.APITokenScopeReadOnly)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		a.apiReportError(w, code, err)
+		return
+	}
+	jobID := mux.Vars(r)["job-id"]
+	if !a.ProjectCmdOutputHandler.IsKeyExists(jobID) {
+		w.Header().Set("Content-Type", "application/json")
+		a.apiReportError(w, http.StatusNotFound, fmt.Errorf("no job found with id %q", jobID))
+		return
+	}
+	if !caller.admin() {
+		if pull, ok := a.jobPull(jobID); !ok || !caller.allowsRepo(pull.RepoFullName) {
+			w.Header().Set("Content-Type", "application/json")
+			a.apiReportError(w, http.StatusForbidden, errors.New("token isn't allowed to read the logs of this job"))
+			return
+		}
+	}
+
+	// Buffered like the websocket multiplexor's so lines get queued.
+	receiver := make(chan string, 1000)
+	go a.ProjectCmdOutputHandler.Register(jobID, receiver)
+	defer a.ProjectCmdOutputHandler.Deregister(jobID, receiver)
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	for {
+		select {
+		case line, ok := <-receiver:
+			if !ok {
+				return
+			}
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// PlanArtifact downloads the current plan of the project of a job in the
+// format query parameter: text, the plan's full output, json, the output of
+// terraform show -json, or binary, the planfile. Defaults to text.
+func (a *APIController) PlanArtifact(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	allowsRepo, code, err := a.artifactAuthenticate(r)
+	if err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "text"
+	}
+	if format != "text" && format != "json" && format != "binary" {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("invalid format %q, must be one of text, json or binary", format))
+		return
+	}
+	jobID := mux.Vars(r)["job-id"]
+	pull, ok := a.jobPull(jobID)
+	if !ok {
+		a.apiReportError(w, http.StatusNotFound, fmt.Errorf("no job found with id %q", jobID))
+		return
+	}
+	if !allowsRepo(pull.RepoFullName) {
+		a.apiReportError(w, http.StatusForbidden, fmt.Errorf("token isn't allowed to download the artifacts of %s", pull.RepoFullName))
+		return
+	}
+	ctx := command.ProjectContext{
+		Pull:        models.PullRequest{Num: pull.PullNum, BaseRepo: models.Repo{FullName: pull.RepoFullName}},
+		RepoRelDir:  pull.Path,
+		Workspace:   pull.Workspace,
+		ProjectName: pull.ProjectName,
+	}
+
+	switch format {
+	case "text":
+		output := events.LoadSavedPlan(a.WorkingDir, a.PlanfileEncryptor, ctx)
+		if output.PlanSuccess == nil {
+			a.apiReportError(w, http.StatusNotFound, errors.New(output.Failure))
+			return
+		}
+		a.writeArtifact(w, "text/plain; charset=utf-8", []byte(output.PlanSuccess.TerraformOutput))
+	case "json":
+		if a.PlanJSONs == nil {
+			a.apiReportError(w, http.StatusBadRequest, errors.New("exporting plan json isn't enabled"))
+			return
+		}
+		plans, err := a.PlanJSONs.List(pull.RepoFullName, pull.PullNum)
+		if err != nil {
+			a.apiReportError(w, http.StatusInternalServerError, err)
+			return
+		}
+		idx := slices.IndexFunc(plans, func(plan events.PlanJSON) bool {
+			return plan.RepoRelDir == pull.Path && plan.Workspace == pull.Workspace && plan.ProjectName == pull.ProjectName
+		})
+		if idx == -1 {
+			a.apiReportError(w, http.StatusNotFound, errors.New("no plan json found"))
+			return
+		}
+		a.writeArtifact(w, "application/json", plans[idx].Plan)
+	case "binary":
+		repoDir, err := a.WorkingDir.GetWorkingDir(ctx.Pull.BaseRepo, ctx.Pull, ctx.Workspace)
+		if err != nil {
+			a.apiReportError(w, http.StatusNotFound, errors.New("no plan found"))
+			return
+		}
+		planFilename := runtime.GetPlanFilename(ctx.Workspace, ctx.ProjectName)
+		planPath := filepath.Join(repoDir, ctx.RepoRelDir, planFilename)
+		var planfile []byte
+		if a.PlanfileEncryptor != nil {
+			planfile, err = a.PlanfileEncryptor.ReadPlanfile(planPath)
+		} else {
+			planfile, err = os.ReadFile(planPath) // nolint: gosec
+		}
+		if os.IsNotExist(err) {
+			a.apiReportError(w, http.StatusNotFound, errors.New("no plan found"))
+			return
+		}
+		if err != nil {
+			a.apiReportError(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", planFilename))
+		a.writeArtifact(w, "application/octet-stream", planfile)
+	}
+}
+
+// writeArtifact responds with artifact. Unlike respond, it doesn't log it since
+// plans can contain sensitive values.
+func (a *APIController) writeArtifact(w http.ResponseWriter, contentType string, artifact []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(artifact); err != nil {
+		a.Logger.Warn("unable to write artifact: %s", err)
+	}
+}
+
+// jobPull returns the pull request and project of the job with jobID.
+func (a *APIController) jobPull(jobID string) (jobs.PullInfo, bool) {
+	for _, pull := range a.ProjectCmdOutputHandler.GetPullToJobMapping() {
+		for _, job := range pull.JobIDInfos {
+			if job.JobID == jobID {
+				return pull.Pull, true
+			}
+		}
+	}
+	return jobs.PullInfo{}, false
+}
+
+// artifactAuthenticate returns whether the request's token may download the
+// artifacts of a repo, or an error and the response code if the API and
+// artifact tokens are disabled or it doesn't match any.
+func (a *APIController) artifactAuthenticate(r *http.Request) (func(repoFullName string) bool, int, error) {
+	secret := []byte(r.Header.Get(atlantisTokenHeader))
+	var tokens []APIArtifactToken
+	for _, token := range a.ArtifactTokens {
+		if len(secret) > 0 && subtle.ConstantTimeCompare(secret, []byte(token.Token)) == 1 {
+			tokens = append(tokens, token)
+		}
+	}
+	if len(tokens) > 0 {
+		return func(repoFullName string) bool {
+			return slices.ContainsFunc(tokens, func(t APIArtifactToken) bool { return t.Allows(repoFullName) })
+		}, 0, nil
+	}
+	caller, code, err := a.apiAuthenticate(r, models.APITokenScopeReadOnly)
+	if err != nil {
+		if code == http.StatusBadRequest && len(a.ArtifactTokens) > 0 {
+			return nil, http.StatusUnauthorized, fmt.Errorf("header %s did not match expected secret", atlantisTokenHeader)
+		}
+		return nil, code, err
+	}
+	return caller.allowsRepo, 0, nil
+}
+
+// ListSummaries lists the plan summaries recently generated for the pull
+// request in the repository and pull query parameters.
+func (a *APIController) ListSummaries(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	caller, code, err := a.apiAuthenticate(r, models.APITokenScopeReadOnly)
+	if err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	repository := r.URL.Query().Get("repository")
+	pullNum, err := strconv.Atoi(r.URL.Query().Get("pull"))
+	if repository == "" || err != nil {
+		a.apiReportError(w, http.StatusBadRequest, errors.New("repository and pull query parameters are required"))
+		return
+	}
+	if !caller.allowsRepo(repository) {
+		a.apiReportError(w, http.StatusForbidden, fmt.Errorf("token isn't allowed to read the summaries of %s", repository))
+		return
+	}
+	result := ListSummariesResult{Summaries: []events.StoredSummary{}}
+	if a.Summaries != nil {
+		result.Summaries = append(result.Summaries, a.Summaries.List(repository, pullNum)...)
+	}
+	response, err := json.Marshal(result)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
+// CompareSummaryVariants compares the feedback, latency and cost of the
+// summaries generated by each summarizer variant, model and prompt.
+func (a *APIController) CompareSummaryVariants(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticateAdmin(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	if a.Database == nil {
+		a.apiReportError(w, http.StatusBadRequest, errors.New("no database is configured"))
+		return
+	}
+	feedback, err := a.Database.ListSummaryFeedback()
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	result := CompareSummaryVariantsResult{Variants: []events.SummaryVariantStats{}}
+	result.Variants = append(result.Variants, events.CompareSummaryVariants(feedback)...)
+	response, err := json.Marshal(result)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
+// ListPullEvents lists the timeline of everything Atlantis did for the pull
+// request in the repo and pull path variables, oldest first, optionally only
+// the events of the type query parameter.
+func (a *APIController) ListPullEvents(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	caller, code, err := a.apiAuthenticate(r, models.APITokenScopeReadOnly)
+	if err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	repository := mux.Vars(r)["repo"]
+	pullNum, err := strconv.Atoi(mux.Vars(r)["pull"])
+	if err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("invalid pull: %w", err))
+		return
+	}
+	if !caller.allowsRepo(repository) {
+		a.apiReportError(w, http.StatusForbidden, fmt.Errorf("token isn't allowed to read the events of %s", repository))
+		return
+	}
+	result := ListPullEventsResult{Events: []timeline.Event{}}
+	for _, event := range a.Timeline.List(repository, pullNum) {
+		if r.URL.Query().Has("type") && string(event.Type) != r.URL.Query().Get("type") {
+			continue
+		}
+		result.Events = append(result.Events, event)
+	}
+	response, err := json.Marshal(result)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
+// ListPullCommands lists the stored comment commands of the pull request in
+// the repo and pull path parameters, the most recent first, to replay them.
+func (a *APIController) ListPullCommands(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	caller, code, err := a.apiAuthenticate(r, models.APITokenScopeReadOnly)
+	if err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	if a.Database == nil {
+		a.apiReportError(w, http.StatusBadRequest, errors.New("no database is configured"))
+		return
+	}
+	repository := mux.Vars(r)["repo"]
+	pullNum, err := strconv.Atoi(mux.Vars(r)["pull"])
+	if err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("invalid pull: %w", err))
+		return
+	}
+	if !caller.allowsRepo(repository) {
+		a.apiReportError(w, http.StatusForbidden, fmt.Errorf("token isn't allowed to read the commands of %s", repository))
+		return
+	}
+	records, err := a.Database.ListCommandRecords(models.CommandRecordQuery{Repository: repository, PullNum: pullNum})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	response, err := json.Marshal(ListPullCommandsResult{Commands: records})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
+// ListPlans lists the JSON plans of the projects of the pull request in the
+// repository and pull query parameters, optionally only the ones in the dir,
+// workspace or project query parameters.
+func (a *APIController) ListPlans(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	caller, code, err := a.apiAuthenticate(r, models.APITokenScopeReadOnly)
+	if err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	query := r.URL.Query()
+	repository := query.Get("repository")
+	pullNum, err := strconv.Atoi(query.Get("pull"))
+	if repository == "" || err != nil {
+		a.apiReportError(w, http.StatusBadRequest, errors.New("repository and pull query parameters are required"))
+		return
+	}
+	if !caller.allowsRepo(repository) {
+		a.apiReportError(w, http.StatusForbidden, fmt.Errorf("token isn't allowed to read the plans of %s", repository))
+		return
+	}
+	if a.PlanJSONs == nil {
+		a.apiReportError(w, http.StatusBadRequest, errors.New("exporting plan json isn't enabled"))
+		return
+	}
+	plans, err := a.PlanJSONs.List(repository, pullNum)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	result := ListPlansResult{Plans: []events.PlanJSON{}}
+	for _, plan := range plans {
+		if (query.Has("dir") && plan.RepoRelDir != query.Get("dir")) ||
+			(query.Has("workspace") && plan.Workspace != query.Get("workspace")) ||
+			(query.Has("project") && plan.ProjectName != query.Get("project")) {
+			continue
+		}
+		result.Plans = append(result.Plans, plan)
+	}
+	response, err := json.Marshal(result)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
+// defaultResourceChangesLimit is how many resource changes are listed if the
+// limit query parameter isn't set.
+const defaultResourceChangesLimit = 100
+
+// ListResourceChanges lists the recorded changes applies made to resources,
+// the most recent first, optionally only the ones selected by the repository,
+// pull, address, project, user and since query parameters.
+func (a *APIController) ListResourceChanges(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	caller, code, err := a.apiAuthenticate(r, models.APITokenScopeReadOnly)
+	if err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	if a.Database == nil {
+		a.apiReportError(w, http.StatusBadRequest, errors.New("no database is configured"))
+		return
+	}
+	query := r.URL.Query()
+	changesQuery := models.ResourceChangeQuery{
+		Repository:  query.Get("repository"),
+		Address:     query.Get("address"),
+		ProjectName: query.Get("project"),
+		User:        query.Get("user"),
+		Limit:       defaultResourceChangesLimit,
+	}
+	if query.Has("pull") {
+		if changesQuery.PullNum, err = strconv.Atoi(query.Get("pull")); err != nil {
+			a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("invalid pull: %w", err))
+			return
+		}
+	}
+	if query.Has("since") {
+		if changesQuery.Since, err = time.Parse(time.RFC3339, query.Get("since")); err != nil {
+			a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("invalid since, must be an RFC 3339 time: %w", err))
+			return
+		}
+	}
+	if query.Has("limit") {
+		if changesQuery.Limit, err = strconv.Atoi(query.Get("limit")); err != nil || changesQuery.Limit < 1 {
+			a.apiReportError(w, http.StatusBadRequest, errors.New("invalid limit, must be a positive number"))
+			return
+		}
+	}
+	limit := changesQuery.Limit
+	if !caller.admin() {
+		// The changes of the repos the token isn't allowed are filtered out
+		// before applying the limit.
+		changesQuery.Limit = 0
+	}
+	changes, err := a.Database.ListResourceChanges(changesQuery)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !caller.admin() {
+		changes = slices.DeleteFunc(changes, func(change models.ResourceChange) bool {
+			return !caller.allowsRepo(change.Repository)
+		})
+		changes = changes[:min(len(changes), limit)]
+	}
+	response, err := json.Marshal(ListResourceChangesResult{ResourceChanges: changes})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
+// defaultApplyReportsLimit is how many apply reports are listed if the limit
+// query parameter isn't set.
+const defaultApplyReportsLimit = 100
+
+// ListApplyReports lists the signed reports of the applies, the most recent
+// first, optionally only the ones selected by the repository, pull, project
+// and since query parameters.
+func (a *APIController) ListApplyReports(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	caller, code, err := a.apiAuthenticate(r, models.APITokenScopeReadOnly)
+	if err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	if a.Database == nil {
+		a.apiReportError(w, http.StatusBadRequest, errors.New("no database is configured"))
+		return
+	}
+	query := r.URL.Query()
+	reportsQuery := models.ApplyReportQuery{
+		Repository:  query.Get("repository"),
+		ProjectName: query.Get("project"),
+		Limit:       defaultApplyReportsLimit,
+	}
+	if query.Has("pull") {
+		if reportsQuery.PullNum, err = strconv.Atoi(query.Get("pull")); err != nil {
+			a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("invalid pull: %w", err))
+			return
+		}
+	}
+	if query.Has("since") {
+		if reportsQuery.Since, err = time.Parse(time.RFC3339, query.Get("since")); err != nil {
+			a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("invalid since, must be an RFC 3339 time: %w", err))
+			return
+		}
+	}
+	if query.Has("limit") {
+		if reportsQuery.Limit, err = strconv.Atoi(query.Get("limit")); err != nil || reportsQuery.Limit < 1 {
+			a.apiReportError(w, http.StatusBadRequest, errors.New("invalid limit, must be a positive number"))
+			return
+		}
+	}
+	limit := reportsQuery.Limit
+	if !caller.admin() {
+		// The reports of the repos the token isn't allowed are filtered out
+		// before applying the limit.
+		reportsQuery.Limit = 0
+	}
+	reports, err := a.Database.ListApplyReports(reportsQuery)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !caller.admin() {
+		reports = slices.DeleteFunc(reports, func(report models.SignedApplyReport) bool {
+			return !caller.allowsRepo(report.Report.Repository)
+		})
+		reports = reports[:min(len(reports), limit)]
+	}
+	response, err := models.MarshalApplyReportJSON(ListApplyReportsResult{ApplyReports: reports})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
+// ApplyReport downloads the signed report of the apply with the id in the
+// path, to be attached as change management evidence.
+func (a *APIController) ApplyReport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	caller, code, err := a.apiAuthenticate(r, models.APITokenScopeReadOnly)
+	if err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	if a.Database == nil {
+		a.apiReportError(w, http.StatusBadRequest, errors.New("no database is configured"))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	reports, err := a.Database.ListApplyReports(models.ApplyReportQuery{ID: id, Limit: 1})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if len(reports) == 0 {
+		a.apiReportError(w, http.StatusNotFound, fmt.Errorf("no apply report found with id %q", id))
+		return
+	}
+	if !caller.allowsRepo(reports[0].Report.Repository) {
+		a.apiReportError(w, http.StatusForbidden, fmt.Errorf("token isn't allowed to download the apply reports of %s", reports[0].Report.Repository))
+		return
+	}
+	report, err := models.MarshalApplyReportJSON(reports[0])
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("apply-report-%s.json", id)))
+	a.writeArtifact(w, "application/json", report)
+}
+
+// Cancel cancels the commands of the pull request in the repository and pull
+// query parameters, like commenting atlantis cancel does.
+func (a *APIController) Cancel(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	caller, code, err := a.apiAuthenticate(r, models.APITokenScopeApply)
+	if err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	repository := r.URL.Query().Get("repository")
+	pullNum, err := strconv.Atoi(r.URL.Query().Get("pull"))
+	if repository == "" || err != nil {
+		a.apiReportError(w, http.StatusBadRequest, errors.New("repository and pull query parameters are required"))
+		return
+	}
+	if !caller.allowsRepo(repository) {
+		a.apiReportError(w, http.StatusForbidden, fmt.Errorf("token isn't allowed to cancel the commands of %s", repository))
+		return
+	}
+	if a.Canceller == nil {
+		a.apiReportError(w, http.StatusBadRequest, errors.New("cancelling isn't enabled"))
+		return
+	}
+	a.Logger.Info("cancelling the commands of %s#%d through the API, requested by %s from %s", repository, pullNum, caller.name(), r.RemoteAddr)
+	interrupted, err := a.Canceller.CancelPull(a.Logger, models.PullRequest{Num: pullNum, BaseRepo: models.Repo{FullName: repository}})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	response, err := json.Marshal(CancelResult{Interrupted: interrupted})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Info, http.StatusOK, "%s", string(response))
+}
+
+// ReplayCommand runs again, in the background, a comment command of a pull
+// request, with the same project and flags and as the same user, ex. after
+// fixing the server config that made a batch of commands fail.
+func (a *APIController) ReplayCommand(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticateAdmin(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	var request ReplayCommandRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("failed to parse request: %v", err.Error()))
+		return
+	}
+	if err := validator.New().Struct(request); err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("request is missing fields: %v", err.Error()))
+		return
+	}
+	record, code, err := a.commandRecord(request)
+	if err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	cmd, err := events.ReplayedCommentCommand(record.Request)
+	if err != nil {
+		a.apiReportError(w, http.StatusBadRequest, err)
+		return
+	}
+	VCSHostType, err := models.NewVCSHostType(request.Type)
+	if err != nil {
+		a.apiReportError(w, http.StatusBadRequest, err)
+		return
+	}
+	// Bitbucket pull requests can't be fetched, the command runner relies on
+	// the ones parsed from webhooks.
+	if VCSHostType == models.BitbucketCloud || VCSHostType == models.BitbucketServer {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("replaying commands isn't supported for %s", VCSHostType))
+		return
+	}
+	cloneURL, err := a.VCSClient.GetCloneURL(a.Logger, VCSHostType, request.Repository)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	baseRepo, err := a.Parser.ParseAPIPlanRequest(VCSHostType, request.Repository, cloneURL)
+	if err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("failed to parse request: %v", err))
+		return
+	}
+	if !a.RepoAllowlistChecker.IsAllowlisted(baseRepo.FullName, baseRepo.VCSHost.Hostname) {
+		a.apiReportError(w, http.StatusForbidden, fmt.Errorf("repo not allowlisted"))
+		return
+	}
+
+	correlationID := logging.NewCorrelationID()
+	logger := a.Logger.With(logging.CorrelationIDKey, correlationID)
+	logger.Info("replaying %s of %s#%d, requested by the API secret from %s: %s", record.ID, request.Repository, request.PR, r.RemoteAddr, cmd)
+	// The head repo is the base repo unless the command runner fetches it with
+	// the pull request.
+	go a.CommandRunner.RunCommentCommand(logger, baseRepo, &baseRepo, nil, models.User{Username: record.User}, request.PR, cmd)
+
+	response, err := json.Marshal(ReplayCommandResult{CorrelationID: correlationID, Command: record})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Info, http.StatusOK, "%s", string(response))
+}
+
+// commandRecord returns the command request replays. Commands are replayed
+// from the command records, which survive restarts and outlive the in-memory
+// timeline, or from the timeline if there's no database.
+func (a *APIController) commandRecord(request ReplayCommandRequest) (models.CommandRecord, int, error) {
+	if a.Database != nil {
+		records, err := a.Database.ListCommandRecords(models.CommandRecordQuery{ID: request.EventID, Repository: request.Repository, PullNum: request.PR, Limit: 1})
+		if err != nil {
+			return models.CommandRecord{}, http.StatusInternalServerError, err
+		}
+		if len(records) == 0 {
+			return models.CommandRecord{}, http.StatusNotFound, fmt.Errorf("no comment command %s of %s#%d, they're kept for %s", request.EventID, request.Repository, request.PR, models.CommandRecordRetention)
+		}
+		return records[0], 0, nil
+	}
+	event, ok := a.Timeline.Get(request.Repository, request.PR, request.EventID)
+	if !ok {
+		return models.CommandRecord{}, http.StatusNotFound, fmt.Errorf("no event %s in the timeline of %s#%d", request.EventID, request.Repository, request.PR)
+	}
+	if event.Request == nil {
+		return models.CommandRecord{}, http.StatusBadRequest, fmt.Errorf("event %s isn't a comment command", request.EventID)
+	}
+	return models.CommandRecord{ID: event.ID, Time: event.Time, Repository: request.Repository, PullNum: request.PR, User: event.User, Request: *event.Request}, 0, nil
+}
 
-	for _, project := range a.Projects {
-		cc = append(cc, &events.CommentCommand{
-			Name:        cmdName,
-			ProjectName: project,
-		})
+// InspectConfig returns the configs Atlantis would use for a repo's projects
+// once server-side org and repo settings and the repo's atlantis.yaml are
+// merged, without running anything.
+func (a *APIController) InspectConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	caller, code, err := a.apiAuthenticate(r, models.APITokenScopeReadOnly)
+	if err != nil {
+		a.apiReportError(w, code, err)
+		return
 	}
-	for _, path := range a.Paths {
-		cc = append(cc, &events.CommentCommand{
-			Name:       cmdName,
-			RepoRelDir: strings.TrimRight(path.Directory, "/"),
-			Workspace:  path.Workspace,
-		})
+	opDone, ok := a.startOp(w)
+	if !ok {
+		return
+	}
+	defer opDone()
+	var request InspectConfigRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("failed to parse request: %v", err.Error()))
+		return
+	}
+	if err := validator.New().Struct(request); err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("request is missing fields: %v", err.Error()))
+		return
+	}
+	// The repo's id is its hostname followed by its full name.
+	if _, repoFullName, _ := strings.Cut(request.Repository, "/"); !caller.allowsRepo(repoFullName) {
+		a.apiReportError(w, http.StatusForbidden, fmt.Errorf("token isn't allowed to inspect the config of %s", request.Repository))
+		return
 	}
 
-	cmds := make([]command.ProjectContext, 0)
-	for _, commentCommand := range cc {
-		projectCmds, err := cmdBuilder(ctx, commentCommand)
+	log := a.Logger.WithHistory("repo", request.Repository)
+	var merged []valid.MergedProjectCfg
+	if request.RepoConfig == "" {
+		merged = append(merged, a.GlobalCfg.DefaultProjCfg(log, request.Repository, events.DefaultRepoRelDir, events.DefaultWorkspace))
+	} else {
+		repoCfg, err := a.ParserValidator.ParseRepoCfgData([]byte(request.RepoConfig), a.GlobalCfg, request.Repository, request.Branch)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to build command: %v", err)
+			a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("parsing repo config: %w", err))
+			return
+		}
+		for _, proj := range repoCfg.Projects {
+			merged = append(merged, a.GlobalCfg.MergeProjectCfg(log, request.Repository, proj, repoCfg))
 		}
-		cmds = append(cmds, projectCmds...)
 	}
 
-	return cmds, cc, nil
-}
+	result := InspectConfigResult{Projects: []InspectedProject{}}
+	for _, m := range merged {
+		result.Projects = append(result.Projects, InspectedProject{
+			Name:                      m.Name,
+			Dir:                       m.RepoRelDir,
+			Workspace:                 m.Workspace,
+			Workflow:                  m.Workflow.Name,
+			PlanRequirements:          m.PlanRequirements,
+			ApplyRequirements:         m.ApplyRequirements,
+			ImportRequirements:        m.ImportRequirements,
+			DeleteSourceBranchOnMerge: m.DeleteSourceBranchOnMerge,
+			RepoLocks:                 string(m.RepoLocks.Mode),
+			PolicyCheck:               m.PolicyCheck,
+			SilencePRComments:         m.SilencePRComments,
+		})
+	}
+	if history := strings.TrimSpace(log.GetHistory()); history != "" {
+		result.Trace = strings.Split(history, "\n")
+	}
 
-func (a *APIController) apiReportError(w http.ResponseWriter, code int, err error) {
-	response, _ := json.Marshal(map[string]string{
-		"error": err.Error(),
-	})
-	a.respond(w, logging.Warn, code, "%s", string(response))
+	response, err := json.Marshal(result)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
 }
 
-func (a *APIController) Plan(w http.ResponseWriter, r *http.Request) {
+// ValidateConfig validates a repo's atlantis.yaml against its schema and the
+// server-side repo config, responding every problem found.
+func (a *APIController) ValidateConfig(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	request, ctx, code, err := a.apiParseAndValidate(r)
+	caller, code, err := a.apiAuthenticate(r, models.APITokenScopeReadOnly)
 	if err != nil {
 		a.apiReportError(w, code, err)
 		return
 	}
+	var request ValidateConfigRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("failed to parse request: %v", err.Error()))
+		return
+	}
+	if err := validator.New().Struct(request); err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("request is missing fields: %v", err.Error()))
+		return
+	}
+	if _, repoFullName, _ := strings.Cut(request.Repository, "/"); !caller.allowsRepo(repoFullName) {
+		a.apiReportError(w, http.StatusForbidden, fmt.Errorf("token isn't allowed to validate the config of %s", request.Repository))
+		return
+	}
 
-	err = a.apiSetup(ctx, command.Plan)
+	configErrs := a.ParserValidator.ValidateRepoCfgData([]byte(request.RepoConfig), a.GlobalCfg, request.Repository)
+	response, err := json.Marshal(ValidateConfigResult{Valid: len(configErrs) == 0, Errors: configErrs})
 	if err != nil {
 		a.apiReportError(w, http.StatusInternalServerError, err)
 		return
 	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
 
-	result, err := a.apiPlan(request, ctx)
+// ConfigSchema responds the JSON Schema of the repo-level atlantis.yaml.
+func (a *APIController) ConfigSchema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/schema+json")
+
+	if _, code, err := a.apiAuthenticate(r, models.APITokenScopeReadOnly); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	response, err := json.MarshalIndent(raw.RepoCfgSchema(), "", "  ")
 	if err != nil {
 		a.apiReportError(w, http.StatusInternalServerError, err)
 		return
 	}
-	defer a.Locker.UnlockByPull(ctx.HeadRepo.FullName, ctx.Pull.Num) // nolint: errcheck
-	if result.HasErrors() {
-		code = http.StatusInternalServerError
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
+
+// ReloadConfigs reloads the server-side repo config and tenants config. It
+// responds once in-progress operations completed and the configs are reloaded.
+func (a *APIController) ReloadConfigs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticateAdmin(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	if a.ReloadConfig == nil {
+		a.apiReportError(w, http.StatusBadRequest, errors.New("config reloading isn't enabled"))
+		return
+	}
+	if err := a.ReloadConfig(); err != nil {
+		a.apiReportError(w, http.StatusBadRequest, err)
+		return
 	}
+	a.respond(w, logging.Info, http.StatusOK, "%s", `{"reloaded": true}`)
+}
 
-	// TODO: make a better response
+// Drain stops accepting new commands, so Atlantis can be restarted once the
+// in-progress operations, ex. applies, completed. With ?wait=true it responds
+// once they completed, or when the request is canceled.
+func (a *APIController) Drain(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticateAdmin(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	if a.Drainer == nil {
+		a.apiReportError(w, http.StatusBadRequest, errors.New("draining isn't enabled"))
+		return
+	}
+	if !a.Drainer.GetStatus().ShuttingDown {
+		a.Logger.Warn("draining, new commands are rejected until Atlantis restarts or resumes")
+	}
+	a.Drainer.Drain()
+	if r.URL.Query().Get("wait") == "true" {
+		select {
+		case <-a.Drainer.Drained():
+		case <-r.Context().Done():
+		}
+	}
+	a.respondDrainResult(w)
+}
+
+// Resume accepts new commands again after Drain, and runs the commands
+// deferred in the meantime.
+func (a *APIController) Resume(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticateAdmin(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	if a.Drainer == nil {
+		a.apiReportError(w, http.StatusBadRequest, errors.New("draining isn't enabled"))
+		return
+	}
+	if a.Drainer.Resume() {
+		a.Logger.Info("resumed accepting new commands")
+		if a.DeferredCommands != nil {
+			if err := a.DeferredCommands.Replay(a.CommandRunner, a.Logger); err != nil {
+				a.apiReportError(w, http.StatusInternalServerError, fmt.Errorf("running the deferred commands: %w", err))
+				return
+			}
+		}
+	}
+	a.respondDrainResult(w)
+}
+
+func (a *APIController) respondDrainResult(w http.ResponseWriter) {
+	status := a.Drainer.GetStatus()
+	result := DrainResult{Draining: status.ShuttingDown, InProgressOps: status.InProgressOps}
+	if a.DeferredCommands != nil {
+		deferred, err := a.DeferredCommands.Count()
+		if err != nil {
+			a.apiReportError(w, http.StatusInternalServerError, err)
+			return
+		}
+		result.DeferredCommands = deferred
+	}
 	response, err := json.Marshal(result)
 	if err != nil {
 		a.apiReportError(w, http.StatusInternalServerError, err)
 		return
 	}
-	a.respond(w, logging.Warn, code, "%s", string(response))
+	a.respond(w, logging.Info, http.StatusOK, "%s", string(response))
 }
 
-func (a *APIController) Apply(w http.ResponseWriter, r *http.Request) {
+// ListAPITokens lists the issued API tokens, without the tokens themselves.
+func (a *APIController) ListAPITokens(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	request, ctx, code, err := a.apiParseAndValidate(r)
-	if err != nil {
+	if code, err := a.apiAuthenticateAdmin(r); err != nil {
 		a.apiReportError(w, code, err)
 		return
 	}
-
-	err = a.apiSetup(ctx, command.Apply)
+	if a.Database == nil {
+		a.apiReportError(w, http.StatusBadRequest, errors.New("no database is configured"))
+		return
+	}
+	tokens, err := listAPITokens(a.Database)
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	response, err := json.Marshal(ListAPITokensResult{APITokens: tokens})
 	if err != nil {
 		a.apiReportError(w, http.StatusInternalServerError, err)
 		return
 	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
 
-	// We must first make the plan for all projects
-	_, err = a.apiPlan(request, ctx)
+// CreateAPIToken issues an API token from a CreateAPITokenRequest. The token
+// is only returned in the response.
+func (a *APIController) CreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticateAdmin(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	if a.Database == nil {
+		a.apiReportError(w, http.StatusBadRequest, errors.New("no database is configured"))
+		return
+	}
+	var request CreateAPITokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("failed to parse request: %v", err.Error()))
+		return
+	}
+	result, err := issueAPIToken(a.Database, request, "the API secret")
+	if err != nil {
+		a.apiReportError(w, http.StatusBadRequest, err)
+		return
+	}
+	a.Logger.Info("issued API token %s (%s) with scope %s through the API, requested from %s", result.APIToken.ID, result.APIToken.Name, result.APIToken.Scope, r.RemoteAddr)
+	response, err := json.Marshal(result)
 	if err != nil {
 		a.apiReportError(w, http.StatusInternalServerError, err)
 		return
 	}
-	defer a.Locker.UnlockByPull(ctx.HeadRepo.FullName, ctx.Pull.Num) // nolint: errcheck
+	// The response isn't logged since it contains the token.
+	w.WriteHeader(http.StatusOK)
+	w.Write(response) // nolint: errcheck
+}
 
-	// We can now prepare and run the apply step
-	result, err := a.apiApply(request, ctx)
+// RevokeAPIToken revokes the API token with the id query parameter.
+func (a *APIController) RevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticateAdmin(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	if a.Database == nil {
+		a.apiReportError(w, http.StatusBadRequest, errors.New("no database is configured"))
+		return
+	}
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		a.apiReportError(w, http.StatusBadRequest, errors.New("no token id in request"))
+		return
+	}
+	token, err := a.Database.DeleteAPIToken(id)
 	if err != nil {
 		a.apiReportError(w, http.StatusInternalServerError, err)
 		return
 	}
-	if result.HasErrors() {
-		code = http.StatusInternalServerError
+	if token == nil {
+		a.apiReportError(w, http.StatusNotFound, fmt.Errorf("no API token found with id %q", id))
+		return
 	}
-
-	response, err := json.Marshal(result)
+	a.Logger.Info("revoked API token %s (%s) through the API, requested from %s", token.ID, token.Name, r.RemoteAddr)
+	response, err := json.Marshal(map[string]string{"revoked": id})
 	if err != nil {
 		a.apiReportError(w, http.StatusInternalServerError, err)
 		return
 	}
-	a.respond(w, logging.Warn, code, "%s", string(response))
+	a.respond(w, logging.Info, http.StatusOK, "%s", string(response))
 }
 
-type LockDetail struct {
-	Name            string
-	ProjectName     string
-	ProjectRepo     string
-	ProjectRepoPath string
-	PullID          int `json:",string"`
-	PullURL         string
-	User            string
-	Workspace       string
-	Time            time.Time
-}
+// maxProfileDuration is the longest profiles may be captured for.
+const maxProfileDuration = 5 * time.Minute
 
-type ListLocksResult struct {
-	Locks []LockDetail
+// CaptureProfiles captures profiles of the server in the background. The
+// seconds query parameter is how long the CPU profile is recorded for, 30 by
+// default, and types the comma-separated types of profiles, all by default.
+func (a *APIController) CaptureProfiles(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticateAdmin(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	if a.Profiles == nil {
+		a.apiReportError(w, http.StatusBadRequest, errors.New("capturing profiles isn't enabled"))
+		return
+	}
+	duration := 30 * time.Second
+	if seconds := r.URL.Query().Get("seconds"); seconds != "" {
+		n, err := strconv.Atoi(seconds)
+		if err != nil || n <= 0 || time.Duration(n)*time.Second > maxProfileDuration {
+			a.apiReportError(w, http.StatusBadRequest, fmt.Errorf("invalid seconds %q, must be between 1 and %d", seconds, int(maxProfileDuration.Seconds())))
+			return
+		}
+		duration = time.Duration(n) * time.Second
+	}
+	types := events.ProfileTypes
+	if t := r.URL.Query().Get("types"); t != "" {
+		types = strings.Split(t, ",")
+	}
+	ids, err := a.Profiles.Capture(types, duration)
+	if errors.Is(err, events.ErrProfileCaptureInProgress) {
+		a.apiReportError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		a.apiReportError(w, http.StatusBadRequest, err)
+		return
+	}
+	a.Logger.Info("capturing profiles %s for %s, requested from %s", strings.Join(ids, ", "), duration, r.RemoteAddr)
+	response, err := json.Marshal(CaptureProfilesResult{IDs: ids})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.respond(w, logging.Info, http.StatusAccepted, "%s", string(response))
 }
 
-func (a *APIController) ListLocks(w http.ResponseWriter, r *http.Request) {
+// ListProfiles lists the captured profiles.
+func (a *APIController) ListProfiles(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	locks, err := a.Locker.List()
+	if code, err := a.apiAuthenticateAdmin(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	if a.Profiles == nil {
+		a.apiReportError(w, http.StatusBadRequest, errors.New("capturing profiles isn't enabled"))
+		return
+	}
+	profiles, err := a.Profiles.List()
 	if err != nil {
 		a.apiReportError(w, http.StatusInternalServerError, err)
 		return
 	}
-
-	result := ListLocksResult{}
-	for name, lock := range locks {
-		lockDetail := LockDetail{
-			name,
-			lock.Project.ProjectName,
-			lock.Project.RepoFullName,
-			lock.Project.Path,
-			lock.Pull.Num,
-			lock.Pull.URL,
-			lock.User.Username,
-			lock.Workspace,
-			lock.Time,
-		}
-		result.Locks = append(result.Locks, lockDetail)
+	response, err := json.Marshal(ListProfilesResult{Profiles: profiles})
+	if err != nil {
+		a.apiReportError(w, http.StatusInternalServerError, err)
+		return
 	}
+	a.respond(w, logging.Debug, http.StatusOK, "%s", string(response))
+}
 
-	response, err := json.Marshal(result)
+// Profile downloads the captured profile with the id route variable.
+func (a *APIController) Profile(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if code, err := a.apiAuthenticateAdmin(r); err != nil {
+		a.apiReportError(w, code, err)
+		return
+	}
+	if a.Profiles == nil {
+		a.apiReportError(w, http.StatusBadRequest, errors.New("capturing profiles isn't enabled"))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	profile, err := a.Profiles.Read(id)
+	if os.IsNotExist(err) {
+		a.apiReportError(w, http.StatusNotFound, fmt.Errorf("no profile found with id %q", id))
+		return
+	}
 	if err != nil {
 		a.apiReportError(w, http.StatusInternalServerError, err)
 		return
 	}
-	a.respond(w, logging.Warn, http.StatusOK, "%s", string(response))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", id+".pb.gz"))
+	a.writeArtifact(w, "application/octet-stream", profile)
+}
+
+// startOp tracks the request as an operation, if there's a drainer. It
+// returns false and responds if Atlantis is shutting down.
+func (a *APIController) startOp(w http.ResponseWriter) (func(), bool) {
+	if a.Drainer == nil {
+		return func() {}, true
+	}
+	if !a.Drainer.StartOp() {
+		a.apiReportError(w, http.StatusServiceUnavailable, errors.New("atlantis is shutting down"))
+		return nil, false
+	}
+	return a.Drainer.OpDone, true
 }
 
 func (a *APIController) apiSetup(ctx *command.Context, cmdName command.Name) error {
@@ -333,15 +1693,87 @@ func (a *APIController) apiApply(request *APIRequest, ctx *command.Context) (*co
 	return &command.Result{ProjectResults: projectResults}, nil
 }
 
-func (a *APIController) apiParseAndValidate(r *http.Request) (*APIRequest, *command.Context, int, error) {
-	if len(a.APISecret) == 0 {
-		return nil, nil, http.StatusBadRequest, fmt.Errorf("ignoring request since API is disabled")
+// apiCaller is who a request to the API is authenticated as.
+type apiCaller struct {
+	// token is the scoped token the request is authenticated with, nil if
+	// it's authenticated with the API secret, which may do everything.
+	token *models.APIToken
+}
+
+// admin returns whether the caller is authenticated with the API secret.
+func (c apiCaller) admin() bool {
+	return c.token == nil
+}
+
+// allowsRepo returns whether the caller may call the API on repoFullName.
+func (c apiCaller) allowsRepo(repoFullName string) bool {
+	return c.token == nil || c.token.AllowsRepo(repoFullName)
+}
+
+// name identifies the caller in logs.
+func (c apiCaller) name() string {
+	if c.token == nil {
+		return "the API secret"
 	}
+	return fmt.Sprintf("API token %s (%s)", c.token.ID, c.token.Name)
+}
 
-	// Validate the secret token
+// apiAuthenticate returns who the request's token authenticates, or an error
+// and the response code if the API is disabled, the token doesn't match the
+// API secret or a scoped token, or its token's scope doesn't include scope.
+func (a *APIController) apiAuthenticate(r *http.Request, scope models.APITokenScope) (apiCaller, int, error) {
 	secret := r.Header.Get(atlantisTokenHeader)
-	if secret != string(a.APISecret) {
-		return nil, nil, http.StatusUnauthorized, fmt.Errorf("header %s did not match expected secret", atlantisTokenHeader)
+	if len(a.APISecret) > 0 && subtle.ConstantTimeCompare([]byte(secret), a.APISecret) == 1 {
+		return apiCaller{}, 0, nil
+	}
+	tokens, err := a.apiTokens()
+	if err != nil {
+		return apiCaller{}, http.StatusInternalServerError, err
+	}
+	if len(a.APISecret) == 0 && len(tokens) == 0 {
+		return apiCaller{}, http.StatusBadRequest, fmt.Errorf("ignoring request since API is disabled")
+	}
+	hash := models.HashAPIToken(secret)
+	for _, token := range tokens {
+		if secret != "" && subtle.ConstantTimeCompare([]byte(hash), []byte(token.Hash)) == 1 {
+			if !token.Scope.Includes(scope) {
+				return apiCaller{}, http.StatusForbidden, fmt.Errorf("token's scope %s doesn't allow this request, it requires %s", token.Scope, scope)
+			}
+			return apiCaller{token: &token}, 0, nil
+		}
+	}
+	return apiCaller{}, http.StatusUnauthorized, fmt.Errorf("header %s did not match expected secret", atlantisTokenHeader)
+}
+
+// apiAuthenticateAdmin returns an error and the response code if the request
+// isn't authenticated with the API secret.
+func (a *APIController) apiAuthenticateAdmin(r *http.Request) (int, error) {
+	caller, code, err := a.apiAuthenticate(r, models.APITokenScopeApply)
+	if err != nil {
+		return code, err
+	}
+	if !caller.admin() {
+		return http.StatusForbidden, errors.New("only the API secret is allowed this request")
+	}
+	return 0, nil
+}
+
+// apiTokens returns the scoped API tokens, none if there's no database.
+func (a *APIController) apiTokens() ([]models.APIToken, error) {
+	if a.Database == nil {
+		return nil, nil
+	}
+	tokens, err := a.Database.ListAPITokens()
+	if err != nil {
+		return nil, fmt.Errorf("listing API tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+func (a *APIController) apiParseAndValidate(r *http.Request, scope models.APITokenScope) (*APIRequest, *command.Context, int, error) {
+	caller, code, err := a.apiAuthenticate(r, scope)
+	if err != nil {
+		return nil, nil, code, err
 	}
 
 	// Parse the JSON payload
@@ -375,6 +1807,9 @@ func (a *APIController) apiParseAndValidate(r *http.Request) (*APIRequest, *comm
 	if !a.RepoAllowlistChecker.IsAllowlisted(baseRepo.FullName, baseRepo.VCSHost.Hostname) {
 		return nil, nil, http.StatusForbidden, fmt.Errorf("repo not allowlisted")
 	}
+	if !caller.allowsRepo(baseRepo.FullName) {
+		return nil, nil, http.StatusForbidden, fmt.Errorf("token isn't allowed to %s %s", scope, baseRepo.FullName)
+	}
 
 	return &request, &command.Context{
 		HeadRepo: baseRepo,
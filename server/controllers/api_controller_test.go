@@ -6,20 +6,31 @@ package controllers_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gorilla/mux"
 	. "github.com/petergtz/pegomock/v4"
 	"github.com/runatlantis/atlantis/server/controllers"
+	"github.com/runatlantis/atlantis/server/core/boltdb"
+	"github.com/runatlantis/atlantis/server/core/config"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
 	. "github.com/runatlantis/atlantis/server/core/locking/mocks"
 	"github.com/runatlantis/atlantis/server/events"
 	"github.com/runatlantis/atlantis/server/events/command"
 	. "github.com/runatlantis/atlantis/server/events/mocks"
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/timeline"
 	. "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/jobs"
 	"github.com/runatlantis/atlantis/server/logging"
 	"github.com/runatlantis/atlantis/server/metrics/metricstest"
 	. "github.com/runatlantis/atlantis/testing"
@@ -332,6 +343,88 @@ func TestAPIController_ListLocksEmpty(t *testing.T) {
 	Equals(t, expected, result)
 }
 
+func TestAPIController_ValidateConfig(t *testing.T) {
+	ac, _, _ := setup(t)
+	ac.ParserValidator = &config.ParserValidator{}
+	ac.GlobalCfg = valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})
+
+	validate := func(repoConfig string) controllers.ValidateConfigResult {
+		body, _ := json.Marshal(controllers.ValidateConfigRequest{Repository: "github.com/owner/repo", RepoConfig: repoConfig})
+		req, _ := http.NewRequest("POST", "", bytes.NewBuffer(body))
+		req.Header.Set(atlantisTokenHeader, atlantisToken)
+		w := httptest.NewRecorder()
+		ac.ValidateConfig(w, req)
+		Equals(t, http.StatusOK, w.Result().StatusCode)
+		var result controllers.ValidateConfigResult
+		Ok(t, json.NewDecoder(w.Result().Body).Decode(&result))
+		return result
+	}
+
+	Equals(t, controllers.ValidateConfigResult{Valid: true}, validate("version: 3\nprojects:\n- dir: infra\n"))
+	Equals(t, controllers.ValidateConfigResult{Errors: []config.ConfigError{
+		{Path: "projects[0].dri", Line: 3, Message: `unknown key "dri", did you mean "dir"?`},
+	}}, validate("version: 3\nprojects:\n- dri: infra\n"))
+
+	t.Log("the schema is exported")
+	req, _ := http.NewRequest("GET", "", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w := httptest.NewRecorder()
+	ac.ConfigSchema(w, req)
+	ResponseContains(t, w, http.StatusOK, `"$schema": "https://json-schema.org/draft/2020-12/schema"`)
+}
+
+func TestAPIController_InspectConfig(t *testing.T) {
+	ac, _, _ := setup(t)
+	ac.ParserValidator = &config.ParserValidator{}
+	var err error
+	ac.GlobalCfg, err = ac.ParserValidator.ParseGlobalCfgJSON(`{
+		"orgs": [{"id": "github.com/owner", "apply_requirements": ["approved"], "allowed_overrides": ["workflow"]}],
+		"repos": [{"id": "github.com/owner/repo", "plan_requirements": ["mergeable"]}],
+		"workflows": {"custom": {}}
+	}`, valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{}))
+	Ok(t, err)
+
+	inspect := func(request controllers.InspectConfigRequest) controllers.InspectConfigResult {
+		body, _ := json.Marshal(request)
+		req, _ := http.NewRequest("POST", "", bytes.NewBuffer(body))
+		req.Header.Set(atlantisTokenHeader, atlantisToken)
+		w := httptest.NewRecorder()
+		ac.InspectConfig(w, req)
+		Equals(t, http.StatusOK, w.Result().StatusCode)
+		var result controllers.InspectConfigResult
+		Ok(t, json.NewDecoder(w.Result().Body).Decode(&result))
+		return result
+	}
+
+	t.Log("without a repo config the default project is inspected")
+	result := inspect(controllers.InspectConfigRequest{Repository: "github.com/owner/repo"})
+	Equals(t, []controllers.InspectedProject{{
+		Dir:                ".",
+		Workspace:          "default",
+		Workflow:           "default",
+		PlanRequirements:   []string{"mergeable"},
+		ApplyRequirements:  []string{"approved"},
+		ImportRequirements: []string{},
+		RepoLocks:          "on_plan",
+	}}, result.Projects)
+	Assert(t, slices.Contains(result.Trace, "[DBUG] setting apply_requirements: [approved] from org defaults, id: github.com/owner"), "exp trace of org defaults, got %v", result.Trace)
+
+	t.Log("projects of the repo config are merged")
+	result = inspect(controllers.InspectConfigRequest{
+		Repository: "github.com/owner/repo",
+		RepoConfig: "version: 3\nprojects:\n- dir: infra\n  workflow: custom\n",
+	})
+	Equals(t, 1, len(result.Projects))
+	Equals(t, "infra", result.Projects[0].Dir)
+	Equals(t, "custom", result.Projects[0].Workflow)
+
+	t.Log("the API secret is required")
+	req, _ := http.NewRequest("POST", "", bytes.NewBufferString(`{"Repository": "github.com/owner/repo"}`))
+	w := httptest.NewRecorder()
+	ac.InspectConfig(w, req)
+	Equals(t, http.StatusUnauthorized, w.Result().StatusCode)
+}
+
 func setup(t *testing.T) (controllers.APIController, *MockProjectCommandBuilder, *MockProjectCommandRunner) {
 	RegisterMockTestingT(t)
 	locker := NewMockLocker()
@@ -396,3 +489,716 @@ func setup(t *testing.T) (controllers.APIController, *MockProjectCommandBuilder,
 	}
 	return ac, projectCommandBuilder, projectCommandRunner
 }
+
+func TestAPIController_ReloadConfigs(t *testing.T) {
+	ac, _, _ := setup(t)
+	reload := func(withToken bool) int {
+		req, _ := http.NewRequest("POST", "", nil)
+		if withToken {
+			req.Header.Set(atlantisTokenHeader, atlantisToken)
+		}
+		w := httptest.NewRecorder()
+		ac.ReloadConfigs(w, req)
+		return w.Result().StatusCode
+	}
+
+	t.Log("reloading must be enabled")
+	Equals(t, http.StatusBadRequest, reload(true))
+
+	reloads := 0
+	ac.ReloadConfig = func() error {
+		reloads++
+		return nil
+	}
+	Equals(t, http.StatusOK, reload(true))
+	Equals(t, 1, reloads)
+
+	t.Log("the API secret is required")
+	Equals(t, http.StatusUnauthorized, reload(false))
+	Equals(t, 1, reloads)
+
+	t.Log("invalid configs are reported")
+	ac.ReloadConfig = func() error { return errors.New("invalid") }
+	Equals(t, http.StatusBadRequest, reload(true))
+}
+
+func TestAPIController_Drain(t *testing.T) {
+	ac, _, _ := setup(t)
+	commandRunner := NewMockCommandRunner()
+	ac.CommandRunner = commandRunner
+	ac.Drainer = &events.Drainer{}
+	ac.DeferredCommands = &events.DeferredCommands{Path: filepath.Join(t.TempDir(), "deferred-commands.json")}
+	request := func(method string, path string, withToken bool) (int, controllers.DrainResult) {
+		req, _ := http.NewRequest(method, path, nil)
+		if withToken {
+			req.Header.Set(atlantisTokenHeader, atlantisToken)
+		}
+		w := httptest.NewRecorder()
+		if method == "DELETE" {
+			ac.Resume(w, req)
+		} else {
+			ac.Drain(w, req)
+		}
+		var result controllers.DrainResult
+		json.NewDecoder(w.Result().Body).Decode(&result) // nolint: errcheck
+		return w.Result().StatusCode, result
+	}
+
+	t.Log("the API secret is required")
+	code, _ := request("POST", "/api/drain", false)
+	Equals(t, http.StatusUnauthorized, code)
+	Equals(t, false, ac.Drainer.GetStatus().ShuttingDown)
+
+	ac.Drainer.StartOp()
+	code, result := request("POST", "/api/drain", true)
+	Equals(t, http.StatusOK, code)
+	Equals(t, controllers.DrainResult{Draining: true, InProgressOps: 1}, result)
+	Equals(t, false, ac.Drainer.StartOp())
+
+	t.Log("waiting responds once the in-progress operations completed")
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		ac.Drainer.OpDone()
+	}()
+	code, result = request("POST", "/api/drain?wait=true", true)
+	Equals(t, http.StatusOK, code)
+	Equals(t, controllers.DrainResult{Draining: true}, result)
+
+	t.Log("resuming runs the deferred commands")
+	repo := models.Repo{FullName: "owner/repo"}
+	Ok(t, ac.DeferredCommands.Add(events.WorkItem{BaseRepo: repo, PullNum: 1, Command: &events.CommentCommand{Name: command.Plan}}))
+	code, result = request("DELETE", "/api/drain", true)
+	Equals(t, http.StatusOK, code)
+	Equals(t, controllers.DrainResult{}, result)
+	Equals(t, true, ac.Drainer.StartOp())
+	commandRunner.VerifyWasCalledEventually(Once(), 5*time.Second).RunCommentCommand(
+		Any[logging.SimpleLogging](), Eq(repo), Any[*models.Repo](), Any[*models.PullRequest](), Any[models.User](), Eq(1),
+		Eq(&events.CommentCommand{Name: command.Plan}))
+}
+
+func TestAPIController_DeleteLock(t *testing.T) {
+	ac, _, _ := setup(t)
+	deleteLockCommand := NewMockDeleteLockCommand()
+	ac.DeleteLockCommand = deleteLockCommand
+	When(deleteLockCommand.DeleteLock(Any[logging.SimpleLogging](), Eq("owner/repo/path/default"))).
+		ThenReturn(&models.ProjectLock{Workspace: "default", Project: models.Project{Path: "path"}}, nil)
+
+	deleteLock := func(id string, withToken bool) int {
+		req, _ := http.NewRequest("DELETE", "/api/locks?id="+id, nil)
+		if withToken {
+			req.Header.Set(atlantisTokenHeader, atlantisToken)
+		}
+		w := httptest.NewRecorder()
+		ac.DeleteLock(w, req)
+		return w.Result().StatusCode
+	}
+
+	Equals(t, http.StatusOK, deleteLock("owner/repo/path/default", true))
+	Equals(t, http.StatusNotFound, deleteLock("owner/repo/other/default", true))
+	Equals(t, http.StatusBadRequest, deleteLock("", true))
+
+	t.Log("the API secret is required")
+	Equals(t, http.StatusUnauthorized, deleteLock("owner/repo/path/default", false))
+	deleteLockCommand.VerifyWasCalledOnce().DeleteLock(Any[logging.SimpleLogging](), Eq("owner/repo/path/default"))
+}
+
+func TestAPIController_JobLogs(t *testing.T) {
+	ac, _, _ := setup(t)
+	output := make(chan *jobs.ProjectCmdOutputLine)
+	handler := jobs.NewAsyncProjectCommandOutputHandler(output, logging.NewNoopLogger(t))
+	go handler.Handle()
+	defer close(output)
+	ac.ProjectCmdOutputHandler = handler
+
+	ctx := command.ProjectContext{JobID: "job-1", RepoRelDir: "infra", Workspace: "default"}
+	handler.Send(ctx, "Initializing...", false)
+	handler.Send(ctx, "Plan: 1 to add", false)
+	handler.Send(ctx, "", true)
+	// Handle processes lines in order so the job is complete once the next
+	// line is received.
+	handler.Send(command.ProjectContext{JobID: "job-2"}, "", false)
+
+	req, _ := http.NewRequest("GET", "/api/jobs/job-1/logs", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	req = mux.SetURLVars(req, map[string]string{"job-id": "job-1"})
+	w := httptest.NewRecorder()
+	ac.JobLogs(w, req)
+	Equals(t, http.StatusOK, w.Result().StatusCode)
+	Equals(t, "Initializing...\nPlan: 1 to add\n", w.Body.String())
+
+	req = mux.SetURLVars(req, map[string]string{"job-id": "missing"})
+	w = httptest.NewRecorder()
+	ac.JobLogs(w, req)
+	Equals(t, http.StatusNotFound, w.Result().StatusCode)
+
+	req, _ = http.NewRequest("GET", "/api/jobs", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w = httptest.NewRecorder()
+	ac.ListJobs(w, req)
+	Equals(t, http.StatusOK, w.Result().StatusCode)
+	var result controllers.ListJobsResult
+	Ok(t, json.NewDecoder(w.Result().Body).Decode(&result))
+	Equals(t, 1, len(result.Jobs))
+	Equals(t, "infra", result.Jobs[0].Pull.Path)
+}
+
+func TestAPIController_PlanArtifact(t *testing.T) {
+	ac, _, _ := setup(t)
+	output := make(chan *jobs.ProjectCmdOutputLine)
+	handler := jobs.NewAsyncProjectCommandOutputHandler(output, logging.NewNoopLogger(t))
+	go handler.Handle()
+	defer close(output)
+	ac.ProjectCmdOutputHandler = handler
+	ac.ArtifactTokens = []controllers.APIArtifactToken{
+		{Token: "ci-token", RepoPattern: "owner/*"},
+		{Token: "other-token", RepoPattern: "other/*"},
+	}
+
+	repo := models.Repo{FullName: "owner/repo"}
+	pull := models.PullRequest{Num: 7, BaseRepo: repo}
+	ctx := command.ProjectContext{JobID: "job-1", BaseRepo: repo, Pull: pull, RepoRelDir: "infra", Workspace: "default"}
+	handler.Send(ctx, "Plan: 1 to add", false)
+	// Handle processes lines in order so the first job is mapped once the
+	// next line is received.
+	handler.Send(command.ProjectContext{JobID: "job-2"}, "", false)
+
+	repoDir := t.TempDir()
+	Ok(t, os.MkdirAll(filepath.Join(repoDir, "infra"), 0700))
+	Ok(t, os.WriteFile(filepath.Join(repoDir, "infra", "default.tfplan"), []byte("planfile"), 0600))
+	Ok(t, os.WriteFile(filepath.Join(repoDir, "infra", ctx.GetPlanOutputFileName()), []byte(`{"terraform_output":"full plan"}`), 0600))
+	workingDir := NewMockWorkingDir()
+	When(workingDir.GetWorkingDir(Any[models.Repo](), Any[models.PullRequest](), Eq("default"))).ThenReturn(repoDir, nil)
+	ac.WorkingDir = workingDir
+	ac.PlanJSONs = &events.PlanJSONStore{Dir: t.TempDir()}
+	Ok(t, ac.PlanJSONs.Save(ctx, `{"format_version":"1.2"}`))
+
+	download := func(jobID string, token string, format string) *http.Response {
+		req, _ := http.NewRequest("GET", "/api/jobs/"+jobID+"/artifacts/plan?format="+format, nil)
+		req.Header.Set(atlantisTokenHeader, token)
+		req = mux.SetURLVars(req, map[string]string{"job-id": jobID})
+		w := httptest.NewRecorder()
+		ac.PlanArtifact(w, req)
+		return w.Result()
+	}
+	body := func(resp *http.Response) string {
+		b, err := io.ReadAll(resp.Body)
+		Ok(t, err)
+		return string(b)
+	}
+
+	resp := download("job-1", atlantisToken, "")
+	Equals(t, http.StatusOK, resp.StatusCode)
+	Equals(t, "full plan", body(resp))
+
+	resp = download("job-1", "ci-token", "json")
+	Equals(t, http.StatusOK, resp.StatusCode)
+	Equals(t, "application/json", resp.Header.Get("Content-Type"))
+	Equals(t, `{"format_version":"1.2"}`, body(resp))
+
+	resp = download("job-1", "ci-token", "binary")
+	Equals(t, http.StatusOK, resp.StatusCode)
+	Equals(t, "application/octet-stream", resp.Header.Get("Content-Type"))
+	Equals(t, `attachment; filename="default.tfplan"`, resp.Header.Get("Content-Disposition"))
+	Equals(t, "planfile", body(resp))
+
+	t.Log("tokens are scoped to repos")
+	Equals(t, http.StatusForbidden, download("job-1", "other-token", "text").StatusCode)
+	Equals(t, http.StatusUnauthorized, download("job-1", "wrong-token", "text").StatusCode)
+	Equals(t, http.StatusUnauthorized, download("job-1", "", "text").StatusCode)
+
+	Equals(t, http.StatusNotFound, download("missing", atlantisToken, "text").StatusCode)
+	Equals(t, http.StatusBadRequest, download("job-1", atlantisToken, "yaml").StatusCode)
+
+	t.Log("artifact tokens can't use the rest of the API")
+	req, _ := http.NewRequest("GET", "/api/jobs", nil)
+	req.Header.Set(atlantisTokenHeader, "ci-token")
+	w := httptest.NewRecorder()
+	ac.ListJobs(w, req)
+	Equals(t, http.StatusUnauthorized, w.Result().StatusCode)
+}
+
+func TestAPIArtifactToken_Allows(t *testing.T) {
+	Assert(t, controllers.APIArtifactToken{Token: "t"}.Allows("owner/repo"), "exp unscoped token to allow all repos")
+	Assert(t, controllers.APIArtifactToken{Token: "t", RepoPattern: "owner/*"}.Allows("owner/repo"), "exp token to allow matching repo")
+	Assert(t, !controllers.APIArtifactToken{Token: "t", RepoPattern: "owner/*"}.Allows("other/repo"), "exp token not to allow other repo")
+}
+
+func TestAPIController_ListSummaries(t *testing.T) {
+	ac, _, _ := setup(t)
+	ac.Summaries = events.NewSummaryStore()
+	pull := models.PullRequest{Num: 7, HeadCommit: "abc123", BaseRepo: models.Repo{FullName: "owner/repo"}}
+	ac.Summaries.Add(pull, "jdoe", events.GeneratedSummary{Summary: "**1 to add.**"})
+
+	listSummaries := func(query string) (int, controllers.ListSummariesResult) {
+		req, _ := http.NewRequest("GET", "/api/summaries?"+query, nil)
+		req.Header.Set(atlantisTokenHeader, atlantisToken)
+		w := httptest.NewRecorder()
+		ac.ListSummaries(w, req)
+		var result controllers.ListSummariesResult
+		json.NewDecoder(w.Result().Body).Decode(&result) // nolint: errcheck
+		return w.Result().StatusCode, result
+	}
+
+	code, result := listSummaries("repository=owner/repo&pull=7")
+	Equals(t, http.StatusOK, code)
+	Equals(t, 1, len(result.Summaries))
+	Equals(t, "**1 to add.**", result.Summaries[0].Summary)
+	Equals(t, "abc123", result.Summaries[0].HeadCommit)
+
+	code, result = listSummaries("repository=owner/repo&pull=8")
+	Equals(t, http.StatusOK, code)
+	Equals(t, []events.StoredSummary{}, result.Summaries)
+
+	code, _ = listSummaries("repository=owner/repo")
+	Equals(t, http.StatusBadRequest, code)
+}
+
+func TestAPIController_CompareSummaryVariants(t *testing.T) {
+	ac, _, _ := setup(t)
+	database, err := boltdb.New(t.TempDir())
+	Ok(t, err)
+	ac.Database = database
+	for i, variant := range []string{"control", "concise", "concise"} {
+		Ok(t, database.SaveSummaryFeedback(models.SummaryFeedback{
+			ID:       models.NewSummaryFeedbackID("owner/repo", 1, time.Unix(int64(i), 0)),
+			Variant:  variant,
+			Model:    "model",
+			ThumbsUp: 1,
+			Latency:  time.Second,
+		}))
+	}
+
+	req, _ := http.NewRequest("GET", "/api/summaries/variants", nil)
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w := httptest.NewRecorder()
+	ac.CompareSummaryVariants(w, req)
+	Equals(t, http.StatusOK, w.Result().StatusCode)
+	var result controllers.CompareSummaryVariantsResult
+	Ok(t, json.NewDecoder(w.Result().Body).Decode(&result))
+	Equals(t, []events.SummaryVariantStats{
+		{Variant: "concise", Model: "model", Summaries: 2, ThumbsUp: 2, AverageLatency: time.Second},
+		{Variant: "control", Model: "model", Summaries: 1, ThumbsUp: 1, AverageLatency: time.Second},
+	}, result.Variants)
+}
+
+func TestAPIController_ListPullEvents(t *testing.T) {
+	ac, _, _ := setup(t)
+	ac.Timeline = timeline.NewStore()
+	ac.Timeline.Record("owner/repo", 7, timeline.Event{Type: timeline.Webhook, Description: "Received a comment with the plan command", User: "jdoe"})
+	ac.Timeline.Record("owner/repo", 7, timeline.Event{Type: timeline.Lock, Description: "Locked .", Project: "project", Workspace: "default"})
+	router := mux.NewRouter()
+	router.HandleFunc("/api/pulls/{repo:.+}/{pull:[0-9]+}/events", ac.ListPullEvents)
+
+	listEvents := func(path string) (int, controllers.ListPullEventsResult) {
+		req, _ := http.NewRequest("GET", path, nil)
+		req.Header.Set(atlantisTokenHeader, atlantisToken)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var result controllers.ListPullEventsResult
+		json.NewDecoder(w.Result().Body).Decode(&result) // nolint: errcheck
+		return w.Result().StatusCode, result
+	}
+
+	code, result := listEvents("/api/pulls/owner/repo/7/events")
+	Equals(t, http.StatusOK, code)
+	Equals(t, 2, len(result.Events))
+	Equals(t, timeline.Webhook, result.Events[0].Type)
+	Equals(t, "jdoe", result.Events[0].User)
+	Equals(t, "Locked .", result.Events[1].Description)
+	Equals(t, "project", result.Events[1].Project)
+
+	code, result = listEvents("/api/pulls/owner/repo/7/events?type=lock")
+	Equals(t, http.StatusOK, code)
+	Equals(t, 1, len(result.Events))
+	Equals(t, timeline.Lock, result.Events[0].Type)
+
+	code, result = listEvents("/api/pulls/owner/repo/8/events")
+	Equals(t, http.StatusOK, code)
+	Equals(t, []timeline.Event{}, result.Events)
+}
+
+func TestAPIController_ReplayCommand(t *testing.T) {
+	ac, _, _ := setup(t)
+	commandRunner := NewMockCommandRunner()
+	ac.CommandRunner = commandRunner
+	ac.Timeline = timeline.NewStore()
+	ac.Timeline.Record("owner/repo", 7, timeline.Event{Type: timeline.Lock, Description: "Locked ."})
+	ac.Timeline.Record("owner/repo", 7, timeline.Event{
+		Type:        timeline.Command,
+		Description: "Running the plan command",
+		User:        "jdoe",
+		Request:     &models.CommandRequest{Name: "plan", ProjectName: "project", Flags: []string{"-var=a=b"}},
+	})
+	recorded := ac.Timeline.List("owner/repo", 7)
+
+	replay := func(body string, token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/commands/replay", strings.NewReader(body))
+		req.Header.Set(atlantisTokenHeader, token)
+		w := httptest.NewRecorder()
+		ac.ReplayCommand(w, req)
+		return w
+	}
+
+	t.Log("without a database commands are replayed from the timeline")
+	w := replay(`{"Repository": "owner/repo", "Type": "Github", "PR": 7, "EventID": "`+recorded[1].ID+`"}`, atlantisToken)
+	Equals(t, http.StatusOK, w.Result().StatusCode)
+	var result controllers.ReplayCommandResult
+	Ok(t, json.NewDecoder(w.Result().Body).Decode(&result))
+	Equals(t, models.CommandRecord{ID: recorded[1].ID, Time: recorded[1].Time, Repository: "owner/repo", PullNum: 7, User: "jdoe", Request: *recorded[1].Request}, result.Command)
+	Assert(t, result.CorrelationID != "", "exp the replay to have a correlation ID")
+	commandRunner.VerifyWasCalledEventually(Once(), 5*time.Second).RunCommentCommand(
+		Any[logging.SimpleLogging](), Any[models.Repo](), Any[*models.Repo](), Any[*models.PullRequest](), Eq(models.User{Username: "jdoe"}), Eq(7),
+		Eq(&events.CommentCommand{Name: command.Plan, ProjectName: "project", Flags: []string{"-var=a=b"}}))
+
+	t.Log("only comment commands are replayed")
+	w = replay(`{"Repository": "owner/repo", "Type": "Github", "PR": 7, "EventID": "`+recorded[0].ID+`"}`, atlantisToken)
+	Equals(t, http.StatusBadRequest, w.Result().StatusCode)
+	w = replay(`{"Repository": "owner/repo", "Type": "Github", "PR": 7, "EventID": "unknown"}`, atlantisToken)
+	Equals(t, http.StatusNotFound, w.Result().StatusCode)
+
+	t.Log("the API secret is required")
+	w = replay(`{"Repository": "owner/repo", "Type": "Github", "PR": 7, "EventID": "`+recorded[1].ID+`"}`, "")
+	Equals(t, http.StatusUnauthorized, w.Result().StatusCode)
+
+	t.Log("commands are replayed from the database once they're gone from the timeline")
+	database, err := boltdb.New(t.TempDir())
+	Ok(t, err)
+	ac.Database = database
+	ac.Timeline = timeline.NewStore()
+	stored := models.CommandRecord{ID: "abc", Time: time.Now().UTC(), Repository: "owner/repo", PullNum: 7, User: "alice", Request: models.CommandRequest{Name: "apply", ProjectName: "project"}}
+	Ok(t, database.SaveCommandRecord(stored))
+	w = replay(`{"Repository": "owner/repo", "Type": "Github", "PR": 7, "EventID": "abc"}`, atlantisToken)
+	Equals(t, http.StatusOK, w.Result().StatusCode)
+	Ok(t, json.NewDecoder(w.Result().Body).Decode(&result))
+	Equals(t, stored, result.Command)
+	commandRunner.VerifyWasCalledEventually(Once(), 5*time.Second).RunCommentCommand(
+		Any[logging.SimpleLogging](), Any[models.Repo](), Any[*models.Repo](), Any[*models.PullRequest](), Eq(models.User{Username: "alice"}), Eq(7),
+		Eq(&events.CommentCommand{Name: command.Apply, ProjectName: "project"}))
+	// The command must be of the requested pull request.
+	w = replay(`{"Repository": "owner/repo", "Type": "Github", "PR": 8, "EventID": "abc"}`, atlantisToken)
+	Equals(t, http.StatusNotFound, w.Result().StatusCode)
+
+	req, _ := http.NewRequest("GET", "/api/pulls/owner/repo/7/commands", nil)
+	req = mux.SetURLVars(req, map[string]string{"repo": "owner/repo", "pull": "7"})
+	req.Header.Set(atlantisTokenHeader, atlantisToken)
+	w = httptest.NewRecorder()
+	ac.ListPullCommands(w, req)
+	Equals(t, http.StatusOK, w.Result().StatusCode)
+	var list controllers.ListPullCommandsResult
+	Ok(t, json.NewDecoder(w.Result().Body).Decode(&list))
+	Equals(t, []models.CommandRecord{stored}, list.Commands)
+}
+
+func TestAPIController_Profiles(t *testing.T) {
+	ac, _, _ := setup(t)
+	router := mux.NewRouter()
+	router.HandleFunc("/api/profiles", ac.ListProfiles).Methods("GET")
+	router.HandleFunc("/api/profiles", ac.CaptureProfiles).Methods("POST")
+	router.HandleFunc("/api/profiles/{id}", ac.Profile).Methods("GET")
+	request := func(method string, path string, withToken bool) *http.Response {
+		req, _ := http.NewRequest(method, path, nil)
+		if withToken {
+			req.Header.Set(atlantisTokenHeader, atlantisToken)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Result()
+	}
+
+	t.Log("capturing profiles must be enabled")
+	Equals(t, http.StatusBadRequest, request("POST", "/api/profiles", true).StatusCode)
+
+	ac.Profiles = &events.ProfileStore{Dir: t.TempDir(), Logger: logging.NewNoopLogger(t)}
+	Equals(t, http.StatusUnauthorized, request("POST", "/api/profiles", false).StatusCode)
+	Equals(t, http.StatusBadRequest, request("POST", "/api/profiles?seconds=3600", true).StatusCode)
+	Equals(t, http.StatusBadRequest, request("POST", "/api/profiles?types=trace", true).StatusCode)
+
+	resp := request("POST", "/api/profiles?seconds=1&types=heap", true)
+	Equals(t, http.StatusAccepted, resp.StatusCode)
+	var captured controllers.CaptureProfilesResult
+	Ok(t, json.NewDecoder(resp.Body).Decode(&captured))
+	Equals(t, 1, len(captured.IDs))
+	Equals(t, http.StatusConflict, request("POST", "/api/profiles", true).StatusCode)
+
+	var listed controllers.ListProfilesResult
+	for range 100 {
+		listed = controllers.ListProfilesResult{}
+		json.NewDecoder(request("GET", "/api/profiles", true).Body).Decode(&listed) // nolint: errcheck
+		if len(listed.Profiles) == 1 {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	Equals(t, 1, len(listed.Profiles))
+	Equals(t, captured.IDs[0], listed.Profiles[0].ID)
+	Equals(t, "heap", listed.Profiles[0].Type)
+
+	resp = request("GET", "/api/profiles/"+captured.IDs[0], true)
+	Equals(t, http.StatusOK, resp.StatusCode)
+	Equals(t, "application/octet-stream", resp.Header.Get("Content-Type"))
+	Equals(t, `attachment; filename="`+captured.IDs[0]+`.pb.gz"`, resp.Header.Get("Content-Disposition"))
+	Equals(t, http.StatusUnauthorized, request("GET", "/api/profiles/"+captured.IDs[0], false).StatusCode)
+	Equals(t, http.StatusNotFound, request("GET", "/api/profiles/20250101T000000Z-heap", true).StatusCode)
+}
+
+func TestAPIController_ListPlans(t *testing.T) {
+	ac, _, _ := setup(t)
+	listPlans := func(query string) (int, controllers.ListPlansResult) {
+		req, _ := http.NewRequest("GET", "/api/plans?"+query, nil)
+		req.Header.Set(atlantisTokenHeader, atlantisToken)
+		w := httptest.NewRecorder()
+		ac.ListPlans(w, req)
+		var result controllers.ListPlansResult
+		json.NewDecoder(w.Result().Body).Decode(&result) // nolint: errcheck
+		return w.Result().StatusCode, result
+	}
+
+	code, _ := listPlans("repository=owner/repo&pull=7")
+	Equals(t, http.StatusBadRequest, code)
+
+	ac.PlanJSONs = &events.PlanJSONStore{Dir: t.TempDir()}
+	pull := models.PullRequest{Num: 7, HeadCommit: "abc123", BaseRepo: models.Repo{FullName: "owner/repo"}}
+	for _, dir := range []string{"network", "app"} {
+		Ok(t, ac.PlanJSONs.Save(command.ProjectContext{Pull: pull, RepoRelDir: dir, Workspace: "default"}, `{"format_version":"1.2"}`))
+	}
+
+	code, result := listPlans("repository=owner/repo&pull=7")
+	Equals(t, http.StatusOK, code)
+	Equals(t, 2, len(result.Plans))
+	Equals(t, "app", result.Plans[0].RepoRelDir)
+	Equals(t, `{"format_version":"1.2"}`, string(result.Plans[0].Plan))
+
+	code, result = listPlans("repository=owner/repo&pull=7&dir=network")
+	Equals(t, http.StatusOK, code)
+	Equals(t, 1, len(result.Plans))
+	Equals(t, "network", result.Plans[0].RepoRelDir)
+	Equals(t, "abc123", result.Plans[0].HeadCommit)
+
+	code, result = listPlans("repository=owner/repo&pull=7&workspace=staging")
+	Equals(t, http.StatusOK, code)
+	Equals(t, []events.PlanJSON{}, result.Plans)
+
+	code, _ = listPlans("repository=owner/repo")
+	Equals(t, http.StatusBadRequest, code)
+}
+
+func TestAPIController_ListResourceChanges(t *testing.T) {
+	ac, _, _ := setup(t)
+	database, err := boltdb.New(t.TempDir())
+	Ok(t, err)
+	ac.Database = database
+	listChanges := func(query string) (int, controllers.ListResourceChangesResult) {
+		req, _ := http.NewRequest("GET", "/api/resource-changes?"+query, nil)
+		req.Header.Set(atlantisTokenHeader, atlantisToken)
+		w := httptest.NewRecorder()
+		ac.ListResourceChanges(w, req)
+		var result controllers.ListResourceChangesResult
+		json.NewDecoder(w.Result().Body).Decode(&result) // nolint: errcheck
+		return w.Result().StatusCode, result
+	}
+
+	appliedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	Ok(t, database.SaveResourceChanges([]models.ResourceChange{
+		{Address: "aws_security_group.web", Action: "create", Repository: "owner/repo", PullNum: 1, User: "alice", AppliedAt: appliedAt},
+		{Address: "aws_instance.web[0]", Action: "create", Repository: "owner/repo", PullNum: 1, User: "alice", AppliedAt: appliedAt},
+	}))
+	Ok(t, database.SaveResourceChanges([]models.ResourceChange{
+		{Address: "aws_security_group.web", Action: "update", Repository: "owner/repo", PullNum: 2, User: "bob", AppliedAt: appliedAt.Add(time.Hour)},
+	}))
+
+	code, result := listChanges("")
+	Equals(t, http.StatusOK, code)
+	Equals(t, 3, len(result.ResourceChanges))
+	Equals(t, 2, result.ResourceChanges[0].PullNum)
+
+	code, result = listChanges("address=aws_security_group.web&limit=1")
+	Equals(t, http.StatusOK, code)
+	Equals(t, []models.ResourceChange{
+		{Address: "aws_security_group.web", Action: "update", Repository: "owner/repo", PullNum: 2, User: "bob", AppliedAt: appliedAt.Add(time.Hour)},
+	}, result.ResourceChanges)
+
+	code, result = listChanges("address=aws_instance.web&pull=1")
+	Equals(t, http.StatusOK, code)
+	Equals(t, 1, len(result.ResourceChanges))
+	Equals(t, "aws_instance.web[0]", result.ResourceChanges[0].Address)
+
+	code, result = listChanges("since=2025-01-02T03:30:00Z")
+	Equals(t, http.StatusOK, code)
+	Equals(t, 1, len(result.ResourceChanges))
+
+	code, _ = listChanges("since=yesterday")
+	Equals(t, http.StatusBadRequest, code)
+	code, _ = listChanges("limit=0")
+	Equals(t, http.StatusBadRequest, code)
+}
+
+func TestAPIController_ApplyReports(t *testing.T) {
+	ac, _, _ := setup(t)
+	database, err := boltdb.New(t.TempDir())
+	Ok(t, err)
+	ac.Database = database
+	listReports := func(query string) (int, controllers.ListApplyReportsResult) {
+		req, _ := http.NewRequest("GET", "/api/apply-reports?"+query, nil)
+		req.Header.Set(atlantisTokenHeader, atlantisToken)
+		w := httptest.NewRecorder()
+		ac.ListApplyReports(w, req)
+		var result controllers.ListApplyReportsResult
+		json.NewDecoder(w.Result().Body).Decode(&result) // nolint: errcheck
+		return w.Result().StatusCode, result
+	}
+
+	appliedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	first := models.SignedApplyReport{
+		Report:    models.ApplyReport{ID: "1", Repository: "owner/repo", PullNum: 1, AppliedBy: "alice", AppliedAt: appliedAt, Summary: "Opens <port> 443 & 80."},
+		Signature: "hmac-sha256:00",
+	}
+	second := models.SignedApplyReport{
+		Report:    models.ApplyReport{ID: "2", Repository: "owner/repo", PullNum: 2, AppliedBy: "bob", AppliedAt: appliedAt.Add(time.Hour)},
+		Signature: "hmac-sha256:01",
+	}
+	Ok(t, database.SaveApplyReport(first))
+	Ok(t, database.SaveApplyReport(second))
+
+	code, result := listReports("")
+	Equals(t, http.StatusOK, code)
+	Equals(t, []models.SignedApplyReport{second, first}, result.ApplyReports)
+	code, result = listReports("pull=1")
+	Equals(t, http.StatusOK, code)
+	Equals(t, []models.SignedApplyReport{first}, result.ApplyReports)
+	code, result = listReports("since=2025-01-02T03:30:00Z&limit=5")
+	Equals(t, http.StatusOK, code)
+	Equals(t, []models.SignedApplyReport{second}, result.ApplyReports)
+	code, _ = listReports("limit=0")
+	Equals(t, http.StatusBadRequest, code)
+
+	downloadReport := func(id string) *http.Response {
+		req, _ := http.NewRequest("GET", "/api/apply-reports/"+id, nil)
+		req.Header.Set(atlantisTokenHeader, atlantisToken)
+		req = mux.SetURLVars(req, map[string]string{"id": id})
+		w := httptest.NewRecorder()
+		ac.ApplyReport(w, req)
+		return w.Result()
+	}
+	response := downloadReport("1")
+	Equals(t, http.StatusOK, response.StatusCode)
+	Equals(t, `attachment; filename="apply-report-1.json"`, response.Header.Get("Content-Disposition"))
+	body, err := io.ReadAll(response.Body)
+	Ok(t, err)
+	// The reports aren't HTML escaped so standard tools output the JSON
+	// that was signed.
+	Assert(t, strings.Contains(string(body), `"Summary":"Opens <port> 443 & 80."`), "unexpected report %s", body)
+	Equals(t, http.StatusNotFound, downloadReport("3").StatusCode)
+}
+
+func TestAPIController_Cancel(t *testing.T) {
+	ac, _, _ := setup(t)
+	cancellationTracker := events.NewCancellationTracker()
+	workingDirLocker := events.NewDefaultWorkingDirLocker()
+	ac.Canceller = events.NewCancelCommandRunner(nil, &events.DefaultProjectCommandRunner{
+		CancellationTracker: cancellationTracker,
+		WorkingDirLocker:    workingDirLocker,
+	}, nil, workingDirLocker, false)
+	pull := models.PullRequest{Num: 7, BaseRepo: models.Repo{FullName: "owner/repo"}}
+
+	cancel := func(query string) (int, controllers.CancelResult) {
+		req, _ := http.NewRequest("POST", "/api/cancel?"+query, nil)
+		req.Header.Set(atlantisTokenHeader, atlantisToken)
+		w := httptest.NewRecorder()
+		ac.Cancel(w, req)
+		var result controllers.CancelResult
+		json.NewDecoder(w.Result().Body).Decode(&result) // nolint: errcheck
+		return w.Result().StatusCode, result
+	}
+
+	code, result := cancel("repository=owner/repo&pull=7")
+	Equals(t, http.StatusOK, code)
+	Equals(t, 0, result.Interrupted)
+	Assert(t, cancellationTracker.IsCancelled(pull), "expected the pull request to be cancelled")
+
+	code, _ = cancel("repository=owner/repo")
+	Equals(t, http.StatusBadRequest, code)
+}
+
+func TestAPIController_ManageAPITokens(t *testing.T) {
+	ac, _, _ := setup(t)
+	database, err := boltdb.New(t.TempDir())
+	Ok(t, err)
+	ac.Database = database
+	send := func(method string, query string, body string, token string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, "/api/tokens?"+query, strings.NewReader(body))
+		req.Header.Set(atlantisTokenHeader, token)
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	w := send("POST", "", `{"Name": "ci", "Scope": "plan", "Repos": ["owner/*"]}`, atlantisToken, ac.CreateAPIToken)
+	Equals(t, http.StatusOK, w.Result().StatusCode)
+	var created controllers.CreateAPITokenResult
+	Ok(t, json.NewDecoder(w.Result().Body).Decode(&created))
+	Assert(t, strings.HasPrefix(created.Token, "atlantis_"), "unexpected token %q", created.Token)
+	Equals(t, models.APITokenScopePlan, created.APIToken.Scope)
+	Equals(t, "", created.APIToken.Hash)
+
+	w = send("POST", "", `{"Name": "ci", "Scope": "admin"}`, atlantisToken, ac.CreateAPIToken)
+	Equals(t, http.StatusBadRequest, w.Result().StatusCode)
+
+	// Scoped tokens can't manage tokens.
+	w = send("GET", "", "", created.Token, ac.ListAPITokens)
+	Equals(t, http.StatusForbidden, w.Result().StatusCode)
+
+	w = send("GET", "", "", atlantisToken, ac.ListAPITokens)
+	Equals(t, http.StatusOK, w.Result().StatusCode)
+	var listed controllers.ListAPITokensResult
+	Ok(t, json.NewDecoder(w.Result().Body).Decode(&listed))
+	Equals(t, []models.APIToken{created.APIToken}, listed.APITokens)
+
+	w = send("DELETE", "id="+created.APIToken.ID, "", atlantisToken, ac.RevokeAPIToken)
+	Equals(t, http.StatusOK, w.Result().StatusCode)
+	w = send("DELETE", "id="+created.APIToken.ID, "", atlantisToken, ac.RevokeAPIToken)
+	Equals(t, http.StatusNotFound, w.Result().StatusCode)
+
+	// Revoked tokens are rejected.
+	w = send("GET", "repository=owner/repo&pull=1", "", created.Token, ac.ListSummaries)
+	Equals(t, http.StatusUnauthorized, w.Result().StatusCode)
+}
+
+func TestAPIController_ScopedAPITokens(t *testing.T) {
+	ac, _, _ := setup(t)
+	ac.APISecret = nil
+	database, err := boltdb.New(t.TempDir())
+	Ok(t, err)
+	ac.Database = database
+	ac.Summaries = events.NewSummaryStore()
+	Ok(t, database.SaveAPIToken(models.APIToken{ID: "1", Name: "dashboard", Hash: models.HashAPIToken("read-token"), Scope: models.APITokenScopeReadOnly, Repos: []string{"owner/*"}}))
+	Ok(t, database.SaveAPIToken(models.APIToken{ID: "2", Name: "ci", Hash: models.HashAPIToken("plan-token"), Scope: models.APITokenScopePlan}))
+
+	listSummaries := func(token string, repository string) int {
+		req, _ := http.NewRequest("GET", "/api/summaries?pull=1&repository="+repository, nil)
+		req.Header.Set(atlantisTokenHeader, token)
+		w := httptest.NewRecorder()
+		ac.ListSummaries(w, req)
+		return w.Result().StatusCode
+	}
+	Equals(t, http.StatusOK, listSummaries("read-token", "owner/repo"))
+	Equals(t, http.StatusForbidden, listSummaries("read-token", "other/repo"))
+	Equals(t, http.StatusOK, listSummaries("plan-token", "other/repo"))
+	Equals(t, http.StatusUnauthorized, listSummaries("wrong-token", "owner/repo"))
+
+	cancel := func(token string) int {
+		req, _ := http.NewRequest("POST", "/api/cancel?repository=owner/repo&pull=1", nil)
+		req.Header.Set(atlantisTokenHeader, token)
+		w := httptest.NewRecorder()
+		ac.Cancel(w, req)
+		return w.Result().StatusCode
+	}
+	Equals(t, http.StatusForbidden, cancel("read-token"))
+	Equals(t, http.StatusForbidden, cancel("plan-token"))
+
+	// The config can only be reloaded with the API secret.
+	req, _ := http.NewRequest("POST", "/api/config/reload", nil)
+	req.Header.Set(atlantisTokenHeader, "plan-token")
+	w := httptest.NewRecorder()
+	ac.ReloadConfigs(w, req)
+	Equals(t, http.StatusForbidden, w.Result().StatusCode)
+}
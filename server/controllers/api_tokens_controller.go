@@ -0,0 +1,212 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/controllers/web_templates"
+	"github.com/runatlantis/atlantis/server/core/db"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/webauth"
+)
+
+// apiTokenPrefix prefixes the issued API tokens so they're recognizable, ex.
+// by secret scanners.
+const apiTokenPrefix = "atlantis_"
+
+// CreateAPITokenRequest issues an API token.
+type CreateAPITokenRequest struct {
+	// Name describes what the token is used for.
+	Name  string
+	Scope models.APITokenScope
+	// Repos are patterns matching the full names of the repos the token may
+	// be used on, ex. owner/*. Empty to allow all repos.
+	Repos []string
+}
+
+type CreateAPITokenResult struct {
+	// Token is the issued token. It's only returned when it's issued.
+	Token    string
+	APIToken models.APIToken
+}
+
+type ListAPITokensResult struct {
+	APITokens []models.APIToken
+}
+
+// issueAPIToken validates request and stores a new token, which is returned
+// along with the token itself.
+func issueAPIToken(database db.Database, request CreateAPITokenRequest, createdBy string) (CreateAPITokenResult, error) {
+	if strings.TrimSpace(request.Name) == "" {
+		return CreateAPITokenResult{}, errors.New("name is required")
+	}
+	if err := request.Scope.Validate(); err != nil {
+		return CreateAPITokenResult{}, err
+	}
+	for _, pattern := range request.Repos {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			return CreateAPITokenResult{}, fmt.Errorf("invalid repo pattern %q", pattern)
+		}
+	}
+
+	secret := make([]byte, 32)
+	id := make([]byte, 8)
+	if _, err := rand.Read(secret); err != nil {
+		return CreateAPITokenResult{}, err
+	}
+	if _, err := rand.Read(id); err != nil {
+		return CreateAPITokenResult{}, err
+	}
+	token := apiTokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	apiToken := models.APIToken{
+		ID:        hex.EncodeToString(id),
+		Name:      request.Name,
+		Hash:      models.HashAPIToken(token),
+		Scope:     request.Scope,
+		Repos:     request.Repos,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+	if err := database.SaveAPIToken(apiToken); err != nil {
+		return CreateAPITokenResult{}, fmt.Errorf("saving API token: %w", err)
+	}
+	return CreateAPITokenResult{Token: token, APIToken: withoutHash(apiToken)}, nil
+}
+
+// listAPITokens returns the API tokens without their hashes, the most
+// recently issued first.
+func listAPITokens(database db.Database) ([]models.APIToken, error) {
+	tokens, err := database.ListAPITokens()
+	if err != nil {
+		return nil, fmt.Errorf("listing API tokens: %w", err)
+	}
+	result := []models.APIToken{}
+	for _, token := range tokens {
+		result = append(result, withoutHash(token))
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	return result, nil
+}
+
+// withoutHash returns token without its hash, which isn't returned by the
+// API or shown in the UI.
+func withoutHash(token models.APIToken) models.APIToken {
+	token.Hash = ""
+	return token
+}
+
+// APITokensController lets the users of the UI issue and revoke API tokens.
+// It requires web authentication so only authenticated users manage tokens.
+type APITokensController struct {
+	AtlantisVersion string
+	AtlantisURL     *url.URL
+	Database        db.Database
+	Logger          logging.SimpleLogging
+	Template        web_templates.TemplateWriter
+	// WebAuthentication is whether the UI requires authentication.
+	WebAuthentication bool
+}
+
+// Get renders the API tokens page.
+func (c *APITokensController) Get(w http.ResponseWriter, _ *http.Request) {
+	data := web_templates.APITokensData{
+		Enabled:         c.WebAuthentication,
+		AtlantisVersion: c.AtlantisVersion,
+		CleanedBasePath: c.AtlantisURL.Path,
+	}
+	if c.WebAuthentication {
+		tokens, err := listAPITokens(c.Database)
+		if err != nil {
+			c.respond(w, logging.Error, http.StatusInternalServerError, "Failed listing API tokens: %s", err)
+			return
+		}
+		for _, token := range tokens {
+			data.APITokens = append(data.APITokens, web_templates.APITokenData{
+				ID:                 token.ID,
+				Name:               token.Name,
+				Scope:              string(token.Scope),
+				Repos:              strings.Join(token.Repos, ", "),
+				CreatedBy:          token.CreatedBy,
+				CreatedAtFormatted: token.CreatedAt.Format("2006-01-02 15:04:05"),
+			})
+		}
+	}
+	if err := c.Template.Execute(w, data); err != nil {
+		c.Logger.Err(err.Error())
+	}
+}
+
+// Create issues an API token from the JSON CreateAPITokenRequest body. JSON is
+// required so cross-site forms can't issue tokens.
+func (c *APITokensController) Create(w http.ResponseWriter, r *http.Request) {
+	if !c.WebAuthentication {
+		c.respond(w, logging.Warn, http.StatusForbidden, "Managing API tokens from the UI requires --web-basic-auth or --web-oidc-issuer-url")
+		return
+	}
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		c.respond(w, logging.Warn, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+		return
+	}
+	var request CreateAPITokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		c.respond(w, logging.Warn, http.StatusBadRequest, "Failed to parse request: %s", err)
+		return
+	}
+	user := webauth.Username(r)
+	result, err := issueAPIToken(c.Database, request, user)
+	if err != nil {
+		c.respond(w, logging.Warn, http.StatusBadRequest, "Failed issuing API token: %s", err)
+		return
+	}
+	c.Logger.Info("issued API token %s (%s) with scope %s, requested by %s from %s", result.APIToken.ID, result.APIToken.Name, result.APIToken.Scope, user, r.RemoteAddr)
+	response, err := json.Marshal(result)
+	if err != nil {
+		c.respond(w, logging.Error, http.StatusInternalServerError, "%s", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response) // nolint: errcheck
+}
+
+// Revoke revokes the API token with the id query parameter.
+func (c *APITokensController) Revoke(w http.ResponseWriter, r *http.Request) {
+	if !c.WebAuthentication {
+		c.respond(w, logging.Warn, http.StatusForbidden, "Managing API tokens from the UI requires --web-basic-auth or --web-oidc-issuer-url")
+		return
+	}
+	id := r.URL.Query().Get("id")
+	token, err := c.Database.DeleteAPIToken(id)
+	if err != nil {
+		c.respond(w, logging.Error, http.StatusInternalServerError, "Failed revoking API token: %s", err)
+		return
+	}
+	if token == nil {
+		c.respond(w, logging.Warn, http.StatusNotFound, "No API token with id %q", id)
+		return
+	}
+	user := webauth.Username(r)
+	c.respond(w, logging.Info, http.StatusOK, "Revoked API token %s (%s), requested by %s from %s", token.ID, token.Name, user, r.RemoteAddr)
+}
+
+func (c *APITokensController) respond(w http.ResponseWriter, lvl logging.LogLevel, responseCode int, format string, args ...any) {
+	response := fmt.Sprintf(format, args...)
+	c.Logger.Log(lvl, response)
+	w.WriteHeader(responseCode)
+	fmt.Fprintln(w, response)
+}
@@ -0,0 +1,91 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/controllers"
+	"github.com/runatlantis/atlantis/server/controllers/web_templates"
+	"github.com/runatlantis/atlantis/server/core/boltdb"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/webauth"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestAPITokensController(t *testing.T) {
+	database, err := boltdb.New(t.TempDir())
+	Ok(t, err)
+	c := controllers.APITokensController{
+		AtlantisURL:       &url.URL{},
+		Database:          database,
+		Logger:            logging.NewNoopLogger(t),
+		Template:          web_templates.APITokensTemplate,
+		WebAuthentication: true,
+	}
+	create := func(contentType string, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api-tokens", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req = req.WithContext(webauth.NewContext(req.Context(), &webauth.User{Name: "admin"}))
+		w := httptest.NewRecorder()
+		c.Create(w, req)
+		return w
+	}
+
+	w := create("application/x-www-form-urlencoded", `{"Name": "ci", "Scope": "read-only"}`)
+	Equals(t, http.StatusUnsupportedMediaType, w.Result().StatusCode)
+
+	w = create("application/json", `{"Name": "ci", "Scope": "read-only", "Repos": ["owner/repo"]}`)
+	Equals(t, http.StatusOK, w.Result().StatusCode)
+	var result controllers.CreateAPITokenResult
+	Ok(t, json.NewDecoder(w.Result().Body).Decode(&result))
+	Equals(t, "admin", result.APIToken.CreatedBy)
+	tokens, err := database.ListAPITokens()
+	Ok(t, err)
+	Equals(t, 1, len(tokens))
+	Equals(t, models.HashAPIToken(result.Token), tokens[0].Hash)
+
+	req, _ := http.NewRequest("GET", "/api-tokens", nil)
+	w = httptest.NewRecorder()
+	c.Get(w, req)
+	Equals(t, http.StatusOK, w.Result().StatusCode)
+	Assert(t, strings.Contains(w.Body.String(), "owner/repo"), "exp the token's repos to be shown")
+	Assert(t, !strings.Contains(w.Body.String(), result.Token), "exp the token not to be shown")
+
+	req, _ = http.NewRequest("DELETE", "/api-tokens?id="+result.APIToken.ID, nil)
+	w = httptest.NewRecorder()
+	c.Revoke(w, req)
+	Equals(t, http.StatusOK, w.Result().StatusCode)
+	tokens, err = database.ListAPITokens()
+	Ok(t, err)
+	Equals(t, 0, len(tokens))
+}
+
+func TestAPITokensController_RequiresWebAuthentication(t *testing.T) {
+	database, err := boltdb.New(t.TempDir())
+	Ok(t, err)
+	c := controllers.APITokensController{
+		AtlantisURL: &url.URL{},
+		Database:    database,
+		Logger:      logging.NewNoopLogger(t),
+		Template:    web_templates.APITokensTemplate,
+	}
+	req, _ := http.NewRequest("POST", "/api-tokens", strings.NewReader(`{"Name": "ci", "Scope": "read-only"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c.Create(w, req)
+	Equals(t, http.StatusForbidden, w.Result().StatusCode)
+
+	req, _ = http.NewRequest("GET", "/api-tokens", nil)
+	w = httptest.NewRecorder()
+	c.Get(w, req)
+	Equals(t, http.StatusOK, w.Result().StatusCode)
+	Assert(t, strings.Contains(w.Body.String(), "requires <code>--web-basic-auth</code>"), "exp web basic auth to be required")
+}
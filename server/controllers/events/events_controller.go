@@ -14,6 +14,7 @@
 package events
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -23,16 +24,20 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/drmaxgit/go-azuredevops/azuredevops"
 	"github.com/google/go-github/v71/github"
 	"github.com/microcosm-cc/bluemonday"
 	"github.com/runatlantis/atlantis/server/events"
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/timeline"
 	"github.com/runatlantis/atlantis/server/events/vcs"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketcloud"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketserver"
+	"github.com/runatlantis/atlantis/server/events/vcs/codecommit"
 	"github.com/runatlantis/atlantis/server/events/vcs/common"
+	"github.com/runatlantis/atlantis/server/events/vcs/gerrit"
 	"github.com/runatlantis/atlantis/server/events/vcs/gitea"
 	"github.com/runatlantis/atlantis/server/logging"
 	tally "github.com/uber-go/tally/v4"
@@ -54,6 +59,14 @@ const bitbucketCloudRequestIDHeader = "X-Request-UUID"
 const bitbucketServerRequestIDHeader = "X-Request-ID"
 const bitbucketSignatureHeader = "X-Hub-Signature"
 
+// snsMessageTypeHeader is set by SNS on the messages it sends to HTTP
+// subscriptions.
+const snsMessageTypeHeader = "X-Amz-Sns-Message-Type"
+
+// eventBridgeUserAgent is the user agent of the requests of EventBridge API
+// destinations.
+const eventBridgeUserAgent = "Amazon/EventBridge/ApiDestinations"
+
 // The URL used for Azure DevOps test webhooks
 const azuredevopsTestURL = "https://fabrikam.visualstudio.com/DefaultCollection/_apis/git/repositories/4bc14d40-c903-45e2-872e-0462c7748079"
 
@@ -103,64 +116,134 @@ type VCSEventsController struct {
 	AzureDevopsWebhookBasicPassword []byte
 	AzureDevopsRequestValidator     AzureDevopsRequestValidator `validate:"required"`
 	GiteaWebhookSecret              []byte
+	// GerritWebhookSecret is the password of the basic auth of the requests
+	// of Gerrit's webhooks plugin that identifies them as coming from Gerrit.
+	// If empty, no request validation is done.
+	GerritWebhookSecret []byte
+	// CodeCommitWebhookSecret is the password of the basic auth of the
+	// requests of EventBridge API destinations or SNS subscriptions that
+	// identifies them as coming from CodeCommit. If empty, no request
+	// validation is done.
+	CodeCommitWebhookSecret []byte
+	// CodeCommitClient fetches the content of the comments of CodeCommit
+	// comment events and confirms SNS subscriptions. It's nil unless
+	// CodeCommit is supported.
+	CodeCommitClient *codecommit.Client
+	// BaseBranchReplanner plans pull requests again when their base branch
+	// advances. It's nil if GitHub push events are ignored.
+	BaseBranchReplanner *events.BaseBranchReplanner
+	// Timeline records the webhooks received for pull requests. It may be
+	// nil.
+	Timeline *timeline.Store
+	// History keeps the most recently received webhooks to inspect and
+	// replay them. It may be nil.
+	History *WebhookHistory
+}
+
+// webhookSecretsMutex guards the webhook secrets of the controllers while
+// they're rotated.
+var webhookSecretsMutex sync.RWMutex
+
+// secret returns *field, one of the controller's webhook secrets.
+func (e *VCSEventsController) secret(field *[]byte) []byte {
+	webhookSecretsMutex.RLock()
+	defer webhookSecretsMutex.RUnlock()
+	return *field
+}
+
+// RotateSecret replaces *field, one of the controller's webhook secrets, with
+// secret while webhooks are being handled, ex.
+// e.RotateSecret(&e.GithubWebhookSecret, secret).
+func (e *VCSEventsController) RotateSecret(field *[]byte, secret []byte) {
+	webhookSecretsMutex.Lock()
+	defer webhookSecretsMutex.Unlock()
+	*field = secret
 }
 
 // Post handles POST webhook requests.
 func (e *VCSEventsController) Post(w http.ResponseWriter, r *http.Request) {
+	if e.History != nil {
+		e.handleWithHistory(w, r, "", e.post)
+		return
+	}
+	e.post(w, r)
+}
+
+func (e *VCSEventsController) post(w http.ResponseWriter, r *http.Request) {
+	// Everything logged for the webhook, and for the commands it runs, is
+	// correlated by an ID that's returned to the VCS host too.
+	correlationID := logging.NewCorrelationID()
+	w.Header().Set(logging.CorrelationIDHeader, correlationID)
+	logger := e.Logger.With(logging.CorrelationIDKey, correlationID)
+
 	if r.Header.Get(giteaHeader) != "" {
 		if !e.supportsHost(models.Gitea) {
-			e.respond(w, logging.Debug, http.StatusBadRequest, "Ignoring request since not configured to support Gitea")
+			e.respond(logger, w, logging.Debug, http.StatusBadRequest, "Ignoring request since not configured to support Gitea")
 			return
 		}
-		e.Logger.Debug("handling Gitea post")
-		e.handleGiteaPost(w, r)
+		logger.Debug("handling Gitea post")
+		e.handleGiteaPost(logger, w, r)
 		return
 	} else if r.Header.Get(githubHeader) != "" {
 		if !e.supportsHost(models.Github) {
-			e.respond(w, logging.Debug, http.StatusBadRequest, "Ignoring request since not configured to support GitHub")
+			e.respond(logger, w, logging.Debug, http.StatusBadRequest, "Ignoring request since not configured to support GitHub")
 			return
 		}
-		e.Logger.Debug("handling GitHub post")
-		e.handleGithubPost(w, r)
+		logger.Debug("handling GitHub post")
+		e.handleGithubPost(logger, w, r)
 		return
 	} else if r.Header.Get(gitlabHeader) != "" {
 		if !e.supportsHost(models.Gitlab) {
-			e.respond(w, logging.Debug, http.StatusBadRequest, "Ignoring request since not configured to support GitLab")
+			e.respond(logger, w, logging.Debug, http.StatusBadRequest, "Ignoring request since not configured to support GitLab")
 			return
 		}
-		e.Logger.Debug("handling GitLab post")
-		e.handleGitlabPost(w, r)
+		logger.Debug("handling GitLab post")
+		e.handleGitlabPost(logger, w, r)
 		return
 	} else if r.Header.Get(bitbucketEventTypeHeader) != "" {
 		// Bitbucket Cloud and Server use the same event type header but they
 		// use different request ID headers.
 		if r.Header.Get(bitbucketCloudRequestIDHeader) != "" {
 			if !e.supportsHost(models.BitbucketCloud) {
-				e.respond(w, logging.Debug, http.StatusBadRequest, "Ignoring request since not configured to support Bitbucket Cloud")
+				e.respond(logger, w, logging.Debug, http.StatusBadRequest, "Ignoring request since not configured to support Bitbucket Cloud")
 				return
 			}
-			e.Logger.Debug("handling Bitbucket Cloud post")
-			e.handleBitbucketCloudPost(w, r)
+			logger.Debug("handling Bitbucket Cloud post")
+			e.handleBitbucketCloudPost(logger, w, r)
 			return
 		} else if r.Header.Get(bitbucketServerRequestIDHeader) != "" {
 			if !e.supportsHost(models.BitbucketServer) {
-				e.respond(w, logging.Debug, http.StatusBadRequest, "Ignoring request since not configured to support Bitbucket Server")
+				e.respond(logger, w, logging.Debug, http.StatusBadRequest, "Ignoring request since not configured to support Bitbucket Server")
 				return
 			}
-			e.Logger.Debug("handling Bitbucket Server post")
-			e.handleBitbucketServerPost(w, r)
+			logger.Debug("handling Bitbucket Server post")
+			e.handleBitbucketServerPost(logger, w, r)
 			return
 		}
 	} else if r.Header.Get(azuredevopsHeader) != "" {
 		if !e.supportsHost(models.AzureDevops) {
-			e.respond(w, logging.Debug, http.StatusBadRequest, "Ignoring request since not configured to support AzureDevops")
+			e.respond(logger, w, logging.Debug, http.StatusBadRequest, "Ignoring request since not configured to support AzureDevops")
+			return
+		}
+		logger.Debug("handling AzureDevops post")
+		e.handleAzureDevopsPost(logger, w, r)
+		return
+	} else if r.Header.Get(snsMessageTypeHeader) != "" || strings.HasPrefix(r.UserAgent(), eventBridgeUserAgent) {
+		if !e.supportsHost(models.CodeCommit) {
+			e.respond(logger, w, logging.Debug, http.StatusBadRequest, "Ignoring request since not configured to support CodeCommit")
 			return
 		}
-		e.Logger.Debug("handling AzureDevops post")
-		e.handleAzureDevopsPost(w, r)
+		logger.Debug("handling CodeCommit post")
+		e.handleCodeCommitPost(logger, w, r)
+		return
+	} else if e.supportsHost(models.Gerrit) {
+		// Gerrit's webhooks plugin doesn't identify its requests with a
+		// header so we fall back to it.
+		logger.Debug("handling Gerrit post")
+		e.handleGerritPost(logger, w, r)
 		return
 	}
-	e.respond(w, logging.Debug, http.StatusBadRequest, "Ignoring request")
+	e.respond(logger, w, logging.Debug, http.StatusBadRequest, "Ignoring request")
 }
 
 type HTTPError struct {
@@ -174,16 +257,16 @@ type HTTPResponse struct {
 	err  HTTPError
 }
 
-func (e *VCSEventsController) handleGithubPost(w http.ResponseWriter, r *http.Request) {
+func (e *VCSEventsController) handleGithubPost(logger logging.SimpleLogging, w http.ResponseWriter, r *http.Request) {
 	// Validate the request against the optional webhook secret.
-	payload, err := e.GithubRequestValidator.Validate(r, e.GithubWebhookSecret)
+	payload, err := e.GithubRequestValidator.Validate(r, e.secret(&e.GithubWebhookSecret))
 	if err != nil {
-		e.respond(w, logging.Warn, http.StatusBadRequest, "%s", err.Error())
+		e.respond(logger, w, logging.Warn, http.StatusBadRequest, "%s", err.Error())
 		return
 	}
 
 	githubReqID := "X-Github-Delivery=" + html.EscapeString(r.Header.Get("X-Github-Delivery"))
-	logger := e.Logger.With("gh-request-id", githubReqID)
+	logger = logger.With("gh-request-id", githubReqID)
 	scope := e.Scope.SubScope("github_event")
 
 	logger.Debug("request valid")
@@ -201,6 +284,9 @@ func (e *VCSEventsController) handleGithubPost(w http.ResponseWriter, r *http.Re
 		resp = e.HandleGithubPullRequestEvent(logger, event, githubReqID)
 		scope = scope.SubScope(fmt.Sprintf("pr_%s", *event.Action))
 		scope = common.SetGitScopeTags(scope, event.GetRepo().GetFullName(), event.GetNumber())
+	case *github.PushEvent:
+		resp = e.HandleGithubPushEvent(logger, event, githubReqID)
+		scope = scope.SubScope("push")
 	default:
 		resp = HTTPResponse{
 			body: fmt.Sprintf("Ignoring unsupported event %s", githubReqID),
@@ -222,100 +308,100 @@ func (e *VCSEventsController) handleGithubPost(w http.ResponseWriter, r *http.Re
 	fmt.Fprintln(w, resp.body)
 }
 
-func (e *VCSEventsController) handleBitbucketCloudPost(w http.ResponseWriter, r *http.Request) {
+func (e *VCSEventsController) handleBitbucketCloudPost(logger logging.SimpleLogging, w http.ResponseWriter, r *http.Request) {
 	eventType := r.Header.Get(bitbucketEventTypeHeader)
 	reqID := r.Header.Get(bitbucketCloudRequestIDHeader)
 	sig := r.Header.Get(bitbucketSignatureHeader)
 	defer r.Body.Close() // nolint: errcheck
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		e.respond(w, logging.Error, http.StatusBadRequest, "Unable to read body: %s %s=%s", err, bitbucketCloudRequestIDHeader, reqID)
+		e.respond(logger, w, logging.Error, http.StatusBadRequest, "Unable to read body: %s %s=%s", err, bitbucketCloudRequestIDHeader, reqID)
 		return
 	}
-	if len(e.BitbucketWebhookSecret) > 0 {
-		if err := common.ValidateSignature(body, sig, e.BitbucketWebhookSecret); err != nil {
-			e.respond(w, logging.Warn, http.StatusBadRequest, "%s", fmt.Errorf("request did not pass validation: %w", err).Error())
+	if secret := e.secret(&e.BitbucketWebhookSecret); len(secret) > 0 {
+		if err := common.ValidateSignature(body, sig, secret); err != nil {
+			e.respond(logger, w, logging.Warn, http.StatusBadRequest, "%s", fmt.Errorf("request did not pass validation: %w", err).Error())
 			return
 		}
 	}
 	switch eventType {
 	case bitbucketcloud.PullCreatedHeader, bitbucketcloud.PullUpdatedHeader, bitbucketcloud.PullFulfilledHeader, bitbucketcloud.PullRejectedHeader:
-		e.Logger.Debug("handling as pull request state changed event")
-		e.handleBitbucketCloudPullRequestEvent(e.Logger, w, eventType, body, reqID)
+		logger.Debug("handling as pull request state changed event")
+		e.handleBitbucketCloudPullRequestEvent(logger, w, eventType, body, reqID)
 		return
 	case bitbucketcloud.PullCommentCreatedHeader:
-		e.Logger.Debug("handling as comment created event")
-		e.HandleBitbucketCloudCommentEvent(w, body, reqID)
+		logger.Debug("handling as comment created event")
+		e.HandleBitbucketCloudCommentEvent(logger, w, body, reqID)
 		return
 	default:
-		e.respond(w, logging.Debug, http.StatusOK, "Ignoring unsupported event type %s %s=%s", eventType, bitbucketCloudRequestIDHeader, reqID)
+		e.respond(logger, w, logging.Debug, http.StatusOK, "Ignoring unsupported event type %s %s=%s", eventType, bitbucketCloudRequestIDHeader, reqID)
 	}
 }
 
-func (e *VCSEventsController) handleBitbucketServerPost(w http.ResponseWriter, r *http.Request) {
+func (e *VCSEventsController) handleBitbucketServerPost(logger logging.SimpleLogging, w http.ResponseWriter, r *http.Request) {
 	eventType := r.Header.Get(bitbucketEventTypeHeader)
 	reqID := r.Header.Get(bitbucketServerRequestIDHeader)
 	sig := r.Header.Get(bitbucketSignatureHeader)
 	defer r.Body.Close() // nolint: errcheck
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		e.respond(w, logging.Error, http.StatusBadRequest, "Unable to read body: %s %s=%s", err, bitbucketServerRequestIDHeader, reqID)
+		e.respond(logger, w, logging.Error, http.StatusBadRequest, "Unable to read body: %s %s=%s", err, bitbucketServerRequestIDHeader, reqID)
 		return
 	}
 	if eventType == bitbucketserver.DiagnosticsPingHeader {
 		// Specially handle the diagnostics:ping event because Bitbucket Server
 		// doesn't send the signature with this event for some reason.
-		e.respond(w, logging.Info, http.StatusOK, "Successfully received %s event %s=%s", eventType, bitbucketServerRequestIDHeader, reqID)
+		e.respond(logger, w, logging.Info, http.StatusOK, "Successfully received %s event %s=%s", eventType, bitbucketServerRequestIDHeader, reqID)
 		return
 	}
-	if len(e.BitbucketWebhookSecret) > 0 {
-		if err := common.ValidateSignature(body, sig, e.BitbucketWebhookSecret); err != nil {
-			e.respond(w, logging.Warn, http.StatusBadRequest, "%s", fmt.Errorf("request did not pass validation: %w", err).Error())
+	if secret := e.secret(&e.BitbucketWebhookSecret); len(secret) > 0 {
+		if err := common.ValidateSignature(body, sig, secret); err != nil {
+			e.respond(logger, w, logging.Warn, http.StatusBadRequest, "%s", fmt.Errorf("request did not pass validation: %w", err).Error())
 			return
 		}
 	}
 	switch eventType {
 	case bitbucketserver.PullCreatedHeader, bitbucketserver.PullFromRefUpdatedHeader, bitbucketserver.PullMergedHeader, bitbucketserver.PullDeclinedHeader, bitbucketserver.PullDeletedHeader:
-		e.Logger.Debug("handling as pull request state changed event")
-		e.handleBitbucketServerPullRequestEvent(e.Logger, w, eventType, body, reqID)
+		logger.Debug("handling as pull request state changed event")
+		e.handleBitbucketServerPullRequestEvent(logger, w, eventType, body, reqID)
 		return
 	case bitbucketserver.PullCommentCreatedHeader:
-		e.Logger.Debug("handling as comment created event")
-		e.HandleBitbucketServerCommentEvent(w, body, reqID)
+		logger.Debug("handling as comment created event")
+		e.HandleBitbucketServerCommentEvent(logger, w, body, reqID)
 		return
 	default:
-		e.respond(w, logging.Debug, http.StatusOK, "Ignoring unsupported event type %s %s=%s", eventType, bitbucketServerRequestIDHeader, reqID)
+		e.respond(logger, w, logging.Debug, http.StatusOK, "Ignoring unsupported event type %s %s=%s", eventType, bitbucketServerRequestIDHeader, reqID)
 	}
 }
 
-func (e *VCSEventsController) handleAzureDevopsPost(w http.ResponseWriter, r *http.Request) {
+func (e *VCSEventsController) handleAzureDevopsPost(logger logging.SimpleLogging, w http.ResponseWriter, r *http.Request) {
 	// Validate the request against the optional basic auth username and password.
-	payload, err := e.AzureDevopsRequestValidator.Validate(r, e.AzureDevopsWebhookBasicUser, e.AzureDevopsWebhookBasicPassword)
+	payload, err := e.AzureDevopsRequestValidator.Validate(r, e.AzureDevopsWebhookBasicUser, e.secret(&e.AzureDevopsWebhookBasicPassword))
 	if err != nil {
-		e.respond(w, logging.Warn, http.StatusUnauthorized, "%s", err.Error())
+		e.respond(logger, w, logging.Warn, http.StatusUnauthorized, "%s", err.Error())
 		return
 	}
-	e.Logger.Debug("request valid")
+	logger.Debug("request valid")
 
 	azuredevopsReqID := "Request-Id=" + r.Header.Get("Request-Id")
 	event, err := azuredevops.ParseWebHook(payload)
 	if err != nil {
-		e.respond(w, logging.Error, http.StatusBadRequest, "Failed parsing webhook: %v %s", err, azuredevopsReqID)
+		e.respond(logger, w, logging.Error, http.StatusBadRequest, "Failed parsing webhook: %v %s", err, azuredevopsReqID)
 		return
 	}
 	switch event.PayloadType {
 	case azuredevops.PullRequestCommentedEvent:
-		e.Logger.Debug("handling as pull request commented event")
-		e.HandleAzureDevopsPullRequestCommentedEvent(w, event, azuredevopsReqID)
+		logger.Debug("handling as pull request commented event")
+		e.HandleAzureDevopsPullRequestCommentedEvent(logger, w, event, azuredevopsReqID)
 	case azuredevops.PullRequestEvent:
-		e.Logger.Debug("handling as pull request event")
-		e.HandleAzureDevopsPullRequestEvent(w, event, azuredevopsReqID)
+		logger.Debug("handling as pull request event")
+		e.HandleAzureDevopsPullRequestEvent(logger, w, event, azuredevopsReqID)
 	default:
-		e.respond(w, logging.Debug, http.StatusOK, "Ignoring unsupported event: %v %s", event.PayloadType, azuredevopsReqID)
+		e.respond(logger, w, logging.Debug, http.StatusOK, "Ignoring unsupported event: %v %s", event.PayloadType, azuredevopsReqID)
 	}
 }
 
-func (e *VCSEventsController) handleGiteaPost(w http.ResponseWriter, r *http.Request) {
+func (e *VCSEventsController) handleGiteaPost(logger logging.SimpleLogging, w http.ResponseWriter, r *http.Request) {
 	signature := r.Header.Get(giteaSignatureHeader)
 	eventType := r.Header.Get(giteaEventTypeHeader)
 	reqID := r.Header.Get(giteaRequestIDHeader)
@@ -324,18 +410,18 @@ func (e *VCSEventsController) handleGiteaPost(w http.ResponseWriter, r *http.Req
 
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		e.respond(w, logging.Error, http.StatusBadRequest, "Unable to read body: %s %s=%s", err, "X-Gitea-Delivery", reqID)
+		e.respond(logger, w, logging.Error, http.StatusBadRequest, "Unable to read body: %s %s=%s", err, "X-Gitea-Delivery", reqID)
 		return
 	}
 
-	if len(e.GiteaWebhookSecret) > 0 {
-		if err := gitea.ValidateSignature(body, signature, e.GiteaWebhookSecret); err != nil {
-			e.respond(w, logging.Warn, http.StatusBadRequest, "%s", fmt.Errorf("request did not pass validation: %w", err).Error())
+	if secret := e.secret(&e.GiteaWebhookSecret); len(secret) > 0 {
+		if err := gitea.ValidateSignature(body, signature, secret); err != nil {
+			e.respond(logger, w, logging.Warn, http.StatusBadRequest, "%s", fmt.Errorf("request did not pass validation: %w", err).Error())
 			return
 		}
 	}
 
-	logger := e.Logger.With("gitea-request-id", reqID)
+	logger = logger.With("gitea-request-id", reqID)
 
 	// Log the event type for debugging purposes
 	logger.Debug("Received Gitea event %s with ID %s", eventType, reqID)
@@ -343,13 +429,13 @@ func (e *VCSEventsController) handleGiteaPost(w http.ResponseWriter, r *http.Req
 	// Depending on the event type, handle the event appropriately
 	switch eventType {
 	case "pull_request_comment":
-		e.HandleGiteaPullRequestCommentEvent(w, body, reqID)
+		e.HandleGiteaPullRequestCommentEvent(logger, w, body, reqID)
 	case "pull_request":
 		logger.Debug("Handling as pull_request")
 		e.handleGiteaPullRequestEvent(logger, w, body, reqID)
 	// Add other case handlers as necessary
 	default:
-		e.respond(w, logging.Debug, http.StatusOK, "Ignoring unsupported Gitea event type: %s %s=%s", eventType, "X-Gitea-Delivery", reqID)
+		e.respond(logger, w, logging.Debug, http.StatusOK, "Ignoring unsupported Gitea event type: %s %s=%s", eventType, "X-Gitea-Delivery", reqID)
 	}
 }
 
@@ -358,8 +444,8 @@ func (e *VCSEventsController) handleGiteaPullRequestEvent(logger logging.SimpleL
 	// Attempt to unmarshal the incoming body into the Gitea PullRequest struct
 	var payload gitea.GiteaWebhookPayload
 	if err := json.Unmarshal(body, &payload); err != nil {
-		e.Logger.Err("Failed to unmarshal Gitea webhook payload: %v", err)
-		e.respond(w, logging.Error, http.StatusBadRequest, "Failed to parse request body: %s %s=%s", err, giteaRequestIDHeader, reqID)
+		logger.Err("Failed to unmarshal Gitea webhook payload: %v", err)
+		e.respond(logger, w, logging.Error, http.StatusBadRequest, "Failed to parse request body: %s %s=%s", err, giteaRequestIDHeader, reqID)
 		return
 	}
 
@@ -368,8 +454,8 @@ func (e *VCSEventsController) handleGiteaPullRequestEvent(logger logging.SimpleL
 	// Use the parser function to convert into Atlantis models
 	pull, pullEventType, baseRepo, headRepo, user, err := e.Parser.ParseGiteaPullRequestEvent(payload.PullRequest)
 	if err != nil {
-		e.Logger.Err("Failed to parse Gitea pull request event: %v", err)
-		e.respond(w, logging.Error, http.StatusInternalServerError, "Failed to process event")
+		logger.Err("Failed to parse Gitea pull request event: %v", err)
+		e.respond(logger, w, logging.Error, http.StatusInternalServerError, "Failed to process event")
 		return
 	}
 
@@ -383,25 +469,199 @@ func (e *VCSEventsController) handleGiteaPullRequestEvent(logger logging.SimpleL
 	logger.Info("Handling Gitea Pull Request '%s' event", pullEventType.String())
 	response := e.handlePullRequestEvent(logger, baseRepo, headRepo, pull, user, pullEventType)
 
-	e.respond(w, logging.Debug, http.StatusOK, "%s", response.body)
+	e.respond(logger, w, logging.Debug, http.StatusOK, "%s", response.body)
 }
 
 // HandleGiteaPullRequestCommentEvent handles comment events from Gitea where Atlantis commands can come from.
-func (e *VCSEventsController) HandleGiteaPullRequestCommentEvent(w http.ResponseWriter, body []byte, reqID string) {
+func (e *VCSEventsController) HandleGiteaPullRequestCommentEvent(logger logging.SimpleLogging, w http.ResponseWriter, body []byte, reqID string) {
 	var event gitea.GiteaIssueCommentPayload
 	if err := json.Unmarshal(body, &event); err != nil {
-		e.Logger.Err("Failed to unmarshal Gitea comment payload: %v", err)
-		e.respond(w, logging.Error, http.StatusBadRequest, "Failed to parse request body")
+		logger.Err("Failed to unmarshal Gitea comment payload: %v", err)
+		e.respond(logger, w, logging.Error, http.StatusBadRequest, "Failed to parse request body")
 		return
 	}
-	e.Logger.Debug("Successfully unmarshaled Gitea comment event")
+	logger.Debug("Successfully unmarshaled Gitea comment event")
 
 	baseRepo, user, pullNum, _ := e.Parser.ParseGiteaIssueCommentEvent(event)
 	// Since we're lacking headRepo and maybePull details, we'll pass nil
 	// This follows the same approach as the GitHub client for handling comment events without full PR details
-	response := e.handleCommentEvent(e.Logger, baseRepo, nil, nil, user, pullNum, event.Comment.Body, event.Comment.ID, models.Gitea)
+	response := e.handleCommentEvent(logger, baseRepo, nil, nil, user, pullNum, event.Comment.Body, event.Comment.ID, models.Gitea)
+
+	e.respond(logger, w, logging.Debug, http.StatusOK, "%s", response.body)
+}
 
-	e.respond(w, logging.Debug, http.StatusOK, "%s", response.body)
+func (e *VCSEventsController) handleGerritPost(logger logging.SimpleLogging, w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close() // nolint: errcheck
+
+	if secret := e.secret(&e.GerritWebhookSecret); len(secret) > 0 {
+		_, password, _ := r.BasicAuth()
+		if subtle.ConstantTimeCompare([]byte(password), secret) != 1 {
+			e.respond(logger, w, logging.Warn, http.StatusUnauthorized, "request did not pass validation: basic auth password doesn't match the Gerrit webhook secret")
+			return
+		}
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		e.respond(logger, w, logging.Error, http.StatusBadRequest, "Unable to read body: %s", err)
+		return
+	}
+	var event gerrit.Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		e.respond(logger, w, logging.Error, http.StatusBadRequest, "Failed to parse request body: %s", err)
+		return
+	}
+	logger.Debug("Received Gerrit event %s", event.Type)
+
+	switch event.Type {
+	case gerrit.CommentAddedEvent:
+		e.HandleGerritCommentEvent(logger, w, event)
+	case gerrit.PatchsetCreatedEvent, gerrit.WorkInProgressChanged, gerrit.ChangeRestoredEvent, gerrit.ChangeMergedEvent, gerrit.ChangeAbandonedEvent:
+		e.handleGerritChangeEvent(logger, w, event)
+	default:
+		e.respond(logger, w, logging.Debug, http.StatusOK, "Ignoring unsupported Gerrit event type: %s", event.Type)
+	}
+}
+
+func (e *VCSEventsController) handleGerritChangeEvent(logger logging.SimpleLogging, w http.ResponseWriter, event gerrit.Event) {
+	pull, pullEventType, baseRepo, headRepo, user, err := e.Parser.ParseGerritChangeEvent(event)
+	if err != nil {
+		e.respond(logger, w, logging.Error, http.StatusBadRequest, "Error parsing Gerrit %s event: %s", event.Type, err)
+		return
+	}
+	logger = logger.With(
+		"repo", baseRepo.FullName,
+		"pull", strconv.Itoa(pull.Num),
+	)
+	logger.Info("Handling Gerrit change '%s' event", pullEventType.String())
+	response := e.handlePullRequestEvent(logger, baseRepo, headRepo, pull, user, pullEventType)
+	e.respond(logger, w, logging.Debug, http.StatusOK, "%s", response.body)
+}
+
+// HandleGerritCommentEvent handles the reviews of Gerrit changes where
+// Atlantis commands can come from. The command must be the message of the
+// review.
+func (e *VCSEventsController) HandleGerritCommentEvent(logger logging.SimpleLogging, w http.ResponseWriter, event gerrit.Event) {
+	baseRepo, user, pullNum, comment, err := e.Parser.ParseGerritCommentEvent(event)
+	if err != nil {
+		e.respond(logger, w, logging.Error, http.StatusBadRequest, "Error parsing Gerrit comment-added event: %s", err)
+		return
+	}
+	// Like GitHub comments, the events lack the details of the change so
+	// they're fetched when running the command. Gerrit reviews have no ID.
+	response := e.handleCommentEvent(logger, baseRepo, nil, nil, user, pullNum, comment, -1, models.Gerrit)
+	if response.err.code != 0 {
+		e.respond(logger, w, logging.Error, response.err.code, "%s", response.err.err.Error())
+		return
+	}
+	e.respond(logger, w, logging.Debug, http.StatusOK, "%s", response.body)
+}
+
+func (e *VCSEventsController) handleCodeCommitPost(logger logging.SimpleLogging, w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close() // nolint: errcheck
+
+	if secret := e.secret(&e.CodeCommitWebhookSecret); len(secret) > 0 {
+		_, password, _ := r.BasicAuth()
+		if subtle.ConstantTimeCompare([]byte(password), secret) != 1 {
+			e.respond(logger, w, logging.Warn, http.StatusUnauthorized, "request did not pass validation: basic auth password doesn't match the CodeCommit webhook secret")
+			return
+		}
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		e.respond(logger, w, logging.Error, http.StatusBadRequest, "Unable to read body: %s", err)
+		return
+	}
+	// SNS wraps the events of EventBridge in its own messages.
+	if messageType := r.Header.Get(snsMessageTypeHeader); messageType != "" {
+		var message codecommit.SNSMessage
+		if err := json.Unmarshal(body, &message); err != nil {
+			e.respond(logger, w, logging.Error, http.StatusBadRequest, "Failed to parse request body: %s", err)
+			return
+		}
+		switch messageType {
+		case codecommit.SNSSubscriptionConfirmation:
+			if err := e.CodeCommitClient.ConfirmSubscription(logger, message); err != nil {
+				e.respond(logger, w, logging.Error, http.StatusBadRequest, "Failed to confirm SNS subscription: %s", err)
+				return
+			}
+			e.respond(logger, w, logging.Info, http.StatusOK, "Confirmed subscription to SNS topic %s", message.TopicArn)
+			return
+		case codecommit.SNSNotification:
+			body = []byte(message.Message)
+		default:
+			e.respond(logger, w, logging.Debug, http.StatusOK, "Ignoring SNS message type: %s", messageType)
+			return
+		}
+	}
+	var event codecommit.Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		e.respond(logger, w, logging.Error, http.StatusBadRequest, "Failed to parse request body: %s", err)
+		return
+	}
+	logger.Debug("Received CodeCommit event %s", event.DetailType)
+
+	switch event.DetailType {
+	case codecommit.PullRequestStateChangeDetailType:
+		e.handleCodeCommitPullEvent(logger, w, event)
+	case codecommit.CommentOnPullRequestDetailType:
+		e.HandleCodeCommitCommentEvent(logger, w, event)
+	default:
+		e.respond(logger, w, logging.Debug, http.StatusOK, "Ignoring unsupported CodeCommit event type: %s", event.DetailType)
+	}
+}
+
+func (e *VCSEventsController) handleCodeCommitPullEvent(logger logging.SimpleLogging, w http.ResponseWriter, event codecommit.Event) {
+	pull, pullEventType, baseRepo, headRepo, user, err := e.Parser.ParseCodeCommitPullEvent(event)
+	if err != nil {
+		e.respond(logger, w, logging.Error, http.StatusBadRequest, "Error parsing CodeCommit %s event: %s", event.Detail.Event, err)
+		return
+	}
+	logger = logger.With(
+		"repo", baseRepo.FullName,
+		"pull", strconv.Itoa(pull.Num),
+	)
+	logger.Info("Handling CodeCommit pull request '%s' event", pullEventType.String())
+	response := e.handlePullRequestEvent(logger, baseRepo, headRepo, pull, user, pullEventType)
+	e.respond(logger, w, logging.Debug, http.StatusOK, "%s", response.body)
+}
+
+// HandleCodeCommitCommentEvent handles the comments of CodeCommit pull
+// requests where Atlantis commands can come from. The events only have the
+// ID of their comment so its content is fetched.
+func (e *VCSEventsController) HandleCodeCommitCommentEvent(logger logging.SimpleLogging, w http.ResponseWriter, event codecommit.Event) {
+	if event.Detail.Event != codecommit.CommentOnPullRequestCreatedEvent {
+		e.respond(logger, w, logging.Debug, http.StatusOK, "Ignoring comment event since event was not %s", codecommit.CommentOnPullRequestCreatedEvent)
+		return
+	}
+	baseRepo, pullNum, commentID, err := e.Parser.ParseCodeCommitCommentEvent(event)
+	if err != nil {
+		e.respond(logger, w, logging.Error, http.StatusBadRequest, "Error parsing CodeCommit comment event: %s", err)
+		return
+	}
+	comment, err := e.CodeCommitClient.GetComment(logger, commentID)
+	if err != nil {
+		e.respond(logger, w, logging.Error, http.StatusInternalServerError, "Error getting CodeCommit comment %s: %s", commentID, err)
+		return
+	}
+	// The events can be sent by anyone when there's no webhook secret, so the
+	// commands run as the author of the fetched comment rather than the
+	// caller of the event.
+	if comment.AuthorARN == "" {
+		e.respond(logger, w, logging.Error, http.StatusInternalServerError, "CodeCommit comment %s has no author", commentID)
+		return
+	}
+	user := models.User{Username: codecommit.UsernameFromARN(comment.AuthorARN)}
+	// Like GitHub comments, the events lack the details of the pull request
+	// so they're fetched when running the command. The comment IDs aren't
+	// numbers so they can't be reacted to.
+	response := e.handleCommentEvent(logger, baseRepo, nil, nil, user, pullNum, comment.Content, -1, models.CodeCommit)
+	if response.err.code != 0 {
+		e.respond(logger, w, logging.Error, response.err.code, "%s", response.err.err.Error())
+		return
+	}
+	e.respond(logger, w, logging.Debug, http.StatusOK, "%s", response.body)
 }
 
 // HandleGithubCommentEvent handles comment events from GitHub where Atlantis
@@ -435,13 +695,13 @@ func (e *VCSEventsController) HandleGithubCommentEvent(event *github.IssueCommen
 }
 
 // HandleBitbucketCloudCommentEvent handles comment events from Bitbucket.
-func (e *VCSEventsController) HandleBitbucketCloudCommentEvent(w http.ResponseWriter, body []byte, reqID string) {
+func (e *VCSEventsController) HandleBitbucketCloudCommentEvent(logger logging.SimpleLogging, w http.ResponseWriter, body []byte, reqID string) {
 	pull, baseRepo, headRepo, user, comment, err := e.Parser.ParseBitbucketCloudPullCommentEvent(body)
 	if err != nil {
-		e.respond(w, logging.Error, http.StatusBadRequest, "Error parsing pull data: %s %s=%s", err, bitbucketCloudRequestIDHeader, reqID)
+		e.respond(logger, w, logging.Error, http.StatusBadRequest, "Error parsing pull data: %s %s=%s", err, bitbucketCloudRequestIDHeader, reqID)
 		return
 	}
-	resp := e.handleCommentEvent(e.Logger, baseRepo, &headRepo, &pull, user, pull.Num, comment, -1, models.BitbucketCloud)
+	resp := e.handleCommentEvent(logger, baseRepo, &headRepo, &pull, user, pull.Num, comment, -1, models.BitbucketCloud)
 
 	//TODO: move this to the outer most function similar to github
 	lvl := logging.Debug
@@ -452,17 +712,17 @@ func (e *VCSEventsController) HandleBitbucketCloudCommentEvent(w http.ResponseWr
 		code = resp.err.code
 		msg = resp.err.err.Error()
 	}
-	e.respond(w, lvl, code, "%s", msg)
+	e.respond(logger, w, lvl, code, "%s", msg)
 }
 
 // HandleBitbucketServerCommentEvent handles comment events from Bitbucket.
-func (e *VCSEventsController) HandleBitbucketServerCommentEvent(w http.ResponseWriter, body []byte, reqID string) {
+func (e *VCSEventsController) HandleBitbucketServerCommentEvent(logger logging.SimpleLogging, w http.ResponseWriter, body []byte, reqID string) {
 	pull, baseRepo, headRepo, user, comment, err := e.Parser.ParseBitbucketServerPullCommentEvent(body)
 	if err != nil {
-		e.respond(w, logging.Error, http.StatusBadRequest, "Error parsing pull data: %s %s=%s", err, bitbucketCloudRequestIDHeader, reqID)
+		e.respond(logger, w, logging.Error, http.StatusBadRequest, "Error parsing pull data: %s %s=%s", err, bitbucketCloudRequestIDHeader, reqID)
 		return
 	}
-	resp := e.handleCommentEvent(e.Logger, baseRepo, &headRepo, &pull, user, pull.Num, comment, -1, models.BitbucketCloud)
+	resp := e.handleCommentEvent(logger, baseRepo, &headRepo, &pull, user, pull.Num, comment, -1, models.BitbucketCloud)
 
 	//TODO: move this to the outer most function similar to github
 	lvl := logging.Debug
@@ -473,16 +733,16 @@ func (e *VCSEventsController) HandleBitbucketServerCommentEvent(w http.ResponseW
 		code = resp.err.code
 		msg = resp.err.err.Error()
 	}
-	e.respond(w, lvl, code, "%s", msg)
+	e.respond(logger, w, lvl, code, "%s", msg)
 }
 
 func (e *VCSEventsController) handleBitbucketCloudPullRequestEvent(logger logging.SimpleLogging, w http.ResponseWriter, eventType string, body []byte, reqID string) {
 	pull, baseRepo, headRepo, user, err := e.Parser.ParseBitbucketCloudPullEvent(body)
 	if err != nil {
-		e.respond(w, logging.Error, http.StatusBadRequest, "Error parsing pull data: %s %s=%s", err, bitbucketCloudRequestIDHeader, reqID)
+		e.respond(logger, w, logging.Error, http.StatusBadRequest, "Error parsing pull data: %s %s=%s", err, bitbucketCloudRequestIDHeader, reqID)
 		return
 	}
-	e.Logger.Debug("SHA is %q", pull.HeadCommit)
+	logger.Debug("SHA is %q", pull.HeadCommit)
 	pullEventType := e.Parser.GetBitbucketCloudPullEventType(eventType, pull.HeadCommit, pull.URL)
 
 	// Annotate logger with repo and pull/merge request number.
@@ -492,7 +752,7 @@ func (e *VCSEventsController) handleBitbucketCloudPullRequestEvent(logger loggin
 	)
 
 	logger.Info("Handling Bitbucket Cloud Pull Request '%s' event", pullEventType.String())
-	resp := e.handlePullRequestEvent(e.Logger, baseRepo, headRepo, pull, user, pullEventType)
+	resp := e.handlePullRequestEvent(logger, baseRepo, headRepo, pull, user, pullEventType)
 
 	//TODO: move this to the outer most function similar to github
 	lvl := logging.Debug
@@ -503,13 +763,13 @@ func (e *VCSEventsController) handleBitbucketCloudPullRequestEvent(logger loggin
 		code = resp.err.code
 		msg = resp.err.err.Error()
 	}
-	e.respond(w, lvl, code, "%s", msg)
+	e.respond(logger, w, lvl, code, "%s", msg)
 }
 
 func (e *VCSEventsController) handleBitbucketServerPullRequestEvent(logger logging.SimpleLogging, w http.ResponseWriter, eventType string, body []byte, reqID string) {
 	pull, baseRepo, headRepo, user, err := e.Parser.ParseBitbucketServerPullEvent(body)
 	if err != nil {
-		e.respond(w, logging.Error, http.StatusBadRequest, "Error parsing pull data: %s %s=%s", err, bitbucketServerRequestIDHeader, reqID)
+		e.respond(logger, w, logging.Error, http.StatusBadRequest, "Error parsing pull data: %s %s=%s", err, bitbucketServerRequestIDHeader, reqID)
 		return
 	}
 	pullEventType := e.Parser.GetBitbucketServerPullEventType(eventType)
@@ -521,7 +781,7 @@ func (e *VCSEventsController) handleBitbucketServerPullRequestEvent(logger loggi
 	)
 
 	logger.Info("Handling Bitbucket Server Pull Request '%s' event", pullEventType.String())
-	resp := e.handlePullRequestEvent(e.Logger, baseRepo, headRepo, pull, user, pullEventType)
+	resp := e.handlePullRequestEvent(logger, baseRepo, headRepo, pull, user, pullEventType)
 
 	//TODO: move this to the outer most function similar to github
 	lvl := logging.Debug
@@ -532,7 +792,7 @@ func (e *VCSEventsController) handleBitbucketServerPullRequestEvent(logger loggi
 		code = resp.err.code
 		msg = resp.err.err.Error()
 	}
-	e.respond(w, lvl, code, "%s", msg)
+	e.respond(logger, w, lvl, code, "%s", msg)
 }
 
 // HandleGithubPullRequestEvent will delete any locks associated with the pull
@@ -562,6 +822,62 @@ func (e *VCSEventsController) HandleGithubPullRequestEvent(logger logging.Simple
 	return e.handlePullRequestEvent(logger, baseRepo, headRepo, pull, user, pullEventType)
 }
 
+// HandleGithubPushEvent plans the open pull requests into the pushed branch
+// again if their plans are stale. It's exported to make testing easier.
+func (e *VCSEventsController) HandleGithubPushEvent(logger logging.SimpleLogging, event *github.PushEvent, githubReqID string) HTTPResponse {
+	if e.BaseBranchReplanner == nil {
+		return HTTPResponse{
+			body: fmt.Sprintf("Ignoring push event %s", githubReqID),
+		}
+	}
+	branch, isBranch := strings.CutPrefix(event.GetRef(), "refs/heads/")
+	if !isBranch || event.GetDeleted() {
+		return HTTPResponse{
+			body: fmt.Sprintf("Ignoring push event that didn't advance a branch %s", githubReqID),
+		}
+	}
+	baseRepo, err := e.Parser.ParseGithubRepo(&github.Repository{
+		FullName: event.GetRepo().FullName,
+		CloneURL: event.GetRepo().CloneURL,
+	})
+	if err != nil {
+		wrapped := fmt.Errorf("parsing repo: %s: %w", githubReqID, err)
+		return HTTPResponse{
+			body: wrapped.Error(),
+			err: HTTPError{
+				code:       http.StatusBadRequest,
+				err:        wrapped,
+				isSilenced: false,
+			},
+		}
+	}
+	if !e.RepoAllowlistChecker.IsAllowlisted(baseRepo.FullName, baseRepo.VCSHost.Hostname) ||
+		!e.RepoAllowlistChecker.IsBranchAllowlisted(baseRepo.FullName, baseRepo.VCSHost.Hostname, branch) {
+		return HTTPResponse{
+			body: fmt.Sprintf("Ignoring push event to non-allowlisted repo or branch %s", githubReqID),
+		}
+	}
+
+	var files []string
+	for _, commit := range event.Commits {
+		files = append(files, commit.Added...)
+		files = append(files, commit.Removed...)
+		files = append(files, commit.Modified...)
+	}
+	// Push events only include the latest commits.
+	allChanged := event.GetSize() > len(event.Commits)
+
+	logger.Info("Handling GitHub push to %s of %s", branch, baseRepo.FullName)
+	if !e.TestingMode {
+		go e.BaseBranchReplanner.BasePushed(baseRepo, branch, event.GetAfter(), files, allChanged)
+	} else {
+		e.BaseBranchReplanner.BasePushed(baseRepo, branch, event.GetAfter(), files, allChanged)
+	}
+	return HTTPResponse{
+		body: "Processing...",
+	}
+}
+
 func (e *VCSEventsController) handlePullRequestEvent(logger logging.SimpleLogging, baseRepo models.Repo, headRepo models.Repo, pull models.PullRequest, user models.User, eventType models.PullRequestEventType) HTTPResponse {
 	if !e.RepoAllowlistChecker.IsAllowlisted(baseRepo.FullName, baseRepo.VCSHost.Hostname) {
 		// If the repo isn't allowlisted and we receive an opened pull request
@@ -569,7 +885,7 @@ func (e *VCSEventsController) handlePullRequestEvent(logger logging.SimpleLoggin
 		// allowlisted. This is because the user might be expecting Atlantis to
 		// autoplan. For other events, we just ignore them.
 		if eventType == models.OpenedPullEvent {
-			e.commentNotAllowlisted(baseRepo, pull.Num)
+			e.commentNotAllowlisted(logger, baseRepo, pull.Num)
 		}
 
 		err := fmt.Errorf("pull request event from non-allowlisted repo '%s/%s'", baseRepo.VCSHost.Hostname, baseRepo.FullName)
@@ -584,18 +900,32 @@ func (e *VCSEventsController) handlePullRequestEvent(logger logging.SimpleLoggin
 		}
 	}
 
+	if eventType != models.OtherPullEvent {
+		e.Timeline.Record(baseRepo.FullName, pull.Num, timeline.Event{
+			Type:        timeline.Webhook,
+			Description: fmt.Sprintf("Received a pull request %s event", eventType),
+			User:        user.Username,
+		})
+	}
+
 	switch eventType {
 	case models.OpenedPullEvent, models.UpdatedPullEvent:
 		// If the pull request was opened or updated, we will try to autoplan.
+		if !e.RepoAllowlistChecker.IsBranchAllowlisted(baseRepo.FullName, baseRepo.VCSHost.Hostname, pull.BaseBranch) {
+			logger.Info("Ignoring pull request targeting non-allowlisted branch '%s'", pull.BaseBranch)
+			return HTTPResponse{
+				body: fmt.Sprintf("Ignoring pull request targeting non-allowlisted branch %q", pull.BaseBranch),
+			}
+		}
 
 		// Respond with success and then actually execute the command asynchronously.
 		// We use a goroutine so that this function returns and the connection is
 		// closed.
 		if !e.TestingMode {
-			go e.CommandRunner.RunAutoplanCommand(baseRepo, headRepo, pull, user)
+			go e.CommandRunner.RunAutoplanCommand(logger, baseRepo, headRepo, pull, user)
 		} else {
 			// When testing we want to wait for everything to complete.
-			e.CommandRunner.RunAutoplanCommand(baseRepo, headRepo, pull, user)
+			e.CommandRunner.RunAutoplanCommand(logger, baseRepo, headRepo, pull, user)
 		}
 		return HTTPResponse{
 			body: "Processing...",
@@ -626,39 +956,39 @@ func (e *VCSEventsController) handlePullRequestEvent(logger logging.SimpleLoggin
 	return HTTPResponse{}
 }
 
-func (e *VCSEventsController) handleGitlabPost(w http.ResponseWriter, r *http.Request) {
-	event, err := e.GitlabRequestParserValidator.ParseAndValidate(r, e.GitlabWebhookSecret)
+func (e *VCSEventsController) handleGitlabPost(logger logging.SimpleLogging, w http.ResponseWriter, r *http.Request) {
+	event, err := e.GitlabRequestParserValidator.ParseAndValidate(r, e.secret(&e.GitlabWebhookSecret))
 	if err != nil {
-		e.respond(w, logging.Warn, http.StatusBadRequest, "%s", err.Error())
+		e.respond(logger, w, logging.Warn, http.StatusBadRequest, "%s", err.Error())
 		return
 	}
-	e.Logger.Debug("request valid")
+	logger.Debug("request valid")
 
 	switch event := event.(type) {
 	case gitlab.MergeCommentEvent:
-		e.Logger.Debug("handling as comment event")
-		e.HandleGitlabCommentEvent(w, event)
+		logger.Debug("handling as comment event")
+		e.HandleGitlabCommentEvent(logger, w, event)
 	case gitlab.MergeEvent:
-		e.HandleGitlabMergeRequestEvent(e.Logger, w, event)
+		e.HandleGitlabMergeRequestEvent(logger, w, event)
 	case gitlab.CommitCommentEvent:
-		e.Logger.Debug("comments on commits are not supported, only comments on merge requests")
-		e.respond(w, logging.Debug, http.StatusOK, "Ignoring comment on commit event")
+		logger.Debug("comments on commits are not supported, only comments on merge requests")
+		e.respond(logger, w, logging.Debug, http.StatusOK, "Ignoring comment on commit event")
 	default:
-		e.respond(w, logging.Debug, http.StatusOK, "Ignoring unsupported event")
+		e.respond(logger, w, logging.Debug, http.StatusOK, "Ignoring unsupported event")
 	}
 
 }
 
 // HandleGitlabCommentEvent handles comment events from GitLab where Atlantis
 // commands can come from. It's exported to make testing easier.
-func (e *VCSEventsController) HandleGitlabCommentEvent(w http.ResponseWriter, event gitlab.MergeCommentEvent) {
+func (e *VCSEventsController) HandleGitlabCommentEvent(logger logging.SimpleLogging, w http.ResponseWriter, event gitlab.MergeCommentEvent) {
 	// todo: can gitlab return the pull request here too?
 	baseRepo, headRepo, commentID, user, err := e.Parser.ParseGitlabMergeRequestCommentEvent(event)
 	if err != nil {
-		e.respond(w, logging.Error, http.StatusBadRequest, "Error parsing webhook: %s", err)
+		e.respond(logger, w, logging.Error, http.StatusBadRequest, "Error parsing webhook: %s", err)
 		return
 	}
-	resp := e.handleCommentEvent(e.Logger, baseRepo, &headRepo, nil, user, event.MergeRequest.IID, event.ObjectAttributes.Note, int64(commentID), models.Gitlab)
+	resp := e.handleCommentEvent(logger, baseRepo, &headRepo, nil, user, event.MergeRequest.IID, event.ObjectAttributes.Note, int64(commentID), models.Gitlab)
 
 	//TODO: move this to the outer most function similar to github
 	lvl := logging.Debug
@@ -669,13 +999,13 @@ func (e *VCSEventsController) HandleGitlabCommentEvent(w http.ResponseWriter, ev
 		code = resp.err.code
 		msg = resp.err.err.Error()
 	}
-	e.respond(w, lvl, code, "%s", msg)
+	e.respond(logger, w, lvl, code, "%s", msg)
 }
 
 func (e *VCSEventsController) handleCommentEvent(logger logging.SimpleLogging, baseRepo models.Repo, maybeHeadRepo *models.Repo, maybePull *models.PullRequest, user models.User, pullNum int, comment string, commentID int64, vcsHost models.VCSHostType) HTTPResponse {
-	logger = logger.WithHistory(
+	logger = logger.With(
 		"repo", baseRepo.FullName,
-		"pull", pullNum,
+		"pull", strconv.Itoa(pullNum),
 	)
 
 	parseResult := e.CommentParser.Parse(comment, vcsHost)
@@ -697,7 +1027,7 @@ func (e *VCSEventsController) handleCommentEvent(logger logging.SimpleLogging, b
 	// At this point we know it's a command we're not supposed to ignore, so now
 	// we check if this repo is allowed to run commands in the first place.
 	if !e.RepoAllowlistChecker.IsAllowlisted(baseRepo.FullName, baseRepo.VCSHost.Hostname) {
-		e.commentNotAllowlisted(baseRepo, pullNum)
+		e.commentNotAllowlisted(logger, baseRepo, pullNum)
 
 		err := errors.New("repo not allowlisted")
 
@@ -711,6 +1041,13 @@ func (e *VCSEventsController) handleCommentEvent(logger logging.SimpleLogging, b
 		}
 	}
 
+	event := timeline.Event{Type: timeline.Webhook, Description: "Received a comment Atlantis responds to", User: user.Username}
+	if parseResult.Command != nil {
+		event.Description = fmt.Sprintf("Received a comment with the %s command", parseResult.Command.Name)
+		event.Project, event.Workspace = parseResult.Command.ProjectName, parseResult.Command.Workspace
+	}
+	e.Timeline.Record(baseRepo.FullName, pullNum, event)
+
 	// It's a comment we're going to react to so add a reaction.
 	if e.EmojiReaction != "" {
 		err := e.VCSClient.ReactToComment(logger, baseRepo, pullNum, commentID, e.EmojiReaction)
@@ -731,6 +1068,7 @@ func (e *VCSEventsController) handleCommentEvent(logger logging.SimpleLogging, b
 			body: "Commenting back on pull request",
 		}
 	}
+	parseResult.Command.CommentID = commentID
 	if parseResult.Command.RepoRelDir != "" {
 		logger.Info("Running comment command '%v' on dir '%v' for user '%v'.",
 			parseResult.Command.Name, parseResult.Command.RepoRelDir, user.Username)
@@ -741,10 +1079,10 @@ func (e *VCSEventsController) handleCommentEvent(logger logging.SimpleLogging, b
 		// Respond with success and then actually execute the command asynchronously.
 		// We use a goroutine so that this function returns and the connection is
 		// closed.
-		go e.CommandRunner.RunCommentCommand(baseRepo, maybeHeadRepo, maybePull, user, pullNum, parseResult.Command)
+		go e.CommandRunner.RunCommentCommand(logger, baseRepo, maybeHeadRepo, maybePull, user, pullNum, parseResult.Command)
 	} else {
 		// When testing we want to wait for everything to complete.
-		e.CommandRunner.RunCommentCommand(baseRepo, maybeHeadRepo, maybePull, user, pullNum, parseResult.Command)
+		e.CommandRunner.RunCommentCommand(logger, baseRepo, maybeHeadRepo, maybePull, user, pullNum, parseResult.Command)
 	}
 
 	return HTTPResponse{
@@ -758,7 +1096,7 @@ func (e *VCSEventsController) handleCommentEvent(logger logging.SimpleLogging, b
 func (e *VCSEventsController) HandleGitlabMergeRequestEvent(logger logging.SimpleLogging, w http.ResponseWriter, event gitlab.MergeEvent) {
 	pull, pullEventType, baseRepo, headRepo, user, err := e.Parser.ParseGitlabMergeRequestEvent(event)
 	if err != nil {
-		e.respond(w, logging.Error, http.StatusBadRequest, "Error parsing webhook: %s", err)
+		e.respond(logger, w, logging.Error, http.StatusBadRequest, "Error parsing webhook: %s", err)
 		return
 	}
 
@@ -779,39 +1117,39 @@ func (e *VCSEventsController) HandleGitlabMergeRequestEvent(logger logging.Simpl
 		code = resp.err.code
 		msg = resp.err.err.Error()
 	}
-	e.respond(w, lvl, code, "%s", msg)
+	e.respond(logger, w, lvl, code, "%s", msg)
 }
 
 // HandleAzureDevopsPullRequestCommentedEvent handles comment events from Azure DevOps where Atlantis
 // commands can come from. It's exported to make testing easier.
 // Sometimes we may want data from the parent azuredevops.Event struct, so we handle type checking here.
 // Requires Resource Version 2.0 of the Pull Request Commented On webhook payload.
-func (e *VCSEventsController) HandleAzureDevopsPullRequestCommentedEvent(w http.ResponseWriter, event *azuredevops.Event, azuredevopsReqID string) {
+func (e *VCSEventsController) HandleAzureDevopsPullRequestCommentedEvent(logger logging.SimpleLogging, w http.ResponseWriter, event *azuredevops.Event, azuredevopsReqID string) {
 	resource, ok := event.Resource.(*azuredevops.GitPullRequestWithComment)
 	if !ok || event.PayloadType != azuredevops.PullRequestCommentedEvent {
-		e.respond(w, logging.Error, http.StatusBadRequest, "Event.Resource is nil or received bad event type %v; %s", event.Resource, azuredevopsReqID)
+		e.respond(logger, w, logging.Error, http.StatusBadRequest, "Event.Resource is nil or received bad event type %v; %s", event.Resource, azuredevopsReqID)
 		return
 	}
 
 	if resource.Comment == nil {
-		e.respond(w, logging.Debug, http.StatusOK, "Ignoring comment event since no comment is linked to payload; %s", azuredevopsReqID)
+		e.respond(logger, w, logging.Debug, http.StatusOK, "Ignoring comment event since no comment is linked to payload; %s", azuredevopsReqID)
 		return
 	}
 
 	if resource.Comment.GetIsDeleted() {
-		e.respond(w, logging.Debug, http.StatusOK, "Ignoring comment event since it is linked to deleting a pull request comment; %s", azuredevopsReqID)
+		e.respond(logger, w, logging.Debug, http.StatusOK, "Ignoring comment event since it is linked to deleting a pull request comment; %s", azuredevopsReqID)
 		return
 	}
 
 	strippedComment := bluemonday.StrictPolicy().SanitizeBytes([]byte(*resource.Comment.Content))
 
 	if resource.PullRequest == nil {
-		e.respond(w, logging.Debug, http.StatusOK, "Ignoring comment event since no pull request is linked to payload; %s", azuredevopsReqID)
+		e.respond(logger, w, logging.Debug, http.StatusOK, "Ignoring comment event since no pull request is linked to payload; %s", azuredevopsReqID)
 		return
 	}
 
 	if isAzureDevOpsTestRepoURL(resource.PullRequest.GetRepository()) {
-		e.respond(w, logging.Debug, http.StatusOK, "Ignoring Azure DevOps Test Event with Repo URL: %v %s", resource.PullRequest.Repository.URL, azuredevopsReqID)
+		e.respond(logger, w, logging.Debug, http.StatusOK, "Ignoring Azure DevOps Test Event with Repo URL: %v %s", resource.PullRequest.Repository.URL, azuredevopsReqID)
 		return
 	}
 
@@ -819,10 +1157,10 @@ func (e *VCSEventsController) HandleAzureDevopsPullRequestCommentedEvent(w http.
 	user := models.User{Username: createdBy.GetUniqueName()}
 	baseRepo, err := e.Parser.ParseAzureDevopsRepo(resource.PullRequest.GetRepository())
 	if err != nil {
-		e.respond(w, logging.Error, http.StatusBadRequest, "Error parsing pull request repository field: %s; %s", err, azuredevopsReqID)
+		e.respond(logger, w, logging.Error, http.StatusBadRequest, "Error parsing pull request repository field: %s; %s", err, azuredevopsReqID)
 		return
 	}
-	resp := e.handleCommentEvent(e.Logger, baseRepo, nil, nil, user, resource.PullRequest.GetPullRequestID(), string(strippedComment), -1, models.AzureDevops)
+	resp := e.handleCommentEvent(logger, baseRepo, nil, nil, user, resource.PullRequest.GetPullRequestID(), string(strippedComment), -1, models.AzureDevops)
 
 	//TODO: move this to the outer most function similar to github
 	lvl := logging.Debug
@@ -833,13 +1171,13 @@ func (e *VCSEventsController) HandleAzureDevopsPullRequestCommentedEvent(w http.
 		code = resp.err.code
 		msg = resp.err.err.Error()
 	}
-	e.respond(w, lvl, code, "%s", msg)
+	e.respond(logger, w, lvl, code, "%s", msg)
 }
 
 // HandleAzureDevopsPullRequestEvent will delete any locks associated with the pull
 // request if the event is a pull request closed event. It's exported to make
 // testing easier.
-func (e *VCSEventsController) HandleAzureDevopsPullRequestEvent(w http.ResponseWriter, event *azuredevops.Event, azuredevopsReqID string) {
+func (e *VCSEventsController) HandleAzureDevopsPullRequestEvent(logger logging.SimpleLogging, w http.ResponseWriter, event *azuredevops.Event, azuredevopsReqID string) {
 	prText := event.Message.GetText()
 	ignoreEvents := []string{
 		"changed the reviewer list",
@@ -852,28 +1190,32 @@ func (e *VCSEventsController) HandleAzureDevopsPullRequestEvent(w http.ResponseW
 	for _, s := range ignoreEvents {
 		if strings.Contains(prText, s) {
 			msg := fmt.Sprintf("pull request updated event is not a supported type [%s]", s)
-			e.respond(w, logging.Debug, http.StatusOK, "%s: %s", msg, azuredevopsReqID)
+			e.respond(logger, w, logging.Debug, http.StatusOK, "%s: %s", msg, azuredevopsReqID)
 			return
 		}
 	}
 
 	resource, ok := event.Resource.(*azuredevops.GitPullRequest)
 	if !ok || event.PayloadType != azuredevops.PullRequestEvent {
-		e.respond(w, logging.Error, http.StatusBadRequest, "Event.Resource is nil or received bad event type %v; %s", event.Resource, azuredevopsReqID)
+		e.respond(logger, w, logging.Error, http.StatusBadRequest, "Event.Resource is nil or received bad event type %v; %s", event.Resource, azuredevopsReqID)
 		return
 	}
 	if isAzureDevOpsTestRepoURL(resource.GetRepository()) {
-		e.respond(w, logging.Debug, http.StatusOK, "Ignoring Azure DevOps Test Event with Repo URL: %v %s", resource.Repository.URL, azuredevopsReqID)
+		e.respond(logger, w, logging.Debug, http.StatusOK, "Ignoring Azure DevOps Test Event with Repo URL: %v %s", resource.Repository.URL, azuredevopsReqID)
 		return
 	}
 
 	pull, pullEventType, baseRepo, headRepo, user, err := e.Parser.ParseAzureDevopsPullEvent(*event)
 	if err != nil {
-		e.respond(w, logging.Error, http.StatusBadRequest, "Error parsing pull data: %s %s", err, azuredevopsReqID)
+		e.respond(logger, w, logging.Error, http.StatusBadRequest, "Error parsing pull data: %s %s", err, azuredevopsReqID)
 		return
 	}
-	e.Logger.Info("identified event as type %q", pullEventType.String())
-	resp := e.handlePullRequestEvent(e.Logger, baseRepo, headRepo, pull, user, pullEventType)
+	logger = logger.With(
+		"repo", baseRepo.FullName,
+		"pull", strconv.Itoa(pull.Num),
+	)
+	logger.Info("identified event as type %q", pullEventType.String())
+	resp := e.handlePullRequestEvent(logger, baseRepo, headRepo, pull, user, pullEventType)
 
 	//TODO: move this to the outer most function similar to github
 	lvl := logging.Debug
@@ -884,7 +1226,7 @@ func (e *VCSEventsController) HandleAzureDevopsPullRequestEvent(w http.ResponseW
 		code = resp.err.code
 		msg = resp.err.err.Error()
 	}
-	e.respond(w, lvl, code, "%s", msg)
+	e.respond(logger, w, lvl, code, "%s", msg)
 }
 
 // supportsHost returns true if h is in e.SupportedVCSHosts and false otherwise.
@@ -892,23 +1234,23 @@ func (e *VCSEventsController) supportsHost(h models.VCSHostType) bool {
 	return slices.Contains(e.SupportedVCSHosts, h)
 }
 
-func (e *VCSEventsController) respond(w http.ResponseWriter, lvl logging.LogLevel, code int, format string, args ...any) {
+func (e *VCSEventsController) respond(logger logging.SimpleLogging, w http.ResponseWriter, lvl logging.LogLevel, code int, format string, args ...any) {
 	response := fmt.Sprintf(format, args...)
-	e.Logger.Log(lvl, response)
+	logger.Log(lvl, response)
 	w.WriteHeader(code)
 	fmt.Fprintln(w, response)
 }
 
 // commentNotAllowlisted comments on the pull request that the repo is not
 // allowlisted unless allowlist error comments are disabled.
-func (e *VCSEventsController) commentNotAllowlisted(baseRepo models.Repo, pullNum int) {
+func (e *VCSEventsController) commentNotAllowlisted(logger logging.SimpleLogging, baseRepo models.Repo, pullNum int) {
 	if e.SilenceAllowlistErrors {
 		return
 	}
 
 	errMsg := "```\nError: This repo is not allowlisted for Atlantis.\n```"
-	if err := e.VCSClient.CreateComment(e.Logger, baseRepo, pullNum, errMsg, ""); err != nil {
-		e.Logger.Err("unable to comment on pull request: %s", err)
+	if err := e.VCSClient.CreateComment(logger, baseRepo, pullNum, errMsg, ""); err != nil {
+		logger.Err("unable to comment on pull request: %s", err)
 	}
 }
 
@@ -25,8 +25,10 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/drmaxgit/go-azuredevops/azuredevops"
 	"github.com/google/go-github/v71/github"
+	"github.com/google/uuid"
 	. "github.com/petergtz/pegomock/v4"
 	events_controllers "github.com/runatlantis/atlantis/server/controllers/events"
 	"github.com/runatlantis/atlantis/server/controllers/events/mocks"
@@ -34,6 +36,8 @@ import (
 	"github.com/runatlantis/atlantis/server/events/command"
 	emocks "github.com/runatlantis/atlantis/server/events/mocks"
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs/codecommit"
+	"github.com/runatlantis/atlantis/server/events/vcs/gerrit"
 	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
 	"github.com/runatlantis/atlantis/server/logging"
 	"github.com/runatlantis/atlantis/server/metrics/metricstest"
@@ -147,6 +151,97 @@ func TestPost_UnsupportedGiteaEvent(t *testing.T) {
 	ResponseContains(t, w, http.StatusOK, "Ignoring unsupported Gitea event")
 }
 
+func TestPost_InvalidGerritSecret(t *testing.T) {
+	t.Log("when the gerrit basic auth password doesn't match the secret a 401 is returned")
+	e, _, _, _, _, _, _, _, _ := setup(t)
+	e.SupportedVCSHosts = []models.VCSHostType{models.Gerrit}
+	e.GerritWebhookSecret = secret
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "", strings.NewReader(`{"type": "comment-added"}`))
+	req.SetBasicAuth("gerrit", "wrong")
+	e.Post(w, req)
+	ResponseContains(t, w, http.StatusUnauthorized, "request did not pass validation")
+}
+
+func TestPost_UnsupportedGerritEvent(t *testing.T) {
+	t.Log("when the event type is an unsupported gerrit event we ignore it")
+	e, _, _, _, _, _, _, _, _ := setup(t)
+	e.SupportedVCSHosts = []models.VCSHostType{models.Gerrit}
+	e.GerritWebhookSecret = secret
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "", strings.NewReader(`{"type": "ref-updated"}`))
+	req.SetBasicAuth("gerrit", string(secret))
+	e.Post(w, req)
+	ResponseContains(t, w, http.StatusOK, "Ignoring unsupported Gerrit event type: ref-updated")
+}
+
+func TestPost_GerritCommentSuccess(t *testing.T) {
+	t.Log("when the event is a gerrit review with a valid command we call the command handler")
+	e, _, _, _, p, cr, _, _, cp := setup(t)
+	e.SupportedVCSHosts = []models.VCSHostType{models.Gerrit}
+	cmd := events.CommentCommand{}
+	When(p.ParseGerritCommentEvent(Any[gerrit.Event]())).ThenReturn(models.Repo{}, models.User{}, 12345, "atlantis plan", nil)
+	When(cp.Parse(Eq("atlantis plan"), Eq(models.Gerrit))).ThenReturn(events.CommentParseResult{Command: &cmd})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "", strings.NewReader(`{"type": "comment-added"}`))
+	e.Post(w, req)
+	ResponseContains(t, w, http.StatusOK, "Processing...")
+
+	cr.VerifyWasCalledOnce().RunCommentCommand(Any[logging.SimpleLogging](), Eq(models.Repo{}), Eq[*models.Repo](nil), Eq[*models.PullRequest](nil), Eq(models.User{}), Eq(12345), Eq(&cmd))
+}
+
+func TestPost_InvalidCodeCommitSecret(t *testing.T) {
+	t.Log("when the codecommit basic auth password doesn't match the secret a 401 is returned")
+	e, _, _, _, _, _, _, _, _ := setup(t)
+	e.SupportedVCSHosts = []models.VCSHostType{models.CodeCommit}
+	e.CodeCommitWebhookSecret = secret
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "", strings.NewReader(`{"detail-type": "CodeCommit Comment on Pull Request"}`))
+	req.Header.Set("User-Agent", "Amazon/EventBridge/ApiDestinations")
+	req.SetBasicAuth("atlantis", "wrong")
+	e.Post(w, req)
+	ResponseContains(t, w, http.StatusUnauthorized, "request did not pass validation")
+}
+
+func TestPost_UnsupportedCodeCommitEvent(t *testing.T) {
+	t.Log("when the event type is an unsupported codecommit event we ignore it")
+	e, _, _, _, _, _, _, _, _ := setup(t)
+	e.SupportedVCSHosts = []models.VCSHostType{models.CodeCommit}
+	e.CodeCommitWebhookSecret = secret
+	w := httptest.NewRecorder()
+	// SNS wraps the events in its notifications.
+	req, _ := http.NewRequest("POST", "", strings.NewReader(`{"Type": "Notification", "Message": "{\"detail-type\": \"CodeCommit Repository State Change\"}"}`))
+	req.Header.Set("X-Amz-Sns-Message-Type", "Notification")
+	req.SetBasicAuth("atlantis", string(secret))
+	e.Post(w, req)
+	ResponseContains(t, w, http.StatusOK, "Ignoring unsupported CodeCommit event type: CodeCommit Repository State Change")
+}
+
+func TestPost_CodeCommitCommentSuccess(t *testing.T) {
+	t.Log("when the event is a codecommit comment with a valid command we call the command handler")
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Equals(t, "CodeCommit_20150413.GetComment", r.Header.Get("X-Amz-Target"))
+		fmt.Fprint(w, `{"comment": {"commentId": "abc", "content": "atlantis plan", "authorArn": "arn:aws:iam::123456789012:user/alice"}}`) // nolint: errcheck
+	}))
+	defer testServer.Close()
+	client, err := codecommit.New(http.DefaultClient, "us-east-1", testServer.URL, credentials.NewStaticCredentialsProvider("access-key", "secret-key", ""))
+	Ok(t, err)
+
+	e, _, _, _, p, cr, _, _, cp := setup(t)
+	e.SupportedVCSHosts = []models.VCSHostType{models.CodeCommit}
+	e.CodeCommitClient = client
+	cmd := events.CommentCommand{}
+	When(p.ParseCodeCommitCommentEvent(Any[codecommit.Event]())).ThenReturn(models.Repo{}, 7, "abc", nil)
+	When(cp.Parse(Eq("atlantis plan"), Eq(models.CodeCommit))).ThenReturn(events.CommentParseResult{Command: &cmd})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "", strings.NewReader(`{"detail-type": "CodeCommit Comment on Pull Request", "detail": {"event": "commentOnPullRequestCreated"}}`))
+	req.Header.Set("User-Agent", "Amazon/EventBridge/ApiDestinations")
+	e.Post(w, req)
+	ResponseContains(t, w, http.StatusOK, "Processing...")
+
+	cr.VerifyWasCalledOnce().RunCommentCommand(Any[logging.SimpleLogging](), Eq(models.Repo{}), Eq[*models.Repo](nil), Eq[*models.PullRequest](nil), Eq(models.User{Username: "alice"}), Eq(7), Eq(&cmd))
+}
+
 func TestPost_UnsupportedGitlabEvent(t *testing.T) {
 	t.Log("when the event type is an unsupported gitlab event we ignore it")
 	e, _, gl, _, _, _, _, _, _ := setup(t)
@@ -403,7 +498,28 @@ func TestPost_GitlabCommentSuccess(t *testing.T) {
 	e.Post(w, req)
 	ResponseContains(t, w, http.StatusOK, "Processing...")
 
-	cr.VerifyWasCalledOnce().RunCommentCommand(models.Repo{}, &models.Repo{}, nil, models.User{}, 0, &cmd)
+	cr.VerifyWasCalledOnce().RunCommentCommand(Any[logging.SimpleLogging](), Eq(models.Repo{}), Eq(&models.Repo{}), Eq[*models.PullRequest](nil), Eq(models.User{}), Eq(0), Eq(&cmd))
+}
+
+func TestPost_CorrelationID(t *testing.T) {
+	t.Log("every webhook gets a new correlation ID that's returned in the response")
+	e, _, gl, _, _, _, _, _, cp := setup(t)
+	When(gl.ParseAndValidate(Any[*http.Request](), Eq(secret))).ThenReturn(gitlab.MergeCommentEvent{}, nil)
+	When(cp.Parse(Any[string](), Eq(models.Gitlab))).ThenReturn(events.CommentParseResult{Ignore: true})
+
+	var ids []string
+	for range 2 {
+		req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+		req.Header.Set(gitlabHeader, "value")
+		w := httptest.NewRecorder()
+		e.Post(w, req)
+		ResponseContains(t, w, http.StatusOK, "Ignoring non-command comment")
+		id := w.Result().Header.Get(logging.CorrelationIDHeader)
+		_, err := uuid.Parse(id)
+		Ok(t, err)
+		ids = append(ids, id)
+	}
+	Assert(t, ids[0] != ids[1], "exp a new correlation ID for every webhook, got %s twice", ids[0])
 }
 
 func TestPost_GithubCommentSuccess(t *testing.T) {
@@ -422,7 +538,7 @@ func TestPost_GithubCommentSuccess(t *testing.T) {
 	e.Post(w, req)
 	ResponseContains(t, w, http.StatusOK, "Processing...")
 
-	cr.VerifyWasCalledOnce().RunCommentCommand(baseRepo, nil, nil, user, 1, &cmd)
+	cr.VerifyWasCalledOnce().RunCommentCommand(Any[logging.SimpleLogging](), Eq(baseRepo), Eq[*models.Repo](nil), Eq[*models.PullRequest](nil), Eq(user), Eq(1), Eq(&cmd))
 }
 
 func TestPost_GithubCommentReaction(t *testing.T) {
@@ -522,6 +638,26 @@ func TestPost_GitlabMergeRequestNotAllowlisted(t *testing.T) {
 	ResponseContains(t, w, http.StatusForbidden, "pull request event from non-allowlisted repo")
 }
 
+func TestPost_GitlabMergeRequestBranchNotAllowlisted(t *testing.T) {
+	t.Log("when the event is a gitlab merge request targeting a non-allowlisted branch we don't autoplan")
+	e, _, gl, _, p, cr, _, _, _ := setup(t)
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req.Header.Set(gitlabHeader, "value")
+
+	var err error
+	e.RepoAllowlistChecker, err = events.NewRepoAllowlistChecker("gitlab.com/owner/repo@main,gitlab.com/owner/repo@release/*")
+	Ok(t, err)
+	When(gl.ParseAndValidate(req, secret)).ThenReturn(gitlab.MergeEvent{}, nil)
+	repo := models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Hostname: "gitlab.com"}}
+	pullRequest := models.PullRequest{State: models.OpenPullState, BaseBranch: "feature"}
+	When(p.ParseGitlabMergeRequestEvent(gitlab.MergeEvent{})).ThenReturn(pullRequest, models.OpenedPullEvent, repo, repo, models.User{}, nil)
+
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	ResponseContains(t, w, http.StatusOK, `Ignoring pull request targeting non-allowlisted branch "feature"`)
+	cr.VerifyWasCalled(Never()).RunAutoplanCommand(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.Repo](), Any[models.PullRequest](), Any[models.User]())
+}
+
 func TestPost_GithubPullRequestUnsupportedAction(t *testing.T) {
 	t.Skip("relies too much on mocks, should use real event parser")
 	e, v, _, _, _, _, _, _, _ := setup(t)
@@ -913,8 +1049,8 @@ func TestPost_BBServerPullClosed(t *testing.T) {
 				},
 			}
 			pullCleaner.VerifyWasCalledOnce().CleanUpPull(
-				logger,
-				expRepo, models.PullRequest{
+				Any[logging.SimpleLogging](),
+				Eq(expRepo), Eq(models.PullRequest{
 					Num:        10,
 					HeadCommit: "2d9fb6b9a46eafb1dcef7b008d1a429d45ca742c",
 					URL:        "https://bbserver.com/projects/PROJ/repos/repository/pull-requests/10",
@@ -923,7 +1059,7 @@ func TestPost_BBServerPullClosed(t *testing.T) {
 					Author:     "admin",
 					State:      models.OpenPullState,
 					BaseRepo:   expRepo,
-				})
+				}))
 		})
 	}
 }
@@ -984,7 +1120,7 @@ func TestPost_PullOpenedOrUpdated(t *testing.T) {
 			w := httptest.NewRecorder()
 			e.Post(w, req)
 			ResponseContains(t, w, http.StatusOK, "Processing...")
-			cr.VerifyWasCalledOnce().RunAutoplanCommand(models.Repo{}, models.Repo{}, models.PullRequest{State: models.ClosedPullState}, models.User{})
+			cr.VerifyWasCalledOnce().RunAutoplanCommand(Any[logging.SimpleLogging](), Eq(models.Repo{}), Eq(models.Repo{}), Eq(models.PullRequest{State: models.ClosedPullState}), Eq(models.User{}))
 		})
 	}
 }
@@ -0,0 +1,252 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+const (
+	// redacted replaces the secrets of stored webhooks.
+	redacted = "<redacted>"
+	// maxWebhookResponse is how much of the response to a webhook is kept.
+	maxWebhookResponse = 4096
+)
+
+// secretNames are the substrings of the names of headers and payload fields
+// whose values are redacted.
+var secretNames = []string{"authorization", "cookie", "password", "secret", "signature", "token"}
+
+// ReceivedWebhook is a webhook Atlantis received and how it was handled.
+// Headers and Payload have their secrets redacted.
+type ReceivedWebhook struct {
+	// ID is the correlation ID of the webhook, see logging.CorrelationIDKey.
+	ID         string
+	ReceivedAt time.Time
+	Headers    map[string][]string
+	Payload    string
+	// StatusCode and Response are what Atlantis responded.
+	StatusCode int
+	Response   string
+	// ReplayOf is the ID of the webhook this one replayed, if it's a replay.
+	ReplayOf string
+
+	// header and body are what was received, to replay it.
+	header http.Header
+	body   []byte
+}
+
+// Event returns the event of the webhook according to the VCS host headers,
+// ex. issue_comment, or "" if it isn't known.
+func (w ReceivedWebhook) Event() string {
+	for _, header := range []string{githubHeader, gitlabHeader, giteaHeader, bitbucketEventTypeHeader} {
+		if event := http.Header(w.Headers).Get(header); event != "" {
+			return event
+		}
+	}
+	return ""
+}
+
+// WebhookHistory keeps the most recently received webhooks in memory to be
+// inspected and replayed from the UI, ex. to debug missed or misrouted
+// events.
+type WebhookHistory struct {
+	size     int
+	mutex    sync.Mutex
+	webhooks []ReceivedWebhook
+}
+
+// NewWebhookHistory returns a WebhookHistory keeping the last size webhooks.
+func NewWebhookHistory(size int) *WebhookHistory {
+	return &WebhookHistory{size: size}
+}
+
+// add stores webhook, dropping the oldest one if the history is full.
+func (h *WebhookHistory) add(webhook ReceivedWebhook) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.webhooks = append(h.webhooks, webhook)
+	if len(h.webhooks) > h.size {
+		h.webhooks = slices.Delete(h.webhooks, 0, len(h.webhooks)-h.size)
+	}
+}
+
+// List returns the webhooks, the most recently received first.
+func (h *WebhookHistory) List() []ReceivedWebhook {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	webhooks := slices.Clone(h.webhooks)
+	slices.Reverse(webhooks)
+	return webhooks
+}
+
+// get returns the webhook with id.
+func (h *WebhookHistory) get(id string) (ReceivedWebhook, bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	i := slices.IndexFunc(h.webhooks, func(w ReceivedWebhook) bool { return w.ID == id })
+	if i == -1 {
+		return ReceivedWebhook{}, false
+	}
+	return h.webhooks[i], true
+}
+
+// webhookRecorder records the response to a webhook, writing it to w too if
+// it isn't nil.
+type webhookRecorder struct {
+	w        http.ResponseWriter
+	header   http.Header
+	status   int
+	response bytes.Buffer
+}
+
+func (r *webhookRecorder) Header() http.Header {
+	if r.w != nil {
+		return r.w.Header()
+	}
+	if r.header == nil {
+		r.header = make(http.Header)
+	}
+	return r.header
+}
+
+func (r *webhookRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	if r.w != nil {
+		r.w.WriteHeader(status)
+	}
+}
+
+func (r *webhookRecorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	if room := maxWebhookResponse - r.response.Len(); room > 0 {
+		r.response.Write(p[:min(room, len(p))])
+	}
+	if r.w != nil {
+		return r.w.Write(p)
+	}
+	return len(p), nil
+}
+
+// handleWithHistory handles the webhook r with handle and stores it in the
+// history along with the response.
+func (e *VCSEventsController) handleWithHistory(w http.ResponseWriter, r *http.Request, replayOf string, handle http.HandlerFunc) *ReceivedWebhook {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		e.respond(e.Logger, w, logging.Warn, http.StatusBadRequest, "reading webhook: %s", err)
+		return nil
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	recorder := &webhookRecorder{w: w}
+	receivedAt := time.Now()
+	handle(recorder, r)
+
+	webhook := ReceivedWebhook{
+		ID:         recorder.Header().Get(logging.CorrelationIDHeader),
+		ReceivedAt: receivedAt,
+		Headers:    e.redactHeaders(r.Header),
+		Payload:    e.redactPayload(body),
+		StatusCode: recorder.status,
+		Response:   strings.TrimSpace(recorder.response.String()),
+		ReplayOf:   replayOf,
+		header:     r.Header.Clone(),
+		body:       body,
+	}
+	e.History.add(webhook)
+	return &webhook
+}
+
+// Replay handles the webhook with id in the history again, as if it was
+// received again, and returns the replay.
+func (e *VCSEventsController) Replay(id string) (*ReceivedWebhook, error) {
+	if e.History == nil {
+		return nil, fmt.Errorf("the webhook history isn't enabled")
+	}
+	original, ok := e.History.get(id)
+	if !ok {
+		return nil, fmt.Errorf("no webhook %s in the history", id)
+	}
+	r, err := http.NewRequest(http.MethodPost, "/events", bytes.NewReader(original.body))
+	if err != nil {
+		return nil, err
+	}
+	r.Header = original.header.Clone()
+	return e.handleWithHistory(&webhookRecorder{}, r, id, e.post), nil
+}
+
+// redactHeaders returns header with the values of secret headers redacted.
+func (e *VCSEventsController) redactHeaders(header http.Header) map[string][]string {
+	headers := make(map[string][]string, len(header))
+	for name, values := range header {
+		if isSecretName(name) {
+			values = []string{redacted}
+		}
+		headers[name] = values
+	}
+	return headers
+}
+
+// redactPayload returns body with the values of secret fields of JSON
+// payloads, and the configured webhook secrets, redacted.
+func (e *VCSEventsController) redactPayload(body []byte) string {
+	payload := string(body)
+	var decoded any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	// Numbers are kept as is, ex. IDs too large for float64.
+	decoder.UseNumber()
+	if decoder.Decode(&decoded) == nil {
+		if indented, err := json.MarshalIndent(redactJSON(decoded), "", "  "); err == nil {
+			payload = string(indented)
+		}
+	}
+	for _, secret := range [][]byte{
+		e.secret(&e.GithubWebhookSecret),
+		e.secret(&e.GitlabWebhookSecret),
+		e.secret(&e.BitbucketWebhookSecret),
+		e.secret(&e.GiteaWebhookSecret),
+		e.secret(&e.AzureDevopsWebhookBasicPassword),
+	} {
+		if len(secret) > 0 {
+			payload = strings.ReplaceAll(payload, string(secret), redacted)
+		}
+	}
+	return payload
+}
+
+func redactJSON(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if _, isString := field.(string); isString && isSecretName(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redactJSON(field)
+			}
+		}
+	case []any:
+		for i := range v {
+			v[i] = redactJSON(v[i])
+		}
+	}
+	return value
+}
+
+func isSecretName(name string) bool {
+	name = strings.ToLower(name)
+	return slices.ContainsFunc(secretNames, func(s string) bool { return strings.Contains(name, s) })
+}
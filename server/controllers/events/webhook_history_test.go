@@ -0,0 +1,64 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/petergtz/pegomock/v4"
+	events_controllers "github.com/runatlantis/atlantis/server/controllers/events"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestPost_WebhookHistory(t *testing.T) {
+	e, v, _, _, _, _, _, _, _ := setup(t)
+	e.History = events_controllers.NewWebhookHistory(2)
+	When(v.Validate(Any[*http.Request](), Any[[]byte]())).ThenReturn([]byte(`{"not an event": ""}`), nil)
+	post := func(body string) {
+		req, _ := http.NewRequest("POST", "/events", bytes.NewBufferString(body))
+		req.Header.Set(githubHeader, "value")
+		req.Header.Set("X-Hub-Signature-256", "sha256=abc")
+		w := httptest.NewRecorder()
+		e.Post(w, req)
+		ResponseContains(t, w, http.StatusOK, "Ignoring unsupported event")
+	}
+
+	post(`{"installation": {"access_token": "ghs_abc"}, "comment": {"body": "the secret", "id": 12345678901234567890}}`)
+	webhooks := e.History.List()
+	Equals(t, 1, len(webhooks))
+	webhook := webhooks[0]
+	Assert(t, webhook.ID != "", "exp the webhook to have the correlation ID")
+	Equals(t, "value", webhook.Event())
+	Equals(t, http.StatusOK, webhook.StatusCode)
+	Assert(t, strings.Contains(webhook.Response, "Ignoring unsupported event"), "exp the response to be kept, got %q", webhook.Response)
+	Equals(t, []string{"<redacted>"}, webhook.Headers["X-Hub-Signature-256"])
+	Assert(t, !strings.Contains(webhook.Payload, "ghs_abc"), "exp tokens to be redacted, got %s", webhook.Payload)
+	Assert(t, !strings.Contains(webhook.Payload, "secret"), "exp the webhook secret to be redacted, got %s", webhook.Payload)
+	Assert(t, strings.Contains(webhook.Payload, "12345678901234567890"), "exp numbers to be kept as is, got %s", webhook.Payload)
+
+	t.Log("replays are stored too")
+	replay, err := e.Replay(webhook.ID)
+	Ok(t, err)
+	Equals(t, webhook.ID, replay.ReplayOf)
+	Equals(t, http.StatusOK, replay.StatusCode)
+	Assert(t, replay.ID != webhook.ID, "exp the replay to have its own ID")
+
+	t.Log("the oldest webhooks are dropped")
+	post(`{}`)
+	webhooks = e.History.List()
+	Equals(t, 2, len(webhooks))
+	Equals(t, replay.ID, webhooks[1].ID)
+	_, err = e.Replay(webhook.ID)
+	ErrContains(t, "no webhook", err)
+}
+
+func TestReplay_WithoutHistory(t *testing.T) {
+	e, _, _, _, _, _, _, _, _ := setup(t)
+	_, err := e.Replay("id")
+	ErrEquals(t, "the webhook history isn't enabled", err)
+}
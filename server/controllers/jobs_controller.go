@@ -7,13 +7,21 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/runatlantis/atlantis/server/controllers/web_templates"
 	"github.com/runatlantis/atlantis/server/controllers/websocket"
 	"github.com/runatlantis/atlantis/server/core/db"
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/jobs"
 	"github.com/runatlantis/atlantis/server/logging"
 	"github.com/runatlantis/atlantis/server/metrics"
+	"github.com/runatlantis/atlantis/server/webauth"
 	tally "github.com/uber-go/tally/v4"
 )
 
@@ -38,6 +46,16 @@ type JobsController struct {
 	WsMux                    *websocket.Multiplexor       `validate:"required"`
 	KeyGenerator             JobIDKeyGenerator
 	StatsScope               tally.Scope `validate:"required"`
+	// Canceller cancels the commands of pull requests from the UI.
+	Canceller *events.CancelCommandRunner
+	// OutputHandler finds the jobs of runs. It may be nil.
+	OutputHandler jobs.ProjectCommandOutputHandler
+	// WorkingDir finds the stored plans shown on the pages of plan jobs. It
+	// may be nil, then the pages only stream the jobs' logs.
+	WorkingDir events.WorkingDir
+	// PlanfileEncryptor decrypts the stored plans. Nil if planfiles aren't
+	// encrypted.
+	PlanfileEncryptor *runtime.PlanfileEncryptor
 }
 
 func (j *JobsController) getProjectJobs(w http.ResponseWriter, r *http.Request) error {
@@ -47,16 +65,57 @@ func (j *JobsController) getProjectJobs(w http.ResponseWriter, r *http.Request)
 		j.respond(w, logging.Error, http.StatusBadRequest, "%s", err.Error())
 		return err
 	}
+	if !j.canView(r, jobID) {
+		j.respond(w, logging.Warn, http.StatusForbidden, "%s isn't allowed to view job %s", webauth.Username(r), jobID)
+		return nil
+	}
 
 	viewData := web_templates.ProjectJobData{
 		AtlantisVersion: j.AtlantisVersion,
 		ProjectPath:     jobID,
 		CleanedBasePath: j.AtlantisURL.Path,
 	}
+	if r.URL.Query().Get("view") != "log" {
+		viewData.Plan = j.storedPlan(jobID)
+	}
 
 	return j.ProjectJobsTemplate.Execute(w, viewData)
 }
 
+// storedPlan returns the view of the stored plan of the project of the job
+// with jobID if it's a plan job, nil if there's none.
+func (j *JobsController) storedPlan(jobID string) *web_templates.PlanView {
+	if j.OutputHandler == nil || j.WorkingDir == nil {
+		return nil
+	}
+	for _, pull := range j.OutputHandler.GetPullToJobMapping() {
+		for _, job := range pull.JobIDInfos {
+			if job.JobID != jobID {
+				continue
+			}
+			if job.JobStep != command.Plan.String() {
+				return nil
+			}
+			output := events.LoadSavedPlan(j.WorkingDir, j.PlanfileEncryptor, command.ProjectContext{
+				Pull:        models.PullRequest{Num: pull.Pull.PullNum, BaseRepo: models.Repo{FullName: pull.Pull.RepoFullName}},
+				RepoRelDir:  pull.Pull.Path,
+				Workspace:   pull.Pull.Workspace,
+				ProjectName: pull.Pull.ProjectName,
+			})
+			if output.PlanSuccess == nil {
+				return nil
+			}
+			view := NewPlanView(output.PlanSuccess.TerraformOutput)
+			view.Project = fmt.Sprintf("%s#%d dir: %s workspace: %s", pull.Pull.RepoFullName, pull.Pull.PullNum, pull.Pull.Path, pull.Pull.Workspace)
+			if pull.Pull.ProjectName != "" {
+				view.Project = fmt.Sprintf("%s#%d project: %s", pull.Pull.RepoFullName, pull.Pull.PullNum, pull.Pull.ProjectName)
+			}
+			return view
+		}
+	}
+	return nil
+}
+
 func (j *JobsController) GetProjectJobs(w http.ResponseWriter, r *http.Request) {
 	errorCounter := j.StatsScope.SubScope("getprojectjobs").Counter(metrics.ExecutionErrorMetric)
 	err := j.getProjectJobs(w, r)
@@ -67,6 +126,10 @@ func (j *JobsController) GetProjectJobs(w http.ResponseWriter, r *http.Request)
 }
 
 func (j *JobsController) getProjectJobsWS(w http.ResponseWriter, r *http.Request) error {
+	if jobID, err := j.KeyGenerator.Generate(r); err == nil && !j.canView(r, jobID) {
+		j.respond(w, logging.Warn, http.StatusForbidden, "%s isn't allowed to view job %s", webauth.Username(r), jobID)
+		return nil
+	}
 	err := j.WsMux.Handle(w, r)
 
 	if err != nil {
@@ -90,6 +153,119 @@ func (j *JobsController) GetProjectJobsWS(w http.ResponseWriter, r *http.Request
 	}
 }
 
+// GetRun redirects to the page of the first job of the run in the run-id
+// route variable, the ID in the footer of its comments.
+func (j *JobsController) GetRun(w http.ResponseWriter, r *http.Request) {
+	runID := mux.Vars(r)["run-id"]
+	var first *jobs.JobIDInfo
+	var firstPull jobs.PullInfo
+	if j.OutputHandler != nil {
+		for _, pull := range j.OutputHandler.GetPullToJobMapping() {
+			for _, job := range pull.JobIDInfos {
+				if job.RunID == runID && (first == nil || job.Time.Before(first.Time)) {
+					first = &job
+					firstPull = pull.Pull
+				}
+			}
+		}
+	}
+	if first == nil {
+		j.respond(w, logging.Debug, http.StatusNotFound, "No jobs of run %s, its logs have a %s starting with %s", runID, logging.CorrelationIDKey, runID)
+		return
+	}
+	if !webauth.Allowed(r, webauth.ViewJobs, firstPull.RepoFullName, firstPull.VCSHostname) {
+		j.respond(w, logging.Warn, http.StatusForbidden, "%s isn't allowed to view the jobs of %s", webauth.Username(r), firstPull.RepoFullName)
+		return
+	}
+	http.Redirect(w, r, j.AtlantisURL.Path+"/jobs/"+url.PathEscape(first.JobID), http.StatusFound)
+}
+
+// CancelPull cancels the commands of the pull request in the repo and pull
+// query parameters, like commenting atlantis cancel does.
+func (j *JobsController) CancelPull(w http.ResponseWriter, r *http.Request) {
+	repoFullName := r.URL.Query().Get("repo")
+	pullNum, err := strconv.Atoi(r.URL.Query().Get("pull"))
+	if repoFullName == "" || err != nil {
+		j.respond(w, logging.Warn, http.StatusBadRequest, "No repo or pull in request")
+		return
+	}
+	if j.Canceller == nil {
+		j.respond(w, logging.Warn, http.StatusBadRequest, "Cancelling isn't enabled")
+		return
+	}
+
+	// Anyone who can reach the UI could otherwise cancel the commands of any
+	// pull request.
+	if webauth.FromRequest(r) == nil {
+		j.respond(w, logging.Warn, http.StatusForbidden, "Cancelling from the UI requires web authentication, see --web-basic-auth and --web-oidc-issuer-url")
+		return
+	}
+	host := r.URL.Query().Get("host")
+	tracked, err := j.trackedPull(repoFullName, pullNum, host)
+	if err != nil {
+		j.respond(w, logging.Error, http.StatusInternalServerError, "Could not retrieve the status of the pull request: %s", err)
+		return
+	}
+	// The commands are cancelled by repo full name so the host in the query
+	// is only trusted if the pull request is tracked on it, otherwise
+	// cancelling requires permissions for all repos.
+	allowedRepo := ""
+	if tracked {
+		allowedRepo = repoFullName
+	}
+	user := webauth.Username(r)
+	if !webauth.Allowed(r, webauth.TriggerApplies, allowedRepo, host) {
+		j.respond(w, logging.Warn, http.StatusForbidden, "%s isn't allowed to cancel the commands of %s", user, repoFullName)
+		return
+	}
+	j.Logger.Info("cancelling the commands of %s#%d requested by %s from %s", repoFullName, pullNum, user, r.RemoteAddr)
+
+	interrupted, err := j.Canceller.CancelPull(j.Logger, models.PullRequest{Num: pullNum, BaseRepo: models.Repo{FullName: repoFullName, VCSHost: models.VCSHost{Hostname: host}}})
+	if err != nil {
+		j.respond(w, logging.Error, http.StatusInternalServerError, "cancelling failed with: '%s'", err)
+		return
+	}
+	j.respond(w, logging.Info, http.StatusOK, "Cancelled the commands of %s#%d and interrupted %d running processes, requested by %s from %s",
+		repoFullName, pullNum, interrupted, user, r.RemoteAddr)
+}
+
+// trackedPull returns true if the pull request pullNum of the repo is
+// tracked on hostname: it has jobs or a status.
+func (j *JobsController) trackedPull(repoFullName string, pullNum int, hostname string) (bool, error) {
+	if strings.Contains(hostname, "/") {
+		return false, nil
+	}
+	if j.OutputHandler != nil {
+		for _, pull := range j.OutputHandler.GetPullToJobMapping() {
+			if pull.Pull.RepoFullName == repoFullName && pull.Pull.VCSHostname == hostname && pull.Pull.PullNum == pullNum {
+				return true, nil
+			}
+		}
+	}
+	pullStatus, err := j.Database.GetPullStatus(models.PullRequest{
+		Num:      pullNum,
+		BaseRepo: models.Repo{FullName: repoFullName, VCSHost: models.VCSHost{Hostname: hostname}},
+	})
+	return pullStatus != nil, err
+}
+
+// canView returns true if the user of r is allowed to view the job with
+// jobID. Jobs that aren't known, or whose repo isn't, are only viewable with
+// permissions for all repos.
+func (j *JobsController) canView(r *http.Request, jobID string) bool {
+	var repoFullName, hostname string
+	if j.OutputHandler != nil {
+		for _, pull := range j.OutputHandler.GetPullToJobMapping() {
+			for _, job := range pull.JobIDInfos {
+				if job.JobID == jobID {
+					repoFullName, hostname = pull.Pull.RepoFullName, pull.Pull.VCSHostname
+				}
+			}
+		}
+	}
+	return webauth.Allowed(r, webauth.ViewJobs, repoFullName, hostname)
+}
+
 func (j *JobsController) respond(w http.ResponseWriter, lvl logging.LogLevel, responseCode int, format string, args ...any) {
 	response := fmt.Sprintf(format, args...)
 	j.Logger.Log(lvl, response)
@@ -0,0 +1,67 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/controllers"
+	"github.com/runatlantis/atlantis/server/core/boltdb"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/jobs"
+	jobmocks "github.com/runatlantis/atlantis/server/jobs/mocks"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/webauth"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestJobsController_CancelPull(t *testing.T) {
+	RegisterMockTestingT(t)
+	database, err := boltdb.New(t.TempDir())
+	Ok(t, err)
+	outputHandler := jobmocks.NewMockProjectCommandOutputHandler()
+	When(outputHandler.GetPullToJobMapping()).ThenReturn([]jobs.PullInfoWithJobIDs{{
+		Pull:       jobs.PullInfo{PullNum: 1, RepoFullName: "owner/repo", VCSHostname: "github.com", Path: ".", Workspace: "default"},
+		JobIDInfos: []jobs.JobIDInfo{{JobID: "job-id"}},
+	}})
+	cancellationTracker := events.NewCancellationTracker()
+	workingDirLocker := events.NewDefaultWorkingDirLocker()
+	j := controllers.JobsController{
+		Logger:        logging.NewNoopLogger(t),
+		Database:      database,
+		OutputHandler: outputHandler,
+		Canceller: events.NewCancelCommandRunner(nil, &events.DefaultProjectCommandRunner{
+			CancellationTracker: cancellationTracker,
+			WorkingDirLocker:    workingDirLocker,
+		}, nil, workingDirLocker, false),
+	}
+	permissionsPath := filepath.Join(t.TempDir(), "permissions.yaml")
+	Ok(t, os.WriteFile(permissionsPath, []byte("groups:\n- name: infra\n  capabilities: [trigger_applies]\n  repos: github.com/owner/repo,github.com/other/infra-*"), 0600))
+	permissions, err := webauth.LoadPermissions(permissionsPath)
+	Ok(t, err)
+	infra := &webauth.User{Name: "infra-user", Groups: []string{"infra"}, Permissions: permissions}
+	cancel := func(query string, user *webauth.User) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/jobs/cancel?"+query, nil)
+		req = req.WithContext(webauth.NewContext(req.Context(), user))
+		req.RemoteAddr = "10.0.0.1:1234"
+		w := httptest.NewRecorder()
+		j.CancelPull(w, req)
+		return w
+	}
+
+	t.Log("the host in the query is only trusted if the pull request is tracked on it")
+	secret := models.PullRequest{Num: 1, BaseRepo: models.Repo{FullName: "owner/secret"}}
+	ResponseContains(t, cancel("repo=owner%2Fsecret&pull=1&host=github.com%2Fother%2Finfra-x", infra), http.StatusForbidden, "infra-user isn't allowed to cancel the commands of owner/secret")
+	ResponseContains(t, cancel("repo=owner%2Frepo&pull=1&host=gitlab.com", infra), http.StatusForbidden, "infra-user isn't allowed to cancel the commands of owner/repo")
+	Assert(t, !cancellationTracker.IsCancelled(secret), "expected the pull request not to be cancelled")
+
+	ResponseContains(t, cancel("repo=owner%2Frepo&pull=1&host=github.com", infra), http.StatusOK, "Cancelled the commands of owner/repo#1 and interrupted 0 running processes, requested by infra-user from 10.0.0.1:1234")
+	Assert(t, cancellationTracker.IsCancelled(models.PullRequest{Num: 1, BaseRepo: models.Repo{FullName: "owner/repo"}}), "expected the pull request to be cancelled")
+}
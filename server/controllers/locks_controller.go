@@ -17,6 +17,7 @@ import (
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
 	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/webauth"
 )
 
 // LocksController handles all requests relating to Atlantis locks.
@@ -32,6 +33,10 @@ type LocksController struct {
 	WorkingDirLocker   events.WorkingDirLocker      `validate:"required"`
 	Database           db.Database                  `validate:"required"`
 	DeleteLockCommand  events.DeleteLockCommand     `validate:"required"`
+	// StateLocks are the Terraform state locks that can be force-unlocked.
+	StateLocks *events.StateLockTracker
+	// WebAuthentication is whether the UI requires authentication.
+	WebAuthentication bool
 }
 
 // LockApply handles creating a global apply lock.
@@ -80,6 +85,10 @@ func (l *LocksController) GetLock(w http.ResponseWriter, r *http.Request) {
 		l.respond(w, logging.Info, http.StatusNotFound, "No lock found at id '%s'", idUnencoded)
 		return
 	}
+	if !webauth.Allowed(r, webauth.ViewJobs, lock.Project.RepoFullName, lock.Pull.BaseRepo.VCSHost.Hostname) {
+		l.respond(w, logging.Warn, http.StatusForbidden, "%s isn't allowed to view the locks of %s", webauth.Username(r), lock.Project.RepoFullName)
+		return
+	}
 
 	owner, repo := models.SplitRepoFullName(lock.Project.RepoFullName)
 	viewData := web_templates.LockDetailData{
@@ -115,6 +124,19 @@ func (l *LocksController) DeleteLock(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The repo of the lock is only needed to authorize the user.
+	if webauth.FromRequest(r) != nil {
+		existing, err := l.Locker.GetLock(idUnencoded)
+		if err != nil {
+			l.respond(w, logging.Error, http.StatusInternalServerError, "Failed getting lock: %s", err)
+			return
+		}
+		if existing != nil && !webauth.Allowed(r, webauth.ReleaseLocks, existing.Project.RepoFullName, existing.Pull.BaseRepo.VCSHost.Hostname) {
+			l.respond(w, logging.Warn, http.StatusForbidden, "%s isn't allowed to release the locks of %s", webauth.Username(r), existing.Project.RepoFullName)
+			return
+		}
+	}
+
 	lock, err := l.DeleteLockCommand.DeleteLock(l.Logger, idUnencoded)
 	if err != nil {
 		l.respond(w, logging.Error, http.StatusInternalServerError, "deleting lock failed with: '%s'", err)
@@ -126,24 +148,80 @@ func (l *LocksController) DeleteLock(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// NOTE: Because BaseRepo was added to the PullRequest model later, previous
-	// installations of Atlantis will have locks in their DB that do not have
-	// this field on PullRequest. We skip commenting in this case.
-	if lock.Pull.BaseRepo != (models.Repo{}) {
-		if err := l.Database.UpdateProjectStatus(lock.Pull, lock.Workspace, lock.Project.Path, models.DiscardedPlanStatus); err != nil {
-			l.Logger.Err("unable to update project status: %s", err)
+	discardLockedPlan(l.Logger, l.Database, l.VCSClient, lock, "the Atlantis UI")
+	l.respond(w, logging.Info, http.StatusOK, "Deleted lock id '%s'", id)
+}
+
+// ForceUnlockState handles force-unlocking the Terraform state lock at id and
+// commenting back on the pull request that failed on it. Force-unlocks are
+// logged along with who requested them for auditing.
+func (l *LocksController) ForceUnlockState(w http.ResponseWriter, r *http.Request) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok || id == "" {
+		l.respond(w, logging.Warn, http.StatusBadRequest, "No state lock id in request")
+		return
+	}
+
+	user := webauth.Username(r)
+	if !l.WebAuthentication {
+		l.respond(w, logging.Warn, http.StatusForbidden, "Force-unlocking state locks from the UI requires --web-basic-auth or --web-oidc-issuer-url")
+		return
+	}
+	// Locks that aren't tracked, ex. because Atlantis restarted, may belong
+	// to any repo so they require the permission in all repos.
+	if tracked, ok := l.StateLocks.Get(id); ok {
+		if !webauth.Allowed(r, webauth.ReleaseLocks, tracked.BaseRepo.FullName, tracked.BaseRepo.VCSHost.Hostname) {
+			l.respond(w, logging.Warn, http.StatusForbidden, "%s isn't allowed to force-unlock the state locks of %s", user, tracked.BaseRepo.FullName)
+			return
 		}
+	} else if !webauth.Allowed(r, webauth.ReleaseLocks, "", "") {
+		l.respond(w, logging.Warn, http.StatusForbidden, "%s isn't allowed to force-unlock state locks", user)
+		return
+	}
+	l.Logger.Info("state lock %q force-unlock requested by %s from %s", id, user, r.RemoteAddr)
+
+	lock, err := l.StateLocks.ForceUnlock(id)
+	if err != nil {
+		l.respond(w, logging.Error, http.StatusInternalServerError, "force-unlocking state lock failed with: '%s'", err)
+		return
+	}
+	if lock == nil {
+		l.respond(w, logging.Info, http.StatusNotFound, "No state lock found at id '%s'", id)
+		return
+	}
 
-		// Once the lock has been deleted, comment back on the pull request.
-		comment := fmt.Sprintf("**Warning**: The plan for dir: `%s` workspace: `%s` was **discarded** via the Atlantis UI.\n\n"+
-			"To `apply` this plan you must run `plan` again.", lock.Project.Path, lock.Workspace)
-		if err = l.VCSClient.CreateComment(l.Logger, lock.Pull.BaseRepo, lock.Pull.Num, comment, ""); err != nil {
+	if lock.PullNum != 0 {
+		comment := fmt.Sprintf("**Warning**: The Terraform state lock `%s` held by `%s` for dir: `%s` workspace: `%s` was **force-unlocked** by %s via the Atlantis UI.\n\n"+
+			"Run `plan` again to retry.", id, lock.Lock.Who, lock.RepoRelDir, lock.Workspace, user)
+		if err := l.VCSClient.CreateComment(l.Logger, lock.BaseRepo, lock.PullNum, comment, ""); err != nil {
 			l.Logger.Warn("failed commenting on pull request: %s", err)
 		}
-	} else {
-		l.Logger.Debug("skipping commenting on pull request and deleting workspace because BaseRepo field is empty")
 	}
-	l.respond(w, logging.Info, http.StatusOK, "Deleted lock id '%s'", id)
+	l.respond(w, logging.Info, http.StatusOK, "Force-unlocked state lock '%s' held by %s for %s dir: %s workspace: %s, requested by %s from %s",
+		id, lock.Lock.Who, lock.BaseRepo.FullName, lock.RepoRelDir, lock.Workspace, user, r.RemoteAddr)
+}
+
+// discardLockedPlan marks the plan of a deleted lock as discarded and comments
+// back on the pull request. via is where the lock was deleted from, ex. the
+// Atlantis UI.
+func discardLockedPlan(logger logging.SimpleLogging, database db.Database, vcsClient vcs.Client, lock *models.ProjectLock, via string) {
+	// NOTE: Because BaseRepo was added to the PullRequest model later, previous
+	// installations of Atlantis will have locks in their DB that do not have
+	// this field on PullRequest. We skip commenting in this case.
+	if lock.Pull.BaseRepo == (models.Repo{}) {
+		logger.Debug("skipping commenting on pull request and deleting workspace because BaseRepo field is empty")
+		return
+	}
+	if err := database.UpdateProjectStatus(lock.Pull, lock.Workspace, lock.Project.Path, models.DiscardedPlanStatus); err != nil {
+		logger.Err("unable to update project status: %s", err)
+	}
+
+	// Once the lock has been deleted, comment back on the pull request.
+	comment := fmt.Sprintf("**Warning**: The plan for dir: `%s` workspace: `%s` was **discarded** via %s.\n\n"+
+		"To `apply` this plan you must run `plan` again.", lock.Project.Path, lock.Workspace, via)
+	if err := vcsClient.CreateComment(logger, lock.Pull.BaseRepo, lock.Pull.Num, comment, ""); err != nil {
+		logger.Warn("failed commenting on pull request: %s", err)
+	}
 }
 
 // respond is a helper function to respond and log the response. lvl is the log
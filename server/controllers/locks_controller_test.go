@@ -10,6 +10,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -25,11 +27,13 @@ import (
 	"github.com/runatlantis/atlantis/server/events"
 
 	"github.com/runatlantis/atlantis/server/core/locking/mocks"
+	tfclientmocks "github.com/runatlantis/atlantis/server/core/terraform/tfclient/mocks"
 	"github.com/runatlantis/atlantis/server/events/command"
 	mocks2 "github.com/runatlantis/atlantis/server/events/mocks"
 	"github.com/runatlantis/atlantis/server/events/models"
 	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
 	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/webauth"
 	. "github.com/runatlantis/atlantis/testing"
 )
 
@@ -411,3 +415,57 @@ func TestDeleteLock_CommentSuccess(t *testing.T) {
 		Eq("**Warning**: The plan for dir: `path` workspace: `workspace` was **discarded** via the Atlantis UI.\n\n"+
 			"To `apply` this plan you must run `plan` again."), Eq(""))
 }
+
+func TestForceUnlockState(t *testing.T) {
+	RegisterMockTestingT(t)
+	cp := vcsmocks.NewMockClient()
+	repoDir := t.TempDir()
+	pull := models.PullRequest{Num: 2, BaseRepo: models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Hostname: "github.com"}}}
+	workingDir := mocks2.NewMockWorkingDir()
+	When(workingDir.GetWorkingDir(pull.BaseRepo, pull, "default")).ThenReturn(repoDir, nil)
+	tf := tfclientmocks.NewMockClient()
+	stateLocks := &events.StateLockTracker{
+		TerraformExecutor: tf,
+		WorkingDir:        workingDir,
+		WorkingDirLocker:  events.NewDefaultWorkingDirLocker(),
+	}
+	stateLocks.Track(command.ProjectContext{
+		Log:        logging.NewNoopLogger(t),
+		Pull:       pull,
+		RepoRelDir: ".",
+		Workspace:  "default",
+	}, errors.New("Error: Error acquiring the state lock\n\nLock Info:\n  ID:        lock-id\n  Who:       someone@host\n"))
+	permissionsPath := filepath.Join(t.TempDir(), "permissions.yaml")
+	Ok(t, os.WriteFile(permissionsPath, []byte("groups:\n- name: infra\n  capabilities: [release_locks]\n  repos: github.com/owner/repo"), 0600))
+	permissions, err := webauth.LoadPermissions(permissionsPath)
+	Ok(t, err)
+	infra := &webauth.User{Name: "infra-user", Groups: []string{"infra"}, Permissions: permissions}
+	lc := controllers.LocksController{
+		Logger:            logging.NewNoopLogger(t),
+		VCSClient:         cp,
+		StateLocks:        stateLocks,
+		WebAuthentication: true,
+	}
+	forceUnlock := func(lc controllers.LocksController, id string, user *webauth.User) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "", bytes.NewBuffer(nil))
+		req = mux.SetURLVars(req, map[string]string{"id": id})
+		req = req.WithContext(webauth.NewContext(req.Context(), user))
+		req.RemoteAddr = "10.0.0.1:1234"
+		w := httptest.NewRecorder()
+		lc.ForceUnlockState(w, req)
+		return w
+	}
+
+	disabled := lc
+	disabled.WebAuthentication = false
+	ResponseContains(t, forceUnlock(disabled, "lock-id", nil), http.StatusForbidden, "Force-unlocking state locks from the UI requires --web-basic-auth or --web-oidc-issuer-url")
+
+	// Untracked locks may belong to any repo.
+	ResponseContains(t, forceUnlock(lc, "unknown", infra), http.StatusForbidden, "infra-user isn't allowed to force-unlock state locks")
+	ResponseContains(t, forceUnlock(lc, "unknown", &webauth.User{Name: "admin"}), http.StatusNotFound, "No state lock found at id 'unknown'")
+
+	ResponseContains(t, forceUnlock(lc, "lock-id", infra), http.StatusOK, "Force-unlocked state lock 'lock-id' held by someone@host for owner/repo dir: . workspace: default, requested by infra-user from 10.0.0.1:1234")
+	cp.VerifyWasCalled(Once()).CreateComment(Any[logging.SimpleLogging](), Eq(pull.BaseRepo), Eq(pull.Num),
+		Eq("**Warning**: The Terraform state lock `lock-id` held by `someone@host` for dir: `.` workspace: `default` was **force-unlocked** by infra-user via the Atlantis UI.\n\n"+
+			"Run `plan` again to retry."), Eq(""))
+}
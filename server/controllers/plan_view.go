@@ -0,0 +1,130 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/controllers/web_templates"
+)
+
+// minFoldedLines is the number of consecutive unchanged lines from which they
+// are folded in the plan view.
+const minFoldedLines = 8
+
+var (
+	// planResourceHeaderRegex matches the line starting the changes of a
+	// resource, ex. "  # aws_instance.web will be updated in-place".
+	planResourceHeaderRegex = regexp.MustCompile(`^\s*# (\S+) ((?:will|must|has|is) .+)$`)
+	// planRefreshRegex matches the lines of terraform refreshing the state.
+	planRefreshRegex = regexp.MustCompile(`: (?:Refreshing state\.\.\.|Reading\.\.\.|Read complete after)`)
+	// planUnsafeAnchorRegex matches what's replaced in resource addresses to
+	// use them as anchors.
+	planUnsafeAnchorRegex = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+)
+
+// NewPlanView splits the output of a plan into lines highlighted by the change
+// they show, folding the runs of unchanged lines and linking the resources it
+// changes to their lines.
+func NewPlanView(output string) *web_templates.PlanView {
+	view := &web_templates.PlanView{}
+	anchors := map[string]bool{}
+	var lines []web_templates.PlanLine
+	for _, text := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		line := web_templates.PlanLine{Text: text, Class: planLineClass(text)}
+		if match := planResourceHeaderRegex.FindStringSubmatch(text); match != nil {
+			line.Anchor = uniqueAnchor(anchors, "resource-"+planUnsafeAnchorRegex.ReplaceAllString(match[1], "-"))
+			view.Resources = append(view.Resources, web_templates.PlanResource{
+				Address: match[1],
+				Action:  match[2],
+				Anchor:  line.Anchor,
+			})
+		}
+		lines = append(lines, line)
+	}
+
+	// Fold the runs of unchanged lines.
+	start := 0
+	endRun := func(end int) {
+		if end-start >= minFoldedLines {
+			view.Blocks = append(view.Blocks, web_templates.PlanBlock{Folded: true, Lines: lines[start:end]})
+		} else {
+			view.Blocks = appendUnfolded(view.Blocks, lines[start:end])
+		}
+	}
+	for i, line := range lines {
+		if foldable(line) {
+			continue
+		}
+		endRun(i)
+		view.Blocks = appendUnfolded(view.Blocks, lines[i:i+1])
+		start = i + 1
+	}
+	endRun(len(lines))
+	return view
+}
+
+// appendUnfolded appends lines to the last block of blocks if it isn't
+// folded, or to a new block.
+func appendUnfolded(blocks []web_templates.PlanBlock, lines []web_templates.PlanLine) []web_templates.PlanBlock {
+	if len(lines) == 0 {
+		return blocks
+	}
+	if len(blocks) > 0 && !blocks[len(blocks)-1].Folded {
+		last := &blocks[len(blocks)-1]
+		last.Lines = append(last.Lines, lines...)
+		return blocks
+	}
+	return append(blocks, web_templates.PlanBlock{Lines: slices.Clone(lines)})
+}
+
+// planLineClass returns the CSS class highlighting a line of a plan output by
+// the change it shows.
+func planLineClass(text string) string {
+	trimmed := strings.TrimLeft(text, " │")
+	switch {
+	case planResourceHeaderRegex.MatchString(text):
+		return "plan-resource"
+	case strings.HasPrefix(trimmed, "-/+ "), strings.HasPrefix(trimmed, "+/- "):
+		return "plan-replace"
+	case strings.HasPrefix(trimmed, "+ "):
+		return "plan-add"
+	case strings.HasPrefix(trimmed, "- "):
+		return "plan-destroy"
+	case strings.HasPrefix(trimmed, "~ "):
+		return "plan-change"
+	case strings.HasPrefix(trimmed, "<= "):
+		return "plan-read"
+	case strings.HasPrefix(trimmed, "# ("):
+		return "plan-hidden"
+	case strings.HasPrefix(trimmed, "Plan: "), strings.HasPrefix(trimmed, "No changes."), strings.HasPrefix(trimmed, "Changes to Outputs:"):
+		return "plan-summary"
+	case strings.HasPrefix(trimmed, "Warning:"):
+		return "plan-warning"
+	case strings.HasPrefix(trimmed, "Error:"):
+		return "plan-error"
+	case planRefreshRegex.MatchString(text):
+		return "plan-refresh"
+	}
+	return ""
+}
+
+// foldable returns true if line doesn't show a change, so it can be folded.
+func foldable(line web_templates.PlanLine) bool {
+	return line.Class == "" || line.Class == "plan-refresh" || line.Class == "plan-hidden"
+}
+
+// uniqueAnchor returns anchor, suffixed with a number if it's in anchors, and
+// adds it to anchors.
+func uniqueAnchor(anchors map[string]bool, anchor string) string {
+	unique := anchor
+	for i := 2; anchors[unique]; i++ {
+		unique = fmt.Sprintf("%s-%d", anchor, i)
+	}
+	anchors[unique] = true
+	return unique
+}
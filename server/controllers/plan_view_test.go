@@ -0,0 +1,64 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/controllers"
+	"github.com/runatlantis/atlantis/server/controllers/web_templates"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestNewPlanView(t *testing.T) {
+	var refreshes []string
+	for i := range 8 {
+		refreshes = append(refreshes, fmt.Sprintf("null_resource.r%d: Refreshing state... [id=%d]", i, i))
+	}
+	output := strings.Join(refreshes, "\n") + `
+
+Terraform will perform the following actions:
+
+  # null_resource.a will be created
+  + resource "null_resource" "a" {
+      + id = (known after apply)
+    }
+
+  # module.app.null_resource.b["x"] must be replaced
+-/+ resource "null_resource" "b" {
+      ~ id = "1" -> (known after apply)
+        # (1 unchanged attribute hidden)
+    }
+
+Plan: 2 to add, 0 to change, 1 to destroy.
+`
+	view := controllers.NewPlanView(output)
+
+	Equals(t, []web_templates.PlanResource{
+		{Address: "null_resource.a", Action: "will be created", Anchor: "resource-null_resource.a"},
+		{Address: `module.app.null_resource.b["x"]`, Action: "must be replaced", Anchor: "resource-module.app.null_resource.b-x-"},
+	}, view.Resources)
+
+	t.Log("the refreshes are folded")
+	Equals(t, 2, len(view.Blocks))
+	Equals(t, true, view.Blocks[0].Folded)
+	Equals(t, 8+3, len(view.Blocks[0].Lines))
+	Equals(t, "plan-refresh", view.Blocks[0].Lines[0].Class)
+
+	t.Log("the changes are highlighted")
+	var classes []string
+	for _, line := range view.Blocks[1].Lines {
+		classes = append(classes, line.Class)
+	}
+	Equals(t, []string{
+		"plan-resource", "plan-add", "plan-add", "",
+		"",
+		"plan-resource", "plan-replace", "plan-change", "plan-hidden", "",
+		"",
+		"plan-summary",
+	}, classes)
+	Equals(t, "resource-null_resource.a", view.Blocks[1].Lines[0].Anchor)
+}
@@ -0,0 +1,115 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/runatlantis/atlantis/server/controllers/web_templates"
+	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/webauth"
+)
+
+// ProjectsController renders the projects page, listing every known project
+// with its lock, last plan and apply, last applied commit and drift so
+// operators get a fleet-wide picture.
+type ProjectsController struct {
+	AtlantisVersion string
+	AtlantisURL     *url.URL
+	Locker          locking.Locker
+	// Statuses is the last plan and apply of the projects. It may be nil.
+	Statuses         *events.ProjectStatusTracker
+	LockURLGenerator events.LockURLGenerator
+	Logger           logging.SimpleLogging
+	Template         web_templates.TemplateWriter
+}
+
+// Get renders the projects page.
+func (c *ProjectsController) Get(w http.ResponseWriter, r *http.Request) {
+	locks, err := c.Locker.List()
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, "Could not retrieve locks: %s", err) // nolint: errcheck
+		return
+	}
+
+	projects := map[string]*web_templates.ProjectStatusData{}
+	for _, status := range c.Statuses.List() {
+		if !webauth.Allowed(r, webauth.ViewJobs, status.Repository, status.VCSHostname) {
+			continue
+		}
+		project := &web_templates.ProjectStatusData{
+			RepoFullName:      status.Repository,
+			ProjectName:       status.ProjectName,
+			Path:              status.RepoRelDir,
+			Workspace:         status.Workspace,
+			LastPlan:          projectCommandData(status.LastPlan),
+			LastApply:         projectCommandData(status.LastApply),
+			LastAppliedCommit: status.LastAppliedCommit,
+			Drift:             string(status.Drift),
+		}
+		if !status.DriftCheckedAt.IsZero() {
+			project.DriftCheckedAtFormatted = status.DriftCheckedAt.Format("2006-01-02 15:04:05")
+		}
+		projects[projectKey(status.Repository, status.RepoRelDir, status.Workspace, status.ProjectName)] = project
+	}
+	for id, lock := range locks {
+		if !webauth.Allowed(r, webauth.ViewJobs, lock.Project.RepoFullName, lock.Pull.BaseRepo.VCSHost.Hostname) {
+			continue
+		}
+		key := projectKey(lock.Project.RepoFullName, lock.Project.Path, lock.Workspace, lock.Project.ProjectName)
+		project, ok := projects[key]
+		if !ok {
+			project = &web_templates.ProjectStatusData{
+				RepoFullName: lock.Project.RepoFullName,
+				ProjectName:  lock.Project.ProjectName,
+				Path:         lock.Project.Path,
+				Workspace:    lock.Workspace,
+			}
+			projects[key] = project
+		}
+		project.LockURL = c.LockURLGenerator.GenerateLockURL(id)
+		project.LockedBy = lock.User.Username
+		project.LockPullNum = lock.Pull.Num
+		project.LockPullURL = lock.Pull.URL
+	}
+
+	data := web_templates.ProjectsData{
+		AtlantisVersion: c.AtlantisVersion,
+		CleanedBasePath: c.AtlantisURL.Path,
+	}
+	for _, project := range projects {
+		data.Projects = append(data.Projects, *project)
+	}
+	sort.Slice(data.Projects, func(i, j int) bool {
+		a, b := data.Projects[i], data.Projects[j]
+		return projectKey(a.RepoFullName, a.Path, a.Workspace, a.ProjectName) < projectKey(b.RepoFullName, b.Path, b.Workspace, b.ProjectName)
+	})
+	if err := c.Template.Execute(w, data); err != nil {
+		c.Logger.Err(err.Error())
+	}
+}
+
+func projectCommandData(status *events.ProjectCommandStatus) *web_templates.ProjectCommandData {
+	if status == nil {
+		return nil
+	}
+	return &web_templates.ProjectCommandData{
+		TimeFormatted: status.Time.Format("2006-01-02 15:04:05"),
+		Success:       status.Success,
+		Summary:       status.Summary,
+		PullNum:       status.PullNum,
+		PullURL:       status.PullURL,
+		User:          status.User,
+	}
+}
+
+func projectKey(repoFullName string, path string, workspace string, projectName string) string {
+	return repoFullName + "\x00" + path + "\x00" + workspace + "\x00" + projectName
+}
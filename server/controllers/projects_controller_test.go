@@ -0,0 +1,74 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/controllers"
+	"github.com/runatlantis/atlantis/server/controllers/web_templates"
+	"github.com/runatlantis/atlantis/server/core/locking/mocks"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	mocks2 "github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestProjectsController_Get(t *testing.T) {
+	RegisterMockTestingT(t)
+	repo := models.Repo{FullName: "owner/repo"}
+	locker := mocks.NewMockLocker()
+	When(locker.List()).ThenReturn(map[string]models.ProjectLock{
+		"owner/repo/app/default": {
+			Project:   models.Project{RepoFullName: "owner/repo", Path: "app"},
+			Pull:      models.PullRequest{Num: 2, URL: "https://github.com/owner/repo/pull/2"},
+			User:      models.User{Username: "locker"},
+			Workspace: "default",
+		},
+	}, nil)
+	lockURLGenerator := mocks2.NewMockLockURLGenerator()
+	When(lockURLGenerator.GenerateLockURL("owner/repo/app/default")).ThenReturn("https://atlantis.example.com/lock?id=app-lock")
+
+	statuses := &events.ProjectStatusTracker{}
+	statuses.RecordPlan(command.ProjectContext{
+		Pull:       models.PullRequest{Num: 1, BaseRepo: repo, HeadCommit: "abc1234567"},
+		RepoRelDir: "network",
+		Workspace:  "default",
+	}, &models.PlanSuccess{TerraformOutput: "Plan: 1 to add, 0 to change, 0 to destroy."}, "", nil)
+	statuses.RecordApply(command.ProjectContext{
+		Pull:       models.PullRequest{Num: 1, BaseRepo: repo, HeadCommit: "abc1234567"},
+		RepoRelDir: "network",
+		Workspace:  "default",
+	}, "", nil)
+
+	c := controllers.ProjectsController{
+		AtlantisURL:      &url.URL{},
+		Locker:           locker,
+		Statuses:         statuses,
+		LockURLGenerator: lockURLGenerator,
+		Logger:           logging.NewNoopLogger(t),
+		Template:         web_templates.ProjectsTemplate,
+	}
+	req, _ := http.NewRequest("GET", "/projects", nil)
+	w := httptest.NewRecorder()
+	c.Get(w, req)
+	Equals(t, http.StatusOK, w.Result().StatusCode)
+	body := w.Body.String()
+	for _, exp := range []string{
+		"https://atlantis.example.com/lock?id=app-lock",
+		"locker",
+		"Plan: 1 to add, 0 to change, 0 to destroy.",
+		"<code>abc1234</code>",
+	} {
+		Assert(t, strings.Contains(body, exp), "exp %q in the projects page", exp)
+	}
+	Assert(t, strings.Index(body, `<span class="lock-path">app</span>`) < strings.Index(body, `<span class="lock-path">network</span>`), "exp the projects to be sorted")
+}
@@ -0,0 +1,183 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/controllers/web_templates"
+	"github.com/runatlantis/atlantis/server/core/db"
+	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/jobs"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/webauth"
+)
+
+// PullController renders the page of a pull request, aggregating its
+// projects with their status, plan summary, lock and jobs. Comments link to it
+// in their footers.
+type PullController struct {
+	AtlantisVersion string
+	AtlantisURL     *url.URL
+	Database        db.Database
+	Locker          locking.Locker
+	OutputHandler   jobs.ProjectCommandOutputHandler
+	// Statuses is the last plan and apply of the projects. It may be nil.
+	Statuses         *events.ProjectStatusTracker
+	LockURLGenerator events.LockURLGenerator
+	Logger           logging.SimpleLogging
+	Template         web_templates.TemplateWriter
+}
+
+// Get renders the page of the pull request in the repo, pull and host query
+// parameters.
+func (c *PullController) Get(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	repoFullName := query.Get("repo")
+	pullNum, err := strconv.Atoi(query.Get("pull"))
+	if repoFullName == "" || err != nil {
+		c.respond(w, logging.Warn, http.StatusBadRequest, "No repo or pull in request")
+		return
+	}
+	locks, err := c.Locker.List()
+	if err != nil {
+		c.respond(w, logging.Error, http.StatusServiceUnavailable, "Could not retrieve locks: %s", err)
+		return
+	}
+	hostname := query.Get("host")
+	pullStatus, tracked, err := c.trackedPull(repoFullName, pullNum, hostname, locks)
+	if err != nil {
+		c.respond(w, logging.Error, http.StatusInternalServerError, "Could not retrieve the status of the pull request: %s", err)
+		return
+	}
+	// The host is in the query so it's only trusted if a pull request of the
+	// repo is tracked on it, otherwise viewing requires permissions for all
+	// repos.
+	allowedRepo := ""
+	if tracked {
+		allowedRepo = repoFullName
+	}
+	if !webauth.Allowed(r, webauth.ViewJobs, allowedRepo, hostname) {
+		c.respond(w, logging.Warn, http.StatusForbidden, "%s isn't allowed to view the pull requests of %s", webauth.Username(r), repoFullName)
+		return
+	}
+	if strings.Contains(hostname, "/") {
+		c.respond(w, logging.Warn, http.StatusBadRequest, "Invalid host %q in request", hostname)
+		return
+	}
+
+	data := web_templates.PullData{
+		RepoFullName:    repoFullName,
+		PullNum:         pullNum,
+		AtlantisVersion: c.AtlantisVersion,
+		CleanedBasePath: c.AtlantisURL.Path,
+	}
+	projects := map[string]*web_templates.PullProjectData{}
+	project := func(path string, workspace string, projectName string) *web_templates.PullProjectData {
+		key := projectKey(repoFullName, path, workspace, projectName)
+		if _, ok := projects[key]; !ok {
+			projects[key] = &web_templates.PullProjectData{ProjectName: projectName, Path: path, Workspace: workspace}
+		}
+		return projects[key]
+	}
+	if pullStatus != nil {
+		data.PullURL = pullStatus.Pull.URL
+		for _, status := range pullStatus.Projects {
+			project(status.RepoRelDir, status.Workspace, status.ProjectName).Status = status.Status.String()
+		}
+	}
+	for id, lock := range locks {
+		if !isPull(lock.Project.RepoFullName, lock.Pull.BaseRepo.VCSHost.Hostname, lock.Pull.Num, repoFullName, hostname, pullNum) {
+			continue
+		}
+		project(lock.Project.Path, lock.Workspace, lock.Project.ProjectName).LockURL = c.LockURLGenerator.GenerateLockURL(id)
+		if data.PullURL == "" {
+			data.PullURL = lock.Pull.URL
+		}
+	}
+	if c.OutputHandler != nil {
+		for _, mapping := range c.OutputHandler.GetPullToJobMapping() {
+			if !isPull(mapping.Pull.RepoFullName, mapping.Pull.VCSHostname, mapping.Pull.PullNum, repoFullName, hostname, pullNum) {
+				continue
+			}
+			p := project(mapping.Pull.Path, mapping.Pull.Workspace, mapping.Pull.ProjectName)
+			p.Jobs = append(p.Jobs, mapping.JobIDInfos...)
+		}
+	}
+	for _, status := range c.Statuses.List() {
+		if status.LastPlan == nil || !isPull(status.Repository, status.VCSHostname, status.LastPlan.PullNum, repoFullName, hostname, pullNum) {
+			continue
+		}
+		if p, ok := projects[projectKey(repoFullName, status.RepoRelDir, status.Workspace, status.ProjectName)]; ok && status.LastPlan.Success {
+			p.PlanSummary = status.LastPlan.Summary
+		}
+	}
+
+	for _, p := range projects {
+		sort.Slice(p.Jobs, func(i, j int) bool { return p.Jobs[i].Time.Before(p.Jobs[j].Time) })
+		data.Projects = append(data.Projects, *p)
+	}
+	sort.Slice(data.Projects, func(i, j int) bool {
+		a, b := data.Projects[i], data.Projects[j]
+		return projectKey(repoFullName, a.Path, a.Workspace, a.ProjectName) < projectKey(repoFullName, b.Path, b.Workspace, b.ProjectName)
+	})
+	if err := c.Template.Execute(w, data); err != nil {
+		c.Logger.Err(err.Error())
+	}
+}
+
+// trackedPull returns the status of the pull request pullNum of the repo on
+// hostname, if any, and whether a pull request of the repo is tracked on
+// hostname: it has a status, locks, jobs or project statuses.
+func (c *PullController) trackedPull(repoFullName string, pullNum int, hostname string, locks map[string]models.ProjectLock) (*models.PullStatus, bool, error) {
+	if strings.Contains(hostname, "/") {
+		return nil, false, nil
+	}
+	pullStatus, err := c.Database.GetPullStatus(models.PullRequest{
+		Num:      pullNum,
+		BaseRepo: models.Repo{FullName: repoFullName, VCSHost: models.VCSHost{Hostname: hostname}},
+	})
+	if err != nil || pullStatus != nil {
+		return pullStatus, pullStatus != nil, err
+	}
+	for _, lock := range locks {
+		if isPull(lock.Project.RepoFullName, lock.Pull.BaseRepo.VCSHost.Hostname, lock.Pull.Num, repoFullName, hostname, pullNum) {
+			return nil, true, nil
+		}
+	}
+	if c.OutputHandler != nil {
+		for _, mapping := range c.OutputHandler.GetPullToJobMapping() {
+			if isPull(mapping.Pull.RepoFullName, mapping.Pull.VCSHostname, mapping.Pull.PullNum, repoFullName, hostname, pullNum) {
+				return nil, true, nil
+			}
+		}
+	}
+	for _, status := range c.Statuses.List() {
+		if status.LastPlan != nil && isPull(status.Repository, status.VCSHostname, status.LastPlan.PullNum, repoFullName, hostname, pullNum) {
+			return nil, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// isPull returns true if the record of the pull request recordPull of
+// recordRepo on recordHostname is about the pull request pullNum of
+// repoFullName on hostname.
+func isPull(recordRepo string, recordHostname string, recordPull int, repoFullName string, hostname string, pullNum int) bool {
+	return recordRepo == repoFullName && recordHostname == hostname && recordPull == pullNum
+}
+
+func (c *PullController) respond(w http.ResponseWriter, lvl logging.LogLevel, responseCode int, format string, args ...any) {
+	response := fmt.Sprintf(format, args...)
+	c.Logger.Log(lvl, response)
+	w.WriteHeader(responseCode)
+	fmt.Fprintln(w, response)
+}
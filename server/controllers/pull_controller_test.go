@@ -0,0 +1,127 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/controllers"
+	"github.com/runatlantis/atlantis/server/controllers/web_templates"
+	"github.com/runatlantis/atlantis/server/core/boltdb"
+	"github.com/runatlantis/atlantis/server/core/locking/mocks"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	mocks2 "github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/jobs"
+	jobmocks "github.com/runatlantis/atlantis/server/jobs/mocks"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/webauth"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestPullController_Get(t *testing.T) {
+	RegisterMockTestingT(t)
+	repo := models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Hostname: "github.com"}}
+	pull := models.PullRequest{Num: 1, BaseRepo: repo, URL: "https://github.com/owner/repo/pull/1", HeadCommit: "abc123"}
+
+	database, err := boltdb.New(t.TempDir())
+	Ok(t, err)
+	planSuccess := &models.PlanSuccess{TerraformOutput: "Plan: 1 to add, 0 to change, 0 to destroy."}
+	_, err = database.UpdatePullWithResults(pull, []command.ProjectResult{{
+		Command:              command.Plan,
+		RepoRelDir:           "network",
+		Workspace:            "default",
+		ProjectCommandOutput: command.ProjectCommandOutput{PlanSuccess: planSuccess},
+	}})
+	Ok(t, err)
+
+	locker := mocks.NewMockLocker()
+	When(locker.List()).ThenReturn(map[string]models.ProjectLock{
+		"owner/repo/network/default": {
+			Project:   models.Project{RepoFullName: "owner/repo", Path: "network"},
+			Pull:      pull,
+			Workspace: "default",
+		},
+		"owner/secret/default": {
+			Project:   models.Project{RepoFullName: "owner/secret", Path: "."},
+			Pull:      models.PullRequest{Num: 1, BaseRepo: models.Repo{FullName: "owner/secret", VCSHost: models.VCSHost{Hostname: "github.com"}}},
+			Workspace: "default",
+		},
+		"owner/repo/app/default": {
+			Project:   models.Project{RepoFullName: "owner/repo", Path: "app"},
+			Pull:      models.PullRequest{Num: 2, BaseRepo: repo},
+			Workspace: "default",
+		},
+	}, nil)
+	lockURLGenerator := mocks2.NewMockLockURLGenerator()
+	When(lockURLGenerator.GenerateLockURL("owner/repo/network/default")).ThenReturn("https://atlantis.example.com/lock?id=network-lock")
+
+	outputHandler := jobmocks.NewMockProjectCommandOutputHandler()
+	When(outputHandler.GetPullToJobMapping()).ThenReturn([]jobs.PullInfoWithJobIDs{{
+		Pull:       jobs.PullInfo{PullNum: 1, RepoFullName: "owner/repo", VCSHostname: "github.com", Path: "network", Workspace: "default"},
+		JobIDInfos: []jobs.JobIDInfo{{JobID: "job-id", JobIDUrl: "/jobs/job-id", JobStep: "plan"}},
+	}})
+
+	statuses := &events.ProjectStatusTracker{}
+	statuses.RecordPlan(command.ProjectContext{Pull: pull, RepoRelDir: "network", Workspace: "default"}, planSuccess, "", nil)
+
+	c := controllers.PullController{
+		AtlantisURL:      &url.URL{},
+		Database:         database,
+		Locker:           locker,
+		OutputHandler:    outputHandler,
+		Statuses:         statuses,
+		LockURLGenerator: lockURLGenerator,
+		Logger:           logging.NewNoopLogger(t),
+		Template:         web_templates.PullTemplate,
+	}
+
+	t.Log("the repo and pull are required")
+	req, _ := http.NewRequest("GET", "/pull?repo=owner/repo", nil)
+	w := httptest.NewRecorder()
+	c.Get(w, req)
+	Equals(t, http.StatusBadRequest, w.Result().StatusCode)
+
+	req, _ = http.NewRequest("GET", "/pull?repo=owner%2Frepo&pull=1&host=github.com", nil)
+	w = httptest.NewRecorder()
+	c.Get(w, req)
+	Equals(t, http.StatusOK, w.Result().StatusCode)
+	body := w.Body.String()
+	for _, exp := range []string{
+		"https://github.com/owner/repo/pull/1",
+		"<code>planned</code>",
+		"Plan: 1 to add, 0 to change, 0 to destroy.",
+		"https://atlantis.example.com/lock?id=network-lock",
+		`href="/jobs/job-id"`,
+	} {
+		Assert(t, strings.Contains(body, exp), "exp %q in the pull request page", exp)
+	}
+	Assert(t, !strings.Contains(body, `<span class="lock-path">app</span>`), "exp the projects of other pull requests not to be shown")
+
+	t.Log("the host in the query is only trusted if the pull request is tracked on it")
+	permissionsPath := filepath.Join(t.TempDir(), "permissions.yaml")
+	Ok(t, os.WriteFile(permissionsPath, []byte("groups:\n- name: infra\n  capabilities: [view_jobs]\n  repos: github.com/owner/repo,github.com/other/infra-*"), 0600))
+	permissions, err := webauth.LoadPermissions(permissionsPath)
+	Ok(t, err)
+	infra := &webauth.User{Name: "infra-user", Groups: []string{"infra"}, Permissions: permissions}
+	get := func(query string, user *webauth.User) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/pull?"+query, nil)
+		req = req.WithContext(webauth.NewContext(req.Context(), user))
+		w := httptest.NewRecorder()
+		c.Get(w, req)
+		return w
+	}
+	Equals(t, http.StatusOK, get("repo=owner%2Frepo&pull=1&host=github.com", infra).Result().StatusCode)
+	ResponseContains(t, get("repo=owner%2Fsecret&pull=1&host=github.com%2Fother%2Finfra-x", infra), http.StatusForbidden, "infra-user isn't allowed to view the pull requests of owner/secret")
+	ResponseContains(t, get("repo=owner%2Frepo&pull=1&host=gitlab.com", infra), http.StatusForbidden, "infra-user isn't allowed to view the pull requests of owner/repo")
+	ResponseContains(t, get("repo=owner%2Fsecret&pull=1&host=github.com%2Fother%2Finfra-x", &webauth.User{Name: "admin"}), http.StatusBadRequest, "Invalid host")
+}
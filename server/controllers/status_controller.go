@@ -17,22 +17,21 @@ type StatusController struct {
 	Logger          logging.SimpleLogging `validate:"required"`
 	Drainer         *events.Drainer       `validate:"required"`
 	AtlantisVersion string                `validate:"required"`
+	// DeferredCommands are the commands requested while draining. It may be
+	// nil.
+	DeferredCommands *events.DeferredCommands
 }
 
 type StatusResponse struct {
 	ShuttingDown    bool   `json:"shutting_down"`
 	InProgressOps   int    `json:"in_progress_operations"`
+	DeferredCmds    int    `json:"deferred_commands"`
 	AtlantisVersion string `json:"version"`
 }
 
 // Get is the GET /status route.
 func (d *StatusController) Get(w http.ResponseWriter, _ *http.Request) {
-	status := d.Drainer.GetStatus()
-	data, err := json.MarshalIndent(&StatusResponse{
-		ShuttingDown:    status.ShuttingDown,
-		InProgressOps:   status.InProgressOps,
-		AtlantisVersion: d.AtlantisVersion,
-	}, "", "  ")
+	data, err := json.MarshalIndent(d.status(), "", "  ")
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprintf(w, "Error creating status json response: %s", err)
@@ -41,3 +40,20 @@ func (d *StatusController) Get(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(data) // nolint: errcheck
 }
+
+func (d *StatusController) status() *StatusResponse {
+	status := d.Drainer.GetStatus()
+	response := &StatusResponse{
+		ShuttingDown:    status.ShuttingDown,
+		InProgressOps:   status.InProgressOps,
+		AtlantisVersion: d.AtlantisVersion,
+	}
+	if d.DeferredCommands != nil {
+		deferred, err := d.DeferredCommands.Count()
+		if err != nil {
+			d.Logger.Warn("unable to count the deferred commands: %s", err)
+		}
+		response.DeferredCmds = deferred
+	}
+	return response
+}
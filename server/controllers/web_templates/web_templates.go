@@ -37,6 +37,10 @@ var templateFileNames = map[string]string{
 	"project-jobs":       "project-jobs.html.tmpl",
 	"project-jobs-error": "project-jobs-error.html.tmpl",
 	"github-app":         "github-app.html.tmpl",
+	"api-tokens":         "api-tokens.html.tmpl",
+	"webhooks":           "webhooks.html.tmpl",
+	"projects":           "projects.html.tmpl",
+	"pull":               "pull.html.tmpl",
 }
 
 // TemplateWriter is an interface over html/template that's used to enable
@@ -59,6 +63,21 @@ type LockIndexData struct {
 	TimeFormatted string
 }
 
+// StateLockIndexData holds the fields needed to display a Terraform state lock
+// in the index view.
+type StateLockIndexData struct {
+	ID            string
+	RepoFullName  string
+	PullNum       int
+	PullURL       string
+	Path          string
+	Workspace     string
+	Who           string
+	Operation     string
+	Created       string
+	TimeFormatted string
+}
+
 // ApplyLockData holds the fields to display in the index view
 type ApplyLockData struct {
 	Locked                 bool
@@ -71,6 +90,10 @@ type ApplyLockData struct {
 type IndexData struct {
 	Locks            []LockIndexData
 	PullToJobMapping []jobs.PullInfoWithJobIDs
+	StateLocks       []StateLockIndexData
+	// StateForceUnlockEnabled is true if state locks can be force-unlocked
+	// from the UI.
+	StateForceUnlockEnabled bool
 
 	ApplyLock       ApplyLockData
 	AtlantisVersion string
@@ -105,6 +128,42 @@ type ProjectJobData struct {
 	AtlantisVersion string
 	ProjectPath     string
 	CleanedBasePath string
+	// Plan is the stored plan output of the job, shown instead of the log
+	// stream if set.
+	Plan *PlanView
+}
+
+// PlanView is a plan output split into lines highlighted by the change they
+// show, with the runs of unchanged lines folded.
+type PlanView struct {
+	// Project describes the project the plan is of.
+	Project string
+	// Resources are the resources the plan changes, linking to their lines.
+	Resources []PlanResource
+	Blocks    []PlanBlock
+}
+
+// PlanResource is a resource changed by a plan.
+type PlanResource struct {
+	Address string
+	Action  string
+	// Anchor is the id of the line starting the resource's changes.
+	Anchor string
+}
+
+// PlanBlock is a run of lines of a plan output, folded if Folded.
+type PlanBlock struct {
+	Folded bool
+	Lines  []PlanLine
+}
+
+// PlanLine is a line of a plan output.
+type PlanLine struct {
+	Text string
+	// Class is the CSS class highlighting the line, ex. plan-add.
+	Class string
+	// Anchor is the id of the line if it starts the changes of a resource.
+	Anchor string
 }
 
 var ProjectJobsTemplate = templates.Lookup(templateFileNames["project-jobs"])
@@ -129,3 +188,138 @@ type GithubSetupData struct {
 }
 
 var GithubAppSetupTemplate = templates.Lookup(templateFileNames["github-app"])
+
+// APITokenData holds the fields needed to display an API token.
+type APITokenData struct {
+	ID                 string
+	Name               string
+	Scope              string
+	Repos              string
+	CreatedBy          string
+	CreatedAtFormatted string
+}
+
+// APITokensData holds the data for rendering the API tokens page.
+type APITokensData struct {
+	APITokens []APITokenData
+	// Enabled is whether API tokens can be managed from the UI, which
+	// requires web basic auth.
+	Enabled         bool
+	AtlantisVersion string
+	// CleanedBasePath is the path Atlantis is accessible at externally. If
+	// not using a path-based proxy, this will be an empty string. Never ends
+	// in a '/' (hence "cleaned").
+	CleanedBasePath string
+}
+
+var APITokensTemplate = templates.Lookup(templateFileNames["api-tokens"])
+
+// WebhookData holds the fields needed to display a received webhook.
+type WebhookData struct {
+	ID                  string
+	Event               string
+	ReceivedAtFormatted string
+	StatusCode          int
+	Response            string
+	// Headers and Payload are formatted for display, with their secrets
+	// redacted.
+	Headers  string
+	Payload  string
+	ReplayOf string
+}
+
+// WebhooksData holds the data for rendering the webhooks page.
+type WebhooksData struct {
+	Webhooks []WebhookData
+	// Enabled is whether webhooks can be inspected from the UI, which
+	// requires the webhook history and web basic auth.
+	Enabled         bool
+	AtlantisVersion string
+	// CleanedBasePath is the path Atlantis is accessible at externally. If
+	// not using a path-based proxy, this will be an empty string. Never ends
+	// in a '/' (hence "cleaned").
+	CleanedBasePath string
+}
+
+var WebhooksTemplate = templates.Lookup(templateFileNames["webhooks"])
+
+// ProjectCommandData holds the fields needed to display the last plan or
+// apply of a project.
+type ProjectCommandData struct {
+	TimeFormatted string
+	Success       bool
+	// Summary is the counts of the changes of successful plans or the error
+	// of failed commands.
+	Summary string
+	PullNum int
+	PullURL string
+	User    string
+}
+
+// ProjectStatusData holds the fields needed to display the status of a
+// project on the projects page.
+type ProjectStatusData struct {
+	RepoFullName string
+	ProjectName  string
+	Path         string
+	Workspace    string
+	// LockURL is the URL of the lock of the project, empty if it isn't
+	// locked.
+	LockURL     string
+	LockedBy    string
+	LockPullNum int
+	LockPullURL string
+	LastPlan    *ProjectCommandData
+	LastApply   *ProjectCommandData
+	// LastAppliedCommit is the head commit of the last successful apply.
+	LastAppliedCommit string
+	// Drift is "drifted" or "in sync" once the project has been planned
+	// outside of a pull request, ex. through the API.
+	Drift                   string
+	DriftCheckedAtFormatted string
+}
+
+// ProjectsData holds the data for rendering the projects page.
+type ProjectsData struct {
+	Projects        []ProjectStatusData
+	AtlantisVersion string
+	// CleanedBasePath is the path Atlantis is accessible at externally. If
+	// not using a path-based proxy, this will be an empty string. Never ends
+	// in a '/' (hence "cleaned").
+	CleanedBasePath string
+}
+
+var ProjectsTemplate = templates.Lookup(templateFileNames["projects"])
+
+// PullProjectData holds the fields needed to display a project of a pull
+// request on the pull request page.
+type PullProjectData struct {
+	ProjectName string
+	Path        string
+	Workspace   string
+	// Status is where the project is at in the planning cycle, ex.
+	// "planned", empty if Atlantis didn't run a command for it.
+	Status string
+	// PlanSummary is the counts of the changes of the last plan of the
+	// project for the pull request.
+	PlanSummary string
+	// LockURL is the URL of the lock the pull request holds on the project,
+	// empty if it doesn't hold one.
+	LockURL string
+	Jobs    []jobs.JobIDInfo
+}
+
+// PullData holds the data for rendering the page of a pull request.
+type PullData struct {
+	RepoFullName    string
+	PullNum         int
+	PullURL         string
+	Projects        []PullProjectData
+	AtlantisVersion string
+	// CleanedBasePath is the path Atlantis is accessible at externally. If
+	// not using a path-based proxy, this will be an empty string. Never ends
+	// in a '/' (hence "cleaned").
+	CleanedBasePath string
+}
+
+var PullTemplate = templates.Lookup(templateFileNames["pull"])
@@ -5,6 +5,7 @@ package web_templates
 
 import (
 	"io"
+	"strings"
 	"testing"
 	"time"
 
@@ -75,8 +76,33 @@ func TestProjectJobsTemplate(t *testing.T) {
 	Ok(t, err)
 }
 
+func TestProjectJobsTemplate_Plan(t *testing.T) {
+	var buf strings.Builder
+	err := ProjectJobsTemplate.Execute(&buf, ProjectJobData{
+		AtlantisVersion: "v0.0.0",
+		ProjectPath:     "project path",
+		CleanedBasePath: "/path",
+		Plan: &PlanView{
+			Project:   "owner/repo#1 dir: . workspace: default",
+			Resources: []PlanResource{{Address: "null_resource.a", Action: "will be created", Anchor: "resource-null_resource.a"}},
+			Blocks: []PlanBlock{
+				{Folded: true, Lines: []PlanLine{{Text: "null_resource.b: Refreshing state...", Class: "plan-refresh"}}},
+				{Lines: []PlanLine{
+					{Text: "  # null_resource.a will be created", Class: "plan-resource", Anchor: "resource-null_resource.a"},
+					{Text: `  + resource "null_resource" "a" {`, Class: "plan-add"},
+				}},
+			},
+		},
+	})
+	Ok(t, err)
+	Assert(t, strings.Contains(buf.String(), `<a href="#resource-null_resource.a">null_resource.a</a>`), "missing resource link in %s", buf.String())
+	Assert(t, strings.Contains(buf.String(), `<span id="resource-null_resource.a" class="plan-resource">  # null_resource.a will be created</span>`), "missing resource anchor in %s", buf.String())
+	Assert(t, strings.Contains(buf.String(), `<span class="plan-add">  &#43; resource &#34;null_resource&#34; &#34;a&#34; {</span>`), "missing escaped line in %s", buf.String())
+	Assert(t, !strings.Contains(buf.String(), "new Terminal("), "the log stream is shown with the plan")
+}
+
 func TestProjectJobsErrorTemplate(t *testing.T) {
-	err := ProjectJobsTemplate.Execute(io.Discard, ProjectJobsError{
+	err := ProjectJobsErrorTemplate.Execute(io.Discard, ProjectJobsError{
 		AtlantisVersion: "v0.0.0",
 		ProjectPath:     "project path",
 		CleanedBasePath: "/path",
@@ -96,3 +122,61 @@ func TestGithubAppSetupTemplate(t *testing.T) {
 	})
 	Ok(t, err)
 }
+
+func TestAPITokensTemplate(t *testing.T) {
+	err := APITokensTemplate.Execute(io.Discard, APITokensData{
+		APITokens: []APITokenData{
+			{ID: "1", Name: "ci", Scope: "plan", Repos: "owner/*", CreatedBy: "admin", CreatedAtFormatted: "2006-01-02 15:04:05"},
+		},
+		Enabled:         true,
+		AtlantisVersion: "v0.0.0",
+		CleanedBasePath: "/path",
+	})
+	Ok(t, err)
+}
+
+func TestProjectsTemplate(t *testing.T) {
+	err := ProjectsTemplate.Execute(io.Discard, ProjectsData{
+		Projects: []ProjectStatusData{
+			{
+				RepoFullName: "owner/repo",
+				ProjectName:  "network",
+				Path:         "network",
+				Workspace:    "default",
+				LockURL:      "https://example.com/lock?id=lock-id",
+				LockedBy:     "user",
+				LockPullNum:  1,
+				LockPullURL:  "https://github.com/owner/repo/pull/1",
+				LastPlan:     &ProjectCommandData{TimeFormatted: "2006-01-02 15:04:05", Success: true, Summary: "1 to add, 0 to change, 0 to destroy", PullNum: 1, User: "user"},
+				LastApply:    &ProjectCommandData{TimeFormatted: "2006-01-02 15:04:05", Summary: "apply failed"},
+				Drift:        "drifted",
+			},
+			{RepoFullName: "owner/repo", Path: ".", Workspace: "default"},
+		},
+		AtlantisVersion: "v0.0.0",
+		CleanedBasePath: "/path",
+	})
+	Ok(t, err)
+}
+
+func TestPullTemplate(t *testing.T) {
+	err := PullTemplate.Execute(io.Discard, PullData{
+		RepoFullName: "owner/repo",
+		PullNum:      1,
+		PullURL:      "https://github.com/owner/repo/pull/1",
+		Projects: []PullProjectData{
+			{
+				Path:        "network",
+				Workspace:   "default",
+				Status:      "planned",
+				PlanSummary: "Plan: 1 to add, 0 to change, 0 to destroy.",
+				LockURL:     "https://example.com/lock?id=lock-id",
+				Jobs:        []jobs.JobIDInfo{{JobIDUrl: "/jobs/job-id", JobStep: "plan", TimeFormatted: "2006-01-02 15:04:05"}},
+			},
+			{Path: ".", Workspace: "default"},
+		},
+		AtlantisVersion: "v0.0.0",
+		CleanedBasePath: "/path",
+	})
+	Ok(t, err)
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/controllers/events"
+	"github.com/runatlantis/atlantis/server/controllers/web_templates"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/webauth"
+)
+
+// ReplayWebhookRequest replays a webhook of the history.
+type ReplayWebhookRequest struct {
+	ID string
+}
+
+// WebhookReplayer handles a webhook of the history again.
+type WebhookReplayer interface {
+	Replay(id string) (*events.ReceivedWebhook, error)
+}
+
+// WebhooksController lets the users of the UI inspect the recently received
+// webhooks and replay them, ex. to debug missed or misrouted events. It
+// requires web basic auth since replaying runs the commands of the webhooks
+// again.
+type WebhooksController struct {
+	AtlantisVersion string
+	AtlantisURL     *url.URL
+	// History is the webhook history. It may be nil if it isn't enabled.
+	History  *events.WebhookHistory
+	Replayer WebhookReplayer
+	Logger   logging.SimpleLogging
+	Template web_templates.TemplateWriter
+	// WebAuthentication is whether the UI requires authentication.
+	WebAuthentication bool
+}
+
+func (c *WebhooksController) enabled() bool {
+	return c.History != nil && c.WebAuthentication
+}
+
+// Get renders the webhooks page.
+func (c *WebhooksController) Get(w http.ResponseWriter, _ *http.Request) {
+	data := web_templates.WebhooksData{
+		Enabled:         c.enabled(),
+		AtlantisVersion: c.AtlantisVersion,
+		CleanedBasePath: c.AtlantisURL.Path,
+	}
+	if c.enabled() {
+		for _, webhook := range c.History.List() {
+			data.Webhooks = append(data.Webhooks, web_templates.WebhookData{
+				ID:                  webhook.ID,
+				Event:               webhook.Event(),
+				ReceivedAtFormatted: webhook.ReceivedAt.Format("2006-01-02 15:04:05"),
+				StatusCode:          webhook.StatusCode,
+				Response:            webhook.Response,
+				Headers:             formatHeaders(webhook.Headers),
+				Payload:             webhook.Payload,
+				ReplayOf:            webhook.ReplayOf,
+			})
+		}
+	}
+	if err := c.Template.Execute(w, data); err != nil {
+		c.Logger.Err(err.Error())
+	}
+}
+
+// Replay replays the webhook of the JSON ReplayWebhookRequest body and
+// responds with the replay. JSON is required so cross-site forms can't replay
+// webhooks.
+func (c *WebhooksController) Replay(w http.ResponseWriter, r *http.Request) {
+	if !c.enabled() {
+		c.respond(w, logging.Warn, http.StatusForbidden, "Replaying webhooks from the UI requires --webhook-history and --web-basic-auth or --web-oidc-issuer-url")
+		return
+	}
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		c.respond(w, logging.Warn, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+		return
+	}
+	var request ReplayWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		c.respond(w, logging.Warn, http.StatusBadRequest, "Failed to parse request: %s", err)
+		return
+	}
+	if request.ID == "" {
+		c.respond(w, logging.Warn, http.StatusBadRequest, "ID is required")
+		return
+	}
+	user := webauth.Username(r)
+	c.Logger.Info("replaying webhook %s, requested by %s from %s", request.ID, user, r.RemoteAddr)
+	replay, err := c.Replayer.Replay(request.ID)
+	if err != nil {
+		c.respond(w, logging.Warn, http.StatusNotFound, "Failed replaying webhook: %s", err)
+		return
+	}
+	response, err := json.Marshal(replay)
+	if err != nil {
+		c.respond(w, logging.Error, http.StatusInternalServerError, "%s", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response) // nolint: errcheck
+}
+
+// formatHeaders formats headers one per line, sorted by name.
+func formatHeaders(headers map[string][]string) string {
+	var lines []string
+	for name, values := range headers {
+		for _, value := range values {
+			lines = append(lines, fmt.Sprintf("%s: %s", name, value))
+		}
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+func (c *WebhooksController) respond(w http.ResponseWriter, lvl logging.LogLevel, responseCode int, format string, args ...any) {
+	response := fmt.Sprintf(format, args...)
+	c.Logger.Log(lvl, response)
+	w.WriteHeader(responseCode)
+	fmt.Fprintln(w, response)
+}
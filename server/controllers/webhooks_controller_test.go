@@ -0,0 +1,93 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/controllers"
+	events_controllers "github.com/runatlantis/atlantis/server/controllers/events"
+	"github.com/runatlantis/atlantis/server/controllers/web_templates"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/webauth"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestWebhooksController(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	events := &events_controllers.VCSEventsController{
+		Logger:  logger,
+		History: events_controllers.NewWebhookHistory(10),
+	}
+	req, _ := http.NewRequest("POST", "/events", strings.NewReader(`{"action": "created"}`))
+	req.Header.Set("X-Github-Event", "issue_comment")
+	events.Post(httptest.NewRecorder(), req)
+	id := events.History.List()[0].ID
+
+	c := controllers.WebhooksController{
+		AtlantisURL:       &url.URL{},
+		History:           events.History,
+		Replayer:          events,
+		Logger:            logger,
+		Template:          web_templates.WebhooksTemplate,
+		WebAuthentication: true,
+	}
+	req, _ = http.NewRequest("GET", "/webhooks", nil)
+	w := httptest.NewRecorder()
+	c.Get(w, req)
+	Equals(t, http.StatusOK, w.Result().StatusCode)
+	Assert(t, strings.Contains(w.Body.String(), id), "exp the webhook to be shown")
+	Assert(t, strings.Contains(w.Body.String(), "issue_comment"), "exp the event to be shown")
+
+	replay := func(contentType string, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/webhooks/replay", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req = req.WithContext(webauth.NewContext(req.Context(), &webauth.User{Name: "admin"}))
+		w := httptest.NewRecorder()
+		c.Replay(w, req)
+		return w
+	}
+	w = replay("application/x-www-form-urlencoded", `{"ID": "`+id+`"}`)
+	Equals(t, http.StatusUnsupportedMediaType, w.Result().StatusCode)
+
+	w = replay("application/json", `{"ID": "unknown"}`)
+	Equals(t, http.StatusNotFound, w.Result().StatusCode)
+
+	w = replay("application/json", `{"ID": "`+id+`"}`)
+	Equals(t, http.StatusOK, w.Result().StatusCode)
+	var result events_controllers.ReceivedWebhook
+	Ok(t, json.NewDecoder(w.Result().Body).Decode(&result))
+	Equals(t, id, result.ReplayOf)
+	Equals(t, 2, len(events.History.List()))
+}
+
+func TestWebhooksController_RequiresWebAuthentication(t *testing.T) {
+	events := &events_controllers.VCSEventsController{
+		Logger:  logging.NewNoopLogger(t),
+		History: events_controllers.NewWebhookHistory(10),
+	}
+	c := controllers.WebhooksController{
+		AtlantisURL: &url.URL{},
+		History:     events.History,
+		Replayer:    events,
+		Logger:      logging.NewNoopLogger(t),
+		Template:    web_templates.WebhooksTemplate,
+	}
+	req, _ := http.NewRequest("POST", "/webhooks/replay", strings.NewReader(`{"ID": "id"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c.Replay(w, req)
+	Equals(t, http.StatusForbidden, w.Result().StatusCode)
+
+	req, _ = http.NewRequest("GET", "/webhooks", nil)
+	w = httptest.NewRecorder()
+	c.Get(w, req)
+	Equals(t, http.StatusOK, w.Result().StatusCode)
+	Assert(t, strings.Contains(w.Body.String(), "<code>--web-basic-auth</code>"), "exp web basic auth to be required")
+}
@@ -6,6 +6,7 @@ package boltdb
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -15,6 +16,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/encryption"
 	"github.com/runatlantis/atlantis/server/core/locking"
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
@@ -27,12 +29,24 @@ type BoltDB struct {
 	locksBucketName       []byte
 	pullsBucketName       []byte
 	globalLocksBucketName []byte
+	// cipher encrypts the stored values. It's nil if encryption isn't
+	// enabled.
+	cipher *encryption.Cipher
 }
 
 const (
 	locksBucketName       = "runLocks"
 	pullsBucketName       = "pulls"
 	globalLocksBucketName = "globalLocks"
+	summaryFeedbackBucket = "summaryFeedback"
+	resourceChangesBucket = "resourceChanges"
+	apiTokensBucket       = "apiTokens"
+	previewsBucket        = "previewEnvironments"
+	applyReportsBucket    = "applyReports"
+	commandRecordsBucket  = "commandRecords"
+	encryptionBucket      = "encryption"
+	encryptionCheckKey    = "check"
+	encryptionDataKey     = "dataKey"
 	pullKeySeparator      = "::"
 )
 
@@ -86,7 +100,8 @@ func New(dataDir string) (*BoltDB, error) {
 
 		if err := bucket.ForEach(func(oldKey, oldValue []byte) error {
 			_, err := locking.IsCurrentLocking(string(oldKey))
-			if err != nil {
+			// Encrypted locks were written after the migration.
+			if err != nil && !encryption.IsEncrypted(oldValue) {
 				var currLock models.ProjectLock
 				if err := json.Unmarshal(oldValue, &currLock); err != nil {
 					return errors.Wrap(err, "failed to deserialize current lock")
@@ -145,7 +160,10 @@ func (b *BoltDB) TryLock(newLock models.ProjectLock) (bool, models.ProjectLock,
 	var lockAcquired bool
 	var currLock models.ProjectLock
 	key := b.lockKey(newLock.Project, newLock.Workspace)
-	newLockSerialized, _ := json.Marshal(newLock)
+	newLockSerialized, err := b.marshal(newLock)
+	if err != nil {
+		return false, currLock, fmt.Errorf("serializing lock: %w", err)
+	}
 	transactionErr := b.db.Update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket(b.locksBucketName)
 
@@ -160,7 +178,7 @@ func (b *BoltDB) TryLock(newLock models.ProjectLock) (bool, models.ProjectLock,
 		}
 
 		// otherwise the lock fails, return to caller the run that's holding the lock
-		if err := json.Unmarshal(currLockSerialized, &currLock); err != nil {
+		if err := b.unmarshal(currLockSerialized, &currLock); err != nil {
 			return fmt.Errorf("failed to deserialize current lock: %w", err)
 		}
 		lockAcquired = false
@@ -186,7 +204,7 @@ func (b *BoltDB) Unlock(p models.Project, workspace string) (*models.ProjectLock
 		bucket := tx.Bucket(b.locksBucketName)
 		serialized := bucket.Get([]byte(key))
 		if serialized != nil {
-			if err := json.Unmarshal(serialized, &lock); err != nil {
+			if err := b.unmarshal(serialized, &lock); err != nil {
 				return fmt.Errorf("failed to deserialize lock: %w", err)
 			}
 			foundLock = true
@@ -221,7 +239,7 @@ func (b *BoltDB) List() ([]models.ProjectLock, error) {
 	// deserialize bytes into the proper objects
 	for k, v := range locksBytes {
 		var lock models.ProjectLock
-		if err := json.Unmarshal(v, &lock); err != nil {
+		if err := b.unmarshal(v, &lock); err != nil {
 			return locks, fmt.Errorf("failed to deserialize lock at key '%d': %w", k, err)
 		}
 		locks = append(locks, lock)
@@ -241,7 +259,10 @@ func (b *BoltDB) LockCommand(cmdName command.Name, lockTime time.Time) (*command
 		},
 	}
 
-	newLockSerialized, _ := json.Marshal(lock)
+	newLockSerialized, err := b.marshal(lock)
+	if err != nil {
+		return nil, fmt.Errorf("serializing lock: %w", err)
+	}
 	transactionErr := b.db.Update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket(b.globalLocksBucketName)
 
@@ -295,7 +316,7 @@ func (b *BoltDB) CheckCommandLock(cmdName command.Name) (*command.Lock, error) {
 		serializedLock := bucket.Get([]byte(b.commandLockKey(cmdName)))
 
 		if serializedLock != nil {
-			if err := json.Unmarshal(serializedLock, &cmdLock); err != nil {
+			if err := b.unmarshal(serializedLock, &cmdLock); err != nil {
 				return fmt.Errorf("failed to deserialize UserConfig: %w", err)
 			}
 			found = true
@@ -320,7 +341,7 @@ func (b *BoltDB) UnlockByPull(repoFullName string, pullNum int) ([]models.Projec
 		// we can use the repoFullName as a prefix search since that's the first part of the key
 		for k, v := c.Seek([]byte(repoFullName)); k != nil && bytes.HasPrefix(k, []byte(repoFullName)); k, v = c.Next() {
 			var lock models.ProjectLock
-			if err := json.Unmarshal(v, &lock); err != nil {
+			if err := b.unmarshal(v, &lock); err != nil {
 				return fmt.Errorf("deserializing lock at key %q: %w", string(k), err)
 			}
 			if lock.Pull.Num == pullNum {
@@ -361,7 +382,7 @@ func (b *BoltDB) GetLock(p models.Project, workspace string) (*models.ProjectLoc
 	}
 
 	var lock models.ProjectLock
-	if err := json.Unmarshal(lockBytes, &lock); err != nil {
+	if err := b.unmarshal(lockBytes, &lock); err != nil {
 		return nil, fmt.Errorf("deserializing lock at key %q: %w", key, err)
 	}
 
@@ -552,14 +573,14 @@ func (b *BoltDB) getPullFromBucket(bucket *bolt.Bucket, key []byte) (*models.Pul
 	}
 
 	var p models.PullStatus
-	if err := json.Unmarshal(serialized, &p); err != nil {
+	if err := b.unmarshal(serialized, &p); err != nil {
 		return nil, fmt.Errorf("deserializing pull at %q with contents %q: %w", key, serialized, err)
 	}
 	return &p, nil
 }
 
 func (b *BoltDB) writePullToBucket(bucket *bolt.Bucket, key []byte, pull models.PullStatus) error {
-	serialized, err := json.Marshal(pull)
+	serialized, err := b.marshal(pull)
 	if err != nil {
 		return fmt.Errorf("serializing: %w", err)
 	}
@@ -576,6 +597,514 @@ func (b *BoltDB) projectResultToProject(p command.ProjectResult) models.ProjectS
 	}
 }
 
+// SaveSummaryFeedback creates or replaces the feedback with feedback.ID.
+func (b *BoltDB) SaveSummaryFeedback(feedback models.SummaryFeedback) error {
+	serialized, err := b.marshal(feedback)
+	if err != nil {
+		return fmt.Errorf("serializing: %w", err)
+	}
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(summaryFeedbackBucket))
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(feedback.ID), serialized)
+	})
+	if err != nil {
+		return fmt.Errorf("DB transaction failed: %w", err)
+	}
+	return nil
+}
+
+// ListSummaryFeedback returns all the stored summary feedback.
+func (b *BoltDB) ListSummaryFeedback() ([]models.SummaryFeedback, error) {
+	var feedback []models.SummaryFeedback
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(summaryFeedbackBucket))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			var f models.SummaryFeedback
+			if err := b.unmarshal(v, &f); err != nil {
+				return fmt.Errorf("failed to deserialize summary feedback at key '%s': %w", string(k), err)
+			}
+			feedback = append(feedback, f)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("DB transaction failed: %w", err)
+	}
+	return feedback, nil
+}
+
+// DeleteSummaryFeedback deletes the feedback with id, if any.
+func (b *BoltDB) DeleteSummaryFeedback(id string) error {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(summaryFeedbackBucket))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.Delete([]byte(id))
+	})
+	if err != nil {
+		return fmt.Errorf("DB transaction failed: %w", err)
+	}
+	return nil
+}
+
+// SaveResourceChanges appends changes to the stored resource changes.
+func (b *BoltDB) SaveResourceChanges(changes []models.ResourceChange) error {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(resourceChangesBucket))
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			serialized, err := b.marshal(change)
+			if err != nil {
+				return fmt.Errorf("serializing: %w", err)
+			}
+			// Keys are sequential so changes are iterated in the order
+			// they were saved.
+			seq, err := bucket.NextSequence()
+			if err != nil {
+				return err
+			}
+			key := make([]byte, 8)
+			binary.BigEndian.PutUint64(key, seq)
+			if err := bucket.Put(key, serialized); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("DB transaction failed: %w", err)
+	}
+	return nil
+}
+
+// ListResourceChanges returns the stored resource changes query selects, the
+// most recent first.
+func (b *BoltDB) ListResourceChanges(query models.ResourceChangeQuery) ([]models.ResourceChange, error) {
+	changes := []models.ResourceChange{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(resourceChangesBucket))
+		if bucket == nil {
+			return nil
+		}
+		c := bucket.Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			var change models.ResourceChange
+			if err := b.unmarshal(v, &change); err != nil {
+				return fmt.Errorf("failed to deserialize resource change at key '%x': %w", k, err)
+			}
+			if !query.Matches(change) {
+				continue
+			}
+			changes = append(changes, change)
+			if query.Limit > 0 && len(changes) == query.Limit {
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("DB transaction failed: %w", err)
+	}
+	return changes, nil
+}
+
+// SaveAPIToken creates or replaces the API token with token.ID.
+func (b *BoltDB) SaveAPIToken(token models.APIToken) error {
+	serialized, err := b.marshal(token)
+	if err != nil {
+		return fmt.Errorf("serializing: %w", err)
+	}
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(apiTokensBucket))
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(token.ID), serialized)
+	})
+	if err != nil {
+		return fmt.Errorf("DB transaction failed: %w", err)
+	}
+	return nil
+}
+
+// ListAPITokens returns all the stored API tokens.
+func (b *BoltDB) ListAPITokens() ([]models.APIToken, error) {
+	var tokens []models.APIToken
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(apiTokensBucket))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			var token models.APIToken
+			if err := b.unmarshal(v, &token); err != nil {
+				return fmt.Errorf("failed to deserialize API token at key '%s': %w", string(k), err)
+			}
+			tokens = append(tokens, token)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("DB transaction failed: %w", err)
+	}
+	return tokens, nil
+}
+
+// DeleteAPIToken deletes the API token with id and returns it, or nil if
+// there's none.
+func (b *BoltDB) DeleteAPIToken(id string) (*models.APIToken, error) {
+	var deleted *models.APIToken
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(apiTokensBucket))
+		if bucket == nil {
+			return nil
+		}
+		serialized := bucket.Get([]byte(id))
+		if serialized == nil {
+			return nil
+		}
+		var token models.APIToken
+		if err := b.unmarshal(serialized, &token); err != nil {
+			return fmt.Errorf("failed to deserialize API token at key '%s': %w", id, err)
+		}
+		deleted = &token
+		return bucket.Delete([]byte(id))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("DB transaction failed: %w", err)
+	}
+	return deleted, nil
+}
+
+// SavePreviewEnvironment creates or replaces the preview environment with
+// env.ID().
+func (b *BoltDB) SavePreviewEnvironment(env models.PreviewEnvironment) error {
+	serialized, err := b.marshal(env)
+	if err != nil {
+		return fmt.Errorf("serializing: %w", err)
+	}
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(previewsBucket))
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(env.ID()), serialized)
+	})
+	if err != nil {
+		return fmt.Errorf("DB transaction failed: %w", err)
+	}
+	return nil
+}
+
+// ListPreviewEnvironments returns all the stored preview environments.
+func (b *BoltDB) ListPreviewEnvironments() ([]models.PreviewEnvironment, error) {
+	var envs []models.PreviewEnvironment
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(previewsBucket))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			var env models.PreviewEnvironment
+			if err := b.unmarshal(v, &env); err != nil {
+				return fmt.Errorf("failed to deserialize preview environment at key '%s': %w", string(k), err)
+			}
+			envs = append(envs, env)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("DB transaction failed: %w", err)
+	}
+	return envs, nil
+}
+
+// DeletePreviewEnvironment deletes the preview environment with id, if any.
+func (b *BoltDB) DeletePreviewEnvironment(id string) error {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(previewsBucket))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.Delete([]byte(id))
+	})
+	if err != nil {
+		return fmt.Errorf("DB transaction failed: %w", err)
+	}
+	return nil
+}
+
+// SaveApplyReport appends report to the stored apply reports.
+func (b *BoltDB) SaveApplyReport(report models.SignedApplyReport) error {
+	serialized, err := b.marshal(report)
+	if err != nil {
+		return fmt.Errorf("serializing: %w", err)
+	}
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(applyReportsBucket))
+		if err != nil {
+			return err
+		}
+		// Keys are sequential so reports are iterated in the order they
+		// were saved.
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, seq)
+		return bucket.Put(key, serialized)
+	})
+	if err != nil {
+		return fmt.Errorf("DB transaction failed: %w", err)
+	}
+	return nil
+}
+
+// ListApplyReports returns the stored apply reports query selects, the most
+// recent first.
+func (b *BoltDB) ListApplyReports(query models.ApplyReportQuery) ([]models.SignedApplyReport, error) {
+	reports := []models.SignedApplyReport{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(applyReportsBucket))
+		if bucket == nil {
+			return nil
+		}
+		c := bucket.Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			var report models.SignedApplyReport
+			if err := b.unmarshal(v, &report); err != nil {
+				return fmt.Errorf("failed to deserialize apply report at key '%x': %w", k, err)
+			}
+			if !query.Matches(report) {
+				continue
+			}
+			reports = append(reports, report)
+			if query.Limit > 0 && len(reports) == query.Limit {
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("DB transaction failed: %w", err)
+	}
+	return reports, nil
+}
+
+// SaveCommandRecord appends record to the stored command records and deletes
+// the ones older than models.CommandRecordRetention.
+func (b *BoltDB) SaveCommandRecord(record models.CommandRecord) error {
+	serialized, err := b.marshal(record)
+	if err != nil {
+		return fmt.Errorf("serializing: %w", err)
+	}
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(commandRecordsBucket))
+		if err != nil {
+			return err
+		}
+		// Keys are sequential so records are iterated in the order they
+		// were saved, and the expired ones are first.
+		cutoff := time.Now().Add(-models.CommandRecordRetention)
+		c := bucket.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			var old models.CommandRecord
+			if err := b.unmarshal(v, &old); err != nil {
+				return fmt.Errorf("failed to deserialize command record at key '%x': %w", k, err)
+			}
+			if !old.Time.Before(cutoff) {
+				break
+			}
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, seq)
+		return bucket.Put(key, serialized)
+	})
+	if err != nil {
+		return fmt.Errorf("DB transaction failed: %w", err)
+	}
+	return nil
+}
+
+// ListCommandRecords returns the stored command records query selects, the
+// most recent first.
+func (b *BoltDB) ListCommandRecords(query models.CommandRecordQuery) ([]models.CommandRecord, error) {
+	records := []models.CommandRecord{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(commandRecordsBucket))
+		if bucket == nil {
+			return nil
+		}
+		c := bucket.Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			var record models.CommandRecord
+			if err := b.unmarshal(v, &record); err != nil {
+				return fmt.Errorf("failed to deserialize command record at key '%x': %w", k, err)
+			}
+			if !query.Matches(record) {
+				continue
+			}
+			records = append(records, record)
+			if query.Limit > 0 && len(records) == query.Limit {
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("DB transaction failed: %w", err)
+	}
+	return records, nil
+}
+
+// EnableEncryption encrypts the values stored from now on with c, and the
+// values stored before encryption was enabled. Keys, ex. the repo names in
+// lock keys, aren't encrypted. It errors if the database was encrypted with
+// another key.
+func (b *BoltDB) EnableEncryption(c *encryption.Cipher) error {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists([]byte(encryptionBucket))
+		if err != nil {
+			return err
+		}
+		if check := meta.Get([]byte(encryptionCheckKey)); check != nil {
+			if _, err := c.Decrypt(check); err != nil {
+				return errors.New("the database was encrypted with another key")
+			}
+		} else {
+			check, err := c.Encrypt([]byte(encryptionCheckKey))
+			if err != nil {
+				return err
+			}
+			if err := meta.Put([]byte(encryptionCheckKey), check); err != nil {
+				return err
+			}
+		}
+
+		return tx.ForEach(func(name []byte, bucket *bolt.Bucket) error {
+			if string(name) == encryptionBucket {
+				return nil
+			}
+			return encryptBucket(c, bucket)
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("enabling encryption: %w", err)
+	}
+	b.cipher = c
+	return nil
+}
+
+// encryptBucket encrypts the plaintext values of bucket.
+func encryptBucket(c *encryption.Cipher, bucket *bolt.Bucket) error {
+	plaintext := map[string][]byte{}
+	if err := bucket.ForEach(func(k, v []byte) error {
+		// Nested buckets have nil values.
+		if v != nil && !encryption.IsEncrypted(v) {
+			plaintext[string(k)] = append([]byte(nil), v...)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	for k, v := range plaintext {
+		encrypted, err := c.Encrypt(v)
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put([]byte(k), encrypted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Encrypted returns whether encryption was enabled on the database.
+func (b *BoltDB) Encrypted() (bool, error) {
+	var encrypted bool
+	err := b.db.View(func(tx *bolt.Tx) error {
+		meta := tx.Bucket([]byte(encryptionBucket))
+		encrypted = meta != nil && meta.Get([]byte(encryptionCheckKey)) != nil
+		return nil
+	})
+	return encrypted, err
+}
+
+// DataKey returns the key encrypting the database with envelope encryption.
+// The first time, it's generated with encrypter and stored encrypted by it,
+// afterwards the stored key is decrypted with encrypter.
+func (b *BoltDB) DataKey(encrypter encryption.KeyEncrypter) ([]byte, error) {
+	var stored []byte
+	err := b.db.View(func(tx *bolt.Tx) error {
+		if meta := tx.Bucket([]byte(encryptionBucket)); meta != nil {
+			stored = append([]byte(nil), meta.Get([]byte(encryptionDataKey))...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("DB transaction failed: %w", err)
+	}
+	if len(stored) > 0 {
+		return encrypter.Decrypt(stored)
+	}
+
+	key, encrypted, err := encrypter.GenerateDataKey()
+	if err != nil {
+		return nil, err
+	}
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists([]byte(encryptionBucket))
+		if err != nil {
+			return err
+		}
+		return meta.Put([]byte(encryptionDataKey), encrypted)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("DB transaction failed: %w", err)
+	}
+	return key, nil
+}
+
+// marshal serializes v, encrypting it if encryption is enabled.
+func (b *BoltDB) marshal(v any) ([]byte, error) {
+	serialized, err := json.Marshal(v)
+	if err != nil || b.cipher == nil {
+		return serialized, err
+	}
+	return b.cipher.Encrypt(serialized)
+}
+
+// unmarshal deserializes data into v, decrypting it if it's encrypted.
+func (b *BoltDB) unmarshal(data []byte, v any) error {
+	if encryption.IsEncrypted(data) {
+		if b.cipher == nil {
+			return errors.New("value is encrypted but database encryption isn't enabled")
+		}
+		var err error
+		if data, err = b.cipher.Decrypt(data); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(data, v)
+}
+
 func (b *BoltDB) Close() error {
 	return b.db.Close()
 }
@@ -14,6 +14,7 @@
 package boltdb_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -22,6 +23,7 @@ import (
 	"time"
 
 	"github.com/runatlantis/atlantis/server/core/boltdb"
+	"github.com/runatlantis/atlantis/server/core/encryption"
 
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
@@ -1052,6 +1054,37 @@ func TestPullStatus_UpdateMerge_ApprovePolicies(t *testing.T) {
 }
 
 // newTestDB returns a TestDB using a temporary path.
+func TestSummaryFeedback(t *testing.T) {
+	b := newTestDB2(t)
+
+	feedback, err := b.ListSummaryFeedback()
+	Ok(t, err)
+	Equals(t, 0, len(feedback))
+
+	created := time.Now().UTC().Truncate(time.Second)
+	f := models.SummaryFeedback{
+		ID:         "runatlantis/atlantis/1/1",
+		Repo:       models.Repo{FullName: "runatlantis/atlantis"},
+		PullNum:    1,
+		Model:      "model",
+		PromptHash: "hash",
+		CreatedAt:  created,
+		UpdatedAt:  created,
+	}
+	Ok(t, b.SaveSummaryFeedback(f))
+	f.CommentID = 10
+	f.ThumbsUp = 2
+	Ok(t, b.SaveSummaryFeedback(f))
+
+	feedback, err = b.ListSummaryFeedback()
+	Ok(t, err)
+	Equals(t, []models.SummaryFeedback{f}, feedback)
+	Ok(t, b.DeleteSummaryFeedback(f.ID))
+	feedback, err = b.ListSummaryFeedback()
+	Ok(t, err)
+	Equals(t, 0, len(feedback))
+}
+
 func newTestDB() (*bolt.DB, *boltdb.BoltDB) {
 	// Retrieve a temporary path.
 	f, err := os.CreateTemp("", "")
@@ -1092,3 +1125,211 @@ func cleanupDB(db *bolt.DB) {
 	db.Close()           // nolint: errcheck
 	os.Remove(db.Path()) // nolint: errcheck
 }
+
+func TestAPITokens(t *testing.T) {
+	b := newTestDB2(t)
+
+	tokens, err := b.ListAPITokens()
+	Ok(t, err)
+	Equals(t, 0, len(tokens))
+
+	token := models.APIToken{
+		ID:        "1",
+		Name:      "ci",
+		Hash:      models.HashAPIToken("token"),
+		Scope:     models.APITokenScopePlan,
+		Repos:     []string{"runatlantis/*"},
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	Ok(t, b.SaveAPIToken(token))
+	tokens, err = b.ListAPITokens()
+	Ok(t, err)
+	Equals(t, []models.APIToken{token}, tokens)
+
+	deleted, err := b.DeleteAPIToken("1")
+	Ok(t, err)
+	Equals(t, &token, deleted)
+	deleted, err = b.DeleteAPIToken("1")
+	Ok(t, err)
+	Assert(t, deleted == nil, "exp no token to be deleted")
+	tokens, err = b.ListAPITokens()
+	Ok(t, err)
+	Equals(t, 0, len(tokens))
+}
+
+func TestPreviewEnvironments(t *testing.T) {
+	b := newTestDB2(t)
+
+	envs, err := b.ListPreviewEnvironments()
+	Ok(t, err)
+	Equals(t, 0, len(envs))
+
+	env := models.PreviewEnvironment{
+		Pull:        models.PullRequest{Num: 12, BaseRepo: models.Repo{FullName: "owner/repo"}},
+		ProjectName: "app",
+		RepoRelDir:  "app",
+		Workspace:   "pr-12",
+		DeployedAt:  time.Now().UTC().Truncate(time.Second),
+		Outputs:     map[string]string{"url": "https://pr-12.example.com"},
+	}
+	Ok(t, b.SavePreviewEnvironment(env))
+	env.Closed = true
+	Ok(t, b.SavePreviewEnvironment(env))
+	envs, err = b.ListPreviewEnvironments()
+	Ok(t, err)
+	Equals(t, []models.PreviewEnvironment{env}, envs)
+
+	Ok(t, b.DeletePreviewEnvironment(env.ID()))
+	Ok(t, b.DeletePreviewEnvironment(env.ID()))
+	envs, err = b.ListPreviewEnvironments()
+	Ok(t, err)
+	Equals(t, 0, len(envs))
+}
+
+func TestApplyReports(t *testing.T) {
+	b := newTestDB2(t)
+
+	reports, err := b.ListApplyReports(models.ApplyReportQuery{})
+	Ok(t, err)
+	Equals(t, 0, len(reports))
+
+	appliedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	first := models.SignedApplyReport{
+		Report: models.ApplyReport{
+			ID:              "1",
+			Repository:      "owner/repo",
+			PullNum:         1,
+			ProjectName:     "app",
+			AppliedBy:       "alice",
+			AppliedAt:       appliedAt,
+			Success:         true,
+			ResourceChanges: []models.ApplyReportResourceChange{{Address: "aws_instance.web", Action: "create"}},
+		},
+		Signature: "hmac-sha256:00",
+	}
+	second := models.SignedApplyReport{
+		Report:    models.ApplyReport{ID: "2", Repository: "owner/other", PullNum: 1, AppliedAt: appliedAt.Add(time.Hour)},
+		Signature: "hmac-sha256:01",
+	}
+	Ok(t, b.SaveApplyReport(first))
+	Ok(t, b.SaveApplyReport(second))
+
+	reports, err = b.ListApplyReports(models.ApplyReportQuery{})
+	Ok(t, err)
+	Equals(t, []models.SignedApplyReport{second, first}, reports)
+	reports, err = b.ListApplyReports(models.ApplyReportQuery{Limit: 1})
+	Ok(t, err)
+	Equals(t, []models.SignedApplyReport{second}, reports)
+	reports, err = b.ListApplyReports(models.ApplyReportQuery{Repository: "owner/repo", PullNum: 1})
+	Ok(t, err)
+	Equals(t, []models.SignedApplyReport{first}, reports)
+	reports, err = b.ListApplyReports(models.ApplyReportQuery{ID: "2"})
+	Ok(t, err)
+	Equals(t, []models.SignedApplyReport{second}, reports)
+}
+
+func TestCommandRecords(t *testing.T) {
+	b := newTestDB2(t)
+
+	now := time.Now().UTC()
+	expired := models.CommandRecord{ID: "1", Time: now.Add(-models.CommandRecordRetention - time.Hour), Repository: "owner/repo", PullNum: 1}
+	first := models.CommandRecord{ID: "2", Time: now, Repository: "owner/repo", PullNum: 1, User: "alice", Request: models.CommandRequest{Name: "plan", Flags: []string{"-var=a=b"}}}
+	second := models.CommandRecord{ID: "3", Time: now, Repository: "owner/other", PullNum: 1, Request: models.CommandRequest{Name: "apply"}}
+	Ok(t, b.SaveCommandRecord(expired))
+	Ok(t, b.SaveCommandRecord(first))
+	Ok(t, b.SaveCommandRecord(second))
+
+	// The expired record was deleted when the others were saved.
+	records, err := b.ListCommandRecords(models.CommandRecordQuery{})
+	Ok(t, err)
+	Equals(t, []models.CommandRecord{second, first}, records)
+	records, err = b.ListCommandRecords(models.CommandRecordQuery{Repository: "owner/repo", PullNum: 1})
+	Ok(t, err)
+	Equals(t, []models.CommandRecord{first}, records)
+	records, err = b.ListCommandRecords(models.CommandRecordQuery{ID: "3", Limit: 1})
+	Ok(t, err)
+	Equals(t, []models.CommandRecord{second}, records)
+}
+
+// fakeKeyEncrypter "encrypts" data keys by reversing them.
+type fakeKeyEncrypter struct {
+	generated int
+}
+
+func (f *fakeKeyEncrypter) GenerateDataKey() ([]byte, []byte, error) {
+	f.generated++
+	key := bytes.Repeat([]byte{byte(f.generated)}, encryption.KeySize)
+	return key, append([]byte("encrypted:"), key...), nil
+}
+
+func (f *fakeKeyEncrypter) Decrypt(encrypted []byte) ([]byte, error) {
+	return bytes.TrimPrefix(encrypted, []byte("encrypted:")), nil
+}
+
+func TestEnableEncryption(t *testing.T) {
+	db, b := newTestDB()
+	defer cleanupDB(db)
+	rawLock := func(p models.Project) []byte {
+		var value []byte
+		Ok(t, db.View(func(tx *bolt.Tx) error {
+			value = append([]byte(nil), tx.Bucket([]byte(lockBucket)).Get([]byte(models.GenerateLockKey(p, workspace)))...)
+			return nil
+		}))
+		return value
+	}
+
+	_, _, err := b.TryLock(lock)
+	Ok(t, err)
+	Assert(t, json.Valid(rawLock(project)), "exp the lock to be stored in plaintext")
+	encrypted, err := b.Encrypted()
+	Ok(t, err)
+	Assert(t, !encrypted, "exp the database not to be encrypted")
+
+	c, err := encryption.NewCipher(bytes.Repeat([]byte{1}, encryption.KeySize))
+	Ok(t, err)
+	Ok(t, b.EnableEncryption(c))
+	Assert(t, encryption.IsEncrypted(rawLock(project)), "exp the existing lock to be encrypted")
+	l, err := b.GetLock(project, workspace)
+	Ok(t, err)
+	Equals(t, lock.Pull.Num, l.Pull.Num)
+
+	otherProject := models.NewProject("owner/repo", "other", "")
+	otherLock := lock
+	otherLock.Project = otherProject
+	_, _, err = b.TryLock(otherLock)
+	Ok(t, err)
+	Assert(t, encryption.IsEncrypted(rawLock(otherProject)), "exp new locks to be encrypted")
+
+	// Enabling encryption again with the same key is a no-op.
+	Ok(t, b.EnableEncryption(c))
+	l, err = b.GetLock(otherProject, workspace)
+	Ok(t, err)
+	Equals(t, otherProject, l.Project)
+
+	plain, err := boltdb.NewWithDB(db, lockBucket, configBucket)
+	Ok(t, err)
+	encrypted, err = plain.Encrypted()
+	Ok(t, err)
+	Assert(t, encrypted, "exp the database to be encrypted")
+	_, err = plain.GetLock(project, workspace)
+	ErrContains(t, "value is encrypted but database encryption isn't enabled", err)
+
+	other, err := encryption.NewCipher(bytes.Repeat([]byte{2}, encryption.KeySize))
+	Ok(t, err)
+	ErrEquals(t, "enabling encryption: the database was encrypted with another key", plain.EnableEncryption(other))
+}
+
+func TestDataKey(t *testing.T) {
+	b := newTestDB2(t)
+	encrypter := &fakeKeyEncrypter{}
+
+	key, err := b.DataKey(encrypter)
+	Ok(t, err)
+	Equals(t, bytes.Repeat([]byte{1}, encryption.KeySize), key)
+
+	// The stored data key is reused.
+	key, err = b.DataKey(encrypter)
+	Ok(t, err)
+	Equals(t, bytes.Repeat([]byte{1}, encryption.KeySize), key)
+	Equals(t, 1, encrypter.generated)
+}
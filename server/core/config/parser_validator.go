@@ -347,6 +347,12 @@ func (p *ParserValidator) copyProjectWithDir(original raw.Project, newDir string
 		PolicyCheck:               original.PolicyCheck,
 		CustomPolicyCheck:         original.CustomPolicyCheck,
 		SilencePRComments:         original.SilencePRComments,
+		ApplyWindow:               original.ApplyWindow,
+		CostThreshold:             original.CostThreshold,
+		Environment:               original.Environment,
+		AgentPool:                 original.AgentPool,
+		Owners:                    original.Owners,
+		TFCWorkspace:              original.TFCWorkspace,
 	}
 
 	// Note: We intentionally do NOT copy the Name field.
@@ -13,6 +13,7 @@ import (
 	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-version"
 	"github.com/runatlantis/atlantis/server/core/config"
@@ -1310,6 +1311,22 @@ func TestParseGlobalCfg(t *testing.T) {
 		"workflow doesn't exist": {
 			input: `repos:
 - id: /.*/
+  workflow: notdefined`,
+			expErr: "workflow \"notdefined\" is not defined",
+		},
+		"org id is a regex": {
+			input: `orgs:
+- id: /.*/`,
+			expErr: "orgs: \"/.*/\" isn't an org id, org ids are {hostname}/{owner}, ex. github.com/runatlantis.",
+		},
+		"org id is a hostname": {
+			input: `orgs:
+- id: github.com`,
+			expErr: "orgs: \"github.com\" isn't an org id, org ids are {hostname}/{owner}, ex. github.com/runatlantis.",
+		},
+		"org workflow doesn't exist": {
+			input: `orgs:
+- id: github.com/owner
   workflow: notdefined`,
 			expErr: "workflow \"notdefined\" is not defined",
 		},
@@ -1317,7 +1334,7 @@ func TestParseGlobalCfg(t *testing.T) {
 			input: `repos:
 - id: /.*/
   allowed_overrides: [invalid]`,
-			expErr: "repos: (0: (allowed_overrides: \"invalid\" is not a valid override, only \"plan_requirements\", \"apply_requirements\", \"import_requirements\", \"workflow\", \"delete_source_branch_on_merge\", \"repo_locking\", \"repo_locks\", \"policy_check\", \"custom_policy_check\", and \"silence_pr_comments\" are supported.).).",
+			expErr: "repos: (0: (allowed_overrides: \"invalid\" is not a valid override, only \"plan_requirements\", \"apply_requirements\", \"import_requirements\", \"workflow\", \"delete_source_branch_on_merge\", \"repo_locking\", \"repo_locks\", \"policy_check\", \"custom_policy_check\", \"silence_pr_comments\", and \"preview\" are supported.).).",
 		},
 		"invalid plan_requirement": {
 			input: `repos:
@@ -1329,7 +1346,7 @@ func TestParseGlobalCfg(t *testing.T) {
 			input: `repos:
 - id: /.*/
   apply_requirements: [invalid]`,
-			expErr: "repos: (0: (apply_requirements: \"invalid\" is not a valid apply_requirement, only \"approved\", \"mergeable\" and \"undiverged\" are supported.).).",
+			expErr: "repos: (0: (apply_requirements: \"invalid\" is not a valid apply_requirement, only \"approved\", \"mergeable\", \"undiverged\", \"fresh\", \"change_request\", \"description\", \"verified_commits\" and \"codeowners_approved\" are supported.).).",
 		},
 		"invalid import_requirement": {
 			input: `repos:
@@ -1381,6 +1398,237 @@ func TestParseGlobalCfg(t *testing.T) {
 				},
 			},
 		},
+		"checkout strategy": {
+			input: `repos:
+- id: github.com/owner/repo
+  checkout_strategy: merge`,
+			exp: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					defaultCfg.Repos[0],
+					{
+						ID:               "github.com/owner/repo",
+						CheckoutStrategy: valid.CheckoutStrategyMerge,
+					},
+				},
+				Workflows: defaultCfg.Workflows,
+				TeamAuthz: valid.TeamAuthz{
+					Args: make([]string, 0),
+				},
+			},
+		},
+		"invalid checkout strategy": {
+			input: `repos:
+- id: /.*/
+  checkout_strategy: rebase`,
+			expErr: "repos: (0: (checkout_strategy: must be a valid value.).).",
+		},
+		"description sections": {
+			input: `repos:
+- id: github.com/owner/repo
+  apply_requirements: [description]
+  description_sections:
+  - name: Rollback plan
+    regex: '(?im)^#+\s*rollback plan'`,
+			exp: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					defaultCfg.Repos[0],
+					{
+						ID:                "github.com/owner/repo",
+						ApplyRequirements: []string{"description"},
+						DescriptionSections: []valid.DescriptionSection{
+							{Name: "Rollback plan", Regex: regexp.MustCompile(`(?im)^#+\s*rollback plan`)},
+						},
+					},
+				},
+				Workflows: defaultCfg.Workflows,
+				TeamAuthz: valid.TeamAuthz{
+					Args: make([]string, 0),
+				},
+			},
+		},
+		"invalid description section regex": {
+			input: `repos:
+- id: /.*/
+  description_sections:
+  - name: Rollback plan
+    regex: '(rollback'`,
+			expErr: "repos: (0: (description_sections: (0: (regex: \"(rollback\" is not a valid regex: error parsing regexp: missing closing ): `(rollback`.).).).).",
+		},
+		"behavior rules": {
+			input: `repos:
+- id: github.com/owner/repo
+  behavior_rules:
+  - branch: '^hotfix/'
+    skip_apply_window: true
+    skip_apply_requirements: [approved]
+  - commit_message: '^chore\(deps\)'
+    auto_apply: true`,
+			exp: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					defaultCfg.Repos[0],
+					{
+						ID: "github.com/owner/repo",
+						BehaviorRules: []valid.BehaviorRule{
+							{Branch: regexp.MustCompile(`^hotfix/`), SkipApplyWindow: true, SkipApplyRequirements: []string{"approved"}},
+							{CommitMessage: regexp.MustCompile(`^chore\(deps\)`), AutoApply: true},
+						},
+					},
+				},
+				Workflows: defaultCfg.Workflows,
+				TeamAuthz: valid.TeamAuthz{
+					Args: make([]string, 0),
+				},
+			},
+		},
+		"behavior rule without branch or commit message": {
+			input: `repos:
+- id: /.*/
+  behavior_rules:
+  - auto_apply: true`,
+			expErr: "repos: (0: (behavior_rules: (0: branch or commit_message must be set.).).).",
+		},
+		"freezes": {
+			input: `freezes:
+- name: holidays
+  start: 2025-12-20T00:00:00Z
+  end: 2026-01-05T00:00:00Z
+  override_users: [alice]
+- name: releases
+  ical_url: https://calendar.example.com/freezes.ics
+  projects: [prod-*]`,
+			exp: valid.GlobalCfg{
+				Repos:     defaultCfg.Repos,
+				Workflows: defaultCfg.Workflows,
+				TeamAuthz: valid.TeamAuthz{
+					Args: make([]string, 0),
+				},
+				Freezes: []valid.Freeze{
+					{
+						Name: "holidays",
+						Windows: []valid.FreezeWindow{{
+							Start: time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC),
+							End:   time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC),
+						}},
+						OverrideUsers: []string{"alice"},
+					},
+					{
+						Name:     "releases",
+						ICalURL:  "https://calendar.example.com/freezes.ics",
+						Projects: []string{"prod-*"},
+					},
+				},
+			},
+		},
+		"duplicate freezes": {
+			input: `freezes:
+- name: holidays
+  ical_url: https://calendar.example.com/holidays.ics
+- name: holidays
+  ical_url: https://calendar.example.com/other-holidays.ics`,
+			expErr: `freeze "holidays" is defined more than once`,
+		},
+		"no changes plan comments": {
+			input: `repos:
+- id: github.com/owner/repo
+  no_changes_plan_comments: rollup`,
+			exp: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					defaultCfg.Repos[0],
+					{
+						ID:                    "github.com/owner/repo",
+						NoChangesPlanComments: "rollup",
+					},
+				},
+				Workflows: defaultCfg.Workflows,
+				TeamAuthz: valid.TeamAuthz{
+					Args: make([]string, 0),
+				},
+			},
+		},
+		"invalid no changes plan comments": {
+			input: `repos:
+- id: /.*/
+  no_changes_plan_comments: hide`,
+			expErr: "repos: (0: (no_changes_plan_comments: must be a valid value.).).",
+		},
+		"allowed tfc workspaces": {
+			input: `repos:
+- id: github.com/owner/repo
+  allowed_tfc_workspaces: [my-org/prod-*]`,
+			exp: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					defaultCfg.Repos[0],
+					{
+						ID:                   "github.com/owner/repo",
+						AllowedTFCWorkspaces: []string{"my-org/prod-*"},
+					},
+				},
+				Workflows: defaultCfg.Workflows,
+				TeamAuthz: valid.TeamAuthz{
+					Args: make([]string, 0),
+				},
+			},
+		},
+		"concurrency group": {
+			input: `repos:
+- id: github.com/owner/repo
+  concurrency_group: prod`,
+			exp: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					defaultCfg.Repos[0],
+					{
+						ID:               "github.com/owner/repo",
+						ConcurrencyGroup: "prod",
+					},
+				},
+				Workflows: defaultCfg.Workflows,
+				TeamAuthz: valid.TeamAuthz{
+					Args: make([]string, 0),
+				},
+			},
+		},
+		"apply window override users": {
+			input: `repos:
+- id: github.com/owner/repo
+  apply_window_override_users: [alice]`,
+			exp: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					defaultCfg.Repos[0],
+					{
+						ID:                       "github.com/owner/repo",
+						ApplyWindowOverrideUsers: []string{"alice"},
+					},
+				},
+				Workflows: defaultCfg.Workflows,
+				TeamAuthz: valid.TeamAuthz{
+					Args: make([]string, 0),
+				},
+			},
+		},
+		"cost approvers": {
+			input: `repos:
+- id: github.com/owner/repo
+  cost_approvers: ["@org/finops"]`,
+			exp: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					defaultCfg.Repos[0],
+					{
+						ID:            "github.com/owner/repo",
+						CostApprovers: []string{"@org/finops"},
+					},
+				},
+				Workflows: defaultCfg.Workflows,
+				TeamAuthz: valid.TeamAuthz{
+					Args: make([]string, 0),
+				},
+			},
+		},
+		"invalid allowed tfc workspaces": {
+			input: `repos:
+- id: /.*/
+  allowed_tfc_workspaces: [prod]`,
+			expErr: `repos: (0: (allowed_tfc_workspaces: "prod" must be an organization and a workspace pattern, ex. my-org/prod-*.).).`,
+		},
 		"no workflows key": {
 			input: `repos: []`,
 			exp:   defaultCfg,
@@ -0,0 +1,151 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package raw
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// ApplyWindow is the raw schema for a project's apply_window key, ex.
+//
+//	apply_window:
+//	  days: [Mon-Thu]
+//	  hours: 09:00-16:00
+//	  tz: America/New_York
+type ApplyWindow struct {
+	Days  []string `yaml:"days,omitempty"`
+	Hours *string  `yaml:"hours,omitempty"`
+	TZ    *string  `yaml:"tz,omitempty"`
+}
+
+func (a ApplyWindow) Validate() error {
+	daysValid := func(value any) error {
+		for _, d := range value.([]string) {
+			if _, err := parseWeekdays(d); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	hoursValid := func(value any) error {
+		hours := value.(*string)
+		if hours == nil {
+			return nil
+		}
+		_, _, err := parseHours(*hours)
+		return err
+	}
+	tzValid := func(value any) error {
+		tz := value.(*string)
+		if tz == nil {
+			return nil
+		}
+		if _, err := time.LoadLocation(*tz); err != nil {
+			return fmt.Errorf("%q is not a valid time zone: %w", *tz, err)
+		}
+		return nil
+	}
+	return validation.ValidateStruct(&a,
+		validation.Field(&a.Days, validation.By(daysValid)),
+		validation.Field(&a.Hours, validation.By(hoursValid)),
+		validation.Field(&a.TZ, validation.By(tzValid)),
+	)
+}
+
+func (a ApplyWindow) ToValid() *valid.ApplyWindow {
+	v := valid.ApplyWindow{
+		Location: time.UTC,
+	}
+	seen := make(map[time.Weekday]bool)
+	for _, d := range a.Days {
+		// Safe to ignore the error because we test it in Validate().
+		days, _ := parseWeekdays(d)
+		for _, day := range days {
+			if !seen[day] {
+				seen[day] = true
+				v.Days = append(v.Days, day)
+			}
+		}
+	}
+	if a.Hours != nil {
+		v.Start, v.End, _ = parseHours(*a.Hours)
+	}
+	if a.TZ != nil {
+		v.Location, _ = time.LoadLocation(*a.TZ)
+	}
+	return &v
+}
+
+// parseWeekdays parses a single weekday ("Mon") or an inclusive range of
+// weekdays ("Mon-Thu", "Fri-Mon").
+func parseWeekdays(s string) ([]time.Weekday, error) {
+	from, to, isRange := strings.Cut(s, "-")
+	start, ok := weekdays[strings.ToLower(strings.TrimSpace(from))]
+	if !ok {
+		return nil, fmt.Errorf("%q is not a valid day, expected one of Mon, Tue, Wed, Thu, Fri, Sat, Sun or a range like Mon-Thu", s)
+	}
+	if !isRange {
+		return []time.Weekday{start}, nil
+	}
+	end, ok := weekdays[strings.ToLower(strings.TrimSpace(to))]
+	if !ok {
+		return nil, fmt.Errorf("%q is not a valid day range, expected a range like Mon-Thu", s)
+	}
+	var days []time.Weekday
+	for d := start; ; d = (d + 1) % 7 {
+		days = append(days, d)
+		if d == end {
+			break
+		}
+	}
+	return days, nil
+}
+
+// parseHours parses a range of hours like "09:00-16:00" into offsets from
+// midnight.
+func parseHours(s string) (time.Duration, time.Duration, error) {
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("%q is not a valid hours range, expected a range like 09:00-16:00", s)
+	}
+	start, err := parseClock(strings.TrimSpace(from))
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := parseClock(strings.TrimSpace(to))
+	if err != nil {
+		return 0, 0, err
+	}
+	if start == end {
+		return 0, 0, fmt.Errorf("%q is not a valid hours range, start and end cannot be equal", s)
+	}
+	return start, end, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	if s == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, errors.New("hours must be formatted as HH:MM-HH:MM")
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package raw_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestApplyWindow_Validate(t *testing.T) {
+	cases := []struct {
+		description string
+		input       raw.ApplyWindow
+		errContains string
+	}{
+		{
+			description: "nothing set",
+			input:       raw.ApplyWindow{},
+		},
+		{
+			description: "all fields set",
+			input: raw.ApplyWindow{
+				Days:  []string{"Mon-Thu", "sat"},
+				Hours: String("09:00-16:00"),
+				TZ:    String("UTC"),
+			},
+		},
+		{
+			description: "invalid day",
+			input: raw.ApplyWindow{
+				Days: []string{"Monday"},
+			},
+			errContains: `Days: "Monday" is not a valid day`,
+		},
+		{
+			description: "invalid day range",
+			input: raw.ApplyWindow{
+				Days: []string{"Mon-Someday"},
+			},
+			errContains: `Days: "Mon-Someday" is not a valid day range`,
+		},
+		{
+			description: "invalid hours",
+			input: raw.ApplyWindow{
+				Hours: String("9am-4pm"),
+			},
+			errContains: "Hours: hours must be formatted as HH:MM-HH:MM",
+		},
+		{
+			description: "equal hours",
+			input: raw.ApplyWindow{
+				Hours: String("09:00-09:00"),
+			},
+			errContains: "start and end cannot be equal",
+		},
+		{
+			description: "invalid time zone",
+			input: raw.ApplyWindow{
+				TZ: String("Mars/Olympus_Mons"),
+			},
+			errContains: `TZ: "Mars/Olympus_Mons" is not a valid time zone`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			err := c.input.Validate()
+			if c.errContains == "" {
+				Ok(t, err)
+			} else {
+				ErrContains(t, c.errContains, err)
+			}
+		})
+	}
+}
+
+func TestApplyWindow_ToValid(t *testing.T) {
+	w := raw.ApplyWindow{
+		Days:  []string{"Fri-Mon", "Sun"},
+		Hours: String("22:00-06:30"),
+	}
+	Equals(t, &valid.ApplyWindow{
+		Days:     []time.Weekday{time.Friday, time.Saturday, time.Sunday, time.Monday},
+		Start:    22 * time.Hour,
+		End:      6*time.Hour + 30*time.Minute,
+		Location: time.UTC,
+	}, w.ToValid())
+}
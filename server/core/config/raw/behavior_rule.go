@@ -0,0 +1,66 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package raw
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+)
+
+// BehaviorRule is the raw schema for a rule of a repo's behavior_rules key,
+// ex.
+//
+//	behavior_rules:
+//	- branch: '^hotfix/'
+//	  skip_apply_window: true
+//	- commit_message: '^chore\(deps\)'
+//	  auto_apply: true
+type BehaviorRule struct {
+	Branch                *string  `yaml:"branch,omitempty" json:"branch,omitempty"`
+	CommitMessage         *string  `yaml:"commit_message,omitempty" json:"commit_message,omitempty"`
+	SkipApplyWindow       bool     `yaml:"skip_apply_window,omitempty" json:"skip_apply_window,omitempty"`
+	SkipApplyRequirements []string `yaml:"skip_apply_requirements,omitempty" json:"skip_apply_requirements,omitempty"`
+	AutoApply             bool     `yaml:"auto_apply,omitempty" json:"auto_apply,omitempty"`
+}
+
+func (b BehaviorRule) Validate() error {
+	if b.Branch == nil && b.CommitMessage == nil {
+		return errors.New("branch or commit_message must be set")
+	}
+	regexValid := func(value any) error {
+		regex := value.(*string)
+		if regex == nil {
+			return nil
+		}
+		if _, err := regexp.Compile(*regex); err != nil {
+			return fmt.Errorf("%q is not a valid regex: %w", *regex, err)
+		}
+		return nil
+	}
+	return validation.ValidateStruct(&b,
+		validation.Field(&b.Branch, validation.By(regexValid)),
+		validation.Field(&b.CommitMessage, validation.By(regexValid)),
+		validation.Field(&b.SkipApplyRequirements, validation.By(validApplyReq)),
+	)
+}
+
+func (b BehaviorRule) ToValid() valid.BehaviorRule {
+	v := valid.BehaviorRule{
+		SkipApplyWindow:       b.SkipApplyWindow,
+		SkipApplyRequirements: b.SkipApplyRequirements,
+		AutoApply:             b.AutoApply,
+	}
+	// Safe to use MustCompile because we test it in Validate().
+	if b.Branch != nil {
+		v.Branch = regexp.MustCompile(*b.Branch)
+	}
+	if b.CommitMessage != nil {
+		v.CommitMessage = regexp.MustCompile(*b.CommitMessage)
+	}
+	return v
+}
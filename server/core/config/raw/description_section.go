@@ -0,0 +1,44 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package raw
+
+import (
+	"fmt"
+	"regexp"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+)
+
+// DescriptionSection is the raw schema for a section of a repo's
+// description_sections key, ex.
+//
+//	description_sections:
+//	- name: Rollback plan
+//	  regex: '(?im)^#+\s*rollback plan\s*$'
+type DescriptionSection struct {
+	Name  string `yaml:"name" json:"name"`
+	Regex string `yaml:"regex" json:"regex"`
+}
+
+func (d DescriptionSection) Validate() error {
+	regexValid := func(value any) error {
+		if _, err := regexp.Compile(value.(string)); err != nil {
+			return fmt.Errorf("%q is not a valid regex: %w", value, err)
+		}
+		return nil
+	}
+	return validation.ValidateStruct(&d,
+		validation.Field(&d.Name, validation.Required),
+		validation.Field(&d.Regex, validation.Required, validation.By(regexValid)),
+	)
+}
+
+func (d DescriptionSection) ToValid() valid.DescriptionSection {
+	return valid.DescriptionSection{
+		Name: d.Name,
+		// Safe to use MustCompile because we test it in Validate().
+		Regex: regexp.MustCompile(d.Regex),
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package raw
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+)
+
+// Freeze is the raw schema for a change freeze of the server-side repo
+// config, ex.
+//
+//	freezes:
+//	- name: holidays
+//	  start: 2025-12-20T00:00:00Z
+//	  end: 2026-01-05T00:00:00Z
+//	  repos: [/github.com/org/.*/]
+//	  override_users: [alice]
+type Freeze struct {
+	Name          string   `yaml:"name" json:"name"`
+	Start         string   `yaml:"start,omitempty" json:"start,omitempty"`
+	End           string   `yaml:"end,omitempty" json:"end,omitempty"`
+	ICalURL       string   `yaml:"ical_url,omitempty" json:"ical_url,omitempty"`
+	Repos         []string `yaml:"repos,omitempty" json:"repos,omitempty"`
+	Projects      []string `yaml:"projects,omitempty" json:"projects,omitempty"`
+	Message       string   `yaml:"message,omitempty" json:"message,omitempty"`
+	OverrideUsers []string `yaml:"override_users,omitempty" json:"override_users,omitempty"`
+}
+
+func (f Freeze) Validate() error {
+	if (f.Start != "" || f.End != "") == (f.ICalURL != "") {
+		return errors.New("exactly one of start and end, or ical_url must be set")
+	}
+	timeValid := func(value any) error {
+		s := value.(string)
+		if s == "" {
+			return errors.New("must be set along with start and end")
+		}
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			return fmt.Errorf("%q is not an RFC 3339 time, ex. 2025-12-20T00:00:00Z", s)
+		}
+		return nil
+	}
+	urlValid := func(value any) error {
+		u, err := url.Parse(value.(string))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("%q is not an http or https URL", value)
+		}
+		return nil
+	}
+	reposValid := func(value any) error {
+		for _, id := range value.([]string) {
+			if strings.HasPrefix(id, "/") && strings.HasSuffix(id, "/") && len(id) > 1 {
+				if _, err := regexp.Compile(id[1 : len(id)-1]); err != nil {
+					return fmt.Errorf("parsing: %s: %w", id, err)
+				}
+			}
+		}
+		return nil
+	}
+	projectsValid := func(value any) error {
+		for _, pattern := range value.([]string) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("%q is not a valid pattern", pattern)
+			}
+		}
+		return nil
+	}
+
+	rules := []*validation.FieldRules{
+		validation.Field(&f.Name, validation.Required),
+		validation.Field(&f.Repos, validation.By(reposValid)),
+		validation.Field(&f.Projects, validation.By(projectsValid)),
+	}
+	if f.ICalURL != "" {
+		rules = append(rules, validation.Field(&f.ICalURL, validation.By(urlValid)))
+	} else {
+		rules = append(rules,
+			validation.Field(&f.Start, validation.By(timeValid)),
+			validation.Field(&f.End, validation.By(timeValid)),
+		)
+	}
+	if err := validation.ValidateStruct(&f, rules...); err != nil {
+		return err
+	}
+	if f.ICalURL == "" {
+		start, _ := time.Parse(time.RFC3339, f.Start)
+		end, _ := time.Parse(time.RFC3339, f.End)
+		if !end.After(start) {
+			return errors.New("end must be after start")
+		}
+	}
+	return nil
+}
+
+func (f Freeze) ToValid() valid.Freeze {
+	v := valid.Freeze{
+		Name:          f.Name,
+		ICalURL:       f.ICalURL,
+		Repos:         f.Repos,
+		Projects:      f.Projects,
+		Message:       f.Message,
+		OverrideUsers: f.OverrideUsers,
+	}
+	if f.ICalURL == "" {
+		// Safe to ignore the errors because we test them in Validate().
+		start, _ := time.Parse(time.RFC3339, f.Start)
+		end, _ := time.Parse(time.RFC3339, f.End)
+		v.Windows = []valid.FreezeWindow{{Start: start, End: end}}
+	}
+	return v
+}
@@ -0,0 +1,130 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package raw_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestFreeze_Validate(t *testing.T) {
+	cases := []struct {
+		description string
+		input       raw.Freeze
+		errContains string
+	}{
+		{
+			description: "static freeze",
+			input: raw.Freeze{
+				Name:          "holidays",
+				Start:         "2025-12-20T00:00:00Z",
+				End:           "2026-01-05T00:00:00+01:00",
+				Repos:         []string{"github.com/org/repo", "/github.com/org/.*/"},
+				Projects:      []string{"prod-*"},
+				OverrideUsers: []string{"alice"},
+			},
+		},
+		{
+			description: "calendar freeze",
+			input: raw.Freeze{
+				Name:    "releases",
+				ICalURL: "https://calendar.example.com/freezes.ics",
+			},
+		},
+		{
+			description: "no name",
+			input:       raw.Freeze{ICalURL: "https://calendar.example.com/freezes.ics"},
+			errContains: "name: cannot be blank",
+		},
+		{
+			description: "no window",
+			input:       raw.Freeze{Name: "holidays"},
+			errContains: "exactly one of start and end, or ical_url must be set",
+		},
+		{
+			description: "window and calendar",
+			input: raw.Freeze{
+				Name:    "holidays",
+				Start:   "2025-12-20T00:00:00Z",
+				End:     "2026-01-05T00:00:00Z",
+				ICalURL: "https://calendar.example.com/freezes.ics",
+			},
+			errContains: "exactly one of start and end, or ical_url must be set",
+		},
+		{
+			description: "no end",
+			input:       raw.Freeze{Name: "holidays", Start: "2025-12-20T00:00:00Z"},
+			errContains: "end: must be set along with start and end",
+		},
+		{
+			description: "invalid start",
+			input:       raw.Freeze{Name: "holidays", Start: "2025-12-20", End: "2026-01-05T00:00:00Z"},
+			errContains: `start: "2025-12-20" is not an RFC 3339 time`,
+		},
+		{
+			description: "end before start",
+			input:       raw.Freeze{Name: "holidays", Start: "2026-01-05T00:00:00Z", End: "2025-12-20T00:00:00Z"},
+			errContains: "end must be after start",
+		},
+		{
+			description: "invalid calendar URL",
+			input:       raw.Freeze{Name: "releases", ICalURL: "file:///etc/freezes.ics"},
+			errContains: `ical_url: "file:///etc/freezes.ics" is not an http or https URL`,
+		},
+		{
+			description: "invalid repo regex",
+			input:       raw.Freeze{Name: "releases", ICalURL: "https://calendar.example.com/freezes.ics", Repos: []string{"/github.com/(org/"}},
+			errContains: "repos: parsing: /github.com/(org/",
+		},
+		{
+			description: "invalid project pattern",
+			input:       raw.Freeze{Name: "releases", ICalURL: "https://calendar.example.com/freezes.ics", Projects: []string{"prod-["}},
+			errContains: `projects: "prod-[" is not a valid pattern`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			err := c.input.Validate()
+			if c.errContains == "" {
+				Ok(t, err)
+			} else {
+				ErrContains(t, c.errContains, err)
+			}
+		})
+	}
+}
+
+func TestFreeze_ToValid(t *testing.T) {
+	Equals(t, valid.Freeze{
+		Name: "holidays",
+		Windows: []valid.FreezeWindow{{
+			Start: time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC),
+		}},
+		Repos:         []string{"github.com/org/repo"},
+		Message:       "Happy holidays!",
+		OverrideUsers: []string{"alice"},
+	}, raw.Freeze{
+		Name:          "holidays",
+		Start:         "2025-12-20T00:00:00Z",
+		End:           "2026-01-05T00:00:00Z",
+		Repos:         []string{"github.com/org/repo"},
+		Message:       "Happy holidays!",
+		OverrideUsers: []string{"alice"},
+	}.ToValid())
+
+	Equals(t, valid.Freeze{
+		Name:     "releases",
+		ICalURL:  "https://calendar.example.com/freezes.ics",
+		Projects: []string{"prod-*"},
+	}, raw.Freeze{
+		Name:     "releases",
+		ICalURL:  "https://calendar.example.com/freezes.ics",
+		Projects: []string{"prod-*"},
+	}.ToValid())
+}
@@ -6,7 +6,9 @@ package raw
 import (
 	"errors"
 	"fmt"
+	"path"
 	"regexp"
+	"slices"
 	"strings"
 
 	validation "github.com/go-ozzo/ozzo-validation"
@@ -16,48 +18,80 @@ import (
 
 // GlobalCfg is the raw schema for server-side repo config.
 type GlobalCfg struct {
+	// Orgs are defaults for all repos of an org, they're merged beneath Repos.
+	Orgs       []Repo              `yaml:"orgs" json:"orgs"`
 	Repos      []Repo              `yaml:"repos" json:"repos"`
 	Workflows  map[string]Workflow `yaml:"workflows" json:"workflows"`
 	PolicySets PolicySets          `yaml:"policies" json:"policies"`
 	Metrics    Metrics             `yaml:"metrics" json:"metrics"`
 	TeamAuthz  TeamAuthz           `yaml:"team_authz" json:"team_authz"`
+	Freezes    []Freeze            `yaml:"freezes" json:"freezes"`
 }
 
 // Repo is the raw schema for repos in the server-side repo config.
 type Repo struct {
-	ID                        string         `yaml:"id" json:"id"`
-	Branch                    string         `yaml:"branch" json:"branch"`
-	RepoConfigFile            string         `yaml:"repo_config_file" json:"repo_config_file"`
-	PlanRequirements          []string       `yaml:"plan_requirements" json:"plan_requirements"`
-	ApplyRequirements         []string       `yaml:"apply_requirements" json:"apply_requirements"`
-	ImportRequirements        []string       `yaml:"import_requirements" json:"import_requirements"`
-	PreWorkflowHooks          []WorkflowHook `yaml:"pre_workflow_hooks" json:"pre_workflow_hooks"`
-	Workflow                  *string        `yaml:"workflow,omitempty" json:"workflow,omitempty"`
-	PostWorkflowHooks         []WorkflowHook `yaml:"post_workflow_hooks" json:"post_workflow_hooks"`
-	AllowedWorkflows          []string       `yaml:"allowed_workflows,omitempty" json:"allowed_workflows,omitempty"`
-	AllowedOverrides          []string       `yaml:"allowed_overrides" json:"allowed_overrides"`
-	AllowCustomWorkflows      *bool          `yaml:"allow_custom_workflows,omitempty" json:"allow_custom_workflows,omitempty"`
-	DeleteSourceBranchOnMerge *bool          `yaml:"delete_source_branch_on_merge,omitempty" json:"delete_source_branch_on_merge,omitempty"`
-	RepoLocking               *bool          `yaml:"repo_locking,omitempty" json:"repo_locking,omitempty"`
-	RepoLocks                 *RepoLocks     `yaml:"repo_locks,omitempty" json:"repo_locks,omitempty"`
-	PolicyCheck               *bool          `yaml:"policy_check,omitempty" json:"policy_check,omitempty"`
-	CustomPolicyCheck         *bool          `yaml:"custom_policy_check,omitempty" json:"custom_policy_check,omitempty"`
-	AutoDiscover              *AutoDiscover  `yaml:"autodiscover,omitempty" json:"autodiscover,omitempty"`
-	SilencePRComments         []string       `yaml:"silence_pr_comments,omitempty" json:"silence_pr_comments,omitempty"`
+	ID                        string               `yaml:"id" json:"id"`
+	Branch                    string               `yaml:"branch" json:"branch"`
+	RepoConfigFile            string               `yaml:"repo_config_file" json:"repo_config_file"`
+	PlanRequirements          []string             `yaml:"plan_requirements" json:"plan_requirements"`
+	ApplyRequirements         []string             `yaml:"apply_requirements" json:"apply_requirements"`
+	ImportRequirements        []string             `yaml:"import_requirements" json:"import_requirements"`
+	PreWorkflowHooks          []WorkflowHook       `yaml:"pre_workflow_hooks" json:"pre_workflow_hooks"`
+	Workflow                  *string              `yaml:"workflow,omitempty" json:"workflow,omitempty"`
+	PostWorkflowHooks         []WorkflowHook       `yaml:"post_workflow_hooks" json:"post_workflow_hooks"`
+	AllowedWorkflows          []string             `yaml:"allowed_workflows,omitempty" json:"allowed_workflows,omitempty"`
+	AllowedOverrides          []string             `yaml:"allowed_overrides" json:"allowed_overrides"`
+	AllowCustomWorkflows      *bool                `yaml:"allow_custom_workflows,omitempty" json:"allow_custom_workflows,omitempty"`
+	DeleteSourceBranchOnMerge *bool                `yaml:"delete_source_branch_on_merge,omitempty" json:"delete_source_branch_on_merge,omitempty"`
+	RepoLocking               *bool                `yaml:"repo_locking,omitempty" json:"repo_locking,omitempty"`
+	RepoLocks                 *RepoLocks           `yaml:"repo_locks,omitempty" json:"repo_locks,omitempty"`
+	PolicyCheck               *bool                `yaml:"policy_check,omitempty" json:"policy_check,omitempty"`
+	CustomPolicyCheck         *bool                `yaml:"custom_policy_check,omitempty" json:"custom_policy_check,omitempty"`
+	AutoDiscover              *AutoDiscover        `yaml:"autodiscover,omitempty" json:"autodiscover,omitempty"`
+	SilencePRComments         []string             `yaml:"silence_pr_comments,omitempty" json:"silence_pr_comments,omitempty"`
+	CheckoutStrategy          string               `yaml:"checkout_strategy,omitempty" json:"checkout_strategy,omitempty"`
+	DescriptionSections       []DescriptionSection `yaml:"description_sections,omitempty" json:"description_sections,omitempty"`
+	BehaviorRules             []BehaviorRule       `yaml:"behavior_rules,omitempty" json:"behavior_rules,omitempty"`
+	NoChangesPlanComments     string               `yaml:"no_changes_plan_comments,omitempty" json:"no_changes_plan_comments,omitempty"`
+	AllowedTFCWorkspaces      []string             `yaml:"allowed_tfc_workspaces,omitempty" json:"allowed_tfc_workspaces,omitempty"`
+	ConcurrencyGroup          string               `yaml:"concurrency_group,omitempty" json:"concurrency_group,omitempty"`
+	ApplyWindowOverrideUsers  []string             `yaml:"apply_window_override_users,omitempty" json:"apply_window_override_users,omitempty"`
+	CostApprovers             []string             `yaml:"cost_approvers,omitempty" json:"cost_approvers,omitempty"`
 }
 
 func (g GlobalCfg) Validate() error {
+	orgIDValid := func(value any) error {
+		for _, org := range value.([]Repo) {
+			if org.HasRegexID() || !strings.Contains(org.ID, "/") || strings.HasSuffix(org.ID, "/") {
+				return fmt.Errorf("%q isn't an org id, org ids are {hostname}/{owner}, ex. github.com/runatlantis", org.ID)
+			}
+		}
+		return nil
+	}
 	err := validation.ValidateStruct(&g,
+		validation.Field(&g.Orgs, validation.By(orgIDValid)),
 		validation.Field(&g.Repos),
 		validation.Field(&g.Workflows),
 		validation.Field(&g.Metrics),
+		validation.Field(&g.Freezes),
 	)
 	if err != nil {
 		return err
 	}
 
+	freezeNames := make(map[string]bool)
+	for _, f := range g.Freezes {
+		if freezeNames[f.Name] {
+			return fmt.Errorf("freeze %q is defined more than once", f.Name)
+		}
+		freezeNames[f.Name] = true
+	}
+
+	// Orgs have the same settings as repos so they're validated together.
+	repos := append(slices.Clone(g.Orgs), g.Repos...)
+
 	// Check that all workflows referenced by repos are actually defined.
-	for _, repo := range g.Repos {
+	for _, repo := range repos {
 		if repo.Workflow == nil {
 			continue
 		}
@@ -79,7 +113,7 @@ func (g GlobalCfg) Validate() error {
 	}
 
 	// Check that all allowed workflows are defined
-	for _, repo := range g.Repos {
+	for _, repo := range repos {
 		if repo.AllowedWorkflows == nil {
 			continue
 		}
@@ -102,7 +136,7 @@ func (g GlobalCfg) Validate() error {
 	}
 
 	// Validate supported SilencePRComments values.
-	for _, repo := range g.Repos {
+	for _, repo := range repos {
 		if repo.SilencePRComments == nil {
 			continue
 		}
@@ -154,11 +188,23 @@ func (g GlobalCfg) ToValid(defaultCfg valid.GlobalCfg) valid.GlobalCfg {
 		}
 	}
 
-	var repos []valid.Repo
+	// Settings are merged in order so org defaults are placed between the
+	// default server config and the repos that override them.
+	repos := defaultCfg.Repos
+	for _, o := range g.Orgs {
+		org := o.ToValid(workflows, globalPlanReqs, globalApplyReqs, globalImportReqs)
+		org.ID = ""
+		org.IDRegex = regexp.MustCompile("^" + regexp.QuoteMeta(o.ID) + "/")
+		org.Org = o.ID
+		repos = append(repos, org)
+	}
 	for _, r := range g.Repos {
 		repos = append(repos, r.ToValid(workflows, globalPlanReqs, globalApplyReqs, globalImportReqs))
 	}
-	repos = append(defaultCfg.Repos, repos...)
+	var freezes []valid.Freeze
+	for _, f := range g.Freezes {
+		freezes = append(freezes, f.ToValid())
+	}
 
 	return valid.GlobalCfg{
 		Repos:      repos,
@@ -166,6 +212,7 @@ func (g GlobalCfg) ToValid(defaultCfg valid.GlobalCfg) valid.GlobalCfg {
 		PolicySets: g.PolicySets.ToValid(),
 		Metrics:    g.Metrics.ToValid(),
 		TeamAuthz:  g.TeamAuthz.ToValid(),
+		Freezes:    freezes,
 	}
 }
 
@@ -226,8 +273,8 @@ func (r Repo) Validate() error {
 	overridesValid := func(value any) error {
 		overrides := value.([]string)
 		for _, o := range overrides {
-			if o != valid.PlanRequirementsKey && o != valid.ApplyRequirementsKey && o != valid.ImportRequirementsKey && o != valid.WorkflowKey && o != valid.DeleteSourceBranchOnMergeKey && o != valid.RepoLockingKey && o != valid.RepoLocksKey && o != valid.PolicyCheckKey && o != valid.CustomPolicyCheckKey && o != valid.SilencePRCommentsKey {
-				return fmt.Errorf("%q is not a valid override, only %q, %q, %q, %q, %q, %q, %q, %q, %q, and %q are supported", o, valid.PlanRequirementsKey, valid.ApplyRequirementsKey, valid.ImportRequirementsKey, valid.WorkflowKey, valid.DeleteSourceBranchOnMergeKey, valid.RepoLockingKey, valid.RepoLocksKey, valid.PolicyCheckKey, valid.CustomPolicyCheckKey, valid.SilencePRCommentsKey)
+			if o != valid.PlanRequirementsKey && o != valid.ApplyRequirementsKey && o != valid.ImportRequirementsKey && o != valid.WorkflowKey && o != valid.DeleteSourceBranchOnMergeKey && o != valid.RepoLockingKey && o != valid.RepoLocksKey && o != valid.PolicyCheckKey && o != valid.CustomPolicyCheckKey && o != valid.SilencePRCommentsKey && o != valid.PreviewKey {
+				return fmt.Errorf("%q is not a valid override, only %q, %q, %q, %q, %q, %q, %q, %q, %q, %q, and %q are supported", o, valid.PlanRequirementsKey, valid.ApplyRequirementsKey, valid.ImportRequirementsKey, valid.WorkflowKey, valid.DeleteSourceBranchOnMergeKey, valid.RepoLockingKey, valid.RepoLocksKey, valid.PolicyCheckKey, valid.CustomPolicyCheckKey, valid.SilencePRCommentsKey, valid.PreviewKey)
 			}
 		}
 		return nil
@@ -260,6 +307,16 @@ func (r Repo) Validate() error {
 		return nil
 	}
 
+	tfcWorkspacesValid := func(value any) error {
+		for _, pattern := range value.([]string) {
+			org, workspace, ok := strings.Cut(pattern, "/")
+			if _, err := path.Match(pattern, ""); err != nil || !ok || org == "" || workspace == "" {
+				return fmt.Errorf("%q must be an organization and a workspace pattern, ex. my-org/prod-*", pattern)
+			}
+		}
+		return nil
+	}
+
 	return validation.ValidateStruct(&r,
 		validation.Field(&r.ID, validation.Required, validation.By(idValid)),
 		validation.Field(&r.Branch, validation.By(branchValid)),
@@ -272,6 +329,11 @@ func (r Repo) Validate() error {
 		validation.Field(&r.DeleteSourceBranchOnMerge, validation.By(deleteSourceBranchOnMergeValid)),
 		validation.Field(&r.AutoDiscover, validation.By(autoDiscoverValid)),
 		validation.Field(&r.RepoLocks, validation.By(repoLocksValid)),
+		validation.Field(&r.CheckoutStrategy, validation.In(valid.CheckoutStrategyMerge, valid.CheckoutStrategyBranch)),
+		validation.Field(&r.DescriptionSections),
+		validation.Field(&r.BehaviorRules),
+		validation.Field(&r.NoChangesPlanComments, validation.In(valid.NoChangesPlanCommentsShow, valid.NoChangesPlanCommentsRollup, valid.NoChangesPlanCommentsSkip)),
+		validation.Field(&r.AllowedTFCWorkspaces, validation.By(tfcWorkspacesValid)),
 	)
 }
 
@@ -376,6 +438,16 @@ OuterGlobalImportReqs:
 		repoLocks = r.RepoLocks.ToValid()
 	}
 
+	var descriptionSections []valid.DescriptionSection
+	for _, section := range r.DescriptionSections {
+		descriptionSections = append(descriptionSections, section.ToValid())
+	}
+
+	var behaviorRules []valid.BehaviorRule
+	for _, rule := range r.BehaviorRules {
+		behaviorRules = append(behaviorRules, rule.ToValid())
+	}
+
 	return valid.Repo{
 		ID:                        id,
 		IDRegex:                   idRegex,
@@ -397,5 +469,13 @@ OuterGlobalImportReqs:
 		CustomPolicyCheck:         r.CustomPolicyCheck,
 		AutoDiscover:              autoDiscover,
 		SilencePRComments:         r.SilencePRComments,
+		CheckoutStrategy:          r.CheckoutStrategy,
+		DescriptionSections:       descriptionSections,
+		BehaviorRules:             behaviorRules,
+		NoChangesPlanComments:     r.NoChangesPlanComments,
+		AllowedTFCWorkspaces:      r.AllowedTFCWorkspaces,
+		ConcurrencyGroup:          r.ConcurrencyGroup,
+		ApplyWindowOverrideUsers:  r.ApplyWindowOverrideUsers,
+		CostApprovers:             r.CostApprovers,
 	}
 }
@@ -0,0 +1,80 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package raw
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+)
+
+// Preview is the raw schema for a project's preview key, ex.
+//
+//	preview:
+//	  auto: true
+//	  ttl: 72h
+//	  max_environments: 5
+type Preview struct {
+	Auto            *bool   `yaml:"auto,omitempty"`
+	TTL             *string `yaml:"ttl,omitempty"`
+	MaxEnvironments *int    `yaml:"max_environments,omitempty"`
+	WorkspacePrefix *string `yaml:"workspace_prefix,omitempty"`
+}
+
+func (p Preview) Validate() error {
+	ttlValid := func(value any) error {
+		ttl := value.(*string)
+		if ttl == nil {
+			return nil
+		}
+		d, err := time.ParseDuration(*ttl)
+		if err != nil {
+			return fmt.Errorf("%q is not a valid duration, ex. 72h", *ttl)
+		}
+		if d <= 0 {
+			return fmt.Errorf("%q must be positive", *ttl)
+		}
+		return nil
+	}
+	prefixValid := func(value any) error {
+		prefix := value.(*string)
+		if prefix == nil {
+			return nil
+		}
+		// The same restrictions as workspaces in comments since the
+		// workspace names files.
+		if *prefix == "" || *prefix != url.PathEscape(*prefix) || strings.Contains(*prefix, "..") {
+			return errors.New("must be a valid workspace name prefix, ex. pr-")
+		}
+		return nil
+	}
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.TTL, validation.By(ttlValid)),
+		validation.Field(&p.MaxEnvironments, validation.Min(0)),
+		validation.Field(&p.WorkspacePrefix, validation.By(prefixValid)),
+	)
+}
+
+func (p Preview) ToValid() *valid.Preview {
+	v := valid.Preview{WorkspacePrefix: valid.DefaultPreviewWorkspacePrefix}
+	if p.Auto != nil {
+		v.Auto = *p.Auto
+	}
+	if p.TTL != nil {
+		// Safe to ignore the error because we test it in Validate().
+		v.TTL, _ = time.ParseDuration(*p.TTL)
+	}
+	if p.MaxEnvironments != nil {
+		v.MaxEnvironments = *p.MaxEnvironments
+	}
+	if p.WorkspacePrefix != nil {
+		v.WorkspacePrefix = *p.WorkspacePrefix
+	}
+	return &v
+}
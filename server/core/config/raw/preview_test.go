@@ -0,0 +1,80 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package raw_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestPreview_Validate(t *testing.T) {
+	cases := []struct {
+		description string
+		input       raw.Preview
+		errContains string
+	}{
+		{
+			description: "nothing set",
+			input:       raw.Preview{},
+		},
+		{
+			description: "all fields set",
+			input: raw.Preview{
+				Auto:            Bool(true),
+				TTL:             String("72h"),
+				MaxEnvironments: Int(5),
+				WorkspacePrefix: String("preview-"),
+			},
+		},
+		{
+			description: "invalid ttl",
+			input:       raw.Preview{TTL: String("3 days")},
+			errContains: `TTL: "3 days" is not a valid duration`,
+		},
+		{
+			description: "negative ttl",
+			input:       raw.Preview{TTL: String("-1h")},
+			errContains: `TTL: "-1h" must be positive`,
+		},
+		{
+			description: "negative max environments",
+			input:       raw.Preview{MaxEnvironments: Int(-1)},
+			errContains: "MaxEnvironments: must be no less than 0",
+		},
+		{
+			description: "invalid workspace prefix",
+			input:       raw.Preview{WorkspacePrefix: String("../pr")},
+			errContains: "WorkspacePrefix: must be a valid workspace name prefix",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			err := c.input.Validate()
+			if c.errContains == "" {
+				Ok(t, err)
+			} else {
+				ErrContains(t, c.errContains, err)
+			}
+		})
+	}
+}
+
+func TestPreview_ToValid(t *testing.T) {
+	Equals(t, &valid.Preview{WorkspacePrefix: "pr-"}, raw.Preview{}.ToValid())
+	Equals(t, &valid.Preview{
+		Auto:            true,
+		TTL:             72 * time.Hour,
+		MaxEnvironments: 5,
+		WorkspacePrefix: "preview-",
+	}, raw.Preview{
+		Auto:            Bool(true),
+		TTL:             String("72h"),
+		MaxEnvironments: Int(5),
+		WorkspacePrefix: String("preview-"),
+	}.ToValid())
+}
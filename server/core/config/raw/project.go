@@ -18,32 +18,48 @@ import (
 )
 
 const (
-	DefaultWorkspace      = "default"
-	ApprovedRequirement   = "approved"
-	MergeableRequirement  = "mergeable"
-	UnDivergedRequirement = "undiverged"
+	DefaultWorkspace              = "default"
+	ApprovedRequirement           = "approved"
+	MergeableRequirement          = "mergeable"
+	UnDivergedRequirement         = "undiverged"
+	FreshRequirement              = "fresh"
+	ChangeRequestRequirement      = "change_request"
+	DescriptionRequirement        = "description"
+	VerifiedCommitsRequirement    = "verified_commits"
+	CodeOwnersApprovedRequirement = "codeowners_approved"
 )
 
+// tfcWorkspaceRegex matches the organization and name of a Terraform Cloud
+// workspace, ex. my-org/my-workspace.
+var tfcWorkspaceRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+/[A-Za-z0-9_-]+$`)
+
 type Project struct {
-	Name                      *string    `yaml:"name,omitempty"`
-	Branch                    *string    `yaml:"branch,omitempty"`
-	Dir                       *string    `yaml:"dir,omitempty"`
-	Workspace                 *string    `yaml:"workspace,omitempty"`
-	Workflow                  *string    `yaml:"workflow,omitempty"`
-	TerraformDistribution     *string    `yaml:"terraform_distribution,omitempty"`
-	TerraformVersion          *string    `yaml:"terraform_version,omitempty"`
-	Autoplan                  *Autoplan  `yaml:"autoplan,omitempty"`
-	PlanRequirements          []string   `yaml:"plan_requirements,omitempty"`
-	ApplyRequirements         []string   `yaml:"apply_requirements,omitempty"`
-	ImportRequirements        []string   `yaml:"import_requirements,omitempty"`
-	DependsOn                 []string   `yaml:"depends_on,omitempty"`
-	DeleteSourceBranchOnMerge *bool      `yaml:"delete_source_branch_on_merge,omitempty"`
-	RepoLocking               *bool      `yaml:"repo_locking,omitempty"`
-	RepoLocks                 *RepoLocks `yaml:"repo_locks,omitempty"`
-	ExecutionOrderGroup       *int       `yaml:"execution_order_group,omitempty"`
-	PolicyCheck               *bool      `yaml:"policy_check,omitempty"`
-	CustomPolicyCheck         *bool      `yaml:"custom_policy_check,omitempty"`
-	SilencePRComments         []string   `yaml:"silence_pr_comments,omitempty"`
+	Name                      *string      `yaml:"name,omitempty"`
+	Branch                    *string      `yaml:"branch,omitempty"`
+	Dir                       *string      `yaml:"dir,omitempty"`
+	Workspace                 *string      `yaml:"workspace,omitempty"`
+	Workflow                  *string      `yaml:"workflow,omitempty"`
+	TerraformDistribution     *string      `yaml:"terraform_distribution,omitempty"`
+	TerraformVersion          *string      `yaml:"terraform_version,omitempty"`
+	Autoplan                  *Autoplan    `yaml:"autoplan,omitempty"`
+	PlanRequirements          []string     `yaml:"plan_requirements,omitempty"`
+	ApplyRequirements         []string     `yaml:"apply_requirements,omitempty"`
+	ImportRequirements        []string     `yaml:"import_requirements,omitempty"`
+	DependsOn                 []string     `yaml:"depends_on,omitempty"`
+	DeleteSourceBranchOnMerge *bool        `yaml:"delete_source_branch_on_merge,omitempty"`
+	RepoLocking               *bool        `yaml:"repo_locking,omitempty"`
+	RepoLocks                 *RepoLocks   `yaml:"repo_locks,omitempty"`
+	ExecutionOrderGroup       *int         `yaml:"execution_order_group,omitempty"`
+	PolicyCheck               *bool        `yaml:"policy_check,omitempty"`
+	CustomPolicyCheck         *bool        `yaml:"custom_policy_check,omitempty"`
+	SilencePRComments         []string     `yaml:"silence_pr_comments,omitempty"`
+	ApplyWindow               *ApplyWindow `yaml:"apply_window,omitempty"`
+	CostThreshold             *float64     `yaml:"cost_threshold,omitempty"`
+	Environment               *string      `yaml:"environment,omitempty"`
+	AgentPool                 *string      `yaml:"agent_pool,omitempty"`
+	Owners                    []string     `yaml:"owners,omitempty"`
+	TFCWorkspace              *string      `yaml:"tfc_workspace,omitempty"`
+	Preview                   *Preview     `yaml:"preview,omitempty"`
 }
 
 func (p Project) Validate() error {
@@ -96,6 +112,26 @@ func (p Project) Validate() error {
 		return nil
 	}
 
+	validOwners := func(value any) error {
+		for _, owner := range value.([]string) {
+			if !strings.HasPrefix(owner, "@") || len(owner) == 1 {
+				return fmt.Errorf("%q must be a user or team to mention, ex. @org/team", owner)
+			}
+		}
+		return nil
+	}
+
+	validTFCWorkspace := func(value any) error {
+		strPtr := value.(*string)
+		if strPtr == nil {
+			return nil
+		}
+		if !tfcWorkspaceRegex.MatchString(*strPtr) {
+			return fmt.Errorf("%q must be an organization and a workspace, ex. my-org/my-workspace", *strPtr)
+		}
+		return nil
+	}
+
 	// Validate that name doesn't contain glob patterns - glob expansion only works for 'dir'
 	if p.Name != nil && ContainsGlobPattern(*p.Name) {
 		return errors.New("name: cannot contain glob pattern characters ('*', '?', '['); glob expansion is only supported in the 'dir' field")
@@ -117,6 +153,11 @@ func (p Project) Validate() error {
 		validation.Field(&p.DependsOn, validation.By(DependsOn)),
 		validation.Field(&p.Name, validation.By(validName)),
 		validation.Field(&p.Branch, validation.By(branchValid)),
+		validation.Field(&p.ApplyWindow),
+		validation.Field(&p.CostThreshold, validation.Min(0.0)),
+		validation.Field(&p.Owners, validation.By(validOwners)),
+		validation.Field(&p.TFCWorkspace, validation.By(validTFCWorkspace)),
+		validation.Field(&p.Preview),
 	)
 }
 
@@ -191,6 +232,20 @@ func (p Project) ToValid() valid.Project {
 		v.SilencePRComments = p.SilencePRComments
 	}
 
+	if p.ApplyWindow != nil {
+		v.ApplyWindow = p.ApplyWindow.ToValid()
+	}
+
+	v.CostThreshold = p.CostThreshold
+
+	v.Environment = p.Environment
+	v.AgentPool = p.AgentPool
+	v.Owners = p.Owners
+	v.TFCWorkspace = p.TFCWorkspace
+	if p.Preview != nil {
+		v.Preview = p.Preview.ToValid()
+	}
+
 	return v
 }
 
@@ -216,8 +271,8 @@ func validPlanReq(value any) error {
 func validApplyReq(value any) error {
 	reqs := value.([]string)
 	for _, r := range reqs {
-		if r != ApprovedRequirement && r != MergeableRequirement && r != UnDivergedRequirement {
-			return fmt.Errorf("%q is not a valid apply_requirement, only %q, %q and %q are supported", r, ApprovedRequirement, MergeableRequirement, UnDivergedRequirement)
+		if r != ApprovedRequirement && r != MergeableRequirement && r != UnDivergedRequirement && r != FreshRequirement && r != ChangeRequestRequirement && r != DescriptionRequirement && r != VerifiedCommitsRequirement && r != CodeOwnersApprovedRequirement {
+			return fmt.Errorf("%q is not a valid apply_requirement, only %q, %q, %q, %q, %q, %q, %q and %q are supported", r, ApprovedRequirement, MergeableRequirement, UnDivergedRequirement, FreshRequirement, ChangeRequestRequirement, DescriptionRequirement, VerifiedCommitsRequirement, CodeOwnersApprovedRequirement)
 		}
 	}
 	return nil
@@ -53,7 +53,12 @@ apply_requirements:
 - mergeable
 import_requirements:
 - mergeable
-execution_order_group: 10`,
+execution_order_group: 10
+environment: staging
+agent_pool: aws-prod
+owners:
+- "@org/sre"
+tfc_workspace: my-org/prod`,
 			exp: raw.Project{
 				Name:             String("myname"),
 				Branch:           String("mybranch"),
@@ -69,6 +74,10 @@ execution_order_group: 10`,
 				ApplyRequirements:   []string{"mergeable"},
 				ImportRequirements:  []string{"mergeable"},
 				ExecutionOrderGroup: Int(10),
+				Environment:         String("staging"),
+				AgentPool:           String("aws-prod"),
+				Owners:              []string{"@org/sre"},
+				TFCWorkspace:        String("my-org/prod"),
 			},
 		},
 	}
@@ -110,6 +119,30 @@ func TestProject_Validate(t *testing.T) {
 			},
 			expErr: "dir: cannot contain '..'.",
 		},
+		{
+			description: "negative cost threshold",
+			input: raw.Project{
+				Dir:           String("."),
+				CostThreshold: Float64(-1),
+			},
+			expErr: "cost_threshold: must be no less than 0.",
+		},
+		{
+			description: "owner without @",
+			input: raw.Project{
+				Dir:    String("."),
+				Owners: []string{"@org/sre", "sre@example.com"},
+			},
+			expErr: `owners: "sre@example.com" must be a user or team to mention, ex. @org/team.`,
+		},
+		{
+			description: "tfc workspace without organization",
+			input: raw.Project{
+				Dir:          String("."),
+				TFCWorkspace: String("prod"),
+			},
+			expErr: `tfc_workspace: "prod" must be an organization and a workspace, ex. my-org/my-workspace.`,
+		},
 		{
 			description: "not a regexp for branch",
 			input: raw.Project{
@@ -172,7 +205,7 @@ func TestProject_Validate(t *testing.T) {
 				Dir:               String("."),
 				ApplyRequirements: []string{"unsupported"},
 			},
-			expErr: "apply_requirements: \"unsupported\" is not a valid apply_requirement, only \"approved\", \"mergeable\" and \"undiverged\" are supported.",
+			expErr: "apply_requirements: \"unsupported\" is not a valid apply_requirement, only \"approved\", \"mergeable\", \"undiverged\", \"fresh\", \"change_request\", \"description\", \"verified_commits\" and \"codeowners_approved\" are supported.",
 		},
 		{
 			description: "apply reqs with approved requirement",
@@ -476,6 +509,7 @@ func TestProject_ToValid(t *testing.T) {
 				ApplyRequirements:   []string{"approved"},
 				Name:                String("myname"),
 				ExecutionOrderGroup: Int(10),
+				Environment:         String("staging"),
 			},
 			exp: valid.Project{
 				Dir:              ".",
@@ -492,6 +526,7 @@ func TestProject_ToValid(t *testing.T) {
 				ApplyRequirements:   []string{"approved"},
 				Name:                String("myname"),
 				ExecutionOrderGroup: 10,
+				Environment:         String("staging"),
 			},
 		},
 		{
@@ -0,0 +1,140 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package raw
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+)
+
+// schemaRequired are the keys required by the structs of the repo config.
+var schemaRequired = map[reflect.Type][]string{
+	reflect.TypeFor[RepoCfg]():   {"version"},
+	reflect.TypeFor[Project]():   {"dir"},
+	reflect.TypeFor[PolicySet](): {"name", "path"},
+}
+
+// schemaFields are the schemas of the keys their type doesn't describe, by
+// struct and key.
+var schemaFields = map[reflect.Type]map[string]map[string]any{
+	reflect.TypeFor[RepoCfg](): {
+		"version": {"type": "integer", "enum": []any{2, 3}},
+	},
+}
+
+// RepoCfgSchema returns the JSON Schema of the repo-level atlantis.yaml,
+// generated from RepoCfg, for editors to validate and complete it.
+func RepoCfgSchema() map[string]any {
+	schema := typeSchema(reflect.TypeFor[RepoCfg]())
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "Atlantis repo-level atlantis.yaml"
+	return schema
+}
+
+// YAMLKeys adds the keys of the struct t, and of the structs of its fields, to
+// keys by type name, ex. raw.Project.
+func YAMLKeys(t reflect.Type, keys map[string][]string) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || keys[t.String()] != nil {
+		return
+	}
+	keys[t.String()] = []string{}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if key := yamlKey(field); key != "" {
+			keys[t.String()] = append(keys[t.String()], key)
+			YAMLKeys(field.Type, keys)
+		}
+	}
+}
+
+func typeSchema(t reflect.Type) map[string]any {
+	switch t {
+	case reflect.TypeFor[Step]():
+		return stepSchema()
+	case reflect.TypeFor[WorkflowHook]():
+		return map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}}
+	case reflect.TypeFor[valid.AutoDiscoverMode]():
+		return map[string]any{"type": "string", "enum": []any{valid.AutoDiscoverAutoMode, valid.AutoDiscoverEnabledMode, valid.AutoDiscoverDisabledMode}}
+	case reflect.TypeFor[valid.RepoLocksMode]():
+		return map[string]any{"type": "string", "enum": []any{valid.RepoLocksOnPlanMode, valid.RepoLocksOnApplyMode, valid.RepoLocksDisabledMode}}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		properties := map[string]any{}
+		for i := range t.NumField() {
+			field := t.Field(i)
+			key := yamlKey(field)
+			if key == "" {
+				continue
+			}
+			if schema, ok := schemaFields[t][key]; ok {
+				properties[key] = schema
+			} else {
+				properties[key] = typeSchema(field.Type)
+			}
+		}
+		schema := map[string]any{"type": "object", "properties": properties, "additionalProperties": false}
+		if required, ok := schemaRequired[t]; ok {
+			schema["required"] = required
+		}
+		return schema
+	}
+	return map[string]any{}
+}
+
+// stepSchema is the schema of a step, the name of a built-in step or a map of
+// a step name to its config.
+func stepSchema() map[string]any {
+	builtIn := []any{InitStepName, PlanStepName, ShowStepName, CostStepName, AuditStepName, ValidateStepName, PolicyCheckStepName, ApplyStepName, ImportStepName, StateRmStepName}
+	properties := map[string]any{}
+	for _, name := range builtIn {
+		properties[name.(string)] = map[string]any{
+			"type":       "object",
+			"properties": map[string]any{ExtraArgsKey: map[string]any{"type": "array", "items": map[string]any{"type": "string"}}},
+		}
+	}
+	properties[ValidateStepName].(map[string]any)["properties"].(map[string]any)[FailFastArgKey] = map[string]any{"type": "boolean"}
+	properties[RunStepName] = map[string]any{"type": []any{"string", "object"}}
+	properties[EnvStepName] = map[string]any{"type": "object", "required": []any{NameArgKey}}
+	properties[MultiEnvStepName] = map[string]any{"type": "object", "required": []any{CommandArgKey}}
+	return map[string]any{
+		"oneOf": []any{
+			map[string]any{"type": "string", "enum": builtIn},
+			map[string]any{
+				"type":                 "object",
+				"properties":           properties,
+				"additionalProperties": false,
+				"minProperties":        1,
+				"maxProperties":        1,
+			},
+		},
+	}
+}
+
+func yamlKey(field reflect.StructField) string {
+	key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if key == "-" {
+		return ""
+	}
+	return key
+}
@@ -0,0 +1,50 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package raw_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestRepoCfgSchema(t *testing.T) {
+	data, err := json.Marshal(raw.RepoCfgSchema())
+	Ok(t, err)
+	var schema struct {
+		Required   []string
+		Properties struct {
+			Version  map[string]any
+			Projects struct {
+				Items struct {
+					Required             []string
+					AdditionalProperties bool
+					Properties           map[string]struct {
+						Type       string
+						Properties map[string]json.RawMessage
+					}
+				}
+			}
+			RepoLocks struct {
+				Properties struct {
+					Mode struct{ Enum []string }
+				}
+			} `json:"repo_locks"`
+		}
+	}
+	Ok(t, json.Unmarshal(data, &schema))
+
+	Equals(t, []string{"version"}, schema.Required)
+	Equals(t, []any{2.0, 3.0}, schema.Properties.Version["enum"])
+	project := schema.Properties.Projects.Items
+	Equals(t, []string{"dir"}, project.Required)
+	Equals(t, false, project.AdditionalProperties)
+	Equals(t, "string", project.Properties["workspace"].Type)
+	Equals(t, "boolean", project.Properties["policy_check"].Type)
+	_, ok := project.Properties["autoplan"].Properties["when_modified"]
+	Assert(t, ok, "exp the keys of nested structs")
+	Equals(t, []string{"on_plan", "on_apply", "disabled"}, schema.Properties.RepoLocks.Properties.Mode.Enum)
+}
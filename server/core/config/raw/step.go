@@ -24,9 +24,13 @@ const (
 	CommandArgKey       = "command"
 	ValueArgKey         = "value"
 	OutputArgKey        = "output"
+	FailFastArgKey      = "fail_fast"
 	RunStepName         = "run"
 	PlanStepName        = "plan"
 	ShowStepName        = "show"
+	CostStepName        = "cost"
+	AuditStepName       = "audit"
+	ValidateStepName    = "validate"
 	PolicyCheckStepName = "policy_check"
 	ApplyStepName       = "apply"
 	InitStepName        = "init"
@@ -70,9 +74,15 @@ Step represents a single action/command to perform. In YAML, it can be set as
     output: ["strip_refreshing", {"filter_regex": "((?i)secret:\\s\")[^\"]*"}]
 
 3. A map for a built-in command and extra_args:
+
   - plan:
     extra_args: [-var-file=staging.tfvars]
 
+    or a validate step failing on its findings:
+
+  - validate:
+    fail_fast: true
+
 4. A map for a custom run command:
   - run: my custom command
 
@@ -120,6 +130,9 @@ func (s Step) validStepName(stepName string) bool {
 		stepName == EnvStepName ||
 		stepName == MultiEnvStepName ||
 		stepName == ShowStepName ||
+		stepName == CostStepName ||
+		stepName == AuditStepName ||
+		stepName == ValidateStepName ||
 		stepName == PolicyCheckStepName ||
 		stepName == ImportStepName ||
 		stepName == StateRmStepName
@@ -202,6 +215,29 @@ func (s Step) Validate() error {
 		// Sort so tests can be deterministic.
 		sort.Strings(argKeys)
 
+		if stepName == ValidateStepName {
+			for _, k := range argKeys {
+				if k != ExtraArgsKey && k != FailFastArgKey {
+					return fmt.Errorf("validate steps only support keys %q and %q, found key %q", ExtraArgsKey, FailFastArgKey, k)
+				}
+			}
+			if _, ok := args[FailFastArgKey].(bool); !ok {
+				return fmt.Errorf("validate step %q option must be a boolean", FailFastArgKey)
+			}
+			if extraArgs, ok := args[ExtraArgsKey]; ok {
+				list, ok := extraArgs.([]any)
+				if !ok {
+					return fmt.Errorf("validate step %q option must be a list of strings", ExtraArgsKey)
+				}
+				for _, arg := range list {
+					if _, ok := arg.(string); !ok {
+						return fmt.Errorf("validate step %q option must contain only strings, found %v", ExtraArgsKey, arg)
+					}
+				}
+			}
+			return nil
+		}
+
 		// Validate keys common for all the steps.
 		if utils.SlicesContains(argKeys, ShellArgKey) && !utils.SlicesContains(argKeys, CommandArgKey) {
 			return fmt.Errorf("workflow steps only support %q key in combination with %q key",
@@ -412,6 +448,14 @@ func (s Step) ToValid() valid.Step {
 		// step name so we just use the first one.
 		for stepName, stepArgs := range s.CommandMap {
 			step := valid.Step{StepName: stepName}
+			if failFast, ok := stepArgs[FailFastArgKey].(bool); ok {
+				step.FailFast = failFast
+			}
+			if extraArgs, ok := stepArgs[ExtraArgsKey].([]any); ok {
+				for _, arg := range extraArgs {
+					step.ExtraArgs = append(step.ExtraArgs, arg.(string))
+				}
+			}
 			if name, ok := stepArgs[NameArgKey].(string); ok {
 				step.EnvVarName = name
 			}
@@ -483,6 +483,40 @@ func TestStep_Validate(t *testing.T) {
 			},
 			expErr: "\"run\" step \"shellArgs\" option must contain only strings, found 42",
 		},
+		{
+			description: "validate step with fail_fast and extra_args",
+			input: raw.Step{
+				CommandMap: EnvType{
+					"validate": {
+						"fail_fast":  true,
+						"extra_args": []any{"-no-tests"},
+					},
+				},
+			},
+		},
+		{
+			description: "validate step with non-boolean fail_fast",
+			input: raw.Step{
+				CommandMap: EnvType{
+					"validate": {
+						"fail_fast": "yes",
+					},
+				},
+			},
+			expErr: "validate step \"fail_fast\" option must be a boolean",
+		},
+		{
+			description: "validate step with unsupported key",
+			input: raw.Step{
+				CommandMap: EnvType{
+					"validate": {
+						"fail_fast": true,
+						"command":   "echo",
+					},
+				},
+			},
+			expErr: "validate steps only support keys \"extra_args\" and \"fail_fast\", found key \"command\"",
+		},
 		{
 			// For atlantis.yaml v2, this wouldn't parse, but now there should
 			// be no error.
@@ -551,6 +585,22 @@ func TestStep_ToValid(t *testing.T) {
 				StepName: "apply",
 			},
 		},
+		{
+			description: "validate step with fail_fast",
+			input: raw.Step{
+				CommandMap: EnvType{
+					"validate": {
+						"fail_fast":  true,
+						"extra_args": []any{"-no-tests"},
+					},
+				},
+			},
+			exp: valid.Step{
+				StepName:  "validate",
+				ExtraArgs: []string{"-no-tests"},
+				FailFast:  true,
+			},
+		},
 		{
 			description: "env step",
 			input: raw.Step{
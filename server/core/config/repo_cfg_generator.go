@@ -0,0 +1,263 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	yaml "gopkg.in/yaml.v3"
+)
+
+const (
+	terragruntFile = "terragrunt.hcl"
+	// TerragruntWorkflow is the workflow generated for terragrunt stacks.
+	TerragruntWorkflow = "terragrunt"
+	// WorkspacesWorkflow is the workflow generated for root modules with a
+	// var file per workspace, passing the var file of the workspace to plan.
+	WorkspacesWorkflow = "workspaces"
+)
+
+var terraformWorkspaceRegex = regexp.MustCompile(`\bterraform\.workspace\b`)
+
+// RepoCfgGenerator scaffolds the repo-level atlantis.yaml of a repo from its
+// root modules, their workspaces and terraform versions, and its terragrunt
+// stacks.
+type RepoCfgGenerator struct {
+	// DetectVersion returns the terraform version pinned by the root module or
+	// terragrunt stack in dir, nil if it doesn't pin one.
+	DetectVersion func(dir string) *version.Version
+}
+
+// Generate returns the repo config of the repo checked out in repoDir.
+//
+// Root modules are the directories with .tf files that aren't called as a
+// local module by another directory, and aren't under a modules directory.
+// When a root module uses terraform.workspace and has a var file per
+// workspace, ex. staging.tfvars, it gets a project per workspace. Terragrunt
+// stacks are the directories with a terragrunt.hcl file and none under them,
+// so the parent configs they include aren't projects.
+func (g *RepoCfgGenerator) Generate(repoDir string) (raw.RepoCfg, error) {
+	var tfDirs, terragruntDirs []string
+	seen := map[string]bool{}
+	err := filepath.WalkDir(repoDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// Skips .git, .terraform, .terragrunt-cache, etc.
+			if path != repoDir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		dir := filepath.Dir(path)
+		switch {
+		case d.Name() == terragruntFile:
+			terragruntDirs = append(terragruntDirs, dir)
+		case filepath.Ext(d.Name()) == ".tf" && !seen[dir]:
+			seen[dir] = true
+			tfDirs = append(tfDirs, dir)
+		}
+		return nil
+	})
+	if err != nil {
+		return raw.RepoCfg{}, err
+	}
+
+	cfgVersion := 3
+	cfg := raw.RepoCfg{Version: &cfgVersion}
+	terragrunt := map[string]bool{}
+	for _, dir := range terragruntDirs {
+		terragrunt[dir] = true
+		if !hasDirUnder(terragruntDirs, dir) {
+			cfg.Projects = append(cfg.Projects, g.project(repoDir, dir, "", TerragruntWorkflow, []string{"*.hcl", "*.tf*"}))
+		}
+	}
+
+	modules := map[string]*tfconfig.Module{}
+	called := map[string]bool{}
+	for _, dir := range tfDirs {
+		module, _ := tfconfig.LoadModule(dir)
+		modules[dir] = module
+		for _, call := range module.ModuleCalls {
+			if isLocalSource(call.Source) {
+				called[filepath.Join(dir, call.Source)] = true
+			}
+		}
+	}
+	for _, dir := range tfDirs {
+		rel, _ := filepath.Rel(repoDir, dir)
+		if called[dir] || terragrunt[dir] || isUnderModulesDir(rel) {
+			continue
+		}
+		whenModified := []string{"*.tf*", ".terraform.lock.hcl"}
+		for _, source := range localSources(modules[dir]) {
+			whenModified = append(whenModified, filepath.ToSlash(filepath.Join(source, "**", "*.tf")))
+		}
+		workspaces := workspaceVarFiles(dir)
+		if len(workspaces) == 0 {
+			cfg.Projects = append(cfg.Projects, g.project(repoDir, dir, "", "", whenModified))
+			continue
+		}
+		for _, workspace := range workspaces {
+			cfg.Projects = append(cfg.Projects, g.project(repoDir, dir, workspace, WorkspacesWorkflow, whenModified))
+		}
+	}
+	sort.SliceStable(cfg.Projects, func(i, j int) bool {
+		return *cfg.Projects[i].Name < *cfg.Projects[j].Name
+	})
+
+	workflows := map[string]raw.Workflow{}
+	for _, project := range cfg.Projects {
+		if project.Workflow != nil {
+			workflows[*project.Workflow] = generatedWorkflows[*project.Workflow]
+		}
+	}
+	if len(workflows) > 0 {
+		cfg.Workflows = workflows
+	}
+	return cfg, nil
+}
+
+// GenerateData returns the repo config of the repo checked out in repoDir as
+// YAML.
+func (g *RepoCfgGenerator) GenerateData(repoDir string) ([]byte, error) {
+	cfg, err := g.Generate(repoDir)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString("# Generated by atlantis onboard, see https://www.runatlantis.io/docs/repo-level-atlantis-yaml.html\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cfg); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), encoder.Close()
+}
+
+func (g *RepoCfgGenerator) project(repoDir string, dir string, workspace string, workflow string, whenModified []string) raw.Project {
+	rel, _ := filepath.Rel(repoDir, dir)
+	rel = filepath.ToSlash(rel)
+	name := rel
+	if name == "." {
+		name = filepath.Base(repoDir)
+	}
+	name = strings.ReplaceAll(name, "/", "-")
+	autoplanEnabled := true
+	project := raw.Project{
+		Dir:      &rel,
+		Autoplan: &raw.Autoplan{WhenModified: whenModified, Enabled: &autoplanEnabled},
+	}
+	if workspace != "" {
+		name += "-" + workspace
+		project.Workspace = &workspace
+	}
+	project.Name = &name
+	if workflow != "" {
+		project.Workflow = &workflow
+	}
+	if g.DetectVersion != nil {
+		if v := g.DetectVersion(dir); v != nil {
+			tfVersion := v.String()
+			project.TerraformVersion = &tfVersion
+		}
+	}
+	return project
+}
+
+// generatedWorkflows are the workflows of generated projects by name.
+var generatedWorkflows = map[string]raw.Workflow{
+	TerragruntWorkflow: {
+		Plan: &raw.Stage{Steps: []raw.Step{
+			runStep("terragrunt plan -input=false -out=$PLANFILE"),
+			runStep("terragrunt show -json $PLANFILE > $SHOWFILE"),
+		}},
+		Apply: &raw.Stage{Steps: []raw.Step{
+			runStep("terragrunt apply -input=false $PLANFILE"),
+		}},
+	},
+	WorkspacesWorkflow: {
+		Plan: &raw.Stage{Steps: []raw.Step{
+			{Key: strPtr(raw.InitStepName)},
+			runStep("terraform${ATLANTIS_TERRAFORM_VERSION} plan -input=false -refresh -out $PLANFILE -var-file $WORKSPACE.tfvars"),
+		}},
+	},
+}
+
+func runStep(command string) raw.Step {
+	return raw.Step{StringVal: map[string]string{raw.RunStepName: command}}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+// workspaceVarFiles returns the workspaces the root module in dir has a var
+// file for, ex. staging for staging.tfvars, if it uses terraform.workspace.
+func workspaceVarFiles(dir string) []string {
+	varFiles, _ := filepath.Glob(filepath.Join(dir, "*.tfvars"))
+	var workspaces []string
+	for _, varFile := range varFiles {
+		if name := strings.TrimSuffix(filepath.Base(varFile), ".tfvars"); name != "terraform" {
+			workspaces = append(workspaces, name)
+		}
+	}
+	if len(workspaces) == 0 {
+		return nil
+	}
+	tfFiles, _ := filepath.Glob(filepath.Join(dir, "*.tf"))
+	for _, tfFile := range tfFiles {
+		contents, err := os.ReadFile(tfFile) // nolint: gosec
+		if err == nil && terraformWorkspaceRegex.Match(contents) {
+			return workspaces
+		}
+	}
+	return nil
+}
+
+// localSources returns the sources of the local modules module calls,
+// relative to its directory.
+func localSources(module *tfconfig.Module) []string {
+	var sources []string
+	for _, call := range module.ModuleCalls {
+		if isLocalSource(call.Source) && !strings.Contains(call.Source, "$") {
+			sources = append(sources, filepath.Clean(call.Source))
+		}
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+func isLocalSource(source string) bool {
+	return strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../")
+}
+
+func isUnderModulesDir(rel string) bool {
+	for _, segment := range strings.Split(filepath.ToSlash(rel), "/") {
+		if segment == "modules" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDirUnder returns whether one of dirs is under dir.
+func hasDirUnder(dirs []string, dir string) bool {
+	for _, other := range dirs {
+		if strings.HasPrefix(other, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
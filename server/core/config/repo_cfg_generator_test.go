@@ -0,0 +1,105 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/runatlantis/atlantis/server/core/config"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestRepoCfgGenerator_GenerateData(t *testing.T) {
+	repoDir := filepath.Join(t.TempDir(), "infra")
+	for path, contents := range map[string]string{
+		"envs/prod/main.tf":           `module "vpc" { source = "../../modules/vpc" }`,
+		"modules/vpc/main.tf":         `resource "null_resource" "vpc" {}`,
+		"app/main.tf":                 `module "bucket" { source = "./bucket" }` + "\nlocals { env = terraform.workspace }",
+		"app/bucket/main.tf":          `resource "null_resource" "bucket" {}`,
+		"app/staging.tfvars":          "",
+		"app/production.tfvars":       "",
+		"app/terraform.tfvars":        "",
+		"live/terragrunt.hcl":         "",
+		"live/dev/vpc/terragrunt.hcl": `include "root" { path = find_in_parent_folders() }`,
+		".terraform/modules/main.tf":  "",
+	} {
+		Ok(t, os.MkdirAll(filepath.Join(repoDir, filepath.Dir(path)), 0700))
+		Ok(t, os.WriteFile(filepath.Join(repoDir, path), []byte(contents), 0600))
+	}
+
+	generator := config.RepoCfgGenerator{
+		DetectVersion: func(dir string) *version.Version {
+			if filepath.Base(dir) == "prod" {
+				return version.Must(version.NewVersion("1.5.7"))
+			}
+			return nil
+		},
+	}
+	data, err := generator.GenerateData(repoDir)
+	Ok(t, err)
+	Equals(t, `# Generated by atlantis onboard, see https://www.runatlantis.io/docs/repo-level-atlantis-yaml.html
+version: 3
+projects:
+  - name: app-production
+    dir: app
+    workspace: production
+    workflow: workspaces
+    autoplan:
+      when_modified:
+        - '*.tf*'
+        - .terraform.lock.hcl
+        - bucket/**/*.tf
+      enabled: true
+  - name: app-staging
+    dir: app
+    workspace: staging
+    workflow: workspaces
+    autoplan:
+      when_modified:
+        - '*.tf*'
+        - .terraform.lock.hcl
+        - bucket/**/*.tf
+      enabled: true
+  - name: envs-prod
+    dir: envs/prod
+    terraform_version: 1.5.7
+    autoplan:
+      when_modified:
+        - '*.tf*'
+        - .terraform.lock.hcl
+        - ../../modules/vpc/**/*.tf
+      enabled: true
+  - name: live-dev-vpc
+    dir: live/dev/vpc
+    workflow: terragrunt
+    autoplan:
+      when_modified:
+        - '*.hcl'
+        - '*.tf*'
+      enabled: true
+workflows:
+  terragrunt:
+    apply:
+      steps:
+        - run: terragrunt apply -input=false $PLANFILE
+    plan:
+      steps:
+        - run: terragrunt plan -input=false -out=$PLANFILE
+        - run: terragrunt show -json $PLANFILE > $SHOWFILE
+  workspaces:
+    plan:
+      steps:
+        - init
+        - run: terraform${ATLANTIS_TERRAFORM_VERSION} plan -input=false -refresh -out $PLANFILE -var-file $WORKSPACE.tfvars
+`, string(data))
+
+	t.Log("the generated config is valid")
+	globalCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{AllowAllRepoSettings: true})
+	_, err = (&config.ParserValidator{}).ParseRepoCfgData(data, globalCfg, "github.com/owner/infra", "main")
+	Ok(t, err)
+}
@@ -0,0 +1,249 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/core/config/raw"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/utils"
+	yaml "gopkg.in/yaml.v3"
+)
+
+var (
+	yamlSyntaxErrRegex    = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+	yamlDecodeErrRegex    = regexp.MustCompile(`^line (\d+): (.*)$`)
+	yamlUnknownKeyRegex   = regexp.MustCompile(`^field (\S+) not found in type (\S+)$`)
+	yamlWrongTypeErrRegex = regexp.MustCompile("^cannot unmarshal !!(\\w+)(?: `(.*)`)? into (.+)$")
+)
+
+// ConfigError is a problem of a repo config, with where it is.
+type ConfigError struct {
+	// Path is the key with the problem, ex. projects[0].dir, empty if it's
+	// about the whole config.
+	Path string `json:"path,omitempty"`
+	// Line is the line of the key, 0 if it's unknown.
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e ConfigError) Error() string {
+	msg := e.Message
+	if e.Path != "" {
+		msg = fmt.Sprintf("%s: %s", e.Path, msg)
+	}
+	if e.Line > 0 {
+		msg = fmt.Sprintf("line %d: %s", e.Line, msg)
+	}
+	return msg
+}
+
+// ValidateRepoCfgData validates the repo config repoCfgData like
+// ParseRepoCfgData, returning all the problems of its keys found instead of
+// the first one, with their lines. The semantic checks, ex. against
+// globalCfg, are only made once the keys are valid.
+func (p *ParserValidator) ValidateRepoCfgData(repoCfgData []byte, globalCfg valid.GlobalCfg, repoID string) []ConfigError {
+	var root yaml.Node
+	if err := yaml.Unmarshal(repoCfgData, &root); err != nil {
+		if match := yamlSyntaxErrRegex.FindStringSubmatch(err.Error()); match != nil {
+			line, _ := strconv.Atoi(match[1])
+			return []ConfigError{{Line: line, Message: "invalid YAML: " + match[2]}}
+		}
+		return []ConfigError{{Message: err.Error()}}
+	}
+	lines := map[string]int{}
+	nodeLines(&root, "", lines)
+
+	var rawConfig raw.RepoCfg
+	decoder := yaml.NewDecoder(bytes.NewReader(repoCfgData))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&rawConfig); err != nil && !errors.Is(err, io.EOF) {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return []ConfigError{{Message: err.Error()}}
+		}
+		var configErrs []ConfigError
+		for _, msg := range typeErr.Errors {
+			configErrs = append(configErrs, decodeError(msg, lines))
+		}
+		return sortConfigErrors(configErrs)
+	}
+
+	validation.ErrorTag = "yaml"
+	if err := rawConfig.Validate(); err != nil {
+		var configErrs []ConfigError
+		flattenValidationError(err, "", lines, &configErrs)
+		return sortConfigErrors(configErrs)
+	}
+	if _, err := p.parseRawRepoCfg(rawConfig, globalCfg, repoID, ""); err != nil {
+		return []ConfigError{{Message: err.Error()}}
+	}
+	return nil
+}
+
+// decodeError turns an error decoding the YAML, ex. "line 3: field foo not
+// found in type raw.Project", into an actionable one.
+func decodeError(msg string, lines map[string]int) ConfigError {
+	match := yamlDecodeErrRegex.FindStringSubmatch(msg)
+	if match == nil {
+		return ConfigError{Message: msg}
+	}
+	line, _ := strconv.Atoi(match[1])
+	configErr := ConfigError{Line: line, Message: match[2]}
+
+	if match := yamlUnknownKeyRegex.FindStringSubmatch(configErr.Message); match != nil {
+		key, typeName := match[1], match[2]
+		configErr.Path = pathAtLine(lines, line, key)
+		keys := map[string][]string{}
+		raw.YAMLKeys(reflect.TypeFor[raw.RepoCfg](), keys)
+		configErr.Message = fmt.Sprintf("unknown key %q", key)
+		for _, validKey := range keys[typeName] {
+			if utils.IsSimilarWord(key, validKey) {
+				configErr.Message += fmt.Sprintf(", did you mean %q?", validKey)
+				return configErr
+			}
+		}
+		if len(keys[typeName]) > 0 {
+			configErr.Message += fmt.Sprintf(", must be one of %s", strings.Join(keys[typeName], ", "))
+		}
+		return configErr
+	}
+
+	if match := yamlWrongTypeErrRegex.FindStringSubmatch(configErr.Message); match != nil {
+		configErr.Path = pathAtLine(lines, line, "")
+		got := fmt.Sprintf("%q", match[2])
+		if match[2] == "" {
+			got = yamlTypeDescription(match[1])
+		}
+		configErr.Message = fmt.Sprintf("must be %s, got %s", goTypeDescription(match[3]), got)
+	}
+	return configErr
+}
+
+func yamlTypeDescription(tag string) string {
+	switch tag {
+	case "seq":
+		return "a list"
+	case "map":
+		return "a map"
+	}
+	return tag
+}
+
+func goTypeDescription(goType string) string {
+	goType = strings.TrimPrefix(goType, "*")
+	switch {
+	case strings.HasPrefix(goType, "[]"):
+		return "a list"
+	case strings.HasPrefix(goType, "map["), strings.HasPrefix(goType, "raw."):
+		return "a map"
+	case strings.HasPrefix(goType, "int"), strings.HasPrefix(goType, "float"):
+		return "a number"
+	case goType == "bool":
+		return "true or false"
+	case goType == "string":
+		return "a string"
+	}
+	return goType
+}
+
+// nodeLines records the lines of the keys and list items under node by
+// path, ex. projects[0].dir.
+func nodeLines(node *yaml.Node, path string, lines map[string]int) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			nodeLines(child, path, lines)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyPath := childPath(path, node.Content[i].Value)
+			lines[keyPath] = node.Content[i].Line
+			nodeLines(node.Content[i+1], keyPath, lines)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			itemPath := childPath(path, strconv.Itoa(i))
+			lines[itemPath] = child.Line
+			nodeLines(child, itemPath, lines)
+		}
+	}
+}
+
+// pathAtLine returns the deepest key at line, the key named key if set.
+func pathAtLine(lines map[string]int, line int, key string) string {
+	var found string
+	for path, pathLine := range lines {
+		if pathLine != line || strings.HasSuffix(path, "]") {
+			continue
+		}
+		if key != "" && path != key && !strings.HasSuffix(path, "."+key) {
+			continue
+		}
+		if len(path) > len(found) {
+			found = path
+		}
+	}
+	return found
+}
+
+// childPath is the path of the key, or list index, of the key at path.
+func childPath(path string, key string) string {
+	if _, err := strconv.Atoi(key); err == nil {
+		return fmt.Sprintf("%s[%s]", path, key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// flattenValidationError adds the errors of the keys of err, a
+// validation.Errors nested by key, to configErrs.
+func flattenValidationError(err error, path string, lines map[string]int, configErrs *[]ConfigError) {
+	var keyErrs validation.Errors
+	if !errors.As(err, &keyErrs) {
+		*configErrs = append(*configErrs, ConfigError{Path: path, Line: lineOf(lines, path), Message: err.Error()})
+		return
+	}
+	for key, keyErr := range keyErrs {
+		flattenValidationError(keyErr, childPath(path, key), lines, configErrs)
+	}
+}
+
+// lineOf returns the line of the key at path, or of its closest parent if
+// it's missing.
+func lineOf(lines map[string]int, path string) int {
+	for path != "" {
+		if line, ok := lines[path]; ok {
+			return line
+		}
+		if i := strings.LastIndexAny(path, ".["); i >= 0 {
+			path = path[:i]
+		} else {
+			path = ""
+		}
+	}
+	return 0
+}
+
+func sortConfigErrors(configErrs []ConfigError) []ConfigError {
+	sort.SliceStable(configErrs, func(i, j int) bool {
+		if configErrs[i].Line != configErrs[j].Line {
+			return configErrs[i].Line < configErrs[j].Line
+		}
+		return configErrs[i].Path < configErrs[j].Path
+	})
+	return configErrs
+}
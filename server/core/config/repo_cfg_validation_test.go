@@ -0,0 +1,63 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package config_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestValidateRepoCfgData(t *testing.T) {
+	cases := []struct {
+		description string
+		input       string
+		globalCfg   valid.GlobalCfg
+		exp         []config.ConfigError
+	}{
+		{
+			description: "valid",
+			input:       "version: 3\nprojects:\n- dir: infra\n",
+		},
+		{
+			description: "invalid yaml",
+			input:       "version: 3\nprojects:\n- dir: infra\n  workspace: [\n",
+			exp:         []config.ConfigError{{Line: 4, Message: "invalid YAML: did not find expected node content"}},
+		},
+		{
+			description: "unknown keys and wrong types are all reported",
+			input:       "version: 3\nprojects:\n- dir: infra\n  worksapce: staging\n  autoplan:\n    enabled: sometimes\n    foo: bar\n",
+			exp: []config.ConfigError{
+				{Path: "projects[0].worksapce", Line: 4, Message: `unknown key "worksapce", did you mean "workspace"?`},
+				{Path: "projects[0].autoplan.enabled", Line: 6, Message: `must be true or false, got "sometimes"`},
+				{Path: "projects[0].autoplan.foo", Line: 7, Message: `unknown key "foo", must be one of when_modified, enabled`},
+			},
+		},
+		{
+			description: "missing keys are reported at their parent",
+			input:       "projects:\n- dir: infra\n- workspace: staging\n",
+			exp: []config.ConfigError{
+				{Path: "version", Message: "is required. If you've just upgraded Atlantis you need to rewrite your atlantis.yaml for version 3. See www.runatlantis.io/docs/upgrading-atlantis-yaml.html"},
+				{Path: "projects[1].dir", Line: 3, Message: "cannot be blank"},
+			},
+		},
+		{
+			description: "the server-side repo config is checked once the keys are valid",
+			input:       "version: 3\nprojects:\n- dir: infra\n  workflow: custom\n",
+			globalCfg:   valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{}),
+			exp:         []config.ConfigError{{Message: "repo config not allowed to set 'workflow' key: server-side config needs 'allowed_overrides: [workflow]'"}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			cfg := globalCfg
+			if c.globalCfg.Repos != nil {
+				cfg = c.globalCfg
+			}
+			Equals(t, c.exp, (&config.ParserValidator{}).ValidateRepoCfgData([]byte(c.input), cfg, "github.com/owner/repo"))
+		})
+	}
+}
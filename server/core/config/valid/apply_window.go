@@ -0,0 +1,85 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package valid
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ApplyWindow restricts when a project may be applied.
+type ApplyWindow struct {
+	// Days are the weekdays applies are allowed on. Empty means every day.
+	Days []time.Weekday
+	// Start and End are offsets from midnight bounding the allowed hours.
+	// If End is before Start the window wraps around midnight. If both are
+	// zero, applies are allowed the whole day.
+	Start time.Duration
+	End   time.Duration
+	// Location is the time zone Days and hours are evaluated in.
+	Location *time.Location
+	// OverrideUsers may apply outside the window. They're set by the
+	// server-side repo config's apply_window_override_users.
+	OverrideUsers []string
+}
+
+// Contains returns true if t falls within the window.
+func (w ApplyWindow) Contains(t time.Time) bool {
+	loc := w.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	sinceMidnight := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+
+	day := t.Weekday()
+	inHours := true
+	switch {
+	case w.Start == 0 && w.End == 0:
+	case w.Start <= w.End:
+		inHours = sinceMidnight >= w.Start && sinceMidnight < w.End
+	default:
+		// The window wraps around midnight so the early morning hours
+		// belong to the window that opened on the previous day.
+		if sinceMidnight < w.End {
+			day = (day + 6) % 7
+		} else {
+			inHours = sinceMidnight >= w.Start
+		}
+	}
+	return inHours && (len(w.Days) == 0 || slices.Contains(w.Days, day))
+}
+
+// CanOverride returns true if username may apply outside the window.
+func (w ApplyWindow) CanOverride(username string) bool {
+	return slices.Contains(w.OverrideUsers, username)
+}
+
+// String returns a human readable description of the window,
+// ex. "Mon, Tue, Wed 09:00-16:00 (America/New_York)".
+func (w ApplyWindow) String() string {
+	days := "every day"
+	if len(w.Days) > 0 {
+		var names []string
+		for _, d := range w.Days {
+			names = append(names, d.String()[:3])
+		}
+		days = strings.Join(names, ", ")
+	}
+	hours := "all day"
+	if w.Start != 0 || w.End != 0 {
+		hours = fmt.Sprintf("%s-%s", formatClock(w.Start), formatClock(w.End))
+	}
+	loc := time.UTC
+	if w.Location != nil {
+		loc = w.Location
+	}
+	return fmt.Sprintf("%s %s (%s)", days, hours, loc)
+}
+
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
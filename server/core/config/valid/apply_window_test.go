@@ -0,0 +1,84 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package valid_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestApplyWindow_Contains(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	Ok(t, err)
+	// 2025-01-06 is a Monday.
+	monday := func(hour, minute int) time.Time {
+		return time.Date(2025, 1, 6, hour, minute, 0, 0, time.UTC)
+	}
+	cases := []struct {
+		description string
+		window      valid.ApplyWindow
+		t           time.Time
+		exp         bool
+	}{
+		{
+			description: "empty window always contains",
+			window:      valid.ApplyWindow{},
+			t:           monday(3, 0),
+			exp:         true,
+		},
+		{
+			description: "inside hours",
+			window:      valid.ApplyWindow{Start: 9 * time.Hour, End: 16 * time.Hour},
+			t:           monday(9, 0),
+			exp:         true,
+		},
+		{
+			description: "end is exclusive",
+			window:      valid.ApplyWindow{Start: 9 * time.Hour, End: 16 * time.Hour},
+			t:           monday(16, 0),
+			exp:         false,
+		},
+		{
+			description: "wrong day",
+			window:      valid.ApplyWindow{Days: []time.Weekday{time.Tuesday}},
+			t:           monday(12, 0),
+			exp:         false,
+		},
+		{
+			description: "time zone is applied",
+			window:      valid.ApplyWindow{Start: 9 * time.Hour, End: 16 * time.Hour, Location: ny},
+			t:           monday(12, 0), // 07:00 in New York
+			exp:         false,
+		},
+		{
+			description: "overnight window belongs to the day it opened",
+			window:      valid.ApplyWindow{Days: []time.Weekday{time.Sunday}, Start: 22 * time.Hour, End: 6 * time.Hour},
+			t:           monday(2, 0),
+			exp:         true,
+		},
+		{
+			description: "overnight window outside hours",
+			window:      valid.ApplyWindow{Start: 22 * time.Hour, End: 6 * time.Hour},
+			t:           monday(12, 0),
+			exp:         false,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			Equals(t, c.exp, c.window.Contains(c.t))
+		})
+	}
+}
+
+func TestApplyWindow_String(t *testing.T) {
+	w := valid.ApplyWindow{
+		Days:  []time.Weekday{time.Monday, time.Tuesday},
+		Start: 9 * time.Hour,
+		End:   16*time.Hour + 30*time.Minute,
+	}
+	Equals(t, "Mon, Tue 09:00-16:30 (UTC)", w.String())
+}
@@ -0,0 +1,77 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package valid
+
+import (
+	"regexp"
+	"slices"
+)
+
+// BehaviorRule changes how the pull requests whose head branch and head
+// commit message match it are handled, ex. hotfix branches skip the apply
+// window.
+type BehaviorRule struct {
+	// Branch matches the head branch, nil matches any branch.
+	Branch *regexp.Regexp
+	// CommitMessage matches the message of the head commit, nil matches any
+	// message.
+	CommitMessage *regexp.Regexp
+	// SkipApplyWindow allows applying outside of the projects' apply windows.
+	SkipApplyWindow bool
+	// SkipApplyRequirements are the apply requirements that aren't enforced.
+	SkipApplyRequirements []string
+	// AutoApply applies the pull request once it's autoplanned without errors.
+	AutoApply bool
+}
+
+// Matches returns true if the pull request with the head branch and head
+// commit message matches the rule.
+func (r BehaviorRule) Matches(branch string, commitMessage string) bool {
+	return (r.Branch == nil || r.Branch.MatchString(branch)) &&
+		(r.CommitMessage == nil || r.CommitMessage.MatchString(commitMessage))
+}
+
+// Behavior is the combination of the behavior rules a pull request matches.
+type Behavior struct {
+	SkipApplyWindow       bool
+	SkipApplyRequirements []string
+	AutoApply             bool
+}
+
+// MatchBehaviorRules returns the combined behavior of the rules the pull
+// request with the head branch and head commit message matches.
+func MatchBehaviorRules(rules []BehaviorRule, branch string, commitMessage string) Behavior {
+	var behavior Behavior
+	for _, rule := range rules {
+		if !rule.Matches(branch, commitMessage) {
+			continue
+		}
+		behavior.SkipApplyWindow = behavior.SkipApplyWindow || rule.SkipApplyWindow
+		behavior.AutoApply = behavior.AutoApply || rule.AutoApply
+		for _, req := range rule.SkipApplyRequirements {
+			if !slices.Contains(behavior.SkipApplyRequirements, req) {
+				behavior.SkipApplyRequirements = append(behavior.SkipApplyRequirements, req)
+			}
+		}
+	}
+	return behavior
+}
+
+// Apply returns cfg without the apply window and apply requirements the
+// behavior skips.
+func (b Behavior) Apply(cfg MergedProjectCfg) MergedProjectCfg {
+	if b.SkipApplyWindow {
+		cfg.ApplyWindow = nil
+	}
+	if len(b.SkipApplyRequirements) > 0 {
+		var applyReqs []string
+		for _, req := range cfg.ApplyRequirements {
+			if !slices.Contains(b.SkipApplyRequirements, req) {
+				applyReqs = append(applyReqs, req)
+			}
+		}
+		cfg.ApplyRequirements = applyReqs
+	}
+	return cfg
+}
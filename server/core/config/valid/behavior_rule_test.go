@@ -0,0 +1,66 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package valid_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestMatchBehaviorRules(t *testing.T) {
+	rules := []valid.BehaviorRule{
+		{Branch: regexp.MustCompile(`^hotfix/`), SkipApplyWindow: true, SkipApplyRequirements: []string{"approved"}},
+		{CommitMessage: regexp.MustCompile(`^chore\(deps\)`), AutoApply: true},
+		{Branch: regexp.MustCompile(`^hotfix/`), CommitMessage: regexp.MustCompile(`\[skip review\]`), SkipApplyRequirements: []string{"approved", "mergeable"}},
+	}
+	cases := []struct {
+		description   string
+		branch        string
+		commitMessage string
+		exp           valid.Behavior
+	}{
+		{
+			description:   "no match",
+			branch:        "feature/vpc",
+			commitMessage: "Add a VPC",
+			exp:           valid.Behavior{},
+		},
+		{
+			description:   "branch match",
+			branch:        "hotfix/dns",
+			commitMessage: "Fix DNS",
+			exp:           valid.Behavior{SkipApplyWindow: true, SkipApplyRequirements: []string{"approved"}},
+		},
+		{
+			description:   "commit message match",
+			branch:        "renovate/aws",
+			commitMessage: "chore(deps): update aws provider",
+			exp:           valid.Behavior{AutoApply: true},
+		},
+		{
+			description:   "matches are combined",
+			branch:        "hotfix/dns",
+			commitMessage: "Fix DNS [skip review]",
+			exp:           valid.Behavior{SkipApplyWindow: true, SkipApplyRequirements: []string{"approved", "mergeable"}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			Equals(t, c.exp, valid.MatchBehaviorRules(rules, c.branch, c.commitMessage))
+		})
+	}
+}
+
+func TestBehavior_Apply(t *testing.T) {
+	cfg := valid.MergedProjectCfg{
+		ApplyRequirements: []string{"approved", "mergeable"},
+		ApplyWindow:       &valid.ApplyWindow{},
+	}
+	Equals(t, cfg, valid.Behavior{AutoApply: true}.Apply(cfg))
+	Equals(t, valid.MergedProjectCfg{ApplyRequirements: []string{"mergeable"}},
+		valid.Behavior{SkipApplyWindow: true, SkipApplyRequirements: []string{"approved"}}.Apply(cfg))
+}
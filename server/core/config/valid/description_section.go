@@ -0,0 +1,27 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package valid
+
+import "regexp"
+
+// DescriptionSection is a section the description of a pull request must
+// contain before applying, for the description requirement.
+type DescriptionSection struct {
+	// Name describes the section in the failure, ex. Rollback plan.
+	Name string
+	// Regex matches the section in the description.
+	Regex *regexp.Regexp
+}
+
+// MissingDescriptionSections returns the names of the sections description
+// doesn't contain.
+func MissingDescriptionSections(sections []DescriptionSection, description string) []string {
+	var missing []string
+	for _, section := range sections {
+		if !section.Regex.MatchString(description) {
+			missing = append(missing, section.Name)
+		}
+	}
+	return missing
+}
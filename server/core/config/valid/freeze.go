@@ -0,0 +1,81 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package valid
+
+import (
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Freeze is a change freeze, applies of the repos and projects it matches are
+// blocked while one of its windows is active.
+type Freeze struct {
+	Name string
+	// Windows are the periods the freeze is active. They're empty if the
+	// freeze is defined by ICalURL.
+	Windows []FreezeWindow
+	// ICalURL is the URL of an iCalendar whose events are the windows of the
+	// freeze.
+	ICalURL string
+	// Repos are the ids of the frozen repos, ex. github.com/org/repo, or
+	// regexes matching them, ex. /github.com/org/.*/. Empty means all repos.
+	Repos []string
+	// Projects are the names or dirs of the frozen projects, they can be
+	// patterns like prod-*. Empty means all projects.
+	Projects []string
+	// Message explains the freeze in the comments blocking applies.
+	Message string
+	// OverrideUsers may apply during the freeze with --break-glass.
+	OverrideUsers []string
+}
+
+// FreezeWindow is a period a freeze is active, from Start to End.
+type FreezeWindow struct {
+	Start time.Time
+	End   time.Time
+	// Summary describes the window, ex. the summary of its calendar event.
+	Summary string
+}
+
+// Contains returns true if t falls within the window.
+func (w FreezeWindow) Contains(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+// MatchesRepo returns true if the repo with id repoID is frozen.
+func (f Freeze) MatchesRepo(repoID string) bool {
+	if len(f.Repos) == 0 {
+		return true
+	}
+	for _, id := range f.Repos {
+		if strings.HasPrefix(id, "/") && strings.HasSuffix(id, "/") && len(id) > 1 {
+			// Safe to use MustCompile because it's checked when parsing.
+			if regexp.MustCompile(id[1 : len(id)-1]).MatchString(repoID) {
+				return true
+			}
+			continue
+		}
+		if id == repoID {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesProject returns true if the project named name in dir is frozen.
+func (f Freeze) MatchesProject(name string, dir string) bool {
+	return len(f.Projects) == 0 || slices.ContainsFunc(f.Projects, func(pattern string) bool {
+		nameMatches, _ := path.Match(pattern, name)
+		dirMatches, _ := path.Match(pattern, dir)
+		return (name != "" && nameMatches) || dirMatches
+	})
+}
+
+// CanOverride returns true if username may apply during the freeze.
+func (f Freeze) CanOverride(username string) bool {
+	return slices.Contains(f.OverrideUsers, username)
+}
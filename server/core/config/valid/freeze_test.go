@@ -0,0 +1,104 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package valid_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestFreezeWindow_Contains(t *testing.T) {
+	window := valid.FreezeWindow{
+		Start: time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC),
+	}
+	Equals(t, false, window.Contains(window.Start.Add(-time.Second)))
+	Equals(t, true, window.Contains(window.Start))
+	Equals(t, true, window.Contains(window.End.Add(-time.Second)))
+	Equals(t, false, window.Contains(window.End))
+}
+
+func TestFreeze_MatchesRepo(t *testing.T) {
+	cases := []struct {
+		description string
+		repos       []string
+		exp         bool
+	}{
+		{
+			description: "no repos matches all",
+			exp:         true,
+		},
+		{
+			description: "exact id",
+			repos:       []string{"github.com/org/other", "github.com/org/repo"},
+			exp:         true,
+		},
+		{
+			description: "regex",
+			repos:       []string{"/github.com/org/.*/"},
+			exp:         true,
+		},
+		{
+			description: "no match",
+			repos:       []string{"github.com/org/other", "/gitlab.com/.*/"},
+			exp:         false,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			Equals(t, c.exp, valid.Freeze{Repos: c.repos}.MatchesRepo("github.com/org/repo"))
+		})
+	}
+}
+
+func TestFreeze_MatchesProject(t *testing.T) {
+	cases := []struct {
+		description string
+		projects    []string
+		name        string
+		dir         string
+		exp         bool
+	}{
+		{
+			description: "no projects matches all",
+			name:        "staging",
+			dir:         "staging",
+			exp:         true,
+		},
+		{
+			description: "name pattern",
+			projects:    []string{"prod-*"},
+			name:        "prod-db",
+			dir:         "db",
+			exp:         true,
+		},
+		{
+			description: "dir pattern",
+			projects:    []string{"envs/prod/*"},
+			dir:         "envs/prod/db",
+			exp:         true,
+		},
+		{
+			description: "no match",
+			projects:    []string{"prod-*", "envs/prod/*"},
+			name:        "staging-db",
+			dir:         "envs/staging/db",
+			exp:         false,
+		},
+		{
+			description: "unnamed project",
+			projects:    []string{"*"},
+			dir:         "envs/prod",
+			exp:         false,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			Equals(t, c.exp, valid.Freeze{Projects: c.projects}.MatchesProject(c.name, c.dir))
+		})
+	}
+}
@@ -5,6 +5,7 @@ package valid
 
 import (
 	"fmt"
+	"path"
 	"regexp"
 	"strings"
 
@@ -16,6 +17,8 @@ import (
 const MergeableCommandReq = "mergeable"
 const ApprovedCommandReq = "approved"
 const UnDivergedCommandReq = "undiverged"
+const FreshCommandReq = "fresh"
+const ChangeRequestCommandReq = "change_request"
 const PoliciesPassedCommandReq = "policies_passed"
 const PlanRequirementsKey = "plan_requirements"
 const ApplyRequirementsKey = "apply_requirements"
@@ -31,9 +34,28 @@ const PolicyCheckKey = "policy_check"
 const CustomPolicyCheckKey = "custom_policy_check"
 const AutoDiscoverKey = "autodiscover"
 const SilencePRCommentsKey = "silence_pr_comments"
+const PreviewKey = "preview"
+const AllowedTFCWorkspacesKey = "allowed_tfc_workspaces"
 
 var AllowedSilencePRComments = []string{"plan", "apply"}
 
+// The checkout strategies repos can be configured with.
+const (
+	CheckoutStrategyMerge  = "merge"
+	CheckoutStrategyBranch = "branch"
+)
+
+// How plans without changes are commented on pull requests.
+const (
+	// NoChangesPlanCommentsShow comments them like other plans.
+	NoChangesPlanCommentsShow = "show"
+	// NoChangesPlanCommentsRollup lists them in a single line below the other
+	// plans.
+	NoChangesPlanCommentsRollup = "rollup"
+	// NoChangesPlanCommentsSkip doesn't comment them.
+	NoChangesPlanCommentsSkip = "skip"
+)
+
 // DefaultAtlantisFile is the default name of the config file for each repo.
 const DefaultAtlantisFile = "atlantis.yaml"
 
@@ -51,6 +73,8 @@ type GlobalCfg struct {
 	PolicySets PolicySets
 	Metrics    Metrics
 	TeamAuthz  TeamAuthz
+	// Freezes are the change freezes blocking applies.
+	Freezes []Freeze
 }
 
 type Metrics struct {
@@ -93,6 +117,35 @@ type Repo struct {
 	CustomPolicyCheck         *bool
 	AutoDiscover              *AutoDiscover
 	SilencePRComments         []string
+	// CheckoutStrategy overrides the server's --checkout-strategy for the
+	// repo if set.
+	CheckoutStrategy string
+	// DescriptionSections are the sections the description of the repo's
+	// pull requests must contain for the description requirement.
+	DescriptionSections []DescriptionSection
+	// BehaviorRules change how the repo's pull requests are handled by their
+	// head branch and head commit message.
+	BehaviorRules []BehaviorRule
+	// NoChangesPlanComments is how plans without changes are commented, one of
+	// the NoChangesPlanComments constants, "" to comment them like other plans.
+	NoChangesPlanComments string
+	// AllowedTFCWorkspaces are the patterns, ex. my-org/prod-*, of the
+	// Terraform Cloud workspaces the repo's projects can be run by with
+	// tfc_workspace. None are allowed if it's empty.
+	AllowedTFCWorkspaces []string
+	// ConcurrencyGroup is the group of --concurrency-groups limiting how many
+	// terraform commands of the repo's projects run at once.
+	ConcurrencyGroup string
+	// ApplyWindowOverrideUsers may apply the repo's projects outside of their
+	// apply windows.
+	ApplyWindowOverrideUsers []string
+	// CostApprovers are the users, ex. @alice, and teams, ex. @org/finops,
+	// who must approve the plans exceeding the cost thresholds of the repo's
+	// projects. Any approval is enough if it's empty.
+	CostApprovers []string
+	// Org is the id of the org, ex. github.com/runatlantis, if these are the
+	// defaults of an org's repos rather than a repo's settings.
+	Org string
 }
 
 type MergedProjectCfg struct {
@@ -118,6 +171,18 @@ type MergedProjectCfg struct {
 	PolicyCheck               bool
 	CustomPolicyCheck         bool
 	SilencePRComments         []string
+	ApplyWindow               *ApplyWindow
+	CostThreshold             *float64
+	CostApprovers             []string
+	Environment               string
+	AgentPool                 string
+	ConcurrencyGroup          string
+	DescriptionSections       []DescriptionSection
+	BehaviorRules             []BehaviorRule
+	NoChangesPlanComments     string
+	Owners                    []string
+	TFCWorkspace              string
+	Preview                   *Preview
 }
 
 // WorkflowHook is a map of custom run commands to run before or after workflows.
@@ -227,7 +292,7 @@ func NewGlobalCfgFromArgs(args GlobalCfgArgs) GlobalCfg {
 	autoDiscover := AutoDiscover{Mode: AutoDiscoverAutoMode}
 	var silencePRComments []string
 	if args.AllowAllRepoSettings {
-		allowedOverrides = []string{PlanRequirementsKey, ApplyRequirementsKey, ImportRequirementsKey, WorkflowKey, DeleteSourceBranchOnMergeKey, RepoLockingKey, RepoLocksKey, PolicyCheckKey, SilencePRCommentsKey}
+		allowedOverrides = []string{PlanRequirementsKey, ApplyRequirementsKey, ImportRequirementsKey, WorkflowKey, DeleteSourceBranchOnMergeKey, RepoLockingKey, RepoLocksKey, PolicyCheckKey, SilencePRCommentsKey, PreviewKey}
 		allowCustomWorkflows = true
 	}
 
@@ -428,6 +493,18 @@ func (g GlobalCfg) MergeProjectCfg(log logging.SimpleLogging, repoID string, pro
 		PolicyCheck:               policyCheck,
 		CustomPolicyCheck:         customPolicyCheck,
 		SilencePRComments:         silencePRComments,
+		ApplyWindow:               g.applyWindow(repoID, proj.ApplyWindow),
+		CostThreshold:             proj.CostThreshold,
+		CostApprovers:             g.CostApprovers(repoID),
+		Environment:               proj.GetEnvironment(),
+		AgentPool:                 proj.GetAgentPool(),
+		ConcurrencyGroup:          g.ConcurrencyGroup(repoID),
+		DescriptionSections:       g.DescriptionSections(repoID),
+		BehaviorRules:             g.BehaviorRules(repoID),
+		NoChangesPlanComments:     g.NoChangesPlanComments(repoID),
+		Owners:                    proj.Owners,
+		TFCWorkspace:              proj.GetTFCWorkspace(),
+		Preview:                   proj.Preview,
 	}
 }
 
@@ -453,6 +530,10 @@ func (g GlobalCfg) DefaultProjCfg(log logging.SimpleLogging, repoID string, repo
 		PolicyCheck:               policyCheck,
 		CustomPolicyCheck:         customPolicyCheck,
 		SilencePRComments:         silencePRComments,
+		DescriptionSections:       g.DescriptionSections(repoID),
+		BehaviorRules:             g.BehaviorRules(repoID),
+		NoChangesPlanComments:     g.NoChangesPlanComments(repoID),
+		ConcurrencyGroup:          g.ConcurrencyGroup(repoID),
 	}
 }
 
@@ -513,6 +594,9 @@ func (g GlobalCfg) ValidateRepoCfg(rCfg RepoCfg, repoID string) error {
 		if p.CustomPolicyCheck != nil && !utils.SlicesContains(allowedOverrides, CustomPolicyCheckKey) {
 			return fmt.Errorf("repo config not allowed to set '%s' key: server-side config needs '%s: [%s]'", CustomPolicyCheckKey, AllowedOverridesKey, CustomPolicyCheckKey)
 		}
+		if p.Preview != nil && !utils.SlicesContains(allowedOverrides, PreviewKey) {
+			return fmt.Errorf("repo config not allowed to set '%s' key: server-side config needs '%s: [%s]'", PreviewKey, AllowedOverridesKey, PreviewKey)
+		}
 		if p.SilencePRComments != nil {
 			if !utils.SlicesContains(allowedOverrides, SilencePRCommentsKey) {
 				return fmt.Errorf(
@@ -587,6 +671,32 @@ func (g GlobalCfg) ValidateRepoCfg(rCfg RepoCfg, repoID string) error {
 		}
 	}
 
+	// Check the Terraform Cloud workspaces are allowed. The workspaces are run
+	// with the server's --tfe-token so repos can't pick any workspace it can
+	// access.
+	var allowedTFCWorkspaces []string
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) && repo.AllowedTFCWorkspaces != nil {
+			allowedTFCWorkspaces = repo.AllowedTFCWorkspaces
+		}
+	}
+	for _, p := range rCfg.Projects {
+		workspace := p.GetTFCWorkspace()
+		if workspace == "" {
+			continue
+		}
+		allowed := false
+		for _, pattern := range allowedTFCWorkspaces {
+			if matched, _ := path.Match(pattern, workspace); matched {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("tfc_workspace %q is not allowed for this repo: server-side config needs '%s' matching it", workspace, AllowedTFCWorkspacesKey)
+		}
+	}
+
 	return nil
 }
 
@@ -595,7 +705,9 @@ func (g GlobalCfg) getMatchingCfg(log logging.SimpleLogging, repoID string) (pla
 	toLog := make(map[string]string)
 	traceF := func(repoIdx int, repoID string, key string, val any) string {
 		from := "default server config"
-		if repoIdx > 0 {
+		if g.Repos[repoIdx].Org != "" {
+			from = fmt.Sprintf("org defaults, id: %s", g.Repos[repoIdx].Org)
+		} else if repoIdx > 0 {
 			from = fmt.Sprintf("repos[%d], id: %s", repoIdx, repoID)
 		}
 		var valStr string
@@ -713,6 +825,98 @@ func (g GlobalCfg) MatchingRepo(repoID string) *Repo {
 	return nil
 }
 
+// CheckoutStrategy returns the checkout strategy configured for the repo or
+// "" if it uses the server's --checkout-strategy.
+func (g GlobalCfg) CheckoutStrategy(repoID string) string {
+	for i := len(g.Repos) - 1; i >= 0; i-- {
+		repo := g.Repos[i]
+		if repo.CheckoutStrategy != "" && repo.IDMatches(repoID) {
+			return repo.CheckoutStrategy
+		}
+	}
+	return ""
+}
+
+// DescriptionSections returns the sections the description of the repo's pull
+// requests must contain for the description requirement, nil if none are
+// configured.
+func (g GlobalCfg) DescriptionSections(repoID string) []DescriptionSection {
+	for i := len(g.Repos) - 1; i >= 0; i-- {
+		repo := g.Repos[i]
+		if len(repo.DescriptionSections) > 0 && repo.IDMatches(repoID) {
+			return repo.DescriptionSections
+		}
+	}
+	return nil
+}
+
+// BehaviorRules returns the behavior rules of the repo's pull requests, nil
+// if none are configured.
+func (g GlobalCfg) BehaviorRules(repoID string) []BehaviorRule {
+	for i := len(g.Repos) - 1; i >= 0; i-- {
+		repo := g.Repos[i]
+		if len(repo.BehaviorRules) > 0 && repo.IDMatches(repoID) {
+			return repo.BehaviorRules
+		}
+	}
+	return nil
+}
+
+// NoChangesPlanComments returns how plans without changes are commented on
+// the repo's pull requests, one of the NoChangesPlanComments constants, or ""
+// if it isn't configured and they're commented like other plans.
+func (g GlobalCfg) NoChangesPlanComments(repoID string) string {
+	for i := len(g.Repos) - 1; i >= 0; i-- {
+		repo := g.Repos[i]
+		if repo.NoChangesPlanComments != "" && repo.IDMatches(repoID) {
+			return repo.NoChangesPlanComments
+		}
+	}
+	return ""
+}
+
+// applyWindow returns window with the users the server-side config of the repo
+// allows to apply outside of it, nil if the project doesn't have a window.
+func (g GlobalCfg) applyWindow(repoID string, window *ApplyWindow) *ApplyWindow {
+	if window == nil {
+		return nil
+	}
+	merged := *window
+	merged.OverrideUsers = nil
+	for i := len(g.Repos) - 1; i >= 0; i-- {
+		repo := g.Repos[i]
+		if repo.ApplyWindowOverrideUsers != nil && repo.IDMatches(repoID) {
+			merged.OverrideUsers = repo.ApplyWindowOverrideUsers
+			break
+		}
+	}
+	return &merged
+}
+
+// ConcurrencyGroup returns the concurrency group of the repo's projects, or ""
+// if they aren't in one.
+func (g GlobalCfg) ConcurrencyGroup(repoID string) string {
+	for i := len(g.Repos) - 1; i >= 0; i-- {
+		repo := g.Repos[i]
+		if repo.ConcurrencyGroup != "" && repo.IDMatches(repoID) {
+			return repo.ConcurrencyGroup
+		}
+	}
+	return ""
+}
+
+// CostApprovers returns who must approve the plans of the repo's projects
+// exceeding their cost thresholds, nil if any approval is enough.
+func (g GlobalCfg) CostApprovers(repoID string) []string {
+	for i := len(g.Repos) - 1; i >= 0; i-- {
+		repo := g.Repos[i]
+		if repo.CostApprovers != nil && repo.IDMatches(repoID) {
+			return repo.CostApprovers
+		}
+	}
+	return nil
+}
+
 // RepoConfigFile returns a repository specific file path
 // If not defined, return atlantis.yaml as default
 func (g GlobalCfg) RepoConfigFile(repoID string) string {
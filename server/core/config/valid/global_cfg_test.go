@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-version"
 	"github.com/mohae/deepcopy"
@@ -135,7 +136,7 @@ func TestNewGlobalCfg(t *testing.T) {
 
 			if c.allowAllRepoSettings {
 				exp.Repos[0].AllowCustomWorkflows = Bool(true)
-				exp.Repos[0].AllowedOverrides = []string{"plan_requirements", "apply_requirements", "import_requirements", "workflow", "delete_source_branch_on_merge", "repo_locking", "repo_locks", "policy_check", "silence_pr_comments"}
+				exp.Repos[0].AllowedOverrides = []string{"plan_requirements", "apply_requirements", "import_requirements", "workflow", "delete_source_branch_on_merge", "repo_locking", "repo_locks", "policy_check", "silence_pr_comments", "preview"}
 			}
 			if c.policyCheckEnabled {
 				exp.Repos[0].ApplyRequirements = append(exp.Repos[0].ApplyRequirements, "policies_passed")
@@ -197,6 +198,63 @@ func TestGlobalCfg_ValidateRepoCfg(t *testing.T) {
 			repoID: "github.com/owner/repo",
 			expErr: "workflow 'forbidden' is not allowed for this repo",
 		},
+		"repo uses tfc workspace that is allowed": {
+			gCfg: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{}).Repos[0],
+					{
+						ID:                   "github.com/owner/repo",
+						AllowedTFCWorkspaces: []string{"my-org/prod-*"},
+					},
+				},
+			},
+			rCfg: valid.RepoCfg{
+				Projects: []valid.Project{
+					{
+						Dir:          ".",
+						Workspace:    "default",
+						TFCWorkspace: String("my-org/prod-network"),
+					},
+				},
+			},
+			repoID: "github.com/owner/repo",
+		},
+		"repo uses tfc workspace that isn't allowed": {
+			gCfg: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{}).Repos[0],
+					{
+						ID:                   "github.com/owner/repo",
+						AllowedTFCWorkspaces: []string{"my-org/prod-*"},
+					},
+				},
+			},
+			rCfg: valid.RepoCfg{
+				Projects: []valid.Project{
+					{
+						Dir:          ".",
+						Workspace:    "default",
+						TFCWorkspace: String("other-org/prod-network"),
+					},
+				},
+			},
+			repoID: "github.com/owner/repo",
+			expErr: `tfc_workspace "other-org/prod-network" is not allowed for this repo: server-side config needs 'allowed_tfc_workspaces' matching it`,
+		},
+		"repo uses tfc workspace without an allowlist": {
+			gCfg: valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{AllowAllRepoSettings: true}),
+			rCfg: valid.RepoCfg{
+				Projects: []valid.Project{
+					{
+						Dir:          ".",
+						Workspace:    "default",
+						TFCWorkspace: String("my-org/prod"),
+					},
+				},
+			},
+			repoID: "github.com/owner/repo",
+			expErr: `tfc_workspace "my-org/prod" is not allowed for this repo: server-side config needs 'allowed_tfc_workspaces' matching it`,
+		},
 		"repo uses workflow that is defined server side but not allowed (without custom workflows)": {
 			gCfg: valid.GlobalCfg{
 				Repos: []valid.Repo{
@@ -492,6 +550,22 @@ func TestGlobalCfg_ValidateRepoCfg(t *testing.T) {
 			repoID: "github.com/owner/repo",
 			expErr: "repo config not allowed to set 'import_requirements' key: server-side config needs 'allowed_overrides: [import_requirements]'",
 		},
+		"preview not allowed": {
+			gCfg: valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{
+				AllowAllRepoSettings: false,
+			}),
+			rCfg: valid.RepoCfg{
+				Projects: []valid.Project{
+					{
+						Dir:       ".",
+						Workspace: "default",
+						Preview:   &valid.Preview{},
+					},
+				},
+			},
+			repoID: "github.com/owner/repo",
+			expErr: "repo config not allowed to set 'preview' key: server-side config needs 'allowed_overrides: [preview]'",
+		},
 		"repo workflow doesn't exist": {
 			gCfg: valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{
 				AllowAllRepoSettings: true,
@@ -715,6 +789,81 @@ workflows:
 				CustomPolicyCheck: false,
 			},
 		},
+		"org defaults apply to the org's repos": {
+			gCfg: `
+orgs:
+- id: github.com/owner
+  apply_requirements: [approved]
+  delete_source_branch_on_merge: true
+`,
+			repoID: "github.com/owner/repo",
+			proj: valid.Project{
+				Dir:       ".",
+				Workspace: "default",
+			},
+			exp: valid.MergedProjectCfg{
+				PlanRequirements:          []string{},
+				ApplyRequirements:         []string{"approved"},
+				ImportRequirements:        []string{},
+				Workflow:                  defaultWorkflow,
+				RepoRelDir:                ".",
+				Workspace:                 "default",
+				PolicySets:                emptyPolicySets,
+				DeleteSourceBranchOnMerge: true,
+				RepoLocks:                 valid.DefaultRepoLocks,
+			},
+		},
+		"org defaults don't apply to other orgs": {
+			gCfg: `
+orgs:
+- id: github.com/owner
+  apply_requirements: [approved]
+`,
+			repoID: "github.com/owner2/repo",
+			proj: valid.Project{
+				Dir:       ".",
+				Workspace: "default",
+			},
+			exp: valid.MergedProjectCfg{
+				PlanRequirements:   []string{},
+				ApplyRequirements:  []string{},
+				ImportRequirements: []string{},
+				Workflow:           defaultWorkflow,
+				RepoRelDir:         ".",
+				Workspace:          "default",
+				PolicySets:         emptyPolicySets,
+				RepoLocks:          valid.DefaultRepoLocks,
+			},
+		},
+		"repo settings override org defaults and projects override both if allowed": {
+			gCfg: `
+orgs:
+- id: github.com/owner
+  allowed_overrides: [import_requirements]
+  plan_requirements: [approved]
+  apply_requirements: [approved]
+  import_requirements: [approved]
+repos:
+- id: github.com/owner/repo
+  apply_requirements: [mergeable]
+`,
+			repoID: "github.com/owner/repo",
+			proj: valid.Project{
+				Dir:                ".",
+				Workspace:          "default",
+				ImportRequirements: []string{"undiverged"},
+			},
+			exp: valid.MergedProjectCfg{
+				PlanRequirements:   []string{"approved"},
+				ApplyRequirements:  []string{"mergeable"},
+				ImportRequirements: []string{"undiverged"},
+				Workflow:           defaultWorkflow,
+				RepoRelDir:         ".",
+				Workspace:          "default",
+				PolicySets:         emptyPolicySets,
+				RepoLocks:          valid.DefaultRepoLocks,
+			},
+		},
 		"repo-side plan reqs win out if allowed": {
 			gCfg: `
 repos:
@@ -1185,6 +1334,41 @@ func TestGlobalCfg_MatchingRepo(t *testing.T) {
 	}
 }
 
+func TestGlobalCfg_CheckoutStrategy(t *testing.T) {
+	gCfg := valid.GlobalCfg{
+		Repos: []valid.Repo{
+			{IDRegex: regexp.MustCompile(".*"), CheckoutStrategy: valid.CheckoutStrategyMerge},
+			{ID: "github.com/owner/branch", CheckoutStrategy: valid.CheckoutStrategyBranch},
+			{ID: "github.com/owner/unset"},
+		},
+	}
+	Equals(t, valid.CheckoutStrategyMerge, gCfg.CheckoutStrategy("github.com/owner/repo"))
+	Equals(t, valid.CheckoutStrategyBranch, gCfg.CheckoutStrategy("github.com/owner/branch"))
+	Equals(t, valid.CheckoutStrategyMerge, gCfg.CheckoutStrategy("github.com/owner/unset"))
+	Equals(t, "", valid.GlobalCfg{}.CheckoutStrategy("github.com/owner/repo"))
+}
+
+func TestGlobalCfg_MergeProjectCfg_ApplyWindowOverrideUsers(t *testing.T) {
+	gCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})
+	gCfg.Repos = append(gCfg.Repos,
+		valid.Repo{IDRegex: regexp.MustCompile(".*"), ApplyWindowOverrideUsers: []string{"alice"}},
+		valid.Repo{ID: "github.com/owner/infra", ApplyWindowOverrideUsers: []string{"bob"}},
+	)
+	window := &valid.ApplyWindow{Start: 9 * time.Hour, End: 16 * time.Hour, OverrideUsers: []string{"mallory"}}
+	proj := valid.Project{Dir: ".", Workspace: "default", ApplyWindow: window}
+
+	merged := gCfg.MergeProjectCfg(logging.NewNoopLogger(t), "github.com/owner/repo", proj, valid.RepoCfg{})
+	Equals(t, []string{"alice"}, merged.ApplyWindow.OverrideUsers)
+	Equals(t, 9*time.Hour, merged.ApplyWindow.Start)
+	merged = gCfg.MergeProjectCfg(logging.NewNoopLogger(t), "github.com/owner/infra", proj, valid.RepoCfg{})
+	Equals(t, []string{"bob"}, merged.ApplyWindow.OverrideUsers)
+	// The window of the repo config isn't changed.
+	Equals(t, []string{"mallory"}, window.OverrideUsers)
+
+	merged = gCfg.MergeProjectCfg(logging.NewNoopLogger(t), "github.com/owner/repo", valid.Project{Dir: ".", Workspace: "default"}, valid.RepoCfg{})
+	Assert(t, merged.ApplyWindow == nil, "exp no apply window")
+}
+
 func TestGlobalCfg_PolicyCheckOverride(t *testing.T) {
 	var emptyPolicySets valid.PolicySets
 
@@ -0,0 +1,37 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package valid
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultPreviewWorkspacePrefix prefixes the number of the pull request in the
+// workspaces of preview environments.
+const DefaultPreviewWorkspacePrefix = "pr-"
+
+// Preview makes a project a preview project: instead of being planned and
+// applied like other projects, each pull request is applied in a workspace
+// of its own by atlantis preview and destroyed once the pull request is
+// closed.
+type Preview struct {
+	// Auto is true if the environment is deployed when the pull request is
+	// opened or updated, without commenting atlantis preview.
+	Auto bool
+	// TTL is how long after it's deployed the environment is destroyed even
+	// if the pull request is still open. Zero if it doesn't expire.
+	TTL time.Duration
+	// MaxEnvironments is how many pull requests may have an environment of
+	// the project at once. Zero if there's no limit.
+	MaxEnvironments int
+	// WorkspacePrefix prefixes the number of the pull request in the
+	// environment's workspace.
+	WorkspacePrefix string
+}
+
+// Workspace returns the workspace of the environment of pull request pullNum.
+func (p Preview) Workspace(pullNum int) string {
+	return fmt.Sprintf("%s%d", p.WorkspacePrefix, pullNum)
+}
@@ -194,6 +194,19 @@ type Project struct {
 	PolicyCheck               *bool
 	CustomPolicyCheck         *bool
 	SilencePRComments         []string
+	ApplyWindow               *ApplyWindow
+	CostThreshold             *float64
+	Environment               *string
+	AgentPool                 *string
+	// Owners are the users and teams mentioned on the project's plans, ex.
+	// @org/team. If empty, the owners of its dir in CODEOWNERS may be.
+	Owners []string
+	// TFCWorkspace is the Terraform Cloud workspace, ex. my-org/my-workspace,
+	// whose runs plan and apply the project.
+	TFCWorkspace *string
+	// Preview is set if the project is a preview project, which is only run
+	// by atlantis preview.
+	Preview *Preview
 }
 
 // GetName returns the name of the project or an empty string if there is no
@@ -205,6 +218,33 @@ func (p Project) GetName() string {
 	return ""
 }
 
+// GetEnvironment returns the environment label of the project or an empty
+// string if it has none.
+func (p Project) GetEnvironment() string {
+	if p.Environment != nil {
+		return *p.Environment
+	}
+	return ""
+}
+
+// GetAgentPool returns the agent pool running the project's terraform
+// commands or an empty string if they're run by Atlantis.
+func (p Project) GetAgentPool() string {
+	if p.AgentPool != nil {
+		return *p.AgentPool
+	}
+	return ""
+}
+
+// GetTFCWorkspace returns the Terraform Cloud workspace planning and applying
+// the project or an empty string if they're run by Atlantis.
+func (p Project) GetTFCWorkspace() string {
+	if p.TFCWorkspace != nil {
+		return *p.TFCWorkspace
+	}
+	return ""
+}
+
 type Autoplan struct {
 	WhenModified []string
 	Enabled      bool
@@ -253,6 +293,8 @@ type Step struct {
 	// FilterRegex is a list of regexes for post-processing a RunCommand output
 	// these will be executed in the received order
 	FilterRegexes []*regexp.Regexp
+	// FailFast fails a validate step on its findings.
+	FailFast bool
 }
 
 type Workflow struct {
@@ -41,5 +41,30 @@ type Database interface {
 	UnlockCommand(cmdName command.Name) error
 	CheckCommandLock(cmdName command.Name) (*command.Lock, error)
 
+	SaveSummaryFeedback(feedback models.SummaryFeedback) error
+	ListSummaryFeedback() ([]models.SummaryFeedback, error)
+	DeleteSummaryFeedback(id string) error
+
+	SaveResourceChanges(changes []models.ResourceChange) error
+	ListResourceChanges(query models.ResourceChangeQuery) ([]models.ResourceChange, error)
+
+	SaveAPIToken(token models.APIToken) error
+	ListAPITokens() ([]models.APIToken, error)
+	// DeleteAPIToken deletes the token with id and returns it, or nil if
+	// there's none.
+	DeleteAPIToken(id string) (*models.APIToken, error)
+
+	SavePreviewEnvironment(env models.PreviewEnvironment) error
+	ListPreviewEnvironments() ([]models.PreviewEnvironment, error)
+	DeletePreviewEnvironment(id string) error
+
+	SaveApplyReport(report models.SignedApplyReport) error
+	ListApplyReports(query models.ApplyReportQuery) ([]models.SignedApplyReport, error)
+
+	// SaveCommandRecord appends record to the stored command records and
+	// deletes the ones older than models.CommandRecordRetention.
+	SaveCommandRecord(record models.CommandRecord) error
+	ListCommandRecords(query models.CommandRecordQuery) ([]models.CommandRecord, error)
+
 	Close() error
 }
@@ -23,6 +23,74 @@ func NewMockDatabase(options ...pegomock.Option) *MockDatabase {
 	return mock
 }
 
+func (mock *MockDatabase) DeleteAPIToken(id string) (*models.APIToken, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockDatabase().")
+	}
+	_params := []pegomock.Param{id}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("DeleteAPIToken", _params, []reflect.Type{reflect.TypeOf((**models.APIToken)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var _ret0 *models.APIToken
+	var _ret1 error
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].(*models.APIToken)
+		}
+		if _result[1] != nil {
+			_ret1 = _result[1].(error)
+		}
+	}
+	return _ret0, _ret1
+}
+
+func (mock *MockDatabase) ListAPITokens() ([]models.APIToken, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockDatabase().")
+	}
+	_params := []pegomock.Param{}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("ListAPITokens", _params, []reflect.Type{reflect.TypeOf((*[]models.APIToken)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var _ret0 []models.APIToken
+	var _ret1 error
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].([]models.APIToken)
+		}
+		if _result[1] != nil {
+			_ret1 = _result[1].(error)
+		}
+	}
+	return _ret0, _ret1
+}
+
+func (mock *MockDatabase) SaveApplyReport(report models.SignedApplyReport) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockDatabase().")
+	}
+	_params := []pegomock.Param{report}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("SaveApplyReport", _params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var _ret0 error
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].(error)
+		}
+	}
+	return _ret0
+}
+
+func (mock *MockDatabase) SaveAPIToken(token models.APIToken) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockDatabase().")
+	}
+	_params := []pegomock.Param{token}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("SaveAPIToken", _params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var _ret0 error
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].(error)
+		}
+	}
+	return _ret0
+}
+
 func (mock *MockDatabase) SetFailHandler(fh pegomock.FailHandler) { mock.fail = fh }
 func (mock *MockDatabase) FailHandler() pegomock.FailHandler      { return mock.fail }
 
@@ -60,6 +128,21 @@ func (mock *MockDatabase) Close() error {
 	return _ret0
 }
 
+func (mock *MockDatabase) DeletePreviewEnvironment(id string) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockDatabase().")
+	}
+	_params := []pegomock.Param{id}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("DeletePreviewEnvironment", _params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var _ret0 error
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].(error)
+		}
+	}
+	return _ret0
+}
+
 func (mock *MockDatabase) DeletePullStatus(pull models.PullRequest) error {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockDatabase().")
@@ -75,6 +158,21 @@ func (mock *MockDatabase) DeletePullStatus(pull models.PullRequest) error {
 	return _ret0
 }
 
+func (mock *MockDatabase) DeleteSummaryFeedback(id string) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockDatabase().")
+	}
+	_params := []pegomock.Param{id}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("DeleteSummaryFeedback", _params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var _ret0 error
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].(error)
+		}
+	}
+	return _ret0
+}
+
 func (mock *MockDatabase) GetLock(project models.Project, workspace string) (*models.ProjectLock, error) {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockDatabase().")
@@ -132,6 +230,101 @@ func (mock *MockDatabase) List() ([]models.ProjectLock, error) {
 	return _ret0, _ret1
 }
 
+func (mock *MockDatabase) ListApplyReports(query models.ApplyReportQuery) ([]models.SignedApplyReport, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockDatabase().")
+	}
+	_params := []pegomock.Param{query}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("ListApplyReports", _params, []reflect.Type{reflect.TypeOf((*[]models.SignedApplyReport)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var _ret0 []models.SignedApplyReport
+	var _ret1 error
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].([]models.SignedApplyReport)
+		}
+		if _result[1] != nil {
+			_ret1 = _result[1].(error)
+		}
+	}
+	return _ret0, _ret1
+}
+
+func (mock *MockDatabase) ListCommandRecords(query models.CommandRecordQuery) ([]models.CommandRecord, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockDatabase().")
+	}
+	_params := []pegomock.Param{query}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("ListCommandRecords", _params, []reflect.Type{reflect.TypeOf((*[]models.CommandRecord)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var _ret0 []models.CommandRecord
+	var _ret1 error
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].([]models.CommandRecord)
+		}
+		if _result[1] != nil {
+			_ret1 = _result[1].(error)
+		}
+	}
+	return _ret0, _ret1
+}
+
+func (mock *MockDatabase) ListPreviewEnvironments() ([]models.PreviewEnvironment, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockDatabase().")
+	}
+	_params := []pegomock.Param{}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("ListPreviewEnvironments", _params, []reflect.Type{reflect.TypeOf((*[]models.PreviewEnvironment)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var _ret0 []models.PreviewEnvironment
+	var _ret1 error
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].([]models.PreviewEnvironment)
+		}
+		if _result[1] != nil {
+			_ret1 = _result[1].(error)
+		}
+	}
+	return _ret0, _ret1
+}
+
+func (mock *MockDatabase) ListResourceChanges(query models.ResourceChangeQuery) ([]models.ResourceChange, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockDatabase().")
+	}
+	_params := []pegomock.Param{query}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("ListResourceChanges", _params, []reflect.Type{reflect.TypeOf((*[]models.ResourceChange)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var _ret0 []models.ResourceChange
+	var _ret1 error
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].([]models.ResourceChange)
+		}
+		if _result[1] != nil {
+			_ret1 = _result[1].(error)
+		}
+	}
+	return _ret0, _ret1
+}
+
+func (mock *MockDatabase) ListSummaryFeedback() ([]models.SummaryFeedback, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockDatabase().")
+	}
+	_params := []pegomock.Param{}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("ListSummaryFeedback", _params, []reflect.Type{reflect.TypeOf((*[]models.SummaryFeedback)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var _ret0 []models.SummaryFeedback
+	var _ret1 error
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].([]models.SummaryFeedback)
+		}
+		if _result[1] != nil {
+			_ret1 = _result[1].(error)
+		}
+	}
+	return _ret0, _ret1
+}
+
 func (mock *MockDatabase) LockCommand(cmdName command.Name, lockTime time.Time) (*command.Lock, error) {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockDatabase().")
@@ -151,6 +344,66 @@ func (mock *MockDatabase) LockCommand(cmdName command.Name, lockTime time.Time)
 	return _ret0, _ret1
 }
 
+func (mock *MockDatabase) SaveCommandRecord(record models.CommandRecord) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockDatabase().")
+	}
+	_params := []pegomock.Param{record}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("SaveCommandRecord", _params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var _ret0 error
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].(error)
+		}
+	}
+	return _ret0
+}
+
+func (mock *MockDatabase) SavePreviewEnvironment(env models.PreviewEnvironment) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockDatabase().")
+	}
+	_params := []pegomock.Param{env}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("SavePreviewEnvironment", _params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var _ret0 error
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].(error)
+		}
+	}
+	return _ret0
+}
+
+func (mock *MockDatabase) SaveResourceChanges(changes []models.ResourceChange) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockDatabase().")
+	}
+	_params := []pegomock.Param{changes}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("SaveResourceChanges", _params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var _ret0 error
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].(error)
+		}
+	}
+	return _ret0
+}
+
+func (mock *MockDatabase) SaveSummaryFeedback(feedback models.SummaryFeedback) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockDatabase().")
+	}
+	_params := []pegomock.Param{feedback}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("SaveSummaryFeedback", _params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var _ret0 error
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].(error)
+		}
+	}
+	return _ret0
+}
+
 func (mock *MockDatabase) TryLock(lock models.ProjectLock) (bool, models.ProjectLock, error) {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockDatabase().")
@@ -344,6 +597,64 @@ func (c *MockDatabase_Close_OngoingVerification) GetCapturedArguments() {
 func (c *MockDatabase_Close_OngoingVerification) GetAllCapturedArguments() {
 }
 
+func (verifier *VerifierMockDatabase) DeleteAPIToken(id string) *MockDatabase_DeleteAPIToken_OngoingVerification {
+	_params := []pegomock.Param{id}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "DeleteAPIToken", _params, verifier.timeout)
+	return &MockDatabase_DeleteAPIToken_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockDatabase_DeleteAPIToken_OngoingVerification struct {
+	mock              *MockDatabase
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockDatabase_DeleteAPIToken_OngoingVerification) GetCapturedArguments() string {
+	id := c.GetAllCapturedArguments()
+	return id[len(id)-1]
+}
+
+func (c *MockDatabase_DeleteAPIToken_OngoingVerification) GetAllCapturedArguments() (_param0 []string) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]string, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(string)
+			}
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockDatabase) DeletePreviewEnvironment(id string) *MockDatabase_DeletePreviewEnvironment_OngoingVerification {
+	_params := []pegomock.Param{id}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "DeletePreviewEnvironment", _params, verifier.timeout)
+	return &MockDatabase_DeletePreviewEnvironment_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockDatabase_DeletePreviewEnvironment_OngoingVerification struct {
+	mock              *MockDatabase
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockDatabase_DeletePreviewEnvironment_OngoingVerification) GetCapturedArguments() string {
+	id := c.GetAllCapturedArguments()
+	return id[len(id)-1]
+}
+
+func (c *MockDatabase_DeletePreviewEnvironment_OngoingVerification) GetAllCapturedArguments() (_param0 []string) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]string, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(string)
+			}
+		}
+	}
+	return
+}
+
 func (verifier *VerifierMockDatabase) DeletePullStatus(pull models.PullRequest) *MockDatabase_DeletePullStatus_OngoingVerification {
 	_params := []pegomock.Param{pull}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "DeletePullStatus", _params, verifier.timeout)
@@ -373,6 +684,35 @@ func (c *MockDatabase_DeletePullStatus_OngoingVerification) GetAllCapturedArgume
 	return
 }
 
+func (verifier *VerifierMockDatabase) DeleteSummaryFeedback(id string) *MockDatabase_DeleteSummaryFeedback_OngoingVerification {
+	_params := []pegomock.Param{id}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "DeleteSummaryFeedback", _params, verifier.timeout)
+	return &MockDatabase_DeleteSummaryFeedback_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockDatabase_DeleteSummaryFeedback_OngoingVerification struct {
+	mock              *MockDatabase
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockDatabase_DeleteSummaryFeedback_OngoingVerification) GetCapturedArguments() string {
+	id := c.GetAllCapturedArguments()
+	return id[len(id)-1]
+}
+
+func (c *MockDatabase_DeleteSummaryFeedback_OngoingVerification) GetAllCapturedArguments() (_param0 []string) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]string, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(string)
+			}
+		}
+	}
+	return
+}
+
 func (verifier *VerifierMockDatabase) GetLock(project models.Project, workspace string) *MockDatabase_GetLock_OngoingVerification {
 	_params := []pegomock.Param{project, workspace}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "GetLock", _params, verifier.timeout)
@@ -454,6 +794,144 @@ func (c *MockDatabase_List_OngoingVerification) GetCapturedArguments() {
 func (c *MockDatabase_List_OngoingVerification) GetAllCapturedArguments() {
 }
 
+func (verifier *VerifierMockDatabase) ListAPITokens() *MockDatabase_ListAPITokens_OngoingVerification {
+	_params := []pegomock.Param{}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "ListAPITokens", _params, verifier.timeout)
+	return &MockDatabase_ListAPITokens_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockDatabase_ListAPITokens_OngoingVerification struct {
+	mock              *MockDatabase
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockDatabase_ListAPITokens_OngoingVerification) GetCapturedArguments() {
+}
+
+func (c *MockDatabase_ListAPITokens_OngoingVerification) GetAllCapturedArguments() {
+}
+
+func (verifier *VerifierMockDatabase) ListApplyReports(query models.ApplyReportQuery) *MockDatabase_ListApplyReports_OngoingVerification {
+	_params := []pegomock.Param{query}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "ListApplyReports", _params, verifier.timeout)
+	return &MockDatabase_ListApplyReports_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockDatabase_ListApplyReports_OngoingVerification struct {
+	mock              *MockDatabase
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockDatabase_ListApplyReports_OngoingVerification) GetCapturedArguments() models.ApplyReportQuery {
+	query := c.GetAllCapturedArguments()
+	return query[len(query)-1]
+}
+
+func (c *MockDatabase_ListApplyReports_OngoingVerification) GetAllCapturedArguments() (_param0 []models.ApplyReportQuery) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]models.ApplyReportQuery, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(models.ApplyReportQuery)
+			}
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockDatabase) ListCommandRecords(query models.CommandRecordQuery) *MockDatabase_ListCommandRecords_OngoingVerification {
+	_params := []pegomock.Param{query}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "ListCommandRecords", _params, verifier.timeout)
+	return &MockDatabase_ListCommandRecords_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockDatabase_ListCommandRecords_OngoingVerification struct {
+	mock              *MockDatabase
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockDatabase_ListCommandRecords_OngoingVerification) GetCapturedArguments() models.CommandRecordQuery {
+	query := c.GetAllCapturedArguments()
+	return query[len(query)-1]
+}
+
+func (c *MockDatabase_ListCommandRecords_OngoingVerification) GetAllCapturedArguments() (_param0 []models.CommandRecordQuery) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]models.CommandRecordQuery, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(models.CommandRecordQuery)
+			}
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockDatabase) ListPreviewEnvironments() *MockDatabase_ListPreviewEnvironments_OngoingVerification {
+	_params := []pegomock.Param{}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "ListPreviewEnvironments", _params, verifier.timeout)
+	return &MockDatabase_ListPreviewEnvironments_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockDatabase_ListPreviewEnvironments_OngoingVerification struct {
+	mock              *MockDatabase
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockDatabase_ListPreviewEnvironments_OngoingVerification) GetCapturedArguments() {
+}
+
+func (c *MockDatabase_ListPreviewEnvironments_OngoingVerification) GetAllCapturedArguments() {
+}
+
+func (verifier *VerifierMockDatabase) ListResourceChanges(query models.ResourceChangeQuery) *MockDatabase_ListResourceChanges_OngoingVerification {
+	_params := []pegomock.Param{query}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "ListResourceChanges", _params, verifier.timeout)
+	return &MockDatabase_ListResourceChanges_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockDatabase_ListResourceChanges_OngoingVerification struct {
+	mock              *MockDatabase
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockDatabase_ListResourceChanges_OngoingVerification) GetCapturedArguments() models.ResourceChangeQuery {
+	query := c.GetAllCapturedArguments()
+	return query[len(query)-1]
+}
+
+func (c *MockDatabase_ListResourceChanges_OngoingVerification) GetAllCapturedArguments() (_param0 []models.ResourceChangeQuery) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]models.ResourceChangeQuery, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(models.ResourceChangeQuery)
+			}
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockDatabase) ListSummaryFeedback() *MockDatabase_ListSummaryFeedback_OngoingVerification {
+	_params := []pegomock.Param{}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "ListSummaryFeedback", _params, verifier.timeout)
+	return &MockDatabase_ListSummaryFeedback_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockDatabase_ListSummaryFeedback_OngoingVerification struct {
+	mock              *MockDatabase
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockDatabase_ListSummaryFeedback_OngoingVerification) GetCapturedArguments() {
+}
+
+func (c *MockDatabase_ListSummaryFeedback_OngoingVerification) GetAllCapturedArguments() {
+}
+
 func (verifier *VerifierMockDatabase) LockCommand(cmdName command.Name, lockTime time.Time) *MockDatabase_LockCommand_OngoingVerification {
 	_params := []pegomock.Param{cmdName, lockTime}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "LockCommand", _params, verifier.timeout)
@@ -489,6 +967,180 @@ func (c *MockDatabase_LockCommand_OngoingVerification) GetAllCapturedArguments()
 	return
 }
 
+func (verifier *VerifierMockDatabase) SaveApplyReport(report models.SignedApplyReport) *MockDatabase_SaveApplyReport_OngoingVerification {
+	_params := []pegomock.Param{report}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "SaveApplyReport", _params, verifier.timeout)
+	return &MockDatabase_SaveApplyReport_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockDatabase_SaveApplyReport_OngoingVerification struct {
+	mock              *MockDatabase
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockDatabase_SaveApplyReport_OngoingVerification) GetCapturedArguments() models.SignedApplyReport {
+	report := c.GetAllCapturedArguments()
+	return report[len(report)-1]
+}
+
+func (c *MockDatabase_SaveApplyReport_OngoingVerification) GetAllCapturedArguments() (_param0 []models.SignedApplyReport) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]models.SignedApplyReport, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(models.SignedApplyReport)
+			}
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockDatabase) SaveAPIToken(token models.APIToken) *MockDatabase_SaveAPIToken_OngoingVerification {
+	_params := []pegomock.Param{token}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "SaveAPIToken", _params, verifier.timeout)
+	return &MockDatabase_SaveAPIToken_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockDatabase_SaveAPIToken_OngoingVerification struct {
+	mock              *MockDatabase
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockDatabase_SaveAPIToken_OngoingVerification) GetCapturedArguments() models.APIToken {
+	token := c.GetAllCapturedArguments()
+	return token[len(token)-1]
+}
+
+func (c *MockDatabase_SaveAPIToken_OngoingVerification) GetAllCapturedArguments() (_param0 []models.APIToken) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]models.APIToken, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(models.APIToken)
+			}
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockDatabase) SaveCommandRecord(record models.CommandRecord) *MockDatabase_SaveCommandRecord_OngoingVerification {
+	_params := []pegomock.Param{record}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "SaveCommandRecord", _params, verifier.timeout)
+	return &MockDatabase_SaveCommandRecord_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockDatabase_SaveCommandRecord_OngoingVerification struct {
+	mock              *MockDatabase
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockDatabase_SaveCommandRecord_OngoingVerification) GetCapturedArguments() models.CommandRecord {
+	record := c.GetAllCapturedArguments()
+	return record[len(record)-1]
+}
+
+func (c *MockDatabase_SaveCommandRecord_OngoingVerification) GetAllCapturedArguments() (_param0 []models.CommandRecord) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]models.CommandRecord, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(models.CommandRecord)
+			}
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockDatabase) SavePreviewEnvironment(env models.PreviewEnvironment) *MockDatabase_SavePreviewEnvironment_OngoingVerification {
+	_params := []pegomock.Param{env}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "SavePreviewEnvironment", _params, verifier.timeout)
+	return &MockDatabase_SavePreviewEnvironment_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockDatabase_SavePreviewEnvironment_OngoingVerification struct {
+	mock              *MockDatabase
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockDatabase_SavePreviewEnvironment_OngoingVerification) GetCapturedArguments() models.PreviewEnvironment {
+	env := c.GetAllCapturedArguments()
+	return env[len(env)-1]
+}
+
+func (c *MockDatabase_SavePreviewEnvironment_OngoingVerification) GetAllCapturedArguments() (_param0 []models.PreviewEnvironment) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]models.PreviewEnvironment, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(models.PreviewEnvironment)
+			}
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockDatabase) SaveResourceChanges(changes []models.ResourceChange) *MockDatabase_SaveResourceChanges_OngoingVerification {
+	_params := []pegomock.Param{changes}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "SaveResourceChanges", _params, verifier.timeout)
+	return &MockDatabase_SaveResourceChanges_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockDatabase_SaveResourceChanges_OngoingVerification struct {
+	mock              *MockDatabase
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockDatabase_SaveResourceChanges_OngoingVerification) GetCapturedArguments() []models.ResourceChange {
+	changes := c.GetAllCapturedArguments()
+	return changes[len(changes)-1]
+}
+
+func (c *MockDatabase_SaveResourceChanges_OngoingVerification) GetAllCapturedArguments() (_param0 [][]models.ResourceChange) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([][]models.ResourceChange, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.([]models.ResourceChange)
+			}
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockDatabase) SaveSummaryFeedback(feedback models.SummaryFeedback) *MockDatabase_SaveSummaryFeedback_OngoingVerification {
+	_params := []pegomock.Param{feedback}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "SaveSummaryFeedback", _params, verifier.timeout)
+	return &MockDatabase_SaveSummaryFeedback_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockDatabase_SaveSummaryFeedback_OngoingVerification struct {
+	mock              *MockDatabase
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockDatabase_SaveSummaryFeedback_OngoingVerification) GetCapturedArguments() models.SummaryFeedback {
+	feedback := c.GetAllCapturedArguments()
+	return feedback[len(feedback)-1]
+}
+
+func (c *MockDatabase_SaveSummaryFeedback_OngoingVerification) GetAllCapturedArguments() (_param0 []models.SummaryFeedback) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]models.SummaryFeedback, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(models.SummaryFeedback)
+			}
+		}
+	}
+	return
+}
+
 func (verifier *VerifierMockDatabase) TryLock(lock models.ProjectLock) *MockDatabase_TryLock_OngoingVerification {
 	_params := []pegomock.Param{lock}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "TryLock", _params, verifier.timeout)
@@ -0,0 +1,99 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package encryption encrypts the data Atlantis stores at rest.
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// KeySize is the size of the keys, in bytes. Values are encrypted with
+// AES-256-GCM.
+const KeySize = 32
+
+// prefix marks encrypted values so they can be told apart from the
+// plaintext values stored before encryption was enabled.
+var prefix = []byte("\x00atlantis-enc-v1:")
+
+// KeyEncrypter encrypts the data keys of envelope encryption with a key
+// management service, ex. AWS KMS.
+type KeyEncrypter interface {
+	// GenerateDataKey returns a new data key and the data key encrypted by
+	// the key management service.
+	GenerateDataKey() (plaintext []byte, encrypted []byte, err error)
+	// Decrypt decrypts a data key returned by GenerateDataKey.
+	Decrypt(encrypted []byte) ([]byte, error)
+}
+
+// Cipher encrypts and decrypts values with a key.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher returns a Cipher using key, which must be KeySize bytes.
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// ParseKey decodes a base64 encoded key.
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("key must be base64 encoded: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+	return key, nil
+}
+
+// Encrypt encrypts plaintext.
+func (c *Cipher) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append(append([]byte{}, prefix...), nonce...)
+	return c.aead.Seal(out, nonce, plaintext, nil), nil
+}
+
+// Decrypt decrypts a value returned by Encrypt.
+func (c *Cipher) Decrypt(value []byte) ([]byte, error) {
+	if !IsEncrypted(value) {
+		return nil, errors.New("value isn't encrypted")
+	}
+	value = value[len(prefix):]
+	if len(value) < c.aead.NonceSize() {
+		return nil, errors.New("encrypted value is truncated")
+	}
+	nonce, ciphertext := value[:c.aead.NonceSize()], value[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("decrypting value: the key doesn't match the key it was encrypted with or it was tampered with")
+	}
+	return plaintext, nil
+}
+
+// IsEncrypted returns whether value was returned by Encrypt.
+func IsEncrypted(value []byte) bool {
+	return bytes.HasPrefix(value, prefix)
+}
@@ -0,0 +1,52 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package encryption_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/encryption"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestCipher_EncryptDecrypt(t *testing.T) {
+	c, err := encryption.NewCipher(bytes.Repeat([]byte{1}, encryption.KeySize))
+	Ok(t, err)
+
+	encrypted, err := c.Encrypt([]byte(`{"Pull":{"Num":1}}`))
+	Ok(t, err)
+	Assert(t, encryption.IsEncrypted(encrypted), "exp value to be encrypted")
+	Assert(t, !bytes.Contains(encrypted, []byte("Pull")), "exp plaintext not to be in the encrypted value")
+	again, err := c.Encrypt([]byte(`{"Pull":{"Num":1}}`))
+	Ok(t, err)
+	Assert(t, !bytes.Equal(encrypted, again), "exp a new nonce for every value")
+
+	decrypted, err := c.Decrypt(encrypted)
+	Ok(t, err)
+	Equals(t, `{"Pull":{"Num":1}}`, string(decrypted))
+
+	other, err := encryption.NewCipher(bytes.Repeat([]byte{2}, encryption.KeySize))
+	Ok(t, err)
+	_, err = other.Decrypt(encrypted)
+	ErrEquals(t, "decrypting value: the key doesn't match the key it was encrypted with or it was tampered with", err)
+
+	_, err = c.Decrypt([]byte(`{"Pull":{"Num":1}}`))
+	ErrEquals(t, "value isn't encrypted", err)
+	Assert(t, !encryption.IsEncrypted([]byte(`{"Pull":{"Num":1}}`)), "exp JSON not to be encrypted")
+}
+
+func TestParseKey(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, encryption.KeySize)
+	parsed, err := encryption.ParseKey(base64.StdEncoding.EncodeToString(key) + "\n")
+	Ok(t, err)
+	Equals(t, key, parsed)
+
+	_, err = encryption.ParseKey(base64.StdEncoding.EncodeToString(key[:16]))
+	ErrEquals(t, "key must be 32 bytes, got 16", err)
+
+	_, err = encryption.NewCipher(key[:16])
+	ErrEquals(t, "key must be 32 bytes, got 16", err)
+}
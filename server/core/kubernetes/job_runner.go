@@ -0,0 +1,464 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/runtime/models"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/jobs"
+)
+
+const (
+	// containerName is the name of the container running the command.
+	containerName = "terraform"
+	// dataVolume is the name of the volume of the data dir.
+	dataVolume = "atlantis-data"
+	// podStartTimeout is how long the pod of a job can be pending, ex. while
+	// it's scheduled and its image is pulled, before the job is deleted.
+	podStartTimeout = 10 * time.Minute
+	// jobTTL is how long completed jobs are kept if Atlantis couldn't delete
+	// them, ex. because it restarted while they ran.
+	jobTTL = time.Hour
+	// deleteTimeout is how long deleting a job or secret can take.
+	deleteTimeout = 30 * time.Second
+	// defaultPollInterval is how often pods are checked by default.
+	defaultPollInterval = 2 * time.Second
+)
+
+// Resources are the compute resources of a container, ex. {"cpu": "1"}.
+type Resources struct {
+	Requests map[string]string `json:"requests,omitempty"`
+	Limits   map[string]string `json:"limits,omitempty"`
+}
+
+// JobConfig configures the Kubernetes Jobs commands are run as.
+type JobConfig struct {
+	// Image is the image of the jobs. It must be able to run the terraform
+	// binaries Atlantis downloads and the run steps of workflows.
+	Image string
+	// ServiceAccount is the service account of the jobs, the default one of
+	// the namespace if empty.
+	ServiceAccount string
+	Resources      Resources
+	NodeSelector   map[string]string
+	// DataVolumeClaim is the persistent volume claim of the data dir of
+	// Atlantis. It's mounted at DataDir so jobs run in the working dir of the
+	// pull request with the terraform binaries and plugins of Atlantis.
+	DataVolumeClaim string
+	DataDir         string
+}
+
+// JobRunner runs commands as Kubernetes Jobs, streaming their logs to the
+// output of the project.
+type JobRunner struct {
+	Client        *Client
+	Config        JobConfig
+	OutputHandler jobs.ProjectCommandOutputHandler
+	// PollInterval is how often the pod of a job is checked while it starts
+	// and completes.
+	PollInterval time.Duration
+}
+
+// RunCommandAsync runs command with sh in workingDir as a job with the
+// environment environ, a list of key=value. It immediately returns an input
+// and output channel like tfclient.DefaultClient.RunCommandAsync, jobs don't
+// have stdin so the input is discarded.
+func (j *JobRunner) RunCommandAsync(ctx command.ProjectContext, command string, environ []string, workingDir string) (chan<- string, <-chan models.Line) {
+	outCh := make(chan models.Line)
+	inCh := make(chan string)
+	go func() {
+		for line := range inCh {
+			ctx.Log.Debug("discarding %q written to the stdin of a Kubernetes job", line)
+		}
+	}()
+	go func() {
+		defer func() {
+			close(outCh)
+			close(inCh)
+		}()
+		start := time.Now()
+		if err := j.run(ctx, command, environ, workingDir, outCh); err != nil {
+			err = fmt.Errorf("running '%s' in '%s' as a Kubernetes job: %w", command, workingDir, err)
+			ctx.Log.With("duration", time.Since(start)).Err(err.Error())
+			outCh <- models.Line{Err: err}
+			return
+		}
+		ctx.Log.With("duration", time.Since(start)).Info("successfully ran '%s' in '%s' as a Kubernetes job", command, workingDir)
+	}()
+	return inCh, outCh
+}
+
+func (j *JobRunner) run(ctx command.ProjectContext, command string, environ []string, workingDir string, outCh chan<- models.Line) error {
+	// The job is deleted when the pull request's commands are cancelled.
+	apiCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	untrack := models.RunningProcesses.AddRemote(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, cancel)
+	err := j.runJob(apiCtx, ctx, command, environ, workingDir, outCh)
+	if untrack() {
+		if j.OutputHandler != nil {
+			j.OutputHandler.Send(ctx, "Cancelled", false)
+		}
+		return fmt.Errorf("%w: the job was deleted", models.ErrCancelled)
+	}
+	return err
+}
+
+// runJob runs command as a job until it completes or apiCtx is cancelled, then
+// deletes the job.
+func (j *JobRunner) runJob(apiCtx context.Context, ctx command.ProjectContext, command string, environ []string, workingDir string, outCh chan<- models.Line) error {
+	// The environment may contain credentials so it's passed in a secret
+	// rather than in the manifest of the job.
+	var secret, job created
+	if err := j.Client.do(apiCtx, "POST", j.namespacePath("/api/v1", "secrets"), nil, j.secret(ctx, environ), &secret); err != nil {
+		return fmt.Errorf("creating the secret of the job: %w", err)
+	}
+	defer func() {
+		if err := j.delete(j.namespacePath("/api/v1", "secrets/"+secret.Metadata.Name)); err != nil {
+			ctx.Log.Warn("unable to delete Kubernetes secret %s: %s", secret.Metadata.Name, err)
+		}
+	}()
+	if err := j.Client.do(apiCtx, "POST", j.namespacePath("/apis/batch/v1", "jobs"), nil, j.job(ctx, command, secret.Metadata.Name, workingDir), &job); err != nil {
+		return fmt.Errorf("creating the job: %w", err)
+	}
+	jobName := job.Metadata.Name
+	ctx.Log.Info("created Kubernetes job %s/%s", j.Client.Namespace, jobName)
+	// Don't leave the job running or its pod in the cluster once it
+	// completed, failed or was cancelled.
+	defer func() {
+		if err := j.delete(j.namespacePath("/apis/batch/v1", "jobs/"+jobName)); err != nil {
+			ctx.Log.Warn("unable to delete Kubernetes job %s: %s", jobName, err)
+		}
+	}()
+	// The job owns the secret so the secret is garbage collected with the job,
+	// ex. if Atlantis restarts while the job runs.
+	owner := map[string]any{"metadata": map[string]any{"ownerReferences": []map[string]any{{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"name":       jobName,
+		"uid":        job.Metadata.UID,
+	}}}}
+	if err := j.Client.do(apiCtx, "PATCH", j.namespacePath("/api/v1", "secrets/"+secret.Metadata.Name), nil, owner, nil); err != nil {
+		ctx.Log.Warn("unable to make Kubernetes job %s the owner of secret %s: %s", jobName, secret.Metadata.Name, err)
+	}
+
+	exitCode, err := j.wait(apiCtx, ctx, jobName, outCh)
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("job %s exited with code %d", jobName, exitCode)
+	}
+	return nil
+}
+
+// created is the API's response to creating a resource.
+type created struct {
+	Metadata struct {
+		Name string `json:"name"`
+		UID  string `json:"uid"`
+	} `json:"metadata"`
+}
+
+// wait streams the logs of the pod of the job and returns its exit code once
+// it completed.
+func (j *JobRunner) wait(apiCtx context.Context, ctx command.ProjectContext, jobName string, outCh chan<- models.Line) (int, error) {
+	podName, err := j.waitForPod(apiCtx, jobName)
+	if err != nil {
+		return 0, err
+	}
+	if err := j.streamLogs(apiCtx, ctx, podName, outCh); err != nil && apiCtx.Err() == nil {
+		ctx.Log.Warn("unable to stream the logs of pod %s, waiting for it to complete: %s", podName, err)
+	}
+	return j.waitForExit(apiCtx, podName)
+}
+
+// secret returns the manifest of the secret holding environ, a list of
+// key=value.
+func (j *JobRunner) secret(ctx command.ProjectContext, environ []string) map[string]any {
+	data := map[string]string{}
+	for _, kv := range environ {
+		if name, value, ok := strings.Cut(kv, "="); ok {
+			data[name] = value
+		}
+	}
+	return map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"type":       "Opaque",
+		"metadata": map[string]any{
+			"generateName": j.generateName(ctx),
+			"labels":       labels,
+		},
+		"stringData": data,
+	}
+}
+
+// job returns the manifest of the job running command with the environment
+// in secretName.
+func (j *JobRunner) job(ctx command.ProjectContext, command string, secretName string, workingDir string) map[string]any {
+	podSpec := map[string]any{
+		"restartPolicy": "Never",
+		"containers": []map[string]any{{
+			"name":         containerName,
+			"image":        j.Config.Image,
+			"command":      []string{"sh", "-c", command},
+			"workingDir":   workingDir,
+			"envFrom":      []map[string]any{{"secretRef": map[string]string{"name": secretName}}},
+			"resources":    j.Config.Resources,
+			"volumeMounts": []map[string]any{{"name": dataVolume, "mountPath": j.Config.DataDir}},
+		}},
+		"volumes": []map[string]any{{
+			"name":                  dataVolume,
+			"persistentVolumeClaim": map[string]string{"claimName": j.Config.DataVolumeClaim},
+		}},
+	}
+	if j.Config.ServiceAccount != "" {
+		podSpec["serviceAccountName"] = j.Config.ServiceAccount
+	}
+	if len(j.Config.NodeSelector) > 0 {
+		podSpec["nodeSelector"] = j.Config.NodeSelector
+	}
+	return map[string]any{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata": map[string]any{
+			"generateName": j.generateName(ctx),
+			"labels":       labels,
+			"annotations": map[string]string{
+				"runatlantis.io/repo":    ctx.Pull.BaseRepo.FullName,
+				"runatlantis.io/pull":    strconv.Itoa(ctx.Pull.Num),
+				"runatlantis.io/project": ctx.ProjectName,
+				"runatlantis.io/dir":     ctx.RepoRelDir,
+			},
+		},
+		"spec": map[string]any{
+			// A failed command isn't retried.
+			"backoffLimit":            0,
+			"ttlSecondsAfterFinished": int(jobTTL.Seconds()),
+			"template": map[string]any{
+				"metadata": map[string]any{"labels": labels},
+				"spec":     podSpec,
+			},
+		},
+	}
+}
+
+// labels are the labels of the resources Atlantis creates.
+var labels = map[string]string{
+	"app.kubernetes.io/managed-by": "atlantis",
+	"app.kubernetes.io/component":  "terraform",
+}
+
+func (j *JobRunner) generateName(ctx command.ProjectContext) string {
+	return "atlantis-" + strings.ReplaceAll(ctx.CommandName.String(), "_", "-") + "-"
+}
+
+type pod struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Status struct {
+		Phase             string `json:"phase"`
+		Reason            string `json:"reason"`
+		Message           string `json:"message"`
+		ContainerStatuses []struct {
+			Name  string `json:"name"`
+			State struct {
+				Waiting *struct {
+					Reason  string `json:"reason"`
+					Message string `json:"message"`
+				} `json:"waiting"`
+				Terminated *struct {
+					ExitCode int    `json:"exitCode"`
+					Reason   string `json:"reason"`
+				} `json:"terminated"`
+			} `json:"state"`
+		} `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+// waitingError returns why the container of the pod can't start, if it can't.
+func (p pod) waitingError() error {
+	for _, status := range p.Status.ContainerStatuses {
+		waiting := status.State.Waiting
+		if waiting == nil {
+			continue
+		}
+		switch waiting.Reason {
+		case "ErrImagePull", "ImagePullBackOff", "InvalidImageName", "CreateContainerConfigError", "CreateContainerError":
+			return fmt.Errorf("pod %s can't start: %s: %s", p.Metadata.Name, waiting.Reason, waiting.Message)
+		}
+	}
+	return nil
+}
+
+// waitForPod waits for the pod of the job to start and returns its name.
+func (j *JobRunner) waitForPod(ctx context.Context, jobName string) (string, error) {
+	deadline := time.Now().Add(podStartTimeout)
+	for {
+		var pods struct {
+			Items []pod `json:"items"`
+		}
+		err := j.Client.do(ctx, "GET", j.namespacePath("/api/v1", "pods"), url.Values{"labelSelector": {"job-name=" + jobName}}, nil, &pods)
+		if err != nil {
+			return "", fmt.Errorf("listing the pods of job %s: %w", jobName, err)
+		}
+		for _, p := range pods.Items {
+			if err := p.waitingError(); err != nil {
+				return "", err
+			}
+			if p.Status.Phase != "Pending" {
+				return p.Metadata.Name, nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("the pod of job %s didn't start within %s", jobName, podStartTimeout)
+		}
+		if err := j.sleep(ctx); err != nil {
+			return "", err
+		}
+	}
+}
+
+// streamLogs sends the logs of the pod to outCh and the output of the project
+// until the pod completes.
+func (j *JobRunner) streamLogs(apiCtx context.Context, ctx command.ProjectContext, podName string, outCh chan<- models.Line) error {
+	resp, err := j.Client.request(apiCtx, "GET", j.namespacePath("/api/v1", "pods/"+podName+"/log"), url.Values{
+		"container": {containerName},
+		"follow":    {"true"},
+	}, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer([]byte{}, models.BufioScannerBufferSize)
+	for scanner.Scan() {
+		message := scanner.Text()
+		outCh <- models.Line{Line: message}
+		if j.OutputHandler != nil {
+			j.OutputHandler.Send(ctx, message, false)
+		}
+	}
+	return scanner.Err()
+}
+
+// waitForExit waits for the container of the pod to terminate and returns its
+// exit code.
+func (j *JobRunner) waitForExit(ctx context.Context, podName string) (int, error) {
+	for {
+		var p pod
+		if err := j.Client.do(ctx, "GET", j.namespacePath("/api/v1", "pods/"+podName), nil, nil, &p); err != nil {
+			return 0, fmt.Errorf("getting pod %s: %w", podName, err)
+		}
+		for _, status := range p.Status.ContainerStatuses {
+			if status.Name == containerName && status.State.Terminated != nil {
+				return status.State.Terminated.ExitCode, nil
+			}
+		}
+		if p.Status.Phase == "Failed" {
+			// ex. the pod was evicted before the container ran.
+			return 0, fmt.Errorf("pod %s failed: %s %s", podName, p.Status.Reason, p.Status.Message)
+		}
+		if err := j.sleep(ctx); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// sleep waits for the poll interval, returning early with an error if ctx is
+// cancelled.
+func (j *JobRunner) sleep(ctx context.Context) error {
+	timer := time.NewTimer(j.pollInterval())
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// delete deletes the resource at path and its dependents, ex. the pods of a
+// job. It isn't cancelled with the command so cancelled jobs are deleted.
+func (j *JobRunner) delete(path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), deleteTimeout)
+	defer cancel()
+	err := j.Client.do(ctx, "DELETE", path, url.Values{"propagationPolicy": {"Background"}}, nil, nil)
+	if err != nil && strings.Contains(err.Error(), "returned 404") {
+		// ex. the job's TTL expired.
+		return nil
+	}
+	return err
+}
+
+func (j *JobRunner) namespacePath(api string, resource string) string {
+	return fmt.Sprintf("%s/namespaces/%s/%s", api, url.PathEscape(j.Client.Namespace), resource)
+}
+
+func (j *JobRunner) pollInterval() time.Duration {
+	if j.PollInterval > 0 {
+		return j.PollInterval
+	}
+	return defaultPollInterval
+}
+
+// ParseResources parses the resources of a comma separated list of
+// <requests|limits>.<resource>=<quantity>, ex. requests.cpu=1,limits.memory=4Gi.
+func ParseResources(s string) (Resources, error) {
+	var resources Resources
+	for _, entry := range splitList(s) {
+		key, quantity, ok := strings.Cut(entry, "=")
+		kind, resource, kindOk := strings.Cut(key, ".")
+		if !ok || !kindOk || resource == "" || quantity == "" {
+			return Resources{}, fmt.Errorf("invalid resource %q, must be <requests|limits>.<resource>=<quantity>", entry)
+		}
+		switch kind {
+		case "requests":
+			if resources.Requests == nil {
+				resources.Requests = map[string]string{}
+			}
+			resources.Requests[resource] = quantity
+		case "limits":
+			if resources.Limits == nil {
+				resources.Limits = map[string]string{}
+			}
+			resources.Limits[resource] = quantity
+		default:
+			return Resources{}, fmt.Errorf("invalid resource %q, must be <requests|limits>.<resource>=<quantity>", entry)
+		}
+	}
+	return resources, nil
+}
+
+// ParseNodeSelector parses a comma separated list of <label>=<value>.
+func ParseNodeSelector(s string) (map[string]string, error) {
+	selector := map[string]string{}
+	for _, entry := range splitList(s) {
+		label, value, ok := strings.Cut(entry, "=")
+		if !ok || label == "" {
+			return nil, fmt.Errorf("invalid node selector %q, must be <label>=<value>", entry)
+		}
+		selector[label] = value
+	}
+	return selector, nil
+}
+
+func splitList(s string) []string {
+	var entries []string
+	for _, entry := range strings.Split(s, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
@@ -0,0 +1,254 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/kubernetes"
+	runtimemodels "github.com/runatlantis/atlantis/server/core/runtime/models"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// fakeAPI is a Kubernetes API server running the pod of each job it creates
+// with the given logs and exit code.
+type fakeAPI struct {
+	logs     string
+	exitCode int
+	waiting  string
+	// running keeps the pod running until the job is deleted.
+	running bool
+	// following is closed once the logs of a running pod are followed.
+	following chan struct{}
+
+	mutex   sync.Mutex
+	secret  map[string]any
+	owner   map[string]any
+	job     map[string]any
+	deleted []string
+	auth    string
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.auth = r.Header.Get("Authorization")
+	container := map[string]any{"name": "terraform", "state": map[string]any{"terminated": map[string]any{"exitCode": f.exitCode}}}
+	if f.running {
+		container["state"] = map[string]any{"running": map[string]any{}}
+	}
+	if f.waiting != "" {
+		container["state"] = map[string]any{"waiting": map[string]any{"reason": f.waiting, "message": "not found"}}
+	}
+	pod := map[string]any{
+		"metadata": map[string]any{"name": "atlantis-plan-abcde-xyz"},
+		"status":   map[string]any{"phase": "Running", "containerStatuses": []any{container}},
+	}
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/api/v1/namespaces/atlantis/secrets":
+		json.NewDecoder(r.Body).Decode(&f.secret) // nolint: errcheck
+		created := map[string]any{"metadata": map[string]any{"name": "atlantis-plan-fghij"}}
+		json.NewEncoder(w).Encode(created) // nolint: errcheck
+	case r.Method == "PATCH" && r.URL.Path == "/api/v1/namespaces/atlantis/secrets/atlantis-plan-fghij":
+		json.NewDecoder(r.Body).Decode(&f.owner) // nolint: errcheck
+		fmt.Fprint(w, "{}")                      // nolint: errcheck
+	case r.Method == "POST" && r.URL.Path == "/apis/batch/v1/namespaces/atlantis/jobs":
+		json.NewDecoder(r.Body).Decode(&f.job) // nolint: errcheck
+		created := map[string]any{"metadata": map[string]any{"name": "atlantis-plan-abcde", "uid": "1234"}}
+		json.NewEncoder(w).Encode(created) // nolint: errcheck
+	case r.Method == "DELETE":
+		f.deleted = append(f.deleted, path.Base(path.Dir(r.URL.Path))+"/"+path.Base(r.URL.Path))
+		f.running = false
+	case r.URL.Path == "/api/v1/namespaces/atlantis/pods" && r.URL.Query().Get("labelSelector") == "job-name=atlantis-plan-abcde":
+		json.NewEncoder(w).Encode(map[string]any{"items": []any{pod}}) // nolint: errcheck
+	case r.URL.Path == "/api/v1/namespaces/atlantis/pods/atlantis-plan-abcde-xyz/log" && r.URL.Query().Get("follow") == "true":
+		fmt.Fprint(w, f.logs) // nolint: errcheck
+		if f.running {
+			// Follow the logs until the request is cancelled.
+			w.(http.Flusher).Flush()
+			close(f.following)
+			f.mutex.Unlock()
+			<-r.Context().Done()
+			f.mutex.Lock()
+		}
+	case r.URL.Path == "/api/v1/namespaces/atlantis/pods/atlantis-plan-abcde-xyz":
+		json.NewEncoder(w).Encode(pod) // nolint: errcheck
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+func newJobRunner(t *testing.T, api *fakeAPI) *kubernetes.JobRunner {
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	Ok(t, os.WriteFile(tokenFile, []byte("token\n"), 0600))
+	resources, err := kubernetes.ParseResources("requests.cpu=1,limits.memory=4Gi")
+	Ok(t, err)
+	nodeSelector, err := kubernetes.ParseNodeSelector("pool=terraform")
+	Ok(t, err)
+	return &kubernetes.JobRunner{
+		Client: &kubernetes.Client{URL: server.URL, Namespace: "atlantis", TokenFile: tokenFile},
+		Config: kubernetes.JobConfig{
+			Image:           "ghcr.io/runatlantis/atlantis",
+			ServiceAccount:  "terraform",
+			Resources:       resources,
+			NodeSelector:    nodeSelector,
+			DataVolumeClaim: "atlantis-data",
+			DataDir:         "/atlantis-data",
+		},
+		PollInterval: time.Millisecond,
+	}
+}
+
+func run(t *testing.T, runner *kubernetes.JobRunner) (string, error) {
+	ctx := command.ProjectContext{
+		Log:         logging.NewNoopLogger(t),
+		CommandName: command.Plan,
+		ProjectName: "project",
+		RepoRelDir:  "dir",
+		Pull:        models.PullRequest{Num: 1, BaseRepo: models.Repo{FullName: "owner/repo"}},
+	}
+	_, outCh := runner.RunCommandAsync(ctx, "terraform plan", []string{"WORKSPACE=default"}, "/atlantis-data/repos/owner/repo/1/default/dir")
+	var lines []string
+	for line := range outCh {
+		if line.Err != nil {
+			return strings.Join(lines, "\n"), line.Err
+		}
+		lines = append(lines, line.Line)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func TestJobRunner_RunCommandAsync(t *testing.T) {
+	api := &fakeAPI{logs: "Planning...\nNo changes.\n"}
+	out, err := run(t, newJobRunner(t, api))
+	Ok(t, err)
+	Equals(t, "Planning...\nNo changes.", out)
+	Equals(t, "Bearer token", api.auth)
+
+	var job struct {
+		Metadata struct {
+			GenerateName string            `json:"generateName"`
+			Annotations  map[string]string `json:"annotations"`
+		} `json:"metadata"`
+		Spec struct {
+			BackoffLimit int `json:"backoffLimit"`
+			Template     struct {
+				Spec struct {
+					ServiceAccountName string            `json:"serviceAccountName"`
+					NodeSelector       map[string]string `json:"nodeSelector"`
+					Containers         []struct {
+						Image      string   `json:"image"`
+						Command    []string `json:"command"`
+						WorkingDir string   `json:"workingDir"`
+						EnvFrom    []struct {
+							SecretRef struct {
+								Name string `json:"name"`
+							} `json:"secretRef"`
+						} `json:"envFrom"`
+						Resources kubernetes.Resources `json:"resources"`
+					} `json:"containers"`
+					Volumes []struct {
+						PersistentVolumeClaim struct {
+							ClaimName string `json:"claimName"`
+						} `json:"persistentVolumeClaim"`
+					} `json:"volumes"`
+				} `json:"spec"`
+			} `json:"template"`
+		} `json:"spec"`
+	}
+	serialized, err := json.Marshal(api.job)
+	Ok(t, err)
+	Ok(t, json.Unmarshal(serialized, &job))
+	Equals(t, "atlantis-plan-", job.Metadata.GenerateName)
+	Equals(t, "owner/repo", job.Metadata.Annotations["runatlantis.io/repo"])
+	Equals(t, 0, job.Spec.BackoffLimit)
+	podSpec := job.Spec.Template.Spec
+	Equals(t, "terraform", podSpec.ServiceAccountName)
+	Equals(t, map[string]string{"pool": "terraform"}, podSpec.NodeSelector)
+	Equals(t, "atlantis-data", podSpec.Volumes[0].PersistentVolumeClaim.ClaimName)
+	container := podSpec.Containers[0]
+	Equals(t, "ghcr.io/runatlantis/atlantis", container.Image)
+	Equals(t, []string{"sh", "-c", "terraform plan"}, container.Command)
+	Equals(t, "/atlantis-data/repos/owner/repo/1/default/dir", container.WorkingDir)
+	Equals(t, "atlantis-plan-fghij", container.EnvFrom[0].SecretRef.Name)
+	Equals(t, kubernetes.Resources{Requests: map[string]string{"cpu": "1"}, Limits: map[string]string{"memory": "4Gi"}}, container.Resources)
+
+	// The environment is in the secret, owned by the job.
+	Equals(t, map[string]any{"WORKSPACE": "default"}, api.secret["stringData"])
+	Equals(t, "atlantis-plan-", api.secret["metadata"].(map[string]any)["generateName"])
+	owner := api.owner["metadata"].(map[string]any)["ownerReferences"].([]any)[0].(map[string]any)
+	Equals(t, "atlantis-plan-abcde", owner["name"])
+	Equals(t, "1234", owner["uid"])
+	// Both are deleted once the job completed.
+	Equals(t, []string{"jobs/atlantis-plan-abcde", "secrets/atlantis-plan-fghij"}, api.deleted)
+}
+
+func TestJobRunner_RunCommandAsync_ExitCode(t *testing.T) {
+	api := &fakeAPI{logs: "Error: invalid\n", exitCode: 1}
+	out, err := run(t, newJobRunner(t, api))
+	ErrContains(t, "job atlantis-plan-abcde exited with code 1", err)
+	Equals(t, "Error: invalid", out)
+}
+
+func TestJobRunner_RunCommandAsync_CantStart(t *testing.T) {
+	api := &fakeAPI{waiting: "ErrImagePull"}
+	_, err := run(t, newJobRunner(t, api))
+	ErrContains(t, "pod atlantis-plan-abcde-xyz can't start: ErrImagePull: not found", err)
+	Equals(t, []string{"jobs/atlantis-plan-abcde", "secrets/atlantis-plan-fghij"}, api.deleted)
+}
+
+func TestJobRunner_RunCommandAsync_Cancelled(t *testing.T) {
+	api := &fakeAPI{logs: "Planning...\n", running: true, following: make(chan struct{})}
+	runner := newJobRunner(t, api)
+	errCh := make(chan error)
+	go func() {
+		_, err := run(t, runner)
+		errCh <- err
+	}()
+	// Cancel the pull request's commands once the job streams its logs.
+	<-api.following
+	Equals(t, 1, runtimemodels.RunningProcesses.Interrupt("owner/repo", 1, time.Minute))
+	err := <-errCh
+	Assert(t, errors.Is(err, runtimemodels.ErrCancelled), "exp the command to be cancelled, got %s", err)
+	Equals(t, []string{"jobs/atlantis-plan-abcde", "secrets/atlantis-plan-fghij"}, api.deleted)
+}
+
+func TestParseResources(t *testing.T) {
+	resources, err := kubernetes.ParseResources(" requests.cpu=500m, requests.memory=1Gi,limits.memory=2Gi ")
+	Ok(t, err)
+	Equals(t, kubernetes.Resources{
+		Requests: map[string]string{"cpu": "500m", "memory": "1Gi"},
+		Limits:   map[string]string{"memory": "2Gi"},
+	}, resources)
+
+	for _, invalid := range []string{"cpu=1", "requests.cpu", "requests.=1", "maximum.cpu=1"} {
+		_, err := kubernetes.ParseResources(invalid)
+		ErrContains(t, "must be <requests|limits>.<resource>=<quantity>", err)
+	}
+}
+
+func TestParseNodeSelector(t *testing.T) {
+	selector, err := kubernetes.ParseNodeSelector("pool=terraform,kubernetes.io/arch=amd64")
+	Ok(t, err)
+	Equals(t, map[string]string{"pool": "terraform", "kubernetes.io/arch": "amd64"}, selector)
+
+	_, err = kubernetes.ParseNodeSelector("pool")
+	ErrContains(t, "must be <label>=<value>", err)
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package logsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/secrets"
+)
+
+// CloudLogging ships entries to a log of Google Cloud Logging. Entries are
+// written as JSON payloads of the global resource, labeled with the stream
+// they're from and the host they're from.
+type CloudLogging struct {
+	// LogName is the log, projects/<project>/logs/<log>.
+	LogName string
+	// APIURL is the URL of the Cloud Logging API.
+	APIURL string
+	*secrets.GCPTokenSource
+}
+
+func (c *CloudLogging) String() string {
+	return "cloud-logging"
+}
+
+func (c *CloudLogging) Ship(ctx context.Context, entries []Entry) error {
+	type logEntry struct {
+		Timestamp   string            `json:"timestamp"`
+		Severity    string            `json:"severity"`
+		JSONPayload json.RawMessage   `json:"jsonPayload"`
+		Labels      map[string]string `json:"labels"`
+	}
+	host := hostname()
+	logEntries := make([]logEntry, 0, len(entries))
+	for _, entry := range entries {
+		logEntries = append(logEntries, logEntry{
+			Timestamp:   entry.Time.UTC().Format(time.RFC3339Nano),
+			Severity:    severity(entry.Line),
+			JSONPayload: entry.Line,
+			Labels:      map[string]string{"stream": entry.Stream, "host": host},
+		})
+	}
+	body, err := json.Marshal(map[string]any{
+		"logName":  c.LogName,
+		"resource": map[string]string{"type": "global"},
+		"entries":  logEntries,
+	})
+	if err != nil {
+		return err
+	}
+
+	token, err := c.Token()
+	if err != nil {
+		return fmt.Errorf("getting access token: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", strings.TrimSuffix(c.APIURL, "/")+"/v2/entries:write", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	_, err = do(req)
+	return err
+}
+
+// severity returns the Cloud Logging severity of the level of line.
+func severity(line []byte) string {
+	var entry struct {
+		Level string `json:"level"`
+	}
+	_ = json.Unmarshal(line, &entry)
+	switch entry.Level {
+	case "debug":
+		return "DEBUG"
+	case "info":
+		return "INFO"
+	case "warn":
+		return "WARNING"
+	case "error":
+		return "ERROR"
+	case "dpanic", "panic", "fatal":
+		return "CRITICAL"
+	default:
+		return "DEFAULT"
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package logsink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/smithy-go"
+)
+
+// maxCloudWatchBatchSize is the maximum size of the events of a PutLogEvents
+// call, each event counts for its message and 26 bytes.
+const maxCloudWatchBatchSize = 1 << 20
+
+// CloudWatch ships entries to a log group of Amazon CloudWatch Logs. The log
+// group must exist, its log streams are created as needed. The entries of
+// StreamJobs are shipped to a separate log stream whose name is suffixed by
+// /jobs.
+type CloudWatch struct {
+	LogGroup  string
+	LogStream string
+	// Client calls the CloudWatch Logs API.
+	Client *cloudwatchlogs.Client
+
+	// createdStreams are the log streams that were created. Ship is only
+	// called by the shipper so it isn't guarded.
+	createdStreams map[string]bool
+}
+
+// NewCloudWatchFromEnv returns a CloudWatch shipping to logGroup,
+// authenticated with the credentials of the AWS SDK's default chain, ex. the
+// AWS_* environment variables, shared config files, web identity tokens or
+// the ECS task and EC2 instance roles, which are refreshed when they expire.
+// The log stream defaults to the hostname, the region to the SDK's, ex.
+// AWS_REGION, and AWS_ENDPOINT_URL_CLOUDWATCH_LOGS overrides the endpoint.
+func NewCloudWatchFromEnv(ctx context.Context, logGroup string, logStream string, region string) (*CloudWatch, error) {
+	if logStream == "" {
+		logStream = hostname()
+	}
+	var opts []func(*config.LoadOptions) error
+	if region != "" {
+		opts = append(opts, config.WithRegion(region))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS config: %w", err)
+	}
+	return &CloudWatch{
+		LogGroup:  logGroup,
+		LogStream: logStream,
+		Client:    cloudwatchlogs.NewFromConfig(cfg),
+	}, nil
+}
+
+func (c *CloudWatch) String() string {
+	return "cloudwatch"
+}
+
+func (c *CloudWatch) Ship(ctx context.Context, entries []Entry) error {
+	if c.Client.Options().Region == "" {
+		return permanentError{errors.New("AWS_REGION must be set to ship logs to CloudWatch")}
+	}
+	var streams []string
+	events := make(map[string][]types.InputLogEvent)
+	for _, entry := range entries {
+		stream := c.LogStream
+		if entry.Stream == StreamJobs {
+			stream += "/jobs"
+		}
+		if _, ok := events[stream]; !ok {
+			streams = append(streams, stream)
+		}
+		events[stream] = append(events[stream], types.InputLogEvent{
+			Timestamp: aws.Int64(entry.Time.UnixMilli()),
+			Message:   aws.String(string(entry.Line)),
+		})
+	}
+
+	for _, stream := range streams {
+		if err := c.createStream(ctx, stream); err != nil {
+			return err
+		}
+		streamEvents := events[stream]
+		for len(streamEvents) > 0 {
+			n, size := 0, 0
+			for ; n < len(streamEvents); n++ {
+				size += len(*streamEvents[n].Message) + 26
+				if size > maxCloudWatchBatchSize && n > 0 {
+					break
+				}
+			}
+			_, err := c.Client.PutLogEvents(ctx, &cloudwatchlogs.PutLogEventsInput{
+				LogGroupName:  aws.String(c.LogGroup),
+				LogStreamName: aws.String(stream),
+				LogEvents:     streamEvents[:n],
+			})
+			if err != nil {
+				var notFound *types.ResourceNotFoundException
+				if errors.As(err, &notFound) {
+					// The log stream was deleted, it's created again for the
+					// next batch.
+					delete(c.createdStreams, stream)
+				}
+				return fmt.Errorf("putting log events in %s/%s: %w", c.LogGroup, stream, cloudWatchError(err))
+			}
+			streamEvents = streamEvents[n:]
+		}
+	}
+	return nil
+}
+
+// createStream creates the log stream if it wasn't already.
+func (c *CloudWatch) createStream(ctx context.Context, stream string) error {
+	if c.createdStreams[stream] {
+		return nil
+	}
+	_, err := c.Client.CreateLogStream(ctx, &cloudwatchlogs.CreateLogStreamInput{
+		LogGroupName:  aws.String(c.LogGroup),
+		LogStreamName: aws.String(stream),
+	})
+	var exists *types.ResourceAlreadyExistsException
+	if err != nil && !errors.As(err, &exists) {
+		return fmt.Errorf("creating log stream %s/%s: %w", c.LogGroup, stream, cloudWatchError(err))
+	}
+	if c.createdStreams == nil {
+		c.createdStreams = make(map[string]bool)
+	}
+	c.createdStreams[stream] = true
+	return nil
+}
+
+// cloudWatchError returns err as a permanentError if the request was rejected
+// and retrying it won't help, ex. because it's unauthorized. The SDK already
+// retried the throttled requests.
+func cloudWatchError(err error) error {
+	var respErr *awshttp.ResponseError
+	if !errors.As(err, &respErr) || respErr.HTTPStatusCode()/100 != 4 || respErr.HTTPStatusCode() == http.StatusTooManyRequests {
+		return err
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "ThrottlingException" {
+		return err
+	}
+	return permanentError{err}
+}
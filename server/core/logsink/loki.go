@@ -0,0 +1,65 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package logsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// Loki ships entries to Grafana Loki. Entries are labeled with
+// service="atlantis", the stream they're from and the host they're from.
+type Loki struct {
+	// URL is the base URL of Loki, the push API is under it.
+	URL string
+	// Username and Password authenticate with basic auth if Username is set.
+	Username string
+	Password string
+	// TenantID is the tenant of the entries in multi-tenant installs.
+	TenantID string
+}
+
+func (l *Loki) String() string {
+	return "loki"
+}
+
+func (l *Loki) Ship(ctx context.Context, entries []Entry) error {
+	type stream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+	var streams []*stream
+	byName := make(map[string]*stream)
+	host := hostname()
+	for _, entry := range entries {
+		s, ok := byName[entry.Stream]
+		if !ok {
+			s = &stream{Stream: map[string]string{"service": "atlantis", "stream": entry.Stream, "host": host}}
+			byName[entry.Stream] = s
+			streams = append(streams, s)
+		}
+		s.Values = append(s.Values, [2]string{strconv.FormatInt(entry.Time.UnixNano(), 10), string(entry.Line)})
+	}
+	body, err := json.Marshal(map[string]any{"streams": streams})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", l.URL+"/loki/api/v1/push", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if l.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", l.TenantID)
+	}
+	if l.Username != "" {
+		req.SetBasicAuth(l.Username, l.Password)
+	}
+	_, err = do(req)
+	return err
+}
@@ -0,0 +1,197 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package logsink ships logs directly to log services, ex. Grafana Loki, for
+// installs without a log agent collecting the logs Atlantis writes to stdout.
+package logsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// StreamServer is the stream of the logs of the server.
+	StreamServer = "server"
+	// StreamJobs is the stream of the terraform output of jobs.
+	StreamJobs = "jobs"
+
+	defaultBufferSize    = 10000
+	defaultBatchSize     = 500
+	defaultBatchInterval = 2 * time.Second
+	defaultRetryBackoff  = time.Second
+	maxRetries           = 3
+	flushTimeout         = 5 * time.Second
+)
+
+// Entry is a log entry.
+type Entry struct {
+	Time time.Time
+	// Stream is the stream of the entry, StreamServer or StreamJobs.
+	Stream string
+	// Line is the entry, a JSON object.
+	Line []byte
+}
+
+// Sink ships log entries to a log service.
+type Sink interface {
+	// Ship ships a batch of entries, in the order they were logged.
+	Ship(ctx context.Context, entries []Entry) error
+	// String names the sink in errors.
+	String() string
+}
+
+// Shipper batches log entries and ships them to a sink in the background.
+// Logging never blocks on the sink: entries are buffered, and dropped if the
+// buffer is full because the sink can't keep up or is down, in which case the
+// number of entries that were dropped is logged to the sink once it recovers.
+type Shipper struct {
+	sink    Sink
+	entries chan Entry
+	flushes chan chan struct{}
+	dropped atomic.Int64
+
+	batchSize     int
+	batchInterval time.Duration
+	retryBackoff  time.Duration
+	// errs is where errors shipping entries are written since they can't be
+	// logged to the logger being shipped.
+	errs io.Writer
+}
+
+// NewShipper returns a Shipper shipping to sink and starts shipping.
+func NewShipper(sink Sink) *Shipper {
+	s := newShipper(sink, defaultBufferSize, defaultBatchSize, defaultBatchInterval, defaultRetryBackoff, os.Stderr)
+	go s.run()
+	return s
+}
+
+func newShipper(sink Sink, bufferSize int, batchSize int, batchInterval time.Duration, retryBackoff time.Duration, errs io.Writer) *Shipper {
+	return &Shipper{
+		sink:          sink,
+		entries:       make(chan Entry, bufferSize),
+		flushes:       make(chan chan struct{}),
+		batchSize:     batchSize,
+		batchInterval: batchInterval,
+		retryBackoff:  retryBackoff,
+		errs:          errs,
+	}
+}
+
+// Write ships a JSON log entry of the server, so the Shipper can be written
+// to by loggers.
+func (s *Shipper) Write(p []byte) (int, error) {
+	// Loggers reuse their buffers once Write returns.
+	line := bytes.TrimSpace(append([]byte{}, p...))
+	if len(line) > 0 {
+		s.Send(Entry{Time: time.Now(), Stream: StreamServer, Line: line})
+	}
+	return len(p), nil
+}
+
+// Send ships entry, or drops it if the buffer is full.
+func (s *Shipper) Send(entry Entry) {
+	select {
+	case s.entries <- entry:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// Sync ships the buffered entries.
+func (s *Shipper) Sync() error {
+	done := make(chan struct{})
+	timeout := time.NewTimer(flushTimeout)
+	defer timeout.Stop()
+	select {
+	case s.flushes <- done:
+	case <-timeout.C:
+		return fmt.Errorf("timed out flushing logs to %s", s.sink)
+	}
+	select {
+	case <-done:
+		return nil
+	case <-timeout.C:
+		return fmt.Errorf("timed out flushing logs to %s", s.sink)
+	}
+}
+
+func (s *Shipper) run() {
+	ticker := time.NewTicker(s.batchInterval)
+	defer ticker.Stop()
+	var batch []Entry
+	for {
+		select {
+		case entry := <-s.entries:
+			batch = append(batch, entry)
+			if len(batch) >= s.batchSize {
+				s.ship(batch)
+				batch = nil
+			}
+		case <-ticker.C:
+			s.ship(batch)
+			batch = nil
+		case done := <-s.flushes:
+			for buffered := len(s.entries); buffered > 0; buffered-- {
+				batch = append(batch, <-s.entries)
+			}
+			s.ship(batch)
+			batch = nil
+			close(done)
+		}
+	}
+}
+
+// ship ships entries in batches, retrying with backoff. Batches that still
+// fail are dropped.
+func (s *Shipper) ship(entries []Entry) {
+	if dropped := s.dropped.Swap(0); dropped > 0 {
+		entries = append(entries, droppedEntry(dropped))
+	}
+	for len(entries) > 0 {
+		batch := entries[:min(len(entries), s.batchSize)]
+		entries = entries[len(batch):]
+
+		var err error
+		for attempt := 0; attempt <= maxRetries; attempt++ {
+			if attempt > 0 {
+				time.Sleep(s.retryBackoff << (attempt - 1))
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), httpTimeout)
+			err = s.sink.Ship(ctx, batch)
+			cancel()
+			var permanent permanentError
+			if err == nil || errors.As(err, &permanent) {
+				break
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(s.errs, "dropping %d log entries, shipping them to %s failed: %s\n", len(batch), s.sink, err) // nolint: errcheck
+			s.dropped.Add(int64(len(batch)))
+		}
+	}
+}
+
+// droppedEntry is the entry reporting that entries were dropped.
+func droppedEntry(dropped int64) Entry {
+	now := time.Now()
+	line, _ := json.Marshal(map[string]any{
+		"level": "warn",
+		"ts":    now.UTC().Format("2006-01-02T15:04:05.000Z0700"),
+		"msg":   fmt.Sprintf("dropped %d log entries because the log sink was unavailable or couldn't keep up", dropped),
+	})
+	return Entry{Time: now, Stream: StreamServer, Line: line}
+}
+
+// permanentError is the error of a request that won't succeed if it's
+// retried, ex. because it's unauthorized.
+type permanentError struct {
+	error
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package logsink
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// fakeSink records the batches it ships, failing the first failures times.
+type fakeSink struct {
+	mutex    sync.Mutex
+	batches  [][]Entry
+	calls    int
+	failures int
+	err      error
+}
+
+func (f *fakeSink) String() string {
+	return "fake"
+}
+
+func (f *fakeSink) Ship(_ context.Context, entries []Entry) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.calls++
+	if f.calls <= f.failures {
+		return f.err
+	}
+	f.batches = append(f.batches, append([]Entry{}, entries...))
+	return nil
+}
+
+func (f *fakeSink) lines() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	var lines []string
+	for _, batch := range f.batches {
+		for _, entry := range batch {
+			lines = append(lines, string(entry.Line))
+		}
+	}
+	return lines
+}
+
+func TestShipper_Batches(t *testing.T) {
+	sink := &fakeSink{}
+	s := newShipper(sink, 10, 2, time.Hour, 0, &bytes.Buffer{})
+	go s.run()
+
+	buf := []byte(`{"msg":"one"}` + "\n")
+	_, err := s.Write(buf)
+	Ok(t, err)
+	// Loggers reuse their buffers.
+	copy(buf, `{"msg":"two"}`)
+	_, err = s.Write(buf)
+	Ok(t, err)
+	s.Send(Entry{Time: time.Now(), Stream: StreamJobs, Line: []byte(`{"line":"three"}`)})
+
+	Ok(t, s.Sync())
+	Equals(t, []string{`{"msg":"one"}`, `{"msg":"two"}`, `{"line":"three"}`}, sink.lines())
+	Equals(t, 2, len(sink.batches))
+	Equals(t, StreamServer, sink.batches[0][0].Stream)
+	Equals(t, StreamJobs, sink.batches[1][0].Stream)
+}
+
+func TestShipper_DropsWhenFull(t *testing.T) {
+	sink := &fakeSink{}
+	// The shipper isn't running so the buffer fills up.
+	s := newShipper(sink, 1, 10, time.Hour, 0, &bytes.Buffer{})
+	s.Send(Entry{Line: []byte(`{"msg":"one"}`)})
+	s.Send(Entry{Line: []byte(`{"msg":"two"}`)})
+	s.Send(Entry{Line: []byte(`{"msg":"three"}`)})
+
+	go s.run()
+	Ok(t, s.Sync())
+	lines := sink.lines()
+	Equals(t, 2, len(lines))
+	Equals(t, `{"msg":"one"}`, lines[0])
+	Assert(t, strings.Contains(lines[1], "dropped 2 log entries"), "exp dropped entries to be reported, got %s", lines[1])
+}
+
+func TestShipper_Retries(t *testing.T) {
+	sink := &fakeSink{failures: 2, err: errors.New("unexpected status 503 Service Unavailable")}
+	errs := &bytes.Buffer{}
+	s := newShipper(sink, 10, 10, time.Hour, time.Millisecond, errs)
+	go s.run()
+
+	s.Send(Entry{Line: []byte(`{"msg":"one"}`)})
+	Ok(t, s.Sync())
+	Equals(t, []string{`{"msg":"one"}`}, sink.lines())
+	Equals(t, "", errs.String())
+}
+
+func TestShipper_PermanentErrors(t *testing.T) {
+	sink := &fakeSink{failures: 1, err: permanentError{errors.New("unexpected status 401 Unauthorized")}}
+	errs := &bytes.Buffer{}
+	s := newShipper(sink, 10, 10, time.Hour, time.Millisecond, errs)
+	go s.run()
+
+	s.Send(Entry{Line: []byte(`{"msg":"one"}`)})
+	Ok(t, s.Sync())
+	Equals(t, 1, sink.calls)
+	Equals(t, "dropping 1 log entries, shipping them to fake failed: unexpected status 401 Unauthorized\n", errs.String())
+
+	// The dropped entry is reported once the sink recovers.
+	Ok(t, s.Sync())
+	lines := sink.lines()
+	Equals(t, 1, len(lines))
+	Assert(t, strings.Contains(lines[0], "dropped 1 log entries"), "exp dropped entries to be reported, got %s", lines[0])
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package logsink
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/secrets"
+)
+
+const httpTimeout = 30 * time.Second
+
+var httpClient = &http.Client{Timeout: httpTimeout}
+
+// Parse parses a sink from its URL, one of:
+//   - loki+https://[<user>:<password>@]<host>[/<path>][?tenant=<tenant>] for
+//     Grafana Loki, or loki+http:// without TLS.
+//   - cloudwatch://<log group>[?stream=<log stream>&region=<region>] for
+//     Amazon CloudWatch Logs, authenticated by the AWS SDK's default
+//     credential chain. The log stream defaults to the hostname.
+//   - cloud-logging://projects/<project>/logs/<log> for Google Cloud Logging,
+//     authenticated like the Secret Manager.
+func Parse(sinkURL string) (Sink, error) {
+	scheme, _, ok := strings.Cut(sinkURL, "://")
+	if !ok {
+		return nil, fmt.Errorf("log sink %q must be a URL", sinkURL)
+	}
+	// The URL isn't included in errors since it can contain a password.
+	u, err := url.Parse(sinkURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s log sink URL", scheme)
+	}
+	switch scheme {
+	case "loki+http", "loki+https":
+		loki := &Loki{
+			URL:      strings.TrimPrefix(u.Scheme, "loki+") + "://" + u.Host + strings.TrimSuffix(u.Path, "/"),
+			TenantID: u.Query().Get("tenant"),
+		}
+		if u.User != nil {
+			loki.Username = u.User.Username()
+			loki.Password, _ = u.User.Password()
+		}
+		return loki, nil
+	case "cloudwatch":
+		group := u.Host + u.Path
+		if group == "" {
+			return nil, fmt.Errorf("cloudwatch log sink must be cloudwatch://<log group>")
+		}
+		return NewCloudWatchFromEnv(context.Background(), group, u.Query().Get("stream"), u.Query().Get("region"))
+	case "cloud-logging":
+		logName := u.Host + u.Path
+		if parts := strings.Split(logName, "/"); len(parts) != 4 || parts[0] != "projects" || parts[2] != "logs" {
+			return nil, fmt.Errorf("cloud-logging log sink must be cloud-logging://projects/<project>/logs/<log>")
+		}
+		return &CloudLogging{
+			LogName:        logName,
+			APIURL:         "https://logging.googleapis.com",
+			GCPTokenSource: secrets.NewGCPTokenSourceFromEnv(),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported log sink %q, must be loki+https, loki+http, cloudwatch or cloud-logging", scheme)
+	}
+}
+
+// ParseList parses a comma separated list of sink URLs.
+func ParseList(sinkURLs string) ([]Sink, error) {
+	var sinks []Sink
+	for _, sinkURL := range strings.Split(sinkURLs, ",") {
+		if sinkURL = strings.TrimSpace(sinkURL); sinkURL == "" {
+			continue
+		}
+		sink, err := Parse(sinkURL)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+// do sends req, and returns the response body if it succeeds. Client errors,
+// except for rate limiting, are permanent.
+func do(req *http.Request) ([]byte, error) {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 == 2 {
+		return respBody, nil
+	}
+	err = fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests {
+		return respBody, permanentError{err}
+	}
+	return respBody, err
+}
+
+// hostname returns the hostname, used to tell apart the logs of Atlantis
+// servers.
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil || name == "" {
+		return "atlantis"
+	}
+	return name
+}
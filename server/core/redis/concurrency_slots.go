@@ -0,0 +1,82 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// concurrencySlotTTL is how long the slot of a server that crashed while
+// holding it is kept. It's refreshed while it's held.
+const concurrencySlotTTL = time.Minute
+
+// acquireSlotScript adds the holder ARGV[1] to the sorted set of the slots of
+// a limit, scored by when it expires, unless ARGV[3] slots are already held.
+// The slots that expired are removed first.
+var acquireSlotScript = redis.NewScript(`
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", ARGV[2])
+if redis.call("ZCARD", KEYS[1]) >= tonumber(ARGV[3]) then
+	return 0
+end
+redis.call("ZADD", KEYS[1], ARGV[4], ARGV[1])
+redis.call("PEXPIRE", KEYS[1], ARGV[5])
+return 1
+`)
+
+// ConcurrencySlots are the slots of concurrency limits shared by the servers.
+// A slot expires after concurrencySlotTTL if the server holding it crashes and
+// is refreshed while it's held.
+type ConcurrencySlots struct {
+	client *redis.Client
+	holder string
+}
+
+// ConcurrencySlots returns the slots of concurrency limits of the server named
+// holder.
+func (r *RedisDB) ConcurrencySlots(holder string) *ConcurrencySlots {
+	return &ConcurrencySlots{client: r.client, holder: holder}
+}
+
+// TryAcquireSlot takes one of the limit slots of key unless they're all held.
+func (s *ConcurrencySlots) TryAcquireSlot(key string, limit int) (func(), bool, error) {
+	setKey := "atlantis:slots:" + key
+	random := make([]byte, 8)
+	rand.Read(random) // nolint: errcheck
+	token := s.holder + "/" + hex.EncodeToString(random)
+	now := time.Now()
+	acquired, err := acquireSlotScript.Run(ctx, s.client, []string{setKey},
+		token, now.UnixMilli(), limit, now.Add(concurrencySlotTTL).UnixMilli(), concurrencySlotTTL.Milliseconds()).Int()
+	if err != nil {
+		return nil, false, fmt.Errorf("acquiring a slot of %s: %w", key, err)
+	}
+	if acquired == 0 {
+		return nil, false, nil
+	}
+
+	refreshed, stopRefreshing := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(concurrencySlotTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-refreshed.Done():
+				return
+			case <-ticker.C:
+				expires := time.Now().Add(concurrencySlotTTL)
+				s.client.ZAddXX(refreshed, setKey, redis.Z{Score: float64(expires.UnixMilli()), Member: token}) // nolint: errcheck
+				s.client.PExpire(refreshed, setKey, concurrencySlotTTL)                                         // nolint: errcheck
+			}
+		}
+	}()
+	return func() {
+		stopRefreshing()
+		s.client.ZRem(ctx, setKey, token) // nolint: errcheck
+	}, true, nil
+}
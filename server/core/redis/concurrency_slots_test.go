@@ -0,0 +1,46 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package redis_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestConcurrencySlots(t *testing.T) {
+	s := miniredis.RunT(t)
+	r := newTestRedis(s)
+	worker1 := r.ConcurrencySlots("worker-1")
+	worker2 := r.ConcurrencySlots("worker-2")
+
+	release1, acquired, err := worker1.TryAcquireSlot("group prod apply", 2)
+	Ok(t, err)
+	Assert(t, acquired, "exp the first slot to be acquired")
+	_, acquired, err = worker2.TryAcquireSlot("group prod apply", 2)
+	Ok(t, err)
+	Assert(t, acquired, "exp the second slot to be acquired")
+
+	t.Log("the slots are shared by the servers")
+	_, acquired, err = worker2.TryAcquireSlot("group prod apply", 2)
+	Ok(t, err)
+	Assert(t, !acquired, "exp the slots to be full")
+	_, acquired, err = worker2.TryAcquireSlot("group staging", 2)
+	Ok(t, err)
+	Assert(t, acquired, "exp the slots of other limits to be available")
+
+	release1()
+	_, acquired, err = worker2.TryAcquireSlot("group prod apply", 2)
+	Ok(t, err)
+	Assert(t, acquired, "exp the released slot to be acquired")
+
+	t.Log("the slots of servers that crashed expire")
+	_, err = s.ZAdd("atlantis:slots:group dev", float64(time.Now().Add(-time.Second).UnixMilli()), "worker-3/crashed")
+	Ok(t, err)
+	_, acquired, err = worker1.TryAcquireSlot("group dev", 1)
+	Ok(t, err)
+	Assert(t, acquired, "exp the expired slot to be acquired")
+}
@@ -0,0 +1,64 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/runatlantis/atlantis/server/jobs"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// jobOutputChannel is the channel the workers publish the output of the jobs
+// they run to.
+const jobOutputChannel = "atlantis:jobs:output"
+
+// JobOutput relays the output of the jobs the workers run to the frontends,
+// which serve it in the UI. Frontends only receive the output published while
+// they're subscribed.
+type JobOutput struct {
+	client *redis.Client
+	logger logging.SimpleLogging
+}
+
+// JobOutput returns the job output relay of the database.
+func (r *RedisDB) JobOutput(logger logging.SimpleLogging) *JobOutput {
+	return &JobOutput{client: r.client, logger: logger}
+}
+
+// Publish publishes line to the subscribed frontends.
+func (o *JobOutput) Publish(line *jobs.ProjectCmdOutputLine) {
+	serialized, err := json.Marshal(line)
+	if err == nil {
+		err = o.client.Publish(ctx, jobOutputChannel, serialized).Err()
+	}
+	if err != nil {
+		o.logger.Warn("unable to publish the output of job %s: %s", line.JobID, err)
+	}
+}
+
+// Subscribe passes the published lines to receive until c is done.
+func (o *JobOutput) Subscribe(c context.Context, receive func(line *jobs.ProjectCmdOutputLine)) {
+	subscription := o.client.Subscribe(c, jobOutputChannel)
+	defer subscription.Close() // nolint: errcheck
+	messages := subscription.Channel()
+	for {
+		select {
+		case <-c.Done():
+			return
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+			var line jobs.ProjectCmdOutputLine
+			if err := json.Unmarshal([]byte(message.Payload), &line); err != nil {
+				o.logger.Warn("dropping invalid job output: %s", err)
+				continue
+			}
+			receive(&line)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package redis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/runatlantis/atlantis/server/jobs"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestJobOutput(t *testing.T) {
+	s := miniredis.RunT(t)
+	r := newTestRedis(s)
+	output := r.JobOutput(logging.NewNoopLogger(t))
+	ctx, cancel := context.WithCancel(context.Background())
+	received := make(chan *jobs.ProjectCmdOutputLine, 10)
+	stopped := make(chan struct{})
+	go func() {
+		output.Subscribe(ctx, func(line *jobs.ProjectCmdOutputLine) { received <- line })
+		close(stopped)
+	}()
+
+	// Lines published before the frontend subscribed aren't received, so
+	// publish until one is.
+	line := &jobs.ProjectCmdOutputLine{JobID: "1234", JobInfo: jobs.JobInfo{HeadCommit: "abc"}, Line: "Plan: 1 to add"}
+	var got *jobs.ProjectCmdOutputLine
+	for got == nil {
+		output.Publish(line)
+		select {
+		case got = <-received:
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	Equals(t, line, got)
+
+	cancel()
+	<-stopped
+}
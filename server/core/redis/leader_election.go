@@ -0,0 +1,150 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+const (
+	// leaderKey holds the ID of the active instance, expiring with its lease.
+	leaderKey = "atlantis:leader"
+	// LeaderLease is how long the active instance stays active without
+	// renewing its lease, ex. after crashing or losing the database, before
+	// a standby is promoted.
+	LeaderLease = 15 * time.Second
+)
+
+// renewLeaderScript extends the lease of the leader only if it's still held
+// by the same instance, in case it expired and a standby was promoted.
+var renewLeaderScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// resignLeaderScript deletes the lease of the leader only if it's still held
+// by the same instance.
+var resignLeaderScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// LeaderElection elects the active instance among the instances sharing the
+// database, the others standing by. The active instance holds a lease it
+// renews, once lapsed a standby is promoted.
+type LeaderElection struct {
+	client *redis.Client
+	// id identifies the instance in the lease.
+	id     string
+	logger logging.SimpleLogging
+	// OnPromoted, if set, is run in the background each time the instance is
+	// promoted to the active instance, ex. to recover the commands of the
+	// previous one. It must be set before Run.
+	OnPromoted func()
+
+	mutex sync.Mutex
+	// renewed is when the instance last took or renewed the lease, zero while
+	// it's a standby.
+	renewed time.Time
+}
+
+// LeaderElection returns the leader election of the database for the
+// instance identified by id.
+func (r *RedisDB) LeaderElection(id string, logger logging.SimpleLogging) *LeaderElection {
+	return &LeaderElection{client: r.client, id: id, logger: logger}
+}
+
+// Run campaigns for the lease until c is done, then resigns it so a standby
+// is promoted right away.
+func (e *LeaderElection) Run(c context.Context) {
+	ticker := time.NewTicker(LeaderLease / 3)
+	defer ticker.Stop()
+	for {
+		e.Campaign(c)
+		select {
+		case <-c.Done():
+			e.resign()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Campaign takes the lease if it's free, or renews it if the instance holds
+// it.
+func (e *LeaderElection) Campaign(c context.Context) {
+	wasLeader := e.IsLeader()
+	start := time.Now()
+	var isLeader bool
+	var err error
+	if wasLeader {
+		var renewed int64
+		renewed, err = renewLeaderScript.Run(c, e.client, []string{leaderKey}, e.id, LeaderLease.Milliseconds()).Int64()
+		isLeader = renewed == 1
+	} else {
+		isLeader, err = e.client.SetNX(c, leaderKey, e.id, LeaderLease).Result()
+	}
+	if err != nil {
+		// The lease lapses if it can't be renewed, see IsLeader.
+		e.logger.Warn("unable to campaign for the lease of the active instance: %s", err)
+		return
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if !isLeader {
+		e.renewed = time.Time{}
+		if wasLeader {
+			e.logger.Warn("lost the lease of the active instance, standing by")
+		}
+		return
+	}
+	e.renewed = start
+	if !wasLeader {
+		e.logger.Info("promoted to the active instance")
+		if e.OnPromoted != nil {
+			go e.OnPromoted()
+		}
+	}
+}
+
+// IsLeader returns whether the instance is the active one, holding a lease
+// that didn't lapse.
+func (e *LeaderElection) IsLeader() bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return !e.renewed.IsZero() && time.Since(e.renewed) < LeaderLease
+}
+
+// Leader returns the ID of the active instance, empty if there's none.
+func (e *LeaderElection) Leader(c context.Context) (string, error) {
+	id, err := e.client.Get(c, leaderKey).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return id, err
+}
+
+func (e *LeaderElection) resign() {
+	if !e.IsLeader() {
+		return
+	}
+	e.mutex.Lock()
+	e.renewed = time.Time{}
+	e.mutex.Unlock()
+	if err := resignLeaderScript.Run(ctx, e.client, []string{leaderKey}, e.id).Err(); err != nil {
+		e.logger.Warn("unable to resign the lease of the active instance: %s", err)
+		return
+	}
+	e.logger.Info("resigned the lease of the active instance")
+}
@@ -0,0 +1,56 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package redis_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/runatlantis/atlantis/server/core/redis"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestLeaderElection(t *testing.T) {
+	s := miniredis.RunT(t)
+	r := newTestRedis(s)
+	c := context.Background()
+	active := r.LeaderElection("active", logging.NewNoopLogger(t))
+	standby := r.LeaderElection("standby", logging.NewNoopLogger(t))
+	promoted := make(chan string, 2)
+	active.OnPromoted = func() { promoted <- "active" }
+	standby.OnPromoted = func() { promoted <- "standby" }
+
+	active.Campaign(c)
+	standby.Campaign(c)
+	Assert(t, active.IsLeader(), "exp the first instance to be active")
+	Assert(t, !standby.IsLeader(), "exp the second instance to stand by")
+	Equals(t, "active", <-promoted)
+	// Renewing the lease isn't a promotion.
+	active.Campaign(c)
+	leader, err := standby.Leader(c)
+	Ok(t, err)
+	Equals(t, "active", leader)
+
+	t.Log("the standby is promoted once the lease of the active instance lapses")
+	s.FastForward(redis.LeaderLease)
+	standby.Campaign(c)
+	active.Campaign(c)
+	Assert(t, standby.IsLeader(), "exp the standby to be promoted")
+	Assert(t, !active.IsLeader(), "exp the previous active instance to stand by")
+	Equals(t, "standby", <-promoted)
+	Equals(t, 0, len(promoted))
+
+	t.Log("the active instance resigns when it stops")
+	stopped, stop := context.WithCancel(c)
+	stop()
+	standby.Run(stopped)
+	Assert(t, !standby.IsLeader(), "exp the instance to resign")
+	leader, err = standby.Leader(c)
+	Ok(t, err)
+	Equals(t, "", leader)
+	active.Campaign(c)
+	Assert(t, active.IsLeader(), "exp the other instance to be promoted right away")
+}
@@ -28,6 +28,14 @@ type RedisDB struct { // nolint: revive
 
 const (
 	pullKeySeparator = "::"
+	// resourceChangesKey is the list the resource changes are appended to.
+	resourceChangesKey = "resourcechanges"
+	// applyReportsKey is the list the apply reports are appended to.
+	applyReportsKey = "applyreports"
+	// commandRecordsKey is the list the command records are appended to.
+	commandRecordsKey = "commandrecords"
+	// commandRecordsPage is how many command records are read at once.
+	commandRecordsPage = 100
 )
 
 func New(hostname string, port int, password string, tlsEnabled bool, insecureSkipVerify bool, db int) (*RedisDB, error) {
@@ -475,6 +483,302 @@ func (r *RedisDB) projectResultToProject(p command.ProjectResult) models.Project
 	}
 }
 
+// SaveSummaryFeedback creates or replaces the feedback with feedback.ID.
+func (r *RedisDB) SaveSummaryFeedback(feedback models.SummaryFeedback) error {
+	serialized, err := json.Marshal(feedback)
+	if err != nil {
+		return fmt.Errorf("serializing: %w", err)
+	}
+	if err := r.client.Set(ctx, r.summaryFeedbackKey(feedback.ID), serialized, 0).Err(); err != nil {
+		return fmt.Errorf("db transaction failed: %w", err)
+	}
+	return nil
+}
+
+// ListSummaryFeedback returns all the stored summary feedback.
+func (r *RedisDB) ListSummaryFeedback() ([]models.SummaryFeedback, error) {
+	var feedback []models.SummaryFeedback
+	iter := r.client.Scan(ctx, 0, r.summaryFeedbackKey("*"), 0).Iterator()
+	for iter.Next(ctx) {
+		val, err := r.client.Get(ctx, iter.Val()).Result()
+		if err != nil {
+			return nil, fmt.Errorf("db transaction failed: %w", err)
+		}
+		var f models.SummaryFeedback
+		if err := json.Unmarshal([]byte(val), &f); err != nil {
+			return feedback, fmt.Errorf("failed to deserialize summary feedback at key '%s': %w", iter.Val(), err)
+		}
+		feedback = append(feedback, f)
+	}
+	if err := iter.Err(); err != nil {
+		return feedback, fmt.Errorf("db transaction failed: %w", err)
+	}
+	return feedback, nil
+}
+
+// DeleteSummaryFeedback deletes the feedback with id, if any.
+func (r *RedisDB) DeleteSummaryFeedback(id string) error {
+	if err := r.client.Del(ctx, r.summaryFeedbackKey(id)).Err(); err != nil {
+		return fmt.Errorf("db transaction failed: %w", err)
+	}
+	return nil
+}
+
+func (r *RedisDB) summaryFeedbackKey(id string) string {
+	return fmt.Sprintf("summaryfeedback/%s", id)
+}
+
+// SaveResourceChanges appends changes to the stored resource changes.
+func (r *RedisDB) SaveResourceChanges(changes []models.ResourceChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	values := make([]any, 0, len(changes))
+	for _, change := range changes {
+		serialized, err := json.Marshal(change)
+		if err != nil {
+			return fmt.Errorf("serializing: %w", err)
+		}
+		values = append(values, serialized)
+	}
+	if err := r.client.RPush(ctx, resourceChangesKey, values...).Err(); err != nil {
+		return fmt.Errorf("db transaction failed: %w", err)
+	}
+	return nil
+}
+
+// ListResourceChanges returns the stored resource changes query selects, the
+// most recent first.
+func (r *RedisDB) ListResourceChanges(query models.ResourceChangeQuery) ([]models.ResourceChange, error) {
+	values, err := r.client.LRange(ctx, resourceChangesKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("db transaction failed: %w", err)
+	}
+	changes := []models.ResourceChange{}
+	for i := len(values) - 1; i >= 0; i-- {
+		var change models.ResourceChange
+		if err := json.Unmarshal([]byte(values[i]), &change); err != nil {
+			return nil, fmt.Errorf("failed to deserialize resource change at index %d: %w", i, err)
+		}
+		if !query.Matches(change) {
+			continue
+		}
+		changes = append(changes, change)
+		if query.Limit > 0 && len(changes) == query.Limit {
+			break
+		}
+	}
+	return changes, nil
+}
+
+// SaveAPIToken creates or replaces the API token with token.ID.
+func (r *RedisDB) SaveAPIToken(token models.APIToken) error {
+	serialized, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("serializing: %w", err)
+	}
+	if err := r.client.Set(ctx, r.apiTokenKey(token.ID), serialized, 0).Err(); err != nil {
+		return fmt.Errorf("db transaction failed: %w", err)
+	}
+	return nil
+}
+
+// ListAPITokens returns all the stored API tokens.
+func (r *RedisDB) ListAPITokens() ([]models.APIToken, error) {
+	var tokens []models.APIToken
+	iter := r.client.Scan(ctx, 0, r.apiTokenKey("*"), 0).Iterator()
+	for iter.Next(ctx) {
+		val, err := r.client.Get(ctx, iter.Val()).Result()
+		if err != nil {
+			return nil, fmt.Errorf("db transaction failed: %w", err)
+		}
+		var token models.APIToken
+		if err := json.Unmarshal([]byte(val), &token); err != nil {
+			return tokens, fmt.Errorf("failed to deserialize API token at key '%s': %w", iter.Val(), err)
+		}
+		tokens = append(tokens, token)
+	}
+	if err := iter.Err(); err != nil {
+		return tokens, fmt.Errorf("db transaction failed: %w", err)
+	}
+	return tokens, nil
+}
+
+// DeleteAPIToken deletes the API token with id and returns it, or nil if
+// there's none.
+func (r *RedisDB) DeleteAPIToken(id string) (*models.APIToken, error) {
+	key := r.apiTokenKey(id)
+	val, err := r.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("db transaction failed: %w", err)
+	}
+	var token models.APIToken
+	if err := json.Unmarshal([]byte(val), &token); err != nil {
+		return nil, fmt.Errorf("failed to deserialize API token at key '%s': %w", key, err)
+	}
+	if err := r.client.Del(ctx, key).Err(); err != nil {
+		return nil, fmt.Errorf("db transaction failed: %w", err)
+	}
+	return &token, nil
+}
+
+func (r *RedisDB) apiTokenKey(id string) string {
+	return fmt.Sprintf("apitoken/%s", id)
+}
+
+// SavePreviewEnvironment creates or replaces the preview environment with
+// env.ID().
+func (r *RedisDB) SavePreviewEnvironment(env models.PreviewEnvironment) error {
+	serialized, err := json.Marshal(env)
+	if err != nil {
+		return fmt.Errorf("serializing: %w", err)
+	}
+	if err := r.client.Set(ctx, r.previewKey(env.ID()), serialized, 0).Err(); err != nil {
+		return fmt.Errorf("db transaction failed: %w", err)
+	}
+	return nil
+}
+
+// ListPreviewEnvironments returns all the stored preview environments.
+func (r *RedisDB) ListPreviewEnvironments() ([]models.PreviewEnvironment, error) {
+	var envs []models.PreviewEnvironment
+	iter := r.client.Scan(ctx, 0, r.previewKey("*"), 0).Iterator()
+	for iter.Next(ctx) {
+		val, err := r.client.Get(ctx, iter.Val()).Result()
+		if err == redis.Nil {
+			// Deleted since the scan.
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("db transaction failed: %w", err)
+		}
+		var env models.PreviewEnvironment
+		if err := json.Unmarshal([]byte(val), &env); err != nil {
+			return envs, fmt.Errorf("failed to deserialize preview environment at key '%s': %w", iter.Val(), err)
+		}
+		envs = append(envs, env)
+	}
+	if err := iter.Err(); err != nil {
+		return envs, fmt.Errorf("db transaction failed: %w", err)
+	}
+	return envs, nil
+}
+
+// DeletePreviewEnvironment deletes the preview environment with id, if any.
+func (r *RedisDB) DeletePreviewEnvironment(id string) error {
+	if err := r.client.Del(ctx, r.previewKey(id)).Err(); err != nil {
+		return fmt.Errorf("db transaction failed: %w", err)
+	}
+	return nil
+}
+
+func (r *RedisDB) previewKey(id string) string {
+	return fmt.Sprintf("preview/%s", id)
+}
+
+// SaveApplyReport appends report to the stored apply reports.
+func (r *RedisDB) SaveApplyReport(report models.SignedApplyReport) error {
+	serialized, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("serializing: %w", err)
+	}
+	if err := r.client.RPush(ctx, applyReportsKey, serialized).Err(); err != nil {
+		return fmt.Errorf("db transaction failed: %w", err)
+	}
+	return nil
+}
+
+// ListApplyReports returns the stored apply reports query selects, the most
+// recent first.
+func (r *RedisDB) ListApplyReports(query models.ApplyReportQuery) ([]models.SignedApplyReport, error) {
+	values, err := r.client.LRange(ctx, applyReportsKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("db transaction failed: %w", err)
+	}
+	reports := []models.SignedApplyReport{}
+	for i := len(values) - 1; i >= 0; i-- {
+		var report models.SignedApplyReport
+		if err := json.Unmarshal([]byte(values[i]), &report); err != nil {
+			return nil, fmt.Errorf("failed to deserialize apply report at index %d: %w", i, err)
+		}
+		if !query.Matches(report) {
+			continue
+		}
+		reports = append(reports, report)
+		if query.Limit > 0 && len(reports) == query.Limit {
+			break
+		}
+	}
+	return reports, nil
+}
+
+// SaveCommandRecord appends record to the stored command records and deletes
+// the ones older than models.CommandRecordRetention.
+func (r *RedisDB) SaveCommandRecord(record models.CommandRecord) error {
+	serialized, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("serializing: %w", err)
+	}
+	if err := r.client.RPush(ctx, commandRecordsKey, serialized).Err(); err != nil {
+		return fmt.Errorf("db transaction failed: %w", err)
+	}
+	// Records are appended in order so the expired ones are first.
+	cutoff := time.Now().Add(-models.CommandRecordRetention)
+	for {
+		val, err := r.client.LIndex(ctx, commandRecordsKey, 0).Result()
+		if err == redis.Nil {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("db transaction failed: %w", err)
+		}
+		var old models.CommandRecord
+		if err := json.Unmarshal([]byte(val), &old); err != nil {
+			return fmt.Errorf("failed to deserialize command record: %w", err)
+		}
+		if !old.Time.Before(cutoff) {
+			return nil
+		}
+		// Only remove the record that was checked, another replica may have
+		// removed it already.
+		if err := r.client.LRem(ctx, commandRecordsKey, 1, val).Err(); err != nil {
+			return fmt.Errorf("db transaction failed: %w", err)
+		}
+	}
+}
+
+// ListCommandRecords returns the stored command records query selects, the
+// most recent first.
+func (r *RedisDB) ListCommandRecords(query models.CommandRecordQuery) ([]models.CommandRecord, error) {
+	records := []models.CommandRecord{}
+	// Read the records a page at a time from the end of the list.
+	for end := int64(-1); ; end -= commandRecordsPage {
+		values, err := r.client.LRange(ctx, commandRecordsKey, end-commandRecordsPage+1, end).Result()
+		if err != nil {
+			return nil, fmt.Errorf("db transaction failed: %w", err)
+		}
+		for i := len(values) - 1; i >= 0; i-- {
+			var record models.CommandRecord
+			if err := json.Unmarshal([]byte(values[i]), &record); err != nil {
+				return nil, fmt.Errorf("failed to deserialize command record: %w", err)
+			}
+			if !query.Matches(record) {
+				continue
+			}
+			records = append(records, record)
+			if query.Limit > 0 && len(records) == query.Limit {
+				return records, nil
+			}
+		}
+		if len(values) < commandRecordsPage {
+			return records, nil
+		}
+	}
+}
+
 func (r *RedisDB) Close() error {
 	return r.client.Close()
 }
@@ -17,6 +17,7 @@ import (
 	"math/big"
 	"net"
 	"os"
+	"strconv"
 	"testing"
 	"time"
 
@@ -1062,6 +1063,63 @@ func TestPullStatus_UpdateMerge_ApprovePolicies(t *testing.T) {
 	}
 }
 
+func TestSummaryFeedback(t *testing.T) {
+	s := miniredis.RunT(t)
+	r := newTestRedis(s)
+	_, _, err := r.TryLock(lock)
+	Ok(t, err)
+
+	created := time.Now().UTC().Truncate(time.Second)
+	f := models.SummaryFeedback{
+		ID:         "runatlantis/atlantis/1/1",
+		Repo:       models.Repo{FullName: "runatlantis/atlantis"},
+		PullNum:    1,
+		Model:      "model",
+		PromptHash: "hash",
+		ThumbsDown: 1,
+		CreatedAt:  created,
+		UpdatedAt:  created,
+	}
+	Ok(t, r.SaveSummaryFeedback(f))
+
+	feedback, err := r.ListSummaryFeedback()
+	Ok(t, err)
+	Equals(t, []models.SummaryFeedback{f}, feedback)
+
+	t.Log("summary feedback isn't listed as a lock")
+	locks, err := r.List()
+	Ok(t, err)
+	Equals(t, 1, len(locks))
+	Ok(t, r.DeleteSummaryFeedback(f.ID))
+	feedback, err = r.ListSummaryFeedback()
+	Ok(t, err)
+	Equals(t, 0, len(feedback))
+}
+
+func TestResourceChanges(t *testing.T) {
+	s := miniredis.RunT(t)
+	r := newTestRedis(s)
+
+	changes, err := r.ListResourceChanges(models.ResourceChangeQuery{})
+	Ok(t, err)
+	Equals(t, []models.ResourceChange{}, changes)
+
+	appliedAt := time.Now().UTC().Truncate(time.Second)
+	first := models.ResourceChange{Address: "aws_security_group.web", Action: "create", Repository: "runatlantis/atlantis", PullNum: 1, AppliedAt: appliedAt}
+	second := models.ResourceChange{Address: "aws_security_group.web", Action: "delete", Repository: "runatlantis/atlantis", PullNum: 2, AppliedAt: appliedAt}
+	other := models.ResourceChange{Address: "aws_vpc.main", Action: "update", Repository: "runatlantis/atlantis", PullNum: 2, AppliedAt: appliedAt}
+	Ok(t, r.SaveResourceChanges([]models.ResourceChange{first}))
+	Ok(t, r.SaveResourceChanges([]models.ResourceChange{second, other}))
+
+	changes, err = r.ListResourceChanges(models.ResourceChangeQuery{Address: "aws_security_group.web"})
+	Ok(t, err)
+	Equals(t, []models.ResourceChange{second, first}, changes)
+
+	changes, err = r.ListResourceChanges(models.ResourceChangeQuery{PullNum: 2, Limit: 1})
+	Ok(t, err)
+	Equals(t, []models.ResourceChange{other}, changes)
+}
+
 func newTestRedis(mr *miniredis.Miniredis) *redis.RedisDB {
 	r, err := redis.New(mr.Host(), mr.Server().Addr().Port, "", false, false, 0)
 	if err != nil {
@@ -1113,3 +1171,106 @@ func generateLocalhostCert() ([]byte, []byte, error) {
 	certBytes, err := x509.CreateCertificate(rand.Reader, &template, &template, &priv.PublicKey, priv)
 	return certBytes, keyBytes, err
 }
+
+func TestAPITokens(t *testing.T) {
+	s := miniredis.RunT(t)
+	r := newTestRedis(s)
+
+	token := models.APIToken{
+		ID:        "1",
+		Name:      "ci",
+		Hash:      models.HashAPIToken("token"),
+		Scope:     models.APITokenScopeReadOnly,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	Ok(t, r.SaveAPIToken(token))
+	tokens, err := r.ListAPITokens()
+	Ok(t, err)
+	Equals(t, []models.APIToken{token}, tokens)
+
+	deleted, err := r.DeleteAPIToken("1")
+	Ok(t, err)
+	Equals(t, &token, deleted)
+	deleted, err = r.DeleteAPIToken("1")
+	Ok(t, err)
+	Assert(t, deleted == nil, "exp no token to be deleted")
+	tokens, err = r.ListAPITokens()
+	Ok(t, err)
+	Equals(t, 0, len(tokens))
+}
+
+func TestPreviewEnvironments(t *testing.T) {
+	s := miniredis.RunT(t)
+	r := newTestRedis(s)
+
+	env := models.PreviewEnvironment{
+		Pull:        models.PullRequest{Num: 12, BaseRepo: models.Repo{FullName: "owner/repo"}},
+		ProjectName: "app",
+		RepoRelDir:  "app",
+		Workspace:   "pr-12",
+		DeployedAt:  time.Now().UTC().Truncate(time.Second),
+		Outputs:     map[string]string{"url": "https://pr-12.example.com"},
+	}
+	Ok(t, r.SavePreviewEnvironment(env))
+	env.Closed = true
+	Ok(t, r.SavePreviewEnvironment(env))
+	envs, err := r.ListPreviewEnvironments()
+	Ok(t, err)
+	Equals(t, []models.PreviewEnvironment{env}, envs)
+
+	Ok(t, r.DeletePreviewEnvironment(env.ID()))
+	envs, err = r.ListPreviewEnvironments()
+	Ok(t, err)
+	Equals(t, 0, len(envs))
+}
+
+func TestApplyReports(t *testing.T) {
+	s := miniredis.RunT(t)
+	r := newTestRedis(s)
+
+	appliedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	first := models.SignedApplyReport{
+		Report:    models.ApplyReport{ID: "1", Repository: "owner/repo", PullNum: 1, AppliedAt: appliedAt, Success: true},
+		Signature: "hmac-sha256:00",
+	}
+	second := models.SignedApplyReport{
+		Report:    models.ApplyReport{ID: "2", Repository: "owner/repo", PullNum: 2, AppliedAt: appliedAt.Add(time.Hour)},
+		Signature: "hmac-sha256:01",
+	}
+	Ok(t, r.SaveApplyReport(first))
+	Ok(t, r.SaveApplyReport(second))
+
+	reports, err := r.ListApplyReports(models.ApplyReportQuery{})
+	Ok(t, err)
+	Equals(t, []models.SignedApplyReport{second, first}, reports)
+	reports, err = r.ListApplyReports(models.ApplyReportQuery{Repository: "owner/repo", Since: appliedAt.Add(time.Minute)})
+	Ok(t, err)
+	Equals(t, []models.SignedApplyReport{second}, reports)
+}
+
+func TestCommandRecords(t *testing.T) {
+	s := miniredis.RunT(t)
+	r := newTestRedis(s)
+
+	now := time.Now().UTC()
+	expired := models.CommandRecord{ID: "0", Time: now.Add(-models.CommandRecordRetention - time.Hour), Repository: "owner/repo", PullNum: 1}
+	Ok(t, r.SaveCommandRecord(expired))
+	// More records than are read at once.
+	var saved []models.CommandRecord
+	for i := 1; i <= 250; i++ {
+		record := models.CommandRecord{ID: strconv.Itoa(i), Time: now, Repository: "owner/repo", PullNum: i % 2, Request: models.CommandRequest{Name: "plan"}}
+		Ok(t, r.SaveCommandRecord(record))
+		saved = append([]models.CommandRecord{record}, saved...)
+	}
+
+	// The expired record was deleted when the others were saved.
+	records, err := r.ListCommandRecords(models.CommandRecordQuery{})
+	Ok(t, err)
+	Equals(t, saved, records)
+	records, err = r.ListCommandRecords(models.CommandRecordQuery{ID: "1"})
+	Ok(t, err)
+	Equals(t, []models.CommandRecord{saved[249]}, records)
+	records, err = r.ListCommandRecords(models.CommandRecordQuery{PullNum: 1, Limit: 2})
+	Ok(t, err)
+	Equals(t, []models.CommandRecord{saved[1], saved[3]}, records)
+}
@@ -0,0 +1,287 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/runatlantis/atlantis/server/events"
+)
+
+const (
+	// workStream is the stream commands are dispatched to.
+	workStream = "atlantis:work"
+	// workGroup is the consumer group of the workers.
+	workGroup = "atlantis-workers"
+	// workItemField is the field of stream entries the item is stored in.
+	workItemField = "item"
+	// workBlock is how long reading the stream blocks before ctx is checked
+	// again.
+	workBlock = 5 * time.Second
+	// workClaimIdle is how long the items delivered to a worker that stopped
+	// keeping them alive, ex. because it crashed, are left before another
+	// worker claims them.
+	workClaimIdle = time.Minute
+	// pullLockTTL is how long the lock of a pull request outlives a worker
+	// that crashed while holding it. It's refreshed while it's held.
+	pullLockTTL = time.Minute
+	// pullLockRetry is how often a locked pull request is tried again.
+	pullLockRetry = time.Second
+)
+
+// WorkQueue is a work queue on a Redis stream. Workers read it as consumers
+// of the same group so each command is run by one worker.
+type WorkQueue struct {
+	client *redis.Client
+	// consumer is the name of the worker in the group. The commands a worker
+	// didn't acknowledge, ex. because it crashed, are delivered to it again
+	// when it restarts with the same name, or claimed by another worker once
+	// they've been idle for workClaimIdle.
+	consumer string
+	// claimIdle is workClaimIdle, it's overridden in tests.
+	claimIdle time.Duration
+
+	mutex   sync.Mutex
+	started bool
+	pending []redis.XMessage
+}
+
+// WorkQueue returns the work queue of the database for the worker named
+// consumer, or for a frontend if consumer is empty.
+func (r *RedisDB) WorkQueue(consumer string) *WorkQueue {
+	return &WorkQueue{client: r.client, consumer: consumer, claimIdle: workClaimIdle}
+}
+
+// Enqueue adds item, without the credentials of its clone URLs, to the
+// stream.
+func (q *WorkQueue) Enqueue(item events.WorkItem) error {
+	serialized, err := json.Marshal(item.WithoutCloneCredentials())
+	if err != nil {
+		return fmt.Errorf("serializing work item: %w", err)
+	}
+	return q.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: workStream,
+		Values: map[string]any{workItemField: string(serialized)},
+	}).Err()
+}
+
+// Dequeue returns the next item of the stream for the worker, first the ones
+// it didn't acknowledge before restarting, then the ones other workers
+// stopped keeping alive.
+func (q *WorkQueue) Dequeue(c context.Context) (events.WorkItem, events.WorkDelivery, error) {
+	if err := q.start(c); err != nil {
+		return events.WorkItem{}, nil, err
+	}
+	for {
+		message, ok := q.nextPending()
+		if !ok {
+			claimed, _, err := q.client.XAutoClaim(c, &redis.XAutoClaimArgs{
+				Stream:   workStream,
+				Group:    workGroup,
+				Consumer: q.consumer,
+				MinIdle:  q.claimIdle,
+				Start:    "0-0",
+				Count:    1,
+			}).Result()
+			if err != nil && !errors.Is(err, redis.Nil) {
+				return events.WorkItem{}, nil, fmt.Errorf("claiming idle work items: %w", err)
+			}
+			if len(claimed) > 0 {
+				message, ok = claimed[0], true
+			}
+		}
+		if !ok {
+			streams, err := q.client.XReadGroup(c, &redis.XReadGroupArgs{
+				Group:    workGroup,
+				Consumer: q.consumer,
+				Streams:  []string{workStream, ">"},
+				Count:    1,
+				Block:    workBlock,
+			}).Result()
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			if err != nil {
+				return events.WorkItem{}, nil, fmt.Errorf("reading work stream: %w", err)
+			}
+			if len(streams) == 0 || len(streams[0].Messages) == 0 {
+				continue
+			}
+			message = streams[0].Messages[0]
+		}
+
+		delivery := q.deliver(message.ID)
+		var item events.WorkItem
+		serialized, _ := message.Values[workItemField].(string)
+		if err := json.Unmarshal([]byte(serialized), &item); err != nil {
+			// The entry would never be handled, it's dropped.
+			delivery.Ack() // nolint: errcheck
+			return events.WorkItem{}, nil, fmt.Errorf("dropping invalid work item %s: %w", message.ID, err)
+		}
+		return item, delivery, nil
+	}
+}
+
+// workDelivery is the delivery of the entry with id to the worker. It's kept
+// alive by claiming it again regularly, which resets how long it's idle.
+type workDelivery struct {
+	queue         *WorkQueue
+	id            string
+	stopKeepAlive context.CancelFunc
+}
+
+func (q *WorkQueue) deliver(id string) *workDelivery {
+	alive, stopKeepAlive := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(q.claimIdle / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-alive.Done():
+				return
+			case <-ticker.C:
+				q.client.XClaimJustID(alive, &redis.XClaimArgs{ // nolint: errcheck
+					Stream:   workStream,
+					Group:    workGroup,
+					Consumer: q.consumer,
+					Messages: []string{id},
+				})
+			}
+		}
+	}()
+	return &workDelivery{queue: q, id: id, stopKeepAlive: stopKeepAlive}
+}
+
+func (d *workDelivery) Ack() error {
+	d.stopKeepAlive()
+	if err := d.queue.client.XAck(ctx, workStream, workGroup, d.id).Err(); err != nil {
+		return err
+	}
+	return d.queue.client.XDel(ctx, workStream, d.id).Err()
+}
+
+// Release stops keeping the entry alive. It's delivered again when the worker
+// restarts or once another worker claims it.
+func (d *workDelivery) Release() {
+	d.stopKeepAlive()
+}
+
+// start creates the group if it doesn't exist and reads the items delivered
+// to the worker but not acknowledged, the first time it's called.
+func (q *WorkQueue) start(c context.Context) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.started {
+		return nil
+	}
+	err := q.client.XGroupCreateMkStream(c, workStream, workGroup, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("creating work group: %w", err)
+	}
+	streams, err := q.client.XReadGroup(c, &redis.XReadGroupArgs{
+		Group:    workGroup,
+		Consumer: q.consumer,
+		Streams:  []string{workStream, "0"},
+	}).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("reading unacknowledged work items: %w", err)
+	}
+	for _, stream := range streams {
+		q.pending = append(q.pending, stream.Messages...)
+	}
+	q.started = true
+	return nil
+}
+
+func (q *WorkQueue) nextPending() (redis.XMessage, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if len(q.pending) == 0 {
+		return redis.XMessage{}, false
+	}
+	message := q.pending[0]
+	q.pending = q.pending[1:]
+	return message, true
+}
+
+// unlockPullScript deletes the lock of a pull request only if it's still held
+// by the same holder, in case it expired and was taken by another worker.
+var unlockPullScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// PullLocks returns the locks of pull requests of the worker named holder.
+func (r *RedisDB) PullLocks(holder string) *PullLocks {
+	return &PullLocks{client: r.client, holder: holder}
+}
+
+// PullLocks are the locks of pull requests shared by the workers. A lock
+// expires after pullLockTTL if the worker holding it crashes and is refreshed
+// while it's held.
+type PullLocks struct {
+	client *redis.Client
+	holder string
+}
+
+// TryLockPull takes the lock of the pull request unless another worker holds
+// it.
+func (l *PullLocks) TryLockPull(repoFullName string, pullNum int) (func(), bool, error) {
+	key := fmt.Sprintf("atlantis:work:pull:%s%s%d", repoFullName, pullKeySeparator, pullNum)
+	holder := make([]byte, 8)
+	rand.Read(holder) // nolint: errcheck
+	token := l.holder + "/" + hex.EncodeToString(holder)
+	locked, err := l.client.SetNX(ctx, key, token, pullLockTTL).Result()
+	if err != nil {
+		return nil, false, fmt.Errorf("locking %s#%d: %w", repoFullName, pullNum, err)
+	}
+	if !locked {
+		return nil, false, nil
+	}
+
+	refreshed, stopRefreshing := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(pullLockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-refreshed.Done():
+				return
+			case <-ticker.C:
+				l.client.Expire(refreshed, key, pullLockTTL) // nolint: errcheck
+			}
+		}
+	}()
+	return func() {
+		stopRefreshing()
+		unlockPullScript.Run(ctx, l.client, []string{key}, token) // nolint: errcheck
+	}, true, nil
+}
+
+// LockPull takes the lock of the pull request, waiting while another worker
+// holds it.
+func (l *PullLocks) LockPull(c context.Context, repoFullName string, pullNum int) (func(), error) {
+	for {
+		unlock, locked, err := l.TryLockPull(repoFullName, pullNum)
+		if err != nil || locked {
+			return unlock, err
+		}
+		select {
+		case <-c.Done():
+			return nil, c.Err()
+		case <-time.After(pullLockRetry):
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package redis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestWorkQueue(t *testing.T) {
+	s := miniredis.RunT(t)
+	r := newTestRedis(s)
+	frontend := r.WorkQueue("")
+	item := events.WorkItem{
+		CorrelationID: "id",
+		BaseRepo:      models.Repo{FullName: "owner/repo"},
+		PullNum:       1,
+		Command:       &events.CommentCommand{Name: command.Plan, ProjectName: "project"},
+	}
+	Ok(t, frontend.Enqueue(item))
+	item.PullNum = 2
+	Ok(t, frontend.Enqueue(item))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	worker := r.WorkQueue("worker-1")
+	dequeued, delivery, err := worker.Dequeue(ctx)
+	Ok(t, err)
+	Equals(t, 1, dequeued.PullNum)
+	Equals(t, "project", dequeued.Command.ProjectName)
+	Ok(t, delivery.Ack())
+
+	t.Log("items that weren't acknowledged are delivered again once the worker restarts")
+	dequeued, delivery, err = worker.Dequeue(ctx)
+	Ok(t, err)
+	Equals(t, 2, dequeued.PullNum)
+	delivery.Release()
+	restarted := r.WorkQueue("worker-1")
+	dequeued, delivery, err = restarted.Dequeue(ctx)
+	Ok(t, err)
+	Equals(t, 2, dequeued.PullNum)
+	Ok(t, delivery.Ack())
+
+	t.Log("dequeuing returns once ctx is done")
+	done, cancelDone := context.WithCancel(context.Background())
+	cancelDone()
+	_, _, err = restarted.Dequeue(done)
+	Assert(t, err != nil, "exp an error once ctx is done")
+}
+
+func TestWorkQueue_ClaimsIdleItems(t *testing.T) {
+	s := miniredis.RunT(t)
+	r := newTestRedis(s)
+	Ok(t, r.WorkQueue("").Enqueue(events.WorkItem{BaseRepo: models.Repo{FullName: "owner/repo"}, PullNum: 1}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, crashed, err := r.WorkQueue("worker-1").Dequeue(ctx)
+	Ok(t, err)
+	crashed.Release()
+
+	t.Log("another worker claims the item once the worker that crashed stopped keeping it alive")
+	s.SetTime(time.Now().Add(2 * time.Minute))
+	dequeued, delivery, err := r.WorkQueue("worker-2").Dequeue(ctx)
+	Ok(t, err)
+	Equals(t, 1, dequeued.PullNum)
+	Ok(t, delivery.Ack())
+}
+
+func TestPullLocks(t *testing.T) {
+	s := miniredis.RunT(t)
+	r := newTestRedis(s)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	unlock, err := r.PullLocks("worker-1").LockPull(ctx, "owner/repo", 1)
+	Ok(t, err)
+
+	t.Log("other pull requests aren't locked")
+	unlockOther, locked, err := r.PullLocks("worker-2").TryLockPull("owner/repo", 2)
+	Ok(t, err)
+	Assert(t, locked, "exp pull 2 to be locked")
+	unlockOther()
+
+	t.Log("other workers can't take the lock")
+	_, locked, err = r.PullLocks("worker-2").TryLockPull("owner/repo", 1)
+	Ok(t, err)
+	Assert(t, !locked, "exp pull 1 to be locked by worker-1")
+	timeout, cancelTimeout := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancelTimeout()
+	_, err = r.PullLocks("worker-2").LockPull(timeout, "owner/repo", 1)
+	Equals(t, context.DeadlineExceeded, err)
+
+	unlock()
+	unlock, err = r.PullLocks("worker-2").LockPull(ctx, "owner/repo", 1)
+	Ok(t, err)
+	unlock()
+}
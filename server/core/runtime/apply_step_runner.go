@@ -26,6 +26,14 @@ type ApplyStepRunner struct {
 	DefaultTFVersion      *version.Version       `validate:"required"`
 	CommitStatusUpdater   StatusUpdater          `validate:"required"`
 	AsyncTFExec           AsyncTFExec            `validate:"required"`
+	// StateCheck is StateCheckWarn or StateCheckAbort to check the state
+	// didn't change since plans were generated before applying them. It's
+	// empty if the state isn't checked.
+	StateCheck string
+	// StateDiffResourceTypes are the patterns, ex. aws_iam_*, of the resource
+	// types whose changes are compared to what plans advertised after applying
+	// them. Nothing is compared if it's empty.
+	StateDiffResourceTypes []string
 }
 
 func (a *ApplyStepRunner) Run(ctx command.ProjectContext, extraArgs []string, path string, envs map[string]string) (string, error) {
@@ -61,10 +69,41 @@ func (a *ApplyStepRunner) Run(ctx command.ProjectContext, extraArgs []string, pa
 			out = a.cleanRemoteApplyOutput(out)
 		}
 	} else {
+		stateChanged := ""
+		if a.StateCheck != "" {
+			stateChanged, err = a.checkState(ctx, path, planPath, envs, tfDistribution, tfVersion)
+			if err != nil && a.StateCheck == StateCheckAbort {
+				// The state may have changed so don't apply a plan that could
+				// be stale.
+				return "", fmt.Errorf("unable to check if the state changed since plan, not applying: %w", err)
+			} else if err != nil {
+				ctx.Log.Warn("unable to check if the state changed since plan: %s", err)
+			} else if stateChanged != "" && a.StateCheck == StateCheckAbort {
+				return "", errors.New(stateChanged)
+			}
+		}
+		var baseline *stateDiffBaseline
+		if len(a.StateDiffResourceTypes) > 0 {
+			baseline, err = a.stateDiffBaseline(ctx, path, planPath, envs, tfDistribution, tfVersion)
+			if err != nil {
+				ctx.Log.Warn("unable to snapshot the state before apply: %s", err)
+			}
+		}
 		// NOTE: we need to quote the plan path because Bitbucket Server can
 		// have spaces in its repo owner names which is part of the path.
 		args := append(append(append([]string{"apply", "-input=false"}, extraArgs...), ctx.EscapedCommentArgs...), fmt.Sprintf("%q", planPath))
 		out, err = a.TerraformExecutor.RunCommandWithVersion(ctx, path, args, envs, tfDistribution, tfVersion, ctx.Workspace)
+		if stateChanged != "" {
+			out = fmt.Sprintf("%s\n\n%s", stateChanged, out)
+		}
+		if err == nil && baseline != nil {
+			report, diffErr := a.diffState(ctx, path, baseline, envs, tfDistribution, tfVersion)
+			if diffErr != nil {
+				ctx.Log.Warn("unable to diff the state after apply: %s", diffErr)
+			} else if report != "" {
+				out = fmt.Sprintf("%s\n\n%s", out, report)
+			}
+		}
 	}
 
 	// If the apply was successful, delete the plan.
@@ -0,0 +1,318 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultRegistryHost = "registry.terraform.io"
+	lockFileName        = ".terraform.lock.hcl"
+	providerKind        = "provider"
+	moduleKind          = "module"
+)
+
+// NewAuditStepRunner returns a runner auditing the providers and modules of
+// projects against the advisories in the file at advisoriesPath, which may be
+// empty to only check for outdated versions.
+func NewAuditStepRunner(advisoriesPath string) Runner {
+	return &auditStepRunner{
+		advisoriesPath: advisoriesPath,
+		registryURL:    func(host string) string { return "https://" + host },
+		client:         &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// auditStepRunner audits the providers locked in .terraform.lock.hcl and the
+// registry modules installed by init for known vulnerabilities and outdated
+// versions, and writes its findings to the project's audit file.
+type auditStepRunner struct {
+	advisoriesPath string
+	// registryURL returns the base URL of the registry at host, it's
+	// overridden in tests.
+	registryURL func(host string) string
+	client      *http.Client
+}
+
+// auditedDependency is a provider or module version used by a project.
+type auditedDependency struct {
+	kind    string
+	source  string
+	version string
+}
+
+// auditAdvisories is the advisories file.
+type auditAdvisories struct {
+	Advisories []struct {
+		ID       string `yaml:"id"`
+		Source   string `yaml:"source"`
+		Versions string `yaml:"versions"`
+		Severity string `yaml:"severity"`
+		Summary  string `yaml:"summary"`
+		URL      string `yaml:"url"`
+	} `yaml:"advisories"`
+}
+
+// auditAdvisory is an advisory of the advisories file with its versions
+// parsed.
+type auditAdvisory struct {
+	models.AuditAdvisory
+	source   string
+	versions version.Constraints
+}
+
+func (a *auditStepRunner) Run(ctx command.ProjectContext, _ []string, path string, _ map[string]string) (string, error) {
+	advisories, err := a.loadAdvisories()
+	if err != nil {
+		return "", err
+	}
+	var report models.AuditReport
+
+	dependencies, err := lockedProviders(filepath.Join(path, lockFileName))
+	if err != nil {
+		return "", err
+	}
+	if dependencies == nil {
+		report.Notes = append(report.Notes, fmt.Sprintf("There's no %s, providers weren't audited. An init step must run before the audit step.", lockFileName))
+	}
+	modules, err := installedModules(filepath.Join(path, ".terraform", "modules", "modules.json"))
+	if err != nil {
+		return "", err
+	}
+	dependencies = append(dependencies, modules...)
+
+	// Hosts whose registry can't be reached are only tried once.
+	unreachable := make(map[string]bool)
+	for _, dependency := range dependencies {
+		finding := models.AuditFinding{Kind: dependency.kind, Source: dependency.source, Version: dependency.version}
+		current, err := version.NewVersion(dependency.version)
+		if err != nil {
+			report.Notes = append(report.Notes, fmt.Sprintf("Unable to parse version %q of %s %s.", dependency.version, dependency.kind, dependency.source))
+			continue
+		}
+		for _, advisory := range advisories {
+			if advisory.source == dependency.source && advisory.versions.Check(current) {
+				finding.Advisories = append(finding.Advisories, advisory.AuditAdvisory)
+			}
+		}
+
+		host := strings.SplitN(dependency.source, "/", 2)[0]
+		if !unreachable[host] {
+			latest, err := a.latestVersion(dependency)
+			if err != nil {
+				ctx.Log.Warn("unable to look up the latest version of %s %s: %s", dependency.kind, dependency.source, err)
+				report.Notes = append(report.Notes, fmt.Sprintf("Unable to look up the latest versions on %s, outdated versions weren't checked.", host))
+				unreachable[host] = true
+			} else if latest != nil && outdatedVersion(current, latest) {
+				finding.LatestVersion = latest.Original()
+			}
+		}
+
+		if len(finding.Advisories) > 0 || finding.Outdated() {
+			report.Findings = append(report.Findings, finding)
+		}
+	}
+
+	contents, err := json.Marshal(report)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(path, ctx.GetAuditFileName()), contents, 0600); err != nil {
+		return "", fmt.Errorf("writing audit findings: %w", err)
+	}
+	return "", nil
+}
+
+// loadAdvisories reads the advisories file. It's read on every run so it can
+// be updated without restarting Atlantis.
+func (a *auditStepRunner) loadAdvisories() ([]auditAdvisory, error) {
+	if a.advisoriesPath == "" {
+		return nil, nil
+	}
+	contents, err := os.ReadFile(a.advisoriesPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading audit advisories: %w", err)
+	}
+	var file auditAdvisories
+	if err := yaml.Unmarshal(contents, &file); err != nil {
+		return nil, fmt.Errorf("parsing audit advisories %s: %w", a.advisoriesPath, err)
+	}
+	var advisories []auditAdvisory
+	for i, raw := range file.Advisories {
+		if raw.ID == "" || raw.Source == "" {
+			return nil, fmt.Errorf("parsing audit advisories %s: advisory %d must have an id and a source", a.advisoriesPath, i)
+		}
+		advisory := auditAdvisory{
+			AuditAdvisory: models.AuditAdvisory{ID: raw.ID, Severity: raw.Severity, Summary: raw.Summary, URL: raw.URL},
+			source:        normalizeSourceAddress(raw.Source),
+		}
+		if raw.Versions != "" {
+			if advisory.versions, err = version.NewConstraint(raw.Versions); err != nil {
+				return nil, fmt.Errorf("parsing audit advisories %s: versions of %s: %w", a.advisoriesPath, raw.ID, err)
+			}
+		}
+		advisories = append(advisories, advisory)
+	}
+	return advisories, nil
+}
+
+// latestVersion returns the latest version of dependency in its registry, or
+// nil if the registry has no versions of it.
+func (a *auditStepRunner) latestVersion(dependency auditedDependency) (*version.Version, error) {
+	parts := strings.Split(dependency.source, "/")
+	var url string
+	switch {
+	case dependency.kind == providerKind && len(parts) == 3:
+		url = fmt.Sprintf("%s/v1/providers/%s/%s/versions", a.registryURL(parts[0]), parts[1], parts[2])
+	case dependency.kind == moduleKind && len(parts) == 4:
+		url = fmt.Sprintf("%s/v1/modules/%s/%s/%s/versions", a.registryURL(parts[0]), parts[1], parts[2], parts[3])
+	default:
+		return nil, fmt.Errorf("%q isn't a registry address", dependency.source)
+	}
+	resp, err := a.client.Get(url) // nolint: gosec
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+
+	type versions []struct {
+		Version string `json:"version"`
+	}
+	var body struct {
+		Versions versions `json:"versions"`
+		Modules  []struct {
+			Versions versions `json:"versions"`
+		} `json:"modules"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("parsing versions from %s: %w", url, err)
+	}
+	all := body.Versions
+	for _, module := range body.Modules {
+		all = append(all, module.Versions...)
+	}
+	var latest *version.Version
+	for _, v := range all {
+		parsed, err := version.NewVersion(v.Version)
+		if err != nil || parsed.Prerelease() != "" {
+			continue
+		}
+		if latest == nil || parsed.GreaterThan(latest) {
+			latest = parsed
+		}
+	}
+	return latest, nil
+}
+
+// outdatedVersion returns true if current is at least a major version behind
+// latest, or a minor version behind for versions before 1.0, whose minor
+// versions may break compatibility.
+func outdatedVersion(current *version.Version, latest *version.Version) bool {
+	currentSegments, latestSegments := current.Segments(), latest.Segments()
+	if latestSegments[0] > currentSegments[0] {
+		return true
+	}
+	return latestSegments[0] == 0 && currentSegments[0] == 0 && latestSegments[1] > currentSegments[1]
+}
+
+// lockedProviders returns the providers locked in the lock file at path, or
+// nil if there's no lock file.
+func lockedProviders(path string) ([]auditedDependency, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	file, diags := hclparse.NewParser().ParseHCLFile(path)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("parsing %s: %w", path, diags)
+	}
+	var lockFile struct {
+		Providers []struct {
+			Source  string   `hcl:"source,label"`
+			Version string   `hcl:"version"`
+			Remain  hcl.Body `hcl:",remain"`
+		} `hcl:"provider,block"`
+		Remain hcl.Body `hcl:",remain"`
+	}
+	if diags := gohcl.DecodeBody(file.Body, nil, &lockFile); diags.HasErrors() {
+		return nil, fmt.Errorf("parsing %s: %w", path, diags)
+	}
+	providers := []auditedDependency{}
+	for _, provider := range lockFile.Providers {
+		providers = append(providers, auditedDependency{kind: providerKind, source: normalizeSourceAddress(provider.Source), version: provider.Version})
+	}
+	return providers, nil
+}
+
+// installedModules returns the registry modules in the modules manifest init
+// writes to path. Modules from other sources, ex. git, have no version and
+// aren't audited.
+func installedModules(path string) ([]auditedDependency, error) {
+	contents, err := os.ReadFile(path) // nolint: gosec
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var manifest struct {
+		Modules []struct {
+			Source  string `json:"Source"`
+			Version string `json:"Version"`
+		} `json:"Modules"`
+	}
+	if err := json.Unmarshal(contents, &manifest); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	var modules []auditedDependency
+	seen := make(map[auditedDependency]bool)
+	for _, module := range manifest.Modules {
+		if module.Version == "" {
+			continue
+		}
+		source := normalizeSourceAddress(module.Source)
+		if strings.Count(source, "/") != 3 {
+			continue
+		}
+		dependency := auditedDependency{kind: moduleKind, source: source, version: module.Version}
+		if !seen[dependency] {
+			seen[dependency] = true
+			modules = append(modules, dependency)
+		}
+	}
+	return modules, nil
+}
+
+// normalizeSourceAddress normalizes a provider or registry module source
+// address so the same provider or module always has the same address. The
+// registry host is added if it's omitted and submodule paths are removed, ex.
+// terraform-aws-modules/iam/aws//modules/iam-user is
+// registry.terraform.io/terraform-aws-modules/iam/aws.
+func normalizeSourceAddress(source string) string {
+	source, _, _ = strings.Cut(strings.ToLower(source), "//")
+	if host, _, _ := strings.Cut(source, "/"); !strings.ContainsAny(host, ".:") {
+		source = defaultRegistryHost + "/" + source
+	}
+	return source
+}
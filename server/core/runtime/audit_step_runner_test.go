@@ -0,0 +1,150 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+const auditLockFile = `
+provider "registry.terraform.io/hashicorp/aws" {
+  version     = "4.67.0"
+  constraints = "~> 4.0"
+  hashes = [
+    "h1:abc=",
+  ]
+}
+
+provider "registry.terraform.io/hashicorp/random" {
+  version = "3.6.0"
+}
+
+provider "registry.terraform.io/example/beta" {
+  version = "0.3.1"
+}
+`
+
+const auditModules = `{"Modules":[
+  {"Key":"","Source":"","Dir":"."},
+  {"Key":"vpc","Source":"registry.terraform.io/terraform-aws-modules/vpc/aws","Version":"3.19.0","Dir":".terraform/modules/vpc"},
+  {"Key":"vpc2","Source":"terraform-aws-modules/vpc/aws","Version":"3.19.0","Dir":".terraform/modules/vpc2"},
+  {"Key":"user","Source":"terraform-aws-modules/iam/aws//modules/iam-user","Version":"5.30.0","Dir":".terraform/modules/user"},
+  {"Key":"local","Source":"git::https://example.com/modules.git?ref=v1.0.0","Dir":".terraform/modules/local"}
+]}`
+
+const auditAdvisoriesFile = `
+advisories:
+  - id: CVE-2023-1234
+    source: hashicorp/aws
+    versions: ">= 4.0.0, < 4.68.0"
+    severity: high
+    summary: credentials are logged
+    url: https://example.com/CVE-2023-1234
+  - id: CVE-2023-5678
+    source: hashicorp/random
+    versions: "< 3.0.0"
+  - id: GHSA-1234
+    source: terraform-aws-modules/iam/aws
+`
+
+func TestAuditStepRunner(t *testing.T) {
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/providers/hashicorp/aws/versions":
+			w.Write([]byte(`{"versions":[{"version":"4.67.0"},{"version":"5.31.0"},{"version":"6.0.0-beta1"}]}`)) // nolint: errcheck
+		case "/v1/providers/hashicorp/random/versions":
+			w.Write([]byte(`{"versions":[{"version":"3.6.0"},{"version":"3.6.1"}]}`)) // nolint: errcheck
+		case "/v1/providers/example/beta/versions":
+			w.Write([]byte(`{"versions":[{"version":"0.3.1"},{"version":"0.4.0"}]}`)) // nolint: errcheck
+		case "/v1/modules/terraform-aws-modules/vpc/aws/versions":
+			w.Write([]byte(`{"modules":[{"versions":[{"version":"3.19.0"},{"version":"5.4.0"}]}]}`)) // nolint: errcheck
+		case "/v1/modules/terraform-aws-modules/iam/aws/versions":
+			w.Write([]byte(`{"modules":[{"versions":[{"version":"5.30.0"}]}]}`)) // nolint: errcheck
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer registry.Close()
+
+	path := t.TempDir()
+	Ok(t, os.WriteFile(filepath.Join(path, ".terraform.lock.hcl"), []byte(auditLockFile), 0600))
+	Ok(t, os.MkdirAll(filepath.Join(path, ".terraform", "modules"), 0700))
+	Ok(t, os.WriteFile(filepath.Join(path, ".terraform", "modules", "modules.json"), []byte(auditModules), 0600))
+	advisoriesPath := filepath.Join(t.TempDir(), "advisories.yaml")
+	Ok(t, os.WriteFile(advisoriesPath, []byte(auditAdvisoriesFile), 0600))
+
+	ctx := command.ProjectContext{Workspace: "default", ProjectName: "test", Log: logging.NewNoopLogger(t)}
+	subject := NewAuditStepRunner(advisoriesPath).(*auditStepRunner)
+	subject.registryURL = func(host string) string {
+		Equals(t, "registry.terraform.io", host)
+		return registry.URL
+	}
+
+	out, err := subject.Run(ctx, nil, path, map[string]string{})
+	Ok(t, err)
+	Equals(t, "", out)
+	Equals(t, models.AuditReport{
+		Findings: []models.AuditFinding{
+			{
+				Kind:          "provider",
+				Source:        "registry.terraform.io/hashicorp/aws",
+				Version:       "4.67.0",
+				LatestVersion: "5.31.0",
+				Advisories:    []models.AuditAdvisory{{ID: "CVE-2023-1234", Severity: "high", Summary: "credentials are logged", URL: "https://example.com/CVE-2023-1234"}},
+			},
+			{Kind: "provider", Source: "registry.terraform.io/example/beta", Version: "0.3.1", LatestVersion: "0.4.0"},
+			{Kind: "module", Source: "registry.terraform.io/terraform-aws-modules/vpc/aws", Version: "3.19.0", LatestVersion: "5.4.0"},
+			{Kind: "module", Source: "registry.terraform.io/terraform-aws-modules/iam/aws", Version: "5.30.0", Advisories: []models.AuditAdvisory{{ID: "GHSA-1234"}}},
+		},
+	}, readAudit(t, filepath.Join(path, "test-default-audit.json")))
+
+	t.Run("unreachable registry", func(t *testing.T) {
+		subject.registryURL = func(string) string { return "http://127.0.0.1:1" }
+		_, err := subject.Run(ctx, nil, path, map[string]string{})
+		Ok(t, err)
+		report := readAudit(t, filepath.Join(path, "test-default-audit.json"))
+		Equals(t, 2, len(report.Findings))
+		Equals(t, []string{"Unable to look up the latest versions on registry.terraform.io, outdated versions weren't checked."}, report.Notes)
+	})
+
+	t.Run("no lock file", func(t *testing.T) {
+		empty := t.TempDir()
+		_, err := NewAuditStepRunner("").Run(ctx, nil, empty, map[string]string{})
+		Ok(t, err)
+		Equals(t, models.AuditReport{
+			Notes: []string{"There's no .terraform.lock.hcl, providers weren't audited. An init step must run before the audit step."},
+		}, readAudit(t, filepath.Join(empty, "test-default-audit.json")))
+	})
+
+	t.Run("invalid advisories", func(t *testing.T) {
+		Ok(t, os.WriteFile(advisoriesPath, []byte("advisories:\n  - id: CVE-1\n    source: hashicorp/aws\n    versions: latest\n"), 0600))
+		_, err := subject.Run(ctx, nil, path, map[string]string{})
+		ErrContains(t, "versions of CVE-1: Malformed constraint: latest", err)
+	})
+}
+
+func TestNormalizeSourceAddress(t *testing.T) {
+	Equals(t, "registry.terraform.io/hashicorp/aws", normalizeSourceAddress("hashicorp/AWS"))
+	Equals(t, "registry.terraform.io/hashicorp/aws", normalizeSourceAddress("registry.terraform.io/hashicorp/aws"))
+	Equals(t, "app.terraform.io/example/vpc/aws", normalizeSourceAddress("app.terraform.io/example/vpc/aws//modules/subnets"))
+	Equals(t, "localhost:8080/example/vpc/aws", normalizeSourceAddress("localhost:8080/example/vpc/aws"))
+}
+
+func readAudit(t *testing.T, path string) models.AuditReport {
+	contents, err := os.ReadFile(path) // nolint: gosec
+	Ok(t, err)
+	var report models.AuditReport
+	Ok(t, json.Unmarshal(contents, &report))
+	return report
+}
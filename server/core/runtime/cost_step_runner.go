@@ -0,0 +1,42 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	runtimemodels "github.com/runatlantis/atlantis/server/core/runtime/models"
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+const infracostBinaryName = "infracost"
+
+func NewCostStepRunner(exec runtimemodels.Exec) Runner {
+	return NewPlanTypeStepRunnerDelegate(&costStepRunner{exec: exec}, NullRunner{})
+}
+
+// costStepRunner estimates the cost of the plan with infracost from the plan
+// in json format, written by a previous show step, and writes the estimate to
+// the project's cost file.
+type costStepRunner struct {
+	exec runtimemodels.Exec
+}
+
+func (c *costStepRunner) Run(ctx command.ProjectContext, extraArgs []string, path string, envs map[string]string) (string, error) {
+	showResultFile := filepath.Join(path, ctx.GetShowResultFileName())
+	if _, err := os.Stat(showResultFile); err != nil {
+		return "", fmt.Errorf("cost step requires the plan in json format, a show step must run before it: %w", err)
+	}
+	costFile := filepath.Join(path, ctx.GetCostFileName())
+
+	args := []string{infracostBinaryName, "breakdown", "--path", showResultFile, "--format", "json", "--out-file", costFile}
+	args = append(args, extraArgs...)
+	output, err := c.exec.CombinedOutput(args, envs, path)
+	if err != nil {
+		return "", fmt.Errorf("running infracost: %s: %w", output, err)
+	}
+	return "", nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/core/runtime/models/mocks"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestCostStepRunner(t *testing.T) {
+	RegisterMockTestingT(t)
+	path := t.TempDir()
+	envs := map[string]string{"key": "val"}
+	ctx := command.ProjectContext{
+		Workspace:   "default",
+		ProjectName: "test",
+		Log:         logging.NewNoopLogger(t),
+	}
+	mockExec := mocks.NewMockExec()
+	subject := costStepRunner{exec: mockExec}
+
+	t.Run("requires show", func(t *testing.T) {
+		_, err := subject.Run(ctx, nil, path, envs)
+		ErrContains(t, "a show step must run before it", err)
+	})
+
+	Ok(t, os.WriteFile(filepath.Join(path, "test-default.json"), []byte("{}"), 0600))
+	args := []string{
+		"infracost", "breakdown", "--path", filepath.Join(path, "test-default.json"),
+		"--format", "json", "--out-file", filepath.Join(path, "test-default-cost.json"),
+		"--show-skipped",
+	}
+
+	t.Run("success", func(t *testing.T) {
+		When(mockExec.CombinedOutput(args, envs, path)).ThenReturn("Evaluating plan...", nil)
+		out, err := subject.Run(ctx, []string{"--show-skipped"}, path, envs)
+		Ok(t, err)
+		Equals(t, "", out)
+	})
+
+	t.Run("infracost error", func(t *testing.T) {
+		When(mockExec.CombinedOutput(args, envs, path)).ThenReturn("No INFRACOST_API_KEY environment variable is set.", errors.New("exit status 1"))
+		_, err := subject.Run(ctx, []string{"--show-skipped"}, path, envs)
+		ErrEquals(t, "running infracost: No INFRACOST_API_KEY environment variable is set.: exit status 1", err)
+	})
+}
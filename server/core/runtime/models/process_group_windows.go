@@ -0,0 +1,22 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build windows
+
+package models
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// setProcessGroup does nothing, processes can't be interrupted on Windows.
+func setProcessGroup(_ *exec.Cmd) {}
+
+func interruptProcess(_ *exec.Cmd) error {
+	return errors.New("interrupting processes isn't supported on windows")
+}
+
+func killProcess(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// ErrCancelled is wrapped by the error of commands that were interrupted
+// because their pull request's commands were cancelled.
+var ErrCancelled = errors.New("cancelled")
+
+// RunningProcesses tracks the processes ShellCommandRunner runs and the
+// Kubernetes jobs JobRunner runs for pull requests so they can be
+// interrupted when the pull request's commands are cancelled.
+var RunningProcesses = NewProcessTracker()
+
+// ProcessTracker tracks the processes running for each pull request.
+type ProcessTracker struct {
+	mutex     sync.Mutex
+	processes map[string]map[*exec.Cmd]struct{}
+	// interrupted are the processes that were interrupted and haven't
+	// exited yet.
+	interrupted map[*exec.Cmd]struct{}
+	// remote are the commands that don't run as local processes, ex.
+	// Kubernetes jobs.
+	remote map[string]map[*remoteCommand]struct{}
+}
+
+type remoteCommand struct {
+	cancel    context.CancelFunc
+	cancelled bool
+}
+
+func NewProcessTracker() *ProcessTracker {
+	return &ProcessTracker{
+		processes:   make(map[string]map[*exec.Cmd]struct{}),
+		interrupted: make(map[*exec.Cmd]struct{}),
+		remote:      make(map[string]map[*remoteCommand]struct{}),
+	}
+}
+
+// Add tracks the started cmd as running for the pull request pullNum of
+// repoFullName.
+func (p *ProcessTracker) Add(repoFullName string, pullNum int, cmd *exec.Cmd) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	key := processKey(repoFullName, pullNum)
+	if p.processes[key] == nil {
+		p.processes[key] = make(map[*exec.Cmd]struct{})
+	}
+	p.processes[key][cmd] = struct{}{}
+}
+
+// Remove stops tracking cmd once it exited. It returns true if cmd was
+// interrupted.
+func (p *ProcessTracker) Remove(repoFullName string, pullNum int, cmd *exec.Cmd) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	key := processKey(repoFullName, pullNum)
+	delete(p.processes[key], cmd)
+	if len(p.processes[key]) == 0 {
+		delete(p.processes, key)
+	}
+	_, interrupted := p.interrupted[cmd]
+	delete(p.interrupted, cmd)
+	return interrupted
+}
+
+// AddRemote tracks a command running for the pull request pullNum of
+// repoFullName that isn't a local process, ex. a Kubernetes job. cancel is
+// called when the pull request's commands are cancelled, the command must
+// then stop. The returned function stops tracking the command once it
+// completed and returns true if it was cancelled.
+func (p *ProcessTracker) AddRemote(repoFullName string, pullNum int, cancel context.CancelFunc) func() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	key := processKey(repoFullName, pullNum)
+	if p.remote[key] == nil {
+		p.remote[key] = make(map[*remoteCommand]struct{})
+	}
+	cmd := &remoteCommand{cancel: cancel}
+	p.remote[key][cmd] = struct{}{}
+	return func() bool {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		delete(p.remote[key], cmd)
+		if len(p.remote[key]) == 0 {
+			delete(p.remote, key)
+		}
+		return cmd.cancelled
+	}
+}
+
+// Interrupt interrupts the processes running for the pull request pullNum of
+// repoFullName so they can stop cleanly, ex. terraform releases the state
+// lock it holds. Processes still running after gracePeriod are killed. Remote
+// commands are cancelled. It returns how many commands were interrupted.
+func (p *ProcessTracker) Interrupt(repoFullName string, pullNum int, gracePeriod time.Duration) int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	key := processKey(repoFullName, pullNum)
+	var cmds []*exec.Cmd
+	for cmd := range p.processes[key] {
+		p.interrupted[cmd] = struct{}{}
+		if err := interruptProcess(cmd); err != nil {
+			killProcess(cmd) // nolint: errcheck
+		}
+		cmds = append(cmds, cmd)
+	}
+	for cmd := range p.remote[key] {
+		if !cmd.cancelled {
+			cmd.cancelled = true
+			cmd.cancel()
+		}
+	}
+	if len(cmds) > 0 {
+		time.AfterFunc(gracePeriod, func() {
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+			for _, cmd := range cmds {
+				if _, running := p.interrupted[cmd]; running {
+					killProcess(cmd) // nolint: errcheck
+				}
+			}
+		})
+	}
+	return len(cmds) + len(p.remote[key])
+}
+
+func processKey(repoFullName string, pullNum int) string {
+	return fmt.Sprintf("%s#%d", repoFullName, pullNum)
+}
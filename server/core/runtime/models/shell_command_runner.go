@@ -60,6 +60,7 @@ func NewShellCommandRunner(
 	cmd := exec.Command(shell.Shell, args...) // #nosec
 	cmd.Env = environ
 	cmd.Dir = workingDir
+	setProcessGroup(cmd)
 
 	return &ShellCommandRunner{
 		command:       command,
@@ -122,6 +123,7 @@ func (s *ShellCommandRunner) RunCommandAsync(ctx command.ProjectContext) (chan<-
 			outCh <- Line{Err: err}
 			return
 		}
+		RunningProcesses.Add(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, s.cmd)
 
 		// If we get anything on inCh, write it to stdin.
 		// This function will exit when inCh is closed which we do in our defer.
@@ -171,6 +173,14 @@ func (s *ShellCommandRunner) RunCommandAsync(ctx command.ProjectContext) (chan<-
 
 		// Wait for the command to complete.
 		err = s.cmd.Wait()
+		if RunningProcesses.Remove(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, s.cmd) {
+			if s.streamOutput {
+				s.outputHandler.Send(ctx, "Cancelled, the command was interrupted.", false)
+			}
+			if err != nil {
+				err = fmt.Errorf("%w: %w", ErrCancelled, err)
+			}
+		}
 
 		dur := time.Since(start)
 		log := ctx.Log.With("duration", dur)
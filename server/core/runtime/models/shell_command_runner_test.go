@@ -4,14 +4,17 @@
 package models_test
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	. "github.com/petergtz/pegomock/v4"
 	"github.com/runatlantis/atlantis/server/core/runtime/models"
 	"github.com/runatlantis/atlantis/server/events/command"
+	internalmodels "github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/jobs/mocks"
 	logmocks "github.com/runatlantis/atlantis/server/logging/mocks"
 	. "github.com/runatlantis/atlantis/testing"
@@ -81,3 +84,36 @@ func TestShellCommandRunner_Run(t *testing.T) {
 		})
 	}
 }
+
+func TestShellCommandRunner_Run_Interrupted(t *testing.T) {
+	RegisterMockTestingT(t)
+	log := logmocks.NewMockSimpleLogging()
+	When(log.With(Any[string](), Any[any]())).ThenReturn(log)
+	ctx := command.ProjectContext{
+		Log:        log,
+		Workspace:  "default",
+		RepoRelDir: ".",
+		Pull:       internalmodels.PullRequest{Num: 1, BaseRepo: internalmodels.Repo{FullName: "owner/repo"}},
+	}
+	cwd, err := os.Getwd()
+	Ok(t, err)
+	runner := models.NewShellCommandRunner(nil, "sleep 30", nil, cwd, false, mocks.NewMockProjectCommandOutputHandler())
+
+	errs := make(chan error)
+	go func() {
+		_, err := runner.Run(ctx)
+		errs <- err
+	}()
+	// Wait for the command to start.
+	for models.RunningProcesses.Interrupt("owner/repo", 1, time.Minute) == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case err := <-errs:
+		Assert(t, errors.Is(err, models.ErrCancelled), "expected the error to wrap ErrCancelled, got %v", err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("the command wasn't interrupted")
+	}
+	Equals(t, 0, models.RunningProcesses.Interrupt("owner/repo", 1, time.Minute))
+}
@@ -0,0 +1,46 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-version"
+	"github.com/runatlantis/atlantis/server/core/terraform"
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// NewOutputStepRunner returns a runner outputting the Terraform outputs of a
+// project's workspace as JSON.
+func NewOutputStepRunner(executor TerraformExec, defaultTfDistribution terraform.Distribution, defaultTFVersion *version.Version) Runner {
+	return &outputStepRunner{
+		terraformExecutor:     executor,
+		defaultTfDistribution: defaultTfDistribution,
+		defaultTFVersion:      defaultTFVersion,
+	}
+}
+
+// outputStepRunner runs terraform output -json.
+type outputStepRunner struct {
+	terraformExecutor     TerraformExec
+	defaultTfDistribution terraform.Distribution
+	defaultTFVersion      *version.Version
+}
+
+func (o *outputStepRunner) Run(ctx command.ProjectContext, _ []string, path string, envs map[string]string) (string, error) {
+	tfDistribution := o.defaultTfDistribution
+	tfVersion := o.defaultTFVersion
+	if ctx.TerraformDistribution != nil {
+		tfDistribution = terraform.NewDistribution(*ctx.TerraformDistribution)
+	}
+	if ctx.TerraformVersion != nil {
+		tfVersion = ctx.TerraformVersion
+	}
+
+	output, err := o.terraformExecutor.RunCommandWithVersion(ctx, path, []string{"output", "-json"}, envs, tfDistribution, tfVersion, ctx.Workspace)
+	if err != nil {
+		return "", fmt.Errorf("running terraform output: %w", err)
+	}
+	return output, nil
+}
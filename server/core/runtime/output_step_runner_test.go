@@ -0,0 +1,43 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	. "github.com/petergtz/pegomock/v4"
+	tf "github.com/runatlantis/atlantis/server/core/terraform"
+	"github.com/runatlantis/atlantis/server/core/terraform/mocks"
+	tfclientmocks "github.com/runatlantis/atlantis/server/core/terraform/tfclient/mocks"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestOutputStepRunner(t *testing.T) {
+	RegisterMockTestingT(t)
+	path := t.TempDir()
+	envs := map[string]string{"key": "val"}
+	tfDistribution := tf.NewDistributionTerraformWithDownloader(mocks.NewMockDownloader())
+	tfVersion, _ := version.NewVersion("1.5.0")
+	ctx := command.ProjectContext{
+		Workspace: "pr-12",
+		Log:       logging.NewNoopLogger(t),
+	}
+	mockExecutor := tfclientmocks.NewMockClient()
+	subject := NewOutputStepRunner(mockExecutor, tfDistribution, tfVersion)
+
+	When(mockExecutor.RunCommandWithVersion(ctx, path, []string{"output", "-json"}, envs, tfDistribution, tfVersion, "pr-12")).
+		ThenReturn(`{"url":{"sensitive":false,"value":"https://pr-12.example.com"}}`, nil)
+	out, err := subject.Run(ctx, nil, path, envs)
+	Ok(t, err)
+	Equals(t, `{"url":{"sensitive":false,"value":"https://pr-12.example.com"}}`, out)
+
+	When(mockExecutor.RunCommandWithVersion(ctx, path, []string{"output", "-json"}, envs, tfDistribution, tfVersion, "pr-12")).
+		ThenReturn("", errors.New("no state"))
+	_, err = subject.Run(ctx, nil, path, envs)
+	ErrEquals(t, "running terraform output: no state", err)
+}
@@ -0,0 +1,157 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// encryptedPlanfileHeader prefixes encrypted planfiles so they're never
+// mistaken for plaintext ones.
+var encryptedPlanfileHeader = []byte("ATLANTIS-ENCRYPTED-PLANFILE-V1\n")
+
+// PlanfileEncryptor encrypts planfiles at rest with AES-256-GCM. Planfiles
+// can contain sensitive values so they're encrypted as soon as they're
+// generated and only decrypted while a command needs them, ex. apply.
+type PlanfileEncryptor struct {
+	aead cipher.AEAD
+}
+
+// NewPlanfileEncryptor returns an encryptor using the base64 encoded 256 bit
+// key, ex. generated with openssl rand -base64 32.
+func NewPlanfileEncryptor(encodedKey string) (*PlanfileEncryptor, error) {
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("decoding planfile encryption key: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("planfile encryption key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &PlanfileEncryptor{aead: aead}, nil
+}
+
+// Encrypt encrypts the planfile at path in place. It's a no-op if there's no
+// planfile or it's already encrypted.
+func (e *PlanfileEncryptor) Encrypt(path string) error {
+	contents, info, err := readPlanfile(path)
+	if err != nil || info == nil || bytes.HasPrefix(contents, encryptedPlanfileHeader) {
+		return err
+	}
+	encrypted, err := e.EncryptFile(filepath.Base(path), contents)
+	if err != nil {
+		return err
+	}
+	return replacePlanfile(path, info, encrypted)
+}
+
+// Decrypt decrypts the planfile at path in place. It's a no-op if there's no
+// planfile or it isn't encrypted, ex. it was generated before encryption was
+// enabled.
+func (e *PlanfileEncryptor) Decrypt(path string) error {
+	contents, info, err := readPlanfile(path)
+	if err != nil || info == nil || !bytes.HasPrefix(contents, encryptedPlanfileHeader) {
+		return err
+	}
+	plaintext, err := e.decrypt(filepath.Base(path), contents)
+	if err != nil {
+		return err
+	}
+	return replacePlanfile(path, info, plaintext)
+}
+
+// ReadPlanfile returns the decrypted contents of the planfile at path, leaving
+// it encrypted at rest.
+func (e *PlanfileEncryptor) ReadPlanfile(path string) ([]byte, error) {
+	contents, err := os.ReadFile(path) // nolint: gosec
+	if err != nil {
+		return nil, err
+	}
+	return e.DecryptFile(filepath.Base(path), contents)
+}
+
+// EncryptFile returns plaintext, the contents of a file named name holding
+// plan data, ex. the JSON of a plan, encrypted like planfiles. The file can
+// only be decrypted under the same name.
+func (e *PlanfileEncryptor) EncryptFile(name string, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, e.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	encrypted := append([]byte{}, encryptedPlanfileHeader...)
+	encrypted = append(encrypted, nonce...)
+	return e.aead.Seal(encrypted, nonce, plaintext, []byte(name)), nil
+}
+
+// DecryptFile returns the decrypted contents of the file named name encrypted
+// by EncryptFile. Contents that aren't encrypted, ex. written before
+// encryption was enabled, are returned as is.
+func (e *PlanfileEncryptor) DecryptFile(name string, contents []byte) ([]byte, error) {
+	if !bytes.HasPrefix(contents, encryptedPlanfileHeader) {
+		return contents, nil
+	}
+	return e.decrypt(name, contents)
+}
+
+// decrypt decrypts contents, the encrypted file named name.
+func (e *PlanfileEncryptor) decrypt(name string, contents []byte) ([]byte, error) {
+	contents = contents[len(encryptedPlanfileHeader):]
+	if len(contents) < e.aead.NonceSize() {
+		return nil, errors.New("encrypted planfile is truncated")
+	}
+	nonce, ciphertext := contents[:e.aead.NonceSize()], contents[e.aead.NonceSize():]
+	// The planfile's name is authenticated so planfiles can't be swapped
+	// between projects.
+	plaintext, err := e.aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return nil, fmt.Errorf("decrypting planfile, was it encrypted with a different key?: %w", err)
+	}
+	return plaintext, nil
+}
+
+// readPlanfile returns the contents and info of the planfile at path, or nil
+// info if it doesn't exist.
+func readPlanfile(path string) ([]byte, os.FileInfo, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	contents, err := os.ReadFile(path) // nolint: gosec
+	if err != nil {
+		return nil, nil, err
+	}
+	return contents, info, nil
+}
+
+// replacePlanfile atomically replaces the planfile at path with contents. The
+// modification time is kept since it's the time the plan was generated.
+func replacePlanfile(path string, info os.FileInfo, contents []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, contents, info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := os.Chtimes(tmp, info.ModTime(), info.ModTime()); err != nil {
+		os.Remove(tmp) // nolint: errcheck
+		return err
+	}
+	return os.Rename(tmp, path)
+}
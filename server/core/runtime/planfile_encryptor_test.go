@@ -0,0 +1,82 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+const planfileKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+func TestNewPlanfileEncryptor_InvalidKey(t *testing.T) {
+	_, err := runtime.NewPlanfileEncryptor("not base64!")
+	Assert(t, err != nil, "exp error")
+	_, err = runtime.NewPlanfileEncryptor("c2hvcnQ=")
+	ErrEquals(t, "planfile encryption key must be 32 bytes, got 5", err)
+}
+
+func TestPlanfileEncryptor(t *testing.T) {
+	encryptor, err := runtime.NewPlanfileEncryptor(planfileKey)
+	Ok(t, err)
+	planPath := filepath.Join(t.TempDir(), "default.tfplan")
+	plan := []byte("plan with a secret")
+	Ok(t, os.WriteFile(planPath, plan, 0600))
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	Ok(t, os.Chtimes(planPath, modTime, modTime))
+
+	Ok(t, encryptor.Encrypt(planPath))
+	encrypted, err := os.ReadFile(planPath)
+	Ok(t, err)
+	Assert(t, !bytes.Contains(encrypted, []byte("secret")), "exp planfile to be encrypted")
+	info, err := os.Stat(planPath)
+	Ok(t, err)
+	Equals(t, modTime, info.ModTime())
+
+	t.Log("encrypting twice is a no-op")
+	Ok(t, encryptor.Encrypt(planPath))
+	again, err := os.ReadFile(planPath)
+	Ok(t, err)
+	Equals(t, encrypted, again)
+
+	t.Log("other keys can't decrypt")
+	other, err := runtime.NewPlanfileEncryptor("ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA=")
+	Ok(t, err)
+	Assert(t, other.Decrypt(planPath) != nil, "exp decrypting with another key to fail")
+
+	t.Log("planfiles can't be moved to another project")
+	swapped := filepath.Join(filepath.Dir(planPath), "other-default.tfplan")
+	Ok(t, os.WriteFile(swapped, encrypted, 0600))
+	Assert(t, encryptor.Decrypt(swapped) != nil, "exp decrypting a renamed planfile to fail")
+
+	t.Log("planfiles can be read without decrypting them at rest")
+	read, err := encryptor.ReadPlanfile(planPath)
+	Ok(t, err)
+	Equals(t, plan, read)
+	again, err = os.ReadFile(planPath)
+	Ok(t, err)
+	Equals(t, encrypted, again)
+
+	Ok(t, encryptor.Decrypt(planPath))
+	decrypted, err := os.ReadFile(planPath)
+	Ok(t, err)
+	Equals(t, plan, decrypted)
+	info, err = os.Stat(planPath)
+	Ok(t, err)
+	Equals(t, modTime, info.ModTime())
+
+	t.Log("plaintext and missing planfiles are left as is")
+	Ok(t, encryptor.Decrypt(planPath))
+	decrypted, err = os.ReadFile(planPath)
+	Ok(t, err)
+	Equals(t, plan, decrypted)
+	Ok(t, encryptor.Encrypt(filepath.Join(t.TempDir(), "missing.tfplan")))
+	Ok(t, encryptor.Decrypt(filepath.Join(t.TempDir(), "missing.tfplan")))
+}
@@ -0,0 +1,147 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	hmacSignaturePrefix    = "hmac-sha256:"
+	ed25519SignaturePrefix = "ed25519:"
+)
+
+// PlanfileSigner signs planfiles when they're generated and verifies them
+// before they're applied, so planfiles swapped on disk between plan and apply
+// are never applied. Planfiles are signed with HMAC-SHA256 or Ed25519, the
+// signatures are stored next to them in PlanfileSignaturePath.
+type PlanfileSigner struct {
+	signingKey
+}
+
+// NewPlanfileSigner returns a signer using key, either a base64 encoded
+// 256 bit HMAC key, ex. generated with openssl rand -base64 32, or a PEM
+// encoded Ed25519 private key, ex. generated with openssl genpkey -algorithm
+// ed25519.
+func NewPlanfileSigner(key string) (*PlanfileSigner, error) {
+	parsed, err := parseSigningKey(key, "planfile")
+	if err != nil {
+		return nil, err
+	}
+	return &PlanfileSigner{signingKey: parsed}, nil
+}
+
+// PlanfileSignaturePath returns the path of the signature of the planfile at
+// path.
+func PlanfileSignaturePath(path string) string {
+	return path + ".sig"
+}
+
+// Sign signs the planfile at path. It's a no-op if there's no planfile.
+func (s *PlanfileSigner) Sign(path string) error {
+	contents, info, err := readPlanfile(path)
+	if err != nil || info == nil {
+		return err
+	}
+	return os.WriteFile(PlanfileSignaturePath(path), []byte(s.sign(planfileDigest(path, contents))+"\n"), 0600)
+}
+
+// Verify verifies the signature of the planfile at path. It's a no-op if
+// there's no planfile, otherwise it errors unless the planfile was signed by
+// Sign and hasn't changed since.
+func (s *PlanfileSigner) Verify(path string) error {
+	contents, info, err := readPlanfile(path)
+	if err != nil || info == nil {
+		return err
+	}
+	stored, err := os.ReadFile(PlanfileSignaturePath(path)) // nolint: gosec
+	if os.IsNotExist(err) {
+		return errors.New("planfile isn't signed, it must be planned again")
+	}
+	if err != nil {
+		return fmt.Errorf("reading planfile signature: %w", err)
+	}
+	if !s.verify(planfileDigest(path, contents), strings.TrimSpace(string(stored))) {
+		return errors.New("planfile signature is invalid, the planfile was modified after it was planned or signed with a different key")
+	}
+	return nil
+}
+
+// planfileDigest is the digest that's signed. The planfile's path is included
+// so planfiles can't be swapped between projects or pull requests.
+func planfileDigest(path string, contents []byte) []byte {
+	digest := sha256.New()
+	digest.Write([]byte(path))
+	digest.Write([]byte{0})
+	digest.Write(contents)
+	return digest.Sum(nil)
+}
+
+// signingKey signs digests with HMAC-SHA256 or Ed25519.
+type signingKey struct {
+	hmacKey    []byte
+	privateKey ed25519.PrivateKey
+}
+
+// parseSigningKey parses key, either a base64 encoded HMAC key of at least
+// 256 bits or a PEM encoded Ed25519 private key. name is what the key signs,
+// for errors.
+func parseSigningKey(key string, name string) (signingKey, error) {
+	if block, _ := pem.Decode([]byte(key)); block != nil {
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return signingKey{}, fmt.Errorf("parsing %s signing key: %w", name, err)
+		}
+		privateKey, ok := parsed.(ed25519.PrivateKey)
+		if !ok {
+			return signingKey{}, fmt.Errorf("%s signing key must be an Ed25519 private key", name)
+		}
+		return signingKey{privateKey: privateKey}, nil
+	}
+	hmacKey, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+	if err != nil {
+		return signingKey{}, fmt.Errorf("decoding %s signing key: %w", name, err)
+	}
+	if len(hmacKey) < 32 {
+		return signingKey{}, fmt.Errorf("%s signing key must be at least 32 bytes, got %d", name, len(hmacKey))
+	}
+	return signingKey{hmacKey: hmacKey}, nil
+}
+
+// sign returns the signature of digest, prefixed with its algorithm.
+func (k signingKey) sign(digest []byte) string {
+	if k.privateKey != nil {
+		return ed25519SignaturePrefix + base64.StdEncoding.EncodeToString(ed25519.Sign(k.privateKey, digest))
+	}
+	return hmacSignaturePrefix + hex.EncodeToString(k.mac(digest))
+}
+
+// verify returns whether signature is the signature of digest.
+func (k signingKey) verify(digest []byte, signature string) bool {
+	switch {
+	case k.privateKey != nil && strings.HasPrefix(signature, ed25519SignaturePrefix):
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(signature, ed25519SignaturePrefix))
+		return err == nil && ed25519.Verify(k.privateKey.Public().(ed25519.PublicKey), digest, decoded)
+	case k.hmacKey != nil && strings.HasPrefix(signature, hmacSignaturePrefix):
+		decoded, err := hex.DecodeString(strings.TrimPrefix(signature, hmacSignaturePrefix))
+		return err == nil && hmac.Equal(decoded, k.mac(digest))
+	}
+	return false
+}
+
+func (k signingKey) mac(digest []byte) []byte {
+	mac := hmac.New(sha256.New, k.hmacKey)
+	mac.Write(digest)
+	return mac.Sum(nil)
+}
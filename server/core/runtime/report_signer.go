@@ -0,0 +1,42 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"crypto/sha256"
+	"errors"
+)
+
+// ReportSigner signs the apply reports exported as change management
+// evidence so that they can't be altered after they're exported. Reports are
+// signed with HMAC-SHA256 or Ed25519, the signature covers the SHA-256 digest
+// of the report's JSON.
+type ReportSigner struct {
+	signingKey
+}
+
+// NewReportSigner returns a signer using key, either a base64 encoded 256 bit
+// HMAC key or a PEM encoded Ed25519 private key, like NewPlanfileSigner.
+func NewReportSigner(key string) (*ReportSigner, error) {
+	parsed, err := parseSigningKey(key, "apply report")
+	if err != nil {
+		return nil, err
+	}
+	return &ReportSigner{signingKey: parsed}, nil
+}
+
+// Sign returns the signature of report, ex. ed25519:<base64 signature>.
+func (s *ReportSigner) Sign(report []byte) string {
+	digest := sha256.Sum256(report)
+	return s.sign(digest[:])
+}
+
+// Verify errors unless signature is the signature of report.
+func (s *ReportSigner) Verify(report []byte, signature string) error {
+	digest := sha256.Sum256(report)
+	if !s.verify(digest[:], signature) {
+		return errors.New("apply report signature is invalid, the report was modified after it was signed or signed with a different key")
+	}
+	return nil
+}
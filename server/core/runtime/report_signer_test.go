@@ -0,0 +1,43 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestNewReportSigner_InvalidKey(t *testing.T) {
+	_, err := runtime.NewReportSigner("c2hvcnQ=")
+	ErrEquals(t, "apply report signing key must be at least 32 bytes, got 5", err)
+}
+
+func TestReportSigner(t *testing.T) {
+	cases := map[string]struct {
+		key       func(t *testing.T) string
+		expPrefix string
+	}{
+		"hmac":    {func(*testing.T) string { return planfileKey }, "hmac-sha256:"},
+		"ed25519": {ed25519SigningKey, "ed25519:"},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			signer, err := runtime.NewReportSigner(c.key(t))
+			Ok(t, err)
+			report := []byte(`{"ID":"1","AppliedBy":"alice"}`)
+			signature := signer.Sign(report)
+			Assert(t, strings.HasPrefix(signature, c.expPrefix), "unexpected signature %q", signature)
+			Ok(t, signer.Verify(report, signature))
+
+			ErrEquals(t, "apply report signature is invalid, the report was modified after it was signed or signed with a different key",
+				signer.Verify([]byte(`{"ID":"1","AppliedBy":"mallory"}`), signature))
+			other, err := runtime.NewReportSigner("ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA=")
+			Ok(t, err)
+			Assert(t, other.Verify(report, signature) != nil, "exp signature to be invalid with another key")
+		})
+	}
+}
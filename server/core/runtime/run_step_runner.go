@@ -72,6 +72,9 @@ func (r *RunStepRunner) Run(
 		"PLANFILE":                        filepath.Join(path, GetPlanFilename(ctx.Workspace, ctx.ProjectName)),
 		"SHOWFILE":                        filepath.Join(path, ctx.GetShowResultFileName()),
 		"POLICYCHECKFILE":                 filepath.Join(path, ctx.GetPolicyCheckResultFileName()),
+		"SARIFFILE":                       filepath.Join(path, ctx.GetSarifFileName()),
+		"COSTFILE":                        filepath.Join(path, ctx.GetCostFileName()),
+		"AUDITFILE":                       filepath.Join(path, ctx.GetAuditFileName()),
 		"PROJECT_NAME":                    ctx.ProjectName,
 		"PULL_AUTHOR":                     ctx.Pull.Author,
 		"PULL_NUM":                        fmt.Sprintf("%d", ctx.Pull.Num),
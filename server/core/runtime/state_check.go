@@ -0,0 +1,178 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/runatlantis/atlantis/server/core/terraform"
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// The state checks ApplyStepRunner can run before applying a plan.
+const (
+	// StateCheckWarn lists the resources that diverged since the plan was
+	// generated in the apply output.
+	StateCheckWarn = "warn"
+	// StateCheckAbort doesn't apply plans if the state changed since they
+	// were generated.
+	StateCheckAbort = "abort"
+)
+
+// tfState is the part of a Terraform state file compared by the state check.
+type tfState struct {
+	Serial    uint64            `json:"serial"`
+	Lineage   string            `json:"lineage"`
+	Resources []tfStateResource `json:"resources"`
+}
+
+type tfStateResource struct {
+	Module    string `json:"module"`
+	Mode      string `json:"mode"`
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Instances []struct {
+		IndexKey   any `json:"index_key"`
+		Attributes any `json:"attributes"`
+	} `json:"instances"`
+}
+
+// instances returns the attributes of the state's resource instances by
+// address, ex. module.network.aws_subnet.private[0].
+func (s *tfState) instances() map[string]any {
+	instances := make(map[string]any)
+	for _, resource := range s.Resources {
+		address := resource.Type + "." + resource.Name
+		if resource.Mode == "data" {
+			address = "data." + address
+		}
+		if resource.Module != "" {
+			address = resource.Module + "." + address
+		}
+		for _, instance := range resource.Instances {
+			switch key := instance.IndexKey.(type) {
+			case nil:
+				instances[address] = instance.Attributes
+			case string:
+				instances[fmt.Sprintf("%s[%q]", address, key)] = instance.Attributes
+			default:
+				instances[fmt.Sprintf("%s[%v]", address, key)] = instance.Attributes
+			}
+		}
+	}
+	return instances
+}
+
+// planPrevRunState returns the state the plan at planPath was generated from,
+// or nil if the planfile doesn't include it.
+func planPrevRunState(planPath string) (*tfState, error) {
+	planfile, err := zip.OpenReader(planPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening planfile: %w", err)
+	}
+	defer planfile.Close() // nolint: errcheck
+
+	// tfstate-prev is the state before it was refreshed, the one Terraform
+	// compares to the current state. Older versions of Terraform only
+	// include tfstate.
+	var stateFile *zip.File
+	for _, file := range planfile.File {
+		if file.Name == "tfstate-prev" || (file.Name == "tfstate" && stateFile == nil) {
+			stateFile = file
+		}
+	}
+	if stateFile == nil {
+		return nil, nil
+	}
+	reader, err := stateFile.Open()
+	if err != nil {
+		return nil, fmt.Errorf("reading planfile state: %w", err)
+	}
+	defer reader.Close() // nolint: errcheck
+	contents, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("reading planfile state: %w", err)
+	}
+	return parseState(string(contents))
+}
+
+// parseState parses the state in the output of terraform state pull, which is
+// empty if there's no state yet.
+func parseState(output string) (*tfState, error) {
+	start := strings.Index(output, "{")
+	if start < 0 {
+		return &tfState{}, nil
+	}
+	var state tfState
+	if err := json.NewDecoder(strings.NewReader(output[start:])).Decode(&state); err != nil {
+		return nil, fmt.Errorf("parsing state: %w", err)
+	}
+	return &state, nil
+}
+
+// checkState returns a message listing the resources that diverged if the
+// state changed since the plan at planPath was generated, or "" if it didn't.
+func (a *ApplyStepRunner) checkState(ctx command.ProjectContext, path string, planPath string, envs map[string]string, tfDistribution terraform.Distribution, tfVersion *version.Version) (string, error) {
+	planned, err := planPrevRunState(planPath)
+	if err != nil || planned == nil {
+		return "", err
+	}
+	out, err := a.TerraformExecutor.RunCommandWithVersion(ctx, path, []string{"state", "pull"}, envs, tfDistribution, tfVersion, ctx.Workspace)
+	if err != nil {
+		return "", fmt.Errorf("pulling state: %w", err)
+	}
+	current, err := parseState(out)
+	if err != nil {
+		return "", err
+	}
+	if current.Serial == planned.Serial && current.Lineage == planned.Lineage {
+		return "", nil
+	}
+	// Planning without a state doesn't create one so the lineages differ
+	// until the first apply without anything having changed.
+	if (planned.Lineage == "" || current.Lineage == "") && len(planned.Resources) == 0 && len(current.Resources) == 0 {
+		return "", nil
+	}
+	return stateChangedMessage(planned, current), nil
+}
+
+// stateChangedMessage explains how current differs from the planned state.
+func stateChangedMessage(planned *tfState, current *tfState) string {
+	change := fmt.Sprintf("serial %d, now %d", planned.Serial, current.Serial)
+	if current.Lineage != planned.Lineage {
+		change = fmt.Sprintf("lineage %q, now %q", planned.Lineage, current.Lineage)
+	}
+
+	plannedInstances := planned.instances()
+	currentInstances := current.instances()
+	var diverged []string
+	for address, attributes := range plannedInstances {
+		currentAttributes, ok := currentInstances[address]
+		switch {
+		case !ok:
+			diverged = append(diverged, fmt.Sprintf("* %s: removed", address))
+		case !reflect.DeepEqual(attributes, currentAttributes):
+			diverged = append(diverged, fmt.Sprintf("* %s: changed", address))
+		}
+	}
+	for address := range currentInstances {
+		if _, ok := plannedInstances[address]; !ok {
+			diverged = append(diverged, fmt.Sprintf("* %s: added", address))
+		}
+	}
+	sort.Strings(diverged)
+
+	resources := "No resources diverged."
+	if len(diverged) > 0 {
+		resources = "These resources diverged:\n" + strings.Join(diverged, "\n")
+	}
+	return fmt.Sprintf("The state changed since the plan was generated (%s), plan again to apply the current state. %s", change, resources)
+}
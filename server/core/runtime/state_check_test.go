@@ -0,0 +1,145 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime_test
+
+import (
+	"archive/zip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	version "github.com/hashicorp/go-version"
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	tf "github.com/runatlantis/atlantis/server/core/terraform"
+	"github.com/runatlantis/atlantis/server/core/terraform/mocks"
+	tfclientmocks "github.com/runatlantis/atlantis/server/core/terraform/tfclient/mocks"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+const plannedState = `{"version":4,"serial":3,"lineage":"abc","resources":[
+{"mode":"managed","type":"aws_s3_bucket","name":"logs","instances":[{"attributes":{"bucket":"logs"}}]},
+{"module":"module.net","mode":"managed","type":"aws_subnet","name":"private","instances":[{"index_key":0,"attributes":{"cidr":"10.0.0.0/24"}},{"index_key":1,"attributes":{"cidr":"10.0.1.0/24"}}]}
+]}`
+
+// writePlanfile writes a planfile whose previous run state is state.
+func writePlanfile(t *testing.T, planPath string, state string) {
+	f, err := os.Create(planPath)
+	Ok(t, err)
+	w := zip.NewWriter(f)
+	stateFile, err := w.Create("tfstate-prev")
+	Ok(t, err)
+	_, err = stateFile.Write([]byte(state))
+	Ok(t, err)
+	Ok(t, w.Close())
+	Ok(t, f.Close())
+}
+
+func TestRun_StateCheck(t *testing.T) {
+	cases := map[string]struct {
+		stateCheck   string
+		currentState string
+		stateErr     error
+		expApply     bool
+		expOutput    string
+		expErr       string
+	}{
+		"unchanged": {
+			stateCheck:   runtime.StateCheckAbort,
+			currentState: plannedState,
+			expApply:     true,
+			expOutput:    "apply output",
+		},
+		"changed with abort": {
+			stateCheck: runtime.StateCheckAbort,
+			currentState: `{"version":4,"serial":5,"lineage":"abc","resources":[
+{"mode":"managed","type":"aws_s3_bucket","name":"logs","instances":[{"attributes":{"bucket":"logs-renamed"}}]},
+{"module":"module.net","mode":"managed","type":"aws_subnet","name":"private","instances":[{"index_key":0,"attributes":{"cidr":"10.0.0.0/24"}}]},
+{"mode":"data","type":"aws_region","name":"current","instances":[{"attributes":{"name":"us-east-1"}}]}
+]}`,
+			expErr: "The state changed since the plan was generated (serial 3, now 5), plan again to apply the current state. These resources diverged:\n" +
+				"* aws_s3_bucket.logs: changed\n" +
+				"* data.aws_region.current: added\n" +
+				"* module.net.aws_subnet.private[1]: removed",
+		},
+		"changed with warn": {
+			stateCheck:   runtime.StateCheckWarn,
+			currentState: `{"version":4,"serial":4,"lineage":"def","resources":[]}`,
+			expApply:     true,
+			expOutput: "The state changed since the plan was generated (lineage \"abc\", now \"def\"), plan again to apply the current state. These resources diverged:\n" +
+				"* aws_s3_bucket.logs: removed\n" +
+				"* module.net.aws_subnet.private[0]: removed\n" +
+				"* module.net.aws_subnet.private[1]: removed\n\n" +
+				"apply output",
+		},
+		"pull fails with abort": {
+			stateCheck: runtime.StateCheckAbort,
+			stateErr:   errors.New("backend unreachable"),
+			expErr:     "unable to check if the state changed since plan, not applying: pulling state: backend unreachable",
+		},
+		"pull fails with warn": {
+			stateCheck: runtime.StateCheckWarn,
+			stateErr:   errors.New("backend unreachable"),
+			expApply:   true,
+			expOutput:  "apply output",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			planPath := filepath.Join(tmpDir, "default.tfplan")
+			writePlanfile(t, planPath, plannedState)
+			ctx := command.ProjectContext{
+				Log:        logging.NewNoopLogger(t),
+				Workspace:  "default",
+				RepoRelDir: ".",
+			}
+
+			RegisterMockTestingT(t)
+			terraform := tfclientmocks.NewMockClient()
+			tfDistribution := tf.NewDistributionTerraformWithDownloader(mocks.NewMockDownloader())
+			o := runtime.ApplyStepRunner{
+				TerraformExecutor:     terraform,
+				DefaultTFDistribution: tfDistribution,
+				StateCheck:            c.stateCheck,
+			}
+			When(terraform.RunCommandWithVersion(Any[command.ProjectContext](), Any[string](), Eq([]string{"state", "pull"}), Any[map[string]string](), Any[tf.Distribution](), Any[*version.Version](), Any[string]())).
+				ThenReturn(c.currentState, c.stateErr)
+			When(terraform.RunCommandWithVersion(Any[command.ProjectContext](), Any[string](), Eq([]string{"apply", "-input=false", `"` + planPath + `"`}), Any[map[string]string](), Any[tf.Distribution](), Any[*version.Version](), Any[string]())).
+				ThenReturn("apply output", nil)
+
+			output, err := o.Run(ctx, nil, tmpDir, map[string]string(nil))
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+			} else {
+				Ok(t, err)
+			}
+			Equals(t, c.expOutput, output)
+			_, err = os.Stat(planPath)
+			Equals(t, c.expApply, os.IsNotExist(err))
+		})
+	}
+}
+
+func TestRun_StateCheckNoState(t *testing.T) {
+	tmpDir := t.TempDir()
+	planPath := filepath.Join(tmpDir, "default.tfplan")
+	writePlanfile(t, planPath, `{"version":4,"serial":0,"lineage":"abc","resources":[]}`)
+
+	RegisterMockTestingT(t)
+	terraform := tfclientmocks.NewMockClient()
+	o := runtime.ApplyStepRunner{
+		TerraformExecutor:     terraform,
+		DefaultTFDistribution: tf.NewDistributionTerraformWithDownloader(mocks.NewMockDownloader()),
+		StateCheck:            runtime.StateCheckAbort,
+	}
+	When(terraform.RunCommandWithVersion(Any[command.ProjectContext](), Any[string](), Any[[]string](), Any[map[string]string](), Any[tf.Distribution](), Any[*version.Version](), Any[string]())).
+		ThenReturn("", nil)
+
+	_, err := o.Run(command.ProjectContext{Log: logging.NewNoopLogger(t), Workspace: "default"}, nil, tmpDir, map[string]string(nil))
+	Ok(t, err)
+}
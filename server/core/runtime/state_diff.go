@@ -0,0 +1,187 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/runatlantis/atlantis/server/core/terraform"
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// stateDiffBaseline is what the state after an apply is compared to: the
+// snapshot of the state the plan was generated from and the resources the
+// plan advertised changing.
+type stateDiffBaseline struct {
+	snapshot map[string]any
+	// advertised are the addresses of the instances the plan changes or
+	// reported as changed outside of Terraform.
+	advertised map[string]bool
+}
+
+// planAdvertisedJSON is the subset of terraform show -json output the
+// resources a plan advertised changing are read from.
+type planAdvertisedJSON struct {
+	ResourceChanges []planAdvertisedChange `json:"resource_changes"`
+	ResourceDrift   []planAdvertisedChange `json:"resource_drift"`
+}
+
+type planAdvertisedChange struct {
+	Address string `json:"address"`
+	Change  struct {
+		Actions []string `json:"actions"`
+	} `json:"change"`
+}
+
+// ValidStateDiffPattern returns an error if pattern, a resource type pattern
+// of the state diff, isn't valid.
+func ValidStateDiffPattern(pattern string) error {
+	_, err := path.Match(pattern, "")
+	return err
+}
+
+// stateDiffTargets returns whether resources of resourceType are part of the
+// state diff snapshots.
+func (a *ApplyStepRunner) stateDiffTargets(resourceType string) bool {
+	for _, pattern := range a.StateDiffResourceTypes {
+		if ok, _ := path.Match(pattern, resourceType); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// snapshot returns the attributes of the state's managed resource instances
+// targeted by the state diff, by address.
+func (a *ApplyStepRunner) snapshot(state *tfState) map[string]any {
+	var targeted tfState
+	for _, resource := range state.Resources {
+		if resource.Mode == "managed" && a.stateDiffTargets(resource.Type) {
+			targeted.Resources = append(targeted.Resources, resource)
+		}
+	}
+	return targeted.instances()
+}
+
+// stateDiffBaseline snapshots the state the plan at planPath was generated
+// from and reads the resources the plan advertised changing. It must be called
+// before the plan is applied. It returns nil if the planfile doesn't include
+// its state.
+func (a *ApplyStepRunner) stateDiffBaseline(ctx command.ProjectContext, path string, planPath string, envs map[string]string, tfDistribution terraform.Distribution, tfVersion *version.Version) (*stateDiffBaseline, error) {
+	planned, err := planPrevRunState(planPath)
+	if err != nil || planned == nil {
+		return nil, err
+	}
+	out, err := a.TerraformExecutor.RunCommandWithVersion(ctx, path, []string{"show", "-json", filepath.Clean(planPath)}, envs, tfDistribution, tfVersion, ctx.Workspace)
+	if err != nil {
+		return nil, fmt.Errorf("running terraform show: %w", err)
+	}
+	advertised, err := planAdvertisedAddresses(out)
+	if err != nil {
+		return nil, err
+	}
+	return &stateDiffBaseline{snapshot: a.snapshot(planned), advertised: advertised}, nil
+}
+
+// planAdvertisedAddresses returns the addresses of the instances the plan in
+// show, the output of terraform show -json, changes or reports as changed
+// outside of Terraform.
+func planAdvertisedAddresses(show string) (map[string]bool, error) {
+	start := strings.Index(show, "{")
+	if start < 0 {
+		return nil, fmt.Errorf("parsing plan json: no json in %q", show)
+	}
+	var plan planAdvertisedJSON
+	if err := json.NewDecoder(strings.NewReader(show[start:])).Decode(&plan); err != nil {
+		return nil, fmt.Errorf("parsing plan json: %w", err)
+	}
+	advertised := make(map[string]bool)
+	for _, rc := range plan.ResourceChanges {
+		actions := rc.Change.Actions
+		if len(actions) == 1 && (actions[0] == "no-op" || actions[0] == "read") {
+			continue
+		}
+		advertised[rc.Address] = true
+	}
+	for _, rc := range plan.ResourceDrift {
+		advertised[rc.Address] = true
+	}
+	return advertised, nil
+}
+
+// diffState snapshots the state after the apply and returns a report of the
+// changes to targeted resources the plan didn't advertise, or "" if there are
+// none.
+func (a *ApplyStepRunner) diffState(ctx command.ProjectContext, path string, baseline *stateDiffBaseline, envs map[string]string, tfDistribution terraform.Distribution, tfVersion *version.Version) (string, error) {
+	out, err := a.TerraformExecutor.RunCommandWithVersion(ctx, path, []string{"state", "pull"}, envs, tfDistribution, tfVersion, ctx.Workspace)
+	if err != nil {
+		return "", fmt.Errorf("pulling state: %w", err)
+	}
+	current, err := parseState(out)
+	if err != nil {
+		return "", err
+	}
+	return stateDiffReport(baseline, a.snapshot(current)), nil
+}
+
+// stateDiffReport lists the instances that were added, removed or changed
+// between the baseline snapshot and current that the plan didn't advertise.
+// Only the names of changed attributes are listed, their values may be
+// sensitive.
+func stateDiffReport(baseline *stateDiffBaseline, current map[string]any) string {
+	var unadvertised []string
+	for address, attributes := range baseline.snapshot {
+		if baseline.advertised[address] {
+			continue
+		}
+		currentAttributes, ok := current[address]
+		switch {
+		case !ok:
+			unadvertised = append(unadvertised, fmt.Sprintf("* %s: removed", address))
+		case !reflect.DeepEqual(attributes, currentAttributes):
+			change := "changed"
+			if names := changedAttributes(attributes, currentAttributes); len(names) > 0 {
+				change += " " + strings.Join(names, ", ")
+			}
+			unadvertised = append(unadvertised, fmt.Sprintf("* %s: %s", address, change))
+		}
+	}
+	for address := range current {
+		if _, ok := baseline.snapshot[address]; !ok && !baseline.advertised[address] {
+			unadvertised = append(unadvertised, fmt.Sprintf("* %s: added", address))
+		}
+	}
+	if len(unadvertised) == 0 {
+		return ""
+	}
+	sort.Strings(unadvertised)
+	return "The apply changed resources the plan didn't include:\n" + strings.Join(unadvertised, "\n")
+}
+
+// changedAttributes returns the sorted names of the top-level attributes that
+// differ between before and after.
+func changedAttributes(before any, after any) []string {
+	beforeAttrs, _ := before.(map[string]any)
+	afterAttrs, _ := after.(map[string]any)
+	var changed []string
+	for name, value := range beforeAttrs {
+		if afterValue, ok := afterAttrs[name]; !ok || !reflect.DeepEqual(value, afterValue) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range afterAttrs {
+		if _, ok := beforeAttrs[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
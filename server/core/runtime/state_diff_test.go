@@ -0,0 +1,85 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	version "github.com/hashicorp/go-version"
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	tf "github.com/runatlantis/atlantis/server/core/terraform"
+	"github.com/runatlantis/atlantis/server/core/terraform/mocks"
+	tfclientmocks "github.com/runatlantis/atlantis/server/core/terraform/tfclient/mocks"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestRun_StateDiff(t *testing.T) {
+	prevState := `{"version":4,"serial":3,"lineage":"abc","resources":[
+{"mode":"managed","type":"aws_iam_role","name":"app","instances":[{"attributes":{"name":"app","tags":{"team":"a"}}}]},
+{"mode":"managed","type":"aws_iam_policy","name":"app","instances":[{"attributes":{"name":"app","policy":"{}"}}]},
+{"mode":"managed","type":"aws_iam_user","name":"ci","instances":[{"attributes":{"name":"ci"}}]},
+{"mode":"managed","type":"aws_s3_bucket","name":"logs","instances":[{"attributes":{"bucket":"logs"}}]}
+]}`
+	show := `{"resource_changes":[
+{"address":"aws_iam_policy.app","change":{"actions":["update"]}},
+{"address":"aws_iam_role.app","change":{"actions":["no-op"]}},
+{"address":"aws_iam_user.deploy","change":{"actions":["create"]}}
+]}`
+	cases := map[string]struct {
+		currentState string
+		expOutput    string
+	}{
+		"only advertised changes": {
+			currentState: `{"version":4,"serial":4,"lineage":"abc","resources":[
+{"mode":"managed","type":"aws_iam_role","name":"app","instances":[{"attributes":{"name":"app","tags":{"team":"a"}}}]},
+{"mode":"managed","type":"aws_iam_policy","name":"app","instances":[{"attributes":{"name":"app","policy":"{\"Version\":\"2012-10-17\"}"}}]},
+{"mode":"managed","type":"aws_iam_user","name":"ci","instances":[{"attributes":{"name":"ci"}}]},
+{"mode":"managed","type":"aws_iam_user","name":"deploy","instances":[{"attributes":{"name":"deploy"}}]},
+{"mode":"managed","type":"aws_s3_bucket","name":"logs","instances":[{"attributes":{"bucket":"logs-renamed"}}]}
+]}`,
+			expOutput: "apply output",
+		},
+		"unadvertised changes": {
+			currentState: `{"version":4,"serial":4,"lineage":"abc","resources":[
+{"mode":"managed","type":"aws_iam_role","name":"app","instances":[{"attributes":{"name":"app","tags":{"team":"b"},"tags_all":{"team":"b"}}}]},
+{"mode":"managed","type":"aws_iam_policy","name":"app","instances":[{"attributes":{"name":"app","policy":"{}"}}]},
+{"mode":"managed","type":"aws_iam_access_key","name":"ci","instances":[{"index_key":0,"attributes":{"user":"ci"}}]}
+]}`,
+			expOutput: "apply output\n\n" +
+				"The apply changed resources the plan didn't include:\n" +
+				"* aws_iam_access_key.ci[0]: added\n" +
+				"* aws_iam_role.app: changed tags, tags_all\n" +
+				"* aws_iam_user.ci: removed",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			planPath := filepath.Join(tmpDir, "default.tfplan")
+			writePlanfile(t, planPath, prevState)
+
+			RegisterMockTestingT(t)
+			terraform := tfclientmocks.NewMockClient()
+			o := runtime.ApplyStepRunner{
+				TerraformExecutor:      terraform,
+				DefaultTFDistribution:  tf.NewDistributionTerraformWithDownloader(mocks.NewMockDownloader()),
+				StateDiffResourceTypes: []string{"aws_iam_*"},
+			}
+			When(terraform.RunCommandWithVersion(Any[command.ProjectContext](), Any[string](), Eq([]string{"show", "-json", planPath}), Any[map[string]string](), Any[tf.Distribution](), Any[*version.Version](), Any[string]())).
+				ThenReturn(show, nil)
+			When(terraform.RunCommandWithVersion(Any[command.ProjectContext](), Any[string](), Eq([]string{"apply", "-input=false", `"` + planPath + `"`}), Any[map[string]string](), Any[tf.Distribution](), Any[*version.Version](), Any[string]())).
+				ThenReturn("apply output", nil)
+			When(terraform.RunCommandWithVersion(Any[command.ProjectContext](), Any[string](), Eq([]string{"state", "pull"}), Any[map[string]string](), Any[tf.Distribution](), Any[*version.Version](), Any[string]())).
+				ThenReturn(c.currentState, nil)
+
+			output, err := o.Run(command.ProjectContext{Log: logging.NewNoopLogger(t), Workspace: "default"}, nil, tmpDir, map[string]string(nil))
+			Ok(t, err)
+			Equals(t, c.expOutput, output)
+		})
+	}
+}
@@ -0,0 +1,378 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/agents"
+	"github.com/runatlantis/atlantis/server/core/terraform/ansi"
+	"github.com/runatlantis/atlantis/server/core/terraform/tfcloud"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/utils"
+)
+
+const (
+	// tfcRunPrefix is written in the planfiles of projects planned by
+	// Terraform Cloud runs, after the remote ops header, followed by the ID
+	// of the run to apply.
+	tfcRunPrefix = "tfc-run: "
+	// defaultTFCPollInterval is how often runs are polled while they're in
+	// progress.
+	defaultTFCPollInterval = 5 * time.Second
+	// tfcRunTimeout is how long the plan or apply of a run can take.
+	tfcRunTimeout = time.Hour
+)
+
+// TFCRuns plans and applies the projects mapped to a Terraform Cloud or
+// Enterprise workspace with runs created by its API, instead of running
+// terraform. Runs are never applied automatically, they're applied by
+// atlantis apply so the apply requirements and locks of Atlantis still gate
+// them.
+type TFCRuns struct {
+	// Client is nil if --tfe-token isn't set.
+	Client              *tfcloud.Client
+	CommitStatusUpdater StatusUpdater
+	// PollInterval is how often runs are polled while they're in progress.
+	// defaultTFCPollInterval is used if it's 0.
+	PollInterval time.Duration
+}
+
+// TFCPlanStepRunner runs the plan step of projects mapped to a workspace.
+type TFCPlanStepRunner struct {
+	*TFCRuns
+}
+
+// TFCApplyStepRunner runs the apply step of projects mapped to a workspace.
+type TFCApplyStepRunner struct {
+	*TFCRuns
+}
+
+// NewTFCStepRunnerDelegate returns a runner running tfcRunner for the projects
+// mapped to a Terraform Cloud workspace and defaultRunner for the others.
+func NewTFCStepRunnerDelegate(defaultRunner Runner, tfcRunner Runner) Runner {
+	return &tfcStepRunnerDelegate{
+		defaultRunner: defaultRunner,
+		tfcRunner:     tfcRunner,
+	}
+}
+
+// tfcStepRunnerDelegate delegates based on whether the project is run by
+// Terraform Cloud.
+type tfcStepRunnerDelegate struct {
+	defaultRunner Runner
+	tfcRunner     Runner
+}
+
+func (d *tfcStepRunnerDelegate) Run(ctx command.ProjectContext, extraArgs []string, path string, envs map[string]string) (string, error) {
+	if ctx.TFCWorkspace != "" {
+		return d.tfcRunner.Run(ctx, extraArgs, path, envs)
+	}
+	return d.defaultRunner.Run(ctx, extraArgs, path, envs)
+}
+
+// Run uploads the project as a configuration version of its workspace and
+// creates a run planning it. The ID of the run is saved in the planfile so
+// the run can be applied.
+func (r TFCPlanStepRunner) Run(ctx command.ProjectContext, extraArgs []string, path string, _ map[string]string) (string, error) {
+	workspace, err := r.workspace(ctx)
+	if err != nil {
+		return "", err
+	}
+	opts, err := tfcRunOptions(slices.Concat(extraArgs, unescapeArgs(ctx.EscapedCommentArgs)))
+	if err != nil {
+		return "", err
+	}
+	opts.Message = fmt.Sprintf("Atlantis plan of %s#%d by %s", ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, ctx.User.Username)
+
+	planFile := filepath.Join(path, GetPlanFilename(ctx.Workspace, ctx.ProjectName))
+	r.discardPreviousRun(ctx, planFile)
+
+	workspace, err = r.Client.Workspace(workspace.Organization, workspace.Name)
+	if err != nil {
+		return "", fmt.Errorf("getting Terraform Cloud workspace %s: %w", ctx.TFCWorkspace, err)
+	}
+	// Workspaces with a working directory run terraform in it, relative to
+	// the root of the repo.
+	dir := path
+	if workspace.WorkingDirectory != "" && ctx.RepoRelDir != "." {
+		dir = strings.TrimSuffix(filepath.Clean(path), string(filepath.Separator)+filepath.FromSlash(ctx.RepoRelDir))
+	}
+	var archive bytes.Buffer
+	if err := agents.WriteArchive(&archive, dir); err != nil {
+		return "", err
+	}
+	configurationVersionID, err := r.Client.UploadConfiguration(workspace, archive.Bytes(), r.pollInterval())
+	if err != nil {
+		return "", fmt.Errorf("uploading the configuration of Terraform Cloud workspace %s: %w", ctx.TFCWorkspace, err)
+	}
+	run, err := r.Client.CreateRun(workspace, configurationVersionID, opts)
+	if err != nil {
+		return "", fmt.Errorf("creating a run of Terraform Cloud workspace %s: %w", ctx.TFCWorkspace, err)
+	}
+	runURL := r.Client.RunURL(workspace, run.ID)
+	ctx.Log.Info("planning with Terraform Cloud run %s", runURL)
+	r.updateStatus(ctx, command.Plan, models.PendingCommitStatus, runURL)
+
+	run, err = r.wait(ctx, run, func(run tfcloud.Run) bool {
+		return run.IsConfirmable || run.Finished() || run.Status == tfcloud.RunPolicyOverride || run.Status == tfcloud.RunPolicySoftFailed
+	})
+	if err != nil {
+		r.updateStatus(ctx, command.Plan, models.FailedCommitStatus, runURL)
+		return "", err
+	}
+	output := r.log(ctx, r.Client.PlanLog, run.PlanID)
+	output = fmt.Sprintf("%s\n\nTerraform Cloud run: %s", output, runURL)
+	if !run.IsConfirmable && run.Status != tfcloud.RunPlannedAndFinished {
+		r.updateStatus(ctx, command.Plan, models.FailedCommitStatus, runURL)
+		if run.Status == tfcloud.RunPolicyOverride || run.Status == tfcloud.RunPolicySoftFailed {
+			return output, fmt.Errorf("the Terraform Cloud run %s failed its policy checks, fix them and re-run plan", run.ID)
+		}
+		return output, fmt.Errorf("the Terraform Cloud run %s is %s", run.ID, run.Status)
+	}
+
+	// Like remote ops, the planfile has the remote ops header so the steps
+	// using the planfile know it's not a terraform plan.
+	if err := os.WriteFile(planFile, []byte(remoteOpsHeader+tfcRunPrefix+run.ID+"\n"+output), 0600); err != nil {
+		return output, fmt.Errorf("unable to create planfile for Terraform Cloud run: %w", err)
+	}
+	r.updateStatus(ctx, command.Plan, models.SuccessCommitStatus, runURL)
+	return output, nil
+}
+
+// Run applies the run saved in the planfile by the plan step.
+func (r TFCApplyStepRunner) Run(ctx command.ProjectContext, _ []string, path string, _ map[string]string) (string, error) {
+	workspace, err := r.workspace(ctx)
+	if err != nil {
+		return "", err
+	}
+	planFile := filepath.Join(path, GetPlanFilename(ctx.Workspace, ctx.ProjectName))
+	runID, err := tfcRunID(planFile)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("no plan found at path %q and workspace %q–did you run plan?", ctx.RepoRelDir, ctx.Workspace)
+	}
+	if err != nil {
+		return "", err
+	}
+	runURL := r.Client.RunURL(workspace, runID)
+
+	run, err := r.Client.Run(runID)
+	if err != nil {
+		return "", fmt.Errorf("getting Terraform Cloud run %s: %w", runID, err)
+	}
+	if run.Status == tfcloud.RunPlannedAndFinished {
+		r.removePlanfile(ctx, planFile)
+		return fmt.Sprintf("Terraform Cloud run %s has no changes to apply.", runURL), nil
+	}
+	if !run.IsConfirmable {
+		return "", fmt.Errorf("the Terraform Cloud run %s can't be applied because it's %s, re-run plan to create a new run", runURL, run.Status)
+	}
+
+	ctx.Log.Info("applying Terraform Cloud run %s", runURL)
+	comment := fmt.Sprintf("Applied by Atlantis for %s#%d by %s", ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, ctx.User.Username)
+	if err := r.Client.ApplyRun(runID, comment); err != nil {
+		return "", fmt.Errorf("applying Terraform Cloud run %s: %w", runID, err)
+	}
+	r.updateStatus(ctx, command.Apply, models.PendingCommitStatus, runURL)
+	run, err = r.wait(ctx, run, tfcloud.Run.Finished)
+	if err != nil {
+		r.updateStatus(ctx, command.Apply, models.FailedCommitStatus, runURL)
+		return "", err
+	}
+	output := r.log(ctx, r.Client.ApplyLog, run.ApplyID)
+	output = fmt.Sprintf("%s\n\nTerraform Cloud run: %s", output, runURL)
+	if run.Status != tfcloud.RunApplied {
+		r.updateStatus(ctx, command.Apply, models.FailedCommitStatus, runURL)
+		return output, fmt.Errorf("the Terraform Cloud run %s is %s", run.ID, run.Status)
+	}
+	r.updateStatus(ctx, command.Apply, models.SuccessCommitStatus, runURL)
+	r.removePlanfile(ctx, planFile)
+	return output, nil
+}
+
+// workspace returns the workspace of the project, with its organization and
+// name only.
+func (r *TFCRuns) workspace(ctx command.ProjectContext) (tfcloud.Workspace, error) {
+	if r.Client == nil {
+		return tfcloud.Workspace{}, fmt.Errorf("project is run by Terraform Cloud workspace %s but --tfe-token isn't set", ctx.TFCWorkspace)
+	}
+	organization, name, _ := strings.Cut(ctx.TFCWorkspace, "/")
+	return tfcloud.Workspace{Organization: organization, Name: name}, nil
+}
+
+// discardPreviousRun discards the run of the previous plan of the project,
+// if it's still waiting to be applied, so it doesn't block the workspace.
+func (r *TFCRuns) discardPreviousRun(ctx command.ProjectContext, planFile string) {
+	runID, err := tfcRunID(planFile)
+	if err != nil {
+		return
+	}
+	run, err := r.Client.Run(runID)
+	if err != nil {
+		ctx.Log.Warn("unable to get the previous Terraform Cloud run %s: %s", runID, err)
+		return
+	}
+	if !run.IsDiscardable {
+		return
+	}
+	comment := fmt.Sprintf("Replaced by a new Atlantis plan of %s#%d", ctx.Pull.BaseRepo.FullName, ctx.Pull.Num)
+	if err := r.Client.DiscardRun(runID, comment); err != nil {
+		ctx.Log.Warn("unable to discard the previous Terraform Cloud run %s: %s", runID, err)
+	}
+}
+
+// wait polls run until done returns true, logging its status changes.
+func (r *TFCRuns) wait(ctx command.ProjectContext, run tfcloud.Run, done func(tfcloud.Run) bool) (tfcloud.Run, error) {
+	deadline := time.Now().Add(tfcRunTimeout)
+	status := ""
+	for {
+		if run.Status != status {
+			status = run.Status
+			ctx.Log.Info("Terraform Cloud run %s is %s", run.ID, status)
+		}
+		if done(run) {
+			return run, nil
+		}
+		if time.Now().After(deadline) {
+			return run, fmt.Errorf("the Terraform Cloud run %s is still %s after %s", run.ID, run.Status, tfcRunTimeout)
+		}
+		time.Sleep(r.pollInterval())
+		var err error
+		if run, err = r.Client.Run(run.ID); err != nil {
+			return run, fmt.Errorf("getting Terraform Cloud run %s: %w", run.ID, err)
+		}
+	}
+}
+
+// log returns the formatted log with ID id fetched by get, or a note that it
+// couldn't be fetched.
+func (r *TFCRuns) log(ctx command.ProjectContext, get func(id string) (string, error), id string) string {
+	if id == "" {
+		return "The log of the run isn't available."
+	}
+	log, err := get(id)
+	if err != nil {
+		ctx.Log.Warn("unable to get the log of Terraform Cloud run: %s", err)
+		return "The log of the run couldn't be fetched."
+	}
+	return fmtTFCLog(log)
+}
+
+func (r *TFCRuns) updateStatus(ctx command.ProjectContext, cmdName command.Name, status models.CommitStatus, url string) {
+	if r.CommitStatusUpdater == nil {
+		return
+	}
+	if err := r.CommitStatusUpdater.UpdateProject(ctx, cmdName, status, url, nil); err != nil {
+		ctx.Log.Err("unable to update status: %s", err)
+	}
+}
+
+func (r *TFCRuns) removePlanfile(ctx command.ProjectContext, planFile string) {
+	if err := utils.RemoveIgnoreNonExistent(planFile); err != nil {
+		ctx.Log.Warn("failed to delete planfile after successful apply: %s", err)
+	}
+}
+
+func (r *TFCRuns) pollInterval() time.Duration {
+	if r.PollInterval > 0 {
+		return r.PollInterval
+	}
+	return defaultTFCPollInterval
+}
+
+// tfcRunID returns the ID of the run saved in planFile by the plan step.
+func tfcRunID(planFile string) (string, error) {
+	contents, err := os.ReadFile(planFile)
+	if err != nil {
+		return "", err
+	}
+	rest, ok := strings.CutPrefix(string(contents), remoteOpsHeader+tfcRunPrefix)
+	if !ok {
+		return "", errors.New("the plan wasn't created by a Terraform Cloud run, re-run plan")
+	}
+	runID, _, _ := strings.Cut(rest, "\n")
+	return runID, nil
+}
+
+// tfcRunOptions returns the options of a run planned with the terraform plan
+// flags args. Runs only support -destroy, -target and -replace.
+func tfcRunOptions(args []string) (tfcloud.RunOptions, error) {
+	var opts tfcloud.RunOptions
+	for i := 0; i < len(args); i++ {
+		flag, value, hasValue := strings.Cut(strings.TrimPrefix(args[i], "-"), "=")
+		switch flag {
+		case "destroy":
+			opts.IsDestroy = true
+			continue
+		case "target", "replace":
+			if !hasValue {
+				if i+1 == len(args) {
+					return opts, fmt.Errorf("flag -%s needs a value", flag)
+				}
+				i++
+				value = args[i]
+			}
+			if flag == "target" {
+				opts.TargetAddrs = append(opts.TargetAddrs, value)
+			} else {
+				opts.ReplaceAddrs = append(opts.ReplaceAddrs, value)
+			}
+			continue
+		}
+		return opts, fmt.Errorf("%q isn't supported by Terraform Cloud runs, only -destroy, -target and -replace are", args[i])
+	}
+	return opts, nil
+}
+
+// unescapeArgs reverses the escaping of comment args, which prefixes each of
+// their characters with a backslash.
+func unescapeArgs(args []string) []string {
+	var unescaped []string
+	for _, arg := range args {
+		var b strings.Builder
+		for i := 1; i < len(arg); i += 2 {
+			b.WriteByte(arg[i])
+		}
+		unescaped = append(unescaped, b.String())
+	}
+	return unescaped
+}
+
+// fmtTFCLog formats the log of a plan or apply like the output of terraform.
+// Logs are wrapped in control characters, and the logs of structured runs are
+// JSON lines whose messages are kept.
+func fmtTFCLog(log string) string {
+	log = strings.NewReplacer("\x02", "", "\x03", "").Replace(ansi.Strip(log))
+	var lines []string
+	for _, line := range strings.Split(log, "\n") {
+		line = strings.TrimRight(line, "\r")
+		var entry struct {
+			Message string `json:"@message"`
+			Type    string `json:"type"`
+		}
+		if strings.HasPrefix(line, "{") && json.Unmarshal([]byte(line), &entry) == nil && entry.Message != "" {
+			if entry.Type == "version" {
+				continue
+			}
+			line = entry.Message
+		}
+		if strings.Contains(line, refreshKeyword) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	output := strings.TrimSpace(strings.Join(lines, "\n"))
+	output = plusDiffRegex.ReplaceAllString(output, "+")
+	output = tildeDiffRegex.ReplaceAllString(output, "~")
+	return minusDiffRegex.ReplaceAllString(output, "-")
+}
@@ -0,0 +1,290 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	runtimemocks "github.com/runatlantis/atlantis/server/core/runtime/mocks"
+	"github.com/runatlantis/atlantis/server/core/terraform/tfcloud"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// fakeTFC is a Terraform Cloud API with a workspace org/ws whose runs go
+// through the statuses they're given.
+type fakeTFC struct {
+	mutex sync.Mutex
+	// statuses are the statuses runs are in, by ID. Each GET of a run pops
+	// its status until the last.
+	statuses map[string][]string
+	// planLog and applyLog are the logs of the plans and applies of runs.
+	planLog  string
+	applyLog string
+	// planStatuses and applyStatuses are what new runs and applied runs go
+	// through.
+	planStatuses  []string
+	applyStatuses []string
+	// uploaded are the names of the files of the uploaded configuration.
+	uploaded []string
+	// runAttributes are the attributes of the last created run.
+	runAttributes map[string]any
+	applied       []string
+	discarded     []string
+	server        *httptest.Server
+}
+
+func newFakeTFC(t *testing.T) *fakeTFC {
+	f := &fakeTFC{
+		statuses:      make(map[string][]string),
+		planLog:       "\x02Terraform v1.9.0\non linux_amd64\naws_instance.web: Refreshing state... [id=i-1]\n\n  + resource \"aws_instance\" \"web\" {}\n\nPlan: 1 to add, 0 to change, 0 to destroy.\n\x03",
+		applyLog:      "\x02aws_instance.web: Creating...\n\nApply complete! Resources: 1 added, 0 changed, 0 destroyed.\n\x03",
+		planStatuses:  []string{"pending", "planning", "planned"},
+		applyStatuses: []string{"applying", "applied"},
+	}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeTFC) client() *tfcloud.Client {
+	return &tfcloud.Client{URL: f.server.URL, Token: "token"}
+}
+
+func (f *fakeTFC) handle(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if strings.HasPrefix(r.URL.Path, "/api/") && r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	write := func(resp any) {
+		json.NewEncoder(w).Encode(resp) // nolint: errcheck
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v2/organizations/org/workspaces/ws":
+		write(map[string]any{"data": map[string]any{"id": "ws-1", "attributes": map[string]any{"name": "ws"}}})
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v2/workspaces/ws-1/configuration-versions":
+		write(map[string]any{"data": map[string]any{"id": "cv-1", "attributes": map[string]any{"upload-url": f.server.URL + "/upload"}}})
+	case r.Method == http.MethodPut && r.URL.Path == "/upload":
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		tr := tar.NewReader(gz)
+		for header, err := tr.Next(); err == nil; header, err = tr.Next() {
+			f.uploaded = append(f.uploaded, header.Name)
+		}
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v2/configuration-versions/cv-1":
+		write(map[string]any{"data": map[string]any{"attributes": map[string]any{"status": "uploaded"}}})
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v2/runs":
+		var req struct {
+			Data struct {
+				Attributes map[string]any `json:"attributes"`
+			} `json:"data"`
+		}
+		json.NewDecoder(r.Body).Decode(&req) // nolint: errcheck
+		f.runAttributes = req.Data.Attributes
+		f.statuses["run-1"] = f.planStatuses
+		write(f.run("run-1"))
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/api/v2/runs/"):
+		write(f.run(strings.TrimPrefix(r.URL.Path, "/api/v2/runs/")))
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/actions/apply"):
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v2/runs/"), "/actions/apply")
+		f.applied = append(f.applied, id)
+		f.statuses[id] = f.applyStatuses
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/actions/discard"):
+		f.discarded = append(f.discarded, strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v2/runs/"), "/actions/discard"))
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v2/plans/plan-1":
+		write(map[string]any{"data": map[string]any{"attributes": map[string]any{"log-read-url": f.server.URL + "/logs/plan"}}})
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v2/applies/apply-1":
+		write(map[string]any{"data": map[string]any{"attributes": map[string]any{"log-read-url": f.server.URL + "/logs/apply"}}})
+	case r.URL.Path == "/logs/plan":
+		w.Write([]byte(f.planLog)) // nolint: errcheck
+	case r.URL.Path == "/logs/apply":
+		w.Write([]byte(f.applyLog)) // nolint: errcheck
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		write(map[string]any{"errors": []map[string]string{{"title": "not found"}}})
+	}
+}
+
+// run returns the response of the run with ID id, popping its status.
+func (f *fakeTFC) run(id string) map[string]any {
+	statuses := f.statuses[id]
+	status := "errored"
+	if len(statuses) > 0 {
+		status = statuses[0]
+	}
+	if len(statuses) > 1 {
+		f.statuses[id] = statuses[1:]
+	}
+	return map[string]any{"data": map[string]any{
+		"id": id,
+		"attributes": map[string]any{
+			"status":      status,
+			"has-changes": true,
+			"actions": map[string]any{
+				"is-confirmable": status == "planned",
+				"is-discardable": status == "planned",
+			},
+		},
+		"relationships": map[string]any{
+			"plan":  map[string]any{"data": map[string]any{"id": "plan-1"}},
+			"apply": map[string]any{"data": map[string]any{"id": "apply-1"}},
+		},
+	}}
+}
+
+func tfcProjectContext(t *testing.T) command.ProjectContext {
+	return command.ProjectContext{
+		Log:          logging.NewNoopLogger(t),
+		Workspace:    "default",
+		RepoRelDir:   ".",
+		TFCWorkspace: "org/ws",
+		Pull:         models.PullRequest{Num: 2, BaseRepo: models.Repo{FullName: "owner/repo"}},
+		User:         models.User{Username: "alice"},
+	}
+}
+
+func TestTFCRuns_PlanAndApply(t *testing.T) {
+	RegisterMockTestingT(t)
+	fake := newFakeTFC(t)
+	statusUpdater := runtimemocks.NewMockStatusUpdater()
+	runs := &runtime.TFCRuns{Client: fake.client(), CommitStatusUpdater: statusUpdater, PollInterval: time.Millisecond}
+	ctx := tfcProjectContext(t)
+	path := t.TempDir()
+	Ok(t, os.WriteFile(filepath.Join(path, "main.tf"), []byte(`resource "aws_instance" "web" {}`), 0600))
+	runURL := fake.server.URL + "/app/org/workspaces/ws/runs/run-1"
+
+	output, err := runtime.TFCPlanStepRunner{TFCRuns: runs}.Run(ctx, nil, path, nil)
+	Ok(t, err)
+	Equals(t, "+ resource \"aws_instance\" \"web\" {}\n\nPlan: 1 to add, 0 to change, 0 to destroy.\n\nTerraform Cloud run: "+runURL, strings.TrimPrefix(output, "Terraform v1.9.0\non linux_amd64\n\n"))
+	Equals(t, []string{"main.tf"}, fake.uploaded)
+	Equals(t, "Atlantis plan of owner/repo#2 by alice", fake.runAttributes["message"])
+	Equals(t, false, fake.runAttributes["auto-apply"])
+	planfile, err := os.ReadFile(filepath.Join(path, "default.tfplan"))
+	Ok(t, err)
+	Assert(t, runtime.IsRemotePlan(planfile), "expected the planfile to be a remote plan")
+	statusUpdater.VerifyWasCalledOnce().UpdateProject(Any[command.ProjectContext](), Eq(command.Plan), Eq(models.PendingCommitStatus), Eq(runURL), Any[*command.ProjectCommandOutput]())
+	statusUpdater.VerifyWasCalledOnce().UpdateProject(Any[command.ProjectContext](), Eq(command.Plan), Eq(models.SuccessCommitStatus), Eq(runURL), Any[*command.ProjectCommandOutput]())
+
+	output, err = runtime.TFCApplyStepRunner{TFCRuns: runs}.Run(ctx, nil, path, nil)
+	Ok(t, err)
+	Equals(t, "aws_instance.web: Creating...\n\nApply complete! Resources: 1 added, 0 changed, 0 destroyed.\n\nTerraform Cloud run: "+runURL, output)
+	Equals(t, []string{"run-1"}, fake.applied)
+	statusUpdater.VerifyWasCalledOnce().UpdateProject(Any[command.ProjectContext](), Eq(command.Apply), Eq(models.SuccessCommitStatus), Eq(runURL), Any[*command.ProjectCommandOutput]())
+	_, err = os.Stat(filepath.Join(path, "default.tfplan"))
+	Assert(t, os.IsNotExist(err), "expected the planfile to be deleted")
+}
+
+func TestTFCPlanStepRunner_DiscardsPreviousRun(t *testing.T) {
+	fake := newFakeTFC(t)
+	fake.statuses["run-0"] = []string{"planned"}
+	path := t.TempDir()
+	Ok(t, os.WriteFile(filepath.Join(path, "default.tfplan"), []byte("Atlantis: this plan was created by remote ops\ntfc-run: run-0\nPlan: 1 to add"), 0600))
+
+	_, err := runtime.TFCPlanStepRunner{TFCRuns: &runtime.TFCRuns{Client: fake.client(), PollInterval: time.Millisecond}}.Run(tfcProjectContext(t), nil, path, nil)
+	Ok(t, err)
+	Equals(t, []string{"run-0"}, fake.discarded)
+}
+
+func TestTFCPlanStepRunner_Flags(t *testing.T) {
+	fake := newFakeTFC(t)
+	ctx := tfcProjectContext(t)
+	// Comment args are escaped.
+	ctx.EscapedCommentArgs = []string{`\-\t\a\r\g\e\t\=\a\w\s\_\i\n\s\t\a\n\c\e\.\w\e\b`}
+	runner := runtime.TFCPlanStepRunner{TFCRuns: &runtime.TFCRuns{Client: fake.client(), PollInterval: time.Millisecond}}
+
+	_, err := runner.Run(ctx, []string{"-replace", "aws_instance.db"}, t.TempDir(), nil)
+	Ok(t, err)
+	Equals(t, []any{"aws_instance.web"}, fake.runAttributes["target-addrs"])
+	Equals(t, []any{"aws_instance.db"}, fake.runAttributes["replace-addrs"])
+
+	_, err = runner.Run(ctx, []string{"-var", "env=prod"}, t.TempDir(), nil)
+	ErrEquals(t, `"-var" isn't supported by Terraform Cloud runs, only -destroy, -target and -replace are`, err)
+}
+
+func TestTFCPlanStepRunner_Errored(t *testing.T) {
+	fake := newFakeTFC(t)
+	fake.planStatuses = []string{"planning", "errored"}
+	fake.planLog = "Error: Invalid resource type"
+	path := t.TempDir()
+
+	output, err := runtime.TFCPlanStepRunner{TFCRuns: &runtime.TFCRuns{Client: fake.client(), PollInterval: time.Millisecond}}.Run(tfcProjectContext(t), nil, path, nil)
+	ErrEquals(t, "the Terraform Cloud run run-1 is errored", err)
+	Assert(t, strings.HasPrefix(output, "Error: Invalid resource type"), "unexpected output %q", output)
+	_, err = os.Stat(filepath.Join(path, "default.tfplan"))
+	Assert(t, os.IsNotExist(err), "expected no planfile")
+}
+
+func TestTFCPlanStepRunner_StructuredLog(t *testing.T) {
+	fake := newFakeTFC(t)
+	fake.planLog = `{"@level":"info","@message":"Terraform 1.9.0","type":"version"}
+{"@level":"info","@message":"aws_instance.web: Plan to create","type":"planned_change"}
+{"@level":"info","@message":"Plan: 1 to add, 0 to change, 0 to destroy.","type":"change_summary"}`
+
+	output, err := runtime.TFCPlanStepRunner{TFCRuns: &runtime.TFCRuns{Client: fake.client(), PollInterval: time.Millisecond}}.Run(tfcProjectContext(t), nil, t.TempDir(), nil)
+	Ok(t, err)
+	Equals(t, fmt.Sprintf("aws_instance.web: Plan to create\nPlan: 1 to add, 0 to change, 0 to destroy.\n\nTerraform Cloud run: %s/app/org/workspaces/ws/runs/run-1", fake.server.URL), output)
+}
+
+func TestTFCApplyStepRunner_NotConfirmable(t *testing.T) {
+	fake := newFakeTFC(t)
+	fake.statuses["run-0"] = []string{"discarded"}
+	path := t.TempDir()
+	Ok(t, os.WriteFile(filepath.Join(path, "default.tfplan"), []byte("Atlantis: this plan was created by remote ops\ntfc-run: run-0\n"), 0600))
+
+	_, err := runtime.TFCApplyStepRunner{TFCRuns: &runtime.TFCRuns{Client: fake.client()}}.Run(tfcProjectContext(t), nil, path, nil)
+	ErrEquals(t, fmt.Sprintf("the Terraform Cloud run %s/app/org/workspaces/ws/runs/run-0 can't be applied because it's discarded, re-run plan to create a new run", fake.server.URL), err)
+	Equals(t, 0, len(fake.applied))
+}
+
+func TestTFCApplyStepRunner_NoPlan(t *testing.T) {
+	fake := newFakeTFC(t)
+	_, err := runtime.TFCApplyStepRunner{TFCRuns: &runtime.TFCRuns{Client: fake.client()}}.Run(tfcProjectContext(t), nil, t.TempDir(), nil)
+	ErrEquals(t, `no plan found at path "." and workspace "default"–did you run plan?`, err)
+}
+
+func TestTFCRuns_NoToken(t *testing.T) {
+	_, err := runtime.TFCPlanStepRunner{TFCRuns: &runtime.TFCRuns{}}.Run(tfcProjectContext(t), nil, t.TempDir(), nil)
+	ErrEquals(t, "project is run by Terraform Cloud workspace org/ws but --tfe-token isn't set", err)
+}
+
+func TestTFCStepRunnerDelegate(t *testing.T) {
+	RegisterMockTestingT(t)
+	defaultRunner := runtimemocks.NewMockRunner()
+	tfcRunner := runtimemocks.NewMockRunner()
+	When(defaultRunner.Run(Any[command.ProjectContext](), Any[[]string](), Any[string](), Any[map[string]string]())).ThenReturn("default", nil)
+	When(tfcRunner.Run(Any[command.ProjectContext](), Any[[]string](), Any[string](), Any[map[string]string]())).ThenReturn("tfc", nil)
+	delegate := runtime.NewTFCStepRunnerDelegate(defaultRunner, tfcRunner)
+
+	ctx := tfcProjectContext(t)
+	output, err := delegate.Run(ctx, nil, "", nil)
+	Ok(t, err)
+	Equals(t, "tfc", output)
+
+	ctx.TFCWorkspace = ""
+	output, err = delegate.Run(ctx, nil, "", nil)
+	Ok(t, err)
+	Equals(t, "default", output)
+}
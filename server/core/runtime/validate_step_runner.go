@@ -0,0 +1,273 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/runatlantis/atlantis/server/core/terraform"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// hunkHeaderRegex matches the header of a hunk of a unified diff, ex.
+// "@@ -3,4 +3,4 @@".
+var hunkHeaderRegex = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+\d+(?:,(\d+))? @@`)
+
+// ReviewAnnotator comments findings on the lines of pull request files.
+type ReviewAnnotator interface {
+	AnnotateFiles(logger logging.SimpleLogging, repo models.Repo, pullNum int, commitSHA string, annotations []models.FileAnnotation) error
+}
+
+// ValidateStepRunner runs terraform fmt -check and terraform validate before
+// the plan and comments their findings on the offending lines of the pull
+// request.
+type ValidateStepRunner struct {
+	TerraformExecutor     TerraformExec
+	DefaultTFDistribution terraform.Distribution
+	DefaultTFVersion      *version.Version
+	// Annotator comments the findings on the pull request. It may be nil.
+	Annotator ReviewAnnotator
+}
+
+// validateOutput is the output of terraform validate -json.
+type validateOutput struct {
+	Diagnostics []struct {
+		Severity string `json:"severity"`
+		Summary  string `json:"summary"`
+		Detail   string `json:"detail"`
+		Range    *struct {
+			Filename string `json:"filename"`
+			Start    struct {
+				Line int `json:"line"`
+			} `json:"start"`
+			End struct {
+				Line int `json:"line"`
+			} `json:"end"`
+		} `json:"range"`
+	} `json:"diagnostics"`
+}
+
+// Run runs the step, the extra args are passed to terraform validate. The
+// findings only fail the step if failFast is set, since the plan would
+// likely fail on them anyway.
+func (v *ValidateStepRunner) Run(ctx command.ProjectContext, extraArgs []string, path string, envs map[string]string, failFast bool) (string, error) {
+	tfDistribution := v.DefaultTFDistribution
+	tfVersion := v.DefaultTFVersion
+	if ctx.TerraformDistribution != nil {
+		tfDistribution = terraform.NewDistribution(*ctx.TerraformDistribution)
+	}
+	if ctx.TerraformVersion != nil {
+		tfVersion = ctx.TerraformVersion
+	}
+
+	var annotations []models.FileAnnotation
+	var failures []string
+
+	// fmt exits with 3 when files aren't formatted, and fails on files it
+	// can't parse, which validate reports below.
+	out, err := v.TerraformExecutor.RunCommandWithVersion(ctx, filepath.Clean(path), []string{"fmt", "-check", "-diff"}, envs, tfDistribution, tfVersion, ctx.Workspace)
+	unformatted := parseFmtDiff(ctx.RepoRelDir, out)
+	if err != nil && len(unformatted) == 0 {
+		ctx.Log.Debug("terraform fmt failed: %s", err)
+	}
+	for _, file := range unformatted {
+		failures = append(failures, fmt.Sprintf("%s isn't formatted, run terraform fmt", file.path))
+		annotations = append(annotations, file.annotations...)
+	}
+
+	out, err = v.TerraformExecutor.RunCommandWithVersion(ctx, filepath.Clean(path), append([]string{"validate", "-json"}, extraArgs...), envs, tfDistribution, tfVersion, ctx.Workspace)
+	var validation validateOutput
+	if jsonErr := json.Unmarshal([]byte(jsonObject(out)), &validation); jsonErr != nil {
+		if err != nil {
+			return "", fmt.Errorf("running terraform validate: %s: %w", out, err)
+		}
+		return "", fmt.Errorf("parsing terraform validate output: %w", jsonErr)
+	}
+	for _, diag := range validation.Diagnostics {
+		message := diag.Summary
+		if diag.Range != nil && diag.Range.Filename != "" {
+			file := filepath.ToSlash(filepath.Join(ctx.RepoRelDir, diag.Range.Filename))
+			message = fmt.Sprintf("%s line %d: %s", file, diag.Range.Start.Line, diag.Summary)
+			body := fmt.Sprintf("**terraform validate %s:** %s", diag.Severity, diag.Summary)
+			if diag.Detail != "" {
+				body += "\n\n" + diag.Detail
+			}
+			annotations = append(annotations, models.FileAnnotation{
+				Path:      file,
+				StartLine: diag.Range.Start.Line,
+				EndLine:   max(diag.Range.Start.Line, diag.Range.End.Line),
+				Body:      body,
+			})
+		}
+		if diag.Severity == "error" {
+			failures = append(failures, message)
+		}
+	}
+
+	v.annotate(ctx, annotations)
+	if failFast && len(failures) > 0 {
+		return "", fmt.Errorf("validation failed before planning:\n* %s", strings.Join(failures, "\n* "))
+	}
+	return "", nil
+}
+
+// annotate comments the annotations on the pull request. It's best effort,
+// errors are only logged.
+func (v *ValidateStepRunner) annotate(ctx command.ProjectContext, annotations []models.FileAnnotation) {
+	if v.Annotator == nil || len(annotations) == 0 || ctx.Pull.BaseRepo.VCSHost.Type != models.Github {
+		return
+	}
+	if err := v.Annotator.AnnotateFiles(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num, ctx.Pull.HeadCommit, annotations); err != nil {
+		ctx.Log.Warn("unable to annotate validation findings: %s", err)
+	}
+}
+
+// unformattedFile is a file terraform fmt would change and suggestions of
+// its changes.
+type unformattedFile struct {
+	path        string
+	annotations []models.FileAnnotation
+}
+
+// parseFmtDiff parses the output of terraform fmt -check -diff, the paths of
+// the files it lists, each followed by the diff formatting it, into the
+// unformatted files with a suggestion per hunk of their diff. Files without
+// a diff, ex. because diff isn't installed, are annotated on their first
+// line.
+func parseFmtDiff(repoRelDir string, output string) []unformattedFile {
+	var files []unformattedFile
+	lines := strings.Split(output, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "+++ ") {
+			continue
+		}
+		match := hunkHeaderRegex.FindStringSubmatch(line)
+		if match == nil {
+			if strings.HasSuffix(line, ".tf") || strings.HasSuffix(line, ".tfvars") || strings.HasSuffix(line, ".tftest.hcl") {
+				files = append(files, unformattedFile{path: filepath.ToSlash(filepath.Join(repoRelDir, line))})
+			}
+			continue
+		}
+		if len(files) == 0 {
+			continue
+		}
+		// The regex only matches digits.
+		oldStart, _ := strconv.Atoi(match[1])
+		oldCount, newCount := hunkCount(match[2]), hunkCount(match[3])
+		var hunk []string
+		for (oldCount > 0 || newCount > 0) && i+1 < len(lines) && lines[i+1] != "" {
+			i++
+			switch lines[i][0] {
+			case ' ':
+				oldCount--
+				newCount--
+			case '-':
+				oldCount--
+			case '+':
+				newCount--
+			}
+			hunk = append(hunk, lines[i])
+		}
+		file := &files[len(files)-1]
+		if annotation, ok := hunkSuggestion(file.path, oldStart, hunk); ok {
+			file.annotations = append(file.annotations, annotation)
+		}
+	}
+	for i, file := range files {
+		if len(file.annotations) == 0 {
+			files[i].annotations = []models.FileAnnotation{{
+				Path:      file.path,
+				StartLine: 1,
+				EndLine:   1,
+				Body:      "**terraform fmt:** this file isn't formatted, run `terraform fmt`.",
+			}}
+		}
+	}
+	return files
+}
+
+// hunkSuggestion returns a suggestion replacing the lines a hunk starting at
+// line oldStart of the file changes with the formatted lines, leaving out the
+// context lines around the changes.
+func hunkSuggestion(path string, oldStart int, hunk []string) (models.FileAnnotation, bool) {
+	first, last := -1, -1
+	removes := false
+	for i, line := range hunk {
+		if line[0] == '-' || line[0] == '+' {
+			if first == -1 {
+				first = i
+			}
+			last = i
+			removes = removes || line[0] == '-'
+		}
+	}
+	if first == -1 {
+		return models.FileAnnotation{}, false
+	}
+	// Lines that are only added are suggested with the context line before
+	// them, since suggestions replace lines.
+	if !removes && first > 0 {
+		first--
+	}
+	startLine := oldStart
+	for _, line := range hunk[:first] {
+		if line[0] == ' ' {
+			startLine++
+		}
+	}
+	endLine := startLine - 1
+	var replacement []string
+	for _, line := range hunk[first : last+1] {
+		switch line[0] {
+		case ' ':
+			endLine++
+			replacement = append(replacement, line[1:])
+		case '-':
+			endLine++
+		case '+':
+			replacement = append(replacement, line[1:])
+		}
+	}
+	if endLine < startLine {
+		return models.FileAnnotation{}, false
+	}
+	suggestion := strings.Join(replacement, "\n")
+	return models.FileAnnotation{
+		Path:       path,
+		StartLine:  startLine,
+		EndLine:    endLine,
+		Body:       "**terraform fmt:** these lines aren't formatted.",
+		Suggestion: &suggestion,
+	}, true
+}
+
+// hunkCount returns the line count of a hunk header, which is 1 if omitted.
+func hunkCount(count string) int {
+	if count == "" {
+		return 1
+	}
+	// The regex only matches digits.
+	n, _ := strconv.Atoi(count)
+	return n
+}
+
+// jsonObject returns the JSON object in output, ignoring what terraform may
+// have printed around it.
+func jsonObject(output string) string {
+	start := strings.Index(output, "{")
+	end := strings.LastIndex(output, "}")
+	if start == -1 || end < start {
+		return output
+	}
+	return output[start : end+1]
+}
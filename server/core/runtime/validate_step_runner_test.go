@@ -0,0 +1,154 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package runtime_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	tf "github.com/runatlantis/atlantis/server/core/terraform"
+	tfclientmocks "github.com/runatlantis/atlantis/server/core/terraform/tfclient/mocks"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// fakeAnnotator records the annotations of the pull requests.
+type fakeAnnotator struct {
+	annotations []models.FileAnnotation
+}
+
+func (f *fakeAnnotator) AnnotateFiles(_ logging.SimpleLogging, _ models.Repo, _ int, _ string, annotations []models.FileAnnotation) error {
+	f.annotations = append(f.annotations, annotations...)
+	return nil
+}
+
+const unformattedFmtOutput = `main.tf
+--- old/main.tf
++++ new/main.tf
+@@ -1,5 +1,5 @@
+ resource "aws_instance" "web" {
+-  ami = "ami-1"
+-  instance_type    = "t3.micro"
++  ami           = "ami-1"
++  instance_type = "t3.micro"
+ }
+
+`
+
+const invalidValidateOutput = `{
+  "valid": false,
+  "error_count": 1,
+  "warning_count": 1,
+  "diagnostics": [
+    {
+      "severity": "error",
+      "summary": "Unsupported argument",
+      "detail": "An argument named \"foo\" is not expected here.",
+      "range": {"filename": "main.tf", "start": {"line": 4, "column": 3}, "end": {"line": 4, "column": 6}}
+    },
+    {
+      "severity": "warning",
+      "summary": "Deprecated attribute"
+    }
+  ]
+}`
+
+func newValidateStepRunner(t *testing.T, fmtOutput string, validateOutput string) (*runtime.ValidateStepRunner, *fakeAnnotator, command.ProjectContext) {
+	RegisterMockTestingT(t)
+	terraform := tfclientmocks.NewMockClient()
+	tfVersion, _ := version.NewVersion("1.9.0")
+	var fmtErr, validateErr error
+	if fmtOutput != "" {
+		fmtErr = errors.New("exit status 3")
+	}
+	if validateOutput != `{"valid": true, "diagnostics": []}` {
+		validateErr = errors.New("exit status 1")
+	}
+	When(terraform.RunCommandWithVersion(Any[command.ProjectContext](), Any[string](), Eq([]string{"fmt", "-check", "-diff"}), Any[map[string]string](), Any[tf.Distribution](), Any[*version.Version](), Any[string]())).
+		ThenReturn(fmtOutput, fmtErr)
+	When(terraform.RunCommandWithVersion(Any[command.ProjectContext](), Any[string](), Eq([]string{"validate", "-json"}), Any[map[string]string](), Any[tf.Distribution](), Any[*version.Version](), Any[string]())).
+		ThenReturn(validateOutput, validateErr)
+	annotator := &fakeAnnotator{}
+	runner := &runtime.ValidateStepRunner{
+		TerraformExecutor: terraform,
+		DefaultTFVersion:  tfVersion,
+		Annotator:         annotator,
+	}
+	ctx := command.ProjectContext{
+		Log:        logging.NewNoopLogger(t),
+		Workspace:  "default",
+		RepoRelDir: "envs/prod",
+		Pull: models.PullRequest{
+			Num:        2,
+			HeadCommit: "sha",
+			BaseRepo:   models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Type: models.Github}},
+		},
+	}
+	return runner, annotator, ctx
+}
+
+func TestValidateStepRunner_Annotates(t *testing.T) {
+	runner, annotator, ctx := newValidateStepRunner(t, unformattedFmtOutput, invalidValidateOutput)
+
+	out, err := runner.Run(ctx, nil, t.TempDir(), map[string]string(nil), false)
+	Ok(t, err)
+	Equals(t, "", out)
+	suggestion := "  ami           = \"ami-1\"\n  instance_type = \"t3.micro\""
+	Equals(t, []models.FileAnnotation{
+		{
+			Path:       "envs/prod/main.tf",
+			StartLine:  2,
+			EndLine:    3,
+			Body:       "**terraform fmt:** these lines aren't formatted.",
+			Suggestion: &suggestion,
+		},
+		{
+			Path:      "envs/prod/main.tf",
+			StartLine: 4,
+			EndLine:   4,
+			Body:      "**terraform validate error:** Unsupported argument\n\nAn argument named \"foo\" is not expected here.",
+		},
+	}, annotator.annotations)
+}
+
+func TestValidateStepRunner_FailFast(t *testing.T) {
+	runner, _, ctx := newValidateStepRunner(t, unformattedFmtOutput, invalidValidateOutput)
+
+	_, err := runner.Run(ctx, nil, t.TempDir(), map[string]string(nil), true)
+	ErrEquals(t, `validation failed before planning:
+* envs/prod/main.tf isn't formatted, run terraform fmt
+* envs/prod/main.tf line 4: Unsupported argument`, err)
+}
+
+func TestValidateStepRunner_NoFindings(t *testing.T) {
+	runner, annotator, ctx := newValidateStepRunner(t, "", `{"valid": true, "diagnostics": []}`)
+
+	_, err := runner.Run(ctx, nil, t.TempDir(), map[string]string(nil), true)
+	Ok(t, err)
+	Equals(t, 0, len(annotator.annotations))
+}
+
+func TestValidateStepRunner_FmtWithoutDiff(t *testing.T) {
+	// Without diff installed, terraform fmt only lists the files.
+	runner, annotator, ctx := newValidateStepRunner(t, "main.tf\nvariables.tf\n", `{"valid": true, "diagnostics": []}`)
+
+	_, err := runner.Run(ctx, nil, t.TempDir(), map[string]string(nil), false)
+	Ok(t, err)
+	Equals(t, []models.FileAnnotation{
+		{Path: "envs/prod/main.tf", StartLine: 1, EndLine: 1, Body: "**terraform fmt:** this file isn't formatted, run `terraform fmt`."},
+		{Path: "envs/prod/variables.tf", StartLine: 1, EndLine: 1, Body: "**terraform fmt:** this file isn't formatted, run `terraform fmt`."},
+	}, annotator.annotations)
+}
+
+func TestValidateStepRunner_ValidateFails(t *testing.T) {
+	runner, _, ctx := newValidateStepRunner(t, "", "Error: Module not installed")
+
+	_, err := runner.Run(ctx, nil, t.TempDir(), map[string]string(nil), false)
+	ErrEquals(t, "running terraform validate: Error: Module not installed: exit status 1", err)
+}
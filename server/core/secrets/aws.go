@@ -0,0 +1,85 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// AWSSecretsManager fetches secrets from AWS Secrets Manager. Its references
+// are aws-sm://<secret name or ARN>[#<key>], where key selects a key of a JSON
+// secret.
+type AWSSecretsManager struct {
+	// Config configures the Secrets Manager client, ex. its region and
+	// credentials. If nil, the AWS SDK's default config is loaded.
+	Config *aws.Config
+}
+
+// NewAWSSecretsManagerFromEnv returns an AWSSecretsManager authenticated with
+// the credentials of the AWS SDK's default chain, ex. the AWS_* environment
+// variables, shared config files, web identity tokens or the ECS task and EC2
+// instance roles, which are refreshed when they expire. The region is the
+// SDK's, ex. AWS_REGION, unless secrets are referenced by ARN, and
+// AWS_ENDPOINT_URL_SECRETS_MANAGER overrides the endpoint.
+func NewAWSSecretsManagerFromEnv() *AWSSecretsManager {
+	return &AWSSecretsManager{}
+}
+
+func (a *AWSSecretsManager) Scheme() string {
+	return "aws-sm"
+}
+
+func (a *AWSSecretsManager) Fetch(ref Reference) (string, error) {
+	cfg, err := loadAWSConfig(a.Config)
+	if err != nil {
+		return "", err
+	}
+	var optFns []func(*secretsmanager.Options)
+	if region := arnRegion(ref.Path); region != "" {
+		optFns = append(optFns, func(o *secretsmanager.Options) { o.Region = region })
+	} else if cfg.Region == "" {
+		return "", errors.New("AWS_REGION must be set to fetch secrets by name from AWS Secrets Manager")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), httpTimeout)
+	defer cancel()
+	out, err := secretsmanager.NewFromConfig(cfg).GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(ref.Path),
+	}, optFns...)
+	if err != nil {
+		return "", err
+	}
+	if out.SecretString == nil {
+		return "", errors.New("secret has no string value, binary secrets aren't supported")
+	}
+	return selectKey(*out.SecretString, ref.Key)
+}
+
+// loadAWSConfig returns cfg, or the AWS SDK's default config if it's nil.
+func loadAWSConfig(cfg *aws.Config) (aws.Config, error) {
+	if cfg != nil {
+		return *cfg, nil
+	}
+	loaded, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("loading AWS config: %w", err)
+	}
+	return loaded, nil
+}
+
+// arnRegion returns the region of arn, or "" if it isn't an ARN. ARNs are
+// arn:<partition>:<service>:<region>:<account>:<resource>.
+func arnRegion(arn string) string {
+	if parts := strings.Split(arn, ":"); len(parts) > 3 && parts[0] == "arn" {
+		return parts[3]
+	}
+	return ""
+}
@@ -0,0 +1,145 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package secrets
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// GCPSecretManager fetches secrets from Google Cloud Secret Manager. Its
+// references are gcp-sm://projects/<project>/secrets/<secret>[/versions/<version>][#<key>],
+// the latest version is fetched if it's not set and key selects a key of a
+// JSON secret.
+type GCPSecretManager struct {
+	// APIURL is the URL of the Secret Manager API.
+	APIURL string
+	*GCPTokenSource
+}
+
+// GCPTokenSource returns the access tokens authenticating to Google Cloud
+// APIs.
+type GCPTokenSource struct {
+	// AccessToken authenticates to the APIs. If empty, the access tokens of
+	// the service account of the instance are fetched from MetadataURL.
+	AccessToken string
+	// MetadataURL is the URL of the metadata server of the instance.
+	MetadataURL string
+
+	once   sync.Once
+	source oauth2.TokenSource
+}
+
+// NewGCPTokenSourceFromEnv returns a GCPTokenSource using the
+// GOOGLE_OAUTH_ACCESS_TOKEN environment variable if it's set, or else the
+// service account of the instance it runs on. GCE_METADATA_HOST overrides the
+// host of the metadata server.
+func NewGCPTokenSourceFromEnv() *GCPTokenSource {
+	metadataHost := os.Getenv("GCE_METADATA_HOST")
+	if metadataHost == "" {
+		metadataHost = "metadata.google.internal"
+	}
+	return &GCPTokenSource{
+		AccessToken: os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"),
+		MetadataURL: "http://" + metadataHost,
+	}
+}
+
+// NewGCPSecretManagerFromEnv returns a GCPSecretManager authenticated by the
+// GOOGLE_OAUTH_ACCESS_TOKEN environment variable if it's set, or else by the
+// service account of the instance it runs on, ex. through GKE workload
+// identity. GCE_METADATA_HOST overrides the host of the metadata server.
+func NewGCPSecretManagerFromEnv() *GCPSecretManager {
+	return &GCPSecretManager{
+		APIURL:         "https://secretmanager.googleapis.com",
+		GCPTokenSource: NewGCPTokenSourceFromEnv(),
+	}
+}
+
+func (g *GCPSecretManager) Scheme() string {
+	return "gcp-sm"
+}
+
+func (g *GCPSecretManager) Fetch(ref Reference) (string, error) {
+	name := ref.Path
+	parts := strings.Split(name, "/")
+	if len(parts) == 4 {
+		name += "/versions/latest"
+	} else if len(parts) != 6 {
+		return "", errors.New("references to Secret Manager secrets must be gcp-sm://projects/<project>/secrets/<secret>[/versions/<version>]")
+	}
+	token, err := g.Token()
+	if err != nil {
+		return "", fmt.Errorf("getting access token: %w", err)
+	}
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/v1/%s:access", strings.TrimSuffix(g.APIURL, "/"), name), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	var version struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := doJSON(req, &version); err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(version.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("decoding secret payload: %w", err)
+	}
+	return selectKey(string(data), ref.Key)
+}
+
+// Token returns AccessToken or a token of the service account of the
+// instance, which is cached until a minute before it expires. It's safe for
+// concurrent use.
+func (g *GCPTokenSource) Token() (string, error) {
+	if g.AccessToken != "" {
+		return g.AccessToken, nil
+	}
+	g.once.Do(func() {
+		g.source = oauth2.ReuseTokenSourceWithExpiry(nil, metadataTokenSource{url: g.MetadataURL}, time.Minute)
+	})
+	token, err := g.source.Token()
+	if err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// metadataTokenSource fetches the tokens of the service account of the
+// instance from its metadata server.
+type metadataTokenSource struct {
+	url string
+}
+
+func (m metadataTokenSource) Token() (*oauth2.Token, error) {
+	req, err := http.NewRequest("GET", strings.TrimSuffix(m.url, "/")+"/computeMetadata/v1/instance/service-accounts/default/token", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := doJSON(req, &token); err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{
+		AccessToken: token.AccessToken,
+		TokenType:   "Bearer",
+		Expiry:      time.Now().Add(time.Duration(token.ExpiresIn) * time.Second),
+	}, nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// AWSKMS generates and decrypts data keys with an AWS KMS key for envelope
+// encryption.
+type AWSKMS struct {
+	// KeyID is the ID, ARN or alias of the KMS key.
+	KeyID string
+	// Config configures the KMS client, ex. its region and credentials. If
+	// nil, the AWS SDK's default config is loaded.
+	Config *aws.Config
+}
+
+// NewAWSKMSFromEnv returns an AWSKMS using keyID, authenticated with the
+// credentials of the AWS SDK's default chain, ex. the AWS_* environment
+// variables, shared config files, web identity tokens or the ECS task and EC2
+// instance roles, which are refreshed when they expire. The region is the
+// SDK's, ex. AWS_REGION, unless keyID is an ARN, and AWS_ENDPOINT_URL_KMS
+// overrides the endpoint.
+func NewAWSKMSFromEnv(keyID string) *AWSKMS {
+	return &AWSKMS{KeyID: keyID}
+}
+
+// GenerateDataKey generates a 256-bit data key. It returns the key and the
+// key encrypted by the KMS key, which is what should be stored.
+func (k *AWSKMS) GenerateDataKey() (plaintext []byte, encrypted []byte, err error) {
+	client, optFns, err := k.client()
+	if err != nil {
+		return nil, nil, fmt.Errorf("generating data key with KMS key %q: %w", k.KeyID, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), httpTimeout)
+	defer cancel()
+	out, err := client.GenerateDataKey(ctx, &kms.GenerateDataKeyInput{
+		KeyId:   aws.String(k.KeyID),
+		KeySpec: types.DataKeySpecAes256,
+	}, optFns...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generating data key with KMS key %q: %w", k.KeyID, err)
+	}
+	return out.Plaintext, out.CiphertextBlob, nil
+}
+
+// Decrypt decrypts a data key returned by GenerateDataKey.
+func (k *AWSKMS) Decrypt(encrypted []byte) ([]byte, error) {
+	client, optFns, err := k.client()
+	if err != nil {
+		return nil, fmt.Errorf("decrypting data key with KMS key %q: %w", k.KeyID, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), httpTimeout)
+	defer cancel()
+	out, err := client.Decrypt(ctx, &kms.DecryptInput{
+		KeyId:          aws.String(k.KeyID),
+		CiphertextBlob: encrypted,
+	}, optFns...)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting data key with KMS key %q: %w", k.KeyID, err)
+	}
+	return out.Plaintext, nil
+}
+
+// client returns the KMS client and the options of the calls using the key,
+// which set the key's region if it's an ARN.
+func (k *AWSKMS) client() (*kms.Client, []func(*kms.Options), error) {
+	cfg, err := loadAWSConfig(k.Config)
+	if err != nil {
+		return nil, nil, err
+	}
+	var optFns []func(*kms.Options)
+	if region := arnRegion(k.KeyID); region != "" {
+		optFns = append(optFns, func(o *kms.Options) { o.Region = region })
+	} else if cfg.Region == "" {
+		return nil, nil, errors.New("AWS_REGION must be set to use KMS keys that aren't referenced by ARN")
+	}
+	return kms.NewFromConfig(cfg), optFns, nil
+}
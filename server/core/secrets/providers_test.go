@@ -0,0 +1,166 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package secrets_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/runatlantis/atlantis/server/core/secrets"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestVault_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/data/atlantis/github" || r.Header.Get("X-Vault-Token") != "vault-token" || r.Header.Get("X-Vault-Namespace") != "ops" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"token":"github-token"}}}`)) // nolint: errcheck
+	}))
+	defer server.Close()
+	vault := &secrets.Vault{Address: server.URL, Token: "vault-token", Namespace: "ops"}
+
+	secret, err := vault.Fetch(secrets.Reference{Scheme: "vault", Path: "secret/atlantis/github", Key: "token"})
+	Ok(t, err)
+	Equals(t, "github-token", secret)
+
+	_, err = vault.Fetch(secrets.Reference{Scheme: "vault", Path: "secret/atlantis/github", Key: "missing"})
+	ErrEquals(t, `secret has no key "missing"`, err)
+
+	_, err = vault.Fetch(secrets.Reference{Scheme: "vault", Path: "secret/atlantis/github"})
+	ErrEquals(t, "references to Vault secrets must be vault://<mount>/<path>#<key>", err)
+
+	_, err = vault.Fetch(secrets.Reference{Scheme: "vault", Path: "secret/other", Key: "token"})
+	ErrEquals(t, "unexpected status 403 Forbidden", err)
+}
+
+func TestAWSSecretsManager_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Equals(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		Equals(t, "session-token", r.Header.Get("X-Amz-Security-Token"))
+		Assert(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access-key/"), "unexpected authorization %q", r.Header.Get("Authorization"))
+		var input struct {
+			SecretID string `json:"SecretId"`
+		}
+		Ok(t, json.NewDecoder(r.Body).Decode(&input))
+		Assert(t, strings.Contains(r.Header.Get("Authorization"), "/"+map[string]string{
+			"atlantis/github": "us-east-1",
+			"arn:aws:secretsmanager:eu-west-1:123456789012:secret:atlantis/github": "eu-west-1",
+		}[input.SecretID]+"/secretsmanager/aws4_request"), "unexpected credential scope %q", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"SecretString":"{\"token\":\"github-token\"}"}`)) // nolint: errcheck
+	}))
+	defer server.Close()
+	sm := &secrets.AWSSecretsManager{
+		Config: &aws.Config{
+			Region:       "us-east-1",
+			Credentials:  credentials.NewStaticCredentialsProvider("access-key", "secret-key", "session-token"),
+			BaseEndpoint: aws.String(server.URL),
+		},
+	}
+
+	secret, err := sm.Fetch(secrets.Reference{Scheme: "aws-sm", Path: "atlantis/github"})
+	Ok(t, err)
+	Equals(t, `{"token":"github-token"}`, secret)
+
+	secret, err = sm.Fetch(secrets.Reference{Scheme: "aws-sm", Path: "arn:aws:secretsmanager:eu-west-1:123456789012:secret:atlantis/github", Key: "token"})
+	Ok(t, err)
+	Equals(t, "github-token", secret)
+
+	_, err = (&secrets.AWSSecretsManager{Config: &aws.Config{}}).Fetch(secrets.Reference{Scheme: "aws-sm", Path: "atlantis/github"})
+	ErrEquals(t, "AWS_REGION must be set to fetch secrets by name from AWS Secrets Manager", err)
+}
+
+func TestGCPSecretManager_Fetch(t *testing.T) {
+	var tokenFetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/computeMetadata/v1/instance/service-accounts/default/token":
+			Equals(t, "Google", r.Header.Get("Metadata-Flavor"))
+			tokenFetches.Add(1)
+			w.Write([]byte(`{"access_token":"instance-token","expires_in":3600}`)) // nolint: errcheck
+		case "/v1/projects/p/secrets/github/versions/latest:access", "/v1/projects/p/secrets/github/versions/3:access":
+			Equals(t, "Bearer instance-token", r.Header.Get("Authorization"))
+			data := base64.StdEncoding.EncodeToString([]byte(`{"token":"github-token"}`))
+			w.Write([]byte(`{"payload":{"data":"` + data + `"}}`)) // nolint: errcheck
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	sm := &secrets.GCPSecretManager{APIURL: server.URL, GCPTokenSource: &secrets.GCPTokenSource{MetadataURL: server.URL}}
+
+	// The instance token is fetched once and shared by concurrent fetches.
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			secret, err := sm.Fetch(secrets.Reference{Scheme: "gcp-sm", Path: "projects/p/secrets/github", Key: "token"})
+			Ok(t, err)
+			Equals(t, "github-token", secret)
+		}()
+	}
+	wg.Wait()
+
+	secret, err := sm.Fetch(secrets.Reference{Scheme: "gcp-sm", Path: "projects/p/secrets/github/versions/3"})
+	Ok(t, err)
+	Equals(t, `{"token":"github-token"}`, secret)
+	Equals(t, int32(1), tokenFetches.Load())
+
+	_, err = sm.Fetch(secrets.Reference{Scheme: "gcp-sm", Path: "github"})
+	ErrEquals(t, "references to Secret Manager secrets must be gcp-sm://projects/<project>/secrets/<secret>[/versions/<version>]", err)
+}
+
+func TestAWSKMS(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Assert(t, strings.Contains(r.Header.Get("Authorization"), "/eu-west-1/kms/aws4_request"), "unexpected credential scope %q", r.Header.Get("Authorization"))
+		var input struct {
+			KeyID          string `json:"KeyId"`
+			KeySpec        string
+			CiphertextBlob []byte
+		}
+		Ok(t, json.NewDecoder(r.Body).Decode(&input))
+		Equals(t, "arn:aws:kms:eu-west-1:123456789012:key/atlantis", input.KeyID)
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.GenerateDataKey":
+			Equals(t, "AES_256", input.KeySpec)
+			w.Write([]byte(`{"Plaintext":"ZGF0YS1rZXk=","CiphertextBlob":"ZW5jcnlwdGVk"}`)) // nolint: errcheck
+		case "TrentService.Decrypt":
+			Equals(t, "encrypted", string(input.CiphertextBlob))
+			w.Write([]byte(`{"Plaintext":"ZGF0YS1rZXk="}`)) // nolint: errcheck
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+	kms := &secrets.AWSKMS{
+		KeyID: "arn:aws:kms:eu-west-1:123456789012:key/atlantis",
+		Config: &aws.Config{
+			Region:       "us-east-1",
+			Credentials:  credentials.NewStaticCredentialsProvider("access-key", "secret-key", ""),
+			BaseEndpoint: aws.String(server.URL),
+		},
+	}
+
+	key, encrypted, err := kms.GenerateDataKey()
+	Ok(t, err)
+	Equals(t, "data-key", string(key))
+	Equals(t, "encrypted", string(encrypted))
+
+	key, err = kms.Decrypt(encrypted)
+	Ok(t, err)
+	Equals(t, "data-key", string(key))
+
+	_, _, err = (&secrets.AWSKMS{KeyID: "atlantis", Config: &aws.Config{}}).GenerateDataKey()
+	ErrEquals(t, `generating data key with KMS key "atlantis": AWS_REGION must be set to use KMS keys that aren't referenced by ARN`, err)
+}
@@ -0,0 +1,209 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package secrets resolves the server's credentials from references to secrets
+// stored in secret managers, ex. vault://secret/atlantis#github-token.
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// httpTimeout is how long fetching a secret can take.
+const httpTimeout = 10 * time.Second
+
+// Reference references a secret stored in a secret manager. It's written
+// <scheme>://<path>[#<key>].
+type Reference struct {
+	// Scheme selects the secret manager, ex. vault.
+	Scheme string
+	// Path identifies the secret in the secret manager.
+	Path string
+	// Key selects a field of the secret, ex. a key of a JSON secret. Empty if
+	// the whole secret is used.
+	Key string
+}
+
+func (r Reference) String() string {
+	if r.Key == "" {
+		return r.Scheme + "://" + r.Path
+	}
+	return r.Scheme + "://" + r.Path + "#" + r.Key
+}
+
+// Provider fetches the secrets of a secret manager.
+type Provider interface {
+	// Scheme is the scheme of the references to the secrets of the secret
+	// manager, ex. vault.
+	Scheme() string
+	// Fetch fetches the current value of the secret ref references.
+	Fetch(ref Reference) (string, error)
+}
+
+// Resolver resolves references to secrets. Resolved secrets are cached, so a
+// secret referenced several times is fetched once, until they're refreshed.
+// It's run periodically to refresh them and pick up rotated secrets.
+type Resolver struct {
+	providers map[string]Provider
+	logger    logging.SimpleLogging
+
+	mutex    sync.Mutex
+	cache    map[Reference]string
+	watchers map[Reference][]func(string)
+}
+
+// NewResolver returns a Resolver of the references to the secrets of
+// providers.
+func NewResolver(logger logging.SimpleLogging, providers ...Provider) *Resolver {
+	r := &Resolver{
+		providers: make(map[string]Provider),
+		logger:    logger,
+		cache:     make(map[Reference]string),
+		watchers:  make(map[Reference][]func(string)),
+	}
+	for _, provider := range providers {
+		r.providers[provider.Scheme()] = provider
+	}
+	return r
+}
+
+// NewResolverFromEnv returns a Resolver of the references to the secrets of
+// Vault, AWS Secrets Manager and Google Cloud Secret Manager, which are
+// configured by their standard environment variables.
+func NewResolverFromEnv(logger logging.SimpleLogging) *Resolver {
+	return NewResolver(logger, NewVaultFromEnv(), NewAWSSecretsManagerFromEnv(), NewGCPSecretManagerFromEnv())
+}
+
+// ParseReference parses value if it's a reference to a secret of one of the
+// resolver's providers. ok is false if value is a plain secret.
+func (r *Resolver) ParseReference(value string) (ref Reference, ok bool) {
+	scheme, rest, found := strings.Cut(value, "://")
+	if !found {
+		return Reference{}, false
+	}
+	if _, known := r.providers[scheme]; !known {
+		return Reference{}, false
+	}
+	ref = Reference{Scheme: scheme, Path: rest}
+	if i := strings.LastIndex(rest, "#"); i != -1 {
+		ref.Path, ref.Key = rest[:i], rest[i+1:]
+	}
+	return ref, true
+}
+
+// Resolve returns value, or the secret it references if it's a reference.
+func (r *Resolver) Resolve(value string) (string, error) {
+	ref, ok := r.ParseReference(value)
+	if !ok {
+		return value, nil
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if secret, cached := r.cache[ref]; cached {
+		return secret, nil
+	}
+	secret, err := r.providers[ref.Scheme].Fetch(ref)
+	if err != nil {
+		return "", fmt.Errorf("fetching %s: %w", ref, err)
+	}
+	r.cache[ref] = secret
+	return secret, nil
+}
+
+// Watch calls onRotate with the new value of the secret value references when
+// it's rotated. It's a no-op if value isn't a reference.
+func (r *Resolver) Watch(value string, onRotate func(secret string)) {
+	ref, ok := r.ParseReference(value)
+	if !ok {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.watchers[ref] = append(r.watchers[ref], onRotate)
+}
+
+// Run fetches the resolved secrets again and notifies the watchers of the
+// ones that were rotated. Secrets that can't be fetched keep their cached
+// value.
+func (r *Resolver) Run() {
+	r.mutex.Lock()
+	refs := make([]Reference, 0, len(r.cache))
+	for ref := range r.cache {
+		refs = append(refs, ref)
+	}
+	r.mutex.Unlock()
+
+	for _, ref := range refs {
+		secret, err := r.providers[ref.Scheme].Fetch(ref)
+		if err != nil {
+			r.logger.Warn("unable to refresh secret %s, keeping its cached value: %s", ref, err)
+			continue
+		}
+		r.mutex.Lock()
+		rotated := r.cache[ref] != secret
+		r.cache[ref] = secret
+		watchers := r.watchers[ref]
+		r.mutex.Unlock()
+		if !rotated {
+			continue
+		}
+		r.logger.Info("secret %s was rotated", ref)
+		for _, onRotate := range watchers {
+			onRotate(secret)
+		}
+	}
+}
+
+// HasReferences returns whether any reference was resolved.
+func (r *Resolver) HasReferences() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.cache) > 0
+}
+
+// selectKey returns the value of key in secret, a JSON object, or secret if
+// key is empty.
+func selectKey(secret string, key string) (string, error) {
+	if key == "" {
+		return secret, nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("selecting key %q: secret isn't a JSON object", key)
+	}
+	return fieldString(fields, key)
+}
+
+// fieldString returns the string value of key in fields.
+func fieldString(fields map[string]any, key string) (string, error) {
+	value, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("secret has no key %q", key)
+	}
+	str, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("key %q of the secret isn't a string", key)
+	}
+	return str, nil
+}
+
+// doJSON sends req and decodes its JSON response into out.
+func doJSON(req *http.Request, out any) error {
+	client := &http.Client{Timeout: httpTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package secrets_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/secrets"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+type fakeProvider struct {
+	secrets map[string]string
+	err     error
+	fetches int
+}
+
+func (f *fakeProvider) Scheme() string {
+	return "fake"
+}
+
+func (f *fakeProvider) Fetch(ref secrets.Reference) (string, error) {
+	f.fetches++
+	if f.err != nil {
+		return "", f.err
+	}
+	secret, ok := f.secrets[ref.String()]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return secret, nil
+}
+
+func TestResolver_ParseReference(t *testing.T) {
+	r := secrets.NewResolver(logging.NewNoopLogger(t), &fakeProvider{})
+	cases := []struct {
+		value  string
+		expRef secrets.Reference
+		expOk  bool
+	}{
+		{"plain-token", secrets.Reference{}, false},
+		{"https://example.com", secrets.Reference{}, false},
+		{"fake://atlantis/github", secrets.Reference{Scheme: "fake", Path: "atlantis/github"}, true},
+		{"fake://atlantis/github#token", secrets.Reference{Scheme: "fake", Path: "atlantis/github", Key: "token"}, true},
+	}
+	for _, c := range cases {
+		t.Run(c.value, func(t *testing.T) {
+			ref, ok := r.ParseReference(c.value)
+			Equals(t, c.expOk, ok)
+			Equals(t, c.expRef, ref)
+		})
+	}
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	provider := &fakeProvider{secrets: map[string]string{"fake://atlantis#token": "secret"}}
+	r := secrets.NewResolver(logging.NewNoopLogger(t), provider)
+
+	secret, err := r.Resolve("plain-token")
+	Ok(t, err)
+	Equals(t, "plain-token", secret)
+	Equals(t, false, r.HasReferences())
+
+	for range 2 {
+		secret, err = r.Resolve("fake://atlantis#token")
+		Ok(t, err)
+		Equals(t, "secret", secret)
+	}
+	Equals(t, 1, provider.fetches)
+	Equals(t, true, r.HasReferences())
+
+	_, err = r.Resolve("fake://missing")
+	ErrEquals(t, "fetching fake://missing: not found", err)
+}
+
+func TestResolver_Run(t *testing.T) {
+	provider := &fakeProvider{secrets: map[string]string{"fake://atlantis#token": "secret"}}
+	r := secrets.NewResolver(logging.NewNoopLogger(t), provider)
+	_, err := r.Resolve("fake://atlantis#token")
+	Ok(t, err)
+	var rotated []string
+	r.Watch("fake://atlantis#token", func(secret string) {
+		rotated = append(rotated, secret)
+	})
+
+	// Unchanged secrets don't notify the watchers.
+	r.Run()
+	Equals(t, 0, len(rotated))
+
+	provider.secrets["fake://atlantis#token"] = "rotated"
+	r.Run()
+	Equals(t, []string{"rotated"}, rotated)
+	secret, err := r.Resolve("fake://atlantis#token")
+	Ok(t, err)
+	Equals(t, "rotated", secret)
+
+	// Secrets that can't be refreshed keep their cached value.
+	provider.err = errors.New("unavailable")
+	r.Run()
+	Equals(t, []string{"rotated"}, rotated)
+	secret, err = r.Resolve("fake://atlantis#token")
+	Ok(t, err)
+	Equals(t, "rotated", secret)
+}
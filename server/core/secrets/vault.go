@@ -0,0 +1,64 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Vault fetches secrets from the KV version 2 secrets engines of HashiCorp
+// Vault. Its references are vault://<mount>/<path>#<key>.
+type Vault struct {
+	// Address is the address of Vault, ex. https://vault.example.com:8200.
+	Address string
+	// Token authenticates to Vault.
+	Token string
+	// Namespace is the Vault Enterprise namespace of the secrets, if any.
+	Namespace string
+}
+
+// NewVaultFromEnv returns a Vault configured like the Vault CLI by the
+// VAULT_ADDR, VAULT_TOKEN and VAULT_NAMESPACE environment variables.
+func NewVaultFromEnv() *Vault {
+	return &Vault{
+		Address:   os.Getenv("VAULT_ADDR"),
+		Token:     os.Getenv("VAULT_TOKEN"),
+		Namespace: os.Getenv("VAULT_NAMESPACE"),
+	}
+}
+
+func (v *Vault) Scheme() string {
+	return "vault"
+}
+
+func (v *Vault) Fetch(ref Reference) (string, error) {
+	if v.Address == "" || v.Token == "" {
+		return "", errors.New("VAULT_ADDR and VAULT_TOKEN must be set to fetch secrets from Vault")
+	}
+	mount, path, ok := strings.Cut(ref.Path, "/")
+	if !ok || ref.Key == "" {
+		return "", errors.New("references to Vault secrets must be vault://<mount>/<path>#<key>")
+	}
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimSuffix(v.Address, "/"), mount, path), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+	var secret struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := doJSON(req, &secret); err != nil {
+		return "", err
+	}
+	return fieldString(secret.Data.Data, ref.Key)
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package servicenow manages ServiceNow change requests through the
+// ServiceNow Table API.
+package servicenow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const changeRequestTable = "/api/now/table/change_request"
+
+// closedState is the state of a closed change request in the default
+// ServiceNow change model.
+const closedState = "3"
+
+// ChangeRequest is a ServiceNow change request.
+type ChangeRequest struct {
+	SysID    string `json:"sys_id"`
+	Number   string `json:"number"`
+	State    string `json:"state"`
+	Approval string `json:"approval"`
+}
+
+// Approved returns true if the change request has been approved.
+func (c ChangeRequest) Approved() bool {
+	return c.Approval == "approved"
+}
+
+// Client creates, looks up and closes change requests.
+type Client struct {
+	// URL is the base URL of the ServiceNow instance, ex.
+	// https://acme.service-now.com.
+	URL      string
+	User     string
+	Password string
+	HTTP     *http.Client
+}
+
+// NewClient returns a client for the ServiceNow instance at baseURL.
+func NewClient(baseURL string, user string, password string) *Client {
+	return &Client{
+		URL:      strings.TrimSuffix(baseURL, "/"),
+		User:     user,
+		Password: password,
+		HTTP:     http.DefaultClient,
+	}
+}
+
+// EnsureChangeRequest returns the active change request identified by
+// correlationID, creating it if it doesn't exist yet.
+func (c *Client) EnsureChangeRequest(correlationID string, shortDescription string, description string) (ChangeRequest, error) {
+	query := url.Values{}
+	query.Set("sysparm_query", fmt.Sprintf("correlation_id=%s^active=true", escapeQueryValue(correlationID)))
+	query.Set("sysparm_fields", "sys_id,number,state,approval")
+	query.Set("sysparm_limit", "1")
+	var found struct {
+		Result []ChangeRequest `json:"result"`
+	}
+	if err := c.do("GET", changeRequestTable+"?"+query.Encode(), nil, &found); err != nil {
+		return ChangeRequest{}, fmt.Errorf("looking up change request: %w", err)
+	}
+	if len(found.Result) > 0 {
+		return found.Result[0], nil
+	}
+
+	var created struct {
+		Result ChangeRequest `json:"result"`
+	}
+	err := c.do("POST", changeRequestTable, map[string]string{
+		"correlation_id":      correlationID,
+		"correlation_display": "atlantis",
+		"short_description":   shortDescription,
+		"description":         description,
+	}, &created)
+	if err != nil {
+		return ChangeRequest{}, fmt.Errorf("creating change request: %w", err)
+	}
+	return created.Result, nil
+}
+
+// escapeQueryValue escapes value for the value of a condition of an encoded
+// query. Conditions are separated by ^, which is doubled in values. The
+// operator of a condition is its first one, so the operators in values, ex.
+// =, are part of them.
+func escapeQueryValue(value string) string {
+	return strings.ReplaceAll(value, "^", "^^")
+}
+
+// CloseChangeRequest closes cr as successful or unsuccessful with notes.
+func (c *Client) CloseChangeRequest(cr ChangeRequest, successful bool, notes string) error {
+	closeCode := "successful"
+	if !successful {
+		closeCode = "unsuccessful"
+	}
+	err := c.do("PATCH", changeRequestTable+"/"+url.PathEscape(cr.SysID), map[string]string{
+		"state":       closedState,
+		"close_code":  closeCode,
+		"close_notes": notes,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("closing change request %s: %w", cr.Number, err)
+	}
+	return nil
+}
+
+func (c *Client) do(method string, path string, payload any, out any) error {
+	var body io.Reader
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewBuffer(b)
+	}
+	req, err := http.NewRequest(method, c.URL+path, body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.User, c.Password)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("returned status code %d with response %q", resp.StatusCode, respBody)
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package servicenow_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/servicenow"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestEnsureChangeRequest_Existing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		Assert(t, ok, "expected basic auth")
+		Equals(t, "user", user)
+		Equals(t, "password", password)
+		Equals(t, "GET", r.Method)
+		Equals(t, "/api/now/table/change_request", r.URL.Path)
+		Equals(t, "correlation_id=atlantis/owner/repo/1/./default^active=true", r.URL.Query().Get("sysparm_query"))
+		w.Write([]byte(`{"result":[{"sys_id":"abc","number":"CHG0001","state":"-2","approval":"approved"}]}`)) // nolint: errcheck
+	}))
+	defer server.Close()
+
+	client := servicenow.NewClient(server.URL+"/", "user", "password")
+	cr, err := client.EnsureChangeRequest("atlantis/owner/repo/1/./default", "short", "description")
+	Ok(t, err)
+	Equals(t, servicenow.ChangeRequest{SysID: "abc", Number: "CHG0001", State: "-2", Approval: "approved"}, cr)
+	Assert(t, cr.Approved(), "expected change request to be approved")
+}
+
+func TestEnsureChangeRequest_EscapesQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The ^ of the directory doesn't start a condition.
+		Equals(t, "correlation_id=atlantis/owner/repo/1/a^^NQactive=false/default^active=true", r.URL.Query().Get("sysparm_query"))
+		w.Write([]byte(`{"result":[{"sys_id":"abc","number":"CHG0001","state":"-2","approval":"approved"}]}`)) // nolint: errcheck
+	}))
+	defer server.Close()
+
+	client := servicenow.NewClient(server.URL, "user", "password")
+	_, err := client.EnsureChangeRequest("atlantis/owner/repo/1/a^NQactive=false/default", "short", "description")
+	Ok(t, err)
+}
+
+func TestEnsureChangeRequest_Create(t *testing.T) {
+	var created map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			w.Write([]byte(`{"result":[]}`)) // nolint: errcheck
+			return
+		}
+		Equals(t, "POST", r.Method)
+		Ok(t, json.NewDecoder(r.Body).Decode(&created))
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"result":{"sys_id":"abc","number":"CHG0002","state":"-4","approval":"requested"}}`)) // nolint: errcheck
+	}))
+	defer server.Close()
+
+	client := servicenow.NewClient(server.URL, "user", "password")
+	cr, err := client.EnsureChangeRequest("id", "short", "description")
+	Ok(t, err)
+	Equals(t, "CHG0002", cr.Number)
+	Assert(t, !cr.Approved(), "expected change request not to be approved")
+	Equals(t, map[string]string{
+		"correlation_id":      "id",
+		"correlation_display": "atlantis",
+		"short_description":   "short",
+		"description":         "description",
+	}, created)
+}
+
+func TestEnsureChangeRequest_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte("denied")) // nolint: errcheck
+	}))
+	defer server.Close()
+
+	client := servicenow.NewClient(server.URL, "user", "password")
+	_, err := client.EnsureChangeRequest("id", "short", "description")
+	ErrEquals(t, `looking up change request: returned status code 401 with response "denied"`, err)
+}
+
+func TestCloseChangeRequest(t *testing.T) {
+	var closed map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Equals(t, "PATCH", r.Method)
+		Equals(t, "/api/now/table/change_request/abc", r.URL.Path)
+		Ok(t, json.NewDecoder(r.Body).Decode(&closed))
+		w.Write([]byte(`{"result":{}}`)) // nolint: errcheck
+	}))
+	defer server.Close()
+
+	client := servicenow.NewClient(server.URL, "user", "password")
+	Ok(t, client.CloseChangeRequest(servicenow.ChangeRequest{SysID: "abc", Number: "CHG0001"}, false, "notes"))
+	Equals(t, map[string]string{
+		"state":       "3",
+		"close_code":  "unsuccessful",
+		"close_notes": "notes",
+	}, closed)
+}
@@ -23,6 +23,9 @@ type Downloader interface {
 	Install(ctx context.Context, dir string, downloadURL string, v *version.Version) (string, error)
 }
 
+// TofuDownloader downloads OpenTofu releases. The releases are verified
+// against their SHA256SUMS file and its signature with the OpenTofu signing
+// key before they're written to disk.
 type TofuDownloader struct{}
 
 func (d *TofuDownloader) Install(ctx context.Context, dir string, _downloadURL string, v *version.Version) (string, error) {
@@ -46,6 +49,10 @@ func (d *TofuDownloader) Install(ctx context.Context, dir string, _downloadURL s
 	return file, nil
 }
 
+// TerraformDownloader downloads Terraform releases from downloadURL, ex. a
+// mirror of releases.hashicorp.com. The archives are verified against the
+// release's SHA256SUMS file and its signature with HashiCorp's signing key
+// before they're installed, so a mirror can't serve a tampered binary.
 type TerraformDownloader struct{}
 
 func (d *TerraformDownloader) Install(ctx context.Context, dir string, downloadURL string, v *version.Version) (string, error) {
@@ -5,13 +5,21 @@ package terraform_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"runtime"
 	"testing"
 
 	"github.com/hashicorp/go-version"
 	. "github.com/petergtz/pegomock/v4"
 	"github.com/runatlantis/atlantis/cmd"
 	"github.com/runatlantis/atlantis/server/core/terraform"
+	. "github.com/runatlantis/atlantis/testing"
 )
 
 func TestTerraformInstall(t *testing.T) {
@@ -47,3 +55,47 @@ func TestOpenTofuInstall(t *testing.T) {
 		t.Errorf("Binary not found at %s", newPath)
 	}
 }
+
+func TestTerraformInstall_RejectsUnsignedChecksums(t *testing.T) {
+	// A mirror serving a release whose SHA256SUMS isn't signed by HashiCorp.
+	archive := []byte("not terraform")
+	sum := sha256.Sum256(archive)
+	filename := fmt.Sprintf("terraform_1.8.1_%s_%s.zip", runtime.GOOS, runtime.GOARCH)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/terraform/1.8.1/index.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{ // nolint: errcheck
+			"name":              "terraform",
+			"version":           "1.8.1",
+			"shasums":           "terraform_1.8.1_SHA256SUMS",
+			"shasums_signature": "terraform_1.8.1_SHA256SUMS.sig",
+			"builds": []map[string]any{{
+				"name":     "terraform",
+				"version":  "1.8.1",
+				"os":       runtime.GOOS,
+				"arch":     runtime.GOARCH,
+				"filename": filename,
+				"url":      "/terraform/1.8.1/" + filename,
+			}},
+		})
+	})
+	mux.HandleFunc("/terraform/1.8.1/terraform_1.8.1_SHA256SUMS", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, "%s  %s\n", hex.EncodeToString(sum[:]), filename) // nolint: errcheck
+	})
+	mux.HandleFunc("/terraform/1.8.1/terraform_1.8.1_SHA256SUMS.sig", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("forged signature")) // nolint: errcheck
+	})
+	mux.HandleFunc("/terraform/1.8.1/"+filename, func(w http.ResponseWriter, _ *http.Request) {
+		w.Write(archive) // nolint: errcheck
+	})
+	mirror := httptest.NewServer(mux)
+	defer mirror.Close()
+
+	binDir := t.TempDir()
+	v, _ := version.NewVersion("1.8.1")
+	_, err := (&terraform.TerraformDownloader{}).Install(context.Background(), binDir, mirror.URL, v)
+	ErrContains(t, "unable to verify checksums signature", err)
+	entries, err := os.ReadDir(binDir)
+	Ok(t, err)
+	Equals(t, 0, len(entries))
+}
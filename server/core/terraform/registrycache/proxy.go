@@ -0,0 +1,622 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package registrycache implements a caching proxy of the provider and module
+// registry protocols that terraform runs are pointed at, so providers and
+// modules are downloaded once from their registries and keep being served
+// when the registries are down.
+package registrycache
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/logging"
+	"golang.org/x/sync/singleflight"
+)
+
+// RoutePrefix is the path the proxy is served under by the Atlantis server.
+const RoutePrefix = "/registry-cache/"
+
+// DefaultVersionsTTL is how long the version lists of providers and modules
+// are served from the cache before being refreshed from their registry.
+const DefaultVersionsTTL = time.Hour
+
+// DefaultMaxSize is how many bytes of provider packages and module archives
+// are cached by default.
+const DefaultMaxSize = 10 << 30
+
+// Proxy proxies the providers.v1 and modules.v1 services of the registries of
+// Hosts, caching their responses and the provider packages in Dir.
+//
+// Version lists are refreshed after VersionsTTL, the responses for a specific
+// version never change and are cached until the files they point at are
+// evicted. When a registry can't be reached, the cached responses are served
+// however old they are.
+//
+// The proxy's routes are under a random token, only terraform runs pointed at
+// the proxy with CLIConfig know it.
+type Proxy struct {
+	// Dir is the directory the responses and packages are cached in.
+	Dir string
+	// Token is the first part of the path of every route of the proxy.
+	Token string
+	// URL is the URL the proxy is served at, ex.
+	// https://atlantis.example.com/registry-cache/<token>.
+	URL string
+	// Hosts are the hostnames of the registries that are proxied, requests
+	// for other hosts are rejected.
+	Hosts       []string
+	VersionsTTL time.Duration
+	// MaxSize is how many bytes of files, ex. provider packages, are cached.
+	// The least recently served files are evicted once it's exceeded.
+	MaxSize    int64
+	HTTPClient *http.Client
+	Logger     logging.SimpleLogging
+
+	downloads singleflight.Group
+	evictMu   sync.Mutex
+}
+
+// NewProxy returns a proxy of the registries of hosts served at baseURL,
+// ex. the Atlantis URL, caching up to maxSize bytes of files in dir.
+func NewProxy(dir string, baseURL string, hosts []string, maxSize int64, logger logging.SimpleLogging) *Proxy {
+	token := make([]byte, 16)
+	rand.Read(token) // nolint: errcheck
+	p := &Proxy{
+		Dir:         dir,
+		Token:       hex.EncodeToString(token),
+		Hosts:       hosts,
+		VersionsTTL: DefaultVersionsTTL,
+		MaxSize:     maxSize,
+		HTTPClient:  &http.Client{Timeout: 10 * time.Minute},
+		Logger:      logger,
+	}
+	p.URL = strings.TrimSuffix(baseURL, "/") + RoutePrefix + p.Token
+	return p
+}
+
+// CLIConfig returns the host blocks of a terraform CLI configuration file
+// overriding the service discovery of the proxied registries so their
+// providers and modules are fetched through the proxy.
+func (p *Proxy) CLIConfig() string {
+	var config strings.Builder
+	for _, host := range p.Hosts {
+		fmt.Fprintf(&config, "host %q {\n  services = {\n    \"modules.v1\"   = %q\n    \"providers.v1\" = %q\n  }\n}\n",
+			host, fmt.Sprintf("%s/%s/modules/", p.URL, host), fmt.Sprintf("%s/%s/providers/", p.URL, host))
+	}
+	return config.String()
+}
+
+// WriteCLIConfig writes the CLI configuration of CLIConfig to file, after
+// the contents of the existing configuration file base, if any, since
+// pointing terraform at file replaces it.
+func (p *Proxy) WriteCLIConfig(file string, base string) error {
+	var config string
+	if base != "" {
+		contents, err := os.ReadFile(base) // nolint: gosec
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("reading %s: %w", base, err)
+		}
+		if len(contents) > 0 {
+			config = strings.TrimRight(string(contents), "\n") + "\n\n"
+		}
+	}
+	config += p.CLIConfig()
+	if err := os.WriteFile(file, []byte(config), 0600); err != nil {
+		return fmt.Errorf("writing registry cache CLI configuration to %s: %w", file, err)
+	}
+	return nil
+}
+
+// ServeHTTP serves the requests under RoutePrefix, ex.
+// /registry-cache/<token>/registry.terraform.io/providers/hashicorp/aws/versions.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	token, routePath, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, RoutePrefix), "/")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(p.Token)) != 1 {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	host, service, rest, ok := splitPath(routePath)
+	if !ok || !slices.Contains(p.Hosts, host) {
+		http.NotFound(w, r)
+		return
+	}
+
+	var err error
+	switch {
+	case service == "providers" && len(rest) == 3 && rest[2] == "versions":
+		err = p.serveVersions(w, host, "providers.v1", rest)
+	case service == "providers" && len(rest) == 6 && rest[3] == "download":
+		err = p.serveProviderDownload(w, host, rest)
+	case service == "modules" && len(rest) == 4 && rest[3] == "versions":
+		err = p.serveVersions(w, host, "modules.v1", rest)
+	case service == "modules" && len(rest) == 5 && rest[4] == "download":
+		err = p.serveModuleDownload(w, host, rest)
+	case service == "files" && len(rest) == 2:
+		err = p.serveFile(w, r, host, rest[0])
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		var upstreamErr *upstreamError
+		if errors.As(err, &upstreamErr) && upstreamErr.Status == http.StatusNotFound {
+			http.NotFound(w, r)
+			return
+		}
+		p.Logger.Warn("registry cache failed to serve %s: %s", r.URL.Path, err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+	}
+}
+
+// serveVersions serves the version list of a provider or module from the
+// cache, refreshing it once it's older than VersionsTTL.
+func (p *Proxy) serveVersions(w http.ResponseWriter, host string, service string, rest []string) error {
+	body, _, err := p.fetchCached(host, service, rest, p.VersionsTTL)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body) // nolint: errcheck
+	return nil
+}
+
+// providerPackage is the response of the provider download endpoint, only its
+// URLs are rewritten, the other fields are passed through.
+type providerPackage map[string]any
+
+// providerPackageURLs are the fields of providerPackage holding URLs of files
+// served through the proxy.
+var providerPackageURLs = []string{"download_url", "shasums_url", "shasums_signature_url"}
+
+// serveProviderDownload serves the package of a provider version, pointing
+// its files at the proxy.
+func (p *Proxy) serveProviderDownload(w http.ResponseWriter, host string, rest []string) error {
+	body, upstreamURL, err := p.fetchCached(host, "providers.v1", rest, 0)
+	if err != nil {
+		return err
+	}
+	var pkg providerPackage
+	if err := json.Unmarshal(body, &pkg); err != nil {
+		return fmt.Errorf("parsing the provider package from %s: %w", upstreamURL, err)
+	}
+	for _, field := range providerPackageURLs {
+		fileURL, ok := pkg[field].(string)
+		if !ok || fileURL == "" {
+			continue
+		}
+		proxied, err := p.proxyFile(host, upstreamURL, fileURL)
+		if err != nil {
+			return err
+		}
+		pkg[field] = proxied
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(pkg)
+}
+
+// serveModuleDownload serves the source address of a module version. The
+// archives of HTTP sources are served through the proxy, other sources, ex.
+// git repositories, are returned as is.
+func (p *Proxy) serveModuleDownload(w http.ResponseWriter, host string, rest []string) error {
+	path, err := p.cachePath(host, "modules.v1", rest)
+	if err != nil {
+		return err
+	}
+	body, err := p.cached(path, 0, func() ([]byte, error) {
+		source, err := p.fetchModuleSource(host, rest)
+		return []byte(source), err
+	})
+	if err != nil {
+		return err
+	}
+	source := string(body)
+	if archive, subdir, ok := httpArchiveSource(source); ok {
+		proxied, err := p.proxyFile(host, "", archive)
+		if err != nil {
+			return err
+		}
+		// The module installer expects the subdirectory before the query,
+		// ex. .../vpc//modules/vpc?archive=zip.
+		source = proxied + subdir
+		if parsed, err := url.Parse(archive); err == nil && parsed.Query().Has("archive") {
+			source += "?archive=" + url.QueryEscape(parsed.Query().Get("archive"))
+		}
+	}
+	w.Header().Set("X-Terraform-Get", source)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// fetchModuleSource returns the source address of a module version, resolved
+// against the URL it was fetched from.
+func (p *Proxy) fetchModuleSource(host string, rest []string) (string, error) {
+	upstreamURL, err := p.upstreamURL(host, "modules.v1", rest)
+	if err != nil {
+		return "", err
+	}
+	resp, err := p.get(upstreamURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	source := resp.Header.Get("X-Terraform-Get")
+	if source == "" {
+		var location struct {
+			Location string `json:"location"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&location); err != nil || location.Location == "" {
+			return "", fmt.Errorf("%s returned no module source", upstreamURL)
+		}
+		source = location.Location
+	}
+	// Relative sources, ex. ./archive.tar.gz, are relative to the download
+	// URL.
+	if strings.HasPrefix(source, "/") || strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../") {
+		base, _ := url.Parse(upstreamURL)
+		ref, err := url.Parse(source)
+		if err != nil {
+			return "", fmt.Errorf("parsing module source %q: %w", source, err)
+		}
+		source = base.ResolveReference(ref).String()
+	}
+	return source, nil
+}
+
+// proxyFile records the upstream URL of a file, resolved against base, and
+// returns the URL it is served at by the proxy.
+func (p *Proxy) proxyFile(host string, base string, fileURL string) (string, error) {
+	if base != "" {
+		baseURL, err := url.Parse(base)
+		if err != nil {
+			return "", err
+		}
+		ref, err := url.Parse(fileURL)
+		if err != nil {
+			return "", fmt.Errorf("parsing %q: %w", fileURL, err)
+		}
+		fileURL = baseURL.ResolveReference(ref).String()
+	}
+	parsed, err := url.Parse(fileURL)
+	if err != nil {
+		return "", fmt.Errorf("parsing %q: %w", fileURL, err)
+	}
+	sum := sha256.Sum256([]byte(fileURL))
+	key := hex.EncodeToString(sum[:])
+	mapping := filepath.Join(p.Dir, host, "files", key+".url")
+	if _, err := os.Stat(mapping); os.IsNotExist(err) {
+		if err := writeAtomic(mapping, []byte(fileURL)); err != nil {
+			return "", err
+		}
+	}
+	name := path.Base(parsed.Path)
+	if name == "." || name == "/" {
+		name = "file"
+	}
+	return fmt.Sprintf("%s/%s/files/%s/%s", p.URL, host, key, name), nil
+}
+
+// serveFile serves a file from the cache, downloading it on the first
+// request or once it was evicted.
+func (p *Proxy) serveFile(w http.ResponseWriter, r *http.Request, host string, key string) error {
+	if len(key) != sha256.Size*2 || strings.Trim(key, "0123456789abcdef") != "" {
+		http.NotFound(w, r)
+		return nil
+	}
+	file := filepath.Join(p.Dir, host, "files", key)
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		fileURL, err := os.ReadFile(file + ".url") // nolint: gosec
+		if os.IsNotExist(err) {
+			http.NotFound(w, r)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// Concurrent requests for the same file share the download.
+		_, err, _ = p.downloads.Do(key, func() (any, error) {
+			return nil, p.download(string(fileURL), file)
+		})
+		if err != nil {
+			return err
+		}
+		p.evict(file)
+	} else {
+		// The modification time of files is when they were last served, the
+		// least recently served are evicted first.
+		now := time.Now()
+		os.Chtimes(file, now, now) // nolint: errcheck
+	}
+	http.ServeFile(w, r, file)
+	return nil
+}
+
+// evict deletes the least recently served files, except keep, until they take
+// up at most MaxSize bytes. Their URLs are kept so they're downloaded again
+// when they're requested.
+func (p *Proxy) evict(keep string) {
+	if p.MaxSize <= 0 {
+		return
+	}
+	p.evictMu.Lock()
+	defer p.evictMu.Unlock()
+	type cachedFile struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []cachedFile
+	var total int64
+	dirs, _ := filepath.Glob(filepath.Join(p.Dir, "*", "files"))
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || strings.HasSuffix(name, ".url") || strings.HasSuffix(name, ".tmp") {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			files = append(files, cachedFile{filepath.Join(dir, name), info.Size(), info.ModTime()})
+			total += info.Size()
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, f := range files {
+		if total <= p.MaxSize {
+			return
+		}
+		if f.path == keep {
+			continue
+		}
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			p.Logger.Warn("registry cache failed to evict %s: %s", f.path, err)
+			continue
+		}
+		total -= f.size
+		p.Logger.Debug("registry cache evicted %s", f.path)
+	}
+}
+
+// download downloads fileURL to file.
+func (p *Proxy) download(fileURL string, file string) error {
+	if _, err := os.Stat(file); err == nil {
+		return nil
+	}
+	// The archive query parameter is for the module installer, not the
+	// server hosting the file.
+	if parsed, err := url.Parse(fileURL); err == nil && parsed.Query().Has("archive") {
+		query := parsed.Query()
+		query.Del("archive")
+		parsed.RawQuery = query.Encode()
+		fileURL = parsed.String()
+	}
+	resp, err := p.get(fileURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // nolint: errcheck
+	if _, err := io.Copy(tmp, resp.Body); err != nil {
+		tmp.Close() // nolint: errcheck
+		return fmt.Errorf("downloading %s: %w", fileURL, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	p.Logger.Info("registry cache downloaded %s", fileURL)
+	return os.Rename(tmp.Name(), file)
+}
+
+// fetchCached returns the response of the registry of host for the path rest
+// of service, from the cache if it isn't older than ttl, where a ttl of 0
+// never expires, and its upstream URL.
+func (p *Proxy) fetchCached(host string, service string, rest []string, ttl time.Duration) ([]byte, string, error) {
+	path, err := p.cachePath(host, service, rest)
+	if err != nil {
+		return nil, "", err
+	}
+	upstreamURL, err := p.upstreamURL(host, service, rest)
+	if err != nil {
+		return nil, "", err
+	}
+	body, err := p.cached(path, ttl, func() ([]byte, error) {
+		resp, err := p.get(upstreamURL)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close() // nolint: errcheck
+		return io.ReadAll(resp.Body)
+	})
+	return body, upstreamURL, err
+}
+
+// cached returns the contents of file if it isn't older than ttl, where a
+// ttl of 0 never expires. Otherwise it's replaced with the result of fetch,
+// unless fetch fails, in which case the stale contents are returned.
+func (p *Proxy) cached(file string, ttl time.Duration, fetch func() ([]byte, error)) ([]byte, error) {
+	info, statErr := os.Stat(file)
+	if statErr == nil && (ttl == 0 || time.Since(info.ModTime()) < ttl) {
+		return os.ReadFile(file) // nolint: gosec
+	}
+	body, err := fetch()
+	if err != nil {
+		if statErr == nil {
+			p.Logger.Warn("registry cache serving stale %s: %s", file, err)
+			return os.ReadFile(file) // nolint: gosec
+		}
+		return nil, err
+	}
+	if err := writeAtomic(file, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// cachePath returns the file the response for the path rest of service of
+// host is cached in.
+func (p *Proxy) cachePath(host string, service string, rest []string) (string, error) {
+	for _, part := range rest {
+		if part == "" || part == "." || part == ".." || strings.ContainsAny(part, `/\`) {
+			return "", &upstreamError{Status: http.StatusNotFound}
+		}
+	}
+	return filepath.Join(append([]string{p.Dir, host, service}, rest...)...) + ".json", nil
+}
+
+// upstreamURL returns the URL of the path rest of service in the registry of
+// host, found with the registry's service discovery.
+func (p *Proxy) upstreamURL(host string, service string, rest []string) (string, error) {
+	discoveryURL := fmt.Sprintf("https://%s/.well-known/terraform.json", host)
+	body, err := p.cached(filepath.Join(p.Dir, host, "discovery.json"), p.VersionsTTL, func() ([]byte, error) {
+		resp, err := p.get(discoveryURL)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close() // nolint: errcheck
+		return io.ReadAll(resp.Body)
+	})
+	if err != nil {
+		return "", err
+	}
+	var services map[string]any
+	if err := json.Unmarshal(body, &services); err != nil {
+		return "", fmt.Errorf("parsing the service discovery of %s: %w", host, err)
+	}
+	servicePath, ok := services[service].(string)
+	if !ok {
+		return "", fmt.Errorf("%s doesn't serve %s", host, service)
+	}
+	base, _ := url.Parse(discoveryURL)
+	ref, err := url.Parse(strings.TrimSuffix(servicePath, "/") + "/")
+	if err != nil {
+		return "", fmt.Errorf("parsing the %s URL of %s: %w", service, host, err)
+	}
+	escaped := make([]string, len(rest))
+	for i, part := range rest {
+		escaped[i] = url.PathEscape(part)
+	}
+	return base.ResolveReference(ref).String() + strings.Join(escaped, "/"), nil
+}
+
+// get gets u, returning an upstreamError if it doesn't respond with a 2xx.
+func (p *Proxy) get(u string) (*http.Response, error) {
+	resp, err := p.HTTPClient.Get(u) // nolint: gosec, noctx
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close() // nolint: errcheck
+		return nil, &upstreamError{URL: u, Status: resp.StatusCode}
+	}
+	return resp, nil
+}
+
+// upstreamError is returned when a registry responds with an error.
+type upstreamError struct {
+	URL    string
+	Status int
+}
+
+func (e *upstreamError) Error() string {
+	return fmt.Sprintf("GET %s returned %d", e.URL, e.Status)
+}
+
+// splitPath splits a path under RoutePrefix, ex.
+// registry.terraform.io/providers/hashicorp/aws/versions, into its host,
+// service and the rest of its parts.
+func splitPath(p string) (string, string, []string, bool) {
+	parts := strings.Split(strings.Trim(p, "/"), "/")
+	if len(parts) < 3 {
+		return "", "", nil, false
+	}
+	return parts[0], parts[1], parts[2:], true
+}
+
+// httpArchiveSource returns the URL and subdirectory, ex. //modules/vpc, of a
+// module source downloading an archive over HTTP. Sources forcing a getter,
+// ex. git::https://..., aren't HTTP archives.
+func httpArchiveSource(source string) (string, string, bool) {
+	if !strings.HasPrefix(source, "https://") && !strings.HasPrefix(source, "http://") {
+		return "", "", false
+	}
+	parsed, err := url.Parse(source)
+	if err != nil {
+		return "", "", false
+	}
+	// The subdirectory of the archive follows a double slash in the path,
+	// ex. https://example.com/vpc.zip//modules/vpc.
+	urlPath := parsed.Path
+	var subdir string
+	if i := strings.Index(urlPath, "//"); i >= 0 {
+		urlPath, subdir = urlPath[:i], urlPath[i:]
+	}
+	if !isArchive(urlPath) && !parsed.Query().Has("archive") {
+		return "", "", false
+	}
+	parsed.Path = urlPath
+	parsed.RawPath = ""
+	return parsed.String(), subdir, true
+}
+
+// isArchive returns whether the path is of an archive the module installer
+// extracts.
+func isArchive(p string) bool {
+	for _, ext := range []string{".zip", ".tar.gz", ".tgz", ".tar.bz2", ".tbz2", ".tar.xz", ".txz"} {
+		if strings.HasSuffix(p, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeAtomic writes contents to file through a temporary file so readers
+// never see it partially written.
+func writeAtomic(file string, contents []byte) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // nolint: errcheck
+	if _, err := tmp.Write(contents); err != nil {
+		tmp.Close() // nolint: errcheck
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
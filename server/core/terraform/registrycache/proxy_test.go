@@ -0,0 +1,240 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package registrycache_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/terraform/registrycache"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// fakeRegistry is a registry serving the hashicorp/null provider and the
+// hashicorp/consul/aws module.
+type fakeRegistry struct {
+	*httptest.Server
+	down     atomic.Bool
+	requests atomic.Int32
+}
+
+func newFakeRegistry(t *testing.T) *fakeRegistry {
+	registry := &fakeRegistry{}
+	registry.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		registry.requests.Add(1)
+		if registry.down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		switch r.URL.Path {
+		case "/.well-known/terraform.json":
+			w.Write([]byte(`{"providers.v1":"/v1/providers/","modules.v1":"/v1/modules/"}`)) // nolint: errcheck
+		case "/v1/providers/hashicorp/null/versions":
+			w.Write([]byte(`{"versions":[{"version":"3.2.1"}]}`)) // nolint: errcheck
+		case "/v1/providers/hashicorp/null/3.2.1/download/linux/amd64":
+			w.Write([]byte(`{"os":"linux","arch":"amd64","filename":"null_3.2.1_linux_amd64.zip","download_url":"/files/null_3.2.1_linux_amd64.zip","shasums_url":"https://` + r.Host + `/files/SHA256SUMS","shasum":"abc"}`)) // nolint: errcheck
+		case "/files/null_3.2.1_linux_amd64.zip":
+			w.Write([]byte("provider")) // nolint: errcheck
+		case "/files/SHA256SUMS":
+			w.Write([]byte("abc null_3.2.1_linux_amd64.zip")) // nolint: errcheck
+		case "/files/consul.tar.gz":
+			w.Write([]byte("module")) // nolint: errcheck
+		case "/v1/modules/hashicorp/consul/aws/0.1.0/download":
+			w.Header().Set("X-Terraform-Get", "/files/consul.tar.gz//modules/consul-cluster")
+			w.WriteHeader(http.StatusNoContent)
+		case "/v1/modules/hashicorp/consul/aws/0.2.0/download":
+			w.Header().Set("X-Terraform-Get", "git::https://github.com/hashicorp/terraform-aws-consul?ref=v0.2.0")
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(registry.Close)
+	return registry
+}
+
+func (f *fakeRegistry) host() string {
+	return strings.TrimPrefix(f.URL, "https://")
+}
+
+func newProxy(t *testing.T, registry *fakeRegistry) *registrycache.Proxy {
+	proxy := registrycache.NewProxy(t.TempDir(), "", []string{registry.host()}, 0, logging.NewNoopLogger(t))
+	proxy.HTTPClient = registry.Client()
+	server := httptest.NewServer(proxy)
+	t.Cleanup(server.Close)
+	proxy.URL = server.URL + registrycache.RoutePrefix + proxy.Token
+	return proxy
+}
+
+func get(t *testing.T, u string) (int, http.Header, string) {
+	t.Helper()
+	resp, err := http.Get(u) // nolint: gosec, noctx
+	Ok(t, err)
+	defer resp.Body.Close() // nolint: errcheck
+	body, err := io.ReadAll(resp.Body)
+	Ok(t, err)
+	return resp.StatusCode, resp.Header, string(body)
+}
+
+func TestProxy_ProviderVersions(t *testing.T) {
+	registry := newFakeRegistry(t)
+	proxy := newProxy(t, registry)
+	versionsURL := proxy.URL + "/" + registry.host() + "/providers/hashicorp/null/versions"
+
+	status, _, body := get(t, versionsURL)
+	Equals(t, http.StatusOK, status)
+	Equals(t, `{"versions":[{"version":"3.2.1"}]}`, body)
+
+	// The version list is served from the cache until it expires.
+	requests := registry.requests.Load()
+	get(t, versionsURL)
+	Equals(t, requests, registry.requests.Load())
+
+	// Once expired, it's served stale while the registry is down.
+	proxy.VersionsTTL = time.Nanosecond
+	registry.down.Store(true)
+	status, _, body = get(t, versionsURL)
+	Equals(t, http.StatusOK, status)
+	Equals(t, `{"versions":[{"version":"3.2.1"}]}`, body)
+
+	status, _, _ = get(t, proxy.URL+"/"+registry.host()+"/providers/hashicorp/aws/versions")
+	Equals(t, http.StatusBadGateway, status)
+}
+
+func TestProxy_ProviderDownload(t *testing.T) {
+	registry := newFakeRegistry(t)
+	proxy := newProxy(t, registry)
+
+	status, _, body := get(t, proxy.URL+"/"+registry.host()+"/providers/hashicorp/null/3.2.1/download/linux/amd64")
+	Equals(t, http.StatusOK, status)
+	var pkg map[string]string
+	Ok(t, json.Unmarshal([]byte(body), &pkg))
+	Equals(t, "abc", pkg["shasum"])
+	filesURL := proxy.URL + "/" + registry.host() + "/files/"
+	Assert(t, strings.HasPrefix(pkg["download_url"], filesURL), "download_url %s isn't proxied", pkg["download_url"])
+	Assert(t, strings.HasSuffix(pkg["download_url"], "/null_3.2.1_linux_amd64.zip"), "download_url %s lost its filename", pkg["download_url"])
+	Assert(t, strings.HasPrefix(pkg["shasums_url"], filesURL), "shasums_url %s isn't proxied", pkg["shasums_url"])
+
+	status, _, body = get(t, pkg["download_url"])
+	Equals(t, http.StatusOK, status)
+	Equals(t, "provider", body)
+
+	// The package and its files are served while the registry is down.
+	registry.down.Store(true)
+	status, _, _ = get(t, proxy.URL+"/"+registry.host()+"/providers/hashicorp/null/3.2.1/download/linux/amd64")
+	Equals(t, http.StatusOK, status)
+	status, _, body = get(t, pkg["download_url"])
+	Equals(t, http.StatusOK, status)
+	Equals(t, "provider", body)
+	status, _, _ = get(t, pkg["shasums_url"])
+	Equals(t, http.StatusBadGateway, status)
+}
+
+func TestProxy_ModuleDownload(t *testing.T) {
+	registry := newFakeRegistry(t)
+	proxy := newProxy(t, registry)
+	modulesURL := proxy.URL + "/" + registry.host() + "/modules/hashicorp/consul/aws/"
+
+	status, header, _ := get(t, modulesURL+"0.1.0/download")
+	Equals(t, http.StatusNoContent, status)
+	source := header.Get("X-Terraform-Get")
+	Assert(t, strings.HasPrefix(source, proxy.URL+"/"+registry.host()+"/files/"), "source %s isn't proxied", source)
+	Assert(t, strings.HasSuffix(source, "/consul.tar.gz//modules/consul-cluster"), "source %s lost its subdirectory", source)
+
+	status, _, body := get(t, strings.TrimSuffix(source, "//modules/consul-cluster"))
+	Equals(t, http.StatusOK, status)
+	Equals(t, "module", body)
+
+	status, header, _ = get(t, modulesURL+"0.2.0/download")
+	Equals(t, http.StatusNoContent, status)
+	Equals(t, "git::https://github.com/hashicorp/terraform-aws-consul?ref=v0.2.0", header.Get("X-Terraform-Get"))
+}
+
+func TestProxy_RejectsOtherHosts(t *testing.T) {
+	registry := newFakeRegistry(t)
+	proxy := newProxy(t, registry)
+
+	status, _, _ := get(t, proxy.URL+"/example.com/providers/hashicorp/null/versions")
+	Equals(t, http.StatusNotFound, status)
+	// Requests need the proxy's token.
+	status, _, _ = get(t, strings.TrimSuffix(proxy.URL, proxy.Token)+"0123456789abcdef/"+registry.host()+"/providers/hashicorp/null/versions")
+	Equals(t, http.StatusUnauthorized, status)
+	status, _, _ = get(t, strings.TrimSuffix(proxy.URL, proxy.Token)+registry.host()+"/providers/hashicorp/null/versions")
+	Equals(t, http.StatusUnauthorized, status)
+	status, _, _ = get(t, proxy.URL+"/"+registry.host()+"/files/not-a-key/file")
+	Equals(t, http.StatusNotFound, status)
+	Equals(t, int32(0), registry.requests.Load())
+}
+
+func TestProxy_EvictsLeastRecentlyServedFiles(t *testing.T) {
+	registry := newFakeRegistry(t)
+	proxy := newProxy(t, registry)
+	proxy.MaxSize = int64(len("provider") + len("abc null_3.2.1_linux_amd64.zip"))
+
+	_, _, body := get(t, proxy.URL+"/"+registry.host()+"/providers/hashicorp/null/3.2.1/download/linux/amd64")
+	var pkg map[string]string
+	Ok(t, json.Unmarshal([]byte(body), &pkg))
+	_, header, _ := get(t, proxy.URL+"/"+registry.host()+"/modules/hashicorp/consul/aws/0.1.0/download")
+	moduleURL := strings.TrimSuffix(header.Get("X-Terraform-Get"), "//modules/consul-cluster")
+
+	get(t, pkg["download_url"])
+	time.Sleep(10 * time.Millisecond)
+	get(t, moduleURL)
+	time.Sleep(10 * time.Millisecond)
+	// Serving the provider again makes the module the least recently served.
+	get(t, pkg["download_url"])
+
+	// Caching the SHA256SUMS exceeds the size, the module is evicted.
+	status, _, _ := get(t, pkg["shasums_url"])
+	Equals(t, http.StatusOK, status)
+	registry.down.Store(true)
+	status, _, body = get(t, pkg["download_url"])
+	Equals(t, http.StatusOK, status)
+	Equals(t, "provider", body)
+	status, _, _ = get(t, moduleURL)
+	Equals(t, http.StatusBadGateway, status)
+
+	// Evicted files are downloaded again.
+	registry.down.Store(false)
+	status, _, body = get(t, moduleURL)
+	Equals(t, http.StatusOK, status)
+	Equals(t, "module", body)
+}
+
+func TestProxy_WriteCLIConfig(t *testing.T) {
+	tmp := t.TempDir()
+	base := filepath.Join(tmp, ".terraformrc")
+	Ok(t, os.WriteFile(base, []byte("credentials \"app.terraform.io\" {\n  token = \"token\"\n}\n"), 0600))
+	proxy := registrycache.NewProxy(tmp, "https://atlantis.example.com/", []string{"registry.terraform.io"}, 0, logging.NewNoopLogger(t))
+
+	file := filepath.Join(tmp, "terraformrc")
+	Ok(t, proxy.WriteCLIConfig(file, base))
+	contents, err := os.ReadFile(file)
+	Ok(t, err)
+	Equals(t, strings.ReplaceAll(`credentials "app.terraform.io" {
+  token = "token"
+}
+
+host "registry.terraform.io" {
+  services = {
+    "modules.v1"   = "https://atlantis.example.com/registry-cache/<token>/registry.terraform.io/modules/"
+    "providers.v1" = "https://atlantis.example.com/registry-cache/<token>/registry.terraform.io/providers/"
+  }
+}
+`, "<token>", proxy.Token), string(contents))
+
+	Ok(t, proxy.WriteCLIConfig(file, filepath.Join(tmp, "missing")))
+	contents, err = os.ReadFile(file)
+	Ok(t, err)
+	Equals(t, proxy.CLIConfig(), string(contents))
+}
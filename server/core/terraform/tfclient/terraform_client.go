@@ -52,10 +52,38 @@ type Client interface {
 	// EnsureVersion makes sure that terraform version `v` is available to use
 	EnsureVersion(log logging.SimpleLogging, d terraform.Distribution, v *version.Version) error
 
-	// DetectVersion Extracts required_version from Terraform configuration in the specified project directory. Returns nil if unable to determine the version.
+	// DetectVersion Extracts the version from a version file or required_version from Terraform configuration in the specified project directory. Returns nil if unable to determine the version.
 	DetectVersion(log logging.SimpleLogging, projectDirectory string) *version.Version
 }
 
+// JobRunner runs commands out of the Atlantis process, ex. as Kubernetes Jobs.
+type JobRunner interface {
+	// RunCommandAsync runs command with sh in workingDir with the environment
+	// environ like DefaultClient.RunCommandAsync.
+	RunCommandAsync(ctx command.ProjectContext, command string, environ []string, workingDir string) (chan<- string, <-chan models.Line)
+}
+
+// TerraformCommand is a terraform command run by a remote agent.
+type TerraformCommand struct {
+	// Distribution is the binary of the distribution, terraform or tofu.
+	Distribution string
+	Version      string
+	Args         []string
+	// Env are the environment variables set for the command, other than the
+	// ones Atlantis always sets for terraform.
+	Env       map[string]string
+	Workspace string
+	// Path is the dir the command is run in.
+	Path string
+}
+
+// AgentRunner runs the terraform commands of the projects of an agent pool on
+// remote agents.
+type AgentRunner interface {
+	// RunCommandAsync runs cmd like DefaultClient.RunCommandAsync.
+	RunCommandAsync(ctx command.ProjectContext, cmd TerraformCommand) (chan<- string, <-chan models.Line)
+}
+
 type DefaultClient struct {
 	// Distribution handles logic specific to the TF distribution being used by Atlantis
 	distribution terraform.Distribution
@@ -85,6 +113,15 @@ type DefaultClient struct {
 	usePluginCache bool
 
 	projectCmdOutputHandler jobs.ProjectCommandOutputHandler
+
+	// jobRunner runs the plans and applies if set, instead of the Atlantis
+	// process.
+	jobRunner JobRunner
+	// agentRunner runs the commands of the projects of agent pools if set.
+	agentRunner AgentRunner
+	// cliConfigFile is the CLI configuration file terraform is pointed at
+	// with TF_CLI_CONFIG_FILE if set.
+	cliConfigFile string
 }
 
 // versionRegex extracts the version from `terraform version` output.
@@ -256,6 +293,22 @@ func NewClient(
 	)
 }
 
+// UseJobRunner runs the plans and applies with r.
+func (c *DefaultClient) UseJobRunner(r JobRunner) {
+	c.jobRunner = r
+}
+
+// UseAgentRunner runs the commands of the projects of agent pools with r.
+func (c *DefaultClient) UseAgentRunner(r AgentRunner) {
+	c.agentRunner = r
+}
+
+// UseCLIConfig points terraform at the CLI configuration file, ex. the one
+// fetching providers and modules through the registry cache.
+func (c *DefaultClient) UseCLIConfig(file string) {
+	c.cliConfigFile = file
+}
+
 func (c *DefaultClient) DefaultDistribution() terraform.Distribution {
 	return c.distribution
 }
@@ -291,42 +344,86 @@ func (c *DefaultClient) ExtractExactRegex(log logging.SimpleLogging, version str
 // DetectVersion extracts required_version from Terraform configuration in the specified project directory. Returns nil if unable to determine the version.
 // It will also try to evaluate non-exact matches by passing the Constraints to the hc-install Releases API, which will return a list of available versions.
 // It will then select the highest version that satisfies the constraint.
+// If the configuration sets required_version more than once, ex. in the root module's
+// versions.tf and main.tf, the version must satisfy all of them.
+// A .terraform-version, .opentofu-version or .tool-versions file takes precedence over
+// required_version, so projects use the same version developers run locally.
 func (c *DefaultClient) DetectVersion(log logging.SimpleLogging, projectDirectory string) *version.Version {
+	if v := detectVersionFile(log, projectDirectory, c.distribution.BinName()); v != nil {
+		return v
+	}
+
 	module, diags := tfconfig.LoadModule(projectDirectory)
 	if diags.HasErrors() {
 		log.Err("trying to detect required version: %s", diags.Error())
 	}
 
-	if len(module.RequiredCore) != 1 {
-		log.Info("cannot determine which version to use from terraform configuration, detected %d possibilities.", len(module.RequiredCore))
+	if len(module.RequiredCore) == 0 {
+		log.Info("cannot determine which version to use from terraform configuration, no required_version found.")
 		return nil
 	}
-	requiredVersionSetting := module.RequiredCore[0]
+	requiredVersionSetting := strings.Join(module.RequiredCore, ", ")
 	log.Debug("Found required_version setting of %q", requiredVersionSetting)
 
 	if !c.downloadAllowed {
 		log.Debug("terraform downloads disabled.")
-		matched := c.ExtractExactRegex(log, requiredVersionSetting)
+		return c.exactVersion(log, module.RequiredCore)
+	}
+
+	downloadVersion, err := c.distribution.ResolveConstraint(context.Background(), requiredVersionSetting)
+	if err != nil {
+		log.Err("%s", err)
+		return nil
+	}
+
+	return downloadVersion
+}
+
+// PinnedVersion returns the exact version the project directory pins, with a
+// version file or an exact required_version, for the distribution with binName,
+// without resolving constraints. Returns nil if it doesn't pin a version.
+func PinnedVersion(log logging.SimpleLogging, projectDirectory string, binName string) *version.Version {
+	if v := detectVersionFile(log, projectDirectory, binName); v != nil {
+		return v
+	}
+	module, diags := tfconfig.LoadModule(projectDirectory)
+	if diags.HasErrors() || len(module.RequiredCore) == 0 {
+		return nil
+	}
+	return (&DefaultClient{}).exactVersion(log, module.RequiredCore)
+}
+
+// exactVersion returns the exact version set by one of requiredCore if it
+// satisfies all of them, or nil if there's none.
+func (c *DefaultClient) exactVersion(log logging.SimpleLogging, requiredCore []string) *version.Version {
+	var exact *version.Version
+	for _, setting := range requiredCore {
+		matched := c.ExtractExactRegex(log, setting)
 		if len(matched) == 0 {
-			log.Debug("did not specify exact version in terraform configuration, found %q", requiredVersionSetting)
-			return nil
+			continue
 		}
-
-		version, err := version.NewVersion(matched[0])
+		v, err := version.NewVersion(matched[0])
 		if err != nil {
 			log.Err("error parsing version string: %s", err)
 			return nil
 		}
-		return version
+		exact = v
+		break
 	}
-
-	downloadVersion, err := c.distribution.ResolveConstraint(context.Background(), requiredVersionSetting)
+	if exact == nil {
+		log.Debug("did not specify exact version in terraform configuration, found %q", strings.Join(requiredCore, ", "))
+		return nil
+	}
+	constraints, err := version.NewConstraint(strings.Join(requiredCore, ", "))
 	if err != nil {
-		log.Err("%s", err)
+		log.Err("error parsing constraint string: %s", err)
 		return nil
 	}
-
-	return downloadVersion
+	if !constraints.Check(exact) {
+		log.Info("exact version %s doesn't satisfy all of the required_version settings %q", exact, strings.Join(requiredCore, ", "))
+		return nil
+	}
+	return exact
 }
 
 // See Client.EnsureVersion.
@@ -348,7 +445,8 @@ func (c *DefaultClient) EnsureVersion(log logging.SimpleLogging, d terraform.Dis
 
 // See Client.RunCommandWithVersion.
 func (c *DefaultClient) RunCommandWithVersion(ctx command.ProjectContext, path string, args []string, customEnvVars map[string]string, d terraform.Distribution, v *version.Version, workspace string) (string, error) {
-	if isAsyncEligibleCommand(args[0]) {
+	// The commands of agents are all run asynchronously.
+	if isAsyncEligibleCommand(args[0]) || c.runsOnAgent(ctx) {
 		_, outCh := c.RunCommandAsync(ctx, path, args, customEnvVars, d, v, workspace)
 
 		var lines []string
@@ -389,6 +487,22 @@ func (c *DefaultClient) RunCommandWithVersion(ctx command.ProjectContext, path s
 	return ansi.Strip(string(out)), nil
 }
 
+// RunInProcess runs terraform with args in path with the default distribution,
+// always in the Atlantis process, even if commands run on agents or in jobs.
+// It's used outside of the commands of projects, ex. to warm working dirs.
+func (c *DefaultClient) RunInProcess(log logging.SimpleLogging, path string, args []string, v *version.Version) (string, error) {
+	tfCmd, cmd, err := c.prepExecCmd(log, c.distribution, v, "default", path, args)
+	if err != nil {
+		return "", err
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return ansi.Strip(string(out)), fmt.Errorf("running '%s' in '%s': %w", tfCmd, path, err)
+	}
+	log.Info("Successfully ran '%s' in '%s'", tfCmd, path)
+	return ansi.Strip(string(out)), nil
+}
+
 // prepExecCmd builds a ready to execute command based on the version of terraform
 // v, and args. It returns a printable representation of the command that will
 // be run and the actual command.
@@ -406,6 +520,19 @@ func (c *DefaultClient) prepExecCmd(log logging.SimpleLogging, d terraform.Distr
 // prepCmd prepares a shell command (to be interpreted with `sh -c <cmd>`) and set of environment
 // variables for running terraform.
 func (c *DefaultClient) prepCmd(log logging.SimpleLogging, d terraform.Distribution, v *version.Version, workspace string, path string, args []string) (string, []string, error) {
+	tfCmd, envVars, err := c.prepTerraformCmd(log, d, v, workspace, path, args)
+	if err != nil {
+		return "", nil, err
+	}
+	// Append current Atlantis process's environment variables, ex.
+	// AWS_ACCESS_KEY.
+	envVars = append(envVars, os.Environ()...)
+	return tfCmd, envVars, nil
+}
+
+// prepTerraformCmd prepares the shell command like prepCmd, with only the
+// environment variables set for terraform.
+func (c *DefaultClient) prepTerraformCmd(log logging.SimpleLogging, d terraform.Distribution, v *version.Version, workspace string, path string, args []string) (string, []string, error) {
 
 	if v == nil {
 		v = c.defaultVersion
@@ -438,9 +565,9 @@ func (c *DefaultClient) prepCmd(log logging.SimpleLogging, d terraform.Distribut
 	if c.usePluginCache {
 		envVars = append(envVars, fmt.Sprintf("TF_PLUGIN_CACHE_DIR=%s", c.terraformPluginCacheDir))
 	}
-	// Append current Atlantis process's environment variables, ex.
-	// AWS_ACCESS_KEY.
-	envVars = append(envVars, os.Environ()...)
+	if c.cliConfigFile != "" {
+		envVars = append(envVars, fmt.Sprintf("TF_CLI_CONFIG_FILE=%s", c.cliConfigFile))
+	}
 	tfCmd := fmt.Sprintf("%s %s", binPath, strings.Join(args, " "))
 	return tfCmd, envVars, nil
 }
@@ -452,7 +579,31 @@ func (c *DefaultClient) prepCmd(log logging.SimpleLogging, d terraform.Distribut
 // If any error is passed on the out channel, there will be no
 // further output (so callers are free to exit).
 func (c *DefaultClient) RunCommandAsync(ctx command.ProjectContext, path string, args []string, customEnvVars map[string]string, d terraform.Distribution, v *version.Version, workspace string) (chan<- string, <-chan models.Line) {
-	cmd, envVars, err := c.prepCmd(ctx.Log, d, v, workspace, path, args)
+	if c.runsOnAgent(ctx) {
+		if v == nil {
+			v = c.defaultVersion
+		}
+		if d == nil {
+			d = c.distribution
+		}
+		return c.agentRunner.RunCommandAsync(ctx, TerraformCommand{
+			Distribution: d.BinName(),
+			Version:      v.String(),
+			Args:         args,
+			Env:          customEnvVars,
+			Workspace:    workspace,
+			Path:         path,
+		})
+	}
+	runInJob := c.jobRunner != nil && isJobEligibleCommand(args[0])
+	prep := c.prepCmd
+	if runInJob {
+		// The environment of the Atlantis process, ex. its tokens, isn't
+		// passed on to jobs that get their credentials from their service
+		// account.
+		prep = c.prepTerraformCmd
+	}
+	cmd, envVars, err := prep(ctx.Log, d, v, workspace, path, args)
 	if err != nil {
 		// The signature of `RunCommandAsync` doesn't provide for returning an immediate error, only one
 		// once reading the output. Since we won't be spawning a process, simulate that by sending the
@@ -471,6 +622,9 @@ func (c *DefaultClient) RunCommandAsync(ctx command.ProjectContext, path string,
 		envVars = append(envVars, fmt.Sprintf("%s=%s", key, val))
 	}
 
+	if runInJob {
+		return c.jobRunner.RunCommandAsync(ctx, cmd, envVars, path)
+	}
 	runner := models.NewShellCommandRunner(nil, cmd, envVars, path, true, c.projectCmdOutputHandler)
 	inCh, outCh := runner.RunCommandAsync(ctx)
 	return inCh, outCh
@@ -572,6 +726,17 @@ func generateRCFile(tfeToken string, tfeHostname string, home string) error {
 	return nil
 }
 
+// runsOnAgent returns whether the commands of the project are run by remote
+// agents.
+func (c *DefaultClient) runsOnAgent(ctx command.ProjectContext) bool {
+	return c.agentRunner != nil && ctx.AgentPool != ""
+}
+
+// isJobEligibleCommand returns whether cmd is run by the job runner.
+func isJobEligibleCommand(cmd string) bool {
+	return cmd == "plan" || cmd == "apply"
+}
+
 func isAsyncEligibleCommand(cmd string) bool {
 	for _, validCmd := range LogStreamingValidCmds {
 		if validCmd == cmd {
@@ -133,6 +133,29 @@ func TestDefaultClient_RunCommandWithVersion_EnvVars(t *testing.T) {
 	Equals(t, exp, out)
 }
 
+// Test that terraform is pointed at the CLI configuration file if set.
+func TestDefaultClient_RunCommandWithVersion_CLIConfig(t *testing.T) {
+	v, err := version.NewVersion("0.11.11")
+	Ok(t, err)
+	tmp := t.TempDir()
+	ctx := command.ProjectContext{
+		Log:        logging.NewNoopLogger(t),
+		Workspace:  "default",
+		RepoRelDir: ".",
+	}
+	client := &DefaultClient{
+		defaultVersion:          v,
+		overrideTF:              "echo",
+		projectCmdOutputHandler: jobmocks.NewMockProjectCommandOutputHandler(),
+	}
+	client.UseCLIConfig("/data/registry-cache/terraformrc")
+
+	distribution := terraform.NewDistributionTerraformWithDownloader(terraform_mocks.NewMockDownloader())
+	out, err := client.RunCommandWithVersion(ctx, tmp, []string{"TF_CLI_CONFIG_FILE=$TF_CLI_CONFIG_FILE"}, map[string]string{}, distribution, nil, "workspace")
+	Ok(t, err)
+	Equals(t, "TF_CLI_CONFIG_FILE=/data/registry-cache/terraformrc\n", out)
+}
+
 // Test that it returns an error on error.
 func TestDefaultClient_RunCommandWithVersion_Error(t *testing.T) {
 	v, err := version.NewVersion("0.11.11")
@@ -412,6 +435,122 @@ func TestDefaultClient_RunCommandAsync_Input(t *testing.T) {
 	logger.VerifyWasCalledOnce().With(Eq("duration"), Any[any]())
 }
 
+// fakeJobRunner echoes the commands it runs.
+type fakeJobRunner struct {
+	environ []string
+	dir     string
+}
+
+func (f *fakeJobRunner) RunCommandAsync(_ command.ProjectContext, command string, environ []string, workingDir string) (chan<- string, <-chan runtimemodels.Line) {
+	f.environ = environ
+	f.dir = workingDir
+	outCh := make(chan runtimemodels.Line, 1)
+	outCh <- runtimemodels.Line{Line: "job: " + command}
+	close(outCh)
+	return make(chan string), outCh
+}
+
+func TestDefaultClient_RunCommandAsync_JobRunner(t *testing.T) {
+	RegisterMockTestingT(t)
+	v, err := version.NewVersion("0.11.11")
+	Ok(t, err)
+	tmp := t.TempDir()
+	logger := logmocks.NewMockSimpleLogging()
+	When(logger.With(Any[string](), Any[any]())).ThenReturn(logger)
+	ctx := command.ProjectContext{
+		Log:       logger,
+		Workspace: "default",
+		Pull:      models.PullRequest{Num: 2},
+	}
+	jobRunner := &fakeJobRunner{}
+	client := &DefaultClient{
+		defaultVersion:          v,
+		terraformPluginCacheDir: tmp,
+		overrideTF:              "echo",
+		projectCmdOutputHandler: jobmocks.NewMockProjectCommandOutputHandler(),
+	}
+	client.UseJobRunner(jobRunner)
+	mockDownloader := terraform_mocks.NewMockDownloader()
+	distribution := terraform.NewDistributionTerraformWithDownloader(mockDownloader)
+
+	_, outCh := client.RunCommandAsync(ctx, tmp, []string{"plan", "-input=false"}, map[string]string{"CUSTOM": "value"}, distribution, nil, "workspace")
+	out, err := waitCh(outCh)
+	Ok(t, err)
+	Equals(t, "job: echo plan -input=false", out)
+	Equals(t, tmp, jobRunner.dir)
+	Equals(t, []string{
+		"TF_IN_AUTOMATION=true",
+		"WORKSPACE=workspace",
+		"ATLANTIS_TERRAFORM_VERSION=0.11.11",
+		"DIR=" + tmp,
+		"CUSTOM=value",
+	}, jobRunner.environ)
+
+	t.Log("other commands run in the Atlantis process")
+	_, outCh = client.RunCommandAsync(ctx, tmp, []string{"init"}, map[string]string{}, distribution, nil, "workspace")
+	out, err = waitCh(outCh)
+	Ok(t, err)
+	Equals(t, "init", out)
+}
+
+// fakeAgentRunner echoes the commands it runs.
+type fakeAgentRunner struct {
+	cmd TerraformCommand
+}
+
+func (f *fakeAgentRunner) RunCommandAsync(_ command.ProjectContext, cmd TerraformCommand) (chan<- string, <-chan runtimemodels.Line) {
+	f.cmd = cmd
+	outCh := make(chan runtimemodels.Line, 1)
+	outCh <- runtimemodels.Line{Line: "agent: " + strings.Join(cmd.Args, " ")}
+	close(outCh)
+	return make(chan string), outCh
+}
+
+func TestDefaultClient_RunCommandAsync_AgentRunner(t *testing.T) {
+	RegisterMockTestingT(t)
+	v, err := version.NewVersion("1.9.0")
+	Ok(t, err)
+	tmp := t.TempDir()
+	logger := logmocks.NewMockSimpleLogging()
+	When(logger.With(Any[string](), Any[any]())).ThenReturn(logger)
+	ctx := command.ProjectContext{
+		Log:       logger,
+		Workspace: "default",
+		Pull:      models.PullRequest{Num: 2},
+		AgentPool: "aws-prod",
+	}
+	agentRunner := &fakeAgentRunner{}
+	client := &DefaultClient{
+		defaultVersion:          v,
+		terraformPluginCacheDir: tmp,
+		overrideTF:              "echo",
+		projectCmdOutputHandler: jobmocks.NewMockProjectCommandOutputHandler(),
+	}
+	client.UseAgentRunner(agentRunner)
+	mockDownloader := terraform_mocks.NewMockDownloader()
+	distribution := terraform.NewDistributionTerraformWithDownloader(mockDownloader)
+
+	_, outCh := client.RunCommandAsync(ctx, tmp, []string{"init", "-upgrade"}, map[string]string{"CUSTOM": "value"}, distribution, nil, "workspace")
+	out, err := waitCh(outCh)
+	Ok(t, err)
+	Equals(t, "agent: init -upgrade", out)
+	Equals(t, TerraformCommand{
+		Distribution: "terraform",
+		Version:      "1.9.0",
+		Args:         []string{"init", "-upgrade"},
+		Env:          map[string]string{"CUSTOM": "value"},
+		Workspace:    "workspace",
+		Path:         tmp,
+	}, agentRunner.cmd)
+
+	t.Log("projects without an agent pool run in the Atlantis process")
+	ctx.AgentPool = ""
+	_, outCh = client.RunCommandAsync(ctx, tmp, []string{"init"}, map[string]string{}, distribution, nil, "workspace")
+	out, err = waitCh(outCh)
+	Ok(t, err)
+	Equals(t, "init", out)
+}
+
 func waitCh(ch <-chan runtimemodels.Line) (string, error) {
 	var ls []string
 	for line := range ch {
@@ -422,3 +561,33 @@ func waitCh(ch <-chan runtimemodels.Line) (string, error) {
 	}
 	return strings.Join(ls, "\n"), nil
 }
+
+func TestDetectVersionFile(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	repo := t.TempDir()
+	Ok(t, os.Mkdir(filepath.Join(repo, ".git"), 0700))
+	project := filepath.Join(repo, "envs", "staging")
+	Ok(t, os.MkdirAll(project, 0700))
+
+	t.Log("no version file")
+	Assert(t, detectVersionFile(logger, project, "terraform") == nil, "exp no version")
+
+	t.Log("version files outside of the repo are ignored")
+	Ok(t, os.WriteFile(filepath.Join(filepath.Dir(repo), ".terraform-version"), []byte("1.0.0\n"), 0600))
+	defer os.Remove(filepath.Join(filepath.Dir(repo), ".terraform-version")) // nolint: errcheck
+	Assert(t, detectVersionFile(logger, project, "terraform") == nil, "exp no version")
+
+	t.Log(".tool-versions in the repo root")
+	Ok(t, os.WriteFile(filepath.Join(repo, ".tool-versions"), []byte("# tools\nnodejs 20.0.0\nterraform 1.5.7 1.4.0 # pinned\nopentofu 1.6.2\n"), 0600))
+	Equals(t, "1.5.7", detectVersionFile(logger, project, "terraform").String())
+	Equals(t, "1.6.2", detectVersionFile(logger, project, "tofu").String())
+
+	t.Log("the closest version file wins")
+	Ok(t, os.WriteFile(filepath.Join(project, ".terraform-version"), []byte("v1.6.0\n"), 0600))
+	Equals(t, "1.6.0", detectVersionFile(logger, project, "terraform").String())
+	Equals(t, "1.6.2", detectVersionFile(logger, project, "tofu").String())
+
+	t.Log("versions that aren't exact are ignored")
+	Ok(t, os.WriteFile(filepath.Join(project, ".terraform-version"), []byte("latest:^1.5\n"), 0600))
+	Assert(t, detectVersionFile(logger, project, "terraform") == nil, "exp no version")
+}
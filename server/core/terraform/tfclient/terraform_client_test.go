@@ -491,6 +491,24 @@ terraform {
 		IsExact: true,
 	}
 
+	testCases["multiple required_version settings"] = testCase{
+		DirStructure: map[string]any{
+			"project1": map[string]any{
+				"main.tf":     fmt.Sprintf(baseVersionConfig, ">= 0.12.0"),
+				"versions.tf": fmt.Sprintf(baseVersionConfig, "= 0.12.8"),
+			},
+			"project2": map[string]any{
+				"main.tf":     fmt.Sprintf(baseVersionConfig, "< 0.12.0"),
+				"versions.tf": fmt.Sprintf(baseVersionConfig, "= 0.12.8"),
+			},
+		},
+		Exp: map[string]string{
+			"project1": "0.12.8",
+			"project2": "",
+		},
+		IsExact: true,
+	}
+
 	runDetectVersionTestCase := func(t *testing.T, name string, testCase testCase, downloadsAllowed bool) bool {
 		return t.Run(name, func(t *testing.T) {
 			RegisterMockTestingT(t)
@@ -0,0 +1,72 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tfclient
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// toolVersionsFile is asdf's version file, it lists a version per tool.
+const toolVersionsFile = ".tool-versions"
+
+// detectVersionFile returns the version set by the tfenv or tofuenv style
+// version file, or asdf's .tool-versions, closest to projectDirectory for
+// the distribution with binName. Like tfenv, parent directories are searched
+// but the search stops at the root of the repo. It returns nil if there's no
+// version file or it doesn't set an exact version.
+func detectVersionFile(log logging.SimpleLogging, projectDirectory string, binName string) *version.Version {
+	versionFile, tool := ".terraform-version", "terraform"
+	if binName == "tofu" {
+		versionFile, tool = ".opentofu-version", "opentofu"
+	}
+
+	for dir := filepath.Clean(projectDirectory); ; dir = filepath.Dir(dir) {
+		for _, name := range []string{versionFile, toolVersionsFile} {
+			contents, err := os.ReadFile(filepath.Join(dir, name)) // nolint: gosec
+			if err != nil {
+				continue
+			}
+			var setting string
+			if name == toolVersionsFile {
+				setting = toolVersion(string(contents), tool)
+				if setting == "" {
+					continue
+				}
+			} else {
+				setting = strings.TrimSpace(string(contents))
+			}
+			v, err := version.NewVersion(setting)
+			if err != nil {
+				log.Info("ignoring %s, %q isn't an exact version: %s", filepath.Join(dir, name), setting, err)
+				return nil
+			}
+			log.Debug("found version %s in %s", v, filepath.Join(dir, name))
+			return v
+		}
+		// The repo's root has the .git directory.
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil || filepath.Dir(dir) == dir {
+			return nil
+		}
+	}
+}
+
+// toolVersion returns the preferred, first, version of tool in the contents
+// of a .tool-versions file, or an empty string if it's not listed.
+func toolVersion(contents string, tool string) string {
+	scanner := bufio.NewScanner(strings.NewReader(contents))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == tool {
+			return fields[1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,400 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package tfcloud is a client of the Terraform Cloud and Terraform Enterprise
+// API, used to plan and apply projects as runs of their workspaces.
+package tfcloud
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	contentType = "application/vnd.api+json"
+	// maxLogSize is the size of the largest plan or apply log that's read.
+	maxLogSize = 10 << 20
+	// requestTimeout is how long a request to the API can take.
+	requestTimeout = 30 * time.Second
+	// configurationTimeout is how long an uploaded configuration can take to
+	// be processed.
+	configurationTimeout = 5 * time.Minute
+)
+
+// The statuses of runs, see
+// https://developer.hashicorp.com/terraform/cloud-docs/api-docs/run#run-states.
+const (
+	RunPlannedAndFinished = "planned_and_finished"
+	RunPlannedAndSaved    = "planned_and_saved"
+	RunPolicyOverride     = "policy_override"
+	RunPolicySoftFailed   = "policy_soft_failed"
+	RunApplied            = "applied"
+	RunDiscarded          = "discarded"
+	RunErrored            = "errored"
+	RunCanceled           = "canceled"
+	RunForceCanceled      = "force_canceled"
+)
+
+// Client calls the API of a Terraform Cloud or Enterprise host.
+type Client struct {
+	// URL is the URL of the host, ex. https://app.terraform.io.
+	URL   string
+	Token string
+	// HTTPClient sends the requests. http.DefaultClient is used if it's nil.
+	HTTPClient *http.Client
+}
+
+// NewClient returns a client of the API of hostname, ex. app.terraform.io,
+// authenticating with token.
+func NewClient(hostname string, token string) *Client {
+	return &Client{URL: "https://" + hostname, Token: token}
+}
+
+// Workspace is a Terraform Cloud workspace.
+type Workspace struct {
+	ID           string
+	Organization string
+	Name         string
+	// WorkingDirectory is the dir terraform runs in, relative to the root of
+	// the uploaded configuration. Empty if it's the root.
+	WorkingDirectory string
+}
+
+// Run is a run of a workspace.
+type Run struct {
+	ID     string
+	Status string
+	// HasChanges is true once planned if the plan has changes.
+	HasChanges bool
+	// IsConfirmable is true if the run is waiting to be applied.
+	IsConfirmable bool
+	// IsDiscardable is true if the run can be discarded.
+	IsDiscardable bool
+	PlanID        string
+	ApplyID       string
+}
+
+// Finished returns true if the run won't change status anymore.
+func (r Run) Finished() bool {
+	switch r.Status {
+	case RunPlannedAndFinished, RunPlannedAndSaved, RunApplied, RunDiscarded, RunErrored, RunCanceled, RunForceCanceled:
+		return true
+	}
+	return false
+}
+
+// RunOptions are the options of a new run.
+type RunOptions struct {
+	Message string
+	// IsDestroy is true to destroy the resources of the workspace.
+	IsDestroy bool
+	// TargetAddrs are the resources to limit the run to, like -target.
+	TargetAddrs []string
+	// ReplaceAddrs are the resources to replace, like -replace.
+	ReplaceAddrs []string
+}
+
+// RunURL returns the URL of the run in the UI.
+func (c *Client) RunURL(workspace Workspace, runID string) string {
+	return fmt.Sprintf("%s/app/%s/workspaces/%s/runs/%s", strings.TrimSuffix(c.URL, "/"), url.PathEscape(workspace.Organization), url.PathEscape(workspace.Name), url.PathEscape(runID))
+}
+
+// Workspace returns the workspace named name of organization.
+func (c *Client) Workspace(organization string, name string) (Workspace, error) {
+	var resp struct {
+		Data struct {
+			ID         string `json:"id"`
+			Attributes struct {
+				Name             string `json:"name"`
+				WorkingDirectory string `json:"working-directory"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	path := fmt.Sprintf("/api/v2/organizations/%s/workspaces/%s", url.PathEscape(organization), url.PathEscape(name))
+	if err := c.do(http.MethodGet, path, nil, &resp); err != nil {
+		return Workspace{}, err
+	}
+	return Workspace{
+		ID:               resp.Data.ID,
+		Organization:     organization,
+		Name:             resp.Data.Attributes.Name,
+		WorkingDirectory: resp.Data.Attributes.WorkingDirectory,
+	}, nil
+}
+
+// UploadConfiguration creates a configuration version of workspace with the
+// gzipped tarball archive and waits for it to be processed. It returns the ID
+// of the configuration version.
+func (c *Client) UploadConfiguration(workspace Workspace, archive []byte, pollInterval time.Duration) (string, error) {
+	req := map[string]any{
+		"data": map[string]any{
+			"type": "configuration-versions",
+			"attributes": map[string]any{
+				// Atlantis creates the runs of the configuration.
+				"auto-queue-runs": false,
+			},
+		},
+	}
+	var resp struct {
+		Data struct {
+			ID         string `json:"id"`
+			Attributes struct {
+				UploadURL string `json:"upload-url"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := c.do(http.MethodPost, fmt.Sprintf("/api/v2/workspaces/%s/configuration-versions", url.PathEscape(workspace.ID)), req, &resp); err != nil {
+		return "", err
+	}
+	id := resp.Data.ID
+
+	// The upload URL is signed so it's sent without the token.
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	upload, err := http.NewRequestWithContext(ctx, http.MethodPut, resp.Data.Attributes.UploadURL, bytes.NewReader(archive))
+	if err != nil {
+		return "", err
+	}
+	upload.Header.Set("Content-Type", "application/octet-stream")
+	uploadResp, err := c.httpClient().Do(upload)
+	if err != nil {
+		return "", fmt.Errorf("uploading configuration version %s: %w", id, err)
+	}
+	uploadResp.Body.Close() // nolint: errcheck
+	if uploadResp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("uploading configuration version %s returned %d", id, uploadResp.StatusCode)
+	}
+
+	deadline := time.Now().Add(configurationTimeout)
+	for {
+		var status struct {
+			Data struct {
+				Attributes struct {
+					Status       string `json:"status"`
+					ErrorMessage string `json:"error-message"`
+				} `json:"attributes"`
+			} `json:"data"`
+		}
+		if err := c.do(http.MethodGet, "/api/v2/configuration-versions/"+url.PathEscape(id), nil, &status); err != nil {
+			return "", err
+		}
+		switch status.Data.Attributes.Status {
+		case "uploaded":
+			return id, nil
+		case "errored":
+			return "", fmt.Errorf("configuration version %s errored: %s", id, status.Data.Attributes.ErrorMessage)
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("configuration version %s wasn't processed after %s", id, configurationTimeout)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// CreateRun creates a run of workspace planning the configuration version
+// configurationVersionID. It's never applied automatically.
+func (c *Client) CreateRun(workspace Workspace, configurationVersionID string, opts RunOptions) (Run, error) {
+	attributes := map[string]any{
+		"message":    opts.Message,
+		"auto-apply": false,
+		"is-destroy": opts.IsDestroy,
+	}
+	if len(opts.TargetAddrs) > 0 {
+		attributes["target-addrs"] = opts.TargetAddrs
+	}
+	if len(opts.ReplaceAddrs) > 0 {
+		attributes["replace-addrs"] = opts.ReplaceAddrs
+	}
+	req := map[string]any{
+		"data": map[string]any{
+			"type":       "runs",
+			"attributes": attributes,
+			"relationships": map[string]any{
+				"workspace": map[string]any{
+					"data": map[string]string{"type": "workspaces", "id": workspace.ID},
+				},
+				"configuration-version": map[string]any{
+					"data": map[string]string{"type": "configuration-versions", "id": configurationVersionID},
+				},
+			},
+		},
+	}
+	var resp runResponse
+	if err := c.do(http.MethodPost, "/api/v2/runs", req, &resp); err != nil {
+		return Run{}, err
+	}
+	return resp.run(), nil
+}
+
+// Run returns the run with ID id.
+func (c *Client) Run(id string) (Run, error) {
+	var resp runResponse
+	if err := c.do(http.MethodGet, "/api/v2/runs/"+url.PathEscape(id), nil, &resp); err != nil {
+		return Run{}, err
+	}
+	return resp.run(), nil
+}
+
+// ApplyRun confirms the run with ID id so it's applied.
+func (c *Client) ApplyRun(id string, comment string) error {
+	return c.do(http.MethodPost, fmt.Sprintf("/api/v2/runs/%s/actions/apply", url.PathEscape(id)), map[string]string{"comment": comment}, nil)
+}
+
+// DiscardRun discards the run with ID id so it's not applied and the
+// workspace can run again.
+func (c *Client) DiscardRun(id string, comment string) error {
+	return c.do(http.MethodPost, fmt.Sprintf("/api/v2/runs/%s/actions/discard", url.PathEscape(id)), map[string]string{"comment": comment}, nil)
+}
+
+// PlanLog returns the log of the plan with ID id.
+func (c *Client) PlanLog(id string) (string, error) {
+	return c.log("/api/v2/plans/" + url.PathEscape(id))
+}
+
+// ApplyLog returns the log of the apply with ID id.
+func (c *Client) ApplyLog(id string) (string, error) {
+	return c.log("/api/v2/applies/" + url.PathEscape(id))
+}
+
+// log returns the log of the plan or apply at path.
+func (c *Client) log(path string) (string, error) {
+	var resp struct {
+		Data struct {
+			Attributes struct {
+				LogReadURL string `json:"log-read-url"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := c.do(http.MethodGet, path, nil, &resp); err != nil {
+		return "", err
+	}
+	// The log URL is signed so it's fetched without the token.
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, resp.Data.Attributes.LogReadURL, nil)
+	if err != nil {
+		return "", err
+	}
+	logResp, err := c.httpClient().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer logResp.Body.Close() // nolint: errcheck
+	if logResp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("reading the log of %s returned %d", path, logResp.StatusCode)
+	}
+	content, err := io.ReadAll(io.LimitReader(logResp.Body, maxLogSize))
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+type runResponse struct {
+	Data struct {
+		ID         string `json:"id"`
+		Attributes struct {
+			Status     string `json:"status"`
+			HasChanges bool   `json:"has-changes"`
+			Actions    struct {
+				IsConfirmable bool `json:"is-confirmable"`
+				IsDiscardable bool `json:"is-discardable"`
+			} `json:"actions"`
+		} `json:"attributes"`
+		Relationships struct {
+			Plan  relationship `json:"plan"`
+			Apply relationship `json:"apply"`
+		} `json:"relationships"`
+	} `json:"data"`
+}
+
+type relationship struct {
+	Data struct {
+		ID string `json:"id"`
+	} `json:"data"`
+}
+
+func (r runResponse) run() Run {
+	return Run{
+		ID:            r.Data.ID,
+		Status:        r.Data.Attributes.Status,
+		HasChanges:    r.Data.Attributes.HasChanges,
+		IsConfirmable: r.Data.Attributes.Actions.IsConfirmable,
+		IsDiscardable: r.Data.Attributes.Actions.IsDiscardable,
+		PlanID:        r.Data.Relationships.Plan.Data.ID,
+		ApplyID:       r.Data.Relationships.Apply.Data.ID,
+	}
+}
+
+// do sends a request to the API at path with body encoded as JSON, and
+// decodes the response into resp unless it's nil.
+func (c *Client) do(method string, path string, body any, resp any) error {
+	var reqBody io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(encoded)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.URL, "/")+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", contentType)
+	httpResp, err := c.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close() // nolint: errcheck
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned %d: %s", method, path, httpResp.StatusCode, apiErrors(httpResp.Body))
+	}
+	if resp == nil {
+		return nil
+	}
+	if err := json.NewDecoder(httpResp.Body).Decode(resp); err != nil {
+		return fmt.Errorf("decoding the response of %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+// apiErrors returns the details of the errors of a failed response.
+func apiErrors(body io.Reader) string {
+	var resp struct {
+		Errors []struct {
+			Title  string `json:"title"`
+			Detail string `json:"detail"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(io.LimitReader(body, 1<<20)).Decode(&resp); err != nil || len(resp.Errors) == 0 {
+		return "no details"
+	}
+	var details []string
+	for _, e := range resp.Errors {
+		if e.Detail != "" {
+			details = append(details, e.Detail)
+		} else {
+			details = append(details, e.Title)
+		}
+	}
+	return strings.Join(details, ", ")
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
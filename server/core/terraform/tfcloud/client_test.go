@@ -0,0 +1,49 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tfcloud_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/terraform/tfcloud"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestClient_Workspace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Equals(t, "Bearer token", r.Header.Get("Authorization"))
+		Equals(t, "application/vnd.api+json", r.Header.Get("Accept"))
+		switch r.URL.Path {
+		case "/api/v2/organizations/my-org/workspaces/prod":
+			w.Write([]byte(`{"data":{"id":"ws-1","attributes":{"name":"prod","working-directory":"envs/prod"}}}`)) // nolint: errcheck
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[{"status":"404","title":"not found"},{"detail":"workspace missing"}]}`)) // nolint: errcheck
+		}
+	}))
+	defer server.Close()
+	client := &tfcloud.Client{URL: server.URL, Token: "token"}
+
+	workspace, err := client.Workspace("my-org", "prod")
+	Ok(t, err)
+	Equals(t, tfcloud.Workspace{ID: "ws-1", Organization: "my-org", Name: "prod", WorkingDirectory: "envs/prod"}, workspace)
+
+	_, err = client.Workspace("my-org", "staging")
+	ErrEquals(t, "GET /api/v2/organizations/my-org/workspaces/staging returned 404: not found, workspace missing", err)
+}
+
+func TestClient_RunURL(t *testing.T) {
+	client := tfcloud.NewClient("tfe.example.com", "token")
+	Equals(t, "https://tfe.example.com/app/my-org/workspaces/prod/runs/run-1", client.RunURL(tfcloud.Workspace{Organization: "my-org", Name: "prod"}, "run-1"))
+}
+
+func TestRun_Finished(t *testing.T) {
+	Equals(t, false, tfcloud.Run{Status: "planning"}.Finished())
+	Equals(t, false, tfcloud.Run{Status: "planned", IsConfirmable: true}.Finished())
+	Equals(t, true, tfcloud.Run{Status: tfcloud.RunPlannedAndFinished}.Finished())
+	Equals(t, true, tfcloud.Run{Status: tfcloud.RunApplied}.Finished())
+	Equals(t, true, tfcloud.Run{Status: tfcloud.RunErrored}.Finished())
+}
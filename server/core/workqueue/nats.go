@@ -0,0 +1,166 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package workqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/runatlantis/atlantis/server/events"
+)
+
+const (
+	// natsStream is the JetStream stream the items are kept in.
+	natsStream = "ATLANTIS_WORK"
+	// natsSubject is the subject the items are published to.
+	natsSubject = "atlantis.work"
+	// natsConsumer is the durable consumer the workers share.
+	natsConsumer = "atlantis-workers"
+	// natsWait is how long fetching a message waits for one before ctx is
+	// checked again.
+	natsWait = 5 * time.Second
+	// natsAckWait is how long a message delivered to a worker that stopped
+	// keeping it alive, ex. because it crashed, is left before it's delivered
+	// again.
+	natsAckWait = time.Minute
+)
+
+// NATS is a work queue on a NATS JetStream stream with work queue retention.
+// The workers share a durable consumer and keep the messages of the items
+// they run from being delivered again by reporting they're in progress.
+type NATS struct {
+	js      jetstream.JetStream
+	ackWait time.Duration
+
+	mutex    sync.Mutex
+	consumer jetstream.Consumer
+}
+
+// NewNATS connects to the NATS servers at url, ex. nats://nats:4222, and
+// creates the stream if it doesn't exist.
+func NewNATS(ctx context.Context, url string) (*NATS, error) {
+	conn, err := nats.Connect(url, nats.Name("atlantis"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("connecting to NATS at %s: %w", url, err)
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if _, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:      natsStream,
+		Subjects:  []string{natsSubject},
+		Retention: jetstream.WorkQueuePolicy,
+		Storage:   jetstream.FileStorage,
+	}); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("creating the %s stream: %w", natsStream, err)
+	}
+	return &NATS{js: js, ackWait: natsAckWait}, nil
+}
+
+// Enqueue publishes item, without the credentials of its clone URLs, to the
+// stream.
+func (q *NATS) Enqueue(item events.WorkItem) error {
+	serialized, err := json.Marshal(item.WithoutCloneCredentials())
+	if err != nil {
+		return fmt.Errorf("serializing work item: %w", err)
+	}
+	_, err = q.js.Publish(context.Background(), natsSubject, serialized)
+	return err
+}
+
+// Dequeue fetches the next message of the stream.
+func (q *NATS) Dequeue(ctx context.Context) (events.WorkItem, events.WorkDelivery, error) {
+	consumer, err := q.workers(ctx)
+	if err != nil {
+		return events.WorkItem{}, nil, err
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return events.WorkItem{}, nil, err
+		}
+		message, err := consumer.Next(jetstream.FetchMaxWait(natsWait))
+		if errors.Is(err, nats.ErrTimeout) {
+			continue
+		}
+		if err != nil {
+			return events.WorkItem{}, nil, fmt.Errorf("fetching from the %s stream: %w", natsStream, err)
+		}
+		delivery := q.deliver(message)
+		if ctx.Err() != nil {
+			delivery.Release()
+			return events.WorkItem{}, nil, ctx.Err()
+		}
+		var item events.WorkItem
+		if err := json.Unmarshal(message.Data(), &item); err != nil {
+			// The message would never be handled, it's dropped.
+			message.Term() // nolint: errcheck
+			delivery.stopKeepAlive()
+			return events.WorkItem{}, nil, fmt.Errorf("dropping invalid work item: %w", err)
+		}
+		return item, delivery, nil
+	}
+}
+
+// workers returns the consumer of the workers, creating it the first time.
+func (q *NATS) workers(ctx context.Context) (jetstream.Consumer, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.consumer != nil {
+		return q.consumer, nil
+	}
+	consumer, err := q.js.CreateOrUpdateConsumer(ctx, natsStream, jetstream.ConsumerConfig{
+		Durable:   natsConsumer,
+		AckPolicy: jetstream.AckExplicitPolicy,
+		AckWait:   q.ackWait,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating the %s consumer: %w", natsConsumer, err)
+	}
+	q.consumer = consumer
+	return consumer, nil
+}
+
+// natsDelivery is the delivery of message to the worker. It's kept from
+// being delivered again by reporting it's in progress regularly.
+type natsDelivery struct {
+	message       jetstream.Msg
+	stopKeepAlive context.CancelFunc
+}
+
+func (q *NATS) deliver(message jetstream.Msg) *natsDelivery {
+	alive, stopKeepAlive := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(q.ackWait / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-alive.Done():
+				return
+			case <-ticker.C:
+				message.InProgress() // nolint: errcheck
+			}
+		}
+	}()
+	return &natsDelivery{message: message, stopKeepAlive: stopKeepAlive}
+}
+
+func (d *natsDelivery) Ack() error {
+	d.stopKeepAlive()
+	return d.message.DoubleAck(context.Background())
+}
+
+// Release delivers the message to the other workers right away.
+func (d *natsDelivery) Release() {
+	d.stopKeepAlive()
+	d.message.Nak() // nolint: errcheck
+}
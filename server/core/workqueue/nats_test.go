@@ -0,0 +1,74 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package workqueue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func newTestNATS(t *testing.T) *NATS {
+	s, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	Ok(t, err)
+	go s.Start()
+	Assert(t, s.ReadyForConnections(10*time.Second), "exp the NATS server to start")
+	t.Cleanup(s.Shutdown)
+
+	q, err := NewNATS(context.Background(), s.ClientURL())
+	Ok(t, err)
+	return q
+}
+
+func TestNATS(t *testing.T) {
+	q := newTestNATS(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	Ok(t, q.Enqueue(workItem(1)))
+	Ok(t, q.Enqueue(workItem(2)))
+
+	item, delivery, err := q.Dequeue(ctx)
+	Ok(t, err)
+	Equals(t, 1, item.PullNum)
+
+	// The other workers receive the next item while the first one runs.
+	next, nextDelivery, err := q.Dequeue(ctx)
+	Ok(t, err)
+	Equals(t, 2, next.PullNum)
+
+	// Released items are delivered again right away.
+	nextDelivery.Release()
+	next, nextDelivery, err = q.Dequeue(ctx)
+	Ok(t, err)
+	Equals(t, 2, next.PullNum)
+
+	// Acked items are removed from the stream.
+	Ok(t, delivery.Ack())
+	Ok(t, nextDelivery.Ack())
+	info, err := q.js.Stream(ctx, natsStream)
+	Ok(t, err)
+	state, err := info.Info(ctx)
+	Ok(t, err)
+	Equals(t, uint64(0), state.State.Msgs)
+}
+
+func TestNATS_DequeueStopsWithContext(t *testing.T) {
+	q := newTestNATS(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := q.Dequeue(ctx)
+	Assert(t, errors.Is(err, context.Canceled), "exp the dequeue to be canceled, got %v", err)
+}
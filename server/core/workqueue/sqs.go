@@ -0,0 +1,156 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package workqueue implements the work queue of the frontend and worker
+// server roles on message brokers other than Redis.
+package workqueue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/runatlantis/atlantis/server/events"
+)
+
+const (
+	// sqsWait is how long receiving messages waits for one before ctx is
+	// checked again. It's the maximum of SQS long polling.
+	sqsWait = 20 * time.Second
+	// sqsVisibilityTimeout is how long a message received by a worker that
+	// stopped extending it, ex. because it crashed, is hidden from the other
+	// workers.
+	sqsVisibilityTimeout = time.Minute
+)
+
+// SQS is a work queue on an Amazon SQS queue. The messages of the items a
+// worker runs are kept hidden from the other workers by extending their
+// visibility timeout. Items of FIFO queues are grouped by pull request so
+// they're received in the order they were dispatched.
+type SQS struct {
+	client            *sqs.Client
+	queueURL          string
+	visibilityTimeout time.Duration
+}
+
+// NewSQS returns the work queue on the SQS queue at queueURL, authenticated
+// with the default credential chain of the AWS SDK, ex. the AWS_*
+// environment variables, shared config files or the IAM role of the
+// instance. The region is the queue's unless AWS_REGION is set.
+func NewSQS(ctx context.Context, queueURL string) (*SQS, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("loading the AWS config: %w", err)
+	}
+	if cfg.Region == "" {
+		// Queue URLs are https://sqs.<region>.amazonaws.com/<account>/<name>.
+		if parsed, err := url.Parse(queueURL); err == nil {
+			if parts := strings.Split(parsed.Hostname(), "."); len(parts) > 2 && parts[0] == "sqs" {
+				cfg.Region = parts[1]
+			}
+		}
+	}
+	return &SQS{client: sqs.NewFromConfig(cfg), queueURL: queueURL, visibilityTimeout: sqsVisibilityTimeout}, nil
+}
+
+// Enqueue sends item, without the credentials of its clone URLs, to the
+// queue.
+func (q *SQS) Enqueue(item events.WorkItem) error {
+	serialized, err := json.Marshal(item.WithoutCloneCredentials())
+	if err != nil {
+		return fmt.Errorf("serializing work item: %w", err)
+	}
+	input := &sqs.SendMessageInput{
+		QueueUrl:    aws.String(q.queueURL),
+		MessageBody: aws.String(string(serialized)),
+	}
+	if strings.HasSuffix(q.queueURL, ".fifo") {
+		input.MessageGroupId = aws.String(item.BaseRepo.FullName + "#" + strconv.Itoa(item.PullNum))
+		input.MessageDeduplicationId = aws.String(fmt.Sprintf("%s-%d", item.CorrelationID, item.EnqueuedAt.UnixNano()))
+	}
+	_, err = q.client.SendMessage(context.Background(), input)
+	return err
+}
+
+// Dequeue receives the next message of the queue.
+func (q *SQS) Dequeue(ctx context.Context) (events.WorkItem, events.WorkDelivery, error) {
+	for {
+		out, err := q.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(q.queueURL),
+			MaxNumberOfMessages: 1,
+			WaitTimeSeconds:     int32(sqsWait.Seconds()),
+			VisibilityTimeout:   int32(q.visibilityTimeout.Seconds()),
+		})
+		if err != nil {
+			return events.WorkItem{}, nil, fmt.Errorf("receiving from %s: %w", q.queueURL, err)
+		}
+		if len(out.Messages) == 0 {
+			continue
+		}
+		message := out.Messages[0]
+		delivery := q.deliver(aws.ToString(message.ReceiptHandle))
+		var item events.WorkItem
+		if err := json.Unmarshal([]byte(aws.ToString(message.Body)), &item); err != nil {
+			// The message would never be handled, it's dropped.
+			delivery.Ack() // nolint: errcheck
+			return events.WorkItem{}, nil, fmt.Errorf("dropping invalid work item %s: %w", aws.ToString(message.MessageId), err)
+		}
+		return item, delivery, nil
+	}
+}
+
+// sqsDelivery is the delivery of the message with receiptHandle to the
+// worker. It's kept hidden by extending its visibility timeout regularly.
+type sqsDelivery struct {
+	queue         *SQS
+	receiptHandle string
+	stopKeepAlive context.CancelFunc
+}
+
+func (q *SQS) deliver(receiptHandle string) *sqsDelivery {
+	alive, stopKeepAlive := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(q.visibilityTimeout / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-alive.Done():
+				return
+			case <-ticker.C:
+				q.changeVisibility(alive, receiptHandle, q.visibilityTimeout) // nolint: errcheck
+			}
+		}
+	}()
+	return &sqsDelivery{queue: q, receiptHandle: receiptHandle, stopKeepAlive: stopKeepAlive}
+}
+
+func (q *SQS) changeVisibility(ctx context.Context, receiptHandle string, timeout time.Duration) error {
+	_, err := q.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(q.queueURL),
+		ReceiptHandle:     aws.String(receiptHandle),
+		VisibilityTimeout: int32(timeout.Seconds()),
+	})
+	return err
+}
+
+func (d *sqsDelivery) Ack() error {
+	d.stopKeepAlive()
+	_, err := d.queue.client.DeleteMessage(context.Background(), &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(d.queue.queueURL),
+		ReceiptHandle: aws.String(d.receiptHandle),
+	})
+	return err
+}
+
+// Release makes the message visible to the other workers right away.
+func (d *sqsDelivery) Release() {
+	d.stopKeepAlive()
+	d.queue.changeVisibility(context.Background(), d.receiptHandle, 0) // nolint: errcheck
+}
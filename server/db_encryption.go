@@ -0,0 +1,46 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/runatlantis/atlantis/server/core/boltdb"
+	"github.com/runatlantis/atlantis/server/core/encryption"
+	"github.com/runatlantis/atlantis/server/core/secrets"
+)
+
+// enableDBEncryption encrypts database at rest with --db-encryption-key or
+// with a data key encrypted by --db-encryption-kms-key-id. Encrypted databases
+// can't be opened without encryption.
+func enableDBEncryption(database *boltdb.BoltDB, userConfig UserConfig) error {
+	var key []byte
+	var err error
+	switch {
+	case userConfig.DBEncryptionKey != "":
+		if key, err = encryption.ParseKey(userConfig.DBEncryptionKey); err != nil {
+			return fmt.Errorf("parsing --db-encryption-key: %w", err)
+		}
+	case userConfig.DBEncryptionKMSKeyID != "":
+		if key, err = database.DataKey(secrets.NewAWSKMSFromEnv(userConfig.DBEncryptionKMSKeyID)); err != nil {
+			return fmt.Errorf("getting the database encryption key: %w", err)
+		}
+	default:
+		encrypted, err := database.Encrypted()
+		if err != nil {
+			return err
+		}
+		if encrypted {
+			return errors.New("the database is encrypted, --db-encryption-key or --db-encryption-kms-key-id must be set")
+		}
+		return nil
+	}
+
+	c, err := encryption.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	return database.EnableEncryption(c)
+}
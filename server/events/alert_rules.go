@@ -0,0 +1,223 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// The metrics alert rules are evaluated against.
+const (
+	// ApplyFailureRateMetric is the ratio of applies that failed in the window.
+	ApplyFailureRateMetric = "apply_failure_rate"
+	// ApplyFailuresMetric is how many applies failed in the window.
+	ApplyFailuresMetric = "apply_failures"
+	// PlanFailureRateMetric is the ratio of plans that failed in the window.
+	PlanFailureRateMetric = "plan_failure_rate"
+	// PlanFailuresMetric is how many plans failed in the window.
+	PlanFailuresMetric = "plan_failures"
+	// QueueDepthMetric is how many operations are in progress, including
+	// those waiting for locks or the parallel pool.
+	QueueDepthMetric = "queue_depth"
+)
+
+var alertMetrics = []string{ApplyFailureRateMetric, ApplyFailuresMetric, PlanFailureRateMetric, PlanFailuresMetric, QueueDepthMetric}
+
+var alertComparisons = []string{">=", "<=", ">", "<"}
+
+// DefaultAlertWindow is the window of alert rules that don't set one.
+const DefaultAlertWindow = 10 * time.Minute
+
+// AlertRule is a condition on the server's activity, ex.
+// apply_failure_rate > 0.2, that fires an alert to the webhooks with the
+// alert event while it holds.
+type AlertRule struct {
+	Name       string
+	Metric     string
+	Comparison string
+	Threshold  float64
+	// Window is how far back plans and applies are counted. It doesn't apply
+	// to queue_depth which is evaluated as is.
+	Window time.Duration
+}
+
+// NewAlertRule parses condition, ex. "apply_failure_rate > 0.2", into the
+// rule named name. window is a duration, ex. 10m, DefaultAlertWindow if it's
+// empty.
+func NewAlertRule(name string, condition string, window string) (AlertRule, error) {
+	rule := AlertRule{Name: name, Window: DefaultAlertWindow}
+	if name == "" {
+		return rule, fmt.Errorf("alert with condition %q must have a name", condition)
+	}
+	fields := strings.Fields(condition)
+	if len(fields) != 3 {
+		return rule, fmt.Errorf("condition %q of alert %s must be <metric> <comparison> <threshold>, ex. %s > 0.2", condition, name, ApplyFailureRateMetric)
+	}
+	rule.Metric, rule.Comparison = fields[0], fields[1]
+	if !slices.Contains(alertMetrics, rule.Metric) {
+		return rule, fmt.Errorf("invalid metric %q in alert %s, must be one of %s", rule.Metric, name, strings.Join(alertMetrics, ", "))
+	}
+	if !slices.Contains(alertComparisons, rule.Comparison) {
+		return rule, fmt.Errorf("invalid comparison %q in alert %s, must be one of %s", rule.Comparison, name, strings.Join(alertComparisons, ", "))
+	}
+	var err error
+	if rule.Threshold, err = strconv.ParseFloat(fields[2], 64); err != nil {
+		return rule, fmt.Errorf("invalid threshold %q in alert %s: %w", fields[2], name, err)
+	}
+	if window != "" {
+		if rule.Window, err = time.ParseDuration(window); err != nil || rule.Window <= 0 {
+			return rule, fmt.Errorf("invalid window %q in alert %s, must be a positive duration, ex. 10m", window, name)
+		}
+	}
+	return rule, nil
+}
+
+func (r AlertRule) String() string {
+	return fmt.Sprintf("%s %s %s", r.Metric, r.Comparison, strconv.FormatFloat(r.Threshold, 'f', -1, 64))
+}
+
+func (r AlertRule) holds(value float64) bool {
+	switch r.Comparison {
+	case ">=":
+		return value >= r.Threshold
+	case "<=":
+		return value <= r.Threshold
+	case ">":
+		return value > r.Threshold
+	default:
+		return value < r.Threshold
+	}
+}
+
+// alertResult is the result of a plan or apply alert rules are evaluated
+// against.
+type alertResult struct {
+	time    time.Time
+	event   string
+	success bool
+}
+
+// AlertEvaluator evaluates alert rules in-process, for installs without
+// Prometheus and Alertmanager. It records the results of plans and applies
+// as a webhook sender and is run periodically, sending an alert event to
+// Notifier when a rule starts holding and another one once it stops.
+type AlertEvaluator struct {
+	Rules    []AlertRule
+	Notifier WebhooksSender
+	// Drainer counts the operations in progress for queue_depth. It may be
+	// nil.
+	Drainer *Drainer
+	Logger  logging.SimpleLogging
+
+	mutex   sync.Mutex
+	results []alertResult
+	firing  map[string]bool
+	// now is the current time, time.Now if nil.
+	now func() time.Time
+}
+
+func (a *AlertEvaluator) currentTime() time.Time {
+	if a.now != nil {
+		return a.now()
+	}
+	return time.Now()
+}
+
+// Send records the results of plans and applies. Other events are ignored.
+func (a *AlertEvaluator) Send(_ logging.SimpleLogging, result webhooks.ApplyResult) error {
+	event := result.EventName()
+	if event != webhooks.PlanEvent && event != webhooks.ApplyEvent {
+		return nil
+	}
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.results = append(a.results, alertResult{time: a.currentTime(), event: event, success: result.Success})
+	return nil
+}
+
+// Run evaluates the rules and sends the alerts of the rules that started or
+// stopped holding.
+func (a *AlertEvaluator) Run() {
+	now := a.currentTime()
+	var alerts []webhooks.ApplyResult
+	a.mutex.Lock()
+	if a.firing == nil {
+		a.firing = make(map[string]bool)
+	}
+	var maxWindow time.Duration
+	for _, rule := range a.Rules {
+		maxWindow = max(maxWindow, rule.Window)
+		value, description := a.evaluate(rule, now)
+		holds := rule.holds(value)
+		if holds == a.firing[rule.Name] {
+			continue
+		}
+		a.firing[rule.Name] = holds
+		alerts = append(alerts, webhooks.ApplyResult{
+			Event:   webhooks.AlertEvent,
+			Alert:   rule.Name,
+			Success: !holds,
+			Summary: fmt.Sprintf("%s: %s", rule, description),
+		})
+	}
+	// Results older than every window won't be counted anymore.
+	a.results = slices.DeleteFunc(a.results, func(r alertResult) bool { return now.Sub(r.time) > maxWindow })
+	a.mutex.Unlock()
+
+	// Alerts are sent without holding the mutex since the notifier may send
+	// them back to Send.
+	for _, alert := range alerts {
+		state := "resolved"
+		if !alert.Success {
+			state = "firing"
+		}
+		a.Logger.Info("alert %s %s, %s", alert.Alert, state, alert.Summary)
+		if err := a.Notifier.Send(a.Logger, alert); err != nil {
+			a.Logger.Warn("unable to send alert %s: %s", alert.Alert, err)
+		}
+	}
+}
+
+// evaluate returns the value of the rule's metric at now and a description of
+// it, ex. "3 of 4 applies failed in the last 10m0s".
+func (a *AlertEvaluator) evaluate(rule AlertRule, now time.Time) (float64, string) {
+	if rule.Metric == QueueDepthMetric {
+		depth := 0
+		if a.Drainer != nil {
+			depth = a.Drainer.GetStatus().InProgressOps
+		}
+		return float64(depth), fmt.Sprintf("%d operations in progress", depth)
+	}
+	event, noun := webhooks.ApplyEvent, "applies"
+	if rule.Metric == PlanFailureRateMetric || rule.Metric == PlanFailuresMetric {
+		event, noun = webhooks.PlanEvent, "plans"
+	}
+	var total, failed int
+	for _, r := range a.results {
+		if r.event != event || now.Sub(r.time) > rule.Window {
+			continue
+		}
+		total++
+		if !r.success {
+			failed++
+		}
+	}
+	if rule.Metric == ApplyFailuresMetric || rule.Metric == PlanFailuresMetric {
+		return float64(failed), fmt.Sprintf("%d %s failed in the last %s", failed, noun, rule.Window)
+	}
+	// Without any plan or apply the failure rate is 0 so it doesn't fire.
+	rate := 0.0
+	if total > 0 {
+		rate = float64(failed) / float64(total)
+	}
+	return rate, fmt.Sprintf("%d of %d %s failed in the last %s", failed, total, noun, rule.Window)
+}
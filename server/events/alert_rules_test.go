@@ -0,0 +1,101 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// recordingNotifier records the alerts it's sent.
+type recordingNotifier struct {
+	alerts []webhooks.ApplyResult
+}
+
+func (r *recordingNotifier) Send(_ logging.SimpleLogging, result webhooks.ApplyResult) error {
+	r.alerts = append(r.alerts, result)
+	return nil
+}
+
+func TestNewAlertRule(t *testing.T) {
+	rule, err := NewAlertRule("apply-failures", "apply_failure_rate > 0.2", "")
+	Ok(t, err)
+	Equals(t, AlertRule{Name: "apply-failures", Metric: ApplyFailureRateMetric, Comparison: ">", Threshold: 0.2, Window: DefaultAlertWindow}, rule)
+	Equals(t, "apply_failure_rate > 0.2", rule.String())
+
+	rule, err = NewAlertRule("queue", "queue_depth >= 10", "5m")
+	Ok(t, err)
+	Equals(t, 5*time.Minute, rule.Window)
+
+	_, err = NewAlertRule("", "queue_depth > 10", "")
+	ErrEquals(t, `alert with condition "queue_depth > 10" must have a name`, err)
+	_, err = NewAlertRule("queue", "queue_depth>10", "")
+	ErrEquals(t, `condition "queue_depth>10" of alert queue must be <metric> <comparison> <threshold>, ex. apply_failure_rate > 0.2`, err)
+	_, err = NewAlertRule("queue", "queue_size > 10", "")
+	ErrEquals(t, `invalid metric "queue_size" in alert queue, must be one of apply_failure_rate, apply_failures, plan_failure_rate, plan_failures, queue_depth`, err)
+	_, err = NewAlertRule("queue", "queue_depth == 10", "")
+	ErrEquals(t, `invalid comparison "==" in alert queue, must be one of >=, <=, >, <`, err)
+	_, err = NewAlertRule("queue", "queue_depth > 10", "soon")
+	ErrEquals(t, `invalid window "soon" in alert queue, must be a positive duration, ex. 10m`, err)
+}
+
+func TestAlertEvaluator_Run(t *testing.T) {
+	notifier := &recordingNotifier{}
+	drainer := &Drainer{}
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	evaluator := &AlertEvaluator{
+		Rules: []AlertRule{
+			{Name: "apply-failures", Metric: ApplyFailureRateMetric, Comparison: ">", Threshold: 0.5, Window: 10 * time.Minute},
+			{Name: "queue", Metric: QueueDepthMetric, Comparison: ">=", Threshold: 2, Window: DefaultAlertWindow},
+		},
+		Notifier: notifier,
+		Drainer:  drainer,
+		Logger:   logging.NewNoopLogger(t),
+		now:      func() time.Time { return now },
+	}
+	log := logging.NewNoopLogger(t)
+
+	t.Log("nothing fires without plans and applies")
+	evaluator.Run()
+	Equals(t, 0, len(notifier.alerts))
+
+	Ok(t, evaluator.Send(log, webhooks.ApplyResult{Event: webhooks.ApplyEvent, Success: true}))
+	Ok(t, evaluator.Send(log, webhooks.ApplyResult{Event: webhooks.ApplyEvent, Success: false}))
+	Ok(t, evaluator.Send(log, webhooks.ApplyResult{Event: webhooks.ApplyEvent, Success: false}))
+	// Only plans and applies are counted.
+	Ok(t, evaluator.Send(log, webhooks.ApplyResult{Event: webhooks.LockAcquiredEvent, Success: false}))
+	evaluator.Run()
+	Equals(t, []webhooks.ApplyResult{{
+		Event:   webhooks.AlertEvent,
+		Alert:   "apply-failures",
+		Summary: "apply_failure_rate > 0.5: 2 of 3 applies failed in the last 10m0s",
+	}}, notifier.alerts)
+
+	t.Log("firing alerts aren't sent again")
+	evaluator.Run()
+	Equals(t, 1, len(notifier.alerts))
+
+	t.Log("alerts resolve once the failures are outside of the window")
+	now = now.Add(11 * time.Minute)
+	drainer.StartOp()
+	drainer.StartOp()
+	evaluator.Run()
+	Equals(t, 3, len(notifier.alerts))
+	Equals(t, webhooks.ApplyResult{
+		Event:   webhooks.AlertEvent,
+		Alert:   "apply-failures",
+		Success: true,
+		Summary: "apply_failure_rate > 0.5: 0 of 0 applies failed in the last 10m0s",
+	}, notifier.alerts[1])
+	Equals(t, webhooks.ApplyResult{
+		Event:   webhooks.AlertEvent,
+		Alert:   "queue",
+		Summary: "queue_depth >= 2: 2 operations in progress",
+	}, notifier.alerts[2])
+	Equals(t, 0, len(evaluator.results))
+}
@@ -0,0 +1,213 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+const (
+	// applyChangelogEnv enables generating changelog entries after successful
+	// applies with the summarizer.
+	applyChangelogEnv = "TERRAFORM_APPLY_CHANGELOG"
+	// applyChangelogSystemPromptEnv overrides the changelog's system prompt.
+	applyChangelogSystemPromptEnv = "OPENROUTER_TERRAFORM_APPLY_CHANGELOG_SYSTEM_PROMPT"
+	// applyChangelogFileEnv is the file the entries are appended to.
+	applyChangelogFileEnv = "APPLY_CHANGELOG_FILE"
+	// applyChangelogURLEnv is the endpoint the entries are posted to as JSON.
+	applyChangelogURLEnv   = "APPLY_CHANGELOG_URL"
+	applyChangelogTokenEnv = "APPLY_CHANGELOG_TOKEN" // nolint: gosec
+	// applyChangelogMergeCommitEnv uses the entries as the description of
+	// the commits of automerged pull requests.
+	applyChangelogMergeCommitEnv = "APPLY_CHANGELOG_MERGE_COMMIT"
+	applyChangelogTimeout        = 10 * time.Second
+	// maxChangelogOutputLen is how much of the end of each project's apply
+	// output is summarized. Terraform prints the applied changes last.
+	maxChangelogOutputLen       = 20000
+	defaultApplyChangelogPrompt = `You write infrastructure changelog entries from the output of successful Terraform applies.
+
+Reply with one short sentence summarizing the change, then a markdown list with one bullet per notable change, ex. "- Created the aws_s3_bucket logs bucket in the production project." Name the projects, resources and values involved, group many similar resources into one bullet and leave out resources that were only read or refreshed.
+
+Don't use headings and don't speculate beyond what the output shows. If nothing changed, reply with exactly: "No changes."`
+)
+
+// ApplyChangelog generates a concise changelog entry for each successful
+// apply of a pull request and records it to a file, an HTTP endpoint or the
+// merge commit, so teams get an automatic log of their infrastructure
+// changes.
+type ApplyChangelog struct {
+	// File is the file the entries are appended to. It may be empty.
+	File string
+	// URL is the endpoint the entries are posted to. It may be empty.
+	URL string
+	// Token is sent as a bearer token. It may be empty.
+	Token  string
+	Client *http.Client
+	// MergeCommit is true if the entries are used as the description of the
+	// merge commits of automerged pull requests.
+	MergeCommit bool
+}
+
+// NewApplyChangelogFromEnv returns an ApplyChangelog configured with the
+// APPLY_CHANGELOG_* environment variables or nil if TERRAFORM_APPLY_CHANGELOG
+// isn't true.
+func NewApplyChangelogFromEnv() *ApplyChangelog {
+	if os.Getenv(applyChangelogEnv) != "true" {
+		return nil
+	}
+	return &ApplyChangelog{
+		File:        os.Getenv(applyChangelogFileEnv),
+		URL:         os.Getenv(applyChangelogURLEnv),
+		Token:       os.Getenv(applyChangelogTokenEnv),
+		Client:      &http.Client{Timeout: applyChangelogTimeout},
+		MergeCommit: os.Getenv(applyChangelogMergeCommitEnv) == "true",
+	}
+}
+
+// ChangelogPayload is the JSON body posted for each changelog entry.
+type ChangelogPayload struct {
+	Repo      string      `json:"repo"`
+	Pull      SummaryPull `json:"pull"`
+	User      string      `json:"user"`
+	Projects  []string    `json:"projects"`
+	Entry     string      `json:"entry"`
+	AppliedAt time.Time   `json:"applied_at"`
+}
+
+// GenerateChangelogEntry returns the changelog entry of the successful
+// applies in projectResults or an empty string if there are none, or nothing
+// changed.
+func GenerateChangelogEntry(projectResults []command.ProjectResult, logger logging.SimpleLogging) string {
+	var outputs []string
+	for _, result := range projectResults {
+		output := strings.TrimSpace(result.ApplySuccess)
+		if output == "" {
+			continue
+		}
+		if len(output) > maxChangelogOutputLen {
+			output = output[len(output)-maxChangelogOutputLen:]
+		}
+		outputs = append(outputs, fmt.Sprintf("Project %s:\n%s", changelogProjectName(result), output))
+	}
+	if len(outputs) == 0 {
+		return ""
+	}
+	systemPrompt := defaultApplyChangelogPrompt
+	if override := os.Getenv(applyChangelogSystemPromptEnv); override != "" {
+		systemPrompt = override
+	}
+	entry := complete(systemPrompt, strings.Join(outputs, "\n\n"), logger)
+	if entry == "No changes." {
+		return ""
+	}
+	return entry
+}
+
+// Record generates the changelog entry of the applies in projectResults and
+// records it. It returns the entry, empty if none was generated. Failing to
+// record it is logged, the applies already happened.
+func (c *ApplyChangelog) Record(ctx *command.Context, projectResults []command.ProjectResult) string {
+	entry := GenerateChangelogEntry(projectResults, ctx.Log)
+	if entry == "" {
+		return ""
+	}
+	payload := ChangelogPayload{
+		Repo: ctx.Pull.BaseRepo.FullName,
+		Pull: SummaryPull{
+			Number:     ctx.Pull.Num,
+			Title:      ctx.Pull.Title,
+			URL:        ctx.Pull.URL,
+			Author:     ctx.Pull.Author,
+			HeadBranch: ctx.Pull.HeadBranch,
+			BaseBranch: ctx.Pull.BaseBranch,
+			HeadCommit: ctx.Pull.HeadCommit,
+		},
+		User:      ctx.User.Username,
+		Projects:  []string{},
+		Entry:     entry,
+		AppliedAt: time.Now().UTC(),
+	}
+	for _, result := range projectResults {
+		if result.ApplySuccess != "" {
+			payload.Projects = append(payload.Projects, changelogProjectName(result))
+		}
+	}
+	if c.File != "" {
+		if err := c.appendToFile(payload); err != nil {
+			ctx.Log.Err("unable to append the changelog entry to %q: %s", c.File, err)
+		}
+	}
+	if c.URL != "" {
+		if err := c.send(payload); err != nil {
+			ctx.Log.Err("unable to post the changelog entry: %s", err)
+		}
+	}
+	return entry
+}
+
+// appendToFile appends payload's entry to the changelog file as markdown.
+func (c *ApplyChangelog) appendToFile(payload ChangelogPayload) error {
+	f, err := os.OpenFile(c.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) // nolint: gosec
+	if err != nil {
+		return err
+	}
+	heading := fmt.Sprintf("## %s %s#%d", payload.AppliedAt.Format(time.DateOnly), payload.Repo, payload.Pull.Number)
+	if payload.Pull.Title != "" {
+		heading += ": " + payload.Pull.Title
+	}
+	_, err = fmt.Fprintf(f, "%s\n\nApplied by %s to %s.\n\n%s\n\n", heading, payload.User, strings.Join(payload.Projects, ", "), payload.Entry)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// send posts payload to the changelog's URL.
+func (c *ApplyChangelog) send(payload ChangelogPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", c.URL, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("returned status code %d with response %q", resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+// changelogProjectName returns how result's project is named in the
+// changelog.
+func changelogProjectName(result command.ProjectResult) string {
+	if result.ProjectName != "" {
+		return result.ProjectName
+	}
+	return fmt.Sprintf("%s (%s)", result.RepoRelDir, result.Workspace)
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestGenerateChangelogEntry(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	t.Setenv("TERRAFORM_PLAN_SUMMARIZER_COMMAND", `echo "$ATLANTIS_SUMMARIZER_SYSTEM_PROMPT"; cat`)
+	t.Setenv("OPENROUTER_TERRAFORM_APPLY_CHANGELOG_SYSTEM_PROMPT", "changelog")
+	results := []command.ProjectResult{
+		{ProjectName: "app", ProjectCommandOutput: command.ProjectCommandOutput{ApplySuccess: "Apply complete! Resources: 1 added, 0 changed, 0 destroyed.\n"}},
+		{RepoRelDir: "failed", Workspace: "default", ProjectCommandOutput: command.ProjectCommandOutput{Failure: "locked"}},
+		{RepoRelDir: "infra", Workspace: "staging", ProjectCommandOutput: command.ProjectCommandOutput{ApplySuccess: "Apply complete! Resources: 0 added, 1 changed, 0 destroyed."}},
+	}
+	Equals(t, "changelog\nProject app:\nApply complete! Resources: 1 added, 0 changed, 0 destroyed.\n\nProject infra (staging):\nApply complete! Resources: 0 added, 1 changed, 0 destroyed.", events.GenerateChangelogEntry(results, logger))
+	Equals(t, "", events.GenerateChangelogEntry(results[1:2], logger))
+
+	// Only the end of long outputs is summarized.
+	t.Setenv("TERRAFORM_PLAN_SUMMARIZER_COMMAND", "wc -c | tr -d ' '")
+	Equals(t, "20013", events.GenerateChangelogEntry([]command.ProjectResult{{ProjectName: "app", ProjectCommandOutput: command.ProjectCommandOutput{ApplySuccess: strings.Repeat("a", 30000)}}}, logger))
+
+	t.Setenv("TERRAFORM_PLAN_SUMMARIZER_COMMAND", "echo 'No changes.'")
+	Equals(t, "", events.GenerateChangelogEntry(results, logger))
+}
+
+func TestApplyChangelog_Record(t *testing.T) {
+	t.Setenv("TERRAFORM_PLAN_SUMMARIZER_COMMAND", "echo 'Created the logs bucket.'")
+	var got events.ChangelogPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Equals(t, "POST", r.Method)
+		Equals(t, "Bearer token", r.Header.Get("Authorization"))
+		Ok(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	changelog := &events.ApplyChangelog{File: file, URL: server.URL, Token: "token"}
+	ctx := &command.Context{
+		Log:  logging.NewNoopLogger(t),
+		Pull: models.PullRequest{Num: 7, Title: "Add bucket", BaseRepo: models.Repo{FullName: "owner/repo"}},
+		User: models.User{Username: "lkysow"},
+	}
+	results := []command.ProjectResult{{ProjectName: "app", ProjectCommandOutput: command.ProjectCommandOutput{ApplySuccess: "Apply complete!"}}}
+	Equals(t, "Created the logs bucket.", changelog.Record(ctx, results))
+	Equals(t, "Created the logs bucket.", changelog.Record(ctx, results))
+
+	Equals(t, "owner/repo", got.Repo)
+	Equals(t, 7, got.Pull.Number)
+	Equals(t, "lkysow", got.User)
+	Equals(t, []string{"app"}, got.Projects)
+	Equals(t, "Created the logs bucket.", got.Entry)
+
+	contents, err := os.ReadFile(file)
+	Ok(t, err)
+	entry := "## " + got.AppliedAt.Format("2006-01-02") + " owner/repo#7: Add bucket\n\nApplied by lkysow to app.\n\nCreated the logs bucket.\n\n"
+	Equals(t, entry+entry, string(contents))
+
+	// Nothing is recorded when nothing was applied.
+	Equals(t, "", changelog.Record(ctx, nil))
+}
+
+func TestNewApplyChangelogFromEnv(t *testing.T) {
+	t.Setenv("TERRAFORM_APPLY_CHANGELOG", "")
+	Assert(t, events.NewApplyChangelogFromEnv() == nil, "exp nil changelog when disabled")
+
+	t.Setenv("TERRAFORM_APPLY_CHANGELOG", "true")
+	t.Setenv("APPLY_CHANGELOG_FILE", "/var/log/atlantis/CHANGELOG.md")
+	t.Setenv("APPLY_CHANGELOG_URL", "https://changelog.example.com/entries")
+	t.Setenv("APPLY_CHANGELOG_TOKEN", "token")
+	t.Setenv("APPLY_CHANGELOG_MERGE_COMMIT", "true")
+	changelog := events.NewApplyChangelogFromEnv()
+	Equals(t, "/var/log/atlantis/CHANGELOG.md", changelog.File)
+	Equals(t, "https://changelog.example.com/entries", changelog.URL)
+	Equals(t, "token", changelog.Token)
+	Assert(t, changelog.MergeCommit, "exp the entries in merge commits")
+}
@@ -68,6 +68,13 @@ type ApplyCommandRunner struct {
 	// are found
 	silenceVCSStatusNoProjects bool
 	SilencePRComments          []string
+	// Confirmations, if set, requires confirming applies of many projects or
+	// that destroy resources.
+	Confirmations *ApplyConfirmations
+	// Freezes, if set, blocks applies during change freezes.
+	Freezes *ChangeFreezes
+	// Changelog, if set, records a changelog entry of successful applies.
+	Changelog *ApplyChangelog
 }
 
 func (a *ApplyCommandRunner) Run(ctx *command.Context, cmd *CommentCommand) {
@@ -125,6 +132,23 @@ func (a *ApplyCommandRunner) Run(ctx *command.Context, cmd *CommentCommand) {
 		return
 	}
 
+	if cmd.Failed {
+		pullStatus, err := a.Database.GetPullStatus(pull)
+		if err != nil {
+			ctx.Log.Warn("unable to fetch pull status: %s", err)
+		}
+		projectCmds = failedProjectCmds(pullStatus, projectCmds, models.ErroredApplyStatus)
+		if len(projectCmds) == 0 {
+			noFailedProjects(ctx, a.vcsClient, command.Apply)
+			if pullStatus != nil {
+				a.updateCommitStatus(ctx, *pullStatus)
+			} else if err := a.commitStatusUpdater.UpdateCombinedCount(ctx.Log, baseRepo, pull, models.SuccessCommitStatus, command.Apply, 0, 0); err != nil {
+				ctx.Log.Warn("unable to update commit status: %s", err)
+			}
+			return
+		}
+	}
+
 	// If there are no projects to apply, don't respond to the PR and ignore
 	if len(projectCmds) == 0 && a.SilenceNoProjects {
 		ctx.Log.Info("determined there was no project to run plan in")
@@ -160,7 +184,65 @@ func (a *ApplyCommandRunner) Run(ctx *command.Context, cmd *CommentCommand) {
 		return
 	}
 
-	result := runProjectCmdsWithCancellationTracker(ctx, projectCmds, a.cancellationTracker, a.parallelPoolSize, a.isParallelEnabled(projectCmds), a.prjCmdRunner.Apply)
+	if a.Freezes != nil {
+		comment, blocked := a.Freezes.freezeComment(ctx, cmd, projectCmds)
+		if comment != "" {
+			if err := a.vcsClient.CreateComment(ctx.Log, baseRepo, pull.Num, comment, command.Apply.String()); err != nil {
+				ctx.Log.Err("unable to comment on pull request: %s", err)
+			}
+		}
+		if blocked {
+			ctx.Log.Info("not applying during change freezes")
+			pullStatus, err := a.Database.GetPullStatus(pull)
+			if err != nil {
+				ctx.Log.Warn("unable to fetch pull status: %s", err)
+			} else if pullStatus != nil {
+				a.updateCommitStatus(ctx, *pullStatus)
+			}
+			return
+		}
+		if comment != "" {
+			// The glass was broken, the projects apply during the freezes.
+			for i := range projectCmds {
+				projectCmds[i].FreezesOverridden = true
+			}
+		}
+	}
+
+	if a.Confirmations != nil {
+		comment, err := a.Confirmations.confirmationComment(ctx, cmd, projectCmds)
+		if err != nil {
+			if statusErr := a.commitStatusUpdater.UpdateCombined(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull, models.FailedCommitStatus, cmd.CommandName()); statusErr != nil {
+				ctx.Log.Warn("unable to update commit status: %s", statusErr)
+			}
+			a.pullUpdater.updatePull(ctx, cmd, command.Result{Error: err})
+			return
+		}
+		if comment != "" {
+			ctx.Log.Info("not applying until the apply is confirmed")
+			if err := a.vcsClient.CreateComment(ctx.Log, baseRepo, pull.Num, comment, command.Apply.String()); err != nil {
+				ctx.Log.Err("unable to comment on pull request: %s", err)
+			}
+			pullStatus, err := a.Database.GetPullStatus(pull)
+			if err != nil {
+				ctx.Log.Warn("unable to fetch pull status: %s", err)
+			} else if pullStatus != nil {
+				a.updateCommitStatus(ctx, *pullStatus)
+			}
+			return
+		}
+	}
+
+	reportProjects(ctx, projectCmds)
+	runnerFunc := withProgress(ctx, a.prjCmdRunner.Apply)
+	var result command.Result
+	if hasProjectDependencies(projectCmds) {
+		// Apply the projects after the projects they depend on so they can be
+		// applied with one command.
+		result = runProjectCmdsByDependencies(ctx, projectCmds, a.cancellationTracker, a.parallelPoolSize, a.isParallelEnabled(projectCmds), runnerFunc)
+	} else {
+		result = runProjectCmdsWithCancellationTracker(ctx, projectCmds, a.cancellationTracker, a.parallelPoolSize, a.isParallelEnabled(projectCmds), runnerFunc)
+	}
 	ctx.CommandHasErrors = result.HasErrors()
 
 	a.pullUpdater.updatePull(
@@ -176,8 +258,16 @@ func (a *ApplyCommandRunner) Run(ctx *command.Context, cmd *CommentCommand) {
 
 	a.updateCommitStatus(ctx, pullStatus)
 
+	var commitMessage string
+	if a.Changelog != nil {
+		entry := a.Changelog.Record(ctx, result.ProjectResults)
+		if a.Changelog.MergeCommit {
+			commitMessage = entry
+		}
+	}
+
 	if a.autoMerger.automergeEnabled(projectCmds) && !cmd.AutoMergeDisabled {
-		a.autoMerger.automerge(ctx, pullStatus, a.autoMerger.deleteSourceBranchOnMergeEnabled(projectCmds), cmd.AutoMergeMethod)
+		a.autoMerger.automerge(ctx, pullStatus, a.autoMerger.deleteSourceBranchOnMergeEnabled(projectCmds), cmd.AutoMergeMethod, commitMessage)
 	}
 }
 
@@ -0,0 +1,123 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// ApplyConfirmations requires applies of many projects or of plans that destroy
+// resources to be confirmed with apply --confirm <token>, the token being
+// commented along with what would be applied, so fleet-wide applies can't be
+// run by mistake.
+type ApplyConfirmations struct {
+	// ProjectsThreshold is how many projects can be applied without
+	// confirming. 0 means any number of projects can.
+	ProjectsThreshold int
+	// Destroys is true if applying plans that destroy resources requires
+	// confirming.
+	Destroys bool
+	// WorkingDir is where the saved plans are read from to find the ones
+	// that destroy resources.
+	WorkingDir WorkingDir
+	// PlanfileEncryptor decrypts the saved plans. Nil if planfiles aren't
+	// encrypted.
+	PlanfileEncryptor *runtime.PlanfileEncryptor
+
+	mutex sync.Mutex
+	// pending are the applies waiting for confirmation, by pull request.
+	pending map[string]pendingApply
+}
+
+// pendingApply is an apply waiting for confirmation. It can only be confirmed
+// for the same commit and projects.
+type pendingApply struct {
+	token      string
+	headCommit string
+	projects   []string
+}
+
+// confirmationComment returns the comment asking to confirm applying
+// projectCmds or "" if applying them doesn't require confirming or cmd
+// confirmed it.
+func (a *ApplyConfirmations) confirmationComment(ctx *command.Context, cmd *CommentCommand, projectCmds []command.ProjectContext) (string, error) {
+	var reasons []string
+	if a.ProjectsThreshold > 0 && len(projectCmds) > a.ProjectsThreshold {
+		reasons = append(reasons, fmt.Sprintf("it applies %d projects, more than the %d that can be applied without confirming", len(projectCmds), a.ProjectsThreshold))
+	}
+	var projects, lines []string
+	destroys, unreadable := false, false
+	for _, projectCmd := range projectCmds {
+		name := projectCmdName(projectCmd)
+		projects = append(projects, name)
+		line := "* " + name
+		output := LoadSavedPlan(a.WorkingDir, a.PlanfileEncryptor, projectCmd)
+		if output.PlanSuccess != nil {
+			stats := models.NewPlanSuccessStats(output.PlanSuccess.TerraformOutput)
+			line += fmt.Sprintf(": %d to import, %d to add, %d to change, %d to destroy", stats.Import, stats.Add, stats.Change, stats.Destroy)
+			if stats.Destroy > 0 {
+				destroys = true
+				line += " :warning:"
+			}
+		} else if a.Destroys {
+			// Without the plan's output we can't tell whether it destroys
+			// resources so it has to be confirmed as if it did.
+			unreadable = true
+			line += fmt.Sprintf(": %s :warning:", output.Failure)
+		}
+		lines = append(lines, line)
+	}
+	if a.Destroys && destroys {
+		reasons = append(reasons, "it destroys resources")
+	}
+	if unreadable {
+		reasons = append(reasons, "the changes of some plans can't be read to tell whether they destroy resources")
+	}
+	if len(reasons) == 0 {
+		return "", nil
+	}
+	slices.Sort(projects)
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	key := fmt.Sprintf("%s#%d", ctx.Pull.BaseRepo.FullName, ctx.Pull.Num)
+	prefix := ""
+	if cmd.ConfirmToken != "" {
+		pending, ok := a.pending[key]
+		if ok && pending.token == cmd.ConfirmToken && pending.headCommit == ctx.Pull.HeadCommit && slices.Equal(pending.projects, projects) {
+			delete(a.pending, key)
+			return "", nil
+		}
+		prefix = fmt.Sprintf("The confirmation token `%s` is invalid: it was already used or the commit or projects to apply changed since it was commented.\n\n", cmd.ConfirmToken)
+	}
+
+	token, err := newConfirmToken()
+	if err != nil {
+		return "", err
+	}
+	if a.pending == nil {
+		a.pending = make(map[string]pendingApply)
+	}
+	a.pending[key] = pendingApply{token: token, headCommit: ctx.Pull.HeadCommit, projects: projects}
+	return fmt.Sprintf("%s**Apply Confirmation Required**\n\nThis apply must be confirmed because %s. It would apply:\n\n%s\n\n"+
+		"To apply exactly these projects at commit %s, comment the same `%s` command with `--%s %s`.",
+		prefix, strings.Join(reasons, " and "), strings.Join(lines, "\n"), ctx.Pull.HeadCommit, command.Apply.String(), confirmFlagLong, token), nil
+}
+
+func newConfirmToken() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating confirmation token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/runatlantis/atlantis/server/core/db"
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// ApplyReports records a signed report of each apply, its provenance, so it
+// can be exported through the API as change management evidence.
+type ApplyReports struct {
+	Signer   *runtime.ReportSigner
+	Database db.Database
+	// Summaries are the plan summaries included in the reports. It may be
+	// nil.
+	Summaries *SummaryStore
+}
+
+// planfileSHA256 returns the hex encoded SHA-256 digest of the planfile of
+// the project described by ctx, or an empty string if there's none. It must
+// be called before the plan is applied.
+func planfileSHA256(ctx command.ProjectContext, absPath string) string {
+	contents, err := os.ReadFile(filepath.Join(absPath, runtime.GetPlanFilename(ctx.Workspace, ctx.ProjectName))) // nolint: gosec
+	if err != nil {
+		if !os.IsNotExist(err) {
+			ctx.Log.Warn("unable to read the planfile to record its digest in the apply report: %s", err)
+		}
+		return ""
+	}
+	digest := sha256.Sum256(contents)
+	return hex.EncodeToString(digest[:])
+}
+
+// Record signs and saves the report of the apply of the project described by
+// ctx. Failing to is logged, the apply already happened.
+func (a *ApplyReports) Record(ctx command.ProjectContext, planSHA256 string, changes []models.ResourceChange, success bool) {
+	report := models.ApplyReport{
+		ID:          uuid.NewString(),
+		Repository:  ctx.Pull.BaseRepo.FullName,
+		PullNum:     ctx.Pull.Num,
+		PullURL:     ctx.Pull.URL,
+		BaseBranch:  ctx.Pull.BaseBranch,
+		HeadCommit:  ctx.Pull.HeadCommit,
+		ProjectName: ctx.ProjectName,
+		RepoRelDir:  ctx.RepoRelDir,
+		Workspace:   ctx.Workspace,
+		AppliedBy:   ctx.User.Username,
+		AppliedAt:   time.Now().UTC(),
+		Success:     success,
+		PlanSHA256:  planSHA256,
+	}
+	if approval := ctx.PullReqStatus.ApprovalStatus; approval.IsApproved {
+		report.ApprovedBy = approval.ApprovedBy
+		if !approval.Date.IsZero() {
+			report.ApprovedAt = approval.Date.UTC()
+		}
+	}
+	for _, status := range ctx.ProjectPolicyStatus {
+		report.PolicyResults = append(report.PolicyResults, models.ApplyReportPolicySet{
+			Name:      status.PolicySetName,
+			Passed:    status.Passed,
+			Approvals: status.Approvals,
+		})
+	}
+	if a.Summaries != nil {
+		// The most recent summary of the applied commit.
+		for _, summary := range a.Summaries.List(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num) {
+			if summary.HeadCommit == ctx.Pull.HeadCommit {
+				report.Summary = summary.Summary
+			}
+		}
+	}
+	for _, change := range changes {
+		report.ResourceChanges = append(report.ResourceChanges, models.ApplyReportResourceChange{Address: change.Address, Action: change.Action})
+	}
+
+	// The report is signed as it's serialized when it's exported, the
+	// digest of its compact JSON is what verifies it.
+	serialized, err := models.MarshalApplyReportJSON(report)
+	if err != nil {
+		ctx.Log.Err("unable to serialize the apply report: %s", err)
+		return
+	}
+	if err := a.Database.SaveApplyReport(models.SignedApplyReport{Report: report, Signature: a.Signer.Sign(serialized)}); err != nil {
+		ctx.Log.Err("unable to save the apply report: %s", err)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/boltdb"
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestApplyReports_Record(t *testing.T) {
+	database, err := boltdb.New(t.TempDir())
+	Ok(t, err)
+	defer database.Close() // nolint: errcheck
+	signer, err := runtime.NewReportSigner("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	Ok(t, err)
+	summaries := NewSummaryStore()
+	pull := models.PullRequest{Num: 1, BaseRepo: models.Repo{FullName: "owner/repo"}, BaseBranch: "main", HeadCommit: "abc"}
+	summaries.Add(pull, "alice", GeneratedSummary{Summary: "Creates the web server & its <security group>."})
+	summaries.Add(models.PullRequest{Num: 1, BaseRepo: pull.BaseRepo, HeadCommit: "def"}, "alice", GeneratedSummary{Summary: "A later commit."})
+	reports := &ApplyReports{Signer: signer, Database: database, Summaries: summaries}
+
+	absPath := t.TempDir()
+	Ok(t, os.WriteFile(filepath.Join(absPath, runtime.GetPlanFilename("default", "app")), []byte("plan"), 0600))
+	approvedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))
+	ctx := command.ProjectContext{
+		Log:         logging.NewNoopLogger(t),
+		Pull:        pull,
+		User:        models.User{Username: "bob"},
+		ProjectName: "app",
+		RepoRelDir:  "app",
+		Workspace:   "default",
+		PullReqStatus: models.PullReqStatus{
+			ApprovalStatus: models.ApprovalStatus{IsApproved: true, ApprovedBy: "carol", Date: approvedAt},
+		},
+		ProjectPolicyStatus: []models.PolicySetStatus{{PolicySetName: "cost", Passed: false, Approvals: 1}},
+	}
+	planSHA256 := planfileSHA256(ctx, absPath)
+	Equals(t, "64879f7d6b960a01909762d911a32d4582c20010c5641ee90278b644a9e3b525", planSHA256)
+	Equals(t, "", planfileSHA256(ctx, t.TempDir()))
+
+	reports.Record(ctx, planSHA256, []models.ResourceChange{{Address: "aws_instance.web", Action: "create", User: "bob"}}, true)
+
+	saved, err := database.ListApplyReports(models.ApplyReportQuery{})
+	Ok(t, err)
+	Equals(t, 1, len(saved))
+	report := saved[0].Report
+	Assert(t, report.ID != "", "exp an ID")
+	Equals(t, "bob", report.AppliedBy)
+	Equals(t, "carol", report.ApprovedBy)
+	Equals(t, approvedAt.UTC(), report.ApprovedAt)
+	Equals(t, planSHA256, report.PlanSHA256)
+	Equals(t, []models.ApplyReportPolicySet{{Name: "cost", Passed: false, Approvals: 1}}, report.PolicyResults)
+	Equals(t, "Creates the web server & its <security group>.", report.Summary)
+	Equals(t, []models.ApplyReportResourceChange{{Address: "aws_instance.web", Action: "create"}}, report.ResourceChanges)
+	Assert(t, report.Success, "exp the apply to have succeeded")
+
+	// The report's JSON as it's exported verifies against the signature.
+	exported, err := models.MarshalApplyReportJSON(saved[0])
+	Ok(t, err)
+	var parsed struct {
+		Report    json.RawMessage
+		Signature string
+	}
+	Ok(t, json.Unmarshal(exported, &parsed))
+	Ok(t, signer.Verify(parsed.Report, parsed.Signature))
+}
@@ -0,0 +1,82 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/metrics"
+	tally "github.com/uber-go/tally/v4"
+)
+
+var (
+	// applySummaryRegex matches the summary terraform apply ends with, ex.
+	// Apply complete! Resources: 1 added, 2 changed, 0 destroyed.
+	applySummaryRegex = regexp.MustCompile(`Apply complete! Resources: (?:\d+ imported, )?(\d+) added, (\d+) changed, (\d+) destroyed`)
+	// appliedResourceRegex matches the lines terraform apply outputs once it
+	// changed a resource, ex. aws_instance.web: Creation complete after 2s.
+	appliedResourceRegex = regexp.MustCompile(`(?m)^\s*(\S+): (Creation|Modifications|Destruction) complete`)
+	// moduleAddressRegex matches the module part of a resource address, ex.
+	// module.dns["example.com"].
+	moduleAddressRegex = regexp.MustCompile(`^(?:module\.[^.\[]+(?:\[[^\]]*\])?\.)+`)
+)
+
+// appliedResourceCounts are the numbers of resources an apply added, changed
+// and destroyed.
+type appliedResourceCounts struct {
+	added, changed, destroyed int64
+}
+
+// applyResourceStats parses the apply output into the numbers of resources it
+// changed, and the numbers by provider, ex. aws for aws_instance resources.
+// ok is false if the output doesn't have an apply summary.
+func applyResourceStats(output string) (total appliedResourceCounts, byProvider map[string]appliedResourceCounts, ok bool) {
+	summary := applySummaryRegex.FindStringSubmatch(output)
+	if summary == nil {
+		return total, nil, false
+	}
+	total.added, _ = strconv.ParseInt(summary[1], 10, 64)
+	total.changed, _ = strconv.ParseInt(summary[2], 10, 64)
+	total.destroyed, _ = strconv.ParseInt(summary[3], 10, 64)
+
+	byProvider = make(map[string]appliedResourceCounts)
+	for _, match := range appliedResourceRegex.FindAllStringSubmatch(output, -1) {
+		address := moduleAddressRegex.ReplaceAllString(match[1], "")
+		resourceType, _, _ := strings.Cut(address, ".")
+		provider, _, _ := strings.Cut(resourceType, "_")
+		counts := byProvider[provider]
+		switch match[2] {
+		case "Creation":
+			counts.added++
+		case "Modifications":
+			counts.changed++
+		case "Destruction":
+			counts.destroyed++
+		}
+		byProvider[provider] = counts
+	}
+	return total, byProvider, true
+}
+
+// emitApplyResourceStats emits the numbers of resources the apply with output
+// changed, in total and by provider.
+func emitApplyResourceStats(scope tally.Scope, output string) {
+	total, byProvider, ok := applyResourceStats(output)
+	if !ok {
+		return
+	}
+	emitAppliedResourceCounts(scope, total)
+	providerScope := scope.SubScope("provider")
+	for provider, counts := range byProvider {
+		emitAppliedResourceCounts(providerScope.Tagged(map[string]string{"provider": provider}), counts)
+	}
+}
+
+func emitAppliedResourceCounts(scope tally.Scope, counts appliedResourceCounts) {
+	scope.Counter(metrics.ResourcesAddedMetric).Inc(counts.added)
+	scope.Counter(metrics.ResourcesChangedMetric).Inc(counts.changed)
+	scope.Counter(metrics.ResourcesDestroyedMetric).Inc(counts.destroyed)
+}
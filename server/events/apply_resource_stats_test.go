@@ -0,0 +1,57 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+	tally "github.com/uber-go/tally/v4"
+)
+
+func TestRunAndEmitStats_ApplyResources(t *testing.T) {
+	output := `aws_instance.web: Destroying... [id=i-1]
+aws_instance.web: Destruction complete after 1s
+aws_instance.web: Creating...
+aws_instance.web: Creation complete after 10s [id=i-2]
+module.dns["example.com"].aws_route53_record.web: Modifications complete after 2s
+module.dns["example.com"].google_dns_record_set.web[0]: Creation complete after 1s
+
+Apply complete! Resources: 2 added, 1 changed, 1 destroyed.
+`
+	scope := tally.NewTestScope("", nil)
+	ctx := command.ProjectContext{CommandName: command.Apply, Log: logging.NewNoopLogger(t)}
+	events.RunAndEmitStats(ctx, func(command.ProjectContext) command.ProjectCommandOutput {
+		return command.ProjectCommandOutput{ApplySuccess: output}
+	}, scope)
+
+	counters := make(map[string]int64)
+	for _, c := range scope.Snapshot().Counters() {
+		counters[c.Name()+"/"+c.Tags()["provider"]] = c.Value()
+	}
+	Equals(t, int64(2), counters["apply.resources_added/"])
+	Equals(t, int64(1), counters["apply.resources_changed/"])
+	Equals(t, int64(1), counters["apply.resources_destroyed/"])
+	Equals(t, int64(1), counters["apply.provider.resources_added/aws"])
+	Equals(t, int64(1), counters["apply.provider.resources_changed/aws"])
+	Equals(t, int64(1), counters["apply.provider.resources_destroyed/aws"])
+	Equals(t, int64(1), counters["apply.provider.resources_added/google"])
+	Equals(t, int64(0), counters["apply.provider.resources_destroyed/google"])
+}
+
+func TestRunAndEmitStats_ApplyWithoutSummary(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	ctx := command.ProjectContext{CommandName: command.Apply, Log: logging.NewNoopLogger(t)}
+	events.RunAndEmitStats(ctx, func(command.ProjectContext) command.ProjectCommandOutput {
+		return command.ProjectCommandOutput{ApplySuccess: "Apply complete, remote run finished."}
+	}, scope)
+
+	for _, c := range scope.Snapshot().Counters() {
+		Assert(t, !strings.Contains(c.Name(), "resources"), "unexpected counter %s", c.Name())
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/jobs"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/utils"
+	tally "github.com/uber-go/tally/v4"
+)
+
+// RetainedArtifact is an artifact the server stores that's deleted once it's
+// older than it's retained for or its repo exceeds its quota.
+type RetainedArtifact struct {
+	// Repo is the full name of the repo the artifact was generated for.
+	Repo string
+	// Size is the size of the artifact in bytes.
+	Size int64
+	// ModTime is when the artifact was last written.
+	ModTime time.Time
+	// Delete deletes the artifact.
+	Delete func() error
+}
+
+// ArtifactStore is a kind of artifact the server stores.
+type ArtifactStore interface {
+	// Name names the artifacts in logs and metrics, ex. planfiles.
+	Name() string
+	// Artifacts lists the stored artifacts.
+	Artifacts() ([]RetainedArtifact, error)
+}
+
+// ArtifactRetention enforces the retention of the artifacts the server
+// stores, so long-lived servers don't grow unbounded. It's run periodically.
+type ArtifactRetention struct {
+	Stores []ArtifactStore
+	// MaxAge is how long artifacts are retained for after they were last
+	// written. Zero if artifacts are retained whatever their age.
+	MaxAge time.Duration
+	// RepoQuota is how many bytes of each store's artifacts are retained per
+	// repo, the oldest artifacts are deleted first. Zero if there's no quota.
+	RepoQuota  int64
+	Logger     logging.SimpleLogging
+	StatsScope tally.Scope
+}
+
+// Run deletes the artifacts that are older than MaxAge or beyond the quota of
+// their repo and publishes how many bytes are retained.
+func (r *ArtifactRetention) Run() {
+	scope := r.StatsScope.SubScope("artifact_retention")
+	for _, store := range r.Stores {
+		artifacts, err := store.Artifacts()
+		if err != nil {
+			r.Logger.Warn("unable to list %s to enforce their retention: %s", store.Name(), err)
+			continue
+		}
+		retained, deleted := r.enforce(store.Name(), artifacts)
+
+		storeScope := scope.Tagged(map[string]string{"store": store.Name()})
+		var retainedBytes, deletedBytes int64
+		for _, artifact := range retained {
+			retainedBytes += artifact.Size
+		}
+		for _, artifact := range deleted {
+			deletedBytes += artifact.Size
+		}
+		storeScope.Gauge("retained_bytes").Update(float64(retainedBytes))
+		storeScope.Gauge("retained_count").Update(float64(len(retained)))
+		storeScope.Counter("deleted_bytes").Inc(deletedBytes)
+		storeScope.Counter("deleted_count").Inc(int64(len(deleted)))
+	}
+}
+
+// enforce deletes the artifacts that aren't retained and returns the ones
+// that are and the ones that were deleted.
+func (r *ArtifactRetention) enforce(storeName string, artifacts []RetainedArtifact) (retained []RetainedArtifact, deleted []RetainedArtifact) {
+	// The newest artifacts are retained first.
+	sort.SliceStable(artifacts, func(i, j int) bool { return artifacts[i].ModTime.After(artifacts[j].ModTime) })
+	repoBytes := make(map[string]int64)
+	for _, artifact := range artifacts {
+		tooOld := r.MaxAge > 0 && time.Since(artifact.ModTime) > r.MaxAge
+		overQuota := r.RepoQuota > 0 && repoBytes[artifact.Repo]+artifact.Size > r.RepoQuota
+		if !tooOld && !overQuota {
+			repoBytes[artifact.Repo] += artifact.Size
+			retained = append(retained, artifact)
+			continue
+		}
+		if err := artifact.Delete(); err != nil {
+			r.Logger.Warn("unable to delete %s of %s: %s", storeName, artifact.Repo, err)
+			retained = append(retained, artifact)
+			continue
+		}
+		deleted = append(deleted, artifact)
+	}
+	if len(deleted) > 0 {
+		r.Logger.Info("deleted %d %s beyond their retention", len(deleted), storeName)
+	}
+	return retained, deleted
+}
+
+// PlanfileArtifacts are the planfiles of the pull requests cloned in the data
+// dir.
+type PlanfileArtifacts struct {
+	DataDir string
+}
+
+func (p *PlanfileArtifacts) Name() string {
+	return "planfiles"
+}
+
+// Artifacts lists the pending planfiles of the clones of pull requests, which
+// are in DataDir/repos/<repo full name>/<pull num>/<workspace> and the same
+// dirs of the data dirs of the tenants.
+func (p *PlanfileArtifacts) Artifacts() ([]RetainedArtifact, error) {
+	allReposDirs, err := reposDirs(p.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	var artifacts []RetainedArtifact
+	finder := &DefaultPendingPlanFinder{}
+	seenPullDirs := make(map[string]bool)
+	for _, reposDir := range allReposDirs {
+		if err := filepath.WalkDir(reposDir, func(path string, d fs.DirEntry, err error) error {
+			if os.IsNotExist(err) && path == reposDir {
+				return filepath.SkipAll
+			}
+			if err != nil || !d.IsDir() {
+				return err
+			}
+			// Clones are the only directories with a .git entry, their parent
+			// is the directory of the pull request.
+			if _, statErr := os.Stat(filepath.Join(path, ".git")); statErr != nil {
+				return nil
+			}
+			pullDir := filepath.Dir(path)
+			if seenPullDirs[pullDir] {
+				return filepath.SkipDir
+			}
+			seenPullDirs[pullDir] = true
+			repo, relErr := filepath.Rel(reposDir, filepath.Dir(pullDir))
+			if relErr != nil {
+				return relErr
+			}
+			_, planPaths, findErr := finder.findWithAbsPaths(pullDir)
+			if findErr != nil {
+				return findErr
+			}
+			for _, planPath := range planPaths {
+				info, statErr := os.Stat(planPath)
+				if statErr != nil {
+					continue
+				}
+				artifacts = append(artifacts, RetainedArtifact{
+					Repo:    filepath.ToSlash(repo),
+					Size:    info.Size(),
+					ModTime: info.ModTime(),
+					Delete:  func() error { return utils.RemoveIgnoreNonExistent(planPath) },
+				})
+			}
+			return filepath.SkipDir
+		}); err != nil {
+			return artifacts, err
+		}
+	}
+	return artifacts, nil
+}
+
+func (s *PlanJSONStore) Name() string {
+	return "plan_jsons"
+}
+
+// Artifacts lists the stored plans.
+func (s *PlanJSONStore) Artifacts() ([]RetainedArtifact, error) {
+	paths, err := filepath.Glob(filepath.Join(s.Dir, "*", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var artifacts []RetainedArtifact
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		contents, err := os.ReadFile(path) // nolint: gosec
+		if err != nil {
+			continue
+		}
+		var plan struct {
+			Repository string
+		}
+		if err := json.Unmarshal(contents, &plan); err != nil {
+			continue
+		}
+		artifacts = append(artifacts, RetainedArtifact{
+			Repo:    plan.Repository,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+			Delete:  func() error { return utils.RemoveIgnoreNonExistent(path) },
+		})
+	}
+	return artifacts, nil
+}
+
+// JobLogArtifacts are the outputs of completed jobs kept in memory.
+type JobLogArtifacts struct {
+	Handler interface {
+		JobLogs() []jobs.JobLog
+		DeleteJobLog(jobID string)
+	}
+}
+
+func (j *JobLogArtifacts) Name() string {
+	return "job_logs"
+}
+
+func (j *JobLogArtifacts) Artifacts() ([]RetainedArtifact, error) {
+	var artifacts []RetainedArtifact
+	for _, log := range j.Handler.JobLogs() {
+		artifacts = append(artifacts, RetainedArtifact{
+			Repo:    log.RepoFullName,
+			Size:    log.Size,
+			ModTime: log.Time,
+			Delete: func() error {
+				j.Handler.DeleteJobLog(log.JobID)
+				return nil
+			},
+		})
+	}
+	return artifacts, nil
+}
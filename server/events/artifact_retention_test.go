@@ -0,0 +1,127 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+	tally "github.com/uber-go/tally/v4"
+)
+
+type fakeArtifactStore struct {
+	artifacts []events.RetainedArtifact
+	deleted   []string
+}
+
+func (f *fakeArtifactStore) Name() string {
+	return "fakes"
+}
+
+func (f *fakeArtifactStore) Artifacts() ([]events.RetainedArtifact, error) {
+	return f.artifacts, nil
+}
+
+func (f *fakeArtifactStore) add(name string, repo string, size int64, age time.Duration) {
+	f.artifacts = append(f.artifacts, events.RetainedArtifact{
+		Repo:    repo,
+		Size:    size,
+		ModTime: time.Now().Add(-age),
+		Delete: func() error {
+			f.deleted = append(f.deleted, name)
+			return nil
+		},
+	})
+}
+
+func TestArtifactRetention_Run(t *testing.T) {
+	store := &fakeArtifactStore{}
+	store.add("a-new", "owner/a", 40, time.Hour)
+	store.add("a-older", "owner/a", 40, 2*time.Hour)
+	store.add("a-oldest", "owner/a", 40, 3*time.Hour)
+	store.add("b-new", "owner/b", 40, time.Hour)
+	store.add("b-expired", "owner/b", 10, 48*time.Hour)
+
+	scope := tally.NewTestScope("", nil)
+	retention := &events.ArtifactRetention{
+		Stores:     []events.ArtifactStore{store},
+		MaxAge:     24 * time.Hour,
+		RepoQuota:  100,
+		Logger:     logging.NewNoopLogger(t),
+		StatsScope: scope,
+	}
+	retention.Run()
+
+	sort.Strings(store.deleted)
+	Equals(t, []string{"a-oldest", "b-expired"}, store.deleted)
+	snapshot := scope.Snapshot()
+	for _, g := range snapshot.Gauges() {
+		Equals(t, "fakes", g.Tags()["store"])
+		switch g.Name() {
+		case "artifact_retention.retained_bytes":
+			Equals(t, float64(120), g.Value())
+		case "artifact_retention.retained_count":
+			Equals(t, float64(3), g.Value())
+		}
+	}
+	for _, c := range snapshot.Counters() {
+		switch c.Name() {
+		case "artifact_retention.deleted_bytes":
+			Equals(t, int64(50), c.Value())
+		case "artifact_retention.deleted_count":
+			Equals(t, int64(2), c.Value())
+		}
+	}
+}
+
+func TestPlanfileArtifacts(t *testing.T) {
+	dataDir := t.TempDir()
+	cloneDir := filepath.Join(dataDir, "repos", "group/subgroup/repo", "1", "default")
+	Ok(t, os.MkdirAll(filepath.Join(cloneDir, "project"), 0700))
+	runCmd(t, cloneDir, "git", "init")
+	planPath := filepath.Join(cloneDir, "project", "default.tfplan")
+	Ok(t, os.WriteFile(planPath, []byte("plan"), 0600))
+
+	artifacts, err := (&events.PlanfileArtifacts{DataDir: dataDir}).Artifacts()
+	Ok(t, err)
+	Equals(t, 1, len(artifacts))
+	Equals(t, "group/subgroup/repo", artifacts[0].Repo)
+	Equals(t, int64(len("plan")), artifacts[0].Size)
+
+	Ok(t, artifacts[0].Delete())
+	_, err = os.Stat(planPath)
+	Assert(t, os.IsNotExist(err), "exp planfile to be deleted")
+
+	artifacts, err = (&events.PlanfileArtifacts{DataDir: t.TempDir()}).Artifacts()
+	Ok(t, err)
+	Equals(t, 0, len(artifacts))
+}
+
+func TestPlanJSONStore_Artifacts(t *testing.T) {
+	store := &events.PlanJSONStore{Dir: t.TempDir()}
+	ctx := command.ProjectContext{
+		Pull:       models.PullRequest{Num: 1, BaseRepo: models.Repo{FullName: "owner/repo"}},
+		RepoRelDir: ".",
+		Workspace:  "default",
+	}
+	Ok(t, store.Save(ctx, `{"format_version":"1.2"}`))
+
+	artifacts, err := store.Artifacts()
+	Ok(t, err)
+	Equals(t, 1, len(artifacts))
+	Equals(t, "owner/repo", artifacts[0].Repo)
+
+	Ok(t, artifacts[0].Delete())
+	plans, err := store.List("owner/repo", 1)
+	Ok(t, err)
+	Equals(t, 0, len(plans))
+}
@@ -0,0 +1,42 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// readAuditReport reads the audit the audit step, or a custom run step, wrote
+// to path. It returns nil if there's no audit.
+func readAuditReport(path string) (*models.AuditReport, error) {
+	contents, err := os.ReadFile(path) // nolint: gosec
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var report models.AuditReport
+	if err := json.Unmarshal(contents, &report); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+// auditReportFor returns the audit of the providers and modules of the
+// project described by ctx, or nil if its workflow doesn't audit them.
+func auditReportFor(ctx command.ProjectContext, absPath string) *models.AuditReport {
+	report, err := readAuditReport(filepath.Join(absPath, ctx.GetAuditFileName()))
+	if err != nil {
+		ctx.Log.Warn("unable to read audit findings: %s", err)
+		return nil
+	}
+	return report
+}
@@ -0,0 +1,38 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestReadAuditReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "default-audit.json")
+	Ok(t, os.WriteFile(path, []byte(`{"findings":[{"kind":"provider","source":"registry.terraform.io/hashicorp/aws","version":"4.0.0","latest_version":"5.31.0","advisories":[{"id":"CVE-2023-1234","severity":"high"}]}],"notes":["note"]}`), 0600))
+	report, err := readAuditReport(path)
+	Ok(t, err)
+	Equals(t, &models.AuditReport{
+		Findings: []models.AuditFinding{{
+			Kind:          "provider",
+			Source:        "registry.terraform.io/hashicorp/aws",
+			Version:       "4.0.0",
+			LatestVersion: "5.31.0",
+			Advisories:    []models.AuditAdvisory{{ID: "CVE-2023-1234", Severity: "high"}},
+		}},
+		Notes: []string{"note"},
+	}, report)
+
+	Ok(t, os.WriteFile(path, []byte(`{"findings":{}}`), 0600))
+	_, err = readAuditReport(path)
+	ErrContains(t, "cannot unmarshal object", err)
+
+	report, err = readAuditReport(filepath.Join(t.TempDir(), "default-audit.json"))
+	Ok(t, err)
+	Assert(t, report == nil, "expected no audit, got %v", report)
+}
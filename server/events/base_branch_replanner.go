@@ -0,0 +1,242 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+	"github.com/runatlantis/atlantis/server/core/db"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// OpenPullsLister lists the open pull requests of a repo.
+type OpenPullsLister interface {
+	// ListOpenPulls returns the open pull requests of repo getting merged
+	// into baseBranch.
+	ListOpenPulls(logger logging.SimpleLogging, repo models.Repo, baseBranch string) ([]*github.PullRequest, error)
+}
+
+// BaseBranchReplanner plans open pull requests again when their base branch
+// advances with changes to the projects they planned, so nobody applies a
+// plan that's outdated. The stale plans are discarded right away and planned
+// again once no other push happened to the branch for Delay, so that merging
+// several pull requests in a row only plans again once.
+type BaseBranchReplanner struct {
+	PullsLister      OpenPullsLister
+	Parser           EventParsing
+	Database         db.Database
+	WorkingDir       WorkingDir
+	WorkingDirLocker WorkingDirLocker
+	// PlanJSONs are the stored JSON plans, deleted with the stale plans. Nil
+	// if they aren't exported.
+	PlanJSONs     *PlanJSONStore
+	VCSClient     vcs.Client
+	CommandRunner CommandRunner
+	Logger        logging.SimpleLogging
+	// Delay is how long to wait for other pushes before planning again.
+	Delay time.Duration
+
+	mutex sync.Mutex
+	// pending are the pull requests to plan again once Delay passed, by
+	// repo and branch.
+	pending map[string]*pendingReplan
+}
+
+// pendingReplan are the pull requests to plan again after pushes to a branch.
+type pendingReplan struct {
+	pulls map[int]replanPull
+	timer *time.Timer
+}
+
+type replanPull struct {
+	baseRepo models.Repo
+	headRepo models.Repo
+	pull     models.PullRequest
+	user     models.User
+}
+
+// basePush are the changes pushed to a base branch.
+type basePush struct {
+	branch string
+	commit string
+	// files are the files changed, unless allChanged is true because they
+	// aren't all known.
+	files      []string
+	allChanged bool
+}
+
+// BasePushed is called when commit was pushed to branch of repo, changing
+// files. allChanged is true if the files changed aren't all known. It
+// discards the plans of the open pull requests into branch that the push made
+// stale and plans them again once no other push happened for Delay.
+func (r *BaseBranchReplanner) BasePushed(repo models.Repo, branch string, commit string, files []string, allChanged bool) {
+	push := basePush{branch: branch, commit: commit, files: files, allChanged: allChanged}
+	logger := r.Logger.With("repo", repo.FullName)
+	ghPulls, err := r.PullsLister.ListOpenPulls(logger, repo, branch)
+	if err != nil {
+		logger.Err("unable to list the pull requests to plan again after %s advanced: %s", branch, err)
+		return
+	}
+	var stalePulls []replanPull
+	for _, ghPull := range ghPulls {
+		pull, baseRepo, headRepo, err := r.Parser.ParseGithubPull(logger, ghPull)
+		if err != nil {
+			logger.Err("unable to parse pull request %d: %s", ghPull.GetNumber(), err)
+			continue
+		}
+		if r.discardStalePlans(logger.With("pull", fmt.Sprint(pull.Num)), push, baseRepo, pull) {
+			stalePulls = append(stalePulls, replanPull{baseRepo: baseRepo, headRepo: headRepo, pull: pull, user: models.User{Username: ghPull.GetUser().GetLogin()}})
+		}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	key := fmt.Sprintf("%s/%s", repo.FullName, branch)
+	pending, ok := r.pending[key]
+	if !ok {
+		if len(stalePulls) == 0 {
+			return
+		}
+		if r.pending == nil {
+			r.pending = make(map[string]*pendingReplan)
+		}
+		pending = &pendingReplan{pulls: make(map[int]replanPull)}
+		r.pending[key] = pending
+		pending.timer = time.AfterFunc(r.Delay, func() {
+			r.mutex.Lock()
+			delete(r.pending, key)
+			r.mutex.Unlock()
+			r.replan(pending)
+		})
+	} else {
+		pending.timer.Reset(r.Delay)
+	}
+	for _, stalePull := range stalePulls {
+		pending.pulls[stalePull.pull.Num] = stalePull
+	}
+}
+
+// replan plans the pending pull requests again.
+func (r *BaseBranchReplanner) replan(pending *pendingReplan) {
+	var nums []int
+	for num := range pending.pulls {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	for _, num := range nums {
+		p := pending.pulls[num]
+		// Replans aren't part of the push that triggered them, they're
+		// correlated on their own.
+		logger := r.Logger.With(
+			logging.CorrelationIDKey, logging.NewCorrelationID(),
+			"repo", p.baseRepo.FullName,
+			"pull", fmt.Sprint(num),
+		)
+		r.CommandRunner.RunAutoplanCommand(logger, p.baseRepo, p.headRepo, p.pull, p.user)
+	}
+}
+
+// discardStalePlans discards the plans of pull that push made stale and
+// comments which. It returns true if any was.
+func (r *BaseBranchReplanner) discardStalePlans(logger logging.SimpleLogging, push basePush, baseRepo models.Repo, pull models.PullRequest) bool {
+	status, err := r.Database.GetPullStatus(pull)
+	if err != nil {
+		logger.Err("unable to get pull status: %s", err)
+		return false
+	}
+	if status == nil {
+		return false
+	}
+	stale := push.staleProjects(status.Projects)
+	if len(stale) == 0 {
+		return false
+	}
+	sort.Slice(stale, func(i, j int) bool {
+		return stale[i].RepoRelDir+"/"+stale[i].Workspace < stale[j].RepoRelDir+"/"+stale[j].Workspace
+	})
+	logger.Info("%s advanced to %s, discarding %d stale plans", push.branch, push.commit, len(stale))
+	for _, project := range stale {
+		r.discardPlan(logger, pull, project)
+	}
+	if err := r.VCSClient.CreateComment(logger, baseRepo, pull.Num, staleComment(push, stale), ""); err != nil {
+		logger.Warn("unable to comment: %s", err)
+	}
+	return true
+}
+
+// discardPlan deletes the planfile of project so it can't be applied and
+// marks it as discarded.
+func (r *BaseBranchReplanner) discardPlan(logger logging.SimpleLogging, pull models.PullRequest, project models.ProjectStatus) {
+	// The plan isn't deleted while a command runs in its directory, it's
+	// planned again after anyway.
+	unlockFn, err := r.WorkingDirLocker.TryLock(pull.BaseRepo.FullName, pull.Num, project.Workspace, project.RepoRelDir, project.ProjectName, command.Plan)
+	if err != nil {
+		logger.Warn("unable to lock dir %s workspace %s to discard its stale plan: %s", project.RepoRelDir, project.Workspace, err)
+	} else {
+		if err := r.WorkingDir.DeletePlan(logger, pull.BaseRepo, pull, project.Workspace, project.RepoRelDir, project.ProjectName); err != nil {
+			logger.Warn("unable to delete stale plan of dir %s workspace %s: %s", project.RepoRelDir, project.Workspace, err)
+		}
+		deletePlanJSON(logger, r.PlanJSONs, pull, project.RepoRelDir, project.Workspace, project.ProjectName)
+		unlockFn()
+	}
+	if err := r.Database.UpdateProjectStatus(pull, project.Workspace, project.RepoRelDir, models.DiscardedPlanStatus); err != nil {
+		logger.Warn("unable to update project status: %s", err)
+	}
+}
+
+// staleProjects returns the projects with unapplied plans that the push changed.
+func (p basePush) staleProjects(projects []models.ProjectStatus) []models.ProjectStatus {
+	var stale []models.ProjectStatus
+	for _, project := range projects {
+		switch project.Status {
+		case models.PlannedPlanStatus, models.PlannedNoChangesPlanStatus, models.PassedPolicyCheckStatus, models.ErroredPolicyCheckStatus:
+		default:
+			continue
+		}
+		if p.changed(project.RepoRelDir) {
+			stale = append(stale, project)
+		}
+	}
+	return stale
+}
+
+// changed returns true if the push changed files in dir.
+func (p basePush) changed(dir string) bool {
+	if p.allChanged {
+		return true
+	}
+	dir = path.Clean(dir)
+	if dir == "." {
+		return len(p.files) > 0
+	}
+	for _, file := range p.files {
+		if strings.HasPrefix(file, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func staleComment(push basePush, stale []models.ProjectStatus) string {
+	var lines []string
+	for _, project := range stale {
+		line := fmt.Sprintf("* dir: `%s` workspace: `%s`", project.RepoRelDir, project.Workspace)
+		if project.ProjectName != "" {
+			line = fmt.Sprintf("* project: `%s` dir: `%s` workspace: `%s`", project.ProjectName, project.RepoRelDir, project.Workspace)
+		}
+		lines = append(lines, line)
+	}
+	return fmt.Sprintf("**Warning**: The base branch `%s` advanced to %s with changes to these projects, their plans are stale and were **discarded**:\n\n%s\n\n"+
+		"They'll be planned again shortly, `apply` the new plans once they're commented.",
+		push.branch, push.commit, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,160 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/core/boltdb"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+type fakeOpenPullsLister struct {
+	pulls []*github.PullRequest
+}
+
+func (f fakeOpenPullsLister) ListOpenPulls(_ logging.SimpleLogging, _ models.Repo, _ string) ([]*github.PullRequest, error) {
+	return f.pulls, nil
+}
+
+type fakeAutoplanRunner struct {
+	mutex   sync.Mutex
+	planned []int
+	ran     chan struct{}
+}
+
+func (f *fakeAutoplanRunner) RunCommentCommand(logging.SimpleLogging, models.Repo, *models.Repo, *models.PullRequest, models.User, int, *CommentCommand) {
+}
+
+func (f *fakeAutoplanRunner) RunAutoplanCommand(_ logging.SimpleLogging, _ models.Repo, _ models.Repo, pull models.PullRequest, _ models.User) {
+	f.mutex.Lock()
+	f.planned = append(f.planned, pull.Num)
+	f.mutex.Unlock()
+	f.ran <- struct{}{}
+}
+
+func TestBasePush_StaleProjects(t *testing.T) {
+	projects := []models.ProjectStatus{
+		{RepoRelDir: ".", Workspace: "default", Status: models.PlannedPlanStatus},
+		{RepoRelDir: "modules/vpc", Workspace: "default", Status: models.PlannedPlanStatus},
+		{RepoRelDir: "modules/vpc-peering", Workspace: "default", Status: models.PlannedNoChangesPlanStatus},
+		{RepoRelDir: "envs/prod", Workspace: "default", Status: models.AppliedPlanStatus},
+	}
+	cases := map[string]struct {
+		push    basePush
+		expDirs []string
+	}{
+		"no files changed": {
+			push: basePush{},
+		},
+		"files in a project changed": {
+			push:    basePush{files: []string{"modules/vpc/main.tf"}},
+			expDirs: []string{".", "modules/vpc"},
+		},
+		"applied projects aren't stale": {
+			push:    basePush{files: []string{"envs/prod/main.tf"}},
+			expDirs: []string{"."},
+		},
+		"all files changed": {
+			push:    basePush{allChanged: true},
+			expDirs: []string{".", "modules/vpc", "modules/vpc-peering"},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var dirs []string
+			for _, project := range c.push.staleProjects(projects) {
+				dirs = append(dirs, project.RepoRelDir)
+			}
+			Equals(t, c.expDirs, dirs)
+		})
+	}
+}
+
+func TestBaseBranchReplanner_BasePushed(t *testing.T) {
+	RegisterMockTestingT(t)
+	database, err := boltdb.New(t.TempDir())
+	Ok(t, err)
+	t.Cleanup(func() {
+		database.Close()
+	})
+	logger := logging.NewNoopLogger(t)
+	parser := &EventParser{GithubUser: "atlantis"}
+	ghRepo := &github.Repository{
+		FullName: github.Ptr("owner/repo"),
+		Owner:    &github.User{Login: github.Ptr("owner")},
+		Name:     github.Ptr("repo"),
+		CloneURL: github.Ptr("https://github.com/owner/repo.git"),
+	}
+	ghPull := func(num int) *github.PullRequest {
+		return &github.PullRequest{
+			Number:  github.Ptr(num),
+			HTMLURL: github.Ptr("https://github.com/owner/repo/pull/1"),
+			User:    &github.User{Login: github.Ptr("user")},
+			Head:    &github.PullRequestBranch{SHA: github.Ptr("sha"), Ref: github.Ptr("feature"), Repo: ghRepo},
+			Base:    &github.PullRequestBranch{SHA: github.Ptr("base"), Ref: github.Ptr("main"), Repo: ghRepo},
+			State:   github.Ptr("open"),
+		}
+	}
+	ghPulls := []*github.PullRequest{ghPull(2), ghPull(1)}
+	for _, p := range ghPulls {
+		pull, _, _, err := parser.ParseGithubPull(logger, p)
+		Ok(t, err)
+		_, err = database.UpdatePullWithResults(pull, []command.ProjectResult{
+			{Command: command.Plan, RepoRelDir: "project", Workspace: "default", ProjectCommandOutput: command.ProjectCommandOutput{PlanSuccess: &models.PlanSuccess{}}},
+		})
+		Ok(t, err)
+	}
+
+	workingDir := NewMockWorkingDir()
+	vcsClient := vcsmocks.NewMockClient()
+	runner := &fakeAutoplanRunner{ran: make(chan struct{}, 2)}
+	replanner := &BaseBranchReplanner{
+		PullsLister:      fakeOpenPullsLister{pulls: ghPulls},
+		Parser:           parser,
+		Database:         database,
+		WorkingDir:       workingDir,
+		WorkingDirLocker: NewDefaultWorkingDirLocker(),
+		VCSClient:        vcsClient,
+		CommandRunner:    runner,
+		Logger:           logger,
+		Delay:            50 * time.Millisecond,
+	}
+	repo, err := parser.ParseGithubRepo(ghRepo)
+	Ok(t, err)
+
+	replanner.BasePushed(repo, "main", "abc123", []string{"project/main.tf"}, false)
+	// The stale plans are discarded right away.
+	workingDir.VerifyWasCalled(Times(2)).DeletePlan(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest](), Eq("default"), Eq("project"), Eq(""))
+	vcsClient.VerifyWasCalledOnce().CreateComment(Any[logging.SimpleLogging](), Any[models.Repo](), Eq(1), Eq(
+		"**Warning**: The base branch `main` advanced to abc123 with changes to these projects, their plans are stale and were **discarded**:\n\n"+
+			"* dir: `project` workspace: `default`\n\n"+
+			"They'll be planned again shortly, `apply` the new plans once they're commented."), Eq(""))
+	for _, p := range ghPulls {
+		pull, _, _, err := parser.ParseGithubPull(logger, p)
+		Ok(t, err)
+		status, err := database.GetPullStatus(pull)
+		Ok(t, err)
+		Equals(t, models.DiscardedPlanStatus, status.Projects[0].Status)
+	}
+
+	// A second push before the delay passed doesn't plan them again twice.
+	replanner.BasePushed(repo, "main", "def456", []string{"project/main.tf"}, false)
+	for range ghPulls {
+		select {
+		case <-runner.ran:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the pull requests to be planned again")
+		}
+	}
+	Equals(t, []int{1, 2}, runner.planned)
+}
@@ -0,0 +1,64 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"os/exec"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// pullBehavior returns the combined behavior of the rules the pull request of
+// ctx matches, reading the message of its head commit from the clone at
+// repoDir if a rule needs it.
+func pullBehavior(ctx *command.Context, rules []valid.BehaviorRule, repoDir string) valid.Behavior {
+	if len(rules) == 0 {
+		return valid.Behavior{}
+	}
+	var commitMessage string
+	for _, rule := range rules {
+		if rule.CommitMessage != nil {
+			commitMessage = headCommitMessage(ctx, repoDir)
+			break
+		}
+	}
+	behavior := valid.MatchBehaviorRules(rules, ctx.Pull.HeadBranch, commitMessage)
+	if behavior.SkipApplyWindow || behavior.AutoApply || len(behavior.SkipApplyRequirements) > 0 {
+		ctx.Log.Debug("pull request matches behavior rules: skip apply window: %t, skip apply requirements: [%s], auto apply: %t",
+			behavior.SkipApplyWindow, strings.Join(behavior.SkipApplyRequirements, ","), behavior.AutoApply)
+	}
+	return behavior
+}
+
+// headCommitMessage returns the message of the head commit of the pull
+// request of ctx, empty if it can't be read from the clone at repoDir.
+func headCommitMessage(ctx *command.Context, repoDir string) string {
+	logCmd := exec.Command("git", "log", "-1", "--format=%B", ctx.Pull.HeadCommit) // nolint: gosec
+	logCmd.Dir = repoDir
+	output, err := logCmd.Output()
+	if err != nil {
+		ctx.Log.Warn("unable to read the message of the head commit %s for the behavior rules: %s", ctx.Pull.HeadCommit, err)
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// cleanlyPlanned returns true if all the projects of pullStatus were planned,
+// and their policies passed, with changes to apply.
+func cleanlyPlanned(pullStatus models.PullStatus) bool {
+	changes := false
+	for _, project := range pullStatus.Projects {
+		switch project.Status {
+		case models.PlannedPlanStatus, models.PassedPolicyCheckStatus:
+			changes = true
+		case models.PlannedNoChangesPlanStatus:
+		default:
+			return false
+		}
+	}
+	return changes
+}
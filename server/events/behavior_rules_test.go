@@ -0,0 +1,62 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"os/exec"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestPullBehavior_CommitMessage(t *testing.T) {
+	repoDir := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		output, err := cmd.CombinedOutput()
+		Assert(t, err == nil, "git %s: %s", strings.Join(args, " "), output)
+		return strings.TrimSpace(string(output))
+	}
+	git("init", "--initial-branch=main")
+	git("config", "--local", "user.email", "atlantisbot@runatlantis.io")
+	git("config", "--local", "user.name", "atlantisbot")
+	git("config", "--local", "commit.gpgsign", "false")
+	git("commit", "--allow-empty", "-m", "chore(deps): update aws provider")
+	headCommit := git("rev-parse", "HEAD")
+
+	ctx := &command.Context{
+		Log:  logging.NewNoopLogger(t),
+		Pull: models.PullRequest{HeadBranch: "renovate/aws", HeadCommit: headCommit},
+	}
+	rules := []valid.BehaviorRule{
+		{CommitMessage: regexp.MustCompile(`^chore\(deps\)`), AutoApply: true},
+		{Branch: regexp.MustCompile(`^hotfix/`), SkipApplyWindow: true},
+	}
+	Equals(t, valid.Behavior{AutoApply: true}, pullBehavior(ctx, rules, repoDir))
+
+	t.Log("the commit message is empty if it can't be read")
+	Equals(t, valid.Behavior{}, pullBehavior(ctx, rules, t.TempDir()))
+}
+
+func TestCleanlyPlanned(t *testing.T) {
+	status := func(statuses ...models.ProjectPlanStatus) models.PullStatus {
+		var pullStatus models.PullStatus
+		for _, s := range statuses {
+			pullStatus.Projects = append(pullStatus.Projects, models.ProjectStatus{Status: s})
+		}
+		return pullStatus
+	}
+	Equals(t, true, cleanlyPlanned(status(models.PlannedPlanStatus, models.PlannedNoChangesPlanStatus)))
+	Equals(t, true, cleanlyPlanned(status(models.PassedPolicyCheckStatus)))
+	Equals(t, false, cleanlyPlanned(status(models.PlannedNoChangesPlanStatus)))
+	Equals(t, false, cleanlyPlanned(status(models.PlannedPlanStatus, models.ErroredPolicyCheckStatus)))
+	Equals(t, false, cleanlyPlanned(status()))
+}
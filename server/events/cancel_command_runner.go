@@ -1,12 +1,31 @@
 package events
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
+	runtimemodels "github.com/runatlantis/atlantis/server/core/runtime/models"
 	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/logging"
 )
 
-const cancelComment = "Cancelled all queued operations and released working directory locks for this pull request.\n" +
-	"New operations can now be started. Currently running operations will continue to completion."
+// cancelGracePeriod is how long interrupted processes have to stop cleanly,
+// ex. for terraform to finish the operations in progress and release the
+// state lock, before they're killed.
+const cancelGracePeriod = 5 * time.Minute
+
+func cancelComment(interrupted int) string {
+	if interrupted > 0 {
+		return "Cancelled all queued operations for this pull request.\n" +
+			fmt.Sprintf("Interrupted %d running process(es), they're killed if they don't stop within %s. ", interrupted, cancelGracePeriod) +
+			"Their working directory locks are released once they exited, new operations can then be started."
+	}
+	return "Cancelled all queued operations and released working directory locks for this pull request.\n" +
+		"No commands were running.\nNew operations can now be started."
+}
 
 func NewCancelCommandRunner(
 	vcsClient vcs.Client,
@@ -21,6 +40,7 @@ func NewCancelCommandRunner(
 		PullUpdater:       pullUpdater,
 		WorkingDirLocker:  workingDirLocker,
 		SilenceNoProjects: silenceNoProjects,
+		Processes:         runtimemodels.RunningProcesses,
 	}
 }
 
@@ -30,37 +50,59 @@ type CancelCommandRunner struct {
 	PullUpdater       *PullUpdater
 	WorkingDirLocker  WorkingDirLocker
 	SilenceNoProjects bool
+	// Processes are the running processes that are interrupted.
+	Processes *runtimemodels.ProcessTracker
 }
 
 func (c *CancelCommandRunner) Run(ctx *command.Context, cmd *CommentCommand) {
-	if c.ProjectCmdRunner == nil {
-		ctx.Log.Err("ProjectCmdRunner is nil")
+	interrupted, err := c.CancelPull(ctx.Log, ctx.Pull)
+	if err != nil {
+		ctx.Log.Err(err.Error())
 		return
 	}
+	if err := c.VCSClient.CreateComment(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num, cancelComment(interrupted), ""); err != nil {
+		ctx.Log.Err("unable to comment: %s", err)
+	}
+}
+
+// CancelPull cancels the commands of pull: the queued operations won't run,
+// the running processes are interrupted and the working directory locks are
+// released. The locks of interrupted commands are released by the commands
+// once their processes exited, so no other command runs in their working
+// directory meanwhile. Only the pull's number and base repo's full name are
+// used. It returns how many processes were interrupted.
+func (c *CancelCommandRunner) CancelPull(log logging.SimpleLogging, pull models.PullRequest) (int, error) {
+	if c.ProjectCmdRunner == nil {
+		return 0, errors.New("ProjectCmdRunner is nil")
+	}
 
 	// Get the DefaultProjectCommandRunner to access the process tracker
 	defaultRunner, ok := c.ProjectCmdRunner.(*DefaultProjectCommandRunner)
 	if !ok {
-		ctx.Log.Err("ProjectCmdRunner is not a DefaultProjectCommandRunner")
-		return
+		return 0, errors.New("ProjectCmdRunner is not a DefaultProjectCommandRunner")
 	}
 
 	if defaultRunner.CancellationTracker == nil {
-		ctx.Log.Err("CancellationTracker is nil")
-		return
+		return 0, errors.New("CancellationTracker is nil")
 	}
 
 	// Cancel the entire pull request to prevent future execution order groups from running
-	defaultRunner.CancellationTracker.Cancel(ctx.Pull)
+	defaultRunner.CancellationTracker.Cancel(pull)
 
-	// Clean up working directory locks for this pull request
-	if defaultRunner.WorkingDirLocker != nil {
-		defaultRunner.WorkingDirLocker.UnlockByPull(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num)
-		ctx.Log.Debug("Released working directory locks for pull request")
+	// Interrupt the running terraform processes so they stop cleanly.
+	interrupted := 0
+	if c.Processes != nil {
+		interrupted = c.Processes.Interrupt(pull.BaseRepo.FullName, pull.Num, cancelGracePeriod)
 	}
 
-	ctx.Log.Info("Cancelled all queued operations and future execution groups for pull request; currently running operations will continue to completion")
-	if err := c.VCSClient.CreateComment(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num, cancelComment, ""); err != nil {
-		ctx.Log.Err("unable to comment: %s", err)
+	// Clean up working directory locks for this pull request
+	if interrupted > 0 {
+		log.Debug("Keeping the working directory locks of the interrupted commands until they exited")
+	} else if defaultRunner.WorkingDirLocker != nil {
+		defaultRunner.WorkingDirLocker.UnlockByPull(pull.BaseRepo.FullName, pull.Num)
+		log.Debug("Released working directory locks for pull request")
 	}
+
+	log.Info("Cancelled all queued operations and future execution groups for pull request %s#%d, interrupted %d running processes", pull.BaseRepo.FullName, pull.Num, interrupted)
+	return interrupted, nil
 }
@@ -0,0 +1,515 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/teambition/rrule-go"
+)
+
+// freezeCalendarTTL is how long the windows fetched from a freeze's iCalendar
+// are used before fetching it again.
+const freezeCalendarTTL = 5 * time.Minute
+
+// maxFreezeCalendarSize is the size of the largest iCalendar that's read.
+const maxFreezeCalendarSize = 10 << 20
+
+// ChangeFreezes blocks applies during the change freezes of the server-side
+// repo config. Users allowed to override a freeze can apply anyway with
+// apply --break-glass.
+type ChangeFreezes struct {
+	// GlobalCfg is the server-side repo config defining the freezes. It's
+	// replaced when the config is reloaded.
+	GlobalCfg  valid.GlobalCfg
+	HTTPClient *http.Client
+
+	mutex sync.Mutex
+	// calendars are the windows fetched from the iCalendars of freezes, by
+	// URL.
+	calendars map[string]freezeCalendar
+}
+
+type freezeCalendar struct {
+	windows []valid.FreezeWindow
+	fetched time.Time
+}
+
+// activeFreeze is a freeze blocking the apply of projects.
+type activeFreeze struct {
+	freeze valid.Freeze
+	// window is the active window of the freeze. It's nil if the windows
+	// couldn't be fetched, in which case the freeze is considered active.
+	window   *valid.FreezeWindow
+	err      error
+	projects []string
+}
+
+// freezeComment returns the comment explaining why applying projectCmds is
+// blocked by the active change freezes and whether it's blocked. If cmd broke
+// the glass and the user may override all the active freezes the apply isn't
+// blocked, and the comment records that the freezes were overridden. The
+// comment is "" if no freeze is active for projectCmds.
+func (f *ChangeFreezes) freezeComment(ctx *command.Context, cmd *CommentCommand, projectCmds []command.ProjectContext) (string, bool) {
+	active := f.activeFreezes(ctx.Log, ctx.Pull.BaseRepo.ID(), projectCmds)
+	if len(active) == 0 {
+		return "", false
+	}
+
+	canOverride := true
+	var lines, overrideUsers []string
+	for _, a := range active {
+		canOverride = canOverride && a.freeze.CanOverride(ctx.User.Username)
+		lines = append(lines, activeFreezeLine(a))
+		for _, user := range a.freeze.OverrideUsers {
+			if !slices.Contains(overrideUsers, user) {
+				overrideUsers = append(overrideUsers, user)
+			}
+		}
+	}
+	freezes := strings.Join(lines, "\n")
+
+	if cmd.BreakGlass && canOverride {
+		ctx.Log.Warn("user %q broke the glass to apply during change freezes", ctx.User.Username)
+		return fmt.Sprintf("**Change Freeze Overridden**\n\n%s broke the glass to apply during these change freezes:\n\n%s", ctx.User.Username, freezes), false
+	}
+
+	prefix := ""
+	if cmd.BreakGlass {
+		prefix = fmt.Sprintf("%s isn't allowed to override these change freezes.\n\n", ctx.User.Username)
+	}
+	override := "They can't be overridden."
+	if len(overrideUsers) > 0 {
+		override = fmt.Sprintf("In an emergency, %s can apply anyway by commenting the same `%s` command with `--%s`.", strings.Join(overrideUsers, ", "), command.Apply.String(), breakGlassFlagLong)
+	}
+	return fmt.Sprintf("%s**Change Freeze**\n\nApplying is blocked by these change freezes:\n\n%s\n\n%s", prefix, freezes, override), true
+}
+
+// projectFailure returns why applying the project is blocked by the active
+// change freezes, or "" if it isn't. It's checked before every apply, ex.
+// through the API or of previews, unless the freezes were overridden with
+// apply --break-glass.
+func (f *ChangeFreezes) projectFailure(ctx command.ProjectContext) string {
+	if ctx.FreezesOverridden {
+		return ""
+	}
+	active := f.activeFreezes(ctx.Log, ctx.Pull.BaseRepo.ID(), []command.ProjectContext{ctx})
+	if len(active) == 0 {
+		return ""
+	}
+	var lines []string
+	for _, a := range active {
+		lines = append(lines, activeFreezeLine(a))
+	}
+	return fmt.Sprintf("Applying is blocked by these change freezes:\n\n%s", strings.Join(lines, "\n"))
+}
+
+// activeFreezes returns the freezes of the repo with id repoID active for
+// projectCmds.
+func (f *ChangeFreezes) activeFreezes(logger logging.SimpleLogging, repoID string, projectCmds []command.ProjectContext) []activeFreeze {
+	now := time.Now()
+	var active []activeFreeze
+	for _, freeze := range f.GlobalCfg.Freezes {
+		if !freeze.MatchesRepo(repoID) {
+			continue
+		}
+		var projects []string
+		for _, projectCmd := range projectCmds {
+			if freeze.MatchesProject(projectCmd.ProjectName, projectCmd.RepoRelDir) {
+				projects = append(projects, projectCmdName(projectCmd))
+			}
+		}
+		if len(projects) == 0 {
+			continue
+		}
+		windows, err := f.windows(logger, freeze)
+		if err != nil {
+			logger.Err("unable to get the windows of change freeze %q: %s", freeze.Name, err)
+			active = append(active, activeFreeze{freeze: freeze, err: err, projects: projects})
+			continue
+		}
+		for _, window := range windows {
+			if window.Contains(now) {
+				active = append(active, activeFreeze{freeze: freeze, window: &window, projects: projects})
+				break
+			}
+		}
+	}
+	return active
+}
+
+// activeFreezeLine describes an active freeze in the freeze comments.
+func activeFreezeLine(a activeFreeze) string {
+	line := fmt.Sprintf("* **%s**", a.freeze.Name)
+	switch {
+	case a.err != nil:
+		line += ", its calendar couldn't be fetched"
+	case a.window.Summary != "":
+		line += fmt.Sprintf(" (%s) until %s", a.window.Summary, a.window.End.UTC().Format("2006-01-02 15:04 MST"))
+	default:
+		line += fmt.Sprintf(" until %s", a.window.End.UTC().Format("2006-01-02 15:04 MST"))
+	}
+	if a.freeze.Message != "" {
+		line += ": " + a.freeze.Message
+	}
+	return fmt.Sprintf("%s\n  Frozen: %s", line, strings.Join(a.projects, ", "))
+}
+
+// windows returns the windows of freeze. The windows of freezes defined by an
+// iCalendar are fetched at most every freezeCalendarTTL, and the last ones
+// fetched are used if fetching them fails.
+func (f *ChangeFreezes) windows(logger logging.SimpleLogging, freeze valid.Freeze) ([]valid.FreezeWindow, error) {
+	if freeze.ICalURL == "" {
+		return freeze.Windows, nil
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	calendar, ok := f.calendars[freeze.ICalURL]
+	if ok && time.Since(calendar.fetched) < freezeCalendarTTL {
+		return calendar.windows, nil
+	}
+	windows, err := f.fetchCalendar(freeze.ICalURL)
+	if err != nil {
+		if ok {
+			logger.Warn("unable to fetch the calendar of change freeze %q, using the one fetched at %s: %s", freeze.Name, calendar.fetched.Format(time.RFC3339), err)
+			return calendar.windows, nil
+		}
+		return nil, err
+	}
+	if f.calendars == nil {
+		f.calendars = make(map[string]freezeCalendar)
+	}
+	f.calendars[freeze.ICalURL] = freezeCalendar{windows: windows, fetched: time.Now()}
+	return windows, nil
+}
+
+func (f *ChangeFreezes) fetchCalendar(url string) ([]valid.FreezeWindow, error) {
+	client := f.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned %d", url, resp.StatusCode)
+	}
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxFreezeCalendarSize))
+	if err != nil {
+		return nil, err
+	}
+	return ParseFreezeCalendar(content)
+}
+
+// ParseFreezeCalendar returns the windows of the events of an iCalendar.
+// Cancelled events are ignored. Recurring events, with RRULE or RDATE, have
+// a window for each of their occurrences from the ongoing one until
+// freezeCalendarHorizon, except the ones excluded by EXDATE or cancelled, and
+// occurrences moved by another event with the same UID and a RECURRENCE-ID
+// take its window instead. Events lasting whole days are in UTC. Properties
+// that can't be parsed are errors so the freeze is considered active rather
+// than silently missed.
+func ParseFreezeCalendar(content []byte) ([]valid.FreezeWindow, error) {
+	return parseFreezeCalendar(content, time.Now())
+}
+
+func parseFreezeCalendar(content []byte, now time.Time) ([]valid.FreezeWindow, error) {
+	// Long lines are folded by starting their continuations with a space or
+	// a tab.
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxFreezeCalendarSize)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var events []*icalEvent
+	var event *icalEvent
+	for i, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name, params, _ := strings.Cut(name, ";")
+		switch strings.ToUpper(name) {
+		case "BEGIN":
+			if strings.EqualFold(value, "VEVENT") {
+				event = &icalEvent{line: i + 1}
+			}
+			continue
+		case "END":
+			if strings.EqualFold(value, "VEVENT") && event != nil {
+				events = append(events, event)
+				event = nil
+			}
+			continue
+		}
+		if event != nil {
+			if err := event.set(strings.ToUpper(name), params, value); err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+		}
+	}
+
+	// Occurrences moved or cancelled by another event are excluded from
+	// their recurring event.
+	moved := make(map[string][]time.Time)
+	for _, e := range events {
+		if e.recurrenceID != nil {
+			moved[e.uid] = append(moved[e.uid], *e.recurrenceID)
+		}
+	}
+	var windows []valid.FreezeWindow
+	for _, e := range events {
+		eventWindows, err := e.windows(now, moved[e.uid])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", e.line, err)
+		}
+		windows = append(windows, eventWindows...)
+	}
+	return windows, nil
+}
+
+// freezeCalendarHorizon is how far ahead the occurrences of recurring events
+// are expanded.
+const freezeCalendarHorizon = 366 * 24 * time.Hour
+
+// maxFreezeCalendarOccurrences is the most occurrences a recurring event is
+// expanded to, so a calendar can't exhaust the memory.
+const maxFreezeCalendarOccurrences = 10000
+
+// icalEvent is the properties of an iCalendar event used for freeze windows.
+type icalEvent struct {
+	// line is the line of the start of the event, for errors.
+	line      int
+	uid       string
+	summary   string
+	cancelled bool
+	start     time.Time
+	allDay    bool
+	end       time.Time
+	duration  time.Duration
+	rrule     string
+	rdates    []time.Time
+	exdates   []time.Time
+	// recurrenceID is the occurrence of the recurring event with the same
+	// UID the event replaces.
+	recurrenceID *time.Time
+}
+
+func (e *icalEvent) set(name string, params string, value string) error {
+	var err error
+	switch name {
+	case "UID":
+		e.uid = value
+	case "SUMMARY":
+		e.summary = strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+	case "STATUS":
+		e.cancelled = strings.EqualFold(value, "CANCELLED")
+	case "DTSTART":
+		if e.start, e.allDay, err = parseICalTime(value, params); err != nil {
+			return fmt.Errorf("DTSTART: %w", err)
+		}
+	case "DTEND":
+		if e.end, _, err = parseICalTime(value, params); err != nil {
+			return fmt.Errorf("DTEND: %w", err)
+		}
+	case "DURATION":
+		if e.duration, err = parseICalDuration(value); err != nil {
+			return fmt.Errorf("DURATION: %w", err)
+		}
+	case "RRULE":
+		if e.rrule != "" {
+			return errors.New("RRULE: events with more than one rule aren't supported")
+		}
+		// The rule is parsed again in the time zone of the event once it's
+		// known.
+		if _, err := rrule.StrToROption(value); err != nil {
+			return fmt.Errorf("RRULE: %w", err)
+		}
+		e.rrule = value
+	case "RDATE", "EXDATE":
+		if strings.Contains(strings.ToUpper(params), "VALUE=PERIOD") {
+			return fmt.Errorf("%s: periods aren't supported", name)
+		}
+		for v := range strings.SplitSeq(value, ",") {
+			t, _, err := parseICalTime(v, params)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			if name == "RDATE" {
+				e.rdates = append(e.rdates, t)
+			} else {
+				e.exdates = append(e.exdates, t)
+			}
+		}
+	case "RECURRENCE-ID":
+		t, _, err := parseICalTime(value, params)
+		if err != nil {
+			return fmt.Errorf("RECURRENCE-ID: %w", err)
+		}
+		e.recurrenceID = &t
+	}
+	return nil
+}
+
+// windows returns the windows of the event, excluding the occurrences of
+// excluded.
+func (e *icalEvent) windows(now time.Time, excluded []time.Time) ([]valid.FreezeWindow, error) {
+	if e.cancelled || e.start.IsZero() {
+		return nil, nil
+	}
+	duration := e.duration
+	switch {
+	case !e.end.IsZero():
+		duration = e.end.Sub(e.start)
+	case duration == 0 && e.allDay:
+		// All day events without an end last one day.
+		duration = 24 * time.Hour
+	}
+	if duration <= 0 {
+		return nil, nil
+	}
+	window := func(start time.Time) valid.FreezeWindow {
+		return valid.FreezeWindow{Start: start, End: start.Add(duration), Summary: e.summary}
+	}
+	if e.rrule == "" && len(e.rdates) == 0 {
+		if slices.ContainsFunc(excluded, e.start.Equal) {
+			return nil, nil
+		}
+		return []valid.FreezeWindow{window(e.start)}, nil
+	}
+
+	set := &rrule.Set{}
+	set.DTStart(e.start)
+	if e.rrule != "" {
+		option, err := rrule.StrToROptionInLocation(e.rrule, e.start.Location())
+		if err != nil {
+			return nil, fmt.Errorf("RRULE: %w", err)
+		}
+		option.Dtstart = e.start
+		r, err := rrule.NewRRule(*option)
+		if err != nil {
+			return nil, fmt.Errorf("RRULE: %w", err)
+		}
+		set.RRule(r)
+	} else {
+		// The start is the first occurrence of events with only RDATEs.
+		set.RDate(e.start)
+	}
+	for _, t := range e.rdates {
+		set.RDate(t)
+	}
+	for _, t := range append(slices.Clone(e.exdates), excluded...) {
+		set.ExDate(t)
+	}
+
+	var windows []valid.FreezeWindow
+	next := set.Iterator()
+	for start, ok := next(); ok && start.Before(now.Add(freezeCalendarHorizon)); start, ok = next() {
+		if !start.Add(duration).After(now) {
+			continue
+		}
+		if len(windows) == maxFreezeCalendarOccurrences {
+			return nil, fmt.Errorf("RRULE: the event has more than %d occurrences in the next year", maxFreezeCalendarOccurrences)
+		}
+		windows = append(windows, window(start))
+	}
+	return windows, nil
+}
+
+// parseICalDuration parses an iCalendar duration, ex. PT1H30M or P1D.
+func parseICalDuration(value string) (time.Duration, error) {
+	v := strings.TrimPrefix(value, "+")
+	if strings.HasPrefix(v, "-") {
+		return 0, fmt.Errorf("%q is negative", value)
+	}
+	v, ok := strings.CutPrefix(v, "P")
+	if !ok || v == "" {
+		return 0, fmt.Errorf("%q is not a valid duration", value)
+	}
+	units := map[byte]time.Duration{'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour, 'H': time.Hour, 'M': time.Minute, 'S': time.Second}
+	var duration time.Duration
+	inTime := false
+	for v != "" {
+		if v[0] == 'T' {
+			inTime = true
+			v = v[1:]
+			continue
+		}
+		i := strings.IndexFunc(v, func(r rune) bool { return r < '0' || r > '9' })
+		if i <= 0 {
+			return 0, fmt.Errorf("%q is not a valid duration", value)
+		}
+		n, err := strconv.Atoi(v[:i])
+		unit, known := units[v[i]]
+		// M is months outside of the time part, which durations don't have.
+		if err != nil || !known || inTime != (v[i] == 'H' || v[i] == 'M' || v[i] == 'S') {
+			return 0, fmt.Errorf("%q is not a valid duration", value)
+		}
+		duration += time.Duration(n) * unit
+		v = v[i+1:]
+	}
+	return duration, nil
+}
+
+// parseICalTime parses an iCalendar date, ex. 20251220, or date-time, ex.
+// 20251220T090000Z, with the parameters of its property, ex.
+// TZID=Europe/Paris. It returns true if it's a date.
+func parseICalTime(value string, params string) (time.Time, bool, error) {
+	loc := time.UTC
+	for _, param := range strings.Split(params, ";") {
+		if tzid, ok := strings.CutPrefix(param, "TZID="); ok {
+			var err error
+			if loc, err = time.LoadLocation(strings.Trim(tzid, `"`)); err != nil {
+				return time.Time{}, false, fmt.Errorf("%q is not a valid time zone", tzid)
+			}
+		}
+	}
+	if len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, time.UTC)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%q is not a valid date", value)
+		}
+		return t, true, nil
+	}
+	if v, ok := strings.CutSuffix(value, "Z"); ok {
+		value, loc = v, time.UTC
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%q is not a valid date-time", value)
+	}
+	return t, false, nil
+}
@@ -0,0 +1,282 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestParseFreezeCalendar(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	Ok(t, err)
+	calendar := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:Release\\, v2\r\n" +
+		"DTSTART:20251220T090000Z\r\n" +
+		"DTEND:20251220T170000Z\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:Black Fri\r\n" +
+		" day\r\n" +
+		"DTSTART;TZID=Europe/Paris:20251128T080000\r\n" +
+		"DTEND;TZID=Europe/Paris:20251128T200000\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:Holidays\r\n" +
+		"DTSTART;VALUE=DATE:20251224\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:Cancelled\r\n" +
+		"STATUS:CANCELLED\r\n" +
+		"DTSTART:20251201T090000Z\r\n" +
+		"DTEND:20251201T170000Z\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	windows, err := ParseFreezeCalendar([]byte(calendar))
+	Ok(t, err)
+	Equals(t, []valid.FreezeWindow{
+		{
+			Start:   time.Date(2025, 12, 20, 9, 0, 0, 0, time.UTC),
+			End:     time.Date(2025, 12, 20, 17, 0, 0, 0, time.UTC),
+			Summary: "Release, v2",
+		},
+		{
+			Start:   time.Date(2025, 11, 28, 8, 0, 0, 0, paris),
+			End:     time.Date(2025, 11, 28, 20, 0, 0, 0, paris),
+			Summary: "Black Friday",
+		},
+		{
+			Start:   time.Date(2025, 12, 24, 0, 0, 0, 0, time.UTC),
+			End:     time.Date(2025, 12, 25, 0, 0, 0, 0, time.UTC),
+			Summary: "Holidays",
+		},
+	}, windows)
+
+	_, err = ParseFreezeCalendar([]byte("BEGIN:VEVENT\nDTSTART:next-week\nEND:VEVENT\n"))
+	ErrEquals(t, `line 2: DTSTART: "next-week" is not a valid date-time`, err)
+}
+
+func TestParseFreezeCalendar_Recurring(t *testing.T) {
+	now := time.Date(2025, 12, 3, 12, 0, 0, 0, time.UTC)
+	calendar := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:fridays\r\n" +
+		"SUMMARY:Friday freeze\r\n" +
+		"DTSTART:20251107T160000Z\r\n" +
+		"DURATION:PT8H\r\n" +
+		"RRULE:FREQ=WEEKLY;BYDAY=FR;UNTIL=20251231T000000Z\r\n" +
+		"EXDATE:20251212T160000Z\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:fridays\r\n" +
+		"SUMMARY:Friday freeze, moved\r\n" +
+		"RECURRENCE-ID:20251219T160000Z\r\n" +
+		"DTSTART:20251218T160000Z\r\n" +
+		"DTEND:20251219T000000Z\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:fridays\r\n" +
+		"RECURRENCE-ID:20251226T160000Z\r\n" +
+		"STATUS:CANCELLED\r\n" +
+		"DTSTART:20251226T160000Z\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:month-end\r\n" +
+		"SUMMARY:Month end\r\n" +
+		"DTSTART;VALUE=DATE:20251130\r\n" +
+		"RDATE;VALUE=DATE:20251231\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	windows, err := parseFreezeCalendar([]byte(calendar), now)
+	Ok(t, err)
+	Equals(t, []valid.FreezeWindow{
+		{Start: time.Date(2025, 12, 5, 16, 0, 0, 0, time.UTC), End: time.Date(2025, 12, 6, 0, 0, 0, 0, time.UTC), Summary: "Friday freeze"},
+		{Start: time.Date(2025, 12, 18, 16, 0, 0, 0, time.UTC), End: time.Date(2025, 12, 19, 0, 0, 0, 0, time.UTC), Summary: "Friday freeze, moved"},
+		{Start: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC), End: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Summary: "Month end"},
+	}, windows)
+
+	for calendar, exp := range map[string]string{
+		"BEGIN:VEVENT\nDTSTART:20251107T160000Z\nRRULE:FREQ=SOMETIMES\nEND:VEVENT\n":                     "line 3: RRULE: ",
+		"BEGIN:VEVENT\nDTSTART:20251107T160000Z\nDURATION:1 hour\nEND:VEVENT\n":                          `line 3: DURATION: "1 hour" is not a valid duration`,
+		"BEGIN:VEVENT\nDTSTART:20251107T160000Z\nEXDATE:next-week\nEND:VEVENT\n":                         `line 3: EXDATE: "next-week" is not a valid date-time`,
+		"BEGIN:VEVENT\nDTSTART:20251107T160000Z\nRDATE;VALUE=PERIOD:20251108T160000Z/PT1H\nEND:VEVENT\n": "line 3: RDATE: periods aren't supported",
+		"BEGIN:VEVENT\nDTSTART:20251107T160000Z\nDURATION:PT1M\nRRULE:FREQ=MINUTELY\nEND:VEVENT\n":       "line 1: RRULE: the event has more than 10000 occurrences in the next year",
+	} {
+		_, err := parseFreezeCalendar([]byte(calendar), now)
+		ErrContains(t, exp, err)
+	}
+}
+
+func TestChangeFreezes_FreezeComment(t *testing.T) {
+	now := time.Now()
+	activeWindow := []valid.FreezeWindow{{Start: now.Add(-time.Hour), End: time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)}}
+	pastWindow := []valid.FreezeWindow{{Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)}}
+	projectCmds := []command.ProjectContext{
+		{ProjectName: "prod-db", RepoRelDir: "db", Workspace: "default"},
+		{RepoRelDir: "staging", Workspace: "default"},
+	}
+	cases := []struct {
+		description string
+		freezes     []valid.Freeze
+		breakGlass  bool
+		exp         string
+		expBlocked  bool
+	}{
+		{
+			description: "no freezes",
+		},
+		{
+			description: "inactive freeze",
+			freezes:     []valid.Freeze{{Name: "holidays", Windows: pastWindow}},
+		},
+		{
+			description: "other repo",
+			freezes:     []valid.Freeze{{Name: "holidays", Windows: activeWindow, Repos: []string{"github.com/org/other"}}},
+		},
+		{
+			description: "other projects",
+			freezes:     []valid.Freeze{{Name: "holidays", Windows: activeWindow, Projects: []string{"network"}}},
+		},
+		{
+			description: "active freeze",
+			freezes: []valid.Freeze{{
+				Name:     "holidays",
+				Windows:  activeWindow,
+				Projects: []string{"prod-*"},
+				Message:  "Happy holidays!",
+			}},
+			exp: "**Change Freeze**\n\nApplying is blocked by these change freezes:\n\n" +
+				"* **holidays** until 2099-01-01 00:00 UTC: Happy holidays!\n  Frozen: `prod-db`\n\n" +
+				"They can't be overridden.",
+			expBlocked: true,
+		},
+		{
+			description: "overridable freezes",
+			freezes: []valid.Freeze{
+				{Name: "holidays", Windows: activeWindow, OverrideUsers: []string{"alice"}},
+				{Name: "release", Windows: activeWindow, Projects: []string{"staging"}, OverrideUsers: []string{"alice", "bob"}},
+			},
+			exp: "**Change Freeze**\n\nApplying is blocked by these change freezes:\n\n" +
+				"* **holidays** until 2099-01-01 00:00 UTC\n  Frozen: `prod-db`, dir: `staging` workspace: `default`\n" +
+				"* **release** until 2099-01-01 00:00 UTC\n  Frozen: dir: `staging` workspace: `default`\n\n" +
+				"In an emergency, alice, bob can apply anyway by commenting the same `apply` command with `--break-glass`.",
+			expBlocked: true,
+		},
+		{
+			description: "break glass",
+			freezes:     []valid.Freeze{{Name: "holidays", Windows: activeWindow, OverrideUsers: []string{"alice"}}},
+			breakGlass:  true,
+			exp: "**Change Freeze Overridden**\n\nalice broke the glass to apply during these change freezes:\n\n" +
+				"* **holidays** until 2099-01-01 00:00 UTC\n  Frozen: `prod-db`, dir: `staging` workspace: `default`",
+		},
+		{
+			description: "break glass without override",
+			freezes: []valid.Freeze{
+				{Name: "holidays", Windows: activeWindow, OverrideUsers: []string{"alice"}},
+				{Name: "release", Windows: activeWindow, Projects: []string{"staging"}, OverrideUsers: []string{"bob"}},
+			},
+			breakGlass: true,
+			exp: "alice isn't allowed to override these change freezes.\n\n" +
+				"**Change Freeze**\n\nApplying is blocked by these change freezes:\n\n" +
+				"* **holidays** until 2099-01-01 00:00 UTC\n  Frozen: `prod-db`, dir: `staging` workspace: `default`\n" +
+				"* **release** until 2099-01-01 00:00 UTC\n  Frozen: dir: `staging` workspace: `default`\n\n" +
+				"In an emergency, alice, bob can apply anyway by commenting the same `apply` command with `--break-glass`.",
+			expBlocked: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			ctx := &command.Context{
+				Log:  logging.NewNoopLogger(t),
+				Pull: models.PullRequest{BaseRepo: models.Repo{FullName: "org/repo", VCSHost: models.VCSHost{Hostname: "github.com"}}},
+				User: models.User{Username: "alice"},
+			}
+			freezes := &ChangeFreezes{GlobalCfg: valid.GlobalCfg{Freezes: c.freezes}}
+			comment, blocked := freezes.freezeComment(ctx, &CommentCommand{Name: command.Apply, BreakGlass: c.breakGlass}, projectCmds)
+			Equals(t, c.exp, comment)
+			Equals(t, c.expBlocked, blocked)
+		})
+	}
+}
+
+func TestChangeFreezes_Calendar(t *testing.T) {
+	now := time.Now().UTC()
+	calendar := "BEGIN:VCALENDAR\n" +
+		"BEGIN:VEVENT\n" +
+		"SUMMARY:Release\n" +
+		"DTSTART:" + now.Add(-time.Hour).Format("20060102T150405Z") + "\n" +
+		"DTEND:20990101T000000Z\n" +
+		"END:VEVENT\n" +
+		"END:VCALENDAR\n"
+	requests := 0
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(calendar)) // nolint: errcheck
+	}))
+	defer server.Close()
+
+	ctx := &command.Context{
+		Log:  logging.NewNoopLogger(t),
+		Pull: models.PullRequest{BaseRepo: models.Repo{FullName: "org/repo", VCSHost: models.VCSHost{Hostname: "github.com"}}},
+		User: models.User{Username: "alice"},
+	}
+	projectCmds := []command.ProjectContext{{RepoRelDir: ".", Workspace: "default"}}
+	freezes := &ChangeFreezes{
+		GlobalCfg:  valid.GlobalCfg{Freezes: []valid.Freeze{{Name: "releases", ICalURL: server.URL}}},
+		HTTPClient: server.Client(),
+	}
+	exp := "**Change Freeze**\n\nApplying is blocked by these change freezes:\n\n" +
+		"* **releases** (Release) until 2099-01-01 00:00 UTC\n  Frozen: dir: `.` workspace: `default`\n\n" +
+		"They can't be overridden."
+
+	t.Run("fetches the calendar", func(t *testing.T) {
+		comment, blocked := freezes.freezeComment(ctx, &CommentCommand{Name: command.Apply}, projectCmds)
+		Equals(t, exp, comment)
+		Equals(t, true, blocked)
+		Equals(t, 1, requests)
+	})
+
+	t.Run("caches the calendar", func(t *testing.T) {
+		_, blocked := freezes.freezeComment(ctx, &CommentCommand{Name: command.Apply}, projectCmds)
+		Equals(t, true, blocked)
+		Equals(t, 1, requests)
+	})
+
+	t.Run("uses the last calendar when fetching fails", func(t *testing.T) {
+		fail = true
+		cal := freezes.calendars[server.URL]
+		cal.fetched = now.Add(-freezeCalendarTTL)
+		freezes.calendars[server.URL] = cal
+		comment, blocked := freezes.freezeComment(ctx, &CommentCommand{Name: command.Apply}, projectCmds)
+		Equals(t, exp, comment)
+		Equals(t, true, blocked)
+		Equals(t, 2, requests)
+	})
+
+	t.Run("blocks when the calendar can't be fetched", func(t *testing.T) {
+		freezes.calendars = nil
+		comment, blocked := freezes.freezeComment(ctx, &CommentCommand{Name: command.Apply}, projectCmds)
+		Equals(t, "**Change Freeze**\n\nApplying is blocked by these change freezes:\n\n"+
+			"* **releases**, its calendar couldn't be fetched\n  Frozen: dir: `.` workspace: `default`\n\n"+
+			"They can't be overridden.", comment)
+		Equals(t, true, blocked)
+	})
+}
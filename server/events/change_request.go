@@ -0,0 +1,49 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"fmt"
+
+	"github.com/runatlantis/atlantis/server/core/servicenow"
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// maxChangeRequestNotesLen is the maximum length of the apply output that's
+// added to the close notes of a change request.
+const maxChangeRequestNotesLen = 4000
+
+// ChangeRequestClient creates and closes the change requests that gate the
+// apply of projects with the change_request apply requirement.
+type ChangeRequestClient interface {
+	EnsureChangeRequest(correlationID string, shortDescription string, description string) (servicenow.ChangeRequest, error)
+	CloseChangeRequest(cr servicenow.ChangeRequest, successful bool, notes string) error
+}
+
+// ensureChangeRequest returns the change request for the project described by
+// ctx, creating it if this is the first time it's needed. Each project of a
+// pull request gets its own change request.
+func ensureChangeRequest(client ChangeRequestClient, ctx command.ProjectContext) (servicenow.ChangeRequest, error) {
+	project := ctx.ProjectName
+	if project == "" {
+		project = ctx.RepoRelDir
+	}
+	correlationID := fmt.Sprintf("atlantis/%s/%d/%s/%s", ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, project, ctx.Workspace)
+	shortDescription := fmt.Sprintf("Atlantis apply of %s (%s) in %s#%d", project, ctx.Workspace, ctx.Pull.BaseRepo.FullName, ctx.Pull.Num)
+	description := fmt.Sprintf("%s\n\nRequested by %s.\n%s", ctx.Pull.Title, ctx.User.Username, ctx.Pull.URL)
+	return client.EnsureChangeRequest(correlationID, shortDescription, description)
+}
+
+// closeChangeRequest closes the change request for the project described by
+// ctx with the result of the apply.
+func closeChangeRequest(client ChangeRequestClient, ctx command.ProjectContext, successful bool, output string) error {
+	cr, err := ensureChangeRequest(client, ctx)
+	if err != nil {
+		return err
+	}
+	if len(output) > maxChangeRequestNotesLen {
+		output = output[len(output)-maxChangeRequestNotesLen:]
+	}
+	return client.CloseChangeRequest(cr, successful, output)
+}
@@ -0,0 +1,328 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+var (
+	// conftestFindingRegex matches a failure or warning in conftest's default
+	// output, ex. "FAIL - plan.json - main - S3 buckets must be private".
+	conftestFindingRegex = regexp.MustCompile(`(?m)^(FAIL|WARN) - (?:[^\n]*? - )?([\w.]+) - (.+)$`)
+	// resourceAddressRegex matches a resource address in a finding's message,
+	// ex. aws_s3_bucket.logs or module.storage.aws_s3_bucket.logs["a"].
+	resourceAddressRegex = regexp.MustCompile(`\b([a-z][a-z0-9]*_[a-z0-9_]+)\.([A-Za-z_][\w-]*)`)
+)
+
+// CodeScanningClient uploads SARIF reports to the code scanning of the repo,
+// so findings show up in its Security tab and as annotations on pull requests.
+type CodeScanningClient interface {
+	UploadSarif(logger logging.SimpleLogging, repo models.Repo, pullNum int, commitSHA string, sarif []byte) error
+}
+
+// conftestFinding is a failure or warning reported by conftest.
+type conftestFinding struct {
+	Level     string
+	Namespace string
+	Message   string
+}
+
+// conftestJSONResult is a result in conftest's json output.
+type conftestJSONResult struct {
+	Namespace string `json:"namespace"`
+	Failures  []struct {
+		Msg string `json:"msg"`
+	} `json:"failures"`
+	Warnings []struct {
+		Msg string `json:"msg"`
+	} `json:"warnings"`
+}
+
+// parseConftestFindings returns the failures and warnings in the output of
+// conftest, in its default or json output format.
+func parseConftestFindings(output string) []conftestFinding {
+	var findings []conftestFinding
+	var results []conftestJSONResult
+	if err := json.Unmarshal([]byte(output), &results); err == nil {
+		for _, result := range results {
+			for _, failure := range result.Failures {
+				findings = append(findings, conftestFinding{Level: "error", Namespace: result.Namespace, Message: failure.Msg})
+			}
+			for _, warning := range result.Warnings {
+				findings = append(findings, conftestFinding{Level: "warning", Namespace: result.Namespace, Message: warning.Msg})
+			}
+		}
+		return findings
+	}
+	for _, match := range conftestFindingRegex.FindAllStringSubmatch(output, -1) {
+		level := "error"
+		if match[1] == "WARN" {
+			level = "warning"
+		}
+		findings = append(findings, conftestFinding{Level: level, Namespace: match[2], Message: strings.TrimSpace(match[3])})
+	}
+	return findings
+}
+
+type sarifLog struct {
+	Version string `json:"version"`
+	Schema  string `json:"$schema"`
+	// Runs are sarifRuns and the unparsed runs of reports.
+	Runs []any `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool              sarifTool              `json:"tool"`
+	AutomationDetails sarifAutomationDetails `json:"automationDetails"`
+	Results           []sarifResult          `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifAutomationDetails struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// sarifCategory returns the code scanning category of the project's
+// analyses, so uploading the findings of a project doesn't replace the ones
+// of other projects.
+func sarifCategory(ctx command.ProjectContext) string {
+	if ctx.ProjectName != "" {
+		return fmt.Sprintf("atlantis/%s/", ctx.ProjectName)
+	}
+	return fmt.Sprintf("atlantis/%s/%s/", ctx.RepoRelDir, ctx.Workspace)
+}
+
+// conftestSarifRun converts the findings of the policy sets into a SARIF run.
+// Each finding is located at the resource its message mentions, or at the
+// project's first Terraform file if it can't be located.
+func conftestSarifRun(ctx command.ProjectContext, absPath string, policySetResults []models.PolicySetResult) sarifRun {
+	locator := newResourceLocator(ctx.RepoRelDir, absPath)
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "conftest",
+			InformationURI: "https://www.conftest.dev",
+			Rules:          []sarifRule{},
+		}},
+		AutomationDetails: sarifAutomationDetails{ID: sarifCategory(ctx)},
+		Results:           []sarifResult{},
+	}
+	rules := make(map[string]bool)
+	for _, policySet := range policySetResults {
+		for _, finding := range parseConftestFindings(policySet.PolicyOutput) {
+			location, ok := locator.locate(finding.Message)
+			if !ok {
+				continue
+			}
+			ruleID := fmt.Sprintf("%s/%s", policySet.PolicySetName, finding.Namespace)
+			if !rules[ruleID] {
+				rules[ruleID] = true
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+					ID:               ruleID,
+					ShortDescription: sarifMessage{Text: fmt.Sprintf("Policy %s of policy set %s", finding.Namespace, policySet.PolicySetName)},
+				})
+			}
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    ruleID,
+				Level:     finding.Level,
+				Message:   sarifMessage{Text: finding.Message},
+				Locations: []sarifLocation{location},
+			})
+		}
+	}
+	return run
+}
+
+// resourceLocator finds where resources are declared in a project.
+type resourceLocator struct {
+	// files are the project's Terraform files relative to the repo root and
+	// their lines, sorted by path.
+	files []string
+	lines map[string][]string
+}
+
+func newResourceLocator(repoRelDir string, absPath string) resourceLocator {
+	locator := resourceLocator{lines: make(map[string][]string)}
+	paths, _ := filepath.Glob(filepath.Join(absPath, "*.tf"))
+	sort.Strings(paths)
+	for _, path := range paths {
+		contents, err := os.ReadFile(path) // nolint: gosec
+		if err != nil {
+			continue
+		}
+		file := filepath.ToSlash(filepath.Join(repoRelDir, filepath.Base(path)))
+		locator.files = append(locator.files, file)
+		locator.lines[file] = strings.Split(string(contents), "\n")
+	}
+	return locator
+}
+
+// locate returns the location of the declaration of the first resource
+// mentioned in message, or of the project's first Terraform file. It returns
+// false if the project has no Terraform files.
+func (l resourceLocator) locate(message string) (sarifLocation, bool) {
+	if len(l.files) == 0 {
+		return sarifLocation{}, false
+	}
+	for _, match := range resourceAddressRegex.FindAllStringSubmatch(message, -1) {
+		declaration := fmt.Sprintf(`resource "%s" "%s"`, match[1], match[2])
+		for _, file := range l.files {
+			for i, line := range l.lines[file] {
+				if strings.HasPrefix(strings.TrimSpace(line), declaration) {
+					return newSarifLocation(file, i+1), true
+				}
+			}
+		}
+	}
+	return newSarifLocation(l.files[0], 1), true
+}
+
+func newSarifLocation(file string, line int) sarifLocation {
+	return sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: file},
+		Region:           sarifRegion{StartLine: line},
+	}}
+}
+
+// readSarifReport reads the SARIF report written to path by a custom run
+// step, ex. by tfsec or trivy, and makes its locations relative to the repo
+// root. The report's runs are returned unparsed so no details are lost.
+// Runs without a category are put in the project's.
+func readSarifReport(ctx command.ProjectContext, repoDir string, absPath string, path string) ([]map[string]any, error) {
+	contents, err := os.ReadFile(path) // nolint: gosec
+	if err != nil {
+		return nil, err
+	}
+	var report struct {
+		Runs []map[string]any `json:"runs"`
+	}
+	if err := json.Unmarshal(contents, &report); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for _, run := range report.Runs {
+		if _, ok := run["automationDetails"]; !ok {
+			run["automationDetails"] = map[string]any{"id": sarifCategory(ctx)}
+		}
+		results, _ := run["results"].([]any)
+		for _, result := range results {
+			result, _ := result.(map[string]any)
+			locations, _ := result["locations"].([]any)
+			for _, location := range locations {
+				location, _ := location.(map[string]any)
+				physical, _ := location["physicalLocation"].(map[string]any)
+				artifact, _ := physical["artifactLocation"].(map[string]any)
+				if uri, ok := artifact["uri"].(string); ok {
+					artifact["uri"] = repoRelativeURI(ctx.RepoRelDir, repoDir, absPath, uri)
+				}
+			}
+		}
+	}
+	return report.Runs, nil
+}
+
+// repoRelativeURI returns uri relative to the repo root. Relative URIs are
+// relative to the project's directory, where the run step ran.
+func repoRelativeURI(repoRelDir string, repoDir string, absPath string, uri string) string {
+	path := uri
+	if parsed, err := url.Parse(uri); err == nil && parsed.Scheme == "file" {
+		path = parsed.Path
+	} else if err == nil && parsed.Scheme != "" {
+		return uri
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(absPath, path)
+	}
+	rel, err := filepath.Rel(repoDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return uri
+	}
+	return filepath.ToSlash(rel)
+}
+
+// uploadSarif uploads the policy check findings of the project described by
+// ctx, and the SARIF report a custom run step wrote to its SARIFFILE, to the
+// code scanning of the repo. Failures are logged, they don't fail the policy
+// check.
+func uploadSarif(client CodeScanningClient, ctx command.ProjectContext, repoDir string, absPath string, policySetResults []models.PolicySetResult) {
+	if client == nil || ctx.Pull.BaseRepo.VCSHost.Type != models.Github {
+		return
+	}
+	var runs []any
+	if !ctx.CustomPolicyCheck && len(policySetResults) > 0 {
+		runs = append(runs, conftestSarifRun(ctx, absPath, policySetResults))
+	}
+	reportRuns, err := readSarifReport(ctx, repoDir, absPath, filepath.Join(absPath, ctx.GetSarifFileName()))
+	if err != nil && !os.IsNotExist(err) {
+		ctx.Log.Warn("unable to read sarif report: %s", err)
+	}
+	for _, run := range reportRuns {
+		runs = append(runs, run)
+	}
+	if len(runs) == 0 {
+		return
+	}
+	sarif, err := json.Marshal(sarifLog{Version: "2.1.0", Schema: sarifSchema, Runs: runs})
+	if err != nil {
+		ctx.Log.Warn("unable to build sarif report: %s", err)
+		return
+	}
+	if err := client.UploadSarif(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num, ctx.Pull.HeadCommit, sarif); err != nil {
+		ctx.Log.Warn("unable to upload sarif report to code scanning: %s", err)
+	}
+}
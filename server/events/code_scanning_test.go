@@ -0,0 +1,118 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+type fakeCodeScanningClient struct {
+	sarif []byte
+}
+
+func (f *fakeCodeScanningClient) UploadSarif(_ logging.SimpleLogging, _ models.Repo, _ int, _ string, sarif []byte) error {
+	f.sarif = sarif
+	return nil
+}
+
+func TestParseConftestFindings(t *testing.T) {
+	cases := map[string]struct {
+		output string
+		exp    []conftestFinding
+	}{
+		"default output": {
+			output: "WARN - <redacted plan file> - main - aws_s3_bucket.logs has no tags\n" +
+				"FAIL - <redacted plan file> - main - aws_s3_bucket.logs must be private - it's public\n\n" +
+				"2 tests, 0 passed, 1 warning, 1 failure, 0 exceptions",
+			exp: []conftestFinding{
+				{Level: "warning", Namespace: "main", Message: "aws_s3_bucket.logs has no tags"},
+				{Level: "error", Namespace: "main", Message: "aws_s3_bucket.logs must be private - it's public"},
+			},
+		},
+		"json output": {
+			output: `[{"filename":"default.json","namespace":"main","successes":1,"failures":[{"msg":"too many resources"}],"warnings":[]}]`,
+			exp:    []conftestFinding{{Level: "error", Namespace: "main", Message: "too many resources"}},
+		},
+		"passed": {
+			output: "1 test, 1 passed, 0 warnings, 0 failures, 0 exceptions",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			Equals(t, c.exp, parseConftestFindings(c.output))
+		})
+	}
+}
+
+func TestUploadSarif(t *testing.T) {
+	repoDir := t.TempDir()
+	absPath := filepath.Join(repoDir, "storage")
+	Ok(t, os.MkdirAll(absPath, 0700))
+	Ok(t, os.WriteFile(filepath.Join(absPath, "main.tf"), []byte("terraform {}\n\nresource \"aws_s3_bucket\" \"logs\" {\n}\n"), 0600))
+	Ok(t, os.WriteFile(filepath.Join(absPath, "default.sarif"), []byte(`{"runs":[{"tool":{"driver":{"name":"tfsec"}},"results":[`+
+		`{"ruleId":"aws-s3-enable-versioning","locations":[{"physicalLocation":{"artifactLocation":{"uri":"main.tf"}}}]}]}]}`), 0600))
+	ctx := command.ProjectContext{
+		Log:        logging.NewNoopLogger(t),
+		RepoRelDir: "storage",
+		Workspace:  "default",
+		Pull:       models.PullRequest{Num: 1, BaseRepo: models.Repo{VCSHost: models.VCSHost{Type: models.Github}}},
+	}
+	client := &fakeCodeScanningClient{}
+
+	uploadSarif(client, ctx, repoDir, absPath, []models.PolicySetResult{{
+		PolicySetName: "s3",
+		PolicyOutput: "FAIL - <redacted plan file> - main - aws_s3_bucket.logs must be private\n" +
+			"WARN - <redacted plan file> - main - missing tags\n",
+	}})
+
+	var sarif struct {
+		Version string
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string
+					Rules []sarifRule
+				}
+			}
+			AutomationDetails sarifAutomationDetails
+			Results           []sarifResult
+		}
+	}
+	Ok(t, json.Unmarshal(client.sarif, &sarif))
+	Equals(t, "2.1.0", sarif.Version)
+	Equals(t, 2, len(sarif.Runs))
+
+	conftest := sarif.Runs[0]
+	Equals(t, "conftest", conftest.Tool.Driver.Name)
+	Equals(t, "atlantis/storage/default/", conftest.AutomationDetails.ID)
+	Equals(t, []sarifRule{{ID: "s3/main", ShortDescription: sarifMessage{Text: "Policy main of policy set s3"}}}, conftest.Tool.Driver.Rules)
+	Equals(t, []sarifResult{
+		{RuleID: "s3/main", Level: "error", Message: sarifMessage{Text: "aws_s3_bucket.logs must be private"}, Locations: []sarifLocation{newSarifLocation("storage/main.tf", 3)}},
+		{RuleID: "s3/main", Level: "warning", Message: sarifMessage{Text: "missing tags"}, Locations: []sarifLocation{newSarifLocation("storage/main.tf", 1)}},
+	}, conftest.Results)
+
+	tfsec := sarif.Runs[1]
+	Equals(t, "tfsec", tfsec.Tool.Driver.Name)
+	Equals(t, "atlantis/storage/default/", tfsec.AutomationDetails.ID)
+	Equals(t, "storage/main.tf", tfsec.Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+}
+
+func TestUploadSarif_NoFindings(t *testing.T) {
+	client := &fakeCodeScanningClient{}
+	ctx := command.ProjectContext{
+		Log:       logging.NewNoopLogger(t),
+		Workspace: "default",
+		Pull:      models.PullRequest{BaseRepo: models.Repo{VCSHost: models.VCSHost{Type: models.Github}}},
+	}
+	uploadSarif(client, ctx, t.TempDir(), t.TempDir(), nil)
+	Assert(t, client.sarif == nil, "expected nothing uploaded, got %s", client.sarif)
+}
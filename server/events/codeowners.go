@@ -0,0 +1,320 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// CodeOwnersPaths are where the CODEOWNERS file of a repo is looked up, in
+// order. They're the locations GitHub and GitLab support.
+var CodeOwnersPaths = []string{".github/CODEOWNERS", ".gitlab/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// CodeOwnersClient gets what the codeowners_approved requirement needs from
+// the VCS host.
+type CodeOwnersClient interface {
+	GetFileContent(logger logging.SimpleLogging, repo models.Repo, branch string, fileName string) (bool, []byte, error)
+	// GetPullApprovers returns the usernames of the users currently
+	// approving pull.
+	GetPullApprovers(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest) ([]string, error)
+	GetTeamNamesForUser(logger logging.SimpleLogging, repo models.Repo, user models.User) ([]string, error)
+	GetModifiedFiles(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest) ([]string, error)
+}
+
+// CodeOwners is a parsed CODEOWNERS file.
+type CodeOwners struct {
+	sections []codeOwnersSection
+}
+
+// codeOwnersSection is a section of a GitLab CODEOWNERS file, ex.
+// "[Database] @dba". Files without sections have a single unnamed one.
+type codeOwnersSection struct {
+	name string
+	// optional is true if the section starts with a ^, its approval isn't
+	// required.
+	optional      bool
+	defaultOwners []string
+	rules         []codeOwnersRule
+}
+
+type codeOwnersRule struct {
+	pattern string
+	// dirOnly is true if the pattern ends with a /, it only matches
+	// directories and their contents.
+	dirOnly bool
+	owners  []string
+}
+
+// ParseCodeOwners parses the content of a CODEOWNERS file.
+func ParseCodeOwners(content []byte) (*CodeOwners, error) {
+	codeOwners := &CodeOwners{sections: []codeOwnersSection{{}}}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// Sections, ex. "^[Docs][2] @docs", may require more than one
+		// approval, which we ignore.
+		if header := strings.TrimPrefix(line, "^"); strings.HasPrefix(header, "[") {
+			end := strings.Index(header, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: section %q isn't closed", lineNum, line)
+			}
+			rest := header[end+1:]
+			if strings.HasPrefix(rest, "[") {
+				if count := strings.Index(rest, "]"); count >= 0 {
+					rest = rest[count+1:]
+				}
+			}
+			codeOwners.sections = append(codeOwners.sections, codeOwnersSection{
+				name:          header[1:end],
+				optional:      header != line,
+				defaultOwners: strings.Fields(rest),
+			})
+			continue
+		}
+		fields := strings.Fields(line)
+		pattern := fields[0]
+		rule := codeOwnersRule{owners: fields[1:]}
+		if strings.HasSuffix(pattern, "/") && pattern != "/" {
+			rule.dirOnly = true
+			pattern = strings.TrimSuffix(pattern, "/")
+		}
+		// Like .gitignore, patterns without a / before their end match at
+		// any depth.
+		if strings.HasPrefix(pattern, "/") {
+			pattern = strings.TrimPrefix(pattern, "/")
+		} else if !strings.Contains(pattern, "/") {
+			pattern = "**/" + pattern
+		}
+		if pattern == "" {
+			pattern = "**"
+		}
+		if !doublestar.ValidatePattern(pattern) {
+			return nil, fmt.Errorf("line %d: %q isn't a valid pattern", lineNum, fields[0])
+		}
+		section := &codeOwners.sections[len(codeOwners.sections)-1]
+		if len(rule.owners) == 0 {
+			rule.owners = section.defaultOwners
+		}
+		rule.pattern = pattern
+		section.rules = append(section.rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return codeOwners, nil
+}
+
+// Owners returns the owners of file, a path relative to the root of the
+// repo, by section name. The owners of a section are the ones of its last
+// rule matching file or one of its parent directories. Optional sections and
+// sections without a matching rule, or whose matching rule has no owners, are
+// omitted.
+func (c *CodeOwners) Owners(file string) map[string][]string {
+	file = path.Clean(file)
+	var dirs []string
+	for d := path.Dir(file); d != "."; d = path.Dir(d) {
+		dirs = append(dirs, d)
+	}
+
+	owners := make(map[string][]string)
+	for _, section := range c.sections {
+		if section.optional {
+			continue
+		}
+		var matched []string
+		for _, rule := range section.rules {
+			if rule.matches(dirs, file) {
+				matched = rule.owners
+			}
+		}
+		if len(matched) > 0 {
+			owners[section.name] = matched
+		}
+	}
+	return owners
+}
+
+// SectionOwners are the owners of files in a section of CODEOWNERS, one of
+// whom must approve changes to the files.
+type SectionOwners struct {
+	Section string
+	Owners  []string
+}
+
+// FilesOwners returns the owners of each of files, without duplicates, sorted
+// by section. Files without owners are ignored.
+func (c *CodeOwners) FilesOwners(files []string) []SectionOwners {
+	var all []SectionOwners
+	for _, file := range files {
+		sections := c.Owners(file)
+		for _, name := range slices.Sorted(maps.Keys(sections)) {
+			owners := SectionOwners{Section: name, Owners: sections[name]}
+			if !slices.ContainsFunc(all, func(o SectionOwners) bool {
+				return o.Section == owners.Section && slices.Equal(o.Owners, owners.Owners)
+			}) {
+				all = append(all, owners)
+			}
+		}
+	}
+	slices.SortStableFunc(all, func(a, b SectionOwners) int { return strings.Compare(a.Section, b.Section) })
+	return all
+}
+
+func (r codeOwnersRule) matches(dirs []string, file string) bool {
+	for _, d := range dirs {
+		if ok, _ := doublestar.Match(r.pattern, d); ok {
+			return true
+		}
+	}
+	if r.dirOnly {
+		return false
+	}
+	ok, _ := doublestar.Match(r.pattern, file)
+	return ok
+}
+
+// projectFiles returns the modified files of the pull request in the
+// project's directory. If none are, ex. the project was planned because a
+// module outside of it changed, all of the modified files are returned.
+func projectFiles(modifiedFiles []string, repoRelDir string) []string {
+	dir := path.Clean(repoRelDir)
+	if dir == "." {
+		return modifiedFiles
+	}
+	var files []string
+	for _, f := range modifiedFiles {
+		if strings.HasPrefix(path.Clean(f), dir+"/") {
+			files = append(files, f)
+		}
+	}
+	if len(files) == 0 {
+		return modifiedFiles
+	}
+	return files
+}
+
+// fileContentGetter gets the content of the files of repos.
+type fileContentGetter interface {
+	GetFileContent(logger logging.SimpleLogging, repo models.Repo, branch string, fileName string) (bool, []byte, error)
+}
+
+// fetchCodeOwners returns the parsed CODEOWNERS of branch, the first one found
+// of CodeOwnersPaths, or nil if it has none.
+func fetchCodeOwners(client fileContentGetter, logger logging.SimpleLogging, repo models.Repo, branch string) (*CodeOwners, error) {
+	for _, p := range CodeOwnersPaths {
+		found, content, err := client.GetFileContent(logger, repo, branch, p)
+		if err != nil {
+			return nil, fmt.Errorf("getting %s: %w", p, err)
+		}
+		if !found {
+			continue
+		}
+		codeOwners, err := ParseCodeOwners(content)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", p, err)
+		}
+		return codeOwners, nil
+	}
+	return nil, nil
+}
+
+// validateCodeOwnersApproval returns a failure if, for any of the project's
+// modified files and section of the base branch's CODEOWNERS with owners for
+// the file, none of them approved the pull request. Owners are users, ex. @alice, or teams,
+// ex. @org/team, and email owners are ignored.
+func validateCodeOwnersApproval(client CodeOwnersClient, ctx command.ProjectContext, cmd command.Name) (string, error) {
+	repo := ctx.Pull.BaseRepo
+	// The CODEOWNERS of the pull request itself can't be trusted since it
+	// could add its author.
+	codeOwners, err := fetchCodeOwners(client, ctx.Log, repo, ctx.Pull.BaseBranch)
+	if err != nil {
+		return "", err
+	}
+	if codeOwners == nil {
+		return fmt.Sprintf("Project requires an approval from its code owners but the repo has no CODEOWNERS file, the project can't run %s.", cmd), nil
+	}
+	modifiedFiles, err := client.GetModifiedFiles(ctx.Log, repo, ctx.Pull)
+	if err != nil {
+		return "", fmt.Errorf("getting modified files: %w", err)
+	}
+	sections := codeOwners.FilesOwners(projectFiles(modifiedFiles, ctx.RepoRelDir))
+	if len(sections) == 0 {
+		return fmt.Sprintf("Project requires an approval from its code owners but CODEOWNERS has no owners for %s, the project can't run %s.", ctx.RepoRelDir, cmd), nil
+	}
+
+	approvers, err := client.GetPullApprovers(ctx.Log, repo, ctx.Pull)
+	if err != nil {
+		return "", fmt.Errorf("getting approvers: %w", err)
+	}
+	approverTeams := make(map[string][]string)
+	var missing []string
+	for _, owners := range sections {
+		approved, err := ownerApproved(client, ctx, owners.Owners, approvers, approverTeams)
+		if err != nil {
+			return "", err
+		}
+		if !approved {
+			missing = append(missing, strings.Join(owners.Owners, ", "))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Sprintf("Pull request must be approved by a code owner of %s before running %s, missing an approval from: %s.", ctx.RepoRelDir, cmd, strings.Join(missing, "; ")), nil
+	}
+	return "", nil
+}
+
+// ownerApproved returns true if one of the approvers is one of owners or a
+// member of one of their teams. The teams of approvers are cached in
+// approverTeams.
+func ownerApproved(client CodeOwnersClient, ctx command.ProjectContext, owners []string, approvers []string, approverTeams map[string][]string) (bool, error) {
+	for _, owner := range owners {
+		if !strings.HasPrefix(owner, "@") {
+			continue
+		}
+		owner = strings.TrimPrefix(owner, "@")
+		isTeam := strings.Contains(owner, "/")
+		for _, approver := range approvers {
+			if !isTeam {
+				if strings.EqualFold(owner, approver) {
+					return true, nil
+				}
+				continue
+			}
+			teams, ok := approverTeams[approver]
+			if !ok {
+				var err error
+				teams, err = client.GetTeamNamesForUser(ctx.Log, ctx.Pull.BaseRepo, models.User{Username: approver})
+				if err != nil {
+					return false, fmt.Errorf("getting teams of %s: %w", approver, err)
+				}
+				approverTeams[approver] = teams
+			}
+			for _, team := range teams {
+				// GitHub teams are the slugs of the teams of the org
+				// while GitLab teams are the full paths of groups.
+				if strings.EqualFold(owner, team) || strings.EqualFold(owner, ctx.Pull.BaseRepo.Owner+"/"+team) {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
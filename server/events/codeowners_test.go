@@ -0,0 +1,79 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestCodeOwners_Owners(t *testing.T) {
+	codeOwners, err := events.ParseCodeOwners([]byte(`# Default owners.
+*                @platform
+
+/prod/           @org/sre @alice # Prod needs the SRE team.
+modules/         @modules
+/staging/*.tf    @staging
+/docs/**         @docs
+/sandbox/        docs@example.com
+/unowned/
+
+[Database] @dba
+/prod/db/
+/dev/db/ @dev-dba
+
+^[Security] @security
+/prod/
+`))
+	Ok(t, err)
+
+	cases := []struct {
+		file string
+		exp  map[string][]string
+	}{
+		{"main.tf", map[string][]string{"": {"@platform"}}},
+		{"dev/main.tf", map[string][]string{"": {"@platform"}}},
+		{"prod/main.tf", map[string][]string{"": {"@org/sre", "@alice"}}},
+		{"prod/db/main.tf", map[string][]string{"": {"@org/sre", "@alice"}, "Database": {"@dba"}}},
+		{"dev/db/main.tf", map[string][]string{"": {"@platform"}, "Database": {"@dev-dba"}}},
+		{"modules/vpc/main.tf", map[string][]string{"": {"@modules"}}},
+		{"infra/modules/vpc/main.tf", map[string][]string{"": {"@modules"}}},
+		{"staging/main.tf", map[string][]string{"": {"@staging"}}},
+		{"staging/README.md", map[string][]string{"": {"@platform"}}},
+		{"staging/envs/main.tf", map[string][]string{"": {"@platform"}}},
+		{"docs/examples/main.tf", map[string][]string{"": {"@docs"}}},
+		{"sandbox/main.tf", map[string][]string{"": {"docs@example.com"}}},
+		{"unowned/main.tf", map[string][]string{}},
+	}
+	for _, c := range cases {
+		t.Run(c.file, func(t *testing.T) {
+			Equals(t, c.exp, codeOwners.Owners(c.file))
+		})
+	}
+}
+
+func TestCodeOwners_FilesOwners(t *testing.T) {
+	codeOwners, err := events.ParseCodeOwners([]byte(`* @platform
+/staging/*.tf @staging
+
+[Database] @dba
+/staging/db/
+`))
+	Ok(t, err)
+	Equals(t, []events.SectionOwners{
+		{Section: "", Owners: []string{"@staging"}},
+		{Section: "", Owners: []string{"@platform"}},
+		{Section: "Database", Owners: []string{"@dba"}},
+	}, codeOwners.FilesOwners([]string{"staging/main.tf", "staging/vars.tf", "staging/README.md", "staging/db/main.tf"}))
+}
+
+func TestParseCodeOwners_Errors(t *testing.T) {
+	_, err := events.ParseCodeOwners([]byte("* @platform\n[Database @dba\n"))
+	ErrEquals(t, `line 2: section "[Database @dba" isn't closed`, err)
+
+	_, err = events.ParseCodeOwners([]byte("/prod/[ @platform\n"))
+	ErrEquals(t, `line 1: "/prod/[" isn't a valid pattern`, err)
+}
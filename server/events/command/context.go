@@ -55,4 +55,23 @@ type Context struct {
 
 	// Set true if there were any errors during the command execution
 	CommandHasErrors bool
+
+	// ParentPull is the pull request Pull is stacked on, if any.
+	ParentPull *models.ParentPull
+
+	// Progress is told about the progress of the command while it runs. It's
+	// nil if the progress isn't reported.
+	Progress ProgressReporter
+}
+
+// ProgressReporter is told about the progress of a command while it runs, ex.
+// to show it on the pull request.
+type ProgressReporter interface {
+	// SetProjects is called with the number of projects the command runs
+	// once they're known.
+	SetProjects(count int)
+	// ProjectStarted is called when a project starts running.
+	ProjectStarted(ctx ProjectContext)
+	// ProjectFinished is called when a project completed.
+	ProjectFinished(ctx ProjectContext)
 }
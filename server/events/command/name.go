@@ -35,6 +35,10 @@ const (
 	State
 	// Cancel is a command to cancel running plan or apply operations
 	Cancel
+	// Summary is a command to regenerate the summary of the current plans
+	Summary
+	// Preview is a command to apply a pull request in preview environments
+	Preview
 	// Adding more? Don't forget to update String() below
 )
 
@@ -53,6 +57,8 @@ var AllCommentCommands = []Name{
 	ApprovePolicies,
 	Import,
 	State,
+	Summary,
+	Preview,
 }
 
 // TitleString returns the string representation in title form.
@@ -82,6 +88,10 @@ func (c Name) String() string {
 		return "state"
 	case Cancel:
 		return "cancel"
+	case Summary:
+		return "summary"
+	case Preview:
+		return "preview"
 	}
 	return ""
 }
@@ -159,6 +169,10 @@ func ParseCommandName(name string) (Name, error) {
 		return State, nil
 	case "cancel":
 		return Cancel, nil
+	case "summary":
+		return Summary, nil
+	case "preview":
+		return Preview, nil
 	}
 	return -1, fmt.Errorf("unknown command name: %s", name)
 }
@@ -44,6 +44,8 @@ func TestName_String(t *testing.T) {
 		{command.Version, "version"},
 		{command.Import, "import"},
 		{command.State, "state"},
+		{command.Summary, "summary"},
+		{command.Preview, "preview"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.want, func(t *testing.T) {
@@ -182,6 +184,8 @@ func TestParseCommandName(t *testing.T) {
 		{command.Version, "version"},
 		{command.Import, "import"},
 		{command.State, "state"},
+		{command.Summary, "summary"},
+		{command.Preview, "preview"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -123,6 +123,60 @@ type ProjectContext struct {
 	DeleteSourceBranchOnMerge bool
 	// Repo locks mode: disabled, on plan or on apply
 	RepoLocksMode valid.RepoLocksMode
+	// ApplyWindow restricts when this project may be applied. Nil if applies
+	// are always allowed.
+	ApplyWindow *valid.ApplyWindow
+	// CostThreshold is how much a plan may increase the project's monthly
+	// cost before the pull request must be approved to apply it. Nil if the
+	// project has no cost threshold.
+	CostThreshold *float64
+	// CostApprovers are the users and teams, ex. @org/finops, one of whom
+	// must approve plans exceeding CostThreshold. Any approval is enough if
+	// it's empty.
+	CostApprovers []string
+	// DescriptionSections are the sections the description of the pull
+	// request must contain for the description requirement.
+	DescriptionSections []valid.DescriptionSection
+	// AutoApply is true if the pull request matches a behavior rule applying
+	// it once it's autoplanned without errors.
+	AutoApply bool
+	// NoChangesPlanComments is how the plan is commented if it has no changes,
+	// one of the valid.NoChangesPlanComments constants.
+	NoChangesPlanComments string
+	// ReuseUnchangedPlan is true if the existing plan of this project is
+	// reused, instead of planning again, when the project's content didn't
+	// change since it was generated.
+	ReuseUnchangedPlan bool
+	// Environment is the environment label of this project, ex. staging.
+	// Empty if the project doesn't declare one.
+	Environment string
+	// AgentPool is the pool of remote agents running this project's terraform
+	// commands. Empty if they're run by Atlantis.
+	AgentPool string
+	// ConcurrencyGroup is the group limiting how many terraform commands of
+	// its projects run at once. Empty if the project isn't in one.
+	ConcurrencyGroup string
+	// Owners are the users and teams to mention on this project's plans, ex.
+	// @org/team. Empty if the project doesn't declare any.
+	Owners []string
+	// TFCWorkspace is the Terraform Cloud workspace, ex.
+	// my-org/my-workspace, whose runs plan and apply this project. Empty if
+	// terraform is run by Atlantis.
+	TFCWorkspace string
+	// Preview is set if this project is a preview project, which is only run
+	// by atlantis preview.
+	Preview *valid.Preview
+	// PreviewApplySteps are the steps applying the preview environments of
+	// this project. They're only set for preview commands, which plan with
+	// Steps and then apply.
+	PreviewApplySteps []valid.Step
+	// PreviewPolicyCheckSteps are the steps checking the policies of the
+	// preview environments of this project before they're applied. They're
+	// only set for preview commands if policy checks are enabled.
+	PreviewPolicyCheckSteps []valid.Step
+	// FreezesOverridden is true if the user broke the glass to apply during
+	// the active change freezes.
+	FreezesOverridden bool
 	// RepoConfigFile
 	RepoConfigFile string
 	// UUID for atlantis logs
@@ -176,6 +230,46 @@ func (p ProjectContext) GetPolicyCheckResultFileName() string {
 	return fmt.Sprintf("%s-%s-policyout.json", projName, p.Workspace)
 }
 
+// GetSarifFileName returns the filename (not the path) custom run steps write
+// SARIF reports to, to upload them to code scanning.
+func (p ProjectContext) GetSarifFileName() string {
+	if p.ProjectName == "" {
+		return fmt.Sprintf("%s.sarif", p.Workspace)
+	}
+	projName := strings.ReplaceAll(p.ProjectName, "/", planfileSlashReplace)
+	return fmt.Sprintf("%s-%s.sarif", projName, p.Workspace)
+}
+
+// GetCostFileName returns the filename (not the path) the cost step, or a
+// custom run step, writes the plan's cost estimate to.
+func (p ProjectContext) GetCostFileName() string {
+	if p.ProjectName == "" {
+		return fmt.Sprintf("%s-cost.json", p.Workspace)
+	}
+	projName := strings.ReplaceAll(p.ProjectName, "/", planfileSlashReplace)
+	return fmt.Sprintf("%s-%s-cost.json", projName, p.Workspace)
+}
+
+// GetAuditFileName returns the filename (not the path) the audit step writes
+// the findings of the audit of the project's providers and modules to.
+func (p ProjectContext) GetAuditFileName() string {
+	if p.ProjectName == "" {
+		return fmt.Sprintf("%s-audit.json", p.Workspace)
+	}
+	projName := strings.ReplaceAll(p.ProjectName, "/", planfileSlashReplace)
+	return fmt.Sprintf("%s-%s-audit.json", projName, p.Workspace)
+}
+
+// GetPlanOutputFileName returns the filename (not the path) to store the plan
+// output the summary is generated from, so it can be summarized again.
+func (p ProjectContext) GetPlanOutputFileName() string {
+	if p.ProjectName == "" {
+		return fmt.Sprintf("%s-planout.json", p.Workspace)
+	}
+	projName := strings.ReplaceAll(p.ProjectName, "/", planfileSlashReplace)
+	return fmt.Sprintf("%s-%s-planout.json", projName, p.Workspace)
+}
+
 // Gets a unique identifier for the current pull request as a single string
 func (p ProjectContext) PullInfo() string {
 	normalizedOwner := strings.ReplaceAll(p.BaseRepo.Owner, "/", "-")
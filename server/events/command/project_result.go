@@ -16,6 +16,17 @@ type ProjectResult struct {
 	Workspace         string
 	ProjectName       string
 	SilencePRComments []string
+	// NoChangesPlanComments is how the plan is commented if it has no changes,
+	// one of the valid.NoChangesPlanComments constants.
+	NoChangesPlanComments string
+	// Environment is the environment label of the project, if it declares one.
+	Environment string
+	// Owners are the users and teams the project declares to mention on its
+	// plans.
+	Owners []string
+	// Stage is the stage of the dependency graph the project ran in, starting
+	// at 1. It's 0 if the projects weren't run by their dependencies.
+	Stage int
 }
 
 // ProjectCommandOutput is the output of a plan/policy_check/apply for a specific project.
@@ -28,6 +39,9 @@ type ProjectCommandOutput struct {
 	VersionSuccess     string
 	ImportSuccess      *models.ImportSuccess
 	StateRmSuccess     *models.StateRmSuccess
+	// StateLock is the Terraform state lock the command failed on, if it
+	// failed because the state is locked.
+	StateLock *models.StateLock
 }
 
 // CommitStatus returns the vcs commit status of this project result.
@@ -0,0 +1,204 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// CommandAuthzQuery is the rego document the command authorization policy
+// must define. It's an object with an allow boolean and an optional deny set
+// of reasons.
+const CommandAuthzQuery = "data.atlantis.authz"
+
+// commandAuthzTimeout bounds how long evaluating the policy may take.
+const commandAuthzTimeout = 30 * time.Second
+
+// CommandAuthzInput is the input the command authorization policy is
+// evaluated with.
+type CommandAuthzInput struct {
+	User    CommandAuthzUser    `json:"user"`
+	Repo    CommandAuthzRepo    `json:"repo"`
+	Pull    CommandAuthzPull    `json:"pull"`
+	Command CommandAuthzCommand `json:"command"`
+}
+
+type CommandAuthzUser struct {
+	Username string   `json:"username"`
+	Teams    []string `json:"teams"`
+}
+
+type CommandAuthzRepo struct {
+	FullName string `json:"full_name"`
+	Owner    string `json:"owner"`
+	Name     string `json:"name"`
+	VCSHost  string `json:"vcs_host"`
+}
+
+type CommandAuthzPull struct {
+	Num        int    `json:"num"`
+	Author     string `json:"author"`
+	State      string `json:"state"`
+	BaseBranch string `json:"base_branch"`
+	HeadBranch string `json:"head_branch"`
+	HeadCommit string `json:"head_commit"`
+	URL        string `json:"url"`
+	// Projects are the statuses of the projects planned on the pull request.
+	Projects []CommandAuthzProject `json:"projects"`
+}
+
+type CommandAuthzProject struct {
+	Name      string `json:"name"`
+	Dir       string `json:"dir"`
+	Workspace string `json:"workspace"`
+	Status    string `json:"status"`
+}
+
+type CommandAuthzCommand struct {
+	Name      string `json:"name"`
+	SubName   string `json:"subcommand"`
+	Project   string `json:"project"`
+	Dir       string `json:"dir"`
+	Workspace string `json:"workspace"`
+	// Flags are the extra arguments appended to the comment.
+	Flags             []string `json:"flags"`
+	Verbose           bool     `json:"verbose"`
+	AutoMergeDisabled bool     `json:"auto_merge_disabled"`
+	Failed            bool     `json:"failed"`
+}
+
+// NewCommandAuthzInput builds the policy input for user running cmd on pull.
+func NewCommandAuthzInput(user models.User, pull models.PullRequest, status *models.PullStatus, cmd *CommentCommand) CommandAuthzInput {
+	input := CommandAuthzInput{
+		User: CommandAuthzUser{Username: user.Username, Teams: user.Teams},
+		Repo: CommandAuthzRepo{
+			FullName: pull.BaseRepo.FullName,
+			Owner:    pull.BaseRepo.Owner,
+			Name:     pull.BaseRepo.Name,
+			VCSHost:  pull.BaseRepo.VCSHost.Hostname,
+		},
+		Pull: CommandAuthzPull{
+			Num:        pull.Num,
+			Author:     pull.Author,
+			State:      "open",
+			BaseBranch: pull.BaseBranch,
+			HeadBranch: pull.HeadBranch,
+			HeadCommit: pull.HeadCommit,
+			URL:        pull.URL,
+			Projects:   []CommandAuthzProject{},
+		},
+		Command: CommandAuthzCommand{
+			Name:              cmd.Name.String(),
+			SubName:           cmd.SubName,
+			Project:           cmd.ProjectName,
+			Dir:               cmd.RepoRelDir,
+			Workspace:         cmd.Workspace,
+			Flags:             cmd.Flags,
+			Verbose:           cmd.Verbose,
+			AutoMergeDisabled: cmd.AutoMergeDisabled,
+			Failed:            cmd.Failed,
+		},
+	}
+	if pull.State != models.OpenPullState {
+		input.Pull.State = "closed"
+	}
+	if input.User.Teams == nil {
+		input.User.Teams = []string{}
+	}
+	if input.Command.Flags == nil {
+		input.Command.Flags = []string{}
+	}
+	if status != nil {
+		for _, project := range status.Projects {
+			input.Pull.Projects = append(input.Pull.Projects, CommandAuthzProject{
+				Name:      project.ProjectName,
+				Dir:       project.RepoRelDir,
+				Workspace: project.Workspace,
+				Status:    project.Status.String(),
+			})
+		}
+	}
+	return input
+}
+
+// CommandAuthzDecision is the result of evaluating the policy.
+type CommandAuthzDecision struct {
+	Allow bool `json:"allow"`
+	// Deny are the reasons the command was denied.
+	Deny []string `json:"deny"`
+}
+
+// Allowed returns whether the command may run: the policy must allow it and
+// not deny it for any reason.
+func (d CommandAuthzDecision) Allowed() bool {
+	return d.Allow && len(d.Deny) == 0
+}
+
+// Reason explains why the command was denied.
+func (d CommandAuthzDecision) Reason() string {
+	if len(d.Deny) == 0 {
+		return "not allowed by the command authorization policy"
+	}
+	return strings.Join(d.Deny, "; ")
+}
+
+// CommandAuthorizer decides whether comment commands may run by evaluating a
+// rego policy with the opa binary.
+type CommandAuthorizer struct {
+	// OPAPath is the path to the opa binary.
+	OPAPath string
+	// PolicyPath is the path to the rego file or the directory of rego files
+	// defining CommandAuthzQuery.
+	PolicyPath string
+}
+
+// opaEvalOutput is the JSON output of opa eval.
+type opaEvalOutput struct {
+	Result []struct {
+		Expressions []struct {
+			Value json.RawMessage `json:"value"`
+		} `json:"expressions"`
+	} `json:"result"`
+}
+
+// Authorize evaluates the policy with input. An error is returned if the policy
+// couldn't be evaluated, in which case the command must not run.
+func (a *CommandAuthorizer) Authorize(input CommandAuthzInput) (CommandAuthzDecision, error) {
+	stdin, err := json.Marshal(input)
+	if err != nil {
+		return CommandAuthzDecision{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), commandAuthzTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, a.OPAPath, "eval", "--format", "json", "--stdin-input", "--data", a.PolicyPath, CommandAuthzQuery) // #nosec
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return CommandAuthzDecision{}, fmt.Errorf("evaluating command authorization policy: %s: %s", err, strings.TrimSpace(stderr.String()+stdout.String()))
+	}
+
+	var output opaEvalOutput
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return CommandAuthzDecision{}, fmt.Errorf("parsing opa output: %w", err)
+	}
+	if len(output.Result) == 0 || len(output.Result[0].Expressions) == 0 {
+		return CommandAuthzDecision{}, errors.New("command authorization policy doesn't define " + CommandAuthzQuery)
+	}
+	var decision CommandAuthzDecision
+	if err := json.Unmarshal(output.Result[0].Expressions[0].Value, &decision); err != nil {
+		return CommandAuthzDecision{}, fmt.Errorf("%s must be an object with an allow boolean and a deny set of strings: %w", CommandAuthzQuery, err)
+	}
+	return decision, nil
+}
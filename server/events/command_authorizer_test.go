@@ -0,0 +1,113 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// fakeOPA writes a script standing in for the opa binary. It saves its
+// arguments and input next to it and runs body.
+func fakeOPA(t *testing.T, body string) string {
+	dir := t.TempDir()
+	opaPath := filepath.Join(dir, "opa")
+	script := "#!/bin/sh\necho \"$@\" > " + filepath.Join(dir, "args") + "\ncat > " + filepath.Join(dir, "input") + "\n" + body + "\n"
+	Ok(t, os.WriteFile(opaPath, []byte(script), 0700)) // nolint: gosec
+	return opaPath
+}
+
+func TestCommandAuthorizer_Authorize(t *testing.T) {
+	input := events.NewCommandAuthzInput(
+		models.User{Username: "alice", Teams: []string{"platform"}},
+		models.PullRequest{Num: 1, Author: "bob", State: models.OpenPullState, BaseRepo: models.Repo{FullName: "owner/repo", Owner: "owner", Name: "repo"}},
+		&models.PullStatus{Projects: []models.ProjectStatus{{ProjectName: "prod", RepoRelDir: "prod", Workspace: "default", Status: models.PlannedPlanStatus}}},
+		&events.CommentCommand{Name: command.Apply, ProjectName: "prod"},
+	)
+
+	cases := []struct {
+		description string
+		body        string
+		exp         events.CommandAuthzDecision
+		expErr      string
+	}{
+		{
+			description: "allowed",
+			body:        `echo '{"result":[{"expressions":[{"value":{"allow":true},"text":"data.atlantis.authz"}]}]}'`,
+			exp:         events.CommandAuthzDecision{Allow: true},
+		},
+		{
+			description: "denied",
+			body:        `echo '{"result":[{"expressions":[{"value":{"allow":true,"deny":["only the platform team can apply prod"]}}]}]}'`,
+			exp:         events.CommandAuthzDecision{Allow: true, Deny: []string{"only the platform team can apply prod"}},
+		},
+		{
+			description: "undefined",
+			body:        `echo '{}'`,
+			expErr:      "command authorization policy doesn't define data.atlantis.authz",
+		},
+		{
+			description: "invalid",
+			body:        `echo '{"result":[{"expressions":[{"value":{"allow":"yes"}}]}]}'`,
+			expErr:      "data.atlantis.authz must be an object with an allow boolean and a deny set of strings: json: cannot unmarshal string into Go struct field CommandAuthzDecision.allow of type bool",
+		},
+		{
+			description: "error",
+			body:        "echo 'rego_parse_error' >&2; exit 1",
+			expErr:      "evaluating command authorization policy: exit status 1: rego_parse_error",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			opaPath := fakeOPA(t, c.body)
+			authorizer := events.CommandAuthorizer{OPAPath: opaPath, PolicyPath: "/etc/atlantis/authz.rego"}
+			decision, err := authorizer.Authorize(input)
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, c.exp, decision)
+
+			args, err := os.ReadFile(filepath.Join(filepath.Dir(opaPath), "args"))
+			Ok(t, err)
+			Equals(t, "eval --format json --stdin-input --data /etc/atlantis/authz.rego data.atlantis.authz", strings.TrimSpace(string(args)))
+			stdin, err := os.ReadFile(filepath.Join(filepath.Dir(opaPath), "input"))
+			Ok(t, err)
+			var actInput events.CommandAuthzInput
+			Ok(t, json.Unmarshal(stdin, &actInput))
+			Equals(t, input, actInput)
+		})
+	}
+}
+
+func TestNewCommandAuthzInput(t *testing.T) {
+	input := events.NewCommandAuthzInput(
+		models.User{Username: "alice"},
+		models.PullRequest{Num: 1, State: models.ClosedPullState, BaseBranch: "main"},
+		&models.PullStatus{Projects: []models.ProjectStatus{{RepoRelDir: "prod", Workspace: "default", Status: models.AppliedPlanStatus}}},
+		&events.CommentCommand{Name: command.Plan, RepoRelDir: "prod", Flags: []string{"-target=a"}},
+	)
+	Equals(t, "closed", input.Pull.State)
+	Equals(t, []string{}, input.User.Teams)
+	Equals(t, []events.CommandAuthzProject{{Dir: "prod", Workspace: "default", Status: "applied"}}, input.Pull.Projects)
+	Equals(t, events.CommandAuthzCommand{Name: "plan", Dir: "prod", Flags: []string{"-target=a"}}, input.Command)
+}
+
+func TestCommandAuthzDecision_Allowed(t *testing.T) {
+	Assert(t, events.CommandAuthzDecision{Allow: true}.Allowed(), "exp allowed")
+	Assert(t, !events.CommandAuthzDecision{}.Allowed(), "exp denied when not allowed")
+	denied := events.CommandAuthzDecision{Allow: true, Deny: []string{"a", "b"}}
+	Assert(t, !denied.Allowed(), "exp denied when there are reasons")
+	Equals(t, "a; b", denied.Reason())
+	Equals(t, "not allowed by the command authorization policy", events.CommandAuthzDecision{}.Reason())
+}
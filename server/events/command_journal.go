@@ -0,0 +1,305 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// InterruptedComment is commented when Atlantis restarted while running a
+// command that isn't retried, with the name of the command.
+const InterruptedComment = "Atlantis server restarted while running the %s command, so it may not have completed." +
+	" Check its result before running it again."
+
+// CommandJournal keeps the commands in progress in a file, so the ones that
+// are interrupted by a crash or a restart are retried, or failed, once
+// Atlantis restarts instead of leaving their pull requests pending.
+type CommandJournal struct {
+	// CloneCredentials add the credentials to the clone URLs of the repos of
+	// the interrupted commands, they aren't stored in the journal.
+	CloneCredentials CloneCredentials
+
+	path string
+
+	mutex   sync.Mutex
+	entries map[string]journalEntry
+	// interrupted are the commands the previous process didn't complete.
+	interrupted []journalEntry
+	// retrying are the interrupted commands that are run again, by
+	// retryKey.
+	retrying map[string]bool
+}
+
+type journalEntry struct {
+	Item      WorkItem
+	StartedAt time.Time
+	// Retry is whether the command was run again since it was interrupted.
+	// It isn't retried twice, ex. if it crashes Atlantis.
+	Retry bool
+	// Projects are the projects of the command with a pending commit status.
+	Projects []journalProject `json:",omitempty"`
+}
+
+// journalProject is a project with a pending commit status.
+type journalProject struct {
+	Command     command.Name
+	ProjectName string
+	RepoRelDir  string
+	Workspace   string
+}
+
+// NewCommandJournal returns a journal keeping the commands in progress in
+// path, loading the commands the previous process didn't complete.
+func NewCommandJournal(path string) (*CommandJournal, error) {
+	j := &CommandJournal{path: path, entries: map[string]journalEntry{}, retrying: map[string]bool{}}
+	serialized, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading the commands in progress: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(serialized, &j.interrupted); err != nil {
+			return nil, fmt.Errorf("parsing the commands in progress in %s: %w", path, err)
+		}
+	}
+	return j, nil
+}
+
+// Start records that item is in progress, until the returned function is
+// called. The credentials of the clone URLs of its repos aren't recorded.
+func (j *CommandJournal) Start(logger logging.SimpleLogging, item WorkItem) (func(), error) {
+	id := make([]byte, 8)
+	rand.Read(id) // nolint: errcheck
+	key := hex.EncodeToString(id)
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	retry := j.retrying[retryKey(item)]
+	delete(j.retrying, retryKey(item))
+	j.entries[key] = journalEntry{Item: item.WithoutCloneCredentials(), StartedAt: time.Now(), Retry: retry}
+	if err := j.write(); err != nil {
+		delete(j.entries, key)
+		return func() {}, err
+	}
+	return func() {
+		j.mutex.Lock()
+		defer j.mutex.Unlock()
+		delete(j.entries, key)
+		if err := j.write(); err != nil {
+			// The command is recovered once Atlantis restarts although it
+			// completed, which is only noisy.
+			logger.Warn("unable to record that %s completed: %s", item, err)
+		}
+	}, nil
+}
+
+// StartProject records that the project of ctx has a pending cmdName commit
+// status, until the returned function is called, so the status is failed if
+// the command is interrupted. The project is recorded with the command in
+// progress with the same correlation ID. It's a no-op if j is nil.
+func (j *CommandJournal) StartProject(ctx command.ProjectContext, cmdName command.Name) func() {
+	correlationID := logging.CorrelationID(ctx.Log)
+	if j == nil || correlationID == "" {
+		return func() {}
+	}
+	project := journalProject{Command: cmdName, ProjectName: ctx.ProjectName, RepoRelDir: ctx.RepoRelDir, Workspace: ctx.Workspace}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	key, ok := j.keyOf(correlationID)
+	if !ok {
+		return func() {}
+	}
+	entry := j.entries[key]
+	entry.Projects = append(entry.Projects, project)
+	j.entries[key] = entry
+	if err := j.write(); err != nil {
+		ctx.Log.Warn("unable to record that the %s commit status is pending, it won't be failed if Atlantis restarts: %s", cmdName, err)
+	}
+	return func() {
+		j.mutex.Lock()
+		defer j.mutex.Unlock()
+		entry, ok := j.entries[key]
+		if !ok {
+			return
+		}
+		entry.Projects = slices.DeleteFunc(entry.Projects, func(p journalProject) bool { return p == project })
+		j.entries[key] = entry
+		if err := j.write(); err != nil {
+			ctx.Log.Warn("unable to record that the %s commit status completed: %s", cmdName, err)
+		}
+	}
+}
+
+// keyOf returns the key of the command in progress with correlationID.
+func (j *CommandJournal) keyOf(correlationID string) (string, bool) {
+	for key, entry := range j.entries {
+		if entry.Item.CorrelationID == correlationID {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// takeInterrupted returns the commands the previous process didn't complete,
+// once.
+func (j *CommandJournal) takeInterrupted() ([]journalEntry, error) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	interrupted := j.interrupted
+	j.interrupted = nil
+	return interrupted, j.write()
+}
+
+// retry marks that item is run again, so it isn't retried if it's
+// interrupted again.
+func (j *CommandJournal) retry(item WorkItem) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.retrying[retryKey(item)] = true
+}
+
+func retryKey(item WorkItem) string {
+	return fmt.Sprintf("%s#%d %s", item.BaseRepo.FullName, item.PullNum, item)
+}
+
+// write replaces the file with the commands in progress, and the interrupted
+// ones until they're taken so they aren't lost if Atlantis restarts again.
+func (j *CommandJournal) write() error {
+	entries := slices.Clone(j.interrupted)
+	for _, entry := range j.entries {
+		entries = append(entries, entry)
+	}
+	slices.SortFunc(entries, func(a, b journalEntry) int { return a.StartedAt.Compare(b.StartedAt) })
+	serialized, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(j.path, serialized)
+}
+
+// RecoverInterruptedCommands runs again the plans the previous process didn't
+// complete, and fails the other commands it didn't complete since running
+// them again, ex. applies, isn't safe. It returns immediately.
+func (c *DefaultCommandRunner) RecoverInterruptedCommands() {
+	if c.Journal == nil {
+		return
+	}
+	interrupted, err := c.Journal.takeInterrupted()
+	if err != nil {
+		c.Logger.Warn("unable to record that the interrupted commands are recovered: %s", err)
+	}
+	for _, entry := range interrupted {
+		logger := c.Logger.With(
+			logging.CorrelationIDKey, entry.Item.CorrelationID,
+			"repo", entry.Item.BaseRepo.FullName,
+			"pull", strconv.Itoa(entry.Item.PullNum),
+		)
+		// Plans are run again unless they already were, or unless their repos
+		// can't be cloned without the credentials.
+		isPlan := entry.Item.Autoplan || (entry.Item.Command != nil && entry.Item.Command.Name == command.Plan)
+		retry := isPlan && !entry.Retry
+		if c.Journal.CloneCredentials != nil {
+			withCredentials, err := entry.Item.withCloneCredentials(c.Journal.CloneCredentials)
+			if err != nil {
+				logger.Warn("unable to add the VCS credentials to the clone URLs of %s: %s", entry.Item, err)
+				retry = false
+			} else {
+				entry.Item = withCredentials
+			}
+		}
+		item := entry.Item
+		if retry {
+			logger.Warn("running %s again since Atlantis restarted while running it", item)
+			c.Journal.retry(item)
+			go func() {
+				if err := runWorkItem(c, logger, item); err != nil {
+					logger.Err("unable to run %s: %s", item, err)
+				}
+			}()
+			continue
+		}
+		logger.Warn("failing %s since Atlantis restarted while running it", item)
+		go c.failInterrupted(logger, entry)
+	}
+}
+
+// failInterrupted fails the commit status of the interrupted command, if it
+// has one, and the pending statuses of its projects, and comments that it was
+// interrupted.
+func (c *DefaultCommandRunner) failInterrupted(logger logging.SimpleLogging, entry journalEntry) {
+	item := entry.Item
+	cmdName := command.Plan
+	if item.Command != nil {
+		cmdName = item.Command.Name
+	}
+	// Only the commands setting a pending combined status are failed.
+	statusName, hasStatus := cmdName, false
+	switch cmdName {
+	case command.Plan, command.Apply:
+		hasStatus = true
+	case command.ApprovePolicies:
+		statusName, hasStatus = command.PolicyCheck, true
+	}
+	failCombined := hasStatus && !c.SilenceVCSStatusNoProjects
+	if failCombined || len(entry.Projects) > 0 {
+		_, pull, err := c.ensureValidRepoMetadata(item.BaseRepo, item.HeadRepo, item.Pull, item.User, item.PullNum, logger)
+		if err != nil {
+			return
+		}
+		if failCombined {
+			if err := c.CommitStatusUpdater.UpdateCombined(logger, item.BaseRepo, pull, models.FailedCommitStatus, statusName); err != nil {
+				logger.Warn("unable to update %s commit status: %s", statusName, err)
+			}
+		}
+		for _, project := range entry.Projects {
+			ctx := command.ProjectContext{
+				Log:         logger,
+				BaseRepo:    item.BaseRepo,
+				Pull:        pull,
+				ProjectName: project.ProjectName,
+				RepoRelDir:  project.RepoRelDir,
+				Workspace:   project.Workspace,
+			}
+			if err := c.CommitStatusUpdater.UpdateProject(ctx, project.Command, models.FailedCommitStatus, "", nil); err != nil {
+				logger.Warn("unable to update %s commit status of project %s: %s", project.Command, ctx.ProjectName, err)
+			}
+		}
+	}
+	comment := fmt.Sprintf(InterruptedComment, cmdName.String())
+	if err := c.VCSClient.CreateComment(logger, item.BaseRepo, item.PullNum, comment, cmdName.String()); err != nil {
+		logger.Err("unable to comment: %s", err)
+	}
+}
+
+// writeFileAtomically replaces the file at path with data, so it's never left
+// half written if Atlantis is killed.
+func writeFileAtomically(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // nolint: errcheck
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() // nolint: errcheck
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,134 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/models/testdata"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// testCloneCredentials add the credentials of testdata.GithubRepo.
+type testCloneCredentials struct{}
+
+func (testCloneCredentials) WithCloneCredentials(repo models.Repo) (models.Repo, error) {
+	repo.CloneURL, repo.SanitizedCloneURL = testdata.GithubRepo.CloneURL, testdata.GithubRepo.SanitizedCloneURL
+	return repo, nil
+}
+
+func TestRecoverInterruptedCommands(t *testing.T) {
+	vcsClient := setup(t)
+	logger := logging.NewNoopLogger(t)
+	path := filepath.Join(t.TempDir(), "in-progress-commands.json")
+	journal, err := events.NewCommandJournal(path)
+	Ok(t, err)
+	_, err = journal.Start(logger, events.WorkItem{BaseRepo: testdata.GithubRepo, PullNum: 1, Command: &events.CommentCommand{Name: command.Apply}})
+	Ok(t, err)
+	done, err := journal.Start(logger, events.WorkItem{BaseRepo: testdata.GithubRepo, PullNum: 2, Command: &events.CommentCommand{Name: command.Unlock}})
+	Ok(t, err)
+	done()
+	serialized, err := os.ReadFile(path)
+	Ok(t, err)
+	Assert(t, !strings.Contains(string(serialized), "password"), "exp no credentials in the journal %s", serialized)
+
+	for _, num := range []int{1, 2} {
+		pull := github.PullRequest{Number: github.Ptr(num)}
+		modelPull := models.PullRequest{Num: num, BaseRepo: testdata.GithubRepo, State: models.OpenPullState}
+		When(githubGetter.GetPullRequest(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(num))).ThenReturn(&pull, nil)
+		When(eventParsing.ParseGithubPull(Any[logging.SimpleLogging](), Eq(&pull))).ThenReturn(modelPull, testdata.GithubRepo, testdata.GithubRepo, nil)
+	}
+
+	t.Log("interrupted applies are failed, completed commands aren't recovered")
+	ch.Journal, err = events.NewCommandJournal(path)
+	Ok(t, err)
+	ch.Journal.CloneCredentials = testCloneCredentials{}
+	ch.RecoverInterruptedCommands()
+	vcsClient.VerifyWasCalledEventually(Once(), 5*time.Second).CreateComment(
+		Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(1), Eq(fmt.Sprintf(events.InterruptedComment, "apply")), Eq("apply"))
+	commitUpdater.VerifyWasCalledEventually(Once(), 5*time.Second).UpdateCombined(
+		Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(models.PullRequest{Num: 1, BaseRepo: testdata.GithubRepo, State: models.OpenPullState}),
+		Eq(models.FailedCommitStatus), Eq(command.Apply))
+	vcsClient.VerifyWasCalled(Never()).CreateComment(
+		Any[logging.SimpleLogging](), Any[models.Repo](), Eq(2), Any[string](), Any[string]())
+
+	t.Log("the commands are only recovered once")
+	journal, err = events.NewCommandJournal(path)
+	Ok(t, err)
+	ch.Journal = journal
+	ch.RecoverInterruptedCommands()
+	vcsClient.VerifyWasCalledOnce().CreateComment(
+		Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(1), Any[string](), Any[string]())
+}
+
+func TestRecoverInterruptedCommands_PlanRetriedOnce(t *testing.T) {
+	vcsClient := setup(t)
+	path := filepath.Join(t.TempDir(), "in-progress-commands.json")
+	// The plan was already run again after being interrupted.
+	serialized, err := json.Marshal([]map[string]any{{
+		"Item":  events.WorkItem{BaseRepo: testdata.GithubRepo, PullNum: 3, Command: &events.CommentCommand{Name: command.Plan}},
+		"Retry": true,
+	}})
+	Ok(t, err)
+	Ok(t, os.WriteFile(path, serialized, 0600))
+	pull := github.PullRequest{Number: github.Ptr(3)}
+	modelPull := models.PullRequest{Num: 3, BaseRepo: testdata.GithubRepo, State: models.OpenPullState}
+	When(githubGetter.GetPullRequest(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(3))).ThenReturn(&pull, nil)
+	When(eventParsing.ParseGithubPull(Any[logging.SimpleLogging](), Eq(&pull))).ThenReturn(modelPull, testdata.GithubRepo, testdata.GithubRepo, nil)
+
+	ch.Journal, err = events.NewCommandJournal(path)
+	Ok(t, err)
+	ch.RecoverInterruptedCommands()
+	vcsClient.VerifyWasCalledEventually(Once(), 5*time.Second).CreateComment(
+		Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(3), Eq(fmt.Sprintf(events.InterruptedComment, "plan")), Eq("plan"))
+	commitUpdater.VerifyWasCalledEventually(Once(), 5*time.Second).UpdateCombined(
+		Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(modelPull), Eq(models.FailedCommitStatus), Eq(command.Plan))
+}
+
+func TestRecoverInterruptedCommands_FailsPendingProjects(t *testing.T) {
+	setup(t)
+	logger := logging.NewNoopLogger(t).With(logging.CorrelationIDKey, "0a1b2c3d-4e5f-6789-abcd-ef0123456789")
+	path := filepath.Join(t.TempDir(), "in-progress-commands.json")
+	journal, err := events.NewCommandJournal(path)
+	Ok(t, err)
+	_, err = journal.Start(logger, events.WorkItem{
+		BaseRepo:      testdata.GithubRepo,
+		PullNum:       4,
+		Command:       &events.CommentCommand{Name: command.Apply},
+		CorrelationID: logging.CorrelationID(logger),
+	})
+	Ok(t, err)
+	journal.StartProject(command.ProjectContext{Log: logger, ProjectName: "pending"}, command.Apply)
+	// Completed projects aren't failed.
+	done := journal.StartProject(command.ProjectContext{Log: logger, RepoRelDir: ".", Workspace: "default"}, command.Apply)
+	done()
+
+	pull := github.PullRequest{Number: github.Ptr(4)}
+	modelPull := models.PullRequest{Num: 4, BaseRepo: testdata.GithubRepo, State: models.OpenPullState}
+	When(githubGetter.GetPullRequest(Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(4))).ThenReturn(&pull, nil)
+	When(eventParsing.ParseGithubPull(Any[logging.SimpleLogging](), Eq(&pull))).ThenReturn(modelPull, testdata.GithubRepo, testdata.GithubRepo, nil)
+
+	ch.Journal, err = events.NewCommandJournal(path)
+	Ok(t, err)
+	ch.Journal.CloneCredentials = testCloneCredentials{}
+	ch.RecoverInterruptedCommands()
+	commitUpdater.VerifyWasCalledEventually(Once(), 5*time.Second).UpdateCombined(
+		Any[logging.SimpleLogging](), Eq(testdata.GithubRepo), Eq(modelPull), Eq(models.FailedCommitStatus), Eq(command.Apply))
+	ctx, _, _, _, _ := commitUpdater.VerifyWasCalledOnce().UpdateProject(
+		Any[command.ProjectContext](), Eq(command.Apply), Eq(models.FailedCommitStatus), Eq(""), Any[*command.ProjectCommandOutput]()).GetCapturedArguments()
+	Equals(t, "pending", ctx.ProjectName)
+	Equals(t, modelPull, ctx.Pull)
+}
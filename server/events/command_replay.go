@@ -0,0 +1,42 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"fmt"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// commandRequest returns cmd as it's recorded in the timeline. The confirm
+// token isn't recorded since it only confirms the apply it was issued for.
+func commandRequest(cmd *CommentCommand) *models.CommandRequest {
+	return &models.CommandRequest{
+		Name:                cmd.Name.String(),
+		SubName:             cmd.SubName,
+		RepoRelDir:          cmd.RepoRelDir,
+		Workspace:           cmd.Workspace,
+		ProjectName:         cmd.ProjectName,
+		Flags:               cmd.Flags,
+		Verbose:             cmd.Verbose,
+		AutoMergeDisabled:   cmd.AutoMergeDisabled,
+		AutoMergeMethod:     cmd.AutoMergeMethod,
+		PolicySet:           cmd.PolicySet,
+		ClearPolicyApproval: cmd.ClearPolicyApproval,
+		Failed:              cmd.Failed,
+	}
+}
+
+// ReplayedCommentCommand returns the comment command of request, recorded in
+// the timeline or the command records, to run it again.
+func ReplayedCommentCommand(request models.CommandRequest) (*CommentCommand, error) {
+	name, err := command.ParseCommandName(request.Name)
+	if err != nil {
+		return nil, fmt.Errorf("replaying command: %w", err)
+	}
+	cmd := NewCommentCommand(request.RepoRelDir, request.Flags, name, request.SubName, request.Verbose, request.AutoMergeDisabled, request.AutoMergeMethod, request.Workspace, request.ProjectName, request.PolicySet, request.ClearPolicyApproval)
+	cmd.Failed = request.Failed
+	return cmd, nil
+}
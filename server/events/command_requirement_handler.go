@@ -5,11 +5,17 @@ package events
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/runatlantis/atlantis/server/core/config/raw"
 	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/core/runtime"
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
 )
 
 //go:generate pegomock generate --package mocks -o mocks/mock_command_requirement_handler.go CommandRequirementHandler
@@ -22,6 +28,30 @@ type CommandRequirementHandler interface {
 
 type DefaultCommandRequirementHandler struct {
 	WorkingDir WorkingDir
+	// PlanfileEncryptor decrypts the saved plan outputs the fresh requirement
+	// reads the base commit of plans from. Nil if planfiles aren't encrypted.
+	PlanfileEncryptor *runtime.PlanfileEncryptor
+	// MaxPlanAge is how old a plan may be before the fresh requirement
+	// rejects applying it. Zero disables the age check.
+	MaxPlanAge time.Duration
+	// ChangeRequests manages the change requests required by the
+	// change_request requirement. It's nil if ServiceNow isn't configured.
+	ChangeRequests ChangeRequestClient
+	// CommitVerifiers check the signatures of the commits of pull requests
+	// for the verified_commits requirement, by VCS host. Hosts without one
+	// can't satisfy the requirement.
+	CommitVerifiers map[models.VCSHostType]CommitVerifier
+	// CodeOwners get the CODEOWNERS and approvers of pull requests for the
+	// codeowners_approved requirement, by VCS host. Hosts without one can't
+	// satisfy the requirement.
+	CodeOwners map[models.VCSHostType]CodeOwnersClient
+}
+
+// CommitVerifier lists the commits of pull requests whose signatures the VCS
+// host didn't verify, ex. unsigned commits or commits signed with a key that
+// isn't registered to their author.
+type CommitVerifier interface {
+	GetUnverifiedCommits(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest) ([]string, error)
 }
 
 func (a *DefaultCommandRequirementHandler) ValidateProjectDependencies(ctx command.ProjectContext) (failure string, err error) {
@@ -43,9 +73,135 @@ func (a *DefaultCommandRequirementHandler) ValidatePlanProject(repoDir string, c
 }
 
 func (a *DefaultCommandRequirementHandler) ValidateApplyProject(repoDir string, ctx command.ProjectContext) (failure string, err error) {
+	if failure := a.validateApplyWindow(ctx); failure != "" {
+		return failure, nil
+	}
+	if failure, err := a.validateCostThreshold(repoDir, ctx); failure != "" || err != nil {
+		return failure, err
+	}
 	return a.validateCommandRequirement(repoDir, ctx, command.Apply, ctx.ApplyRequirements)
 }
 
+// validatePlanFreshness returns a failure if the project's plan is older than
+// MaxPlanAge or the head of the base branch isn't the commit the plan was
+// generated from anymore.
+func (a *DefaultCommandRequirementHandler) validatePlanFreshness(repoDir string, ctx command.ProjectContext, cmd command.Name) (string, error) {
+	absPath := filepath.Join(repoDir, ctx.RepoRelDir)
+	if a.MaxPlanAge > 0 {
+		planPath := filepath.Join(absPath, runtime.GetPlanFilename(ctx.Workspace, ctx.ProjectName))
+		// A missing planfile is reported by the apply itself.
+		if info, err := os.Stat(planPath); err == nil && time.Since(info.ModTime()) > a.MaxPlanAge {
+			return fmt.Sprintf("Plan is older than %s, the project must be planned again before running %s.", a.MaxPlanAge, cmd), nil
+		}
+	}
+	saved, err := readPlanOutput(ctx, a.PlanfileEncryptor, absPath)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("reading the plan's output: %w", err)
+	}
+	if saved.BaseCommit == "" {
+		return fmt.Sprintf("The base commit of the plan wasn't recorded, the project must be planned again before running %s.", cmd), nil
+	}
+	baseHead, err := a.WorkingDir.GetBaseHead(ctx.Log, ctx.Pull)
+	if err != nil {
+		return "", fmt.Errorf("getting the head of the base branch: %w", err)
+	}
+	if baseHead != saved.BaseCommit {
+		return fmt.Sprintf("Base branch has advanced since the plan, from %s to %s, the project must be planned again before running %s.", shortSHA(saved.BaseCommit), shortSHA(baseHead), cmd), nil
+	}
+	return "", nil
+}
+
+func shortSHA(sha string) string {
+	return sha[:min(len(sha), 7)]
+}
+
+// validateApplyWindow returns a failure if the project has an apply window
+// configured, we're currently outside of it and the user isn't allowed to
+// override it.
+func (a *DefaultCommandRequirementHandler) validateApplyWindow(ctx command.ProjectContext) string {
+	if ctx.ApplyWindow == nil || ctx.ApplyWindow.Contains(time.Now()) {
+		return ""
+	}
+	if ctx.ApplyWindow.CanOverride(ctx.User.Username) {
+		ctx.Log.Info("user %q is applying outside of the apply window %s", ctx.User.Username, ctx.ApplyWindow)
+		return ""
+	}
+	failure := fmt.Sprintf("Applies for this project are only allowed during its apply window: %s.", ctx.ApplyWindow)
+	if len(ctx.ApplyWindow.OverrideUsers) > 0 {
+		failure += fmt.Sprintf(" Outside of this window only %s can apply.", strings.Join(ctx.ApplyWindow.OverrideUsers, ", "))
+	}
+	return failure
+}
+
+// validateVerifiedCommits returns a failure if the VCS host didn't verify the
+// signatures of all the commits of the pull request.
+func (a *DefaultCommandRequirementHandler) validateVerifiedCommits(ctx command.ProjectContext, cmd command.Name) (string, error) {
+	host := ctx.Pull.BaseRepo.VCSHost.Type
+	verifier, ok := a.CommitVerifiers[host]
+	if !ok {
+		return fmt.Sprintf("Project requires verified commits but Atlantis can't check the commit signatures of %s, the project can't run %s.", host, cmd), nil
+	}
+	unverified, err := verifier.GetUnverifiedCommits(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull)
+	if err != nil {
+		return "", fmt.Errorf("getting unverified commits: %w", err)
+	}
+	if len(unverified) == 0 {
+		return "", nil
+	}
+	shas := make([]string, len(unverified))
+	for i, sha := range unverified {
+		shas[i] = shortSHA(sha)
+	}
+	return fmt.Sprintf("All commits of the pull request must be signed and verified before running %s, unverified: %s.", cmd, strings.Join(shas, ", ")), nil
+}
+
+// validateCostThreshold returns a failure if the project has a cost threshold,
+// its plan increases the monthly cost by more than it and the pull request
+// isn't approved, by one of the cost approvers if the repo has some. A plan
+// without a cost estimate can't be checked so it fails too.
+func (a *DefaultCommandRequirementHandler) validateCostThreshold(repoDir string, ctx command.ProjectContext) (string, error) {
+	if ctx.CostThreshold == nil {
+		return "", nil
+	}
+	estimate, err := readCostEstimate(filepath.Join(repoDir, ctx.RepoRelDir, ctx.GetCostFileName()))
+	if err != nil {
+		return "", fmt.Errorf("reading cost estimate: %w", err)
+	}
+	if estimate == nil {
+		return "Project has a cost threshold but its plan has no cost estimate, the project's workflow must estimate costs, ex. with a cost step, and the project must be planned again before running apply.", nil
+	}
+	estimate.Threshold = ctx.CostThreshold
+	if !estimate.ExceedsThreshold() {
+		return "", nil
+	}
+	increase := fmt.Sprintf("Plan increases the monthly cost by %s, more than the project's threshold of %s", estimate.FormatCost(estimate.MonthlyDelta), estimate.FormatCost(*ctx.CostThreshold))
+	if len(ctx.CostApprovers) == 0 {
+		if ctx.PullReqStatus.ApprovalStatus.IsApproved {
+			return "", nil
+		}
+		return increase + ", the pull request must be approved before running apply.", nil
+	}
+
+	client, ok := a.CodeOwners[ctx.Pull.BaseRepo.VCSHost.Type]
+	if !ok {
+		return fmt.Sprintf("%s but Atlantis can't check the approvals of the cost approvers on %s, the project can't run apply.", increase, ctx.Pull.BaseRepo.VCSHost.Type), nil
+	}
+	approvers, err := client.GetPullApprovers(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull)
+	if err != nil {
+		return "", fmt.Errorf("getting approvers: %w", err)
+	}
+	// The cost approvers are written like code owners, the @ is optional.
+	owners := make([]string, len(ctx.CostApprovers))
+	for i, approver := range ctx.CostApprovers {
+		owners[i] = "@" + strings.TrimPrefix(approver, "@")
+	}
+	approved, err := ownerApproved(client, ctx, owners, approvers, make(map[string][]string))
+	if err != nil || approved {
+		return "", err
+	}
+	return fmt.Sprintf("%s, the pull request must be approved by one of %s before running apply.", increase, strings.Join(owners, ", ")), nil
+}
+
 func (a *DefaultCommandRequirementHandler) ValidateImportProject(repoDir string, ctx command.ProjectContext) (failure string, err error) {
 	return a.validateCommandRequirement(repoDir, ctx, command.Import, ctx.ImportRequirements)
 }
@@ -75,6 +231,40 @@ func (a *DefaultCommandRequirementHandler) validateCommandRequirement(repoDir st
 			if a.WorkingDir.HasDiverged(ctx.Log, repoDir) {
 				return fmt.Sprintf("Default branch must be rebased onto pull request before running %s.", cmd), nil
 			}
+		case raw.FreshRequirement:
+			if failure, err := a.validatePlanFreshness(repoDir, ctx, cmd); failure != "" || err != nil {
+				return failure, err
+			}
+		case raw.ChangeRequestRequirement:
+			if a.ChangeRequests == nil {
+				return fmt.Sprintf("Project requires a change request but ServiceNow isn't configured, the project can't run %s.", cmd), nil
+			}
+			cr, err := ensureChangeRequest(a.ChangeRequests, ctx)
+			if err != nil {
+				return "", fmt.Errorf("ensuring change request: %w", err)
+			}
+			if !cr.Approved() {
+				return fmt.Sprintf("Change request %s must be approved in ServiceNow before running %s.", cr.Number, cmd), nil
+			}
+		case raw.VerifiedCommitsRequirement:
+			if failure, err := a.validateVerifiedCommits(ctx, cmd); failure != "" || err != nil {
+				return failure, err
+			}
+		case raw.CodeOwnersApprovedRequirement:
+			client, ok := a.CodeOwners[ctx.Pull.BaseRepo.VCSHost.Type]
+			if !ok {
+				return fmt.Sprintf("Project requires an approval from its code owners but Atlantis can't check the approvals of code owners on %s, the project can't run %s.", ctx.Pull.BaseRepo.VCSHost.Type, cmd), nil
+			}
+			if failure, err := validateCodeOwnersApproval(client, ctx, cmd); failure != "" || err != nil {
+				return failure, err
+			}
+		case raw.DescriptionRequirement:
+			if len(ctx.DescriptionSections) == 0 {
+				return fmt.Sprintf("Project requires a pull request description but no description_sections are configured for the repo, the project can't run %s.", cmd), nil
+			}
+			if missing := valid.MissingDescriptionSections(ctx.DescriptionSections, ctx.Pull.Body); len(missing) > 0 {
+				return fmt.Sprintf("Pull request description must contain the required sections before running %s, missing: %s.", cmd, strings.Join(missing, ", ")), nil
+			}
 		}
 	}
 	// Passed all requirements configured.
@@ -5,11 +5,16 @@ package events_test
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
 	"testing"
+	"time"
 
 	. "github.com/petergtz/pegomock/v4"
 	"github.com/runatlantis/atlantis/server/core/config/raw"
 	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/core/servicenow"
 	"github.com/runatlantis/atlantis/server/events"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/logging"
@@ -121,6 +126,12 @@ func TestAggregateApplyRequirements_ValidatePlanProject(t *testing.T) {
 
 func TestAggregateApplyRequirements_ValidateApplyProject(t *testing.T) {
 	repoDir := "repoDir"
+	tomorrow := (time.Now().UTC().Weekday() + 1) % 7
+	closedWindow := &valid.ApplyWindow{
+		Days:          []time.Weekday{tomorrow},
+		Location:      time.UTC,
+		OverrideUsers: []string{"admin"},
+	}
 	fullRequirements := []string{
 		raw.ApprovedRequirement,
 		valid.PoliciesPassedCommandReq,
@@ -211,6 +222,24 @@ func TestAggregateApplyRequirements_ValidateApplyProject(t *testing.T) {
 			wantFailure: "Default branch must be rebased onto pull request before running apply.",
 			wantErr:     assert.NoError,
 		},
+		{
+			name: "fail outside apply window",
+			ctx: command.ProjectContext{
+				ApplyWindow: closedWindow,
+				User:        models.User{Username: "someone"},
+			},
+			wantFailure: fmt.Sprintf("Applies for this project are only allowed during its apply window: %s all day (UTC). Outside of this window only admin can apply.", tomorrow.String()[:3]),
+			wantErr:     assert.NoError,
+		},
+		{
+			name: "pass outside apply window as override user",
+			ctx: command.ProjectContext{
+				ApplyWindow: closedWindow,
+				User:        models.User{Username: "admin"},
+				Log:         logging.NewNoopLogger(t),
+			},
+			wantErr: assert.NoError,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -229,6 +258,404 @@ func TestAggregateApplyRequirements_ValidateApplyProject(t *testing.T) {
 	}
 }
 
+func TestAggregateApplyRequirements_ValidateApplyProject_Fresh(t *testing.T) {
+	tests := []struct {
+		name        string
+		planAge     time.Duration
+		baseCommit  string
+		baseHead    string
+		wantFailure string
+	}{
+		{
+			name:       "pass fresh plan",
+			planAge:    time.Minute,
+			baseCommit: "abc1234567",
+			baseHead:   "abc1234567",
+		},
+		{
+			name:        "fail by old plan",
+			planAge:     5 * time.Hour,
+			baseCommit:  "abc1234567",
+			baseHead:    "abc1234567",
+			wantFailure: "Plan is older than 4h0m0s, the project must be planned again before running apply.",
+		},
+		{
+			name:        "fail by base branch advanced",
+			planAge:     time.Minute,
+			baseCommit:  "abc1234567",
+			baseHead:    "def5678901",
+			wantFailure: "Base branch has advanced since the plan, from abc1234 to def5678, the project must be planned again before running apply.",
+		},
+		{
+			name:        "fail by base commit not recorded",
+			planAge:     time.Minute,
+			baseHead:    "abc1234567",
+			wantFailure: "The base commit of the plan wasn't recorded, the project must be planned again before running apply.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			repoDir := t.TempDir()
+			planPath := filepath.Join(repoDir, "default.tfplan")
+			assert.NoError(t, os.WriteFile(planPath, nil, 0600))
+			modTime := time.Now().Add(-tt.planAge)
+			assert.NoError(t, os.Chtimes(planPath, modTime, modTime))
+			ctx := command.ProjectContext{
+				ApplyRequirements: []string{raw.FreshRequirement},
+				RepoRelDir:        ".",
+				Workspace:         "default",
+			}
+			planOutput := fmt.Sprintf(`{"terraform_output":"","base_commit":%q}`, tt.baseCommit)
+			assert.NoError(t, os.WriteFile(filepath.Join(repoDir, ctx.GetPlanOutputFileName()), []byte(planOutput), 0600))
+
+			workingDir := mocks.NewMockWorkingDir()
+			When(workingDir.GetBaseHead(Any[logging.SimpleLogging](), Any[models.PullRequest]())).ThenReturn(tt.baseHead, nil)
+			a := &events.DefaultCommandRequirementHandler{WorkingDir: workingDir, MaxPlanAge: 4 * time.Hour}
+			gotFailure, err := a.ValidateApplyProject(repoDir, ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantFailure, gotFailure)
+		})
+	}
+}
+
+func TestAggregateApplyRequirements_ValidateApplyProject_CostThreshold(t *testing.T) {
+	threshold := 100.0
+	overThreshold := `{"currency":"USD","totalMonthlyCost":"250.5","pastTotalMonthlyCost":"100","diffTotalMonthlyCost":"150.5"}`
+	tests := []struct {
+		name          string
+		cost          string
+		approved      bool
+		costApprovers []string
+		client        *fakeCodeOwners
+		wantFailure   string
+	}{
+		{
+			name: "pass under threshold",
+			cost: `{"currency":"USD","totalMonthlyCost":"150","pastTotalMonthlyCost":"100","diffTotalMonthlyCost":"50"}`,
+		},
+		{
+			name:        "fail over threshold",
+			cost:        overThreshold,
+			wantFailure: "Plan increases the monthly cost by 150.50 USD, more than the project's threshold of 100.00 USD, the pull request must be approved before running apply.",
+		},
+		{
+			name:     "pass over threshold when approved",
+			cost:     overThreshold,
+			approved: true,
+		},
+		{
+			name:          "pass over threshold when approved by cost approver",
+			cost:          overThreshold,
+			costApprovers: []string{"@alice", "org/finops"},
+			client:        &fakeCodeOwners{approvers: []string{"bob"}, teams: map[string][]string{"bob": {"finops"}}},
+		},
+		{
+			name:          "fail over threshold when approved by someone else",
+			cost:          overThreshold,
+			approved:      true,
+			costApprovers: []string{"@alice", "org/finops"},
+			client:        &fakeCodeOwners{approvers: []string{"mallory"}},
+			wantFailure:   "Plan increases the monthly cost by 150.50 USD, more than the project's threshold of 100.00 USD, the pull request must be approved by one of @alice, @org/finops before running apply.",
+		},
+		{
+			name:          "fail over threshold on vcs host without approvers",
+			cost:          overThreshold,
+			approved:      true,
+			costApprovers: []string{"alice"},
+			wantFailure:   "Plan increases the monthly cost by 150.50 USD, more than the project's threshold of 100.00 USD but Atlantis can't check the approvals of the cost approvers on Github, the project can't run apply.",
+		},
+		{
+			name:        "fail without estimate",
+			approved:    true,
+			wantFailure: "Project has a cost threshold but its plan has no cost estimate, the project's workflow must estimate costs, ex. with a cost step, and the project must be planned again before running apply.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoDir := t.TempDir()
+			if tt.cost != "" {
+				assert.NoError(t, os.WriteFile(filepath.Join(repoDir, "default-cost.json"), []byte(tt.cost), 0600))
+			}
+			a := &events.DefaultCommandRequirementHandler{}
+			if tt.client != nil {
+				a.CodeOwners = map[models.VCSHostType]events.CodeOwnersClient{models.Github: tt.client}
+			}
+			ctx := command.ProjectContext{
+				CostThreshold: &threshold,
+				CostApprovers: tt.costApprovers,
+				RepoRelDir:    ".",
+				Workspace:     "default",
+				Pull: models.PullRequest{
+					BaseRepo: models.Repo{FullName: "org/repo", Owner: "org", VCSHost: models.VCSHost{Type: models.Github}},
+				},
+				PullReqStatus: models.PullReqStatus{
+					ApprovalStatus: models.ApprovalStatus{IsApproved: tt.approved},
+				},
+			}
+			gotFailure, err := a.ValidateApplyProject(repoDir, ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantFailure, gotFailure)
+		})
+	}
+}
+
+// fakeChangeRequests is a ChangeRequestClient that records the change
+// requests it's asked for.
+type fakeChangeRequests struct {
+	cr             servicenow.ChangeRequest
+	correlationIDs []string
+	closed         []bool
+	closeNotes     string
+}
+
+func (f *fakeChangeRequests) EnsureChangeRequest(correlationID string, _ string, _ string) (servicenow.ChangeRequest, error) {
+	f.correlationIDs = append(f.correlationIDs, correlationID)
+	return f.cr, nil
+}
+
+func (f *fakeChangeRequests) CloseChangeRequest(_ servicenow.ChangeRequest, successful bool, notes string) error {
+	f.closed = append(f.closed, successful)
+	f.closeNotes = notes
+	return nil
+}
+
+func TestAggregateApplyRequirements_ValidateApplyProject_ChangeRequest(t *testing.T) {
+	tests := []struct {
+		name           string
+		changeRequests *fakeChangeRequests
+		wantFailure    string
+	}{
+		{
+			name:           "pass approved change request",
+			changeRequests: &fakeChangeRequests{cr: servicenow.ChangeRequest{Number: "CHG0001", Approval: "approved"}},
+		},
+		{
+			name:           "fail by unapproved change request",
+			changeRequests: &fakeChangeRequests{cr: servicenow.ChangeRequest{Number: "CHG0001", Approval: "requested"}},
+			wantFailure:    "Change request CHG0001 must be approved in ServiceNow before running apply.",
+		},
+		{
+			name:        "fail by servicenow not configured",
+			wantFailure: "Project requires a change request but ServiceNow isn't configured, the project can't run apply.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &events.DefaultCommandRequirementHandler{}
+			if tt.changeRequests != nil {
+				a.ChangeRequests = tt.changeRequests
+			}
+			ctx := command.ProjectContext{
+				ApplyRequirements: []string{raw.ChangeRequestRequirement},
+				Pull:              models.PullRequest{BaseRepo: models.Repo{FullName: "owner/repo"}, Num: 1},
+				RepoRelDir:        ".",
+				Workspace:         "default",
+			}
+			gotFailure, err := a.ValidateApplyProject("repoDir", ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantFailure, gotFailure)
+			if tt.changeRequests != nil {
+				assert.Equal(t, []string{"atlantis/owner/repo/1/./default"}, tt.changeRequests.correlationIDs)
+			}
+		})
+	}
+}
+
+// fakeCommitVerifier is a CommitVerifier returning the unverified commits.
+type fakeCommitVerifier struct {
+	unverified []string
+}
+
+func (f *fakeCommitVerifier) GetUnverifiedCommits(_ logging.SimpleLogging, _ models.Repo, _ models.PullRequest) ([]string, error) {
+	return f.unverified, nil
+}
+
+func TestAggregateApplyRequirements_ValidateApplyProject_VerifiedCommits(t *testing.T) {
+	tests := []struct {
+		name        string
+		verifier    *fakeCommitVerifier
+		wantFailure string
+	}{
+		{
+			name:     "pass all commits verified",
+			verifier: &fakeCommitVerifier{},
+		},
+		{
+			name:        "fail by unverified commits",
+			verifier:    &fakeCommitVerifier{unverified: []string{"6d1c5a7f32e5c366bb8a4b3e1e5c7c2b5f3a6f0d", "abc"}},
+			wantFailure: "All commits of the pull request must be signed and verified before running apply, unverified: 6d1c5a7, abc.",
+		},
+		{
+			name:        "fail by vcs host without verification",
+			wantFailure: "Project requires verified commits but Atlantis can't check the commit signatures of Github, the project can't run apply.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &events.DefaultCommandRequirementHandler{}
+			if tt.verifier != nil {
+				a.CommitVerifiers = map[models.VCSHostType]events.CommitVerifier{models.Github: tt.verifier}
+			}
+			ctx := command.ProjectContext{
+				ApplyRequirements: []string{raw.VerifiedCommitsRequirement},
+				Pull:              models.PullRequest{BaseRepo: models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Type: models.Github}}, Num: 1},
+			}
+			gotFailure, err := a.ValidateApplyProject("repoDir", ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantFailure, gotFailure)
+		})
+	}
+}
+
+// fakeCodeOwners is a CodeOwnersClient with a CODEOWNERS file, approvers and
+// teams of users.
+type fakeCodeOwners struct {
+	codeOwners    string
+	approvers     []string
+	teams         map[string][]string
+	modifiedFiles []string
+}
+
+func (f *fakeCodeOwners) GetFileContent(_ logging.SimpleLogging, _ models.Repo, branch string, fileName string) (bool, []byte, error) {
+	if branch != "main" || fileName != ".github/CODEOWNERS" || f.codeOwners == "" {
+		return false, nil, nil
+	}
+	return true, []byte(f.codeOwners), nil
+}
+
+func (f *fakeCodeOwners) GetPullApprovers(_ logging.SimpleLogging, _ models.Repo, _ models.PullRequest) ([]string, error) {
+	return f.approvers, nil
+}
+
+func (f *fakeCodeOwners) GetModifiedFiles(_ logging.SimpleLogging, _ models.Repo, _ models.PullRequest) ([]string, error) {
+	return f.modifiedFiles, nil
+}
+
+func (f *fakeCodeOwners) GetTeamNamesForUser(_ logging.SimpleLogging, _ models.Repo, user models.User) ([]string, error) {
+	return f.teams[user.Username], nil
+}
+
+func TestAggregateApplyRequirements_ValidateApplyProject_CodeOwnersApproved(t *testing.T) {
+	codeOwners := "* @platform\n/prod/ @org/sre @alice\n/prod/*.md @docs\n"
+	modifiedFiles := []string{"prod/main.tf", "staging/main.tf", "modules/vpc/main.tf"}
+	tests := []struct {
+		name        string
+		client      *fakeCodeOwners
+		dir         string
+		wantFailure string
+	}{
+		{
+			name:   "pass approved by owner",
+			client: &fakeCodeOwners{codeOwners: codeOwners, approvers: []string{"bob", "Alice"}, modifiedFiles: modifiedFiles},
+			dir:    "prod",
+		},
+		{
+			name:   "pass approved by member of owner team",
+			client: &fakeCodeOwners{codeOwners: codeOwners, approvers: []string{"bob"}, teams: map[string][]string{"bob": {"sre"}}, modifiedFiles: modifiedFiles},
+			dir:    "prod",
+		},
+		{
+			name:        "fail by approval of another project's owner",
+			client:      &fakeCodeOwners{codeOwners: codeOwners, approvers: []string{"platform"}, teams: map[string][]string{"platform": {"platform"}}, modifiedFiles: modifiedFiles},
+			dir:         "prod",
+			wantFailure: "Pull request must be approved by a code owner of prod before running apply, missing an approval from: @org/sre, @alice.",
+		},
+		{
+			name:        "fail by missing approval of owner of a modified file",
+			client:      &fakeCodeOwners{codeOwners: codeOwners, approvers: []string{"alice"}, modifiedFiles: append([]string{"prod/README.md"}, modifiedFiles...)},
+			dir:         "prod",
+			wantFailure: "Pull request must be approved by a code owner of prod before running apply, missing an approval from: @docs.",
+		},
+		{
+			name:        "fail by missing approval of owner of modified module",
+			client:      &fakeCodeOwners{codeOwners: codeOwners, approvers: []string{"alice"}, modifiedFiles: []string{"modules/vpc/main.tf"}},
+			dir:         "prod",
+			wantFailure: "Pull request must be approved by a code owner of prod before running apply, missing an approval from: @platform.",
+		},
+		{
+			name:        "fail by no owners",
+			client:      &fakeCodeOwners{codeOwners: "/prod/ @alice\n", modifiedFiles: modifiedFiles},
+			dir:         "staging",
+			wantFailure: "Project requires an approval from its code owners but CODEOWNERS has no owners for staging, the project can't run apply.",
+		},
+		{
+			name:        "fail by no CODEOWNERS",
+			client:      &fakeCodeOwners{},
+			dir:         "prod",
+			wantFailure: "Project requires an approval from its code owners but the repo has no CODEOWNERS file, the project can't run apply.",
+		},
+		{
+			name:        "fail by vcs host without code owners",
+			dir:         "prod",
+			wantFailure: "Project requires an approval from its code owners but Atlantis can't check the approvals of code owners on Github, the project can't run apply.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &events.DefaultCommandRequirementHandler{}
+			if tt.client != nil {
+				a.CodeOwners = map[models.VCSHostType]events.CodeOwnersClient{models.Github: tt.client}
+			}
+			ctx := command.ProjectContext{
+				ApplyRequirements: []string{raw.CodeOwnersApprovedRequirement},
+				Pull: models.PullRequest{
+					BaseRepo:   models.Repo{FullName: "org/repo", Owner: "org", VCSHost: models.VCSHost{Type: models.Github}},
+					BaseBranch: "main",
+					Num:        1,
+				},
+				RepoRelDir: tt.dir,
+			}
+			gotFailure, err := a.ValidateApplyProject("repoDir", ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantFailure, gotFailure)
+		})
+	}
+}
+
+func TestAggregateApplyRequirements_ValidateApplyProject_Description(t *testing.T) {
+	sections := []valid.DescriptionSection{
+		{Name: "Rollback plan", Regex: regexp.MustCompile(`(?im)^#+\s*rollback plan\s*$`)},
+		{Name: "Ticket link", Regex: regexp.MustCompile(`https://jira\.example\.com/browse/[A-Z]+-\d+`)},
+	}
+	tests := []struct {
+		name        string
+		sections    []valid.DescriptionSection
+		body        string
+		wantFailure string
+	}{
+		{
+			name:     "pass description with all sections",
+			sections: sections,
+			body:     "Adds a bucket.\n\n## Rollback plan\nRevert.\n\nhttps://jira.example.com/browse/OPS-12",
+		},
+		{
+			name:        "fail by missing sections",
+			sections:    sections,
+			body:        "Adds a bucket.\n\n## Rollback Plan\nRevert.",
+			wantFailure: "Pull request description must contain the required sections before running apply, missing: Ticket link.",
+		},
+		{
+			name:        "fail by no sections configured",
+			body:        "Adds a bucket.",
+			wantFailure: "Project requires a pull request description but no description_sections are configured for the repo, the project can't run apply.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &events.DefaultCommandRequirementHandler{}
+			ctx := command.ProjectContext{
+				ApplyRequirements:   []string{raw.DescriptionRequirement},
+				DescriptionSections: tt.sections,
+				Pull:                models.PullRequest{Body: tt.body},
+			}
+			gotFailure, err := a.ValidateApplyProject("repoDir", ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantFailure, gotFailure)
+		})
+	}
+}
+
 func TestRequirements_ValidateProjectDependencies(t *testing.T) {
 	tests := []struct {
 		name        string
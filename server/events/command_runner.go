@@ -16,14 +16,18 @@ package events
 import (
 	"errors"
 	"fmt"
-	"strconv"
+	"time"
 
 	"github.com/drmaxgit/go-azuredevops/azuredevops"
 	"github.com/google/go-github/v71/github"
 	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/core/db"
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/timeline"
 	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/vcs/codecommit"
+	"github.com/runatlantis/atlantis/server/events/vcs/gerrit"
 	"github.com/runatlantis/atlantis/server/events/vcs/gitea"
 	"github.com/runatlantis/atlantis/server/logging"
 	"github.com/runatlantis/atlantis/server/metrics"
@@ -44,8 +48,10 @@ type CommandRunner interface {
 	// RunCommentCommand is the first step after a command request has been parsed.
 	// It handles gathering additional information needed to execute the command
 	// and then calling the appropriate services to finish executing the command.
-	RunCommentCommand(baseRepo models.Repo, maybeHeadRepo *models.Repo, maybePull *models.PullRequest, user models.User, pullNum int, cmd *CommentCommand)
-	RunAutoplanCommand(baseRepo models.Repo, headRepo models.Repo, pull models.PullRequest, user models.User)
+	// logger is the logger of the event the command was requested by, with its
+	// correlation ID, repo and pull, everything the command logs is logged to it.
+	RunCommentCommand(logger logging.SimpleLogging, baseRepo models.Repo, maybeHeadRepo *models.Repo, maybePull *models.PullRequest, user models.User, pullNum int, cmd *CommentCommand)
+	RunAutoplanCommand(logger logging.SimpleLogging, baseRepo models.Repo, headRepo models.Repo, pull models.PullRequest, user models.User)
 }
 
 //go:generate pegomock generate github.com/runatlantis/atlantis/server/events --package mocks -o mocks/mock_github_pull_getter.go GithubPullGetter
@@ -99,6 +105,8 @@ type DefaultCommandRunner struct {
 	AzureDevopsPullGetter    AzureDevopsPullGetter
 	GitlabMergeRequestGetter GitlabMergeRequestGetter
 	GiteaPullGetter          *gitea.Client
+	GerritChangeGetter       *gerrit.Client
+	CodeCommitPullGetter     *codecommit.Client
 	// User config option: Disables autoplan when a pull request is opened or updated.
 	DisableAutoplan      bool
 	DisableAutoplanLabel string
@@ -133,19 +141,106 @@ type DefaultCommandRunner struct {
 	TeamAllowlistChecker           command.TeamAllowlistChecker          `validate:"required"`
 	VarFileAllowlistChecker        *VarFileAllowlistChecker              `validate:"required"`
 	CommitStatusUpdater            CommitStatusUpdater                   `validate:"required"`
+	// EmojiReactionSuccess is the reaction added to the triggering comment
+	// once the command finished without errors. Disabled if empty.
+	EmojiReactionSuccess string
+	// EmojiReactionFailure is the reaction added to the triggering comment
+	// once the command finished with errors. Disabled if empty.
+	EmojiReactionFailure string
+	// ProgressCommenter shows the progress of plan and apply comment commands
+	// in a comment while they run. It's nil if progress comments are disabled.
+	ProgressCommenter *ProgressCommenter
+	// StackedPulls detects the pull requests stacked on another pull request.
+	// It's nil if the VCS can't find pull requests by branch.
+	StackedPulls *StackedPulls
+	// Tenants, if set, tags command metrics with the tenant of the repo.
+	Tenants *Tenants
+	// RepoAllowlistChecker, if set, ignores comments on pull requests
+	// targeting branches that aren't allowlisted.
+	RepoAllowlistChecker *RepoAllowlistChecker
+	// CommandAuthorizer, if set, evaluates the command authorization policy
+	// before running comment commands.
+	CommandAuthorizer *CommandAuthorizer
+	// Timeline records the commands run for pull requests. It may be nil.
+	Timeline *timeline.Store
+	// CommandRecords stores the comment commands so they can be replayed
+	// once they're gone from the timeline. It may be nil.
+	CommandRecords db.Database
+	// DeferredCommands, if set, keeps the commands requested while Atlantis
+	// is draining to run them once it restarts, instead of rejecting them.
+	DeferredCommands *DeferredCommands
+	// Journal, if set, records the commands in progress so the ones that are
+	// interrupted by a restart are recovered, see RecoverInterruptedCommands.
+	Journal *CommandJournal
+	// Scheduler, if set, limits the rate of the commands and how many run at
+	// once.
+	Scheduler *CommandScheduler
+	// DiskMonitor, if set, rejects new plans while the disk of the data dir is
+	// critically full.
+	DiskMonitor *DiskMonitor
+	// Previews, if set, deploys the preview environments deployed
+	// automatically when pull requests are autoplanned.
+	Previews *PreviewCommandRunner
+}
+
+// rejectWhileShuttingDown comments that the command of item isn't run since
+// Atlantis is shutting down, or that it's deferred if commands are deferred.
+func (c *DefaultCommandRunner) rejectWhileShuttingDown(logger logging.SimpleLogging, item WorkItem, commandName string) {
+	comment := ShutdownComment
+	if c.DeferredCommands != nil {
+		item.CorrelationID = logging.CorrelationID(logger)
+		if err := c.DeferredCommands.Add(item); err != nil {
+			logger.Err("unable to defer %s until Atlantis restarts: %s", item, err)
+		} else {
+			logger.Info("deferred %s until Atlantis restarts", item)
+			comment = DeferredComment
+		}
+	}
+	if commentErr := c.VCSClient.CreateComment(logger, item.BaseRepo, item.PullNum, comment, commandName); commentErr != nil {
+		logger.Log(logging.Error, "unable to comment that Atlantis is shutting down: %s", commentErr)
+	}
+}
+
+// recordInProgress records item in the journal, if any, until the returned
+// function is called.
+func (c *DefaultCommandRunner) recordInProgress(logger logging.SimpleLogging, item WorkItem) func() {
+	if c.Journal == nil {
+		return func() {}
+	}
+	item.CorrelationID = logging.CorrelationID(logger)
+	done, err := c.Journal.Start(logger, item)
+	if err != nil {
+		logger.Warn("unable to record that %s is in progress, it won't be recovered if Atlantis restarts: %s", item, err)
+	}
+	return done
 }
 
 // RunAutoplanCommand runs plan and policy_checks when a pull request is opened or updated.
-func (c *DefaultCommandRunner) RunAutoplanCommand(baseRepo models.Repo, headRepo models.Repo, pull models.PullRequest, user models.User) {
+func (c *DefaultCommandRunner) RunAutoplanCommand(logger logging.SimpleLogging, baseRepo models.Repo, headRepo models.Repo, pull models.PullRequest, user models.User) {
+	item := WorkItem{
+		Autoplan: true,
+		BaseRepo: baseRepo,
+		HeadRepo: &headRepo,
+		Pull:     &pull,
+		User:     user,
+		PullNum:  pull.Num,
+	}
 	if opStarted := c.Drainer.StartOp(); !opStarted {
-		if commentErr := c.VCSClient.CreateComment(c.Logger, baseRepo, pull.Num, ShutdownComment, command.Plan.String()); commentErr != nil {
-			c.Logger.Log(logging.Error, "unable to comment that Atlantis is shutting down: %s", commentErr)
-		}
+		c.rejectWhileShuttingDown(logger, item, command.Plan.String())
 		return
 	}
 	defer c.Drainer.OpDone()
+	if c.rejectWhileDiskFull(logger, item) {
+		return
+	}
+	defer c.recordInProgress(logger, item)()
+	release, admitted := c.Scheduler.Admit(logger, baseRepo, pull.Num)
+	if !admitted {
+		return
+	}
+	defer release()
 
-	log := c.buildLogger(baseRepo.FullName, pull.Num)
+	log := logger.WithHistory()
 	defer c.logPanics(baseRepo, pull.Num, log)
 	status, err := c.PullStatusFetcher.GetPullStatus(pull)
 
@@ -153,7 +248,7 @@ func (c *DefaultCommandRunner) RunAutoplanCommand(baseRepo models.Repo, headRepo
 		log.Err("Unable to fetch pull status, this is likely a bug.", err)
 	}
 
-	scope := c.StatsScope.SubScope("autoplan")
+	scope := c.tenantScope(c.StatsScope.SubScope("autoplan"), baseRepo)
 	timer := scope.Timer(metrics.ExecutionTimeMetric).Start()
 	defer timer.Stop()
 
@@ -187,6 +282,9 @@ func (c *DefaultCommandRunner) RunAutoplanCommand(baseRepo models.Repo, headRepo
 	if !c.validateCtxAndComment(ctx, command.Autoplan) {
 		return
 	}
+	if c.StackedPulls != nil {
+		c.StackedPulls.Resolve(ctx)
+	}
 	if c.DisableAutoplan {
 		return
 	}
@@ -201,6 +299,7 @@ func (c *DefaultCommandRunner) RunAutoplanCommand(baseRepo models.Repo, headRepo
 	}
 
 	ctx.Log.Info("Running autoplan...")
+	c.Timeline.Record(baseRepo.FullName, pull.Num, timeline.Event{Type: timeline.Command, Description: "Running autoplan", User: user.Username})
 	cmd := &CommentCommand{
 		Name: command.Autoplan,
 	}
@@ -250,6 +349,14 @@ func (c *DefaultCommandRunner) RunAutoplanCommand(baseRepo models.Repo, headRepo
 
 	autoPlanRunner.Run(ctx, nil)
 
+	if c.Previews != nil {
+		if ok, err := c.checkUserPermissions(baseRepo, user, command.Preview.String()); err != nil {
+			ctx.Log.Err("Unable to check user permissions: %s", err)
+		} else if ok {
+			c.Previews.AutoDeploy(ctx)
+		}
+	}
+
 	c.PostWorkflowHooksCommandRunner.RunPostHooks(ctx, cmd) // nolint: errcheck
 }
 
@@ -284,6 +391,68 @@ func (c *DefaultCommandRunner) checkUserPermissions(repo models.Repo, user model
 	return true, nil
 }
 
+// AuthorizedAutoApplyRunner runs the applies of the auto_apply behavior rules
+// only if the user who triggered the plan is allowed to apply, as if they had
+// commented atlantis apply.
+type AuthorizedAutoApplyRunner struct {
+	CommandRunner *DefaultCommandRunner
+}
+
+func (a *AuthorizedAutoApplyRunner) Run(ctx *command.Context, cmd *CommentCommand) {
+	c := a.CommandRunner
+	if c.TeamAllowlistChecker != nil && c.TeamAllowlistChecker.HasRules() {
+		if ctx.User.Teams == nil {
+			if err := c.fetchUserTeams(ctx.Log, ctx.Pull.BaseRepo, &ctx.User); err != nil {
+				ctx.Log.Err("Unable to fetch user teams: %s", err)
+				return
+			}
+		}
+		ok, err := c.checkUserPermissions(ctx.Pull.BaseRepo, ctx.User, cmd.Name.String())
+		if err != nil {
+			ctx.Log.Err("Unable to check user permissions: %s", err)
+			return
+		}
+		if !ok {
+			ctx.Log.Info("not auto-applying since %s isn't allowed to apply", ctx.User.Username)
+			c.commentUserDoesNotHavePermissions(ctx.Pull.BaseRepo, ctx.Pull.Num, ctx.User, cmd)
+			return
+		}
+	}
+	if !c.authorizeCommand(ctx, cmd) {
+		return
+	}
+	buildCommentCommandRunner(c, cmd.Name).Run(ctx, cmd)
+}
+
+// authorizeCommand evaluates the command authorization policy, if any, and
+// comments the reason on the pull request if cmd isn't allowed to run.
+func (c *DefaultCommandRunner) authorizeCommand(ctx *command.Context, cmd *CommentCommand) bool {
+	if c.CommandAuthorizer == nil {
+		return true
+	}
+	user := ctx.User
+	if user.Teams == nil {
+		if err := c.fetchUserTeams(ctx.Log, ctx.Pull.BaseRepo, &user); err != nil {
+			ctx.Log.Warn("unable to fetch user teams for the command authorization policy: %s", err)
+		}
+	}
+	var errMsg string
+	decision, err := c.CommandAuthorizer.Authorize(NewCommandAuthzInput(user, ctx.Pull, ctx.PullStatus, cmd))
+	if err != nil {
+		ctx.Log.Err("unable to authorize command: %s", err)
+		errMsg = fmt.Sprintf("```\nError: Unable to authorize '%s' command: %s\n```", cmd.Name.String(), err)
+	} else if !decision.Allowed() {
+		ctx.Log.Info("command %s by %s denied by the command authorization policy: %s", cmd.Name.String(), user.Username, decision.Reason())
+		errMsg = fmt.Sprintf("```\nError: User @%s is not authorized to execute '%s' command: %s\n```", user.Username, cmd.Name.String(), decision.Reason())
+	} else {
+		return true
+	}
+	if err := c.VCSClient.CreateComment(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num, errMsg, ""); err != nil {
+		ctx.Log.Err("unable to comment on pull request: %s", err)
+	}
+	return false
+}
+
 // checkVarFilesInPlanCommandAllowlisted checks if paths in a 'plan' command are allowlisted.
 func (c *DefaultCommandRunner) checkVarFilesInPlanCommandAllowlisted(cmd *CommentCommand) error {
 	if cmd == nil || cmd.CommandName() != command.Plan {
@@ -298,19 +467,34 @@ func (c *DefaultCommandRunner) checkVarFilesInPlanCommandAllowlisted(cmd *Commen
 // enough data to construct the Repo model and callers might want to wait until
 // the event is further validated before making an additional (potentially
 // wasteful) call to get the necessary data.
-func (c *DefaultCommandRunner) RunCommentCommand(baseRepo models.Repo, maybeHeadRepo *models.Repo, maybePull *models.PullRequest, user models.User, pullNum int, cmd *CommentCommand) {
+func (c *DefaultCommandRunner) RunCommentCommand(logger logging.SimpleLogging, baseRepo models.Repo, maybeHeadRepo *models.Repo, maybePull *models.PullRequest, user models.User, pullNum int, cmd *CommentCommand) {
+	item := WorkItem{
+		BaseRepo: baseRepo,
+		HeadRepo: maybeHeadRepo,
+		Pull:     maybePull,
+		User:     user,
+		PullNum:  pullNum,
+		Command:  cmd,
+	}
 	if opStarted := c.Drainer.StartOp(); !opStarted {
-		if commentErr := c.VCSClient.CreateComment(c.Logger, baseRepo, pullNum, ShutdownComment, ""); commentErr != nil {
-			c.Logger.Log(logging.Error, "unable to comment that Atlantis is shutting down: %s", commentErr)
-		}
+		c.rejectWhileShuttingDown(logger, item, "")
 		return
 	}
 	defer c.Drainer.OpDone()
+	if c.rejectWhileDiskFull(logger, item) {
+		return
+	}
+	defer c.recordInProgress(logger, item)()
+	release, admitted := c.Scheduler.Admit(logger, baseRepo, pullNum)
+	if !admitted {
+		return
+	}
+	defer release()
 
-	log := c.buildLogger(baseRepo.FullName, pullNum)
+	log := logger.WithHistory()
 	defer c.logPanics(baseRepo, pullNum, log)
 
-	scope := c.StatsScope.SubScope("comment")
+	scope := c.tenantScope(c.StatsScope.SubScope("comment"), baseRepo)
 
 	if cmd != nil {
 		scope = scope.SubScope(cmd.Name.String())
@@ -373,6 +557,29 @@ func (c *DefaultCommandRunner) RunCommentCommand(baseRepo models.Repo, maybeHead
 	if !c.validateCtxAndComment(ctx, cmd.Name) {
 		return
 	}
+	if !c.authorizeCommand(ctx, cmd) {
+		return
+	}
+	if c.StackedPulls != nil {
+		c.StackedPulls.Resolve(ctx)
+	}
+	event := timeline.Event{
+		ID:          timeline.NewEventID(),
+		Time:        time.Now(),
+		Type:        timeline.Command,
+		Description: fmt.Sprintf("Running the %s command", cmd.Name),
+		User:        user.Username,
+		Project:     cmd.ProjectName,
+		Workspace:   cmd.Workspace,
+		Request:     commandRequest(cmd),
+	}
+	c.Timeline.Record(baseRepo.FullName, pull.Num, event)
+	if c.CommandRecords != nil {
+		record := models.CommandRecord{ID: event.ID, Time: event.Time, Repository: baseRepo.FullName, PullNum: pull.Num, User: user.Username, Request: *event.Request}
+		if err := c.CommandRecords.SaveCommandRecord(record); err != nil {
+			ctx.Log.Warn("unable to record the command to replay it: %s", err)
+		}
+	}
 
 	// Only set pending status if silence is not enabled
 	// The command runners will handle the final status decision based on project results
@@ -392,6 +599,16 @@ func (c *DefaultCommandRunner) RunCommentCommand(baseRepo models.Repo, maybeHead
 		ctx.Log.Debug("silence enabled - not setting pending VCS status")
 	}
 
+	if c.ProgressCommenter != nil && (cmd.Name == command.Plan || cmd.Name == command.Apply) {
+		progress, err := c.ProgressCommenter.Start(ctx, cmd.Name)
+		if err != nil {
+			ctx.Log.Warn("unable to comment progress: %s", err)
+		} else {
+			ctx.Progress = progress
+			defer func() { progress.Finish(ctx.CommandHasErrors) }()
+		}
+	}
+
 	preWorkflowHooksErr := c.PreWorkflowHooksCommandRunner.RunPreHooks(ctx, cmd)
 
 	if preWorkflowHooksErr != nil {
@@ -426,9 +643,29 @@ func (c *DefaultCommandRunner) RunCommentCommand(baseRepo models.Repo, maybeHead
 
 	cmdRunner.Run(ctx, cmd)
 
+	c.reactToCompletion(ctx, cmd)
+
 	c.PostWorkflowHooksCommandRunner.RunPostHooks(ctx, cmd) // nolint: errcheck
 }
 
+// reactToCompletion adds the configured success or failure reaction to the
+// comment that triggered cmd so users can tell at a glance how it went.
+func (c *DefaultCommandRunner) reactToCompletion(ctx *command.Context, cmd *CommentCommand) {
+	if cmd.CommentID == 0 {
+		return
+	}
+	reaction := c.EmojiReactionSuccess
+	if ctx.CommandHasErrors {
+		reaction = c.EmojiReactionFailure
+	}
+	if reaction == "" {
+		return
+	}
+	if err := c.VCSClient.ReactToComment(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num, cmd.CommentID, reaction); err != nil {
+		ctx.Log.Warn("failed to react to comment: %s", err)
+	}
+}
+
 func (c *DefaultCommandRunner) getGithubData(logger logging.SimpleLogging, baseRepo models.Repo, pullNum int) (models.PullRequest, models.Repo, error) {
 	if c.GithubPullGetter == nil {
 		return models.PullRequest{}, models.Repo{}, errors.New("atlantis not configured to support GitHub")
@@ -459,6 +696,37 @@ func (c *DefaultCommandRunner) getGiteaData(logger logging.SimpleLogging, baseRe
 	return pull, headRepo, nil
 }
 
+func (c *DefaultCommandRunner) getGerritData(logger logging.SimpleLogging, baseRepo models.Repo, pullNum int) (models.PullRequest, models.Repo, error) {
+	if c.GerritChangeGetter == nil {
+		return models.PullRequest{}, models.Repo{}, errors.New("atlantis not configured to support Gerrit")
+	}
+	change, err := c.GerritChangeGetter.GetChange(logger, baseRepo, pullNum)
+	if err != nil {
+		return models.PullRequest{}, models.Repo{}, fmt.Errorf("making change API call to Gerrit: %w", err)
+	}
+	pull, _, headRepo, err := c.EventParser.ParseGerritChange(change)
+	if err != nil {
+		return pull, headRepo, fmt.Errorf("extracting required fields from comment data: %w", err)
+	}
+	return pull, headRepo, nil
+}
+
+func (c *DefaultCommandRunner) getCodeCommitData(logger logging.SimpleLogging, baseRepo models.Repo, pullNum int) (models.PullRequest, models.Repo, error) {
+	if c.CodeCommitPullGetter == nil {
+		return models.PullRequest{}, models.Repo{}, errors.New("atlantis not configured to support CodeCommit")
+	}
+	pr, err := c.CodeCommitPullGetter.GetPullRequest(logger, pullNum)
+	if err != nil {
+		return models.PullRequest{}, models.Repo{}, fmt.Errorf("making pull request API call to CodeCommit: %w", err)
+	}
+	// The owner of CodeCommit repos is their account.
+	pull, _, headRepo, err := c.EventParser.ParseCodeCommitPullRequest(baseRepo.Owner, pr)
+	if err != nil {
+		return pull, headRepo, fmt.Errorf("extracting required fields from comment data: %w", err)
+	}
+	return pull, headRepo, nil
+}
+
 func (c *DefaultCommandRunner) getGitlabData(logger logging.SimpleLogging, baseRepo models.Repo, pullNum int) (models.PullRequest, error) {
 	if c.GitlabMergeRequestGetter == nil {
 		return models.PullRequest{}, errors.New("atlantis not configured to support GitLab")
@@ -486,14 +754,6 @@ func (c *DefaultCommandRunner) getAzureDevopsData(logger logging.SimpleLogging,
 	return pull, headRepo, nil
 }
 
-func (c *DefaultCommandRunner) buildLogger(repoFullName string, pullNum int) logging.SimpleLogging {
-
-	return c.Logger.WithHistory(
-		"repo", repoFullName,
-		"pull", strconv.Itoa(pullNum),
-	)
-}
-
 func (c *DefaultCommandRunner) ensureValidRepoMetadata(
 	baseRepo models.Repo,
 	maybeHeadRepo *models.Repo,
@@ -521,6 +781,10 @@ func (c *DefaultCommandRunner) ensureValidRepoMetadata(
 		pull, headRepo, err = c.getAzureDevopsData(log, baseRepo, pullNum)
 	case models.Gitea:
 		pull, headRepo, err = c.getGiteaData(log, baseRepo, pullNum)
+	case models.Gerrit:
+		pull, headRepo, err = c.getGerritData(log, baseRepo, pullNum)
+	case models.CodeCommit:
+		pull, headRepo, err = c.getCodeCommitData(log, baseRepo, pullNum)
 	default:
 		err = errors.New("unknown VCS type–this is a bug")
 	}
@@ -571,6 +835,10 @@ func (c *DefaultCommandRunner) validateCtxAndComment(ctx *command.Context, comma
 		// just ignore it to allow us to use any git workflows without malicious intentions.
 		return false
 	}
+	if c.RepoAllowlistChecker != nil && !c.RepoAllowlistChecker.IsBranchAllowlisted(ctx.Pull.BaseRepo.FullName, ctx.Pull.BaseRepo.VCSHost.Hostname, ctx.Pull.BaseBranch) {
+		ctx.Log.Info("command was run on a pull request targeting non-allowlisted branch '%s'", ctx.Pull.BaseBranch)
+		return false
+	}
 	return true
 }
 
@@ -592,3 +860,15 @@ func (c *DefaultCommandRunner) logPanics(baseRepo models.Repo, pullNum int, logg
 }
 
 var automergeComment = `Automatically merging because all plans have been successfully applied.`
+
+// tenantScope tags scope with the tenant of repo if tenants are configured.
+func (c *DefaultCommandRunner) tenantScope(scope tally.Scope, repo models.Repo) tally.Scope {
+	if c.Tenants == nil {
+		return scope
+	}
+	name, ok := c.Tenants.TenantFor(repo.FullName, repo.VCSHost.Hostname)
+	if !ok {
+		return scope
+	}
+	return scope.Tagged(map[string]string{"tenant": name})
+}
@@ -0,0 +1,184 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// RateLimitedComment is commented when a command is rejected because its repo
+// or pull request ran too many commands, with the limit, what it applies to
+// and how long until a command can run again.
+const RateLimitedComment = "**Error:** at most %d commands can run per minute on this %s, try again in %s."
+
+// rateLimitWindow is the window the rate limits apply to.
+const rateLimitWindow = time.Minute
+
+// CommandScheduler limits the rate of the commands of each repo and pull
+// request, and how many commands run at once, so one busy repo can't starve
+// the other repos of a shared server. The DefaultCommandRunner admits its
+// commands once they're counted by the drainer and recorded in the journal, so
+// the waiting commands are drained and recovered like the running ones.
+type CommandScheduler struct {
+	// VCSClient comments on the pull request when a command is rejected.
+	VCSClient vcs.Client
+	// RepoRateLimit is how many commands a repo can run per minute. 0 means
+	// they aren't limited.
+	RepoRateLimit int
+	// PullRateLimit is how many commands a pull request can run per minute.
+	// 0 means they aren't limited.
+	PullRateLimit int
+	// MaxConcurrent is how many commands run at once. Once reached, commands
+	// wait in a queue per repo and the repos take turns, so a repo with many
+	// waiting commands doesn't delay the others. 0 means they aren't
+	// limited.
+	MaxConcurrent int
+
+	mutex sync.Mutex
+	// runs are when the commands of the last window started, by repo and
+	// by pull request.
+	repoRuns map[string][]time.Time
+	pullRuns map[string][]time.Time
+	running  int
+	// waiting are the commands waiting to run by repo, and turns the repos
+	// with waiting commands in the order they run a command.
+	waiting map[string][]chan struct{}
+	turns   []string
+	// now is overridden in tests.
+	now func() time.Time
+}
+
+// Admit blocks until a command of the pull request can run, returning a
+// function releasing its turn once it completes. It returns false, and
+// comments that the command is rejected, if the command exceeds the rate
+// limits. Every command is admitted on a nil CommandScheduler.
+func (s *CommandScheduler) Admit(logger logging.SimpleLogging, baseRepo models.Repo, pullNum int) (func(), bool) {
+	if s == nil {
+		return func() {}, true
+	}
+	if !s.allow(logger, baseRepo, pullNum) {
+		return nil, false
+	}
+	return s.acquire(logger, baseRepo), true
+}
+
+// allow records that a command of the pull request starts, or comments that
+// it's rejected and returns false if it exceeds the rate limits.
+func (s *CommandScheduler) allow(logger logging.SimpleLogging, baseRepo models.Repo, pullNum int) bool {
+	s.mutex.Lock()
+	if s.repoRuns == nil {
+		s.repoRuns = map[string][]time.Time{}
+		s.pullRuns = map[string][]time.Time{}
+	}
+	now := s.clock()
+	repoKey := baseRepo.FullName
+	pullKey := baseRepo.FullName + "#" + strconv.Itoa(pullNum)
+	repoRuns := pruneRuns(s.repoRuns, repoKey, now)
+	pullRuns := pruneRuns(s.pullRuns, pullKey, now)
+	limit, scope, runs := 0, "", []time.Time(nil)
+	switch {
+	case s.RepoRateLimit > 0 && len(repoRuns) >= s.RepoRateLimit:
+		limit, scope, runs = s.RepoRateLimit, "repo", repoRuns
+	case s.PullRateLimit > 0 && len(pullRuns) >= s.PullRateLimit:
+		limit, scope, runs = s.PullRateLimit, "pull request", pullRuns
+	}
+	if limit == 0 {
+		if s.RepoRateLimit > 0 {
+			s.repoRuns[repoKey] = append(repoRuns, now)
+		}
+		if s.PullRateLimit > 0 {
+			s.pullRuns[pullKey] = append(pullRuns, now)
+		}
+		s.mutex.Unlock()
+		return true
+	}
+	retryIn := runs[len(runs)-limit].Add(rateLimitWindow).Sub(now).Round(time.Second)
+	s.mutex.Unlock()
+
+	logger.Warn("rejecting the command since the %s exceeded its rate limit of %d commands per minute", scope, limit)
+	comment := fmt.Sprintf(RateLimitedComment, limit, scope, max(retryIn, time.Second))
+	if err := s.VCSClient.CreateComment(logger, baseRepo, pullNum, comment, ""); err != nil {
+		logger.Err("unable to comment: %s", err)
+	}
+	return false
+}
+
+// pruneRuns removes the runs of key that are out of the window, returning the
+// remaining ones.
+func pruneRuns(runs map[string][]time.Time, key string, now time.Time) []time.Time {
+	remaining := runs[key]
+	for len(remaining) > 0 && now.Sub(remaining[0]) >= rateLimitWindow {
+		remaining = remaining[1:]
+	}
+	if len(remaining) == 0 {
+		delete(runs, key)
+		return nil
+	}
+	runs[key] = remaining
+	return remaining
+}
+
+// acquire blocks until a command of baseRepo can run, returning a function
+// releasing its turn once it completes.
+func (s *CommandScheduler) acquire(logger logging.SimpleLogging, baseRepo models.Repo) func() {
+	if s.MaxConcurrent <= 0 {
+		return func() {}
+	}
+	s.mutex.Lock()
+	if s.running < s.MaxConcurrent && len(s.turns) == 0 {
+		s.running++
+		s.mutex.Unlock()
+		return s.release
+	}
+	if s.waiting == nil {
+		s.waiting = map[string][]chan struct{}{}
+	}
+	turn := make(chan struct{})
+	if len(s.waiting[baseRepo.FullName]) == 0 {
+		s.turns = append(s.turns, baseRepo.FullName)
+	}
+	s.waiting[baseRepo.FullName] = append(s.waiting[baseRepo.FullName], turn)
+	s.mutex.Unlock()
+
+	logger.Info("waiting for one of the %d commands running to complete", s.MaxConcurrent)
+	start := s.clock()
+	<-turn
+	logger.Info("running the command after waiting %s", s.clock().Sub(start).Round(time.Millisecond))
+	return s.release
+}
+
+// release hands the turn of a completed command to the next repo with waiting
+// commands.
+func (s *CommandScheduler) release() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.turns) == 0 {
+		s.running--
+		return
+	}
+	repo := s.turns[0]
+	s.turns = s.turns[1:]
+	waiting := s.waiting[repo]
+	if len(waiting) > 1 {
+		s.waiting[repo] = waiting[1:]
+		s.turns = append(s.turns, repo)
+	} else {
+		delete(s.waiting, repo)
+	}
+	close(waiting[0])
+}
+
+func (s *CommandScheduler) clock() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/events/models"
+	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// admitted runs a command of the pull request through scheduler, reporting
+// it to started, as repo#pull, once it's admitted and blocking it until
+// proceed is sent to, if it's set.
+func admitted(scheduler *CommandScheduler, logger logging.SimpleLogging, repo models.Repo, pullNum int, started chan<- string, proceed <-chan struct{}) {
+	release, ok := scheduler.Admit(logger, repo, pullNum)
+	if !ok {
+		return
+	}
+	defer release()
+	started <- fmt.Sprintf("%s#%d", repo.FullName, pullNum)
+	if proceed != nil {
+		<-proceed
+	}
+}
+
+func TestCommandScheduler_RateLimits(t *testing.T) {
+	RegisterMockTestingT(t)
+	vcsClient := vcsmocks.NewMockClient()
+	started := make(chan string, 10)
+	now := time.Now()
+	scheduler := &CommandScheduler{
+		VCSClient:     vcsClient,
+		RepoRateLimit: 2,
+		PullRateLimit: 1,
+		now:           func() time.Time { return now },
+	}
+	logger := logging.NewNoopLogger(t)
+	repo := models.Repo{FullName: "owner/repo"}
+
+	admitted(scheduler, logger, repo, 1, started, nil)
+	Equals(t, "owner/repo#1", <-started)
+
+	t.Log("the pull request exceeds its rate limit")
+	now = now.Add(20 * time.Second)
+	admitted(scheduler, logger, repo, 1, started, nil)
+	vcsClient.VerifyWasCalledOnce().CreateComment(
+		Any[logging.SimpleLogging](), Eq(repo), Eq(1), Eq(fmt.Sprintf(RateLimitedComment, 1, "pull request", "40s")), Eq(""))
+
+	t.Log("the repo exceeds its rate limit")
+	admitted(scheduler, logger, repo, 2, started, nil)
+	Equals(t, "owner/repo#2", <-started)
+	now = now.Add(10 * time.Second)
+	admitted(scheduler, logger, repo, 3, started, nil)
+	vcsClient.VerifyWasCalledOnce().CreateComment(
+		Any[logging.SimpleLogging](), Eq(repo), Eq(3), Eq(fmt.Sprintf(RateLimitedComment, 2, "repo", "30s")), Eq(""))
+
+	t.Log("the other repos aren't limited")
+	other := models.Repo{FullName: "owner/other"}
+	admitted(scheduler, logger, other, 1, started, nil)
+	Equals(t, "owner/other#1", <-started)
+
+	t.Log("commands can run again once the window passed")
+	now = now.Add(30 * time.Second)
+	admitted(scheduler, logger, repo, 3, started, nil)
+	Equals(t, "owner/repo#3", <-started)
+	Equals(t, 0, len(started))
+}
+
+func TestCommandScheduler_ReposTakeTurns(t *testing.T) {
+	RegisterMockTestingT(t)
+	started, proceed := make(chan string), make(chan struct{})
+	scheduler := &CommandScheduler{
+		VCSClient:     vcsmocks.NewMockClient(),
+		MaxConcurrent: 1,
+	}
+	logger := logging.NewNoopLogger(t)
+	busy := models.Repo{FullName: "owner/busy"}
+	quiet := models.Repo{FullName: "owner/quiet"}
+	run := func(repo models.Repo, pullNum int, expWaiting int) {
+		go admitted(scheduler, logger, repo, pullNum, started, proceed)
+		// Wait for the command to be queued so the order is deterministic.
+		for {
+			scheduler.mutex.Lock()
+			waiting := 0
+			for _, turns := range scheduler.waiting {
+				waiting += len(turns)
+			}
+			scheduler.mutex.Unlock()
+			if waiting == expWaiting {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	go admitted(scheduler, logger, busy, 1, started, proceed)
+	Equals(t, "owner/busy#1", <-started)
+	run(busy, 2, 1)
+	run(busy, 3, 2)
+	run(busy, 4, 3)
+	run(quiet, 1, 4)
+
+	var order []string
+	for range 4 {
+		proceed <- struct{}{}
+		order = append(order, <-started)
+	}
+	proceed <- struct{}{}
+	Equals(t, []string{"owner/busy#2", "owner/quiet#1", "owner/busy#3", "owner/busy#4"}, order)
+
+	Assert(t, eventually(func() bool {
+		scheduler.mutex.Lock()
+		defer scheduler.mutex.Unlock()
+		return scheduler.running == 0 && len(scheduler.turns) == 0
+	}), "exp every command to release its turn")
+}
+
+func eventually(condition func() bool) bool {
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if condition() {
+			return true
+		}
+	}
+	return false
+}
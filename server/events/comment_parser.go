@@ -49,6 +49,14 @@ const (
 	verboseFlagShort             = ""
 	clearPolicyApprovalFlagLong  = "clear-policy-approval"
 	clearPolicyApprovalFlagShort = ""
+	failedFlagLong               = "failed"
+	failedFlagShort              = ""
+	confirmFlagLong              = "confirm"
+	confirmFlagShort             = ""
+	breakGlassFlagLong           = "break-glass"
+	breakGlassFlagShort          = ""
+	destroyFlagLong              = "destroy"
+	destroyFlagShort             = ""
 )
 
 // multiLineRegex is used to ignore multi-line comments since those aren't valid
@@ -86,12 +94,13 @@ type CommentParser struct {
 	GiteaUser       string
 	BitbucketUser   string
 	AzureDevopsUser string
+	GerritUser      string
 	ExecutableName  string
 	AllowCommands   []command.Name
 }
 
 // NewCommentParser returns a CommentParser
-func NewCommentParser(githubUser, gitlabUser, giteaUser, bitbucketUser, azureDevopsUser, executableName string, allowCommands []command.Name) *CommentParser {
+func NewCommentParser(githubUser, gitlabUser, giteaUser, bitbucketUser, azureDevopsUser, gerritUser, executableName string, allowCommands []command.Name) *CommentParser {
 	var commentAllowCommands []command.Name
 	for _, acceptableCommand := range command.AllCommentCommands {
 		for _, allowCommand := range allowCommands {
@@ -108,6 +117,7 @@ func NewCommentParser(githubUser, gitlabUser, giteaUser, bitbucketUser, azureDev
 		GiteaUser:       giteaUser,
 		BitbucketUser:   bitbucketUser,
 		AzureDevopsUser: azureDevopsUser,
+		GerritUser:      gerritUser,
 		ExecutableName:  executableName,
 		AllowCommands:   commentAllowCommands,
 	}
@@ -187,6 +197,8 @@ func (e *CommentParser) Parse(rawComment string, vcsHost models.VCSHostType) Com
 		vcsUser = e.BitbucketUser
 	case models.AzureDevops:
 		vcsUser = e.AzureDevopsUser
+	case models.Gerrit:
+		vcsUser = e.GerritUser
 	}
 	executableNames := []string{"run", e.ExecutableName, "@" + vcsUser}
 	if !slices.Contains(executableNames, executableName) {
@@ -234,6 +246,10 @@ func (e *CommentParser) Parse(rawComment string, vcsHost models.VCSHostType) Com
 	var verbose bool
 	var autoMergeDisabled bool
 	var autoMergeMethod string
+	var failed bool
+	var confirmToken string
+	var breakGlass bool
+	var destroy bool
 	var flagSet *pflag.FlagSet
 	var name command.Name
 
@@ -246,6 +262,7 @@ func (e *CommentParser) Parse(rawComment string, vcsHost models.VCSHostType) Com
 		flagSet.StringVarP(&workspace, workspaceFlagLong, workspaceFlagShort, "", "Switch to this Terraform workspace before planning.")
 		flagSet.StringVarP(&dir, dirFlagLong, dirFlagShort, "", "Which directory to run plan in relative to root of repo, ex. 'child/dir'.")
 		flagSet.StringVarP(&project, projectFlagLong, projectFlagShort, "", "Which project to run plan for. Refers to the name of the project configured in a repo config file. Cannot be used at same time as workspace or dir flags.")
+		flagSet.BoolVarP(&failed, failedFlagLong, failedFlagShort, false, "Only re-run plan for the projects that failed to plan. Cannot be used at same time as project, workspace or dir flags.")
 		flagSet.BoolVarP(&verbose, verboseFlagLong, verboseFlagShort, false, "Append Atlantis log to comment.")
 	case command.Apply.String():
 		name = command.Apply
@@ -256,6 +273,9 @@ func (e *CommentParser) Parse(rawComment string, vcsHost models.VCSHostType) Com
 		flagSet.StringVarP(&project, projectFlagLong, projectFlagShort, "", "Apply the plan for this project. Refers to the name of the project configured in a repo config file. Cannot be used at same time as workspace or dir flags.")
 		flagSet.BoolVarP(&autoMergeDisabled, autoMergeDisabledFlagLong, autoMergeDisabledFlagShort, false, "Disable automerge after apply.")
 		flagSet.StringVarP(&autoMergeMethod, autoMergeMethodFlagLong, autoMergeMethodFlagShort, "", "Specifies the merge method for the VCS if automerge is enabled. (Currently only implemented for GitHub)")
+		flagSet.BoolVarP(&failed, failedFlagLong, failedFlagShort, false, "Only re-run apply for the projects that failed to apply. Cannot be used at same time as project, workspace or dir flags.")
+		flagSet.StringVarP(&confirmToken, confirmFlagLong, confirmFlagShort, "", "Confirm an apply that requires confirmation with the token Atlantis commented.")
+		flagSet.BoolVarP(&breakGlass, breakGlassFlagLong, breakGlassFlagShort, false, "Apply during a change freeze. Only the users allowed to override the freeze can.")
 		flagSet.BoolVarP(&verbose, verboseFlagLong, verboseFlagShort, false, "Append Atlantis log to comment.")
 	case command.ApprovePolicies.String():
 		name = command.ApprovePolicies
@@ -298,6 +318,21 @@ func (e *CommentParser) Parse(rawComment string, vcsHost models.VCSHostType) Com
 		flagSet.StringVarP(&dir, dirFlagLong, dirFlagShort, "", "Which directory to run state command in relative to root of repo, ex. 'child/dir'.")
 		flagSet.StringVarP(&project, projectFlagLong, projectFlagShort, "", "Which project to run state command for. Refers to the name of the project configured in a repo config file. Cannot be used at same time as workspace or dir flags.")
 		flagSet.BoolVarP(&verbose, verboseFlagLong, verboseFlagShort, false, "Append Atlantis log to comment.")
+	case command.Summary.String():
+		name = command.Summary
+		flagSet = pflag.NewFlagSet(command.Summary.String(), pflag.ContinueOnError)
+		flagSet.SetOutput(io.Discard)
+		flagSet.StringVarP(&workspace, workspaceFlagLong, workspaceFlagShort, "", "Summarize the plan for this Terraform workspace.")
+		flagSet.StringVarP(&dir, dirFlagLong, dirFlagShort, "", "Summarize the plan for this directory, relative to root of repo, ex. 'child/dir'.")
+		flagSet.StringVarP(&project, projectFlagLong, projectFlagShort, "", "Summarize the plan for this project. Refers to the name of the project configured in a repo config file. Cannot be used at same time as workspace or dir flags.")
+	case command.Preview.String():
+		name = command.Preview
+		flagSet = pflag.NewFlagSet(command.Preview.String(), pflag.ContinueOnError)
+		flagSet.SetOutput(io.Discard)
+		flagSet.StringVarP(&dir, dirFlagLong, dirFlagShort, "", "Deploy the preview environment of the project in this directory, relative to root of repo, ex. 'child/dir'.")
+		flagSet.StringVarP(&project, projectFlagLong, projectFlagShort, "", "Deploy the preview environment of this project. Refers to the name of the project configured in a repo config file. Cannot be used at same time as dir flag.")
+		flagSet.BoolVarP(&destroy, destroyFlagLong, destroyFlagShort, false, "Destroy the preview environments instead of deploying them.")
+		flagSet.BoolVarP(&verbose, verboseFlagLong, verboseFlagShort, false, "Append Atlantis log to comment.")
 	default:
 		return CommentParseResult{CommentResponse: fmt.Sprintf("Error: unknown command %q – this is a bug", cmd)}
 	}
@@ -329,6 +364,11 @@ func (e *CommentParser) Parse(rawComment string, vcsHost models.VCSHostType) Com
 		return CommentParseResult{CommentResponse: e.errMarkdown(err, cmd, flagSet)}
 	}
 
+	if failed && (project != "" || workspace != "" || dir != "") {
+		err := fmt.Sprintf("cannot use --%s at same time as -%s/--%s, -%s/--%s or -%s/--%s", failedFlagLong, projectFlagShort, projectFlagLong, dirFlagShort, dirFlagLong, workspaceFlagShort, workspaceFlagLong)
+		return CommentParseResult{CommentResponse: e.errMarkdown(err, cmd, flagSet)}
+	}
+
 	if autoMergeMethod != "" {
 		if autoMergeDisabled {
 			err := fmt.Sprintf("cannot use --%s at the same time as --%s", autoMergeMethodFlagLong, autoMergeDisabledFlagLong)
@@ -341,9 +381,12 @@ func (e *CommentParser) Parse(rawComment string, vcsHost models.VCSHostType) Com
 		}
 	}
 
-	return CommentParseResult{
-		Command: NewCommentCommand(dir, extraArgs, name, subName, verbose, autoMergeDisabled, autoMergeMethod, workspace, project, policySet, clearPolicyApproval),
-	}
+	commentCmd := NewCommentCommand(dir, extraArgs, name, subName, verbose, autoMergeDisabled, autoMergeMethod, workspace, project, policySet, clearPolicyApproval)
+	commentCmd.Failed = failed
+	commentCmd.ConfirmToken = confirmToken
+	commentCmd.BreakGlass = breakGlass
+	commentCmd.Destroy = destroy
+	return CommentParseResult{Command: commentCmd}
 }
 
 func (e *CommentParser) parseArgs(name command.Name, args []string, flagSet *pflag.FlagSet) (string, []string, string) {
@@ -544,6 +587,8 @@ func (e *CommentParser) HelpComment() string {
 		AllowApprovePolicies bool
 		AllowImport          bool
 		AllowState           bool
+		AllowSummary         bool
+		AllowPreview         bool
 	}{
 		ExecutableName:       e.ExecutableName,
 		AllowVersion:         e.isAllowedCommand(command.Version.String()),
@@ -553,6 +598,8 @@ func (e *CommentParser) HelpComment() string {
 		AllowApprovePolicies: e.isAllowedCommand(command.ApprovePolicies.String()),
 		AllowImport:          e.isAllowedCommand(command.Import.String()),
 		AllowState:           e.isAllowedCommand(command.State.String()),
+		AllowSummary:         e.isAllowedCommand(command.Summary.String()),
+		AllowPreview:         e.isAllowedCommand(command.Preview.String()),
 	}); err != nil {
 		return fmt.Sprintf("Failed to render template, this is a bug: %v", err)
 	}
@@ -587,10 +634,12 @@ Commands:
 {{- if .AllowPlan }}
   plan     Runs 'terraform plan' for the changes in this pull request.
            To plan a specific project, use the -d, -w and -p flags.
+           To only re-plan the projects that failed, use the --failed flag.
 {{- end }}
 {{- if .AllowApply }}
   apply    Runs 'terraform apply' on all unapplied plans from this pull request.
            To only apply a specific plan, use the -d, -w and -p flags.
+           To only re-apply the projects that failed, use the --failed flag.
 {{- end }}
 {{- if .AllowUnlock }}
   unlock   Removes all atlantis locks and discards all plans for this PR.
@@ -612,6 +661,16 @@ Commands:
   state rm ADDRESS...
            Runs 'terraform state rm' for the passed address resource.
            To remove a specific project resource, use the -d, -w and -p flags.
+{{- end }}
+{{- if .AllowSummary }}
+  summary  Regenerates the AI summary of the current plans without planning again.
+           To summarize a specific plan, use the -d, -w and -p flags.
+{{- end }}
+{{- if .AllowPreview }}
+  preview  Applies the pull request in the preview environments of the
+           preview projects and comments their outputs.
+           To deploy a specific project's environment, use the -d and -p flags.
+           To destroy the environments, use the --destroy flag.
 {{- end }}
   help     View help.
 
@@ -40,6 +40,7 @@ func TestNewCommentParser(t *testing.T) {
 		giteaUser       string
 		bitbucketUser   string
 		azureDevopsUser string
+		gerritUser      string
 		executableName  string
 		allowCommands   []command.Name
 	}
@@ -70,7 +71,7 @@ func TestNewCommentParser(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equalf(t, tt.want, events.NewCommentParser(tt.args.githubUser, tt.args.gitlabUser, tt.args.giteaUser, tt.args.bitbucketUser, tt.args.azureDevopsUser, tt.args.executableName, tt.args.allowCommands), "NewCommentParser(%v, %v, %v, %v, %v, %v)", tt.args.githubUser, tt.args.gitlabUser, tt.args.bitbucketUser, tt.args.azureDevopsUser, tt.args.executableName, tt.args.allowCommands)
+			assert.Equalf(t, tt.want, events.NewCommentParser(tt.args.githubUser, tt.args.gitlabUser, tt.args.giteaUser, tt.args.bitbucketUser, tt.args.azureDevopsUser, tt.args.gerritUser, tt.args.executableName, tt.args.allowCommands), "NewCommentParser(%v, %v, %v, %v, %v, %v, %v)", tt.args.githubUser, tt.args.gitlabUser, tt.args.bitbucketUser, tt.args.azureDevopsUser, tt.args.gerritUser, tt.args.executableName, tt.args.allowCommands)
 		})
 	}
 }
@@ -298,6 +299,7 @@ func TestParse_InvalidCommand(t *testing.T) {
 		"gitea-user",
 		"bitbucket-user",
 		"azure-devops-user",
+		"gerrit-user",
 		"atlantis",
 		[]command.Name{
 			command.Version,
@@ -548,6 +550,65 @@ func TestParse_UsingProjectAtSameTimeAsWorkspaceOrDir(t *testing.T) {
 	}
 }
 
+func TestParse_UsingFailedAtSameTimeAsProjectWorkspaceOrDir(t *testing.T) {
+	cases := []string{
+		"atlantis plan --failed -p project",
+		"atlantis plan --failed -d dir",
+		"atlantis apply --failed -w workspace",
+	}
+	for _, c := range cases {
+		t.Run(c, func(t *testing.T) {
+			r := commentParser.Parse(c, models.Github)
+			exp := "Error: cannot use --failed at same time as -p/--project, -d/--dir or -w/--workspace"
+			Assert(t, strings.Contains(r.CommentResponse, exp),
+				"For comment %q expected CommentResponse %q to contain %q", c, r.CommentResponse, exp)
+		})
+	}
+}
+
+func TestParse_Failed(t *testing.T) {
+	cases := []struct {
+		comment string
+		exp     events.CommentCommand
+	}{
+		{
+			"atlantis plan --failed",
+			events.CommentCommand{Name: command.Plan, Failed: true},
+		},
+		{
+			"atlantis apply --failed",
+			events.CommentCommand{Name: command.Apply, Failed: true},
+		},
+		{
+			"atlantis plan --failed -- -refresh=false",
+			events.CommentCommand{Name: command.Plan, Failed: true, Flags: []string{"-refresh=false"}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.comment, func(t *testing.T) {
+			r := commentParser.Parse(c.comment, models.Github)
+			Equals(t, "", r.CommentResponse)
+			Equals(t, &c.exp, r.Command)
+		})
+	}
+}
+
+func TestParse_Confirm(t *testing.T) {
+	r := commentParser.Parse("atlantis apply --confirm 3f9a1c", models.Github)
+	Equals(t, "", r.CommentResponse)
+	Equals(t, &events.CommentCommand{Name: command.Apply, ConfirmToken: "3f9a1c"}, r.Command)
+
+	r = commentParser.Parse("atlantis apply -p project --confirm 3f9a1c", models.Github)
+	Equals(t, "", r.CommentResponse)
+	Equals(t, &events.CommentCommand{Name: command.Apply, ProjectName: "project", ConfirmToken: "3f9a1c"}, r.Command)
+}
+
+func TestParse_BreakGlass(t *testing.T) {
+	r := commentParser.Parse("atlantis apply -p project --break-glass", models.Github)
+	Equals(t, "", r.CommentResponse)
+	Equals(t, &events.CommentCommand{Name: command.Apply, ProjectName: "project", BreakGlass: true}, r.Command)
+}
+
 func TestParse_Parsing(t *testing.T) {
 	cases := []struct {
 		flags        string
@@ -811,6 +872,63 @@ func TestParse_Parsing(t *testing.T) {
 	}
 }
 
+func TestParse_Summary(t *testing.T) {
+	cases := []struct {
+		comment string
+		exp     events.CommentCommand
+	}{
+		{
+			"atlantis summary",
+			events.CommentCommand{Name: command.Summary},
+		},
+		{
+			"atlantis summary -p project1",
+			events.CommentCommand{Name: command.Summary, ProjectName: "project1"},
+		},
+		{
+			"atlantis summary -d dir -w staging",
+			events.CommentCommand{Name: command.Summary, RepoRelDir: "dir", Workspace: "staging"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.comment, func(t *testing.T) {
+			r := commentParser.Parse(c.comment, models.Github)
+			Equals(t, "", r.CommentResponse)
+			Equals(t, &c.exp, r.Command)
+		})
+	}
+}
+
+func TestParse_Preview(t *testing.T) {
+	cases := []struct {
+		comment string
+		exp     events.CommentCommand
+	}{
+		{
+			"atlantis preview",
+			events.CommentCommand{Name: command.Preview},
+		},
+		{
+			"atlantis preview -p app",
+			events.CommentCommand{Name: command.Preview, ProjectName: "app"},
+		},
+		{
+			"atlantis preview -d app --destroy",
+			events.CommentCommand{Name: command.Preview, RepoRelDir: "app", Destroy: true},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.comment, func(t *testing.T) {
+			r := commentParser.Parse(c.comment, models.Github)
+			Equals(t, "", r.CommentResponse)
+			Equals(t, &c.exp, r.Command)
+		})
+	}
+
+	r := commentParser.Parse("atlantis preview -w staging", models.Github)
+	Assert(t, strings.Contains(r.CommentResponse, "unknown shorthand flag: 'w'"), "unexpected response %q", r.CommentResponse)
+}
+
 func TestBuildPlanApplyVersionComment(t *testing.T) {
 	cases := []struct {
 		repoRelDir        string
@@ -973,8 +1091,10 @@ Examples:
 Commands:
   plan     Runs 'terraform plan' for the changes in this pull request.
            To plan a specific project, use the -d, -w and -p flags.
+           To only re-plan the projects that failed, use the --failed flag.
   apply    Runs 'terraform apply' on all unapplied plans from this pull request.
            To only apply a specific plan, use the -d, -w and -p flags.
+           To only re-apply the projects that failed, use the --failed flag.
   unlock   Removes all atlantis locks and discards all plans for this PR.
            To unlock a specific plan you can use the Atlantis UI.
   approve_policies
@@ -986,6 +1106,12 @@ Commands:
   state rm ADDRESS...
            Runs 'terraform state rm' for the passed address resource.
            To remove a specific project resource, use the -d, -w and -p flags.
+  summary  Regenerates the AI summary of the current plans without planning again.
+           To summarize a specific plan, use the -d, -w and -p flags.
+  preview  Applies the pull request in the preview environments of the
+           preview projects and comments their outputs.
+           To deploy a specific project's environment, use the -d and -p flags.
+           To destroy the environments, use the --destroy flag.
   help     View help.
 
 Flags:
@@ -1043,6 +1169,7 @@ Examples:
 Commands:
   apply    Runs 'terraform apply' on all unapplied plans from this pull request.
            To only apply a specific plan, use the -d, -w and -p flags.
+           To only re-apply the projects that failed, use the --failed flag.
   unlock   Removes all atlantis locks and discards all plans for this PR.
            To unlock a specific plan you can use the Atlantis UI.
   help     View help.
@@ -1111,6 +1238,9 @@ func TestParse_VCSUsername(t *testing.T) {
 var PlanUsage = `Usage of plan:
   -d, --dir string         Which directory to run plan in relative to root of repo,
                            ex. 'child/dir'.
+      --failed             Only re-run plan for the projects that failed to plan.
+                           Cannot be used at same time as project, workspace or dir
+                           flags.
   -p, --project string     Which project to run plan for. Refers to the name of the
                            project configured in a repo config file. Cannot be used
                            at same time as workspace or dir flags.
@@ -1123,8 +1253,15 @@ var ApplyUsage = `Usage of apply:
       --auto-merge-method string   Specifies the merge method for the VCS if
                                    automerge is enabled. (Currently only implemented
                                    for GitHub)
+      --break-glass                Apply during a change freeze. Only the users
+                                   allowed to override the freeze can.
+      --confirm string             Confirm an apply that requires confirmation with
+                                   the token Atlantis commented.
   -d, --dir string                 Apply the plan for this directory, relative to
                                    root of repo, ex. 'child/dir'.
+      --failed                     Only re-run apply for the projects that failed to
+                                   apply. Cannot be used at same time as project,
+                                   workspace or dir flags.
   -p, --project string             Apply the plan for this project. Refers to the
                                    name of the project configured in a repo config
                                    file. Cannot be used at same time as workspace or
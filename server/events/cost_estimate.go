@@ -0,0 +1,78 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// costFileJSON is the subset of infracost's json output a cost estimate is
+// read from. Other cost engines can be plugged in with a custom run step that
+// writes the same fields to COSTFILE. Infracost writes the costs as strings,
+// numbers are accepted too.
+type costFileJSON struct {
+	Currency             string      `json:"currency"`
+	TotalMonthlyCost     json.Number `json:"totalMonthlyCost"`
+	PastTotalMonthlyCost json.Number `json:"pastTotalMonthlyCost"`
+	DiffTotalMonthlyCost json.Number `json:"diffTotalMonthlyCost"`
+}
+
+// readCostEstimate reads the cost estimate the cost step, or a custom run
+// step, wrote to path. It returns nil if there's no estimate.
+func readCostEstimate(path string) (*models.CostEstimate, error) {
+	contents, err := os.ReadFile(path) // nolint: gosec
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cost costFileJSON
+	if err := json.Unmarshal(contents, &cost); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	var estimate models.CostEstimate
+	estimate.Currency = cost.Currency
+	if estimate.MonthlyCost, err = parseCost(cost.TotalMonthlyCost); err != nil {
+		return nil, fmt.Errorf("parsing totalMonthlyCost: %w", err)
+	}
+	if estimate.PastMonthlyCost, err = parseCost(cost.PastTotalMonthlyCost); err != nil {
+		return nil, fmt.Errorf("parsing pastTotalMonthlyCost: %w", err)
+	}
+	estimate.MonthlyDelta = estimate.MonthlyCost - estimate.PastMonthlyCost
+	if cost.DiffTotalMonthlyCost != "" {
+		if estimate.MonthlyDelta, err = parseCost(cost.DiffTotalMonthlyCost); err != nil {
+			return nil, fmt.Errorf("parsing diffTotalMonthlyCost: %w", err)
+		}
+	}
+	return &estimate, nil
+}
+
+// parseCost parses a cost, a missing cost is zero.
+func parseCost(cost json.Number) (float64, error) {
+	if cost == "" {
+		return 0, nil
+	}
+	return cost.Float64()
+}
+
+// costEstimateFor returns the cost estimate of the plan of the project
+// described by ctx, or nil if its workflow doesn't estimate costs.
+func costEstimateFor(ctx command.ProjectContext, absPath string) *models.CostEstimate {
+	estimate, err := readCostEstimate(filepath.Join(absPath, ctx.GetCostFileName()))
+	if err != nil {
+		ctx.Log.Warn("unable to read cost estimate: %s", err)
+		return nil
+	}
+	if estimate != nil {
+		estimate.Threshold = ctx.CostThreshold
+	}
+	return estimate
+}
@@ -0,0 +1,55 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestReadCostEstimate(t *testing.T) {
+	cases := map[string]struct {
+		cost   string
+		exp    *models.CostEstimate
+		expErr string
+	}{
+		"infracost": {
+			cost: `{"version":"0.2","currency":"USD","totalMonthlyCost":"743.7","pastTotalMonthlyCost":"700","diffTotalMonthlyCost":"43.7","projects":[]}`,
+			exp:  &models.CostEstimate{Currency: "USD", MonthlyCost: 743.7, PastMonthlyCost: 700, MonthlyDelta: 43.7},
+		},
+		"numbers without diff": {
+			cost: `{"currency":"EUR","totalMonthlyCost":20,"pastTotalMonthlyCost":50}`,
+			exp:  &models.CostEstimate{Currency: "EUR", MonthlyCost: 20, PastMonthlyCost: 50, MonthlyDelta: -30},
+		},
+		"no past cost": {
+			cost: `{"currency":"USD","totalMonthlyCost":"12.5","pastTotalMonthlyCost":null}`,
+			exp:  &models.CostEstimate{Currency: "USD", MonthlyCost: 12.5, MonthlyDelta: 12.5},
+		},
+		"invalid cost": {
+			cost:   `{"totalMonthlyCost":"a lot"}`,
+			expErr: "cannot unmarshal string",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "default-cost.json")
+			Ok(t, os.WriteFile(path, []byte(c.cost), 0600))
+			estimate, err := readCostEstimate(path)
+			if c.expErr != "" {
+				ErrContains(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, c.exp, estimate)
+		})
+	}
+
+	estimate, err := readCostEstimate(filepath.Join(t.TempDir(), "default-cost.json"))
+	Ok(t, err)
+	Assert(t, estimate == nil, "expected no estimate, got %v", estimate)
+}
@@ -0,0 +1,134 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// DeferredComment is commented when a command is requested while Atlantis is
+// draining and it's deferred until Atlantis restarts.
+const DeferredComment = "Atlantis server is restarting, this command will run once it's back up."
+
+// DeferredCommands keeps the commands requested while Atlantis is draining in
+// a file, so they run once it restarts instead of being rejected.
+type DeferredCommands struct {
+	// Path is the file the commands are kept in.
+	Path string
+	// CloneCredentials add the credentials to the clone URLs of the repos of
+	// the commands, they aren't kept in the file.
+	CloneCredentials CloneCredentials
+
+	mutex sync.Mutex
+}
+
+// Add defers item until the next Replay. The credentials of the clone URLs
+// of its repos aren't kept.
+func (d *DeferredCommands) Add(item WorkItem) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	items, err := d.read()
+	if err != nil {
+		return err
+	}
+	item.EnqueuedAt = time.Now()
+	return d.write(append(items, item.WithoutCloneCredentials()))
+}
+
+// Count returns how many commands are deferred.
+func (d *DeferredCommands) Count() (int, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	items, err := d.read()
+	return len(items), err
+}
+
+// Replay removes the deferred commands and runs them with runner in the
+// background. The commands of each pull request run one after the other in
+// the order they were deferred, ex. so an apply runs after the plan it
+// applies.
+func (d *DeferredCommands) Replay(runner CommandRunner, logger logging.SimpleLogging) error {
+	d.mutex.Lock()
+	items, err := d.read()
+	if err == nil && len(items) > 0 {
+		err = d.write(nil)
+	}
+	d.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	type pullKey struct {
+		repoFullName string
+		pullNum      int
+	}
+	var pulls []pullKey
+	pullItems := make(map[pullKey][]WorkItem)
+	for _, item := range items {
+		key := pullKey{item.BaseRepo.FullName, item.PullNum}
+		if _, ok := pullItems[key]; !ok {
+			pulls = append(pulls, key)
+		}
+		pullItems[key] = append(pullItems[key], item)
+	}
+	for _, key := range pulls {
+		go func(items []WorkItem) {
+			for _, item := range items {
+				itemLogger := logger.With(
+					logging.CorrelationIDKey, item.CorrelationID,
+					"repo", item.BaseRepo.FullName,
+					"pull", strconv.Itoa(item.PullNum),
+				)
+				itemLogger.Info("running %s deferred %s ago", item, time.Since(item.EnqueuedAt).Round(time.Millisecond))
+				var err error
+				if d.CloneCredentials != nil {
+					item, err = item.withCloneCredentials(d.CloneCredentials)
+				}
+				if err != nil {
+					itemLogger.Err("unable to add the VCS credentials to the clone URLs of %s: %s", item, err)
+				} else if err := runWorkItem(runner, itemLogger, item); err != nil {
+					itemLogger.Err("unable to run %s: %s", item, err)
+				}
+			}
+		}(pullItems[key])
+	}
+	return nil
+}
+
+func (d *DeferredCommands) read() ([]WorkItem, error) {
+	serialized, err := os.ReadFile(d.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading the deferred commands: %w", err)
+	}
+	var items []WorkItem
+	if err := json.Unmarshal(serialized, &items); err != nil {
+		return nil, fmt.Errorf("parsing the deferred commands in %s: %w", d.Path, err)
+	}
+	return items, nil
+}
+
+func (d *DeferredCommands) write(items []WorkItem) error {
+	if len(items) == 0 {
+		if err := os.Remove(d.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	serialized, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(d.Path, serialized)
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/models/testdata"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeferredCommands(t *testing.T) {
+	RegisterMockTestingT(t)
+	path := filepath.Join(t.TempDir(), "deferred-commands.json")
+	deferred := &events.DeferredCommands{Path: path}
+	repo := testdata.GithubRepo
+	pull := models.PullRequest{Num: 1, BaseRepo: repo}
+	Ok(t, deferred.Add(events.WorkItem{Autoplan: true, BaseRepo: repo, HeadRepo: &repo, Pull: &pull, PullNum: 1}))
+	Ok(t, deferred.Add(events.WorkItem{BaseRepo: repo, PullNum: 2, User: models.User{Username: "jdoe"}, Command: &events.CommentCommand{Name: command.Apply}}))
+	serialized, err := os.ReadFile(path)
+	Ok(t, err)
+	Assert(t, !strings.Contains(string(serialized), "password"), "exp no credentials in the deferred commands %s", serialized)
+
+	t.Log("the commands are kept across restarts")
+	deferred = &events.DeferredCommands{Path: path, CloneCredentials: testCloneCredentials{}}
+	count, err := deferred.Count()
+	Ok(t, err)
+	Equals(t, 2, count)
+
+	commandRunner := mocks.NewMockCommandRunner()
+	Ok(t, deferred.Replay(commandRunner, logging.NewNoopLogger(t)))
+	commandRunner.VerifyWasCalledEventually(Once(), 5*time.Second).RunAutoplanCommand(
+		Any[logging.SimpleLogging](), Eq(repo), Eq(repo), Eq(pull), Eq(models.User{}))
+	commandRunner.VerifyWasCalledEventually(Once(), 5*time.Second).RunCommentCommand(
+		Any[logging.SimpleLogging](), Eq(repo), Any[*models.Repo](), Any[*models.PullRequest](), Eq(models.User{Username: "jdoe"}), Eq(2),
+		Eq(&events.CommentCommand{Name: command.Apply}))
+
+	t.Log("replayed commands are removed")
+	count, err = deferred.Count()
+	Ok(t, err)
+	Equals(t, 0, count)
+	_, err = os.Stat(path)
+	Assert(t, os.IsNotExist(err), "exp the file to be removed")
+}
+
+// blockingCommandRunner records the comment commands it runs, blocking on
+// release while running the commands of pull request 1.
+type blockingCommandRunner struct {
+	mutex   sync.Mutex
+	ran     []string
+	release chan struct{}
+}
+
+func (b *blockingCommandRunner) RunCommentCommand(_ logging.SimpleLogging, _ models.Repo, _ *models.Repo, _ *models.PullRequest, _ models.User, pullNum int, cmd *events.CommentCommand) {
+	if pullNum == 1 {
+		<-b.release
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.ran = append(b.ran, fmt.Sprintf("%d %s", pullNum, cmd.Name))
+}
+
+func (b *blockingCommandRunner) RunAutoplanCommand(logging.SimpleLogging, models.Repo, models.Repo, models.PullRequest, models.User) {
+}
+
+func (b *blockingCommandRunner) commands() []string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return slices.Clone(b.ran)
+}
+
+func TestDeferredCommands_ReplaysPullsSequentially(t *testing.T) {
+	deferred := &events.DeferredCommands{Path: filepath.Join(t.TempDir(), "deferred-commands.json")}
+	repo := models.Repo{FullName: "owner/repo"}
+	Ok(t, deferred.Add(events.WorkItem{BaseRepo: repo, PullNum: 1, Command: &events.CommentCommand{Name: command.Plan}}))
+	Ok(t, deferred.Add(events.WorkItem{BaseRepo: repo, PullNum: 2, Command: &events.CommentCommand{Name: command.Plan}}))
+	Ok(t, deferred.Add(events.WorkItem{BaseRepo: repo, PullNum: 1, Command: &events.CommentCommand{Name: command.Apply}}))
+
+	runner := &blockingCommandRunner{release: make(chan struct{}, 2)}
+	Ok(t, deferred.Replay(runner, logging.NewNoopLogger(t)))
+
+	t.Log("the apply of pull request 1 waits for its plan, pull request 2 doesn't")
+	assert.Eventually(t, func() bool { return len(runner.commands()) == 1 }, 5*time.Second, time.Millisecond)
+	Equals(t, []string{"2 plan"}, runner.commands())
+	runner.release <- struct{}{}
+	runner.release <- struct{}{}
+	assert.Eventually(t, func() bool { return len(runner.commands()) == 3 }, 5*time.Second, time.Millisecond)
+	Equals(t, []string{"2 plan", "1 plan", "1 apply"}, runner.commands())
+}
@@ -24,6 +24,9 @@ type DefaultDeleteLockCommand struct {
 	WorkingDir       WorkingDir
 	WorkingDirLocker WorkingDirLocker
 	Database         db.Database
+	// PlanJSONs are the stored JSON plans, deleted with the plans of the
+	// locks. Nil if they aren't exported.
+	PlanJSONs *PlanJSONStore
 }
 
 // DeleteLock handles deleting the lock at id
@@ -41,6 +44,7 @@ func (l *DefaultDeleteLockCommand) DeleteLock(logger logging.SimpleLogging, id s
 		logger.Warn("Failed to delete plan: %s", removeErr)
 		return nil, removeErr
 	}
+	deletePlanJSON(logger, l.PlanJSONs, lock.Pull, lock.Project.Path, lock.Workspace, lock.Project.ProjectName)
 
 	return lock, nil
 }
@@ -65,6 +69,7 @@ func (l *DefaultDeleteLockCommand) DeleteLocksByPull(logger logging.SimpleLoggin
 			logger.Warn("Failed to delete plan: %s", err)
 			return numLocks, err
 		}
+		deletePlanJSON(logger, l.PlanJSONs, lock.Pull, lock.Project.Path, lock.Workspace, lock.Project.ProjectName)
 	}
 
 	return numLocks, nil
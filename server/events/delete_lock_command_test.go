@@ -11,6 +11,7 @@ import (
 	"github.com/runatlantis/atlantis/server/core/boltdb"
 	lockmocks "github.com/runatlantis/atlantis/server/core/locking/mocks"
 	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/logging"
 	. "github.com/runatlantis/atlantis/testing"
@@ -67,17 +68,24 @@ func TestDeleteLock_Success(t *testing.T) {
 		db.Close()
 	})
 	Ok(t, err)
+	planJSONs := &events.PlanJSONStore{Dir: t.TempDir()}
+	Ok(t, planJSONs.Save(command.ProjectContext{Pull: pull, RepoRelDir: path, Workspace: workspace}, "{}"))
 	dlc := events.DefaultDeleteLockCommand{
 		Locker:           l,
 		Database:         db,
 		WorkingDirLocker: workingDirLocker,
 		WorkingDir:       workingDir,
+		PlanJSONs:        planJSONs,
 	}
 	lock, err := dlc.DeleteLock(logger, "id")
 	Ok(t, err)
 	Assert(t, lock != nil, "lock was nil")
 	workingDir.VerifyWasCalledOnce().DeletePlan(Any[logging.SimpleLogging](), Eq(pull.BaseRepo), Eq(pull), Eq(workspace),
 		Eq(path), Eq(projectName))
+	// The exported JSON of the plan is deleted with it.
+	plans, err := planJSONs.List(pull.BaseRepo.FullName, pull.Num)
+	Ok(t, err)
+	Equals(t, 0, len(plans))
 }
 
 func TestDeleteLocksByPull_LockerErr(t *testing.T) {
@@ -0,0 +1,75 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"fmt"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// Deployment states.
+const (
+	InProgressDeploymentState = "in_progress"
+	SuccessDeploymentState    = "success"
+	FailureDeploymentState    = "failure"
+)
+
+// DeploymentClient records applies as deployments so they show up in the
+// repo's environments, ex. with the GitHub Deployments API.
+type DeploymentClient interface {
+	CreateDeployment(logger logging.SimpleLogging, repo models.Repo, ref string, environment string, description string) (int64, error)
+	UpdateDeploymentStatus(logger logging.SimpleLogging, repo models.Repo, deploymentID int64, state string, description string, logURL string) error
+}
+
+// DeploymentEnvironment returns the name of the environment the project
+// described by ctx is deployed to. It's the project name, or dir if the
+// project isn't named, suffixed by the workspace unless it's the default
+// workspace.
+func DeploymentEnvironment(ctx command.ProjectContext) string {
+	environment := ctx.ProjectName
+	if environment == "" {
+		environment = ctx.RepoRelDir
+	}
+	if ctx.Workspace != "" && ctx.Workspace != DefaultWorkspace {
+		environment = fmt.Sprintf("%s/%s", environment, ctx.Workspace)
+	}
+	return environment
+}
+
+// startDeployment creates an in progress deployment for the apply of the
+// project described by ctx. It returns 0 if the deployment couldn't be
+// created, deployments are best effort and never block the apply.
+func startDeployment(client DeploymentClient, ctx command.ProjectContext) int64 {
+	if client == nil || ctx.Pull.BaseRepo.VCSHost.Type != models.Github {
+		return 0
+	}
+	environment := DeploymentEnvironment(ctx)
+	description := fmt.Sprintf("atlantis apply by %s", ctx.User.Username)
+	id, err := client.CreateDeployment(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.HeadCommit, environment, description)
+	if err != nil {
+		ctx.Log.Warn("unable to create deployment to %q: %s", environment, err)
+		return 0
+	}
+	if err := client.UpdateDeploymentStatus(ctx.Log, ctx.Pull.BaseRepo, id, InProgressDeploymentState, "Applying", ctx.Pull.URL); err != nil {
+		ctx.Log.Warn("unable to update deployment status: %s", err)
+	}
+	return id
+}
+
+// finishDeployment sets the final status of the deployment with id.
+func finishDeployment(client DeploymentClient, ctx command.ProjectContext, id int64, success bool) {
+	if client == nil || id == 0 {
+		return
+	}
+	state, description := SuccessDeploymentState, "Apply succeeded"
+	if !success {
+		state, description = FailureDeploymentState, "Apply failed"
+	}
+	if err := client.UpdateDeploymentStatus(ctx.Log, ctx.Pull.BaseRepo, id, state, description, ctx.Pull.URL); err != nil {
+		ctx.Log.Warn("unable to update deployment status: %s", err)
+	}
+}
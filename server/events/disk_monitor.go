@@ -0,0 +1,314 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	tally "github.com/uber-go/tally/v4"
+)
+
+// DiskFullComment is commented when a plan is rejected because the disk of the
+// data dir is critically full, with how full it is.
+const DiskFullComment = "**Error:** the disk of Atlantis is %.0f%% full, so new plans are rejected until some space is freed," +
+	" instead of failing midway. Ask your Atlantis administrator to free some space, then run `atlantis plan` again."
+
+// evictionMinIdle is how long a working dir must be unused for before it can
+// be evicted, so the working dirs of running commands aren't.
+const evictionMinIdle = 15 * time.Minute
+
+// DiskUsage is the usage of a filesystem.
+type DiskUsage struct {
+	UsedBytes uint64
+	// AvailableBytes are the bytes available to Atlantis, excluding the ones
+	// reserved for root.
+	AvailableBytes uint64
+}
+
+// UsedPercent is the percentage of the filesystem used.
+func (u DiskUsage) UsedPercent() float64 {
+	if u.UsedBytes+u.AvailableBytes == 0 {
+		return 0
+	}
+	return 100 * float64(u.UsedBytes) / float64(u.UsedBytes+u.AvailableBytes)
+}
+
+// DiskMonitor publishes the usage of the disk of the data dir, evicts the
+// least recently used working dirs when it's over EvictionThreshold and
+// rejects new plans when it's over CriticalThreshold. It's run periodically.
+// Working dirs with plans or locks aren't evicted, and neither are the ones
+// with a command running.
+type DiskMonitor struct {
+	DataDir string
+	// WorkingDirLocker locks the working dirs while they're evicted so no
+	// command runs in them.
+	WorkingDirLocker WorkingDirLocker
+	// Locker, if set, is checked for the locks of the working dirs to evict.
+	Locker locking.Locker
+	// EvictionThreshold is the percentage of the disk used over which working
+	// dirs are evicted. 0 means they aren't.
+	EvictionThreshold int
+	// CriticalThreshold is the percentage of the disk used over which new
+	// plans are rejected. 0 means they aren't.
+	CriticalThreshold int
+	Logger            logging.SimpleLogging
+	StatsScope        tally.Scope
+
+	// usage is overridden in tests.
+	usage func(dir string) (DiskUsage, error)
+}
+
+// Run publishes the disk usage and evicts working dirs if it's over the
+// eviction threshold.
+func (m *DiskMonitor) Run() {
+	scope := m.StatsScope.SubScope("disk")
+	usage, err := m.measure()
+	if err != nil {
+		m.Logger.Warn("unable to measure the disk usage of the data dir: %s", err)
+		return
+	}
+	if m.EvictionThreshold > 0 && usage.UsedPercent() > float64(m.EvictionThreshold) {
+		var evicted int
+		usage, evicted = m.evict(usage)
+		scope.Counter("evicted_working_dirs").Inc(int64(evicted))
+	}
+	scope.Gauge("used_bytes").Update(float64(usage.UsedBytes))
+	scope.Gauge("available_bytes").Update(float64(usage.AvailableBytes))
+	scope.Gauge("used_percent").Update(usage.UsedPercent())
+}
+
+// Critical returns whether the disk is over the critical threshold, along
+// with how full it is. It's false if the usage can't be measured.
+func (m *DiskMonitor) Critical() (bool, float64) {
+	if m.CriticalThreshold <= 0 {
+		return false, 0
+	}
+	usage, err := m.measure()
+	if err != nil {
+		m.Logger.Warn("unable to measure the disk usage of the data dir: %s", err)
+		return false, 0
+	}
+	return usage.UsedPercent() > float64(m.CriticalThreshold), usage.UsedPercent()
+}
+
+// OverEvictionThreshold returns whether the disk is over the eviction
+// threshold, ex. so no more working dirs are warmed.
+func (m *DiskMonitor) OverEvictionThreshold() bool {
+	if m.EvictionThreshold <= 0 {
+		return false
+	}
+	usage, err := m.measure()
+	return err == nil && usage.UsedPercent() > float64(m.EvictionThreshold)
+}
+
+func (m *DiskMonitor) measure() (DiskUsage, error) {
+	if m.usage != nil {
+		return m.usage(m.DataDir)
+	}
+	return dirDiskUsage(m.DataDir)
+}
+
+// evict deletes the least recently used working dirs until the usage is below
+// the eviction threshold, returning the usage and how many were evicted.
+func (m *DiskMonitor) evict(usage DiskUsage) (DiskUsage, int) {
+	workingDirs, err := m.workingDirs()
+	if err != nil {
+		m.Logger.Warn("unable to list the working dirs to evict: %s", err)
+		return usage, 0
+	}
+	var locks map[string]models.ProjectLock
+	if m.Locker != nil {
+		if locks, err = m.Locker.List(); err != nil {
+			m.Logger.Warn("unable to list the locks of the working dirs to evict: %s", err)
+			return usage, 0
+		}
+	}
+	evicted := 0
+	for _, dir := range workingDirs {
+		if usage.UsedPercent() <= float64(m.EvictionThreshold) {
+			break
+		}
+		if time.Since(dir.lastUsed) < evictionMinIdle {
+			// The other working dirs were used more recently.
+			break
+		}
+		if !m.evictDir(dir, locks) {
+			continue
+		}
+		evicted++
+		m.Logger.Info("evicted the working dir %s unused since %s since the disk is %.0f%% full", dir.path, dir.lastUsed.Format(time.RFC3339), usage.UsedPercent())
+		if usage, err = m.measure(); err != nil {
+			m.Logger.Warn("unable to measure the disk usage of the data dir: %s", err)
+			break
+		}
+	}
+	if usage.UsedPercent() > float64(m.EvictionThreshold) {
+		m.Logger.Warn("the disk is still %.0f%% full after evicting %d working dirs", usage.UsedPercent(), evicted)
+	}
+	return usage, evicted
+}
+
+// evictDir deletes dir unless it has plans or locks, or a command is running
+// in it, returning whether it was deleted.
+func (m *DiskMonitor) evictDir(dir evictableDir, locks map[string]models.ProjectLock) bool {
+	if dir.warm {
+		// Warm working dirs are renamed first so they can't be taken while
+		// they're deleted.
+		evicting := filepath.Join(filepath.Dir(dir.path), "evicting-"+strings.TrimPrefix(filepath.Base(dir.path), "ready-"))
+		if err := os.Rename(dir.path, evicting); err != nil {
+			// It was taken in the meantime.
+			return false
+		}
+		if err := os.RemoveAll(evicting); err != nil {
+			m.Logger.Warn("unable to evict the warm working dir %s: %s", dir.path, err)
+		}
+		return true
+	}
+
+	for _, lock := range locks {
+		if lock.Project.RepoFullName == dir.repoFullName && lock.Pull.Num == dir.pullNum && lock.Workspace == dir.workspace {
+			return false
+		}
+	}
+	if m.WorkingDirLocker != nil {
+		unlockFn, err := m.WorkingDirLocker.TryLockWorkspace(dir.repoFullName, dir.pullNum, dir.workspace, command.Unlock)
+		if err != nil {
+			return false
+		}
+		defer unlockFn()
+	}
+	if hasPlans(dir.path) {
+		return false
+	}
+	if err := os.RemoveAll(dir.path); err != nil {
+		m.Logger.Warn("unable to evict the working dir %s: %s", dir.path, err)
+		return false
+	}
+	// Remove the dir of the pull request once its last workspace is.
+	os.Remove(filepath.Dir(dir.path)) // nolint: errcheck
+	return true
+}
+
+// hasPlans returns whether the clone at path has planfiles, which would be
+// lost if it was evicted.
+func hasPlans(path string) bool {
+	found := false
+	filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error { // nolint: errcheck
+		if err != nil {
+			// Keep the clone if it can't be checked.
+			found = true
+			return filepath.SkipAll
+		}
+		if d.IsDir() && (d.Name() == ".git" || d.Name() == ".terraform") {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && filepath.Ext(p) == ".tfplan" {
+			found = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found
+}
+
+type evictableDir struct {
+	path     string
+	lastUsed time.Time
+	// warm is true if the dir is a ready working dir of a warm pool.
+	warm bool
+	// repoFullName, pullNum and workspace are the pull request and workspace
+	// of the working dirs of pull requests.
+	repoFullName string
+	pullNum      int
+	workspace    string
+}
+
+// workingDirs lists the working dirs in DataDir/repos/<repo full name>/<pull
+// num>/<workspace>, the same dirs of the data dirs of the tenants and the
+// ready working dirs of the warm pools, the least recently used first.
+func (m *DiskMonitor) workingDirs() ([]evictableDir, error) {
+	allReposDirs, err := reposDirs(m.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	var dirs []evictableDir
+	for _, reposDir := range allReposDirs {
+		if err := filepath.WalkDir(reposDir, func(path string, d fs.DirEntry, err error) error {
+			if os.IsNotExist(err) && path == reposDir {
+				return filepath.SkipAll
+			}
+			if err != nil || !d.IsDir() {
+				return err
+			}
+			// Clones are the only directories with a .git entry.
+			if _, statErr := os.Stat(filepath.Join(path, ".git")); statErr != nil {
+				return nil
+			}
+			dir := evictableDir{path: path, lastUsed: lastUsed(path), workspace: filepath.Base(path)}
+			pullDir := filepath.Dir(path)
+			rel, relErr := filepath.Rel(reposDir, filepath.Dir(pullDir))
+			pullNum, numErr := strconv.Atoi(filepath.Base(pullDir))
+			if relErr != nil || numErr != nil {
+				// Not the working dir of a pull request, ex. of a drift check.
+				return filepath.SkipDir
+			}
+			dir.repoFullName = filepath.ToSlash(rel)
+			dir.pullNum = pullNum
+			dirs = append(dirs, dir)
+			return filepath.SkipDir
+		}); err != nil {
+			return nil, err
+		}
+	}
+	warm, err := filepath.Glob(filepath.Join(m.DataDir, warmPoolDir, "*", "*", "*", "ready-*"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range warm {
+		dirs = append(dirs, evictableDir{path: path, lastUsed: lastUsed(path), warm: true})
+	}
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].lastUsed.Before(dirs[j].lastUsed) })
+	return dirs, nil
+}
+
+// lastUsed is when the clone at path was last checked out, fetched or
+// written to.
+func lastUsed(path string) time.Time {
+	var last time.Time
+	for _, name := range []string{"", filepath.Join(".git", "index"), filepath.Join(".git", "FETCH_HEAD")} {
+		if info, err := os.Stat(filepath.Join(path, name)); err == nil && info.ModTime().After(last) {
+			last = info.ModTime()
+		}
+	}
+	return last
+}
+
+// rejectWhileDiskFull comments and returns true if the disk of the data dir is
+// critically full, so a plan doesn't fail midway, ex. while cloning.
+func (c *DefaultCommandRunner) rejectWhileDiskFull(logger logging.SimpleLogging, item WorkItem) bool {
+	isPlan := item.Autoplan || (item.Command != nil && item.Command.Name == command.Plan)
+	if c.DiskMonitor == nil || !isPlan {
+		return false
+	}
+	critical, usedPercent := c.DiskMonitor.Critical()
+	if !critical {
+		return false
+	}
+	logger.Warn("rejecting %s since the disk is %.0f%% full", item, usedPercent)
+	if err := c.VCSClient.CreateComment(logger, item.BaseRepo, item.PullNum, fmt.Sprintf(DiskFullComment, usedPercent), command.Plan.String()); err != nil {
+		logger.Err("unable to comment: %s", err)
+	}
+	return true
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/petergtz/pegomock/v4"
+	lockmocks "github.com/runatlantis/atlantis/server/core/locking/mocks"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+	tally "github.com/uber-go/tally/v4"
+)
+
+func TestDiskMonitor_EvictsLeastRecentlyUsed(t *testing.T) {
+	dataDir := t.TempDir()
+	clones := map[string]time.Duration{
+		"owner/repo/1/default":  3 * time.Hour,
+		"owner/repo/1/staging":  2 * time.Hour,
+		"owner/other/2/default": time.Hour,
+		"owner/repo/3/default":  time.Minute,
+	}
+	for clone, age := range clones {
+		dir := filepath.Join(dataDir, "repos", clone)
+		Ok(t, os.MkdirAll(filepath.Join(dir, ".git"), 0700))
+		modTime := time.Now().Add(-age)
+		Ok(t, os.Chtimes(dir, modTime, modTime))
+	}
+	// Each clone uses 20% of the disk.
+	usage := func(string) (DiskUsage, error) {
+		dirs, err := filepath.Glob(filepath.Join(dataDir, "repos", "*", "*", "*", "*"))
+		return DiskUsage{UsedBytes: uint64(10 + 20*len(dirs)), AvailableBytes: uint64(90 - 20*len(dirs))}, err
+	}
+
+	scope := tally.NewTestScope("", nil)
+	monitor := &DiskMonitor{
+		DataDir:           dataDir,
+		EvictionThreshold: 20,
+		Logger:            logging.NewNoopLogger(t),
+		StatsScope:        scope,
+		usage:             usage,
+	}
+	monitor.Run()
+
+	t.Log("the least recently used clones are evicted, the recently used one isn't although it's still over the threshold")
+	for clone, exists := range map[string]bool{
+		"owner/repo/1":          false,
+		"owner/other/2/default": false,
+		"owner/repo/3/default":  true,
+	} {
+		_, err := os.Stat(filepath.Join(dataDir, "repos", clone))
+		Equals(t, exists, err == nil)
+	}
+	snapshot := scope.Snapshot()
+	Equals(t, int64(3), snapshot.Counters()["disk.evicted_working_dirs+"].Value())
+	Equals(t, float64(30), snapshot.Gauges()["disk.used_percent+"].Value())
+}
+
+func TestDiskMonitor_KeepsWorkingDirsInUse(t *testing.T) {
+	dataDir := t.TempDir()
+	clones := []string{
+		"repos/owner/repo/1/default",
+		"repos/owner/repo/2/default",
+		"repos/owner/repo/3/default",
+		"repos/owner/repo/4/default",
+		"warm/owner/repo/main/ready-1",
+		"warm/owner/repo/main/warming-2",
+	}
+	modTime := time.Now().Add(-time.Hour)
+	for _, clone := range clones {
+		dir := filepath.Join(dataDir, clone)
+		Ok(t, os.MkdirAll(filepath.Join(dir, ".git"), 0700))
+		Ok(t, os.Chtimes(dir, modTime, modTime))
+	}
+	// Pull 1 has a plan, 2 has a lock and 3 has a command running.
+	Ok(t, os.MkdirAll(filepath.Join(dataDir, clones[0], "prod"), 0700))
+	Ok(t, os.WriteFile(filepath.Join(dataDir, clones[0], "prod", "default.tfplan"), nil, 0600))
+	locker := lockmocks.NewMockLocker()
+	When(locker.List()).ThenReturn(map[string]models.ProjectLock{
+		"owner/repo/./default": {Project: models.NewProject("owner/repo", ".", ""), Workspace: "default", Pull: models.PullRequest{Num: 2}},
+	}, nil)
+	workingDirLocker := NewDefaultWorkingDirLocker()
+	unlockFn, err := workingDirLocker.TryLock("owner/repo", 3, "default", "prod", "", command.Plan)
+	Ok(t, err)
+	defer unlockFn()
+
+	monitor := &DiskMonitor{
+		DataDir:           dataDir,
+		WorkingDirLocker:  workingDirLocker,
+		Locker:            locker,
+		EvictionThreshold: 20,
+		Logger:            logging.NewNoopLogger(t),
+		StatsScope:        tally.NewTestScope("", nil),
+		usage: func(string) (DiskUsage, error) {
+			return DiskUsage{UsedBytes: 90, AvailableBytes: 10}, nil
+		},
+	}
+	monitor.Run()
+
+	for clone, exists := range map[string]bool{
+		clones[0]: true,
+		clones[1]: true,
+		clones[2]: true,
+		clones[3]: false,
+		clones[4]: false,
+		// Working dirs being warmed aren't evicted.
+		clones[5]: true,
+	} {
+		_, err := os.Stat(filepath.Join(dataDir, clone))
+		Equals(t, exists, err == nil)
+	}
+	Assert(t, monitor.OverEvictionThreshold(), "exp the disk to still be over the eviction threshold")
+}
+
+func TestDefaultCommandRunner_RejectWhileDiskFull(t *testing.T) {
+	RegisterMockTestingT(t)
+	vcsClient := vcsmocks.NewMockClient()
+	usedPercent := uint64(96)
+	runner := &DefaultCommandRunner{
+		VCSClient: vcsClient,
+		DiskMonitor: &DiskMonitor{
+			CriticalThreshold: 95,
+			Logger:            logging.NewNoopLogger(t),
+			usage: func(string) (DiskUsage, error) {
+				return DiskUsage{UsedBytes: usedPercent, AvailableBytes: 100 - usedPercent}, nil
+			},
+		},
+	}
+	logger := logging.NewNoopLogger(t)
+	repo := models.Repo{FullName: "owner/repo"}
+
+	Assert(t, runner.rejectWhileDiskFull(logger, WorkItem{Autoplan: true, BaseRepo: repo, PullNum: 1}), "exp autoplans to be rejected")
+	Assert(t, runner.rejectWhileDiskFull(logger, WorkItem{BaseRepo: repo, PullNum: 2, Command: &CommentCommand{Name: command.Plan}}), "exp plans to be rejected")
+	vcsClient.VerifyWasCalledOnce().CreateComment(Any[logging.SimpleLogging](), Eq(repo), Eq(2), Eq(fmt.Sprintf(DiskFullComment, 96.0)), Eq("plan"))
+
+	t.Log("other commands, ex. applies, can still run")
+	Assert(t, !runner.rejectWhileDiskFull(logger, WorkItem{BaseRepo: repo, PullNum: 2, Command: &CommentCommand{Name: command.Apply}}), "exp applies not to be rejected")
+
+	usedPercent = 90
+	Assert(t, !runner.rejectWhileDiskFull(logger, WorkItem{Autoplan: true, BaseRepo: repo, PullNum: 1}), "exp autoplans to run below the threshold")
+}
@@ -0,0 +1,21 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !windows
+
+package events
+
+import "syscall"
+
+// dirDiskUsage returns the usage of the filesystem of dir.
+func dirDiskUsage(dir string) (DiskUsage, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return DiskUsage{}, err
+	}
+	blockSize := uint64(stat.Bsize) // nolint: gosec
+	return DiskUsage{
+		UsedBytes:      (stat.Blocks - stat.Bfree) * blockSize,
+		AvailableBytes: stat.Bavail * blockSize,
+	}, nil
+}
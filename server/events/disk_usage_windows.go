@@ -0,0 +1,12 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build windows
+
+package events
+
+import "errors"
+
+func dirDiskUsage(_ string) (DiskUsage, error) {
+	return DiskUsage{}, errors.New("measuring the disk usage isn't supported on windows")
+}
@@ -10,9 +10,14 @@ import (
 // Drainer is used to gracefully shut down atlantis by waiting for in-progress
 // operations to complete.
 type Drainer struct {
-	status DrainStatus    `validate:"required"`
-	mutex  sync.Mutex     `validate:"required"`
-	wg     sync.WaitGroup `validate:"required"`
+	status DrainStatus `validate:"required"`
+	mutex  sync.Mutex  `validate:"required"`
+	// idle is closed once the in-progress operations complete, it's nil while
+	// there are none.
+	idle chan struct{}
+	// exclusive is read locked by operations and write locked while running
+	// exclusive functions, see RunExclusive.
+	exclusive sync.RWMutex
 }
 
 type DrainStatus struct {
@@ -25,14 +30,20 @@ type DrainStatus struct {
 // StartOp tries to start a new operation. It returns false if Atlantis is
 // shutting down.
 func (d *Drainer) StartOp() bool {
+	// Acquired before the mutex so waiting for an exclusive function doesn't
+	// block completing operations.
+	d.exclusive.RLock()
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
 	if d.status.ShuttingDown {
+		d.exclusive.RUnlock()
 		return false
 	}
+	if d.status.InProgressOps == 0 {
+		d.idle = make(chan struct{})
+	}
 	d.status.InProgressOps++
-	d.wg.Add(1)
 	return true
 }
 
@@ -42,25 +53,66 @@ func (d *Drainer) OpDone() {
 	defer d.mutex.Unlock()
 
 	d.status.InProgressOps--
-	d.wg.Done()
 	if d.status.InProgressOps < 0 {
 		// This would be a bug.
 		d.status.InProgressOps = 0
 	}
+	if d.status.InProgressOps == 0 && d.idle != nil {
+		close(d.idle)
+		d.idle = nil
+	}
+	d.exclusive.RUnlock()
 }
 
-// ShutdownBlocking sets "shutting down" to true and blocks until there are no
-// in progress operations.
-func (d *Drainer) ShutdownBlocking() {
-	// Set the shutdown status.
+// RunExclusive waits for in-progress operations to complete and runs fn.
+// Operations started in the meantime wait until fn returns, so fn can safely
+// replace what operations use, ex. config.
+func (d *Drainer) RunExclusive(fn func()) {
+	d.exclusive.Lock()
+	defer d.exclusive.Unlock()
+	fn()
+}
+
+// Drain sets "shutting down" to true so new operations are rejected, without
+// waiting for the in-progress ones. See Drained.
+func (d *Drainer) Drain() {
 	d.mutex.Lock()
+	defer d.mutex.Unlock()
 	d.status.ShuttingDown = true
-	d.mutex.Unlock()
+}
 
-	// Block until there are no in-progress ops.
-	d.wg.Wait()
+// Resume accepts new operations again after Drain. It returns false if
+// Atlantis wasn't draining.
+func (d *Drainer) Resume() bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	draining := d.status.ShuttingDown
+	d.status.ShuttingDown = false
+	return draining
+}
+
+// Drained returns a channel that's closed once there are no in-progress
+// operations.
+func (d *Drainer) Drained() <-chan struct{} {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.idle == nil {
+		drained := make(chan struct{})
+		close(drained)
+		return drained
+	}
+	return d.idle
+}
+
+// ShutdownBlocking sets "shutting down" to true and blocks until there are no
+// in progress operations.
+func (d *Drainer) ShutdownBlocking() {
+	d.Drain()
+	<-d.Drained()
 }
 
 func (d *Drainer) GetStatus() DrainStatus {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
 	return d.status
 }
@@ -70,3 +70,59 @@ func TestDrainer_Shutdown(t *testing.T) {
 
 	}
 }
+
+func TestDrainer_RunExclusive(t *testing.T) {
+	d := events.Drainer{}
+	d.StartOp()
+
+	ran := make(chan bool)
+	go func() {
+		d.RunExclusive(func() {})
+		close(ran)
+	}()
+
+	// RunExclusive should wait for the in-progress op.
+	select {
+	case <-ran:
+		Assert(t, false, "exp RunExclusive to wait for in-progress ops")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	d.OpDone()
+	timer, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+	select {
+	case <-ran:
+	case <-timer.Done():
+		Assert(t, false, "Timer reached without RunExclusive returning")
+	}
+
+	// Ops can be started afterwards.
+	Equals(t, true, d.StartOp())
+	d.OpDone()
+}
+
+func TestDrainer_DrainAndResume(t *testing.T) {
+	d := events.Drainer{}
+	d.StartOp()
+	d.Drain()
+	Equals(t, false, d.StartOp())
+
+	// Drained waits for the in-progress op.
+	select {
+	case <-d.Drained():
+		Assert(t, false, "exp Drained to wait for in-progress ops")
+	default:
+	}
+	d.OpDone()
+	select {
+	case <-d.Drained():
+	case <-time.After(time.Second):
+		Assert(t, false, "Timer reached without being drained")
+	}
+
+	Equals(t, true, d.Resume())
+	Equals(t, false, d.Resume())
+	Equals(t, true, d.StartOp())
+	d.OpDone()
+}
@@ -0,0 +1,298 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	// driftBranchPrefix prefixes the branches of drift pull requests.
+	driftBranchPrefix = "atlantis/drift/"
+	// maxDriftPullRequestBodyLen is how much of the changes outside of
+	// Terraform are included in the pull request's body, below GitHub's
+	// limit of 65536 characters.
+	maxDriftPullRequestBodyLen = 60000
+)
+
+var (
+	// changesOutsideRegex matches the section of a plan's output listing the
+	// changes made outside of Terraform, which is what a refresh-only plan
+	// outputs. It ends at the rule Terraform prints before the plan.
+	changesOutsideRegex = regexp.MustCompile(`(?s)Note: Objects have changed outside of Terraform.*?(?:\n\s*─+\s*\n|$)`)
+	// driftBranchCharsRegex matches the characters that aren't kept in drift
+	// branch names.
+	driftBranchCharsRegex = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// DriftPullRequestClient opens pull requests remediating drift.
+type DriftPullRequestClient interface {
+	OpenPullRequest(logger logging.SimpleLogging, repo models.Repo, pull models.ProposedPullRequest) (string, error)
+}
+
+// driftedResource is a resource of terraform show -json's resource_drift.
+type driftedResource struct {
+	Address       string `json:"address"`
+	ModuleAddress string `json:"module_address"`
+	Mode          string `json:"mode"`
+	Type          string `json:"type"`
+	Name          string `json:"name"`
+	Index         any    `json:"index"`
+	Change        struct {
+		Actions         []string       `json:"actions"`
+		Before          map[string]any `json:"before"`
+		After           map[string]any `json:"after"`
+		BeforeSensitive any            `json:"before_sensitive"`
+		AfterSensitive  any            `json:"after_sensitive"`
+	} `json:"change"`
+}
+
+// driftCodeChange is an attribute of a resource whose value in the code is
+// updated to the value it was changed to outside of Terraform.
+type driftCodeChange struct {
+	Path      string
+	Address   string
+	Attribute string
+	Value     cty.Value
+}
+
+// openDriftPullRequest opens a pull request reporting the drift a plan outside
+// of pull requests found and requests the review of the project's owners, or
+// updates the one already open for the project. show is the output of
+// terraform show -json on the plan, the attributes changed outside of
+// Terraform whose values are literals in the code are updated in the pull
+// request. It's best effort, errors are only logged.
+func openDriftPullRequest(client DriftPullRequestClient, ctx command.ProjectContext, absPath string, planSuccess *models.PlanSuccess, show string) {
+	if client == nil || ctx.Pull.Num != 0 || ctx.Pull.BaseRepo.VCSHost.Type != models.Github || ctx.Pull.BaseBranch == "" || planSuccess.NoChanges() {
+		return
+	}
+	files, changes := driftCodeChanges(ctx, absPath, show)
+	name := driftProjectName(ctx)
+	title := fmt.Sprintf("Remediate drift of %s", driftProjectTitle(ctx))
+
+	url, err := client.OpenPullRequest(ctx.Log, ctx.Pull.BaseRepo, models.ProposedPullRequest{
+		Branch:        driftBranchPrefix + name,
+		BaseBranch:    ctx.Pull.BaseBranch,
+		Title:         title,
+		Body:          driftPullRequestBody(ctx, planSuccess, changes),
+		Files:         files,
+		CommitMessage: title,
+		Reviewers:     ctx.Owners,
+	})
+	if err != nil {
+		ctx.Log.Warn("unable to open drift pull request: %s", err)
+		return
+	}
+	ctx.Log.Info("opened drift pull request %s", url)
+}
+
+// driftProjectName returns the name of the project in the branch and report of
+// its drift pull request, ex. envs-prod-default.
+func driftProjectName(ctx command.ProjectContext) string {
+	name := ctx.ProjectName
+	if name == "" {
+		dir := ctx.RepoRelDir
+		if dir == "." || dir == "" {
+			dir = "root"
+		}
+		name = dir + "-" + ctx.Workspace
+	}
+	return strings.Trim(driftBranchCharsRegex.ReplaceAllString(name, "-"), "-.")
+}
+
+// driftProjectTitle returns how the project is referred to in its drift pull
+// request.
+func driftProjectTitle(ctx command.ProjectContext) string {
+	if ctx.ProjectName != "" {
+		return ctx.ProjectName
+	}
+	return fmt.Sprintf("%s in workspace %s", ctx.RepoRelDir, ctx.Workspace)
+}
+
+// driftPullRequestBody returns the body of the drift pull request. It doesn't
+// include when the drift was detected so the body is only updated when the
+// drift changes.
+func driftPullRequestBody(ctx command.ProjectContext, planSuccess *models.PlanSuccess, changes []driftCodeChange) string {
+	var body strings.Builder
+	fmt.Fprintf(&body, "Atlantis detected drift of %s on `%s`: %s\n\n", driftProjectTitle(ctx), ctx.Pull.BaseBranch, planSuccess.DiffSummary())
+	if len(changes) > 0 {
+		body.WriteString("The code is updated to match the following attributes changed outside of Terraform:\n\n")
+		for _, change := range changes {
+			fmt.Fprintf(&body, "* `%s` `%s` in `%s`\n", change.Address, change.Attribute, change.Path)
+		}
+		body.WriteString("\nThe rest of the drift, if any, has to be remediated by updating the code or by applying it to revert the changes.")
+	} else {
+		body.WriteString("The changes to the code remediating the drift can't be derived, update the code to match the changes or apply it to revert them.")
+	}
+	body.WriteString("\n\nThe branch is reset onto the code's current state each time drift is detected, changes pushed to it are lost.")
+	if outside := changesOutside(planSuccess.TerraformOutput); outside != "" {
+		if len(outside) > maxDriftPullRequestBodyLen {
+			outside = outside[:maxDriftPullRequestBodyLen] + "\n..."
+		}
+		fmt.Fprintf(&body, "\n\n<details><summary>Changes outside of Terraform</summary>\n\n```\n%s\n```\n</details>", outside)
+	}
+	return body.String()
+}
+
+// changesOutside returns the section of the plan output listing the changes
+// made outside of Terraform, or "" if there are none.
+func changesOutside(output string) string {
+	section := changesOutsideRegex.FindString(output)
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(section), "─"))
+}
+
+// driftCodeChanges returns the contents of the project's Terraform files
+// updated so the attributes of root module resources changed outside of
+// Terraform match their new values, by path relative to the repo root, and
+// the changes. Only attributes whose values in the code are literals equal
+// to their values before the change are updated, the others can't be derived.
+// Sensitive attributes are never updated.
+func driftCodeChanges(ctx command.ProjectContext, absPath string, show string) (map[string]string, []driftCodeChange) {
+	files := make(map[string]string)
+	start := strings.Index(show, "{")
+	if start < 0 {
+		return files, nil
+	}
+	var plan struct {
+		ResourceDrift []driftedResource `json:"resource_drift"`
+	}
+	decoder := json.NewDecoder(strings.NewReader(show[start:]))
+	decoder.UseNumber()
+	if err := decoder.Decode(&plan); err != nil {
+		ctx.Log.Warn("unable to parse plan json to remediate drift: %s", err)
+		return files, nil
+	}
+	var drifted []driftedResource
+	for _, resource := range plan.ResourceDrift {
+		if resource.Mode == "managed" && resource.ModuleAddress == "" && resource.Index == nil &&
+			len(resource.Change.Actions) == 1 && resource.Change.Actions[0] == "update" {
+			drifted = append(drifted, resource)
+		}
+	}
+	if len(drifted) == 0 {
+		return files, nil
+	}
+
+	paths, _ := filepath.Glob(filepath.Join(absPath, "*.tf"))
+	sort.Strings(paths)
+	var changes []driftCodeChange
+	for _, path := range paths {
+		contents, err := os.ReadFile(path) // nolint: gosec
+		if err != nil {
+			continue
+		}
+		file, diags := hclwrite.ParseConfig(contents, path, hcl.InitialPos)
+		if diags.HasErrors() {
+			continue
+		}
+		repoRelPath := filepath.ToSlash(filepath.Join(ctx.RepoRelDir, filepath.Base(path)))
+		changed := false
+		for _, block := range file.Body().Blocks() {
+			labels := block.Labels()
+			if block.Type() != "resource" || len(labels) != 2 {
+				continue
+			}
+			for _, resource := range drifted {
+				if resource.Type != labels[0] || resource.Name != labels[1] {
+					continue
+				}
+				for _, attribute := range driftedAttributes(resource) {
+					if !literalEquals(block.Body().GetAttribute(attribute), resource.Change.Before[attribute]) {
+						continue
+					}
+					value, _ := jsonPrimitive(resource.Change.After[attribute])
+					block.Body().SetAttributeValue(attribute, value)
+					changes = append(changes, driftCodeChange{Path: repoRelPath, Address: resource.Address, Attribute: attribute, Value: value})
+					changed = true
+				}
+			}
+		}
+		if changed {
+			files[repoRelPath] = string(file.Bytes())
+		}
+	}
+	return files, changes
+}
+
+// driftedAttributes returns the sorted names of the resource's top level,
+// non-sensitive attributes whose primitive values were changed outside of
+// Terraform.
+func driftedAttributes(resource driftedResource) []string {
+	var attributes []string
+	for name, before := range resource.Change.Before {
+		after, ok := resource.Change.After[name]
+		if !ok || sensitiveAttribute(resource.Change.BeforeSensitive, name) || sensitiveAttribute(resource.Change.AfterSensitive, name) {
+			continue
+		}
+		beforeValue, beforeOK := jsonPrimitive(before)
+		afterValue, afterOK := jsonPrimitive(after)
+		if beforeOK && afterOK && !beforeValue.Equals(afterValue).True() {
+			attributes = append(attributes, name)
+		}
+	}
+	sort.Strings(attributes)
+	return attributes
+}
+
+// sensitiveAttribute returns whether the attribute name is sensitive according
+// to sensitive, the before_sensitive or after_sensitive of a change.
+func sensitiveAttribute(sensitive any, name string) bool {
+	attributes, ok := sensitive.(map[string]any)
+	if !ok {
+		return sensitive == true
+	}
+	value, ok := attributes[name]
+	return ok && value != false
+}
+
+// literalEquals returns whether attribute's expression is a literal equal to
+// value, a value of the plan json.
+func literalEquals(attribute *hclwrite.Attribute, value any) bool {
+	if attribute == nil {
+		return false
+	}
+	expected, ok := jsonPrimitive(value)
+	if !ok {
+		return false
+	}
+	expr, diags := hclsyntax.ParseExpression(attribute.Expr().BuildTokens(nil).Bytes(), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return false
+	}
+	// Expressions referencing anything fail to evaluate without a context.
+	actual, diags := expr.Value(nil)
+	if diags.HasErrors() || !actual.IsWhollyKnown() || actual.IsNull() {
+		return false
+	}
+	return actual.Type().Equals(expected.Type()) && actual.Equals(expected).True()
+}
+
+// jsonPrimitive converts a string, number or bool of the plan json, decoded
+// with json.Number numbers, to a cty value. It returns false for other values.
+func jsonPrimitive(value any) (cty.Value, bool) {
+	switch v := value.(type) {
+	case string:
+		return cty.StringVal(v), true
+	case bool:
+		return cty.BoolVal(v), true
+	case json.Number:
+		number, err := cty.ParseNumberVal(v.String())
+		return number, err == nil
+	}
+	return cty.NilVal, false
+}
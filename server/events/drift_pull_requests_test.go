@@ -0,0 +1,158 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+type fakeDriftPullRequestClient struct {
+	pulls []models.ProposedPullRequest
+}
+
+func (f *fakeDriftPullRequestClient) OpenPullRequest(_ logging.SimpleLogging, _ models.Repo, pull models.ProposedPullRequest) (string, error) {
+	f.pulls = append(f.pulls, pull)
+	return "https://github.com/owner/repo/pull/2", nil
+}
+
+const driftedPlanOutput = `Note: Objects have changed outside of Terraform
+
+Terraform detected the following changes made outside of Terraform since the
+last "terraform apply" which may have affected this plan:
+
+  # aws_instance.web has changed
+  ~ resource "aws_instance" "web" {
+        id            = "i-1"
+      ~ instance_type = "t3.micro" -> "t3.large"
+    }
+
+─────────────────────────────────────────────────────────────────────────────
+
+Terraform will perform the following actions:
+
+  # aws_instance.web will be updated in-place
+  ~ resource "aws_instance" "web" {
+      ~ instance_type = "t3.large" -> "t3.micro"
+    }
+
+Plan: 0 to add, 1 to change, 0 to destroy.`
+
+const driftedPlanJSON = `{"resource_drift":[
+{"address":"aws_instance.web","mode":"managed","type":"aws_instance","name":"web","change":{"actions":["update"],
+ "before":{"id":"i-1","instance_type":"t3.micro","monitoring":false,"volume_size":8,"user_data":"a","tags":{"env":"prod"}},
+ "after":{"id":"i-1","instance_type":"t3.large","monitoring":true,"volume_size":16,"user_data":"b","tags":{"env":"dev"}},
+ "before_sensitive":{"user_data":true},"after_sensitive":{"user_data":true}}},
+{"address":"module.db.aws_db_instance.this","module_address":"module.db","mode":"managed","type":"aws_db_instance","name":"this","change":{"actions":["update"],
+ "before":{"instance_class":"db.t3.micro"},"after":{"instance_class":"db.t3.large"}}}
+]}`
+
+const driftedMainTF = `resource "aws_instance" "web" {
+  instance_type = "t3.micro" # sized for the load
+  monitoring    = false
+  volume_size   = var.volume_size
+  user_data     = "a"
+  tags = {
+    env = "prod"
+  }
+}
+`
+
+func newDriftContext(t *testing.T) command.ProjectContext {
+	return command.ProjectContext{
+		Log:        logging.NewNoopLogger(t),
+		RepoRelDir: "envs/prod",
+		Workspace:  "default",
+		Owners:     []string{"@org/platform", "@alice"},
+		Pull: models.PullRequest{
+			BaseBranch: "main",
+			BaseRepo:   models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Type: models.Github}},
+		},
+	}
+}
+
+func TestOpenDriftPullRequest(t *testing.T) {
+	absPath := t.TempDir()
+	Ok(t, os.WriteFile(filepath.Join(absPath, "main.tf"), []byte(driftedMainTF), 0600))
+	client := &fakeDriftPullRequestClient{}
+
+	openDriftPullRequest(client, newDriftContext(t), absPath, &models.PlanSuccess{TerraformOutput: driftedPlanOutput}, driftedPlanJSON)
+
+	Equals(t, 1, len(client.pulls))
+	pull := client.pulls[0]
+	Equals(t, "atlantis/drift/envs-prod-default", pull.Branch)
+	Equals(t, "main", pull.BaseBranch)
+	Equals(t, "Remediate drift of envs/prod in workspace default", pull.Title)
+	Equals(t, []string{"@org/platform", "@alice"}, pull.Reviewers)
+
+	// Only the literals equal to the values before the drift are updated,
+	// sensitive values and resources of modules are left alone.
+	Equals(t, `resource "aws_instance" "web" {
+  instance_type = "t3.large" # sized for the load
+  monitoring    = true
+  volume_size   = var.volume_size
+  user_data     = "a"
+  tags = {
+    env = "prod"
+  }
+}
+`, pull.Files["envs/prod/main.tf"])
+	// Only the code changes are committed.
+	Equals(t, []string{"envs/prod/main.tf"}, keys(pull.Files))
+
+	Assert(t, strings.Contains(pull.Body, "* `aws_instance.web` `instance_type` in `envs/prod/main.tf`\n* `aws_instance.web` `monitoring` in `envs/prod/main.tf`\n"), "unexpected body %q", pull.Body)
+	Assert(t, strings.Contains(pull.Body, "<summary>Changes outside of Terraform</summary>\n\n```\nNote: Objects have changed outside of Terraform\n"), "body %q is missing the changes outside of Terraform", pull.Body)
+	Assert(t, !strings.Contains(pull.Body, "Terraform will perform the following actions"), "body %q includes the plan", pull.Body)
+}
+
+func TestOpenDriftPullRequest_NotDerivable(t *testing.T) {
+	client := &fakeDriftPullRequestClient{}
+	ctx := newDriftContext(t)
+	ctx.ProjectName = "prod"
+
+	openDriftPullRequest(client, ctx, t.TempDir(), &models.PlanSuccess{TerraformOutput: "Plan: 1 to add, 0 to change, 0 to destroy."}, "")
+
+	Equals(t, 1, len(client.pulls))
+	Equals(t, "atlantis/drift/prod", client.pulls[0].Branch)
+	Equals(t, 0, len(client.pulls[0].Files))
+	Assert(t, strings.Contains(client.pulls[0].Body, "can't be derived"), "unexpected body %q", client.pulls[0].Body)
+}
+
+func TestOpenDriftPullRequest_Skipped(t *testing.T) {
+	cases := map[string]func(ctx *command.ProjectContext, planSuccess *models.PlanSuccess){
+		"pull request": func(ctx *command.ProjectContext, _ *models.PlanSuccess) { ctx.Pull.Num = 1 },
+		"no changes": func(_ *command.ProjectContext, planSuccess *models.PlanSuccess) {
+			planSuccess.TerraformOutput = "No changes. Your infrastructure matches the configuration."
+		},
+		"gitlab": func(ctx *command.ProjectContext, _ *models.PlanSuccess) {
+			ctx.Pull.BaseRepo.VCSHost.Type = models.Gitlab
+		},
+	}
+	for name, modify := range cases {
+		t.Run(name, func(t *testing.T) {
+			client := &fakeDriftPullRequestClient{}
+			ctx := newDriftContext(t)
+			planSuccess := &models.PlanSuccess{TerraformOutput: driftedPlanOutput}
+			modify(&ctx, planSuccess)
+
+			openDriftPullRequest(client, ctx, t.TempDir(), planSuccess, driftedPlanJSON)
+			Equals(t, 0, len(client.pulls))
+		})
+	}
+}
+
+func keys(files map[string]string) []string {
+	var paths []string
+	for path := range files {
+		paths = append(paths, path)
+	}
+	return paths
+}
@@ -20,6 +20,8 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"regexp"
+	"strconv"
 	"strings"
 
 	giteasdk "code.gitea.io/sdk/gitea"
@@ -32,6 +34,8 @@ import (
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketcloud"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketserver"
+	"github.com/runatlantis/atlantis/server/events/vcs/codecommit"
+	"github.com/runatlantis/atlantis/server/events/vcs/gerrit"
 	"github.com/runatlantis/atlantis/server/events/vcs/gitea"
 	"github.com/runatlantis/atlantis/server/logging"
 	gitlab "gitlab.com/gitlab-org/api/client-go"
@@ -144,6 +148,21 @@ type CommentCommand struct {
 	PolicySet string
 	// ClearPolicyApproval is true if approvals should be cleared out for specified policies.
 	ClearPolicyApproval bool
+	// Failed is true if the command should only run for the projects that
+	// failed the last time the command ran on the pull request.
+	Failed bool
+	// ConfirmToken is the token confirming an apply that requires
+	// confirmation. It's empty if the command wasn't confirmed.
+	ConfirmToken string
+	// BreakGlass is true if the apply should run during the change freezes
+	// blocking it, if the user may override them.
+	BreakGlass bool
+	// Destroy is true if the preview environments should be destroyed
+	// instead of deployed.
+	Destroy bool
+	// CommentID is the VCS ID of the comment that triggered this command.
+	// It's 0 if the ID is not known.
+	CommentID int64
 }
 
 // IsForSpecificProject returns true if the command is for a specific dir, workspace
@@ -352,6 +371,62 @@ type EventParsing interface {
 	ParseGiteaIssueCommentEvent(event gitea.GiteaIssueCommentPayload) (baseRepo models.Repo, user models.User, pullNum int, err error)
 
 	ParseGiteaPull(pull *giteasdk.PullRequest) (pullModel models.PullRequest, baseRepo models.Repo, headRepo models.Repo, err error)
+
+	// ParseGerritChangeEvent parses the events of Gerrit changes.
+	// pull is the parsed change, with the ref of the patch set of the event as
+	// its head branch.
+	// pullEventType is the type of event, for example opened/closed.
+	// baseRepo is the project the change will be submitted into.
+	// headRepo is the same project, since patch sets are in the project of
+	// their change.
+	// user is the change owner.
+	ParseGerritChangeEvent(event gerrit.Event) (
+		pull models.PullRequest, pullEventType models.PullRequestEventType,
+		baseRepo models.Repo, headRepo models.Repo, user models.User, err error)
+
+	// ParseGerritCommentEvent parses the comment-added events of Gerrit
+	// changes.
+	// baseRepo is the project of the change.
+	// user is the author of the comment.
+	// comment is the message of the review, without the header Gerrit adds.
+	ParseGerritCommentEvent(event gerrit.Event) (
+		baseRepo models.Repo, user models.User, pullNum int, comment string, err error)
+
+	// ParseGerritChange parses the response from the Gerrit API endpoint that
+	// returns a change.
+	// pull is the parsed change, with its current patch set.
+	// baseRepo is the project the change will be submitted into.
+	// headRepo is the same project.
+	ParseGerritChange(change *gerrit.ChangeInfo) (
+		pull models.PullRequest, baseRepo models.Repo, headRepo models.Repo, err error)
+
+	// ParseCodeCommitPullEvent parses the pull request state change events of
+	// CodeCommit.
+	// pull is the parsed pull request.
+	// pullEventType is the type of event, for example opened/closed.
+	// baseRepo is the repo the pull request will be merged into.
+	// headRepo is the same repo, since CodeCommit has no forks.
+	// user is the pull request author.
+	ParseCodeCommitPullEvent(event codecommit.Event) (
+		pull models.PullRequest, pullEventType models.PullRequestEventType,
+		baseRepo models.Repo, headRepo models.Repo, user models.User, err error)
+
+	// ParseCodeCommitCommentEvent parses the comment events of CodeCommit pull
+	// requests.
+	// baseRepo is the repo of the pull request.
+	// commentID is the ID of the comment, whose content and author aren't
+	// part of the event. The caller of the event isn't necessarily the
+	// author so the user must be taken from the fetched comment.
+	ParseCodeCommitCommentEvent(event codecommit.Event) (
+		baseRepo models.Repo, pullNum int, commentID string, err error)
+
+	// ParseCodeCommitPullRequest parses the response from the CodeCommit API
+	// endpoint that returns a pull request of a repo of account.
+	// pull is the parsed pull request.
+	// baseRepo is the repo the pull request will be merged into.
+	// headRepo is the same repo.
+	ParseCodeCommitPullRequest(account string, pr *codecommit.PullRequest) (
+		pull models.PullRequest, baseRepo models.Repo, headRepo models.Repo, err error)
 }
 
 // EventParser parses VCS events.
@@ -369,6 +444,18 @@ type EventParser struct {
 	BitbucketServerURL string
 	AzureDevopsToken   string
 	AzureDevopsUser    string
+	// BitbucketCloudCredentials authenticate the clones of Bitbucket Cloud
+	// repos instead of BitbucketUser and BitbucketToken if set, ex. for access
+	// tokens and OAuth consumers.
+	BitbucketCloudCredentials bitbucketcloud.Credentials
+
+	GerritBaseURL  string
+	GerritUser     string
+	GerritPassword string
+
+	CodeCommitRegion      string
+	CodeCommitGitUser     string
+	CodeCommitGitPassword string
 }
 
 func (e *EventParser) ParseAPIPlanRequest(vcsHostType models.VCSHostType, repoFullName string, cloneURL string) (models.Repo, error) {
@@ -387,10 +474,66 @@ func (e *EventParser) ParseAPIPlanRequest(vcsHostType models.VCSHostType, repoFu
 		return models.NewRepo(vcsHostType, repoFullName, cloneURL, e.GiteaUser, e.GiteaToken)
 	case models.Gitlab:
 		return models.NewRepo(vcsHostType, repoFullName, cloneURL, e.GitlabUser, e.GitlabToken)
+	case models.Gerrit:
+		return models.NewRepo(vcsHostType, repoFullName, cloneURL, e.GerritUser, e.GerritPassword)
+	case models.CodeCommit:
+		return models.NewRepo(vcsHostType, repoFullName, cloneURL, e.CodeCommitGitUser, e.CodeCommitGitPassword)
 	}
 	return models.Repo{}, fmt.Errorf("not implemented")
 }
 
+// WithCloneCredentials returns repo with the VCS credentials of Atlantis in
+// its clone URLs, replacing the ones it has, if any. It implements
+// CloneCredentials.
+func (e *EventParser) WithCloneCredentials(repo models.Repo) (models.Repo, error) {
+	var user, token string
+	switch repo.VCSHost.Type {
+	case models.Github:
+		user, token = e.GithubUser, e.GithubToken
+		if e.GithubTokenFile != "" {
+			content, err := os.ReadFile(e.GithubTokenFile)
+			if err != nil {
+				return repo, fmt.Errorf("failed reading github token file: %w", err)
+			}
+			token = string(content)
+		}
+	case models.Gitlab:
+		user, token = e.GitlabUser, e.GitlabToken
+	case models.Gitea:
+		user, token = e.GiteaUser, e.GiteaToken
+	case models.BitbucketCloud:
+		user, token = e.BitbucketUser, e.BitbucketToken
+		if e.BitbucketCloudCredentials != nil {
+			var err error
+			if user, token, err = e.BitbucketCloudCredentials.GitCredentials(); err != nil {
+				return repo, fmt.Errorf("getting bitbucket cloud git credentials: %w", err)
+			}
+		}
+	case models.BitbucketServer:
+		user, token = e.BitbucketUser, e.BitbucketToken
+	case models.AzureDevops:
+		user, token = e.AzureDevopsUser, e.AzureDevopsToken
+	case models.Gerrit:
+		user, token = e.GerritUser, e.GerritPassword
+	case models.CodeCommit:
+		user, token = e.CodeCommitGitUser, e.CodeCommitGitPassword
+	default:
+		return repo, fmt.Errorf("no credentials for %s repos", repo.VCSHost.Type)
+	}
+	cloneURL, err := url.Parse(repo.CloneURL)
+	if err != nil {
+		return repo, fmt.Errorf("invalid clone url: %w", err)
+	}
+	cloneURL.User = nil
+	authenticated, err := models.NewRepo(repo.VCSHost.Type, repo.FullName, cloneURL.String(), user, token)
+	if err != nil {
+		return repo, err
+	}
+	repo.CloneURL = authenticated.CloneURL
+	repo.SanitizedCloneURL = authenticated.SanitizedCloneURL
+	return repo, nil
+}
+
 // GetBitbucketCloudPullEventType returns the type of the pull request
 // event given the Bitbucket Cloud header.
 func (e *EventParser) GetBitbucketCloudPullEventType(eventTypeHeader string, sha string, pr string) models.PullRequestEventType {
@@ -446,12 +589,20 @@ func (e *EventParser) parseCommonBitbucketCloudEventData(event bitbucketcloud.Co
 		return
 	}
 
+	gitUser, gitToken := e.BitbucketUser, e.BitbucketToken
+	if e.BitbucketCloudCredentials != nil {
+		gitUser, gitToken, err = e.BitbucketCloudCredentials.GitCredentials()
+		if err != nil {
+			err = fmt.Errorf("getting bitbucket cloud git credentials: %w", err)
+			return
+		}
+	}
 	headRepo, err = models.NewRepo(
 		models.BitbucketCloud,
 		*event.PullRequest.Source.Repository.FullName,
 		*event.PullRequest.Source.Repository.Links.HTML.HREF,
-		e.BitbucketUser,
-		e.BitbucketToken)
+		gitUser,
+		gitToken)
 	if err != nil {
 		return
 	}
@@ -459,8 +610,8 @@ func (e *EventParser) parseCommonBitbucketCloudEventData(event bitbucketcloud.Co
 		models.BitbucketCloud,
 		*event.Repository.FullName,
 		*event.Repository.Links.HTML.HREF,
-		e.BitbucketUser,
-		e.BitbucketToken)
+		gitUser,
+		gitToken)
 	if err != nil {
 		return
 	}
@@ -475,6 +626,9 @@ func (e *EventParser) parseCommonBitbucketCloudEventData(event bitbucketcloud.Co
 		State:      prState,
 		BaseRepo:   baseRepo,
 	}
+	if event.PullRequest.Description != nil {
+		pull.Body = *event.PullRequest.Description
+	}
 	user = models.User{
 		Username: *event.Actor.AccountID,
 	}
@@ -621,6 +775,8 @@ func (e *EventParser) ParseGithubPull(logger logging.SimpleLogging, pull *github
 
 	pullModel = models.PullRequest{
 		Author:     authorUsername,
+		Title:      pull.GetTitle(),
+		Body:       pull.GetBody(),
 		HeadBranch: headBranch,
 		HeadCommit: commit,
 		URL:        url,
@@ -689,6 +845,8 @@ func (e *EventParser) ParseGitlabMergeRequestEvent(event gitlab.MergeEvent) (pul
 	pull = models.PullRequest{
 		URL:        event.ObjectAttributes.URL,
 		Author:     event.User.Username,
+		Title:      event.ObjectAttributes.Title,
+		Body:       event.ObjectAttributes.Description,
 		Num:        event.ObjectAttributes.IID,
 		HeadCommit: event.ObjectAttributes.LastCommit.ID,
 		HeadBranch: event.ObjectAttributes.SourceBranch,
@@ -783,6 +941,8 @@ func (e *EventParser) ParseGitlabMergeRequest(mr *gitlab.MergeRequest, baseRepo
 	return models.PullRequest{
 		URL:        mr.WebURL,
 		Author:     mr.Author.Username,
+		Title:      mr.Title,
+		Body:       mr.Description,
 		Num:        mr.IID,
 		HeadCommit: mr.SHA,
 		HeadBranch: mr.SourceBranch,
@@ -874,6 +1034,9 @@ func (e *EventParser) parseCommonBitbucketServerEventData(event bitbucketserver.
 		State:      prState,
 		BaseRepo:   baseRepo,
 	}
+	if event.PullRequest.Description != nil {
+		pull.Body = *event.PullRequest.Description
+	}
 	user = models.User{
 		Username: *event.Actor.Username,
 	}
@@ -989,6 +1152,7 @@ func (e *EventParser) ParseAzureDevopsPull(pull *azuredevops.GitPullRequest) (pu
 
 	pullModel = models.PullRequest{
 		Author: authorUsername,
+		Body:   pull.GetDescription(),
 		// Change webhook refs from "refs/heads/<branch>" to "<branch>"
 		HeadBranch: strings.Replace(headBranch, "refs/heads/", "", 1),
 		HeadCommit: commit,
@@ -1106,6 +1270,8 @@ func (e *EventParser) ParseGiteaPullRequestEvent(event giteasdk.PullRequest) (mo
 		HeadBranch: (*event.Head).Ref,
 		BaseBranch: event.Base.Ref,
 		Author:     event.Poster.UserName,
+		Title:      event.Title,
+		Body:       event.Body,
 		BaseRepo:   baseRepo,
 	}
 
@@ -1168,6 +1334,7 @@ func (e *EventParser) ParseGiteaPull(pull *giteasdk.PullRequest) (pullModel mode
 
 	pullModel = models.PullRequest{
 		Author:     authorUsername,
+		Body:       pull.Body,
 		HeadBranch: headBranch,
 		HeadCommit: commit,
 		URL:        url,
@@ -1178,3 +1345,290 @@ func (e *EventParser) ParseGiteaPull(pull *giteasdk.PullRequest) (pullModel mode
 	}
 	return
 }
+
+// ParseGerritRepo returns the repo of the Gerrit project.
+func (e *EventParser) ParseGerritRepo(project string) (models.Repo, error) {
+	cloneURL := fmt.Sprintf("%s/a/%s", strings.TrimRight(e.GerritBaseURL, "/"), project)
+	return models.NewRepo(models.Gerrit, project, cloneURL, e.GerritUser, e.GerritPassword)
+}
+
+// ParseGerritChangeEvent parses the events of Gerrit changes sent by its
+// webhooks plugin. Changes are pull requests whose head branch is the ref of
+// their patch set, ex. refs/changes/45/12345/3.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseGerritChangeEvent(event gerrit.Event) (pull models.PullRequest, pullEventType models.PullRequestEventType, baseRepo models.Repo, headRepo models.Repo, user models.User, err error) {
+	if err = validator.New().Struct(event); err != nil {
+		return
+	}
+	baseRepo, err = e.ParseGerritRepo(event.Change.Project)
+	if err != nil {
+		return
+	}
+	// Patch sets are in the project of their change.
+	headRepo = baseRepo
+
+	pullState := models.ClosedPullState
+	if event.Change.Status == "" || event.Change.Status == "NEW" {
+		pullState = models.OpenPullState
+	}
+	pull = models.PullRequest{
+		Num:        event.Change.Number,
+		HeadCommit: event.PatchSet.Revision,
+		URL:        event.Change.URL,
+		HeadBranch: event.PatchSet.Ref,
+		BaseBranch: event.Change.Branch,
+		Author:     event.Change.Owner.Username,
+		Title:      event.Change.Subject,
+		Body:       event.Change.CommitMessage,
+		State:      pullState,
+		BaseRepo:   baseRepo,
+	}
+	user = models.User{Username: event.Change.Owner.Username}
+
+	switch event.Type {
+	case gerrit.PatchsetCreatedEvent:
+		pullEventType = models.UpdatedPullEvent
+		if event.PatchSet.Number == 1 {
+			pullEventType = models.OpenedPullEvent
+		}
+	case gerrit.WorkInProgressChanged:
+		// When a change is marked as ready for review we treat it as
+		// freshly opened, like GitHub's ready_for_review.
+		pullEventType = models.OpenedPullEvent
+		if event.Change.WIP {
+			pullEventType = models.OtherPullEvent
+		}
+	case gerrit.ChangeRestoredEvent:
+		pullEventType = models.OpenedPullEvent
+	case gerrit.ChangeMergedEvent, gerrit.ChangeAbandonedEvent:
+		pullEventType = models.ClosedPullEvent
+	default:
+		pullEventType = models.OtherPullEvent
+	}
+	// Ignore work in progress changes unless draft PRs are allowed. We still
+	// need to check if we need to delete the locks of closed ones.
+	if event.Change.WIP && pullEventType != models.ClosedPullEvent && !e.AllowDraftPRs {
+		pullEventType = models.OtherPullEvent
+	}
+	return
+}
+
+// gerritCommentHeaderRegex matches the header Gerrit prefixes the messages of
+// reviews with, ex. "Patch Set 2: Code-Review+1" followed by the number of
+// inline comments of the review, ex. "(1 comment)".
+var gerritCommentHeaderRegex = regexp.MustCompile(`^Patch Set \d+:[^\n]*(\n+\(\d+ comments?\))?\n*`)
+
+// ParseGerritCommentEvent parses the comment-added events of Gerrit changes
+// sent by its webhooks plugin. comment is the message of the review, without
+// the header Gerrit adds to it.
+func (e *EventParser) ParseGerritCommentEvent(event gerrit.Event) (baseRepo models.Repo, user models.User, pullNum int, comment string, err error) {
+	if err = validator.New().Struct(event); err != nil {
+		return
+	}
+	if event.Author == nil || event.Author.Username == "" {
+		err = errors.New("author.username is null")
+		return
+	}
+	baseRepo, err = e.ParseGerritRepo(event.Change.Project)
+	if err != nil {
+		return
+	}
+	user = models.User{Username: event.Author.Username}
+	pullNum = event.Change.Number
+	comment = gerritCommentHeaderRegex.ReplaceAllString(event.Comment, "")
+	return
+}
+
+// ParseGerritChange parses the response from the Gerrit API endpoint that
+// returns a change, with its current revision.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseGerritChange(change *gerrit.ChangeInfo) (pull models.PullRequest, baseRepo models.Repo, headRepo models.Repo, err error) {
+	revision, ok := change.Revisions[change.CurrentRevision]
+	if change.CurrentRevision == "" || !ok {
+		err = errors.New("current_revision is null")
+		return
+	}
+	if change.Number == 0 {
+		err = errors.New("_number is null")
+		return
+	}
+	if change.Branch == "" {
+		err = errors.New("branch is null")
+		return
+	}
+	baseRepo, err = e.ParseGerritRepo(change.Project)
+	if err != nil {
+		return
+	}
+	headRepo = baseRepo
+
+	pullState := models.ClosedPullState
+	if change.Status == "NEW" {
+		pullState = models.OpenPullState
+	}
+	var body string
+	if revision.Commit != nil {
+		body = revision.Commit.Message
+	}
+	pull = models.PullRequest{
+		Num:        change.Number,
+		HeadCommit: change.CurrentRevision,
+		URL:        fmt.Sprintf("%s/c/%s/+/%d", strings.TrimRight(e.GerritBaseURL, "/"), change.Project, change.Number),
+		HeadBranch: revision.Ref,
+		BaseBranch: change.Branch,
+		Author:     change.Owner.Username,
+		Title:      change.Subject,
+		Body:       body,
+		State:      pullState,
+		BaseRepo:   baseRepo,
+	}
+	return
+}
+
+// ParseCodeCommitRepo returns the CodeCommit repo name of account. Its full
+// name is account/name since repo names are only unique in an account.
+func (e *EventParser) ParseCodeCommitRepo(account string, name string) (models.Repo, error) {
+	return models.NewRepo(models.CodeCommit, fmt.Sprintf("%s/%s", account, name), codecommit.CloneURL(e.CodeCommitRegion, name), e.CodeCommitGitUser, e.CodeCommitGitPassword)
+}
+
+// parseCodeCommitEvent validates the CodeCommit event and returns the repo it
+// is for.
+func (e *EventParser) parseCodeCommitEvent(event codecommit.Event, repoName string) (models.Repo, int, error) {
+	if err := validator.New().Struct(event); err != nil {
+		return models.Repo{}, 0, err
+	}
+	// Repos are regional and Atlantis only has the Git credentials of one
+	// region.
+	if event.Region != e.CodeCommitRegion {
+		return models.Repo{}, 0, fmt.Errorf("event is from region %q, not %q", event.Region, e.CodeCommitRegion)
+	}
+	if repoName == "" {
+		return models.Repo{}, 0, errors.New("repository name is null")
+	}
+	pullNum, err := strconv.Atoi(event.Detail.PullRequestID)
+	if err != nil {
+		return models.Repo{}, 0, fmt.Errorf("parsing pullRequestId: %w", err)
+	}
+	repo, err := e.ParseCodeCommitRepo(event.Account, repoName)
+	return repo, pullNum, err
+}
+
+// ParseCodeCommitPullEvent parses the pull request state change events of
+// CodeCommit sent by EventBridge.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseCodeCommitPullEvent(event codecommit.Event) (pull models.PullRequest, pullEventType models.PullRequestEventType, baseRepo models.Repo, headRepo models.Repo, user models.User, err error) {
+	var repoName string
+	if len(event.Detail.RepositoryNames) > 0 {
+		repoName = event.Detail.RepositoryNames[0]
+	}
+	var pullNum int
+	baseRepo, pullNum, err = e.parseCodeCommitEvent(event, repoName)
+	if err != nil {
+		return
+	}
+	// CodeCommit has no forks.
+	headRepo = baseRepo
+
+	pullState := models.ClosedPullState
+	if strings.EqualFold(event.Detail.PullRequestStatus, codecommit.PullRequestStatusOpen) {
+		pullState = models.OpenPullState
+	}
+	author := codecommit.UsernameFromARN(event.Detail.Author)
+	pull = models.PullRequest{
+		Num:        pullNum,
+		HeadCommit: event.Detail.SourceCommit,
+		URL:        codecommit.PullURL(e.CodeCommitRegion, repoName, pullNum),
+		HeadBranch: strings.TrimPrefix(event.Detail.SourceReference, "refs/heads/"),
+		BaseBranch: strings.TrimPrefix(event.Detail.DestinationReference, "refs/heads/"),
+		Author:     author,
+		Title:      event.Detail.Title,
+		Body:       event.Detail.Description,
+		State:      pullState,
+		BaseRepo:   baseRepo,
+	}
+	user = models.User{Username: author}
+
+	switch event.Detail.Event {
+	case codecommit.PullRequestCreatedEvent:
+		pullEventType = models.OpenedPullEvent
+	case codecommit.PullRequestSourceBranchUpdatedEvent:
+		pullEventType = models.UpdatedPullEvent
+	case codecommit.PullRequestStatusChangedEvent:
+		// Closed pull requests can be reopened.
+		pullEventType = models.OpenedPullEvent
+		if pullState == models.ClosedPullState {
+			pullEventType = models.ClosedPullEvent
+		}
+	case codecommit.PullRequestMergeStatusUpdatedEvent:
+		pullEventType = models.OtherPullEvent
+		if strings.EqualFold(event.Detail.IsMerged, "true") {
+			pullEventType = models.ClosedPullEvent
+		}
+	default:
+		pullEventType = models.OtherPullEvent
+	}
+	return
+}
+
+// ParseCodeCommitCommentEvent parses the comment events of CodeCommit pull
+// requests sent by EventBridge.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseCodeCommitCommentEvent(event codecommit.Event) (baseRepo models.Repo, pullNum int, commentID string, err error) {
+	baseRepo, pullNum, err = e.parseCodeCommitEvent(event, event.Detail.RepositoryName)
+	if err != nil {
+		return
+	}
+	if event.Detail.CommentID == "" {
+		err = errors.New("commentId is null")
+		return
+	}
+	commentID = event.Detail.CommentID
+	return
+}
+
+// ParseCodeCommitPullRequest parses the response from the CodeCommit API
+// endpoint that returns a pull request.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseCodeCommitPullRequest(account string, pr *codecommit.PullRequest) (pull models.PullRequest, baseRepo models.Repo, headRepo models.Repo, err error) {
+	if len(pr.PullRequestTargets) == 0 {
+		err = errors.New("pullRequestTargets is null")
+		return
+	}
+	target := pr.PullRequestTargets[0]
+	if target.SourceCommit == "" {
+		err = errors.New("sourceCommit is null")
+		return
+	}
+	if target.DestinationReference == "" {
+		err = errors.New("destinationReference is null")
+		return
+	}
+	pullNum, err := strconv.Atoi(pr.PullRequestID)
+	if err != nil {
+		err = fmt.Errorf("parsing pullRequestId: %w", err)
+		return
+	}
+	baseRepo, err = e.ParseCodeCommitRepo(account, target.RepositoryName)
+	if err != nil {
+		return
+	}
+	headRepo = baseRepo
+
+	pullState := models.ClosedPullState
+	if pr.PullRequestStatus == codecommit.PullRequestStatusOpen {
+		pullState = models.OpenPullState
+	}
+	pull = models.PullRequest{
+		Num:        pullNum,
+		HeadCommit: target.SourceCommit,
+		URL:        codecommit.PullURL(e.CodeCommitRegion, target.RepositoryName, pullNum),
+		HeadBranch: strings.TrimPrefix(target.SourceReference, "refs/heads/"),
+		BaseBranch: strings.TrimPrefix(target.DestinationReference, "refs/heads/"),
+		Author:     codecommit.UsernameFromARN(pr.AuthorARN),
+		Title:      pr.Title,
+		Body:       pr.Description,
+		State:      pullState,
+		BaseRepo:   baseRepo,
+	}
+	return
+}
@@ -0,0 +1,54 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+const (
+	// execSummarizerCommandEnv is a shell command used instead of OpenRouter
+	// to summarize plans. The combined plan output is written to its stdin
+	// and the summary is read from its stdout.
+	execSummarizerCommandEnv = "TERRAFORM_PLAN_SUMMARIZER_COMMAND"
+	// execSummarizerSystemPromptEnv passes the system prompt to the command.
+	execSummarizerSystemPromptEnv = "ATLANTIS_SUMMARIZER_SYSTEM_PROMPT"
+	execSummarizerTimeout         = 2 * time.Minute
+)
+
+// summarizeWithCommand runs summarizerCommand with sh, writing plans to its
+// stdin, and returns its stdout. Like the OpenRouter summarizer it logs
+// errors and returns an empty string so a broken summarizer never blocks plans.
+func summarizeWithCommand(summarizerCommand string, systemPrompt string, plans string, logger logging.SimpleLogging) string {
+	ctx, cancel := context.WithTimeout(context.Background(), execSummarizerTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", summarizerCommand) // #nosec
+	cmd.Env = append(os.Environ(), execSummarizerSystemPromptEnv+"="+systemPrompt)
+	cmd.Stdin = strings.NewReader(plans)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	logger.Debug("running summarizer command %q", summarizerCommand)
+	if err := cmd.Run(); err != nil {
+		logger.Warn("summarizer command %q failed: %s: %s", summarizerCommand, err, strings.TrimSpace(stderr.String()))
+		return ""
+	}
+
+	summary := strings.TrimSpace(stdout.String())
+	if summary == "" {
+		logger.Warn("summarizer command %q returned an empty summary", summarizerCommand)
+		return ""
+	}
+	logger.Debug("successfully received summary from summarizer command")
+	return summary
+}
@@ -0,0 +1,29 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestSummarizePlans_Command(t *testing.T) {
+	t.Setenv("OPENROUTER_API_KEY", "")
+	t.Setenv("OPENROUTER_TERRAFORM_PLAN_SUMMARIZER_SYSTEM_PROMPT", "prompt")
+	t.Setenv("TERRAFORM_PLAN_SUMMARIZER_COMMAND", `echo "$ATLANTIS_SUMMARIZER_SYSTEM_PROMPT"; tr a-z A-Z`)
+
+	summary := events.SummarizePlans([]string{"plan one", "plan two"}, events.SummaryPromptData{}, logging.NewNoopLogger(t))
+	Equals(t, "prompt\nPLAN ONE\n\n---\n\nPLAN TWO", summary)
+}
+
+func TestSummarizePlans_CommandFailure(t *testing.T) {
+	t.Setenv("TERRAFORM_PLAN_SUMMARIZER_COMMAND", "echo partial; exit 1")
+	Equals(t, "", events.SummarizePlans([]string{"plan"}, events.SummaryPromptData{}, logging.NewNoopLogger(t)))
+
+	t.Setenv("TERRAFORM_PLAN_SUMMARIZER_COMMAND", "cat > /dev/null")
+	Equals(t, "", events.SummarizePlans([]string{"plan"}, events.SummaryPromptData{}, logging.NewNoopLogger(t)))
+}
@@ -0,0 +1,49 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"fmt"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+)
+
+// noFailedProjectsComment is posted when a command is run with --failed but no
+// project failed the last time the command ran.
+var noFailedProjectsComment = "No projects failed the last `%s`, there's nothing to re-run."
+
+// failedProjectCmds returns the commands of projectCmds for the projects whose
+// status in pullStatus is failedStatus, ex. models.ErroredPlanStatus for the
+// projects that failed to plan. pullStatus is nil if nothing ran on the pull
+// request yet.
+func failedProjectCmds(pullStatus *models.PullStatus, projectCmds []command.ProjectContext, failedStatus models.ProjectPlanStatus) []command.ProjectContext {
+	if pullStatus == nil {
+		return nil
+	}
+	var failed []command.ProjectContext
+	for _, projectCmd := range projectCmds {
+		for _, project := range pullStatus.Projects {
+			if project.Status == failedStatus &&
+				project.RepoRelDir == projectCmd.RepoRelDir &&
+				project.Workspace == projectCmd.Workspace &&
+				project.ProjectName == projectCmd.ProjectName {
+				failed = append(failed, projectCmd)
+				break
+			}
+		}
+	}
+	return failed
+}
+
+// noFailedProjects comments that there's nothing to re-run because no project
+// failed the last time cmdName ran.
+func noFailedProjects(ctx *command.Context, vcsClient vcs.Client, cmdName command.Name) {
+	ctx.Log.Info("no projects failed the last %s, not re-running it", cmdName.String())
+	comment := fmt.Sprintf(noFailedProjectsComment, cmdName.String())
+	if err := vcsClient.CreateComment(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num, comment, cmdName.String()); err != nil {
+		ctx.Log.Err("unable to comment on pull request: %s", err)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+const (
+	// failureExplanationsEnv enables explaining failed plans, including init
+	// errors, with the summarizer.
+	failureExplanationsEnv = "TERRAFORM_PLAN_FAILURE_EXPLANATIONS"
+	// failureExplainerSystemPromptEnv overrides the failure explainer's
+	// system prompt.
+	failureExplainerSystemPromptEnv = "OPENROUTER_TERRAFORM_FAILURE_EXPLAINER_SYSTEM_PROMPT"
+	// maxFailureOutputLen is how much of the end of the failure output is
+	// explained. Terraform prints errors last so the start is dropped.
+	maxFailureOutputLen           = 20000
+	defaultFailureExplainerPrompt = `You explain why a Terraform plan or init failed to an engineer reading a PR comment.
+
+Reply with one to three short sentences in plain language: what went wrong and, if it's clear, how to fix it. Name the specific module, provider, resource, file, or version involved, ex. "The module source ref v2.3 doesn't exist in git::https://github.com/org/modules, the latest tag is v2.2."
+
+Don't repeat the error message verbatim, don't speculate beyond what the output shows, and don't use headings or bullets. If the output doesn't show a cause, reply with exactly: "No explanation available."`
+)
+
+// failureExplanationsEnabled returns true if failed plans are explained.
+func failureExplanationsEnabled() bool {
+	return os.Getenv(failureExplanationsEnv) == "true"
+}
+
+// ExplainFailure returns a short plain-language explanation of the failed
+// plan or init output, or an empty string if it can't be explained.
+func ExplainFailure(output string, logger logging.SimpleLogging) string {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return ""
+	}
+	if len(output) > maxFailureOutputLen {
+		output = output[len(output)-maxFailureOutputLen:]
+	}
+	systemPrompt := defaultFailureExplainerPrompt
+	if override := os.Getenv(failureExplainerSystemPromptEnv); override != "" {
+		systemPrompt = override
+	}
+	explanation := complete(systemPrompt, output, logger)
+	if explanation == "No explanation available." {
+		return ""
+	}
+	return explanation
+}
+
+// explainFailures returns the markdown block explaining the errored projects
+// in projectResults or an empty string if there are none, or none could be
+// explained.
+func explainFailures(projectResults []command.ProjectResult, logger logging.SimpleLogging) string {
+	var explanations []string
+	for _, result := range projectResults {
+		if result.Error == nil {
+			continue
+		}
+		explanation := ExplainFailure(result.Error.Error(), logger)
+		if explanation == "" {
+			continue
+		}
+		project := result.ProjectName
+		if project == "" {
+			project = fmt.Sprintf("dir: `%s` workspace: `%s`", result.RepoRelDir, result.Workspace)
+		} else {
+			project = fmt.Sprintf("project: `%s`", project)
+		}
+		explanations = append(explanations, fmt.Sprintf("- %s: %s", project, explanation))
+	}
+	if len(explanations) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("### Failure Explanation (AI generated)\n\n%s", strings.Join(explanations, "\n"))
+}
@@ -0,0 +1,28 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestExplainFailure(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	t.Setenv("TERRAFORM_PLAN_SUMMARIZER_COMMAND", `echo "$ATLANTIS_SUMMARIZER_SYSTEM_PROMPT"; cat`)
+	t.Setenv("OPENROUTER_TERRAFORM_FAILURE_EXPLAINER_SYSTEM_PROMPT", "explain")
+	Equals(t, "explain\nError: Failed to download module", events.ExplainFailure("\nError: Failed to download module\n", logger))
+	Equals(t, "", events.ExplainFailure("  ", logger))
+
+	// Only the end of long outputs is explained.
+	t.Setenv("TERRAFORM_PLAN_SUMMARIZER_COMMAND", "wc -c | tr -d ' '")
+	Equals(t, "20000", events.ExplainFailure(strings.Repeat("a", 30000), logger))
+
+	t.Setenv("TERRAFORM_PLAN_SUMMARIZER_COMMAND", "echo 'No explanation available.'")
+	Equals(t, "", events.ExplainFailure("Error: unknown", logger))
+}
@@ -0,0 +1,146 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+const (
+	// fixSuggestionsEnv enables suggesting fixes for failed plans.
+	fixSuggestionsEnv = "TERRAFORM_PLAN_FIX_SUGGESTIONS"
+	// fixSuggestionContext is how many lines before and after the offending
+	// line are sent to the model.
+	fixSuggestionContext      = 20
+	defaultFixSuggesterPrompt = `You fix Terraform configuration errors. You're given the error output of a failed terraform plan or init and an excerpt of the file the error points at, each line prefixed with its line number.
+
+Reply with only a JSON object, no markdown, of the form {"start_line": 12, "end_line": 14, "replacement": "..."} where the lines start_line to end_line, inclusive and within the excerpt, are replaced by replacement. replacement is the new file content without line numbers and keeps the file's indentation. Change as few lines as possible.
+
+If you aren't confident what the fix is, ex. a required value you can't infer, reply with exactly {}.`
+)
+
+var (
+	// diagnosticRegex matches a Terraform error and the file and line it's
+	// on, with or without the box drawing Terraform prints around it.
+	diagnosticRegex = regexp.MustCompile(`(?m)Error: (.+?)[ \t]*$\n(?:.*\n){0,3}?.*\bon (\S+) line (\d+)`)
+	// fixableDiagnosticRegexes match the summaries of the errors fixes are
+	// suggested for: syntax errors, missing or unsupported arguments and
+	// version constraint conflicts.
+	fixableDiagnosticRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`^Argument or block definition required$`),
+		regexp.MustCompile(`^Invalid (expression|block definition|character|multi-line string)$`),
+		regexp.MustCompile(`^Unclosed configuration block$`),
+		regexp.MustCompile(`^Missing newline after argument$`),
+		regexp.MustCompile(`^Unsupported (argument|block type)$`),
+		regexp.MustCompile(`^Missing required argument$`),
+		regexp.MustCompile(`^(Insufficient|Too many) .+ blocks$`),
+		regexp.MustCompile(`^Unsupported Terraform Core version$`),
+		regexp.MustCompile(`^Invalid (provider )?version constraint$`),
+	}
+)
+
+// FixSuggestionClient comments suggested changes on pull request files.
+type FixSuggestionClient interface {
+	CreateSuggestion(logger logging.SimpleLogging, repo models.Repo, pullNum int, commitSHA string, path string, startLine int, endLine int, body string, suggestion string) error
+}
+
+// diagnostic is a Terraform error located in a file.
+type diagnostic struct {
+	Summary string
+	File    string
+	Line    int
+}
+
+// fixSuggestion is the model's reply.
+type fixSuggestion struct {
+	StartLine   int    `json:"start_line"`
+	EndLine     int    `json:"end_line"`
+	Replacement string `json:"replacement"`
+}
+
+// findFixableDiagnostic returns the first error in output that fixes are
+// suggested for.
+func findFixableDiagnostic(output string) (diagnostic, bool) {
+	for _, match := range diagnosticRegex.FindAllStringSubmatch(output, -1) {
+		for _, r := range fixableDiagnosticRegexes {
+			if !r.MatchString(match[1]) {
+				continue
+			}
+			// The regex only matches digits.
+			line, _ := strconv.Atoi(match[3])
+			return diagnostic{Summary: match[1], File: strings.TrimSuffix(match[2], ","), Line: line}, true
+		}
+	}
+	return diagnostic{}, false
+}
+
+// suggestFix asks the model for a fix of the error in the failed plan output
+// of the project described by ctx and comments it as a suggested change on
+// the offending file. Suggestions are best effort, errors are only logged.
+func suggestFix(client FixSuggestionClient, ctx command.ProjectContext, repoDir string, output string) {
+	if client == nil || os.Getenv(fixSuggestionsEnv) != "true" || ctx.Pull.BaseRepo.VCSHost.Type != models.Github {
+		return
+	}
+	diag, ok := findFixableDiagnostic(output)
+	if !ok {
+		return
+	}
+	path := filepath.Clean(filepath.Join(ctx.RepoRelDir, diag.File))
+	if filepath.IsAbs(path) || path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+		ctx.Log.Debug("not suggesting a fix for %q, it's outside the repo", diag.File)
+		return
+	}
+	contents, err := os.ReadFile(filepath.Join(repoDir, path)) // nolint: gosec
+	if err != nil {
+		ctx.Log.Warn("unable to read %q to suggest a fix: %s", path, err)
+		return
+	}
+	lines := strings.Split(strings.TrimSuffix(string(contents), "\n"), "\n")
+	if diag.Line < 1 || diag.Line > len(lines) {
+		return
+	}
+	first := max(1, diag.Line-fixSuggestionContext)
+	last := min(len(lines), diag.Line+fixSuggestionContext)
+	var excerpt strings.Builder
+	for i := first; i <= last; i++ {
+		fmt.Fprintf(&excerpt, "%d: %s\n", i, lines[i-1])
+	}
+	if len(output) > maxFailureOutputLen {
+		output = output[len(output)-maxFailureOutputLen:]
+	}
+	input := fmt.Sprintf("Error output:\n%s\n\nExcerpt of %s:\n%s", output, path, excerpt.String())
+
+	reply := complete(defaultFixSuggesterPrompt, input, ctx.Log)
+	if reply == "" {
+		return
+	}
+	reply = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(reply, "```json"), "```"), "```")
+	var fix fixSuggestion
+	if err := json.Unmarshal([]byte(strings.TrimSpace(reply)), &fix); err != nil {
+		ctx.Log.Warn("unable to parse suggested fix: %s", err)
+		return
+	}
+	if fix.StartLine == 0 && fix.EndLine == 0 {
+		ctx.Log.Debug("no fix suggested for %q", diag.Summary)
+		return
+	}
+	if fix.StartLine < first || fix.EndLine > last || fix.StartLine > fix.EndLine {
+		ctx.Log.Warn("ignoring suggested fix of lines %d-%d, outside of lines %d-%d", fix.StartLine, fix.EndLine, first, last)
+		return
+	}
+	body := fmt.Sprintf("**Suggested fix (AI generated)** for `Error: %s`", diag.Summary)
+	if err := client.CreateSuggestion(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num, ctx.Pull.HeadCommit, filepath.ToSlash(path), fix.StartLine, fix.EndLine, body, strings.TrimSuffix(fix.Replacement, "\n")); err != nil {
+		ctx.Log.Warn("unable to comment suggested fix: %s", err)
+	}
+}
@@ -90,6 +90,9 @@ func RunAndEmitStats(ctx command.ProjectContext, execute func(ctx command.Projec
 
 	logger.Info("%s success. output available at: %s", commandName, ctx.Pull.URL)
 
+	if ctx.CommandName == command.Apply {
+		emitApplyResourceStats(scope, result.ApplySuccess)
+	}
 	executionSuccess.Inc(1)
 	return result
 
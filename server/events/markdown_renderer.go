@@ -75,6 +75,10 @@ type commonData struct {
 	HideUnchangedPlanComments bool
 	QuietPolicyChecks         bool
 	VcsRequestType            string
+	// Pull and User are exposed so that template overrides can reference
+	// the pull request and the user that triggered the command.
+	Pull models.PullRequest
+	User models.User
 }
 
 // errData is data about an error response.
@@ -110,6 +114,10 @@ type applyResultData struct {
 	NumApplySuccesses int
 	NumApplyFailures  int
 	NumApplyErrors    int
+	// Stages are the results grouped by the stage of the dependency graph
+	// they were applied in. It's empty if the projects weren't applied by
+	// their dependencies.
+	Stages [][]projectResultTmplData
 }
 
 type planSuccessData struct {
@@ -120,6 +128,12 @@ type planSuccessData struct {
 	DisableRepoLocking       bool
 	EnableDiffMarkdownFormat bool
 	PlanStats                models.PlanSuccessStats
+	// SameOutputAs is the number of the project listed before whose plan has
+	// the same output, which is shown instead of this one, 0 if there's none.
+	SameOutputAs int
+	// SharedWith are the projects listed after whose plans have the same
+	// output as this one.
+	SharedWith []string
 }
 
 type policyCheckResultsData struct {
@@ -139,6 +153,16 @@ type projectResultTmplData struct {
 	Rendered     string
 	NoChanges    bool
 	IsSuccessful bool
+	Stage        int
+	// The fields below expose the structured result of the project command
+	// so that template overrides can restructure comments instead of only
+	// rearranging the pre-rendered output.
+	PlanSuccess   *models.PlanSuccess
+	PlanStats     models.PlanSuccessStats
+	ApplySuccess  string
+	ImportSuccess *models.ImportSuccess
+	Error         string
+	Failure       string
 }
 
 // Initialize templates
@@ -174,6 +198,39 @@ func NewMarkdownRenderer(
 	}
 }
 
+// samePlanOutputs returns, for each of the results of a plan of several
+// projects, the index of the first result whose plan has the same output,
+// e.g. for templated environments, so it's only shown once. Plans without
+// changes aren't deduplicated. It returns nil for other commands.
+func samePlanOutputs(results []command.ProjectResult, commandTitle string) []int {
+	if commandTitle != planCommandTitle || len(results) < 2 {
+		return nil
+	}
+	sameOutputs := make([]int, len(results))
+	firsts := make(map[string]int)
+	for i, result := range results {
+		sameOutputs[i] = i
+		if result.Error != nil || result.Failure != "" || result.PlanSuccess == nil || result.PlanSuccess.NoChanges() {
+			continue
+		}
+		output := strings.TrimSpace(result.PlanSuccess.TerraformOutput)
+		if first, ok := firsts[output]; ok {
+			sameOutputs[i] = first
+			continue
+		}
+		firsts[output] = i
+	}
+	return sameOutputs
+}
+
+// projectLabel is how the project of result is listed in comments.
+func projectLabel(result command.ProjectResult) string {
+	if result.ProjectName != "" {
+		return fmt.Sprintf("project: `%s` dir: `%s` workspace: `%s`", result.ProjectName, result.RepoRelDir, result.Workspace)
+	}
+	return fmt.Sprintf("dir: `%s` workspace: `%s`", result.RepoRelDir, result.Workspace)
+}
+
 // Render formats the data into a markdown string.
 // nolint: interfacer
 func (m *MarkdownRenderer) Render(ctx *command.Context, res command.Result, cmd PullCommand) string {
@@ -199,6 +256,8 @@ func (m *MarkdownRenderer) Render(ctx *command.Context, res command.Result, cmd
 		HideUnchangedPlanComments: m.hideUnchangedPlanComments,
 		QuietPolicyChecks:         m.quietPolicyChecks,
 		VcsRequestType:            vcsRequestType,
+		Pull:                      ctx.Pull,
+		User:                      ctx.User,
 	}
 
 	templates := m.markdownTemplates
@@ -227,23 +286,41 @@ func (m *MarkdownRenderer) renderProjectResults(ctx *command.Context, results []
 	numApplyErrors := 0
 
 	templates := m.markdownTemplates
+	sameOutputs := samePlanOutputs(results, common.Command)
 
-	for _, result := range results {
+	for i, result := range results {
 		resultData := projectResultTmplData{
-			Workspace:    result.Workspace,
-			RepoRelDir:   result.RepoRelDir,
-			ProjectName:  result.ProjectName,
-			IsSuccessful: result.IsSuccessful(),
+			Workspace:     result.Workspace,
+			RepoRelDir:    result.RepoRelDir,
+			ProjectName:   result.ProjectName,
+			IsSuccessful:  result.IsSuccessful(),
+			Stage:         result.Stage,
+			ApplySuccess:  result.ApplySuccess,
+			ImportSuccess: result.ImportSuccess,
+			Failure:       result.Failure,
+		}
+		if result.Error != nil {
+			resultData.Error = result.Error.Error()
 		}
 		if result.PlanSuccess != nil {
 			result.PlanSuccess.TerraformOutput = strings.TrimSpace(result.PlanSuccess.TerraformOutput)
+			resultData.PlanSuccess = result.PlanSuccess
+			resultData.PlanStats = result.PlanSuccess.Stats()
 			data := planSuccessData{
 				PlanSuccess:              *result.PlanSuccess,
 				PlanWasDeleted:           common.PlansDeleted,
 				DisableApply:             common.DisableApply,
 				DisableRepoLocking:       common.DisableRepoLocking,
 				EnableDiffMarkdownFormat: common.EnableDiffMarkdownFormat,
-				PlanStats:                result.PlanSuccess.Stats(),
+				PlanStats:                resultData.PlanStats,
+			}
+			for j, same := range sameOutputs {
+				switch {
+				case j == i && same != i:
+					data.SameOutputAs = same + 1
+				case same == i && j != i:
+					data.SharedWith = append(data.SharedWith, fmt.Sprintf("%d. %s", j+1, projectLabel(results[j])))
+				}
 			}
 			if m.shouldUseWrappedTmpl(vcsHost, result.PlanSuccess.TerraformOutput) {
 				data.PlanSummary = result.PlanSuccess.Summary()
@@ -334,6 +411,10 @@ func (m *MarkdownRenderer) renderProjectResults(ctx *command.Context, results []
 				tmpl = templates.Lookup("wrappedErr")
 			}
 			resultData.Rendered = m.renderTemplateTrimSpace(tmpl, errData{result.Error.Error(), resultData.Rendered, common})
+			// Show who holds the state lock outside of the folded output.
+			if result.StateLock != nil {
+				resultData.Rendered = m.renderTemplateTrimSpace(templates.Lookup("stateLock"), result.StateLock) + "\n\n" + resultData.Rendered
+			}
 			if common.Command == applyCommandTitle {
 				numApplyErrors++
 			}
@@ -407,11 +488,27 @@ func (m *MarkdownRenderer) renderProjectResults(ctx *command.Context, results []
 		numPlanFailures := len(results) - numPlanSuccesses
 		return m.renderTemplateTrimSpace(tmpl, planResultData{resultsTmplData, common, numPlansWithChanges, numPlansWithNoChanges, numPlanFailures})
 	case applyCommandTitle:
-		return m.renderTemplateTrimSpace(tmpl, applyResultData{resultsTmplData, common, numApplySuccesses, numApplyFailures, numApplyErrors})
+		return m.renderTemplateTrimSpace(tmpl, applyResultData{resultsTmplData, common, numApplySuccesses, numApplyFailures, numApplyErrors, groupByStage(resultsTmplData)})
 	}
 	return m.renderTemplateTrimSpace(tmpl, resultData{resultsTmplData, common})
 }
 
+// groupByStage groups results by the stage they ran in. It returns nil if the
+// results weren't run in stages.
+func groupByStage(results []projectResultTmplData) [][]projectResultTmplData {
+	var stages [][]projectResultTmplData
+	for _, result := range results {
+		if result.Stage == 0 {
+			return nil
+		}
+		for len(stages) < result.Stage {
+			stages = append(stages, nil)
+		}
+		stages[result.Stage-1] = append(stages[result.Stage-1], result)
+	}
+	return stages
+}
+
 // shouldUseWrappedTmpl returns true if we should use the wrapped markdown
 // templates that collapse the output to make the comment smaller on initial
 // load. Some VCS providers or versions of VCS providers don't support this
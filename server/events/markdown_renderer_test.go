@@ -222,6 +222,7 @@ func TestRenderErrAndFailure(t *testing.T) {
 }
 
 func TestRenderProjectResults(t *testing.T) {
+	costThreshold := 100.0
 	cases := []struct {
 		Description    string
 		Command        command.Name
@@ -323,6 +324,243 @@ $$$
   $$$
 :twisted_rightwards_arrows: Upstream was modified, a new merge was performed.
 
+---
+* :fast_forward: To **apply** all unapplied plans from this Pull Request, comment:
+  $$$shell
+  atlantis apply
+  $$$
+* :put_litter_in_its_place: To **delete** all plans and locks from this Pull Request, comment:
+  $$$shell
+  atlantis unlock
+  $$$
+`,
+		},
+		{
+			"single successful plan with graph",
+			command.Plan,
+			"",
+			[]command.ProjectResult{
+				{
+					ProjectCommandOutput: command.ProjectCommandOutput{
+						PlanSuccess: &models.PlanSuccess{
+							TerraformOutput: "terraform-output",
+							LockURL:         "lock-url",
+							RePlanCmd:       "atlantis plan -d path -w workspace",
+							ApplyCmd:        "atlantis apply -d path -w workspace",
+							PlanGraph:       "flowchart LR\n  r0[\"null_resource.a\"]:::create",
+						},
+					},
+					Workspace:  "workspace",
+					RepoRelDir: "path",
+				},
+			},
+			models.Github,
+			`
+Ran Plan for dir: $path$ workspace: $workspace$
+
+$$$diff
+terraform-output
+$$$
+
+<details><summary>Show Change Graph</summary>
+
+$$$mermaid
+flowchart LR
+  r0["null_resource.a"]:::create
+$$$
+</details>
+
+* :arrow_forward: To **apply** this plan, comment:
+  $$$shell
+  atlantis apply -d path -w workspace
+  $$$
+* :put_litter_in_its_place: To **delete** this plan and lock, click [here](lock-url)
+* :repeat: To **plan** this project again, comment:
+  $$$shell
+  atlantis plan -d path -w workspace
+  $$$
+
+---
+* :fast_forward: To **apply** all unapplied plans from this Pull Request, comment:
+  $$$shell
+  atlantis apply
+  $$$
+* :put_litter_in_its_place: To **delete** all plans and locks from this Pull Request, comment:
+  $$$shell
+  atlantis unlock
+  $$$
+`,
+		},
+		{
+			"single successful plan with cost",
+			command.Plan,
+			"",
+			[]command.ProjectResult{
+				{
+					ProjectCommandOutput: command.ProjectCommandOutput{
+						PlanSuccess: &models.PlanSuccess{
+							TerraformOutput: "terraform-output",
+							LockURL:         "lock-url",
+							RePlanCmd:       "atlantis plan -d path -w workspace",
+							ApplyCmd:        "atlantis apply -d path -w workspace",
+							Cost: &models.CostEstimate{
+								Currency:        "USD",
+								MonthlyCost:     150,
+								PastMonthlyCost: 25.5,
+								MonthlyDelta:    124.5,
+								Threshold:       &costThreshold,
+							},
+						},
+					},
+					Workspace:  "workspace",
+					RepoRelDir: "path",
+				},
+			},
+			models.Github,
+			`
+Ran Plan for dir: $path$ workspace: $workspace$
+
+$$$diff
+terraform-output
+$$$
+
+**Estimated monthly cost:** 150.00 USD (+124.50 USD, previously 25.50 USD)
+
+:warning: This plan increases the monthly cost by more than the project's threshold of 100.00 USD, the pull request must be approved before it can be applied.
+
+* :arrow_forward: To **apply** this plan, comment:
+  $$$shell
+  atlantis apply -d path -w workspace
+  $$$
+* :put_litter_in_its_place: To **delete** this plan and lock, click [here](lock-url)
+* :repeat: To **plan** this project again, comment:
+  $$$shell
+  atlantis plan -d path -w workspace
+  $$$
+
+---
+* :fast_forward: To **apply** all unapplied plans from this Pull Request, comment:
+  $$$shell
+  atlantis apply
+  $$$
+* :put_litter_in_its_place: To **delete** all plans and locks from this Pull Request, comment:
+  $$$shell
+  atlantis unlock
+  $$$
+`,
+		},
+		{
+			"single successful plan with audit findings",
+			command.Plan,
+			"",
+			[]command.ProjectResult{
+				{
+					ProjectCommandOutput: command.ProjectCommandOutput{
+						PlanSuccess: &models.PlanSuccess{
+							TerraformOutput: "terraform-output",
+							LockURL:         "lock-url",
+							RePlanCmd:       "atlantis plan -d path -w workspace",
+							ApplyCmd:        "atlantis apply -d path -w workspace",
+							Audit: &models.AuditReport{
+								Findings: []models.AuditFinding{
+									{
+										Kind:          "provider",
+										Source:        "registry.terraform.io/hashicorp/aws",
+										Version:       "4.67.0",
+										LatestVersion: "5.31.0",
+										Advisories:    []models.AuditAdvisory{{ID: "CVE-2023-1234", Severity: "high", Summary: "credentials are logged", URL: "https://example.com/CVE-2023-1234"}},
+									},
+									{
+										Kind:          "module",
+										Source:        "registry.terraform.io/terraform-aws-modules/vpc/aws",
+										Version:       "3.19.0",
+										LatestVersion: "5.4.0",
+									},
+								},
+								Notes: []string{"Unable to look up the latest versions on registry.example.com, outdated versions weren't checked."},
+							},
+						},
+					},
+					Workspace:  "workspace",
+					RepoRelDir: "path",
+				},
+			},
+			models.Github,
+			`
+Ran Plan for dir: $path$ workspace: $workspace$
+
+$$$diff
+terraform-output
+$$$
+
+**Provider and module audit:** :warning: 2 provider(s) or module(s) with known vulnerabilities or outdated versions
+
+| Type | Source | Version | Findings |
+|------|--------|---------|----------|
+| provider | $registry.terraform.io/hashicorp/aws$ | 4.67.0 | [CVE-2023-1234](https://example.com/CVE-2023-1234) (high): credentials are logged<br>Outdated, the latest version is 5.31.0 |
+| module | $registry.terraform.io/terraform-aws-modules/vpc/aws$ | 3.19.0 | Outdated, the latest version is 5.4.0 |
+* Unable to look up the latest versions on registry.example.com, outdated versions weren't checked.
+
+* :arrow_forward: To **apply** this plan, comment:
+  $$$shell
+  atlantis apply -d path -w workspace
+  $$$
+* :put_litter_in_its_place: To **delete** this plan and lock, click [here](lock-url)
+* :repeat: To **plan** this project again, comment:
+  $$$shell
+  atlantis plan -d path -w workspace
+  $$$
+
+---
+* :fast_forward: To **apply** all unapplied plans from this Pull Request, comment:
+  $$$shell
+  atlantis apply
+  $$$
+* :put_litter_in_its_place: To **delete** all plans and locks from this Pull Request, comment:
+  $$$shell
+  atlantis unlock
+  $$$
+`,
+		},
+		{
+			"single successful plan without audit findings",
+			command.Plan,
+			"",
+			[]command.ProjectResult{
+				{
+					ProjectCommandOutput: command.ProjectCommandOutput{
+						PlanSuccess: &models.PlanSuccess{
+							TerraformOutput: "terraform-output",
+							LockURL:         "lock-url",
+							RePlanCmd:       "atlantis plan -d path -w workspace",
+							ApplyCmd:        "atlantis apply -d path -w workspace",
+							Audit:           &models.AuditReport{},
+						},
+					},
+					Workspace:  "workspace",
+					RepoRelDir: "path",
+				},
+			},
+			models.Github,
+			`
+Ran Plan for dir: $path$ workspace: $workspace$
+
+$$$diff
+terraform-output
+$$$
+
+**Provider and module audit:** no known vulnerabilities or outdated versions
+
+* :arrow_forward: To **apply** this plan, comment:
+  $$$shell
+  atlantis apply -d path -w workspace
+  $$$
+* :put_litter_in_its_place: To **delete** this plan and lock, click [here](lock-url)
+* :repeat: To **plan** this project again, comment:
+  $$$shell
+  atlantis plan -d path -w workspace
+  $$$
+
 ---
 * :fast_forward: To **apply** all unapplied plans from this Pull Request, comment:
   $$$shell
@@ -2174,6 +2412,73 @@ $$$
 	Equals(t, normalize(exp), normalize(rendered))
 }
 
+// Test that template overrides have access to the structured project results
+// and to the sprig function map.
+func TestRenderCustomMultiProjectPlanTemplate_StructuredData(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := fmt.Sprintf("%s/templates.tmpl", tmpDir)
+	tmpl := `{{ define "multiProjectPlan" -}}
+PR #{{ .Pull.Num }} by {{ .User.Username }}
+{{ range .Results -}}
+{{ if .PlanSuccess }}{{ .RepoRelDir | upper }}: +{{ .PlanStats.Add }} ~{{ .PlanStats.Change }} -{{ .PlanStats.Destroy }}{{ else }}{{ .RepoRelDir | upper }}: {{ .Error }}{{ end }}
+{{ end -}}
+{{ end -}}
+`
+	Ok(t, os.WriteFile(filePath, []byte(tmpl), 0600))
+	r := events.NewMarkdownRenderer(
+		false,      // gitlabSupportsCommonMark
+		false,      // disableApplyAll
+		false,      // disableApply
+		false,      // disableMarkdownFolding
+		false,      // disableRepoLocking
+		false,      // enableDiffMarkdownFormat
+		tmpDir,     // markdownTemplateOverridesDir
+		"atlantis", // executableName
+		false,      // hideUnchangedPlanComments
+		false,      // quietPolicyChecks
+	)
+	ctx := &command.Context{
+		Log:  logging.NewNoopLogger(t).WithHistory(),
+		User: models.User{Username: "lkysow"},
+		Pull: models.PullRequest{
+			Num: 7,
+			BaseRepo: models.Repo{
+				VCSHost: models.VCSHost{
+					Type: models.Github,
+				},
+			},
+		},
+	}
+	res := command.Result{
+		ProjectResults: []command.ProjectResult{
+			{
+				Workspace:  "default",
+				RepoRelDir: "staging",
+				ProjectCommandOutput: command.ProjectCommandOutput{
+					PlanSuccess: &models.PlanSuccess{
+						TerraformOutput: "Plan: 1 to add, 2 to change, 3 to destroy.",
+					},
+				},
+			},
+			{
+				Workspace:  "default",
+				RepoRelDir: "production",
+				ProjectCommandOutput: command.ProjectCommandOutput{
+					Error: errors.New("init failed"),
+				},
+			},
+		},
+	}
+	cmd := &events.CommentCommand{
+		Name: command.Plan,
+	}
+	rendered := r.Render(ctx, res, cmd)
+	exp := `PR #7 by lkysow
+STAGING: +1 ~2 -3
+PRODUCTION: init failed`
+	Equals(t, normalize(exp), normalize(rendered))
+}
+
 // Test that if folding is disabled that it's not used.
 func TestRenderProjectResults_DisableFolding(t *testing.T) {
 	mr := events.NewMarkdownRenderer(
@@ -2220,6 +2525,82 @@ func TestRenderProjectResults_DisableFolding(t *testing.T) {
 	Equals(t, false, strings.Contains(rendered, "\n<details>"))
 }
 
+// Test that the holder of the state lock a project failed on is shown before
+// the error.
+func TestRenderProjectResults_StateLock(t *testing.T) {
+	cases := []struct {
+		unlockURL string
+		expHint   string
+	}{
+		{
+			"",
+			"the lock needs to be released with `terraform force-unlock lock-id`.",
+		},
+		{
+			"https://atlantis.example.com/#state-locks",
+			"the lock can be force-unlocked from the [Atlantis UI](https://atlantis.example.com/#state-locks).",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.unlockURL, func(t *testing.T) {
+			mr := events.NewMarkdownRenderer(false, false, false, false, false, false, "", "atlantis", false, false)
+			ctx := &command.Context{
+				Log: logging.NewNoopLogger(t),
+				Pull: models.PullRequest{
+					BaseRepo: models.Repo{VCSHost: models.VCSHost{Type: models.Github}},
+				},
+			}
+			res := command.Result{
+				ProjectResults: []command.ProjectResult{
+					{
+						RepoRelDir: ".",
+						Workspace:  "default",
+						ProjectCommandOutput: command.ProjectCommandOutput{
+							Error: errors.New("Error: Error acquiring the state lock"),
+							StateLock: &models.StateLock{
+								ID:        "lock-id",
+								Operation: "OperationTypeApply",
+								Who:       "someone@host",
+								Created:   "2024-01-02 03:04:05 +0000 UTC",
+								UnlockURL: c.unlockURL,
+							},
+						},
+					},
+				},
+			}
+			rendered := mr.Render(ctx, res, &events.CommentCommand{Name: command.Plan})
+			exp := "**State Locked**: the Terraform state is locked by `someone@host` since 2024-01-02 03:04:05 +0000 UTC for `OperationTypeApply`.\n" +
+				"* Lock ID: `lock-id`\n\n" +
+				"If the run holding the lock is no longer running, " + c.expHint + "\n\n" +
+				"**Plan Error**"
+			Assert(t, strings.Contains(rendered, exp), "exp %q to contain %q", rendered, exp)
+		})
+	}
+}
+
+func TestRenderProjectResults_ApplyStages(t *testing.T) {
+	mr := events.NewMarkdownRenderer(false, false, false, false, false, false, "", "atlantis", false, false)
+	ctx := &command.Context{
+		Log: logging.NewNoopLogger(t),
+		Pull: models.PullRequest{
+			BaseRepo: models.Repo{VCSHost: models.VCSHost{Type: models.Github}},
+		},
+	}
+	res := command.Result{
+		ProjectResults: []command.ProjectResult{
+			{RepoRelDir: "dev", Workspace: "default", ProjectName: "development", Stage: 1, ProjectCommandOutput: command.ProjectCommandOutput{ApplySuccess: "success"}},
+			{RepoRelDir: "staging", Workspace: "default", ProjectName: "staging", Stage: 2, ProjectCommandOutput: command.ProjectCommandOutput{ApplySuccess: "success"}},
+			{RepoRelDir: "qa", Workspace: "default", Stage: 2, ProjectCommandOutput: command.ProjectCommandOutput{Error: errors.New("error")}},
+		},
+	}
+	rendered := mr.Render(ctx, res, &events.CommentCommand{Name: command.Apply})
+	exp := "### Apply Stages\n\n" +
+		"Projects were applied after the projects they depend on, in these stages:\n\n" +
+		"1. `development`\n" +
+		"2. `staging`, dir: `qa` workspace: `default` (failed)"
+	Assert(t, strings.Contains(rendered, exp), "exp %q to contain %q", rendered, exp)
+}
+
 // Test that if the output is longer than 12 lines, it gets wrapped on the right
 // VCS hosts during an error.
 func TestRenderProjectResults_WrappedErr(t *testing.T) {
@@ -2749,6 +3130,9 @@ $$$diff
 $$$
 </details>
 
+The plans of these projects have the same output:
+* 2. dir: $.$ workspace: $production$
+
 * :arrow_forward: To **apply** this plan, comment:
   $$$shell
   staging-apply-cmd
@@ -2762,12 +3146,7 @@ Plan: 1 to add, 0 to change, 0 to destroy.
 
 ---
 ### 2. dir: $.$ workspace: $production$
-<details><summary>Show Output</summary>
-
-$$$diff
-` + tfOut + `
-$$$
-</details>
+Same plan output as **1.** above.
 
 * :arrow_forward: To **apply** this plan, comment:
   $$$shell
@@ -2797,6 +3176,144 @@ Plan: 1 to add, 0 to change, 0 to destroy.
 	Equals(t, normalize(exp), normalize(rendered))
 }
 
+func TestRenderProjectResults_MultiProjectPlanSameOutput(t *testing.T) {
+	mr := events.NewMarkdownRenderer(
+		false,      // gitlabSupportsCommonMark
+		false,      // disableApplyAll
+		false,      // disableApply
+		false,      // disableMarkdownFolding
+		true,       // disableRepoLocking
+		false,      // enableDiffMarkdownFormat
+		"",         // markdownTemplateOverridesDir
+		"atlantis", // executableName
+		false,      // hideUnchangedPlanComments
+		false,      // quietPolicyChecks
+	)
+	ctx := &command.Context{
+		Log: logging.NewNoopLogger(t),
+		Pull: models.PullRequest{
+			BaseRepo: models.Repo{
+				VCSHost: models.VCSHost{
+					Type: models.Github,
+				},
+			},
+		},
+	}
+	planResult := func(name string, dir string, output string) command.ProjectResult {
+		return command.ProjectResult{
+			ProjectName: name,
+			RepoRelDir:  dir,
+			Workspace:   "default",
+			ProjectCommandOutput: command.ProjectCommandOutput{
+				PlanSuccess: &models.PlanSuccess{
+					TerraformOutput: output,
+					ApplyCmd:        dir + "-apply-cmd",
+					RePlanCmd:       dir + "-replan-cmd",
+				},
+			},
+		}
+	}
+	add := "+ null_resource.a\nPlan: 1 to add, 0 to change, 0 to destroy."
+	noChanges := "No changes. Your infrastructure matches the configuration."
+	res := command.Result{
+		ProjectResults: []command.ProjectResult{
+			planResult("", "envs/dev", add),
+			planResult("", "envs/prod", noChanges),
+			planResult("staging", "envs/staging", add),
+			planResult("", "envs/test", noChanges),
+		},
+	}
+	cmd := &events.CommentCommand{
+		Name: command.Plan,
+	}
+	rendered := mr.Render(ctx, res, cmd)
+	exp := `
+Ran Plan for 4 projects:
+
+1. dir: $envs/dev$ workspace: $default$
+1. dir: $envs/prod$ workspace: $default$
+1. project: $staging$ dir: $envs/staging$ workspace: $default$
+1. dir: $envs/test$ workspace: $default$
+---
+
+### 1. dir: $envs/dev$ workspace: $default$
+$$$diff
++ null_resource.a
+Plan: 1 to add, 0 to change, 0 to destroy.
+$$$
+
+The plans of these projects have the same output:
+* 3. project: $staging$ dir: $envs/staging$ workspace: $default$
+
+* :arrow_forward: To **apply** this plan, comment:
+  $$$shell
+  envs/dev-apply-cmd
+  $$$
+* :repeat: To **plan** this project again, comment:
+  $$$shell
+  envs/dev-replan-cmd
+  $$$
+
+---
+### 2. dir: $envs/prod$ workspace: $default$
+$$$diff
+No changes. Your infrastructure matches the configuration.
+$$$
+
+* :arrow_forward: To **apply** this plan, comment:
+  $$$shell
+  envs/prod-apply-cmd
+  $$$
+* :repeat: To **plan** this project again, comment:
+  $$$shell
+  envs/prod-replan-cmd
+  $$$
+
+---
+### 3. project: $staging$ dir: $envs/staging$ workspace: $default$
+Same plan output as **1.** above.
+
+* :arrow_forward: To **apply** this plan, comment:
+  $$$shell
+  envs/staging-apply-cmd
+  $$$
+* :repeat: To **plan** this project again, comment:
+  $$$shell
+  envs/staging-replan-cmd
+  $$$
+
+---
+### 4. dir: $envs/test$ workspace: $default$
+$$$diff
+No changes. Your infrastructure matches the configuration.
+$$$
+
+* :arrow_forward: To **apply** this plan, comment:
+  $$$shell
+  envs/test-apply-cmd
+  $$$
+* :repeat: To **plan** this project again, comment:
+  $$$shell
+  envs/test-replan-cmd
+  $$$
+
+---
+### Plan Summary
+
+4 projects, 2 with changes, 2 with no changes, 0 failed
+
+* :fast_forward: To **apply** all unapplied plans from this Pull Request, comment:
+  $$$shell
+  atlantis apply
+  $$$
+* :put_litter_in_its_place: To **delete** all plans and locks from this Pull Request, comment:
+  $$$shell
+  atlantis unlock
+  $$$
+`
+	Equals(t, normalize(exp), normalize(rendered))
+}
+
 // Test rendering when there was an error in one of the plans and we deleted
 // all the plans as a result.
 func TestRenderProjectResults_PlansDeleted(t *testing.T) {
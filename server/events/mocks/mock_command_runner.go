@@ -7,6 +7,7 @@ import (
 	pegomock "github.com/petergtz/pegomock/v4"
 	events "github.com/runatlantis/atlantis/server/events"
 	models "github.com/runatlantis/atlantis/server/events/models"
+	logging "github.com/runatlantis/atlantis/server/logging"
 	"reflect"
 	"time"
 )
@@ -26,19 +27,19 @@ func NewMockCommandRunner(options ...pegomock.Option) *MockCommandRunner {
 func (mock *MockCommandRunner) SetFailHandler(fh pegomock.FailHandler) { mock.fail = fh }
 func (mock *MockCommandRunner) FailHandler() pegomock.FailHandler      { return mock.fail }
 
-func (mock *MockCommandRunner) RunAutoplanCommand(baseRepo models.Repo, headRepo models.Repo, pull models.PullRequest, user models.User) {
+func (mock *MockCommandRunner) RunAutoplanCommand(logger logging.SimpleLogging, baseRepo models.Repo, headRepo models.Repo, pull models.PullRequest, user models.User) {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockCommandRunner().")
 	}
-	_params := []pegomock.Param{baseRepo, headRepo, pull, user}
+	_params := []pegomock.Param{logger, baseRepo, headRepo, pull, user}
 	pegomock.GetGenericMockFrom(mock).Invoke("RunAutoplanCommand", _params, []reflect.Type{})
 }
 
-func (mock *MockCommandRunner) RunCommentCommand(baseRepo models.Repo, maybeHeadRepo *models.Repo, maybePull *models.PullRequest, user models.User, pullNum int, cmd *events.CommentCommand) {
+func (mock *MockCommandRunner) RunCommentCommand(logger logging.SimpleLogging, baseRepo models.Repo, maybeHeadRepo *models.Repo, maybePull *models.PullRequest, user models.User, pullNum int, cmd *events.CommentCommand) {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockCommandRunner().")
 	}
-	_params := []pegomock.Param{baseRepo, maybeHeadRepo, maybePull, user, pullNum, cmd}
+	_params := []pegomock.Param{logger, baseRepo, maybeHeadRepo, maybePull, user, pullNum, cmd}
 	pegomock.GetGenericMockFrom(mock).Invoke("RunCommentCommand", _params, []reflect.Type{})
 }
 
@@ -79,8 +80,8 @@ type VerifierMockCommandRunner struct {
 	timeout                time.Duration
 }
 
-func (verifier *VerifierMockCommandRunner) RunAutoplanCommand(baseRepo models.Repo, headRepo models.Repo, pull models.PullRequest, user models.User) *MockCommandRunner_RunAutoplanCommand_OngoingVerification {
-	_params := []pegomock.Param{baseRepo, headRepo, pull, user}
+func (verifier *VerifierMockCommandRunner) RunAutoplanCommand(logger logging.SimpleLogging, baseRepo models.Repo, headRepo models.Repo, pull models.PullRequest, user models.User) *MockCommandRunner_RunAutoplanCommand_OngoingVerification {
+	_params := []pegomock.Param{logger, baseRepo, headRepo, pull, user}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "RunAutoplanCommand", _params, verifier.timeout)
 	return &MockCommandRunner_RunAutoplanCommand_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
 }
@@ -90,18 +91,18 @@ type MockCommandRunner_RunAutoplanCommand_OngoingVerification struct {
 	methodInvocations []pegomock.MethodInvocation
 }
 
-func (c *MockCommandRunner_RunAutoplanCommand_OngoingVerification) GetCapturedArguments() (models.Repo, models.Repo, models.PullRequest, models.User) {
-	baseRepo, headRepo, pull, user := c.GetAllCapturedArguments()
-	return baseRepo[len(baseRepo)-1], headRepo[len(headRepo)-1], pull[len(pull)-1], user[len(user)-1]
+func (c *MockCommandRunner_RunAutoplanCommand_OngoingVerification) GetCapturedArguments() (logging.SimpleLogging, models.Repo, models.Repo, models.PullRequest, models.User) {
+	logger, baseRepo, headRepo, pull, user := c.GetAllCapturedArguments()
+	return logger[len(logger)-1], baseRepo[len(baseRepo)-1], headRepo[len(headRepo)-1], pull[len(pull)-1], user[len(user)-1]
 }
 
-func (c *MockCommandRunner_RunAutoplanCommand_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Repo, _param1 []models.Repo, _param2 []models.PullRequest, _param3 []models.User) {
+func (c *MockCommandRunner_RunAutoplanCommand_OngoingVerification) GetAllCapturedArguments() (_param0 []logging.SimpleLogging, _param1 []models.Repo, _param2 []models.Repo, _param3 []models.PullRequest, _param4 []models.User) {
 	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
 	if len(_params) > 0 {
 		if len(_params) > 0 {
-			_param0 = make([]models.Repo, len(c.methodInvocations))
+			_param0 = make([]logging.SimpleLogging, len(c.methodInvocations))
 			for u, param := range _params[0] {
-				_param0[u] = param.(models.Repo)
+				_param0[u] = param.(logging.SimpleLogging)
 			}
 		}
 		if len(_params) > 1 {
@@ -111,23 +112,29 @@ func (c *MockCommandRunner_RunAutoplanCommand_OngoingVerification) GetAllCapture
 			}
 		}
 		if len(_params) > 2 {
-			_param2 = make([]models.PullRequest, len(c.methodInvocations))
+			_param2 = make([]models.Repo, len(c.methodInvocations))
 			for u, param := range _params[2] {
-				_param2[u] = param.(models.PullRequest)
+				_param2[u] = param.(models.Repo)
 			}
 		}
 		if len(_params) > 3 {
-			_param3 = make([]models.User, len(c.methodInvocations))
+			_param3 = make([]models.PullRequest, len(c.methodInvocations))
 			for u, param := range _params[3] {
-				_param3[u] = param.(models.User)
+				_param3[u] = param.(models.PullRequest)
+			}
+		}
+		if len(_params) > 4 {
+			_param4 = make([]models.User, len(c.methodInvocations))
+			for u, param := range _params[4] {
+				_param4[u] = param.(models.User)
 			}
 		}
 	}
 	return
 }
 
-func (verifier *VerifierMockCommandRunner) RunCommentCommand(baseRepo models.Repo, maybeHeadRepo *models.Repo, maybePull *models.PullRequest, user models.User, pullNum int, cmd *events.CommentCommand) *MockCommandRunner_RunCommentCommand_OngoingVerification {
-	_params := []pegomock.Param{baseRepo, maybeHeadRepo, maybePull, user, pullNum, cmd}
+func (verifier *VerifierMockCommandRunner) RunCommentCommand(logger logging.SimpleLogging, baseRepo models.Repo, maybeHeadRepo *models.Repo, maybePull *models.PullRequest, user models.User, pullNum int, cmd *events.CommentCommand) *MockCommandRunner_RunCommentCommand_OngoingVerification {
+	_params := []pegomock.Param{logger, baseRepo, maybeHeadRepo, maybePull, user, pullNum, cmd}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "RunCommentCommand", _params, verifier.timeout)
 	return &MockCommandRunner_RunCommentCommand_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
 }
@@ -137,48 +144,54 @@ type MockCommandRunner_RunCommentCommand_OngoingVerification struct {
 	methodInvocations []pegomock.MethodInvocation
 }
 
-func (c *MockCommandRunner_RunCommentCommand_OngoingVerification) GetCapturedArguments() (models.Repo, *models.Repo, *models.PullRequest, models.User, int, *events.CommentCommand) {
-	baseRepo, maybeHeadRepo, maybePull, user, pullNum, cmd := c.GetAllCapturedArguments()
-	return baseRepo[len(baseRepo)-1], maybeHeadRepo[len(maybeHeadRepo)-1], maybePull[len(maybePull)-1], user[len(user)-1], pullNum[len(pullNum)-1], cmd[len(cmd)-1]
+func (c *MockCommandRunner_RunCommentCommand_OngoingVerification) GetCapturedArguments() (logging.SimpleLogging, models.Repo, *models.Repo, *models.PullRequest, models.User, int, *events.CommentCommand) {
+	logger, baseRepo, maybeHeadRepo, maybePull, user, pullNum, cmd := c.GetAllCapturedArguments()
+	return logger[len(logger)-1], baseRepo[len(baseRepo)-1], maybeHeadRepo[len(maybeHeadRepo)-1], maybePull[len(maybePull)-1], user[len(user)-1], pullNum[len(pullNum)-1], cmd[len(cmd)-1]
 }
 
-func (c *MockCommandRunner_RunCommentCommand_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Repo, _param1 []*models.Repo, _param2 []*models.PullRequest, _param3 []models.User, _param4 []int, _param5 []*events.CommentCommand) {
+func (c *MockCommandRunner_RunCommentCommand_OngoingVerification) GetAllCapturedArguments() (_param0 []logging.SimpleLogging, _param1 []models.Repo, _param2 []*models.Repo, _param3 []*models.PullRequest, _param4 []models.User, _param5 []int, _param6 []*events.CommentCommand) {
 	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
 	if len(_params) > 0 {
 		if len(_params) > 0 {
-			_param0 = make([]models.Repo, len(c.methodInvocations))
+			_param0 = make([]logging.SimpleLogging, len(c.methodInvocations))
 			for u, param := range _params[0] {
-				_param0[u] = param.(models.Repo)
+				_param0[u] = param.(logging.SimpleLogging)
 			}
 		}
 		if len(_params) > 1 {
-			_param1 = make([]*models.Repo, len(c.methodInvocations))
+			_param1 = make([]models.Repo, len(c.methodInvocations))
 			for u, param := range _params[1] {
-				_param1[u] = param.(*models.Repo)
+				_param1[u] = param.(models.Repo)
 			}
 		}
 		if len(_params) > 2 {
-			_param2 = make([]*models.PullRequest, len(c.methodInvocations))
+			_param2 = make([]*models.Repo, len(c.methodInvocations))
 			for u, param := range _params[2] {
-				_param2[u] = param.(*models.PullRequest)
+				_param2[u] = param.(*models.Repo)
 			}
 		}
 		if len(_params) > 3 {
-			_param3 = make([]models.User, len(c.methodInvocations))
+			_param3 = make([]*models.PullRequest, len(c.methodInvocations))
 			for u, param := range _params[3] {
-				_param3[u] = param.(models.User)
+				_param3[u] = param.(*models.PullRequest)
 			}
 		}
 		if len(_params) > 4 {
-			_param4 = make([]int, len(c.methodInvocations))
+			_param4 = make([]models.User, len(c.methodInvocations))
 			for u, param := range _params[4] {
-				_param4[u] = param.(int)
+				_param4[u] = param.(models.User)
 			}
 		}
 		if len(_params) > 5 {
-			_param5 = make([]*events.CommentCommand, len(c.methodInvocations))
+			_param5 = make([]int, len(c.methodInvocations))
 			for u, param := range _params[5] {
-				_param5[u] = param.(*events.CommentCommand)
+				_param5[u] = param.(int)
+			}
+		}
+		if len(_params) > 6 {
+			_param6 = make([]*events.CommentCommand, len(c.methodInvocations))
+			for u, param := range _params[6] {
+				_param6[u] = param.(*events.CommentCommand)
 			}
 		}
 	}
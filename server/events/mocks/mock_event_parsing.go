@@ -9,6 +9,8 @@ import (
 	github "github.com/google/go-github/v71/github"
 	pegomock "github.com/petergtz/pegomock/v4"
 	models "github.com/runatlantis/atlantis/server/events/models"
+	codecommit "github.com/runatlantis/atlantis/server/events/vcs/codecommit"
+	gerrit "github.com/runatlantis/atlantis/server/events/vcs/gerrit"
 	gitea0 "github.com/runatlantis/atlantis/server/events/vcs/gitea"
 	logging "github.com/runatlantis/atlantis/server/logging"
 	client_go "gitlab.com/gitlab-org/api/client-go"
@@ -293,6 +295,188 @@ func (mock *MockEventParsing) ParseBitbucketServerPullEvent(body []byte) (models
 	return _ret0, _ret1, _ret2, _ret3, _ret4
 }
 
+func (mock *MockEventParsing) ParseCodeCommitCommentEvent(event codecommit.Event) (models.Repo, int, string, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockEventParsing().")
+	}
+	_params := []pegomock.Param{event}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("ParseCodeCommitCommentEvent", _params, []reflect.Type{reflect.TypeOf((*models.Repo)(nil)).Elem(), reflect.TypeOf((*int)(nil)).Elem(), reflect.TypeOf((*string)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var _ret0 models.Repo
+	var _ret1 int
+	var _ret2 string
+	var _ret3 error
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].(models.Repo)
+		}
+		if _result[1] != nil {
+			_ret1 = _result[1].(int)
+		}
+		if _result[2] != nil {
+			_ret2 = _result[2].(string)
+		}
+		if _result[3] != nil {
+			_ret3 = _result[3].(error)
+		}
+	}
+	return _ret0, _ret1, _ret2, _ret3
+}
+
+func (mock *MockEventParsing) ParseCodeCommitPullEvent(event codecommit.Event) (models.PullRequest, models.PullRequestEventType, models.Repo, models.Repo, models.User, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockEventParsing().")
+	}
+	_params := []pegomock.Param{event}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("ParseCodeCommitPullEvent", _params, []reflect.Type{reflect.TypeOf((*models.PullRequest)(nil)).Elem(), reflect.TypeOf((*models.PullRequestEventType)(nil)).Elem(), reflect.TypeOf((*models.Repo)(nil)).Elem(), reflect.TypeOf((*models.Repo)(nil)).Elem(), reflect.TypeOf((*models.User)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var _ret0 models.PullRequest
+	var _ret1 models.PullRequestEventType
+	var _ret2 models.Repo
+	var _ret3 models.Repo
+	var _ret4 models.User
+	var _ret5 error
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].(models.PullRequest)
+		}
+		if _result[1] != nil {
+			_ret1 = _result[1].(models.PullRequestEventType)
+		}
+		if _result[2] != nil {
+			_ret2 = _result[2].(models.Repo)
+		}
+		if _result[3] != nil {
+			_ret3 = _result[3].(models.Repo)
+		}
+		if _result[4] != nil {
+			_ret4 = _result[4].(models.User)
+		}
+		if _result[5] != nil {
+			_ret5 = _result[5].(error)
+		}
+	}
+	return _ret0, _ret1, _ret2, _ret3, _ret4, _ret5
+}
+
+func (mock *MockEventParsing) ParseCodeCommitPullRequest(account string, pr *codecommit.PullRequest) (models.PullRequest, models.Repo, models.Repo, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockEventParsing().")
+	}
+	_params := []pegomock.Param{account, pr}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("ParseCodeCommitPullRequest", _params, []reflect.Type{reflect.TypeOf((*models.PullRequest)(nil)).Elem(), reflect.TypeOf((*models.Repo)(nil)).Elem(), reflect.TypeOf((*models.Repo)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var _ret0 models.PullRequest
+	var _ret1 models.Repo
+	var _ret2 models.Repo
+	var _ret3 error
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].(models.PullRequest)
+		}
+		if _result[1] != nil {
+			_ret1 = _result[1].(models.Repo)
+		}
+		if _result[2] != nil {
+			_ret2 = _result[2].(models.Repo)
+		}
+		if _result[3] != nil {
+			_ret3 = _result[3].(error)
+		}
+	}
+	return _ret0, _ret1, _ret2, _ret3
+}
+
+func (mock *MockEventParsing) ParseGerritChange(change *gerrit.ChangeInfo) (models.PullRequest, models.Repo, models.Repo, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockEventParsing().")
+	}
+	_params := []pegomock.Param{change}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("ParseGerritChange", _params, []reflect.Type{reflect.TypeOf((*models.PullRequest)(nil)).Elem(), reflect.TypeOf((*models.Repo)(nil)).Elem(), reflect.TypeOf((*models.Repo)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var _ret0 models.PullRequest
+	var _ret1 models.Repo
+	var _ret2 models.Repo
+	var _ret3 error
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].(models.PullRequest)
+		}
+		if _result[1] != nil {
+			_ret1 = _result[1].(models.Repo)
+		}
+		if _result[2] != nil {
+			_ret2 = _result[2].(models.Repo)
+		}
+		if _result[3] != nil {
+			_ret3 = _result[3].(error)
+		}
+	}
+	return _ret0, _ret1, _ret2, _ret3
+}
+
+func (mock *MockEventParsing) ParseGerritChangeEvent(event gerrit.Event) (models.PullRequest, models.PullRequestEventType, models.Repo, models.Repo, models.User, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockEventParsing().")
+	}
+	_params := []pegomock.Param{event}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("ParseGerritChangeEvent", _params, []reflect.Type{reflect.TypeOf((*models.PullRequest)(nil)).Elem(), reflect.TypeOf((*models.PullRequestEventType)(nil)).Elem(), reflect.TypeOf((*models.Repo)(nil)).Elem(), reflect.TypeOf((*models.Repo)(nil)).Elem(), reflect.TypeOf((*models.User)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var _ret0 models.PullRequest
+	var _ret1 models.PullRequestEventType
+	var _ret2 models.Repo
+	var _ret3 models.Repo
+	var _ret4 models.User
+	var _ret5 error
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].(models.PullRequest)
+		}
+		if _result[1] != nil {
+			_ret1 = _result[1].(models.PullRequestEventType)
+		}
+		if _result[2] != nil {
+			_ret2 = _result[2].(models.Repo)
+		}
+		if _result[3] != nil {
+			_ret3 = _result[3].(models.Repo)
+		}
+		if _result[4] != nil {
+			_ret4 = _result[4].(models.User)
+		}
+		if _result[5] != nil {
+			_ret5 = _result[5].(error)
+		}
+	}
+	return _ret0, _ret1, _ret2, _ret3, _ret4, _ret5
+}
+
+func (mock *MockEventParsing) ParseGerritCommentEvent(event gerrit.Event) (models.Repo, models.User, int, string, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockEventParsing().")
+	}
+	_params := []pegomock.Param{event}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("ParseGerritCommentEvent", _params, []reflect.Type{reflect.TypeOf((*models.Repo)(nil)).Elem(), reflect.TypeOf((*models.User)(nil)).Elem(), reflect.TypeOf((*int)(nil)).Elem(), reflect.TypeOf((*string)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var _ret0 models.Repo
+	var _ret1 models.User
+	var _ret2 int
+	var _ret3 string
+	var _ret4 error
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].(models.Repo)
+		}
+		if _result[1] != nil {
+			_ret1 = _result[1].(models.User)
+		}
+		if _result[2] != nil {
+			_ret2 = _result[2].(int)
+		}
+		if _result[3] != nil {
+			_ret3 = _result[3].(string)
+		}
+		if _result[4] != nil {
+			_ret4 = _result[4].(error)
+		}
+	}
+	return _ret0, _ret1, _ret2, _ret3, _ret4
+}
+
 func (mock *MockEventParsing) ParseGiteaIssueCommentEvent(event gitea0.GiteaIssueCommentPayload) (models.Repo, models.User, int, error) {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockEventParsing().")
@@ -937,6 +1121,186 @@ func (c *MockEventParsing_ParseBitbucketServerPullEvent_OngoingVerification) Get
 	return
 }
 
+func (verifier *VerifierMockEventParsing) ParseCodeCommitCommentEvent(event codecommit.Event) *MockEventParsing_ParseCodeCommitCommentEvent_OngoingVerification {
+	_params := []pegomock.Param{event}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "ParseCodeCommitCommentEvent", _params, verifier.timeout)
+	return &MockEventParsing_ParseCodeCommitCommentEvent_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockEventParsing_ParseCodeCommitCommentEvent_OngoingVerification struct {
+	mock              *MockEventParsing
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockEventParsing_ParseCodeCommitCommentEvent_OngoingVerification) GetCapturedArguments() codecommit.Event {
+	event := c.GetAllCapturedArguments()
+	return event[len(event)-1]
+}
+
+func (c *MockEventParsing_ParseCodeCommitCommentEvent_OngoingVerification) GetAllCapturedArguments() (_param0 []codecommit.Event) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]codecommit.Event, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(codecommit.Event)
+			}
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockEventParsing) ParseCodeCommitPullEvent(event codecommit.Event) *MockEventParsing_ParseCodeCommitPullEvent_OngoingVerification {
+	_params := []pegomock.Param{event}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "ParseCodeCommitPullEvent", _params, verifier.timeout)
+	return &MockEventParsing_ParseCodeCommitPullEvent_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockEventParsing_ParseCodeCommitPullEvent_OngoingVerification struct {
+	mock              *MockEventParsing
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockEventParsing_ParseCodeCommitPullEvent_OngoingVerification) GetCapturedArguments() codecommit.Event {
+	event := c.GetAllCapturedArguments()
+	return event[len(event)-1]
+}
+
+func (c *MockEventParsing_ParseCodeCommitPullEvent_OngoingVerification) GetAllCapturedArguments() (_param0 []codecommit.Event) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]codecommit.Event, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(codecommit.Event)
+			}
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockEventParsing) ParseCodeCommitPullRequest(account string, pr *codecommit.PullRequest) *MockEventParsing_ParseCodeCommitPullRequest_OngoingVerification {
+	_params := []pegomock.Param{account, pr}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "ParseCodeCommitPullRequest", _params, verifier.timeout)
+	return &MockEventParsing_ParseCodeCommitPullRequest_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockEventParsing_ParseCodeCommitPullRequest_OngoingVerification struct {
+	mock              *MockEventParsing
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockEventParsing_ParseCodeCommitPullRequest_OngoingVerification) GetCapturedArguments() (string, *codecommit.PullRequest) {
+	account, pr := c.GetAllCapturedArguments()
+	return account[len(account)-1], pr[len(pr)-1]
+}
+
+func (c *MockEventParsing_ParseCodeCommitPullRequest_OngoingVerification) GetAllCapturedArguments() (_param0 []string, _param1 []*codecommit.PullRequest) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]string, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(string)
+			}
+		}
+		if len(_params) > 1 {
+			_param1 = make([]*codecommit.PullRequest, len(c.methodInvocations))
+			for u, param := range _params[1] {
+				_param1[u] = param.(*codecommit.PullRequest)
+			}
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockEventParsing) ParseGerritChange(change *gerrit.ChangeInfo) *MockEventParsing_ParseGerritChange_OngoingVerification {
+	_params := []pegomock.Param{change}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "ParseGerritChange", _params, verifier.timeout)
+	return &MockEventParsing_ParseGerritChange_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockEventParsing_ParseGerritChange_OngoingVerification struct {
+	mock              *MockEventParsing
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockEventParsing_ParseGerritChange_OngoingVerification) GetCapturedArguments() *gerrit.ChangeInfo {
+	change := c.GetAllCapturedArguments()
+	return change[len(change)-1]
+}
+
+func (c *MockEventParsing_ParseGerritChange_OngoingVerification) GetAllCapturedArguments() (_param0 []*gerrit.ChangeInfo) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]*gerrit.ChangeInfo, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(*gerrit.ChangeInfo)
+			}
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockEventParsing) ParseGerritChangeEvent(event gerrit.Event) *MockEventParsing_ParseGerritChangeEvent_OngoingVerification {
+	_params := []pegomock.Param{event}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "ParseGerritChangeEvent", _params, verifier.timeout)
+	return &MockEventParsing_ParseGerritChangeEvent_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockEventParsing_ParseGerritChangeEvent_OngoingVerification struct {
+	mock              *MockEventParsing
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockEventParsing_ParseGerritChangeEvent_OngoingVerification) GetCapturedArguments() gerrit.Event {
+	event := c.GetAllCapturedArguments()
+	return event[len(event)-1]
+}
+
+func (c *MockEventParsing_ParseGerritChangeEvent_OngoingVerification) GetAllCapturedArguments() (_param0 []gerrit.Event) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]gerrit.Event, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(gerrit.Event)
+			}
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockEventParsing) ParseGerritCommentEvent(event gerrit.Event) *MockEventParsing_ParseGerritCommentEvent_OngoingVerification {
+	_params := []pegomock.Param{event}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "ParseGerritCommentEvent", _params, verifier.timeout)
+	return &MockEventParsing_ParseGerritCommentEvent_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockEventParsing_ParseGerritCommentEvent_OngoingVerification struct {
+	mock              *MockEventParsing
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockEventParsing_ParseGerritCommentEvent_OngoingVerification) GetCapturedArguments() gerrit.Event {
+	event := c.GetAllCapturedArguments()
+	return event[len(event)-1]
+}
+
+func (c *MockEventParsing_ParseGerritCommentEvent_OngoingVerification) GetAllCapturedArguments() (_param0 []gerrit.Event) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]gerrit.Event, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(gerrit.Event)
+			}
+		}
+	}
+	return
+}
+
 func (verifier *VerifierMockEventParsing) ParseGiteaIssueCommentEvent(event gitea0.GiteaIssueCommentPayload) *MockEventParsing_ParseGiteaIssueCommentEvent_OngoingVerification {
 	_params := []pegomock.Param{event}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "ParseGiteaIssueCommentEvent", _params, verifier.timeout)
@@ -0,0 +1,81 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"slices"
+	"time"
+)
+
+// APITokenScope is what an API token may do. Each scope includes the scopes
+// before it.
+type APITokenScope string
+
+const (
+	// APITokenScopeReadOnly may only call the endpoints that don't change
+	// anything, ex. listing locks and downloading plans.
+	APITokenScopeReadOnly APITokenScope = "read-only"
+	// APITokenScopePlan may also plan.
+	APITokenScopePlan APITokenScope = "plan"
+	// APITokenScopeApply may also apply, release locks and cancel commands.
+	APITokenScopeApply APITokenScope = "apply"
+)
+
+// APITokenScopes are the valid scopes, from the least to the most allowed.
+var APITokenScopes = []APITokenScope{APITokenScopeReadOnly, APITokenScopePlan, APITokenScopeApply}
+
+// Includes returns whether s allows what scope allows.
+func (s APITokenScope) Includes(scope APITokenScope) bool {
+	i := slices.Index(APITokenScopes, s)
+	return i != -1 && i >= slices.Index(APITokenScopes, scope)
+}
+
+// Validate returns an error if s isn't a valid scope.
+func (s APITokenScope) Validate() error {
+	if !slices.Contains(APITokenScopes, s) {
+		return fmt.Errorf("invalid scope %q, must be one of read-only, plan or apply", s)
+	}
+	return nil
+}
+
+// APIToken is an API token issued to call the API with a scope on some repos.
+// Only the hash of the token is stored, the token itself is shown once when
+// it's issued.
+type APIToken struct {
+	ID string
+	// Name describes what the token is used for.
+	Name string
+	// Hash is the hex encoded SHA-256 hash of the token.
+	Hash  string
+	Scope APITokenScope
+	// Repos are patterns matching the full names of the repos the token may
+	// be used on, supporting * wildcards ex. owner/*. Empty if it may be used
+	// on all repos.
+	Repos     []string
+	CreatedBy string
+	CreatedAt time.Time
+}
+
+// AllowsRepo returns whether the token may be used on repoFullName.
+func (t APIToken) AllowsRepo(repoFullName string) bool {
+	if len(t.Repos) == 0 {
+		return true
+	}
+	for _, pattern := range t.Repos {
+		if matched, err := path.Match(pattern, repoFullName); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// HashAPIToken returns the hash of token stored in APIToken.Hash.
+func HashAPIToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
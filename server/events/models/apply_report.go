@@ -0,0 +1,103 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// ApplyReport is the provenance of an apply of a project: who approved it,
+// who applied it, which plan was applied, the results of its policy checks
+// and the resources it changed. Reports are signed and exported as change
+// management evidence.
+type ApplyReport struct {
+	ID          string
+	Repository  string
+	PullNum     int
+	PullURL     string
+	BaseBranch  string
+	HeadCommit  string
+	ProjectName string
+	RepoRelDir  string
+	Workspace   string
+	// AppliedBy is the user who applied the plan.
+	AppliedBy string
+	AppliedAt time.Time
+	// Success is false if the apply failed, the resources it changed may then
+	// be missing.
+	Success bool
+	// ApprovedBy is who approved the pull request, empty if it wasn't
+	// approved when it was applied.
+	ApprovedBy string
+	ApprovedAt time.Time
+	// PlanSHA256 is the hex encoded SHA-256 digest of the planfile that was
+	// applied, empty if there was none, ex. for custom workflows.
+	PlanSHA256    string
+	PolicyResults []ApplyReportPolicySet
+	// Summary is the plan summary of the pull request at the applied commit,
+	// empty if none was generated.
+	Summary         string
+	ResourceChanges []ApplyReportResourceChange
+}
+
+// ApplyReportPolicySet is the result of a policy set checked against the
+// applied plan.
+type ApplyReportPolicySet struct {
+	Name      string
+	Passed    bool
+	Approvals int
+}
+
+// ApplyReportResourceChange is a change the applied plan made to a resource.
+type ApplyReportResourceChange struct {
+	Address string
+	// Action is create, update, delete or replace.
+	Action string
+}
+
+// SignedApplyReport is an apply report and its signature.
+type SignedApplyReport struct {
+	Report ApplyReport
+	// Signature is the signature of the SHA-256 digest of Report's compact
+	// JSON, ex. ed25519:<base64 signature> or hmac-sha256:<hex signature>.
+	Signature string
+}
+
+// MarshalApplyReportJSON returns v, an apply report or values containing
+// apply reports, as compact JSON. Unlike json.Marshal, <, > and & aren't
+// escaped so that the JSON of the reports is what standard tools, ex. jq -c,
+// output, which the signatures can then be verified against.
+func MarshalApplyReportJSON(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// ApplyReportQuery selects apply reports. Zero fields match all reports.
+type ApplyReportQuery struct {
+	ID          string
+	Repository  string
+	PullNum     int
+	ProjectName string
+	// Since matches the reports of the applies at or after it.
+	Since time.Time
+	// Limit is the maximum number of reports to return, 0 for no limit.
+	Limit int
+}
+
+// Matches returns true if the query selects report.
+func (q ApplyReportQuery) Matches(report SignedApplyReport) bool {
+	r := report.Report
+	return (q.ID == "" || r.ID == q.ID) &&
+		(q.Repository == "" || r.Repository == q.Repository) &&
+		(q.PullNum == 0 || r.PullNum == q.PullNum) &&
+		(q.ProjectName == "" || r.ProjectName == q.ProjectName) &&
+		!r.AppliedAt.Before(q.Since)
+}
@@ -0,0 +1,56 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+import "time"
+
+// CommandRecordRetention is how long command records are kept.
+const CommandRecordRetention = 30 * 24 * time.Hour
+
+// CommandRequest is a comment command, ex. atlantis plan -p project, as it
+// was requested.
+type CommandRequest struct {
+	Name                string
+	SubName             string
+	RepoRelDir          string
+	Workspace           string
+	ProjectName         string
+	Flags               []string
+	Verbose             bool
+	AutoMergeDisabled   bool
+	AutoMergeMethod     string
+	PolicySet           string
+	ClearPolicyApproval bool
+	Failed              bool
+}
+
+// CommandRecord is a comment command that was run for a pull request. It's
+// stored so the command can be replayed once it's gone from the in-memory
+// timeline, ex. after a restart.
+type CommandRecord struct {
+	// ID is the ID of the command's event in the timeline of the pull
+	// request.
+	ID         string
+	Time       time.Time
+	Repository string
+	PullNum    int
+	User       string
+	Request    CommandRequest
+}
+
+// CommandRecordQuery selects command records. Zero fields match all records.
+type CommandRecordQuery struct {
+	ID         string
+	Repository string
+	PullNum    int
+	// Limit is the maximum number of records to return, 0 for no limit.
+	Limit int
+}
+
+// Matches returns true if the query selects record.
+func (q CommandRecordQuery) Matches(record CommandRecord) bool {
+	return (q.ID == "" || record.ID == q.ID) &&
+		(q.Repository == "" || record.Repository == q.Repository) &&
+		(q.PullNum == 0 || record.PullNum == q.PullNum)
+}
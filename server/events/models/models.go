@@ -80,8 +80,8 @@ func NewRepo(vcsHostType VCSHostType, repoFullName string, cloneURL string, vcsU
 		return Repo{}, errors.New("cloneURL can't be empty")
 	}
 
-	// Azure DevOps doesn't work with .git suffix on clone URLs
-	if !strings.HasSuffix(cloneURL, ".git") && vcsHostType != AzureDevops {
+	// Azure DevOps and CodeCommit don't work with .git suffix on clone URLs
+	if !strings.HasSuffix(cloneURL, ".git") && vcsHostType != AzureDevops && vcsHostType != CodeCommit {
 		cloneURL += ".git"
 	}
 
@@ -95,7 +95,10 @@ func NewRepo(vcsHostType VCSHostType, repoFullName string, cloneURL string, vcsU
 	// and because the caller in that case actually constructs the clone url
 	// from the repo name and so there's no point checking if they match.
 	// Azure DevOps also does not require .git at the end of clone urls.
-	if vcsHostType != BitbucketServer && vcsHostType != AzureDevops {
+	// Gerrit clone urls are prefixed by /a/ for authentication.
+	// CodeCommit clone urls are /v1/repos/<name>, without the account of the
+	// repo.
+	if vcsHostType != BitbucketServer && vcsHostType != AzureDevops && vcsHostType != Gerrit && vcsHostType != CodeCommit {
 		expClonePath := fmt.Sprintf("/%s.git", repoFullName)
 		if expClonePath != cloneURLParsed.Path {
 			return Repo{}, fmt.Errorf("expected clone url to have path %q but had %q", expClonePath, cloneURLParsed.Path)
@@ -119,12 +122,17 @@ func NewRepo(vcsHostType VCSHostType, repoFullName string, cloneURL string, vcsU
 
 	// Get the owner and repo names from the full name.
 	owner, repo := SplitRepoFullName(repoFullName)
-	if owner == "" || repo == "" {
+	// Gerrit projects don't need to be in a parent project.
+	if vcsHostType == Gerrit && !strings.Contains(repoFullName, "/") {
+		repo = repoFullName
+	}
+	if (owner == "" && vcsHostType != Gerrit) || repo == "" {
 		return Repo{}, fmt.Errorf("invalid repo format %q, owner %q or repo %q was empty", repoFullName, owner, repo)
 	}
-	// Only GitLab and AzureDevops repos can have /'s in their owners.
-	// This is for GitLab subgroups and Azure DevOps Team Projects.
-	if strings.Contains(owner, "/") && vcsHostType != Gitlab && vcsHostType != AzureDevops {
+	// Only GitLab, AzureDevops and Gerrit repos can have /'s in their owners.
+	// This is for GitLab subgroups, Azure DevOps Team Projects and nested
+	// Gerrit projects.
+	if strings.Contains(owner, "/") && vcsHostType != Gitlab && vcsHostType != AzureDevops && vcsHostType != Gerrit {
 		return Repo{}, fmt.Errorf("invalid repo format %q, owner %q should not contain any /'s", repoFullName, owner)
 	}
 	if strings.Contains(repo, "/") {
@@ -177,6 +185,12 @@ type PullRequest struct {
 	BaseBranch string
 	// Author is the username of the pull request author.
 	Author string
+	// Title is the title of the pull request. It's only set for VCS hosts
+	// whose events include it.
+	Title string
+	// Body is the description of the pull request. Like Title, it's only set
+	// for VCS hosts whose events include it.
+	Body string
 	// State will be one of Open or Closed.
 	// Gitlab supports an additional "merged" state but Github doesn't so we map
 	// merged to Closed.
@@ -193,6 +207,10 @@ type PullRequestOptions struct {
 	// MergeMethod specifies the merge method for the VCS
 	// Implemented only for Github
 	MergeMethod string
+	// CommitMessage is the description of the merge commit, empty for the
+	// VCS's default.
+	// Implemented only for Github
+	CommitMessage string
 }
 
 type PullRequestState int
@@ -320,6 +338,8 @@ const (
 	BitbucketServer
 	AzureDevops
 	Gitea
+	Gerrit
+	CodeCommit
 )
 
 func (h VCSHostType) String() string {
@@ -336,6 +356,10 @@ func (h VCSHostType) String() string {
 		return "AzureDevops"
 	case Gitea:
 		return "Gitea"
+	case Gerrit:
+		return "Gerrit"
+	case CodeCommit:
+		return "CodeCommit"
 	}
 	return "<missing String() implementation>"
 }
@@ -354,6 +378,10 @@ func NewVCSHostType(t string) (VCSHostType, error) {
 		return AzureDevops, nil
 	case "Gitea":
 		return Gitea, nil
+	case "Gerrit":
+		return Gerrit, nil
+	case "CodeCommit":
+		return CodeCommit, nil
 	}
 
 	return -1, fmt.Errorf("%q is not a valid type", t)
@@ -389,6 +417,126 @@ type PlanSuccess struct {
 	// branch we're merging into had been updated, and we had to merge again
 	// before planning
 	MergedAgain bool
+	// PlanJSON is the compacted JSON representation of the plan that's
+	// summarized instead of TerraformOutput. It's empty if it's unavailable.
+	PlanJSON string
+	// PlanGraph is a Mermaid flowchart of the resources the plan changes and
+	// of the resources depending on them. It's empty if plans aren't graphed.
+	PlanGraph string
+	// Cost is the estimated cost of the plan. It's nil if the project's
+	// workflow doesn't estimate costs.
+	Cost *CostEstimate
+	// Audit is the audit of the project's providers and modules. It's nil if
+	// the project's workflow doesn't audit them.
+	Audit *AuditReport
+}
+
+// CostEstimate is the estimated monthly cost of a project once its plan is
+// applied.
+type CostEstimate struct {
+	// Currency is the currency of the costs, ex. USD.
+	Currency string
+	// MonthlyCost is the monthly cost of the project once the plan is applied.
+	MonthlyCost float64
+	// PastMonthlyCost is the monthly cost of the project before the plan.
+	PastMonthlyCost float64
+	// MonthlyDelta is how much applying the plan changes the monthly cost.
+	MonthlyDelta float64
+	// Threshold is how much the plan may increase the monthly cost before
+	// the pull request must be approved to apply it. It's nil if the project
+	// has no cost threshold.
+	Threshold *float64
+}
+
+// ExceedsThreshold returns true if the plan increases the monthly cost by
+// more than the project's cost threshold.
+func (c CostEstimate) ExceedsThreshold() bool {
+	return c.Threshold != nil && c.MonthlyDelta > *c.Threshold
+}
+
+// FormatCost formats cost in the estimate's currency, ex. 12.50 USD.
+func (c CostEstimate) FormatCost(cost float64) string {
+	return strings.TrimSpace(fmt.Sprintf("%.2f %s", cost, c.Currency))
+}
+
+// FormatDelta formats the change of the monthly cost with its sign, ex.
+// +12.50 USD.
+func (c CostEstimate) FormatDelta() string {
+	if c.MonthlyDelta >= 0 {
+		return "+" + c.FormatCost(c.MonthlyDelta)
+	}
+	return c.FormatCost(c.MonthlyDelta)
+}
+
+// FileAnnotation is a finding commented on lines of a pull request's file.
+type FileAnnotation struct {
+	// Path is the path of the file relative to the repo root.
+	Path string
+	// StartLine and EndLine are the lines the finding is on, inclusive.
+	StartLine int
+	EndLine   int
+	Body      string
+	// Suggestion replaces the lines, unless it's nil.
+	Suggestion *string
+}
+
+// ProposedPullRequest is a pull request Atlantis opens, ex. to remediate
+// drift.
+type ProposedPullRequest struct {
+	// Branch is the branch the changes are committed to. It's reset onto
+	// BaseBranch each time the pull request is opened or updated.
+	Branch     string
+	BaseBranch string
+	Title      string
+	Body       string
+	// Files are the contents of the files the pull request changes, by path
+	// relative to the repo root.
+	Files map[string]string
+	// CommitMessage is the message of the commit changing the files.
+	CommitMessage string
+	// Reviewers are the users and teams, ex. @org/team, whose review is
+	// requested. Users are also assigned.
+	Reviewers []string
+}
+
+// AuditReport is the audit of the providers and modules a project uses for
+// known vulnerabilities and outdated versions.
+type AuditReport struct {
+	// Findings are the providers and modules with known vulnerabilities or
+	// outdated versions.
+	Findings []AuditFinding `json:"findings"`
+	// Notes are what couldn't be audited, ex. because the registry couldn't
+	// be reached.
+	Notes []string `json:"notes,omitempty"`
+}
+
+// AuditFinding is a provider or module with known vulnerabilities or an
+// outdated version.
+type AuditFinding struct {
+	// Kind is provider or module.
+	Kind string `json:"kind"`
+	// Source is the source address, ex. registry.terraform.io/hashicorp/aws.
+	Source string `json:"source"`
+	// Version is the version the project uses.
+	Version string `json:"version"`
+	// LatestVersion is the latest version if Version is outdated.
+	LatestVersion string `json:"latest_version,omitempty"`
+	// Advisories are the known vulnerabilities of Version.
+	Advisories []AuditAdvisory `json:"advisories,omitempty"`
+}
+
+// Outdated returns true if the version the project uses is outdated.
+func (f AuditFinding) Outdated() bool {
+	return f.LatestVersion != ""
+}
+
+// AuditAdvisory is a known vulnerability of a provider or module.
+type AuditAdvisory struct {
+	// ID identifies the vulnerability, ex. CVE-2023-1234.
+	ID       string `json:"id"`
+	Severity string `json:"severity,omitempty"`
+	Summary  string `json:"summary,omitempty"`
+	URL      string `json:"url,omitempty"`
 }
 
 type PolicySetResult struct {
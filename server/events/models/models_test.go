@@ -726,3 +726,19 @@ func TestPlanSuccessStats(t *testing.T) {
 		})
 	}
 }
+
+func TestAPITokenScope_Includes(t *testing.T) {
+	Assert(t, models.APITokenScopeApply.Includes(models.APITokenScopePlan), "apply should include plan")
+	Assert(t, models.APITokenScopePlan.Includes(models.APITokenScopeReadOnly), "plan should include read-only")
+	Assert(t, !models.APITokenScopeReadOnly.Includes(models.APITokenScopePlan), "read-only shouldn't include plan")
+	Assert(t, !models.APITokenScope("admin").Includes(models.APITokenScopeReadOnly), "invalid scopes shouldn't include anything")
+	ErrEquals(t, `invalid scope "admin", must be one of read-only, plan or apply`, models.APITokenScope("admin").Validate())
+}
+
+func TestAPIToken_AllowsRepo(t *testing.T) {
+	Assert(t, models.APIToken{}.AllowsRepo("owner/repo"), "tokens without repos should allow all repos")
+	token := models.APIToken{Repos: []string{"owner/*", "other/repo"}}
+	Assert(t, token.AllowsRepo("owner/repo"), "exp owner/repo to be allowed")
+	Assert(t, token.AllowsRepo("other/repo"), "exp other/repo to be allowed")
+	Assert(t, !token.AllowsRepo("other/other"), "exp other/other not to be allowed")
+}
@@ -0,0 +1,17 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+// ParentPull is the pull request a stacked pull request is based on, its head
+// branch being the stacked pull request's base branch.
+type ParentPull struct {
+	Num int
+	// HeadBranch is the parent's head branch, the base branch of the stacked
+	// pull request.
+	HeadBranch string
+	// BaseBranch is the branch the parent is, or was, getting merged into.
+	BaseBranch string
+	// Merged is true if the parent was merged.
+	Merged bool
+}
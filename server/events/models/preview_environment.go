@@ -0,0 +1,49 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// PreviewEnvironment is a project of a pull request applied in a workspace of
+// its own by atlantis preview. It's destroyed once the pull request is closed
+// or it expires.
+type PreviewEnvironment struct {
+	Pull     PullRequest
+	HeadRepo Repo
+	// User is who deployed the environment last.
+	User User
+	// ProjectName and RepoRelDir identify the project in the repo config.
+	ProjectName string
+	RepoRelDir  string
+	// Workspace is the Terraform workspace the environment is applied in, ex.
+	// pr-12.
+	Workspace  string
+	DeployedAt time.Time
+	// ExpiresAt is when the environment is destroyed even if the pull request
+	// is still open. Zero if it doesn't expire.
+	ExpiresAt time.Time
+	// Closed is true once the pull request is closed, the environment is then
+	// destroyed as soon as possible.
+	Closed bool
+	// Outputs are the Terraform outputs of the environment, ex. its URLs.
+	// Sensitive outputs aren't stored.
+	Outputs map[string]string
+	// Error is why the environment last failed to be destroyed, it's retried
+	// until it succeeds.
+	Error string
+}
+
+// ID identifies the environment among the environments of all the pull
+// requests.
+func (e PreviewEnvironment) ID() string {
+	return fmt.Sprintf("%s/%d/%s/%s/%s", e.Pull.BaseRepo.FullName, e.Pull.Num, e.RepoRelDir, e.ProjectName, e.Workspace)
+}
+
+// Expired returns whether the environment should be destroyed at now.
+func (e PreviewEnvironment) Expired(now time.Time) bool {
+	return e.Closed || (!e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt))
+}
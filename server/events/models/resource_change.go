@@ -0,0 +1,53 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ResourceChange is a change to a resource made by applying a project's plan.
+type ResourceChange struct {
+	// Address is the resource's address, ex. module.vpc.aws_security_group.web.
+	Address string
+	// Action is create, update, delete or replace.
+	Action      string
+	Repository  string
+	PullNum     int
+	PullURL     string
+	HeadCommit  string
+	User        string
+	ProjectName string
+	RepoRelDir  string
+	Workspace   string
+	AppliedAt   time.Time
+}
+
+// ResourceChangeQuery selects resource changes. Zero fields match all changes.
+type ResourceChangeQuery struct {
+	Repository string
+	PullNum    int
+	// Address matches the changes to the resource with this address and to
+	// its instances, ex. aws_instance.web matches aws_instance.web[0].
+	Address     string
+	ProjectName string
+	User        string
+	// Since matches the changes applied at or after it.
+	Since time.Time
+	// Limit is the maximum number of changes to return, 0 for no limit.
+	Limit int
+}
+
+// Matches returns true if the query selects change.
+func (q ResourceChangeQuery) Matches(change ResourceChange) bool {
+	if q.Address != "" && change.Address != q.Address && !strings.HasPrefix(change.Address, q.Address+"[") {
+		return false
+	}
+	return (q.Repository == "" || change.Repository == q.Repository) &&
+		(q.PullNum == 0 || change.PullNum == q.PullNum) &&
+		(q.ProjectName == "" || change.ProjectName == q.ProjectName) &&
+		(q.User == "" || change.User == q.User) &&
+		!change.AppliedAt.Before(q.Since)
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// stateLockErr is part of the error Terraform prints when it can't acquire
+// the state lock, ex. because a DynamoDB lock is held by another run.
+const stateLockErr = "Error acquiring the state lock"
+
+// stateLockInfoRegex matches the fields Terraform prints under "Lock Info:",
+// ex. "  Who:       user@host". Newer versions prefix the lines with "│".
+var stateLockInfoRegex = regexp.MustCompile(`^[│\s]*(ID|Path|Operation|Who|Version|Created|Info):\s*(.*?)\s*$`)
+
+// StateLock is a Terraform state lock that's held by someone else.
+type StateLock struct {
+	// ID is the ID of the lock, it's what terraform force-unlock takes.
+	ID string
+	// Path is the path of the state in the backend.
+	Path string
+	// Operation is the operation that acquired the lock, ex.
+	// OperationTypeApply.
+	Operation string
+	// Who is the user and host that acquired the lock, ex. user@host.
+	Who string
+	// Version is the Terraform version that acquired the lock.
+	Version string
+	// Created is when the lock was acquired, as printed by Terraform.
+	Created string
+	// Info is extra information about the lock, it's often empty.
+	Info string
+	// UnlockURL is the URL of the Atlantis UI the lock can be force-unlocked
+	// from. It's empty if force-unlocking isn't enabled.
+	UnlockURL string
+}
+
+// ParseStateLock returns the state lock described in the output of a failed
+// Terraform command, or nil if the command didn't fail because the state is
+// locked.
+func ParseStateLock(output string) *StateLock {
+	if !strings.Contains(output, stateLockErr) {
+		return nil
+	}
+	_, info, ok := strings.Cut(output, "Lock Info:")
+	if !ok {
+		return nil
+	}
+
+	var lock StateLock
+	fields := map[string]*string{
+		"ID":        &lock.ID,
+		"Path":      &lock.Path,
+		"Operation": &lock.Operation,
+		"Who":       &lock.Who,
+		"Version":   &lock.Version,
+		"Created":   &lock.Created,
+		"Info":      &lock.Info,
+	}
+	for _, line := range strings.Split(info, "\n") {
+		match := stateLockInfoRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		// Only keep the first occurrence, the output of other steps may
+		// follow.
+		if field := fields[match[1]]; *field == "" {
+			*field = match[2]
+		}
+	}
+	if lock.ID == "" {
+		return nil
+	}
+	return &lock
+}
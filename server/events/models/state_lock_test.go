@@ -0,0 +1,86 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package models_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestParseStateLock(t *testing.T) {
+	cases := []struct {
+		description string
+		output      string
+		exp         *models.StateLock
+	}{
+		{
+			"not a state lock error",
+			"running \"terraform plan\": exit status 1\nError: Invalid reference",
+			nil,
+		},
+		{
+			"state lock error",
+			`running "terraform plan": exit status 1
+Error: Error acquiring the state lock
+
+Error message: ConditionalCheckFailedException: The conditional request failed
+Lock Info:
+  ID:        9db590f1-b6fe-c5f2-2678-8804f089deba
+  Path:      my-bucket/network/terraform.tfstate
+  Operation: OperationTypeApply
+  Who:       runner@ci-host
+  Version:   1.5.7
+  Created:   2024-01-02 03:04:05.123456 +0000 UTC
+  Info:
+
+Terraform acquires a state lock to protect the state from being written
+by multiple users at the same time.`,
+			&models.StateLock{
+				ID:        "9db590f1-b6fe-c5f2-2678-8804f089deba",
+				Path:      "my-bucket/network/terraform.tfstate",
+				Operation: "OperationTypeApply",
+				Who:       "runner@ci-host",
+				Version:   "1.5.7",
+				Created:   "2024-01-02 03:04:05.123456 +0000 UTC",
+			},
+		},
+		{
+			"boxed state lock error",
+			`╷
+│ Error: Error acquiring the state lock
+│
+│ Error message: resource temporarily unavailable
+│ Lock Info:
+│   ID:        1f3a
+│   Path:      terraform.tfstate
+│   Operation: OperationTypePlan
+│   Who:       atlantis@host
+│   Version:   1.9.0
+│   Created:   2024-01-02 03:04:05 +0000 UTC
+│   Info:      nightly drift check
+╵`,
+			&models.StateLock{
+				ID:        "1f3a",
+				Path:      "terraform.tfstate",
+				Operation: "OperationTypePlan",
+				Who:       "atlantis@host",
+				Version:   "1.9.0",
+				Created:   "2024-01-02 03:04:05 +0000 UTC",
+				Info:      "nightly drift check",
+			},
+		},
+		{
+			"state lock error without lock info",
+			"Error: Error acquiring the state lock\n\nError message: timeout",
+			nil,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			Equals(t, c.exp, models.ParseStateLock(c.output))
+		})
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// SummaryFeedback is the feedback left on a generated plan summary comment
+// through 👍 and 👎 reactions.
+type SummaryFeedback struct {
+	// ID uniquely identifies the summary. It's embedded in the summary
+	// comment so the comment can be matched back to it.
+	ID      string
+	Repo    Repo
+	PullNum int
+	// CommentID is the ID of the summary comment. It's 0 until the comment
+	// has been found.
+	CommentID int64
+	// Variant is the summarizer variant that generated the summary, empty if
+	// there were no variants.
+	Variant string
+	// Model is the model, or summarizer command, that generated the summary.
+	Model string
+	// PromptHash identifies the system prompt used to generate the summary.
+	PromptHash string
+	// Latency is how long generating the summary took.
+	Latency time.Duration
+	// Cost is the cost of generating the summary in USD, 0 if it isn't known.
+	Cost       float64
+	ThumbsUp   int
+	ThumbsDown int
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// NewSummaryFeedbackID returns the ID of a summary generated at createdAt for
+// the pull request.
+func NewSummaryFeedbackID(repoFullName string, pullNum int, createdAt time.Time) string {
+	return fmt.Sprintf("%s/%d/%d", repoFullName, pullNum, createdAt.UnixNano())
+}
+
+// CommentReactions are the 👍 and 👎 reactions on a comment.
+type CommentReactions struct {
+	CommentID  int64
+	Body       string
+	ThumbsUp   int
+	ThumbsDown int
+}
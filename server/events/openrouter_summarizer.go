@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,9 +19,26 @@ const (
 	openRouterAPIKeyEnv       = "OPENROUTER_API_KEY"
 	openRouterSystemPromptEnv = "OPENROUTER_TERRAFORM_PLAN_SUMMARIZER_SYSTEM_PROMPT"
 	openRouterModelEnv        = "OPENROUTER_TERRAFORM_PLAN_SUMMARIZER_MODEL"
-	openRouterTimeout         = 30 * time.Second
-	defaultModel              = "anthropic/claude-opus-4.8"
-	defaultSystemPrompt       = `You summarize Terraform plans for a senior engineer scanning a PR. Give the gist at a glance: a few thematic bullets, never a flat list of every resource.
+	// openRouterURLEnv overrides the chat completions URL, ex. to go through
+	// a proxy.
+	openRouterURLEnv = "OPENROUTER_API_URL"
+	// openRouterRefererEnv and openRouterTitleEnv set the HTTP-Referer and
+	// X-Title headers OpenRouter attributes usage to in its analytics.
+	openRouterRefererEnv = "OPENROUTER_HTTP_REFERER"
+	openRouterTitleEnv   = "OPENROUTER_X_TITLE"
+	// openRouterUserEnv is sent as the request's user, a stable identifier
+	// OpenRouter reports usage by.
+	openRouterUserEnv = "OPENROUTER_USER"
+	// The provider routing preferences, the lists are comma separated
+	// provider slugs, ex. anthropic,amazon-bedrock.
+	openRouterProviderOrderEnv          = "OPENROUTER_PROVIDER_ORDER"
+	openRouterProviderOnlyEnv           = "OPENROUTER_PROVIDER_ONLY"
+	openRouterProviderAllowFallbacksEnv = "OPENROUTER_PROVIDER_ALLOW_FALLBACKS"
+	openRouterProviderDataCollectionEnv = "OPENROUTER_PROVIDER_DATA_COLLECTION"
+	defaultOpenRouterReferer            = "https://github.com/memfault/atlantis-openrouter-summarizer"
+	openRouterTimeout                   = 30 * time.Second
+	defaultModel                        = "anthropic/claude-opus-4.8"
+	defaultSystemPrompt                 = `You summarize Terraform plans for a senior engineer scanning a PR. Give the gist at a glance: a few thematic bullets, never a flat list of every resource.
 
 If no project has resource changes, reply with exactly one line and stop:
 "**No changes.** All {N} projects match current state."
@@ -43,6 +61,23 @@ Never report terraform init output, provider/module versions, or backend config
 type openRouterRequest struct {
 	Model    string              `json:"model"`
 	Messages []openRouterMessage `json:"messages"`
+	User     string              `json:"user,omitempty"`
+	Provider *openRouterProvider `json:"provider,omitempty"`
+	Usage    openRouterUsageOpts `json:"usage"`
+}
+
+// openRouterUsageOpts asks OpenRouter to report the usage, including the
+// cost, of a request
+type openRouterUsageOpts struct {
+	Include bool `json:"include"`
+}
+
+// openRouterProvider represents the provider routing preferences of a request
+type openRouterProvider struct {
+	Order          []string `json:"order,omitempty"`
+	Only           []string `json:"only,omitempty"`
+	AllowFallbacks *bool    `json:"allow_fallbacks,omitempty"`
+	DataCollection string   `json:"data_collection,omitempty"`
 }
 
 // openRouterMessage represents a message in the chat completion request
@@ -55,6 +90,12 @@ type openRouterMessage struct {
 type openRouterResponse struct {
 	Choices []openRouterChoice `json:"choices"`
 	Error   *openRouterError   `json:"error,omitempty"`
+	Usage   *openRouterUsage   `json:"usage,omitempty"`
+}
+
+// openRouterUsage represents the usage of a request, cost is in USD
+type openRouterUsage struct {
+	Cost float64 `json:"cost"`
 }
 
 // openRouterChoice represents a choice in the response
@@ -68,35 +109,75 @@ type openRouterError struct {
 	Type    string `json:"type"`
 }
 
-// SummarizePlans sends Terraform plan outputs to OpenRouter for summarization.
+// SummarizePlans sends Terraform plan outputs to OpenRouter, or the external
+// summarizer command if one is configured, for summarization.
 // It combines all plan outputs into a single request and returns the summary.
+// promptData is used to render the system prompt if it's a template.
 // If the API key is not set or an error occurs, it returns an empty string
 // and logs the error (fails gracefully).
-func SummarizePlans(terraformOutputs []string, logger logging.SimpleLogging) string {
+func SummarizePlans(terraformOutputs []string, promptData SummaryPromptData, logger logging.SimpleLogging) string {
+	return GenerateSummary(terraformOutputs, promptData, nil, logger).Summary
+}
+
+// GenerateSummary is SummarizePlans with the model and prompt of variant,
+// which may be nil, returning what generated the summary along with it.
+func GenerateSummary(terraformOutputs []string, promptData SummaryPromptData, variant *SummaryVariant, logger logging.SimpleLogging) GeneratedSummary {
 	if len(terraformOutputs) == 0 {
 		logger.Debug("no terraform outputs to summarize")
-		return ""
+		return GeneratedSummary{}
 	}
 
-	apiKey := os.Getenv(openRouterAPIKeyEnv)
-	if apiKey == "" {
-		logger.Debug("OPENROUTER_API_KEY not set, skipping plan summarization")
-		return ""
+	// Strip noise from each plan and combine them with separators
+	filteredOutputs := make([]string, 0, len(terraformOutputs))
+	for _, output := range terraformOutputs {
+		filteredOutputs = append(filteredOutputs, filterPlanForSummary(output))
+	}
+	combinedOutput := strings.Join(filteredOutputs, "\n\n---\n\n")
+
+	source, templated := variant.promptSource(logger)
+	systemPrompt := source
+	if templated {
+		systemPrompt = renderPromptTemplate(source, promptData, logger)
 	}
+	// Variants setting a model always use it so they can be compared.
+	model := variant.model()
+	if variant == nil || variant.Model == "" {
+		model = routeSummaryModel(loadSummaryTiers(logger), model, combinedOutput)
+	}
+	generated := GeneratedSummary{Variant: variant.name()}
+	generated.Model, generated.PromptHash = summarizerIdentity(model, source)
+	start := time.Now()
+	reply := completeWith(model, systemPrompt, combinedOutput, logger)
+	generated.Latency = time.Since(start)
+	generated.Summary, generated.Cost = reply.content, reply.cost
+	return generated
+}
 
-	// Combine all plan outputs with separators
-	combinedOutput := strings.Join(terraformOutputs, "\n\n---\n\n")
+// completion is the reply to a completion request and its cost in USD, 0 if
+// it isn't known.
+type completion struct {
+	content string
+	cost    float64
+}
+
+// complete sends systemPrompt and input to OpenRouter, or the external
+// summarizer command if one is configured, and returns the reply. It returns
+// an empty string if neither is configured or an error occurs.
+func complete(systemPrompt string, input string, logger logging.SimpleLogging) string {
+	return completeWith(summarizerModel(), systemPrompt, input, logger).content
+}
 
-	// Get system prompt from environment variable, with fallback to default
-	systemPrompt := os.Getenv(openRouterSystemPromptEnv)
-	if systemPrompt == "" {
-		systemPrompt = defaultSystemPrompt
+// completeWith is complete with model.
+func completeWith(model string, systemPrompt string, input string, logger logging.SimpleLogging) completion {
+	// An external summarizer command replaces OpenRouter entirely.
+	if summarizerCommand := os.Getenv(execSummarizerCommandEnv); summarizerCommand != "" {
+		return completion{content: summarizeWithCommand(summarizerCommand, systemPrompt, input, logger)}
 	}
 
-	// Get model from environment variable, with fallback to default
-	model := os.Getenv(openRouterModelEnv)
-	if model == "" {
-		model = defaultModel
+	apiKey := os.Getenv(openRouterAPIKeyEnv)
+	if apiKey == "" {
+		logger.Debug("OPENROUTER_API_KEY not set, skipping plan summarization")
+		return completion{}
 	}
 
 	// Prepare the request
@@ -109,27 +190,41 @@ func SummarizePlans(terraformOutputs []string, logger logging.SimpleLogging) str
 			},
 			{
 				Role:    "user",
-				Content: combinedOutput,
+				Content: input,
 			},
 		},
+		User:     os.Getenv(openRouterUserEnv),
+		Provider: openRouterProviderPreferences(logger),
+		Usage:    openRouterUsageOpts{Include: true},
 	}
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
 		logger.Warn("failed to marshal OpenRouter request: %s", err)
-		return ""
+		return completion{}
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequest("POST", openRouterURL, bytes.NewBuffer(jsonData))
+	url := openRouterURL
+	if override := os.Getenv(openRouterURLEnv); override != "" {
+		url = override
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		logger.Warn("failed to create OpenRouter request: %s", err)
-		return ""
+		return completion{}
 	}
 
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("HTTP-Referer", "https://github.com/memfault/atlantis-openrouter-summarizer")
+	referer := defaultOpenRouterReferer
+	if override := os.Getenv(openRouterRefererEnv); override != "" {
+		referer = override
+	}
+	req.Header.Set("HTTP-Referer", referer)
+	if title := os.Getenv(openRouterTitleEnv); title != "" {
+		req.Header.Set("X-Title", title)
+	}
 
 	// Create HTTP client with timeout
 	client := &http.Client{
@@ -141,7 +236,7 @@ func SummarizePlans(terraformOutputs []string, logger logging.SimpleLogging) str
 	resp, err := client.Do(req)
 	if err != nil {
 		logger.Warn("failed to send request to OpenRouter: %s", err)
-		return ""
+		return completion{}
 	}
 	defer resp.Body.Close()
 
@@ -149,40 +244,96 @@ func SummarizePlans(terraformOutputs []string, logger logging.SimpleLogging) str
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		logger.Warn("failed to read OpenRouter response: %s", err)
-		return ""
+		return completion{}
 	}
 
 	// Check HTTP status
 	if resp.StatusCode != http.StatusOK {
 		logger.Warn("OpenRouter API returned status %d: %s", resp.StatusCode, string(body))
-		return ""
+		return completion{}
 	}
 
 	// Parse response
 	var openRouterResp openRouterResponse
 	if err := json.Unmarshal(body, &openRouterResp); err != nil {
 		logger.Warn("failed to parse OpenRouter response: %s", err)
-		return ""
+		return completion{}
 	}
 
 	// Check for API errors
 	if openRouterResp.Error != nil {
 		logger.Warn("OpenRouter API error: %s (type: %s)", openRouterResp.Error.Message, openRouterResp.Error.Type)
-		return ""
+		return completion{}
 	}
 
 	// Extract summary from response
 	if len(openRouterResp.Choices) == 0 {
 		logger.Warn("OpenRouter response contained no choices")
-		return ""
+		return completion{}
 	}
 
 	summary := strings.TrimSpace(openRouterResp.Choices[0].Message.Content)
 	if summary == "" {
 		logger.Warn("OpenRouter returned empty summary")
-		return ""
+		return completion{}
 	}
 
 	logger.Debug("successfully received summary from OpenRouter")
-	return summary
+	reply := completion{content: summary}
+	if openRouterResp.Usage != nil {
+		reply.cost = openRouterResp.Usage.Cost
+	}
+	return reply
+}
+
+// summarizerSystemPrompt returns the system prompt from the environment
+// variable, with fallback to the default.
+func summarizerSystemPrompt() string {
+	if systemPrompt := os.Getenv(openRouterSystemPromptEnv); systemPrompt != "" {
+		return systemPrompt
+	}
+	return defaultSystemPrompt
+}
+
+// summarizerModel returns the model from the environment variable, with
+// fallback to the default.
+func summarizerModel() string {
+	if model := os.Getenv(openRouterModelEnv); model != "" {
+		return model
+	}
+	return defaultModel
+}
+
+// openRouterProviderPreferences returns the provider routing preferences from
+// the environment variables or nil if none are set, in which case OpenRouter
+// routes requests to any provider.
+func openRouterProviderPreferences(logger logging.SimpleLogging) *openRouterProvider {
+	provider := openRouterProvider{
+		Order:          splitProviders(os.Getenv(openRouterProviderOrderEnv)),
+		Only:           splitProviders(os.Getenv(openRouterProviderOnlyEnv)),
+		DataCollection: os.Getenv(openRouterProviderDataCollectionEnv),
+	}
+	if allowFallbacks := os.Getenv(openRouterProviderAllowFallbacksEnv); allowFallbacks != "" {
+		b, err := strconv.ParseBool(allowFallbacks)
+		if err != nil {
+			logger.Warn("ignoring invalid %s %q: %s", openRouterProviderAllowFallbacksEnv, allowFallbacks, err)
+		} else {
+			provider.AllowFallbacks = &b
+		}
+	}
+	if provider.Order == nil && provider.Only == nil && provider.AllowFallbacks == nil && provider.DataCollection == "" {
+		return nil
+	}
+	return &provider
+}
+
+// splitProviders splits a comma separated list of provider slugs.
+func splitProviders(list string) []string {
+	var providers []string
+	for _, p := range strings.Split(list, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			providers = append(providers, p)
+		}
+	}
+	return providers
 }
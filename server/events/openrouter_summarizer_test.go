@@ -0,0 +1,71 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// openRouterServer starts a fake OpenRouter API that replies with summary
+// and records the last request it received.
+func openRouterServer(t *testing.T, summary string) (*http.Request, map[string]any) {
+	var gotReq http.Request
+	gotBody := make(map[string]any)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotReq = *r
+		Ok(t, json.NewDecoder(r.Body).Decode(&gotBody))
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "` + summary + `"}}]}`)) // nolint: errcheck
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENROUTER_API_URL", server.URL)
+	t.Setenv("OPENROUTER_API_KEY", "key")
+	t.Setenv("TERRAFORM_PLAN_SUMMARIZER_COMMAND", "")
+	return &gotReq, gotBody
+}
+
+func TestSummarizePlans_OpenRouterAttribution(t *testing.T) {
+	req, body := openRouterServer(t, "summary")
+
+	Equals(t, "summary", events.SummarizePlans([]string{"plan"}, events.SummaryPromptData{}, logging.NewNoopLogger(t)))
+	Equals(t, "Bearer key", req.Header.Get("Authorization"))
+	Equals(t, "https://github.com/memfault/atlantis-openrouter-summarizer", req.Header.Get("HTTP-Referer"))
+	Equals(t, "", req.Header.Get("X-Title"))
+	_, ok := body["user"]
+	Equals(t, false, ok)
+
+	t.Setenv("OPENROUTER_HTTP_REFERER", "https://example.com")
+	t.Setenv("OPENROUTER_X_TITLE", "Example Atlantis")
+	t.Setenv("OPENROUTER_USER", "infra")
+	Equals(t, "summary", events.SummarizePlans([]string{"plan"}, events.SummaryPromptData{}, logging.NewNoopLogger(t)))
+	Equals(t, "https://example.com", req.Header.Get("HTTP-Referer"))
+	Equals(t, "Example Atlantis", req.Header.Get("X-Title"))
+	Equals(t, "infra", body["user"])
+}
+
+func TestSummarizePlans_OpenRouterProvider(t *testing.T) {
+	_, body := openRouterServer(t, "summary")
+
+	events.SummarizePlans([]string{"plan"}, events.SummaryPromptData{}, logging.NewNoopLogger(t))
+	_, ok := body["provider"]
+	Equals(t, false, ok)
+
+	t.Setenv("OPENROUTER_PROVIDER_ORDER", "anthropic, amazon-bedrock")
+	t.Setenv("OPENROUTER_PROVIDER_ONLY", "anthropic,amazon-bedrock")
+	t.Setenv("OPENROUTER_PROVIDER_ALLOW_FALLBACKS", "false")
+	t.Setenv("OPENROUTER_PROVIDER_DATA_COLLECTION", "deny")
+	events.SummarizePlans([]string{"plan"}, events.SummaryPromptData{}, logging.NewNoopLogger(t))
+	Equals(t, map[string]any{
+		"order":           []any{"anthropic", "amazon-bedrock"},
+		"only":            []any{"anthropic", "amazon-bedrock"},
+		"allow_fallbacks": false,
+		"data_collection": "deny",
+	}, body["provider"])
+}
@@ -0,0 +1,68 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// ownerMentions returns the line of a plan comment mentioning the owners of
+// the projects of results whose plans have changes, or an empty string if
+// there's no one to mention. Projects mention the owners they declare in
+// atlantis.yaml or, if MentionCodeOwners is set, the owners of their
+// modified files in the base branch's CODEOWNERS. The author of the pull request
+// isn't mentioned.
+func (c *PullUpdater) ownerMentions(ctx *command.Context, results []command.ProjectResult) string {
+	var mentions []string
+	add := func(owners []string) {
+		for _, owner := range owners {
+			if !strings.HasPrefix(owner, "@") || strings.EqualFold(owner, "@"+ctx.Pull.Author) || slices.Contains(mentions, owner) {
+				continue
+			}
+			mentions = append(mentions, owner)
+		}
+	}
+
+	var codeOwners *CodeOwners
+	var modifiedFiles []string
+	fetched := false
+	for _, result := range results {
+		if result.PlanSuccess == nil || result.PlanSuccess.NoChanges() {
+			continue
+		}
+		if len(result.Owners) > 0 {
+			add(result.Owners)
+			continue
+		}
+		if !c.MentionCodeOwners {
+			continue
+		}
+		if !fetched {
+			fetched = true
+			var err error
+			if codeOwners, err = fetchCodeOwners(c.VCSClient, ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.BaseBranch); err != nil {
+				ctx.Log.Warn("unable to get the code owners to mention: %s", err)
+			} else if codeOwners != nil {
+				if modifiedFiles, err = c.VCSClient.GetModifiedFiles(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull); err != nil {
+					ctx.Log.Warn("unable to get the modified files to mention their code owners: %s", err)
+					codeOwners = nil
+				}
+			}
+		}
+		if codeOwners == nil {
+			continue
+		}
+		for _, owners := range codeOwners.FilesOwners(projectFiles(modifiedFiles, result.RepoRelDir)) {
+			add(owners.Owners)
+		}
+	}
+	if len(mentions) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("cc %s", strings.Join(mentions, " "))
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"testing"
+
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestPullUpdater_OwnerMentions(t *testing.T) {
+	changes := &models.PlanSuccess{TerraformOutput: "Plan: 1 to add, 0 to change, 0 to destroy."}
+	noChanges := &models.PlanSuccess{TerraformOutput: "No changes. Your infrastructure matches the configuration."}
+	results := []command.ProjectResult{
+		{ProjectCommandOutput: command.ProjectCommandOutput{PlanSuccess: changes}, RepoRelDir: "prod/db"},
+		{ProjectCommandOutput: command.ProjectCommandOutput{PlanSuccess: changes}, RepoRelDir: "network", Owners: []string{"@org/network", "@alice"}},
+		{ProjectCommandOutput: command.ProjectCommandOutput{PlanSuccess: noChanges}, RepoRelDir: "staging"},
+		{ProjectCommandOutput: command.ProjectCommandOutput{Failure: "failed"}, RepoRelDir: "dev"},
+	}
+	cases := map[string]struct {
+		mentionCodeOwners bool
+		codeOwners        string
+		exp               string
+	}{
+		"atlantis.yaml owners only": {
+			codeOwners: "* @platform\n",
+			exp:        "cc @org/network",
+		},
+		"code owners": {
+			mentionCodeOwners: true,
+			codeOwners:        "* @platform\n/prod/ @org/sre @alice\n/staging/*.tf @staging\n\n[Database] @dba\n/prod/db/\n",
+			exp:               "cc @org/sre @dba @org/network",
+		},
+		"no CODEOWNERS": {
+			mentionCodeOwners: true,
+			exp:               "cc @org/network",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			repo := models.Repo{FullName: "owner/repo"}
+			ctx := &command.Context{Log: logging.NewNoopLogger(t), Pull: models.PullRequest{Num: 1, BaseRepo: repo, BaseBranch: "main", Author: "alice"}}
+			vcsClient := vcsmocks.NewMockClient()
+			if c.codeOwners != "" {
+				When(vcsClient.GetFileContent(Any[logging.SimpleLogging](), Eq(repo), Eq("main"), Eq(".github/CODEOWNERS"))).ThenReturn(true, []byte(c.codeOwners), nil)
+			}
+			When(vcsClient.GetModifiedFiles(Any[logging.SimpleLogging](), Eq(repo), Any[models.PullRequest]())).ThenReturn([]string{"prod/db/main.tf", "network/main.tf", "staging/main.tf"}, nil)
+			updater := &PullUpdater{VCSClient: vcsClient, MentionCodeOwners: c.mentionCodeOwners}
+
+			Equals(t, c.exp, updater.ownerMentions(ctx, results))
+			if !c.mentionCodeOwners {
+				vcsClient.VerifyWasCalled(Never()).GetFileContent(Any[logging.SimpleLogging](), Any[models.Repo](), Any[string](), Any[string]())
+			}
+		})
+	}
+}
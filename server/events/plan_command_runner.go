@@ -4,6 +4,9 @@
 package events
 
 import (
+	"path/filepath"
+	"slices"
+
 	"github.com/runatlantis/atlantis/server/core/locking"
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
@@ -95,6 +98,15 @@ type PlanCommandRunner struct {
 	pullReqStatusFetcher  vcs.PullReqStatusFetcher
 	SilencePRComments     []string
 	PendingApplyStatus    bool
+	// ReuseUnchangedPlans is true if autoplans reuse the existing plans of
+	// projects whose content didn't change since they were generated.
+	ReuseUnchangedPlans bool
+	// AutoApplyRunner applies the pull requests matching a behavior rule
+	// with auto_apply once they're autoplanned without errors.
+	AutoApplyRunner CommentCommandRunner
+	// PlanJSONs are the stored JSON plans, deleted with the discarded plans.
+	// Nil if they aren't exported.
+	PlanJSONs *PlanJSONStore
 }
 
 func (p *PlanCommandRunner) runAutoplan(ctx *command.Context) {
@@ -137,7 +149,14 @@ func (p *PlanCommandRunner) runAutoplan(ctx *command.Context) {
 
 	// discard previous plans that might not be relevant anymore
 	ctx.Log.Debug("deleting previous plans and locks")
-	p.deletePlans(ctx)
+	if p.ReuseUnchangedPlans {
+		for i := range projectCmds {
+			projectCmds[i].ReuseUnchangedPlan = true
+		}
+		p.deletePlansExcept(ctx, projectCmds)
+	} else {
+		p.deletePlans(ctx)
+	}
 	_, err = p.lockingLocker.UnlockByPull(baseRepo.FullName, pull.Num)
 	if err != nil {
 		ctx.Log.Err("deleting locks: %s", err)
@@ -179,6 +198,37 @@ func (p *PlanCommandRunner) runAutoplan(ctx *command.Context) {
 
 		p.policyCheckCommandRunner.Run(ctx, policyCheckCmds)
 	}
+
+	if p.AutoApplyRunner != nil && autoApplyEnabled(projectCmds) && !result.HasErrors() && !result.PlansDeleted {
+		p.autoApply(ctx)
+	}
+}
+
+// autoApply applies the pull request of ctx if all its projects were planned,
+// and their policies passed, with changes to apply.
+func (p *PlanCommandRunner) autoApply(ctx *command.Context) {
+	pullStatus, err := p.pullStatusFetcher.GetPullStatus(ctx.Pull)
+	if err != nil {
+		ctx.Log.Err("fetching pull status to auto-apply: %s", err)
+		return
+	}
+	if pullStatus == nil || !cleanlyPlanned(*pullStatus) {
+		ctx.Log.Info("not auto-applying since some projects didn't plan cleanly or have no changes")
+		return
+	}
+	ctx.Log.Info("auto-applying since the pull request matches a behavior rule with auto_apply")
+	ctx.PullStatus = pullStatus
+	p.AutoApplyRunner.Run(ctx, &CommentCommand{Name: command.Apply})
+}
+
+// autoApplyEnabled returns true if all the projects are applied once planned.
+func autoApplyEnabled(projectCmds []command.ProjectContext) bool {
+	for _, projectCmd := range projectCmds {
+		if !projectCmd.AutoApply {
+			return false
+		}
+	}
+	return len(projectCmds) > 0
 }
 
 func (p *PlanCommandRunner) run(ctx *command.Context, cmd *CommentCommand) {
@@ -210,6 +260,23 @@ func (p *PlanCommandRunner) run(ctx *command.Context, cmd *CommentCommand) {
 		return
 	}
 
+	if cmd.Failed {
+		pullStatus, err := p.pullStatusFetcher.GetPullStatus(pull)
+		if err != nil {
+			ctx.Log.Warn("unable to fetch pull status: %s", err)
+		}
+		projectCmds = failedProjectCmds(pullStatus, projectCmds, models.ErroredPlanStatus)
+		if len(projectCmds) == 0 {
+			noFailedProjects(ctx, p.vcsClient, command.Plan)
+			if pullStatus != nil {
+				p.updateCommitStatus(ctx, *pullStatus, command.Plan)
+			} else if err := p.commitStatusUpdater.UpdateCombinedCount(ctx.Log, baseRepo, pull, models.SuccessCommitStatus, command.Plan, 0, 0); err != nil {
+				ctx.Log.Warn("unable to update commit status: %s", err)
+			}
+			return
+		}
+	}
+
 	if len(projectCmds) == 0 && p.SilenceNoProjects {
 		ctx.Log.Info("determined there was no project to run plan in")
 		if !p.silenceVCSStatusNoProjects {
@@ -256,8 +323,10 @@ func (p *PlanCommandRunner) run(ctx *command.Context, cmd *CommentCommand) {
 	projectCmds, policyCheckCmds := p.partitionProjectCmds(ctx, projectCmds)
 
 	// if the plan is generic, new plans will be generated based on changes
-	// discard previous plans that might not be relevant anymore
-	if !cmd.IsForSpecificProject() {
+	// discard previous plans that might not be relevant anymore. Only the
+	// failed projects are planned again with --failed so the other plans are
+	// kept.
+	if !cmd.IsForSpecificProject() && !cmd.Failed {
 		ctx.Log.Debug("deleting previous plans and locks")
 		p.deletePlans(ctx)
 		_, err := p.lockingLocker.UnlockByPull(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num)
@@ -266,7 +335,8 @@ func (p *PlanCommandRunner) run(ctx *command.Context, cmd *CommentCommand) {
 		}
 	}
 
-	result := runProjectCmdsWithCancellationTracker(ctx, projectCmds, p.cancellationTracker, p.parallelPoolSize, p.isParallelEnabled(projectCmds), p.prjCmdRunner.Plan)
+	reportProjects(ctx, projectCmds)
+	result := runProjectCmdsWithCancellationTracker(ctx, projectCmds, p.cancellationTracker, p.parallelPoolSize, p.isParallelEnabled(projectCmds), withProgress(ctx, p.prjCmdRunner.Plan))
 	ctx.CommandHasErrors = result.HasErrors()
 
 	if p.autoMerger.automergeEnabled(projectCmds) && result.HasErrors() {
@@ -381,6 +451,37 @@ func (p *PlanCommandRunner) deletePlans(ctx *command.Context) {
 	if err := p.pendingPlanFinder.DeletePlans(pullDir); err != nil {
 		ctx.Log.Err("deleting pending plans: %s", err)
 	}
+	if p.PlanJSONs != nil {
+		if err := p.PlanJSONs.DeletePull(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num); err != nil {
+			ctx.Log.Err("deleting plan jsons: %s", err)
+		}
+	}
+}
+
+// deletePlansExcept deletes the plans generated in this ctx except those of
+// projectCmds, which are kept to be reused if their projects didn't change.
+func (p *PlanCommandRunner) deletePlansExcept(ctx *command.Context, projectCmds []command.ProjectContext) {
+	pullDir, err := p.workingDir.GetPullDir(ctx.Pull.BaseRepo, ctx.Pull)
+	if err != nil {
+		ctx.Log.Err("getting pull dir: %s", err)
+		return
+	}
+	plans, err := p.pendingPlanFinder.Find(pullDir)
+	if err != nil {
+		ctx.Log.Err("finding pending plans: %s", err)
+		return
+	}
+	for _, plan := range plans {
+		if slices.ContainsFunc(projectCmds, func(cmd command.ProjectContext) bool {
+			return cmd.Workspace == plan.Workspace && filepath.Clean(cmd.RepoRelDir) == filepath.Clean(plan.RepoRelDir) && cmd.ProjectName == plan.ProjectName
+		}) {
+			continue
+		}
+		if err := p.workingDir.DeletePlan(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull, plan.Workspace, plan.RepoRelDir, plan.ProjectName); err != nil {
+			ctx.Log.Err("deleting pending plan: %s", err)
+		}
+		deletePlanJSON(ctx.Log, p.PlanJSONs, ctx.Pull, plan.RepoRelDir, plan.Workspace, plan.ProjectName)
+	}
 }
 
 func (p *PlanCommandRunner) partitionProjectCmds(
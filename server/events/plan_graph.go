@@ -0,0 +1,281 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// maxPlanGraphNodes is how many resources a plan graph can show, larger
+// graphs are unreadable so they're not rendered.
+const maxPlanGraphNodes = 50
+
+// instanceKeyRegex matches the instance keys of an address, ex. [0] or ["a"].
+var instanceKeyRegex = regexp.MustCompile(`\[[^\]]*\]`)
+
+// planGraphJSON is the subset of terraform show -json output that's graphed.
+type planGraphJSON struct {
+	ResourceChanges []struct {
+		ModuleAddress string `json:"module_address"`
+		Mode          string `json:"mode"`
+		Type          string `json:"type"`
+		Name          string `json:"name"`
+		Change        struct {
+			Actions []string `json:"actions"`
+		} `json:"change"`
+	} `json:"resource_changes"`
+	Configuration struct {
+		RootModule planGraphModule `json:"root_module"`
+	} `json:"configuration"`
+}
+
+type planGraphModule struct {
+	Resources []struct {
+		Address     string         `json:"address"`
+		Expressions map[string]any `json:"expressions"`
+		DependsOn   []string       `json:"depends_on"`
+	} `json:"resources"`
+	ModuleCalls map[string]struct {
+		Module planGraphModule `json:"module"`
+	} `json:"module_calls"`
+}
+
+// planGraphChanges counts the instances of a resource by change.
+type planGraphChanges struct {
+	create, update, replace, destroy int
+}
+
+// class returns the style of the resource's node, after its most
+// destructive change.
+func (c planGraphChanges) class() string {
+	switch {
+	case c.destroy > 0:
+		return "destroy"
+	case c.replace > 0:
+		return "replace"
+	case c.update > 0:
+		return "update"
+	case c.create > 0:
+		return "create"
+	default:
+		return "unchanged"
+	}
+}
+
+func (c planGraphChanges) String() string {
+	var counts []string
+	for _, count := range []struct {
+		n    int
+		verb string
+	}{{c.create, "add"}, {c.update, "change"}, {c.replace, "replace"}, {c.destroy, "destroy"}} {
+		if count.n > 0 {
+			counts = append(counts, fmt.Sprintf("%d to %s", count.n, count.verb))
+		}
+	}
+	return strings.Join(counts, ", ")
+}
+
+// planGraph returns a Mermaid flowchart of the resources the plan in show,
+// the output of terraform show -json, changes and of the resources that
+// depend on them, with arrows from resources to the resources depending on
+// them. It returns an empty string if the plan has no changes or too many
+// resources to graph.
+func planGraph(show string) (string, error) {
+	var plan planGraphJSON
+	if err := json.Unmarshal([]byte(show), &plan); err != nil {
+		return "", fmt.Errorf("parsing plan json: %w", err)
+	}
+
+	changes := make(map[string]*planGraphChanges)
+	for _, rc := range plan.ResourceChanges {
+		actions := rc.Change.Actions
+		address := configAddress(rc.ModuleAddress, rc.Mode, rc.Type, rc.Name)
+		c, ok := changes[address]
+		if !ok {
+			c = &planGraphChanges{}
+		}
+		switch {
+		case slices.Equal(actions, []string{"create"}):
+			c.create++
+		case slices.Equal(actions, []string{"update"}):
+			c.update++
+		case slices.Equal(actions, []string{"delete"}):
+			c.destroy++
+		case slices.Contains(actions, "create") && slices.Contains(actions, "delete"):
+			c.replace++
+		default:
+			continue
+		}
+		changes[address] = c
+	}
+	if len(changes) == 0 {
+		return "", nil
+	}
+
+	// dependencies are the resources each resource depends on.
+	dependencies := make(map[string][]string)
+	collectDependencies(plan.Configuration.RootModule, "", dependencies)
+
+	nodes := make(map[string]planGraphChanges)
+	for address, c := range changes {
+		nodes[address] = *c
+	}
+	type edge struct{ from, to string }
+	var edges []edge
+	for address, deps := range dependencies {
+		for _, dep := range deps {
+			if _, ok := changes[dep]; !ok {
+				continue
+			}
+			if _, ok := nodes[address]; !ok {
+				nodes[address] = planGraphChanges{}
+			}
+			edges = append(edges, edge{from: dep, to: address})
+		}
+	}
+	if len(nodes) > maxPlanGraphNodes {
+		return "", nil
+	}
+
+	addresses := make([]string, 0, len(nodes))
+	for address := range nodes {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	ids := make(map[string]string)
+	var graph strings.Builder
+	graph.WriteString("flowchart LR\n")
+	for i, address := range addresses {
+		ids[address] = fmt.Sprintf("r%d", i)
+		label := address
+		if counts := nodes[address].String(); counts != "" {
+			label += "<br/>" + counts
+		}
+		fmt.Fprintf(&graph, "  %s[\"%s\"]:::%s\n", ids[address], strings.ReplaceAll(label, `"`, "#quot;"), nodes[address].class())
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].from != edges[j].from {
+			return edges[i].from < edges[j].from
+		}
+		return edges[i].to < edges[j].to
+	})
+	for _, e := range edges {
+		fmt.Fprintf(&graph, "  %s --> %s\n", ids[e.from], ids[e.to])
+	}
+	graph.WriteString("  classDef create fill:#dafbe1,stroke:#1a7f37\n" +
+		"  classDef update fill:#fff8c5,stroke:#9a6700\n" +
+		"  classDef replace fill:#ffebe9,stroke:#cf222e,stroke-dasharray:5 5\n" +
+		"  classDef destroy fill:#ffebe9,stroke:#cf222e\n" +
+		"  classDef unchanged fill:#f6f8fa,stroke:#8c959f")
+	return graph.String(), nil
+}
+
+// configAddress returns the address of the resource's configuration, without
+// instance keys, ex. module.network.aws_subnet.private.
+func configAddress(moduleAddress string, mode string, resourceType string, name string) string {
+	address := fmt.Sprintf("%s.%s", resourceType, name)
+	if mode == "data" {
+		address = "data." + address
+	}
+	if moduleAddress != "" {
+		address = instanceKeyRegex.ReplaceAllString(moduleAddress, "") + "." + address
+	}
+	return address
+}
+
+// collectDependencies adds the resources each resource of module, whose
+// address is prefix, references or depends on to dependencies. Only
+// dependencies between resources of the same module are collected.
+func collectDependencies(module planGraphModule, prefix string, dependencies map[string][]string) {
+	qualify := func(address string) string {
+		if prefix == "" {
+			return address
+		}
+		return prefix + "." + address
+	}
+	for _, resource := range module.Resources {
+		address := qualify(resource.Address)
+		refs := append(collectReferences(resource.Expressions), resource.DependsOn...)
+		for _, ref := range refs {
+			dep, ok := referencedResource(ref)
+			if !ok {
+				continue
+			}
+			dep = qualify(dep)
+			if dep != address && !slices.Contains(dependencies[address], dep) {
+				dependencies[address] = append(dependencies[address], dep)
+			}
+		}
+	}
+	for name, call := range module.ModuleCalls {
+		collectDependencies(call.Module, qualify("module."+name), dependencies)
+	}
+}
+
+// collectReferences returns the references in the expressions of a
+// resource's configuration, including in nested blocks.
+func collectReferences(expressions any) []string {
+	var refs []string
+	switch e := expressions.(type) {
+	case map[string]any:
+		for key, value := range e {
+			if key != "references" {
+				refs = append(refs, collectReferences(value)...)
+				continue
+			}
+			values, _ := value.([]any)
+			for _, ref := range values {
+				if ref, ok := ref.(string); ok {
+					refs = append(refs, ref)
+				}
+			}
+		}
+	case []any:
+		for _, value := range e {
+			refs = append(refs, collectReferences(value)...)
+		}
+	}
+	return refs
+}
+
+// referencedResource returns the address of the resource ref, ex.
+// aws_vpc.main.id, refers to. It returns false if ref doesn't refer to a
+// resource, ex. var.region.
+func referencedResource(ref string) (string, bool) {
+	parts := strings.Split(instanceKeyRegex.ReplaceAllString(ref, ""), ".")
+	switch parts[0] {
+	case "var", "local", "module", "each", "count", "path", "terraform", "self":
+		return "", false
+	case "data":
+		if len(parts) < 3 {
+			return "", false
+		}
+		return strings.Join(parts[:3], "."), true
+	}
+	if len(parts) < 2 {
+		return "", false
+	}
+	return strings.Join(parts[:2], "."), true
+}
+
+// planGraphFor returns the graph of the plan in show for the project
+// described by ctx, or an empty string if it can't be graphed.
+func planGraphFor(ctx command.ProjectContext, show string) string {
+	if show == "" {
+		return ""
+	}
+	graph, err := planGraph(show)
+	if err != nil {
+		ctx.Log.Warn("unable to graph plan: %s", err)
+		return ""
+	}
+	return graph
+}
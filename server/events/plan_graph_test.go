@@ -0,0 +1,64 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"testing"
+
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestPlanGraph(t *testing.T) {
+	show := `{
+  "resource_changes": [
+    {"mode": "managed", "type": "aws_vpc", "name": "main", "change": {"actions": ["update"]}},
+    {"mode": "managed", "type": "aws_subnet", "name": "private", "change": {"actions": ["delete", "create"]}},
+    {"mode": "managed", "type": "aws_subnet", "name": "private", "change": {"actions": ["create"]}},
+    {"mode": "managed", "type": "aws_instance", "name": "web", "change": {"actions": ["no-op"]}},
+    {"mode": "managed", "type": "aws_eip", "name": "web", "change": {"actions": ["no-op"]}},
+    {"module_address": "module.dns[0]", "mode": "managed", "type": "aws_route53_record", "name": "web", "change": {"actions": ["delete"]}},
+    {"mode": "data", "type": "aws_ami", "name": "ubuntu", "change": {"actions": ["read"]}}
+  ],
+  "configuration": {
+    "root_module": {
+      "resources": [
+        {"address": "aws_vpc.main", "expressions": {"cidr_block": {"references": ["var.cidr"]}}},
+        {"address": "aws_subnet.private", "expressions": {"vpc_id": {"references": ["aws_vpc.main.id", "aws_vpc.main"]}}},
+        {"address": "aws_instance.web", "expressions": {
+          "ami": {"references": ["data.aws_ami.ubuntu.id", "data.aws_ami.ubuntu"]},
+          "network_interface": [{"subnet_id": {"references": ["aws_subnet.private[0].id"]}}]
+        }},
+        {"address": "aws_eip.web", "expressions": {"instance": {"references": ["aws_instance.web.id"]}}},
+        {"address": "data.aws_ami.ubuntu", "expressions": {}}
+      ],
+      "module_calls": {
+        "dns": {"module": {"resources": [{"address": "aws_route53_record.web", "expressions": {}}]}}
+      }
+    }
+  }
+}`
+	graph, err := planGraph(show)
+	Ok(t, err)
+	Equals(t, `flowchart LR
+  r0["aws_instance.web"]:::unchanged
+  r1["aws_subnet.private<br/>1 to add, 1 to replace"]:::replace
+  r2["aws_vpc.main<br/>1 to change"]:::update
+  r3["module.dns.aws_route53_record.web<br/>1 to destroy"]:::destroy
+  r1 --> r0
+  r2 --> r1
+  classDef create fill:#dafbe1,stroke:#1a7f37
+  classDef update fill:#fff8c5,stroke:#9a6700
+  classDef replace fill:#ffebe9,stroke:#cf222e,stroke-dasharray:5 5
+  classDef destroy fill:#ffebe9,stroke:#cf222e
+  classDef unchanged fill:#f6f8fa,stroke:#8c959f`, graph)
+}
+
+func TestPlanGraph_NoChanges(t *testing.T) {
+	graph, err := planGraph(`{"resource_changes": [{"mode": "managed", "type": "null_resource", "name": "a", "change": {"actions": ["no-op"]}}]}`)
+	Ok(t, err)
+	Equals(t, "", graph)
+
+	_, err = planGraph("Version: 0.11.0 is unsupported for this step.")
+	ErrContains(t, "parsing plan json", err)
+}
@@ -0,0 +1,168 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// PlanJSON is the JSON representation of a project's plan, from terraform
+// show -json.
+type PlanJSON struct {
+	Repository  string
+	Pull        int
+	HeadCommit  string
+	ProjectName string
+	RepoRelDir  string
+	Workspace   string
+	CreatedAt   time.Time
+	Plan        json.RawMessage
+}
+
+// PlanJSONStore stores the JSON representation of the latest plan of each
+// project on disk so external tools can fetch it through the API without
+// parsing comments. A plan is deleted with its planfile, ex. when it's
+// discarded or its lock is deleted, and the plans of a pull request once it's
+// closed.
+type PlanJSONStore struct {
+	// Dir is the directory the plans are stored in.
+	Dir string
+	// Encryptor encrypts the plans at rest like planfiles since they contain
+	// the same values. Nil if planfiles aren't encrypted.
+	Encryptor *runtime.PlanfileEncryptor
+}
+
+// Save stores show, the output of terraform show -json on the plan of the
+// project described by ctx, replacing its previous plan.
+func (s *PlanJSONStore) Save(ctx command.ProjectContext, show string) error {
+	if !json.Valid([]byte(show)) {
+		return errors.New("terraform show output isn't valid json")
+	}
+	contents, err := json.Marshal(PlanJSON{
+		Repository:  ctx.Pull.BaseRepo.FullName,
+		Pull:        ctx.Pull.Num,
+		HeadCommit:  ctx.Pull.HeadCommit,
+		ProjectName: ctx.ProjectName,
+		RepoRelDir:  ctx.RepoRelDir,
+		Workspace:   ctx.Workspace,
+		CreatedAt:   time.Now(),
+		Plan:        json.RawMessage(show),
+	})
+	if err != nil {
+		return err
+	}
+	pullDir := s.pullDir(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num)
+	if err := os.MkdirAll(pullDir, 0700); err != nil {
+		return fmt.Errorf("creating plan json dir: %w", err)
+	}
+	// The plan is written to a temporary file first so it's never read
+	// partially written.
+	path := s.planPath(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, ctx.RepoRelDir, ctx.Workspace, ctx.ProjectName)
+	if s.Encryptor != nil {
+		if contents, err = s.Encryptor.EncryptFile(filepath.Base(path), contents); err != nil {
+			return fmt.Errorf("encrypting plan json: %w", err)
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, contents, 0600); err != nil {
+		return fmt.Errorf("writing plan json: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// List returns the plans of the pull request sorted by directory, workspace
+// and project name.
+func (s *PlanJSONStore) List(repoFullName string, pullNum int) ([]PlanJSON, error) {
+	paths, err := filepath.Glob(filepath.Join(s.pullDir(repoFullName, pullNum), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	plans := []PlanJSON{}
+	for _, path := range paths {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading plan json: %w", err)
+		}
+		if s.Encryptor != nil {
+			if contents, err = s.Encryptor.DecryptFile(filepath.Base(path), contents); err != nil {
+				return nil, fmt.Errorf("decrypting plan json %s: %w", path, err)
+			}
+		}
+		var plan PlanJSON
+		if err := json.Unmarshal(contents, &plan); err != nil {
+			return nil, fmt.Errorf("parsing plan json %s: %w", path, err)
+		}
+		plans = append(plans, plan)
+	}
+	sort.Slice(plans, func(i, j int) bool {
+		if plans[i].RepoRelDir != plans[j].RepoRelDir {
+			return plans[i].RepoRelDir < plans[j].RepoRelDir
+		}
+		if plans[i].Workspace != plans[j].Workspace {
+			return plans[i].Workspace < plans[j].Workspace
+		}
+		return plans[i].ProjectName < plans[j].ProjectName
+	})
+	return plans, nil
+}
+
+// Delete deletes the plan of the project of the pull request in repoRelDir
+// and workspace. It's a no-op if there's none.
+func (s *PlanJSONStore) Delete(repoFullName string, pullNum int, repoRelDir string, workspace string, projectName string) error {
+	err := os.Remove(s.planPath(repoFullName, pullNum, repoRelDir, workspace, projectName))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// DeletePull deletes the plans of the pull request.
+func (s *PlanJSONStore) DeletePull(repoFullName string, pullNum int) error {
+	return os.RemoveAll(s.pullDir(repoFullName, pullNum))
+}
+
+// pullDir returns the directory the plans of the pull request are stored in.
+// Its name is hashed so repo names can't escape Dir.
+func (s *PlanJSONStore) pullDir(repoFullName string, pullNum int) string {
+	return filepath.Join(s.Dir, storeKey(repoFullName, fmt.Sprint(pullNum)))
+}
+
+// deletePlanJSON deletes the stored JSON plan of the project when its plan is
+// deleted. Failing to delete it is logged since the plan itself was deleted.
+func deletePlanJSON(logger logging.SimpleLogging, store *PlanJSONStore, pull models.PullRequest, repoRelDir string, workspace string, projectName string) {
+	if store == nil {
+		return
+	}
+	if err := store.Delete(pull.BaseRepo.FullName, pull.Num, repoRelDir, workspace, projectName); err != nil {
+		logger.Warn("unable to delete the plan json of dir %s workspace %s: %s", repoRelDir, workspace, err)
+	}
+}
+
+func (s *PlanJSONStore) planPath(repoFullName string, pullNum int, repoRelDir string, workspace string, projectName string) string {
+	return filepath.Join(s.pullDir(repoFullName, pullNum), storeKey(filepath.Clean(repoRelDir), workspace, projectName)+".json")
+}
+
+func storeKey(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		// The separator can't be in any part, so different parts can't
+		// have the same key.
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
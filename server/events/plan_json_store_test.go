@@ -0,0 +1,80 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestPlanJSONStore(t *testing.T) {
+	store := &events.PlanJSONStore{Dir: t.TempDir()}
+	pull := models.PullRequest{Num: 1, HeadCommit: "abc123", BaseRepo: models.Repo{FullName: "owner/repo"}}
+	ctx := func(dir string) command.ProjectContext {
+		return command.ProjectContext{Pull: pull, RepoRelDir: dir, Workspace: "default"}
+	}
+
+	plans, err := store.List("owner/repo", 1)
+	Ok(t, err)
+	Equals(t, []events.PlanJSON{}, plans)
+
+	Ok(t, store.Save(ctx("b"), `{"resource_changes": []}`))
+	Ok(t, store.Save(ctx("a"), `{"old": true}`))
+	Ok(t, store.Save(ctx("a"), `{"resource_changes": [{"address": "null_resource.a"}]}`))
+	ErrEquals(t, "terraform show output isn't valid json", store.Save(ctx("c"), "Version: 0.11.0 is unsupported for this step."))
+
+	plans, err = store.List("owner/repo", 1)
+	Ok(t, err)
+	Equals(t, 2, len(plans))
+	Equals(t, "a", plans[0].RepoRelDir)
+	Equals(t, "abc123", plans[0].HeadCommit)
+	Equals(t, `{"resource_changes":[{"address":"null_resource.a"}]}`, string(plans[0].Plan))
+	Equals(t, "b", plans[1].RepoRelDir)
+
+	plans, err = store.List("owner/repo", 2)
+	Ok(t, err)
+	Equals(t, 0, len(plans))
+
+	// The plan of a project is deleted with its planfile.
+	Ok(t, store.Delete("owner/repo", 1, "./b", "default", ""))
+	Ok(t, store.Delete("owner/repo", 1, "b", "default", ""))
+	plans, err = store.List("owner/repo", 1)
+	Ok(t, err)
+	Equals(t, 1, len(plans))
+	Equals(t, "a", plans[0].RepoRelDir)
+
+	Ok(t, store.DeletePull("owner/repo", 1))
+	plans, err = store.List("owner/repo", 1)
+	Ok(t, err)
+	Equals(t, 0, len(plans))
+}
+
+func TestPlanJSONStore_Encrypted(t *testing.T) {
+	encryptor, err := runtime.NewPlanfileEncryptor("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	Ok(t, err)
+	store := &events.PlanJSONStore{Dir: t.TempDir(), Encryptor: encryptor}
+	pull := models.PullRequest{Num: 1, BaseRepo: models.Repo{FullName: "owner/repo"}}
+	show := `{"variables": {"password": {"value": "hunter2"}}}`
+	Ok(t, store.Save(command.ProjectContext{Pull: pull, RepoRelDir: ".", Workspace: "default"}, show))
+
+	// The plan isn't readable at rest.
+	paths, err := filepath.Glob(filepath.Join(store.Dir, "*", "*.json"))
+	Ok(t, err)
+	Equals(t, 1, len(paths))
+	contents, err := os.ReadFile(paths[0])
+	Ok(t, err)
+	Assert(t, !strings.Contains(string(contents), "hunter2"), "exp the plan to be encrypted")
+
+	plans, err := store.List("owner/repo", 1)
+	Ok(t, err)
+	Equals(t, `{"variables":{"password":{"value":"hunter2"}}}`, string(plans[0].Plan))
+}
@@ -0,0 +1,137 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// terraformLockFile is the dependency lock file terraform init writes.
+const terraformLockFile = ".terraform.lock.hcl"
+
+// planContentKey identifies the content the plan of the project described by
+// ctx is generated from: the git trees of the project's directory and of the
+// local modules it calls, its lock file, which may not be committed, its var
+// files, and how it's planned. It's the same as long as commits don't touch
+// the project's files, ex. after a rebase.
+func planContentKey(ctx command.ProjectContext, repoDir string, absPath string) (string, error) {
+	relDir := filepath.ToSlash(filepath.Clean(ctx.RepoRelDir))
+	modules := moduleInfo{}
+	// Modules that can't be parsed fail the plan anyway, they only leave out
+	// the modules they call.
+	modules.load(os.DirFS(repoDir), relDir) // nolint: errcheck
+	dirs := make([]string, 0, len(modules))
+	for dir := range modules {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	hash := sha256.New()
+	for _, dir := range dirs {
+		tree, err := gitTree(repoDir, dir)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(hash, "tree %s %s\n", dir, tree)
+	}
+	lockFile, err := os.ReadFile(filepath.Join(absPath, terraformLockFile)) // nolint: gosec
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("reading %s: %w", terraformLockFile, err)
+	}
+	fmt.Fprintf(hash, "lock %x\n", sha256.Sum256(lockFile))
+	for _, varFile := range planVarFiles(ctx) {
+		path := varFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(absPath, path)
+		}
+		contents, err := os.ReadFile(path) // nolint: gosec
+		if err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("reading var file %s: %w", varFile, err)
+		}
+		fmt.Fprintf(hash, "var-file %q %x\n", varFile, sha256.Sum256(contents))
+	}
+	if ctx.TerraformDistribution != nil {
+		fmt.Fprintf(hash, "distribution %s\n", *ctx.TerraformDistribution)
+	}
+	if ctx.TerraformVersion != nil {
+		fmt.Fprintf(hash, "version %s\n", ctx.TerraformVersion)
+	}
+	steps, err := json.Marshal(ctx.Steps)
+	if err != nil {
+		return "", fmt.Errorf("encoding steps: %w", err)
+	}
+	fmt.Fprintf(hash, "steps %s\nargs %q\n", steps, ctx.EscapedCommentArgs)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// gitTree returns the hash of the git tree of relDir at HEAD.
+func gitTree(repoDir string, relDir string) (string, error) {
+	if relDir == "." {
+		relDir = ""
+	}
+	revParseCmd := exec.Command("git", "rev-parse", "HEAD:"+relDir) // nolint: gosec
+	revParseCmd.Dir = repoDir
+	tree, err := revParseCmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("running 'git rev-parse HEAD:%s': %s: %w", relDir, string(tree), err)
+	}
+	return strings.TrimSpace(string(tree)), nil
+}
+
+// planVarFiles returns the var files the project described by ctx is planned
+// with, from the extra args of its steps and the comment's args.
+func planVarFiles(ctx command.ProjectContext) []string {
+	var flags []string
+	for _, step := range ctx.Steps {
+		flags = append(flags, step.ExtraArgs...)
+	}
+	for _, arg := range ctx.EscapedCommentArgs {
+		// Every character of the comment's args is escaped with a backslash.
+		var unescaped strings.Builder
+		for i := 1; i < len(arg); i += 2 {
+			unescaped.WriteByte(arg[i])
+		}
+		flags = append(flags, unescaped.String())
+	}
+	return varFilePaths(flags)
+}
+
+// reusablePlan returns the existing plan of the project described by ctx if
+// it was generated from the project's current content, or nil if it must be
+// planned again.
+func reusablePlan(ctx command.ProjectContext, encryptor *runtime.PlanfileEncryptor, repoDir string, absPath string) *models.PlanSuccess {
+	if _, err := os.Stat(filepath.Join(absPath, runtime.GetPlanFilename(ctx.Workspace, ctx.ProjectName))); err != nil {
+		return nil
+	}
+	saved, err := readPlanOutput(ctx, encryptor, absPath)
+	if err != nil || saved.ContentKey == "" {
+		return nil
+	}
+	key, err := planContentKey(ctx, repoDir, absPath)
+	if err != nil {
+		ctx.Log.Warn("unable to identify the content of the project, planning again: %s", err)
+		return nil
+	}
+	if key != saved.ContentKey {
+		return nil
+	}
+	return &models.PlanSuccess{
+		TerraformOutput: saved.TerraformOutput,
+		PlanJSON:        saved.PlanJSON,
+		PlanGraph:       saved.PlanGraph,
+		Cost:            costEstimateFor(ctx, absPath),
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestReusablePlan(t *testing.T) {
+	repoDir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		out, err := cmd.CombinedOutput()
+		Assert(t, err == nil, "running git %v: %s", args, out)
+	}
+	commitFile := func(name string, contents string) {
+		t.Helper()
+		Ok(t, os.MkdirAll(filepath.Dir(filepath.Join(repoDir, name)), 0700))
+		Ok(t, os.WriteFile(filepath.Join(repoDir, name), []byte(contents), 0600))
+		git("add", name)
+		git("commit", "-m", "update "+name)
+	}
+	git("init", "--initial-branch=main")
+	git("config", "--local", "user.email", "atlantisbot@runatlantis.io")
+	git("config", "--local", "user.name", "atlantisbot")
+	git("config", "--local", "commit.gpgsign", "false")
+	commitFile("project/main.tf", "resource \"null_resource\" \"a\" {}\nmodule \"m\" {\n  source = \"../modules/m\"\n}\n")
+	commitFile("modules/m/main.tf", `resource "null_resource" "m" {}`)
+	commitFile("common.tfvars", `a = 1`)
+	commitFile("other/main.tf", `resource "null_resource" "b" {}`)
+
+	absPath := filepath.Join(repoDir, "project")
+	ctx := command.ProjectContext{
+		Log:        logging.NewNoopLogger(t),
+		RepoRelDir: "project",
+		Workspace:  "default",
+		Steps:      []valid.Step{{StepName: "init"}, {StepName: "plan", ExtraArgs: []string{"-var-file=../common.tfvars"}}},
+	}
+	Assert(t, reusablePlan(ctx, nil, repoDir, absPath) == nil, "exp no plan to reuse")
+
+	Ok(t, os.WriteFile(filepath.Join(absPath, "default.tfplan"), []byte("plan"), 0600))
+	Ok(t, os.WriteFile(filepath.Join(absPath, terraformLockFile), []byte("lock"), 0600))
+	key, err := planContentKey(ctx, repoDir, absPath)
+	Ok(t, err)
+	savePlanOutput(ctx, nil, absPath, savedPlanOutput{TerraformOutput: "Plan: 1 to add", ContentKey: key})
+
+	t.Log("commits that don't touch the project reuse its plan")
+	commitFile("other/main.tf", `resource "null_resource" "c" {}`)
+	reused := reusablePlan(ctx, nil, repoDir, absPath)
+	Assert(t, reused != nil, "exp plan to be reused")
+	Equals(t, "Plan: 1 to add", reused.TerraformOutput)
+
+	t.Log("the project is planned again with other arguments")
+	withArgs := ctx
+	withArgs.EscapedCommentArgs = []string{`\-\v\a\r\=\a`}
+	Assert(t, reusablePlan(withArgs, nil, repoDir, absPath) == nil, "exp plan not to be reused with other arguments")
+
+	t.Log("the project is planned again if its lock file changed")
+	Ok(t, os.WriteFile(filepath.Join(absPath, terraformLockFile), []byte("upgraded lock"), 0600))
+	Assert(t, reusablePlan(ctx, nil, repoDir, absPath) == nil, "exp plan not to be reused after the lock file changed")
+	Ok(t, os.WriteFile(filepath.Join(absPath, terraformLockFile), []byte("lock"), 0600))
+	Assert(t, reusablePlan(ctx, nil, repoDir, absPath) != nil, "exp plan to be reused")
+
+	t.Log("the project is planned again if a module it calls changed")
+	commitFile("modules/m/main.tf", `resource "null_resource" "n" {}`)
+	Assert(t, reusablePlan(ctx, nil, repoDir, absPath) == nil, "exp plan not to be reused after the module changed")
+	key, err = planContentKey(ctx, repoDir, absPath)
+	Ok(t, err)
+	savePlanOutput(ctx, nil, absPath, savedPlanOutput{TerraformOutput: "Plan: 1 to add", ContentKey: key})
+
+	t.Log("the project is planned again if its var files changed")
+	Ok(t, os.WriteFile(filepath.Join(repoDir, "common.tfvars"), []byte(`a = 2`), 0600))
+	Assert(t, reusablePlan(ctx, nil, repoDir, absPath) == nil, "exp plan not to be reused after the var file changed")
+	withVarFile := ctx
+	withVarFile.Steps = []valid.Step{{StepName: "init"}, {StepName: "plan"}}
+	withVarFile.EscapedCommentArgs = escapeArgs([]string{"-var-file", "../other.tfvars"})
+	Equals(t, []string{"../other.tfvars"}, planVarFiles(withVarFile))
+
+	t.Log("the project is planned again if its files changed")
+	commitFile("project/main.tf", `resource "null_resource" "d" {}`)
+	Assert(t, reusablePlan(ctx, nil, repoDir, absPath) == nil, "exp plan not to be reused after the project changed")
+}
+
+func TestPlanContentKey_RootDir(t *testing.T) {
+	repoDir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--initial-branch=main"},
+		{"-c", "user.email=atlantisbot@runatlantis.io", "-c", "user.name=atlantisbot", "-c", "commit.gpgsign=false", "commit", "--allow-empty", "-m", "initial commit"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		out, err := cmd.CombinedOutput()
+		Assert(t, err == nil, "running git %v: %s", args, out)
+	}
+
+	key, err := planContentKey(command.ProjectContext{RepoRelDir: "."}, repoDir, repoDir)
+	Ok(t, err)
+	Assert(t, key != "", "exp key")
+}
+
+func TestSavePlanOutput_Encrypted(t *testing.T) {
+	encryptor, err := runtime.NewPlanfileEncryptor("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	Ok(t, err)
+	absPath := t.TempDir()
+	ctx := command.ProjectContext{Log: logging.NewNoopLogger(t), Workspace: "default"}
+
+	savePlanOutput(ctx, encryptor, absPath, savedPlanOutput{TerraformOutput: "password = hunter2"})
+	contents, err := os.ReadFile(filepath.Join(absPath, ctx.GetPlanOutputFileName()))
+	Ok(t, err)
+	Assert(t, !strings.Contains(string(contents), "hunter2"), "exp the saved output to be encrypted")
+
+	saved, err := readPlanOutput(ctx, encryptor, absPath)
+	Ok(t, err)
+	Equals(t, "password = hunter2", saved.TerraformOutput)
+}
@@ -0,0 +1,485 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/db"
+	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/logging"
+	tally "github.com/uber-go/tally/v4"
+)
+
+// PreviewProjectCommandRunner plans and applies the preview environments.
+type PreviewProjectCommandRunner interface {
+	ProjectPlanCommandRunner
+	ProjectPolicyCheckCommandRunner
+	ProjectApplyCommandRunner
+}
+
+// PreviewCommandRunner deploys the preview environments of pull requests on
+// atlantis preview, and on autoplan for the preview projects deployed
+// automatically: each preview project the pull request modifies is applied
+// in a workspace of the pull request's own and its outputs are commented.
+// The environments are destroyed by PreviewExpiryJob once their pull request
+// is closed or they expire, or on atlantis preview --destroy.
+type PreviewCommandRunner struct {
+	VCSClient             vcs.Client
+	ProjectCommandBuilder ProjectPlanCommandBuilder
+	ProjectCommandRunner  PreviewProjectCommandRunner
+	// OutputStepRunner outputs the Terraform outputs of an environment as
+	// JSON.
+	OutputStepRunner StepRunner
+	WorkingDir       WorkingDir
+	Locker           locking.Locker
+	Database         db.Database
+	// MaxEnvironments is how many preview environments may exist at once,
+	// across all repos. Zero if there's no limit.
+	MaxEnvironments int
+	// Disabled is true if preview environments aren't enabled on the server,
+	// atlantis preview then only comments so.
+	Disabled bool
+	// now is overridden in tests.
+	now func() time.Time
+}
+
+func (p *PreviewCommandRunner) Run(ctx *command.Context, cmd *CommentCommand) {
+	if p.Disabled {
+		p.comment(ctx, "Preview environments aren't enabled on this Atlantis server, see `--enable-preview-environments`.")
+		return
+	}
+	if cmd.Destroy {
+		p.destroyPull(ctx, cmd)
+		return
+	}
+	p.deploy(ctx, cmd, false)
+}
+
+// AutoDeploy deploys the environments of the preview projects the pull
+// request of ctx modifies that are deployed automatically. It's run when the
+// pull request is autoplanned.
+func (p *PreviewCommandRunner) AutoDeploy(ctx *command.Context) {
+	p.deploy(ctx, &CommentCommand{Name: command.Preview}, true)
+}
+
+// ClosePull marks the environments of pull as closed so that they're
+// destroyed by PreviewExpiryJob.
+func (p *PreviewCommandRunner) ClosePull(pull models.PullRequest) error {
+	envs, err := p.pullEnvironments(pull)
+	if err != nil {
+		return err
+	}
+	for _, env := range envs {
+		env.Closed = true
+		if err := p.Database.SavePreviewEnvironment(env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DestroyExpired destroys the environments of closed pull requests and the
+// expired environments. The environments that fail to be destroyed are
+// retried the next time.
+func (p *PreviewCommandRunner) DestroyExpired(logger logging.SimpleLogging, scope tally.Scope) {
+	envs, err := p.Database.ListPreviewEnvironments()
+	if err != nil {
+		logger.Err("listing preview environments: %s", err)
+		return
+	}
+	for _, env := range envs {
+		if !env.Expired(p.clock()) {
+			continue
+		}
+		ctx := &command.Context{
+			HeadRepo: env.HeadRepo,
+			Pull:     env.Pull,
+			Scope:    scope,
+			User:     env.User,
+			Log:      logger.With("repo", env.Pull.BaseRepo.FullName, "pull", env.Pull.Num),
+			Trigger:  command.AutoTrigger,
+		}
+		previousErr := env.Error
+		section, ok := p.destroy(ctx, env)
+		if !ok && section == previousErr {
+			// Only the first time an environment fails to be destroyed
+			// with an error is commented.
+			continue
+		}
+		reason := "the pull request was closed"
+		if !env.Closed {
+			reason = fmt.Sprintf("it expired %s", env.ExpiresAt.UTC().Format(time.RFC1123))
+		}
+		p.comment(ctx, fmt.Sprintf("### Preview Environments\n\nDestroying the preview environments since %s.\n\n%s", reason, section))
+	}
+}
+
+func (p *PreviewCommandRunner) deploy(ctx *command.Context, cmd *CommentCommand, auto bool) {
+	projectCmds, err := p.ProjectCommandBuilder.BuildPlanCommands(ctx, cmd)
+	if err != nil {
+		p.comment(ctx, fmt.Sprintf("**Preview Error**\n```\n%s\n```", err))
+		return
+	}
+	if auto {
+		projectCmds = slices.DeleteFunc(projectCmds, func(projCtx command.ProjectContext) bool {
+			return !projCtx.Preview.Auto
+		})
+	}
+	if len(projectCmds) == 0 {
+		if !auto {
+			p.comment(ctx, "No preview projects to deploy. Preview projects have a `preview` key in the repo config, run `atlantis preview -p PROJECT` to deploy one this pull request doesn't modify.")
+		}
+		return
+	}
+
+	var sections []string
+	for _, projCtx := range projectCmds {
+		sections = append(sections, p.deployProject(ctx, projCtx))
+	}
+	p.comment(ctx, "### Preview Environments\n\n"+strings.Join(sections, "\n\n"))
+}
+
+// deployProject deploys the environment of projCtx and returns the section of
+// the comment about it.
+func (p *PreviewCommandRunner) deployProject(ctx *command.Context, projCtx command.ProjectContext) string {
+	now := p.clock()
+	env := models.PreviewEnvironment{
+		Pull:        ctx.Pull,
+		HeadRepo:    ctx.HeadRepo,
+		User:        ctx.User,
+		ProjectName: projCtx.ProjectName,
+		RepoRelDir:  projCtx.RepoRelDir,
+		Workspace:   projCtx.Preview.Workspace(ctx.Pull.Num),
+		DeployedAt:  now,
+	}
+	if projCtx.Preview.TTL > 0 {
+		env.ExpiresAt = now.Add(projCtx.Preview.TTL)
+	}
+	heading := previewHeading(env)
+
+	quotaFailure, err := p.checkQuota(env, projCtx.Preview.MaxEnvironments)
+	if err != nil {
+		return fmt.Sprintf("%s**Preview Error**\n```\n%s\n```", heading, err)
+	}
+	if quotaFailure != "" {
+		return fmt.Sprintf("%s**Preview Failed**: %s", heading, quotaFailure)
+	}
+	// The environment is recorded before it's applied so that it's destroyed
+	// even if the apply fails halfway through.
+	if err := p.Database.SavePreviewEnvironment(env); err != nil {
+		return fmt.Sprintf("%s**Preview Error**\n```\n%s\n```", heading, err)
+	}
+
+	projCtx.Workspace = env.Workspace
+	if out := p.apply(projCtx, false); out.Error != nil || out.Failure != "" {
+		return heading + previewFailure(out)
+	}
+	env.Outputs = p.outputs(projCtx)
+	if err := p.Database.SavePreviewEnvironment(env); err != nil {
+		ctx.Log.Err("unable to save the outputs of preview environment %s: %s", env.ID(), err)
+	}
+
+	section := heading + "Deployed"
+	if !env.ExpiresAt.IsZero() {
+		section += fmt.Sprintf(", it will be destroyed %s if the pull request isn't closed before", env.ExpiresAt.UTC().Format(time.RFC1123))
+	}
+	return section + ".\n\n" + previewOutputsTable(env.Outputs)
+}
+
+// destroyPull destroys the environments of the pull request of ctx that cmd
+// selects.
+func (p *PreviewCommandRunner) destroyPull(ctx *command.Context, cmd *CommentCommand) {
+	envs, err := p.pullEnvironments(ctx.Pull)
+	if err != nil {
+		p.comment(ctx, fmt.Sprintf("**Preview Error**\n```\n%s\n```", err))
+		return
+	}
+	envs = slices.DeleteFunc(envs, func(env models.PreviewEnvironment) bool {
+		return (cmd.ProjectName != "" && env.ProjectName != cmd.ProjectName) || (cmd.RepoRelDir != "" && env.RepoRelDir != cmd.RepoRelDir)
+	})
+	if len(envs) == 0 {
+		p.comment(ctx, "No preview environments to destroy.")
+		return
+	}
+
+	var sections []string
+	for _, env := range envs {
+		section, _ := p.destroy(ctx, env)
+		sections = append(sections, section)
+	}
+	p.comment(ctx, "### Preview Environments\n\n"+strings.Join(sections, "\n\n"))
+}
+
+// destroy destroys env and deletes it, and returns the section of the comment
+// about it and whether it was destroyed. If it fails to be destroyed the
+// error is recorded.
+func (p *PreviewCommandRunner) destroy(ctx *command.Context, env models.PreviewEnvironment) (string, bool) {
+	heading := previewHeading(env)
+	projCtx, err := p.buildEnvironmentCommand(ctx, env)
+	if err != nil && env.Closed {
+		// The pull request's head may not be available anymore once it's
+		// closed, ex. its branch was deleted after it was merged, in which
+		// case its base is the best bet.
+		ctx.Log.Warn("unable to check out pull request %d to destroy its preview environment, checking out its base branch: %s", env.Pull.Num, err)
+		fallback := *ctx
+		fallback.HeadRepo = ctx.Pull.BaseRepo
+		fallback.Pull.HeadBranch = ctx.Pull.BaseBranch
+		projCtx, err = p.buildEnvironmentCommand(&fallback, env)
+	}
+	var out command.ProjectCommandOutput
+	if err != nil {
+		out.Error = err
+	} else {
+		out = p.apply(projCtx, true)
+	}
+	if out.Error != nil || out.Failure != "" {
+		env.Error = heading + previewFailure(out)
+		if err := p.Database.SavePreviewEnvironment(env); err != nil {
+			ctx.Log.Err("unable to record the error of preview environment %s: %s", env.ID(), err)
+		}
+		return env.Error, false
+	}
+
+	if err := p.Database.DeletePreviewEnvironment(env.ID()); err != nil {
+		ctx.Log.Err("unable to delete preview environment %s: %s", env.ID(), err)
+	}
+	p.cleanUp(ctx.Log, env)
+	return heading + "Destroyed.", true
+}
+
+// buildEnvironmentCommand builds the context of the project of env, in the
+// environment's workspace.
+func (p *PreviewCommandRunner) buildEnvironmentCommand(ctx *command.Context, env models.PreviewEnvironment) (command.ProjectContext, error) {
+	cmd := &CommentCommand{Name: command.Preview, ProjectName: env.ProjectName}
+	if env.ProjectName == "" {
+		cmd.RepoRelDir = env.RepoRelDir
+	}
+	projectCmds, err := p.ProjectCommandBuilder.BuildPlanCommands(ctx, cmd)
+	if err != nil {
+		return command.ProjectContext{}, err
+	}
+	for _, projCtx := range projectCmds {
+		if projCtx.ProjectName == env.ProjectName && projCtx.RepoRelDir == env.RepoRelDir {
+			projCtx.Workspace = env.Workspace
+			return projCtx, nil
+		}
+	}
+	return command.ProjectContext{}, fmt.Errorf("no preview project at dir '%s' named '%s' is defined in the repo config anymore, its environment must be destroyed manually", env.RepoRelDir, env.ProjectName)
+}
+
+// cleanUp releases the lock of env and deletes its working dir once it's
+// destroyed. For closed pull requests, whose locks and working dirs were
+// already cleaned up, everything destroying env created is.
+func (p *PreviewCommandRunner) cleanUp(logger logging.SimpleLogging, env models.PreviewEnvironment) {
+	if env.Closed {
+		if err := p.WorkingDir.Delete(logger, env.Pull.BaseRepo, env.Pull); err != nil {
+			logger.Warn("unable to delete the working dir of pull request %d: %s", env.Pull.Num, err)
+		}
+		if _, err := p.Locker.UnlockByPull(env.Pull.BaseRepo.FullName, env.Pull.Num); err != nil {
+			logger.Warn("unable to release the locks of pull request %d: %s", env.Pull.Num, err)
+		}
+		return
+	}
+	project := models.NewProject(env.Pull.BaseRepo.FullName, env.RepoRelDir, env.ProjectName)
+	if _, err := p.Locker.Unlock(models.GenerateLockKey(project, env.Workspace)); err != nil {
+		logger.Warn("unable to release the lock of preview environment %s: %s", env.ID(), err)
+	}
+	if err := p.WorkingDir.DeleteForWorkspace(logger, env.Pull.BaseRepo, env.Pull, env.Workspace); err != nil {
+		logger.Warn("unable to delete the working dir of preview environment %s: %s", env.ID(), err)
+	}
+}
+
+// apply plans and applies projCtx, destroying its resources if destroy is
+// true. Preview environments are deployed with the apply requirements of the
+// project once their plan passes its policies. They're destroyed without
+// either, the environments of closed pull requests can't be approved or
+// mergeable anymore and destroying only deletes the environment's workspace.
+func (p *PreviewCommandRunner) apply(projCtx command.ProjectContext, destroy bool) command.ProjectCommandOutput {
+	if destroy {
+		projCtx.ApplyRequirements = nil
+	}
+
+	planCtx := projCtx
+	planCtx.CommandName = command.Plan
+	if destroy {
+		planCtx.EscapedCommentArgs = slices.Concat(projCtx.EscapedCommentArgs, escapeArgs([]string{"-destroy"}))
+	}
+	if out := p.ProjectCommandRunner.Plan(planCtx); out.Error != nil || out.Failure != "" {
+		return out
+	}
+
+	applyCtx := projCtx
+	if !destroy && len(projCtx.PreviewPolicyCheckSteps) > 0 {
+		policyCtx := projCtx
+		policyCtx.CommandName = command.PolicyCheck
+		policyCtx.Steps = projCtx.PreviewPolicyCheckSteps
+		out := p.ProjectCommandRunner.PolicyCheck(policyCtx)
+		if out.Error != nil || out.Failure != "" {
+			return out
+		}
+		// The policies_passed apply requirement is checked against this
+		// check rather than the pull request's last one.
+		applyCtx.ProjectPolicyStatus = command.ProjectResult{ProjectCommandOutput: out}.PolicyStatus()
+	}
+	applyCtx.CommandName = command.Apply
+	applyCtx.Steps = projCtx.PreviewApplySteps
+	// The comment's arguments were planned with, the planfile is applied
+	// as is.
+	applyCtx.EscapedCommentArgs = nil
+	return p.ProjectCommandRunner.Apply(applyCtx)
+}
+
+// outputs returns the non-sensitive Terraform outputs of the environment of
+// projCtx. Failing to get them is logged since the environment is deployed
+// nonetheless.
+func (p *PreviewCommandRunner) outputs(projCtx command.ProjectContext) map[string]string {
+	if p.OutputStepRunner == nil {
+		return nil
+	}
+	repoDir, err := p.WorkingDir.GetWorkingDir(projCtx.Pull.BaseRepo, projCtx.Pull, projCtx.Workspace)
+	if err != nil {
+		projCtx.Log.Warn("unable to get the outputs of the preview environment: %s", err)
+		return nil
+	}
+	out, err := p.OutputStepRunner.Run(projCtx, nil, filepath.Join(repoDir, projCtx.RepoRelDir), map[string]string{})
+	if err != nil {
+		projCtx.Log.Warn("unable to get the outputs of the preview environment: %s", err)
+		return nil
+	}
+	outputs, err := parsePreviewOutputs(out)
+	if err != nil {
+		projCtx.Log.Warn("unable to parse the outputs of the preview environment: %s", err)
+	}
+	return outputs
+}
+
+// parsePreviewOutputs parses the non-sensitive outputs in the output of
+// terraform output -json. Strings are kept as is, other values as JSON.
+func parsePreviewOutputs(out string) (map[string]string, error) {
+	var parsed map[string]struct {
+		Sensitive bool            `json:"sensitive"`
+		Value     json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		return nil, err
+	}
+	outputs := make(map[string]string)
+	for name, output := range parsed {
+		if output.Sensitive {
+			continue
+		}
+		var s string
+		if err := json.Unmarshal(output.Value, &s); err == nil {
+			outputs[name] = s
+		} else {
+			outputs[name] = string(output.Value)
+		}
+	}
+	return outputs, nil
+}
+
+// checkQuota returns why env can't be deployed if the quota of its project or
+// of the server is reached. Environments that are already deployed may always
+// be deployed again.
+func (p *PreviewCommandRunner) checkQuota(env models.PreviewEnvironment, maxProjectEnvs int) (string, error) {
+	envs, err := p.Database.ListPreviewEnvironments()
+	if err != nil {
+		return "", err
+	}
+	var projectEnvs int
+	for _, e := range envs {
+		if e.ID() == env.ID() {
+			return "", nil
+		}
+		if e.Pull.BaseRepo.FullName == env.Pull.BaseRepo.FullName && e.RepoRelDir == env.RepoRelDir && e.ProjectName == env.ProjectName {
+			projectEnvs++
+		}
+	}
+	if maxProjectEnvs > 0 && projectEnvs >= maxProjectEnvs {
+		return fmt.Sprintf("the project already has %d preview environments, the most its `max_environments` allows. Close pull requests or destroy their environments with `atlantis preview --destroy` first.", projectEnvs), nil
+	}
+	if p.MaxEnvironments > 0 && len(envs) >= p.MaxEnvironments {
+		return fmt.Sprintf("there are already %d preview environments, the most the server allows. Close pull requests or destroy their environments with `atlantis preview --destroy` first.", len(envs)), nil
+	}
+	return "", nil
+}
+
+// pullEnvironments returns the environments of pull.
+func (p *PreviewCommandRunner) pullEnvironments(pull models.PullRequest) ([]models.PreviewEnvironment, error) {
+	envs, err := p.Database.ListPreviewEnvironments()
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(envs, func(env models.PreviewEnvironment) bool {
+		return env.Pull.BaseRepo.FullName != pull.BaseRepo.FullName || env.Pull.Num != pull.Num
+	}), nil
+}
+
+func (p *PreviewCommandRunner) comment(ctx *command.Context, comment string) {
+	if err := p.VCSClient.CreateComment(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num, comment, command.Preview.String()); err != nil {
+		ctx.Log.Err("unable to comment: %s", err)
+	}
+}
+
+func (p *PreviewCommandRunner) clock() time.Time {
+	if p.now != nil {
+		return p.now()
+	}
+	return time.Now()
+}
+
+func previewHeading(env models.PreviewEnvironment) string {
+	if env.ProjectName != "" {
+		return fmt.Sprintf("#### project: `%s` dir: `%s` workspace: `%s`\n", env.ProjectName, env.RepoRelDir, env.Workspace)
+	}
+	return fmt.Sprintf("#### dir: `%s` workspace: `%s`\n", env.RepoRelDir, env.Workspace)
+}
+
+func previewFailure(out command.ProjectCommandOutput) string {
+	if out.Error != nil {
+		return fmt.Sprintf("**Preview Error**\n```\n%s\n```", out.Error)
+	}
+	return fmt.Sprintf("**Preview Failed**: %s", out.Failure)
+}
+
+// previewOutputsTable renders outputs as a markdown table.
+func previewOutputsTable(outputs map[string]string) string {
+	if len(outputs) == 0 {
+		return "It has no outputs."
+	}
+	names := make([]string, 0, len(outputs))
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var table strings.Builder
+	table.WriteString("| Output | Value |\n| --- | --- |\n")
+	for _, name := range names {
+		value := strings.ReplaceAll(strings.ReplaceAll(outputs[name], "|", `\|`), "\n", " ")
+		fmt.Fprintf(&table, "| `%s` | %s |\n", name, value)
+	}
+	return strings.TrimSuffix(table.String(), "\n")
+}
+
+// PreviewExpiryJob destroys the preview environments of closed pull requests
+// and the expired ones. It's run periodically.
+type PreviewExpiryJob struct {
+	Previews   *PreviewCommandRunner
+	Logger     logging.SimpleLogging
+	StatsScope tally.Scope
+}
+
+func (j *PreviewExpiryJob) Run() {
+	j.Previews.DestroyExpired(j.Logger, j.StatsScope.SubScope("preview"))
+}
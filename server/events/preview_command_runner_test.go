@@ -0,0 +1,287 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/core/boltdb"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	lockmocks "github.com/runatlantis/atlantis/server/core/locking/mocks"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+	tally "github.com/uber-go/tally/v4"
+)
+
+type fakePreviewBuilder struct {
+	ProjectPlanCommandBuilder
+	projects []command.ProjectContext
+}
+
+func (f *fakePreviewBuilder) BuildPlanCommands(_ *command.Context, cmd *CommentCommand) ([]command.ProjectContext, error) {
+	var projects []command.ProjectContext
+	for _, projCtx := range f.projects {
+		if (cmd.ProjectName == "" || projCtx.ProjectName == cmd.ProjectName) && (cmd.RepoRelDir == "" || projCtx.RepoRelDir == cmd.RepoRelDir) {
+			projects = append(projects, projCtx)
+		}
+	}
+	return projects, nil
+}
+
+type fakePreviewRunner struct {
+	plans         []command.ProjectContext
+	policyChecks  []command.ProjectContext
+	applies       []command.ProjectContext
+	failure       string
+	policyFailure string
+}
+
+func (f *fakePreviewRunner) Plan(ctx command.ProjectContext) command.ProjectCommandOutput {
+	f.plans = append(f.plans, ctx)
+	return command.ProjectCommandOutput{PlanSuccess: &models.PlanSuccess{}}
+}
+
+func (f *fakePreviewRunner) PolicyCheck(ctx command.ProjectContext) command.ProjectCommandOutput {
+	f.policyChecks = append(f.policyChecks, ctx)
+	return command.ProjectCommandOutput{
+		Failure: f.policyFailure,
+		PolicyCheckResults: &models.PolicyCheckResults{
+			PolicySetResults: []models.PolicySetResult{{PolicySetName: "policies", Passed: f.policyFailure == ""}},
+		},
+	}
+}
+
+func (f *fakePreviewRunner) Apply(ctx command.ProjectContext) command.ProjectCommandOutput {
+	f.applies = append(f.applies, ctx)
+	return command.ProjectCommandOutput{Failure: f.failure, ApplySuccess: "applied"}
+}
+
+type fakeOutputStepRunner struct {
+	out string
+}
+
+func (f fakeOutputStepRunner) Run(command.ProjectContext, []string, string, map[string]string) (string, error) {
+	return f.out, nil
+}
+
+type fakePreviewWorkingDir struct {
+	WorkingDir
+	deletedWorkspaces []string
+	deletedPulls      []int
+}
+
+func (f *fakePreviewWorkingDir) GetWorkingDir(models.Repo, models.PullRequest, string) (string, error) {
+	return "/data/repos/owner/repo/1/pr-1", nil
+}
+
+func (f *fakePreviewWorkingDir) Delete(_ logging.SimpleLogging, _ models.Repo, pull models.PullRequest) error {
+	f.deletedPulls = append(f.deletedPulls, pull.Num)
+	return nil
+}
+
+func (f *fakePreviewWorkingDir) DeleteForWorkspace(_ logging.SimpleLogging, _ models.Repo, _ models.PullRequest, workspace string) error {
+	f.deletedWorkspaces = append(f.deletedWorkspaces, workspace)
+	return nil
+}
+
+var previewNow = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newPreviewCommandRunner(t *testing.T, projects ...command.ProjectContext) (*PreviewCommandRunner, *fakePreviewRunner, *vcsmocks.MockClient) {
+	RegisterMockTestingT(t)
+	database, err := boltdb.New(t.TempDir())
+	Ok(t, err)
+	t.Cleanup(func() { database.Close() }) // nolint: errcheck
+	runner := &fakePreviewRunner{}
+	vcsClient := vcsmocks.NewMockClient()
+	return &PreviewCommandRunner{
+		VCSClient:             vcsClient,
+		ProjectCommandBuilder: &fakePreviewBuilder{projects: projects},
+		ProjectCommandRunner:  runner,
+		OutputStepRunner:      fakeOutputStepRunner{out: `{"url":{"sensitive":false,"type":"string","value":"https://pr-1.example.com"},"ports":{"sensitive":false,"value":[80,443]},"password":{"sensitive":true,"value":"hunter2"}}`},
+		WorkingDir:            &fakePreviewWorkingDir{},
+		Locker:                lockmocks.NewMockLocker(),
+		Database:              database,
+		now:                   func() time.Time { return previewNow },
+	}, runner, vcsClient
+}
+
+func newPreviewContext(t *testing.T) *command.Context {
+	return &command.Context{
+		Log:  logging.NewNoopLogger(t),
+		Pull: models.PullRequest{Num: 1, BaseRepo: models.Repo{FullName: "owner/repo"}, BaseBranch: "main"},
+		User: models.User{Username: "alice"},
+	}
+}
+
+func newPreviewProject(name string, preview valid.Preview) command.ProjectContext {
+	return command.ProjectContext{
+		ProjectName:       name,
+		RepoRelDir:        "app",
+		Workspace:         "default",
+		Preview:           &preview,
+		ApplyRequirements: []string{valid.ApprovedCommandReq},
+		PreviewApplySteps: []valid.Step{{StepName: "apply"}},
+	}
+}
+
+func previewComments(vcsClient *vcsmocks.MockClient) []string {
+	_, _, _, comments, _ := vcsClient.VerifyWasCalled(AtLeast(0)).CreateComment(
+		Any[logging.SimpleLogging](), Any[models.Repo](), Any[int](), Any[string](), Eq("preview")).GetAllCapturedArguments()
+	return comments
+}
+
+func TestPreviewCommandRunner_Deploy(t *testing.T) {
+	p, runner, vcsClient := newPreviewCommandRunner(t, newPreviewProject("app", valid.Preview{TTL: time.Hour, WorkspacePrefix: "pr-"}))
+	p.Run(newPreviewContext(t), &CommentCommand{Name: command.Preview})
+
+	Equals(t, 1, len(runner.plans))
+	Equals(t, "pr-1", runner.plans[0].Workspace)
+	Equals(t, command.Plan, runner.plans[0].CommandName)
+	Equals(t, 1, len(runner.applies))
+	// The project's apply requirements are kept.
+	Equals(t, []string{valid.ApprovedCommandReq}, runner.applies[0].ApplyRequirements)
+	Equals(t, command.Apply, runner.applies[0].CommandName)
+	Equals(t, []valid.Step{{StepName: "apply"}}, runner.applies[0].Steps)
+
+	envs, err := p.Database.ListPreviewEnvironments()
+	Ok(t, err)
+	Equals(t, 1, len(envs))
+	Equals(t, "owner/repo/1/app/app/pr-1", envs[0].ID())
+	Equals(t, previewNow.Add(time.Hour), envs[0].ExpiresAt.UTC())
+	Equals(t, map[string]string{"url": "https://pr-1.example.com", "ports": "[80,443]"}, envs[0].Outputs)
+
+	comments := previewComments(vcsClient)
+	Equals(t, 1, len(comments))
+	Equals(t, "### Preview Environments\n\n#### project: `app` dir: `app` workspace: `pr-1`\nDeployed, it will be destroyed Sat, 01 Mar 2025 13:00:00 UTC if the pull request isn't closed before.\n\n| Output | Value |\n| --- | --- |\n| `ports` | [80,443] |\n| `url` | https://pr-1.example.com |", comments[0])
+}
+
+func TestPreviewCommandRunner_PolicyCheck(t *testing.T) {
+	project := newPreviewProject("app", valid.Preview{WorkspacePrefix: "pr-"})
+	project.PreviewPolicyCheckSteps = []valid.Step{{StepName: "policy_check"}}
+	p, runner, vcsClient := newPreviewCommandRunner(t, project)
+	runner.policyFailure = "Some policy sets did not pass."
+	ctx := newPreviewContext(t)
+	p.Run(ctx, &CommentCommand{Name: command.Preview})
+
+	Equals(t, 1, len(runner.policyChecks))
+	Equals(t, command.PolicyCheck, runner.policyChecks[0].CommandName)
+	Equals(t, "pr-1", runner.policyChecks[0].Workspace)
+	Equals(t, []valid.Step{{StepName: "policy_check"}}, runner.policyChecks[0].Steps)
+	Equals(t, 0, len(runner.applies))
+	comments := previewComments(vcsClient)
+	Equals(t, 1, len(comments))
+	Assert(t, strings.HasSuffix(comments[0], "**Preview Failed**: Some policy sets did not pass."), "unexpected comment %q", comments[0])
+
+	runner.policyFailure = ""
+	p.Run(ctx, &CommentCommand{Name: command.Preview})
+	Equals(t, 1, len(runner.applies))
+	Equals(t, []models.PolicySetStatus{{PolicySetName: "policies", Passed: true}}, runner.applies[0].ProjectPolicyStatus)
+
+	// Destroying doesn't check the policies.
+	p.Run(ctx, &CommentCommand{Name: command.Preview, Destroy: true})
+	Equals(t, 2, len(runner.policyChecks))
+	Equals(t, 2, len(runner.applies))
+}
+
+func TestPreviewCommandRunner_DeployNoProjects(t *testing.T) {
+	p, runner, vcsClient := newPreviewCommandRunner(t, newPreviewProject("app", valid.Preview{WorkspacePrefix: "pr-"}))
+	// Autoplan only deploys the projects deployed automatically, and doesn't
+	// comment if there are none.
+	p.AutoDeploy(newPreviewContext(t))
+	Equals(t, 0, len(runner.plans))
+	Equals(t, 0, len(previewComments(vcsClient)))
+
+	p.Run(newPreviewContext(t), &CommentCommand{Name: command.Preview, ProjectName: "other"})
+	comments := previewComments(vcsClient)
+	Equals(t, 1, len(comments))
+	Assert(t, strings.HasPrefix(comments[0], "No preview projects to deploy."), "unexpected comment %q", comments[0])
+}
+
+func TestPreviewCommandRunner_Quota(t *testing.T) {
+	p, runner, vcsClient := newPreviewCommandRunner(t, newPreviewProject("app", valid.Preview{MaxEnvironments: 1, WorkspacePrefix: "pr-"}))
+	Ok(t, p.Database.SavePreviewEnvironment(models.PreviewEnvironment{
+		Pull:        models.PullRequest{Num: 2, BaseRepo: models.Repo{FullName: "owner/repo"}},
+		ProjectName: "app",
+		RepoRelDir:  "app",
+		Workspace:   "pr-2",
+	}))
+
+	p.Run(newPreviewContext(t), &CommentCommand{Name: command.Preview})
+	Equals(t, 0, len(runner.plans))
+	comments := previewComments(vcsClient)
+	Equals(t, 1, len(comments))
+	Assert(t, strings.Contains(comments[0], "**Preview Failed**: the project already has 1 preview environments"), "unexpected comment %q", comments[0])
+
+	// The environments that are already deployed may be deployed again.
+	p.Run(&command.Context{
+		Log:  logging.NewNoopLogger(t),
+		Pull: models.PullRequest{Num: 2, BaseRepo: models.Repo{FullName: "owner/repo"}},
+	}, &CommentCommand{Name: command.Preview})
+	Equals(t, 1, len(runner.applies))
+}
+
+func TestPreviewCommandRunner_Destroy(t *testing.T) {
+	p, runner, vcsClient := newPreviewCommandRunner(t, newPreviewProject("app", valid.Preview{WorkspacePrefix: "pr-"}))
+	ctx := newPreviewContext(t)
+	p.Run(ctx, &CommentCommand{Name: command.Preview})
+
+	p.Run(ctx, &CommentCommand{Name: command.Preview, Destroy: true})
+	Equals(t, 2, len(runner.plans))
+	Equals(t, []string{`\-\d\e\s\t\r\o\y`}, runner.plans[1].EscapedCommentArgs)
+	Equals(t, "pr-1", runner.plans[1].Workspace)
+	// Destroys don't have to meet the apply requirements.
+	Equals(t, 0, len(runner.applies[1].ApplyRequirements))
+	envs, err := p.Database.ListPreviewEnvironments()
+	Ok(t, err)
+	Equals(t, 0, len(envs))
+	Equals(t, []string{"pr-1"}, p.WorkingDir.(*fakePreviewWorkingDir).deletedWorkspaces)
+
+	comments := previewComments(vcsClient)
+	Equals(t, 2, len(comments))
+	Equals(t, "### Preview Environments\n\n#### project: `app` dir: `app` workspace: `pr-1`\nDestroyed.", comments[1])
+}
+
+func TestPreviewCommandRunner_DestroyExpired(t *testing.T) {
+	p, runner, vcsClient := newPreviewCommandRunner(t, newPreviewProject("app", valid.Preview{WorkspacePrefix: "pr-"}))
+	p.Run(newPreviewContext(t), &CommentCommand{Name: command.Preview})
+
+	// Environments of open pull requests that don't expire are kept.
+	p.DestroyExpired(logging.NewNoopLogger(t), tally.NewTestScope("test", nil))
+	Equals(t, 1, len(runner.plans))
+
+	Ok(t, p.ClosePull(models.PullRequest{Num: 1, BaseRepo: models.Repo{FullName: "owner/repo"}}))
+	runner.failure = "the apply failed"
+	p.DestroyExpired(logging.NewNoopLogger(t), tally.NewTestScope("test", nil))
+	p.DestroyExpired(logging.NewNoopLogger(t), tally.NewTestScope("test", nil))
+	envs, err := p.Database.ListPreviewEnvironments()
+	Ok(t, err)
+	Equals(t, 1, len(envs))
+	Assert(t, envs[0].Closed, "the environment wasn't marked closed")
+	// The same error is only commented once.
+	comments := previewComments(vcsClient)
+	Equals(t, 2, len(comments))
+	Equals(t, "### Preview Environments\n\nDestroying the preview environments since the pull request was closed.\n\n#### project: `app` dir: `app` workspace: `pr-1`\n**Preview Failed**: the apply failed", comments[1])
+
+	runner.failure = ""
+	p.DestroyExpired(logging.NewNoopLogger(t), tally.NewTestScope("test", nil))
+	envs, err = p.Database.ListPreviewEnvironments()
+	Ok(t, err)
+	Equals(t, 0, len(envs))
+	Equals(t, []int{1}, p.WorkingDir.(*fakePreviewWorkingDir).deletedPulls)
+	Equals(t, 3, len(previewComments(vcsClient)))
+}
+
+func TestPreviewCommandRunner_Disabled(t *testing.T) {
+	p, runner, vcsClient := newPreviewCommandRunner(t, newPreviewProject("app", valid.Preview{WorkspacePrefix: "pr-"}))
+	p.Disabled = true
+	p.Run(newPreviewContext(t), &CommentCommand{Name: command.Preview})
+	Equals(t, 0, len(runner.plans))
+	Equals(t, []string{"Preview environments aren't enabled on this Atlantis server, see `--enable-preview-environments`."}, previewComments(vcsClient))
+}
@@ -0,0 +1,186 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime/pprof"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/utils"
+)
+
+// ProfileTypes are the types of profiles ProfileStore captures.
+var ProfileTypes = []string{"cpu", "heap", "goroutine"}
+
+// ErrProfileCaptureInProgress is returned when profiles are captured while
+// others are still being captured.
+var ErrProfileCaptureInProgress = errors.New("profiles are already being captured")
+
+const profileExt = ".pb.gz"
+
+// profileIDRegex matches the IDs of profiles, <time>-<type>, so IDs can't
+// reference files outside of the store.
+var profileIDRegex = regexp.MustCompile(`^[0-9]{8}T[0-9]{6}Z-[a-z]+$`)
+
+// StoredProfile is a captured profile.
+type StoredProfile struct {
+	ID        string
+	Type      string
+	Size      int64
+	CreatedAt time.Time
+}
+
+// ProfileStore captures profiles of the server on demand and stores them in
+// Dir, so production servers can be profiled without exec access. The
+// profiles are in the format of runtime/pprof, for go tool pprof.
+type ProfileStore struct {
+	Dir    string
+	Logger logging.SimpleLogging
+
+	capturing atomic.Bool
+	// cpuFile is the file the CPU profile is being recorded to.
+	cpuFile *os.File
+}
+
+func (p *ProfileStore) Name() string {
+	return "profiles"
+}
+
+// Capture captures profiles of types in the background and returns their IDs.
+// The CPU profile is recorded for duration, the heap and goroutine profiles
+// are snapshots taken once it's recorded.
+func (p *ProfileStore) Capture(types []string, duration time.Duration) ([]string, error) {
+	for _, profileType := range types {
+		if !slices.Contains(ProfileTypes, profileType) {
+			return nil, fmt.Errorf("invalid profile type %q, must be one of %s", profileType, strings.Join(ProfileTypes, ", "))
+		}
+	}
+	if !p.capturing.CompareAndSwap(false, true) {
+		return nil, ErrProfileCaptureInProgress
+	}
+	if err := os.MkdirAll(p.Dir, 0700); err != nil {
+		p.capturing.Store(false)
+		return nil, err
+	}
+	if slices.Contains(types, "cpu") {
+		if err := p.startCPUProfile(); err != nil {
+			p.capturing.Store(false)
+			return nil, err
+		}
+	}
+
+	prefix := time.Now().UTC().Format("20060102T150405Z")
+	var ids []string
+	for _, profileType := range types {
+		ids = append(ids, prefix+"-"+profileType)
+	}
+	go func() {
+		defer p.capturing.Store(false)
+		time.Sleep(duration)
+		for _, profileType := range types {
+			if err := p.write(prefix+"-"+profileType, profileType); err != nil {
+				p.Logger.Err("unable to capture %s profile: %s", profileType, err)
+			}
+		}
+		p.Logger.Info("captured profiles %s", strings.Join(ids, ", "))
+	}()
+	return ids, nil
+}
+
+// startCPUProfile starts recording the CPU profile to a temporary file that's
+// moved in place by write.
+func (p *ProfileStore) startCPUProfile() error {
+	f, err := os.Create(filepath.Join(p.Dir, "cpu.tmp"))
+	if err != nil {
+		return err
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close() // nolint: errcheck
+		return fmt.Errorf("starting CPU profile: %w", err)
+	}
+	p.cpuFile = f
+	return nil
+}
+
+// write writes the profile of profileType with id. Profiles are written to a
+// temporary file first so partial profiles aren't listed.
+func (p *ProfileStore) write(id string, profileType string) error {
+	tmpPath := filepath.Join(p.Dir, profileType+".tmp")
+	if profileType == "cpu" {
+		pprof.StopCPUProfile()
+		if err := p.cpuFile.Close(); err != nil {
+			return err
+		}
+	} else {
+		f, err := os.Create(tmpPath)
+		if err != nil {
+			return err
+		}
+		err = pprof.Lookup(profileType).WriteTo(f, 0)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmpPath, filepath.Join(p.Dir, id+profileExt))
+}
+
+// List returns the stored profiles, the most recent first.
+func (p *ProfileStore) List() ([]StoredProfile, error) {
+	paths, err := filepath.Glob(filepath.Join(p.Dir, "*"+profileExt))
+	if err != nil {
+		return nil, err
+	}
+	profiles := []StoredProfile{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		id := strings.TrimSuffix(filepath.Base(path), profileExt)
+		_, profileType, _ := strings.Cut(id, "-")
+		profiles = append(profiles, StoredProfile{ID: id, Type: profileType, Size: info.Size(), CreatedAt: info.ModTime()})
+	}
+	slices.SortFunc(profiles, func(a, b StoredProfile) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	return profiles, nil
+}
+
+// Read returns the profile with id, or an error that's os.ErrNotExist if
+// there's none.
+func (p *ProfileStore) Read(id string) ([]byte, error) {
+	if !profileIDRegex.MatchString(id) {
+		return nil, os.ErrNotExist
+	}
+	return os.ReadFile(filepath.Join(p.Dir, id+profileExt)) // nolint: gosec
+}
+
+// Artifacts lists the stored profiles so their retention is enforced. They
+// aren't generated for a repo so they share the quota of the empty repo.
+func (p *ProfileStore) Artifacts() ([]RetainedArtifact, error) {
+	profiles, err := p.List()
+	if err != nil {
+		return nil, err
+	}
+	var artifacts []RetainedArtifact
+	for _, profile := range profiles {
+		path := filepath.Join(p.Dir, profile.ID+profileExt)
+		artifacts = append(artifacts, RetainedArtifact{
+			Size:    profile.Size,
+			ModTime: profile.CreatedAt,
+			Delete:  func() error { return utils.RemoveIgnoreNonExistent(path) },
+		})
+	}
+	return artifacts, nil
+}
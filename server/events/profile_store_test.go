@@ -0,0 +1,58 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestProfileStore(t *testing.T) {
+	store := &events.ProfileStore{Dir: t.TempDir(), Logger: logging.NewNoopLogger(t)}
+	profiles, err := store.List()
+	Ok(t, err)
+	Equals(t, 0, len(profiles))
+
+	_, err = store.Capture([]string{"cpu", "mutex"}, time.Millisecond)
+	ErrEquals(t, `invalid profile type "mutex", must be one of cpu, heap, goroutine`, err)
+
+	ids, err := store.Capture(events.ProfileTypes, 100*time.Millisecond)
+	Ok(t, err)
+	Equals(t, 3, len(ids))
+	_, err = store.Capture([]string{"heap"}, time.Millisecond)
+	Equals(t, events.ErrProfileCaptureInProgress, err)
+
+	for range 50 {
+		if profiles, err = store.List(); err == nil && len(profiles) == 3 {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	Ok(t, err)
+	Equals(t, 3, len(profiles))
+	for _, id := range ids {
+		profile, err := store.Read(id)
+		Ok(t, err)
+		// Profiles are gzipped.
+		Equals(t, []byte{0x1f, 0x8b}, profile[:2])
+	}
+
+	t.Log("ids can't reference other files")
+	_, err = store.Read("../profiles/" + ids[0])
+	Assert(t, os.IsNotExist(err), "exp not exist, got %v", err)
+
+	artifacts, err := store.Artifacts()
+	Ok(t, err)
+	Equals(t, 3, len(artifacts))
+	Equals(t, "", artifacts[0].Repo)
+	Ok(t, artifacts[0].Delete())
+	profiles, err = store.List()
+	Ok(t, err)
+	Equals(t, 2, len(profiles))
+}
@@ -0,0 +1,205 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/jobs"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// ProgressCommentClient creates and edits the comment showing the progress of
+// a command.
+type ProgressCommentClient interface {
+	// CreateEditableComment creates a comment and returns its id.
+	CreateEditableComment(logger logging.SimpleLogging, repo models.Repo, pullNum int, comment string) (int64, error)
+	EditComment(logger logging.SimpleLogging, repo models.Repo, commentID int64, comment string) error
+}
+
+// ProgressCommenter comments on the pull request when a command starts and
+// edits the comment with the command's progress until it completes, instead
+// of nothing showing up on the pull request until the results are posted.
+type ProgressCommenter struct {
+	Client          ProgressCommentClient
+	JobURLGenerator jobs.ProjectJobURLGenerator
+	// Interval is how often the comment is edited while the command runs.
+	Interval time.Duration
+}
+
+// Start comments that cmdName started. The comment is edited with the
+// command's progress until Finish is called on the returned progress.
+func (p *ProgressCommenter) Start(ctx *command.Context, cmdName command.Name) (*CommandProgress, error) {
+	progress := &CommandProgress{
+		client:          p.Client,
+		jobURLGenerator: p.JobURLGenerator,
+		log:             ctx.Log,
+		repo:            ctx.Pull.BaseRepo,
+		cmdName:         cmdName,
+		started:         time.Now(),
+		running:         make(map[string]string),
+		stop:            make(chan struct{}),
+		stopped:         make(chan struct{}),
+	}
+	commentID, err := p.Client.CreateEditableComment(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num, progress.comment(progress.started))
+	if err != nil {
+		return nil, fmt.Errorf("creating progress comment: %w", err)
+	}
+	progress.commentID = commentID
+	go progress.editPeriodically(p.Interval)
+	return progress, nil
+}
+
+// CommandProgress is the progress of a command shown in a comment. It
+// implements command.ProgressReporter.
+type CommandProgress struct {
+	client          ProgressCommentClient
+	jobURLGenerator jobs.ProjectJobURLGenerator
+	log             logging.SimpleLogging
+	repo            models.Repo
+	commentID       int64
+	cmdName         command.Name
+	started         time.Time
+	stop            chan struct{}
+	stopped         chan struct{}
+
+	mutex    sync.Mutex
+	projects int
+	// startedProjects is how many projects started running, including the
+	// ones that completed.
+	startedProjects int
+	// running are the job URLs of the running projects, by name. The URL is
+	// empty if it couldn't be generated.
+	running map[string]string
+}
+
+func (c *CommandProgress) SetProjects(count int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.projects = count
+}
+
+func (c *CommandProgress) ProjectStarted(ctx command.ProjectContext) {
+	url := ""
+	if c.jobURLGenerator != nil {
+		if jobURL, err := c.jobURLGenerator.GenerateProjectJobURL(ctx); err == nil {
+			url = jobURL
+		}
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.startedProjects++
+	c.running[projectCmdName(ctx)] = url
+}
+
+func (c *CommandProgress) ProjectFinished(ctx command.ProjectContext) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.running, projectCmdName(ctx))
+}
+
+// Finish stops editing the comment and edits it a last time to say the
+// command completed, its results are in their own comment.
+func (c *CommandProgress) Finish(hasErrors bool) {
+	close(c.stop)
+	<-c.stopped
+	outcome := "completed"
+	if hasErrors {
+		outcome = "completed with errors"
+	}
+	comment := fmt.Sprintf("`%s` %s in %s, see the results below.", c.cmdName.String(), outcome, elapsedSince(c.started, time.Now()))
+	if err := c.client.EditComment(c.log, c.repo, c.commentID, comment); err != nil {
+		c.log.Warn("unable to edit progress comment: %s", err)
+	}
+}
+
+func (c *CommandProgress) editPeriodically(interval time.Duration) {
+	defer close(c.stopped)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case now := <-ticker.C:
+			if err := c.client.EditComment(c.log, c.repo, c.commentID, c.comment(now)); err != nil {
+				c.log.Warn("unable to edit progress comment: %s", err)
+			}
+		}
+	}
+}
+
+// comment renders the progress at now, ex. "Running `plan`: planning project
+// 3/7, 2m0s elapsed." followed by links to the logs of the running projects.
+func (c *CommandProgress) comment(now time.Time) string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	step := "initializing"
+	if c.projects > 0 {
+		step = fmt.Sprintf("%s project %d/%d", progressVerb(c.cmdName), max(c.startedProjects, 1), c.projects)
+	}
+	comment := fmt.Sprintf(":hourglass: Running `%s`: %s, %s elapsed.", c.cmdName.String(), step, elapsedSince(c.started, now))
+	if len(c.running) == 0 {
+		return comment
+	}
+
+	var names []string
+	for name := range c.running {
+		names = append(names, name)
+	}
+	// Names are sorted so the comment doesn't change between edits.
+	sort.Strings(names)
+	var lines []string
+	for _, name := range names {
+		if url := c.running[name]; url != "" {
+			lines = append(lines, fmt.Sprintf("* %s: [streaming logs](%s)", name, url))
+		} else {
+			lines = append(lines, fmt.Sprintf("* %s", name))
+		}
+	}
+	return comment + "\n\nRunning:\n" + strings.Join(lines, "\n")
+}
+
+func progressVerb(cmdName command.Name) string {
+	switch cmdName {
+	case command.Plan:
+		return "planning"
+	case command.Apply:
+		return "applying"
+	default:
+		return "running"
+	}
+}
+
+func elapsedSince(started time.Time, now time.Time) time.Duration {
+	return now.Sub(started).Round(time.Second)
+}
+
+// withProgress returns runnerFunc telling ctx.Progress, if any, about the
+// projects it runs.
+func withProgress(ctx *command.Context, runnerFunc prjCmdRunnerFunc) prjCmdRunnerFunc {
+	if ctx.Progress == nil {
+		return runnerFunc
+	}
+	return func(projectCtx command.ProjectContext) command.ProjectCommandOutput {
+		ctx.Progress.ProjectStarted(projectCtx)
+		defer ctx.Progress.ProjectFinished(projectCtx)
+		return runnerFunc(projectCtx)
+	}
+}
+
+// reportProjects tells ctx.Progress, if any, how many projects the command
+// runs.
+func reportProjects(ctx *command.Context, projectCmds []command.ProjectContext) {
+	if ctx.Progress != nil {
+		ctx.Progress.SetProjects(len(projectCmds))
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+type fakeProgressCommentClient struct {
+	created []string
+	edited  []string
+}
+
+func (f *fakeProgressCommentClient) CreateEditableComment(_ logging.SimpleLogging, _ models.Repo, _ int, comment string) (int64, error) {
+	f.created = append(f.created, comment)
+	return 1, nil
+}
+
+func (f *fakeProgressCommentClient) EditComment(_ logging.SimpleLogging, _ models.Repo, _ int64, comment string) error {
+	f.edited = append(f.edited, comment)
+	return nil
+}
+
+type fakeJobURLGenerator struct{}
+
+func (fakeJobURLGenerator) GenerateProjectJobURL(p command.ProjectContext) (string, error) {
+	return "https://atlantis/jobs/" + p.ProjectName, nil
+}
+
+func TestProgressCommenter(t *testing.T) {
+	client := &fakeProgressCommentClient{}
+	commenter := &ProgressCommenter{
+		Client:          client,
+		JobURLGenerator: fakeJobURLGenerator{},
+		Interval:        time.Hour,
+	}
+	ctx := &command.Context{
+		Log:  logging.NewNoopLogger(t),
+		Pull: models.PullRequest{Num: 1, BaseRepo: models.Repo{FullName: "owner/repo"}},
+	}
+
+	progress, err := commenter.Start(ctx, command.Plan)
+	Ok(t, err)
+	Equals(t, []string{":hourglass: Running `plan`: initializing, 0s elapsed."}, client.created)
+
+	ctx.Progress = progress
+	projectCmds := []command.ProjectContext{{ProjectName: "a"}, {ProjectName: "b"}, {ProjectName: "c"}}
+	reportProjects(ctx, projectCmds)
+	runnerFunc := withProgress(ctx, func(projectCtx command.ProjectContext) command.ProjectCommandOutput {
+		return command.ProjectCommandOutput{}
+	})
+	runnerFunc(projectCmds[0])
+	progress.ProjectStarted(projectCmds[1])
+	Equals(t, ":hourglass: Running `plan`: planning project 2/3, 2m0s elapsed.\n\n"+
+		"Running:\n"+
+		"* `b`: [streaming logs](https://atlantis/jobs/b)",
+		progress.comment(progress.started.Add(2*time.Minute)))
+
+	progress.Finish(true)
+	Equals(t, 1, len(client.edited))
+	Assert(t, strings.HasPrefix(client.edited[0], "`plan` completed with errors in "), "unexpected final comment %q", client.edited[0])
+}
+
+func TestWithProgress_NoProgress(t *testing.T) {
+	ran := false
+	runnerFunc := withProgress(&command.Context{}, func(command.ProjectContext) command.ProjectCommandOutput {
+		ran = true
+		return command.ProjectCommandOutput{}
+	})
+	runnerFunc(command.ProjectContext{})
+	Assert(t, ran, "expected the runner to run")
+}
@@ -261,7 +261,7 @@ func (p *DefaultProjectCommandBuilder) BuildAutoplanCommands(ctx *command.Contex
 		return nil, err
 	}
 	var autoplanEnabled []command.ProjectContext
-	for _, projCtx := range projCtxs {
+	for _, projCtx := range filterPreviewProjects(ctx, projCtxs, false) {
 		if !projCtx.AutoplanEnabled {
 			ctx.Log.Debug("ignoring project at dir '%s', workspace: '%s' because autoplan is disabled", projCtx.RepoRelDir, projCtx.Workspace)
 			continue
@@ -273,21 +273,53 @@ func (p *DefaultProjectCommandBuilder) BuildAutoplanCommands(ctx *command.Contex
 
 // See ProjectCommandBuilder.BuildPlanCommands.
 func (p *DefaultProjectCommandBuilder) BuildPlanCommands(ctx *command.Context, cmd *CommentCommand) ([]command.ProjectContext, error) {
+	var projCtxs []command.ProjectContext
+	var err error
 	if !cmd.IsForSpecificProject() {
 		ctx.Log.Debug("Building plan command for all affected projects")
-		return p.buildAllCommandsByCfg(ctx, cmd.CommandName(), cmd.SubName, cmd.Flags, cmd.Verbose)
+		projCtxs, err = p.buildAllCommandsByCfg(ctx, cmd.CommandName(), cmd.SubName, cmd.Flags, cmd.Verbose)
+	} else {
+		ctx.Log.Debug("Building plan command for specific project with directory: '%v', workspace: '%v', project: '%v'",
+			cmd.RepoRelDir, cmd.Workspace, cmd.ProjectName)
+		projCtxs, err = p.buildProjectPlanCommand(ctx, cmd)
+	}
+	if err != nil {
+		return nil, err
 	}
-	ctx.Log.Debug("Building plan command for specific project with directory: '%v', workspace: '%v', project: '%v'",
-		cmd.RepoRelDir, cmd.Workspace, cmd.ProjectName)
-	return p.buildProjectPlanCommand(ctx, cmd)
+	return filterPreviewProjects(ctx, projCtxs, cmd.Name == command.Preview), nil
 }
 
 // See ProjectCommandBuilder.BuildApplyCommands.
 func (p *DefaultProjectCommandBuilder) BuildApplyCommands(ctx *command.Context, cmd *CommentCommand) ([]command.ProjectContext, error) {
+	var projCtxs []command.ProjectContext
+	var err error
 	if !cmd.IsForSpecificProject() {
-		return p.buildAllProjectCommandsByPlan(ctx, cmd)
+		projCtxs, err = p.buildAllProjectCommandsByPlan(ctx, cmd)
+	} else {
+		projCtxs, err = p.buildProjectCommand(ctx, cmd)
 	}
-	return p.buildProjectCommand(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	// The plans of preview environments are applied by atlantis preview.
+	return filterPreviewProjects(ctx, projCtxs, false), nil
+}
+
+// filterPreviewProjects returns the contexts of the preview projects in
+// projCtxs if preview is true and the contexts of the other projects
+// otherwise, since preview projects are only run by atlantis preview.
+func filterPreviewProjects(ctx *command.Context, projCtxs []command.ProjectContext, preview bool) []command.ProjectContext {
+	return slices.DeleteFunc(projCtxs, func(projCtx command.ProjectContext) bool {
+		if (projCtx.Preview != nil) == preview {
+			return false
+		}
+		if preview {
+			ctx.Log.Debug("ignoring project at dir '%s', workspace: '%s' because it isn't a preview project", projCtx.RepoRelDir, projCtx.Workspace)
+		} else {
+			ctx.Log.Debug("ignoring project at dir '%s', workspace: '%s' because it's a preview project", projCtx.RepoRelDir, projCtx.Workspace)
+		}
+		return true
+	})
 }
 
 func (p *DefaultProjectCommandBuilder) BuildApprovePoliciesCommands(ctx *command.Context, cmd *CommentCommand) ([]command.ProjectContext, error) {
@@ -79,11 +79,13 @@ func TestDefaultProjectCommandBuilder_BuildAutoplanCommands(t *testing.T) {
 	}
 
 	cases := []struct {
-		Description      string
-		AtlantisYAML     string
-		ServerSideYAML   string
-		TestDirStructure map[string]any
-		exp              []expCtxFields
+		Description    string
+		AtlantisYAML   string
+		ServerSideYAML string
+		// AllowAllRepoSettings allows the repo config to set any key.
+		AllowAllRepoSettings bool
+		TestDirStructure     map[string]any
+		exp                  []expCtxFields
 	}{
 		{
 			Description: "simple atlantis.yaml",
@@ -160,6 +162,27 @@ projects:
 				},
 			},
 		},
+		{
+			Description: "preview projects skipped",
+			AtlantisYAML: `
+version: 3
+projects:
+- dir: .
+- dir: .
+  name: preview
+  preview:
+    auto: true
+`,
+			AllowAllRepoSettings: true,
+			TestDirStructure:     defaultTestDirStructure,
+			exp: []expCtxFields{
+				{
+					ProjectName: "",
+					RepoRelDir:  ".",
+					Workspace:   "default",
+				},
+			},
+		},
 		{
 			Description: "no projects modified",
 			AtlantisYAML: `
@@ -253,7 +276,7 @@ terraform {
 				Ok(t, err)
 			}
 
-			globalCfgArgs := valid.GlobalCfgArgs{}
+			globalCfgArgs := valid.GlobalCfgArgs{AllowAllRepoSettings: c.AllowAllRepoSettings}
 
 			builder := events.NewProjectCommandBuilder(
 				false,
@@ -103,7 +103,7 @@ func (cb *DefaultProjectCommandContextBuilder) BuildProjectContext(
 
 	var steps []valid.Step
 	switch cmdName {
-	case command.Plan:
+	case command.Plan, command.Preview:
 		steps = prjCfg.Workflow.Plan.Steps
 	case command.Apply:
 		steps = prjCfg.Workflow.Apply.Steps
@@ -131,6 +131,9 @@ func (cb *DefaultProjectCommandContextBuilder) BuildProjectContext(
 		prjCfg.TerraformVersion = terraformClient.DetectVersion(ctx.Log, filepath.Join(repoDir, prjCfg.RepoRelDir))
 	}
 
+	behavior := pullBehavior(ctx, prjCfg.BehaviorRules, repoDir)
+	prjCfg = behavior.Apply(prjCfg)
+
 	projectCmdContext := newProjectCommandContext(
 		ctx,
 		cmdName,
@@ -152,6 +155,13 @@ func (cb *DefaultProjectCommandContextBuilder) BuildProjectContext(
 		ctx.PullStatus,
 		ctx.TeamAllowlistChecker,
 	)
+	projectCmdContext.AutoApply = behavior.AutoApply
+	if cmdName == command.Preview {
+		projectCmdContext.PreviewApplySteps = prjCfg.Workflow.Apply.Steps
+		if prjCfg.PolicyCheck {
+			projectCmdContext.PreviewPolicyCheckSteps = prjCfg.Workflow.PolicyCheck.Steps
+		}
+	}
 
 	projectCmds = append(projectCmds, projectCmdContext)
 
@@ -285,6 +295,16 @@ func newProjectCommandContext(ctx *command.Context,
 		AutomergeEnabled:           automergeEnabled,
 		DeleteSourceBranchOnMerge:  projCfg.DeleteSourceBranchOnMerge,
 		RepoLocksMode:              projCfg.RepoLocks.Mode,
+		ApplyWindow:                projCfg.ApplyWindow,
+		CostThreshold:              projCfg.CostThreshold,
+		CostApprovers:              projCfg.CostApprovers,
+		DescriptionSections:        projCfg.DescriptionSections,
+		Environment:                projCfg.Environment,
+		AgentPool:                  projCfg.AgentPool,
+		ConcurrencyGroup:           projCfg.ConcurrencyGroup,
+		Owners:                     projCfg.Owners,
+		TFCWorkspace:               projCfg.TFCWorkspace,
+		Preview:                    projCfg.Preview,
 		CustomPolicyCheck:          projCfg.CustomPolicyCheck,
 		ParallelApplyEnabled:       parallelApplyEnabled,
 		ParallelPlanEnabled:        parallelPlanEnabled,
@@ -319,6 +339,7 @@ func newProjectCommandContext(ctx *command.Context,
 		ExecutionOrderGroup:        projCfg.ExecutionOrderGroup,
 		AbortOnExecutionOrderFail:  abortOnExecutionOrderFail,
 		SilencePRComments:          projCfg.SilencePRComments,
+		NoChangesPlanComments:      projCfg.NoChangesPlanComments,
 		TeamAllowlistChecker:       teamAllowlistChecker,
 	}
 }
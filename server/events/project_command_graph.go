@@ -0,0 +1,255 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// projectCmdGraph is the dependency graph of the projects of a command. A
+// project depends on the projects it lists in depends_on and on the projects
+// of lower execution order groups.
+type projectCmdGraph struct {
+	cmds []command.ProjectContext
+	// dependsOn are the indexes of the projects each project lists in
+	// depends_on.
+	dependsOn [][]int
+	// after are the indexes of the projects each project must run after
+	// because they're in a lower execution order group.
+	after [][]int
+	// stages are the indexes of the projects that can run at the same time,
+	// in the order they run.
+	stages [][]int
+}
+
+// hasProjectDependencies returns true if any of cmds depends on another of
+// cmds through depends_on.
+func hasProjectDependencies(cmds []command.ProjectContext) bool {
+	names := make(map[string]bool, len(cmds))
+	for _, cmd := range cmds {
+		if cmd.ProjectName != "" {
+			names[cmd.ProjectName] = true
+		}
+	}
+	for _, cmd := range cmds {
+		for _, dep := range cmd.DependsOn {
+			if names[dep] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// newProjectCmdGraph builds the dependency graph of cmds and splits it into
+// stages. Dependencies on projects that aren't part of cmds are ignored here,
+// ValidateProjectDependencies checks they were applied already. It returns an
+// error if the dependencies form a cycle.
+func newProjectCmdGraph(cmds []command.ProjectContext) (*projectCmdGraph, error) {
+	g := &projectCmdGraph{
+		cmds:      cmds,
+		dependsOn: make([][]int, len(cmds)),
+		after:     make([][]int, len(cmds)),
+	}
+	byName := make(map[string][]int)
+	for i, cmd := range cmds {
+		if cmd.ProjectName != "" {
+			byName[cmd.ProjectName] = append(byName[cmd.ProjectName], i)
+		}
+	}
+	for i, cmd := range cmds {
+		for _, dep := range cmd.DependsOn {
+			g.dependsOn[i] = append(g.dependsOn[i], byName[dep]...)
+		}
+		for j, other := range cmds {
+			if other.ExecutionOrderGroup < cmd.ExecutionOrderGroup {
+				g.after[i] = append(g.after[i], j)
+			}
+		}
+	}
+
+	// The stage of a project is one more than the highest stage of the
+	// projects it must run after.
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(cmds))
+	stage := make([]int, len(cmds))
+	var visit func(i int, path []int) error
+	visit = func(i int, path []int) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			var names []string
+			for _, j := range append(slices.Clone(path), i) {
+				names = append(names, projectCmdName(cmds[j]))
+			}
+			return fmt.Errorf("projects depend on each other: %s", strings.Join(names, " -> "))
+		}
+		state[i] = visiting
+		path = append(slices.Clone(path), i)
+		for _, j := range slices.Concat(g.dependsOn[i], g.after[i]) {
+			if err := visit(j, path); err != nil {
+				return err
+			}
+			stage[i] = max(stage[i], stage[j]+1)
+		}
+		state[i] = visited
+		return nil
+	}
+	for i := range cmds {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+		for len(g.stages) <= stage[i] {
+			g.stages = append(g.stages, nil)
+		}
+	}
+	for i := range cmds {
+		g.stages[stage[i]] = append(g.stages[stage[i]], i)
+	}
+	return g, nil
+}
+
+// blockedBy returns the project i can't run because of, ex. because it depends
+// on it and it failed, or -1 if i can run. A project is blocked by the
+// projects of lower execution order groups only if abort_on_execution_order_fail
+// is set.
+func (g *projectCmdGraph) blockedBy(i int, failed []bool) int {
+	for _, j := range g.dependsOn[i] {
+		if failed[j] {
+			return j
+		}
+	}
+	if g.cmds[i].AbortOnExecutionOrderFail {
+		for _, j := range g.after[i] {
+			if failed[j] {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// runProjectCmdsByDependencies runs projectCmds stage by stage so projects run
+// after the projects they depend on. The projects of a stage run in parallel
+// if isParallel. Projects that depend on a project that failed are skipped
+// while the projects that don't keep running.
+func runProjectCmdsByDependencies(
+	ctx *command.Context,
+	projectCmds []command.ProjectContext,
+	cancellationTracker CancellationTracker,
+	parallelPoolSize int,
+	isParallel bool,
+	runnerFunc prjCmdRunnerFunc,
+) command.Result {
+	g, err := newProjectCmdGraph(projectCmds)
+	if err != nil {
+		return command.Result{Error: err}
+	}
+	if cancellationTracker != nil {
+		defer cancellationTracker.Clear(ctx.Pull)
+	}
+
+	var results []command.ProjectResult
+	// failed contains the projects that failed or were skipped, their
+	// dependents are skipped too.
+	failed := make([]bool, len(projectCmds))
+	applied := make(map[string]bool)
+	for stageNum, stage := range g.stages {
+		if stageNum > 0 && cancellationTracker != nil && cancellationTracker.IsCancelled(ctx.Pull) {
+			ctx.Log.Info("Skipping stage %d and all subsequent stages due to cancellation", stageNum+1)
+			var remaining [][]command.ProjectContext
+			for _, rest := range g.stages[stageNum:] {
+				remaining = append(remaining, g.stageCmds(rest))
+			}
+			results = append(results, createCancelledResults(remaining)...)
+			break
+		}
+
+		var toRun []command.ProjectContext
+		for _, i := range stage {
+			if j := g.blockedBy(i, failed); j != -1 {
+				failed[i] = true
+				ctx.Log.Info("skipping %s since %s failed", projectCmdName(projectCmds[i]), projectCmdName(projectCmds[j]))
+				results = append(results, command.ProjectResult{
+					Command:     projectCmds[i].CommandName,
+					RepoRelDir:  projectCmds[i].RepoRelDir,
+					Workspace:   projectCmds[i].Workspace,
+					ProjectName: projectCmds[i].ProjectName,
+					Environment: projectCmds[i].Environment,
+					Stage:       stageNum + 1,
+					ProjectCommandOutput: command.ProjectCommandOutput{
+						Failure: fmt.Sprintf("Skipped because %s, which it runs after, failed.", projectCmdName(projectCmds[j])),
+					},
+				})
+				continue
+			}
+			cmd := projectCmds[i]
+			// The dependencies applied earlier in this command weren't
+			// applied yet when the command was built.
+			cmd.PullStatus = withAppliedProjects(cmd.PullStatus, applied)
+			toRun = append(toRun, cmd)
+		}
+
+		stageResult := runGroup(toRun, runnerFunc, isParallel, parallelPoolSize)
+		for _, res := range stageResult.ProjectResults {
+			res.Stage = stageNum + 1
+			results = append(results, res)
+			for _, i := range stage {
+				cmd := projectCmds[i]
+				if cmd.RepoRelDir != res.RepoRelDir || cmd.Workspace != res.Workspace || cmd.ProjectName != res.ProjectName {
+					continue
+				}
+				if res.IsSuccessful() {
+					applied[cmd.ProjectName] = true
+				} else {
+					failed[i] = true
+				}
+			}
+		}
+	}
+	return command.Result{ProjectResults: results}
+}
+
+func (g *projectCmdGraph) stageCmds(stage []int) []command.ProjectContext {
+	var cmds []command.ProjectContext
+	for _, i := range stage {
+		cmds = append(cmds, g.cmds[i])
+	}
+	return cmds
+}
+
+// withAppliedProjects returns a copy of pullStatus where the projects named in
+// applied are applied.
+func withAppliedProjects(pullStatus *models.PullStatus, applied map[string]bool) *models.PullStatus {
+	if pullStatus == nil || len(applied) == 0 {
+		return pullStatus
+	}
+	updated := *pullStatus
+	updated.Projects = make([]models.ProjectStatus, len(pullStatus.Projects))
+	for i, project := range pullStatus.Projects {
+		if applied[project.ProjectName] {
+			project.Status = models.AppliedPlanStatus
+		}
+		updated.Projects[i] = project
+	}
+	return &updated
+}
+
+// projectCmdName returns how cmd is referred to in logs and comments.
+func projectCmdName(cmd command.ProjectContext) string {
+	if cmd.ProjectName != "" {
+		return fmt.Sprintf("`%s`", cmd.ProjectName)
+	}
+	return fmt.Sprintf("dir: `%s` workspace: `%s`", cmd.RepoRelDir, cmd.Workspace)
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestNewProjectCmdGraph_Stages(t *testing.T) {
+	cases := map[string]struct {
+		cmds      []command.ProjectContext
+		expStages [][]string
+	}{
+		"independent projects share a stage": {
+			cmds: []command.ProjectContext{
+				{ProjectName: "a"},
+				{ProjectName: "b"},
+			},
+			expStages: [][]string{{"a", "b"}},
+		},
+		"dependents run after their dependencies": {
+			cmds: []command.ProjectContext{
+				{ProjectName: "production", DependsOn: []string{"staging"}},
+				{ProjectName: "staging", DependsOn: []string{"development"}},
+				{ProjectName: "qa", DependsOn: []string{"development"}},
+				{ProjectName: "development"},
+			},
+			expStages: [][]string{{"development"}, {"qa", "staging"}, {"production"}},
+		},
+		"dependencies that aren't run are ignored": {
+			cmds: []command.ProjectContext{
+				{ProjectName: "a", DependsOn: []string{"other"}},
+				{ProjectName: "b", DependsOn: []string{"a"}},
+			},
+			expStages: [][]string{{"a"}, {"b"}},
+		},
+		"execution order groups are respected": {
+			cmds: []command.ProjectContext{
+				{ProjectName: "a", ExecutionOrderGroup: 1},
+				{ProjectName: "b", ExecutionOrderGroup: 0},
+				{ProjectName: "c", ExecutionOrderGroup: 0, DependsOn: []string{"b"}},
+			},
+			expStages: [][]string{{"b"}, {"c"}, {"a"}},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			g, err := newProjectCmdGraph(c.cmds)
+			Ok(t, err)
+			var stages [][]string
+			for _, stage := range g.stages {
+				var names []string
+				for _, i := range stage {
+					names = append(names, c.cmds[i].ProjectName)
+				}
+				sort.Strings(names)
+				stages = append(stages, names)
+			}
+			Equals(t, c.expStages, stages)
+		})
+	}
+}
+
+func TestNewProjectCmdGraph_Cycle(t *testing.T) {
+	_, err := newProjectCmdGraph([]command.ProjectContext{
+		{ProjectName: "a", DependsOn: []string{"b"}},
+		{ProjectName: "b", DependsOn: []string{"a"}},
+	})
+	ErrEquals(t, "projects depend on each other: `a` -> `b` -> `a`", err)
+}
+
+func TestRunProjectCmdsByDependencies(t *testing.T) {
+	pullStatus := &models.PullStatus{Projects: []models.ProjectStatus{
+		{ProjectName: "development", Status: models.PlannedPlanStatus},
+		{ProjectName: "staging", Status: models.PlannedPlanStatus},
+		{ProjectName: "qa", Status: models.PlannedPlanStatus},
+		{ProjectName: "production", Status: models.PlannedPlanStatus},
+	}}
+	cmds := []command.ProjectContext{
+		{CommandName: command.Apply, ProjectName: "development", PullStatus: pullStatus},
+		{CommandName: command.Apply, ProjectName: "staging", DependsOn: []string{"development"}, PullStatus: pullStatus},
+		{CommandName: command.Apply, ProjectName: "qa", DependsOn: []string{"development"}, PullStatus: pullStatus},
+		{CommandName: command.Apply, ProjectName: "production", DependsOn: []string{"staging"}, PullStatus: pullStatus},
+	}
+	var ran []string
+	runner := func(ctx command.ProjectContext) command.ProjectCommandOutput {
+		ran = append(ran, ctx.ProjectName)
+		switch ctx.ProjectName {
+		case "staging":
+			return command.ProjectCommandOutput{Error: errors.New("apply failed")}
+		case "qa":
+			// The dependency applied in the previous stage is seen as applied.
+			for _, project := range ctx.PullStatus.Projects {
+				if project.ProjectName == "development" {
+					Equals(t, models.AppliedPlanStatus, project.Status)
+				}
+			}
+		}
+		return command.ProjectCommandOutput{ApplySuccess: "success"}
+	}
+	ctx := &command.Context{Log: logging.NewNoopLogger(t)}
+
+	result := runProjectCmdsByDependencies(ctx, cmds, nil, 1, false, runner)
+
+	Equals(t, []string{"development", "staging", "qa"}, ran)
+	Equals(t, 4, len(result.ProjectResults))
+	production := result.ProjectResults[3]
+	Equals(t, "production", production.ProjectName)
+	Equals(t, 3, production.Stage)
+	Equals(t, "Skipped because `staging`, which it runs after, failed.", production.Failure)
+	// The pull status of the command isn't modified.
+	Equals(t, models.PlannedPlanStatus, pullStatus.Projects[0].Status)
+}
@@ -21,13 +21,16 @@ func RunOneProjectCmd(
 	projectCommandOutput := runnerFunc(cmd)
 
 	return command.ProjectResult{
-		ProjectCommandOutput: projectCommandOutput,
-		Command:              cmd.CommandName,
-		SubCommand:           cmd.SubCommand,
-		RepoRelDir:           cmd.RepoRelDir,
-		Workspace:            cmd.Workspace,
-		ProjectName:          cmd.ProjectName,
-		SilencePRComments:    cmd.SilencePRComments,
+		ProjectCommandOutput:  projectCommandOutput,
+		Command:               cmd.CommandName,
+		SubCommand:            cmd.SubCommand,
+		RepoRelDir:            cmd.RepoRelDir,
+		Workspace:             cmd.Workspace,
+		ProjectName:           cmd.ProjectName,
+		SilencePRComments:     cmd.SilencePRComments,
+		NoChangesPlanComments: cmd.NoChangesPlanComments,
+		Environment:           cmd.Environment,
+		Owners:                cmd.Owners,
 	}
 }
 
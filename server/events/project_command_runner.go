@@ -20,9 +20,12 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
+	"github.com/runatlantis/atlantis/server/core/config/raw"
 	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/core/db"
 	"github.com/runatlantis/atlantis/server/core/runtime"
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
@@ -60,6 +63,12 @@ type StepRunner interface {
 	Run(ctx command.ProjectContext, extraArgs []string, path string, envs map[string]string) (string, error)
 }
 
+// ValidateStepRunner runs validate steps.
+type ValidateStepRunner interface {
+	// Run runs the step, failing on its findings if failFast is set.
+	Run(ctx command.ProjectContext, extraArgs []string, path string, envs map[string]string, failFast bool) (string, error)
+}
+
 //go:generate pegomock generate --package mocks -o mocks/mock_custom_step_runner.go CustomStepRunner
 
 // CustomStepRunner runs custom run steps.
@@ -181,6 +190,9 @@ type ProjectOutputWrapper struct {
 	ProjectCommandRunner
 	JobMessageSender JobMessageSender
 	JobURLSetter     JobURLSetter
+	// Journal, if set, records the pending project statuses so they're failed
+	// if Atlantis restarts while they're pending.
+	Journal *CommandJournal
 }
 
 func (p *ProjectOutputWrapper) Plan(ctx command.ProjectContext) command.ProjectCommandOutput {
@@ -202,6 +214,7 @@ func (p *ProjectOutputWrapper) updateProjectPRStatus(commandName command.Name, c
 	if err := p.JobURLSetter.SetJobURLWithStatus(ctx, commandName, models.PendingCommitStatus, nil); err != nil {
 		ctx.Log.Err("updating project PR status", err)
 	}
+	defer p.Journal.StartProject(ctx, commandName)()
 
 	// ensures we are differentiating between project level command and overall command
 	result := execute(ctx)
@@ -230,6 +243,9 @@ type DefaultProjectCommandRunner struct {
 	InitStepRunner            StepRunner
 	PlanStepRunner            StepRunner
 	ShowStepRunner            StepRunner
+	CostStepRunner            StepRunner
+	AuditStepRunner           StepRunner
+	ValidateStepRunner        ValidateStepRunner
 	ApplyStepRunner           StepRunner
 	CancelStepRunner          StepRunner
 	PolicyCheckStepRunner     StepRunner
@@ -245,21 +261,69 @@ type DefaultProjectCommandRunner struct {
 	WorkingDirLocker          WorkingDirLocker
 	CommandRequirementHandler CommandRequirementHandler
 	CancellationTracker       CancellationTracker
+	// ChangeRequests closes the change requests of projects with the
+	// change_request apply requirement after they're applied. It may be nil.
+	ChangeRequests ChangeRequestClient
+	// Deployments records applies as deployments. It may be nil.
+	Deployments DeploymentClient
+	// FixSuggestions comments suggested fixes for failed plans. It may be nil.
+	FixSuggestions FixSuggestionClient
+	// CodeScanning uploads policy check findings to code scanning. It may be
+	// nil.
+	CodeScanning CodeScanningClient
+	// DriftPullRequests opens pull requests remediating the drift plans
+	// outside of pull requests find. It may be nil.
+	DriftPullRequests DriftPullRequestClient
+	// PlanfileEncryptor encrypts planfiles at rest. Nil if planfiles aren't
+	// encrypted.
+	PlanfileEncryptor *runtime.PlanfileEncryptor
+	// PlanfileSigner signs planfiles when they're generated and verifies them
+	// before they're applied. Nil if planfiles aren't signed.
+	PlanfileSigner *runtime.PlanfileSigner
+	// StateLocks tracks the Terraform state locks plans and applies fail on.
+	// It may be nil.
+	StateLocks *StateLockTracker
+	// ProjectStatuses records the last plan and apply of each project for the
+	// projects page. It may be nil.
+	ProjectStatuses *ProjectStatusTracker
+	// PlanJSONs stores the JSON representation of plans so they can be
+	// fetched through the API. It may be nil.
+	PlanJSONs *PlanJSONStore
+	// PlanGraphs is true if the resources plans change are graphed in the
+	// plan comments.
+	PlanGraphs bool
+	// ResourceChanges records the changes applies make to resources. It may
+	// be nil.
+	ResourceChanges db.Database
+	// ApplyReports records a signed report of each apply. It may be nil.
+	ApplyReports *ApplyReports
+	// ReuseUnchangedPlans is true if the content plans are generated from is
+	// recorded so that plans can be reused while it doesn't change.
+	ReuseUnchangedPlans bool
+	// Freezes, if set, blocks the applies of projects during change freezes.
+	Freezes *ChangeFreezes
 }
 
 // Plan runs terraform plan for the project described by ctx.
 func (p *DefaultProjectCommandRunner) Plan(ctx command.ProjectContext) command.ProjectCommandOutput {
+	p.sendWebhook(ctx, webhooks.PlanStartedEvent, true, "")
 	planSuccess, failure, err := p.doPlan(ctx)
+	p.sendPlanWebhooks(ctx, planSuccess, failure, err)
+	p.ProjectStatuses.RecordPlan(ctx, planSuccess, failure, err)
 	return command.ProjectCommandOutput{
 		PlanSuccess: planSuccess,
 		Error:       err,
 		Failure:     failure,
+		StateLock:   trackStateLock(p.StateLocks, ctx, failure, err),
 	}
 }
 
 // PolicyCheck evaluates policies defined with Rego for the project described by ctx.
 func (p *DefaultProjectCommandRunner) PolicyCheck(ctx command.ProjectContext) command.ProjectCommandOutput {
 	policySuccess, failure, err := p.doPolicyCheck(ctx)
+	if err != nil || failure != "" || (policySuccess != nil && !policySuccess.PolicyCleared()) {
+		p.sendWebhook(ctx, webhooks.PolicyCheckEvent, false, failure)
+	}
 	return command.ProjectCommandOutput{
 		PolicyCheckResults: policySuccess,
 		Error:              err,
@@ -270,10 +334,12 @@ func (p *DefaultProjectCommandRunner) PolicyCheck(ctx command.ProjectContext) co
 // Apply runs terraform apply for the project described by ctx.
 func (p *DefaultProjectCommandRunner) Apply(ctx command.ProjectContext) command.ProjectCommandOutput {
 	applyOut, failure, err := p.doApply(ctx)
+	p.ProjectStatuses.RecordApply(ctx, failure, err)
 	return command.ProjectCommandOutput{
 		Failure:      failure,
 		Error:        err,
 		ApplySuccess: applyOut,
+		StateLock:    trackStateLock(p.StateLocks, ctx, failure, err),
 	}
 }
 
@@ -463,6 +529,12 @@ func (p *DefaultProjectCommandRunner) doPolicyCheck(ctx command.ProjectContext)
 		return nil, "", DirNotExistErr{RepoRelDir: ctx.RepoRelDir}
 	}
 
+	reencrypt, err := p.decryptPlanfile(ctx, absPath)
+	if err != nil {
+		return nil, "", err
+	}
+	defer reencrypt()
+
 	var failure string
 	outputs, err := p.runSteps(ctx.Steps, ctx, absPath)
 	var errs error
@@ -575,6 +647,7 @@ func (p *DefaultProjectCommandRunner) doPolicyCheck(ctx command.ProjectContext)
 		ApplyCmd:           ctx.ApplyCmd,
 		ApprovePoliciesCmd: ctx.ApprovePoliciesCmd,
 	}
+	uploadSarif(p.CodeScanning, ctx, repoDir, absPath, policySetResults)
 
 	// Using this function instead of catching failed policy runs with errors, for cases when '--no-fail' is passed to conftest.
 	// One reason to pass such an arg to conftest would be to prevent workflow termination so custom run scripts
@@ -634,22 +707,86 @@ func (p *DefaultProjectCommandRunner) doPlan(ctx command.ProjectContext) (*model
 		return nil, failure, err
 	}
 
+	if ctx.ReuseUnchangedPlan && p.planfileSigned(ctx, projAbsPath) {
+		if reused := reusablePlan(ctx, p.PlanfileEncryptor, repoDir, projAbsPath); reused != nil {
+			ctx.Log.Info("reusing the existing plan, the project didn't change since it was generated")
+			reused.LockURL = p.LockURLGenerator.GenerateLockURL(lockAttempt.LockKey)
+			reused.RePlanCmd = ctx.RePlanCmd
+			reused.ApplyCmd = ctx.ApplyCmd
+			reused.MergedAgain = mergedAgain
+			return reused, "", nil
+		}
+	}
+
+	// The cost estimate and audit of a previous plan mustn't be mistaken for
+	// this one's.
+	if err := os.Remove(filepath.Join(projAbsPath, ctx.GetCostFileName())); err != nil && !os.IsNotExist(err) {
+		ctx.Log.Warn("unable to remove previous cost estimate: %s", err)
+	}
+	if err := os.Remove(filepath.Join(projAbsPath, ctx.GetAuditFileName())); err != nil && !os.IsNotExist(err) {
+		ctx.Log.Warn("unable to remove previous audit findings: %s", err)
+	}
+
 	outputs, err := p.runSteps(ctx.Steps, ctx, projAbsPath)
 
 	if err != nil {
 		if unlockErr := lockAttempt.UnlockFn(); unlockErr != nil {
 			ctx.Log.Err("error unlocking state after plan error: %v", unlockErr)
 		}
-		return nil, "", fmt.Errorf("%s\n%s", err, strings.Join(outputs, "\n"))
+		err = fmt.Errorf("%s\n%s", err, strings.Join(outputs, "\n"))
+		suggestFix(p.FixSuggestions, ctx, repoDir, err.Error())
+		// Steps after the plan step may have failed, leaving a planfile.
+		if signErr := p.signPlanfile(ctx, projAbsPath); signErr != nil {
+			ctx.Log.Err("%s", signErr)
+		}
+		if encryptErr := p.encryptPlanfile(ctx, projAbsPath); encryptErr != nil {
+			ctx.Log.Err("%s", encryptErr)
+		}
+		return nil, "", err
 	}
 
-	return &models.PlanSuccess{
+	show := p.showPlan(ctx, projAbsPath)
+	planJSON := planJSONForSummary(ctx, show)
+	if p.PlanJSONs != nil && show != "" {
+		if err := p.PlanJSONs.Save(ctx, show); err != nil {
+			ctx.Log.Warn("unable to store plan json: %s", err)
+		}
+	}
+	if err := p.signPlanfile(ctx, projAbsPath); err != nil {
+		return nil, "", err
+	}
+	if err := p.encryptPlanfile(ctx, projAbsPath); err != nil {
+		return nil, "", err
+	}
+	terraformOutput := strings.Join(outputs, "\n")
+	planGraph := planGraphFor(ctx, show)
+	saved := savedPlanOutput{TerraformOutput: terraformOutput, PlanJSON: planJSON, PlanGraph: planGraph}
+	if p.ReuseUnchangedPlans {
+		if saved.ContentKey, err = planContentKey(ctx, repoDir, projAbsPath); err != nil {
+			ctx.Log.Warn("unable to identify the content of the plan, it won't be reused: %s", err)
+		}
+	}
+	if slices.Contains(ctx.ApplyRequirements, raw.FreshRequirement) {
+		// Without it the fresh requirement rejects applying the plan.
+		if saved.BaseCommit, err = p.WorkingDir.GetBaseCommit(ctx.Log, repoDir, ctx.Pull); err != nil {
+			ctx.Log.Warn("unable to get the base commit of the plan: %s", err)
+		}
+	}
+	savePlanOutput(ctx, p.PlanfileEncryptor, projAbsPath, saved)
+
+	planSuccess := &models.PlanSuccess{
 		LockURL:         p.LockURLGenerator.GenerateLockURL(lockAttempt.LockKey),
-		TerraformOutput: strings.Join(outputs, "\n"),
+		TerraformOutput: terraformOutput,
 		RePlanCmd:       ctx.RePlanCmd,
 		ApplyCmd:        ctx.ApplyCmd,
 		MergedAgain:     mergedAgain,
-	}, "", nil
+		PlanJSON:        planJSON,
+		PlanGraph:       planGraph,
+		Cost:            costEstimateFor(ctx, projAbsPath),
+		Audit:           auditReportFor(ctx, projAbsPath),
+	}
+	openDriftPullRequest(p.DriftPullRequests, ctx, projAbsPath, planSuccess, show)
+	return planSuccess, "", nil
 }
 
 func (p *DefaultProjectCommandRunner) doApply(ctx command.ProjectContext) (applyOut string, failure string, err error) {
@@ -675,6 +812,12 @@ func (p *DefaultProjectCommandRunner) doApply(ctx command.ProjectContext) (apply
 		return "", failure, err
 	}
 
+	if p.Freezes != nil {
+		if failure := p.Freezes.projectFailure(ctx); failure != "" {
+			return "", failure, nil
+		}
+	}
+
 	// Acquire Atlantis lock for this repo/dir/workspace.
 	lockAttempt, err := p.Locker.TryLock(ctx.Log, ctx.Pull, ctx.User, ctx.Workspace, models.NewProject(ctx.Pull.BaseRepo.FullName, ctx.RepoRelDir, ctx.ProjectName), ctx.RepoLocksMode == valid.RepoLocksOnApplyMode)
 	if err != nil {
@@ -692,23 +835,77 @@ func (p *DefaultProjectCommandRunner) doApply(ctx command.ProjectContext) (apply
 	}
 	defer unlockFn()
 
+	reencrypt, err := p.decryptPlanfile(ctx, absPath)
+	if err != nil {
+		return "", "", err
+	}
+	defer reencrypt()
+	if err := p.verifyPlanfile(ctx, absPath); err != nil {
+		return "", "", err
+	}
+
+	resourceChanges := p.plannedResourceChanges(ctx, absPath)
+	var planSHA256 string
+	if p.ApplyReports != nil {
+		planSHA256 = planfileSHA256(ctx, absPath)
+	}
+	deploymentID := startDeployment(p.Deployments, ctx)
 	outputs, err := p.runSteps(ctx.Steps, ctx, absPath)
+	finishDeployment(p.Deployments, ctx, deploymentID, err == nil)
+	if p.ApplyReports != nil {
+		p.ApplyReports.Record(ctx, planSHA256, resourceChanges, err == nil)
+	}
 
+	p.sendWebhook(ctx, webhooks.ApplyEvent, err == nil, "")
+
+	if p.ChangeRequests != nil && slices.Contains(ctx.ApplyRequirements, raw.ChangeRequestRequirement) {
+		if crErr := closeChangeRequest(p.ChangeRequests, ctx, err == nil, strings.Join(outputs, "\n")); crErr != nil {
+			ctx.Log.Warn("unable to close change request: %s", crErr)
+		}
+	}
+
+	if err != nil {
+		return "", "", fmt.Errorf("%s\n%s", err, strings.Join(outputs, "\n"))
+	}
+	p.recordResourceChanges(ctx, resourceChanges)
+
+	return strings.Join(outputs, "\n"), "", nil
+}
+
+// sendPlanWebhooks sends the plan event and, for plans that weren't run for a
+// pull request (ex. scheduled plans through the API), the drift event if the
+// plan has changes.
+func (p *DefaultProjectCommandRunner) sendPlanWebhooks(ctx command.ProjectContext, planSuccess *models.PlanSuccess, failure string, err error) {
+	if planSuccess == nil {
+		summary := failure
+		if err != nil {
+			summary = err.Error()
+		}
+		p.sendWebhook(ctx, webhooks.PlanEvent, false, summary)
+		return
+	}
+	p.sendWebhook(ctx, webhooks.PlanEvent, true, planSuccess.DiffSummary())
+	if ctx.Pull.Num == 0 && !planSuccess.NoChanges() {
+		p.sendWebhook(ctx, webhooks.DriftEvent, true, planSuccess.DiffSummary())
+	}
+}
+
+func (p *DefaultProjectCommandRunner) sendWebhook(ctx command.ProjectContext, event string, success bool, summary string) {
+	if p.Webhooks == nil {
+		return
+	}
 	p.Webhooks.Send(ctx.Log, webhooks.ApplyResult{ // nolint: errcheck
+		Event:       event,
 		Workspace:   ctx.Workspace,
 		User:        ctx.User,
 		Repo:        ctx.Pull.BaseRepo,
 		Pull:        ctx.Pull,
-		Success:     err == nil,
+		Success:     success,
 		Directory:   ctx.RepoRelDir,
 		ProjectName: ctx.ProjectName,
+		Environment: ctx.Environment,
+		Summary:     summary,
 	})
-
-	if err != nil {
-		return "", "", fmt.Errorf("%s\n%s", err, strings.Join(outputs, "\n"))
-	}
-
-	return strings.Join(outputs, "\n"), "", nil
 }
 
 func (p *DefaultProjectCommandRunner) doVersion(ctx command.ProjectContext) (versionOut string, failure string, err error) {
@@ -840,6 +1037,12 @@ func (p *DefaultProjectCommandRunner) runSteps(steps []valid.Step, ctx command.P
 			out, err = p.PlanStepRunner.Run(ctx, step.ExtraArgs, absPath, envs)
 		case "show":
 			_, err = p.ShowStepRunner.Run(ctx, step.ExtraArgs, absPath, envs)
+		case "cost":
+			_, err = p.CostStepRunner.Run(ctx, step.ExtraArgs, absPath, envs)
+		case "audit":
+			_, err = p.AuditStepRunner.Run(ctx, step.ExtraArgs, absPath, envs)
+		case "validate":
+			out, err = p.ValidateStepRunner.Run(ctx, step.ExtraArgs, absPath, envs, step.FailFast)
 		case "policy_check":
 			out, err = p.PolicyCheckStepRunner.Run(ctx, step.ExtraArgs, absPath, envs)
 		case "apply":
@@ -882,3 +1085,69 @@ func getMissingPolicySetNames(policySets []valid.PolicySet, receivedCount int) [
 	}
 	return missing
 }
+
+// signPlanfile signs the planfile of the project described by ctx if
+// planfiles are signed. It must be signed before it's encrypted.
+func (p *DefaultProjectCommandRunner) signPlanfile(ctx command.ProjectContext, absPath string) error {
+	if p.PlanfileSigner == nil {
+		return nil
+	}
+	if err := p.PlanfileSigner.Sign(filepath.Join(absPath, runtime.GetPlanFilename(ctx.Workspace, ctx.ProjectName))); err != nil {
+		return fmt.Errorf("signing planfile: %w", err)
+	}
+	return nil
+}
+
+// planfileSigned returns whether the planfile of the project described by
+// ctx is signed, or planfiles aren't signed. Plans generated before signing
+// was enabled mustn't be reused since they can't be applied.
+func (p *DefaultProjectCommandRunner) planfileSigned(ctx command.ProjectContext, absPath string) bool {
+	if p.PlanfileSigner == nil {
+		return true
+	}
+	_, err := os.Stat(runtime.PlanfileSignaturePath(filepath.Join(absPath, runtime.GetPlanFilename(ctx.Workspace, ctx.ProjectName))))
+	return err == nil
+}
+
+// verifyPlanfile verifies the signature of the decrypted planfile of the
+// project described by ctx if planfiles are signed.
+func (p *DefaultProjectCommandRunner) verifyPlanfile(ctx command.ProjectContext, absPath string) error {
+	if p.PlanfileSigner == nil {
+		return nil
+	}
+	if err := p.PlanfileSigner.Verify(filepath.Join(absPath, runtime.GetPlanFilename(ctx.Workspace, ctx.ProjectName))); err != nil {
+		return fmt.Errorf("verifying planfile: %w", err)
+	}
+	return nil
+}
+
+// encryptPlanfile encrypts the planfile of the project described by ctx if
+// planfiles are encrypted at rest.
+func (p *DefaultProjectCommandRunner) encryptPlanfile(ctx command.ProjectContext, absPath string) error {
+	if p.PlanfileEncryptor == nil {
+		return nil
+	}
+	if err := p.PlanfileEncryptor.Encrypt(filepath.Join(absPath, runtime.GetPlanFilename(ctx.Workspace, ctx.ProjectName))); err != nil {
+		return fmt.Errorf("encrypting planfile: %w", err)
+	}
+	return nil
+}
+
+// decryptPlanfile decrypts the planfile of the project described by ctx for
+// the duration of a command that needs it. The returned function encrypts it
+// again, it's a no-op if the command deleted the planfile, ex. after a
+// successful apply.
+func (p *DefaultProjectCommandRunner) decryptPlanfile(ctx command.ProjectContext, absPath string) (func(), error) {
+	if p.PlanfileEncryptor == nil {
+		return func() {}, nil
+	}
+	planPath := filepath.Join(absPath, runtime.GetPlanFilename(ctx.Workspace, ctx.ProjectName))
+	if err := p.PlanfileEncryptor.Decrypt(planPath); err != nil {
+		return nil, err
+	}
+	return func() {
+		if err := p.PlanfileEncryptor.Encrypt(planPath); err != nil {
+			ctx.Log.Err("unable to encrypt planfile: %s", err)
+		}
+	}, nil
+}
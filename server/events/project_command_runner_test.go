@@ -17,12 +17,16 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-version"
 	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/core/config/raw"
 	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/core/servicenow"
 	"github.com/runatlantis/atlantis/server/core/terraform"
 	tmocks "github.com/runatlantis/atlantis/server/core/terraform/mocks"
 	tfclientmocks "github.com/runatlantis/atlantis/server/core/terraform/tfclient/mocks"
@@ -125,6 +129,191 @@ func TestDefaultProjectCommandRunner_Plan(t *testing.T) {
 	}
 }
 
+func TestDefaultProjectCommandRunner_PlanJSONForSummary(t *testing.T) {
+	show := `{
+  "format_version": "1.2",
+  "resource_changes": [
+    {"address": "aws_instance.web", "change": {"actions": ["update"], "before": {"ami": "ami-1", "tags": {"a": "b"}}, "after": {"ami": "ami-2", "tags": {"a": "b"}}}},
+    {"address": "aws_s3_bucket.logs", "change": {"actions": ["delete", "create"], "before": {"bucket": "logs"}, "after": {"bucket": "logs-2"}}},
+    {"address": "aws_iam_role.ci", "change": {"actions": ["no-op"], "before": {}, "after": {}}},
+    {"address": "data.aws_ami.ubuntu", "change": {"actions": ["read"], "before": null, "after": {}}}
+  ]
+}`
+	cases := []struct {
+		name    string
+		enabled bool
+		showOut string
+		showErr error
+		expJSON string
+	}{
+		{
+			name:    "enabled",
+			enabled: true,
+			showOut: show,
+			expJSON: `{"resource_changes":[{"address":"aws_instance.web","actions":["update"],"changed_attributes":["ami"]},{"address":"aws_s3_bucket.logs","actions":["delete","create"]}]}`,
+		},
+		{
+			name:    "disabled",
+			showOut: show,
+		},
+		{
+			name:    "show fails",
+			enabled: true,
+			showErr: errors.New("show failed"),
+		},
+		{
+			name:    "invalid json",
+			enabled: true,
+			showOut: "not json",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			if c.enabled {
+				t.Setenv("TERRAFORM_PLAN_SUMMARIZER_JSON", "true")
+			}
+			mockPlan := mocks.NewMockStepRunner()
+			mockShow := mocks.NewMockStepRunner()
+			mockWorkingDir := mocks.NewMockWorkingDir()
+			mockLocker := mocks.NewMockProjectLocker()
+			runner := events.DefaultProjectCommandRunner{
+				Locker:                    mockLocker,
+				LockURLGenerator:          mockURLGenerator{},
+				PlanStepRunner:            mockPlan,
+				ShowStepRunner:            mockShow,
+				WorkingDir:                mockWorkingDir,
+				WorkingDirLocker:          events.NewDefaultWorkingDirLocker(),
+				CommandRequirementHandler: mocks.NewMockCommandRequirementHandler(),
+			}
+			repoDir := t.TempDir()
+			When(mockWorkingDir.Clone(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest](),
+				Any[string]())).ThenReturn(repoDir, nil)
+			When(mockLocker.TryLock(Any[logging.SimpleLogging](), Any[models.PullRequest](), Any[models.User](), Any[string](),
+				Any[models.Project](), AnyBool())).ThenReturn(&events.TryLockResponse{LockAcquired: true, LockKey: "lock-key"}, nil)
+
+			ctx := command.ProjectContext{
+				Log:        logging.NewNoopLogger(t),
+				Steps:      []valid.Step{{StepName: "plan"}},
+				Workspace:  "default",
+				RepoRelDir: ".",
+			}
+			When(mockPlan.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn("plan", nil)
+			When(mockShow.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn(c.showOut, c.showErr)
+
+			res := runner.Plan(ctx)
+			Assert(t, res.PlanSuccess != nil, "exp plan success")
+			Equals(t, "plan", res.PlanSuccess.TerraformOutput)
+			Equals(t, c.expJSON, res.PlanSuccess.PlanJSON)
+		})
+	}
+}
+
+func TestDefaultProjectCommandRunner_PlanfileEncryption(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockPlan := mocks.NewMockStepRunner()
+	mockApply := mocks.NewMockStepRunner()
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockLocker := mocks.NewMockProjectLocker()
+	encryptor, err := runtime.NewPlanfileEncryptor("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	Ok(t, err)
+	runner := events.DefaultProjectCommandRunner{
+		Locker:                    mockLocker,
+		LockURLGenerator:          mockURLGenerator{},
+		PlanStepRunner:            mockPlan,
+		ApplyStepRunner:           mockApply,
+		WorkingDir:                mockWorkingDir,
+		WorkingDirLocker:          events.NewDefaultWorkingDirLocker(),
+		CommandRequirementHandler: mocks.NewMockCommandRequirementHandler(),
+		PlanfileEncryptor:         encryptor,
+	}
+	repoDir := t.TempDir()
+	planPath := filepath.Join(repoDir, "default.tfplan")
+	When(mockWorkingDir.Clone(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest](),
+		Any[string]())).ThenReturn(repoDir, nil)
+	When(mockWorkingDir.GetWorkingDir(Any[models.Repo](), Any[models.PullRequest](), Any[string]())).ThenReturn(repoDir, nil)
+	When(mockLocker.TryLock(Any[logging.SimpleLogging](), Any[models.PullRequest](), Any[models.User](), Any[string](),
+		Any[models.Project](), AnyBool())).ThenReturn(&events.TryLockResponse{LockAcquired: true, LockKey: "lock-key"}, nil)
+
+	ctx := command.ProjectContext{
+		Log:        logging.NewNoopLogger(t),
+		Steps:      []valid.Step{{StepName: "plan"}},
+		Workspace:  "default",
+		RepoRelDir: ".",
+	}
+	When(mockPlan.Run(ctx, nil, repoDir, map[string]string{})).Then(func(_ []Param) ReturnValues {
+		Ok(t, os.WriteFile(planPath, []byte("plan"), 0600))
+		return ReturnValues{"plan", nil}
+	})
+	res := runner.Plan(ctx)
+	Assert(t, res.PlanSuccess != nil, "exp plan success")
+	planfile, err := os.ReadFile(planPath)
+	Ok(t, err)
+	Assert(t, string(planfile) != "plan", "exp planfile to be encrypted after plan")
+
+	ctx.Steps = []valid.Step{{StepName: "apply"}}
+	var appliedPlanfile string
+	When(mockApply.Run(ctx, nil, repoDir, map[string]string{})).Then(func(_ []Param) ReturnValues {
+		contents, err := os.ReadFile(planPath)
+		Ok(t, err)
+		appliedPlanfile = string(contents)
+		return ReturnValues{"", errors.New("apply failed")}
+	})
+	runner.Apply(ctx)
+	Equals(t, "plan", appliedPlanfile)
+	planfile, err = os.ReadFile(planPath)
+	Ok(t, err)
+	Assert(t, string(planfile) != "plan", "exp planfile to be encrypted again after the apply failed")
+}
+
+func TestDefaultProjectCommandRunner_SignedPlanfile(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockPlan := mocks.NewMockStepRunner()
+	mockApply := mocks.NewMockStepRunner()
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockLocker := mocks.NewMockProjectLocker()
+	signer, err := runtime.NewPlanfileSigner("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	Ok(t, err)
+	runner := events.DefaultProjectCommandRunner{
+		Locker:                    mockLocker,
+		LockURLGenerator:          mockURLGenerator{},
+		PlanStepRunner:            mockPlan,
+		ApplyStepRunner:           mockApply,
+		WorkingDir:                mockWorkingDir,
+		WorkingDirLocker:          events.NewDefaultWorkingDirLocker(),
+		CommandRequirementHandler: mocks.NewMockCommandRequirementHandler(),
+		PlanfileSigner:            signer,
+	}
+	repoDir := t.TempDir()
+	planPath := filepath.Join(repoDir, "default.tfplan")
+	When(mockWorkingDir.Clone(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest](),
+		Any[string]())).ThenReturn(repoDir, nil)
+	When(mockWorkingDir.GetWorkingDir(Any[models.Repo](), Any[models.PullRequest](), Any[string]())).ThenReturn(repoDir, nil)
+	When(mockLocker.TryLock(Any[logging.SimpleLogging](), Any[models.PullRequest](), Any[models.User](), Any[string](),
+		Any[models.Project](), AnyBool())).ThenReturn(&events.TryLockResponse{LockAcquired: true, LockKey: "lock-key"}, nil)
+
+	ctx := command.ProjectContext{
+		Log:        logging.NewNoopLogger(t),
+		Steps:      []valid.Step{{StepName: "plan"}},
+		Workspace:  "default",
+		RepoRelDir: ".",
+	}
+	When(mockPlan.Run(ctx, nil, repoDir, map[string]string{})).Then(func(_ []Param) ReturnValues {
+		Ok(t, os.WriteFile(planPath, []byte("plan"), 0600))
+		return ReturnValues{"plan", nil}
+	})
+	res := runner.Plan(ctx)
+	Assert(t, res.PlanSuccess != nil, "exp plan success")
+	_, err = os.Stat(runtime.PlanfileSignaturePath(planPath))
+	Ok(t, err)
+
+	Ok(t, os.WriteFile(planPath, []byte("malicious plan"), 0600))
+	ctx.Steps = []valid.Step{{StepName: "apply"}}
+	res = runner.Apply(ctx)
+	ErrEquals(t, "verifying planfile: planfile signature is invalid, the planfile was modified after it was planned or signed with a different key", res.Error)
+	mockApply.VerifyWasCalled(Never()).Run(Any[command.ProjectContext](), Any[[]string](), Any[string](), Any[map[string]string]())
+}
+
 func TestProjectOutputWrapper(t *testing.T) {
 	RegisterMockTestingT(t)
 	ctx := command.ProjectContext{
@@ -543,8 +732,305 @@ func TestDefaultProjectCommandRunner_ApplyRunStepFailure(t *testing.T) {
 	mockApply.VerifyWasCalledOnce().Run(ctx, nil, repoDir, expEnvs)
 }
 
+func TestDefaultProjectCommandRunner_ApplyDuringFreeze(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockApply := mocks.NewMockStepRunner()
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockLocker := mocks.NewMockProjectLocker()
+	runner := events.DefaultProjectCommandRunner{
+		Locker:           mockLocker,
+		LockURLGenerator: mockURLGenerator{},
+		ApplyStepRunner:  mockApply,
+		WorkingDir:       mockWorkingDir,
+		WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+		CommandRequirementHandler: &events.DefaultCommandRequirementHandler{
+			WorkingDir: mockWorkingDir,
+		},
+		Freezes: &events.ChangeFreezes{GlobalCfg: valid.GlobalCfg{Freezes: []valid.Freeze{{
+			Name:     "holidays",
+			Windows:  []valid.FreezeWindow{{Start: time.Now().Add(-time.Hour), End: time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)}},
+			Projects: []string{"prod"},
+		}}}},
+	}
+	repoDir := t.TempDir()
+	When(mockWorkingDir.GetWorkingDir(Any[models.Repo](), Any[models.PullRequest](), Any[string]())).ThenReturn(repoDir, nil)
+	When(mockLocker.TryLock(Any[logging.SimpleLogging](), Any[models.PullRequest](), Any[models.User](), Any[string](), Any[models.Project](), AnyBool())).
+		ThenReturn(&events.TryLockResponse{LockAcquired: true, LockKey: "lock-key"}, nil)
+	When(mockApply.Run(Any[command.ProjectContext](), Any[[]string](), Eq(repoDir), Any[map[string]string]())).ThenReturn("applied", nil)
+
+	ctx := command.ProjectContext{
+		Log:         logging.NewNoopLogger(t),
+		Steps:       []valid.Step{{StepName: "apply"}},
+		Workspace:   "default",
+		RepoRelDir:  ".",
+		ProjectName: "prod",
+	}
+	t.Log("applies of frozen projects are blocked whichever way they're run, ex. through the API")
+	res := runner.Apply(ctx)
+	Equals(t, "Applying is blocked by these change freezes:\n\n* **holidays** until 2099-01-01 00:00 UTC\n  Frozen: `prod`", res.Failure)
+	mockApply.VerifyWasCalled(Never()).Run(Any[command.ProjectContext](), Any[[]string](), Any[string](), Any[map[string]string]())
+
+	t.Log("unless the glass was broken")
+	ctx.FreezesOverridden = true
+	res = runner.Apply(ctx)
+	Equals(t, "", res.Failure)
+	Equals(t, "applied", res.ApplySuccess)
+}
+
+func TestDefaultProjectCommandRunner_ApplyClosesChangeRequest(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockApply := mocks.NewMockStepRunner()
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockLocker := mocks.NewMockProjectLocker()
+	changeRequests := &fakeChangeRequests{cr: servicenow.ChangeRequest{Number: "CHG0001", Approval: "approved"}}
+	runner := events.DefaultProjectCommandRunner{
+		Locker:           mockLocker,
+		LockURLGenerator: mockURLGenerator{},
+		ApplyStepRunner:  mockApply,
+		WorkingDir:       mockWorkingDir,
+		WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+		CommandRequirementHandler: &events.DefaultCommandRequirementHandler{
+			WorkingDir:     mockWorkingDir,
+			ChangeRequests: changeRequests,
+		},
+		ChangeRequests: changeRequests,
+	}
+	repoDir := t.TempDir()
+	When(mockWorkingDir.GetWorkingDir(
+		Any[models.Repo](),
+		Any[models.PullRequest](),
+		Any[string](),
+	)).ThenReturn(repoDir, nil)
+	When(mockLocker.TryLock(
+		Any[logging.SimpleLogging](),
+		Any[models.PullRequest](),
+		Any[models.User](),
+		Any[string](),
+		Any[models.Project](),
+		AnyBool(),
+	)).ThenReturn(&events.TryLockResponse{
+		LockAcquired: true,
+		LockKey:      "lock-key",
+	}, nil)
+
+	ctx := command.ProjectContext{
+		Log: logging.NewNoopLogger(t),
+		Steps: []valid.Step{
+			{
+				StepName: "apply",
+			},
+		},
+		Workspace:         "default",
+		ApplyRequirements: []string{raw.ChangeRequestRequirement},
+		RepoRelDir:        ".",
+	}
+	When(mockApply.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn("applied", nil)
+
+	res := runner.Apply(ctx)
+	Equals(t, "applied", res.ApplySuccess)
+	Equals(t, []bool{true}, changeRequests.closed)
+	Equals(t, "applied", changeRequests.closeNotes)
+}
+
+// fakeDeployments is a DeploymentClient that records the deployments it
+// creates and their statuses.
+type fakeDeployments struct {
+	environments []string
+	states       []string
+}
+
+func (f *fakeDeployments) CreateDeployment(_ logging.SimpleLogging, _ models.Repo, _ string, environment string, _ string) (int64, error) {
+	f.environments = append(f.environments, environment)
+	return int64(len(f.environments)), nil
+}
+
+func (f *fakeDeployments) UpdateDeploymentStatus(_ logging.SimpleLogging, _ models.Repo, deploymentID int64, state string, _ string, _ string) error {
+	f.states = append(f.states, fmt.Sprintf("%d:%s", deploymentID, state))
+	return nil
+}
+
+func TestDefaultProjectCommandRunner_ApplyDeployments(t *testing.T) {
+	cases := []struct {
+		name      string
+		vcsHost   models.VCSHostType
+		applyErr  error
+		expEnvs   []string
+		expStates []string
+	}{
+		{
+			name:      "successful apply",
+			vcsHost:   models.Github,
+			expEnvs:   []string{"infra/staging"},
+			expStates: []string{"1:in_progress", "1:success"},
+		},
+		{
+			name:      "failed apply",
+			vcsHost:   models.Github,
+			applyErr:  errors.New("apply failed"),
+			expEnvs:   []string{"infra/staging"},
+			expStates: []string{"1:in_progress", "1:failure"},
+		},
+		{
+			name:    "not github",
+			vcsHost: models.Gitlab,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			mockApply := mocks.NewMockStepRunner()
+			mockWorkingDir := mocks.NewMockWorkingDir()
+			mockLocker := mocks.NewMockProjectLocker()
+			deployments := &fakeDeployments{}
+			runner := events.DefaultProjectCommandRunner{
+				Locker:           mockLocker,
+				LockURLGenerator: mockURLGenerator{},
+				ApplyStepRunner:  mockApply,
+				WorkingDir:       mockWorkingDir,
+				WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+				CommandRequirementHandler: &events.DefaultCommandRequirementHandler{
+					WorkingDir: mockWorkingDir,
+				},
+				Deployments: deployments,
+			}
+			repoDir := t.TempDir()
+			When(mockWorkingDir.GetWorkingDir(
+				Any[models.Repo](),
+				Any[models.PullRequest](),
+				Any[string](),
+			)).ThenReturn(repoDir, nil)
+			When(mockLocker.TryLock(
+				Any[logging.SimpleLogging](),
+				Any[models.PullRequest](),
+				Any[models.User](),
+				Any[string](),
+				Any[models.Project](),
+				AnyBool(),
+			)).ThenReturn(&events.TryLockResponse{
+				LockAcquired: true,
+				LockKey:      "lock-key",
+			}, nil)
+
+			ctx := command.ProjectContext{
+				Log: logging.NewNoopLogger(t),
+				Steps: []valid.Step{
+					{
+						StepName: "apply",
+					},
+				},
+				Pull:        models.PullRequest{BaseRepo: models.Repo{VCSHost: models.VCSHost{Type: c.vcsHost}}},
+				Workspace:   "staging",
+				ProjectName: "infra",
+				RepoRelDir:  ".",
+			}
+			When(mockApply.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn("output", c.applyErr)
+
+			runner.Apply(ctx)
+			Equals(t, c.expEnvs, deployments.environments)
+			Equals(t, c.expStates, deployments.states)
+		})
+	}
+}
+
 // Test run and env steps. We don't use mocks for this test since we're
 // not running any Terraform.
+// fakeFixSuggestions is a FixSuggestionClient that records the suggestions
+// it comments.
+type fakeFixSuggestions struct {
+	suggestions []string
+}
+
+func (f *fakeFixSuggestions) CreateSuggestion(_ logging.SimpleLogging, _ models.Repo, _ int, commitSHA string, path string, startLine int, endLine int, _ string, suggestion string) error {
+	f.suggestions = append(f.suggestions, fmt.Sprintf("%s:%s:%d-%d:%s", commitSHA, path, startLine, endLine, suggestion))
+	return nil
+}
+
+func TestDefaultProjectCommandRunner_PlanFixSuggestion(t *testing.T) {
+	mainTF := `resource "aws_instance" "web" {
+  instance_type = "t3.micro"
+  amii          = "ami-1"
+}
+`
+	cases := []struct {
+		name           string
+		planErr        string
+		expSuggestions []string
+	}{
+		{
+			name: "fixable error",
+			planErr: `╷
+│ Error: Unsupported argument
+│
+│   on main.tf line 3, in resource "aws_instance" "web":
+│    3:   amii          = "ami-1"
+│
+│ An argument named "amii" is not expected here. Did you mean "ami"?
+╵`,
+			expSuggestions: []string{`sha:infra/main.tf:3-3:  ami           = "ami-1"`},
+		},
+		{
+			name: "unfixable error",
+			planErr: `Error: No valid credential sources found
+
+  on providers.tf line 1, in provider "aws":`,
+		},
+		{
+			name: "outside the repo",
+			planErr: `Error: Unsupported argument
+
+  on ../../main.tf line 3, in resource "aws_instance" "web":`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			t.Setenv("TERRAFORM_PLAN_FIX_SUGGESTIONS", "true")
+			t.Setenv("TERRAFORM_PLAN_SUMMARIZER_COMMAND", `echo '{"start_line": 3, "end_line": 3, "replacement": "  ami           = \"ami-1\""}'`)
+			mockPlan := mocks.NewMockStepRunner()
+			mockWorkingDir := mocks.NewMockWorkingDir()
+			mockLocker := mocks.NewMockProjectLocker()
+			fixSuggestions := &fakeFixSuggestions{}
+			runner := events.DefaultProjectCommandRunner{
+				Locker:                    mockLocker,
+				LockURLGenerator:          mockURLGenerator{},
+				PlanStepRunner:            mockPlan,
+				WorkingDir:                mockWorkingDir,
+				WorkingDirLocker:          events.NewDefaultWorkingDirLocker(),
+				CommandRequirementHandler: mocks.NewMockCommandRequirementHandler(),
+				FixSuggestions:            fixSuggestions,
+			}
+			repoDir := t.TempDir()
+			Ok(t, os.MkdirAll(filepath.Join(repoDir, "infra"), 0700))
+			Ok(t, os.WriteFile(filepath.Join(repoDir, "infra", "main.tf"), []byte(mainTF), 0600))
+			When(mockWorkingDir.Clone(Any[logging.SimpleLogging](), Any[models.Repo](), Any[models.PullRequest](),
+				Any[string]())).ThenReturn(repoDir, nil)
+			When(mockLocker.TryLock(Any[logging.SimpleLogging](), Any[models.PullRequest](), Any[models.User](), Any[string](),
+				Any[models.Project](), AnyBool())).ThenReturn(&events.TryLockResponse{
+				LockAcquired: true,
+				LockKey:      "lock-key",
+				UnlockFn:     func() error { return nil },
+			}, nil)
+
+			ctx := command.ProjectContext{
+				Log:   logging.NewNoopLogger(t),
+				Steps: []valid.Step{{StepName: "plan"}},
+				Pull: models.PullRequest{
+					Num:        1,
+					HeadCommit: "sha",
+					BaseRepo:   models.Repo{VCSHost: models.VCSHost{Type: models.Github}},
+				},
+				Workspace:  "default",
+				RepoRelDir: "infra",
+			}
+			When(mockPlan.Run(ctx, nil, filepath.Join(repoDir, "infra"), map[string]string{})).ThenReturn("", errors.New(c.planErr))
+
+			res := runner.Plan(ctx)
+			Assert(t, res.Error != nil, "exp plan error")
+			Equals(t, c.expSuggestions, fixSuggestions.suggestions)
+		})
+	}
+}
+
 func TestDefaultProjectCommandRunner_RunEnvSteps(t *testing.T) {
 	RegisterMockTestingT(t)
 	tfClient := tfclientmocks.NewMockClient()
@@ -0,0 +1,276 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// ConcurrencyGroup limits how many terraform commands of the projects in the
+// group run at once, across repos.
+type ConcurrencyGroup struct {
+	// Name is the group repos are put in with concurrency_group in the
+	// server-side repo config.
+	Name string
+	// Limit is how many commands of the group run at once.
+	Limit int
+	// Command is the command limited, ex. apply, or empty if all the
+	// terraform commands are.
+	Command string
+}
+
+// ProjectCommandScheduler is a ProjectCommandRunner limiting how many
+// terraform commands run at once, in total, per repo and per concurrency
+// group. Commands over a limit wait, and run in the order they were queued
+// once their limits allow it.
+type ProjectCommandScheduler struct {
+	ProjectCommandRunner
+	// JobMessageSender, if set, reports in their job output that commands
+	// are waiting.
+	JobMessageSender JobMessageSender
+	// MaxConcurrent is how many terraform commands run at once. 0 means they
+	// aren't limited.
+	MaxConcurrent int
+	// MaxConcurrentPerRepo is how many terraform commands of each repo run at
+	// once. 0 means they aren't limited.
+	MaxConcurrentPerRepo int
+	Groups               []ConcurrencyGroup
+	// SharedSlots, if set, count the commands of the concurrency groups
+	// across the servers sharing them, ex. the workers, instead of on each
+	// server.
+	SharedSlots ConcurrencySlots
+
+	mutex   sync.Mutex
+	running map[string]int
+	waiting []*projectSlotRequest
+}
+
+// ConcurrencySlots are the slots of concurrency limits shared by servers, ex.
+// in the locking DB.
+type ConcurrencySlots interface {
+	// TryAcquireSlot takes one of the limit slots of key unless they're all
+	// held, returning a function releasing it and whether it was taken.
+	TryAcquireSlot(key string, limit int) (func(), bool, error)
+}
+
+// sharedSlotRetry is how often a full shared slot is tried again.
+var sharedSlotRetry = time.Second
+
+// projectSlot is a limit a command counts towards.
+type projectSlot struct {
+	key   string
+	limit int
+	// description describes the limit to the users waiting for it.
+	description string
+	// group is true for the limits of concurrency groups.
+	group bool
+}
+
+type projectSlotRequest struct {
+	slots   []projectSlot
+	granted chan struct{}
+}
+
+func (s *ProjectCommandScheduler) Plan(ctx command.ProjectContext) command.ProjectCommandOutput {
+	return s.run(ctx, s.ProjectCommandRunner.Plan)
+}
+
+func (s *ProjectCommandScheduler) Apply(ctx command.ProjectContext) command.ProjectCommandOutput {
+	return s.run(ctx, s.ProjectCommandRunner.Apply)
+}
+
+func (s *ProjectCommandScheduler) Import(ctx command.ProjectContext) command.ProjectCommandOutput {
+	return s.run(ctx, s.ProjectCommandRunner.Import)
+}
+
+func (s *ProjectCommandScheduler) StateRm(ctx command.ProjectContext) command.ProjectCommandOutput {
+	return s.run(ctx, s.ProjectCommandRunner.StateRm)
+}
+
+func (s *ProjectCommandScheduler) run(ctx command.ProjectContext, execute func(ctx command.ProjectContext) command.ProjectCommandOutput) command.ProjectCommandOutput {
+	defer s.acquire(ctx)()
+	return execute(ctx)
+}
+
+// slots returns the limits the command of ctx counts towards.
+func (s *ProjectCommandScheduler) slots(ctx command.ProjectContext) []projectSlot {
+	var slots []projectSlot
+	if s.MaxConcurrent > 0 {
+		slots = append(slots, projectSlot{
+			key:         "all",
+			limit:       s.MaxConcurrent,
+			description: fmt.Sprintf("the limit of %d concurrent projects", s.MaxConcurrent),
+		})
+	}
+	if s.MaxConcurrentPerRepo > 0 {
+		slots = append(slots, projectSlot{
+			key:         "repo " + ctx.BaseRepo.FullName,
+			limit:       s.MaxConcurrentPerRepo,
+			description: fmt.Sprintf("the limit of %d concurrent projects of %s", s.MaxConcurrentPerRepo, ctx.BaseRepo.FullName),
+		})
+	}
+	for _, group := range s.Groups {
+		if group.Name != ctx.ConcurrencyGroup || (group.Command != "" && group.Command != ctx.CommandName.String()) {
+			continue
+		}
+		commands := "terraform commands"
+		if group.Command != "" {
+			commands = group.Command + " commands"
+		}
+		slots = append(slots, projectSlot{
+			key:         fmt.Sprintf("group %s %s", group.Name, group.Command),
+			limit:       group.Limit,
+			description: fmt.Sprintf("the limit of %d concurrent %s of the %s concurrency group", group.Limit, commands, group.Name),
+			group:       true,
+		})
+	}
+	return slots
+}
+
+// acquire blocks until the command of ctx is within its limits, returning a
+// function releasing them once it completed.
+func (s *ProjectCommandScheduler) acquire(ctx command.ProjectContext) func() {
+	slots := s.slots(ctx)
+	var shared []projectSlot
+	if s.SharedSlots != nil {
+		shared = slices.DeleteFunc(slices.Clone(slots), func(slot projectSlot) bool { return !slot.group })
+		slots = slices.DeleteFunc(slots, func(slot projectSlot) bool { return slot.group })
+	}
+	release := s.acquireLocal(ctx, slots)
+	if len(shared) == 0 {
+		return release
+	}
+	releaseShared := s.acquireShared(ctx, shared)
+	return func() {
+		releaseShared()
+		release()
+	}
+}
+
+// acquireLocal blocks until the command of ctx is within the limits of slots
+// counted by this server.
+func (s *ProjectCommandScheduler) acquireLocal(ctx command.ProjectContext, slots []projectSlot) func() {
+	if len(slots) == 0 {
+		return func() {}
+	}
+	request := &projectSlotRequest{slots: slots, granted: make(chan struct{})}
+	s.mutex.Lock()
+	if s.running == nil {
+		s.running = map[string]int{}
+	}
+	s.waiting = append(s.waiting, request)
+	s.grant()
+	full, _ := s.fullSlot(slots)
+	s.mutex.Unlock()
+	release := func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		for _, slot := range slots {
+			s.running[slot.key]--
+			if s.running[slot.key] == 0 {
+				delete(s.running, slot.key)
+			}
+		}
+		s.grant()
+	}
+
+	select {
+	case <-request.granted:
+		return release
+	default:
+	}
+	s.reportWaiting(ctx, full)
+	start := time.Now()
+	<-request.granted
+	ctx.Log.Info("running after waiting %s", time.Since(start).Round(time.Millisecond))
+	return release
+}
+
+// acquireShared blocks until the command of ctx holds one of the shared slots
+// of each of slots.
+func (s *ProjectCommandScheduler) acquireShared(ctx command.ProjectContext, slots []projectSlot) func() {
+	var start time.Time
+	for {
+		release, full, err := s.tryAcquireShared(slots)
+		if release != nil {
+			if !start.IsZero() {
+				ctx.Log.Info("running after waiting %s", time.Since(start).Round(time.Millisecond))
+			}
+			return release
+		}
+		if err != nil {
+			// The command waits until the slots can be acquired again rather
+			// than running over the limit.
+			ctx.Log.Warn("unable to acquire a slot of %s, retrying: %s", full.description, err)
+		} else if start.IsZero() {
+			s.reportWaiting(ctx, full)
+		}
+		if start.IsZero() {
+			start = time.Now()
+		}
+		time.Sleep(sharedSlotRetry)
+	}
+}
+
+// tryAcquireShared takes one of the shared slots of each of slots, returning
+// a function releasing them, or the first one that's full. The slots taken
+// before a full one are released so the other servers aren't blocked while
+// the command waits.
+func (s *ProjectCommandScheduler) tryAcquireShared(slots []projectSlot) (func(), projectSlot, error) {
+	var releases []func()
+	releaseAll := func() {
+		for _, release := range releases {
+			release()
+		}
+	}
+	for _, slot := range slots {
+		release, acquired, err := s.SharedSlots.TryAcquireSlot(slot.key, slot.limit)
+		if err != nil || !acquired {
+			releaseAll()
+			return nil, slot, err
+		}
+		releases = append(releases, release)
+	}
+	return releaseAll, projectSlot{}, nil
+}
+
+// reportWaiting reports that the command of ctx waits since the limit of slot
+// is reached.
+func (s *ProjectCommandScheduler) reportWaiting(ctx command.ProjectContext, slot projectSlot) {
+	ctx.Log.Info("waiting to run since %s is reached", slot.description)
+	if s.JobMessageSender != nil {
+		s.JobMessageSender.Send(ctx, fmt.Sprintf("Waiting to run since %s is reached.", slot.description), false)
+	}
+}
+
+// grant grants the waiting requests within their limits, in the order they
+// were queued. A request waiting for a limit doesn't delay the requests that
+// don't count towards it. It's called with the mutex locked.
+func (s *ProjectCommandScheduler) grant() {
+	s.waiting = slices.DeleteFunc(s.waiting, func(request *projectSlotRequest) bool {
+		if _, isFull := s.fullSlot(request.slots); isFull {
+			return false
+		}
+		for _, slot := range request.slots {
+			s.running[slot.key]++
+		}
+		close(request.granted)
+		return true
+	})
+}
+
+// fullSlot returns the first of slots that's full, if any.
+func (s *ProjectCommandScheduler) fullSlot(slots []projectSlot) (projectSlot, bool) {
+	for _, slot := range slots {
+		if s.running[slot.key] >= slot.limit {
+			return slot, true
+		}
+	}
+	return projectSlot{}, false
+}
@@ -0,0 +1,167 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// blockingProjectCommandRunner reports the projects it starts running and
+// blocks them until their done channel is closed.
+type blockingProjectCommandRunner struct {
+	ProjectCommandRunner
+	started chan string
+	done    map[string]chan struct{}
+}
+
+func (b *blockingProjectCommandRunner) Plan(ctx command.ProjectContext) command.ProjectCommandOutput {
+	b.started <- ctx.ProjectName
+	<-b.done[ctx.ProjectName]
+	return command.ProjectCommandOutput{}
+}
+
+func (b *blockingProjectCommandRunner) Apply(ctx command.ProjectContext) command.ProjectCommandOutput {
+	return b.Plan(ctx)
+}
+
+type recordingJobMessageSender struct {
+	mutex    sync.Mutex
+	messages []string
+}
+
+func (r *recordingJobMessageSender) Send(_ command.ProjectContext, msg string, _ bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.messages = append(r.messages, msg)
+}
+
+func TestProjectCommandScheduler(t *testing.T) {
+	runner := &blockingProjectCommandRunner{started: make(chan string), done: map[string]chan struct{}{}}
+	for _, name := range []string{"a-apply", "b-apply", "b-plan"} {
+		runner.done[name] = make(chan struct{})
+	}
+	messages := &recordingJobMessageSender{}
+	scheduler := &ProjectCommandScheduler{
+		ProjectCommandRunner: runner,
+		JobMessageSender:     messages,
+		MaxConcurrentPerRepo: 1,
+		Groups:               []ConcurrencyGroup{{Name: "prod", Limit: 1, Command: "apply"}},
+	}
+	run := func(name string, repo string, cmdName command.Name) {
+		ctx := command.ProjectContext{
+			ProjectName:      name,
+			BaseRepo:         models.Repo{FullName: repo},
+			CommandName:      cmdName,
+			ConcurrencyGroup: "prod",
+			Log:              logging.NewNoopLogger(t),
+		}
+		if cmdName == command.Apply {
+			go scheduler.Apply(ctx)
+		} else {
+			go scheduler.Plan(ctx)
+		}
+	}
+	waiting := func() int {
+		scheduler.mutex.Lock()
+		defer scheduler.mutex.Unlock()
+		return len(scheduler.waiting)
+	}
+
+	run("a-apply", "owner/a", command.Apply)
+	Equals(t, "a-apply", <-runner.started)
+
+	t.Log("applies of the group wait for the running one")
+	run("b-apply", "owner/b", command.Apply)
+	Assert(t, eventually(func() bool { return waiting() == 1 }), "exp the apply to wait")
+
+	t.Log("plans of the group aren't limited by it, nor delayed by the waiting apply")
+	run("b-plan", "owner/b", command.Plan)
+	Equals(t, "b-plan", <-runner.started)
+
+	t.Log("the waiting apply is still limited by its repo")
+	close(runner.done["a-apply"])
+	Assert(t, eventually(func() bool {
+		scheduler.mutex.Lock()
+		defer scheduler.mutex.Unlock()
+		return scheduler.running["group prod apply"] == 0
+	}), "exp the apply to release the group")
+	Equals(t, 1, waiting())
+
+	close(runner.done["b-plan"])
+	Equals(t, "b-apply", <-runner.started)
+	close(runner.done["b-apply"])
+	Assert(t, eventually(func() bool {
+		scheduler.mutex.Lock()
+		defer scheduler.mutex.Unlock()
+		return len(scheduler.running) == 0
+	}), "exp every command to release its limits")
+	messages.mutex.Lock()
+	defer messages.mutex.Unlock()
+	Equals(t, []string{"Waiting to run since the limit of 1 concurrent apply commands of the prod concurrency group is reached."}, messages.messages)
+}
+
+// memorySlots are ConcurrencySlots in memory, ex. as shared by other servers.
+type memorySlots struct {
+	mutex sync.Mutex
+	held  map[string]int
+}
+
+func (m *memorySlots) TryAcquireSlot(key string, limit int) (func(), bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.held[key] >= limit {
+		return nil, false, nil
+	}
+	m.held[key]++
+	return func() {
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		m.held[key]--
+	}, true, nil
+}
+
+func TestProjectCommandScheduler_SharedSlots(t *testing.T) {
+	defer func(retry time.Duration) { sharedSlotRetry = retry }(sharedSlotRetry)
+	sharedSlotRetry = 10 * time.Millisecond
+	runner := &blockingProjectCommandRunner{started: make(chan string), done: map[string]chan struct{}{"b-apply": make(chan struct{})}}
+	messages := &recordingJobMessageSender{}
+	// Another server runs an apply of the group.
+	slots := &memorySlots{held: map[string]int{"group prod apply": 1}}
+	scheduler := &ProjectCommandScheduler{
+		ProjectCommandRunner: runner,
+		JobMessageSender:     messages,
+		Groups:               []ConcurrencyGroup{{Name: "prod", Limit: 1, Command: "apply"}},
+		SharedSlots:          slots,
+	}
+	go scheduler.Apply(command.ProjectContext{
+		ProjectName:      "b-apply",
+		BaseRepo:         models.Repo{FullName: "owner/b"},
+		CommandName:      command.Apply,
+		ConcurrencyGroup: "prod",
+		Log:              logging.NewNoopLogger(t),
+	})
+	Assert(t, eventually(func() bool {
+		messages.mutex.Lock()
+		defer messages.mutex.Unlock()
+		return len(messages.messages) == 1
+	}), "exp the apply to wait for the other server's")
+
+	slots.mutex.Lock()
+	slots.held["group prod apply"] = 0
+	slots.mutex.Unlock()
+	Equals(t, "b-apply", <-runner.started)
+	close(runner.done["b-apply"])
+	Assert(t, eventually(func() bool {
+		slots.mutex.Lock()
+		defer slots.mutex.Unlock()
+		return slots.held["group prod apply"] == 0
+	}), "exp the apply to release its shared slot")
+}
@@ -18,6 +18,7 @@ import (
 
 	"github.com/runatlantis/atlantis/server/core/locking"
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/timeline"
 	"github.com/runatlantis/atlantis/server/events/vcs"
 	"github.com/runatlantis/atlantis/server/logging"
 )
@@ -41,6 +42,8 @@ type DefaultProjectLocker struct {
 	Locker     locking.Locker
 	NoOpLocker locking.Locker
 	VCSClient  vcs.Client
+	// Timeline records the locks taken by pull requests. It may be nil.
+	Timeline *timeline.Store
 }
 
 // TryLockResponse is the result of trying to lock a project.
@@ -69,7 +72,10 @@ func (p *DefaultProjectLocker) TryLock(log logging.SimpleLogging, pull models.Pu
 	if err != nil {
 		return nil, err
 	}
+	event := timeline.Event{Type: timeline.Lock, User: user.Username, Project: project.ProjectName, Workspace: workspace}
 	if !lockAttempt.LockAcquired && lockAttempt.CurrLock.Pull.Num != pull.Num {
+		event.Description = fmt.Sprintf("Unable to lock %s, it's locked by pull %d", project.Path, lockAttempt.CurrLock.Pull.Num)
+		p.Timeline.Record(pull.BaseRepo.FullName, pull.Num, event)
 		link, err := p.VCSClient.MarkdownPullLink(lockAttempt.CurrLock.Pull)
 		if err != nil {
 			return nil, err
@@ -84,6 +90,10 @@ func (p *DefaultProjectLocker) TryLock(log logging.SimpleLogging, pull models.Pu
 		}, nil
 	}
 	log.Info("Acquired lock with id '%s'", lockAttempt.LockKey)
+	if repoLocking {
+		event.Description = fmt.Sprintf("Locked %s", project.Path)
+		p.Timeline.Record(pull.BaseRepo.FullName, pull.Num, event)
+	}
 	return &TryLockResponse{
 		LockAcquired: true,
 		UnlockFn: func() error {
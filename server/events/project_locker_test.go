@@ -22,6 +22,7 @@ import (
 	"github.com/runatlantis/atlantis/server/core/locking/mocks"
 	"github.com/runatlantis/atlantis/server/events"
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/timeline"
 	"github.com/runatlantis/atlantis/server/events/vcs"
 	"github.com/runatlantis/atlantis/server/events/vcs/github"
 	"github.com/runatlantis/atlantis/server/logging"
@@ -30,7 +31,7 @@ import (
 
 func TestDefaultProjectLocker_TryLockWhenLocked(t *testing.T) {
 	var githubClient *github.Client
-	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil, nil)
+	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil, nil, nil, nil)
 	mockLocker := mocks.NewMockLocker()
 	locker := events.DefaultProjectLocker{
 		Locker:    mockLocker,
@@ -66,7 +67,7 @@ func TestDefaultProjectLocker_TryLockWhenLocked(t *testing.T) {
 func TestDefaultProjectLocker_TryLockWhenLockedSamePull(t *testing.T) {
 	RegisterMockTestingT(t)
 	var githubClient *github.Client
-	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil, nil)
+	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil, nil, nil, nil)
 	mockLocker := mocks.NewMockLocker()
 	locker := events.DefaultProjectLocker{
 		Locker:    mockLocker,
@@ -105,7 +106,7 @@ func TestDefaultProjectLocker_TryLockWhenLockedSamePull(t *testing.T) {
 func TestDefaultProjectLocker_TryLockUnlocked(t *testing.T) {
 	RegisterMockTestingT(t)
 	var githubClient *github.Client
-	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil, nil)
+	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil, nil, nil, nil)
 	mockLocker := mocks.NewMockLocker()
 	locker := events.DefaultProjectLocker{
 		Locker:    mockLocker,
@@ -143,7 +144,7 @@ func TestDefaultProjectLocker_TryLockUnlocked(t *testing.T) {
 
 func TestDefaultProjectLocker_RepoLocking(t *testing.T) {
 	var githubClient *github.Client
-	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil, nil)
+	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil, nil, nil, nil)
 	expProject := models.Project{}
 	expWorkspace := "default"
 	expPull := models.PullRequest{Num: 2}
@@ -211,3 +212,35 @@ func TestDefaultProjectLocker_RepoLocking(t *testing.T) {
 		})
 	}
 }
+
+func TestDefaultProjectLocker_TryLockRecordsTimeline(t *testing.T) {
+	RegisterMockTestingT(t)
+	var githubClient *github.Client
+	mockLocker := mocks.NewMockLocker()
+	store := timeline.NewStore()
+	locker := events.DefaultProjectLocker{
+		Locker:    mockLocker,
+		VCSClient: vcs.NewClientProxy(githubClient, nil, nil, nil, nil, nil, nil, nil),
+		Timeline:  store,
+	}
+	project := models.Project{ProjectName: "project", Path: "dir"}
+	pull := models.PullRequest{Num: 1, BaseRepo: models.Repo{FullName: "owner/repo"}}
+	user := models.User{Username: "jdoe"}
+
+	When(mockLocker.TryLock(project, "default", pull, user)).ThenReturn(locking.TryLockResponse{LockAcquired: true, LockKey: "key"}, nil)
+	_, err := locker.TryLock(logging.NewNoopLogger(t), pull, user, "default", project, true)
+	Ok(t, err)
+
+	When(mockLocker.TryLock(project, "staging", pull, user)).ThenReturn(locking.TryLockResponse{LockAcquired: false, CurrLock: models.ProjectLock{Pull: models.PullRequest{Num: 2}}}, nil)
+	_, err = locker.TryLock(logging.NewNoopLogger(t), pull, user, "staging", project, true)
+	Ok(t, err)
+
+	recorded := store.List("owner/repo", 1)
+	Equals(t, 2, len(recorded))
+	Equals(t, timeline.Lock, recorded[0].Type)
+	Equals(t, "Locked dir", recorded[0].Description)
+	Equals(t, "project", recorded[0].Project)
+	Equals(t, "default", recorded[0].Workspace)
+	Equals(t, "jdoe", recorded[0].User)
+	Equals(t, "Unable to lock dir, it's locked by pull 2", recorded[1].Description)
+}
@@ -0,0 +1,164 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// DriftStatus is whether the infrastructure of a project matches its code.
+type DriftStatus string
+
+const (
+	// DriftUnknown is the drift status of projects that weren't planned
+	// outside of pull requests.
+	DriftUnknown DriftStatus = ""
+	// DriftDetected is the drift status of projects whose last plan outside
+	// of a pull request, ex. through the API, has changes.
+	DriftDetected DriftStatus = "drifted"
+	// DriftNone is the drift status of projects whose last plan outside of a
+	// pull request has no changes.
+	DriftNone DriftStatus = "in sync"
+)
+
+// ProjectCommandStatus is the result of the last plan or apply of a project.
+type ProjectCommandStatus struct {
+	Time    time.Time
+	Success bool
+	// Summary is the counts of the changes for successful plans, ex. "Plan: 1 to
+	// add, 0 to change, 0 to destroy.", or the error of failed commands.
+	Summary    string
+	PullNum    int
+	PullURL    string
+	HeadCommit string
+	User       string
+}
+
+// ProjectStatus is the last status of a project across pull requests.
+type ProjectStatus struct {
+	Repository  string
+	VCSHostname string
+	ProjectName string
+	RepoRelDir  string
+	Workspace   string
+	LastPlan    *ProjectCommandStatus
+	LastApply   *ProjectCommandStatus
+	// LastAppliedCommit is the head commit of the last successful apply.
+	LastAppliedCommit string
+	Drift             DriftStatus
+	// DriftCheckedAt is when the drift status was last updated.
+	DriftCheckedAt time.Time
+}
+
+// ProjectStatusTracker keeps the last plan and apply of every project so
+// operators can browse them in the UI. Statuses are kept in memory and don't
+// survive restarts. Its methods are no-ops on a nil ProjectStatusTracker.
+type ProjectStatusTracker struct {
+	mutex    sync.Mutex
+	statuses map[string]*ProjectStatus
+}
+
+// RecordPlan records the plan of the project of ctx.
+func (t *ProjectStatusTracker) RecordPlan(ctx command.ProjectContext, planSuccess *models.PlanSuccess, failure string, err error) {
+	if t == nil {
+		return
+	}
+	result := newProjectCommandStatus(ctx, failure, err)
+	if planSuccess != nil {
+		result.Summary = planSuccess.DiffSummary()
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	status := t.status(ctx)
+	status.LastPlan = result
+	if ctx.Pull.Num == 0 && planSuccess != nil {
+		status.Drift = DriftNone
+		if !planSuccess.NoChanges() {
+			status.Drift = DriftDetected
+		}
+		status.DriftCheckedAt = result.Time
+	}
+}
+
+// RecordApply records the apply of the project of ctx.
+func (t *ProjectStatusTracker) RecordApply(ctx command.ProjectContext, failure string, err error) {
+	if t == nil {
+		return
+	}
+	result := newProjectCommandStatus(ctx, failure, err)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	status := t.status(ctx)
+	status.LastApply = result
+	if result.Success {
+		status.LastAppliedCommit = ctx.Pull.HeadCommit
+	}
+}
+
+// List returns the statuses of the projects sorted by repository, then
+// project.
+func (t *ProjectStatusTracker) List() []ProjectStatus {
+	if t == nil {
+		return nil
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	statuses := make([]ProjectStatus, 0, len(t.statuses))
+	for _, status := range t.statuses {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return projectStatusKey(statuses[i].Repository, statuses[i].RepoRelDir, statuses[i].Workspace, statuses[i].ProjectName) <
+			projectStatusKey(statuses[j].Repository, statuses[j].RepoRelDir, statuses[j].Workspace, statuses[j].ProjectName)
+	})
+	return statuses
+}
+
+// status returns the status of the project of ctx, adding it if it's unknown.
+// t.mutex must be held.
+func (t *ProjectStatusTracker) status(ctx command.ProjectContext) *ProjectStatus {
+	if t.statuses == nil {
+		t.statuses = make(map[string]*ProjectStatus)
+	}
+	key := projectStatusKey(ctx.Pull.BaseRepo.FullName, ctx.RepoRelDir, ctx.Workspace, ctx.ProjectName)
+	status, ok := t.statuses[key]
+	if !ok {
+		status = &ProjectStatus{
+			Repository:  ctx.Pull.BaseRepo.FullName,
+			VCSHostname: ctx.Pull.BaseRepo.VCSHost.Hostname,
+			ProjectName: ctx.ProjectName,
+			RepoRelDir:  ctx.RepoRelDir,
+			Workspace:   ctx.Workspace,
+		}
+		t.statuses[key] = status
+	}
+	return status
+}
+
+func newProjectCommandStatus(ctx command.ProjectContext, failure string, err error) *ProjectCommandStatus {
+	result := &ProjectCommandStatus{
+		Time:       time.Now(),
+		Success:    err == nil && failure == "",
+		Summary:    failure,
+		PullNum:    ctx.Pull.Num,
+		PullURL:    ctx.Pull.URL,
+		HeadCommit: ctx.Pull.HeadCommit,
+		User:       ctx.User.Username,
+	}
+	if err != nil {
+		result.Summary = err.Error()
+	}
+	return result
+}
+
+func projectStatusKey(repository string, repoRelDir string, workspace string, projectName string) string {
+	return repository + "\x00" + repoRelDir + "\x00" + workspace + "\x00" + projectName
+}
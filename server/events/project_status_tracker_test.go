@@ -0,0 +1,70 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestProjectStatusTracker(t *testing.T) {
+	tracker := &events.ProjectStatusTracker{}
+	repo := models.Repo{FullName: "owner/repo"}
+	network := command.ProjectContext{
+		Pull:        models.PullRequest{Num: 1, BaseRepo: repo, HeadCommit: "abc123"},
+		User:        models.User{Username: "user"},
+		ProjectName: "network",
+		RepoRelDir:  "network",
+		Workspace:   "default",
+	}
+	changes := &models.PlanSuccess{TerraformOutput: "Plan: 1 to add, 0 to change, 0 to destroy."}
+	noChanges := &models.PlanSuccess{TerraformOutput: "No changes. Your infrastructure matches the configuration."}
+
+	tracker.RecordPlan(network, changes, "", nil)
+	tracker.RecordApply(network, "", nil)
+	app := network
+	app.ProjectName = "app"
+	app.RepoRelDir = "app"
+	tracker.RecordPlan(app, nil, "", errors.New("plan failed"))
+
+	statuses := tracker.List()
+	Equals(t, 2, len(statuses))
+	Equals(t, "app", statuses[0].ProjectName)
+	Assert(t, !statuses[0].LastPlan.Success, "exp the plan of app to fail")
+	Equals(t, "plan failed", statuses[0].LastPlan.Summary)
+	Assert(t, statuses[0].LastApply == nil, "exp app not to be applied")
+
+	Equals(t, "network", statuses[1].ProjectName)
+	Assert(t, statuses[1].LastPlan.Success, "exp the plan of network to succeed")
+	Equals(t, "Plan: 1 to add, 0 to change, 0 to destroy.", statuses[1].LastPlan.Summary)
+	Equals(t, 1, statuses[1].LastPlan.PullNum)
+	Equals(t, "abc123", statuses[1].LastAppliedCommit)
+	Equals(t, events.DriftUnknown, statuses[1].Drift)
+
+	t.Log("plans outside of pull requests update the drift")
+	scheduled := network
+	scheduled.Pull.Num = 0
+	tracker.RecordPlan(scheduled, changes, "", nil)
+	Equals(t, events.DriftDetected, tracker.List()[1].Drift)
+	tracker.RecordPlan(scheduled, noChanges, "", nil)
+	Equals(t, events.DriftNone, tracker.List()[1].Drift)
+
+	t.Log("failed applies don't change the last applied commit")
+	network.Pull.HeadCommit = "def456"
+	tracker.RecordApply(network, "apply failed", nil)
+	Equals(t, "abc123", tracker.List()[1].LastAppliedCommit)
+	Equals(t, "apply failed", tracker.List()[1].LastApply.Summary)
+}
+
+func TestProjectStatusTracker_Nil(t *testing.T) {
+	var tracker *events.ProjectStatusTracker
+	tracker.RecordPlan(command.ProjectContext{}, nil, "", nil)
+	tracker.RecordApply(command.ProjectContext{}, "", nil)
+	Equals(t, 0, len(tracker.List()))
+}
@@ -56,6 +56,12 @@ type PullClosedExecutor struct {
 	PullClosedTemplate       PullCleanupTemplate
 	LogStreamResourceCleaner ResourceCleaner
 	CancellationTracker      CancellationTracker
+	// PlanJSONs are the stored JSON plans, deleted with the pull request. It
+	// may be nil.
+	PlanJSONs *PlanJSONStore
+	// Previews marks the preview environments of the pull request to be
+	// destroyed. It may be nil.
+	Previews *PreviewCommandRunner
 }
 
 type templatedProject struct {
@@ -92,6 +98,7 @@ func (p *PullClosedExecutor) CleanUpPull(logger logging.SimpleLogging, repo mode
 				PullNum:      pull.Num,
 				Repo:         pull.BaseRepo.Name,
 				RepoFullName: pull.BaseRepo.FullName,
+				VCSHostname:  pull.BaseRepo.VCSHost.Hostname,
 				ProjectName:  project.ProjectName,
 				Path:         project.RepoRelDir,
 				Workspace:    project.Workspace,
@@ -116,6 +123,17 @@ func (p *PullClosedExecutor) CleanUpPull(logger logging.SimpleLogging, repo mode
 	if err := p.Database.DeletePullStatus(pull); err != nil {
 		logger.Err("deleting pull from db: %s", err)
 	}
+	if p.PlanJSONs != nil {
+		if err := p.PlanJSONs.DeletePull(pull.BaseRepo.FullName, pull.Num); err != nil {
+			logger.Err("deleting plan json: %s", err)
+		}
+	}
+
+	if p.Previews != nil {
+		if err := p.Previews.ClosePull(pull); err != nil {
+			logger.Err("marking the preview environments to be destroyed: %s", err)
+		}
+	}
 
 	// Clear any operations to avoid unbounded growth.
 	if p.CancellationTracker != nil {
@@ -376,6 +376,7 @@ func TestCleanUpPullWithCorrectJobContext(t *testing.T) {
 		PullNum:      testdata.Pull.Num,
 		Repo:         testdata.Pull.BaseRepo.Name,
 		RepoFullName: testdata.Pull.BaseRepo.FullName,
+		VCSHostname:  testdata.Pull.BaseRepo.VCSHost.Hostname,
 		ProjectName:  "project1",
 		Path:         "path/to/project1",
 		Workspace:    "default",
@@ -387,6 +388,7 @@ func TestCleanUpPullWithCorrectJobContext(t *testing.T) {
 		PullNum:      testdata.Pull.Num,
 		Repo:         testdata.Pull.BaseRepo.Name,
 		RepoFullName: testdata.Pull.BaseRepo.FullName,
+		VCSHostname:  testdata.Pull.BaseRepo.VCSHost.Hostname,
 		ProjectName:  "project2",
 		Path:         "path/to/project2",
 		Workspace:    "staging",
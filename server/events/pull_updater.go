@@ -5,9 +5,13 @@ package events
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/timeline"
 	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
 	"github.com/runatlantis/atlantis/server/utils"
 )
 
@@ -19,8 +23,22 @@ const aiSummarySplitThreshold = 50000
 
 type PullUpdater struct {
 	HidePrevPlanComments bool
-	VCSClient            vcs.Client
-	MarkdownRenderer     *MarkdownRenderer
+	// MentionCodeOwners mentions the code owners of the projects with changes
+	// on plan comments, unless the projects declare their owners.
+	MentionCodeOwners bool
+	VCSClient         vcs.Client
+	MarkdownRenderer  *MarkdownRenderer
+	// Webhooks is used to send the summary_generated event. It may be nil.
+	Webhooks WebhooksSender
+	// SummarySink receives every generated plan summary. It may be nil.
+	SummarySink *SummarySink
+	// SummaryFeedback tracks the reactions on summary comments. It may be nil.
+	SummaryFeedback *SummaryFeedbackTracker
+	// Summaries keeps the generated summaries for the API. It may be nil.
+	Summaries *SummaryStore
+	// Timeline records the commands that finished and the summaries
+	// generated for pull requests. It may be nil.
+	Timeline *timeline.Store
 }
 
 func (c *PullUpdater) updatePull(ctx *command.Context, cmd PullCommand, res command.Result) {
@@ -30,6 +48,11 @@ func (c *PullUpdater) updatePull(ctx *command.Context, cmd PullCommand, res comm
 	} else if res.Failure != "" {
 		ctx.Log.Warn(res.Failure)
 	}
+	c.Timeline.Record(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, timeline.Event{
+		Type:        timeline.Command,
+		Description: commandFinishedDescription(cmd.CommandName(), res),
+		User:        ctx.User.Username,
+	})
 
 	// HidePrevCommandComments will hide old comments left from previous runs to reduce
 	// clutter in a pull/merge request. This will not delete the comment, since the
@@ -41,6 +64,7 @@ func (c *PullUpdater) updatePull(ctx *command.Context, cmd PullCommand, res comm
 		}
 	}
 
+	var noChangesRollup []string
 	if len(res.ProjectResults) > 0 {
 		var commentOnProjects []command.ProjectResult
 		for _, result := range res.ProjectResults {
@@ -48,45 +72,74 @@ func (c *PullUpdater) updatePull(ctx *command.Context, cmd PullCommand, res comm
 				ctx.Log.Debug("silenced command '%s' comment for project '%s'", cmd.CommandName().String(), result.ProjectName)
 				continue
 			}
+			if cmd.CommandName() == command.Plan && result.PlanSuccess != nil && result.PlanSuccess.NoChanges() {
+				switch result.NoChangesPlanComments {
+				case valid.NoChangesPlanCommentsSkip:
+					ctx.Log.Debug("skipped plan comment without changes for project '%s'", result.ProjectName)
+					continue
+				case valid.NoChangesPlanCommentsRollup:
+					noChangesRollup = append(noChangesRollup, noChangesProjectLabel(result))
+					continue
+				}
+			}
 			commentOnProjects = append(commentOnProjects, result)
 		}
 
-		if len(commentOnProjects) == 0 {
+		if len(commentOnProjects) == 0 && len(noChangesRollup) == 0 {
 			return
 		}
 
 		res.ProjectResults = commentOnProjects
 	}
 
-	comment := c.MarkdownRenderer.Render(ctx, res, cmd)
+	var comment string
+	switch {
+	case len(noChangesRollup) == 0:
+		comment = c.MarkdownRenderer.Render(ctx, res, cmd)
+	case len(res.ProjectResults) == 0:
+		comment = noChangesRollupLine(noChangesRollup)
+	default:
+		comment = fmt.Sprintf("%s\n\n%s", c.MarkdownRenderer.Render(ctx, res, cmd), noChangesRollupLine(noChangesRollup))
+	}
 
-	// Add OpenRouter summary for plan commands
+	// Explain failed plans, including init errors, in plain language
+	if cmd.CommandName() == command.Plan && failureExplanationsEnabled() {
+		if explanations := explainFailures(res.ProjectResults, ctx.Log); explanations != "" {
+			comment = fmt.Sprintf("%s\n\n%s", comment, explanations)
+		}
+	}
+
+	// Pull in the reviewers of the projects that changed
 	if cmd.CommandName() == command.Plan {
-		var terraformOutputs []string
-		for _, result := range res.ProjectResults {
-			if result.PlanSuccess != nil {
-				terraformOutputs = append(terraformOutputs, result.PlanSuccess.TerraformOutput)
-			}
+		if mentions := c.ownerMentions(ctx, res.ProjectResults); mentions != "" {
+			comment = fmt.Sprintf("%s\n\n%s", comment, mentions)
+		}
+	}
+
+	// Say whether the changes shown include the pull request this one is
+	// stacked on.
+	if cmd.CommandName() == command.Plan || cmd.CommandName() == command.Apply {
+		if note := stackedPullNote(ctx); note != "" {
+			comment = fmt.Sprintf("%s\n\n%s", note, comment)
 		}
+	}
 
-		if len(terraformOutputs) > 0 {
-			summary := SummarizePlans(terraformOutputs, ctx.Log)
-			if summary != "" {
-				summaryBlock := fmt.Sprintf("### Plan Summary (AI generated by Topher's AI)\n\n%s", summary)
-				planBlock := fmt.Sprintf("### Regular Atlantis Plan Details\n\n%s", comment)
-				combined := fmt.Sprintf("%s\n\n---\n\n%s", summaryBlock, planBlock)
-				if len(combined) > aiSummarySplitThreshold {
-					// Post summary and plan details as two comments so the AI summary
-					// stays visible as markdown; the underlying VCS split would put
-					// continuation in "Show Output" / diff.
-					if err := c.VCSClient.CreateComment(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num, summaryBlock, cmd.CommandName().String()); err != nil {
-						ctx.Log.Err("unable to comment (summary): %s", err)
-						return
-					}
-					comment = planBlock
-				} else {
-					comment = combined
+	// Add OpenRouter summary for plan commands
+	if cmd.CommandName() == command.Plan {
+		if summaryBlock := c.summarize(ctx, res.ProjectResults); summaryBlock != "" {
+			planBlock := fmt.Sprintf("### Regular Atlantis Plan Details\n\n%s", comment)
+			combined := fmt.Sprintf("%s\n\n---\n\n%s", summaryBlock, planBlock)
+			if len(combined) > aiSummarySplitThreshold {
+				// Post summary and plan details as two comments so the AI summary
+				// stays visible as markdown; the underlying VCS split would put
+				// continuation in "Show Output" / diff.
+				if err := c.VCSClient.CreateComment(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num, summaryBlock, cmd.CommandName().String()); err != nil {
+					ctx.Log.Err("unable to comment (summary): %s", err)
+					return
 				}
+				comment = planBlock
+			} else {
+				comment = combined
 			}
 		}
 	}
@@ -95,3 +148,120 @@ func (c *PullUpdater) updatePull(ctx *command.Context, cmd PullCommand, res comm
 		ctx.Log.Err("unable to comment: %s", err)
 	}
 }
+
+// summarize summarizes the successful plans of projectResults and returns the
+// summary block to comment, or an empty string if there's nothing to summarize
+// or summarizing failed.
+func (c *PullUpdater) summarize(ctx *command.Context, projectResults []command.ProjectResult) string {
+	var terraformOutputs []string
+	for _, result := range projectResults {
+		if result.PlanSuccess == nil {
+			continue
+		}
+		output := result.PlanSuccess.TerraformOutput
+		if result.PlanSuccess.PlanJSON != "" {
+			output = result.PlanSuccess.PlanJSON
+		}
+		terraformOutputs = append(terraformOutputs, labelPlanForSummary(result, output))
+	}
+	if len(terraformOutputs) == 0 {
+		return ""
+	}
+
+	variant := PickSummaryVariant(ctx.Pull, ctx.Log)
+	var changedPaths []string
+	if variant.Templated() {
+		var err error
+		if changedPaths, err = c.VCSClient.GetModifiedFiles(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull); err != nil {
+			ctx.Log.Warn("unable to get modified files for the summary prompt: %s", err)
+		}
+	}
+	promptData := NewSummaryPromptData(ctx, projectResults, changedPaths)
+	generated := GenerateSummary(terraformOutputs, promptData, variant, ctx.Log)
+	summary := generated.Summary
+	if summary == "" {
+		return ""
+	}
+	if generated.Variant != "" {
+		ctx.Log.Info("generated the plan summary with variant %s, model %s and prompt %s in %s", generated.Variant, generated.Model, generated.PromptHash, generated.Latency)
+	}
+	c.sendSummaryWebhook(ctx, summary)
+	c.sendSummaryToSink(ctx, summary, projectResults)
+	if c.Summaries != nil {
+		c.Summaries.Add(ctx.Pull, ctx.User.Username, generated)
+	}
+	c.Timeline.Record(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, timeline.Event{Type: timeline.Summary, Description: "Generated a plan summary", User: ctx.User.Username})
+	summaryBlock := fmt.Sprintf("### Plan Summary (AI generated by Topher's AI)\n\n%s", summary)
+	if c.SummaryFeedback != nil {
+		if marker := c.SummaryFeedback.Track(ctx.Log, ctx.Pull, generated); marker != "" {
+			summaryBlock = fmt.Sprintf("%s\n\n%s", summaryBlock, marker)
+		}
+	}
+	return summaryBlock
+}
+
+// noChangesProjectLabel returns how the project of result is listed in the
+// line rolling up the plans without changes.
+func noChangesProjectLabel(result command.ProjectResult) string {
+	if result.ProjectName != "" {
+		return fmt.Sprintf("project: `%s`", result.ProjectName)
+	}
+	return fmt.Sprintf("dir: `%s` workspace: `%s`", result.RepoRelDir, result.Workspace)
+}
+
+// noChangesRollupLine returns the line commented instead of the plans without
+// changes of projects.
+func noChangesRollupLine(projects []string) string {
+	if len(projects) == 1 {
+		return fmt.Sprintf("No changes in %s.", projects[0])
+	}
+	return fmt.Sprintf("No changes in %d projects: %s.", len(projects), strings.Join(projects, ", "))
+}
+
+// commandFinishedDescription describes the result of a command in the
+// timeline.
+func commandFinishedDescription(name command.Name, res command.Result) string {
+	switch {
+	case res.Error != nil:
+		return fmt.Sprintf("The %s command errored: %s", name, res.Error)
+	case res.Failure != "":
+		return fmt.Sprintf("The %s command failed: %s", name, res.Failure)
+	}
+	var failed int
+	for _, result := range res.ProjectResults {
+		if !result.IsSuccessful() {
+			failed++
+		}
+	}
+	projects := "projects"
+	if len(res.ProjectResults) == 1 {
+		projects = "project"
+	}
+	if failed > 0 {
+		return fmt.Sprintf("The %s command finished for %d %s, %d failed", name, len(res.ProjectResults), projects, failed)
+	}
+	return fmt.Sprintf("The %s command finished for %d %s", name, len(res.ProjectResults), projects)
+}
+
+func (c *PullUpdater) sendSummaryWebhook(ctx *command.Context, summary string) {
+	if c.Webhooks == nil {
+		return
+	}
+	c.Webhooks.Send(ctx.Log, webhooks.ApplyResult{ // nolint: errcheck
+		Event:   webhooks.SummaryGeneratedEvent,
+		Repo:    ctx.Pull.BaseRepo,
+		Pull:    ctx.Pull,
+		User:    ctx.User,
+		Success: true,
+		Summary: summary,
+	})
+}
+
+func (c *PullUpdater) sendSummaryToSink(ctx *command.Context, summary string, projectResults []command.ProjectResult) {
+	if c.SummarySink == nil {
+		return
+	}
+	if err := c.SummarySink.Send(NewSummaryPayload(ctx, summary, projectResults)); err != nil {
+		ctx.Log.Warn("unable to send plan summary to sink: %s", err)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"testing"
+
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+func TestPullUpdater_NoChangesPlanComments(t *testing.T) {
+	noChanges := &models.PlanSuccess{TerraformOutput: "No changes. Your infrastructure matches the configuration."}
+	cases := map[string]struct {
+		mode       string
+		expComment string
+	}{
+		"rollup": {
+			mode:       valid.NoChangesPlanCommentsRollup,
+			expComment: "No changes in 2 projects: project: `network`, dir: `app` workspace: `staging`.",
+		},
+		"skip": {
+			mode: valid.NoChangesPlanCommentsSkip,
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			repo := models.Repo{FullName: "owner/repo"}
+			ctx := &command.Context{Log: logging.NewNoopLogger(t), Pull: models.PullRequest{Num: 1, BaseRepo: repo}}
+			vcsClient := vcsmocks.NewMockClient()
+			updater := &PullUpdater{VCSClient: vcsClient}
+
+			updater.updatePull(ctx, AutoplanCommand{}, command.Result{ProjectResults: []command.ProjectResult{
+				{ProjectCommandOutput: command.ProjectCommandOutput{PlanSuccess: noChanges}, ProjectName: "network", NoChangesPlanComments: c.mode},
+				{ProjectCommandOutput: command.ProjectCommandOutput{PlanSuccess: noChanges}, RepoRelDir: "app", Workspace: "staging", NoChangesPlanComments: c.mode},
+			}})
+
+			if c.expComment == "" {
+				vcsClient.VerifyWasCalled(Never()).CreateComment(Any[logging.SimpleLogging](), Any[models.Repo](), Any[int](), Any[string](), Any[string]())
+				return
+			}
+			vcsClient.VerifyWasCalledOnce().CreateComment(Any[logging.SimpleLogging](), Eq(repo), Eq(1), Eq(c.expComment), Eq("plan"))
+		})
+	}
+}
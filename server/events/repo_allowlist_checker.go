@@ -15,6 +15,7 @@ package events
 
 import (
 	"fmt"
+	"path"
 	"strings"
 )
 
@@ -24,23 +25,44 @@ const Wildcard = "*"
 // RepoAllowlistChecker implements checking if repos are allowlisted to be used with
 // this Atlantis.
 type RepoAllowlistChecker struct {
-	includeRules []string
-	omitRules    []string
+	includeRules []allowlistRule
+	omitRules    []allowlistRule
+	// Tenants, if set, additionally requires repos to belong to a tenant.
+	Tenants *Tenants
+}
+
+// allowlistRule matches repos and, if branch is set, the base branches of
+// their pull requests, ex. github.com/owner/*@release/*.
+type allowlistRule struct {
+	repo   string
+	branch string
 }
 
 // NewRepoAllowlistChecker constructs a new checker and validates that the
 // allowlist isn't malformed.
 func NewRepoAllowlistChecker(allowlist string) (*RepoAllowlistChecker, error) {
-	includeRules := make([]string, 0)
-	omitRules := make([]string, 0)
+	includeRules := make([]allowlistRule, 0)
+	omitRules := make([]allowlistRule, 0)
 	for rule := range strings.SplitSeq(allowlist, ",") {
 		if strings.Contains(rule, "://") {
 			return nil, fmt.Errorf("allowlist %q contained ://", rule)
 		}
-		if len(rule) > 1 && rule[0] == '!' {
-			omitRules = append(omitRules, rule[1:])
+		omit := len(rule) > 1 && rule[0] == '!'
+		repo := rule
+		if omit {
+			repo = rule[1:]
+		}
+		// Repo names can't contain @ so it always separates the branch.
+		repo, branch, hasBranch := strings.Cut(repo, "@")
+		if hasBranch {
+			if _, err := path.Match(branch, ""); branch == "" || err != nil {
+				return nil, fmt.Errorf("allowlist %q has an invalid branch pattern", rule)
+			}
+		}
+		if omit {
+			omitRules = append(omitRules, allowlistRule{repo: repo, branch: branch})
 		} else {
-			includeRules = append(includeRules, rule)
+			includeRules = append(includeRules, allowlistRule{repo: repo, branch: branch})
 		}
 	}
 	return &RepoAllowlistChecker{
@@ -50,17 +72,44 @@ func NewRepoAllowlistChecker(allowlist string) (*RepoAllowlistChecker, error) {
 }
 
 // IsAllowlisted returns true if this repo is in our allowlist and false
-// otherwise.
+// otherwise. A repo is allowlisted if any of its branches are, see
+// IsBranchAllowlisted.
 func (r *RepoAllowlistChecker) IsAllowlisted(repoFullName string, vcsHostname string) bool {
 	candidate := fmt.Sprintf("%s/%s", vcsHostname, repoFullName)
-	shouldInclude := r.matchesAtLeastOneRule(r.includeRules, candidate)
-	shouldOmit := r.matchesAtLeastOneRule(r.omitRules, candidate)
-	return shouldInclude && !shouldOmit
+	// Omit rules with a branch filter only omit that branch.
+	shouldInclude := r.matchesAtLeastOneRule(r.includeRules, candidate, func(string) bool { return true })
+	shouldOmit := r.matchesAtLeastOneRule(r.omitRules, candidate, func(string) bool { return false })
+	if !shouldInclude || shouldOmit {
+		return false
+	}
+	if r.Tenants != nil {
+		_, ok := r.Tenants.TenantFor(repoFullName, vcsHostname)
+		return ok
+	}
+	return true
+}
+
+// IsBranchAllowlisted returns true if pull requests of this repo targeting
+// baseBranch are allowlisted and false otherwise.
+func (r *RepoAllowlistChecker) IsBranchAllowlisted(repoFullName string, vcsHostname string, baseBranch string) bool {
+	if !r.IsAllowlisted(repoFullName, vcsHostname) {
+		return false
+	}
+	candidate := fmt.Sprintf("%s/%s", vcsHostname, repoFullName)
+	branchMatches := func(pattern string) bool {
+		// Unlike repo names, branch names are case sensitive.
+		matched, err := path.Match(pattern, baseBranch)
+		return err == nil && matched
+	}
+	return r.matchesAtLeastOneRule(r.includeRules, candidate, branchMatches) &&
+		!r.matchesAtLeastOneRule(r.omitRules, candidate, branchMatches)
 }
 
-func (r *RepoAllowlistChecker) matchesAtLeastOneRule(rules []string, candidate string) bool {
+// matchesAtLeastOneRule returns true if candidate matches one of rules,
+// branchMatches is called for rules with a branch filter.
+func (r *RepoAllowlistChecker) matchesAtLeastOneRule(rules []allowlistRule, candidate string, branchMatches func(pattern string) bool) bool {
 	for _, rule := range rules {
-		if r.matchesRule(rule, candidate) {
+		if r.matchesRule(rule.repo, candidate) && (rule.branch == "" || branchMatches(rule.branch)) {
 			return true
 		}
 	}
@@ -200,6 +200,82 @@ func TestRepoAllowlistChecker_IsAllowlisted(t *testing.T) {
 	}
 }
 
+func TestRepoAllowlistChecker_IsBranchAllowlisted(t *testing.T) {
+	cases := []struct {
+		description    string
+		allowlist      string
+		baseBranch     string
+		expAllowlisted bool
+		expBranch      bool
+	}{
+		{
+			"no branch filter",
+			"github.com/owner/*",
+			"feature",
+			true,
+			true,
+		},
+		{
+			"matching branch filter",
+			"github.com/owner/*@main,github.com/owner/*@release/*",
+			"release/1.0",
+			true,
+			true,
+		},
+		{
+			"non-matching branch filter",
+			"github.com/owner/*@main,github.com/owner/*@release/*",
+			"feature",
+			true,
+			false,
+		},
+		{
+			"branch filters are case sensitive",
+			"github.com/owner/*@main",
+			"Main",
+			true,
+			false,
+		},
+		{
+			"negative rule with branch filter only omits the branch",
+			"github.com/owner/*,!github.com/owner/repo@legacy",
+			"legacy",
+			true,
+			false,
+		},
+		{
+			"negative rule with branch filter doesn't omit other branches",
+			"github.com/owner/*,!github.com/owner/repo@legacy",
+			"main",
+			true,
+			true,
+		},
+		{
+			"negative rule without branch filter omits all branches",
+			"github.com/owner/*@main,!github.com/owner/repo",
+			"main",
+			false,
+			false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			w, err := events.NewRepoAllowlistChecker(c.allowlist)
+			Ok(t, err)
+			Equals(t, c.expAllowlisted, w.IsAllowlisted("owner/repo", "github.com"))
+			Equals(t, c.expBranch, w.IsBranchAllowlisted("owner/repo", "github.com", c.baseBranch))
+		})
+	}
+}
+
+func TestNewRepoAllowlistChecker_InvalidBranch(t *testing.T) {
+	_, err := events.NewRepoAllowlistChecker("github.com/owner/repo@")
+	ErrEquals(t, `allowlist "github.com/owner/repo@" has an invalid branch pattern`, err)
+	_, err = events.NewRepoAllowlistChecker("!github.com/owner/repo@[")
+	ErrEquals(t, `allowlist "!github.com/owner/repo@[" has an invalid branch pattern`, err)
+}
+
 // If the allowlist contains a schema then we should get an error.
 func TestRepoAllowlistChecker_ContainsSchema(t *testing.T) {
 	cases := []struct {
@@ -0,0 +1,105 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// resourceChangesJSON is the subset of terraform show -json output the
+// changes of a plan are read from.
+type resourceChangesJSON struct {
+	ResourceChanges []struct {
+		Address string `json:"address"`
+		Change  struct {
+			Actions []string `json:"actions"`
+		} `json:"change"`
+	} `json:"resource_changes"`
+}
+
+// planResourceChanges returns the changes the plan in show, the output of
+// terraform show -json, makes to resources. Resources that are only read or
+// don't change are left out.
+func planResourceChanges(show string) ([]models.ResourceChange, error) {
+	var plan resourceChangesJSON
+	if err := json.Unmarshal([]byte(show), &plan); err != nil {
+		return nil, fmt.Errorf("parsing plan json: %w", err)
+	}
+	var changes []models.ResourceChange
+	for _, rc := range plan.ResourceChanges {
+		actions := rc.Change.Actions
+		var action string
+		switch {
+		case slices.Equal(actions, []string{"create"}):
+			action = "create"
+		case slices.Equal(actions, []string{"update"}):
+			action = "update"
+		case slices.Equal(actions, []string{"delete"}):
+			action = "delete"
+		case slices.Contains(actions, "create") && slices.Contains(actions, "delete"):
+			action = "replace"
+		default:
+			continue
+		}
+		changes = append(changes, models.ResourceChange{Address: rc.Address, Action: action})
+	}
+	return changes, nil
+}
+
+// plannedResourceChanges returns the changes applying the plan of the project
+// described by ctx will make to resources, if resource changes are recorded
+// or included in apply reports. It must be called before the plan is
+// applied, while its planfile exists.
+func (p *DefaultProjectCommandRunner) plannedResourceChanges(ctx command.ProjectContext, absPath string) []models.ResourceChange {
+	if (p.ResourceChanges == nil && p.ApplyReports == nil) || p.ShowStepRunner == nil {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(absPath, runtime.GetPlanFilename(ctx.Workspace, ctx.ProjectName))); err != nil {
+		ctx.Log.Debug("not recording resource changes, there's no planfile: %s", err)
+		return nil
+	}
+	show, err := p.ShowStepRunner.Run(ctx, nil, absPath, map[string]string{})
+	if err != nil {
+		ctx.Log.Warn("unable to show plan as json to record its resource changes: %s", err)
+		return nil
+	}
+	changes, err := planResourceChanges(show)
+	if err != nil {
+		ctx.Log.Warn("unable to read the resource changes of the plan: %s", err)
+		return nil
+	}
+	return changes
+}
+
+// recordResourceChanges records that the project described by ctx applied
+// changes.
+func (p *DefaultProjectCommandRunner) recordResourceChanges(ctx command.ProjectContext, changes []models.ResourceChange) {
+	if p.ResourceChanges == nil || len(changes) == 0 {
+		return
+	}
+	appliedAt := time.Now().UTC()
+	for i := range changes {
+		changes[i].Repository = ctx.Pull.BaseRepo.FullName
+		changes[i].PullNum = ctx.Pull.Num
+		changes[i].PullURL = ctx.Pull.URL
+		changes[i].HeadCommit = ctx.Pull.HeadCommit
+		changes[i].User = ctx.User.Username
+		changes[i].ProjectName = ctx.ProjectName
+		changes[i].RepoRelDir = ctx.RepoRelDir
+		changes[i].Workspace = ctx.Workspace
+		changes[i].AppliedAt = appliedAt
+	}
+	if err := p.ResourceChanges.SaveResourceChanges(changes); err != nil {
+		ctx.Log.Warn("unable to record resource changes: %s", err)
+	}
+}
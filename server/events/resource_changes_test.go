@@ -0,0 +1,90 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/boltdb"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// fakeShowStepRunner returns show as the output of terraform show -json.
+type fakeShowStepRunner struct {
+	show string
+}
+
+func (f fakeShowStepRunner) Run(_ command.ProjectContext, _ []string, _ string, _ map[string]string) (string, error) {
+	return f.show, nil
+}
+
+func TestPlanResourceChanges(t *testing.T) {
+	changes, err := planResourceChanges(`{"resource_changes": [
+  {"address": "aws_security_group.web", "change": {"actions": ["update"]}},
+  {"address": "aws_instance.web[0]", "change": {"actions": ["create", "delete"]}},
+  {"address": "aws_eip.web", "change": {"actions": ["no-op"]}},
+  {"address": "data.aws_ami.ubuntu", "change": {"actions": ["read"]}},
+  {"address": "module.dns.aws_route53_record.web", "change": {"actions": ["delete"]}}
+]}`)
+	Ok(t, err)
+	Equals(t, []models.ResourceChange{
+		{Address: "aws_security_group.web", Action: "update"},
+		{Address: "aws_instance.web[0]", Action: "replace"},
+		{Address: "module.dns.aws_route53_record.web", Action: "delete"},
+	}, changes)
+
+	_, err = planResourceChanges("Version: 0.11.0 is unsupported for this step.")
+	ErrContains(t, "parsing plan json", err)
+}
+
+func TestRecordResourceChanges(t *testing.T) {
+	database, err := boltdb.New(t.TempDir())
+	Ok(t, err)
+	defer database.Close() // nolint: errcheck
+	absPath := t.TempDir()
+	ctx := command.ProjectContext{
+		Log:        logging.NewNoopLogger(t),
+		RepoRelDir: "network",
+		Workspace:  "default",
+		User:       models.User{Username: "alice"},
+		Pull: models.PullRequest{
+			Num:        7,
+			URL:        "https://github.com/owner/repo/pull/7",
+			HeadCommit: "abc123",
+			BaseRepo:   models.Repo{FullName: "owner/repo"},
+		},
+	}
+	runner := &DefaultProjectCommandRunner{
+		ShowStepRunner:  fakeShowStepRunner{show: `{"resource_changes": [{"address": "aws_security_group.web", "change": {"actions": ["update"]}}]}`},
+		ResourceChanges: database,
+	}
+
+	Equals(t, 0, len(runner.plannedResourceChanges(ctx, absPath)))
+
+	Ok(t, os.WriteFile(filepath.Join(absPath, "default.tfplan"), nil, 0600))
+	runner.recordResourceChanges(ctx, runner.plannedResourceChanges(ctx, absPath))
+
+	changes, err := database.ListResourceChanges(models.ResourceChangeQuery{})
+	Ok(t, err)
+	Equals(t, 1, len(changes))
+	appliedAt := changes[0].AppliedAt
+	Assert(t, !appliedAt.IsZero(), "expected the change to have an applied time")
+	Equals(t, models.ResourceChange{
+		Address:    "aws_security_group.web",
+		Action:     "update",
+		Repository: "owner/repo",
+		PullNum:    7,
+		PullURL:    "https://github.com/owner/repo/pull/7",
+		HeadCommit: "abc123",
+		User:       "alice",
+		RepoRelDir: "network",
+		Workspace:  "default",
+		AppliedAt:  appliedAt,
+	}, changes[0])
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"fmt"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// ParentPullFinder finds the pull request a stacked pull request is based on.
+type ParentPullFinder interface {
+	// FindParentPull returns the open pull request of repo whose head is
+	// branch or, if there's none, the last merged one. It returns nil if
+	// there's neither.
+	FindParentPull(logger logging.SimpleLogging, repo models.Repo, branch string) (*models.ParentPull, error)
+}
+
+// StackedPulls detects pull requests based on another pull request rather than
+// on a long lived branch so their plans aren't misleading: the changes shown
+// include the changes of the parent while it's open and once it's merged, the
+// stacked pull request is planned against the branch the parent was merged
+// into rather than the parent's stale branch.
+type StackedPulls struct {
+	Finder ParentPullFinder
+}
+
+// Resolve sets ctx.ParentPull if ctx.Pull is stacked on another pull request.
+// If the parent was merged, ctx.Pull's base branch is set to the branch the
+// parent was merged into.
+func (s *StackedPulls) Resolve(ctx *command.Context) {
+	if ctx.Pull.BaseBranch == "" || ctx.Pull.BaseBranch == ctx.Pull.HeadBranch {
+		return
+	}
+	parent, err := s.Finder.FindParentPull(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.BaseBranch)
+	if err != nil {
+		ctx.Log.Warn("unable to find the pull request %s is stacked on: %s", ctx.Pull.BaseBranch, err)
+		return
+	}
+	if parent == nil || parent.Num == ctx.Pull.Num || parent.BaseBranch == ctx.Pull.BaseBranch {
+		return
+	}
+	ctx.ParentPull = parent
+	if parent.Merged {
+		ctx.Log.Info("base branch %s was merged into %s by #%d, using %s as the base branch", parent.HeadBranch, parent.BaseBranch, parent.Num, parent.BaseBranch)
+		ctx.Pull.BaseBranch = parent.BaseBranch
+	} else {
+		ctx.Log.Info("pull request is stacked on open pull request #%d", parent.Num)
+	}
+}
+
+// stackedPullNote returns the note explaining whether the changes shown for
+// ctx.Pull include the changes of the pull request it's stacked on, or "" if
+// it isn't stacked.
+func stackedPullNote(ctx *command.Context) string {
+	parent := ctx.ParentPull
+	if parent == nil {
+		return ""
+	}
+	if parent.Merged {
+		return fmt.Sprintf(":information_source: This pull request is stacked on #%d which was merged into `%s`, "+
+			"so it's planned against `%s` instead of `%s` and the changes shown exclude the changes of #%d.",
+			parent.Num, parent.BaseBranch, parent.BaseBranch, parent.HeadBranch, parent.Num)
+	}
+	return fmt.Sprintf(":information_source: This pull request is stacked on #%d which isn't merged yet, "+
+		"its base branch `%s` being the head of #%d, so the changes shown include the changes of #%d.",
+		parent.Num, parent.HeadBranch, parent.Num, parent.Num)
+}
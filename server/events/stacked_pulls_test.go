@@ -0,0 +1,79 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+type fakeParentPullFinder struct {
+	parents map[string]*models.ParentPull
+	err     error
+}
+
+func (f fakeParentPullFinder) FindParentPull(_ logging.SimpleLogging, _ models.Repo, branch string) (*models.ParentPull, error) {
+	return f.parents[branch], f.err
+}
+
+func TestStackedPulls_Resolve(t *testing.T) {
+	open := &models.ParentPull{Num: 1, HeadBranch: "feature-a", BaseBranch: "main"}
+	merged := &models.ParentPull{Num: 2, HeadBranch: "feature-b", BaseBranch: "main", Merged: true}
+	finder := fakeParentPullFinder{parents: map[string]*models.ParentPull{
+		"feature-a": open,
+		"feature-b": merged,
+	}}
+
+	cases := map[string]struct {
+		finder        fakeParentPullFinder
+		baseBranch    string
+		expParent     *models.ParentPull
+		expBaseBranch string
+		expNote       string
+	}{
+		"not stacked": {
+			finder:        finder,
+			baseBranch:    "main",
+			expBaseBranch: "main",
+		},
+		"stacked on open pull request": {
+			finder:        finder,
+			baseBranch:    "feature-a",
+			expParent:     open,
+			expBaseBranch: "feature-a",
+			expNote: ":information_source: This pull request is stacked on #1 which isn't merged yet, " +
+				"its base branch `feature-a` being the head of #1, so the changes shown include the changes of #1.",
+		},
+		"stacked on merged pull request": {
+			finder:        finder,
+			baseBranch:    "feature-b",
+			expParent:     merged,
+			expBaseBranch: "main",
+			expNote: ":information_source: This pull request is stacked on #2 which was merged into `main`, " +
+				"so it's planned against `main` instead of `feature-b` and the changes shown exclude the changes of #2.",
+		},
+		"finding parent fails": {
+			finder:        fakeParentPullFinder{err: errors.New("error")},
+			baseBranch:    "feature-a",
+			expBaseBranch: "feature-a",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := &command.Context{
+				Log:  logging.NewNoopLogger(t),
+				Pull: models.PullRequest{Num: 3, HeadBranch: "feature-c", BaseBranch: c.baseBranch},
+			}
+			(&StackedPulls{Finder: c.finder}).Resolve(ctx)
+			Equals(t, c.expParent, ctx.ParentPull)
+			Equals(t, c.expBaseBranch, ctx.Pull.BaseBranch)
+			Equals(t, c.expNote, stackedPullNote(ctx))
+		})
+	}
+}
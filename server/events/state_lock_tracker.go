@@ -0,0 +1,193 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/core/terraform"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// DetectedStateLock is a Terraform state lock that failed a plan or apply.
+type DetectedStateLock struct {
+	Lock        models.StateLock
+	BaseRepo    models.Repo
+	PullNum     int
+	PullURL     string
+	RepoRelDir  string
+	Workspace   string
+	ProjectName string
+	DetectedAt  time.Time
+
+	// ctx is the context of the failed command, terraform force-unlock is run
+	// with it.
+	ctx command.ProjectContext
+}
+
+// StateLockTracker keeps the Terraform state locks plans and applies failed on
+// so they can be listed in the UI and force-unlocked. Locks are kept in memory
+// until the project is planned or applied successfully, or force-unlocked.
+type StateLockTracker struct {
+	TerraformExecutor     runtime.TerraformExec
+	DefaultTFDistribution terraform.Distribution
+	DefaultTFVersion      *version.Version
+	WorkingDir            WorkingDir
+	WorkingDirLocker      WorkingDirLocker
+	// UnlockURL is the URL of the UI page locks can be force-unlocked from.
+	// It's empty if force-unlocking isn't enabled.
+	UnlockURL string
+
+	mutex sync.Mutex
+	locks map[string]DetectedStateLock
+}
+
+// Track records the state lock the command of ctx failed with cmdErr on and
+// returns it, or returns nil if the command didn't fail because of a state
+// lock.
+func (s *StateLockTracker) Track(ctx command.ProjectContext, cmdErr error) *models.StateLock {
+	stateLock := models.ParseStateLock(cmdErr.Error())
+	if stateLock == nil {
+		return nil
+	}
+	stateLock.UnlockURL = s.UnlockURL
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.locks == nil {
+		s.locks = make(map[string]DetectedStateLock)
+	}
+	s.locks[stateLock.ID] = DetectedStateLock{
+		Lock:        *stateLock,
+		BaseRepo:    ctx.Pull.BaseRepo,
+		PullNum:     ctx.Pull.Num,
+		PullURL:     ctx.Pull.URL,
+		RepoRelDir:  ctx.RepoRelDir,
+		Workspace:   ctx.Workspace,
+		ProjectName: ctx.ProjectName,
+		DetectedAt:  time.Now(),
+		ctx:         ctx,
+	}
+	ctx.Log.Info("state is locked by %s with lock %s", stateLock.Who, stateLock.ID)
+	return stateLock
+}
+
+// Forget drops the state locks the project of ctx failed on, ex. once it's
+// planned successfully.
+func (s *StateLockTracker) Forget(ctx command.ProjectContext) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for id, lock := range s.locks {
+		if lock.BaseRepo.FullName == ctx.Pull.BaseRepo.FullName && lock.PullNum == ctx.Pull.Num &&
+			lock.RepoRelDir == ctx.RepoRelDir && lock.Workspace == ctx.Workspace {
+			delete(s.locks, id)
+		}
+	}
+}
+
+// List returns the tracked state locks, most recently detected first. Locks
+// of projects whose working directory was deleted, ex. because the pull
+// request was closed, are dropped since they can't be force-unlocked anymore.
+func (s *StateLockTracker) List() []DetectedStateLock {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var locks []DetectedStateLock
+	for id, lock := range s.locks {
+		if _, err := s.projectDir(lock); err != nil {
+			delete(s.locks, id)
+			continue
+		}
+		locks = append(locks, lock)
+	}
+	sort.Slice(locks, func(i, j int) bool { return locks[i].DetectedAt.After(locks[j].DetectedAt) })
+	return locks
+}
+
+// Get returns the tracked state lock with id, false if there's none.
+func (s *StateLockTracker) Get(id string) (DetectedStateLock, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	lock, ok := s.locks[id]
+	return lock, ok
+}
+
+// ForceUnlock runs terraform force-unlock for the tracked state lock with id
+// in the directory of the project that failed on it. It returns nil if no
+// lock is tracked with id.
+func (s *StateLockTracker) ForceUnlock(id string) (*DetectedStateLock, error) {
+	s.mutex.Lock()
+	lock, ok := s.locks[id]
+	s.mutex.Unlock()
+	if !ok {
+		return nil, nil
+	}
+
+	unlockFn, err := s.WorkingDirLocker.TryLock(lock.BaseRepo.FullName, lock.PullNum, lock.Workspace, lock.RepoRelDir, lock.ProjectName, command.Unlock)
+	if err != nil {
+		return nil, err
+	}
+	defer unlockFn()
+
+	dir, err := s.projectDir(lock)
+	if err != nil {
+		return nil, fmt.Errorf("the working directory of the project no longer exists, run plan again: %w", err)
+	}
+
+	tfDistribution := s.DefaultTFDistribution
+	if lock.ctx.TerraformDistribution != nil {
+		tfDistribution = terraform.NewDistribution(*lock.ctx.TerraformDistribution)
+	}
+	tfVersion := s.DefaultTFVersion
+	if lock.ctx.TerraformVersion != nil {
+		tfVersion = lock.ctx.TerraformVersion
+	}
+	out, err := s.TerraformExecutor.RunCommandWithVersion(lock.ctx, dir, []string{"force-unlock", "-force", id}, nil, tfDistribution, tfVersion, lock.Workspace)
+	if err != nil {
+		return nil, fmt.Errorf("running terraform force-unlock: %w: %s", err, out)
+	}
+
+	s.mutex.Lock()
+	delete(s.locks, id)
+	s.mutex.Unlock()
+	return &lock, nil
+}
+
+// projectDir returns the directory of the project that failed on lock, or an
+// error if it doesn't exist.
+func (s *StateLockTracker) projectDir(lock DetectedStateLock) (string, error) {
+	repoDir, err := s.WorkingDir.GetWorkingDir(lock.ctx.Pull.BaseRepo, lock.ctx.Pull, lock.Workspace)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(repoDir, lock.RepoRelDir)
+	if _, err := os.Stat(dir); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// trackStateLock records the state lock the command of ctx failed on, if any,
+// and returns it. The state locks of the project are forgotten if the command
+// succeeded. The lock is still returned if tracker is nil so it's shown in the
+// comment.
+func trackStateLock(tracker *StateLockTracker, ctx command.ProjectContext, failure string, err error) *models.StateLock {
+	switch {
+	case err != nil && tracker == nil:
+		return models.ParseStateLock(err.Error())
+	case err != nil:
+		return tracker.Track(ctx, err)
+	case failure == "" && tracker != nil:
+		tracker.Forget(ctx)
+	}
+	return nil
+}
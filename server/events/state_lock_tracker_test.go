@@ -0,0 +1,114 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/core/terraform"
+	tfclientmocks "github.com/runatlantis/atlantis/server/core/terraform/tfclient/mocks"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+const stateLockOutput = `Error: Error acquiring the state lock
+
+Lock Info:
+  ID:        lock-id
+  Path:      bucket/terraform.tfstate
+  Operation: OperationTypeApply
+  Who:       someone@host
+  Version:   1.5.7
+  Created:   2024-01-02 03:04:05 +0000 UTC
+  Info:
+`
+
+func newStateLockTracker(t *testing.T) (*events.StateLockTracker, *tfclientmocks.MockClient, command.ProjectContext, string) {
+	RegisterMockTestingT(t)
+	repoDir := t.TempDir()
+	Ok(t, os.Mkdir(filepath.Join(repoDir, "network"), 0700))
+	pull := models.PullRequest{Num: 2, BaseRepo: models.Repo{FullName: "owner/repo"}}
+	workingDir := mocks.NewMockWorkingDir()
+	When(workingDir.GetWorkingDir(pull.BaseRepo, pull, "default")).ThenReturn(repoDir, nil)
+	tf := tfclientmocks.NewMockClient()
+	tracker := &events.StateLockTracker{
+		TerraformExecutor:     tf,
+		DefaultTFDistribution: terraform.NewDistributionTerraform(),
+		DefaultTFVersion:      version.Must(version.NewVersion("1.5.7")),
+		WorkingDir:            workingDir,
+		WorkingDirLocker:      events.NewDefaultWorkingDirLocker(),
+		UnlockURL:             "https://atlantis.example.com/#state-locks",
+	}
+	ctx := command.ProjectContext{
+		Log:        logging.NewNoopLogger(t),
+		Pull:       pull,
+		RepoRelDir: "network",
+		Workspace:  "default",
+	}
+	return tracker, tf, ctx, filepath.Join(repoDir, "network")
+}
+
+func TestStateLockTracker_Track(t *testing.T) {
+	tracker, _, ctx, _ := newStateLockTracker(t)
+
+	Assert(t, tracker.Track(ctx, errors.New("exit status 1\nError: Invalid reference")) == nil, "exp no state lock")
+	Equals(t, 0, len(tracker.List()))
+
+	lock := tracker.Track(ctx, errors.New(stateLockOutput))
+	Assert(t, lock != nil, "exp a state lock")
+	Equals(t, "someone@host", lock.Who)
+	Equals(t, "https://atlantis.example.com/#state-locks", lock.UnlockURL)
+
+	locks := tracker.List()
+	Equals(t, 1, len(locks))
+	Equals(t, "lock-id", locks[0].Lock.ID)
+	Equals(t, "owner/repo", locks[0].BaseRepo.FullName)
+	Equals(t, "network", locks[0].RepoRelDir)
+
+	tracker.Forget(ctx)
+	Equals(t, 0, len(tracker.List()))
+}
+
+func TestStateLockTracker_ListDropsDeletedWorkingDirs(t *testing.T) {
+	tracker, _, ctx, projectDir := newStateLockTracker(t)
+	tracker.Track(ctx, errors.New(stateLockOutput))
+	Ok(t, os.RemoveAll(projectDir))
+	Equals(t, 0, len(tracker.List()))
+}
+
+func TestStateLockTracker_ForceUnlock(t *testing.T) {
+	tracker, tf, ctx, projectDir := newStateLockTracker(t)
+
+	lock, err := tracker.ForceUnlock("lock-id")
+	Ok(t, err)
+	Assert(t, lock == nil, "exp no lock to be found")
+
+	tracker.Track(ctx, errors.New(stateLockOutput))
+	lock, err = tracker.ForceUnlock("lock-id")
+	Ok(t, err)
+	Equals(t, "someone@host", lock.Lock.Who)
+	tf.VerifyWasCalledOnce().RunCommandWithVersion(Any[command.ProjectContext](), Eq(projectDir), Eq([]string{"force-unlock", "-force", "lock-id"}),
+		Any[map[string]string](), Any[terraform.Distribution](), Any[*version.Version](), Eq("default"))
+	Equals(t, 0, len(tracker.List()))
+}
+
+func TestStateLockTracker_ForceUnlockErr(t *testing.T) {
+	tracker, tf, ctx, _ := newStateLockTracker(t)
+	When(tf.RunCommandWithVersion(Any[command.ProjectContext](), Any[string](), Any[[]string](), Any[map[string]string](),
+		Any[terraform.Distribution](), Any[*version.Version](), Any[string]())).ThenReturn("Failed to unlock state", errors.New("exit status 1"))
+
+	tracker.Track(ctx, errors.New(stateLockOutput))
+	_, err := tracker.ForceUnlock("lock-id")
+	ErrEquals(t, "running terraform force-unlock: exit status 1: Failed to unlock state", err)
+	Equals(t, 1, len(tracker.List()))
+}
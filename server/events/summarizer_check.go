@@ -0,0 +1,133 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// ValidateSummarizerConfig validates the plan summarizer environment
+// variables and returns a description of the configured summarizer. Unlike
+// summarizing, which falls back to defaults, invalid settings are errors.
+func ValidateSummarizerConfig() (string, error) {
+	if path := os.Getenv(summaryPromptTemplateFileEnv); path != "" {
+		if err := validatePromptTemplate(path, summaryPromptTemplateFileEnv); err != nil {
+			return "", err
+		}
+	}
+	var variants []SummaryVariant
+	if path := os.Getenv(summaryVariantsFileEnv); path != "" {
+		contents, err := os.ReadFile(path) // nolint: gosec
+		if err != nil {
+			return "", fmt.Errorf("reading %s: %w", summaryVariantsFileEnv, err)
+		}
+		if variants, err = parseSummaryVariants(contents); err != nil {
+			return "", fmt.Errorf("%s: %w", summaryVariantsFileEnv, err)
+		}
+		for _, v := range variants {
+			if v.SystemPromptTemplateFile == "" {
+				continue
+			}
+			if err := validatePromptTemplate(v.SystemPromptTemplateFile, "the template of variant "+v.Name); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	var tiers []SummaryTier
+	if path := os.Getenv(summaryTiersFileEnv); path != "" {
+		contents, err := os.ReadFile(path) // nolint: gosec
+		if err != nil {
+			return "", fmt.Errorf("reading %s: %w", summaryTiersFileEnv, err)
+		}
+		if tiers, err = parseSummaryTiers(contents); err != nil {
+			return "", fmt.Errorf("%s: %w", summaryTiersFileEnv, err)
+		}
+	}
+
+	description, err := validateSummarizer()
+	if err != nil {
+		return "", err
+	}
+	if len(tiers) > 0 {
+		description = fmt.Sprintf("%s, %d model tiers", description, len(tiers))
+	}
+	if len(variants) > 0 {
+		description = fmt.Sprintf("%s, %d variants", description, len(variants))
+	}
+	return description, nil
+}
+
+func validateSummarizer() (string, error) {
+	if summarizerCommand := os.Getenv(execSummarizerCommandEnv); summarizerCommand != "" {
+		if _, err := exec.LookPath("sh"); err != nil {
+			return "", fmt.Errorf("%s requires sh: %w", execSummarizerCommandEnv, err)
+		}
+		return fmt.Sprintf("command %q", summarizerCommand), nil
+	}
+
+	if os.Getenv(openRouterAPIKeyEnv) == "" {
+		return fmt.Sprintf("disabled, %s isn't set", openRouterAPIKeyEnv), nil
+	}
+	if allowFallbacks := os.Getenv(openRouterProviderAllowFallbacksEnv); allowFallbacks != "" {
+		if _, err := strconv.ParseBool(allowFallbacks); err != nil {
+			return "", fmt.Errorf("invalid %s %q: %w", openRouterProviderAllowFallbacksEnv, allowFallbacks, err)
+		}
+	}
+	return fmt.Sprintf("OpenRouter, model %s", summarizerModel()), nil
+}
+
+// validatePromptTemplate checks the system prompt template at path, named
+// name in errors, renders.
+func validatePromptTemplate(path string, name string) error {
+	contents, err := os.ReadFile(path) // nolint: gosec
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", name, err)
+	}
+	tmpl, err := template.New("prompt").Parse(string(contents))
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", name, err)
+	}
+	if err := tmpl.Execute(io.Discard, SummaryPromptData{}); err != nil {
+		return fmt.Errorf("rendering %s: %w", name, err)
+	}
+	return nil
+}
+
+// ProbeSummarizer checks OpenRouter is reachable and accepts the API key. It's
+// a no-op if plans aren't summarized with OpenRouter.
+func ProbeSummarizer(client *http.Client) error {
+	apiKey := os.Getenv(openRouterAPIKeyEnv)
+	if apiKey == "" || os.Getenv(execSummarizerCommandEnv) != "" {
+		return nil
+	}
+	url := openRouterURL
+	if override := os.Getenv(openRouterURLEnv); override != "" {
+		url = override
+	}
+	// The key endpoint describes the API key without using any credits.
+	url = strings.TrimSuffix(url, "/chat/completions") + "/key"
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("OpenRouter returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestValidateSummarizerConfig(t *testing.T) {
+	t.Setenv("OPENROUTER_API_KEY", "")
+	t.Setenv("TERRAFORM_PLAN_SUMMARIZER_COMMAND", "")
+	t.Setenv("OPENROUTER_TERRAFORM_PLAN_SUMMARIZER_SYSTEM_PROMPT_TEMPLATE_FILE", "")
+	t.Setenv("OPENROUTER_TERRAFORM_PLAN_SUMMARIZER_MODEL", "")
+	t.Setenv("OPENROUTER_TERRAFORM_PLAN_SUMMARIZER_VARIANTS_FILE", "")
+	t.Setenv("OPENROUTER_TERRAFORM_PLAN_SUMMARIZER_TIERS_FILE", "")
+
+	description, err := events.ValidateSummarizerConfig()
+	Ok(t, err)
+	Equals(t, "disabled, OPENROUTER_API_KEY isn't set", description)
+
+	t.Setenv("OPENROUTER_API_KEY", "key")
+	description, err = events.ValidateSummarizerConfig()
+	Ok(t, err)
+	Equals(t, "OpenRouter, model anthropic/claude-opus-4.8", description)
+
+	t.Setenv("OPENROUTER_PROVIDER_ALLOW_FALLBACKS", "maybe")
+	_, err = events.ValidateSummarizerConfig()
+	ErrEquals(t, `invalid OPENROUTER_PROVIDER_ALLOW_FALLBACKS "maybe": strconv.ParseBool: parsing "maybe": invalid syntax`, err)
+
+	t.Setenv("TERRAFORM_PLAN_SUMMARIZER_COMMAND", "summarize")
+	description, err = events.ValidateSummarizerConfig()
+	Ok(t, err)
+	Equals(t, `command "summarize"`, description)
+
+	writeSummaryVariants(t, "variants:\n- name: control\n  weight: 1\n- name: concise\n  weight: 1\n")
+	description, err = events.ValidateSummarizerConfig()
+	Ok(t, err)
+	Equals(t, `command "summarize", 2 variants`, description)
+	writeSummaryVariants(t, "variants:\n- name: control\n")
+	_, err = events.ValidateSummarizerConfig()
+	ErrEquals(t, "OPENROUTER_TERRAFORM_PLAN_SUMMARIZER_VARIANTS_FILE: weight of variant control must be positive", err)
+	t.Setenv("OPENROUTER_TERRAFORM_PLAN_SUMMARIZER_VARIANTS_FILE", "")
+
+	writeSummaryTiers(t, "tiers:\n- model: openai/gpt-5-mini\n  max_bytes: 20000\n")
+	description, err = events.ValidateSummarizerConfig()
+	Ok(t, err)
+	Equals(t, `command "summarize", 1 model tiers`, description)
+	writeSummaryTiers(t, "tiers:\n- model: openai/gpt-5-mini\n")
+	_, err = events.ValidateSummarizerConfig()
+	ErrEquals(t, "OPENROUTER_TERRAFORM_PLAN_SUMMARIZER_TIERS_FILE: tier openai/gpt-5-mini must set max_bytes or max_tokens", err)
+	t.Setenv("OPENROUTER_TERRAFORM_PLAN_SUMMARIZER_TIERS_FILE", "")
+
+	template := filepath.Join(t.TempDir(), "prompt.tmpl")
+	Ok(t, os.WriteFile(template, []byte("Summarize {{ .RepoName "), 0600))
+	t.Setenv("OPENROUTER_TERRAFORM_PLAN_SUMMARIZER_SYSTEM_PROMPT_TEMPLATE_FILE", template)
+	_, err = events.ValidateSummarizerConfig()
+	Assert(t, err != nil, "exp invalid template error")
+}
+
+func TestProbeSummarizer(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Equals(t, "/api/v1/key", r.URL.Path)
+		Equals(t, "Bearer key", r.Header.Get("Authorization"))
+		w.WriteHeader(status)
+		w.Write([]byte(`{"error": {"message": "invalid key"}}`)) // nolint: errcheck
+	}))
+	defer server.Close()
+	t.Setenv("OPENROUTER_API_URL", server.URL+"/api/v1/chat/completions")
+	t.Setenv("OPENROUTER_API_KEY", "key")
+	t.Setenv("TERRAFORM_PLAN_SUMMARIZER_COMMAND", "")
+
+	Ok(t, events.ProbeSummarizer(http.DefaultClient))
+
+	status = http.StatusUnauthorized
+	ErrEquals(t, `OpenRouter returned status 401: {"error": {"message": "invalid key"}}`, events.ProbeSummarizer(http.DefaultClient))
+
+	t.Setenv("OPENROUTER_API_KEY", "")
+	Ok(t, events.ProbeSummarizer(http.DefaultClient))
+}
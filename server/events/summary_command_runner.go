@@ -0,0 +1,154 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+)
+
+// savedPlanOutput is the output of a plan saved next to its planfile so the
+// plan can be summarized again, or reused, without planning.
+type savedPlanOutput struct {
+	TerraformOutput string `json:"terraform_output"`
+	PlanJSON        string `json:"plan_json,omitempty"`
+	PlanGraph       string `json:"plan_graph,omitempty"`
+	// ContentKey identifies the content the plan was generated from, it's
+	// empty if plans aren't reused.
+	ContentKey string `json:"content_key,omitempty"`
+	// BaseCommit is the commit of the base branch the plan was generated
+	// from, it's only recorded for the fresh apply requirement.
+	BaseCommit string `json:"base_commit,omitempty"`
+}
+
+// savePlanOutput saves the output of the plan of the project described by
+// ctx, encrypted like the planfile if encryptor isn't nil since it can contain
+// the same values. Failing to save it only prevents summarizing or reusing the
+// plan so it's logged.
+func savePlanOutput(ctx command.ProjectContext, encryptor *runtime.PlanfileEncryptor, absPath string, saved savedPlanOutput) {
+	contents, err := json.Marshal(saved)
+	if err == nil && encryptor != nil {
+		contents, err = encryptor.EncryptFile(ctx.GetPlanOutputFileName(), contents)
+	}
+	if err == nil {
+		err = os.WriteFile(filepath.Join(absPath, ctx.GetPlanOutputFileName()), contents, 0600)
+	}
+	if err != nil {
+		ctx.Log.Warn("unable to save the plan output: %s", err)
+	}
+}
+
+// readPlanOutput reads the saved output of the plan of the project described
+// by ctx in absPath.
+func readPlanOutput(ctx command.ProjectContext, encryptor *runtime.PlanfileEncryptor, absPath string) (savedPlanOutput, error) {
+	var saved savedPlanOutput
+	contents, err := os.ReadFile(filepath.Join(absPath, ctx.GetPlanOutputFileName()))
+	if err != nil {
+		return saved, err
+	}
+	if encryptor != nil {
+		if contents, err = encryptor.DecryptFile(ctx.GetPlanOutputFileName(), contents); err != nil {
+			return saved, err
+		}
+	}
+	err = json.Unmarshal(contents, &saved)
+	return saved, err
+}
+
+// SummaryCommandRunner regenerates the summary of the current plans of a pull
+// request from their saved output without running terraform, ex. after the
+// summarizer prompt changed or the summary failed.
+type SummaryCommandRunner struct {
+	VCSClient             vcs.Client
+	PullUpdater           *PullUpdater
+	ProjectCommandBuilder ProjectApplyCommandBuilder
+	WorkingDir            WorkingDir
+	// PlanfileEncryptor decrypts the saved plan outputs. Nil if planfiles
+	// aren't encrypted.
+	PlanfileEncryptor *runtime.PlanfileEncryptor
+}
+
+func (s *SummaryCommandRunner) Run(ctx *command.Context, cmd *CommentCommand) {
+	// The projects to summarize are the ones that would be applied.
+	projectCmds, err := s.ProjectCommandBuilder.BuildApplyCommands(ctx, cmd)
+	if err != nil {
+		s.comment(ctx, fmt.Sprintf("**Summary Error**\n```\n%s\n```", err))
+		return
+	}
+
+	result := runProjectCmds(projectCmds, s.loadPlan)
+	var missing []string
+	for _, projectResult := range result.ProjectResults {
+		if projectResult.Failure != "" {
+			missing = append(missing, fmt.Sprintf("* dir: `%s` workspace: `%s`: %s", projectResult.RepoRelDir, projectResult.Workspace, projectResult.Failure))
+		}
+	}
+	if len(missing) == len(result.ProjectResults) {
+		s.comment(ctx, fmt.Sprintf("No plans to summarize, run `%s` first.%s", command.Plan.String(), missingPlans(missing)))
+		return
+	}
+
+	summaryBlock := s.PullUpdater.summarize(ctx, result.ProjectResults)
+	if summaryBlock == "" {
+		s.comment(ctx, "**Summary Error**: the plans couldn't be summarized, check the summarizer is configured and see the Atlantis logs for details.")
+		return
+	}
+	s.comment(ctx, summaryBlock+missingPlans(missing))
+}
+
+// loadPlan loads the saved output of the current plan of the project described
+// by ctx.
+func (s *SummaryCommandRunner) loadPlan(ctx command.ProjectContext) command.ProjectCommandOutput {
+	return LoadSavedPlan(s.WorkingDir, s.PlanfileEncryptor, ctx)
+}
+
+// LoadSavedPlan loads the saved output of the current plan of the project
+// described by ctx from workingDir, decrypting it with encryptor if it isn't
+// nil. The output's Failure says why if there's no plan or its output wasn't
+// saved.
+func LoadSavedPlan(workingDir WorkingDir, encryptor *runtime.PlanfileEncryptor, ctx command.ProjectContext) command.ProjectCommandOutput {
+	repoDir, err := workingDir.GetWorkingDir(ctx.Pull.BaseRepo, ctx.Pull, ctx.Workspace)
+	if err != nil {
+		return command.ProjectCommandOutput{Failure: "no plan found"}
+	}
+	absPath := filepath.Join(repoDir, ctx.RepoRelDir)
+	if _, err := os.Stat(filepath.Join(absPath, runtime.GetPlanFilename(ctx.Workspace, ctx.ProjectName))); err != nil {
+		return command.ProjectCommandOutput{Failure: "no plan found"}
+	}
+	saved, err := readPlanOutput(ctx, encryptor, absPath)
+	if os.IsNotExist(err) {
+		return command.ProjectCommandOutput{Failure: "the plan's output wasn't saved, run plan again"}
+	}
+	if err != nil {
+		return command.ProjectCommandOutput{Failure: fmt.Sprintf("reading the plan's output: %s", err)}
+	}
+	return command.ProjectCommandOutput{
+		PlanSuccess: &models.PlanSuccess{
+			TerraformOutput: saved.TerraformOutput,
+			PlanJSON:        saved.PlanJSON,
+		},
+	}
+}
+
+func (s *SummaryCommandRunner) comment(ctx *command.Context, comment string) {
+	if err := s.VCSClient.CreateComment(ctx.Log, ctx.Pull.BaseRepo, ctx.Pull.Num, comment, command.Summary.String()); err != nil {
+		ctx.Log.Err("unable to comment: %s", err)
+	}
+}
+
+// missingPlans lists the projects that weren't summarized.
+func missingPlans(missing []string) string {
+	if len(missing) == 0 {
+		return ""
+	}
+	return "\n\nNot summarized:\n" + strings.Join(missing, "\n")
+}
@@ -0,0 +1,79 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/models"
+	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestSummaryCommandRunner_Run(t *testing.T) {
+	t.Setenv("OPENROUTER_API_KEY", "")
+	t.Setenv("TERRAFORM_PLAN_SUMMARIZER_COMMAND", "tr a-z A-Z")
+	RegisterMockTestingT(t)
+
+	repoDir := t.TempDir()
+	Ok(t, os.MkdirAll(filepath.Join(repoDir, "network"), 0700))
+	Ok(t, os.MkdirAll(filepath.Join(repoDir, "app"), 0700))
+	// network has a plan with its output saved, app only has a plan.
+	Ok(t, os.WriteFile(filepath.Join(repoDir, "network", "default.tfplan"), nil, 0600))
+	Ok(t, os.WriteFile(filepath.Join(repoDir, "network", "default-planout.json"), []byte(`{"terraform_output":"Plan: 1 to add\n"}`), 0600))
+	Ok(t, os.WriteFile(filepath.Join(repoDir, "app", "default.tfplan"), nil, 0600))
+
+	pull := models.PullRequest{Num: 1, BaseRepo: models.Repo{FullName: "owner/repo"}}
+	ctx := &command.Context{Log: logging.NewNoopLogger(t), Pull: pull}
+	cmd := &events.CommentCommand{Name: command.Summary}
+	projectCmds := []command.ProjectContext{
+		{Log: ctx.Log, Pull: pull, RepoRelDir: "network", Workspace: "default"},
+		{Log: ctx.Log, Pull: pull, RepoRelDir: "app", Workspace: "default"},
+	}
+
+	vcsClient := vcsmocks.NewMockClient()
+	builder := mocks.NewMockProjectCommandBuilder()
+	When(builder.BuildApplyCommands(ctx, cmd)).ThenReturn(projectCmds, nil)
+	workingDir := mocks.NewMockWorkingDir()
+	When(workingDir.GetWorkingDir(pull.BaseRepo, pull, "default")).ThenReturn(repoDir, nil)
+	runner := &events.SummaryCommandRunner{
+		VCSClient:             vcsClient,
+		PullUpdater:           &events.PullUpdater{VCSClient: vcsClient},
+		ProjectCommandBuilder: builder,
+		WorkingDir:            workingDir,
+	}
+
+	runner.Run(ctx, cmd)
+	vcsClient.VerifyWasCalledOnce().CreateComment(Any[logging.SimpleLogging](), Eq(pull.BaseRepo), Eq(1),
+		Eq("### Plan Summary (AI generated by Topher's AI)\n\nPLAN: 1 TO ADD\n\n"+
+			"Not summarized:\n* dir: `app` workspace: `default`: the plan's output wasn't saved, run plan again"), Eq("summary"))
+}
+
+func TestSummaryCommandRunner_NoPlans(t *testing.T) {
+	RegisterMockTestingT(t)
+	pull := models.PullRequest{Num: 1, BaseRepo: models.Repo{FullName: "owner/repo"}}
+	ctx := &command.Context{Log: logging.NewNoopLogger(t), Pull: pull}
+	cmd := &events.CommentCommand{Name: command.Summary}
+
+	vcsClient := vcsmocks.NewMockClient()
+	builder := mocks.NewMockProjectCommandBuilder()
+	When(builder.BuildApplyCommands(ctx, cmd)).ThenReturn(nil, nil)
+	runner := &events.SummaryCommandRunner{
+		VCSClient:             vcsClient,
+		PullUpdater:           &events.PullUpdater{VCSClient: vcsClient},
+		ProjectCommandBuilder: builder,
+		WorkingDir:            mocks.NewMockWorkingDir(),
+	}
+
+	runner.Run(ctx, cmd)
+	vcsClient.VerifyWasCalledOnce().CreateComment(Any[logging.SimpleLogging](), Eq(pull.BaseRepo), Eq(1),
+		Eq("No plans to summarize, run `plan` first."), Eq("summary"))
+}
@@ -0,0 +1,197 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/db"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	tally "github.com/uber-go/tally/v4"
+)
+
+const (
+	// summaryFeedbackPollIntervalEnv enables feedback tracking and sets how
+	// often reactions on summary comments are polled, ex. 10m.
+	summaryFeedbackPollIntervalEnv = "TERRAFORM_PLAN_SUMMARY_FEEDBACK_POLL_INTERVAL"
+	// summaryFeedbackRetention is how long after a summary is generated its
+	// reactions are polled for.
+	summaryFeedbackRetention = 14 * 24 * time.Hour
+	// summaryFeedbackPerRepo is how many of the most recent summaries' feedback
+	// is kept per repo, older feedback is deleted.
+	summaryFeedbackPerRepo = 500
+)
+
+// summaryFeedbackMarkerRegex matches the hidden marker that identifies a
+// summary comment.
+var summaryFeedbackMarkerRegex = regexp.MustCompile(`<!-- atlantis-summary-feedback: (\S+) -->`)
+
+// CommentReactionsLister lists the reactions on the comments Atlantis left on
+// a pull request.
+type CommentReactionsLister interface {
+	ListCommentReactions(logger logging.SimpleLogging, repo models.Repo, pullNum int) ([]models.CommentReactions, error)
+}
+
+// SummaryFeedbackTracker records the 👍 and 👎 reactions on plan summary
+// comments along with the variant, model and prompt that generated the
+// summary, and publishes them as metrics so prompts can be tuned based on real
+// feedback.
+// It implements scheduled.Job, each run polls the reactions.
+type SummaryFeedbackTracker struct {
+	DB         db.Database
+	Reactions  CommentReactionsLister
+	Logger     logging.SimpleLogging
+	StatsScope tally.Scope
+}
+
+// SummaryFeedbackPollInterval returns how often summary feedback should be
+// polled or 0 if feedback tracking isn't enabled.
+func SummaryFeedbackPollInterval() (time.Duration, error) {
+	interval := os.Getenv(summaryFeedbackPollIntervalEnv)
+	if interval == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(interval)
+	if err != nil {
+		return 0, fmt.Errorf("parsing %s: %w", summaryFeedbackPollIntervalEnv, err)
+	}
+	return d, nil
+}
+
+// Track records that summary is about to be posted to pull and returns the
+// marker to embed in the summary comment. It returns an empty string if the
+// summary can't be tracked.
+func (t *SummaryFeedbackTracker) Track(logger logging.SimpleLogging, pull models.PullRequest, summary GeneratedSummary) string {
+	if pull.BaseRepo.VCSHost.Type != models.Github {
+		return ""
+	}
+	now := time.Now()
+	feedback := models.SummaryFeedback{
+		ID:         models.NewSummaryFeedbackID(pull.BaseRepo.FullName, pull.Num, now),
+		Repo:       pull.BaseRepo,
+		PullNum:    pull.Num,
+		Variant:    summary.Variant,
+		Model:      summary.Model,
+		PromptHash: summary.PromptHash,
+		Latency:    summary.Latency,
+		Cost:       summary.Cost,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := t.DB.SaveSummaryFeedback(feedback); err != nil {
+		logger.Warn("unable to track summary feedback: %s", err)
+		return ""
+	}
+	return fmt.Sprintf("<!-- atlantis-summary-feedback: %s -->", feedback.ID)
+}
+
+// Run deletes the oldest feedback of repos over the cap, polls the reactions
+// on recent summary comments and publishes the aggregate feedback metrics.
+func (t *SummaryFeedbackTracker) Run() {
+	feedback, err := t.DB.ListSummaryFeedback()
+	if err != nil {
+		t.Logger.Warn("unable to list summary feedback: %s", err)
+		return
+	}
+	feedback = t.prune(feedback)
+	t.poll(feedback)
+	t.publish(feedback)
+}
+
+// prune deletes the feedback of each repo beyond the summaryFeedbackPerRepo
+// most recent and returns the feedback that's kept.
+func (t *SummaryFeedbackTracker) prune(feedback []models.SummaryFeedback) []models.SummaryFeedback {
+	sort.SliceStable(feedback, func(i, j int) bool {
+		return feedback[i].CreatedAt.After(feedback[j].CreatedAt)
+	})
+	perRepo := make(map[string]int)
+	var kept []models.SummaryFeedback
+	for _, f := range feedback {
+		perRepo[f.Repo.FullName]++
+		if perRepo[f.Repo.FullName] <= summaryFeedbackPerRepo {
+			kept = append(kept, f)
+			continue
+		}
+		if err := t.DB.DeleteSummaryFeedback(f.ID); err != nil {
+			t.Logger.Warn("unable to delete summary feedback: %s", err)
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// poll updates feedback, in place, with the reactions on their comments.
+func (t *SummaryFeedbackTracker) poll(feedback []models.SummaryFeedback) {
+	type pullKey struct {
+		repo string
+		num  int
+	}
+	byID := make(map[string]*models.SummaryFeedback)
+	pulls := make(map[pullKey]models.Repo)
+	for i := range feedback {
+		f := &feedback[i]
+		if time.Since(f.CreatedAt) > summaryFeedbackRetention {
+			continue
+		}
+		byID[f.ID] = f
+		pulls[pullKey{f.Repo.FullName, f.PullNum}] = f.Repo
+	}
+
+	for key, repo := range pulls {
+		reactions, err := t.Reactions.ListCommentReactions(t.Logger, repo, key.num)
+		if err != nil {
+			t.Logger.Warn("unable to list reactions on %s#%d: %s", key.repo, key.num, err)
+			continue
+		}
+		for _, r := range reactions {
+			match := summaryFeedbackMarkerRegex.FindStringSubmatch(r.Body)
+			if match == nil {
+				continue
+			}
+			f, ok := byID[match[1]]
+			if !ok || (f.CommentID == r.CommentID && f.ThumbsUp == r.ThumbsUp && f.ThumbsDown == r.ThumbsDown) {
+				continue
+			}
+			f.CommentID, f.ThumbsUp, f.ThumbsDown, f.UpdatedAt = r.CommentID, r.ThumbsUp, r.ThumbsDown, time.Now()
+			if err := t.DB.SaveSummaryFeedback(*f); err != nil {
+				t.Logger.Warn("unable to save summary feedback: %s", err)
+			}
+		}
+	}
+}
+
+// publish updates the feedback gauges, tagged by variant, model and prompt.
+func (t *SummaryFeedbackTracker) publish(feedback []models.SummaryFeedback) {
+	scope := t.StatsScope.SubScope("summary_feedback")
+	for _, stats := range CompareSummaryVariants(feedback) {
+		tags := map[string]string{"model": stats.Model, "prompt": stats.PromptHash}
+		if stats.Variant != "" {
+			tags["variant"] = stats.Variant
+		}
+		tagged := scope.Tagged(tags)
+		tagged.Gauge("summaries").Update(float64(stats.Summaries))
+		tagged.Gauge("thumbs_up").Update(float64(stats.ThumbsUp))
+		tagged.Gauge("thumbs_down").Update(float64(stats.ThumbsDown))
+		tagged.Gauge("latency_seconds").Update(stats.AverageLatency.Seconds())
+		tagged.Gauge("cost").Update(stats.TotalCost)
+	}
+}
+
+// summarizerIdentity returns the model, or summarizer command if one is
+// configured, and the hash of promptSource, the system prompt or its
+// template, identifying what generates summaries.
+func summarizerIdentity(model string, promptSource string) (string, string) {
+	if summarizerCommand := os.Getenv(execSummarizerCommandEnv); summarizerCommand != "" {
+		model = "command:" + summarizerCommand
+	}
+	sum := sha256.Sum256([]byte(promptSource))
+	return model, hex.EncodeToString(sum[:])[:12]
+}
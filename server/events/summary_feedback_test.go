@@ -0,0 +1,112 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/boltdb"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+	tally "github.com/uber-go/tally/v4"
+)
+
+// fakeCommentReactions returns the same comments for every pull request.
+type fakeCommentReactions struct {
+	comments []models.CommentReactions
+}
+
+func (f *fakeCommentReactions) ListCommentReactions(_ logging.SimpleLogging, _ models.Repo, _ int) ([]models.CommentReactions, error) {
+	return f.comments, nil
+}
+
+func TestSummaryFeedbackTracker(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	database, err := boltdb.New(t.TempDir())
+	Ok(t, err)
+	reactions := &fakeCommentReactions{}
+	scope := tally.NewTestScope("", nil)
+	tracker := &events.SummaryFeedbackTracker{
+		DB:         database,
+		Reactions:  reactions,
+		Logger:     logger,
+		StatsScope: scope,
+	}
+
+	summary := events.GeneratedSummary{Summary: "summary", Variant: "concise", Model: "model", PromptHash: "0123456789ab", Latency: 2 * time.Second, Cost: 0.01}
+
+	t.Log("summaries on other VCS hosts aren't tracked")
+	Equals(t, "", tracker.Track(logger, models.PullRequest{Num: 1, BaseRepo: models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Type: models.Gitlab}}}, summary))
+
+	marker := tracker.Track(logger, models.PullRequest{Num: 1, BaseRepo: models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Type: models.Github}}}, summary)
+	Assert(t, marker != "", "exp marker")
+	reactions.comments = []models.CommentReactions{
+		{CommentID: 1, Body: "### Plan", ThumbsUp: 5},
+		{CommentID: 2, Body: "### Plan Summary\n\nsummary\n\n" + marker, ThumbsUp: 2, ThumbsDown: 1},
+	}
+
+	tracker.Run()
+	feedback, err := database.ListSummaryFeedback()
+	Ok(t, err)
+	Equals(t, 1, len(feedback))
+	Equals(t, int64(2), feedback[0].CommentID)
+	Equals(t, 2, feedback[0].ThumbsUp)
+	Equals(t, 1, feedback[0].ThumbsDown)
+	Equals(t, "model", feedback[0].Model)
+	Equals(t, "concise", feedback[0].Variant)
+	Equals(t, 2*time.Second, feedback[0].Latency)
+
+	gauges := make(map[string]float64)
+	for _, g := range scope.Snapshot().Gauges() {
+		Equals(t, map[string]string{"model": "model", "prompt": "0123456789ab", "variant": "concise"}, g.Tags())
+		gauges[g.Name()] = g.Value()
+	}
+	Equals(t, map[string]float64{
+		"summary_feedback.summaries":       1,
+		"summary_feedback.thumbs_up":       2,
+		"summary_feedback.thumbs_down":     1,
+		"summary_feedback.latency_seconds": 2,
+		"summary_feedback.cost":            0.01,
+	}, gauges)
+}
+
+func TestSummaryFeedbackTracker_CapsFeedbackPerRepo(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	database, err := boltdb.New(t.TempDir())
+	Ok(t, err)
+	tracker := &events.SummaryFeedbackTracker{
+		DB:         database,
+		Reactions:  &fakeCommentReactions{},
+		Logger:     logger,
+		StatsScope: tally.NewTestScope("", nil),
+	}
+
+	created := time.Now().Add(-time.Hour)
+	for i := 0; i < 501; i++ {
+		createdAt := created.Add(time.Duration(i) * time.Second)
+		Ok(t, database.SaveSummaryFeedback(models.SummaryFeedback{
+			ID:        models.NewSummaryFeedbackID("owner/repo", i, createdAt),
+			Repo:      models.Repo{FullName: "owner/repo"},
+			PullNum:   i,
+			CreatedAt: createdAt,
+		}))
+	}
+	Ok(t, database.SaveSummaryFeedback(models.SummaryFeedback{
+		ID:        models.NewSummaryFeedbackID("owner/other", 1, created),
+		Repo:      models.Repo{FullName: "owner/other"},
+		PullNum:   1,
+		CreatedAt: created,
+	}))
+
+	tracker.Run()
+	feedback, err := database.ListSummaryFeedback()
+	Ok(t, err)
+	Equals(t, 501, len(feedback))
+	for _, f := range feedback {
+		Assert(t, f.Repo.FullName != "owner/repo" || f.PullNum != 0, "exp the oldest feedback of owner/repo to be deleted")
+	}
+}
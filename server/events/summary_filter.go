@@ -0,0 +1,60 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"os"
+	"regexp"
+	"strings"
+)
+
+// summaryFilterDisableEnv disables filtering plan output before it's
+// summarized when set to true.
+const summaryFilterDisableEnv = "TERRAFORM_PLAN_SUMMARIZER_DISABLE_FILTER"
+
+// noopPlanLineRegexes match plan output lines that carry no information
+// about the changes: refresh output, init and provider download chatter and
+// the placeholders for unchanged attributes.
+var noopPlanLineRegexes = []*regexp.Regexp{
+	// Refresh output, ex. "aws_instance.web: Refreshing state... [id=i-123]".
+	regexp.MustCompile(`: (Refreshing state\.\.\.|Reading\.\.\.|Read complete after \S+)`),
+	// Init output and provider download chatter.
+	regexp.MustCompile(`^(Initializing (the backend|modules|provider plugins)\.\.\.|Upgrading modules\.\.\.)`),
+	regexp.MustCompile(`^- (Finding|Installing|Installed|Using previously-installed|Reusing previous version of|Downloading) `),
+	regexp.MustCompile(`^(Downloading|Terraform has created a lock file|OpenTofu has created a lock file|Partner and community providers are signed|Terraform has been successfully initialized|OpenTofu has been successfully initialized)`),
+	// Placeholders for unchanged attributes and blocks, ex.
+	// "# (12 unchanged attributes hidden)".
+	regexp.MustCompile(`^# \(\d+ unchanged (attributes?|blocks?|elements?) hidden\)$`),
+	// Horizontal rules separating the sections of the output.
+	regexp.MustCompile(`^─+$`),
+}
+
+// multipleBlankLinesRegex matches runs of blank lines left after filtering.
+var multipleBlankLinesRegex = regexp.MustCompile(`\n{3,}`)
+
+// filterPlanForSummary strips the lines of a plan's output that don't
+// describe changes so fewer tokens are sent to the summarizer.
+func filterPlanForSummary(output string) string {
+	if os.Getenv(summaryFilterDisableEnv) == "true" {
+		return output
+	}
+	lines := strings.Split(output, "\n")
+	filtered := lines[:0]
+	for _, line := range lines {
+		if isNoopPlanLine(strings.TrimSpace(line)) {
+			continue
+		}
+		filtered = append(filtered, line)
+	}
+	return strings.TrimSpace(multipleBlankLinesRegex.ReplaceAllString(strings.Join(filtered, "\n"), "\n\n"))
+}
+
+func isNoopPlanLine(line string) bool {
+	for _, r := range noopPlanLineRegexes {
+		if r.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
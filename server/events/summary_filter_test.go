@@ -0,0 +1,58 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+const noisyPlan = `Initializing the backend...
+Initializing provider plugins...
+- Finding hashicorp/aws versions matching "~> 5.0"...
+- Installing hashicorp/aws v5.1.0...
+- Installed hashicorp/aws v5.1.0 (signed by HashiCorp)
+
+Terraform has been successfully initialized!
+aws_s3_bucket.logs: Refreshing state... [id=logs]
+aws_instance.web: Refreshing state... [id=i-123]
+data.aws_ami.ubuntu: Reading...
+data.aws_ami.ubuntu: Read complete after 1s [id=ami-123]
+
+
+
+Terraform will perform the following actions:
+
+  # aws_instance.web will be updated in-place
+  ~ resource "aws_instance" "web" {
+        id            = "i-123"
+      ~ instance_type = "t3.micro" -> "t3.small"
+        # (30 unchanged attributes hidden)
+
+        # (2 unchanged blocks hidden)
+    }
+
+Plan: 0 to add, 1 to change, 0 to destroy.`
+
+func TestSummarizePlans_Filter(t *testing.T) {
+	t.Setenv("TERRAFORM_PLAN_SUMMARIZER_COMMAND", "cat")
+	summary := events.SummarizePlans([]string{noisyPlan}, events.SummaryPromptData{}, logging.NewNoopLogger(t))
+	Equals(t, `Terraform will perform the following actions:
+
+  # aws_instance.web will be updated in-place
+  ~ resource "aws_instance" "web" {
+        id            = "i-123"
+      ~ instance_type = "t3.micro" -> "t3.small"
+
+    }
+
+Plan: 0 to add, 1 to change, 0 to destroy.`, summary)
+
+	t.Setenv("TERRAFORM_PLAN_SUMMARIZER_DISABLE_FILTER", "true")
+	summary = events.SummarizePlans([]string{noisyPlan}, events.SummaryPromptData{}, logging.NewNoopLogger(t))
+	Equals(t, noisyPlan, summary)
+}
@@ -0,0 +1,131 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+// summaryPlanJSONEnv enables summarizing the JSON representation of plans,
+// from terraform show -json, instead of their human-readable output.
+const summaryPlanJSONEnv = "TERRAFORM_PLAN_SUMMARIZER_JSON"
+
+// showPlanJSON is the subset of terraform show -json output that's sent to
+// the summarizer.
+type showPlanJSON struct {
+	ResourceChanges []struct {
+		Address string `json:"address"`
+		Change  struct {
+			Actions []string       `json:"actions"`
+			Before  map[string]any `json:"before"`
+			After   map[string]any `json:"after"`
+		} `json:"change"`
+	} `json:"resource_changes"`
+}
+
+// summaryResourceChange is a resource change as sent to the summarizer.
+type summaryResourceChange struct {
+	Address string   `json:"address"`
+	Actions []string `json:"actions"`
+	// ChangedAttributes are the top level attributes that are updated in
+	// place. They're omitted for creates and deletes.
+	ChangedAttributes []string `json:"changed_attributes,omitempty"`
+}
+
+// summaryPlanJSONEnabled returns true if plans are summarized from their
+// JSON representation.
+func summaryPlanJSONEnabled() bool {
+	return os.Getenv(summaryPlanJSONEnv) == "true"
+}
+
+// compactPlanJSON reduces the output of terraform show -json to the
+// resource changes with their actions. No-op and read changes are dropped.
+func compactPlanJSON(show string) (string, error) {
+	var plan showPlanJSON
+	if err := json.Unmarshal([]byte(show), &plan); err != nil {
+		return "", fmt.Errorf("parsing plan json: %w", err)
+	}
+	changes := []summaryResourceChange{}
+	for _, rc := range plan.ResourceChanges {
+		actions := rc.Change.Actions
+		if len(actions) == 0 || slices.Equal(actions, []string{"no-op"}) || slices.Equal(actions, []string{"read"}) {
+			continue
+		}
+		change := summaryResourceChange{
+			Address: rc.Address,
+			Actions: actions,
+		}
+		if slices.Equal(actions, []string{"update"}) {
+			change.ChangedAttributes = changedAttributes(rc.Change.Before, rc.Change.After)
+		}
+		changes = append(changes, change)
+	}
+	out, err := json.Marshal(map[string]any{"resource_changes": changes})
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// changedAttributes returns the sorted names of the attributes whose values
+// differ between before and after.
+func changedAttributes(before map[string]any, after map[string]any) []string {
+	var changed []string
+	for k, v := range after {
+		if !reflect.DeepEqual(before[k], v) {
+			changed = append(changed, k)
+		}
+	}
+	for k := range before {
+		if _, ok := after[k]; !ok {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// showPlan runs terraform show -json on the plan of the project described by
+// ctx if the JSON plan is needed, to summarize, export or graph it or to
+// remediate drift. It returns an empty string if it isn't needed or is
+// unavailable.
+func (p *DefaultProjectCommandRunner) showPlan(ctx command.ProjectContext, absPath string) string {
+	driftPullRequests := p.DriftPullRequests != nil && ctx.Pull.Num == 0
+	if (!summaryPlanJSONEnabled() && p.PlanJSONs == nil && !p.PlanGraphs && !driftPullRequests) || p.ShowStepRunner == nil {
+		return ""
+	}
+	if !slices.ContainsFunc(ctx.Steps, func(s valid.Step) bool { return s.StepName == "plan" }) {
+		return ""
+	}
+	show, err := p.ShowStepRunner.Run(ctx, nil, absPath, map[string]string{})
+	if err != nil {
+		ctx.Log.Warn("unable to show plan as json: %s", err)
+		return ""
+	}
+	return show
+}
+
+// planJSONForSummary returns show, the output of terraform show -json on the
+// plan, compacted for the summarizer. It returns an empty string if the JSON
+// plan is disabled or unavailable, in which case the plan's text output is
+// summarized instead.
+func planJSONForSummary(ctx command.ProjectContext, show string) string {
+	if !summaryPlanJSONEnabled() || show == "" {
+		return ""
+	}
+	compacted, err := compactPlanJSON(show)
+	if err != nil {
+		ctx.Log.Warn("unable to compact plan for the summary, falling back to the plan output: %s", err)
+		return ""
+	}
+	return compacted
+}
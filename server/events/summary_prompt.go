@@ -0,0 +1,142 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// summaryPromptTemplateFileEnv is the path to a Go template rendered into
+// the system prompt. It takes precedence over the system prompt variable.
+const summaryPromptTemplateFileEnv = "OPENROUTER_TERRAFORM_PLAN_SUMMARIZER_SYSTEM_PROMPT_TEMPLATE_FILE"
+
+// environmentNames are directory names that are detected as environments.
+var environmentNames = []string{"dev", "development", "test", "qa", "uat", "stage", "staging", "preprod", "prod", "production", "sandbox"}
+
+// SummaryPromptData is the data the system prompt template is rendered with.
+type SummaryPromptData struct {
+	// RepoName is the full name of the repo, ex. runatlantis/atlantis.
+	RepoName  string
+	PullNum   int
+	PullTitle string
+	Projects  []SummaryPromptProject
+	// Environments are the environments the projects declare, or that are
+	// detected from their workspaces and directories if they don't declare
+	// one, ex. staging and production.
+	Environments []string
+	// ChangedPaths are the files modified by the pull request.
+	ChangedPaths []string
+	// ParentPull is the pull request this one is stacked on, if any.
+	ParentPull *models.ParentPull
+}
+
+// SummaryPromptProject is a project that was planned.
+type SummaryPromptProject struct {
+	Name      string
+	Dir       string
+	Workspace string
+	// Environment is the environment the project declares in atlantis.yaml.
+	Environment string
+}
+
+// NewSummaryPromptData returns the prompt data for the projects planned in
+// ctx. changedPaths may be nil if the modified files aren't known.
+func NewSummaryPromptData(ctx *command.Context, projectResults []command.ProjectResult, changedPaths []string) SummaryPromptData {
+	data := SummaryPromptData{
+		RepoName:     ctx.Pull.BaseRepo.FullName,
+		PullNum:      ctx.Pull.Num,
+		PullTitle:    ctx.Pull.Title,
+		ChangedPaths: changedPaths,
+		ParentPull:   ctx.ParentPull,
+	}
+	for _, result := range projectResults {
+		data.Projects = append(data.Projects, SummaryPromptProject{
+			Name:        result.ProjectName,
+			Dir:         result.RepoRelDir,
+			Workspace:   result.Workspace,
+			Environment: result.Environment,
+		})
+		if result.Environment != "" {
+			data.Environments = appendUnique(data.Environments, result.Environment)
+			continue
+		}
+		if result.Workspace != "" && result.Workspace != DefaultWorkspace {
+			data.Environments = appendUnique(data.Environments, result.Workspace)
+		}
+		for _, segment := range strings.Split(result.RepoRelDir, "/") {
+			if slices.Contains(environmentNames, strings.ToLower(segment)) {
+				data.Environments = appendUnique(data.Environments, segment)
+			}
+		}
+	}
+	return data
+}
+
+// labelPlanForSummary prefixes output with the project and the environment
+// it declares so the summarizer doesn't have to infer environments from the
+// plan. Outputs of projects without an environment are returned as is.
+func labelPlanForSummary(result command.ProjectResult, output string) string {
+	if result.Environment == "" {
+		return output
+	}
+	project := result.ProjectName
+	if project == "" {
+		project = result.RepoRelDir
+	}
+	return fmt.Sprintf("Project %s (workspace: %s, environment: %s):\n\n%s", project, result.Workspace, result.Environment, output)
+}
+
+func appendUnique(s []string, v string) []string {
+	if slices.Contains(s, v) {
+		return s
+	}
+	return append(s, v)
+}
+
+// summaryPromptTemplated returns true if the system prompt is rendered from a
+// template file.
+func summaryPromptTemplated() bool {
+	return os.Getenv(summaryPromptTemplateFileEnv) != ""
+}
+
+// summaryPromptTemplate returns the contents of the system prompt template
+// file or an empty string if none is configured.
+func summaryPromptTemplate(logger logging.SimpleLogging) string {
+	path := os.Getenv(summaryPromptTemplateFileEnv)
+	if path == "" {
+		return ""
+	}
+	contents, err := os.ReadFile(path) // nolint: gosec
+	if err != nil {
+		logger.Warn("failed to read system prompt template %q: %s", path, err)
+		return ""
+	}
+	return string(contents)
+}
+
+// renderPromptTemplate returns the system prompt rendered from the template
+// source with data, falling back to the system prompt variable or the default
+// if it fails to render.
+func renderPromptTemplate(source string, data SummaryPromptData, logger logging.SimpleLogging) string {
+	tmpl, err := template.New("prompt").Parse(source)
+	if err != nil {
+		logger.Warn("failed to parse system prompt template: %s", err)
+		return summarizerSystemPrompt()
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		logger.Warn("failed to render system prompt template: %s", err)
+		return summarizerSystemPrompt()
+	}
+	return buf.String()
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestNewSummaryPromptData(t *testing.T) {
+	ctx := &command.Context{
+		Pull: models.PullRequest{Num: 3, Title: "Bump AMI", BaseRepo: models.Repo{FullName: "owner/repo"}},
+	}
+	data := events.NewSummaryPromptData(ctx, []command.ProjectResult{
+		{ProjectName: "app-staging", RepoRelDir: "envs/staging/app", Workspace: "default"},
+		{ProjectName: "app-production", RepoRelDir: "envs/production/app", Workspace: "default"},
+		{RepoRelDir: "global", Workspace: "staging"},
+		{ProjectName: "dns", RepoRelDir: "dns/prod", Workspace: "default", Environment: "shared"},
+	}, []string{"envs/staging/app/main.tf"})
+	Equals(t, events.SummaryPromptData{
+		RepoName:  "owner/repo",
+		PullNum:   3,
+		PullTitle: "Bump AMI",
+		Projects: []events.SummaryPromptProject{
+			{Name: "app-staging", Dir: "envs/staging/app", Workspace: "default"},
+			{Name: "app-production", Dir: "envs/production/app", Workspace: "default"},
+			{Dir: "global", Workspace: "staging"},
+			{Name: "dns", Dir: "dns/prod", Workspace: "default", Environment: "shared"},
+		},
+		Environments: []string{"staging", "production", "shared"},
+		ChangedPaths: []string{"envs/staging/app/main.tf"},
+	}, data)
+}
+
+func TestSummarizePlans_PromptTemplate(t *testing.T) {
+	templateFile := filepath.Join(t.TempDir(), "prompt.tmpl")
+	Ok(t, os.WriteFile(templateFile, []byte(`Summarize {{ .RepoName }}:{{ range .Projects }} {{ .Dir }}{{ end }} ({{ join .Environments }})`), 0600))
+	t.Setenv("OPENROUTER_TERRAFORM_PLAN_SUMMARIZER_SYSTEM_PROMPT_TEMPLATE_FILE", templateFile)
+	t.Setenv("OPENROUTER_TERRAFORM_PLAN_SUMMARIZER_SYSTEM_PROMPT", "fallback")
+	t.Setenv("TERRAFORM_PLAN_SUMMARIZER_COMMAND", `echo "$ATLANTIS_SUMMARIZER_SYSTEM_PROMPT"`)
+	data := events.SummaryPromptData{
+		RepoName:     "owner/repo",
+		Projects:     []events.SummaryPromptProject{{Dir: "staging"}, {Dir: "production"}},
+		Environments: []string{"staging", "production"},
+	}
+
+	t.Log("templates that fail to parse fall back to the system prompt")
+	Equals(t, "fallback", events.SummarizePlans([]string{"plan"}, data, logging.NewNoopLogger(t)))
+
+	Ok(t, os.WriteFile(templateFile, []byte(`Summarize {{ .RepoName }}:{{ range .Projects }} {{ .Dir }}{{ end }}`), 0600))
+	Equals(t, "Summarize owner/repo: staging production", events.SummarizePlans([]string{"plan"}, data, logging.NewNoopLogger(t)))
+}
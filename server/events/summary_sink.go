@@ -0,0 +1,191 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+)
+
+const (
+	summarySinkURLEnv   = "PLAN_SUMMARY_SINK_URL"
+	summarySinkTokenEnv = "PLAN_SUMMARY_SINK_TOKEN" // nolint: gosec
+	summarySinkTimeout  = 10 * time.Second
+)
+
+// Risk levels of a plan summary.
+const (
+	LowPlanRisk    = "low"
+	MediumPlanRisk = "medium"
+	HighPlanRisk   = "high"
+)
+
+// SummarySink posts generated plan summaries as JSON to an HTTP endpoint, ex.
+// an internal dashboard.
+type SummarySink struct {
+	URL string
+	// Token is sent as a bearer token. It may be empty.
+	Token  string
+	Client *http.Client
+}
+
+// NewSummarySinkFromEnv returns a SummarySink configured with the
+// PLAN_SUMMARY_SINK_URL and PLAN_SUMMARY_SINK_TOKEN environment variables or
+// nil if no URL is set.
+func NewSummarySinkFromEnv() *SummarySink {
+	url := os.Getenv(summarySinkURLEnv)
+	if url == "" {
+		return nil
+	}
+	return &SummarySink{
+		URL:    url,
+		Token:  os.Getenv(summarySinkTokenEnv),
+		Client: &http.Client{Timeout: summarySinkTimeout},
+	}
+}
+
+// SummaryPayload is the JSON body posted for each generated summary.
+type SummaryPayload struct {
+	Repo        string           `json:"repo"`
+	Pull        SummaryPull      `json:"pull"`
+	User        string           `json:"user"`
+	Summary     string           `json:"summary"`
+	Projects    []SummaryProject `json:"projects"`
+	Risk        SummaryRisk      `json:"risk"`
+	GeneratedAt time.Time        `json:"generated_at"`
+}
+
+// SummaryPull describes the pull request the summary was generated for.
+type SummaryPull struct {
+	Number     int    `json:"number"`
+	Title      string `json:"title"`
+	URL        string `json:"url"`
+	Author     string `json:"author"`
+	HeadBranch string `json:"head_branch"`
+	BaseBranch string `json:"base_branch"`
+	HeadCommit string `json:"head_commit"`
+}
+
+// SummaryProject are the plan stats of one project.
+type SummaryProject struct {
+	Name      string `json:"name"`
+	Dir       string `json:"dir"`
+	Workspace string `json:"workspace"`
+	Import    int    `json:"import"`
+	Add       int    `json:"add"`
+	Change    int    `json:"change"`
+	Destroy   int    `json:"destroy"`
+	Error     bool   `json:"error"`
+}
+
+// SummaryRisk aggregates the plan stats of all projects. Level is "high" if
+// any resource is destroyed or replaced, "medium" if any resource changes or
+// a project failed to plan and "low" otherwise.
+type SummaryRisk struct {
+	Level              string   `json:"level"`
+	Import             int      `json:"import"`
+	Add                int      `json:"add"`
+	Change             int      `json:"change"`
+	Destroy            int      `json:"destroy"`
+	ChangedProjects    int      `json:"changed_projects"`
+	DestroyingProjects []string `json:"destroying_projects"`
+}
+
+// NewSummaryPayload builds the payload for summary, generated from the plans
+// of projectResults.
+func NewSummaryPayload(ctx *command.Context, summary string, projectResults []command.ProjectResult) SummaryPayload {
+	payload := SummaryPayload{
+		Repo: ctx.Pull.BaseRepo.FullName,
+		Pull: SummaryPull{
+			Number:     ctx.Pull.Num,
+			Title:      ctx.Pull.Title,
+			URL:        ctx.Pull.URL,
+			Author:     ctx.Pull.Author,
+			HeadBranch: ctx.Pull.HeadBranch,
+			BaseBranch: ctx.Pull.BaseBranch,
+			HeadCommit: ctx.Pull.HeadCommit,
+		},
+		User:        ctx.User.Username,
+		Summary:     summary,
+		Projects:    []SummaryProject{},
+		Risk:        SummaryRisk{Level: LowPlanRisk, DestroyingProjects: []string{}},
+		GeneratedAt: time.Now().UTC(),
+	}
+	failed := false
+	for _, result := range projectResults {
+		project := SummaryProject{
+			Name:      result.ProjectName,
+			Dir:       result.RepoRelDir,
+			Workspace: result.Workspace,
+		}
+		if result.PlanSuccess == nil {
+			project.Error = true
+			failed = true
+			payload.Projects = append(payload.Projects, project)
+			continue
+		}
+		stats := result.PlanSuccess.Stats()
+		project.Import, project.Add, project.Change, project.Destroy = stats.Import, stats.Add, stats.Change, stats.Destroy
+		payload.Projects = append(payload.Projects, project)
+
+		payload.Risk.Import += stats.Import
+		payload.Risk.Add += stats.Add
+		payload.Risk.Change += stats.Change
+		payload.Risk.Destroy += stats.Destroy
+		if stats.Changes {
+			payload.Risk.ChangedProjects++
+		}
+		if stats.Destroy > 0 {
+			name := result.ProjectName
+			if name == "" {
+				name = result.RepoRelDir
+			}
+			payload.Risk.DestroyingProjects = append(payload.Risk.DestroyingProjects, name)
+		}
+	}
+	switch {
+	case payload.Risk.Destroy > 0:
+		payload.Risk.Level = HighPlanRisk
+	case payload.Risk.ChangedProjects > 0 || failed:
+		payload.Risk.Level = MediumPlanRisk
+	}
+	return payload
+}
+
+// Send posts payload to the sink's URL.
+func (s *SummarySink) Send(payload SummaryPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", s.URL, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("returned status code %d with response %q", resp.StatusCode, respBody)
+	}
+	return nil
+}
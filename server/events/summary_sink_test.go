@@ -0,0 +1,125 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func summarySinkContext(t *testing.T) *command.Context {
+	return &command.Context{
+		Log: logging.NewNoopLogger(t),
+		Pull: models.PullRequest{
+			Num:        7,
+			Title:      "Add bucket",
+			URL:        "https://github.com/owner/repo/pull/7",
+			BaseRepo:   models.Repo{FullName: "owner/repo"},
+			BaseBranch: "main",
+			HeadBranch: "bucket",
+		},
+		User: models.User{Username: "lkysow"},
+	}
+}
+
+func TestNewSummaryPayload(t *testing.T) {
+	cases := []struct {
+		name    string
+		results []command.ProjectResult
+		expRisk events.SummaryRisk
+	}{
+		{
+			name: "no changes",
+			results: []command.ProjectResult{
+				{RepoRelDir: ".", ProjectCommandOutput: command.ProjectCommandOutput{PlanSuccess: &models.PlanSuccess{TerraformOutput: "No changes. Your infrastructure matches the configuration."}}},
+			},
+			expRisk: events.SummaryRisk{Level: events.LowPlanRisk, DestroyingProjects: []string{}},
+		},
+		{
+			name: "changes",
+			results: []command.ProjectResult{
+				{RepoRelDir: "a", ProjectCommandOutput: command.ProjectCommandOutput{PlanSuccess: &models.PlanSuccess{TerraformOutput: "Plan: 1 to add, 2 to change, 0 to destroy."}}},
+				{RepoRelDir: "b", ProjectCommandOutput: command.ProjectCommandOutput{PlanSuccess: &models.PlanSuccess{TerraformOutput: "Plan: 1 to add, 0 to change, 0 to destroy."}}},
+			},
+			expRisk: events.SummaryRisk{Level: events.MediumPlanRisk, Add: 2, Change: 2, ChangedProjects: 2, DestroyingProjects: []string{}},
+		},
+		{
+			name: "failed plan",
+			results: []command.ProjectResult{
+				{RepoRelDir: ".", ProjectCommandOutput: command.ProjectCommandOutput{Error: errors.New("init failed")}},
+			},
+			expRisk: events.SummaryRisk{Level: events.MediumPlanRisk, DestroyingProjects: []string{}},
+		},
+		{
+			name: "destroy",
+			results: []command.ProjectResult{
+				{ProjectName: "prod", RepoRelDir: "prod", ProjectCommandOutput: command.ProjectCommandOutput{PlanSuccess: &models.PlanSuccess{TerraformOutput: "Plan: 1 to add, 0 to change, 1 to destroy."}}},
+			},
+			expRisk: events.SummaryRisk{Level: events.HighPlanRisk, Add: 1, Destroy: 1, ChangedProjects: 1, DestroyingProjects: []string{"prod"}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			payload := events.NewSummaryPayload(summarySinkContext(t), "summary", c.results)
+			Equals(t, c.expRisk, payload.Risk)
+			Equals(t, len(c.results), len(payload.Projects))
+		})
+	}
+}
+
+func TestSummarySink_Send(t *testing.T) {
+	var got events.SummaryPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Equals(t, "POST", r.Method)
+		Equals(t, "Bearer token", r.Header.Get("Authorization"))
+		Equals(t, "application/json", r.Header.Get("Content-Type"))
+		Ok(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sink := &events.SummarySink{URL: server.URL, Token: "token"}
+	payload := events.NewSummaryPayload(summarySinkContext(t), "summary", []command.ProjectResult{
+		{RepoRelDir: ".", Workspace: "default", ProjectCommandOutput: command.ProjectCommandOutput{PlanSuccess: &models.PlanSuccess{TerraformOutput: "Plan: 1 to add, 0 to change, 0 to destroy."}}},
+	})
+	Ok(t, sink.Send(payload))
+	Equals(t, "owner/repo", got.Repo)
+	Equals(t, events.SummaryPull{Number: 7, Title: "Add bucket", URL: "https://github.com/owner/repo/pull/7", HeadBranch: "bucket", BaseBranch: "main"}, got.Pull)
+	Equals(t, "lkysow", got.User)
+	Equals(t, "summary", got.Summary)
+	Equals(t, []events.SummaryProject{{Dir: ".", Workspace: "default", Add: 1}}, got.Projects)
+	Equals(t, events.MediumPlanRisk, got.Risk.Level)
+}
+
+func TestSummarySink_SendError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte("denied")) // nolint: errcheck
+	}))
+	defer server.Close()
+
+	sink := &events.SummarySink{URL: server.URL}
+	err := sink.Send(events.SummaryPayload{})
+	ErrEquals(t, `returned status code 401 with response "denied"`, err)
+}
+
+func TestNewSummarySinkFromEnv(t *testing.T) {
+	t.Setenv("PLAN_SUMMARY_SINK_URL", "")
+	Assert(t, events.NewSummarySinkFromEnv() == nil, "exp nil sink without a url")
+
+	t.Setenv("PLAN_SUMMARY_SINK_URL", "https://dashboard.example.com/summaries")
+	t.Setenv("PLAN_SUMMARY_SINK_TOKEN", "token")
+	sink := events.NewSummarySinkFromEnv()
+	Equals(t, "https://dashboard.example.com/summaries", sink.URL)
+	Equals(t, "token", sink.Token)
+}
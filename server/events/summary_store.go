@@ -0,0 +1,93 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+const (
+	// maxSummariesPerPull is how many summaries are kept for each pull
+	// request, older ones are dropped.
+	maxSummariesPerPull = 10
+	// maxSummaryPulls is how many pull requests summaries are kept for, the
+	// least recently summarized pull request is dropped.
+	maxSummaryPulls = 500
+)
+
+// StoredSummary is a generated plan summary.
+type StoredSummary struct {
+	Summary    string
+	HeadCommit string
+	User       string
+	CreatedAt  time.Time
+	// Variant, Model, PromptHash, Latency and Cost are what generated the
+	// summary, see GeneratedSummary.
+	Variant    string
+	Model      string
+	PromptHash string
+	Latency    time.Duration
+	Cost       float64
+}
+
+// SummaryStore keeps the plan summaries recently generated for each pull
+// request in memory so they can be fetched through the API. Summaries don't
+// survive restarts, they're posted on the pull requests too.
+type SummaryStore struct {
+	mutex sync.Mutex
+	pulls map[string][]StoredSummary
+	// order are the keys of pulls, least recently summarized first.
+	order []string
+}
+
+// NewSummaryStore returns an empty SummaryStore.
+func NewSummaryStore() *SummaryStore {
+	return &SummaryStore{pulls: make(map[string][]StoredSummary)}
+}
+
+// Add stores a summary generated for pull.
+func (s *SummaryStore) Add(pull models.PullRequest, user string, summary GeneratedSummary) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := summaryStoreKey(pull.BaseRepo.FullName, pull.Num)
+	summaries := append(s.pulls[key], StoredSummary{
+		Summary:    summary.Summary,
+		HeadCommit: pull.HeadCommit,
+		User:       user,
+		CreatedAt:  time.Now(),
+		Variant:    summary.Variant,
+		Model:      summary.Model,
+		PromptHash: summary.PromptHash,
+		Latency:    summary.Latency,
+		Cost:       summary.Cost,
+	})
+	if len(summaries) > maxSummariesPerPull {
+		summaries = summaries[len(summaries)-maxSummariesPerPull:]
+	}
+	s.pulls[key] = summaries
+
+	s.order = slices.DeleteFunc(s.order, func(k string) bool { return k == key })
+	s.order = append(s.order, key)
+	if len(s.order) > maxSummaryPulls {
+		delete(s.pulls, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+// List returns the summaries of the pull request, oldest first.
+func (s *SummaryStore) List(repoFullName string, pullNum int) []StoredSummary {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return slices.Clone(s.pulls[summaryStoreKey(repoFullName, pullNum)])
+}
+
+func summaryStoreKey(repoFullName string, pullNum int) string {
+	return fmt.Sprintf("%s#%d", repoFullName, pullNum)
+}
@@ -0,0 +1,42 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestSummaryStore(t *testing.T) {
+	store := events.NewSummaryStore()
+	pull := func(num int) models.PullRequest {
+		return models.PullRequest{Num: num, HeadCommit: "abc123", BaseRepo: models.Repo{FullName: "owner/repo"}}
+	}
+
+	Equals(t, 0, len(store.List("owner/repo", 1)))
+	for i := range 12 {
+		store.Add(pull(1), "jdoe", events.GeneratedSummary{Summary: fmt.Sprintf("summary %d", i)})
+	}
+	summaries := store.List("owner/repo", 1)
+	Equals(t, 10, len(summaries))
+	Equals(t, "summary 2", summaries[0].Summary)
+	Equals(t, "summary 11", summaries[9].Summary)
+	Equals(t, "abc123", summaries[9].HeadCommit)
+	Equals(t, "jdoe", summaries[9].User)
+
+	t.Log("the least recently summarized pulls are dropped")
+	for i := 2; i <= 501; i++ {
+		store.Add(pull(i), "jdoe", events.GeneratedSummary{Summary: "summary"})
+	}
+	Equals(t, 0, len(store.List("owner/repo", 1)))
+	Equals(t, 1, len(store.List("owner/repo", 2)))
+	store.Add(pull(2), "jdoe", events.GeneratedSummary{Summary: "summary"})
+	store.Add(pull(502), "jdoe", events.GeneratedSummary{Summary: "summary"})
+	Equals(t, 2, len(store.List("owner/repo", 2)))
+	Equals(t, 0, len(store.List("owner/repo", 3)))
+}
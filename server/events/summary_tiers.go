@@ -0,0 +1,100 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/runatlantis/atlantis/server/logging"
+	"gopkg.in/yaml.v3"
+)
+
+// summaryTiersFileEnv is the path to a YAML file of model tiers routing small
+// plans to cheaper models than the configured one.
+const summaryTiersFileEnv = "OPENROUTER_TERRAFORM_PLAN_SUMMARIZER_TIERS_FILE"
+
+// bytesPerToken estimates the number of tokens of plans from their size.
+const bytesPerToken = 4
+
+// summaryDestroyRegex matches the plans, human-readable or compacted JSON,
+// destroying or replacing resources.
+var summaryDestroyRegex = regexp.MustCompile(`will be destroyed|must be replaced|[1-9][0-9]* to destroy|"delete"`)
+
+// SummaryTier is a model that summarizes the plans under its thresholds.
+// Plans are summarized by the first tier they fit in, or by the configured
+// model if they fit in none.
+type SummaryTier struct {
+	Model string `yaml:"model"`
+	// MaxBytes and MaxTokens are the sizes of the plans over which the tier
+	// doesn't summarize them, 0 if the tier doesn't limit it. Tokens are
+	// estimated as 4 bytes each.
+	MaxBytes  int `yaml:"max_bytes"`
+	MaxTokens int `yaml:"max_tokens"`
+	// Destroys is true if the tier also summarizes plans destroying or
+	// replacing resources.
+	Destroys bool `yaml:"destroys"`
+}
+
+// loadSummaryTiers reads the tiers file. It returns nil, logging why, if
+// there's none or it's invalid, in which case plans are summarized by the
+// configured model.
+func loadSummaryTiers(logger logging.SimpleLogging) []SummaryTier {
+	path := os.Getenv(summaryTiersFileEnv)
+	if path == "" {
+		return nil
+	}
+	contents, err := os.ReadFile(path) // nolint: gosec
+	if err != nil {
+		logger.Warn("failed to read summarizer tiers %q: %s", path, err)
+		return nil
+	}
+	tiers, err := parseSummaryTiers(contents)
+	if err != nil {
+		logger.Warn("ignoring summarizer tiers %q: %s", path, err)
+		return nil
+	}
+	return tiers
+}
+
+func parseSummaryTiers(contents []byte) ([]SummaryTier, error) {
+	var file struct {
+		Tiers []SummaryTier `yaml:"tiers"`
+	}
+	if err := yaml.Unmarshal(contents, &file); err != nil {
+		return nil, fmt.Errorf("parsing tiers: %w", err)
+	}
+	for i, tier := range file.Tiers {
+		if tier.Model == "" {
+			return nil, fmt.Errorf("tier %d must have a model", i+1)
+		}
+		if tier.MaxBytes < 0 || tier.MaxTokens < 0 {
+			return nil, fmt.Errorf("thresholds of tier %s can't be negative", tier.Model)
+		}
+		if tier.MaxBytes == 0 && tier.MaxTokens == 0 {
+			return nil, fmt.Errorf("tier %s must set max_bytes or max_tokens", tier.Model)
+		}
+	}
+	return file.Tiers, nil
+}
+
+// routeSummaryModel returns the model of the first of tiers input fits in, or
+// model if it fits in none.
+func routeSummaryModel(tiers []SummaryTier, model string, input string) string {
+	destroys := summaryDestroyRegex.MatchString(input)
+	for _, tier := range tiers {
+		if destroys && !tier.Destroys {
+			continue
+		}
+		if tier.MaxBytes > 0 && len(input) > tier.MaxBytes {
+			continue
+		}
+		if tier.MaxTokens > 0 && len(input) > tier.MaxTokens*bytesPerToken {
+			continue
+		}
+		return tier.Model
+	}
+	return model
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func writeSummaryTiers(t *testing.T, contents string) {
+	path := filepath.Join(t.TempDir(), "tiers.yaml")
+	Ok(t, os.WriteFile(path, []byte(contents), 0600))
+	t.Setenv("OPENROUTER_TERRAFORM_PLAN_SUMMARIZER_TIERS_FILE", path)
+}
+
+func TestGenerateSummary_Tiers(t *testing.T) {
+	var body struct{ Model string }
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Ok(t, json.NewDecoder(r.Body).Decode(&body))
+		fmt.Fprint(w, `{"choices": [{"message": {"role": "assistant", "content": "summary"}}]}`)
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENROUTER_API_URL", server.URL)
+	t.Setenv("OPENROUTER_API_KEY", "key")
+	t.Setenv("TERRAFORM_PLAN_SUMMARIZER_COMMAND", "")
+	t.Setenv("OPENROUTER_TERRAFORM_PLAN_SUMMARIZER_MODEL", "anthropic/claude-opus-4.8")
+	writeSummaryTiers(t, `
+tiers:
+- model: openai/gpt-5-nano
+  max_bytes: 200
+- model: openai/gpt-5-mini
+  max_tokens: 100
+  destroys: true
+`)
+	logger := logging.NewNoopLogger(t)
+	summarize := func(plan string) string {
+		generated := events.GenerateSummary([]string{plan}, events.SummaryPromptData{}, nil, logger)
+		Equals(t, body.Model, generated.Model)
+		return body.Model
+	}
+
+	Equals(t, "openai/gpt-5-nano", summarize("  # null_resource.a will be created\nPlan: 1 to add, 0 to change, 0 to destroy."))
+	t.Log("plans destroying resources skip the tiers that don't summarize them")
+	Equals(t, "openai/gpt-5-mini", summarize("  # null_resource.a will be destroyed\nPlan: 0 to add, 0 to change, 1 to destroy."))
+	t.Log("plans over the thresholds of a tier skip it")
+	Equals(t, "openai/gpt-5-mini", summarize(strings.Repeat("  # null_resource.a will be created\n", 8)))
+	t.Log("plans fitting in no tier are summarized by the configured model")
+	Equals(t, "anthropic/claude-opus-4.8", summarize(strings.Repeat("  # null_resource.a will be created\n", 20)))
+
+	t.Log("variants setting a model aren't routed")
+	variant := &events.SummaryVariant{Name: "concise", Weight: 1, Model: "openai/gpt-5"}
+	events.GenerateSummary([]string{"Plan: 1 to add, 0 to change, 0 to destroy."}, events.SummaryPromptData{}, variant, logger)
+	Equals(t, "openai/gpt-5", body.Model)
+
+	t.Log("invalid tiers are ignored")
+	writeSummaryTiers(t, "tiers:\n- model: openai/gpt-5-nano\n")
+	Equals(t, "anthropic/claude-opus-4.8", summarize("Plan: 1 to add, 0 to change, 0 to destroy."))
+}
@@ -0,0 +1,211 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	"gopkg.in/yaml.v3"
+)
+
+// summaryVariantsFileEnv is the path to a YAML file of prompt and model
+// variants the plan summarizer splits pull requests between, to compare
+// them with the feedback, latency and cost of their summaries.
+const summaryVariantsFileEnv = "OPENROUTER_TERRAFORM_PLAN_SUMMARIZER_VARIANTS_FILE"
+
+// SummaryVariant is a variant of the plan summarizer. The model and prompt
+// it doesn't set are the ones configured without variants.
+type SummaryVariant struct {
+	Name string `yaml:"name"`
+	// Weight is the share of pull requests summarized by the variant,
+	// relative to the weights of the other variants.
+	Weight                   int    `yaml:"weight"`
+	Model                    string `yaml:"model"`
+	SystemPrompt             string `yaml:"system_prompt"`
+	SystemPromptTemplateFile string `yaml:"system_prompt_template_file"`
+}
+
+// GeneratedSummary is a plan summary and what generated it.
+type GeneratedSummary struct {
+	Summary string
+	// Variant is the name of the variant that generated the summary, empty if
+	// there are no variants.
+	Variant string
+	// Model is the model, or summarizer command, that generated the summary.
+	Model string
+	// PromptHash identifies the system prompt, or its template.
+	PromptHash string
+	Latency    time.Duration
+	// Cost is the cost of the summary in USD reported by OpenRouter, 0 if it
+	// isn't known.
+	Cost float64
+}
+
+// loadSummaryVariants reads the variants file. It returns nil, logging why,
+// if there's none or it's invalid, in which case summaries are generated
+// without variants.
+func loadSummaryVariants(logger logging.SimpleLogging) []SummaryVariant {
+	path := os.Getenv(summaryVariantsFileEnv)
+	if path == "" {
+		return nil
+	}
+	contents, err := os.ReadFile(path) // nolint: gosec
+	if err != nil {
+		logger.Warn("failed to read summarizer variants %q: %s", path, err)
+		return nil
+	}
+	variants, err := parseSummaryVariants(contents)
+	if err != nil {
+		logger.Warn("ignoring summarizer variants %q: %s", path, err)
+		return nil
+	}
+	return variants
+}
+
+func parseSummaryVariants(contents []byte) ([]SummaryVariant, error) {
+	var file struct {
+		Variants []SummaryVariant `yaml:"variants"`
+	}
+	if err := yaml.Unmarshal(contents, &file); err != nil {
+		return nil, fmt.Errorf("parsing variants: %w", err)
+	}
+	var names []string
+	for _, v := range file.Variants {
+		if v.Name == "" {
+			return nil, fmt.Errorf("variants must have a name")
+		}
+		if slices.Contains(names, v.Name) {
+			return nil, fmt.Errorf("variant %s is defined more than once", v.Name)
+		}
+		if v.Weight <= 0 {
+			return nil, fmt.Errorf("weight of variant %s must be positive", v.Name)
+		}
+		names = append(names, v.Name)
+	}
+	return file.Variants, nil
+}
+
+// PickSummaryVariant returns the variant that summarizes the plans of pull,
+// or nil if there are no variants. Pull requests are split between variants
+// by their weights, always picking the same variant for a pull request so its
+// summaries, and their feedback, are consistent.
+func PickSummaryVariant(pull models.PullRequest, logger logging.SimpleLogging) *SummaryVariant {
+	return pickSummaryVariant(loadSummaryVariants(logger), summaryStoreKey(pull.BaseRepo.FullName, pull.Num))
+}
+
+func pickSummaryVariant(variants []SummaryVariant, key string) *SummaryVariant {
+	total := 0
+	for _, v := range variants {
+		total += v.Weight
+	}
+	if total == 0 {
+		return nil
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))                // nolint: errcheck
+	n := int(h.Sum32() % uint32(total)) // nolint: gosec
+	for i := range variants {
+		if n < variants[i].Weight {
+			return &variants[i]
+		}
+		n -= variants[i].Weight
+	}
+	return nil
+}
+
+// Templated returns true if the system prompt of the variant is rendered from
+// a template. v may be nil for the summarizer without variants.
+func (v *SummaryVariant) Templated() bool {
+	if v != nil && v.SystemPromptTemplateFile != "" {
+		return true
+	}
+	if v != nil && v.SystemPrompt != "" {
+		return false
+	}
+	return summaryPromptTemplated()
+}
+
+// model returns the model of the variant. v may be nil.
+func (v *SummaryVariant) model() string {
+	if v != nil && v.Model != "" {
+		return v.Model
+	}
+	return summarizerModel()
+}
+
+// name returns the name of the variant. v may be nil.
+func (v *SummaryVariant) name() string {
+	if v == nil {
+		return ""
+	}
+	return v.Name
+}
+
+// promptSource returns the system prompt of the variant, or its template if
+// templated is true. v may be nil.
+func (v *SummaryVariant) promptSource(logger logging.SimpleLogging) (source string, templated bool) {
+	if v != nil && v.SystemPromptTemplateFile != "" {
+		contents, err := os.ReadFile(v.SystemPromptTemplateFile) // nolint: gosec
+		if err == nil {
+			return string(contents), true
+		}
+		logger.Warn("failed to read system prompt template %q of variant %s: %s", v.SystemPromptTemplateFile, v.Name, err)
+	} else if v != nil && v.SystemPrompt != "" {
+		return v.SystemPrompt, false
+	}
+	if source := summaryPromptTemplate(logger); source != "" {
+		return source, true
+	}
+	return summarizerSystemPrompt(), false
+}
+
+// SummaryVariantStats compares the summaries generated by a variant, model
+// and prompt.
+type SummaryVariantStats struct {
+	Variant        string
+	Model          string
+	PromptHash     string
+	Summaries      int
+	ThumbsUp       int
+	ThumbsDown     int
+	AverageLatency time.Duration
+	// TotalCost is the cost in USD of the summaries whose cost is known.
+	TotalCost float64
+}
+
+// CompareSummaryVariants aggregates feedback by variant, model and prompt,
+// sorted by variant.
+func CompareSummaryVariants(feedback []models.SummaryFeedback) []SummaryVariantStats {
+	var stats []SummaryVariantStats
+	var latencies []time.Duration
+	for _, f := range feedback {
+		i := slices.IndexFunc(stats, func(s SummaryVariantStats) bool {
+			return s.Variant == f.Variant && s.Model == f.Model && s.PromptHash == f.PromptHash
+		})
+		if i == -1 {
+			stats = append(stats, SummaryVariantStats{Variant: f.Variant, Model: f.Model, PromptHash: f.PromptHash})
+			latencies = append(latencies, 0)
+			i = len(stats) - 1
+		}
+		stats[i].Summaries++
+		stats[i].ThumbsUp += f.ThumbsUp
+		stats[i].ThumbsDown += f.ThumbsDown
+		stats[i].TotalCost += f.Cost
+		latencies[i] += f.Latency
+	}
+	for i := range stats {
+		stats[i].AverageLatency = latencies[i] / time.Duration(stats[i].Summaries)
+	}
+	slices.SortStableFunc(stats, func(a, b SummaryVariantStats) int {
+		return strings.Compare(a.Variant+"\x00"+a.Model+"\x00"+a.PromptHash, b.Variant+"\x00"+b.Model+"\x00"+b.PromptHash)
+	})
+	return stats
+}
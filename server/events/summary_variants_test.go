@@ -0,0 +1,105 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func writeSummaryVariants(t *testing.T, contents string) {
+	path := filepath.Join(t.TempDir(), "variants.yaml")
+	Ok(t, os.WriteFile(path, []byte(contents), 0600))
+	t.Setenv("OPENROUTER_TERRAFORM_PLAN_SUMMARIZER_VARIANTS_FILE", path)
+}
+
+func TestPickSummaryVariant(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	pull := func(num int) models.PullRequest {
+		return models.PullRequest{Num: num, BaseRepo: models.Repo{FullName: "owner/repo"}}
+	}
+
+	t.Setenv("OPENROUTER_TERRAFORM_PLAN_SUMMARIZER_VARIANTS_FILE", "")
+	Assert(t, events.PickSummaryVariant(pull(1), logger) == nil, "exp no variant without a variants file")
+
+	writeSummaryVariants(t, `
+variants:
+- name: control
+  weight: 3
+- name: concise
+  weight: 1
+  model: openai/gpt-5
+  system_prompt: Be concise.
+`)
+	picked := make(map[string]int)
+	for num := range 400 {
+		variant := events.PickSummaryVariant(pull(num), logger)
+		picked[variant.Name]++
+		// A pull request is always summarized by the same variant.
+		Equals(t, variant.Name, events.PickSummaryVariant(pull(num), logger).Name)
+	}
+	Assert(t, picked["control"] > 250 && picked["concise"] > 50, "exp pulls split 3 to 1, got %v", picked)
+
+	t.Log("invalid variants are ignored")
+	writeSummaryVariants(t, "variants:\n- name: control\n")
+	Assert(t, events.PickSummaryVariant(pull(1), logger) == nil, "exp no variant with a variant without weight")
+}
+
+func TestGenerateSummary_Variant(t *testing.T) {
+	var body struct {
+		Model    string
+		Messages []struct{ Role, Content string }
+		Usage    struct{ Include bool }
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Ok(t, json.NewDecoder(r.Body).Decode(&body))
+		fmt.Fprint(w, `{"choices": [{"message": {"role": "assistant", "content": "summary"}}], "usage": {"cost": 0.002}}`)
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENROUTER_API_URL", server.URL)
+	t.Setenv("OPENROUTER_API_KEY", "key")
+	t.Setenv("TERRAFORM_PLAN_SUMMARIZER_COMMAND", "")
+
+	variant := &events.SummaryVariant{Name: "concise", Weight: 1, Model: "openai/gpt-5", SystemPrompt: "Be concise."}
+	generated := events.GenerateSummary([]string{"plan"}, events.SummaryPromptData{}, variant, logging.NewNoopLogger(t))
+	Equals(t, "summary", generated.Summary)
+	Equals(t, "concise", generated.Variant)
+	Equals(t, "openai/gpt-5", generated.Model)
+	Equals(t, 12, len(generated.PromptHash))
+	Equals(t, 0.002, generated.Cost)
+	Assert(t, generated.Latency > 0, "exp latency to be measured")
+	Equals(t, "openai/gpt-5", body.Model)
+	Equals(t, "Be concise.", body.Messages[0].Content)
+	Equals(t, true, body.Usage.Include)
+
+	t.Log("variants without a prompt use the configured one")
+	t.Setenv("OPENROUTER_TERRAFORM_PLAN_SUMMARIZER_SYSTEM_PROMPT", "Summarize.")
+	t.Setenv("OPENROUTER_TERRAFORM_PLAN_SUMMARIZER_SYSTEM_PROMPT_TEMPLATE_FILE", "")
+	other := events.GenerateSummary([]string{"plan"}, events.SummaryPromptData{}, &events.SummaryVariant{Name: "control", Weight: 1}, logging.NewNoopLogger(t))
+	Equals(t, "Summarize.", body.Messages[0].Content)
+	Assert(t, other.PromptHash != generated.PromptHash, "exp prompts to be told apart")
+}
+
+func TestCompareSummaryVariants(t *testing.T) {
+	feedback := []models.SummaryFeedback{
+		{Variant: "concise", Model: "b", PromptHash: "p2", ThumbsUp: 1, Latency: time.Second, Cost: 0.01},
+		{Variant: "control", Model: "a", PromptHash: "p1", ThumbsDown: 1, Latency: 4 * time.Second},
+		{Variant: "concise", Model: "b", PromptHash: "p2", ThumbsUp: 2, ThumbsDown: 1, Latency: 3 * time.Second, Cost: 0.02},
+	}
+	Equals(t, []events.SummaryVariantStats{
+		{Variant: "concise", Model: "b", PromptHash: "p2", Summaries: 2, ThumbsUp: 3, ThumbsDown: 1, AverageLatency: 2 * time.Second, TotalCost: 0.03},
+		{Variant: "control", Model: "a", PromptHash: "p1", Summaries: 1, ThumbsDown: 1, AverageLatency: 4 * time.Second},
+	}, events.CompareSummaryVariants(feedback))
+}
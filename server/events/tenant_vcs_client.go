@@ -0,0 +1,189 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"sync"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// TenantVCSClient implements vcs.Client. It sends the calls about the repos of
+// tenants with VCS credentials of their own to clients authenticated with
+// them, and the others to Client.
+type TenantVCSClient struct {
+	vcs.Client
+	Tenants *Tenants
+	// NewClient returns a client of the VCS host type authenticated with
+	// credentials.
+	NewClient func(vcsHostType models.VCSHostType, credentials VCSCredentials) (vcs.Client, error)
+	// Hostnames are the hostnames of the VCS hosts by type, to find the
+	// tenants of the repos of GetCloneURL.
+	Hostnames map[models.VCSHostType]string
+
+	mutex sync.Mutex
+	// clients are the clients of the tenants by tenant and VCS host type.
+	clients map[tenantClientKey]tenantClient
+}
+
+type tenantClientKey struct {
+	tenant      string
+	vcsHostType models.VCSHostType
+}
+
+type tenantClient struct {
+	credentials VCSCredentials
+	client      vcs.Client
+}
+
+// clientFor returns the client of the tenant of the repo, or Client if it
+// has no credentials of its own. Clients are created on first use and
+// recreated if the credentials of the tenant are reloaded.
+func (c *TenantVCSClient) clientFor(vcsHostType models.VCSHostType, repoFullName string, vcsHostname string) (vcs.Client, error) {
+	tenant, credentials, ok := c.Tenants.Credentials(vcsHostType, repoFullName, vcsHostname)
+	if !ok {
+		return c.Client, nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	key := tenantClientKey{tenant: tenant, vcsHostType: vcsHostType}
+	if cached, ok := c.clients[key]; ok && cached.credentials == credentials {
+		return cached.client, nil
+	}
+	client, err := c.NewClient(vcsHostType, credentials)
+	if err != nil {
+		return nil, err
+	}
+	if c.clients == nil {
+		c.clients = make(map[tenantClientKey]tenantClient)
+	}
+	c.clients[key] = tenantClient{credentials: credentials, client: client}
+	return client, nil
+}
+
+func (c *TenantVCSClient) repoClient(repo models.Repo) (vcs.Client, error) {
+	return c.clientFor(repo.VCSHost.Type, repo.FullName, repo.VCSHost.Hostname)
+}
+
+func (c *TenantVCSClient) GetModifiedFiles(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest) ([]string, error) {
+	client, err := c.repoClient(repo)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetModifiedFiles(logger, repo, pull)
+}
+
+func (c *TenantVCSClient) CreateComment(logger logging.SimpleLogging, repo models.Repo, pullNum int, comment string, command string) error {
+	client, err := c.repoClient(repo)
+	if err != nil {
+		return err
+	}
+	return client.CreateComment(logger, repo, pullNum, comment, command)
+}
+
+func (c *TenantVCSClient) ReactToComment(logger logging.SimpleLogging, repo models.Repo, pullNum int, commentID int64, reaction string) error {
+	client, err := c.repoClient(repo)
+	if err != nil {
+		return err
+	}
+	return client.ReactToComment(logger, repo, pullNum, commentID, reaction)
+}
+
+func (c *TenantVCSClient) HidePrevCommandComments(logger logging.SimpleLogging, repo models.Repo, pullNum int, command string, dir string) error {
+	client, err := c.repoClient(repo)
+	if err != nil {
+		return err
+	}
+	return client.HidePrevCommandComments(logger, repo, pullNum, command, dir)
+}
+
+func (c *TenantVCSClient) PullIsApproved(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest) (models.ApprovalStatus, error) {
+	client, err := c.repoClient(repo)
+	if err != nil {
+		return models.ApprovalStatus{}, err
+	}
+	return client.PullIsApproved(logger, repo, pull)
+}
+
+func (c *TenantVCSClient) PullIsMergeable(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest, vcsstatusname string, ignoreVCSStatusNames []string) (models.MergeableStatus, error) {
+	client, err := c.repoClient(repo)
+	if err != nil {
+		return models.MergeableStatus{}, err
+	}
+	return client.PullIsMergeable(logger, repo, pull, vcsstatusname, ignoreVCSStatusNames)
+}
+
+func (c *TenantVCSClient) UpdateStatus(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string, url string) error {
+	client, err := c.repoClient(repo)
+	if err != nil {
+		return err
+	}
+	return client.UpdateStatus(logger, repo, pull, state, src, description, url)
+}
+
+func (c *TenantVCSClient) DiscardReviews(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest) error {
+	client, err := c.repoClient(repo)
+	if err != nil {
+		return err
+	}
+	return client.DiscardReviews(logger, repo, pull)
+}
+
+func (c *TenantVCSClient) MergePull(logger logging.SimpleLogging, pull models.PullRequest, pullOptions models.PullRequestOptions) error {
+	client, err := c.repoClient(pull.BaseRepo)
+	if err != nil {
+		return err
+	}
+	return client.MergePull(logger, pull, pullOptions)
+}
+
+func (c *TenantVCSClient) MarkdownPullLink(pull models.PullRequest) (string, error) {
+	client, err := c.repoClient(pull.BaseRepo)
+	if err != nil {
+		return "", err
+	}
+	return client.MarkdownPullLink(pull)
+}
+
+func (c *TenantVCSClient) GetTeamNamesForUser(logger logging.SimpleLogging, repo models.Repo, user models.User) ([]string, error) {
+	client, err := c.repoClient(repo)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetTeamNamesForUser(logger, repo, user)
+}
+
+func (c *TenantVCSClient) GetFileContent(logger logging.SimpleLogging, repo models.Repo, branch string, fileName string) (bool, []byte, error) {
+	client, err := c.repoClient(repo)
+	if err != nil {
+		return false, nil, err
+	}
+	return client.GetFileContent(logger, repo, branch, fileName)
+}
+
+func (c *TenantVCSClient) SupportsSingleFileDownload(repo models.Repo) bool {
+	client, err := c.repoClient(repo)
+	if err != nil {
+		return false
+	}
+	return client.SupportsSingleFileDownload(repo)
+}
+
+func (c *TenantVCSClient) GetCloneURL(logger logging.SimpleLogging, VCSHostType models.VCSHostType, repo string) (string, error) {
+	client, err := c.clientFor(VCSHostType, repo, c.Hostnames[VCSHostType])
+	if err != nil {
+		return "", err
+	}
+	return client.GetCloneURL(logger, VCSHostType, repo)
+}
+
+func (c *TenantVCSClient) GetPullLabels(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest) ([]string, error) {
+	client, err := c.repoClient(repo)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetPullLabels(logger, repo, pull)
+}
@@ -0,0 +1,65 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"testing"
+
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestTenantVCSClient(t *testing.T) {
+	RegisterMockTestingT(t)
+	logger := logging.NewNoopLogger(t)
+	tenants, err := events.LoadTenants(writeTenantsConfig(t, `
+tenants:
+- name: acme
+  repo_allowlist: github.com/acme/*
+  github_user: acme-atlantis
+  github_token: acme-token
+- name: globex
+  repo_allowlist: github.com/globex/*
+`))
+	Ok(t, err)
+	defaultClient := vcsmocks.NewMockClient()
+	acmeClient := vcsmocks.NewMockClient()
+	var created []events.VCSCredentials
+	client := &events.TenantVCSClient{
+		Client:  defaultClient,
+		Tenants: tenants,
+		NewClient: func(vcsHostType models.VCSHostType, credentials events.VCSCredentials) (vcs.Client, error) {
+			Equals(t, models.Github, vcsHostType)
+			created = append(created, credentials)
+			return acmeClient, nil
+		},
+		Hostnames: map[models.VCSHostType]string{models.Github: "github.com"},
+	}
+
+	acmeRepo, err := models.NewRepo(models.Github, "acme/infra", "https://github.com/acme/infra.git", "atlantis", "atlantis-token")
+	Ok(t, err)
+	globexRepo, err := models.NewRepo(models.Github, "globex/infra", "https://github.com/globex/infra.git", "atlantis", "atlantis-token")
+	Ok(t, err)
+
+	// The repos of tenants with credentials use the tenant's client, which is
+	// created once.
+	Ok(t, client.CreateComment(logger, acmeRepo, 1, "comment", "plan"))
+	Ok(t, client.MergePull(logger, models.PullRequest{Num: 1, BaseRepo: acmeRepo}, models.PullRequestOptions{}))
+	_, err = client.GetCloneURL(logger, models.Github, "acme/infra")
+	Ok(t, err)
+	acmeClient.VerifyWasCalledOnce().CreateComment(logger, acmeRepo, 1, "comment", "plan")
+	acmeClient.VerifyWasCalledOnce().MergePull(logger, models.PullRequest{Num: 1, BaseRepo: acmeRepo}, models.PullRequestOptions{})
+	acmeClient.VerifyWasCalledOnce().GetCloneURL(logger, models.Github, "acme/infra")
+	Equals(t, []events.VCSCredentials{{User: "acme-atlantis", Token: "acme-token"}}, created)
+
+	// The repos of tenants without credentials use the default client.
+	Ok(t, client.CreateComment(logger, globexRepo, 1, "comment", "plan"))
+	defaultClient.VerifyWasCalledOnce().CreateComment(logger, globexRepo, 1, "comment", "plan")
+	acmeClient.VerifyWasCalled(Never()).CreateComment(logger, globexRepo, 1, "comment", "plan")
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// TenantWorkingDir implements WorkingDir. It keeps the working dirs of the
+// repos of each tenant in the tenant's data dir, see TenantDataDir, and
+// clones them with the VCS credentials of the tenant if it has some. The
+// working dirs of the repos that don't belong to a tenant are WorkingDir's.
+type TenantWorkingDir struct {
+	WorkingDir
+	Tenants *Tenants
+	// DataDir is the data dir of Atlantis.
+	DataDir string
+	// NewWorkingDir returns a working dir keeping its clones in dataDir.
+	NewWorkingDir func(dataDir string) WorkingDir
+
+	mutex sync.Mutex
+	// dirs are the working dirs of the tenants by tenant.
+	dirs map[string]WorkingDir
+}
+
+// forRepo returns the working dir of the tenant of the repo, created on first
+// use, or WorkingDir if it doesn't belong to a tenant.
+func (w *TenantWorkingDir) forRepo(r models.Repo) WorkingDir {
+	tenant, ok := w.Tenants.TenantFor(r.FullName, r.VCSHost.Hostname)
+	if !ok {
+		return w.WorkingDir
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if dir, ok := w.dirs[tenant]; ok {
+		return dir
+	}
+	if w.dirs == nil {
+		w.dirs = make(map[string]WorkingDir)
+	}
+	dir := w.NewWorkingDir(TenantDataDir(w.DataDir, tenant))
+	w.dirs[tenant] = dir
+	return dir
+}
+
+// withTenantCredentials replaces the credentials of the clone URLs of the base
+// repo of p and of headRepo, if it's not nil, with the VCS credentials of the
+// tenant of the base repo if it has some.
+func (w *TenantWorkingDir) withTenantCredentials(p *models.PullRequest, headRepo *models.Repo) {
+	_, credentials, ok := w.Tenants.Credentials(p.BaseRepo.VCSHost.Type, p.BaseRepo.FullName, p.BaseRepo.VCSHost.Hostname)
+	if !ok {
+		return
+	}
+	setCloneCredentials(&p.BaseRepo, credentials)
+	if headRepo != nil {
+		setCloneCredentials(headRepo, credentials)
+	}
+}
+
+// setCloneCredentials replaces the credentials of the clone URLs of repo, the
+// same way models.NewRepo sets them.
+func setCloneCredentials(repo *models.Repo, credentials VCSCredentials) {
+	cloneURL, err := url.Parse(repo.CloneURL)
+	if err != nil {
+		return
+	}
+	cloneURL.User = nil
+	plain := cloneURL.String()
+	user := url.QueryEscape(credentials.User)
+	repo.CloneURL = strings.Replace(plain, "://", "://"+user+":"+url.QueryEscape(credentials.Token)+"@", 1)
+	repo.SanitizedCloneURL = strings.Replace(plain, "://", "://"+user+":<redacted>@", 1)
+}
+
+func (w *TenantWorkingDir) Clone(logger logging.SimpleLogging, headRepo models.Repo, p models.PullRequest, workspace string) (string, error) {
+	w.withTenantCredentials(&p, &headRepo)
+	return w.forRepo(p.BaseRepo).Clone(logger, headRepo, p, workspace)
+}
+
+func (w *TenantWorkingDir) MergeAgain(logger logging.SimpleLogging, headRepo models.Repo, p models.PullRequest, workspace string) (bool, error) {
+	w.withTenantCredentials(&p, &headRepo)
+	return w.forRepo(p.BaseRepo).MergeAgain(logger, headRepo, p, workspace)
+}
+
+func (w *TenantWorkingDir) GetWorkingDir(r models.Repo, p models.PullRequest, workspace string) (string, error) {
+	return w.forRepo(r).GetWorkingDir(r, p, workspace)
+}
+
+func (w *TenantWorkingDir) GetBaseCommit(logger logging.SimpleLogging, cloneDir string, p models.PullRequest) (string, error) {
+	return w.forRepo(p.BaseRepo).GetBaseCommit(logger, cloneDir, p)
+}
+
+func (w *TenantWorkingDir) GetBaseHead(logger logging.SimpleLogging, p models.PullRequest) (string, error) {
+	w.withTenantCredentials(&p, nil)
+	return w.forRepo(p.BaseRepo).GetBaseHead(logger, p)
+}
+
+func (w *TenantWorkingDir) GetPullDir(r models.Repo, p models.PullRequest) (string, error) {
+	return w.forRepo(r).GetPullDir(r, p)
+}
+
+func (w *TenantWorkingDir) Delete(logger logging.SimpleLogging, r models.Repo, p models.PullRequest) error {
+	return w.forRepo(r).Delete(logger, r, p)
+}
+
+func (w *TenantWorkingDir) DeleteForWorkspace(logger logging.SimpleLogging, r models.Repo, p models.PullRequest, workspace string) error {
+	return w.forRepo(r).DeleteForWorkspace(logger, r, p, workspace)
+}
+
+func (w *TenantWorkingDir) DeletePlan(logger logging.SimpleLogging, r models.Repo, p models.PullRequest, workspace string, path string, projectName string) error {
+	return w.forRepo(r).DeletePlan(logger, r, p, workspace, path, projectName)
+}
+
+func (w *TenantWorkingDir) GetGitUntrackedFiles(logger logging.SimpleLogging, r models.Repo, p models.PullRequest, workspace string) ([]string, error) {
+	return w.forRepo(r).GetGitUntrackedFiles(logger, r, p, workspace)
+}
@@ -0,0 +1,211 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sync"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"gopkg.in/yaml.v3"
+)
+
+// tenantsDir is the directory of the data dir the data dirs of the tenants
+// are in.
+const tenantsDir = "tenants"
+
+// Tenants are the organizations, ex. teams or business units, owning the
+// repos Atlantis serves. Each repo belongs to the first tenant whose allowlist
+// it matches, repos that don't belong to any tenant are treated as not
+// allowlisted. The working dirs of each tenant's repos are kept in a data dir
+// of its own, see TenantWorkingDir, tenants with VCS credentials of their own
+// call the VCS and clone with them, see TenantVCSClient, and the web UI users
+// of a tenant's groups only see its repos.
+type Tenants struct {
+	mutex   sync.RWMutex
+	tenants []tenant
+}
+
+type tenant struct {
+	name      string
+	allowlist *RepoAllowlistChecker
+	// credentials are the tenant's VCS credentials by VCS host type.
+	credentials map[models.VCSHostType]VCSCredentials
+	webGroups   []string
+}
+
+// VCSCredentials are the credentials of the VCS user of a tenant.
+type VCSCredentials struct {
+	User  string
+	Token string
+}
+
+// tenantNameRegex matches the tenant names, which are used as directory
+// names.
+var tenantNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// tenantsConfig is the schema of the tenants config file, ex.
+//
+//	tenants:
+//	- name: acme
+//	  repo_allowlist: github.com/acme/*,!github.com/acme/sandbox
+//	  github_user: acme-atlantis
+//	  github_token: ghp_...
+//	  web_groups: [acme-engineers]
+type tenantsConfig struct {
+	Tenants []struct {
+		Name          string   `yaml:"name"`
+		RepoAllowlist string   `yaml:"repo_allowlist"`
+		GithubUser    string   `yaml:"github_user"`
+		GithubToken   string   `yaml:"github_token"`
+		GitlabUser    string   `yaml:"gitlab_user"`
+		GitlabToken   string   `yaml:"gitlab_token"`
+		WebGroups     []string `yaml:"web_groups"`
+	} `yaml:"tenants"`
+}
+
+// LoadTenants parses the tenants config file at path.
+func LoadTenants(path string) (*Tenants, error) {
+	contents, err := os.ReadFile(path) // nolint: gosec
+	if err != nil {
+		return nil, err
+	}
+	var cfg tenantsConfig
+	if err := yaml.Unmarshal(contents, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if len(cfg.Tenants) == 0 {
+		return nil, fmt.Errorf("%s doesn't define any tenants", path)
+	}
+	tenants := &Tenants{}
+	names := make(map[string]bool)
+	for _, t := range cfg.Tenants {
+		if t.Name == "" {
+			return nil, errors.New("tenants must have a name")
+		}
+		if names[t.Name] {
+			return nil, fmt.Errorf("tenant %q is defined more than once", t.Name)
+		}
+		names[t.Name] = true
+		if !tenantNameRegex.MatchString(t.Name) {
+			return nil, fmt.Errorf("tenant %q must only contain letters, digits, '_', '.' and '-' and start with a letter or digit", t.Name)
+		}
+		if t.RepoAllowlist == "" {
+			return nil, fmt.Errorf("tenant %q must have a repo_allowlist", t.Name)
+		}
+		allowlist, err := NewRepoAllowlistChecker(t.RepoAllowlist)
+		if err != nil {
+			return nil, fmt.Errorf("tenant %q: %w", t.Name, err)
+		}
+		credentials := make(map[models.VCSHostType]VCSCredentials)
+		for _, c := range []struct {
+			vcs         string
+			vcsHostType models.VCSHostType
+			user        string
+			token       string
+		}{
+			{"github", models.Github, t.GithubUser, t.GithubToken},
+			{"gitlab", models.Gitlab, t.GitlabUser, t.GitlabToken},
+		} {
+			if (c.user == "") != (c.token == "") {
+				return nil, fmt.Errorf("tenant %q must set both %s_user and %s_token or neither", t.Name, c.vcs, c.vcs)
+			}
+			if c.user != "" {
+				credentials[c.vcsHostType] = VCSCredentials{User: c.user, Token: c.token}
+			}
+		}
+		tenants.tenants = append(tenants.tenants, tenant{
+			name:        t.Name,
+			allowlist:   allowlist,
+			credentials: credentials,
+			webGroups:   t.WebGroups,
+		})
+	}
+	return tenants, nil
+}
+
+// TenantFor returns the name of the tenant the repo belongs to and false if
+// it doesn't belong to any.
+func (t *Tenants) TenantFor(repoFullName string, vcsHostname string) (string, bool) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	for _, tenant := range t.tenants {
+		if tenant.allowlist.IsAllowlisted(repoFullName, vcsHostname) {
+			return tenant.name, true
+		}
+	}
+	return "", false
+}
+
+// Credentials returns the tenant of the repo and its credentials for the VCS
+// host of the repo, false if the repo doesn't belong to a tenant or its
+// tenant has no credentials of its own for the VCS host.
+func (t *Tenants) Credentials(vcsHostType models.VCSHostType, repoFullName string, vcsHostname string) (string, VCSCredentials, bool) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	for _, tenant := range t.tenants {
+		if tenant.allowlist.IsAllowlisted(repoFullName, vcsHostname) {
+			credentials, ok := tenant.credentials[vcsHostType]
+			return tenant.name, credentials, ok
+		}
+	}
+	return "", VCSCredentials{}, false
+}
+
+// ViewableBy returns true if the repo belongs to a tenant whose web_groups
+// include one of groups.
+func (t *Tenants) ViewableBy(groups []string, repoFullName string, vcsHostname string) bool {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	for _, tenant := range t.tenants {
+		if tenant.allowlist.IsAllowlisted(repoFullName, vcsHostname) {
+			return slices.ContainsFunc(tenant.webGroups, func(g string) bool { return slices.Contains(groups, g) })
+		}
+	}
+	return false
+}
+
+// WebGroups returns the web_groups of all the tenants.
+func (t *Tenants) WebGroups() []string {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	var groups []string
+	for _, tenant := range t.tenants {
+		groups = append(groups, tenant.webGroups...)
+	}
+	return groups
+}
+
+// TenantDataDir returns the data dir of the tenant, a subdirectory of the
+// data dir of Atlantis.
+func TenantDataDir(dataDir string, tenant string) string {
+	return filepath.Join(dataDir, tenantsDir, tenant)
+}
+
+// reposDirs returns the directories of the working dirs of the repos in
+// dataDir and in the data dirs of the tenants in it.
+func reposDirs(dataDir string) ([]string, error) {
+	tenantReposDirs, err := filepath.Glob(filepath.Join(dataDir, tenantsDir, "*", workingDirPrefix))
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{filepath.Join(dataDir, workingDirPrefix)}, tenantReposDirs...), nil
+}
+
+// Replace atomically replaces the tenants with other's, ex. when the tenants
+// config is reloaded.
+func (t *Tenants) Replace(other *Tenants) {
+	other.mutex.RLock()
+	tenants := other.tenants
+	other.mutex.RUnlock()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.tenants = tenants
+}
@@ -0,0 +1,145 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func writeTenantsConfig(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tenants.yaml")
+	Ok(t, os.WriteFile(path, []byte(contents), 0600))
+	return path
+}
+
+func TestLoadTenants(t *testing.T) {
+	tenants, err := events.LoadTenants(writeTenantsConfig(t, `
+tenants:
+- name: acme
+  repo_allowlist: github.com/acme/*,!github.com/acme/sandbox
+- name: globex
+  repo_allowlist: gitlab.example.com/globex/*,github.com/acme/sandbox
+`))
+	Ok(t, err)
+
+	cases := []struct {
+		repoFullName string
+		hostname     string
+		expTenant    string
+		expOk        bool
+	}{
+		{"acme/infra", "github.com", "acme", true},
+		{"acme/sandbox", "github.com", "globex", true},
+		{"globex/infra", "gitlab.example.com", "globex", true},
+		{"globex/infra", "github.com", "", false},
+		{"initech/infra", "github.com", "", false},
+	}
+	for _, c := range cases {
+		t.Run(c.repoFullName+"@"+c.hostname, func(t *testing.T) {
+			tenant, ok := tenants.TenantFor(c.repoFullName, c.hostname)
+			Equals(t, c.expOk, ok)
+			Equals(t, c.expTenant, tenant)
+		})
+	}
+}
+
+func TestLoadTenants_Invalid(t *testing.T) {
+	cases := []struct {
+		description string
+		contents    string
+		expErr      string
+	}{
+		{
+			"no name",
+			"tenants:\n- repo_allowlist: github.com/acme/*\n",
+			"tenants must have a name",
+		},
+		{
+			"duplicate name",
+			"tenants:\n- name: acme\n  repo_allowlist: github.com/acme/*\n- name: acme\n  repo_allowlist: github.com/acme2/*\n",
+			`tenant "acme" is defined more than once`,
+		},
+		{
+			"no allowlist",
+			"tenants:\n- name: acme\n",
+			`tenant "acme" must have a repo_allowlist`,
+		},
+		{
+			"invalid name",
+			"tenants:\n- name: ../acme\n  repo_allowlist: github.com/acme/*\n",
+			`tenant "../acme" must only contain letters, digits, '_', '.' and '-' and start with a letter or digit`,
+		},
+		{
+			"user without token",
+			"tenants:\n- name: acme\n  repo_allowlist: github.com/acme/*\n  github_user: acme-atlantis\n",
+			`tenant "acme" must set both github_user and github_token or neither`,
+		},
+		{
+			"scheme in allowlist",
+			"tenants:\n- name: acme\n  repo_allowlist: https://github.com/acme/*\n",
+			`tenant "acme": allowlist "https://github.com/acme/*" contained ://`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			_, err := events.LoadTenants(writeTenantsConfig(t, c.contents))
+			ErrEquals(t, c.expErr, err)
+		})
+	}
+
+	path := writeTenantsConfig(t, "tenants: []\n")
+	_, err := events.LoadTenants(path)
+	ErrEquals(t, path+" doesn't define any tenants", err)
+}
+
+func TestTenants_CredentialsAndWebGroups(t *testing.T) {
+	tenants, err := events.LoadTenants(writeTenantsConfig(t, `
+tenants:
+- name: acme
+  repo_allowlist: github.com/acme/*
+  github_user: acme-atlantis
+  github_token: acme-token
+  web_groups: [acme-engineers, acme-ops]
+- name: globex
+  repo_allowlist: gitlab.example.com/globex/*
+`))
+	Ok(t, err)
+
+	tenant, credentials, ok := tenants.Credentials(models.Github, "acme/infra", "github.com")
+	Assert(t, ok, "exp acme to have GitHub credentials")
+	Equals(t, "acme", tenant)
+	Equals(t, events.VCSCredentials{User: "acme-atlantis", Token: "acme-token"}, credentials)
+	_, _, ok = tenants.Credentials(models.Gitlab, "globex/infra", "gitlab.example.com")
+	Assert(t, !ok, "exp globex not to have credentials")
+	_, _, ok = tenants.Credentials(models.Github, "initech/infra", "github.com")
+	Assert(t, !ok, "exp repos of no tenant not to have credentials")
+
+	Equals(t, true, tenants.ViewableBy([]string{"acme-ops"}, "acme/infra", "github.com"))
+	Equals(t, false, tenants.ViewableBy([]string{"acme-ops"}, "globex/infra", "gitlab.example.com"))
+	Equals(t, false, tenants.ViewableBy([]string{"acme-ops"}, "", ""))
+	Equals(t, []string{"acme-engineers", "acme-ops"}, tenants.WebGroups())
+}
+
+func TestRepoAllowlistChecker_Tenants(t *testing.T) {
+	tenants, err := events.LoadTenants(writeTenantsConfig(t, `
+tenants:
+- name: acme
+  repo_allowlist: github.com/acme/*
+`))
+	Ok(t, err)
+	allowlist, err := events.NewRepoAllowlistChecker("github.com/*,!github.com/acme/secret")
+	Ok(t, err)
+	allowlist.Tenants = tenants
+
+	Equals(t, true, allowlist.IsAllowlisted("acme/infra", "github.com"))
+	Equals(t, false, allowlist.IsAllowlisted("acme/secret", "github.com"))
+	Equals(t, false, allowlist.IsAllowlisted("initech/infra", "github.com"))
+}
@@ -0,0 +1,146 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package timeline records the timeline of everything Atlantis did for each
+// pull request, for debugging and tooling.
+package timeline
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+const (
+	// maxEventsPerPull is how many events are kept for each pull request,
+	// older ones are dropped.
+	maxEventsPerPull = 200
+	// maxPulls is how many pull requests events are kept for, the pull
+	// request with the least recent event is dropped.
+	maxPulls = 500
+)
+
+// EventType is the type of an event.
+type EventType string
+
+const (
+	// Webhook is a webhook received for the pull request.
+	Webhook EventType = "webhook"
+	// Command is a command started or finished for the pull request.
+	Command EventType = "command"
+	// Lock is a project lock taken, or failed to be taken, by the pull
+	// request.
+	Lock EventType = "lock"
+	// Comment is a comment posted on the pull request.
+	Comment EventType = "comment"
+	// Summary is a plan summary generated for the pull request.
+	Summary EventType = "summary"
+)
+
+// Event is something Atlantis did for a pull request.
+type Event struct {
+	// ID identifies the event in the timeline of its pull request.
+	ID          string
+	Time        time.Time
+	Type        EventType
+	Description string
+	// User is who triggered the event, if any.
+	User string
+	// Project and Workspace are set for the events of a project.
+	Project   string
+	Workspace string
+	// Request is the command that was run, set for the command events of
+	// comment commands so they can be replayed.
+	Request *models.CommandRequest
+}
+
+// Store keeps the timeline of the pull requests in memory so it can be
+// fetched through the API. Timelines don't survive restarts. Its methods are
+// no-ops on a nil Store, so recording events is optional.
+type Store struct {
+	mutex sync.Mutex
+	pulls map[string][]Event
+	// order are the keys of pulls, the one with the least recent event first.
+	order []string
+}
+
+// NewStore returns an empty Store.
+func NewStore() *Store {
+	return &Store{pulls: make(map[string][]Event)}
+}
+
+// Record records event in the timeline of the pull request. Its time is set
+// to now, and its ID generated, if they're not set.
+func (s *Store) Record(repoFullName string, pullNum int, event Event) {
+	if s == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if event.ID == "" {
+		event.ID = NewEventID()
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := storeKey(repoFullName, pullNum)
+	events := append(s.pulls[key], event)
+	if len(events) > maxEventsPerPull {
+		events = events[len(events)-maxEventsPerPull:]
+	}
+	s.pulls[key] = events
+
+	s.order = slices.DeleteFunc(s.order, func(k string) bool { return k == key })
+	s.order = append(s.order, key)
+	if len(s.order) > maxPulls {
+		delete(s.pulls, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+// List returns the timeline of the pull request, oldest first.
+func (s *Store) List(repoFullName string, pullNum int) []Event {
+	if s == nil {
+		return nil
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	events := slices.Clone(s.pulls[storeKey(repoFullName, pullNum)])
+	// Events recorded concurrently, ex. by parallel plans, may be recorded
+	// slightly out of order.
+	slices.SortStableFunc(events, func(a, b Event) int { return a.Time.Compare(b.Time) })
+	return events
+}
+
+// Get returns the event with id in the timeline of the pull request.
+func (s *Store) Get(repoFullName string, pullNum int, id string) (Event, bool) {
+	if s == nil {
+		return Event{}, false
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	events := s.pulls[storeKey(repoFullName, pullNum)]
+	i := slices.IndexFunc(events, func(e Event) bool { return e.ID == id })
+	if i == -1 {
+		return Event{}, false
+	}
+	return events[i], true
+}
+
+// NewEventID returns a random event ID, ex. to record the event elsewhere
+// with its ID before recording it in the timeline.
+func NewEventID() string {
+	id := make([]byte, 8)
+	rand.Read(id) // nolint: errcheck
+	return hex.EncodeToString(id)
+}
+
+func storeKey(repoFullName string, pullNum int) string {
+	return fmt.Sprintf("%s#%d", repoFullName, pullNum)
+}
@@ -0,0 +1,64 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package timeline_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/timeline"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestStore(t *testing.T) {
+	store := timeline.NewStore()
+	Equals(t, 0, len(store.List("owner/repo", 1)))
+
+	for i := range 205 {
+		store.Record("owner/repo", 1, timeline.Event{Type: timeline.Command, Description: fmt.Sprintf("event %d", i)})
+	}
+	events := store.List("owner/repo", 1)
+	Equals(t, 200, len(events))
+	Equals(t, "event 5", events[0].Description)
+	Equals(t, "event 204", events[199].Description)
+	Assert(t, !events[0].Time.IsZero(), "exp the time to be set")
+
+	t.Log("events are ordered by time")
+	at := time.Now()
+	store.Record("owner/repo", 2, timeline.Event{Time: at.Add(time.Second), Type: timeline.Comment})
+	store.Record("owner/repo", 2, timeline.Event{Time: at, Type: timeline.Lock})
+	events = store.List("owner/repo", 2)
+	Equals(t, timeline.Lock, events[0].Type)
+	Equals(t, timeline.Comment, events[1].Type)
+
+	t.Log("the pulls with the least recent events are dropped")
+	for i := 3; i <= 501; i++ {
+		store.Record("owner/repo", i, timeline.Event{Type: timeline.Webhook})
+	}
+	Equals(t, 0, len(store.List("owner/repo", 1)))
+	Equals(t, 2, len(store.List("owner/repo", 2)))
+
+	t.Log("a nil store records nothing")
+	var nilStore *timeline.Store
+	nilStore.Record("owner/repo", 1, timeline.Event{Type: timeline.Webhook})
+	Equals(t, 0, len(nilStore.List("owner/repo", 1)))
+}
+
+func TestStore_Get(t *testing.T) {
+	store := timeline.NewStore()
+	store.Record("owner/repo", 1, timeline.Event{Type: timeline.Command, Description: "Running the plan command"})
+	store.Record("owner/repo", 1, timeline.Event{ID: "id", Type: timeline.Lock})
+	events := store.List("owner/repo", 1)
+	Assert(t, events[0].ID != "", "exp the ID to be generated")
+
+	event, ok := store.Get("owner/repo", 1, events[0].ID)
+	Assert(t, ok, "exp the event to be found")
+	Equals(t, events[0], event)
+	event, ok = store.Get("owner/repo", 1, "id")
+	Assert(t, ok, "exp the event to be found")
+	Equals(t, timeline.Lock, event.Type)
+	_, ok = store.Get("owner/repo", 2, "id")
+	Assert(t, !ok, "exp the events of other pulls not to be found")
+}
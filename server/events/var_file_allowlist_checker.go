@@ -35,6 +35,18 @@ func NewVarFileAllowlistChecker(allowlist string) (*VarFileAllowlistChecker, err
 }
 
 func (p *VarFileAllowlistChecker) Check(flags []string) error {
+	for _, path := range varFilePaths(flags) {
+		if !p.isAllowedPath(path) {
+			return fmt.Errorf("var file path %s is not allowed by the current allowlist: [%s]",
+				path, strings.Join(p.rules, ", "))
+		}
+	}
+	return nil
+}
+
+// varFilePaths returns the paths of the -var-file flags in flags.
+func varFilePaths(flags []string) []string {
+	var paths []string
 	for i, flag := range flags {
 		var path string
 		if i < len(flags)-1 && flag == "-var-file" {
@@ -47,13 +59,11 @@ func (p *VarFileAllowlistChecker) Check(flags []string) error {
 				path = flagSplit[1]
 			}
 		}
-
-		if path != "" && !p.isAllowedPath(path) {
-			return fmt.Errorf("var file path %s is not allowed by the current allowlist: [%s]",
-				path, strings.Join(p.rules, ", "))
+		if path != "" {
+			paths = append(paths, path)
 		}
 	}
-	return nil
+	return paths
 }
 
 func (p *VarFileAllowlistChecker) isAllowedPath(path string) bool {
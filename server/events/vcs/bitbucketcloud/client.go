@@ -24,9 +24,7 @@ const BaseURL = "https://api.bitbucket.org"
 
 type Client struct {
 	httpClient  *http.Client
-	username    string // Used for git operations
-	apiUser     string // Used for API calls (Basic Auth)
-	password    string
+	credentials Credentials
 	BaseURL     string
 	atlantisURL string
 }
@@ -38,18 +36,22 @@ type Client struct {
 // username is used for git operations, apiUser is used for API authentication (Basic Auth).
 // If apiUser is empty, it will default to username for backward compatibility.
 func New(httpClient *http.Client, username string, password string, apiUser string, atlantisURL string) *Client {
+	return NewWithCredentials(httpClient, &AppPasswordCredentials{
+		Username:    username,
+		APIUser:     apiUser,
+		AppPassword: password,
+	}, atlantisURL)
+}
+
+// NewWithCredentials builds a bitbucket cloud client authenticating with
+// credentials, ex. an access token or an OAuth consumer.
+func NewWithCredentials(httpClient *http.Client, credentials Credentials, atlantisURL string) *Client {
 	if httpClient == nil {
 		httpClient = http.DefaultClient
 	}
-	// Use apiUser for API calls if provided, otherwise fall back to username for backward compatibility
-	if apiUser == "" {
-		apiUser = username
-	}
 	return &Client{
 		httpClient:  httpClient,
-		username:    username,
-		apiUser:     apiUser,
-		password:    password,
+		credentials: credentials,
 		BaseURL:     BaseURL,
 		atlantisURL: atlantisURL,
 	}
@@ -331,8 +333,9 @@ func (b *Client) prepRequest(method string, path string, body io.Reader) (*http.
 	if err != nil {
 		return nil, err
 	}
-	// Use ApiUser for API authentication, Username is for git operations
-	req.SetBasicAuth(b.apiUser, b.password)
+	if err := b.credentials.Authorize(req); err != nil {
+		return nil, fmt.Errorf("authenticating request: %w", err)
+	}
 	if body != nil {
 		req.Header.Add("Content-Type", "application/json")
 	}
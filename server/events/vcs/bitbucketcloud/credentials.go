@@ -0,0 +1,179 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bitbucketcloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OAuthTokenURL is the endpoint issuing the access tokens of OAuth consumers.
+const OAuthTokenURL = "https://bitbucket.org/site/oauth2/access_token"
+
+// tokenGitUser is the git username of the access tokens and OAuth tokens.
+// See https://support.atlassian.com/bitbucket-cloud/docs/using-access-tokens/.
+const tokenGitUser = "x-token-auth"
+
+// oauthTokenExpiryMargin is how long before they expire OAuth tokens are
+// refreshed so that requests and clones don't use expired tokens.
+const oauthTokenExpiryMargin = 5 * time.Minute
+
+// Credentials authenticate Atlantis to the Bitbucket Cloud API and to git.
+type Credentials interface {
+	// Authorize adds the authentication to req.
+	Authorize(req *http.Request) error
+	// GitCredentials returns the user and password of git operations.
+	GitCredentials() (user string, password string, err error)
+}
+
+// AppPasswordCredentials authenticate with the app password of a user.
+type AppPasswordCredentials struct {
+	// Username is the user of git operations.
+	Username string
+	// APIUser is the user of API calls. It defaults to Username.
+	APIUser     string
+	AppPassword string
+}
+
+// Authorize adds basic authentication to req.
+func (c *AppPasswordCredentials) Authorize(req *http.Request) error {
+	apiUser := c.APIUser
+	if apiUser == "" {
+		apiUser = c.Username
+	}
+	req.SetBasicAuth(apiUser, c.AppPassword)
+	return nil
+}
+
+// GitCredentials returns the username and app password.
+func (c *AppPasswordCredentials) GitCredentials() (string, string, error) {
+	return c.Username, c.AppPassword, nil
+}
+
+// AccessTokenCredentials authenticate with a workspace, project or repository
+// access token.
+type AccessTokenCredentials struct {
+	Token string
+}
+
+// Authorize adds the token to req.
+func (c *AccessTokenCredentials) Authorize(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	return nil
+}
+
+// GitCredentials returns the token with the user git expects for tokens.
+func (c *AccessTokenCredentials) GitCredentials() (string, string, error) {
+	return tokenGitUser, c.Token, nil
+}
+
+// OAuthCredentials authenticate as an OAuth consumer with the client
+// credentials grant. Access tokens are requested when needed and refreshed
+// before they expire.
+type OAuthCredentials struct {
+	HTTPClient   *http.Client
+	ClientID     string
+	ClientSecret string
+	// TokenURL defaults to OAuthTokenURL.
+	TokenURL string
+
+	mutex        sync.Mutex
+	token        string
+	refreshToken string
+	expiry       time.Time
+}
+
+type oauthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// Authorize adds a valid access token to req.
+func (c *OAuthCredentials) Authorize(req *http.Request) error {
+	token, err := c.Token()
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// GitCredentials returns a valid access token with the user git expects for
+// tokens.
+func (c *OAuthCredentials) GitCredentials() (string, string, error) {
+	token, err := c.Token()
+	return tokenGitUser, token, err
+}
+
+// Token returns the access token, requesting a new one if there's none or it
+// expires soon.
+func (c *OAuthCredentials) Token() (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.token != "" && time.Now().Before(c.expiry.Add(-oauthTokenExpiryMargin)) {
+		return c.token, nil
+	}
+
+	// Refresh the token if there's one, falling back to the client credentials
+	// if the refresh token was revoked.
+	if c.refreshToken != "" {
+		err := c.requestToken(url.Values{"grant_type": {"refresh_token"}, "refresh_token": {c.refreshToken}})
+		if err == nil {
+			return c.token, nil
+		}
+		c.refreshToken = ""
+	}
+	if err := c.requestToken(url.Values{"grant_type": {"client_credentials"}}); err != nil {
+		return "", err
+	}
+	return c.token, nil
+}
+
+// requestToken requests an access token with form. c.mutex must be held.
+func (c *OAuthCredentials) requestToken(form url.Values) error {
+	tokenURL := c.TokenURL
+	if tokenURL == "" {
+		tokenURL = OAuthTokenURL
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("constructing OAuth token request: %w", err)
+	}
+	req.SetBasicAuth(c.ClientID, c.ClientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("requesting OAuth token: %w", err)
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading OAuth token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("requesting OAuth token: %s response %q", resp.Status, string(body))
+	}
+	var token oauthTokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return fmt.Errorf("parsing OAuth token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return fmt.Errorf("OAuth token response has no access token")
+	}
+	c.token = token.AccessToken
+	c.refreshToken = token.RefreshToken
+	c.expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bitbucketcloud_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketcloud"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestAppPasswordCredentials(t *testing.T) {
+	req, err := http.NewRequest("GET", "https://api.bitbucket.org", nil)
+	Ok(t, err)
+	credentials := &bitbucketcloud.AppPasswordCredentials{Username: "user", AppPassword: "password"}
+	Ok(t, credentials.Authorize(req))
+	user, password, ok := req.BasicAuth()
+	Assert(t, ok, "expected basic auth")
+	Equals(t, "user", user)
+	Equals(t, "password", password)
+
+	credentials.APIUser = "api-user@example.com"
+	Ok(t, credentials.Authorize(req))
+	user, _, _ = req.BasicAuth()
+	Equals(t, "api-user@example.com", user)
+	user, password, err = credentials.GitCredentials()
+	Ok(t, err)
+	Equals(t, "user", user)
+	Equals(t, "password", password)
+}
+
+func TestAccessTokenCredentials(t *testing.T) {
+	var authorization string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Write([]byte(`{"values":[]}`)) // nolint: errcheck
+	}))
+	defer testServer.Close()
+
+	client := bitbucketcloud.NewWithCredentials(http.DefaultClient, &bitbucketcloud.AccessTokenCredentials{Token: "token"}, "https://atlantis.example.com")
+	client.BaseURL = testServer.URL
+	_, err := client.GetModifiedFiles(logging.NewNoopLogger(t), models.Repo{FullName: "owner/repo"}, models.PullRequest{Num: 1})
+	Ok(t, err)
+	Equals(t, "Bearer token", authorization)
+
+	user, password, err := (&bitbucketcloud.AccessTokenCredentials{Token: "token"}).GitCredentials()
+	Ok(t, err)
+	Equals(t, "x-token-auth", user)
+	Equals(t, "token", password)
+}
+
+func TestOAuthCredentials(t *testing.T) {
+	var grants []string
+	expiresIn := 7200
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, _ := r.BasicAuth()
+		Equals(t, "key", clientID)
+		Equals(t, "secret", clientSecret)
+		Ok(t, r.ParseForm())
+		grants = append(grants, r.PostForm.Get("grant_type")+":"+r.PostForm.Get("refresh_token"))
+		fmt.Fprintf(w, `{"access_token":"token-%d","refresh_token":"refresh-%d","expires_in":%d}`, len(grants), len(grants), expiresIn) // nolint: errcheck
+	}))
+	defer testServer.Close()
+
+	credentials := &bitbucketcloud.OAuthCredentials{ClientID: "key", ClientSecret: "secret", TokenURL: testServer.URL}
+	user, token, err := credentials.GitCredentials()
+	Ok(t, err)
+	Equals(t, "x-token-auth", user)
+	Equals(t, "token-1", token)
+
+	// The token is reused until it expires.
+	req, err := http.NewRequest("GET", "https://api.bitbucket.org", nil)
+	Ok(t, err)
+	Ok(t, credentials.Authorize(req))
+	Equals(t, "Bearer token-1", req.Header.Get("Authorization"))
+	Equals(t, []string{"client_credentials:"}, grants)
+
+	// Tokens expiring soon are refreshed with their refresh token.
+	expiresIn = 60
+	expiring := &bitbucketcloud.OAuthCredentials{ClientID: "key", ClientSecret: "secret", TokenURL: testServer.URL}
+	token, err = expiring.Token()
+	Ok(t, err)
+	Equals(t, "token-2", token)
+	token, err = expiring.Token()
+	Ok(t, err)
+	Equals(t, "token-3", token)
+	Equals(t, []string{"client_credentials:", "client_credentials:", "refresh_token:refresh-2"}, grants)
+}
+
+func TestOAuthCredentials_Error(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"invalid_client"}`)) // nolint: errcheck
+	}))
+	defer testServer.Close()
+
+	credentials := &bitbucketcloud.OAuthCredentials{ClientID: "key", ClientSecret: "wrong", TokenURL: testServer.URL}
+	_, err := credentials.Token()
+	ErrEquals(t, `requesting OAuth token: 401 Unauthorized response "{\"error\":\"invalid_client\"}"`, err)
+}
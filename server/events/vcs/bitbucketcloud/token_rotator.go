@@ -0,0 +1,59 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package bitbucketcloud
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/vcs/common"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/scheduled"
+)
+
+// GitHostname is the hostname of the git operations of Bitbucket Cloud.
+const GitHostname = "bitbucket.org"
+
+// TokenRotator keeps the ~/.git-credentials file up to date with the access
+// token of OAuth consumers, which expire after two hours.
+type TokenRotator struct {
+	log         logging.SimpleLogging
+	credentials Credentials
+	homeDirPath string
+}
+
+func NewTokenRotator(log logging.SimpleLogging, credentials Credentials, homeDirPath string) *TokenRotator {
+	return &TokenRotator{
+		log:         log,
+		credentials: credentials,
+		homeDirPath: homeDirPath,
+	}
+}
+
+// GenerateJob writes the credentials and returns the job refreshing them
+// every 30 seconds.
+func (r *TokenRotator) GenerateJob() (scheduled.JobDefinition, error) {
+	return scheduled.JobDefinition{
+		Job:    r,
+		Period: 30 * time.Second,
+	}, r.rotate()
+}
+
+func (r *TokenRotator) Run() {
+	if err := r.rotate(); err != nil {
+		r.log.Err(err.Error())
+	}
+}
+
+func (r *TokenRotator) rotate() error {
+	r.log.Debug("Refreshing Bitbucket Cloud token for .git-credentials")
+	user, token, err := r.credentials.GitCredentials()
+	if err != nil {
+		return fmt.Errorf("getting bitbucket cloud token: %w", err)
+	}
+	if err := common.WriteGitCreds(user, token, GitHostname, r.homeDirPath, r.log, true); err != nil {
+		return fmt.Errorf("writing ~/.git-credentials file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,481 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package codecommit
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs/common"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// maxCommentLength is the maximum number of chars CodeCommit allows in a
+// single comment.
+const maxCommentLength = 10240
+
+// targetPrefix is the prefix of the X-Amz-Target header of the API actions.
+const targetPrefix = "CodeCommit_20150413."
+
+// approvalStateApprove is the approval state of users who approved a pull
+// request.
+const approvalStateApprove = "APPROVE"
+
+type Client struct {
+	httpClient  *http.Client
+	Region      string
+	endpoint    string
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+}
+
+// New builds a client that makes API calls to CodeCommit in region.
+// httpClient is the client to use to make the requests, endpoint overrides
+// the endpoint of the region, ex. for VPC endpoints, and credentials sign the
+// requests.
+func New(httpClient *http.Client, region string, endpoint string, credentials aws.CredentialsProvider) (*Client, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	if region == "" {
+		return nil, errors.New("region must be set, ex. with AWS_REGION")
+	}
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://codecommit.%s.amazonaws.com", region)
+	}
+	parsedURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", endpoint, err)
+	}
+	if parsedURL.Scheme == "" {
+		return nil, fmt.Errorf("must have 'http://' or 'https://' in endpoint %q", endpoint)
+	}
+	return &Client{
+		httpClient:  httpClient,
+		Region:      region,
+		endpoint:    strings.TrimRight(parsedURL.String(), "/"),
+		credentials: credentials,
+		signer:      v4.NewSigner(),
+	}, nil
+}
+
+// NewFromDefaultConfig builds a client of CodeCommit in region with the
+// credentials of the AWS SDK's default chain, ex. environment variables,
+// shared config files, web identity tokens or the ECS and EC2 instance roles,
+// which are refreshed when they expire. The region defaults to the SDK's, ex.
+// AWS_REGION, and the endpoint to AWS_ENDPOINT_URL_CODECOMMIT.
+func NewFromDefaultConfig(ctx context.Context, httpClient *http.Client, region string) (*Client, error) {
+	var opts []func(*config.LoadOptions) error
+	if region != "" {
+		opts = append(opts, config.WithRegion(region))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS config: %w", err)
+	}
+	return New(httpClient, cfg.Region, os.Getenv("AWS_ENDPOINT_URL_CODECOMMIT"), cfg.Credentials)
+}
+
+// GitHostname returns the hostname of the Git repos of CodeCommit in region.
+func GitHostname(region string) string {
+	return fmt.Sprintf("git-codecommit.%s.amazonaws.com", region)
+}
+
+// CloneURL returns the HTTPS clone URL of the repo name in region, without
+// credentials.
+func CloneURL(region string, name string) string {
+	return fmt.Sprintf("https://%s/v1/repos/%s", GitHostname(region), name)
+}
+
+// PullURL returns the URL of the pull request pullNum of the repo name in the
+// console.
+func PullURL(region string, name string, pullNum int) string {
+	return fmt.Sprintf("https://%s.console.aws.amazon.com/codesuite/codecommit/repositories/%s/pull-requests/%d/details?region=%s", region, name, pullNum, region)
+}
+
+// UsernameFromARN returns the name of the user or role session of arn, ex.
+// alice for arn:aws:iam::123456789012:user/alice or
+// arn:aws:sts::123456789012:assumed-role/Admin/alice.
+func UsernameFromARN(arn string) string {
+	name := arn[strings.LastIndex(arn, ":")+1:]
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+// GetPullRequest returns the pull request pullNum.
+func (c *Client) GetPullRequest(logger logging.SimpleLogging, pullNum int) (*PullRequest, error) {
+	logger.Debug("Getting CodeCommit pull request %d", pullNum)
+	var out struct {
+		PullRequest PullRequest `json:"pullRequest"`
+	}
+	if err := c.call("GetPullRequest", map[string]any{"pullRequestId": strconv.Itoa(pullNum)}, &out); err != nil {
+		return nil, err
+	}
+	if len(out.PullRequest.PullRequestTargets) == 0 {
+		return nil, fmt.Errorf("pull request %d has no targets", pullNum)
+	}
+	return &out.PullRequest, nil
+}
+
+// GetComment returns the comment commentID. Comment events don't include the
+// content of their comment.
+func (c *Client) GetComment(logger logging.SimpleLogging, commentID string) (*Comment, error) {
+	logger.Debug("Getting CodeCommit comment %s", commentID)
+	var out struct {
+		Comment Comment `json:"comment"`
+	}
+	if err := c.call("GetComment", map[string]any{"commentId": commentID}, &out); err != nil {
+		return nil, err
+	}
+	return &out.Comment, nil
+}
+
+// ConfirmSubscription confirms the subscription of Atlantis to the SNS topic
+// of message by visiting its SubscribeURL. The URL must be an SNS endpoint of
+// the region of the client so requests can't make Atlantis visit any URL.
+func (c *Client) ConfirmSubscription(logger logging.SimpleLogging, message SNSMessage) error {
+	subscribeURL, err := url.Parse(message.SubscribeURL)
+	if err != nil {
+		return fmt.Errorf("parsing SubscribeURL: %w", err)
+	}
+	if subscribeURL.Scheme != "https" || subscribeURL.Host != fmt.Sprintf("sns.%s.amazonaws.com", c.Region) {
+		return fmt.Errorf("SubscribeURL %q isn't an SNS endpoint of %s", message.SubscribeURL, c.Region)
+	}
+	logger.Info("Confirming subscription to SNS topic %s", message.TopicArn)
+	resp, err := c.httpClient.Get(subscribeURL.String())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("confirming subscription unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// GetModifiedFiles returns the names of files that were modified in the merge request
+// relative to the repo root, e.g. parent/child/file.txt.
+func (c *Client) GetModifiedFiles(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest) ([]string, error) {
+	pr, err := c.GetPullRequest(logger, pull.Num)
+	if err != nil {
+		return nil, err
+	}
+	target, err := pr.Target()
+	if err != nil {
+		return nil, err
+	}
+	before := target.MergeBase
+	if before == "" {
+		before = target.DestinationCommit
+	}
+
+	var files []string
+	nextToken := ""
+	for {
+		input := map[string]any{
+			"repositoryName":        repo.Name,
+			"beforeCommitSpecifier": before,
+			"afterCommitSpecifier":  pull.HeadCommit,
+		}
+		if nextToken != "" {
+			input["NextToken"] = nextToken
+		}
+		var out struct {
+			Differences []Difference `json:"differences"`
+			NextToken   string       `json:"NextToken"`
+		}
+		if err := c.call("GetDifferences", input, &out); err != nil {
+			return nil, err
+		}
+		for _, difference := range out.Differences {
+			if difference.AfterBlob != nil {
+				files = append(files, difference.AfterBlob.Path)
+			}
+			// Include the old path of renamed and deleted files, since they
+			// modify their old directory too.
+			if difference.BeforeBlob != nil && (difference.AfterBlob == nil || difference.AfterBlob.Path != difference.BeforeBlob.Path) {
+				files = append(files, difference.BeforeBlob.Path)
+			}
+		}
+		if out.NextToken == "" {
+			return files, nil
+		}
+		nextToken = out.NextToken
+	}
+}
+
+// CreateComment comments on the pull request. It will write multiple comments
+// if a single comment is too long.
+func (c *Client) CreateComment(logger logging.SimpleLogging, repo models.Repo, pullNum int, comment string, _ string) error {
+	pr, err := c.GetPullRequest(logger, pullNum)
+	if err != nil {
+		return err
+	}
+	target, err := pr.Target()
+	if err != nil {
+		return err
+	}
+
+	logger.Debug("Creating comment on CodeCommit pull request %d", pullNum)
+	sepEnd := "\n```\n**Warning**: Output length greater than max comment size. Continued in next comment."
+	sepStart := "Continued from previous comment.\n```diff\n"
+	comments := common.SplitComment(comment, maxCommentLength, sepEnd, sepStart, 0, "")
+	for _, content := range comments {
+		input := map[string]any{
+			"pullRequestId":  strconv.Itoa(pullNum),
+			"repositoryName": repo.Name,
+			"beforeCommitId": target.DestinationCommit,
+			"afterCommitId":  target.SourceCommit,
+			"content":        content,
+		}
+		if err := c.call("PostCommentForPullRequest", input, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReactToComment does nothing, the comment IDs of CodeCommit aren't numbers
+// so they can't be passed to it.
+func (c *Client) ReactToComment(_ logging.SimpleLogging, _ models.Repo, _ int, _ int64, _ string) error {
+	return nil
+}
+
+// HidePrevCommandComments does nothing, CodeCommit can't hide comments.
+func (c *Client) HidePrevCommandComments(_ logging.SimpleLogging, _ models.Repo, _ int, _ string, _ string) error {
+	return nil
+}
+
+// PullIsApproved returns true if a user approved the current revision of the
+// pull request.
+func (c *Client) PullIsApproved(logger logging.SimpleLogging, _ models.Repo, pull models.PullRequest) (models.ApprovalStatus, error) {
+	pr, err := c.GetPullRequest(logger, pull.Num)
+	if err != nil {
+		return models.ApprovalStatus{}, err
+	}
+	var out struct {
+		Approvals []Approval `json:"approvals"`
+	}
+	input := map[string]any{"pullRequestId": pr.PullRequestID, "revisionId": pr.RevisionID}
+	if err := c.call("GetPullRequestApprovalStates", input, &out); err != nil {
+		return models.ApprovalStatus{}, err
+	}
+	for _, approval := range out.Approvals {
+		if approval.ApprovalState == approvalStateApprove {
+			return models.ApprovalStatus{IsApproved: true, ApprovedBy: UsernameFromARN(approval.UserARN)}, nil
+		}
+	}
+	return models.ApprovalStatus{IsApproved: false}, nil
+}
+
+// DiscardReviews does nothing, approvals are for a single revision of a pull
+// request so CodeCommit discards them when the pull request is updated.
+func (c *Client) DiscardReviews(_ logging.SimpleLogging, _ models.Repo, _ models.PullRequest) error {
+	return nil
+}
+
+// PullIsMergeable returns true if the pull request is open, its approval rules
+// are satisfied or overridden and it has no merge conflicts.
+func (c *Client) PullIsMergeable(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest, _ string, _ []string) (models.MergeableStatus, error) {
+	pr, err := c.GetPullRequest(logger, pull.Num)
+	if err != nil {
+		return models.MergeableStatus{}, err
+	}
+	target, err := pr.Target()
+	if err != nil {
+		return models.MergeableStatus{}, err
+	}
+	if pr.PullRequestStatus != PullRequestStatusOpen {
+		return models.MergeableStatus{IsMergeable: false, Reason: "Pull request is closed"}, nil
+	}
+
+	var evaluation struct {
+		Evaluation Evaluation `json:"evaluation"`
+	}
+	input := map[string]any{"pullRequestId": pr.PullRequestID, "revisionId": pr.RevisionID}
+	if err := c.call("EvaluatePullRequestApprovalRules", input, &evaluation); err != nil {
+		return models.MergeableStatus{}, err
+	}
+	if !evaluation.Evaluation.Approved && !evaluation.Evaluation.Overridden {
+		return models.MergeableStatus{
+			IsMergeable: false,
+			Reason:      fmt.Sprintf("Approval rules are not satisfied: %s", strings.Join(evaluation.Evaluation.ApprovalRulesNotSatisfied, ", ")),
+		}, nil
+	}
+
+	var conflicts struct {
+		Mergeable bool `json:"mergeable"`
+	}
+	input = map[string]any{
+		"repositoryName":             repo.Name,
+		"destinationCommitSpecifier": target.DestinationCommit,
+		"sourceCommitSpecifier":      target.SourceCommit,
+		"mergeOption":                "THREE_WAY_MERGE",
+	}
+	if err := c.call("GetMergeConflicts", input, &conflicts); err != nil {
+		return models.MergeableStatus{}, err
+	}
+	if !conflicts.Mergeable {
+		return models.MergeableStatus{IsMergeable: false, Reason: "Pull request has merge conflicts"}, nil
+	}
+	return models.MergeableStatus{IsMergeable: true}, nil
+}
+
+// UpdateStatus does nothing, CodeCommit has no commit statuses.
+func (c *Client) UpdateStatus(logger logging.SimpleLogging, _ models.Repo, pull models.PullRequest, state models.CommitStatus, src string, _ string, _ string) error {
+	logger.Debug("Skipping status %s of '%s' on CodeCommit pull request %d", state.String(), src, pull.Num)
+	return nil
+}
+
+// MergePull merges the pull request with a three-way merge, or a squash merge
+// or fast-forward if the merge method is squash or rebase.
+func (c *Client) MergePull(logger logging.SimpleLogging, pull models.PullRequest, pullOptions models.PullRequestOptions) error {
+	action := "MergePullRequestByThreeWay"
+	switch pullOptions.MergeMethod {
+	case "squash":
+		action = "MergePullRequestBySquash"
+	case "rebase":
+		action = "MergePullRequestByFastForward"
+	}
+	logger.Debug("Merging CodeCommit pull request %d with %s", pull.Num, action)
+	input := map[string]any{
+		"pullRequestId":  strconv.Itoa(pull.Num),
+		"repositoryName": pull.BaseRepo.Name,
+		"sourceCommitId": pull.HeadCommit,
+	}
+	return c.call(action, input, nil)
+}
+
+// MarkdownPullLink links to the pull request, CodeCommit has no markdown
+// shorthand for pull requests.
+func (c *Client) MarkdownPullLink(pull models.PullRequest) (string, error) {
+	if pull.URL == "" {
+		return fmt.Sprintf("pull request %d", pull.Num), nil
+	}
+	return fmt.Sprintf("[pull request %d](%s)", pull.Num, pull.URL), nil
+}
+
+// GetTeamNamesForUser returns no teams, the IAM groups of users aren't
+// available to CodeCommit.
+func (c *Client) GetTeamNamesForUser(_ logging.SimpleLogging, _ models.Repo, _ models.User) ([]string, error) {
+	return nil, nil
+}
+
+func (c *Client) SupportsSingleFileDownload(_ models.Repo) bool {
+	return true
+}
+
+// GetFileContent a repository file content from VCS (which support fetch a single file from repository)
+// The first return value indicates whether the repo contains a file or not
+// if BaseRepo had a file, its content will placed on the second return value
+func (c *Client) GetFileContent(logger logging.SimpleLogging, repo models.Repo, branch string, fileName string) (bool, []byte, error) {
+	logger.Debug("Getting file content for %s in CodeCommit repo %s", fileName, repo.FullName)
+	var out struct {
+		FileContent []byte `json:"fileContent"`
+	}
+	input := map[string]any{"repositoryName": repo.Name, "commitSpecifier": branch, "filePath": fileName}
+	err := c.call("GetFile", input, &out)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Name() == "FileDoesNotExistException" {
+		return false, []byte{}, nil
+	}
+	if err != nil {
+		return true, []byte{}, err
+	}
+	return true, out.FileContent, nil
+}
+
+// GetCloneURL returns the clone URL of repo, whose full name is its account
+// and name, without credentials.
+func (c *Client) GetCloneURL(_ logging.SimpleLogging, _ models.VCSHostType, repo string) (string, error) {
+	return CloneURL(c.Region, repo[strings.LastIndex(repo, "/")+1:]), nil
+}
+
+// GetPullLabels returns no labels, CodeCommit pull requests have none.
+func (c *Client) GetPullLabels(_ logging.SimpleLogging, _ models.Repo, _ models.PullRequest) ([]string, error) {
+	return nil, nil
+}
+
+// APIError is an error returned by the API.
+type APIError struct {
+	// Type is the type of the error, ex.
+	// com.amazonaws.codecommit#FileDoesNotExistException.
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+// Name returns the name of the type of the error, ex. FileDoesNotExistException.
+func (e *APIError) Name() string {
+	return e.Type[strings.LastIndex(e.Type, "#")+1:]
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Name(), e.Message)
+}
+
+// call calls the CodeCommit action with input and decodes its output into
+// out if it isn't nil.
+func (c *Client) call(action string, input any, out any) error {
+	if c.credentials == nil {
+		return errors.New("AWS credentials must be configured to use CodeCommit")
+	}
+	ctx := context.Background()
+	credentials, err := c.credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("retrieving AWS credentials: %w", err)
+	}
+	body, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("json encoding: %w", err)
+	}
+	req, err := http.NewRequest("POST", c.endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("constructing request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", targetPrefix+action)
+	payloadHash := sha256.Sum256(body)
+	if err := c.signer.SignHTTP(ctx, credentials, req, hex.EncodeToString(payloadHash[:]), "codecommit", c.Region, time.Now()); err != nil {
+		return fmt.Errorf("signing %s: %w", action, err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response from %s: %w", action, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{}
+		if json.Unmarshal(respBody, apiErr) != nil || apiErr.Type == "" {
+			return fmt.Errorf("calling %s unexpected status code: %d, body: %s", action, resp.StatusCode, string(respBody))
+		}
+		return fmt.Errorf("calling %s: %w", action, apiErr)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("parsing response from %s: %w", action, err)
+	}
+	return nil
+}
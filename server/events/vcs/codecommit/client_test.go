@@ -0,0 +1,254 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package codecommit_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs/codecommit"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+var repo = models.Repo{FullName: "123456789012/terraform", Owner: "123456789012", Name: "terraform"}
+
+const pullRequest = `{"pullRequest": {
+	"pullRequestId": "7",
+	"pullRequestStatus": "OPEN",
+	"revisionId": "rev1",
+	"pullRequestTargets": [{
+		"repositoryName": "terraform",
+		"sourceReference": "refs/heads/feature",
+		"sourceCommit": "source",
+		"destinationReference": "refs/heads/main",
+		"destinationCommit": "destination",
+		"mergeBase": "base"
+	}]
+}}`
+
+// codecommitServer returns a client of a CodeCommit test server that responds
+// to the actions of responses and records the bodies of the requests it
+// receives.
+func codecommitServer(t *testing.T, responses map[string]string, requests map[string][]string) *codecommit.Client {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Assert(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access-key/"), "expected a signed request")
+		action := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "CodeCommit_20150413.")
+		body, err := io.ReadAll(r.Body)
+		Ok(t, err)
+		if requests != nil {
+			requests[action] = append(requests[action], string(body))
+		}
+		resp, ok := responses[action]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"__type": "com.amazonaws.codecommit#InvalidActionException", "message": "unexpected %s"}`, action) // nolint: errcheck
+			return
+		}
+		fmt.Fprint(w, resp) // nolint: errcheck
+	}))
+	t.Cleanup(testServer.Close)
+
+	client, err := codecommit.New(http.DefaultClient, "us-east-1", testServer.URL, credentials.NewStaticCredentialsProvider("access-key", "secret-key", ""))
+	Ok(t, err)
+	return client
+}
+
+func TestClient_GetModifiedFiles(t *testing.T) {
+	requests := map[string][]string{}
+	client := codecommitServer(t, map[string]string{
+		"GetPullRequest": pullRequest,
+		"GetDifferences": `{"differences": [
+			{"afterBlob": {"path": "prod/main.tf"}},
+			{"beforeBlob": {"path": "old/main.tf"}, "afterBlob": {"path": "staging/main.tf"}},
+			{"beforeBlob": {"path": "deleted/main.tf"}}
+		]}`,
+	}, requests)
+
+	files, err := client.GetModifiedFiles(logging.NewNoopLogger(t), repo, models.PullRequest{Num: 7, HeadCommit: "source"})
+	Ok(t, err)
+	Equals(t, []string{"prod/main.tf", "staging/main.tf", "old/main.tf", "deleted/main.tf"}, files)
+	Equals(t, []string{`{"afterCommitSpecifier":"source","beforeCommitSpecifier":"base","repositoryName":"terraform"}`}, requests["GetDifferences"])
+}
+
+func TestClient_CreateComment(t *testing.T) {
+	requests := map[string][]string{}
+	client := codecommitServer(t, map[string]string{
+		"GetPullRequest":            pullRequest,
+		"PostCommentForPullRequest": `{}`,
+	}, requests)
+
+	Ok(t, client.CreateComment(logging.NewNoopLogger(t), repo, 7, "Ran Plan for dir: `prod`", "plan"))
+	Equals(t, []string{`{"afterCommitId":"source","beforeCommitId":"destination","content":"Ran Plan for dir: ` + "`prod`" + `","pullRequestId":"7","repositoryName":"terraform"}`}, requests["PostCommentForPullRequest"])
+}
+
+func TestClient_PullIsApproved(t *testing.T) {
+	requests := map[string][]string{}
+	client := codecommitServer(t, map[string]string{
+		"GetPullRequest":               pullRequest,
+		"GetPullRequestApprovalStates": `{"approvals": [{"userArn": "arn:aws:iam::123456789012:user/bob", "approvalState": "REVOKE"}, {"userArn": "arn:aws:sts::123456789012:assumed-role/Admin/alice", "approvalState": "APPROVE"}]}`,
+	}, requests)
+
+	status, err := client.PullIsApproved(logging.NewNoopLogger(t), repo, models.PullRequest{Num: 7})
+	Ok(t, err)
+	Equals(t, models.ApprovalStatus{IsApproved: true, ApprovedBy: "alice"}, status)
+	Equals(t, []string{`{"pullRequestId":"7","revisionId":"rev1"}`}, requests["GetPullRequestApprovalStates"])
+}
+
+func TestClient_PullIsMergeable(t *testing.T) {
+	cases := []struct {
+		description string
+		pullRequest string
+		evaluation  string
+		conflicts   string
+		exp         models.MergeableStatus
+	}{
+		{
+			"approved pull request without conflicts is mergeable",
+			pullRequest,
+			`{"evaluation": {"approved": true, "approvalRulesSatisfied": ["Require 1 approval"]}}`,
+			`{"mergeable": true}`,
+			models.MergeableStatus{IsMergeable: true},
+		},
+		{
+			"pull request with overridden approval rules is mergeable",
+			pullRequest,
+			`{"evaluation": {"approved": false, "overridden": true, "approvalRulesNotSatisfied": ["Require 1 approval"]}}`,
+			`{"mergeable": true}`,
+			models.MergeableStatus{IsMergeable: true},
+		},
+		{
+			"pull request with unsatisfied approval rules isn't mergeable",
+			pullRequest,
+			`{"evaluation": {"approved": false, "approvalRulesNotSatisfied": ["Require 1 approval", "Require security"]}}`,
+			`{"mergeable": true}`,
+			models.MergeableStatus{IsMergeable: false, Reason: "Approval rules are not satisfied: Require 1 approval, Require security"},
+		},
+		{
+			"pull request with conflicts isn't mergeable",
+			pullRequest,
+			`{"evaluation": {"approved": true}}`,
+			`{"mergeable": false}`,
+			models.MergeableStatus{IsMergeable: false, Reason: "Pull request has merge conflicts"},
+		},
+		{
+			"closed pull request isn't mergeable",
+			strings.Replace(pullRequest, `"OPEN"`, `"CLOSED"`, 1),
+			"",
+			"",
+			models.MergeableStatus{IsMergeable: false, Reason: "Pull request is closed"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			client := codecommitServer(t, map[string]string{
+				"GetPullRequest":                   c.pullRequest,
+				"EvaluatePullRequestApprovalRules": c.evaluation,
+				"GetMergeConflicts":                c.conflicts,
+			}, nil)
+			status, err := client.PullIsMergeable(logging.NewNoopLogger(t), repo, models.PullRequest{Num: 7}, "atlantis", nil)
+			Ok(t, err)
+			Equals(t, c.exp, status)
+		})
+	}
+}
+
+func TestClient_MergePull(t *testing.T) {
+	for method, action := range map[string]string{
+		"":       "MergePullRequestByThreeWay",
+		"squash": "MergePullRequestBySquash",
+		"rebase": "MergePullRequestByFastForward",
+	} {
+		t.Run(action, func(t *testing.T) {
+			requests := map[string][]string{}
+			client := codecommitServer(t, map[string]string{action: `{}`}, requests)
+			err := client.MergePull(logging.NewNoopLogger(t), models.PullRequest{Num: 7, HeadCommit: "source", BaseRepo: repo}, models.PullRequestOptions{MergeMethod: method})
+			Ok(t, err)
+			Equals(t, []string{`{"pullRequestId":"7","repositoryName":"terraform","sourceCommitId":"source"}`}, requests[action])
+		})
+	}
+}
+
+func TestClient_GetFileContent(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		Ok(t, err)
+		if strings.Contains(string(body), "missing.yaml") {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"__type": "com.amazonaws.codecommit#FileDoesNotExistException", "message": "file does not exist"}`) // nolint: errcheck
+			return
+		}
+		// The content is base64 encoded, "version: 3\n".
+		fmt.Fprint(w, `{"fileContent": "dmVyc2lvbjogMwo="}`) // nolint: errcheck
+	}))
+	defer testServer.Close()
+	client, err := codecommit.New(http.DefaultClient, "us-east-1", testServer.URL, credentials.NewStaticCredentialsProvider("access-key", "secret-key", ""))
+	Ok(t, err)
+	logger := logging.NewNoopLogger(t)
+
+	found, content, err := client.GetFileContent(logger, repo, "main", "atlantis.yaml")
+	Ok(t, err)
+	Equals(t, true, found)
+	Equals(t, "version: 3\n", string(content))
+
+	found, _, err = client.GetFileContent(logger, repo, "main", "missing.yaml")
+	Ok(t, err)
+	Equals(t, false, found)
+}
+
+func TestClient_Errors(t *testing.T) {
+	client := codecommitServer(t, map[string]string{}, nil)
+	_, err := client.GetPullRequest(logging.NewNoopLogger(t), 7)
+	ErrEquals(t, "calling GetPullRequest: InvalidActionException: unexpected GetPullRequest", err)
+
+	client, err = codecommit.New(http.DefaultClient, "us-east-1", "", aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		return aws.Credentials{}, errors.New("no credentials")
+	}))
+	Ok(t, err)
+	_, err = client.GetPullRequest(logging.NewNoopLogger(t), 7)
+	ErrEquals(t, "retrieving AWS credentials: no credentials", err)
+
+	// Pull requests without targets are rejected rather than indexed.
+	client = codecommitServer(t, map[string]string{"GetPullRequest": `{"pullRequest": {"pullRequestId": "7", "pullRequestTargets": []}}`}, nil)
+	_, err = client.GetPullRequest(logging.NewNoopLogger(t), 7)
+	ErrEquals(t, "pull request 7 has no targets", err)
+	_, err = client.GetModifiedFiles(logging.NewNoopLogger(t), repo, models.PullRequest{Num: 7})
+	ErrEquals(t, "pull request 7 has no targets", err)
+	_, err = (&codecommit.PullRequest{PullRequestID: "7"}).Target()
+	ErrEquals(t, "pull request 7 has no targets", err)
+}
+
+func TestClient_ConfirmSubscription(t *testing.T) {
+	client, err := codecommit.New(http.DefaultClient, "us-east-1", "", nil)
+	Ok(t, err)
+	for _, subscribeURL := range []string{
+		"http://sns.us-east-1.amazonaws.com/?Action=ConfirmSubscription",
+		"https://sns.eu-west-1.amazonaws.com/?Action=ConfirmSubscription",
+		"https://attacker.example.com/?Action=ConfirmSubscription",
+	} {
+		err := client.ConfirmSubscription(logging.NewNoopLogger(t), codecommit.SNSMessage{SubscribeURL: subscribeURL})
+		ErrEquals(t, fmt.Sprintf("SubscribeURL %q isn't an SNS endpoint of us-east-1", subscribeURL), err)
+	}
+}
+
+func TestUsernameFromARN(t *testing.T) {
+	for arn, exp := range map[string]string{
+		"arn:aws:iam::123456789012:user/alice":                 "alice",
+		"arn:aws:iam::123456789012:user/division/alice":        "alice",
+		"arn:aws:sts::123456789012:assumed-role/Admin/alice":   "alice",
+		"arn:aws:iam::123456789012:root":                       "root",
+		"arn:aws:sts::123456789012:federated-user/bob@corp.io": "bob@corp.io",
+	} {
+		Equals(t, exp, codecommit.UsernameFromARN(arn))
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package codecommit
+
+import "fmt"
+
+// The detail types of the CodeCommit events sent by EventBridge. See
+// https://docs.aws.amazon.com/codecommit/latest/userguide/monitoring-events.html.
+const (
+	PullRequestStateChangeDetailType = "CodeCommit Pull Request State Change"
+	CommentOnPullRequestDetailType   = "CodeCommit Comment on Pull Request"
+)
+
+// The events of the details of the CodeCommit events.
+const (
+	PullRequestCreatedEvent             = "pullRequestCreated"
+	PullRequestSourceBranchUpdatedEvent = "pullRequestSourceBranchUpdated"
+	PullRequestStatusChangedEvent       = "pullRequestStatusChanged"
+	PullRequestMergeStatusUpdatedEvent  = "pullRequestMergeStatusUpdated"
+	CommentOnPullRequestCreatedEvent    = "commentOnPullRequestCreated"
+)
+
+// The statuses of pull requests. Events spell them Open and Closed while the
+// API spells them OPEN and CLOSED.
+const (
+	PullRequestStatusOpen   = "OPEN"
+	PullRequestStatusClosed = "CLOSED"
+)
+
+// The types of the messages SNS sends to HTTP subscriptions, in the
+// X-Amz-Sns-Message-Type header.
+const (
+	SNSSubscriptionConfirmation = "SubscriptionConfirmation"
+	SNSNotification             = "Notification"
+)
+
+// Event is a CodeCommit event sent by EventBridge, either directly through an
+// API destination or as the message of an SNS notification.
+type Event struct {
+	DetailType string      `json:"detail-type" validate:"required"`
+	Source     string      `json:"source"`
+	Account    string      `json:"account" validate:"required"`
+	Region     string      `json:"region" validate:"required"`
+	Resources  []string    `json:"resources"`
+	Detail     EventDetail `json:"detail"`
+}
+
+// EventDetail is the detail of a CodeCommit event. The pull request state
+// change and comment events share most of their fields.
+type EventDetail struct {
+	Event         string `json:"event" validate:"required"`
+	PullRequestID string `json:"pullRequestId" validate:"required"`
+	// CallerUserARN is the ARN of the user or role that caused the event.
+	CallerUserARN string `json:"callerUserArn"`
+
+	// The fields of pull request state change events.
+	Author               string   `json:"author"`
+	Title                string   `json:"title"`
+	Description          string   `json:"description"`
+	PullRequestStatus    string   `json:"pullRequestStatus"`
+	IsMerged             string   `json:"isMerged"`
+	RepositoryNames      []string `json:"repositoryNames"`
+	SourceReference      string   `json:"sourceReference"`
+	SourceCommit         string   `json:"sourceCommit"`
+	DestinationReference string   `json:"destinationReference"`
+	DestinationCommit    string   `json:"destinationCommit"`
+	RevisionID           string   `json:"revisionId"`
+
+	// The fields of comment events. The content of the comment isn't part
+	// of the event, it must be fetched with the comment ID.
+	CommentID      string `json:"commentId"`
+	RepositoryName string `json:"repositoryName"`
+	AfterCommitID  string `json:"afterCommitId"`
+	BeforeCommitID string `json:"beforeCommitId"`
+}
+
+// SNSMessage is a message SNS sends to HTTP subscriptions.
+type SNSMessage struct {
+	Type     string `json:"Type"`
+	TopicArn string `json:"TopicArn"`
+	// Message is the JSON encoded Event of notifications.
+	Message string `json:"Message"`
+	// SubscribeURL must be visited to confirm subscriptions.
+	SubscribeURL string `json:"SubscribeURL"`
+}
+
+// PullRequest is a pull request returned by the API.
+type PullRequest struct {
+	PullRequestID      string              `json:"pullRequestId"`
+	Title              string              `json:"title"`
+	Description        string              `json:"description"`
+	PullRequestStatus  string              `json:"pullRequestStatus"`
+	AuthorARN          string              `json:"authorArn"`
+	RevisionID         string              `json:"revisionId"`
+	PullRequestTargets []PullRequestTarget `json:"pullRequestTargets"`
+}
+
+// Target returns the target of the pull request. Pull requests have one
+// target per repo and Atlantis only handles pull requests of one repo.
+func (p *PullRequest) Target() (PullRequestTarget, error) {
+	if len(p.PullRequestTargets) == 0 {
+		return PullRequestTarget{}, fmt.Errorf("pull request %s has no targets", p.PullRequestID)
+	}
+	return p.PullRequestTargets[0], nil
+}
+
+// PullRequestTarget is the source and destination branches of a pull request.
+type PullRequestTarget struct {
+	RepositoryName       string        `json:"repositoryName"`
+	SourceReference      string        `json:"sourceReference"`
+	SourceCommit         string        `json:"sourceCommit"`
+	DestinationReference string        `json:"destinationReference"`
+	DestinationCommit    string        `json:"destinationCommit"`
+	MergeBase            string        `json:"mergeBase"`
+	MergeMetadata        MergeMetadata `json:"mergeMetadata"`
+}
+
+// MergeMetadata is whether a pull request is merged.
+type MergeMetadata struct {
+	IsMerged bool `json:"isMerged"`
+}
+
+// Comment is a comment on a pull request.
+type Comment struct {
+	CommentID string `json:"commentId"`
+	Content   string `json:"content"`
+	AuthorARN string `json:"authorArn"`
+	Deleted   bool   `json:"deleted"`
+}
+
+// Approval is the approval state of a user on a revision of a pull request.
+type Approval struct {
+	UserARN       string `json:"userArn"`
+	ApprovalState string `json:"approvalState"`
+}
+
+// Evaluation is the evaluation of the approval rules of a revision of a pull
+// request.
+type Evaluation struct {
+	Approved                  bool     `json:"approved"`
+	Overridden                bool     `json:"overridden"`
+	ApprovalRulesSatisfied    []string `json:"approvalRulesSatisfied"`
+	ApprovalRulesNotSatisfied []string `json:"approvalRulesNotSatisfied"`
+}
+
+// Difference is a file changed between two commits.
+type Difference struct {
+	BeforeBlob *BlobMetadata `json:"beforeBlob"`
+	AfterBlob  *BlobMetadata `json:"afterBlob"`
+}
+
+// BlobMetadata is the path of a file of a Difference.
+type BlobMetadata struct {
+	Path string `json:"path"`
+}
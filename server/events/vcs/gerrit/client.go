@@ -0,0 +1,375 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gerrit
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs/common"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// maxCommentLength is the default maximum number of chars Gerrit allows in a
+// single change message, change.commentSizeLimit.
+const maxCommentLength = 16384
+
+// codeReviewLabel is the label of the reviews that approve changes.
+const codeReviewLabel = "Code-Review"
+
+// jsonPrefix is the prefix of the JSON responses of the REST API, which
+// protects against cross site script inclusion.
+const jsonPrefix = ")]}'"
+
+// changeRefRegex matches the refs of patch sets, ex. refs/changes/45/12345/3.
+var changeRefRegex = regexp.MustCompile(`^refs/changes/\d+/(\d+)/(\d+)$`)
+
+var errNotFound = errors.New("not found")
+
+type Client struct {
+	httpClient *http.Client
+	BaseURL    string
+	user       string
+	password   string
+	// verifiedLabel is the label Atlantis votes on with the result of its
+	// commands so it gates submitting changes.
+	verifiedLabel string
+}
+
+// New builds a client that makes API calls to Gerrit. httpClient is the
+// client to use to make the requests, baseURL is the URL of Gerrit, ex.
+// https://gerrit.corp.com. user and password are the username and HTTP
+// password of the account of Atlantis. verifiedLabel is the label Atlantis
+// votes on with the result of its commands.
+func New(httpClient *http.Client, baseURL string, user string, password string, verifiedLabel string) (*Client, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", baseURL, err)
+	}
+	if parsedURL.Scheme == "" {
+		return nil, fmt.Errorf("must have 'http://' or 'https://' in base url %q", baseURL)
+	}
+	return &Client{
+		httpClient:    httpClient,
+		BaseURL:       strings.TrimRight(parsedURL.String(), "/"),
+		user:          user,
+		password:      password,
+		verifiedLabel: verifiedLabel,
+	}, nil
+}
+
+// GetChange returns the change pullNum of repo with its current patch set
+// and the votes on its labels.
+func (c *Client) GetChange(logger logging.SimpleLogging, repo models.Repo, pullNum int) (*ChangeInfo, error) {
+	logger.Debug("Getting Gerrit change %d", pullNum)
+	path := fmt.Sprintf("/changes/%s?o=CURRENT_REVISION&o=CURRENT_COMMIT&o=DETAILED_LABELS&o=DETAILED_ACCOUNTS", changeID(repo, pullNum))
+	var change ChangeInfo
+	if err := c.makeJSONRequest("GET", path, nil, &change); err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// GetModifiedFiles returns the names of files that were modified in the merge request
+// relative to the repo root, e.g. parent/child/file.txt.
+func (c *Client) GetModifiedFiles(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest) ([]string, error) {
+	logger.Debug("Getting modified files for Gerrit change %d", pull.Num)
+	path := fmt.Sprintf("/changes/%s/revisions/%s/files", changeID(repo, pull.Num), pull.HeadCommit)
+	var files map[string]FileInfo
+	if err := c.makeJSONRequest("GET", path, nil, &files); err != nil {
+		return nil, err
+	}
+
+	var modifiedFiles []string
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		file := files[name]
+		// Magic files like /COMMIT_MSG aren't in the repo.
+		if strings.HasPrefix(name, "/") {
+			continue
+		}
+		modifiedFiles = append(modifiedFiles, name)
+		// Include the old path of renamed files, since a rename of a file
+		// modifies its old directory too.
+		if file.OldPath != "" {
+			modifiedFiles = append(modifiedFiles, file.OldPath)
+		}
+	}
+	return modifiedFiles, nil
+}
+
+// CreateComment reviews the current patch set of the change with comment as
+// its message. It will write multiple messages if a single one is too long.
+func (c *Client) CreateComment(logger logging.SimpleLogging, repo models.Repo, pullNum int, comment string, _ string) error {
+	logger.Debug("Creating comment on Gerrit change %d", pullNum)
+	sepEnd := "\n```\n**Warning**: Output length greater than max comment size. Continued in next comment."
+	sepStart := "Continued from previous comment.\n```diff\n"
+	comments := common.SplitComment(comment, maxCommentLength, sepEnd, sepStart, 0, "")
+	for _, message := range comments {
+		if err := c.review(repo, pullNum, "current", ReviewInput{Message: message}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReactToComment does nothing, Gerrit has no reactions.
+func (c *Client) ReactToComment(_ logging.SimpleLogging, _ models.Repo, _ int, _ int64, _ string) error {
+	return nil
+}
+
+// HidePrevCommandComments does nothing, Gerrit can't hide change messages.
+// The messages of Atlantis are tagged as autogenerated so Gerrit lets users
+// filter them out.
+func (c *Client) HidePrevCommandComments(_ logging.SimpleLogging, _ models.Repo, _ int, _ string, _ string) error {
+	return nil
+}
+
+// PullIsApproved returns true if the change has the maximum vote on its
+// Code-Review label.
+func (c *Client) PullIsApproved(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest) (models.ApprovalStatus, error) {
+	change, err := c.GetChange(logger, repo, pull.Num)
+	if err != nil {
+		return models.ApprovalStatus{}, err
+	}
+	label, ok := change.Labels[codeReviewLabel]
+	if !ok || label.Approved == nil || label.Rejected != nil {
+		return models.ApprovalStatus{IsApproved: false}, nil
+	}
+	status := models.ApprovalStatus{IsApproved: true, ApprovedBy: label.Approved.Username}
+	for _, approval := range label.All {
+		if approval.AccountID == label.Approved.AccountID {
+			// Gerrit timestamps are in UTC.
+			status.Date, _ = time.Parse("2006-01-02 15:04:05.000000000", approval.Date)
+		}
+	}
+	return status, nil
+}
+
+// DiscardReviews does nothing, votes are kept by Gerrit or copied to new
+// patch sets depending on the configuration of their labels.
+func (c *Client) DiscardReviews(_ logging.SimpleLogging, _ models.Repo, _ models.PullRequest) error {
+	return nil
+}
+
+// PullIsMergeable returns true if the change is open and its labels are
+// satisfied. The verified label is ignored since Atlantis votes on it once
+// the change is applied.
+func (c *Client) PullIsMergeable(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest, _ string, _ []string) (models.MergeableStatus, error) {
+	change, err := c.GetChange(logger, repo, pull.Num)
+	if err != nil {
+		return models.MergeableStatus{}, err
+	}
+	if change.Status != changeStatusNew {
+		return models.MergeableStatus{IsMergeable: false, Reason: fmt.Sprintf("Change is %s", strings.ToLower(change.Status))}, nil
+	}
+	for _, name := range slices.Sorted(maps.Keys(change.Labels)) {
+		label := change.Labels[name]
+		if name == c.verifiedLabel || label.Optional {
+			continue
+		}
+		if label.Blocking || label.Rejected != nil {
+			return models.MergeableStatus{IsMergeable: false, Reason: fmt.Sprintf("%s is rejected", name)}, nil
+		}
+		if label.Approved == nil {
+			return models.MergeableStatus{IsMergeable: false, Reason: fmt.Sprintf("%s is not approved", name)}, nil
+		}
+	}
+	return models.MergeableStatus{IsMergeable: true}, nil
+}
+
+// UpdateStatus votes on the verified label of the patch set of pull with the
+// combined status of a command. Gerrit has no commit statuses, so the
+// statuses of single projects are ignored. Failures are -1 and a successful
+// apply is +1 so the change can be submitted. Other statuses reset the vote
+// since they mean the patch set must be planned or applied again.
+func (c *Client) UpdateStatus(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string, _ string) error {
+	// Project statuses are named "atlantis/plan: dir/workspace".
+	if strings.Contains(src, ": ") {
+		return nil
+	}
+	vote := 0
+	switch {
+	case state == models.FailedCommitStatus:
+		vote = -1
+	case state == models.SuccessCommitStatus && strings.HasSuffix(src, "/"+command.Apply.String()):
+		vote = 1
+	}
+
+	logger.Info("Voting %s%+d on Gerrit change %d for '%s'", c.verifiedLabel, vote, pull.Num, src)
+	review := ReviewInput{
+		Labels: map[string]int{c.verifiedLabel: vote},
+		Tag:    autogeneratedReviewTag,
+	}
+	if vote != 0 {
+		review.Message = fmt.Sprintf("%s: %s", src, description)
+	}
+	return c.review(repo, pull.Num, pull.HeadCommit, review)
+}
+
+// MergePull submits the change.
+func (c *Client) MergePull(logger logging.SimpleLogging, pull models.PullRequest, _ models.PullRequestOptions) error {
+	logger.Debug("Submitting Gerrit change %d", pull.Num)
+	_, err := c.makeRequest("POST", fmt.Sprintf("/changes/%s/submit", changeID(pull.BaseRepo, pull.Num)), struct{}{})
+	return err
+}
+
+// MarkdownPullLink links to the change, Gerrit has no markdown shorthand for
+// changes.
+func (c *Client) MarkdownPullLink(pull models.PullRequest) (string, error) {
+	if pull.URL == "" {
+		return fmt.Sprintf("change %d", pull.Num), nil
+	}
+	return fmt.Sprintf("[change %d](%s)", pull.Num, pull.URL), nil
+}
+
+// GetTeamNamesForUser returns the names of the groups of user.
+func (c *Client) GetTeamNamesForUser(logger logging.SimpleLogging, _ models.Repo, user models.User) ([]string, error) {
+	logger.Debug("Getting Gerrit groups of %s", user.Username)
+	var groups []GroupInfo
+	if err := c.makeJSONRequest("GET", fmt.Sprintf("/accounts/%s/groups", url.PathEscape(user.Username)), nil, &groups); err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, group := range groups {
+		names = append(names, group.Name)
+	}
+	return names, nil
+}
+
+func (c *Client) SupportsSingleFileDownload(_ models.Repo) bool {
+	return true
+}
+
+// GetFileContent a repository file content from VCS (which support fetch a single file from repository)
+// The first return value indicates whether the repo contains a file or not
+// if BaseRepo had a file, its content will placed on the second return value
+// branch is either a branch or the ref of a patch set.
+func (c *Client) GetFileContent(logger logging.SimpleLogging, repo models.Repo, branch string, fileName string) (bool, []byte, error) {
+	logger.Debug("Getting file content for %s in Gerrit project %s", fileName, repo.FullName)
+	path := fmt.Sprintf("/projects/%s/branches/%s/files/%s/content", escape(repo.FullName), escape(branch), escape(fileName))
+	if match := changeRefRegex.FindStringSubmatch(branch); match != nil {
+		pullNum, _ := strconv.Atoi(match[1])
+		path = fmt.Sprintf("/changes/%s/revisions/%s/files/%s/content", changeID(repo, pullNum), match[2], escape(fileName))
+	}
+
+	body, err := c.makeRequest("GET", path, nil)
+	if errors.Is(err, errNotFound) {
+		return false, []byte{}, nil
+	}
+	if err != nil {
+		return true, []byte{}, err
+	}
+	content, err := base64.StdEncoding.DecodeString(string(body))
+	if err != nil {
+		return true, []byte{}, fmt.Errorf("decoding content of %s: %w", fileName, err)
+	}
+	return true, content, nil
+}
+
+// GetCloneURL returns the authenticated clone URL of the project repo,
+// without credentials.
+func (c *Client) GetCloneURL(_ logging.SimpleLogging, _ models.VCSHostType, repo string) (string, error) {
+	return fmt.Sprintf("%s/a/%s", c.BaseURL, repo), nil
+}
+
+// GetPullLabels returns the hashtags of the change, Gerrit's equivalent of
+// labels.
+func (c *Client) GetPullLabels(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest) ([]string, error) {
+	change, err := c.GetChange(logger, repo, pull.Num)
+	if err != nil {
+		return nil, err
+	}
+	return change.Hashtags, nil
+}
+
+// review reviews revision of the change pullNum.
+func (c *Client) review(repo models.Repo, pullNum int, revision string, review ReviewInput) error {
+	if review.Tag == "" {
+		review.Tag = autogeneratedReviewTag
+	}
+	_, err := c.makeRequest("POST", fmt.Sprintf("/changes/%s/revisions/%s/review", changeID(repo, pullNum), revision), review)
+	return err
+}
+
+// makeJSONRequest makes a request to the REST API and decodes its JSON
+// response into resp.
+func (c *Client) makeJSONRequest(method string, path string, reqBody any, resp any) error {
+	body, err := c.makeRequest(method, path, reqBody)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, resp); err != nil {
+		return fmt.Errorf("parsing response from request %q: %w", fmt.Sprintf("%s %s", method, path), err)
+	}
+	return nil
+}
+
+// makeRequest makes an authenticated request to the REST API, with reqBody
+// encoded as JSON if it isn't nil. It returns the body of the response
+// without the prefix of JSON responses, or errNotFound if the response is a
+// 404.
+func (c *Client) makeRequest(method string, path string, reqBody any) ([]byte, error) {
+	var body io.Reader
+	if reqBody != nil {
+		bodyBytes, err := json.Marshal(reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("json encoding: %w", err)
+		}
+		body = bytes.NewBuffer(bodyBytes)
+	}
+	// The /a/ prefix makes Gerrit authenticate the request.
+	req, err := http.NewRequest(method, c.BaseURL+"/a"+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("constructing request: %w", err)
+	}
+	req.SetBasicAuth(c.user, c.password)
+	if body != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	requestStr := fmt.Sprintf("%s %s", method, path)
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response from request %q: %w", requestStr, err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("making request %q: %w", requestStr, errNotFound)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
+		return nil, fmt.Errorf("making request %q unexpected status code: %d, body: %s", requestStr, resp.StatusCode, string(respBody))
+	}
+	return bytes.TrimPrefix(respBody, []byte(jsonPrefix)), nil
+}
+
+// changeID returns the ID of the change pullNum of repo in the REST API.
+func changeID(repo models.Repo, pullNum int) string {
+	return fmt.Sprintf("%s~%d", escape(repo.FullName), pullNum)
+}
+
+// escape escapes s as a segment of the path of a URL, including its slashes.
+func escape(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), "/", "%2F")
+}
@@ -0,0 +1,235 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gerrit_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs/gerrit"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+var repo = models.Repo{FullName: "infra/terraform"}
+
+// gerritServer returns a Gerrit test server that responds to the escaped
+// paths of responses and records the bodies of the requests it receives.
+func gerritServer(t *testing.T, responses map[string]string, requests map[string]string) *gerrit.Client {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		Equals(t, "atlantis", user)
+		Equals(t, "password", password)
+		path := r.URL.EscapedPath()
+		if r.URL.RawQuery != "" {
+			path += "?" + r.URL.RawQuery
+		}
+		if requests != nil {
+			body, err := io.ReadAll(r.Body)
+			Ok(t, err)
+			requests[r.Method+" "+path] = string(body)
+		}
+		resp, ok := responses[r.Method+" "+path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, resp) // nolint: errcheck
+	}))
+	t.Cleanup(testServer.Close)
+
+	client, err := gerrit.New(http.DefaultClient, testServer.URL, "atlantis", "password", "Verified")
+	Ok(t, err)
+	return client
+}
+
+func TestClient_GetModifiedFiles(t *testing.T) {
+	client := gerritServer(t, map[string]string{
+		"GET /a/changes/infra%2Fterraform~12345/revisions/abc123/files": `)]}'
+{"/COMMIT_MSG":{"status":"A"},"prod/main.tf":{},"staging/main.tf":{"status":"R","old_path":"old/main.tf"}}`,
+	}, nil)
+
+	files, err := client.GetModifiedFiles(logging.NewNoopLogger(t), repo, models.PullRequest{Num: 12345, HeadCommit: "abc123"})
+	Ok(t, err)
+	Equals(t, []string{"prod/main.tf", "staging/main.tf", "old/main.tf"}, files)
+}
+
+func TestClient_CreateComment(t *testing.T) {
+	requests := map[string]string{}
+	client := gerritServer(t, map[string]string{
+		"POST /a/changes/infra%2Fterraform~12345/revisions/current/review": `)]}'
+{}`,
+	}, requests)
+
+	Ok(t, client.CreateComment(logging.NewNoopLogger(t), repo, 12345, "Ran Plan for dir: `prod`", "plan"))
+	Equals(t, `{"message":"Ran Plan for dir: `+"`prod`"+`","tag":"autogenerated:atlantis"}`, requests["POST /a/changes/infra%2Fterraform~12345/revisions/current/review"])
+}
+
+func TestClient_UpdateStatus(t *testing.T) {
+	reviewPath := "POST /a/changes/infra%2Fterraform~12345/revisions/abc123/review"
+	pull := models.PullRequest{Num: 12345, HeadCommit: "abc123"}
+
+	cases := []struct {
+		description string
+		state       models.CommitStatus
+		src         string
+		status      string
+		expReview   string
+	}{
+		{
+			"a successful apply verifies the change",
+			models.SuccessCommitStatus,
+			"atlantis/apply",
+			"1/1 projects applied successfully.",
+			`{"message":"atlantis/apply: 1/1 projects applied successfully.","labels":{"Verified":1},"tag":"autogenerated:atlantis"}`,
+		},
+		{
+			"a failed plan rejects the change",
+			models.FailedCommitStatus,
+			"atlantis/plan",
+			"1/2 projects planned successfully.",
+			`{"message":"atlantis/plan: 1/2 projects planned successfully.","labels":{"Verified":-1},"tag":"autogenerated:atlantis"}`,
+		},
+		{
+			"a new plan resets the vote",
+			models.PendingCommitStatus,
+			"atlantis/plan",
+			"in progress...",
+			`{"labels":{"Verified":0},"tag":"autogenerated:atlantis"}`,
+		},
+		{
+			"the statuses of projects are ignored",
+			models.SuccessCommitStatus,
+			"atlantis/apply: prod/default",
+			"apply succeeded.",
+			"",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			requests := map[string]string{}
+			client := gerritServer(t, map[string]string{reviewPath: ")]}'\n{}"}, requests)
+			Ok(t, client.UpdateStatus(logging.NewNoopLogger(t), repo, pull, c.state, c.src, c.status, ""))
+			Equals(t, c.expReview, requests[reviewPath])
+		})
+	}
+}
+
+func TestClient_PullIsMergeable(t *testing.T) {
+	changePath := "GET /a/changes/infra%2Fterraform~12345?o=CURRENT_REVISION&o=CURRENT_COMMIT&o=DETAILED_LABELS&o=DETAILED_ACCOUNTS"
+	cases := []struct {
+		description string
+		change      gerrit.ChangeInfo
+		exp         models.MergeableStatus
+	}{
+		{
+			"approved change without a verified vote is mergeable",
+			gerrit.ChangeInfo{
+				Status: "NEW",
+				Labels: map[string]gerrit.LabelInfo{
+					"Code-Review": {Approved: &gerrit.AccountInfo{Username: "reviewer"}},
+					"Verified":    {},
+					"Lint":        {Optional: true},
+				},
+			},
+			models.MergeableStatus{IsMergeable: true},
+		},
+		{
+			"unapproved change isn't mergeable",
+			gerrit.ChangeInfo{
+				Status: "NEW",
+				Labels: map[string]gerrit.LabelInfo{"Code-Review": {}},
+			},
+			models.MergeableStatus{IsMergeable: false, Reason: "Code-Review is not approved"},
+		},
+		{
+			"rejected change isn't mergeable",
+			gerrit.ChangeInfo{
+				Status: "NEW",
+				Labels: map[string]gerrit.LabelInfo{
+					"Code-Review": {
+						Approved: &gerrit.AccountInfo{Username: "reviewer"},
+						Rejected: &gerrit.AccountInfo{Username: "other"},
+						Blocking: true,
+					},
+				},
+			},
+			models.MergeableStatus{IsMergeable: false, Reason: "Code-Review is rejected"},
+		},
+		{
+			"merged change isn't mergeable",
+			gerrit.ChangeInfo{Status: "MERGED"},
+			models.MergeableStatus{IsMergeable: false, Reason: "Change is merged"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			change, err := json.Marshal(c.change)
+			Ok(t, err)
+			client := gerritServer(t, map[string]string{changePath: ")]}'\n" + string(change)}, nil)
+			status, err := client.PullIsMergeable(logging.NewNoopLogger(t), repo, models.PullRequest{Num: 12345}, "atlantis", nil)
+			Ok(t, err)
+			Equals(t, c.exp, status)
+		})
+	}
+}
+
+func TestClient_PullIsApproved(t *testing.T) {
+	client := gerritServer(t, map[string]string{
+		"GET /a/changes/infra%2Fterraform~12345?o=CURRENT_REVISION&o=CURRENT_COMMIT&o=DETAILED_LABELS&o=DETAILED_ACCOUNTS": `)]}'
+{"labels":{"Code-Review":{"approved":{"_account_id":2,"username":"reviewer"},"all":[{"_account_id":1,"value":0},{"_account_id":2,"value":2,"date":"2025-03-04 05:06:07.000000000"}]}}}`,
+	}, nil)
+
+	status, err := client.PullIsApproved(logging.NewNoopLogger(t), repo, models.PullRequest{Num: 12345})
+	Ok(t, err)
+	Equals(t, true, status.IsApproved)
+	Equals(t, "reviewer", status.ApprovedBy)
+	Equals(t, "2025-03-04 05:06:07", status.Date.Format("2006-01-02 15:04:05"))
+}
+
+func TestClient_GetFileContent(t *testing.T) {
+	content := base64.StdEncoding.EncodeToString([]byte("version: 3\n"))
+	client := gerritServer(t, map[string]string{
+		"GET /a/projects/infra%2Fterraform/branches/main/files/atlantis.yaml/content":           content,
+		"GET /a/changes/infra%2Fterraform~12345/revisions/3/files/atlantis.yaml/content":        content,
+		"GET /a/changes/infra%2Fterraform~12345/revisions/3/files/prod%2Fatlantis.yaml/content": content,
+	}, nil)
+	logger := logging.NewNoopLogger(t)
+
+	for _, branch := range []string{"main", "refs/changes/45/12345/3"} {
+		found, got, err := client.GetFileContent(logger, repo, branch, "atlantis.yaml")
+		Ok(t, err)
+		Equals(t, true, found)
+		Equals(t, "version: 3\n", string(got))
+	}
+
+	found, _, err := client.GetFileContent(logger, repo, "refs/changes/45/12345/3", "prod/atlantis.yaml")
+	Ok(t, err)
+	Equals(t, true, found)
+
+	found, _, err = client.GetFileContent(logger, repo, "main", "missing.yaml")
+	Ok(t, err)
+	Equals(t, false, found)
+}
+
+func TestClient_Errors(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprint(w, "change is closed") // nolint: errcheck
+	}))
+	defer testServer.Close()
+
+	client, err := gerrit.New(http.DefaultClient, testServer.URL, "atlantis", "password", "Verified")
+	Ok(t, err)
+	err = client.MergePull(logging.NewNoopLogger(t), models.PullRequest{Num: 12345, BaseRepo: repo}, models.PullRequestOptions{})
+	ErrEquals(t, `making request "POST /changes/infra%2Fterraform~12345/submit" unexpected status code: 409, body: change is closed`, err)
+	Assert(t, !strings.Contains(err.Error(), "password"), "error shouldn't contain the password")
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package gerrit
+
+// The types of the events sent by Gerrit's webhooks plugin, the same as the
+// stream events. See
+// https://gerrit-review.googlesource.com/Documentation/cmd-stream-events.html.
+const (
+	PatchsetCreatedEvent   = "patchset-created"
+	CommentAddedEvent      = "comment-added"
+	ChangeMergedEvent      = "change-merged"
+	ChangeAbandonedEvent   = "change-abandoned"
+	ChangeRestoredEvent    = "change-restored"
+	WorkInProgressChanged  = "wip-state-changed"
+	changeStatusNew        = "NEW"
+	autogeneratedReviewTag = "autogenerated:atlantis"
+)
+
+// Event is an event of a change sent by the webhooks plugin.
+type Event struct {
+	Type     string        `json:"type" validate:"required"`
+	Change   EventChange   `json:"change"`
+	PatchSet EventPatchSet `json:"patchSet"`
+	// Author is the author of the comment of comment-added events.
+	Author *EventAccount `json:"author,omitempty"`
+	// Comment is the comment of comment-added events, prefixed by the patch
+	// set it was made on and the votes that came with it, ex.
+	// "Patch Set 2: Code-Review+1\n\natlantis plan".
+	Comment  string        `json:"comment,omitempty"`
+	Uploader *EventAccount `json:"uploader,omitempty"`
+	// Changer is the account changing the work in progress state of
+	// wip-state-changed events.
+	Changer        *EventAccount `json:"changer,omitempty"`
+	Submitter      *EventAccount `json:"submitter,omitempty"`
+	Abandoner      *EventAccount `json:"abandoner,omitempty"`
+	Restorer       *EventAccount `json:"restorer,omitempty"`
+	EventCreatedOn int64         `json:"eventCreatedOn,omitempty"`
+}
+
+// EventChange is the change of an event.
+type EventChange struct {
+	Project       string       `json:"project" validate:"required"`
+	Branch        string       `json:"branch" validate:"required"`
+	ID            string       `json:"id"`
+	Number        int          `json:"number" validate:"required"`
+	Subject       string       `json:"subject"`
+	Owner         EventAccount `json:"owner"`
+	URL           string       `json:"url"`
+	CommitMessage string       `json:"commitMessage"`
+	Status        string       `json:"status"`
+	WIP           bool         `json:"wip"`
+	Private       bool         `json:"private"`
+}
+
+// EventPatchSet is the patch set of an event.
+type EventPatchSet struct {
+	Number   int          `json:"number" validate:"required"`
+	Revision string       `json:"revision" validate:"required"`
+	Ref      string       `json:"ref" validate:"required"`
+	Uploader EventAccount `json:"uploader"`
+	// Kind is how the patch set differs from the previous one, ex. REWORK or
+	// NO_CODE_CHANGE.
+	Kind string `json:"kind"`
+}
+
+// EventAccount is an account of an event.
+type EventAccount struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Username string `json:"username"`
+}
+
+// ChangeInfo is a change returned by the REST API.
+type ChangeInfo struct {
+	ID              string                  `json:"id"`
+	Project         string                  `json:"project"`
+	Branch          string                  `json:"branch"`
+	Hashtags        []string                `json:"hashtags"`
+	ChangeID        string                  `json:"change_id"`
+	Subject         string                  `json:"subject"`
+	Status          string                  `json:"status"`
+	Number          int                     `json:"_number"`
+	Owner           AccountInfo             `json:"owner"`
+	WorkInProgress  bool                    `json:"work_in_progress"`
+	CurrentRevision string                  `json:"current_revision"`
+	Revisions       map[string]RevisionInfo `json:"revisions"`
+	Labels          map[string]LabelInfo    `json:"labels"`
+}
+
+// RevisionInfo is a patch set of a change returned by the REST API.
+type RevisionInfo struct {
+	Number int         `json:"_number"`
+	Ref    string      `json:"ref"`
+	Commit *CommitInfo `json:"commit"`
+}
+
+// CommitInfo is the commit of a patch set.
+type CommitInfo struct {
+	Message string `json:"message"`
+}
+
+// AccountInfo is an account returned by the REST API.
+type AccountInfo struct {
+	AccountID int    `json:"_account_id"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	Username  string `json:"username"`
+}
+
+// LabelInfo is the votes on a label of a change.
+type LabelInfo struct {
+	// Optional is true if the label doesn't block submitting the change.
+	Optional bool `json:"optional"`
+	// Approved and Rejected are the accounts who voted the maximum and
+	// minimum value of the label, if any.
+	Approved *AccountInfo `json:"approved"`
+	Rejected *AccountInfo `json:"rejected"`
+	Blocking bool         `json:"blocking"`
+	// All is every vote on the label.
+	All []ApprovalInfo `json:"all"`
+}
+
+// ApprovalInfo is a vote on a label.
+type ApprovalInfo struct {
+	AccountInfo
+	Value int    `json:"value"`
+	Date  string `json:"date"`
+}
+
+// ReviewInput reviews a patch set with a message and votes.
+type ReviewInput struct {
+	Message string         `json:"message,omitempty"`
+	Labels  map[string]int `json:"labels,omitempty"`
+	// Tag marks the reviews made by Atlantis so they can be filtered out in
+	// the UI.
+	Tag string `json:"tag,omitempty"`
+}
+
+// FileInfo is a file modified by a patch set.
+type FileInfo struct {
+	Status  string `json:"status"`
+	OldPath string `json:"old_path"`
+}
+
+// GroupInfo is a group of accounts.
+type GroupInfo struct {
+	Name string `json:"name"`
+}
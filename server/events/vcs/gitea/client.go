@@ -233,6 +233,40 @@ func (c *Client) HidePrevCommandComments(logger logging.SimpleLogging, repo mode
 	return nil
 }
 
+// GetUnverifiedCommits returns the SHAs of the commits of pull whose
+// signatures Gitea didn't verify.
+func (c *Client) GetUnverifiedCommits(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest) ([]string, error) {
+	logger.Debug("Getting unverified commits of Gitea pull request %d", pull.Num)
+
+	var unverified []string
+	listOptions := gitea.ListPullRequestCommitsOptions{
+		ListOptions: gitea.ListOptions{
+			Page:     1,
+			PageSize: c.pageSize,
+		},
+	}
+	for {
+		commits, resp, err := c.giteaClient.ListPullRequestCommits(repo.Owner, repo.Name, int64(pull.Num), listOptions)
+		if resp != nil {
+			logger.Debug("[page %d] GET /repos/%v/%v/pulls/%d/commits returned: %v", listOptions.Page, repo.Owner, repo.Name, pull.Num, resp.StatusCode)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, commit := range commits {
+			if commit.RepoCommit == nil || commit.RepoCommit.Verification == nil || !commit.RepoCommit.Verification.Verified {
+				unverified = append(unverified, commit.SHA)
+			}
+		}
+		// Emergency break after giteaPaginationEBreak pages
+		if resp.NextPage == 0 || listOptions.Page >= giteaPaginationEBreak {
+			break
+		}
+		listOptions.Page = resp.NextPage
+	}
+	return unverified, nil
+}
+
 // PullIsApproved returns ApprovalStatus with IsApproved set to true if the pull request has a review that approved the PR.
 func (c *Client) PullIsApproved(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest) (models.ApprovalStatus, error) {
 	logger.Debug("Checking if Gitea pull request %d is approved", pull.Num)
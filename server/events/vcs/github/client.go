@@ -14,6 +14,8 @@
 package github
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/base64"
 	"errors"
@@ -28,6 +30,7 @@ import (
 
 	"github.com/gofri/go-github-ratelimit/github_ratelimit"
 	"github.com/google/go-github/v71/github"
+	"github.com/hashicorp/go-version"
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs/common"
@@ -87,6 +90,9 @@ type Client struct {
 	config                Config
 	maxCommentsPerCommand int
 	repoIdCache           GitHubRepoIdCache
+	// enterpriseVersion is the version of the GitHub Enterprise Server, nil
+	// on github.com or if it wasn't detected.
+	enterpriseVersion *version.Version
 }
 
 // GithubAppTemporarySecrets holds app credentials obtained from github after creation.
@@ -258,8 +264,36 @@ func (g *Client) CreateComment(logger logging.SimpleLogging, repo models.Repo, p
 	return nil
 }
 
+// CreateEditableComment creates a comment that isn't split like CreateComment
+// does and returns its id so it can be edited.
+func (g *Client) CreateEditableComment(logger logging.SimpleLogging, repo models.Repo, pullNum int, comment string) (int64, error) {
+	logger.Debug("Creating editable comment on GitHub pull request %d", pullNum)
+	created, resp, err := g.client.Issues.CreateComment(g.ctx, repo.Owner, repo.Name, pullNum, &github.IssueComment{Body: &comment})
+	if resp != nil {
+		logger.Debug("POST /repos/%v/%v/issues/%d/comments returned: %v", repo.Owner, repo.Name, pullNum, resp.StatusCode)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return created.GetID(), nil
+}
+
+// EditComment replaces the body of a comment.
+func (g *Client) EditComment(logger logging.SimpleLogging, repo models.Repo, commentID int64, comment string) error {
+	logger.Debug("Editing GitHub pull request comment %d", commentID)
+	_, resp, err := g.client.Issues.EditComment(g.ctx, repo.Owner, repo.Name, commentID, &github.IssueComment{Body: &comment})
+	if resp != nil {
+		logger.Debug("PATCH /repos/%v/%v/issues/comments/%d returned: %v", repo.Owner, repo.Name, commentID, resp.StatusCode)
+	}
+	return err
+}
+
 // ReactToComment adds a reaction to a comment.
 func (g *Client) ReactToComment(logger logging.SimpleLogging, repo models.Repo, _ int, commentID int64, reaction string) error {
+	if !g.supports(featureCommentReactions) {
+		logger.Debug("not reacting to comment %d: %s", commentID, g.unsupportedError(featureCommentReactions))
+		return nil
+	}
 	logger.Debug("Adding reaction to GitHub pull request comment %d", commentID)
 	_, resp, err := g.client.Reactions.CreateIssueCommentReaction(g.ctx, repo.Owner, repo.Name, commentID, reaction)
 	if resp != nil {
@@ -268,6 +302,173 @@ func (g *Client) ReactToComment(logger logging.SimpleLogging, repo models.Repo,
 	return err
 }
 
+// ListCommentReactions returns the 👍 and 👎 reactions on the comments
+// Atlantis left on the pull request, none if the server doesn't support
+// reactions.
+func (g *Client) ListCommentReactions(logger logging.SimpleLogging, repo models.Repo, pullNum int) ([]models.CommentReactions, error) {
+	if !g.supports(featureCommentReactions) {
+		return nil, nil
+	}
+	var reactions []models.CommentReactions
+	nextPage := 0
+	for {
+		comments, resp, err := g.client.Issues.ListComments(g.ctx, repo.Owner, repo.Name, pullNum, &github.IssueListCommentsOptions{
+			ListOptions: github.ListOptions{Page: nextPage},
+		})
+		if resp != nil {
+			logger.Debug("GET /repos/%v/%v/issues/%d/comments returned: %v", repo.Owner, repo.Name, pullNum, resp.StatusCode)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("listing comments: %w", err)
+		}
+		for _, comment := range comments {
+			if comment.User != nil && !strings.EqualFold(comment.User.GetLogin(), g.user) {
+				continue
+			}
+			reactions = append(reactions, models.CommentReactions{
+				CommentID:  comment.GetID(),
+				Body:       comment.GetBody(),
+				ThumbsUp:   comment.GetReactions().GetPlusOne(),
+				ThumbsDown: comment.GetReactions().GetMinusOne(),
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		nextPage = resp.NextPage
+	}
+	return reactions, nil
+}
+
+// FindParentPull returns the open pull request whose head is branch or, if
+// there's none, the most recently created merged one. It returns nil if
+// there's neither.
+func (g *Client) FindParentPull(logger logging.SimpleLogging, repo models.Repo, branch string) (*models.ParentPull, error) {
+	logger.Debug("Finding GitHub pull request with head branch %s", branch)
+	pulls, resp, err := g.client.PullRequests.List(g.ctx, repo.Owner, repo.Name, &github.PullRequestListOptions{
+		Head:  repo.Owner + ":" + branch,
+		State: "all",
+	})
+	if resp != nil {
+		logger.Debug("GET /repos/%v/%v/pulls returned: %v", repo.Owner, repo.Name, resp.StatusCode)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("listing pull requests: %w", err)
+	}
+	var merged *models.ParentPull
+	for _, pull := range pulls {
+		parent := &models.ParentPull{
+			Num:        pull.GetNumber(),
+			HeadBranch: pull.GetHead().GetRef(),
+			BaseBranch: pull.GetBase().GetRef(),
+			Merged:     pull.MergedAt != nil,
+		}
+		if pull.GetState() == "open" {
+			return parent, nil
+		}
+		// Pull requests are listed newest first.
+		if parent.Merged && merged == nil {
+			merged = parent
+		}
+	}
+	return merged, nil
+}
+
+// ListOpenPulls returns the open pull requests of repo getting merged into
+// baseBranch.
+func (g *Client) ListOpenPulls(logger logging.SimpleLogging, repo models.Repo, baseBranch string) ([]*github.PullRequest, error) {
+	logger.Debug("Listing GitHub pull requests into %s", baseBranch)
+	var pulls []*github.PullRequest
+	nextPage := 0
+	for {
+		pagePulls, resp, err := g.client.PullRequests.List(g.ctx, repo.Owner, repo.Name, &github.PullRequestListOptions{
+			State:       "open",
+			Base:        baseBranch,
+			ListOptions: github.ListOptions{Page: nextPage},
+		})
+		if resp != nil {
+			logger.Debug("GET /repos/%v/%v/pulls returned: %v", repo.Owner, repo.Name, resp.StatusCode)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("listing pull requests: %w", err)
+		}
+		pulls = append(pulls, pagePulls...)
+		if resp.NextPage == 0 {
+			break
+		}
+		nextPage = resp.NextPage
+	}
+	return pulls, nil
+}
+
+// GetPullApprovers returns the logins of the users whose latest review of
+// pull approves it.
+func (g *Client) GetPullApprovers(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest) ([]string, error) {
+	logger.Debug("Getting approvers of GitHub pull request %d", pull.Num)
+	var logins []string
+	states := make(map[string]string)
+	nextPage := 0
+	for {
+		reviews, resp, err := g.client.PullRequests.ListReviews(g.ctx, repo.Owner, repo.Name, pull.Num, &github.ListOptions{Page: nextPage, PerPage: 100})
+		if resp != nil {
+			logger.Debug("GET /repos/%v/%v/pulls/%d/reviews returned: %v", repo.Owner, repo.Name, pull.Num, resp.StatusCode)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("getting reviews: %w", err)
+		}
+		// Reviews are listed oldest first and comments don't change
+		// whether their author approves the pull request.
+		for _, review := range reviews {
+			login := review.GetUser().GetLogin()
+			if review.GetState() == "COMMENTED" || login == "" {
+				continue
+			}
+			if _, ok := states[login]; !ok {
+				logins = append(logins, login)
+			}
+			states[login] = review.GetState()
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		nextPage = resp.NextPage
+	}
+	var approvers []string
+	for _, login := range logins {
+		if states[login] == "APPROVED" {
+			approvers = append(approvers, login)
+		}
+	}
+	return approvers, nil
+}
+
+// GetUnverifiedCommits returns the SHAs of the commits of pull whose
+// signatures GitHub didn't verify.
+func (g *Client) GetUnverifiedCommits(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest) ([]string, error) {
+	logger.Debug("Getting unverified commits of GitHub pull request %d", pull.Num)
+	var unverified []string
+	nextPage := 0
+	for {
+		commits, resp, err := g.client.PullRequests.ListCommits(g.ctx, repo.Owner, repo.Name, pull.Num, &github.ListOptions{Page: nextPage, PerPage: 100})
+		if resp != nil {
+			logger.Debug("GET /repos/%v/%v/pulls/%d/commits returned: %v", repo.Owner, repo.Name, pull.Num, resp.StatusCode)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("listing commits: %w", err)
+		}
+		for _, commit := range commits {
+			if !commit.GetCommit().GetVerification().GetVerified() {
+				unverified = append(unverified, commit.GetSHA())
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		nextPage = resp.NextPage
+	}
+	return unverified, nil
+}
+
 func (g *Client) HidePrevCommandComments(logger logging.SimpleLogging, repo models.Repo, pullNum int, command string, dir string) error {
 	logger.Debug("Hiding previous command comments on GitHub pull request %d", pullNum)
 	var allComments []*github.IssueComment
@@ -598,6 +799,99 @@ func (g *Client) WorkflowRunMatchesWorkflowFileReference(workflowRunFile Workflo
 	}
 }
 
+// pullRequestMergeability is the page of the rulesets, branch protections
+// and status checks of a pull request read to find whether it's mergeable.
+type pullRequestMergeability struct {
+	ReviewDecision githubv4.String
+	BaseRef        struct {
+		BranchProtectionRule mergeabilityBranchProtection
+		Rules                mergeabilityRules `graphql:"rules(first: 100, after: $ruleCursor)"`
+	}
+	Commits mergeabilityCommits `graphql:"commits(last: 1)"`
+}
+
+// legacyPullRequestMergeability is pullRequestMergeability for GitHub
+// Enterprise Servers without rulesets.
+type legacyPullRequestMergeability struct {
+	ReviewDecision githubv4.String
+	BaseRef        struct {
+		BranchProtectionRule mergeabilityBranchProtection
+	}
+	Commits mergeabilityCommits `graphql:"commits(last: 1)"`
+}
+
+type mergeabilityBranchProtection struct {
+	RequiredStatusChecks []struct {
+		Context githubv4.String
+	}
+}
+
+type mergeabilityRules struct {
+	PageInfo PageInfo
+	Nodes    []struct {
+		Type              githubv4.String
+		RepositoryRuleset struct {
+			Enforcement githubv4.String
+		}
+		Parameters struct {
+			RequiredStatusChecksParameters struct {
+				RequiredStatusChecks []struct {
+					Context githubv4.String
+				}
+			} `graphql:"... on RequiredStatusChecksParameters"`
+			WorkflowsParameters struct {
+				Workflows []WorkflowFileReference
+			} `graphql:"... on WorkflowsParameters"`
+		}
+	}
+}
+
+type mergeabilityCommits struct {
+	Nodes []struct {
+		Commit struct {
+			StatusCheckRollup struct {
+				Contexts struct {
+					PageInfo PageInfo
+					Nodes    []struct {
+						Typename      githubv4.String `graphql:"__typename"`
+						CheckRun      CheckRun        `graphql:"... on CheckRun"`
+						StatusContext StatusContext   `graphql:"... on StatusContext"`
+					}
+				} `graphql:"contexts(first: 100, after: $contextCursor)"`
+			}
+		}
+	}
+}
+
+// queryPullRequestMergeability queries a page of the mergeability of the pull
+// request of variables. The rules are left empty on servers without rulesets.
+func (g *Client) queryPullRequestMergeability(variables map[string]any) (pullRequestMergeability, error) {
+	if g.supports(featureRulesets) {
+		var query struct {
+			Repository struct {
+				PullRequest pullRequestMergeability `graphql:"pullRequest(number: $number)"`
+			} `graphql:"repository(owner: $owner, name: $name)"`
+		}
+		err := g.v4Client.Query(g.ctx, &query, variables)
+		return query.Repository.PullRequest, err
+	}
+
+	var query struct {
+		Repository struct {
+			PullRequest legacyPullRequestMergeability `graphql:"pullRequest(number: $number)"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+	// GraphQL rejects the queries declaring variables they don't use.
+	legacyVariables := maps.Clone(variables)
+	delete(legacyVariables, "ruleCursor")
+	err := g.v4Client.Query(g.ctx, &query, legacyVariables)
+	var pullRequest pullRequestMergeability
+	pullRequest.ReviewDecision = query.Repository.PullRequest.ReviewDecision
+	pullRequest.BaseRef.BranchProtectionRule = query.Repository.PullRequest.BaseRef.BranchProtectionRule
+	pullRequest.Commits = query.Repository.PullRequest.Commits
+	return pullRequest, err
+}
+
 func (g *Client) GetPullRequestMergeabilityInfo(
 	repo models.Repo,
 	pull *github.PullRequest,
@@ -609,56 +903,6 @@ func (g *Client) GetPullRequestMergeabilityInfo(
 	statusContexts []StatusContext,
 	err error,
 ) {
-	var query struct {
-		Repository struct {
-			PullRequest struct {
-				ReviewDecision githubv4.String
-				BaseRef        struct {
-					BranchProtectionRule struct {
-						RequiredStatusChecks []struct {
-							Context githubv4.String
-						}
-					}
-					Rules struct {
-						PageInfo PageInfo
-						Nodes    []struct {
-							Type              githubv4.String
-							RepositoryRuleset struct {
-								Enforcement githubv4.String
-							}
-							Parameters struct {
-								RequiredStatusChecksParameters struct {
-									RequiredStatusChecks []struct {
-										Context githubv4.String
-									}
-								} `graphql:"... on RequiredStatusChecksParameters"`
-								WorkflowsParameters struct {
-									Workflows []WorkflowFileReference
-								} `graphql:"... on WorkflowsParameters"`
-							}
-						}
-					} `graphql:"rules(first: 100, after: $ruleCursor)"`
-				}
-				Commits struct {
-					Nodes []struct {
-						Commit struct {
-							StatusCheckRollup struct {
-								Contexts struct {
-									PageInfo PageInfo
-									Nodes    []struct {
-										Typename      githubv4.String `graphql:"__typename"`
-										CheckRun      CheckRun        `graphql:"... on CheckRun"`
-										StatusContext StatusContext   `graphql:"... on StatusContext"`
-									}
-								} `graphql:"contexts(first: 100, after: $contextCursor)"`
-							}
-						}
-					}
-				} `graphql:"commits(last: 1)"`
-			} `graphql:"pullRequest(number: $number)"`
-		} `graphql:"repository(owner: $owner, name: $name)"`
-	}
-
 	variables := map[string]any{
 		"owner":         githubv4.String(repo.Owner),
 		"name":          githubv4.String(repo.Name),
@@ -671,19 +915,20 @@ func (g *Client) GetPullRequestMergeabilityInfo(
 
 pagination:
 	for {
-		err = g.v4Client.Query(g.ctx, &query, variables)
+		var pullRequest pullRequestMergeability
+		pullRequest, err = g.queryPullRequestMergeability(variables)
 
 		if err != nil {
 			break pagination
 		}
 
-		reviewDecision = query.Repository.PullRequest.ReviewDecision
+		reviewDecision = pullRequest.ReviewDecision
 
-		for _, rule := range query.Repository.PullRequest.BaseRef.BranchProtectionRule.RequiredStatusChecks {
+		for _, rule := range pullRequest.BaseRef.BranchProtectionRule.RequiredStatusChecks {
 			requiredChecksSet[rule.Context] = struct{}{}
 		}
 
-		for _, rule := range query.Repository.PullRequest.BaseRef.Rules.Nodes {
+		for _, rule := range pullRequest.BaseRef.Rules.Nodes {
 			if rule.RepositoryRuleset.Enforcement != "ACTIVE" {
 				continue
 			}
@@ -701,12 +946,12 @@ pagination:
 			}
 		}
 
-		if len(query.Repository.PullRequest.Commits.Nodes) == 0 {
+		if len(pullRequest.Commits.Nodes) == 0 {
 			err = errors.New("no commits found on PR")
 			break pagination
 		}
 
-		for _, context := range query.Repository.PullRequest.Commits.Nodes[0].Commit.StatusCheckRollup.Contexts.Nodes {
+		for _, context := range pullRequest.Commits.Nodes[0].Commit.StatusCheckRollup.Contexts.Nodes {
 			switch context.Typename {
 			case "CheckRun":
 				checkRuns = append(checkRuns, context.CheckRun.Copy())
@@ -718,16 +963,16 @@ pagination:
 			}
 		}
 
-		if !query.Repository.PullRequest.BaseRef.Rules.PageInfo.HasNextPage &&
-			!query.Repository.PullRequest.Commits.Nodes[0].Commit.StatusCheckRollup.Contexts.PageInfo.HasNextPage {
+		if !pullRequest.BaseRef.Rules.PageInfo.HasNextPage &&
+			!pullRequest.Commits.Nodes[0].Commit.StatusCheckRollup.Contexts.PageInfo.HasNextPage {
 			break pagination
 		}
 
-		if query.Repository.PullRequest.BaseRef.Rules.PageInfo.EndCursor != nil {
-			variables["ruleCursor"] = query.Repository.PullRequest.BaseRef.Rules.PageInfo.EndCursor
+		if pullRequest.BaseRef.Rules.PageInfo.EndCursor != nil {
+			variables["ruleCursor"] = pullRequest.BaseRef.Rules.PageInfo.EndCursor
 		}
-		if query.Repository.PullRequest.Commits.Nodes[0].Commit.StatusCheckRollup.Contexts.PageInfo.EndCursor != nil {
-			variables["contextCursor"] = query.Repository.PullRequest.Commits.Nodes[0].Commit.StatusCheckRollup.Contexts.PageInfo.EndCursor
+		if pullRequest.Commits.Nodes[0].Commit.StatusCheckRollup.Contexts.PageInfo.EndCursor != nil {
+			variables["contextCursor"] = pullRequest.Commits.Nodes[0].Commit.StatusCheckRollup.Contexts.PageInfo.EndCursor
 		}
 	}
 
@@ -981,6 +1226,289 @@ func (g *Client) UpdateStatus(logger logging.SimpleLogging, repo models.Repo, pu
 	return err
 }
 
+// CreateDeployment creates a deployment of ref to environment and returns its
+// ID. See https://docs.github.com/en/rest/deployments/deployments.
+func (g *Client) CreateDeployment(logger logging.SimpleLogging, repo models.Repo, ref string, environment string, description string) (int64, error) {
+	logger.Debug("Creating GitHub deployment of '%s' to environment '%s'", ref, environment)
+	deployment, resp, err := g.client.Repositories.CreateDeployment(g.ctx, repo.Owner, repo.Name, &github.DeploymentRequest{
+		Ref:         github.Ptr(ref),
+		Task:        github.Ptr("deploy:atlantis"),
+		AutoMerge:   github.Ptr(false),
+		Environment: github.Ptr(environment),
+		Description: github.Ptr(description),
+		// Atlantis already validated the apply requirements so don't let
+		// GitHub check the commit statuses, which include pending
+		// atlantis/apply statuses.
+		RequiredContexts: &[]string{},
+	})
+	if resp != nil {
+		logger.Debug("POST /repos/%v/%v/deployments returned: %v", repo.Owner, repo.Name, resp.StatusCode)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return deployment.GetID(), nil
+}
+
+// UpdateDeploymentStatus sets the state of the deployment with deploymentID,
+// ex. in_progress, success or failure.
+func (g *Client) UpdateDeploymentStatus(logger logging.SimpleLogging, repo models.Repo, deploymentID int64, state string, description string, logURL string) error {
+	logger.Debug("Updating GitHub deployment %d status to '%s'", deploymentID, state)
+	_, resp, err := g.client.Repositories.CreateDeploymentStatus(g.ctx, repo.Owner, repo.Name, deploymentID, &github.DeploymentStatusRequest{
+		State:       github.Ptr(state),
+		Description: github.Ptr(description),
+		LogURL:      github.Ptr(logURL),
+	})
+	if resp != nil {
+		logger.Debug("POST /repos/%v/%v/deployments/%d/statuses returned: %v", repo.Owner, repo.Name, deploymentID, resp.StatusCode)
+	}
+	return err
+}
+
+// CreateSuggestion comments a suggested change replacing lines startLine to
+// endLine of path at commitSHA with suggestion. GitHub only accepts review
+// comments on lines that are part of the pull request's diff.
+func (g *Client) CreateSuggestion(logger logging.SimpleLogging, repo models.Repo, pullNum int, commitSHA string, path string, startLine int, endLine int, body string, suggestion string) error {
+	if startLine < endLine && !g.supports(featureMultiLineComments) {
+		return g.unsupportedError(featureMultiLineComments)
+	}
+	logger.Debug("Creating GitHub suggestion on '%s' lines %d-%d", path, startLine, endLine)
+	return g.createReviewComment(logger, repo, pullNum, newReviewComment(commitSHA, path, startLine, endLine, fmt.Sprintf("%s\n\n```suggestion\n%s\n```", body, suggestion)))
+}
+
+// AnnotateFiles comments the annotations on the files of the pull request at
+// commitSHA, skipping the ones already commented on the same lines so
+// planning again doesn't repeat them. Annotations on lines that aren't part
+// of the pull request's diff are rejected by GitHub and skipped.
+func (g *Client) AnnotateFiles(logger logging.SimpleLogging, repo models.Repo, pullNum int, commitSHA string, annotations []models.FileAnnotation) error {
+	existing := make(map[string]bool)
+	opts := &github.PullRequestListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := g.client.PullRequests.ListComments(g.ctx, repo.Owner, repo.Name, pullNum, opts)
+		if resp != nil {
+			logger.Debug("GET /repos/%v/%v/pulls/%d/comments returned: %v", repo.Owner, repo.Name, pullNum, resp.StatusCode)
+		}
+		if err != nil {
+			return fmt.Errorf("listing review comments: %w", err)
+		}
+		for _, comment := range comments {
+			existing[fmt.Sprintf("%s:%d:%s", comment.GetPath(), comment.GetLine(), comment.GetBody())] = true
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	var failed int
+	for _, annotation := range annotations {
+		startLine := annotation.StartLine
+		if !g.supports(featureMultiLineComments) {
+			startLine = annotation.EndLine
+		}
+		body := annotation.Body
+		if annotation.Suggestion != nil && startLine == annotation.StartLine {
+			body = fmt.Sprintf("%s\n\n```suggestion\n%s\n```", body, *annotation.Suggestion)
+		}
+		if existing[fmt.Sprintf("%s:%d:%s", annotation.Path, annotation.EndLine, body)] {
+			continue
+		}
+		err := g.createReviewComment(logger, repo, pullNum, newReviewComment(commitSHA, annotation.Path, startLine, annotation.EndLine, body))
+		var errResp *github.ErrorResponse
+		if errors.As(err, &errResp) && errResp.Response.StatusCode == http.StatusUnprocessableEntity {
+			logger.Debug("skipping annotation of '%s' line %d outside of the pull request's diff", annotation.Path, annotation.EndLine)
+			continue
+		}
+		if err != nil {
+			logger.Warn("unable to annotate '%s' line %d: %s", annotation.Path, annotation.EndLine, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d annotations failed", failed, len(annotations))
+	}
+	return nil
+}
+
+// newReviewComment returns a review comment on lines startLine to endLine of
+// path at commitSHA.
+func newReviewComment(commitSHA string, path string, startLine int, endLine int, body string) *github.PullRequestComment {
+	comment := &github.PullRequestComment{
+		Body:     github.Ptr(body),
+		CommitID: github.Ptr(commitSHA),
+		Path:     github.Ptr(path),
+		Line:     github.Ptr(endLine),
+		Side:     github.Ptr("RIGHT"),
+	}
+	if startLine < endLine {
+		comment.StartLine = github.Ptr(startLine)
+		comment.StartSide = github.Ptr("RIGHT")
+	}
+	return comment
+}
+
+func (g *Client) createReviewComment(logger logging.SimpleLogging, repo models.Repo, pullNum int, comment *github.PullRequestComment) error {
+	_, resp, err := g.client.PullRequests.CreateComment(g.ctx, repo.Owner, repo.Name, pullNum, comment)
+	if resp != nil {
+		logger.Debug("POST /repos/%v/%v/pulls/%d/comments returned: %v", repo.Owner, repo.Name, pullNum, resp.StatusCode)
+	}
+	return err
+}
+
+// OpenPullRequest commits the files of pull onto its base branch, force-updates
+// its branch to the commit and opens a pull request from it, or updates the
+// title and body of the one already open, then returns its URL. Reviews are
+// requested and users assigned when the pull request is opened.
+func (g *Client) OpenPullRequest(logger logging.SimpleLogging, repo models.Repo, pull models.ProposedPullRequest) (string, error) {
+	if err := g.resetProposedBranch(repo, pull); err != nil {
+		return "", err
+	}
+
+	open, resp, err := g.client.PullRequests.List(g.ctx, repo.Owner, repo.Name, &github.PullRequestListOptions{
+		State: "open",
+		Head:  repo.Owner + ":" + pull.Branch,
+		Base:  pull.BaseBranch,
+	})
+	if resp != nil {
+		logger.Debug("GET /repos/%v/%v/pulls returned: %v", repo.Owner, repo.Name, resp.StatusCode)
+	}
+	if err != nil {
+		return "", fmt.Errorf("listing pull requests: %w", err)
+	}
+	if len(open) > 0 {
+		existing := open[0]
+		if existing.GetTitle() == pull.Title && existing.GetBody() == pull.Body {
+			return existing.GetHTMLURL(), nil
+		}
+		edited, resp, err := g.client.PullRequests.Edit(g.ctx, repo.Owner, repo.Name, existing.GetNumber(), &github.PullRequest{
+			Title: github.Ptr(pull.Title),
+			Body:  github.Ptr(pull.Body),
+		})
+		if resp != nil {
+			logger.Debug("PATCH /repos/%v/%v/pulls/%d returned: %v", repo.Owner, repo.Name, existing.GetNumber(), resp.StatusCode)
+		}
+		if err != nil {
+			return "", fmt.Errorf("updating pull request: %w", err)
+		}
+		return edited.GetHTMLURL(), nil
+	}
+
+	created, resp, err := g.client.PullRequests.Create(g.ctx, repo.Owner, repo.Name, &github.NewPullRequest{
+		Title: github.Ptr(pull.Title),
+		Head:  github.Ptr(pull.Branch),
+		Base:  github.Ptr(pull.BaseBranch),
+		Body:  github.Ptr(pull.Body),
+	})
+	if resp != nil {
+		logger.Debug("POST /repos/%v/%v/pulls returned: %v", repo.Owner, repo.Name, resp.StatusCode)
+	}
+	if err != nil {
+		return "", fmt.Errorf("creating pull request: %w", err)
+	}
+	var users, teams []string
+	for _, reviewer := range pull.Reviewers {
+		reviewer = strings.TrimPrefix(reviewer, "@")
+		if _, team, ok := strings.Cut(reviewer, "/"); ok {
+			teams = append(teams, team)
+		} else {
+			users = append(users, reviewer)
+		}
+	}
+	// The pull request is open, failing to request reviews isn't worth
+	// failing over.
+	if len(users) > 0 || len(teams) > 0 {
+		if _, _, err := g.client.PullRequests.RequestReviewers(g.ctx, repo.Owner, repo.Name, created.GetNumber(), github.ReviewersRequest{Reviewers: users, TeamReviewers: teams}); err != nil {
+			logger.Warn("unable to request reviews of pull request #%d: %s", created.GetNumber(), err)
+		}
+	}
+	if len(users) > 0 {
+		if _, _, err := g.client.Issues.AddAssignees(g.ctx, repo.Owner, repo.Name, created.GetNumber(), users); err != nil {
+			logger.Warn("unable to assign pull request #%d: %s", created.GetNumber(), err)
+		}
+	}
+	return created.GetHTMLURL(), nil
+}
+
+// resetProposedBranch commits the files of pull onto the current head of its
+// base branch and force-updates its branch, creating it if needed, to the
+// commit.
+func (g *Client) resetProposedBranch(repo models.Repo, pull models.ProposedPullRequest) error {
+	baseRef, _, err := g.client.Git.GetRef(g.ctx, repo.Owner, repo.Name, "heads/"+pull.BaseBranch)
+	if err != nil {
+		return fmt.Errorf("getting branch %s: %w", pull.BaseBranch, err)
+	}
+	baseCommit, _, err := g.client.Git.GetCommit(g.ctx, repo.Owner, repo.Name, baseRef.GetObject().GetSHA())
+	if err != nil {
+		return fmt.Errorf("getting commit of branch %s: %w", pull.BaseBranch, err)
+	}
+	tree := baseCommit.GetTree()
+	if len(pull.Files) > 0 {
+		paths := make([]string, 0, len(pull.Files))
+		for path := range pull.Files {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		var entries []*github.TreeEntry
+		for _, path := range paths {
+			entries = append(entries, &github.TreeEntry{
+				Path:    github.Ptr(path),
+				Mode:    github.Ptr("100644"),
+				Type:    github.Ptr("blob"),
+				Content: github.Ptr(pull.Files[path]),
+			})
+		}
+		if tree, _, err = g.client.Git.CreateTree(g.ctx, repo.Owner, repo.Name, tree.GetSHA(), entries); err != nil {
+			return fmt.Errorf("creating tree: %w", err)
+		}
+	}
+	commit, _, err := g.client.Git.CreateCommit(g.ctx, repo.Owner, repo.Name, &github.Commit{
+		Message: github.Ptr(pull.CommitMessage),
+		Tree:    tree,
+		Parents: []*github.Commit{{SHA: baseCommit.SHA}},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("creating commit: %w", err)
+	}
+	ref := &github.Reference{Ref: github.Ptr("refs/heads/" + pull.Branch), Object: &github.GitObject{SHA: commit.SHA}}
+	_, resp, err := g.client.Git.UpdateRef(g.ctx, repo.Owner, repo.Name, ref, true)
+	if resp != nil && resp.StatusCode == http.StatusUnprocessableEntity {
+		// The branch doesn't exist yet.
+		_, _, err = g.client.Git.CreateRef(g.ctx, repo.Owner, repo.Name, ref)
+	}
+	if err != nil {
+		return fmt.Errorf("updating branch %s: %w", pull.Branch, err)
+	}
+	return nil
+}
+
+// UploadSarif uploads the SARIF report to the code scanning of repo for the
+// commit of the pull request.
+func (g *Client) UploadSarif(logger logging.SimpleLogging, repo models.Repo, pullNum int, commitSHA string, sarif []byte) error {
+	if !g.supports(featureCodeScanning) {
+		return g.unsupportedError(featureCodeScanning)
+	}
+	logger.Debug("Uploading SARIF report to the code scanning of GitHub repo '%s'", repo.FullName)
+	// The API requires the report gzipped and base64 encoded.
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	if _, err := gz.Write(sarif); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	_, resp, err := g.client.CodeScanning.UploadSarif(g.ctx, repo.Owner, repo.Name, &github.SarifAnalysis{
+		CommitSHA: github.Ptr(commitSHA),
+		Ref:       github.Ptr(fmt.Sprintf("refs/pull/%d/head", pullNum)),
+		Sarif:     github.Ptr(base64.StdEncoding.EncodeToString(compressed.Bytes())),
+		ToolName:  github.Ptr("atlantis"),
+	})
+	if resp != nil {
+		logger.Debug("POST /repos/%v/%v/code-scanning/sarifs returned: %v", repo.Owner, repo.Name, resp.StatusCode)
+	}
+	return err
+}
+
 // MergePull merges the pull request.
 func (g *Client) MergePull(logger logging.SimpleLogging, pull models.PullRequest, pullOptions models.PullRequestOptions) error {
 	logger.Debug("Merging GitHub pull request %d", pull.Num)
@@ -1044,7 +1572,7 @@ func (g *Client) MergePull(logger logging.SimpleLogging, pull models.PullRequest
 		pull.Num,
 		// NOTE: Using the empty string here causes GitHub to autogenerate
 		// the commit message as it normally would.
-		"",
+		pullOptions.CommitMessage,
 		options)
 	if resp != nil {
 		logger.Debug("POST /repos/%v/%v/pulls/%d/merge returned: %v", repo.Owner, repo.Name, pull.Num, resp.StatusCode)
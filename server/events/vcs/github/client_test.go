@@ -4,7 +4,10 @@
 package github_test
 
 import (
+	"bytes"
+	"compress/gzip"
 	"crypto/tls"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,6 +15,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -435,6 +439,519 @@ func TestClient_UpdateStatus(t *testing.T) {
 	}
 }
 
+func TestClient_Deployments(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			Ok(t, err)
+			defer r.Body.Close() // nolint: errcheck
+			switch r.RequestURI {
+			case "/api/v3/repos/owner/repo/deployments":
+				Equals(t, `{"ref":"sha","task":"deploy:atlantis","auto_merge":false,"required_contexts":[],"environment":"production","description":"description"}`+"\n", string(body))
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"id":42}`)) // nolint: errcheck
+			case "/api/v3/repos/owner/repo/deployments/42/statuses":
+				Equals(t, `{"state":"success","log_url":"https://github.com/owner/repo/pull/1","description":"Apply succeeded"}`+"\n", string(body))
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"id":1}`)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := github.New(testServerURL.Host, &github.UserCredentials{"user", "pass", ""}, github.Config{}, 0, logging.NewNoopLogger(t))
+	Ok(t, err)
+	defer disableSSLVerification()()
+
+	repo := models.Repo{
+		FullName: "owner/repo",
+		Owner:    "owner",
+		Name:     "repo",
+		VCSHost: models.VCSHost{
+			Type:     models.Github,
+			Hostname: "github.com",
+		},
+	}
+	id, err := client.CreateDeployment(logger, repo, "sha", "production", "description")
+	Ok(t, err)
+	Equals(t, int64(42), id)
+	Ok(t, client.UpdateDeploymentStatus(logger, repo, id, "success", "Apply succeeded", "https://github.com/owner/repo/pull/1"))
+}
+
+func TestClient_CreateSuggestion(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	var bodies []string
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			Ok(t, err)
+			defer r.Body.Close() // nolint: errcheck
+			switch r.RequestURI {
+			case "/api/v3/repos/owner/repo/pulls/1/comments":
+				bodies = append(bodies, string(body))
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"id":1}`)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := github.New(testServerURL.Host, &github.UserCredentials{"user", "pass", ""}, github.Config{}, 0, logging.NewNoopLogger(t))
+	Ok(t, err)
+	defer disableSSLVerification()()
+
+	repo := models.Repo{
+		FullName: "owner/repo",
+		Owner:    "owner",
+		Name:     "repo",
+		VCSHost: models.VCSHost{
+			Type:     models.Github,
+			Hostname: "github.com",
+		},
+	}
+	Ok(t, client.CreateSuggestion(logger, repo, 1, "sha", "main.tf", 3, 3, "fix", `  ami = "ami-1"`))
+	Ok(t, client.CreateSuggestion(logger, repo, 1, "sha", "main.tf", 3, 4, "fix", "a\nb"))
+	Equals(t, []string{
+		`{"body":"fix\n\n` + "```" + `suggestion\n  ami = \"ami-1\"\n` + "```" + `","path":"main.tf","line":3,"side":"RIGHT","commit_id":"sha"}` + "\n",
+		`{"body":"fix\n\n` + "```" + `suggestion\na\nb\n` + "```" + `","path":"main.tf","start_line":3,"line":4,"side":"RIGHT","start_side":"RIGHT","commit_id":"sha"}` + "\n",
+	}, bodies)
+}
+
+func TestClient_AnnotateFiles(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	var bodies []string
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == "/api/v3/repos/owner/repo/pulls/1/comments":
+				w.Write([]byte(`[{"path":"main.tf","line":3,"body":"already commented"}]`)) // nolint: errcheck
+			case r.Method == http.MethodPost && r.URL.Path == "/api/v3/repos/owner/repo/pulls/1/comments":
+				body, err := io.ReadAll(r.Body)
+				Ok(t, err)
+				if strings.Contains(string(body), `"line":9`) {
+					http.Error(w, `{"message":"Validation Failed"}`, http.StatusUnprocessableEntity)
+					return
+				}
+				bodies = append(bodies, string(body))
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"id":1}`)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := github.New(testServerURL.Host, &github.UserCredentials{"user", "pass", ""}, github.Config{}, 0, logging.NewNoopLogger(t))
+	Ok(t, err)
+	defer disableSSLVerification()()
+
+	repo := models.Repo{FullName: "owner/repo", Owner: "owner", Name: "repo"}
+	suggestion := "a = 1"
+	Ok(t, client.AnnotateFiles(logger, repo, 1, "sha", []models.FileAnnotation{
+		{Path: "main.tf", StartLine: 3, EndLine: 3, Body: "already commented"},
+		{Path: "main.tf", StartLine: 5, EndLine: 5, Body: "fmt", Suggestion: &suggestion},
+		{Path: "main.tf", StartLine: 9, EndLine: 9, Body: "outside of the diff"},
+	}))
+	Equals(t, []string{
+		`{"body":"fmt\n\n` + "```" + `suggestion\na = 1\n` + "```" + `","path":"main.tf","line":5,"side":"RIGHT","commit_id":"sha"}` + "\n",
+	}, bodies)
+}
+
+func TestClient_OpenPullRequest(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	pull := models.ProposedPullRequest{
+		Branch:        "atlantis/drift/prod",
+		BaseBranch:    "main",
+		Title:         "Remediate drift of prod",
+		Body:          "drift",
+		Files:         map[string]string{"main.tf": "a = 1\n"},
+		CommitMessage: "Remediate drift of prod",
+		Reviewers:     []string{"@org/platform", "@alice"},
+	}
+	for name, openPulls := range map[string]string{
+		"new":  `[]`,
+		"open": `[{"number":3,"title":"Remediate drift of prod","body":"old drift","html_url":"https://github.com/owner/repo/pull/3"}]`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var requests []string
+			testServer := httptest.NewTLSServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					body, err := io.ReadAll(r.Body)
+					Ok(t, err)
+					request := r.Method + " " + strings.TrimPrefix(r.URL.Path, "/api/v3/repos/owner/repo")
+					requests = append(requests, request)
+					switch request {
+					case "GET /pulls":
+						Equals(t, "owner:atlantis/drift/prod", r.URL.Query().Get("head"))
+						w.Write([]byte(openPulls)) // nolint: errcheck
+					case "PATCH /pulls/3":
+						Equals(t, `{"title":"Remediate drift of prod","body":"drift"}`+"\n", string(body))
+						w.Write([]byte(`{"number":3,"html_url":"https://github.com/owner/repo/pull/3"}`)) // nolint: errcheck
+					case "GET /git/ref/heads/main":
+						w.Write([]byte(`{"ref":"refs/heads/main","object":{"sha":"base"}}`)) // nolint: errcheck
+					case "GET /git/commits/base":
+						w.Write([]byte(`{"sha":"base","tree":{"sha":"basetree"}}`)) // nolint: errcheck
+					case "POST /git/trees":
+						Equals(t, `{"base_tree":"basetree","tree":[{"path":"main.tf","mode":"100644","type":"blob","content":"a = 1\n"}]}`+"\n", string(body))
+						w.Write([]byte(`{"sha":"tree"}`)) // nolint: errcheck
+					case "POST /git/commits":
+						Equals(t, `{"message":"Remediate drift of prod","tree":"tree","parents":["base"]}`+"\n", string(body))
+						w.Write([]byte(`{"sha":"commit"}`)) // nolint: errcheck
+					case "PATCH /git/refs/heads/atlantis/drift/prod":
+						Equals(t, `{"sha":"commit","force":true}`+"\n", string(body))
+						if name == "new" {
+							http.Error(w, `{"message":"Reference does not exist"}`, http.StatusUnprocessableEntity)
+							return
+						}
+						w.Write([]byte(`{"ref":"refs/heads/atlantis/drift/prod"}`)) // nolint: errcheck
+					case "POST /git/refs":
+						Equals(t, `{"ref":"refs/heads/atlantis/drift/prod","sha":"commit"}`+"\n", string(body))
+						w.Write([]byte(`{"ref":"refs/heads/atlantis/drift/prod"}`)) // nolint: errcheck
+					case "POST /pulls":
+						w.Write([]byte(`{"number":4,"html_url":"https://github.com/owner/repo/pull/4"}`)) // nolint: errcheck
+					case "POST /pulls/4/requested_reviewers":
+						Equals(t, `{"reviewers":["alice"],"team_reviewers":["platform"]}`+"\n", string(body))
+						w.Write([]byte(`{"number":4}`)) // nolint: errcheck
+					case "POST /issues/4/assignees":
+						Equals(t, `{"assignees":["alice"]}`+"\n", string(body))
+						w.Write([]byte(`{"number":4}`)) // nolint: errcheck
+					default:
+						t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+						http.Error(w, "not found", http.StatusNotFound)
+					}
+				}))
+
+			testServerURL, err := url.Parse(testServer.URL)
+			Ok(t, err)
+			client, err := github.New(testServerURL.Host, &github.UserCredentials{"user", "pass", ""}, github.Config{}, 0, logging.NewNoopLogger(t))
+			Ok(t, err)
+			defer disableSSLVerification()()
+
+			pullURL, err := client.OpenPullRequest(logger, models.Repo{FullName: "owner/repo", Owner: "owner", Name: "repo"}, pull)
+			Ok(t, err)
+			if name == "open" {
+				// The branch of an open pull request is reset too.
+				Equals(t, "https://github.com/owner/repo/pull/3", pullURL)
+				Equals(t, []string{"GET /git/ref/heads/main", "GET /git/commits/base", "POST /git/trees", "POST /git/commits",
+					"PATCH /git/refs/heads/atlantis/drift/prod", "GET /pulls", "PATCH /pulls/3"}, requests)
+				return
+			}
+			Equals(t, "https://github.com/owner/repo/pull/4", pullURL)
+			Equals(t, 10, len(requests))
+		})
+	}
+}
+
+func TestClient_FindParentPull(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	pulls := map[string]string{
+		"open":   `[{"number":3,"state":"open","head":{"ref":"open"},"base":{"ref":"main"}}]`,
+		"merged": `[{"number":2,"state":"closed","head":{"ref":"merged"},"base":{"ref":"main"}},{"number":1,"state":"closed","merged_at":"2025-01-01T00:00:00Z","head":{"ref":"merged"},"base":{"ref":"main"}}]`,
+		"none":   `[]`,
+	}
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Equals(t, "/api/v3/repos/owner/repo/pulls", r.URL.Path)
+			Equals(t, "all", r.URL.Query().Get("state"))
+			w.Write([]byte(pulls[strings.TrimPrefix(r.URL.Query().Get("head"), "owner:")])) // nolint: errcheck
+		}))
+
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := github.New(testServerURL.Host, &github.UserCredentials{"user", "pass", ""}, github.Config{}, 0, logging.NewNoopLogger(t))
+	Ok(t, err)
+	defer disableSSLVerification()()
+
+	repo := models.Repo{
+		FullName: "owner/repo",
+		Owner:    "owner",
+		Name:     "repo",
+	}
+	parent, err := client.FindParentPull(logger, repo, "open")
+	Ok(t, err)
+	Equals(t, &models.ParentPull{Num: 3, HeadBranch: "open", BaseBranch: "main"}, parent)
+
+	parent, err = client.FindParentPull(logger, repo, "merged")
+	Ok(t, err)
+	Equals(t, &models.ParentPull{Num: 1, HeadBranch: "merged", BaseBranch: "main", Merged: true}, parent)
+
+	parent, err = client.FindParentPull(logger, repo, "none")
+	Ok(t, err)
+	Assert(t, parent == nil, "expected no parent, got %v", parent)
+}
+
+func TestClient_GetPullApprovers(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Equals(t, "/api/v3/repos/owner/repo/pulls/1/reviews", r.URL.Path)
+			w.Write([]byte(`[
+				{"user":{"login":"alice"},"state":"APPROVED"},
+				{"user":{"login":"bob"},"state":"APPROVED"},
+				{"user":{"login":"carol"},"state":"CHANGES_REQUESTED"},
+				{"user":{"login":"bob"},"state":"CHANGES_REQUESTED"},
+				{"user":{"login":"alice"},"state":"COMMENTED"},
+				{"user":{"login":"carol"},"state":"APPROVED"}
+			]`)) // nolint: errcheck
+		}))
+
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := github.New(testServerURL.Host, &github.UserCredentials{"user", "pass", ""}, github.Config{}, 0, logging.NewNoopLogger(t))
+	Ok(t, err)
+	defer disableSSLVerification()()
+
+	repo := models.Repo{
+		FullName: "owner/repo",
+		Owner:    "owner",
+		Name:     "repo",
+	}
+	approvers, err := client.GetPullApprovers(logger, repo, models.PullRequest{Num: 1})
+	Ok(t, err)
+	Equals(t, []string{"alice", "carol"}, approvers)
+}
+
+func TestClient_GetUnverifiedCommits(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Equals(t, "/api/v3/repos/owner/repo/pulls/1/commits", r.URL.Path)
+			if r.URL.Query().Get("page") == "2" {
+				w.Write([]byte(`[{"sha":"ccc3333","commit":{}}]`)) // nolint: errcheck
+				return
+			}
+			w.Header().Set("Link", fmt.Sprintf(`<https://%s/api/v3/repos/owner/repo/pulls/1/commits?page=2>; rel="next"`, r.Host))
+			w.Write([]byte(`[{"sha":"aaa1111","commit":{"verification":{"verified":true,"reason":"valid"}}},{"sha":"bbb2222","commit":{"verification":{"verified":false,"reason":"unsigned"}}}]`)) // nolint: errcheck
+		}))
+
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := github.New(testServerURL.Host, &github.UserCredentials{"user", "pass", ""}, github.Config{}, 0, logging.NewNoopLogger(t))
+	Ok(t, err)
+	defer disableSSLVerification()()
+
+	repo := models.Repo{
+		FullName: "owner/repo",
+		Owner:    "owner",
+		Name:     "repo",
+	}
+	unverified, err := client.GetUnverifiedCommits(logger, repo, models.PullRequest{Num: 1})
+	Ok(t, err)
+	Equals(t, []string{"bbb2222", "ccc3333"}, unverified)
+}
+
+func TestClient_UploadSarif(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	var analysis map[string]string
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Equals(t, "/api/v3/repos/owner/repo/code-scanning/sarifs", r.URL.Path)
+			Ok(t, json.NewDecoder(r.Body).Decode(&analysis))
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"id":"47177e22-5596-11eb-80a1-c1e54ef945c6"}`)) // nolint: errcheck
+		}))
+
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := github.New(testServerURL.Host, &github.UserCredentials{"user", "pass", ""}, github.Config{}, 0, logging.NewNoopLogger(t))
+	Ok(t, err)
+	defer disableSSLVerification()()
+
+	repo := models.Repo{
+		FullName: "owner/repo",
+		Owner:    "owner",
+		Name:     "repo",
+	}
+	Ok(t, client.UploadSarif(logger, repo, 7, "abc123", []byte(`{"version":"2.1.0"}`)))
+	Equals(t, "abc123", analysis["commit_sha"])
+	Equals(t, "refs/pull/7/head", analysis["ref"])
+	compressed, err := base64.StdEncoding.DecodeString(analysis["sarif"])
+	Ok(t, err)
+	gz, err := gzip.NewReader(bytes.NewReader(compressed))
+	Ok(t, err)
+	sarif, err := io.ReadAll(gz)
+	Ok(t, err)
+	Equals(t, `{"version":"2.1.0"}`, string(sarif))
+}
+
+func TestClient_DetectEnterpriseVersion(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	jsBytes, err := os.ReadFile("testdata/pull-request.json")
+	Ok(t, err)
+	prJSON := string(jsBytes)
+	mergeabilityJSON := `{"data":{"repository":{"pullRequest":{
+  "reviewDecision":null,
+  "baseRef":{"branchProtectionRule":{"requiredStatusChecks":[{"context":"atlantis/apply"},{"context":"ci"}]}%s},
+  "commits":{"nodes":[{"commit":{"statusCheckRollup":{"contexts":{"pageInfo":{"hasNextPage":false},"nodes":[]}}}}]}
+}}}}`
+
+	cases := []struct {
+		installedVersion string
+		supportsRulesets bool
+		supportsFeatures bool
+	}{
+		{"", true, true},
+		{"3.14.2", true, true},
+		{"3.10.1", false, true},
+		{"2.22.0", false, false},
+	}
+	for _, c := range cases {
+		t.Run(c.installedVersion, func(t *testing.T) {
+			var requests []string
+			testServer := httptest.NewTLSServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					requests = append(requests, r.Method+" "+r.URL.Path)
+					switch r.URL.Path {
+					case "/api/v3/meta":
+						fmt.Fprintf(w, `{"installed_version":%q}`, c.installedVersion) // nolint: errcheck
+					case "/api/v3/repos/owner/repo/pulls/1":
+						w.Write([]byte(prJSON)) // nolint: errcheck
+					case "/api/graphql":
+						body, err := io.ReadAll(r.Body)
+						Ok(t, err)
+						Equals(t, c.supportsRulesets, strings.Contains(string(body), "ruleCursor"))
+						rules := ""
+						if c.supportsRulesets {
+							rules = `,"rules":{"pageInfo":{"hasNextPage":false},"nodes":[]}`
+						}
+						fmt.Fprintf(w, mergeabilityJSON, rules) // nolint: errcheck
+					case "/api/v3/repos/owner/repo/code-scanning/sarifs":
+						w.WriteHeader(http.StatusAccepted)
+						w.Write([]byte(`{"id":"47177e22-5596-11eb-80a1-c1e54ef945c6"}`)) // nolint: errcheck
+					case "/api/v3/repos/owner/repo/issues/comments/2/reactions":
+						w.WriteHeader(http.StatusCreated)
+						w.Write([]byte(`{"id":1,"content":"eyes"}`)) // nolint: errcheck
+					case "/api/v3/repos/owner/repo/issues/1/comments":
+						w.Write([]byte(`[]`)) // nolint: errcheck
+					default:
+						t.Errorf("got unexpected request at %q", r.URL.Path)
+						http.Error(w, "not found", http.StatusNotFound)
+					}
+				}))
+
+			testServerURL, err := url.Parse(testServer.URL)
+			Ok(t, err)
+			client, err := github.New(testServerURL.Host, &github.UserCredentials{"user", "pass", ""}, github.Config{}, 0, logging.NewNoopLogger(t))
+			Ok(t, err)
+			defer disableSSLVerification()()
+			client.DetectEnterpriseVersion(logger)
+
+			repo := models.Repo{
+				FullName: "owner/repo",
+				Owner:    "owner",
+				Name:     "repo",
+			}
+			pull, err := client.GetPullRequest(logger, repo, 1)
+			Ok(t, err)
+			_, requiredChecks, _, _, _, err := client.GetPullRequestMergeabilityInfo(repo, pull)
+			Ok(t, err)
+			Equals(t, 2, len(requiredChecks))
+
+			Ok(t, client.ReactToComment(logger, repo, 1, 2, "eyes"))
+			_, err = client.ListCommentReactions(logger, repo, 1)
+			Ok(t, err)
+			err = client.UploadSarif(logger, repo, 1, "abc123", []byte(`{}`))
+			if c.supportsFeatures {
+				Assert(t, slices.Contains(requests, "POST /api/v3/repos/owner/repo/issues/comments/2/reactions"), "expected a reaction")
+				Assert(t, slices.Contains(requests, "GET /api/v3/repos/owner/repo/issues/1/comments"), "expected the comments to be listed")
+				Ok(t, err)
+			} else {
+				Assert(t, !slices.Contains(requests, "POST /api/v3/repos/owner/repo/issues/comments/2/reactions"), "expected no reaction")
+				Assert(t, !slices.Contains(requests, "GET /api/v3/repos/owner/repo/issues/1/comments"), "expected no comments to be listed")
+				ErrEquals(t, "GitHub Enterprise Server 2.22.0 doesn't support code scanning, which requires 3.0.0", err)
+			}
+		})
+	}
+}
+
+func TestClient_EditableComment(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	var requests []string
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			Ok(t, err)
+			defer r.Body.Close() // nolint: errcheck
+			requests = append(requests, r.Method+" "+r.RequestURI+" "+string(body))
+			switch r.RequestURI {
+			case "/api/v3/repos/owner/repo/issues/1/comments":
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"id":42}`)) // nolint: errcheck
+			case "/api/v3/repos/owner/repo/issues/comments/42":
+				w.Write([]byte(`{"id":42}`)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := github.New(testServerURL.Host, &github.UserCredentials{"user", "pass", ""}, github.Config{}, 0, logging.NewNoopLogger(t))
+	Ok(t, err)
+	defer disableSSLVerification()()
+
+	repo := models.Repo{
+		FullName: "owner/repo",
+		Owner:    "owner",
+		Name:     "repo",
+		VCSHost: models.VCSHost{
+			Type:     models.Github,
+			Hostname: "github.com",
+		},
+	}
+	commentID, err := client.CreateEditableComment(logger, repo, 1, "running")
+	Ok(t, err)
+	Equals(t, int64(42), commentID)
+	Ok(t, client.EditComment(logger, repo, commentID, "done"))
+	Equals(t, []string{
+		`POST /api/v3/repos/owner/repo/issues/1/comments {"body":"running"}` + "\n",
+		`PATCH /api/v3/repos/owner/repo/issues/comments/42 {"body":"done"}` + "\n",
+	}, requests)
+}
+
+func TestClient_ListCommentReactions(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.RequestURI {
+			case "/api/v3/user":
+				w.Write([]byte(`{"login":"atlantis"}`)) // nolint: errcheck
+			case "/api/v3/repos/owner/repo/issues/1/comments":
+				w.Write([]byte(`[
+					{"id":1,"body":"summary","user":{"login":"atlantis"},"reactions":{"+1":2,"-1":1}},
+					{"id":2,"body":"lgtm","user":{"login":"someone"},"reactions":{"+1":1,"-1":0}}
+				]`)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := github.New(testServerURL.Host, &github.UserCredentials{"atlantis", "pass", ""}, github.Config{}, 0, logging.NewNoopLogger(t))
+	Ok(t, err)
+	defer disableSSLVerification()()
+
+	reactions, err := client.ListCommentReactions(logger, models.Repo{
+		FullName: "owner/repo",
+		Owner:    "owner",
+		Name:     "repo",
+	}, 1)
+	Ok(t, err)
+	Equals(t, []models.CommentReactions{{CommentID: 1, Body: "summary", ThumbsUp: 2, ThumbsDown: 1}}, reactions)
+}
+
 func TestClient_PullIsApproved(t *testing.T) {
 	logger := logging.NewNoopLogger(t)
 	respTemplate := `[
@@ -4,12 +4,18 @@
 package github
 
 import (
+	"bytes"
 	"context"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/bradleyfalzon/ghinstallation/v2"
 	"github.com/google/go-github/v71/github"
@@ -106,23 +112,46 @@ func (c *UserCredentials) GetToken() (string, error) {
 }
 
 // GithubAppCredentials implements GithubCredentials for github app installation token flow.
+// The App can have several concurrently valid private keys so they can be
+// rotated without downtime: the first key GitHub accepts is used.
 type AppCredentials struct {
-	AppID          int64
-	Key            []byte
+	AppID int64
+	// Key holds one or more PEM encoded private keys.
+	Key []byte
+	// KeyFiles are paths to files of PEM encoded private keys, which are
+	// reloaded when they're modified. Used instead of Key if set.
+	KeyFiles       []string
 	Hostname       string
 	apiURL         *url.URL
 	InstallationID int64
-	tr             *ghinstallation.Transport
 	AppSlug        string
+
+	mutex         sync.Mutex
+	keyFilesMTime []time.Time
+	transports    []*ghinstallation.Transport
+	active        int
+}
+
+// appTransport authenticates requests with the installation tokens of the
+// active private key of the App.
+type appTransport struct {
+	credentials *AppCredentials
+}
+
+func (t *appTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	itr, err := t.credentials.transport()
+	if err != nil {
+		return nil, err
+	}
+	return itr.RoundTrip(req)
 }
 
 // Client returns a github app installation client.
 func (c *AppCredentials) Client() (*http.Client, error) {
-	itr, err := c.transport()
-	if err != nil {
+	if _, err := c.transport(); err != nil {
 		return nil, err
 	}
-	return &http.Client{Transport: itr}, nil
+	return &http.Client{Transport: &appTransport{credentials: c}}, nil
 }
 
 // GetUser returns the username for these credentials.
@@ -161,16 +190,47 @@ func (c *AppCredentials) GetToken() (string, error) {
 	return tr.Token(context.Background())
 }
 
-func (c *AppCredentials) getInstallationID() (int64, error) {
+// RotateKey replaces the private keys of Key with the PEM encoded private
+// keys of key.
+func (c *AppCredentials) RotateKey(key []byte) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.Key = key
+	c.transports = nil
+}
+
+func (c *AppCredentials) getInstallationID(keys [][]byte) (int64, error) {
 	if c.InstallationID != 0 {
 		return c.InstallationID, nil
 	}
 
+	var installations []*github.Installation
+	var err error
+	for _, key := range keys {
+		installations, err = c.listInstallations(key)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if len(installations) != 1 {
+		return 0, fmt.Errorf("wrong number of installations, expected 1, found %d", len(installations))
+	}
+
+	c.InstallationID = installations[0].GetID()
+	return c.InstallationID, nil
+}
+
+// listInstallations lists the installations of the App authenticated by key.
+func (c *AppCredentials) listInstallations(key []byte) ([]*github.Installation, error) {
 	tr := http.DefaultTransport
 	// A non-installation transport
-	t, err := ghinstallation.NewAppsTransport(tr, c.AppID, c.Key)
+	t, err := ghinstallation.NewAppsTransport(tr, c.AppID, key)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 	t.BaseURL = c.getAPIURL().String()
 
@@ -180,36 +240,113 @@ func (c *AppCredentials) getInstallationID() (int64, error) {
 	ctx := context.Background()
 
 	installations, _, err := client.Apps.ListInstallations(ctx, nil)
-	if err != nil {
-		return 0, err
-	}
+	return installations, err
+}
 
-	if len(installations) != 1 {
-		return 0, fmt.Errorf("wrong number of installations, expected 1, found %d", len(installations))
+// transport returns the installation transport of the active private key. If
+// GitHub rejects it, ex. because it was revoked, the next key GitHub accepts
+// becomes the active key.
+func (c *AppCredentials) transport() (*ghinstallation.Transport, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if err := c.reloadKeyFiles(); err != nil {
+		return nil, err
+	}
+	if c.transports == nil {
+		keys, err := c.keys()
+		if err != nil {
+			return nil, err
+		}
+		installationID, err := c.getInstallationID(keys)
+		if err != nil {
+			return nil, err
+		}
+		apiURL := strings.TrimSuffix(c.getAPIURL().String(), "/")
+		for _, key := range keys {
+			itr, err := ghinstallation.New(http.DefaultTransport, c.AppID, installationID, key)
+			if err != nil {
+				return nil, err
+			}
+			itr.BaseURL = apiURL
+			c.transports = append(c.transports, itr)
+		}
+		c.active = 0
 	}
 
-	c.InstallationID = installations[0].GetID()
-	return c.InstallationID, nil
+	if len(c.transports) == 1 {
+		return c.transports[0], nil
+	}
+	var err error
+	for i := range c.transports {
+		index := (c.active + i) % len(c.transports)
+		// Tokens are cached until they expire, so this only calls GitHub
+		// when the token must be refreshed.
+		if _, err = c.transports[index].Token(context.Background()); err == nil {
+			c.active = index
+			return c.transports[index], nil
+		}
+	}
+	return nil, fmt.Errorf("no private key of the app was accepted: %w", err)
 }
 
-func (c *AppCredentials) transport() (*ghinstallation.Transport, error) {
-	if c.tr != nil {
-		return c.tr, nil
+// keys returns the PEM encoded private keys of Key or KeyFiles.
+func (c *AppCredentials) keys() ([][]byte, error) {
+	var keys [][]byte
+	if len(c.KeyFiles) == 0 {
+		keys = splitPEMKeys(c.Key)
 	}
+	for _, keyFile := range c.KeyFiles {
+		content, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading github app key file: %w", err)
+		}
+		keys = append(keys, splitPEMKeys(content)...)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no PEM encoded private key was found for the github app")
+	}
+	return keys, nil
+}
 
-	installationID, err := c.getInstallationID()
-	if err != nil {
-		return nil, err
+// reloadKeyFiles resets the transports if a file of KeyFiles was modified
+// since the keys were loaded.
+func (c *AppCredentials) reloadKeyFiles() error {
+	if len(c.KeyFiles) == 0 {
+		return nil
 	}
+	mtimes := make([]time.Time, len(c.KeyFiles))
+	for i, keyFile := range c.KeyFiles {
+		info, err := os.Stat(keyFile)
+		if err != nil {
+			return fmt.Errorf("failed reading github app key file: %w", err)
+		}
+		mtimes[i] = info.ModTime()
+	}
+	if !slices.EqualFunc(mtimes, c.keyFilesMTime, time.Time.Equal) {
+		c.keyFilesMTime = mtimes
+		c.transports = nil
+	}
+	return nil
+}
 
-	tr := http.DefaultTransport
-	itr, err := ghinstallation.New(tr, c.AppID, installationID, c.Key)
-	if err == nil {
-		apiURL := c.getAPIURL()
-		itr.BaseURL = strings.TrimSuffix(apiURL.String(), "/")
-		c.tr = itr
+// splitPEMKeys splits content into its PEM encoded blocks. content is returned
+// as is if it has no PEM block so the errors of invalid keys are reported.
+func splitPEMKeys(content []byte) [][]byte {
+	var keys [][]byte
+	rest := content
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		keys = append(keys, pem.EncodeToMemory(block))
+	}
+	if len(keys) == 0 && len(bytes.TrimSpace(content)) > 0 {
+		return [][]byte{content}
 	}
-	return itr, err
+	return keys
 }
 
 func (c *AppCredentials) getAPIURL() *url.URL {
@@ -4,7 +4,14 @@
 package github_test
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/runatlantis/atlantis/server/events/vcs/github"
 	"github.com/runatlantis/atlantis/server/events/vcs/github/testdata"
@@ -109,3 +116,56 @@ func TestClient_MultipleAppAuthentication(t *testing.T) {
 		t.Errorf("app token was not cached: %q != %q", token, newToken)
 	}
 }
+
+// revokedKey returns a PEM encoded private key the test servers reject.
+func revokedKey(t *testing.T) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Ok(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+}
+
+func TestClient_AppAuthentication_MultipleKeys(t *testing.T) {
+	defer disableSSLVerification()()
+	testServer, err := testdata.GithubAppTestServer(t)
+	Ok(t, err)
+
+	appCreds := &github.AppCredentials{
+		AppID:    1,
+		Key:      []byte(revokedKey(t) + testdata.PrivateKey),
+		Hostname: testServer,
+	}
+	_, err = github.New(testServer, appCreds, github.Config{}, 0, logging.NewNoopLogger(t))
+	Ok(t, err)
+
+	token, err := appCreds.GetToken()
+	Ok(t, err)
+	Assert(t, token != "", "token should not be empty")
+
+	appCreds.RotateKey([]byte(revokedKey(t)))
+	_, err = appCreds.GetToken()
+	Assert(t, err != nil, "expected an error when no key is accepted")
+}
+
+func TestClient_AppAuthentication_ReloadsKeyFiles(t *testing.T) {
+	defer disableSSLVerification()()
+	testServer, err := testdata.GithubAppTestServer(t)
+	Ok(t, err)
+
+	keyFile := filepath.Join(t.TempDir(), "app-key.pem")
+	Ok(t, os.WriteFile(keyFile, []byte(revokedKey(t)), 0600))
+	appCreds := &github.AppCredentials{
+		AppID:          1,
+		InstallationID: 1,
+		KeyFiles:       []string{keyFile},
+		Hostname:       testServer,
+	}
+	_, err = appCreds.GetToken()
+	Assert(t, err != nil, "expected an error with a revoked key")
+
+	Ok(t, os.WriteFile(keyFile, []byte(testdata.PrivateKey), 0600))
+	mtime := time.Now().Add(time.Minute)
+	Ok(t, os.Chtimes(keyFile, mtime, mtime))
+	token, err := appCreds.GetToken()
+	Ok(t, err)
+	Assert(t, token != "", "token should not be empty")
+}
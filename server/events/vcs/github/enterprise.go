@@ -0,0 +1,84 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package github
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-version"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// enterpriseFeature is a feature of the GitHub API that GitHub Enterprise
+// Server only has from a version.
+type enterpriseFeature struct {
+	name       string
+	minVersion *version.Version
+}
+
+var (
+	// featureRulesets is the rules of the branches in the GraphQL API, used to
+	// find the required status checks and workflows of repository rulesets.
+	featureRulesets = enterpriseFeature{"repository rulesets", version.Must(version.NewVersion("3.11"))}
+	// featureCommentReactions is the reactions to issue comments.
+	featureCommentReactions = enterpriseFeature{"comment reactions", version.Must(version.NewVersion("3.0"))}
+	// featureCodeScanning is the upload of SARIF reports to code scanning.
+	featureCodeScanning = enterpriseFeature{"code scanning", version.Must(version.NewVersion("3.0"))}
+	// featureMultiLineComments is the review comments spanning several lines.
+	featureMultiLineComments = enterpriseFeature{"multi-line review comments", version.Must(version.NewVersion("3.1"))}
+
+	enterpriseFeatures = []enterpriseFeature{featureRulesets, featureCommentReactions, featureCodeScanning, featureMultiLineComments}
+)
+
+// DetectEnterpriseVersion reads the version of the GitHub Enterprise Server
+// of the client so the features it doesn't have are degraded instead of
+// failing with 404s. It logs the features that are degraded. If the version
+// can't be read, the client assumes the server has every feature.
+func (g *Client) DetectEnterpriseVersion(logger logging.SimpleLogging) {
+	req, err := g.client.NewRequest("GET", "meta", nil)
+	if err != nil {
+		logger.Warn("failed to read the GitHub Enterprise Server version: %s", err)
+		return
+	}
+	var meta struct {
+		InstalledVersion string `json:"installed_version"`
+	}
+	resp, err := g.client.Do(g.ctx, req, &meta)
+	if resp != nil {
+		logger.Debug("GET /meta returned: %v", resp.StatusCode)
+	}
+	if err != nil {
+		logger.Warn("failed to read the GitHub Enterprise Server version: %s", err)
+		return
+	}
+	if meta.InstalledVersion == "" {
+		logger.Debug("GitHub server has no installed version, assuming it has every feature")
+		return
+	}
+	installed, err := version.NewVersion(meta.InstalledVersion)
+	if err != nil {
+		logger.Warn("failed to parse the GitHub Enterprise Server version %q: %s", meta.InstalledVersion, err)
+		return
+	}
+
+	g.enterpriseVersion = installed
+	logger.Info("detected GitHub Enterprise Server %s", installed)
+	for _, feature := range enterpriseFeatures {
+		if !g.supports(feature) {
+			logger.Warn("GitHub Enterprise Server %s doesn't support %s, which requires %s, so it's disabled", installed, feature.name, feature.minVersion)
+		}
+	}
+}
+
+// supports returns true if the GitHub server of the client has feature. It
+// does if it isn't a GitHub Enterprise Server or its version is unknown.
+func (g *Client) supports(feature enterpriseFeature) bool {
+	return g.enterpriseVersion == nil || g.enterpriseVersion.GreaterThanOrEqual(feature.minVersion)
+}
+
+// unsupportedError returns the error of using feature on a GitHub Enterprise
+// Server that doesn't support it.
+func (g *Client) unsupportedError(feature enterpriseFeature) error {
+	return fmt.Errorf("GitHub Enterprise Server %s doesn't support %s, which requires %s", g.enterpriseVersion, feature.name, feature.minVersion)
+}
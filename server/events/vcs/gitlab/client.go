@@ -20,6 +20,8 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -294,18 +296,81 @@ func (g *Client) PullIsApproved(logger logging.SimpleLogging, repo models.Repo,
 	}, nil
 }
 
+// GetPullApprovers returns the usernames of the users approving pull.
+func (g *Client) GetPullApprovers(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest) ([]string, error) {
+	logger.Debug("Getting approvers of GitLab merge request %d", pull.Num)
+	approvals, resp, err := g.Client.MergeRequests.GetMergeRequestApprovals(repo.FullName, pull.Num)
+	if resp != nil {
+		logger.Debug("GET /projects/%s/merge_requests/%d/approvals returned: %d", repo.FullName, pull.Num, resp.StatusCode)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var approvers []string
+	for _, approver := range approvals.ApprovedBy {
+		if approver.User != nil {
+			approvers = append(approvers, approver.User.Username)
+		}
+	}
+	return approvers, nil
+}
+
+// GetUnverifiedCommits returns the SHAs of the commits of pull whose GPG, SSH
+// or X.509 signatures GitLab didn't verify.
+func (g *Client) GetUnverifiedCommits(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest) ([]string, error) {
+	logger.Debug("Getting unverified commits of GitLab merge request %d", pull.Num)
+	var unverified []string
+	nextPage := 1
+	for {
+		commits, resp, err := g.Client.MergeRequests.GetMergeRequestCommits(repo.FullName, pull.Num, &gitlab.GetMergeRequestCommitsOptions{Page: nextPage, PerPage: 100})
+		if resp != nil {
+			logger.Debug("GET /projects/%s/merge_requests/%d/commits returned: %d", repo.FullName, pull.Num, resp.StatusCode)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, commit := range commits {
+			// The signature endpoint covers every type of signature despite
+			// its name in the client.
+			signature, sigResp, err := g.Client.Commits.GetGPGSignature(repo.FullName, commit.ID)
+			if sigResp != nil {
+				logger.Debug("GET /projects/%s/repository/commits/%s/signature returned: %d", repo.FullName, commit.ID, sigResp.StatusCode)
+			}
+			// Unsigned commits have no signature.
+			if sigResp != nil && sigResp.StatusCode == http.StatusNotFound {
+				unverified = append(unverified, commit.ID)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			// Commits made in the UI are signed by GitLab itself.
+			if signature.VerificationStatus != "verified" && signature.VerificationStatus != "verified_system" {
+				unverified = append(unverified, commit.ID)
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		nextPage = resp.NextPage
+	}
+	return unverified, nil
+}
+
 // PullIsMergeable returns true if the merge request can be merged.
 // In GitLab, there isn't a single field that tells us if the pull request is
-// mergeable so for now we check the merge_status and approvals_before_merge
-// fields.
-// In order to check if the repo required these, we'd need to make another API
-// call to get the repo settings.
-// It's also possible that GitLab implements their own "mergeable" field in
-// their API in the future.
+// mergeable so we evaluate what blocks merges ourselves:
+// - the commit statuses of the head pipeline, if the project requires
+// pipelines to succeed, except the apply statuses of this Atlantis and the
+// statuses of ignoreVCSStatusNames.
+// - the approvals, blocking discussions, draft state and merge status of the
+// merge request.
+// - the merge access levels of the target branch if it's protected, since
+// Atlantis merges with its own user.
 // See:
 // - https://gitlab.com/gitlab-org/gitlab-ee/issues/3169
 // - https://gitlab.com/gitlab-org/gitlab-ce/issues/42344
-func (g *Client) PullIsMergeable(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest, vcsstatusname string, _ []string) (models.MergeableStatus, error) {
+func (g *Client) PullIsMergeable(logger logging.SimpleLogging, repo models.Repo, pull models.PullRequest, vcsstatusname string, ignoreVCSStatusNames []string) (models.MergeableStatus, error) {
 	logger.Debug("Checking if GitLab merge request %d is mergeable", pull.Num)
 	mr, resp, err := g.Client.MergeRequests.GetMergeRequest(repo.FullName, pull.Num, nil)
 	if resp != nil {
@@ -331,25 +396,13 @@ func (g *Client) PullIsMergeable(logger logging.SimpleLogging, repo models.Repo,
 		return models.MergeableStatus{}, err
 	}
 
-	// Get Commit Statuses
-	statuses, _, err := g.Client.Commits.GetCommitStatuses(mr.ProjectID, commit, nil)
-	if resp != nil {
-		logger.Debug("GET /projects/%d/commits/%s/statuses returned: %d", mr.ProjectID, commit, resp.StatusCode)
-	}
-	if err != nil {
-		return models.MergeableStatus{}, err
-	}
-
-	for _, status := range statuses {
-		// Ignore any commit statuses with 'atlantis/apply' as prefix
-		if strings.HasPrefix(status.Name, fmt.Sprintf("%s/%s", vcsstatusname, command.Apply.String())) {
-			continue
+	if project.OnlyAllowMergeIfPipelineSucceeds {
+		res, err := g.pipelineSucceeded(logger, mr.ProjectID, commit, vcsstatusname, ignoreVCSStatusNames)
+		if err != nil {
+			return models.MergeableStatus{}, err
 		}
-		if !status.AllowFailure && project.OnlyAllowMergeIfPipelineSucceeds && status.Status != "success" {
-			return models.MergeableStatus{
-				IsMergeable: false,
-				Reason:      fmt.Sprintf("Pipeline %s has status %s", status.Name, status.Status),
-			}, nil
+		if !res.IsMergeable {
+			return res, nil
 		}
 	}
 
@@ -365,6 +418,12 @@ func (g *Client) PullIsMergeable(logger logging.SimpleLogging, repo models.Repo,
 	}
 
 	res := isMergeable(mr, project, supportsDetailedMergeStatus)
+	if res.IsMergeable {
+		res, err = g.canMergeInto(logger, mr.ProjectID, mr.TargetBranch)
+		if err != nil {
+			return models.MergeableStatus{}, err
+		}
+	}
 	if res.IsMergeable {
 		logger.Debug("Merge request is mergeable")
 	} else {
@@ -373,6 +432,142 @@ func (g *Client) PullIsMergeable(logger logging.SimpleLogging, repo models.Repo,
 	return res, nil
 }
 
+// pipelineSucceeded returns whether all the commit statuses of commit passed.
+// The apply statuses of vcsstatusname, since applying is what the check is
+// for, and the statuses of ignoreVCSStatusNames are ignored, as well as jobs
+// allowed to fail and skipped jobs, which don't fail pipelines.
+func (g *Client) pipelineSucceeded(logger logging.SimpleLogging, projectID int, commit string, vcsstatusname string, ignoreVCSStatusNames []string) (models.MergeableStatus, error) {
+	nextPage := 1
+	for {
+		statuses, resp, err := g.Client.Commits.GetCommitStatuses(projectID, commit, &gitlab.GetCommitStatusesOptions{ListOptions: gitlab.ListOptions{Page: nextPage, PerPage: 100}})
+		if resp != nil {
+			logger.Debug("GET /projects/%d/commits/%s/statuses returned: %d", projectID, commit, resp.StatusCode)
+		}
+		if err != nil {
+			return models.MergeableStatus{}, err
+		}
+		for _, status := range statuses {
+			// Ignore any commit statuses with 'atlantis/apply' as prefix
+			if strings.HasPrefix(status.Name, fmt.Sprintf("%s/%s", vcsstatusname, command.Apply.String())) {
+				continue
+			}
+			if slices.Contains(ignoreVCSStatusNames, strings.Split(status.Name, "/")[0]) {
+				continue
+			}
+			if status.AllowFailure || status.Status == "success" || status.Status == "skipped" {
+				continue
+			}
+			return models.MergeableStatus{
+				IsMergeable: false,
+				Reason:      fmt.Sprintf("Pipeline %s has status %s", status.Name, status.Status),
+			}, nil
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		nextPage = resp.NextPage
+	}
+	return models.MergeableStatus{IsMergeable: true}, nil
+}
+
+// canMergeInto returns whether the user of the client is allowed to merge into
+// branch. Unprotected branches allow anyone who can push, otherwise the user
+// must be an admin or match one of the merge access levels of the protected
+// branches matching branch, by user, group or role.
+func (g *Client) canMergeInto(logger logging.SimpleLogging, projectID int, branch string) (models.MergeableStatus, error) {
+	var protections []*gitlab.ProtectedBranch
+	nextPage := 1
+	for {
+		page, resp, err := g.Client.ProtectedBranches.ListProtectedBranches(projectID, &gitlab.ListProtectedBranchesOptions{ListOptions: gitlab.ListOptions{Page: nextPage, PerPage: 100}})
+		if resp != nil {
+			logger.Debug("GET /projects/%d/protected_branches returned: %d", projectID, resp.StatusCode)
+		}
+		if err != nil {
+			return models.MergeableStatus{}, err
+		}
+		for _, p := range page {
+			if protectedBranchMatches(p.Name, branch) {
+				protections = append(protections, p)
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		nextPage = resp.NextPage
+	}
+	if len(protections) == 0 {
+		return models.MergeableStatus{IsMergeable: true}, nil
+	}
+
+	user, resp, err := g.Client.Users.CurrentUser()
+	if resp != nil {
+		logger.Debug("GET /user returned: %d", resp.StatusCode)
+	}
+	if err != nil {
+		return models.MergeableStatus{}, err
+	}
+	if user.IsAdmin {
+		return models.MergeableStatus{IsMergeable: true}, nil
+	}
+
+	// The access level of the user is only looked up if a protection needs it.
+	accessLevel := gitlab.AccessLevelValue(-1)
+	for _, protection := range protections {
+		for _, level := range protection.MergeAccessLevels {
+			switch {
+			case level.UserID != 0:
+				if level.UserID == user.ID {
+					return models.MergeableStatus{IsMergeable: true}, nil
+				}
+			case level.GroupID != 0:
+				_, resp, err := g.Client.GroupMembers.GetInheritedGroupMember(level.GroupID, user.ID)
+				if resp != nil {
+					logger.Debug("GET /groups/%d/members/all/%d returned: %d", level.GroupID, user.ID, resp.StatusCode)
+				}
+				if resp != nil && resp.StatusCode == http.StatusNotFound {
+					continue
+				}
+				if err != nil {
+					return models.MergeableStatus{}, err
+				}
+				return models.MergeableStatus{IsMergeable: true}, nil
+			case level.AccessLevel > gitlab.NoPermissions:
+				if accessLevel < 0 {
+					member, resp, err := g.Client.ProjectMembers.GetInheritedProjectMember(projectID, user.ID)
+					if resp != nil {
+						logger.Debug("GET /projects/%d/members/all/%d returned: %d", projectID, user.ID, resp.StatusCode)
+					}
+					switch {
+					case resp != nil && resp.StatusCode == http.StatusNotFound:
+						accessLevel = gitlab.NoPermissions
+					case err != nil:
+						return models.MergeableStatus{}, err
+					default:
+						accessLevel = member.AccessLevel
+					}
+				}
+				if accessLevel >= level.AccessLevel {
+					return models.MergeableStatus{IsMergeable: true}, nil
+				}
+			}
+		}
+	}
+	return models.MergeableStatus{
+		IsMergeable: false,
+		Reason:      fmt.Sprintf("User %s is not allowed to merge into protected branch %s", user.Username, branch),
+	}, nil
+}
+
+// protectedBranchMatches returns whether branch is protected by name, the name
+// of a protected branch which may contain * wildcards, ex. release/*.
+func protectedBranchMatches(name string, branch string) bool {
+	parts := strings.Split(name, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$").MatchString(branch)
+}
+
 // gitlabIsMergeable a pure function that encapsulates the tricky logic behind determining whether a gitlab MR is mergeable
 // It doesn't make any external calls and cannot error, so is much easier to test
 func isMergeable(mr *gitlab.MergeRequest, project *gitlab.Project, supportsDetailedMergeStatus bool) models.MergeableStatus {
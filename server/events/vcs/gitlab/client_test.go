@@ -979,6 +979,15 @@ func TestClient_PullIsMergeable(t *testing.T) {
 				IsMergeable: true,
 			},
 		},
+		{
+			"other-atlantis/plan",
+			models.FailedCommitStatus,
+			gitlabServerVersions,
+			defaultMr,
+			models.MergeableStatus{
+				IsMergeable: true,
+			},
+		},
 		{
 			fmt.Sprintf("%s/plan", vcsStatusName),
 			models.SuccessCommitStatus,
@@ -1052,10 +1061,13 @@ func TestClient_PullIsMergeable(t *testing.T) {
 						case r.RequestURI == fmt.Sprintf("/api/v4/projects/%v", projectID):
 							w.WriteHeader(http.StatusOK)
 							w.Write(projectSuccess) // nolint: errcheck
-						case r.RequestURI == fmt.Sprintf("/api/v4/projects/%v/repository/commits/67cb91d3f6198189f433c045154a885784ba6977/statuses", projectID):
+						case strings.HasPrefix(r.RequestURI, fmt.Sprintf("/api/v4/projects/%v/repository/commits/67cb91d3f6198189f433c045154a885784ba6977/statuses", projectID)):
 							w.WriteHeader(http.StatusOK)
 							response := fmt.Sprintf(`[{"id":133702594,"sha":"67cb91d3f6198189f433c045154a885784ba6977","ref":"patch-1","status":"%s","name":"%s","target_url":null,"description":"ApplySuccess","created_at":"2018-12-12T18:31:57.957Z","started_at":null,"finished_at":"2018-12-12T18:31:58.480Z","allow_failure":false,"coverage":null,"author":{"id":1755902,"username":"lkysow","name":"LukeKysow","state":"active","avatar_url":"https://secure.gravatar.com/avatar/25fd57e71590fe28736624ff24d41c5f?s=80&d=identicon","web_url":"https://gitlab.com/lkysow"}}]`, c.status, c.statusName)
 							w.Write([]byte(response)) // nolint: errcheck
+						case strings.HasPrefix(r.RequestURI, fmt.Sprintf("/api/v4/projects/%v/protected_branches", projectID)):
+							w.WriteHeader(http.StatusOK)
+							w.Write([]byte("[]")) // nolint: errcheck
 						case r.RequestURI == "/api/v4/version":
 							w.WriteHeader(http.StatusOK)
 							w.Header().Set("Content-Type", "application/json")
@@ -1095,7 +1107,7 @@ func TestClient_PullIsMergeable(t *testing.T) {
 						Num:        c.mrID,
 						BaseRepo:   repo,
 						HeadCommit: "67cb91d3f6198189f433c045154a885784ba6977",
-					}, vcsStatusName, []string{})
+					}, vcsStatusName, []string{"other-atlantis"})
 
 				Ok(t, err)
 				Equals(t, c.expState, mergeable)
@@ -1104,6 +1116,116 @@ func TestClient_PullIsMergeable(t *testing.T) {
 	}
 }
 
+func TestClient_PullIsMergeable_BranchProtections(t *testing.T) {
+	cases := []struct {
+		description       string
+		statuses          string
+		protectedBranches string
+		user              string
+		projectMember     string
+		groupMember       bool
+		exp               models.MergeableStatus
+	}{
+		{
+			description:       "skipped jobs don't block",
+			statuses:          `[{"name":"lint","status":"skipped"},{"name":"test","status":"failed","allow_failure":true}]`,
+			protectedBranches: `[]`,
+			exp:               models.MergeableStatus{IsMergeable: true},
+		},
+		{
+			description:       "unprotected target branch",
+			protectedBranches: `[{"name":"main","merge_access_levels":[{"access_level":40}]}]`,
+			exp:               models.MergeableStatus{IsMergeable: true},
+		},
+		{
+			description:       "admins can merge",
+			protectedBranches: `[{"name":"patch-*","merge_access_levels":[{"access_level":60}]}]`,
+			user:              `{"id":1,"username":"atlantis","is_admin":true}`,
+			exp:               models.MergeableStatus{IsMergeable: true},
+		},
+		{
+			description:       "allowed role",
+			protectedBranches: `[{"name":"patch-1","merge_access_levels":[{"access_level":40}]}]`,
+			user:              `{"id":1,"username":"atlantis"}`,
+			projectMember:     `{"id":1,"access_level":40}`,
+			exp:               models.MergeableStatus{IsMergeable: true},
+		},
+		{
+			description:       "allowed group",
+			protectedBranches: `[{"name":"patch-1","merge_access_levels":[{"access_level":40,"group_id":7}]}]`,
+			user:              `{"id":1,"username":"atlantis"}`,
+			groupMember:       true,
+			exp:               models.MergeableStatus{IsMergeable: true},
+		},
+		{
+			description:       "allowed user",
+			protectedBranches: `[{"name":"patch-1","merge_access_levels":[{"access_level":40,"user_id":2},{"access_level":40,"user_id":1}]}]`,
+			user:              `{"id":1,"username":"atlantis"}`,
+			exp:               models.MergeableStatus{IsMergeable: true},
+		},
+		{
+			description:       "role too low",
+			protectedBranches: `[{"name":"patch-1","merge_access_levels":[{"access_level":40},{"access_level":40,"group_id":7}]}]`,
+			user:              `{"id":1,"username":"atlantis"}`,
+			projectMember:     `{"id":1,"access_level":30}`,
+			exp:               models.MergeableStatus{IsMergeable: false, Reason: "User atlantis is not allowed to merge into protected branch patch-1"},
+		},
+		{
+			description:       "no one allowed",
+			protectedBranches: `[{"name":"patch-*","merge_access_levels":[{"access_level":0}]}]`,
+			user:              `{"id":1,"username":"atlantis"}`,
+			exp:               models.MergeableStatus{IsMergeable: false, Reason: "User atlantis is not allowed to merge into protected branch patch-1"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/api/v4/projects/runatlantis/atlantis/merge_requests/1":
+					w.Write(mustReadFile(t, "testdata/pipeline-success.json")) // nolint: errcheck
+				case r.URL.Path == fmt.Sprintf("/api/v4/projects/%v", projectID):
+					w.Write(mustReadFile(t, "testdata/project-success.json")) // nolint: errcheck
+				case r.URL.Path == fmt.Sprintf("/api/v4/projects/%v/repository/commits/67cb91d3f6198189f433c045154a885784ba6977/statuses", projectID):
+					statuses := c.statuses
+					if statuses == "" {
+						statuses = `[{"name":"atlantis/plan","status":"success"}]`
+					}
+					w.Write([]byte(statuses)) // nolint: errcheck
+				case r.URL.Path == fmt.Sprintf("/api/v4/projects/%v/protected_branches", projectID):
+					w.Write([]byte(c.protectedBranches)) // nolint: errcheck
+				case r.URL.Path == "/api/v4/user" && c.user != "":
+					w.Write([]byte(c.user)) // nolint: errcheck
+				case r.URL.Path == fmt.Sprintf("/api/v4/projects/%v/members/all/1", projectID) && c.projectMember != "":
+					w.Write([]byte(c.projectMember)) // nolint: errcheck
+				case r.URL.Path == "/api/v4/groups/7/members/all/1":
+					if !c.groupMember {
+						http.Error(w, `{"message":"404 Not found"}`, http.StatusNotFound)
+						return
+					}
+					w.Write([]byte(`{"id":1,"access_level":30}`)) // nolint: errcheck
+				case r.URL.Path == "/api/v4/version":
+					w.Write([]byte(`{"version":"15.8.3-ee"}`)) // nolint: errcheck
+				case r.URL.Path == "/api/v4/":
+					// Rate limiter requests.
+				default:
+					t.Errorf("got unexpected request at %q", r.RequestURI)
+					http.Error(w, "not found", http.StatusNotFound)
+				}
+			}))
+			defer testServer.Close()
+
+			internalClient, err := gitlab.NewClient("token", gitlab.WithBaseURL(testServer.URL))
+			Ok(t, err)
+			client := &Client{Client: internalClient}
+			repo := models.Repo{FullName: "runatlantis/atlantis", Owner: "runatlantis", Name: "atlantis"}
+
+			mergeable, err := client.PullIsMergeable(logging.NewNoopLogger(t), repo, models.PullRequest{Num: 1, BaseRepo: repo}, "atlantis", nil)
+			Ok(t, err)
+			Equals(t, c.exp, mergeable)
+		})
+	}
+}
+
 func TestClient_gitlabIsMergeable(t *testing.T) {
 	// Test the helper gitlabIsMergeable directly
 
@@ -1450,6 +1572,61 @@ func TestClient_GetPullLabels(t *testing.T) {
 	Equals(t, []string{"work in progress"}, labels)
 }
 
+func TestClient_GetPullApprovers(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	testServer := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.RequestURI {
+			case "/api/v4/projects/runatlantis%2Fatlantis/merge_requests/1/approvals":
+				w.Write([]byte(`{"approved_by":[{"user":{"username":"alice"}},{"user":{"username":"bob"}}]}`)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+
+	internalClient, err := gitlab.NewClient("token", gitlab.WithBaseURL(testServer.URL))
+	Ok(t, err)
+	client := &Client{
+		Client:  internalClient,
+		Version: nil,
+	}
+
+	approvers, err := client.GetPullApprovers(logger, models.Repo{FullName: "runatlantis/atlantis"}, models.PullRequest{Num: 1})
+	Ok(t, err)
+	Equals(t, []string{"alice", "bob"}, approvers)
+}
+
+func TestClient_GetUnverifiedCommits(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	testServer := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.RequestURI {
+			case "/api/v4/projects/runatlantis%2Fatlantis/merge_requests/1/commits?page=1&per_page=100":
+				w.Write([]byte(`[{"id":"aaa1111"},{"id":"bbb2222"},{"id":"ccc3333"},{"id":"ddd4444"}]`)) // nolint: errcheck
+			case "/api/v4/projects/runatlantis%2Fatlantis/repository/commits/aaa1111/signature":
+				w.Write([]byte(`{"signature_type":"SSH","verification_status":"verified"}`)) // nolint: errcheck
+			case "/api/v4/projects/runatlantis%2Fatlantis/repository/commits/bbb2222/signature":
+				w.Write([]byte(`{"signature_type":"PGP","verification_status":"unverified_key"}`)) // nolint: errcheck
+			case "/api/v4/projects/runatlantis%2Fatlantis/repository/commits/ddd4444/signature":
+				w.Write([]byte(`{"signature_type":"SSH","verification_status":"verified_system"}`)) // nolint: errcheck
+			default:
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+
+	internalClient, err := gitlab.NewClient("token", gitlab.WithBaseURL(testServer.URL))
+	Ok(t, err)
+	client := &Client{
+		Client:  internalClient,
+		Version: nil,
+	}
+
+	unverified, err := client.GetUnverifiedCommits(logger, models.Repo{FullName: "runatlantis/atlantis"}, models.PullRequest{Num: 1})
+	Ok(t, err)
+	Equals(t, []string{"bbb2222", "ccc3333"}, unverified)
+}
+
 func TestClient_GetPullLabels_EmptyResponse(t *testing.T) {
 	logger := logging.NewNoopLogger(t)
 	pipelineSuccess, err := os.ReadFile("testdata/pipeline-success.json")
@@ -14,7 +14,10 @@
 package vcs
 
 import (
+	"fmt"
+
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/timeline"
 	"github.com/runatlantis/atlantis/server/logging"
 )
 
@@ -24,9 +27,28 @@ type ClientProxy struct {
 	// clients maps from the vcs host type to the client that implements the
 	// api for that host type, ex. github -> github client.
 	clients map[models.VCSHostType]Client
+	// Timeline records the comments posted on pull requests. It may be nil.
+	Timeline *timeline.Store
+	// RunURLs generates the links of the run IDs in the footers of comments.
+	// It may be nil.
+	RunURLs RunURLGenerator
+	// PullURLs generates the links to the pages of the pull requests in the
+	// footers of comments. It may be nil.
+	PullURLs PullURLGenerator
+}
+
+// RunURLGenerator generates the URL of the page of a run, see logging.RunID.
+type RunURLGenerator interface {
+	GenerateRunURL(runID string) string
 }
 
-func NewClientProxy(githubClient Client, gitlabClient Client, bitbucketCloudClient Client, bitbucketServerClient Client, azuredevopsClient Client, giteaClient Client) *ClientProxy {
+// PullURLGenerator generates the URL of the page aggregating the projects of
+// a pull request.
+type PullURLGenerator interface {
+	GeneratePullURL(repo models.Repo, pullNum int) string
+}
+
+func NewClientProxy(githubClient Client, gitlabClient Client, bitbucketCloudClient Client, bitbucketServerClient Client, azuredevopsClient Client, giteaClient Client, gerritClient Client, codecommitClient Client) *ClientProxy {
 	if githubClient == nil {
 		githubClient = &NotConfiguredVCSClient{}
 	}
@@ -45,6 +67,12 @@ func NewClientProxy(githubClient Client, gitlabClient Client, bitbucketCloudClie
 	if giteaClient == nil {
 		giteaClient = &NotConfiguredVCSClient{}
 	}
+	if gerritClient == nil {
+		gerritClient = &NotConfiguredVCSClient{}
+	}
+	if codecommitClient == nil {
+		codecommitClient = &NotConfiguredVCSClient{}
+	}
 	return &ClientProxy{
 		clients: map[models.VCSHostType]Client{
 			models.Github:          githubClient,
@@ -53,6 +81,8 @@ func NewClientProxy(githubClient Client, gitlabClient Client, bitbucketCloudClie
 			models.BitbucketServer: bitbucketServerClient,
 			models.AzureDevops:     azuredevopsClient,
 			models.Gitea:           giteaClient,
+			models.Gerrit:          gerritClient,
+			models.CodeCommit:      codecommitClient,
 		},
 	}
 }
@@ -62,7 +92,35 @@ func (d *ClientProxy) GetModifiedFiles(logger logging.SimpleLogging, repo models
 }
 
 func (d *ClientProxy) CreateComment(logger logging.SimpleLogging, repo models.Repo, pullNum int, comment string, command string) error {
-	return d.clients[repo.VCSHost.Type].CreateComment(logger, repo, pullNum, comment, command)
+	comment += d.runFooter(logger, repo, pullNum)
+	if err := d.clients[repo.VCSHost.Type].CreateComment(logger, repo, pullNum, comment, command); err != nil {
+		return err
+	}
+	description := "Posted a comment"
+	if command != "" {
+		description = fmt.Sprintf("Posted the comment of the %s command", command)
+	}
+	d.Timeline.Record(repo.FullName, pullNum, timeline.Event{Type: timeline.Comment, Description: description})
+	return nil
+}
+
+// runFooter returns the footer of the comments of the run logger logs for,
+// ex. "run: abc12345" linking to its jobs, so the logs and jobs of a comment
+// can be found from it, followed by a link to the page of the pull request if
+// there's a URL generator for it. It's "" if there's no run.
+func (d *ClientProxy) runFooter(logger logging.SimpleLogging, repo models.Repo, pullNum int) string {
+	runID := logging.RunID(logger)
+	if runID == "" {
+		return ""
+	}
+	footer := fmt.Sprintf("\n\nrun: `%s`", runID)
+	if d.RunURLs != nil {
+		footer = fmt.Sprintf("\n\nrun: [`%s`](%s)", runID, d.RunURLs.GenerateRunURL(runID))
+	}
+	if d.PullURLs != nil {
+		footer += fmt.Sprintf(" · [all projects](%s)", d.PullURLs.GeneratePullURL(repo, pullNum))
+	}
+	return footer
 }
 
 func (d *ClientProxy) HidePrevCommandComments(logger logging.SimpleLogging, repo models.Repo, pullNum int, command string, dir string) error {
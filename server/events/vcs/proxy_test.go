@@ -0,0 +1,54 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vcs_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+type runURLs struct{}
+
+func (runURLs) GenerateRunURL(runID string) string {
+	return "https://atlantis.example.com/runs/" + runID
+}
+
+type pullURLs struct{}
+
+func (pullURLs) GeneratePullURL(repo models.Repo, pullNum int) string {
+	return fmt.Sprintf("https://atlantis.example.com/pull?repo=%s&pull=%d", repo.FullName, pullNum)
+}
+
+func TestClientProxy_CreateComment_RunFooter(t *testing.T) {
+	RegisterMockTestingT(t)
+	client := mocks.NewMockClient()
+	proxy := vcs.NewClientProxy(client, nil, nil, nil, nil, nil, nil, nil)
+	repo := models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Type: models.Github}}
+
+	t.Log("comments without a run have no footer")
+	logger := logging.NewNoopLogger(t)
+	proxy.CreateComment(logger, repo, 1, "comment", "plan") // nolint: errcheck
+	client.VerifyWasCalledOnce().CreateComment(logger, repo, 1, "comment", "plan")
+
+	logger = logger.With(logging.CorrelationIDKey, "0a1b2c3d-4e5f-6789-abcd-ef0123456789")
+	proxy.CreateComment(logger, repo, 1, "comment", "plan") // nolint: errcheck
+	client.VerifyWasCalledOnce().CreateComment(logger, repo, 1, "comment\n\nrun: `0a1b2c3d`", "plan")
+
+	t.Log("the run ID links to the run if there's a URL generator")
+	proxy.RunURLs = runURLs{}
+	proxy.CreateComment(logger, repo, 1, "comment", "plan") // nolint: errcheck
+	client.VerifyWasCalledOnce().CreateComment(logger, repo, 1, "comment\n\nrun: [`0a1b2c3d`](https://atlantis.example.com/runs/0a1b2c3d)", "plan")
+
+	t.Log("the footer links to the page of the pull request if there's a URL generator")
+	proxy.PullURLs = pullURLs{}
+	proxy.CreateComment(logger, repo, 2, "comment", "plan") // nolint: errcheck
+	client.VerifyWasCalledOnce().CreateComment(logger, repo, 2,
+		"comment\n\nrun: [`0a1b2c3d`](https://atlantis.example.com/runs/0a1b2c3d) · [all projects](https://atlantis.example.com/pull?repo=owner/repo&pull=2)", "plan")
+}
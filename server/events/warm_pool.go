@@ -0,0 +1,242 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/runatlantis/atlantis/server/core/config"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	tally "github.com/uber-go/tally/v4"
+)
+
+// warmPoolDir is the dir of the data dir the warm pools are kept in.
+const warmPoolDir = "warm"
+
+// WarmPool keeps pools of working dirs of busy repos cloned and initialized
+// on the base branches of their pull requests, so a clone only has to fetch
+// and merge the latest changes into one of them. Pools are kept for the base
+// branches pull requests were cloned from. It's only used with the merge
+// checkout strategy.
+type WarmPool struct {
+	DataDir string
+	// Sizes is how many working dirs are kept warm per base branch of each
+	// repo, by repo full name.
+	Sizes map[string]int
+	// CheckoutDepth is how many commits of the base branch are cloned. 0
+	// means all of them.
+	CheckoutDepth int
+	// Init, if set, initializes the projects of the working dirs once they're
+	// cloned, ex. with terraform init.
+	Init func(logger logging.SimpleLogging, repo models.Repo, branch string, dir string) error
+	// DiskMonitor, if set, stops the pools from being topped up while the disk
+	// is over its eviction threshold, since their working dirs would be
+	// evicted.
+	DiskMonitor *DiskMonitor
+	Logger      logging.SimpleLogging
+	StatsScope  tally.Scope
+
+	mutex sync.Mutex
+	// branches are the base branches pull requests were cloned from, by pool
+	// dir, along with their latest repo whose clone URL has up to date
+	// credentials.
+	branches map[string]warmBranch
+	// filling is how many working dirs of each pool dir are being warmed.
+	filling map[string]int
+	wg      sync.WaitGroup
+}
+
+type warmBranch struct {
+	repo   models.Repo
+	branch string
+}
+
+// Take moves a warm working dir of the branch of repo to dest, returning
+// false if none is ready. It then warms another one in the background.
+func (p *WarmPool) Take(logger logging.SimpleLogging, repo models.Repo, branch string, dest string) bool {
+	if p.Sizes[repo.FullName] <= 0 {
+		return false
+	}
+	scope := p.StatsScope.SubScope("warm_pool")
+	pool := p.poolDir(repo, branch)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.branches == nil {
+		p.branches = map[string]warmBranch{}
+		p.filling = map[string]int{}
+	}
+	p.branches[pool] = warmBranch{repo: repo, branch: branch}
+	ready, err := p.readyDirs(pool)
+	if err != nil {
+		logger.Warn("unable to list the warm working dirs of %s: %s", repo.FullName, err)
+	}
+	taken := false
+	if len(ready) > 0 {
+		if err := os.Rename(ready[0], dest); err != nil {
+			logger.Warn("unable to take the warm working dir %s: %s", ready[0], err)
+		} else {
+			ready = ready[1:]
+			taken = true
+		}
+	}
+	p.fill(pool, len(ready))
+	if !taken {
+		scope.Counter("misses").Inc(1)
+		logger.Info("no warm working dir of %s is ready on %s", repo.FullName, branch)
+		return false
+	}
+	scope.Counter("hits").Inc(1)
+	logger.Info("using a warm working dir of %s on %s", repo.FullName, branch)
+	return true
+}
+
+// Run tops up the pools and deletes the working dirs left half warmed, ex.
+// by a restart. It's run periodically.
+func (p *WarmPool) Run() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	leftovers, err := filepath.Glob(filepath.Join(p.DataDir, warmPoolDir, "*", "*", "*", "warming-*"))
+	if err != nil {
+		p.Logger.Warn("unable to list the working dirs being warmed: %s", err)
+	}
+	for _, dir := range leftovers {
+		if p.filling[filepath.Dir(dir)] > 0 {
+			continue
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			p.Logger.Warn("unable to delete the half warmed working dir %s: %s", dir, err)
+		}
+	}
+	if p.DiskMonitor != nil && p.DiskMonitor.OverEvictionThreshold() {
+		p.Logger.Warn("not warming working dirs since the disk is over the eviction threshold")
+		return
+	}
+	for pool := range p.branches {
+		ready, err := p.readyDirs(pool)
+		if err != nil {
+			p.Logger.Warn("unable to list the warm working dirs in %s: %s", pool, err)
+			continue
+		}
+		p.fill(pool, len(ready))
+	}
+}
+
+// Wait waits for the working dirs being warmed.
+func (p *WarmPool) Wait() {
+	p.wg.Wait()
+}
+
+// fill warms working dirs in the background until the pool has as many as its
+// size. It's called with the mutex locked.
+func (p *WarmPool) fill(pool string, ready int) {
+	b := p.branches[pool]
+	for missing := p.Sizes[b.repo.FullName] - ready - p.filling[pool]; missing > 0; missing-- {
+		p.filling[pool]++
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			err := p.warm(b, pool)
+			p.mutex.Lock()
+			p.filling[pool]--
+			p.mutex.Unlock()
+			if err != nil {
+				p.Logger.Warn("unable to warm a working dir of %s on %s: %s", b.repo.FullName, b.branch, err)
+			}
+		}()
+	}
+}
+
+// warm clones and initializes a working dir in pool, only making it ready
+// once it's complete.
+func (p *WarmPool) warm(b warmBranch, pool string) error {
+	if err := os.MkdirAll(pool, 0700); err != nil {
+		return err
+	}
+	dir := filepath.Join(pool, fmt.Sprintf("warming-%d", time.Now().UnixNano()))
+	args := []string{"clone"}
+	if p.CheckoutDepth > 0 {
+		args = append(args, "--depth", fmt.Sprint(p.CheckoutDepth))
+	}
+	args = append(args, "--branch", b.branch, "--single-branch", b.repo.CloneURL, dir)
+	if output, err := exec.Command("git", args...).CombinedOutput(); err != nil { // nolint: gosec
+		os.RemoveAll(dir) // nolint: errcheck
+		sanitize := func(s string) string { return strings.ReplaceAll(s, b.repo.CloneURL, b.repo.SanitizedCloneURL) }
+		return fmt.Errorf("cloning: %s: %s", sanitize(string(output)), sanitize(err.Error()))
+	}
+	if p.Init != nil {
+		if err := p.Init(p.Logger, b.repo, b.branch, dir); err != nil {
+			os.RemoveAll(dir) // nolint: errcheck
+			return fmt.Errorf("initializing: %w", err)
+		}
+	}
+	return os.Rename(dir, filepath.Join(pool, fmt.Sprintf("ready-%d", time.Now().UnixNano())))
+}
+
+// readyDirs lists the warm working dirs of pool, the oldest first.
+func (p *WarmPool) readyDirs(pool string) ([]string, error) {
+	dirs, err := filepath.Glob(filepath.Join(pool, "ready-*"))
+	sort.Strings(dirs)
+	return dirs, err
+}
+
+func (p *WarmPool) poolDir(repo models.Repo, branch string) string {
+	return filepath.Join(p.DataDir, warmPoolDir, repo.FullName, url.PathEscape(branch))
+}
+
+// WarmTerraformClient runs terraform in the Atlantis process.
+type WarmTerraformClient interface {
+	DetectVersion(log logging.SimpleLogging, projectDirectory string) *version.Version
+	RunInProcess(log logging.SimpleLogging, path string, args []string, v *version.Version) (string, error)
+}
+
+// TerraformWarmer initializes the projects of the repo config of warm
+// working dirs with terraform init, downloading their providers and modules.
+// Their backends aren't initialized since the workflows of the projects may
+// configure them.
+type TerraformWarmer struct {
+	ParserValidator *config.ParserValidator
+	// GlobalCfg is a pointer since the server-side repo config is reloaded.
+	GlobalCfg       *valid.GlobalCfg
+	TerraformClient WarmTerraformClient
+}
+
+// Init is a WarmPool.Init.
+func (t *TerraformWarmer) Init(logger logging.SimpleLogging, repo models.Repo, branch string, dir string) error {
+	repoCfg, err := t.ParserValidator.ParseRepoCfg(dir, *t.GlobalCfg, repo.ID(), branch)
+	if errors.Is(err, os.ErrNotExist) {
+		// The projects are discovered when planning.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, project := range repoCfg.Projects {
+		if project.GetAgentPool() != "" {
+			// Its commands aren't run in this working dir.
+			continue
+		}
+		projectDir := filepath.Join(dir, project.Dir)
+		v := project.TerraformVersion
+		if v == nil {
+			v = t.TerraformClient.DetectVersion(logger, projectDir)
+		}
+		if output, err := t.TerraformClient.RunInProcess(logger, projectDir, []string{"init", "-input=false", "-backend=false"}, v); err != nil {
+			return fmt.Errorf("in %s: %s: %w", project.Dir, output, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+	tally "github.com/uber-go/tally/v4"
+)
+
+func TestClone_WarmPool(t *testing.T) {
+	repoDir := initRepo(t)
+	dataDir := t.TempDir()
+	logger := logging.NewNoopLogger(t)
+	repo := models.Repo{FullName: "owner/repo", CloneURL: fmt.Sprintf("file://%s", repoDir)}
+
+	scope := tally.NewTestScope("", nil)
+	pool := &events.WarmPool{
+		DataDir: dataDir,
+		Sizes:   map[string]int{"owner/repo": 1},
+		// Stands in for terraform init.
+		Init: func(_ logging.SimpleLogging, _ models.Repo, _ string, dir string) error {
+			return os.WriteFile(filepath.Join(dir, ".terraform"), []byte("initialized"), 0600)
+		},
+		Logger:     logger,
+		StatsScope: scope,
+	}
+	t.Cleanup(pool.Wait)
+	wd := &events.FileWorkspace{
+		DataDir:             dataDir,
+		CheckoutMerge:       true,
+		GpgNoSigningEnabled: true,
+		WarmPool:            pool,
+	}
+	clone := func(num int) string {
+		cloneDir, err := wd.Clone(logger, repo, models.PullRequest{
+			BaseRepo:   repo,
+			Num:        num,
+			HeadBranch: "branch",
+			BaseBranch: "main",
+		}, "default")
+		Ok(t, err)
+		return cloneDir
+	}
+
+	t.Log("the first clone warms the pool of its base branch")
+	cloneDir := clone(1)
+	_, err := os.Stat(filepath.Join(cloneDir, ".terraform"))
+	Assert(t, os.IsNotExist(err), "exp the first clone not to be warm")
+	pool.Wait()
+
+	t.Log("the next clone brings the warm working dir up to date and merges the pull request into it")
+	runCmd(t, repoDir, "git", "commit", "--allow-empty", "-m", "main commit")
+	runCmd(t, repoDir, "git", "checkout", "branch")
+	runCmd(t, repoDir, "touch", "branch-file")
+	runCmd(t, repoDir, "git", "add", "branch-file")
+	runCmd(t, repoDir, "git", "commit", "-m", "branch commit")
+	cloneDir = clone(2)
+	initialized, err := os.ReadFile(filepath.Join(cloneDir, ".terraform"))
+	Ok(t, err)
+	Equals(t, "initialized", string(initialized))
+	Equals(t, runCmd(t, repoDir, "git", "rev-parse", "main"), runCmd(t, cloneDir, "git", "rev-parse", "HEAD^1"))
+	Equals(t, runCmd(t, repoDir, "git", "rev-parse", "branch"), runCmd(t, cloneDir, "git", "rev-parse", "HEAD^2"))
+
+	pool.Wait()
+	ready, err := filepath.Glob(filepath.Join(dataDir, "warm", "owner", "repo", "main", "ready-*"))
+	Ok(t, err)
+	Equals(t, 1, len(ready))
+	counters := scope.Snapshot().Counters()
+	Equals(t, int64(1), counters["warm_pool.hits+"].Value())
+	Equals(t, int64(1), counters["warm_pool.misses+"].Value())
+}
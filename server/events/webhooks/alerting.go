@@ -0,0 +1,214 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+const (
+	// DefaultPagerDutyURL is the PagerDuty Events API v2 endpoint.
+	DefaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+	// DefaultOpsgenieURL is the Opsgenie alert API endpoint. EU accounts
+	// should use https://api.eu.opsgenie.com/v2/alerts instead.
+	DefaultOpsgenieURL = "https://api.opsgenie.com/v2/alerts"
+)
+
+// alertDedupKey returns the key used to deduplicate alerts for the project
+// a result is for so that a later successful apply resolves the alert
+// raised by a failed one. Alert events are deduplicated by their rule.
+func alertDedupKey(applyResult ApplyResult) string {
+	if applyResult.EventName() == AlertEvent {
+		return "atlantis/alert/" + applyResult.Alert
+	}
+	project := applyResult.ProjectName
+	if project == "" {
+		project = applyResult.Directory
+	}
+	return fmt.Sprintf("atlantis/%s/%s/%s", applyResult.Repo.FullName, project, applyResult.Workspace)
+}
+
+func alertSummary(applyResult ApplyResult) string {
+	if applyResult.EventName() == AlertEvent {
+		return "Atlantis: " + eventTitle(applyResult)
+	}
+	project := applyResult.ProjectName
+	if project == "" {
+		project = applyResult.Directory
+	}
+	return fmt.Sprintf("Atlantis apply failed for %s (project: %s, workspace: %s)", applyResult.Repo.FullName, project, applyResult.Workspace)
+}
+
+func alertDetails(applyResult ApplyResult) map[string]string {
+	return map[string]string{
+		"repo":      applyResult.Repo.FullName,
+		"project":   applyResult.ProjectName,
+		"directory": applyResult.Directory,
+		"workspace": applyResult.Workspace,
+		"branch":    applyResult.Pull.BaseBranch,
+		"user":      applyResult.User.Username,
+		"pull":      applyResult.Pull.URL,
+	}
+}
+
+// PagerDutyWebhook triggers a PagerDuty incident when an apply fails and
+// resolves it when a later apply of the same project succeeds. Alerts trigger
+// and resolve incidents the same way.
+type PagerDutyWebhook struct {
+	Client         *HttpClient
+	WorkspaceRegex *regexp.Regexp
+	BranchRegex    *regexp.Regexp
+	URL            string
+	RoutingKey     string
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+	Links       []pagerDutyLink   `json:"links,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Component     string            `json:"component,omitempty"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+type pagerDutyLink struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+// Send triggers or resolves the incident if workspace and branch matches their respective regex.
+func (p *PagerDutyWebhook) Send(_ logging.SimpleLogging, applyResult ApplyResult) error {
+	if !p.WorkspaceRegex.MatchString(applyResult.Workspace) || !p.BranchRegex.MatchString(applyResult.Pull.BaseBranch) {
+		return nil
+	}
+	event := pagerDutyEvent{
+		RoutingKey:  p.RoutingKey,
+		EventAction: "resolve",
+		DedupKey:    alertDedupKey(applyResult),
+	}
+	if !applyResult.Success {
+		event.EventAction = "trigger"
+		event.Payload = &pagerDutyPayload{
+			Summary:       alertSummary(applyResult),
+			Source:        "atlantis",
+			Severity:      "error",
+			Component:     applyResult.Repo.FullName,
+			CustomDetails: alertDetails(applyResult),
+		}
+		if applyResult.Pull.URL != "" {
+			event.Links = []pagerDutyLink{{Href: applyResult.Pull.URL, Text: "Pull request"}}
+		}
+	}
+	if err := postAlertJSON(p.Client, p.URL, nil, event); err != nil {
+		return fmt.Errorf("sending pagerduty event: %w", err)
+	}
+	return nil
+}
+
+// OpsgenieWebhook creates an Opsgenie alert when an apply fails and closes
+// it when a later apply of the same project succeeds. Alerts create and close
+// Opsgenie alerts the same way.
+type OpsgenieWebhook struct {
+	Client         *HttpClient
+	WorkspaceRegex *regexp.Regexp
+	BranchRegex    *regexp.Regexp
+	URL            string
+	APIKey         string
+}
+
+type opsgenieAlert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description,omitempty"`
+	Source      string            `json:"source"`
+	Priority    string            `json:"priority"`
+	Details     map[string]string `json:"details,omitempty"`
+}
+
+type opsgenieClose struct {
+	Source string `json:"source"`
+	Note   string `json:"note,omitempty"`
+}
+
+// Send creates or closes the alert if workspace and branch matches their respective regex.
+func (o *OpsgenieWebhook) Send(_ logging.SimpleLogging, applyResult ApplyResult) error {
+	if !o.WorkspaceRegex.MatchString(applyResult.Workspace) || !o.BranchRegex.MatchString(applyResult.Pull.BaseBranch) {
+		return nil
+	}
+	headers := map[string][]string{"Authorization": {"GenieKey " + o.APIKey}}
+	alias := alertDedupKey(applyResult)
+	var err error
+	if applyResult.Success {
+		note := fmt.Sprintf("Apply succeeded by %s", applyResult.User.Username)
+		if applyResult.EventName() == AlertEvent {
+			note = eventTitle(applyResult)
+		}
+		closeURL := fmt.Sprintf("%s/%s/close?identifierType=alias", strings.TrimSuffix(o.URL, "/"), url.PathEscape(alias))
+		err = postAlertJSON(o.Client, closeURL, headers, opsgenieClose{
+			Source: "atlantis",
+			Note:   note,
+		})
+	} else {
+		err = postAlertJSON(o.Client, o.URL, headers, opsgenieAlert{
+			Message:     alertSummary(applyResult),
+			Alias:       alias,
+			Description: applyResult.Pull.URL,
+			Source:      "atlantis",
+			Priority:    "P2",
+			Details:     alertDetails(applyResult),
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("sending opsgenie alert: %w", err)
+	}
+	return nil
+}
+
+func postAlertJSON(client *HttpClient, endpoint string, headers map[string][]string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for header, values := range headers {
+		for _, value := range values {
+			req.Header.Add(header, value)
+		}
+	}
+	httpClient := http.DefaultClient
+	if client != nil && client.Client != nil {
+		httpClient = client.Client
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// PagerDuty, Opsgenie and Datadog respond with 202 Accepted.
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("returned status code %d with response %q", resp.StatusCode, respBody)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestPagerDutyWebhook_TriggerAndResolve(t *testing.T) {
+	var events []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event map[string]any
+		Ok(t, json.NewDecoder(r.Body).Decode(&event))
+		events = append(events, event)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	webhook := webhooks.PagerDutyWebhook{
+		Client:         &webhooks.HttpClient{Client: http.DefaultClient},
+		WorkspaceRegex: regexp.MustCompile(".*"),
+		BranchRegex:    regexp.MustCompile(".*"),
+		URL:            server.URL,
+		RoutingKey:     "routing-key",
+	}
+	failed := httpApplyResult
+	failed.Success = false
+	failed.ProjectName = "prod"
+	Ok(t, webhook.Send(logging.NewNoopLogger(t), failed))
+
+	succeeded := failed
+	succeeded.Success = true
+	Ok(t, webhook.Send(logging.NewNoopLogger(t), succeeded))
+
+	Equals(t, 2, len(events))
+	Equals(t, "trigger", events[0]["event_action"])
+	Equals(t, "routing-key", events[0]["routing_key"])
+	Equals(t, "atlantis/runatlantis/atlantis/prod/production", events[0]["dedup_key"])
+	Equals(t, "error", events[0]["payload"].(map[string]any)["severity"])
+	Equals(t, "resolve", events[1]["event_action"])
+	Equals(t, events[0]["dedup_key"], events[1]["dedup_key"])
+	Equals(t, nil, events[1]["payload"])
+}
+
+func TestPagerDutyWebhook_Alert(t *testing.T) {
+	var events []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event map[string]any
+		Ok(t, json.NewDecoder(r.Body).Decode(&event))
+		events = append(events, event)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	webhook := webhooks.PagerDutyWebhook{
+		Client:         &webhooks.HttpClient{Client: http.DefaultClient},
+		WorkspaceRegex: regexp.MustCompile(".*"),
+		BranchRegex:    regexp.MustCompile(".*"),
+		URL:            server.URL,
+		RoutingKey:     "routing-key",
+	}
+	alert := webhooks.ApplyResult{Event: webhooks.AlertEvent, Alert: "apply-failures", Summary: "apply_failure_rate > 0.2: 3 of 4 applies failed in the last 10m0s"}
+	Ok(t, webhook.Send(logging.NewNoopLogger(t), alert))
+	alert.Success = true
+	Ok(t, webhook.Send(logging.NewNoopLogger(t), alert))
+
+	Equals(t, 2, len(events))
+	Equals(t, "trigger", events[0]["event_action"])
+	Equals(t, "atlantis/alert/apply-failures", events[0]["dedup_key"])
+	Equals(t, "Atlantis: Alert apply-failures firing: apply_failure_rate > 0.2: 3 of 4 applies failed in the last 10m0s", events[0]["payload"].(map[string]any)["summary"])
+	Equals(t, "resolve", events[1]["event_action"])
+	Equals(t, events[0]["dedup_key"], events[1]["dedup_key"])
+}
+
+func TestOpsgenieWebhook_CreateAndClose(t *testing.T) {
+	var paths []string
+	var alert map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Equals(t, "GenieKey api-key", r.Header.Get("Authorization"))
+		paths = append(paths, r.URL.RequestURI())
+		if alert == nil {
+			Ok(t, json.NewDecoder(r.Body).Decode(&alert))
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	webhook := webhooks.OpsgenieWebhook{
+		Client:         &webhooks.HttpClient{Client: http.DefaultClient},
+		WorkspaceRegex: regexp.MustCompile(".*"),
+		BranchRegex:    regexp.MustCompile(".*"),
+		URL:            server.URL + "/v2/alerts",
+		APIKey:         "api-key",
+	}
+	failed := httpApplyResult
+	failed.Success = false
+	failed.Directory = "."
+	Ok(t, webhook.Send(logging.NewNoopLogger(t), failed))
+
+	succeeded := failed
+	succeeded.Success = true
+	Ok(t, webhook.Send(logging.NewNoopLogger(t), succeeded))
+
+	Equals(t, "atlantis/runatlantis/atlantis/./production", alert["alias"])
+	Equals(t, []string{
+		"/v2/alerts",
+		"/v2/alerts/atlantis%2Frunatlantis%2Fatlantis%2F.%2Fproduction/close?identifierType=alias",
+	}, paths)
+}
+
+func TestNewWebhooksManager_AlertingRequiresApplyAndKey(t *testing.T) {
+	configs := []webhooks.Config{{Event: webhooks.PlanEvent, Kind: webhooks.PagerDutyKind, IntegrationKey: "key"}}
+	_, err := webhooks.NewMultiWebhookSender(configs, validClients())
+	ErrEquals(t, "\"kind: pagerduty\" only supports \"event: apply\" and \"event: alert\"", err)
+
+	configs = []webhooks.Config{{Event: webhooks.AlertEvent, Kind: webhooks.PagerDutyKind, IntegrationKey: "key"}}
+	_, err = webhooks.NewMultiWebhookSender(configs, validClients())
+	Ok(t, err)
+
+	configs = []webhooks.Config{{Event: webhooks.ApplyEvent, Kind: webhooks.OpsgenieKind}}
+	_, err = webhooks.NewMultiWebhookSender(configs, validClients())
+	ErrEquals(t, "must specify \"integration-key\" if using a webhook of \"kind: opsgenie\"", err)
+}
@@ -0,0 +1,115 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+const (
+	cloudEventsSpecVersion = "1.0"
+	cloudEventsContentType = "application/cloudevents+json"
+	// CloudEventsTypePrefix prefixes the event name to form the CloudEvents
+	// type, ex. io.runatlantis.plan.
+	CloudEventsTypePrefix = "io.runatlantis."
+)
+
+// CloudEvent is a CloudEvents v1.0 event in structured content mode.
+// See https://github.com/cloudevents/spec/blob/v1.0.2/cloudevents/spec.md.
+type CloudEvent struct {
+	SpecVersion     string      `json:"specversion"`
+	ID              string      `json:"id"`
+	Source          string      `json:"source"`
+	Type            string      `json:"type"`
+	Subject         string      `json:"subject,omitempty"`
+	Time            time.Time   `json:"time"`
+	DataContentType string      `json:"datacontenttype"`
+	Data            ApplyResult `json:"data"`
+}
+
+// CloudEventsWebhook sends every event as a CloudEvent to any HTTP destination.
+type CloudEventsWebhook struct {
+	Client         *HttpClient
+	WorkspaceRegex *regexp.Regexp
+	BranchRegex    *regexp.Regexp
+	URL            string
+}
+
+// Send sends the event to URL if workspace and branch matches their respective regex.
+func (c *CloudEventsWebhook) Send(_ logging.SimpleLogging, applyResult ApplyResult) error {
+	if !c.WorkspaceRegex.MatchString(applyResult.Workspace) || !c.BranchRegex.MatchString(applyResult.Pull.BaseBranch) {
+		return nil
+	}
+	if err := c.doSend(NewCloudEvent(applyResult)); err != nil {
+		return fmt.Errorf("sending cloudevent to %q: %w", c.URL, err)
+	}
+	return nil
+}
+
+// NewCloudEvent wraps applyResult in a CloudEvent. The source is the repo
+// and the subject the project the event is for.
+func NewCloudEvent(applyResult ApplyResult) CloudEvent {
+	subject := applyResult.ProjectName
+	if subject == "" {
+		subject = applyResult.Directory
+	}
+	if subject != "" && applyResult.Workspace != "" {
+		subject = fmt.Sprintf("%s/%s", subject, applyResult.Workspace)
+	}
+	if applyResult.Event == "" {
+		applyResult.Event = ApplyEvent
+	}
+	return CloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              uuid.NewString(),
+		Source:          fmt.Sprintf("atlantis/%s", applyResult.Repo.FullName),
+		Type:            CloudEventsTypePrefix + applyResult.Event,
+		Subject:         subject,
+		Time:            time.Now().UTC(),
+		DataContentType: "application/json",
+		Data:            applyResult,
+	}
+}
+
+func (c *CloudEventsWebhook) doSend(event CloudEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", c.URL, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", cloudEventsContentType)
+	httpClient := http.DefaultClient
+	if c.Client != nil {
+		for header, values := range c.Client.Headers {
+			for _, value := range values {
+				req.Header.Add(header, value)
+			}
+		}
+		if c.Client.Client != nil {
+			httpClient = c.Client.Client
+		}
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("returned status code %d with response %q", resp.StatusCode, respBody)
+	}
+	return nil
+}
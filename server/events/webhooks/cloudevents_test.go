@@ -0,0 +1,56 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestCloudEventsWebhook_Send(t *testing.T) {
+	var event map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Equals(t, "application/cloudevents+json", r.Header.Get("Content-Type"))
+		Equals(t, "Bearer token", r.Header.Get("Authorization"))
+		Ok(t, json.NewDecoder(r.Body).Decode(&event))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	webhook := webhooks.CloudEventsWebhook{
+		Client: &webhooks.HttpClient{
+			Client:  http.DefaultClient,
+			Headers: map[string][]string{"Authorization": {"Bearer token"}},
+		},
+		URL:            server.URL,
+		WorkspaceRegex: regexp.MustCompile(".*"),
+		BranchRegex:    regexp.MustCompile(".*"),
+	}
+	result := httpApplyResult
+	result.Event = webhooks.LockAcquiredEvent
+	result.ProjectName = "prod"
+
+	err := webhook.Send(logging.NewNoopLogger(t), result)
+	Ok(t, err)
+	Equals(t, "1.0", event["specversion"])
+	Equals(t, "io.runatlantis.lock_acquired", event["type"])
+	Equals(t, "atlantis/runatlantis/atlantis", event["source"])
+	Equals(t, "prod/production", event["subject"])
+	Assert(t, event["id"] != "", "expected id to be set")
+	Equals(t, "lock_acquired", event["data"].(map[string]any)["Event"])
+}
+
+func TestNewCloudEvent_DefaultsToApply(t *testing.T) {
+	event := webhooks.NewCloudEvent(webhooks.ApplyResult{Directory: "dir"})
+	Equals(t, "io.runatlantis.apply", event.Type)
+	Equals(t, "dir", event.Subject)
+	Equals(t, webhooks.ApplyEvent, event.Data.Event)
+}
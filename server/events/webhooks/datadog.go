@@ -0,0 +1,123 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cactus/go-statsd-client/v5/statsd"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+const (
+	// DefaultDatadogURL is the Datadog events API endpoint. Accounts on
+	// other Datadog sites should use that site's endpoint, ex.
+	// https://api.datadoghq.eu/api/v1/events.
+	DefaultDatadogURL = "https://api.datadoghq.com/api/v1/events"
+	// datadogMetricPrefix prefixes the metrics sent with DogStatsD, ex.
+	// atlantis.webhook.apply.
+	datadogMetricPrefix = "atlantis.webhook"
+)
+
+// DatadogWebhook sends events to the Datadog events API so they can be
+// overlaid on dashboards and, if Statter is set, increments a DogStatsD
+// counter per event.
+type DatadogWebhook struct {
+	Client         *HttpClient
+	WorkspaceRegex *regexp.Regexp
+	BranchRegex    *regexp.Regexp
+	URL            string
+	APIKey         string
+	// Tags are added to every event and metric, ex. env:production. The env
+	// tag is replaced by the environment of the project, if it has one.
+	Tags []string
+	// Statter sends DogStatsD metrics. It may be nil.
+	Statter statsd.Statter
+}
+
+// NewDatadogStatter returns a DogStatsD client sending to address.
+func NewDatadogStatter(address string) (statsd.Statter, error) {
+	return statsd.NewClientWithConfig(&statsd.ClientConfig{
+		Address:   address,
+		Prefix:    datadogMetricPrefix,
+		TagFormat: statsd.SuffixOctothorpe,
+	})
+}
+
+type datadogEvent struct {
+	Title          string   `json:"title"`
+	Text           string   `json:"text"`
+	Tags           []string `json:"tags,omitempty"`
+	AlertType      string   `json:"alert_type"`
+	SourceTypeName string   `json:"source_type_name"`
+	AggregationKey string   `json:"aggregation_key"`
+}
+
+// Send sends the event and metric if workspace and branch matches their respective regex.
+func (d *DatadogWebhook) Send(_ logging.SimpleLogging, applyResult ApplyResult) error {
+	if !d.WorkspaceRegex.MatchString(applyResult.Workspace) || !d.BranchRegex.MatchString(applyResult.Pull.BaseBranch) {
+		return nil
+	}
+	tags := d.tags(applyResult)
+	if d.Statter != nil {
+		statsdTags := make([]statsd.Tag, 0, len(tags))
+		for _, t := range tags {
+			k, v, _ := strings.Cut(t, ":")
+			statsdTags = append(statsdTags, statsd.Tag{k, v})
+		}
+		if err := d.Statter.Inc(applyResult.EventName(), 1, 1.0, statsdTags...); err != nil {
+			return fmt.Errorf("sending datadog metric: %w", err)
+		}
+	}
+
+	alertType := "error"
+	if applyResult.Success {
+		alertType = "success"
+	}
+	text := applyResult.Summary
+	if applyResult.Pull.URL != "" {
+		text = strings.TrimSpace(fmt.Sprintf("%s\n%s", text, applyResult.Pull.URL))
+	}
+	event := datadogEvent{
+		Title:          "Atlantis: " + eventTitle(applyResult),
+		Text:           text,
+		Tags:           tags,
+		AlertType:      alertType,
+		SourceTypeName: "atlantis",
+		AggregationKey: alertDedupKey(applyResult),
+	}
+	headers := map[string][]string{"DD-API-KEY": {d.APIKey}}
+	if err := postAlertJSON(d.Client, d.URL, headers, event); err != nil {
+		return fmt.Errorf("sending datadog event: %w", err)
+	}
+	return nil
+}
+
+func (d *DatadogWebhook) tags(applyResult ApplyResult) []string {
+	tags := []string{
+		"event:" + applyResult.EventName(),
+		"repo:" + applyResult.Repo.FullName,
+		"workspace:" + applyResult.Workspace,
+		"success:" + strconv.FormatBool(applyResult.Success),
+	}
+	if applyResult.ProjectName != "" {
+		tags = append(tags, "project:"+applyResult.ProjectName)
+	}
+	if applyResult.Directory != "" {
+		tags = append(tags, "directory:"+applyResult.Directory)
+	}
+	if applyResult.Environment == "" {
+		return append(tags, d.Tags...)
+	}
+	tags = append(tags, "env:"+applyResult.Environment)
+	for _, t := range d.Tags {
+		if !strings.HasPrefix(t, "env:") {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
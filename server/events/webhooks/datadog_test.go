@@ -0,0 +1,85 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/cactus/go-statsd-client/v5/statsd"
+	"github.com/cactus/go-statsd-client/v5/statsd/statsdtest"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestDatadogWebhook_Send(t *testing.T) {
+	var event map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Equals(t, "api-key", r.Header.Get("DD-API-KEY"))
+		Ok(t, json.NewDecoder(r.Body).Decode(&event))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sender := statsdtest.NewRecordingSender()
+	statter, err := statsd.NewClientWithSender(sender, "atlantis.webhook", statsd.SuffixOctothorpe)
+	Ok(t, err)
+
+	webhook := webhooks.DatadogWebhook{
+		Client:         &webhooks.HttpClient{Client: http.DefaultClient},
+		WorkspaceRegex: regexp.MustCompile(".*"),
+		BranchRegex:    regexp.MustCompile(".*"),
+		URL:            server.URL,
+		APIKey:         "api-key",
+		Tags:           []string{"env:production"},
+		Statter:        statter,
+	}
+	result := httpApplyResult
+	result.ProjectName = "prod"
+
+	Ok(t, webhook.Send(logging.NewNoopLogger(t), result))
+
+	Equals(t, "Atlantis: Apply succeeded for runatlantis/atlantis", event["title"])
+	Equals(t, "success", event["alert_type"])
+	Equals(t, "url", event["text"])
+	Equals(t, []any{
+		"event:apply",
+		"repo:runatlantis/atlantis",
+		"workspace:production",
+		"success:true",
+		"project:prod",
+		"env:production",
+	}, event["tags"])
+
+	sent := sender.GetSent()
+	Equals(t, 1, len(sent))
+	Equals(t, "atlantis.webhook.apply:1|c|#event:apply,repo:runatlantis/atlantis,workspace:production,success:true,project:prod,env:production", string(sent[0].Raw))
+
+	t.Log("the environment of the project replaces the configured env tag")
+	webhook.Tags = []string{"env:production", "team:infra"}
+	result.Environment = "staging"
+	Ok(t, webhook.Send(logging.NewNoopLogger(t), result))
+	Equals(t, []any{
+		"event:apply",
+		"repo:runatlantis/atlantis",
+		"workspace:production",
+		"success:true",
+		"project:prod",
+		"env:staging",
+		"team:infra",
+	}, event["tags"])
+	sent = sender.GetSent()
+	Equals(t, 2, len(sent))
+	Equals(t, "atlantis.webhook.apply:1|c|#event:apply,repo:runatlantis/atlantis,workspace:production,success:true,project:prod,env:staging,team:infra", string(sent[1].Raw))
+}
+
+func TestNewWebhooksManager_DatadogRequiresKey(t *testing.T) {
+	configs := []webhooks.Config{{Event: webhooks.ApplyEvent, Kind: webhooks.DatadogKind}}
+	_, err := webhooks.NewMultiWebhookSender(configs, validClients())
+	ErrEquals(t, "must specify \"integration-key\" if using a webhook of \"kind: datadog\"", err)
+}
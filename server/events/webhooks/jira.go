@@ -0,0 +1,150 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// jiraKeyRegex matches Jira issue keys, ex. INFRA-123.
+var jiraKeyRegex = regexp.MustCompile(`\b[A-Z][A-Z0-9_]+-[1-9][0-9]*\b`)
+
+// JiraWebhook comments on the Jira issues referenced by the pull request's
+// head branch or title and optionally transitions them after a successful
+// apply.
+type JiraWebhook struct {
+	Client         *HttpClient
+	WorkspaceRegex *regexp.Regexp
+	BranchRegex    *regexp.Regexp
+	// URL is the base URL of the Jira instance, ex. https://acme.atlassian.net.
+	URL string
+	// Credentials are either "user:api-token" for basic auth or a personal
+	// access token sent as a bearer token.
+	Credentials string
+	// Transition is the name of the transition to apply to the issues after
+	// a successful apply, ex. "Done". If empty, issues aren't transitioned.
+	Transition string
+}
+
+// JiraIssueKeys returns the unique Jira issue keys referenced by the pull
+// request's head branch and title, in order of appearance.
+func JiraIssueKeys(applyResult ApplyResult) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	// Branch names are often lower case, ex. infra-123-fix-dns.
+	for _, s := range []string{strings.ToUpper(applyResult.Pull.HeadBranch), applyResult.Pull.Title} {
+		for _, key := range jiraKeyRegex.FindAllString(s, -1) {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// Send comments on the linked issues if workspace and branch matches their respective regex.
+func (j *JiraWebhook) Send(_ logging.SimpleLogging, applyResult ApplyResult) error {
+	if !j.WorkspaceRegex.MatchString(applyResult.Workspace) || !j.BranchRegex.MatchString(applyResult.Pull.BaseBranch) {
+		return nil
+	}
+	comment := jiraComment(applyResult)
+	for _, key := range JiraIssueKeys(applyResult) {
+		if err := j.do("POST", fmt.Sprintf("/rest/api/2/issue/%s/comment", key), map[string]string{"body": comment}, nil); err != nil {
+			return fmt.Errorf("commenting on jira issue %s: %w", key, err)
+		}
+		if j.Transition != "" && applyResult.EventName() == ApplyEvent && applyResult.Success {
+			if err := j.transition(key); err != nil {
+				return fmt.Errorf("transitioning jira issue %s: %w", key, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (j *JiraWebhook) transition(key string) error {
+	var transitions struct {
+		Transitions []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"transitions"`
+	}
+	path := fmt.Sprintf("/rest/api/2/issue/%s/transitions", key)
+	if err := j.do("GET", path, nil, &transitions); err != nil {
+		return err
+	}
+	for _, t := range transitions.Transitions {
+		if strings.EqualFold(t.Name, j.Transition) {
+			return j.do("POST", path, map[string]any{"transition": map[string]string{"id": t.ID}}, nil)
+		}
+	}
+	// The issue may already be in the target status in which case the
+	// transition isn't available.
+	return nil
+}
+
+func jiraComment(applyResult ApplyResult) string {
+	project := applyResult.ProjectName
+	if project == "" {
+		project = applyResult.Directory
+	}
+	lines := []string{
+		fmt.Sprintf("Atlantis: %s for %s (project: %s, workspace: %s)", describeEvent(applyResult), applyResult.Repo.FullName, project, applyResult.Workspace),
+	}
+	if applyResult.Summary != "" {
+		lines = append(lines, "", applyResult.Summary)
+	}
+	if applyResult.Pull.URL != "" {
+		lines = append(lines, "", applyResult.Pull.URL)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (j *JiraWebhook) do(method string, path string, payload any, out any) error {
+	var body io.Reader
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewBuffer(b)
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(j.URL, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if strings.Contains(j.Credentials, ":") {
+		req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(j.Credentials)))
+	} else if j.Credentials != "" {
+		req.Header.Set("Authorization", "Bearer "+j.Credentials)
+	}
+	httpClient := http.DefaultClient
+	if j.Client != nil && j.Client.Client != nil {
+		httpClient = j.Client.Client
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("returned status code %d with response %q", resp.StatusCode, respBody)
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestJiraIssueKeys(t *testing.T) {
+	cases := []struct {
+		branch string
+		title  string
+		exp    []string
+	}{
+		{"infra-123-fix-dns", "", []string{"INFRA-123"}},
+		{"feature", "OPS-1: Add bucket (see OPS-2)", []string{"OPS-1", "OPS-2"}},
+		{"OPS-1", "OPS-1 Add bucket", []string{"OPS-1"}},
+		{"main", "Add bucket", nil},
+	}
+	for _, c := range cases {
+		t.Run(c.branch, func(t *testing.T) {
+			keys := webhooks.JiraIssueKeys(webhooks.ApplyResult{Pull: models.PullRequest{HeadBranch: c.branch, Title: c.title}})
+			Equals(t, c.exp, keys)
+		})
+	}
+}
+
+func TestJiraWebhook_CommentAndTransition(t *testing.T) {
+	var requests []string
+	var comment map[string]string
+	var transition map[string]map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Equals(t, "Bearer pat", r.Header.Get("Authorization"))
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.URL.Path == "/rest/api/2/issue/INFRA-1/comment":
+			Ok(t, json.NewDecoder(r.Body).Decode(&comment))
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == "GET":
+			w.Write([]byte(`{"transitions":[{"id":"11","name":"In Progress"},{"id":"31","name":"Done"}]}`)) // nolint: errcheck
+		default:
+			Ok(t, json.NewDecoder(r.Body).Decode(&transition))
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	webhook := webhooks.JiraWebhook{
+		Client:         &webhooks.HttpClient{Client: http.DefaultClient},
+		WorkspaceRegex: regexp.MustCompile(".*"),
+		BranchRegex:    regexp.MustCompile(".*"),
+		URL:            server.URL + "/",
+		Credentials:    "pat",
+		Transition:     "done",
+	}
+	result := httpApplyResult
+	result.Pull.HeadBranch = "infra-1-add-bucket"
+	result.Directory = "."
+
+	Ok(t, webhook.Send(logging.NewNoopLogger(t), result))
+	Equals(t, []string{
+		"POST /rest/api/2/issue/INFRA-1/comment",
+		"GET /rest/api/2/issue/INFRA-1/transitions",
+		"POST /rest/api/2/issue/INFRA-1/transitions",
+	}, requests)
+	Equals(t, "Atlantis: Apply succeeded for runatlantis/atlantis (project: ., workspace: production)\n\nurl", comment["body"])
+	Equals(t, "31", transition["transition"]["id"])
+
+	t.Log("plans are commented on but not transitioned")
+	requests = nil
+	result.Event = webhooks.PlanEvent
+	Ok(t, webhook.Send(logging.NewNoopLogger(t), result))
+	Equals(t, []string{"POST /rest/api/2/issue/INFRA-1/comment"}, requests)
+}
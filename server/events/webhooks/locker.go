@@ -0,0 +1,66 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// LockingWebhooks wraps a locking.Locker to send the lock_acquired and
+// lock_released events.
+type LockingWebhooks struct {
+	locking.Locker
+	Sender Sender
+	Logger logging.SimpleLogging
+}
+
+// NewLockingWebhooks returns locker wrapped to send lock events to sender.
+func NewLockingWebhooks(locker locking.Locker, sender Sender, logger logging.SimpleLogging) *LockingWebhooks {
+	return &LockingWebhooks{
+		Locker: locker,
+		Sender: sender,
+		Logger: logger,
+	}
+}
+
+func (l *LockingWebhooks) TryLock(p models.Project, workspace string, pull models.PullRequest, user models.User) (locking.TryLockResponse, error) {
+	resp, err := l.Locker.TryLock(p, workspace, pull, user)
+	if err == nil && resp.LockAcquired {
+		l.send(LockAcquiredEvent, resp.CurrLock)
+	}
+	return resp, err
+}
+
+func (l *LockingWebhooks) Unlock(key string) (*models.ProjectLock, error) {
+	lock, err := l.Locker.Unlock(key)
+	if err == nil && lock != nil {
+		l.send(LockReleasedEvent, *lock)
+	}
+	return lock, err
+}
+
+func (l *LockingWebhooks) UnlockByPull(repoFullName string, pullNum int) ([]models.ProjectLock, error) {
+	locks, err := l.Locker.UnlockByPull(repoFullName, pullNum)
+	if err == nil {
+		for _, lock := range locks {
+			l.send(LockReleasedEvent, lock)
+		}
+	}
+	return locks, err
+}
+
+func (l *LockingWebhooks) send(event string, lock models.ProjectLock) {
+	l.Sender.Send(l.Logger, ApplyResult{ // nolint: errcheck
+		Event:       event,
+		Workspace:   lock.Workspace,
+		Repo:        lock.Pull.BaseRepo,
+		Pull:        lock.Pull,
+		User:        lock.User,
+		Success:     true,
+		Directory:   lock.Project.Path,
+		ProjectName: lock.Project.ProjectName,
+	})
+}
@@ -0,0 +1,62 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks_test
+
+import (
+	"testing"
+
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/core/locking"
+	lockmocks "github.com/runatlantis/atlantis/server/core/locking/mocks"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/events/webhooks/mocks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestLockingWebhooks(t *testing.T) {
+	RegisterMockTestingT(t)
+	logger := logging.NewNoopLogger(t)
+	locker := lockmocks.NewMockLocker()
+	sender := mocks.NewMockSender()
+	l := webhooks.NewLockingWebhooks(locker, sender, logger)
+
+	repo := models.Repo{FullName: "owner/repo"}
+	pull := models.PullRequest{Num: 1, BaseRepo: repo}
+	user := models.User{Username: "user"}
+	project := models.NewProject(repo.FullName, "dir", "proj")
+	lock := models.ProjectLock{Project: project, Workspace: "default", Pull: pull, User: user}
+	expected := webhooks.ApplyResult{
+		Event:       webhooks.LockAcquiredEvent,
+		Workspace:   "default",
+		Repo:        repo,
+		Pull:        pull,
+		User:        user,
+		Success:     true,
+		Directory:   "dir",
+		ProjectName: "proj",
+	}
+
+	t.Log("an acquired lock sends lock_acquired")
+	When(locker.TryLock(project, "default", pull, user)).ThenReturn(locking.TryLockResponse{LockAcquired: true, CurrLock: lock}, nil)
+	resp, err := l.TryLock(project, "default", pull, user)
+	Ok(t, err)
+	Equals(t, true, resp.LockAcquired)
+	sender.VerifyWasCalledOnce().Send(logger, expected)
+
+	t.Log("releasing the lock sends lock_released")
+	When(locker.Unlock("key")).ThenReturn(&lock, nil)
+	_, err = l.Unlock("key")
+	Ok(t, err)
+	expected.Event = webhooks.LockReleasedEvent
+	sender.VerifyWasCalledOnce().Send(logger, expected)
+
+	t.Log("a lock held by another pull sends nothing")
+	otherPull := models.PullRequest{Num: 2, BaseRepo: repo}
+	When(locker.TryLock(project, "default", otherPull, user)).ThenReturn(locking.TryLockResponse{LockAcquired: false, CurrLock: lock}, nil)
+	_, err = l.TryLock(project, "default", otherPull, user)
+	Ok(t, err)
+	sender.VerifyWasCalled(Times(2)).Send(Any[logging.SimpleLogging](), Any[webhooks.ApplyResult]())
+}
@@ -55,6 +55,21 @@ func (mock *MockSlackClient) PostMessage(channel string, applyResult webhooks.Ap
 	return _ret0
 }
 
+func (mock *MockSlackClient) PostText(channel string, text string) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockSlackClient().")
+	}
+	_params := []pegomock.Param{channel, text}
+	_result := pegomock.GetGenericMockFrom(mock).Invoke("PostText", _params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var _ret0 error
+	if len(_result) != 0 {
+		if _result[0] != nil {
+			_ret0 = _result[0].(error)
+		}
+	}
+	return _ret0
+}
+
 func (mock *MockSlackClient) TokenIsSet() bool {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockSlackClient().")
@@ -159,6 +174,41 @@ func (c *MockSlackClient_PostMessage_OngoingVerification) GetAllCapturedArgument
 	return
 }
 
+func (verifier *VerifierMockSlackClient) PostText(channel string, text string) *MockSlackClient_PostText_OngoingVerification {
+	_params := []pegomock.Param{channel, text}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "PostText", _params, verifier.timeout)
+	return &MockSlackClient_PostText_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockSlackClient_PostText_OngoingVerification struct {
+	mock              *MockSlackClient
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockSlackClient_PostText_OngoingVerification) GetCapturedArguments() (string, string) {
+	channel, text := c.GetAllCapturedArguments()
+	return channel[len(channel)-1], text[len(text)-1]
+}
+
+func (c *MockSlackClient_PostText_OngoingVerification) GetAllCapturedArguments() (_param0 []string, _param1 []string) {
+	_params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(_params) > 0 {
+		if len(_params) > 0 {
+			_param0 = make([]string, len(c.methodInvocations))
+			for u, param := range _params[0] {
+				_param0[u] = param.(string)
+			}
+		}
+		if len(_params) > 1 {
+			_param1 = make([]string, len(c.methodInvocations))
+			for u, param := range _params[1] {
+				_param1[u] = param.(string)
+			}
+		}
+	}
+	return
+}
+
 func (verifier *VerifierMockSlackClient) TokenIsSet() *MockSlackClient_TokenIsSet_OngoingVerification {
 	_params := []pegomock.Param{}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "TokenIsSet", _params, verifier.timeout)
@@ -14,11 +14,14 @@
 package webhooks
 
 import (
+	"bytes"
 	"regexp"
+	"text/template"
 
 	"fmt"
 
 	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/slack-go/slack"
 )
 
 // SlackWebhook sends webhooks to Slack.
@@ -27,6 +30,9 @@ type SlackWebhook struct {
 	WorkspaceRegex *regexp.Regexp
 	BranchRegex    *regexp.Regexp
 	Channel        string
+	// Template, if set, is used to render the message text instead of the
+	// default attachment.
+	Template *template.Template
 }
 
 func NewSlack(wr *regexp.Regexp, br *regexp.Regexp, channel string, client SlackClient) (*SlackWebhook, error) {
@@ -47,5 +53,56 @@ func (s *SlackWebhook) Send(_ logging.SimpleLogging, applyResult ApplyResult) er
 	if !s.WorkspaceRegex.MatchString(applyResult.Workspace) || !s.BranchRegex.MatchString(applyResult.Pull.BaseBranch) {
 		return nil
 	}
+	if s.Template != nil {
+		text, err := renderSlackTemplate(s.Template, applyResult)
+		if err != nil {
+			return err
+		}
+		return s.Client.PostText(s.Channel, text)
+	}
 	return s.Client.PostMessage(s.Channel, applyResult)
 }
+
+// SlackIncomingWebhook sends webhooks to a Slack incoming webhook URL. Unlike
+// SlackWebhook it doesn't need a slack-token, the channel is configured
+// as part of the incoming webhook in Slack.
+type SlackIncomingWebhook struct {
+	Client         *HttpClient
+	WorkspaceRegex *regexp.Regexp
+	BranchRegex    *regexp.Regexp
+	URL            string
+	Template       *template.Template
+}
+
+// Send posts to the incoming webhook if workspace and branch matches their respective regex.
+func (s *SlackIncomingWebhook) Send(_ logging.SimpleLogging, applyResult ApplyResult) error {
+	if !s.WorkspaceRegex.MatchString(applyResult.Workspace) || !s.BranchRegex.MatchString(applyResult.Pull.BaseBranch) {
+		return nil
+	}
+	msg := slack.WebhookMessage{Attachments: createSlackAttachments(applyResult)}
+	if s.Template != nil {
+		text, err := renderSlackTemplate(s.Template, applyResult)
+		if err != nil {
+			return err
+		}
+		msg = slack.WebhookMessage{Text: text}
+	}
+	var err error
+	if s.Client != nil && s.Client.Client != nil {
+		err = slack.PostWebhookCustomHTTP(s.URL, s.Client.Client, &msg)
+	} else {
+		err = slack.PostWebhook(s.URL, &msg)
+	}
+	if err != nil {
+		return fmt.Errorf("sending slack incoming webhook: %w", err)
+	}
+	return nil
+}
+
+func renderSlackTemplate(tmpl *template.Template, applyResult ApplyResult) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, applyResult); err != nil {
+		return "", fmt.Errorf("rendering slack template: %w", err)
+	}
+	return buf.String(), nil
+}
@@ -31,6 +31,7 @@ type SlackClient interface {
 	AuthTest() error
 	TokenIsSet() bool
 	PostMessage(channel string, applyResult ApplyResult) error
+	PostText(channel string, text string) error
 }
 
 //go:generate pegomock generate --package mocks -o mocks/mock_underlying_slack_client.go UnderlyingSlackClient
@@ -65,7 +66,7 @@ func (d *DefaultSlackClient) TokenIsSet() bool {
 }
 
 func (d *DefaultSlackClient) PostMessage(channel string, applyResult ApplyResult) error {
-	attachments := d.createAttachments(applyResult)
+	attachments := createSlackAttachments(applyResult)
 	_, _, err := d.Slack.PostMessage(
 		channel,
 		slack.MsgOptionAsUser(true),
@@ -75,18 +76,30 @@ func (d *DefaultSlackClient) PostMessage(channel string, applyResult ApplyResult
 	return err
 }
 
-func (d *DefaultSlackClient) createAttachments(applyResult ApplyResult) []slack.Attachment {
-	var colour string
-	var successWord string
+func (d *DefaultSlackClient) PostText(channel string, text string) error {
+	_, _, err := d.Slack.PostMessage(
+		channel,
+		slack.MsgOptionAsUser(true),
+		slack.MsgOptionText(text, false),
+	)
+	return err
+}
+
+func createSlackAttachments(applyResult ApplyResult) []slack.Attachment {
+	colour := slackFailureColour
 	if applyResult.Success {
 		colour = slackSuccessColour
-		successWord = "succeeded"
-	} else {
-		colour = slackFailureColour
-		successWord = "failed"
 	}
 
-	text := fmt.Sprintf("Apply %s for <%s|%s>", successWord, applyResult.Pull.URL, applyResult.Repo.FullName)
+	text := fmt.Sprintf("%s for <%s|%s>", describeEvent(applyResult), applyResult.Pull.URL, applyResult.Repo.FullName)
+	if applyResult.EventName() == DriftEvent {
+		// Drift is detected outside of a pull request so there's no URL to link.
+		colour = slackFailureColour
+		text = fmt.Sprintf("%s in %s", describeEvent(applyResult), applyResult.Repo.FullName)
+	}
+	if applyResult.EventName() == AlertEvent {
+		text = eventTitle(applyResult)
+	}
 	directory := applyResult.Directory
 	// Since "." looks weird, replace it with "/" to make it clear this is the root.
 	if directory == "." {
@@ -119,5 +132,11 @@ func (d *DefaultSlackClient) createAttachments(applyResult ApplyResult) []slack.
 			},
 		},
 	}
+	if applyResult.Summary != "" {
+		attachment.Fields = append(attachment.Fields, slack.AttachmentField{
+			Title: "Summary",
+			Value: applyResult.Summary,
+		})
+	}
 	return []slack.Attachment{attachment}
 }
@@ -14,8 +14,12 @@
 package webhooks_test
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"regexp"
 	"testing"
+	"text/template"
 
 	. "github.com/petergtz/pegomock/v4"
 	"github.com/runatlantis/atlantis/server/events/models"
@@ -75,3 +79,55 @@ func TestSend_NoopSuccess(t *testing.T) {
 	Ok(t, err)
 	client.VerifyWasCalled(Never()).PostMessage(channel, result)
 }
+
+func TestSend_PostTextWithTemplate(t *testing.T) {
+	t.Log("Sending a hook with a template should post the rendered text")
+	RegisterMockTestingT(t)
+	client := mocks.NewMockSlackClient()
+	regex, err := regexp.Compile(".*")
+	Ok(t, err)
+
+	channel := "somechannel"
+	hook := webhooks.SlackWebhook{
+		Client:         client,
+		WorkspaceRegex: regex,
+		BranchRegex:    regex,
+		Channel:        channel,
+		Template:       template.Must(template.New("plan").Parse("{{ .EventName }} of {{ .ProjectName }}: {{ .Summary }}")),
+	}
+	result := webhooks.ApplyResult{
+		Event:       webhooks.PlanEvent,
+		ProjectName: "prod",
+		Summary:     "Plan: 1 to add, 0 to change, 0 to destroy.",
+	}
+
+	Ok(t, hook.Send(logging.NewNoopLogger(t), result))
+	client.VerifyWasCalledOnce().PostText(channel, "plan of prod: Plan: 1 to add, 0 to change, 0 to destroy.")
+	client.VerifyWasCalled(Never()).PostMessage(channel, result)
+}
+
+func TestSlackIncomingWebhook_Send(t *testing.T) {
+	t.Log("Sending a hook to an incoming webhook should post the message to its url")
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Ok(t, json.NewDecoder(r.Body).Decode(&body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	regex, err := regexp.Compile(".*")
+	Ok(t, err)
+
+	hook := webhooks.SlackIncomingWebhook{
+		Client:         &webhooks.HttpClient{Client: http.DefaultClient},
+		WorkspaceRegex: regex,
+		BranchRegex:    regex,
+		URL:            server.URL,
+		Template:       template.Must(template.New("drift").Parse("Drift in {{ .Repo.FullName }}")),
+	}
+	err = hook.Send(logging.NewNoopLogger(t), webhooks.ApplyResult{
+		Event: webhooks.DriftEvent,
+		Repo:  models.Repo{FullName: "runatlantis/atlantis"},
+	})
+	Ok(t, err)
+	Equals(t, "Drift in runatlantis/atlantis", body["text"])
+}
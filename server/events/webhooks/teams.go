@@ -0,0 +1,162 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+const (
+	teamsCardContentType = "application/vnd.microsoft.card.adaptive"
+	teamsCardSchema      = "http://adaptivecards.io/schemas/adaptive-card.json"
+	teamsCardVersion     = "1.4"
+)
+
+// TeamsWebhook sends webhooks to a Microsoft Teams incoming webhook or
+// workflow URL formatted as an adaptive card.
+type TeamsWebhook struct {
+	Client         *HttpClient
+	WorkspaceRegex *regexp.Regexp
+	BranchRegex    *regexp.Regexp
+	URL            string
+}
+
+// Send sends the webhook to Teams if workspace and branch matches their respective regex.
+func (t *TeamsWebhook) Send(_ logging.SimpleLogging, applyResult ApplyResult) error {
+	if !t.WorkspaceRegex.MatchString(applyResult.Workspace) || !t.BranchRegex.MatchString(applyResult.Pull.BaseBranch) {
+		return nil
+	}
+	if err := t.doSend(applyResult); err != nil {
+		return fmt.Errorf("sending teams webhook: %w", err)
+	}
+	return nil
+}
+
+func (t *TeamsWebhook) doSend(applyResult ApplyResult) error {
+	body, err := json.Marshal(createTeamsMessage(applyResult))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", t.URL, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	httpClient := http.DefaultClient
+	if t.Client != nil && t.Client.Client != nil {
+		httpClient = t.Client.Client
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Teams connectors respond with 200 while workflows respond with 202.
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("returned status code %d with response %q", resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string    `json:"contentType"`
+	Content     teamsCard `json:"content"`
+}
+
+type teamsCard struct {
+	Schema  string           `json:"$schema"`
+	Type    string           `json:"type"`
+	Version string           `json:"version"`
+	Body    []map[string]any `json:"body"`
+	Actions []map[string]any `json:"actions,omitempty"`
+}
+
+type teamsFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+func createTeamsMessage(applyResult ApplyResult) teamsMessage {
+	colour := "attention"
+	if applyResult.Success {
+		colour = "good"
+	}
+	title := eventTitle(applyResult)
+	if applyResult.EventName() == DriftEvent {
+		colour = "attention"
+		title = fmt.Sprintf("%s in %s", describeEvent(applyResult), applyResult.Repo.FullName)
+	}
+
+	directory := applyResult.Directory
+	// Since "." looks weird, replace it with "/" to make it clear this is the root.
+	if directory == "." {
+		directory = "/"
+	}
+	facts := []teamsFact{
+		{Title: "Workspace", Value: applyResult.Workspace},
+		{Title: "Branch", Value: applyResult.Pull.BaseBranch},
+		{Title: "User", Value: applyResult.User.Username},
+		{Title: "Directory", Value: directory},
+	}
+	if applyResult.ProjectName != "" {
+		facts = append(facts, teamsFact{Title: "Project", Value: applyResult.ProjectName})
+	}
+
+	body := []map[string]any{
+		{
+			"type":   "TextBlock",
+			"text":   title,
+			"weight": "bolder",
+			"size":   "medium",
+			"color":  colour,
+			"wrap":   true,
+		},
+		{
+			"type":  "FactSet",
+			"facts": facts,
+		},
+	}
+	if applyResult.Summary != "" {
+		body = append(body, map[string]any{
+			"type": "TextBlock",
+			"text": applyResult.Summary,
+			"wrap": true,
+		})
+	}
+	var actions []map[string]any
+	if applyResult.Pull.URL != "" {
+		actions = append(actions, map[string]any{
+			"type":  "Action.OpenUrl",
+			"title": "View pull request",
+			"url":   applyResult.Pull.URL,
+		})
+	}
+
+	return teamsMessage{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: teamsCardContentType,
+			Content: teamsCard{
+				Schema:  teamsCardSchema,
+				Type:    "AdaptiveCard",
+				Version: teamsCardVersion,
+				Body:    body,
+				Actions: actions,
+			},
+		}},
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestTeamsWebhook_AdaptiveCard(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Equals(t, "application/json", r.Header.Get("Content-Type"))
+		Ok(t, json.NewDecoder(r.Body).Decode(&body))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	webhook := webhooks.TeamsWebhook{
+		Client:         &webhooks.HttpClient{Client: http.DefaultClient},
+		URL:            server.URL,
+		WorkspaceRegex: regexp.MustCompile(".*"),
+		BranchRegex:    regexp.MustCompile(".*"),
+	}
+	result := httpApplyResult
+	result.Event = webhooks.PlanEvent
+	result.Summary = "Plan: 1 to add, 0 to change, 0 to destroy."
+
+	err := webhook.Send(logging.NewNoopLogger(t), result)
+	Ok(t, err)
+
+	Equals(t, "message", body["type"])
+	attachment := body["attachments"].([]any)[0].(map[string]any)
+	Equals(t, "application/vnd.microsoft.card.adaptive", attachment["contentType"])
+	card := attachment["content"].(map[string]any)
+	Equals(t, "AdaptiveCard", card["type"])
+	cardBody := card["body"].([]any)
+	Equals(t, "Plan succeeded for runatlantis/atlantis", cardBody[0].(map[string]any)["text"])
+	Equals(t, "good", cardBody[0].(map[string]any)["color"])
+	Equals(t, result.Summary, cardBody[2].(map[string]any)["text"])
+	action := card["actions"].([]any)[0].(map[string]any)
+	Equals(t, "url", action["url"])
+}
+
+func TestTeamsWebhook_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	webhook := webhooks.TeamsWebhook{
+		Client:         &webhooks.HttpClient{Client: http.DefaultClient},
+		URL:            server.URL,
+		WorkspaceRegex: regexp.MustCompile(".*"),
+		BranchRegex:    regexp.MustCompile(".*"),
+	}
+	err := webhook.Send(logging.NewNoopLogger(t), httpApplyResult)
+	ErrContains(t, "returned status code 400", err)
+}
+
+func TestTeamsWebhook_NoRegexMatch(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	webhook := webhooks.TeamsWebhook{
+		Client:         &webhooks.HttpClient{Client: http.DefaultClient},
+		URL:            server.URL,
+		WorkspaceRegex: regexp.MustCompile("staging"),
+		BranchRegex:    regexp.MustCompile(".*"),
+	}
+	err := webhook.Send(logging.NewNoopLogger(t), httpApplyResult)
+	Ok(t, err)
+	Equals(t, false, called)
+}
@@ -14,8 +14,15 @@
 package webhooks
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"path/filepath"
 	"regexp"
+	"slices"
+	"strings"
+	"text/template"
 
 	"errors"
 
@@ -25,7 +32,28 @@ import (
 
 const SlackKind = "slack"
 const HttpKind = "http"
+const TeamsKind = "teams"
+const PagerDutyKind = "pagerduty"
+const OpsgenieKind = "opsgenie"
+const CloudEventsKind = "cloudevents"
+const DatadogKind = "datadog"
+const EmailKind = "email"
+const JiraKind = "jira"
 const ApplyEvent = "apply"
+const PlanEvent = "plan"
+const PolicyCheckEvent = "policy_check"
+const DriftEvent = "drift"
+const PlanStartedEvent = "plan_started"
+const LockAcquiredEvent = "lock_acquired"
+const LockReleasedEvent = "lock_released"
+const SummaryGeneratedEvent = "summary_generated"
+const AlertEvent = "alert"
+
+// AllEvents can be configured as the event of a webhook to receive every event.
+const AllEvents = "all"
+
+var supportedKinds = []string{SlackKind, HttpKind, TeamsKind, PagerDutyKind, OpsgenieKind, CloudEventsKind, DatadogKind, EmailKind, JiraKind}
+var supportedEvents = []string{ApplyEvent, PlanEvent, PolicyCheckEvent, DriftEvent, PlanStartedEvent, LockAcquiredEvent, LockReleasedEvent, SummaryGeneratedEvent, AlertEvent, AllEvents}
 
 //go:generate pegomock generate --package mocks -o mocks/mock_sender.go Sender
 
@@ -35,8 +63,11 @@ type Sender interface {
 	Send(log logging.SimpleLogging, applyResult ApplyResult) error
 }
 
-// ApplyResult is the result of a terraform apply.
+// ApplyResult is the result of a terraform apply. Despite its name it's also
+// used to describe every other event, see Event.
 type ApplyResult struct {
+	// Event is the event this result is for, ex. plan. Empty means apply.
+	Event       string
 	Workspace   string
 	Repo        models.Repo
 	Pull        models.PullRequest
@@ -44,6 +75,65 @@ type ApplyResult struct {
 	Success     bool
 	Directory   string
 	ProjectName string
+	// Environment is the environment label of the project, ex. staging.
+	Environment string
+	// Summary is a short description of the result, ex. the plan's
+	// "Plan: 1 to add, 0 to change, 0 to destroy." line or the generated
+	// plan summary for summary_generated events.
+	Summary string
+	// Alert is the name of the alert rule of alert events, which aren't for
+	// a repo. Success is false when it fires and true when it resolves.
+	Alert string
+}
+
+// EventName returns the event this result is for.
+func (a ApplyResult) EventName() string {
+	if a.Event == "" {
+		return ApplyEvent
+	}
+	return a.Event
+}
+
+// describeEvent returns a short human readable description of the result,
+// ex. "Plan succeeded".
+func describeEvent(a ApplyResult) string {
+	successWord := "failed"
+	if a.Success {
+		successWord = "succeeded"
+	}
+	switch a.EventName() {
+	case PlanEvent:
+		return "Plan " + successWord
+	case PolicyCheckEvent:
+		return "Policy check " + successWord
+	case DriftEvent:
+		return "Drift detected"
+	case PlanStartedEvent:
+		return "Plan started"
+	case LockAcquiredEvent:
+		return "Lock acquired"
+	case LockReleasedEvent:
+		return "Lock released"
+	case SummaryGeneratedEvent:
+		return "Plan summary generated"
+	case AlertEvent:
+		if a.Success {
+			return fmt.Sprintf("Alert %s resolved", a.Alert)
+		}
+		return fmt.Sprintf("Alert %s firing", a.Alert)
+	default:
+		return "Apply " + successWord
+	}
+}
+
+// eventTitle returns the description of the result and the repo it's for,
+// ex. "Plan failed for owner/repo". Alerts aren't for a repo so their summary
+// is used instead.
+func eventTitle(a ApplyResult) string {
+	if a.EventName() == AlertEvent {
+		return fmt.Sprintf("%s: %s", describeEvent(a), a.Summary)
+	}
+	return fmt.Sprintf("%s for %s", describeEvent(a), a.Repo.FullName)
 }
 
 // MultiWebhookSender sends multiple webhooks for each one it's configured for.
@@ -55,14 +145,38 @@ type Config struct {
 	Event          string
 	WorkspaceRegex string
 	BranchRegex    string
+	RepoRegex      string
+	ProjectRegex   string
 	Kind           string
 	Channel        string
 	URL            string
+	// IntegrationKey is the PagerDuty routing key, the Opsgenie or Datadog
+	// API key or the Jira credentials.
+	IntegrationKey string
+	// Tags are added to datadog events and metrics, ex. env:production.
+	Tags []string
+	// StatsdAddress is the DogStatsD address datadog metrics are sent to,
+	// ex. localhost:8125. If empty, no metrics are sent.
+	StatsdAddress string
+	// Transition is the Jira transition applied after a successful apply.
+	Transition string
+	// From and To are the sender and recipients of email webhooks.
+	From string
+	To   []string
+	// Template is an optional text/template used to render the message of
+	// slack webhooks. It's executed against an ApplyResult.
+	Template string
 }
 
 type Clients struct {
 	Slack SlackClient
 	Http  *HttpClient
+	// Logger is used by webhooks that send in the background, ex. email
+	// digests. It may be nil.
+	Logger logging.SimpleLogging
+	// DataDir, if set, is where webhooks keep what they send later, ex. the
+	// drift of the next email digest, so it isn't lost if Atlantis restarts.
+	DataDir string
 }
 
 func NewMultiWebhookSender(configs []Config, clients Clients) (*MultiWebhookSender, error) {
@@ -76,39 +190,172 @@ func NewMultiWebhookSender(configs []Config, clients Clients) (*MultiWebhookSend
 		if err != nil {
 			return nil, err
 		}
+		rr, err := regexp.Compile(c.RepoRegex)
+		if err != nil {
+			return nil, err
+		}
+		pr, err := regexp.Compile(c.ProjectRegex)
+		if err != nil {
+			return nil, err
+		}
 		if c.Kind == "" || c.Event == "" {
 			return nil, errors.New("must specify \"kind\" and \"event\" keys for webhooks")
 		}
-		if c.Event != ApplyEvent {
-			return nil, fmt.Errorf("\"event: %s\" not supported. Only \"event: %s\" is supported right now", c.Event, ApplyEvent)
+		if !slices.Contains(supportedEvents, c.Event) {
+			return nil, fmt.Errorf("\"event: %s\" not supported. Only %s events are supported right now", c.Event, quoteList(supportedEvents))
 		}
+		var tmpl *template.Template
+		if c.Template != "" {
+			if c.Kind != SlackKind && c.Kind != EmailKind {
+				return nil, errors.New("\"template\" is only supported for webhooks of \"kind: slack\" and \"kind: email\"")
+			}
+			tmpl, err = template.New(c.Event).Parse(c.Template)
+			if err != nil {
+				return nil, fmt.Errorf("parsing webhook template: %w", err)
+			}
+		}
+		var sender Sender
 		switch c.Kind {
 		case SlackKind:
+			if c.URL != "" {
+				sender = &SlackIncomingWebhook{
+					Client:         clients.Http,
+					WorkspaceRegex: wr,
+					BranchRegex:    br,
+					URL:            c.URL,
+					Template:       tmpl,
+				}
+				break
+			}
 			if !clients.Slack.TokenIsSet() {
-				return nil, errors.New("must specify top-level \"slack-token\" if using a webhook of \"kind: slack\"")
+				return nil, errors.New("must specify top-level \"slack-token\" or \"url\" if using a webhook of \"kind: slack\"")
 			}
 			if c.Channel == "" {
-				return nil, errors.New("must specify \"channel\" if using a webhook of \"kind: slack\"")
+				return nil, errors.New("must specify \"channel\" if using a webhook of \"kind: slack\" with \"slack-token\"")
 			}
 			slack, err := NewSlack(wr, br, c.Channel, clients.Slack)
 			if err != nil {
 				return nil, err
 			}
-			webhooks = append(webhooks, slack)
+			slack.Template = tmpl
+			sender = slack
 		case HttpKind:
 			if c.URL == "" {
 				return nil, errors.New("must specify \"url\" if using a webhook of \"kind: http\"")
 			}
-			httpWebhook := &HttpWebhook{
+			sender = &HttpWebhook{
+				Client:         clients.Http,
+				WorkspaceRegex: wr,
+				BranchRegex:    br,
+				URL:            c.URL,
+			}
+		case TeamsKind:
+			if c.URL == "" {
+				return nil, errors.New("must specify \"url\" if using a webhook of \"kind: teams\"")
+			}
+			sender = &TeamsWebhook{
+				Client:         clients.Http,
+				WorkspaceRegex: wr,
+				BranchRegex:    br,
+				URL:            c.URL,
+			}
+		case PagerDutyKind, OpsgenieKind:
+			if c.Event != ApplyEvent && c.Event != AlertEvent {
+				return nil, fmt.Errorf("\"kind: %s\" only supports \"event: %s\" and \"event: %s\"", c.Kind, ApplyEvent, AlertEvent)
+			}
+			if c.IntegrationKey == "" {
+				return nil, fmt.Errorf("must specify \"integration-key\" if using a webhook of \"kind: %s\"", c.Kind)
+			}
+			if c.Kind == PagerDutyKind {
+				pd := &PagerDutyWebhook{
+					Client:         clients.Http,
+					WorkspaceRegex: wr,
+					BranchRegex:    br,
+					URL:            DefaultPagerDutyURL,
+					RoutingKey:     c.IntegrationKey,
+				}
+				if c.URL != "" {
+					pd.URL = c.URL
+				}
+				sender = pd
+			} else {
+				og := &OpsgenieWebhook{
+					Client:         clients.Http,
+					WorkspaceRegex: wr,
+					BranchRegex:    br,
+					URL:            DefaultOpsgenieURL,
+					APIKey:         c.IntegrationKey,
+				}
+				if c.URL != "" {
+					og.URL = c.URL
+				}
+				sender = og
+			}
+		case CloudEventsKind:
+			if c.URL == "" {
+				return nil, errors.New("must specify \"url\" if using a webhook of \"kind: cloudevents\"")
+			}
+			sender = &CloudEventsWebhook{
+				Client:         clients.Http,
+				WorkspaceRegex: wr,
+				BranchRegex:    br,
+				URL:            c.URL,
+			}
+		case DatadogKind:
+			if c.IntegrationKey == "" {
+				return nil, errors.New("must specify \"integration-key\" if using a webhook of \"kind: datadog\"")
+			}
+			dd := &DatadogWebhook{
+				Client:         clients.Http,
+				WorkspaceRegex: wr,
+				BranchRegex:    br,
+				URL:            DefaultDatadogURL,
+				APIKey:         c.IntegrationKey,
+				Tags:           c.Tags,
+			}
+			if c.URL != "" {
+				dd.URL = c.URL
+			}
+			if c.StatsdAddress != "" {
+				dd.Statter, err = NewDatadogStatter(c.StatsdAddress)
+				if err != nil {
+					return nil, fmt.Errorf("initializing datadog statsd client: %w", err)
+				}
+			}
+			sender = dd
+		case EmailKind:
+			if c.URL == "" || c.From == "" || len(c.To) == 0 {
+				return nil, errors.New("must specify \"url\", \"from\" and \"to\" if using a webhook of \"kind: email\"")
+			}
+			var digestPath string
+			if clients.DataDir != "" {
+				digestPath = filepath.Join(clients.DataDir, emailDigestFile(c))
+			}
+			sender, err = NewEmailWebhook(wr, br, c.URL, c.From, c.To, tmpl, DefaultEmailDigestInterval, digestPath, clients.Logger)
+			if err != nil {
+				return nil, err
+			}
+		case JiraKind:
+			if c.URL == "" || c.IntegrationKey == "" {
+				return nil, errors.New("must specify \"url\" and \"integration-key\" if using a webhook of \"kind: jira\"")
+			}
+			sender = &JiraWebhook{
 				Client:         clients.Http,
 				WorkspaceRegex: wr,
 				BranchRegex:    br,
 				URL:            c.URL,
+				Credentials:    c.IntegrationKey,
+				Transition:     c.Transition,
 			}
-			webhooks = append(webhooks, httpWebhook)
 		default:
-			return nil, fmt.Errorf("\"kind: %s\" not supported. Only \"kind: %s\" and \"kind: %s\" are supported right now", c.Kind, SlackKind, HttpKind)
+			return nil, fmt.Errorf("\"kind: %s\" not supported. Only %s kinds are supported right now", c.Kind, quoteList(supportedKinds))
 		}
+		webhooks = append(webhooks, &routedSender{
+			Event:        c.Event,
+			RepoRegex:    rr,
+			ProjectRegex: pr,
+			Sender:       sender,
+		})
 	}
 
 	return &MultiWebhookSender{
@@ -116,6 +363,60 @@ func NewMultiWebhookSender(configs []Config, clients Clients) (*MultiWebhookSend
 	}, nil
 }
 
+// emailDigestFile returns the name of the file keeping the next drift digest
+// of the email webhook c, which is the same as long as its server, sender and
+// recipients are.
+func emailDigestFile(c Config) string {
+	sum := sha256.Sum256([]byte(strings.Join(append([]string{c.URL, c.From}, c.To...), "\n")))
+	return fmt.Sprintf("email-digest-%s.json", hex.EncodeToString(sum[:8]))
+}
+
+// quoteList formats values as "a", "b" and "c".
+func quoteList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	if len(quoted) < 2 {
+		return strings.Join(quoted, "")
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " and " + quoted[len(quoted)-1]
+}
+
+// routedSender only forwards results for its event whose repo and project
+// match the configured regexes. This is what allows routing different repos
+// and projects to different destinations.
+type routedSender struct {
+	Event        string
+	RepoRegex    *regexp.Regexp
+	ProjectRegex *regexp.Regexp
+	Sender       Sender
+}
+
+func (r *routedSender) Send(log logging.SimpleLogging, result ApplyResult) error {
+	if r.Event != AllEvents && result.EventName() != r.Event {
+		return nil
+	}
+	if !r.RepoRegex.MatchString(result.Repo.FullName) || !r.ProjectRegex.MatchString(result.ProjectName) {
+		return nil
+	}
+	return r.Sender.Send(log, result)
+}
+
+// Close stops the webhooks that send in the background, ex. email digests.
+func (w *MultiWebhookSender) Close() error {
+	var errs []error
+	for _, sender := range w.Webhooks {
+		if routed, ok := sender.(*routedSender); ok {
+			sender = routed.Sender
+		}
+		if closer, ok := sender.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // Send sends the webhook using its Webhooks.
 func (w *MultiWebhookSender) Send(log logging.SimpleLogging, result ApplyResult) error {
 	for _, w := range w.Webhooks {
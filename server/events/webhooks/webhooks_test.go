@@ -15,10 +15,12 @@ package webhooks_test
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
 	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/webhooks"
 	"github.com/runatlantis/atlantis/server/events/webhooks/mocks"
 	"github.com/runatlantis/atlantis/server/logging"
@@ -112,7 +114,7 @@ func TestNewWebhooksManager_UnsupportedEvent(t *testing.T) {
 	configs[0].Event = unsupportedEvent
 	_, err := webhooks.NewMultiWebhookSender(configs, clients)
 	Assert(t, err != nil, "expected error")
-	Equals(t, "\"event: badevent\" not supported. Only \"event: apply\" is supported right now", err.Error())
+	Equals(t, "\"event: badevent\" not supported. Only \"apply\", \"plan\", \"policy_check\", \"drift\", \"plan_started\", \"lock_acquired\", \"lock_released\", \"summary_generated\", \"alert\" and \"all\" events are supported right now", err.Error())
 }
 
 func TestNewWebhooksManager_NoKind(t *testing.T) {
@@ -136,7 +138,7 @@ func TestNewWebhooksManager_UnsupportedKind(t *testing.T) {
 	configs[0].Kind = unsupportedKind
 	_, err := webhooks.NewMultiWebhookSender(configs, clients)
 	Assert(t, err != nil, "expected error")
-	Equals(t, "\"kind: badkind\" not supported. Only \"kind: slack\" and \"kind: http\" are supported right now", err.Error())
+	Equals(t, "\"kind: badkind\" not supported. Only \"slack\", \"http\", \"teams\", \"pagerduty\", \"opsgenie\", \"cloudevents\", \"datadog\", \"email\" and \"jira\" kinds are supported right now", err.Error())
 }
 
 func TestNewWebhooksManager_NoConfigSuccess(t *testing.T) {
@@ -217,3 +219,60 @@ func TestSend_MultipleSuccess(t *testing.T) {
 		s.VerifyWasCalledOnce().Send(logger, result)
 	}
 }
+
+func TestSend_RoutesByEventRepoAndProject(t *testing.T) {
+	t.Log("Results should only be sent to webhooks whose event, repo and project match")
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	configs := []webhooks.Config{
+		{Event: webhooks.ApplyEvent, Kind: webhooks.HttpKind, URL: server.URL + "/infra", RepoRegex: "^acme/infra$"},
+		{Event: webhooks.ApplyEvent, Kind: webhooks.HttpKind, URL: server.URL + "/prod", ProjectRegex: "^prod-"},
+		{Event: webhooks.PlanEvent, Kind: webhooks.HttpKind, URL: server.URL + "/plans"},
+	}
+	m, err := webhooks.NewMultiWebhookSender(configs, validClients())
+	Ok(t, err)
+
+	logger := logging.NewNoopLogger(t)
+	Ok(t, m.Send(logger, webhooks.ApplyResult{Repo: models.Repo{FullName: "acme/infra"}, ProjectName: "staging"}))
+	Equals(t, []string{"/infra"}, received)
+
+	received = nil
+	Ok(t, m.Send(logger, webhooks.ApplyResult{Event: webhooks.ApplyEvent, Repo: models.Repo{FullName: "acme/app"}, ProjectName: "prod-app"}))
+	Equals(t, []string{"/prod"}, received)
+
+	received = nil
+	Ok(t, m.Send(logger, webhooks.ApplyResult{Event: webhooks.PlanEvent, Repo: models.Repo{FullName: "acme/infra"}, ProjectName: "prod-app"}))
+	Equals(t, []string{"/plans"}, received)
+}
+
+func TestNewWebhooksManager_SlackIncomingWebhook(t *testing.T) {
+	t.Log("A slack webhook with a url shouldn't require a slack-token or channel")
+	RegisterMockTestingT(t)
+	clients := validClients()
+	When(clients.Slack.TokenIsSet()).ThenReturn(false)
+
+	configs := []webhooks.Config{{Event: webhooks.DriftEvent, Kind: webhooks.SlackKind, URL: "https://hooks.slack.com/services/x"}}
+	m, err := webhooks.NewMultiWebhookSender(configs, clients)
+	Ok(t, err)
+	Equals(t, 1, len(m.Webhooks))
+}
+
+func TestNewWebhooksManager_InvalidTemplate(t *testing.T) {
+	RegisterMockTestingT(t)
+	clients := validClients()
+	When(clients.Slack.TokenIsSet()).ThenReturn(true)
+
+	configs := validConfigs()
+	configs[0].Template = "{{ .Workspace"
+	_, err := webhooks.NewMultiWebhookSender(configs, clients)
+	ErrContains(t, "parsing webhook template", err)
+
+	configs = []webhooks.Config{{Event: webhooks.ApplyEvent, Kind: webhooks.HttpKind, URL: "https://example.com", Template: "{{ .Workspace }}"}}
+	_, err = webhooks.NewMultiWebhookSender(configs, clients)
+	ErrEquals(t, "\"template\" is only supported for webhooks of \"kind: slack\" and \"kind: email\"", err)
+}
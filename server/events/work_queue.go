@@ -0,0 +1,342 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// WorkItem is a command dispatched through the work queue.
+type WorkItem struct {
+	// CorrelationID is the correlation ID of the webhook the command was
+	// requested by, so the logs of the frontend and the worker are correlated.
+	CorrelationID string
+	// Autoplan is true for autoplans, in which case Command is nil and
+	// HeadRepo and Pull are set.
+	Autoplan bool
+	BaseRepo models.Repo
+	HeadRepo *models.Repo
+	Pull     *models.PullRequest
+	User     models.User
+	PullNum  int
+	Command  *CommentCommand
+	// EnqueuedAt is when the command was dispatched.
+	EnqueuedAt time.Time
+}
+
+// CloneCredentials add the VCS credentials of Atlantis to the clone URLs of
+// repos.
+type CloneCredentials interface {
+	WithCloneCredentials(repo models.Repo) (models.Repo, error)
+}
+
+// WithoutCloneCredentials returns a copy of the item whose repos' clone URLs
+// have no credentials, for the queues to not store the VCS tokens. The workers
+// add them back with CloneCredentials, so tokens that expired while the item
+// was queued, ex. of GitHub App installations, aren't used either.
+func (i WorkItem) WithoutCloneCredentials() WorkItem {
+	i.BaseRepo = withoutCloneCredentials(i.BaseRepo)
+	if i.HeadRepo != nil {
+		headRepo := withoutCloneCredentials(*i.HeadRepo)
+		i.HeadRepo = &headRepo
+	}
+	if i.Pull != nil {
+		pull := *i.Pull
+		pull.BaseRepo = withoutCloneCredentials(pull.BaseRepo)
+		i.Pull = &pull
+	}
+	return i
+}
+
+// withCloneCredentials returns a copy of the item whose repos' clone URLs have
+// the credentials of credentials.
+func (i WorkItem) withCloneCredentials(credentials CloneCredentials) (WorkItem, error) {
+	var err error
+	if i.BaseRepo, err = credentials.WithCloneCredentials(i.BaseRepo); err != nil {
+		return i, err
+	}
+	if i.HeadRepo != nil {
+		headRepo, err := credentials.WithCloneCredentials(*i.HeadRepo)
+		if err != nil {
+			return i, err
+		}
+		i.HeadRepo = &headRepo
+	}
+	if i.Pull != nil {
+		pull := *i.Pull
+		if pull.BaseRepo, err = credentials.WithCloneCredentials(pull.BaseRepo); err != nil {
+			return i, err
+		}
+		i.Pull = &pull
+	}
+	return i, nil
+}
+
+// withoutCloneCredentials returns repo with the credentials removed from its
+// clone URLs.
+func withoutCloneCredentials(repo models.Repo) models.Repo {
+	if repo.CloneURL == "" {
+		return repo
+	}
+	cloneURL, err := url.Parse(repo.CloneURL)
+	if err != nil {
+		// The clone URL can't be stored without knowing where its
+		// credentials are.
+		repo.CloneURL = ""
+	} else {
+		cloneURL.User = nil
+		repo.CloneURL = cloneURL.String()
+	}
+	repo.SanitizedCloneURL = repo.CloneURL
+	return repo
+}
+
+// WorkQueue is a durable queue the frontends dispatch commands to and the
+// workers run them from.
+type WorkQueue interface {
+	// Enqueue adds item to the queue.
+	Enqueue(item WorkItem) error
+	// Dequeue blocks until an item is available, returning it along with its
+	// delivery, or until ctx is done. The item is kept from the other workers
+	// until the delivery is acknowledged or released, or until the worker
+	// stops keeping it alive, ex. because it crashed.
+	Dequeue(ctx context.Context) (WorkItem, WorkDelivery, error)
+}
+
+// WorkDelivery is the delivery of an item of the work queue to a worker.
+type WorkDelivery interface {
+	// Ack removes the item from the queue once it's been handled.
+	Ack() error
+	// Release stops keeping the item from the other workers without handling
+	// it, so it's delivered again.
+	Release()
+}
+
+// PullLocks lock pull requests across workers. The commands of a pull request
+// share its working dir so they're run one at a time.
+type PullLocks interface {
+	// TryLockPull locks the pull request unless another worker holds its
+	// lock, returning a function unlocking it and whether it was locked.
+	TryLockPull(repoFullName string, pullNum int) (func(), bool, error)
+	// LockPull blocks until no other worker holds the lock of the pull
+	// request, or until ctx is done, returning a function unlocking it.
+	LockPull(ctx context.Context, repoFullName string, pullNum int) (func(), error)
+}
+
+// QueuedCommandRunner is the CommandRunner of frontends. It dispatches the
+// commands to the workers instead of running them.
+type QueuedCommandRunner struct {
+	Queue WorkQueue
+	// VCSClient comments on the pull request when a command can't be
+	// dispatched.
+	VCSClient vcs.Client
+}
+
+func (q *QueuedCommandRunner) RunCommentCommand(logger logging.SimpleLogging, baseRepo models.Repo, maybeHeadRepo *models.Repo, maybePull *models.PullRequest, user models.User, pullNum int, cmd *CommentCommand) {
+	q.enqueue(logger, WorkItem{
+		BaseRepo: baseRepo,
+		HeadRepo: maybeHeadRepo,
+		Pull:     maybePull,
+		User:     user,
+		PullNum:  pullNum,
+		Command:  cmd,
+	})
+}
+
+func (q *QueuedCommandRunner) RunAutoplanCommand(logger logging.SimpleLogging, baseRepo models.Repo, headRepo models.Repo, pull models.PullRequest, user models.User) {
+	q.enqueue(logger, WorkItem{
+		Autoplan: true,
+		BaseRepo: baseRepo,
+		HeadRepo: &headRepo,
+		Pull:     &pull,
+		User:     user,
+		PullNum:  pull.Num,
+	})
+}
+
+func (q *QueuedCommandRunner) enqueue(logger logging.SimpleLogging, item WorkItem) {
+	item.CorrelationID = logging.CorrelationID(logger)
+	item.EnqueuedAt = time.Now()
+	if err := q.Queue.Enqueue(item); err != nil {
+		logger.Err("unable to dispatch %s to the workers: %s", item, err)
+		comment := fmt.Sprintf("**Error:** unable to dispatch the command to the workers: %s", err)
+		if commentErr := q.VCSClient.CreateComment(logger, item.BaseRepo, item.PullNum, comment, ""); commentErr != nil {
+			logger.Err("unable to comment: %s", commentErr)
+		}
+		return
+	}
+	logger.Info("dispatched %s to the workers", item)
+}
+
+func (i WorkItem) String() string {
+	if i.Autoplan {
+		return "autoplan"
+	}
+	if i.Command == nil {
+		return "a command"
+	}
+	return fmt.Sprintf("the %s command", i.Command.Name)
+}
+
+// Worker runs the commands of the work queue.
+type Worker struct {
+	Queue     WorkQueue
+	PullLocks PullLocks
+	// CommandRunner runs the commands in process.
+	CommandRunner CommandRunner
+	// Drainer is checked so commands dequeued while shutting down are left in
+	// the queue, to be run once the worker restarts, instead of being
+	// rejected.
+	Drainer *Drainer
+	Logger  logging.SimpleLogging
+	// Concurrency is how many commands are run at once.
+	Concurrency int
+	// CloneCredentials add the credentials to the clone URLs of the repos of
+	// the items, see WorkItem.WithoutCloneCredentials.
+	CloneCredentials CloneCredentials
+}
+
+// Run runs commands until ctx is done, waiting for the running ones to
+// complete before returning.
+func (w *Worker) Run(ctx context.Context) {
+	// slots holds a value for each command that's run, commands waiting for
+	// another worker to run a command of their pull request don't take one.
+	slots := make(chan struct{}, max(w.Concurrency, 1))
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		// Wait for a free slot before dequeuing, without holding it while the
+		// queue is empty so the commands that waited for their pull request
+		// can take it.
+		select {
+		case <-ctx.Done():
+			return
+		case slots <- struct{}{}:
+			<-slots
+		}
+		item, delivery, err := w.Queue.Dequeue(ctx)
+		if ctx.Err() != nil {
+			if err == nil {
+				delivery.Release()
+			}
+			return
+		}
+		if err != nil {
+			w.Logger.Err("unable to dequeue a command: %s", err)
+			// Don't spin while the queue is unavailable.
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(5 * time.Second):
+			}
+			continue
+		}
+		if w.Drainer != nil && w.Drainer.GetStatus().ShuttingDown {
+			// The item is delivered again once the worker restarts.
+			delivery.Release()
+			return
+		}
+		select {
+		case <-ctx.Done():
+			delivery.Release()
+			return
+		case slots <- struct{}{}:
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.run(ctx, item, delivery, slots)
+		}()
+	}
+}
+
+// run runs the command of item, which holds a slot, once it has the lock of
+// its pull request.
+func (w *Worker) run(ctx context.Context, item WorkItem, delivery WorkDelivery, slots chan struct{}) {
+	logger := w.Logger.With(
+		logging.CorrelationIDKey, item.CorrelationID,
+		"repo", item.BaseRepo.FullName,
+		"pull", strconv.Itoa(item.PullNum),
+	)
+	unlock, locked, err := w.PullLocks.TryLockPull(item.BaseRepo.FullName, item.PullNum)
+	if err == nil && !locked {
+		// Another worker runs a command of the pull request, the slot is
+		// freed for the commands of other pull requests meanwhile.
+		<-slots
+		logger.Debug("waiting for another worker to run a command of the pull request to run %s", item)
+		unlock, err = w.PullLocks.LockPull(ctx, item.BaseRepo.FullName, item.PullNum)
+		if err == nil {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				unlock()
+				err = ctx.Err()
+			}
+		}
+		if err != nil {
+			w.release(ctx, logger, item, delivery, err)
+			return
+		}
+	} else if err != nil {
+		<-slots
+		w.release(ctx, logger, item, delivery, err)
+		return
+	}
+	defer func() { <-slots }()
+	defer unlock()
+
+	if w.Drainer != nil && w.Drainer.GetStatus().ShuttingDown {
+		delivery.Release()
+		return
+	}
+	logger.Info("running %s dispatched %s ago", item, time.Since(item.EnqueuedAt).Round(time.Millisecond))
+	if w.CloneCredentials != nil {
+		item, err = item.withCloneCredentials(w.CloneCredentials)
+	}
+	if err != nil {
+		logger.Err("unable to add the VCS credentials to the clone URLs of %s: %s", item, err)
+	} else if err := runWorkItem(w.CommandRunner, logger, item); err != nil {
+		logger.Err("unable to run %s: %s", item, err)
+	}
+	if err := delivery.Ack(); err != nil {
+		logger.Err("unable to acknowledge %s, it may run again: %s", item, err)
+	}
+}
+
+// release releases the delivery of item, whose pull request couldn't be
+// locked because of err, so it's run again.
+func (w *Worker) release(ctx context.Context, logger logging.SimpleLogging, item WorkItem, delivery WorkDelivery, err error) {
+	delivery.Release()
+	if ctx.Err() != nil {
+		// The item is run once the worker restarts.
+		return
+	}
+	logger.Err("unable to lock the pull request to run %s, it will run again: %s", item, err)
+}
+
+// runWorkItem runs the command of item with runner.
+func runWorkItem(runner CommandRunner, logger logging.SimpleLogging, item WorkItem) error {
+	if item.Autoplan {
+		if item.HeadRepo == nil || item.Pull == nil {
+			return errors.New("autoplan without a head repo and pull request")
+		}
+		runner.RunAutoplanCommand(logger, item.BaseRepo, *item.HeadRepo, *item.Pull, item.User)
+		return nil
+	}
+	if item.Command == nil {
+		return errors.New("no command")
+	}
+	runner.RunCommentCommand(logger, item.BaseRepo, item.HeadRepo, item.Pull, item.User, item.PullNum, item.Command)
+	return nil
+}
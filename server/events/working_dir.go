@@ -14,6 +14,7 @@
 package events
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -22,6 +23,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/core/runtime"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/logging"
@@ -32,6 +34,10 @@ const workingDirPrefix = "repos"
 
 const prSourceRemote = "source"
 
+// mergeCommitMsg is the message of the commits merging pull requests into
+// their base branch with the merge checkout strategy.
+const mergeCommitMsg = "atlantis-merge"
+
 var cloneLocks sync.Map
 var recheckRequiredMap sync.Map
 
@@ -50,6 +56,11 @@ type WorkingDir interface {
 	// If workspace does not exist on disk, error will be of type os.IsNotExist.
 	GetWorkingDir(r models.Repo, p models.PullRequest, workspace string) (string, error)
 	HasDiverged(logger logging.SimpleLogging, cloneDir string) bool
+	// GetBaseCommit returns the commit of the base branch the working dir at
+	// cloneDir was checked out with.
+	GetBaseCommit(logger logging.SimpleLogging, cloneDir string, p models.PullRequest) (string, error)
+	// GetBaseHead returns the current head commit of the base branch of p.
+	GetBaseHead(logger logging.SimpleLogging, p models.PullRequest) (string, error)
 	GetPullDir(r models.Repo, p models.PullRequest) (string, error)
 	// Delete deletes the workspace for this repo and pull.
 	Delete(logger logging.SimpleLogging, r models.Repo, p models.PullRequest) error
@@ -68,6 +79,10 @@ type FileWorkspace struct {
 	// If this is false, then we will check out the head branch from the pull
 	// request.
 	CheckoutMerge bool
+	// GlobalCfg is the server-side repo config whose checkout_strategy, if
+	// set, overrides CheckoutMerge for the matching repos. If nil,
+	// CheckoutMerge is used for all repos.
+	GlobalCfg *valid.GlobalCfg
 	// CheckoutDepth is how many commits of feature branch and main branch we'll
 	// retrieve by default. If their merge base is not retrieved with this depth,
 	// full fetch will be performed. Only matters if CheckoutMerge=true.
@@ -86,6 +101,9 @@ type FileWorkspace struct {
 	GpgNoSigningEnabled bool
 	// flag indicating if we have to merge with potential new changes upstream (directly after grabbing project lock)
 	CheckForUpstreamChanges bool
+	// WarmPool, if set, provides pre-cloned working dirs to the clones of
+	// the merge checkout strategy.
+	WarmPool *WarmPool
 }
 
 // Clone git clones headRepo, checks out the branch and then returns the absolute
@@ -156,7 +174,7 @@ func (w *FileWorkspace) MergeAgain(
 	p models.PullRequest,
 	workspace string) (bool, error) {
 
-	if !w.CheckoutMerge {
+	if !w.checkoutMerge(p.BaseRepo) {
 		return false, nil
 	}
 
@@ -195,7 +213,7 @@ func (w *FileWorkspace) MergeAgain(
 // If there are any errors we return true since we prefer to assume divergence
 // for safety.
 func (w *FileWorkspace) recheckDiverged(logger logging.SimpleLogging, p models.PullRequest, headRepo models.Repo, cloneDir string) bool {
-	if !w.CheckoutMerge {
+	if !w.checkoutMerge(p.BaseRepo) {
 		// It only makes sense to warn that main has diverged if we're using
 		// the checkout merge strategy. If we're just checking out the branch,
 		// then it doesn't matter what's going on with main because we've
@@ -235,7 +253,7 @@ func (w *FileWorkspace) recheckDiverged(logger logging.SimpleLogging, p models.P
 }
 
 func (w *FileWorkspace) HasDiverged(logger logging.SimpleLogging, cloneDir string) bool {
-	if !w.CheckoutMerge {
+	if !w.isMergeCheckout(cloneDir) {
 		// Both the diverged warning and the UnDiverged apply requirement only apply to merge checkout strategy so
 		// we assume false here for 'branch' strategy.
 		return false
@@ -261,6 +279,77 @@ func (w *FileWorkspace) HasDiverged(logger logging.SimpleLogging, cloneDir strin
 	return hasDiverged
 }
 
+// GetBaseCommit returns the commit of the base branch the pull request was
+// merged into with the merge strategy. With the branch strategy, the clone
+// doesn't include the base branch so its current head is returned.
+func (w *FileWorkspace) GetBaseCommit(logger logging.SimpleLogging, cloneDir string, p models.PullRequest) (string, error) {
+	if !w.isMergeCheckout(cloneDir) {
+		return w.GetBaseHead(logger, p)
+	}
+	revParseCmd := exec.Command("git", "rev-parse", "refs/remotes/origin/"+p.BaseBranch) // nolint: gosec
+	revParseCmd.Dir = cloneDir
+	output, err := revParseCmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("getting the commit of origin/%s: %s: %w", p.BaseBranch, string(output), err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetBaseHead returns the current head commit of the base branch of p on the
+// VCS host.
+func (w *FileWorkspace) GetBaseHead(logger logging.SimpleLogging, p models.PullRequest) (string, error) {
+	baseCloneURL := p.BaseRepo.CloneURL
+	if w.TestingOverrideBaseCloneURL != "" {
+		baseCloneURL = w.TestingOverrideBaseCloneURL
+	}
+	lsRemoteCmd := exec.Command("git", "ls-remote", baseCloneURL, "refs/heads/"+p.BaseBranch) // nolint: gosec
+	output, err := lsRemoteCmd.CombinedOutput()
+	if err != nil {
+		sanitizedOutput := w.sanitizeGitCredentials(string(output), p.BaseRepo, p.BaseRepo)
+		return "", fmt.Errorf("listing the head of %s: %s: %w", p.BaseBranch, sanitizedOutput, err)
+	}
+	sha, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\t")
+	if sha == "" {
+		return "", fmt.Errorf("base branch %s not found", p.BaseBranch)
+	}
+	logger.Debug("head of base branch %s is %s", p.BaseBranch, sha)
+	return sha, nil
+}
+
+// checkoutMerge returns true if pull requests of repo are checked out with
+// the merge strategy. Gerrit changes always are since the refs of their patch
+// sets can't be cloned as branches.
+func (w *FileWorkspace) checkoutMerge(repo models.Repo) bool {
+	if repo.VCSHost.Type == models.Gerrit {
+		return true
+	}
+	if w.GlobalCfg != nil {
+		switch w.GlobalCfg.CheckoutStrategy(repo.ID()) {
+		case valid.CheckoutStrategyMerge:
+			return true
+		case valid.CheckoutStrategyBranch:
+			return false
+		}
+	}
+	return w.CheckoutMerge
+}
+
+// isMergeCheckout returns true if cloneDir was checked out with the merge
+// strategy. Since the strategy can be set per repo, the checkout is checked
+// for our merge commit unless all repos use CheckoutMerge.
+func (w *FileWorkspace) isMergeCheckout(cloneDir string) bool {
+	if w.GlobalCfg == nil {
+		return w.CheckoutMerge
+	}
+	logCmd := exec.Command("git", "log", "-1", "--format=%s")
+	logCmd.Dir = cloneDir
+	output, err := logCmd.Output()
+	if err != nil {
+		return w.CheckoutMerge
+	}
+	return strings.TrimSpace(string(output)) == mergeCommitMsg
+}
+
 func (w *FileWorkspace) remoteHasBranch(logger logging.SimpleLogging, c wrappedGitContext, branch string) bool {
 	ref := "refs/remotes/origin/" + branch
 
@@ -281,7 +370,7 @@ func (w *FileWorkspace) updateToRef(logger logging.SimpleLogging, c wrappedGitCo
 	}
 
 	// For branch strategy it's easy: just *go to* the ref we're supposed to be at.
-	if !w.CheckoutMerge {
+	if !w.checkoutMerge(c.pr.BaseRepo) {
 		// If targetRef names a remote-tracking branch (e.g. "master"), reset
 		// to origin/<branch>. `git fetch --all` updates origin/<branch> but
 		// not the local branch ref, so resetting to the bare branch name
@@ -330,7 +419,7 @@ func (w *FileWorkspace) isBranchAtTargetRef(logger logging.SimpleLogging, c wrap
 	// because we'll already have performed a merge. Instead, we'll check
 	// HEAD^2 since that will be the commit before our merge.
 	pullHead := "HEAD"
-	if w.CheckoutMerge && c.pr.Num > 0 {
+	if w.checkoutMerge(c.pr.BaseRepo) && c.pr.Num > 0 {
 		pullHead = "HEAD^2"
 	}
 	revParseCmd := exec.Command("git", "rev-parse", pullHead) // #nosec
@@ -354,12 +443,6 @@ func (w *FileWorkspace) forceClone(logger logging.SimpleLogging, c wrappedGitCon
 		return fmt.Errorf("deleting dir '%s' before cloning: %w", c.dir, err)
 	}
 
-	// Create the directory and parents if necessary.
-	logger.Info("creating dir '%s'", c.dir)
-	if err := os.MkdirAll(c.dir, 0700); err != nil {
-		return fmt.Errorf("creating new workspace: %w", err)
-	}
-
 	// During testing, we mock some of this out.
 	headCloneURL := c.head.CloneURL
 	if w.TestingOverrideHeadCloneURL != "" {
@@ -370,8 +453,31 @@ func (w *FileWorkspace) forceClone(logger logging.SimpleLogging, c wrappedGitCon
 		baseCloneURL = w.TestingOverrideBaseCloneURL
 	}
 
+	if w.WarmPool != nil && w.checkoutMerge(c.pr.BaseRepo) {
+		if err := os.MkdirAll(filepath.Dir(c.dir), 0700); err != nil {
+			return fmt.Errorf("creating new workspace: %w", err)
+		}
+		if w.WarmPool.Take(logger, c.pr.BaseRepo, c.pr.BaseBranch, c.dir) {
+			err := w.useWarmDir(logger, c, baseCloneURL, headCloneURL)
+			var conflictErr *MergeConflictError
+			if err == nil || errors.As(err, &conflictErr) {
+				return err
+			}
+			logger.Warn("unable to use the warm working dir, cloning instead: %s", err)
+			if err := os.RemoveAll(c.dir); err != nil {
+				return fmt.Errorf("deleting dir '%s' before cloning: %w", c.dir, err)
+			}
+		}
+	}
+
+	// Create the directory and parents if necessary.
+	logger.Info("creating dir '%s'", c.dir)
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return fmt.Errorf("creating new workspace: %w", err)
+	}
+
 	// if branch strategy, use depth=1
-	if !w.CheckoutMerge {
+	if !w.checkoutMerge(c.pr.BaseRepo) {
 		return w.wrappedGit(logger, c, "clone", "--depth=1", "--branch", c.pr.HeadBranch, "--single-branch", headCloneURL, c.dir)
 	}
 
@@ -400,6 +506,38 @@ func (w *FileWorkspace) forceClone(logger logging.SimpleLogging, c wrappedGitCon
 	return w.mergeToBaseBranch(logger, c)
 }
 
+// useWarmDir brings the warm working dir taken into c.dir up to date with the
+// base branch, then merges the pull request into it like a new clone.
+func (w *FileWorkspace) useWarmDir(logger logging.SimpleLogging, c wrappedGitContext, baseCloneURL string, headCloneURL string) error {
+	// The credentials of the clone URL may have changed since it was warmed.
+	if err := w.wrappedGit(logger, c, "remote", "set-url", "origin", baseCloneURL); err != nil {
+		return err
+	}
+	fetchArgs := []string{"fetch"}
+	if w.CheckoutDepth > 0 {
+		fetchArgs = append(fetchArgs, "--depth", fmt.Sprint(w.CheckoutDepth))
+	}
+	if err := w.wrappedGit(logger, c, append(fetchArgs, "origin")...); err != nil {
+		return err
+	}
+	if err := w.wrappedGit(logger, c, "checkout", "-q", "-f", "-B", c.pr.BaseBranch, fmt.Sprintf("refs/remotes/origin/%s", c.pr.BaseBranch)); err != nil {
+		return err
+	}
+	// Keep the initialized .terraform dirs, even if they aren't ignored.
+	if err := w.wrappedGit(logger, c, "clean", "-q", "-f", "-d", "-e", ".terraform"); err != nil {
+		return err
+	}
+	if err := w.wrappedGit(logger, c, "remote", "add", prSourceRemote, headCloneURL); err != nil {
+		return err
+	}
+	if w.GpgNoSigningEnabled {
+		if err := w.wrappedGit(logger, c, "config", "--local", "commit.gpgsign", "false"); err != nil {
+			return err
+		}
+	}
+	return w.mergeToBaseBranch(logger, c)
+}
+
 // There is a new upstream update that we need, and we want to update to it
 // without deleting any existing plans
 func (w *FileWorkspace) mergeAgain(logger logging.SimpleLogging, c wrappedGitContext) error {
@@ -449,6 +587,11 @@ func (w *FileWorkspace) mergeToBaseBranch(logger logging.SimpleLogging, c wrappe
 		fetchRef = fmt.Sprintf("pull/%d/head:", c.pr.Num)
 		fetchRemote = "origin"
 	}
+	// Gerrit changes are refs of their patch sets, ex. refs/changes/45/12345/3.
+	if strings.HasPrefix(c.pr.HeadBranch, "refs/") {
+		fetchRef = fmt.Sprintf("+%s:", c.pr.HeadBranch)
+		fetchRemote = prSourceRemote
+	}
 
 	// if no checkout depth, omit depth arg
 	if w.CheckoutDepth == 0 {
@@ -481,7 +624,48 @@ func (w *FileWorkspace) mergeToBaseBranch(logger logging.SimpleLogging, c wrappe
 	// git rev-parse HEAD^2 to get the head commit because it will
 	// always succeed whereas without --no-ff, if the merge was fast
 	// forwarded then git rev-parse HEAD^2 would fail.
-	return w.wrappedGit(logger, c, "merge", "-q", "--no-ff", "-m", "atlantis-merge", "FETCH_HEAD")
+	mergeErr := w.wrappedGit(logger, c, "merge", "-q", "--no-ff", "-m", mergeCommitMsg, "FETCH_HEAD")
+	if mergeErr == nil {
+		return nil
+	}
+	conflicts, err := w.conflictingFiles(c)
+	if err != nil || len(conflicts) == 0 {
+		return mergeErr
+	}
+	if err := w.wrappedGit(logger, c, "merge", "--abort"); err != nil {
+		logger.Warn("unable to abort conflicting merge: %s", err)
+	}
+	return &MergeConflictError{BaseBranch: c.pr.BaseBranch, Files: conflicts}
+}
+
+// conflictingFiles returns the files with conflicts after a failed merge.
+func (w *FileWorkspace) conflictingFiles(c wrappedGitContext) ([]string, error) {
+	diffCmd := exec.Command("git", "diff", "--name-only", "--diff-filter=U")
+	diffCmd.Dir = c.dir
+	output, err := diffCmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, file := range strings.Split(string(output), "\n") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// MergeConflictError is returned when cloning with the merge strategy fails
+// because the pull request conflicts with its base branch.
+type MergeConflictError struct {
+	BaseBranch string
+	// Files are the files with conflicts.
+	Files []string
+}
+
+func (m *MergeConflictError) Error() string {
+	return fmt.Sprintf("the pull request can't be merged into %q because of conflicts in these files, resolve them and push again:\n  %s",
+		m.BaseBranch, strings.Join(m.Files, "\n  "))
 }
 
 // GetWorkingDir returns the path to the workspace for this repo and pull.
@@ -34,6 +34,11 @@ type WorkingDirLocker interface {
 	// an error if the workspace is already locked. The error is expected to
 	// be printed to the pull request.
 	TryLock(repoFullName string, pullNum int, workspace string, path string, projectName string, cmdName command.Name) (func(), error)
+	// TryLockWorkspace tries to acquire a lock for the whole workspace of this
+	// repo and pull, ex. to delete its working dir. It returns an error if
+	// any of its paths is locked, and its paths can't be locked until it's
+	// unlocked.
+	TryLockWorkspace(repoFullName string, pullNum int, workspace string, cmdName command.Name) (func(), error)
 	// UnlockByPull unlocks all workspaces for a specific pull request
 	UnlockByPull(repoFullName string, pullNum int)
 }
@@ -57,7 +62,11 @@ func (d *DefaultWorkingDirLocker) TryLock(repoFullName string, pullNum int, work
 	defer d.mutex.Unlock()
 
 	workspaceKey := d.workspaceKey(repoFullName, pullNum, workspace, path, projectName)
-	if currentLock, exists := d.locks[workspaceKey]; exists {
+	currentLock, exists := d.locks[workspaceKey]
+	if !exists {
+		currentLock, exists = d.locks[d.workspaceKey(repoFullName, pullNum, workspace, "", "")]
+	}
+	if exists {
 		return func() {}, fmt.Errorf("cannot run %q: the %s workspace at path %s is currently locked for this pull request by %q.\n"+
 			"Wait until the previous command is complete and try again", cmdName, workspace, path, currentLock)
 	}
@@ -67,6 +76,22 @@ func (d *DefaultWorkingDirLocker) TryLock(repoFullName string, pullNum int, work
 	}, nil
 }
 
+func (d *DefaultWorkingDirLocker) TryLockWorkspace(repoFullName string, pullNum int, workspace string, cmdName command.Name) (func(), error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	workspaceKey := d.workspaceKey(repoFullName, pullNum, workspace, "", "")
+	for key, currentLock := range d.locks {
+		if key == workspaceKey || strings.HasPrefix(key, workspaceKey+"/") {
+			return func() {}, fmt.Errorf("cannot run %q: the %s workspace is currently locked for this pull request by %q", cmdName, workspace, currentLock)
+		}
+	}
+	d.locks[workspaceKey] = cmdName
+	return func() {
+		d.unlock(repoFullName, pullNum, workspace, "", "")
+	}, nil
+}
+
 // UnlockByPull unlocks all workspaces for a specific pull request
 func (d *DefaultWorkingDirLocker) UnlockByPull(repoFullName string, pullNum int) {
 	d.mutex.Lock()
@@ -224,3 +224,28 @@ func TestUnlockDifferentProjectNames(t *testing.T) {
 	_, err = locker.TryLock(repo, 1, workspace, path, newProjectName, cmd)
 	Ok(t, err)
 }
+
+func TestTryLockWorkspace(t *testing.T) {
+	locker := events.NewDefaultWorkingDirLocker()
+
+	unlockFn, err := locker.TryLock(repo, 1, workspace, "prod", projectName, cmd)
+	Ok(t, err)
+	// The workspace can't be locked while one of its paths is.
+	_, err = locker.TryLockWorkspace(repo, 1, workspace, command.Unlock)
+	ErrEquals(t, "cannot run \"unlock\": the default workspace is currently locked for this pull request by \"plan\"", err)
+	// Other workspaces can be locked.
+	unlockWorkspaceFn, err := locker.TryLockWorkspace(repo, 1, "default2", command.Unlock)
+	Ok(t, err)
+	unlockWorkspaceFn()
+
+	unlockFn()
+	unlockWorkspaceFn, err = locker.TryLockWorkspace(repo, 1, workspace, command.Unlock)
+	Ok(t, err)
+	// Its paths can't be locked until it's unlocked.
+	_, err = locker.TryLock(repo, 1, workspace, "prod", projectName, cmd)
+	ErrEquals(t, "cannot run \"plan\": the default workspace at path prod is currently locked for this pull request by \"unlock\".\n"+
+		"Wait until the previous command is complete and try again", err)
+	unlockWorkspaceFn()
+	_, err = locker.TryLock(repo, 1, workspace, "prod", projectName, cmd)
+	Ok(t, err)
+}
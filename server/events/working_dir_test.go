@@ -5,6 +5,7 @@ package events_test
 
 import (
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/events"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/logging"
@@ -308,12 +310,62 @@ func TestClone_CheckoutMergeConflict(t *testing.T) {
 		BaseBranch: "main",
 	}, "default")
 
-	ErrContains(t, "running git merge -q --no-ff -m atlantis-merge FETCH_HEAD", err)
-	ErrContains(t, "Auto-merging file", err)
-	ErrContains(t, "CONFLICT (add/add)", err)
-	ErrContains(t, "Merge conflict in file", err)
-	ErrContains(t, "Automatic merge failed; fix conflicts and then commit the result.", err)
-	ErrContains(t, "exit status 1", err)
+	var conflictErr *events.MergeConflictError
+	Assert(t, errors.As(err, &conflictErr), "expected a merge conflict error, got %v", err)
+	Equals(t, &events.MergeConflictError{BaseBranch: "main", Files: []string{"file"}}, conflictErr)
+	ErrEquals(t, "the pull request can't be merged into \"main\" because of conflicts in these files, resolve them and push again:\n  file", err)
+}
+
+// Test that the checkout strategy of the server-side repo config overrides
+// CheckoutMerge.
+func TestClone_RepoCheckoutStrategy(t *testing.T) {
+	repoDir := initRepo(t)
+	runCmd(t, repoDir, "git", "checkout", "branch")
+	runCmd(t, repoDir, "touch", "branch-file")
+	runCmd(t, repoDir, "git", "add", "branch-file")
+	runCmd(t, repoDir, "git", "commit", "-m", "branch-commit")
+	branchCommit := runCmd(t, repoDir, "git", "rev-parse", "HEAD")
+	runCmd(t, repoDir, "git", "checkout", "main")
+	runCmd(t, repoDir, "touch", "main-file")
+	runCmd(t, repoDir, "git", "add", "main-file")
+	runCmd(t, repoDir, "git", "commit", "-m", "main-commit")
+
+	overrideURL := fmt.Sprintf("file://%s", repoDir)
+	wd := &events.FileWorkspace{
+		DataDir:       t.TempDir(),
+		CheckoutMerge: false,
+		GlobalCfg: &valid.GlobalCfg{
+			Repos: []valid.Repo{{ID: "github.com/owner/merged", CheckoutStrategy: valid.CheckoutStrategyMerge}},
+		},
+		TestingOverrideHeadCloneURL: overrideURL,
+		TestingOverrideBaseCloneURL: overrideURL,
+		GpgNoSigningEnabled:         true,
+	}
+	logger := logging.NewNoopLogger(t)
+
+	for _, c := range []struct {
+		repo   string
+		merged bool
+	}{
+		{"github.com/owner/merged", true},
+		{"github.com/owner/branch", false},
+	} {
+		t.Run(c.repo, func(t *testing.T) {
+			repo, err := models.NewRepo(models.Github, strings.TrimPrefix(c.repo, "github.com/"), "https://"+c.repo+".git", "", "")
+			Ok(t, err)
+			cloneDir, err := wd.Clone(logger, repo, models.PullRequest{
+				BaseRepo:   repo,
+				HeadBranch: "branch",
+				HeadCommit: strings.TrimSpace(branchCommit),
+				BaseBranch: "main",
+			}, "default")
+			Ok(t, err)
+
+			_, err = os.Stat(filepath.Join(cloneDir, "main-file"))
+			Equals(t, c.merged, err == nil)
+			Equals(t, c.merged, runCmd(t, cloneDir, "git", "log", "-1", "--format=%s") == "atlantis-merge\n")
+		})
+	}
 }
 
 func TestClone_CheckoutMergeShallow(t *testing.T) {
@@ -863,6 +915,57 @@ func TestHasDiverged_MasterHasDiverged(t *testing.T) {
 	Equals(t, hasDiverged, false)
 }
 
+func TestGetBaseCommit(t *testing.T) {
+	repoDir := initRepo(t)
+	runCmd(t, repoDir, "git", "checkout", "branch")
+	runCmd(t, repoDir, "touch", "branch-file")
+	runCmd(t, repoDir, "git", "add", "branch-file")
+	runCmd(t, repoDir, "git", "commit", "-m", "branch-commit")
+	runCmd(t, repoDir, "git", "checkout", "main")
+	mainCommit := strings.TrimSpace(runCmd(t, repoDir, "git", "rev-parse", "HEAD"))
+
+	logger := logging.NewNoopLogger(t)
+	overrideURL := fmt.Sprintf("file://%s", repoDir)
+	pull := models.PullRequest{HeadBranch: "branch", BaseBranch: "main"}
+	for _, checkoutMerge := range []bool{true, false} {
+		wd := &events.FileWorkspace{
+			DataDir:                     t.TempDir(),
+			CheckoutMerge:               checkoutMerge,
+			TestingOverrideHeadCloneURL: overrideURL,
+			TestingOverrideBaseCloneURL: overrideURL,
+			GpgNoSigningEnabled:         true,
+		}
+		cloneDir, err := wd.Clone(logger, models.Repo{}, pull, "default")
+		Ok(t, err)
+		baseCommit, err := wd.GetBaseCommit(logger, cloneDir, pull)
+		Ok(t, err)
+		Equals(t, mainCommit, baseCommit)
+	}
+
+	// The head of the base branch advances but not the base commit of the
+	// merge checkout.
+	wd := &events.FileWorkspace{
+		DataDir:                     t.TempDir(),
+		CheckoutMerge:               true,
+		TestingOverrideHeadCloneURL: overrideURL,
+		TestingOverrideBaseCloneURL: overrideURL,
+		GpgNoSigningEnabled:         true,
+	}
+	cloneDir, err := wd.Clone(logger, models.Repo{}, pull, "default")
+	Ok(t, err)
+	runCmd(t, repoDir, "git", "commit", "--allow-empty", "-m", "main-commit")
+	newMainCommit := strings.TrimSpace(runCmd(t, repoDir, "git", "rev-parse", "HEAD"))
+	baseCommit, err := wd.GetBaseCommit(logger, cloneDir, pull)
+	Ok(t, err)
+	Equals(t, mainCommit, baseCommit)
+	baseHead, err := wd.GetBaseHead(logger, pull)
+	Ok(t, err)
+	Equals(t, newMainCommit, baseHead)
+
+	_, err = wd.GetBaseHead(logger, models.PullRequest{BaseBranch: "missing"})
+	ErrEquals(t, "base branch missing not found", err)
+}
+
 func initRepo(t *testing.T) string {
 	repoDir := t.TempDir()
 	runCmd(t, repoDir, "git", "init", "--initial-branch=main")
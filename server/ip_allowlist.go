@@ -0,0 +1,189 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/scheduled"
+)
+
+// GithubIPRanges is the allowlist entry for the ranges GitHub sends webhooks
+// from, as published by its meta API.
+const GithubIPRanges = "github"
+
+// ipAllowlistRefreshPeriod is how often the ranges published by providers are
+// refreshed.
+const ipAllowlistRefreshPeriod = time.Hour
+
+// IPRangeProvider publishes the ranges a provider sends requests from.
+type IPRangeProvider interface {
+	// Name names the provider in logs, ex. github.
+	Name() string
+	// Ranges fetches the published ranges.
+	Ranges() ([]*net.IPNet, error)
+}
+
+// IPAllowlist is the source IP ranges requests are allowed from. It's run
+// periodically to refresh the ranges published by its providers.
+type IPAllowlist struct {
+	static    []*net.IPNet
+	providers []IPRangeProvider
+	logger    logging.SimpleLogging
+
+	mu sync.RWMutex
+	// published are the ranges last fetched from each provider, they're kept
+	// when refreshing them fails.
+	published map[string][]*net.IPNet
+}
+
+// ParseIPAllowlist parses list, a comma separated list of CIDRs, IPs and
+// GithubIPRanges. githubMetaURL is the URL of the meta API of GitHub the
+// ranges it publishes are fetched from. The published ranges aren't fetched
+// until the allowlist is refreshed.
+func ParseIPAllowlist(list string, githubMetaURL string, logger logging.SimpleLogging) (*IPAllowlist, error) {
+	allowlist := &IPAllowlist{logger: logger, published: make(map[string][]*net.IPNet)}
+	for entry := range strings.SplitSeq(list, ",") {
+		entry = strings.TrimSpace(entry)
+		switch {
+		case entry == "":
+			continue
+		case entry == GithubIPRanges:
+			allowlist.providers = append(allowlist.providers, &GithubMetaRanges{MetaURL: githubMetaURL})
+		case strings.Contains(entry, "/"):
+			_, ipNet, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, err
+			}
+			allowlist.static = append(allowlist.static, ipNet)
+		default:
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("%q is not a CIDR, an IP or %s", entry, GithubIPRanges)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			allowlist.static = append(allowlist.static, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		}
+	}
+	return allowlist, nil
+}
+
+// Run refreshes the ranges published by the providers.
+func (a *IPAllowlist) Run() {
+	for _, provider := range a.providers {
+		ranges, err := provider.Ranges()
+		if err != nil {
+			a.logger.Warn("unable to refresh the IP ranges of %s, keeping the previous ones: %s", provider.Name(), err)
+			continue
+		}
+		a.mu.Lock()
+		a.published[provider.Name()] = ranges
+		a.mu.Unlock()
+	}
+}
+
+// Allows returns whether requests are allowed from remoteAddr, an IP
+// optionally followed by a port.
+func (a *IPAllowlist) Allows(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range a.static {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for _, ranges := range a.published {
+		for _, ipNet := range ranges {
+			if ipNet.Contains(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GithubMetaRanges are the ranges GitHub sends webhooks from.
+type GithubMetaRanges struct {
+	// MetaURL is the URL of the meta API, ex. https://api.github.com/meta.
+	MetaURL string
+}
+
+func (g *GithubMetaRanges) Name() string {
+	return GithubIPRanges
+}
+
+// Ranges fetches the hooks ranges from the meta API.
+func (g *GithubMetaRanges) Ranges() ([]*net.IPNet, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(g.MetaURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: unexpected status %s", g.MetaURL, resp.Status)
+	}
+	var meta struct {
+		Hooks []string `json:"hooks"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&meta); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", g.MetaURL, err)
+	}
+	if len(meta.Hooks) == 0 {
+		return nil, fmt.Errorf("%s published no hooks ranges", g.MetaURL)
+	}
+	var ranges []*net.IPNet
+	for _, cidr := range meta.Hooks {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("parsing hooks range from %s: %w", g.MetaURL, err)
+		}
+		ranges = append(ranges, ipNet)
+	}
+	return ranges, nil
+}
+
+// newIPAllowlist parses list and fetches the ranges published by its
+// providers, which are refreshed periodically by scheduledExecutorService. It
+// returns nil if list is empty.
+func newIPAllowlist(list string, githubHostname string, logger logging.SimpleLogging, scheduledExecutorService *scheduled.ExecutorService) (*IPAllowlist, error) {
+	allowlist, err := ParseIPAllowlist(list, githubMetaURL(githubHostname), logger)
+	if err != nil || (len(allowlist.static) == 0 && len(allowlist.providers) == 0) {
+		return nil, err
+	}
+	if len(allowlist.providers) > 0 {
+		allowlist.Run()
+		scheduledExecutorService.AddJob(scheduled.JobDefinition{
+			Job:    allowlist,
+			Period: ipAllowlistRefreshPeriod,
+		})
+	}
+	return allowlist, nil
+}
+
+// githubMetaURL returns the URL of the meta API of the GitHub at hostname.
+func githubMetaURL(hostname string) string {
+	if hostname == "" || hostname == "github.com" {
+		return "https://api.github.com/meta"
+	}
+	return fmt.Sprintf("https://%s/api/v3/meta", hostname)
+}
@@ -0,0 +1,89 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestIPAllowlist_Allows(t *testing.T) {
+	allowlist, err := server.ParseIPAllowlist("10.0.0.0/8, 192.0.2.1,2001:db8::/32", "", logging.NewNoopLogger(t))
+	Ok(t, err)
+	for addr, exp := range map[string]bool{
+		"10.1.2.3:1234":     true,
+		"192.0.2.1:80":      true,
+		"192.0.2.2:80":      false,
+		"[2001:db8::1]:443": true,
+		"[2001:db9::1]:443": false,
+		"172.16.0.1":        false,
+		"not-an-ip":         false,
+	} {
+		Equals(t, exp, allowlist.Allows(addr))
+	}
+
+	_, err = server.ParseIPAllowlist("10.0.0.0/8,bitbucket", "", nil)
+	ErrEquals(t, `"bitbucket" is not a CIDR, an IP or github`, err)
+}
+
+func TestIPAllowlist_GithubRanges(t *testing.T) {
+	hooks := `["192.30.252.0/22","2606:50c0::/32"]`
+	status := http.StatusOK
+	meta := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"hooks":%s,"web":["140.82.112.0/20"]}`, hooks)
+	}))
+	defer meta.Close()
+
+	allowlist, err := server.ParseIPAllowlist("github", meta.URL, logging.NewNoopLogger(t))
+	Ok(t, err)
+	Assert(t, !allowlist.Allows("192.30.252.1:443"), "exp ranges not to be fetched before refreshing")
+
+	allowlist.Run()
+	Assert(t, allowlist.Allows("192.30.252.1:443"), "exp hooks range to be allowed")
+	Assert(t, allowlist.Allows("[2606:50c0::1]:443"), "exp hooks range to be allowed")
+	Assert(t, !allowlist.Allows("140.82.112.1:443"), "exp web range not to be allowed")
+
+	t.Log("the previous ranges are kept if refreshing fails")
+	status = http.StatusInternalServerError
+	allowlist.Run()
+	Assert(t, allowlist.Allows("192.30.252.1:443"), "exp previous range to be kept")
+
+	status = http.StatusOK
+	hooks = `["185.199.108.0/22"]`
+	allowlist.Run()
+	Assert(t, !allowlist.Allows("192.30.252.1:443"), "exp range to be removed")
+	Assert(t, allowlist.Allows("185.199.108.1:443"), "exp new range to be allowed")
+}
+
+func TestIPAllowlister(t *testing.T) {
+	webhooks, err := server.ParseIPAllowlist("10.0.0.0/8", "", nil)
+	Ok(t, err)
+	allowlister := &server.IPAllowlister{Webhooks: webhooks, Logger: logging.NewNoopLogger(t)}
+	cases := []struct {
+		path       string
+		remoteAddr string
+		expCode    int
+	}{
+		{"/events", "10.0.0.1:1234", http.StatusOK},
+		{"/events", "192.0.2.1:1234", http.StatusForbidden},
+		{"/api/plan", "192.0.2.1:1234", http.StatusOK},
+		{"/healthz", "192.0.2.1:1234", http.StatusOK},
+	}
+	for _, c := range cases {
+		t.Run(c.path+" from "+c.remoteAddr, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", c.path, nil)
+			req.RemoteAddr = c.remoteAddr
+			w := httptest.NewRecorder()
+			allowlister.ServeHTTP(w, req, func(http.ResponseWriter, *http.Request) {})
+			Equals(t, c.expCode, w.Result().StatusCode)
+		})
+	}
+}
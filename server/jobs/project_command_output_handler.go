@@ -21,6 +21,7 @@ type PullInfo struct {
 	PullNum      int
 	Repo         string
 	RepoFullName string
+	VCSHostname  string
 	ProjectName  string
 	Path         string
 	Workspace    string
@@ -33,6 +34,8 @@ type JobIDInfo struct {
 	Time           time.Time
 	TimeFormatted  string
 	JobStep        string
+	// RunID is the run the job is part of, see logging.RunID.
+	RunID string
 }
 
 type PullInfoWithJobIDs struct {
@@ -45,6 +48,8 @@ type JobInfo struct {
 	HeadCommit     string
 	JobDescription string
 	JobStep        string
+	// RunID is the run the job is part of, see logging.RunID.
+	RunID string
 }
 
 type ProjectCmdOutputLine struct {
@@ -69,6 +74,12 @@ type AsyncProjectCommandOutputHandler struct {
 
 	// Tracks all the jobs for a pull request which is used for clean up after a pull request is closed.
 	pullToJobMapping sync.Map
+
+	// forward receives the output lines of jobs, see ForwardOutput.
+	forward func(line *ProjectCmdOutputLine)
+	// relay receives the output lines and completions of jobs, see
+	// RelayOutput.
+	relay func(line *ProjectCmdOutputLine)
 }
 
 //go:generate pegomock generate --package mocks -o mocks/mock_project_command_output_handler.go ProjectCommandOutputHandler
@@ -150,11 +161,13 @@ func (p *AsyncProjectCommandOutputHandler) Send(ctx command.ProjectContext, msg
 				PullNum:      ctx.Pull.Num,
 				Repo:         ctx.BaseRepo.Name,
 				RepoFullName: ctx.BaseRepo.FullName,
+				VCSHostname:  ctx.BaseRepo.VCSHost.Hostname,
 				ProjectName:  ctx.ProjectName,
 				Path:         ctx.RepoRelDir,
 				Workspace:    ctx.Workspace,
 			},
 			JobStep: ctx.CommandName.String(),
+			RunID:   logging.RunID(ctx.Log),
 		},
 		Line:              msg,
 		OperationComplete: operationComplete,
@@ -170,21 +183,44 @@ func (p *AsyncProjectCommandOutputHandler) SendWorkflowHook(ctx models.WorkflowH
 				PullNum:      ctx.Pull.Num,
 				Repo:         ctx.BaseRepo.Name,
 				RepoFullName: ctx.BaseRepo.FullName,
+				VCSHostname:  ctx.BaseRepo.VCSHost.Hostname,
 			},
 			JobDescription: ctx.HookDescription,
 			JobStep:        ctx.HookStepName,
+			RunID:          logging.RunID(ctx.Log),
 		},
 		Line:              msg,
 		OperationComplete: operationComplete,
 	}
 }
 
+// ForwardOutput forwards the output lines of jobs to forward as they're
+// handled, ex. to ship them to log sinks. It must be called before Handle.
+func (p *AsyncProjectCommandOutputHandler) ForwardOutput(forward func(line *ProjectCmdOutputLine)) {
+	p.forward = forward
+}
+
+// RelayOutput relays the output lines of jobs and their completions to relay
+// as they're handled, ex. from the workers running the jobs to the frontends
+// serving the UI, which Receive them. It must be called before Handle.
+func (p *AsyncProjectCommandOutputHandler) RelayOutput(relay func(line *ProjectCmdOutputLine)) {
+	p.relay = relay
+}
+
+// Receive handles the line of a job run by another server, see RelayOutput.
+func (p *AsyncProjectCommandOutputHandler) Receive(line *ProjectCmdOutputLine) {
+	p.projectCmdOutput <- line
+}
+
 func (p *AsyncProjectCommandOutputHandler) Register(jobID string, receiver chan string) {
 	p.addChan(receiver, jobID)
 }
 
 func (p *AsyncProjectCommandOutputHandler) Handle() {
 	for msg := range p.projectCmdOutput {
+		if p.relay != nil {
+			p.relay(msg)
+		}
 		if msg.OperationComplete {
 			p.completeJob(msg.JobID)
 			continue
@@ -201,10 +237,14 @@ func (p *AsyncProjectCommandOutputHandler) Handle() {
 			JobDescription: msg.JobInfo.JobDescription,
 			Time:           time.Now(),
 			JobStep:        msg.JobInfo.JobStep,
+			RunID:          msg.JobInfo.RunID,
 		})
 
 		// Forward new message to all receiver channels and output buffer
 		p.writeLogLine(msg.JobID, msg.Line)
+		if p.forward != nil {
+			p.forward(msg)
+		}
 	}
 }
 
@@ -334,6 +374,59 @@ func (p *AsyncProjectCommandOutputHandler) CleanUp(pullInfo PullInfo) {
 	}
 }
 
+// JobLog describes the output of a completed job kept in memory.
+type JobLog struct {
+	JobID        string
+	RepoFullName string
+	// Size is the number of bytes of output.
+	Size int64
+	// Time is when the job last output a line.
+	Time time.Time
+}
+
+// JobLogs returns the outputs of the completed jobs kept in memory, the
+// outputs of jobs in progress are left out.
+func (p *AsyncProjectCommandOutputHandler) JobLogs() []JobLog {
+	var logs []JobLog
+	p.projectOutputBuffersLock.RLock()
+	defer p.projectOutputBuffersLock.RUnlock()
+	p.pullToJobMapping.Range(func(key, value any) bool {
+		pullInfo := key.(PullInfo)
+		value.(*sync.Map).Range(func(k, v any) bool {
+			jobID := k.(string)
+			outputBuffer, ok := p.projectOutputBuffers[jobID]
+			if !ok || !outputBuffer.OperationComplete {
+				return true
+			}
+			log := JobLog{JobID: jobID, RepoFullName: pullInfo.RepoFullName, Time: v.(JobIDInfo).Time}
+			for _, line := range outputBuffer.Buffer {
+				log.Size += int64(len(line))
+			}
+			logs = append(logs, log)
+			return true
+		})
+		return true
+	})
+	return logs
+}
+
+// DeleteJobLog deletes the output of the job with jobID, ex. once it's older
+// than it's retained for.
+func (p *AsyncProjectCommandOutputHandler) DeleteJobLog(jobID string) {
+	p.projectOutputBuffersLock.Lock()
+	delete(p.projectOutputBuffers, jobID)
+	p.projectOutputBuffersLock.Unlock()
+
+	p.receiverBuffersLock.Lock()
+	delete(p.receiverBuffers, jobID)
+	p.receiverBuffersLock.Unlock()
+
+	p.pullToJobMapping.Range(func(_, value any) bool {
+		value.(*sync.Map).Delete(jobID)
+		return true
+	})
+}
+
 // NoopProjectOutputHandler is a mock that doesn't do anything
 type NoopProjectOutputHandler struct{}
 
@@ -422,3 +422,92 @@ func TestHighConcurrencyStress(t *testing.T) {
 	wg.Wait()
 	close(prjCmdOutputChan)
 }
+
+func TestJobLogs(t *testing.T) {
+	handler := createProjectCommandOutputHandler(t).(*jobs.AsyncProjectCommandOutputHandler)
+	ctx := createTestProjectCmdContext(t)
+	ctx.BaseRepo.FullName = "test-org/test-repo"
+	inProgress := ctx
+	inProgress.JobID = "5678"
+
+	handler.Send(ctx, "Plan: 1 to add", false)
+	handler.Send(inProgress, "Still planning", false)
+	handler.Send(ctx, "", true)
+
+	assert.Eventually(t, func() bool { return len(handler.JobLogs()) == 1 }, time.Second, 10*time.Millisecond)
+	logs := handler.JobLogs()
+	Equals(t, "1234", logs[0].JobID)
+	Equals(t, "test-org/test-repo", logs[0].RepoFullName)
+	Equals(t, int64(len("Plan: 1 to add")), logs[0].Size)
+
+	handler.DeleteJobLog(ctx.JobID)
+	Equals(t, 0, len(handler.JobLogs()))
+	Assert(t, !handler.IsKeyExists(ctx.JobID), "exp job log to be deleted")
+	Assert(t, handler.IsKeyExists(inProgress.JobID), "exp job in progress to be kept")
+}
+
+func TestForwardOutput(t *testing.T) {
+	prjCmdOutputChan := make(chan *jobs.ProjectCmdOutputLine)
+	handler := jobs.NewAsyncProjectCommandOutputHandler(prjCmdOutputChan, logging.NewNoopLogger(t)).(*jobs.AsyncProjectCommandOutputHandler)
+	forwarded := make(chan *jobs.ProjectCmdOutputLine, 2)
+	handler.ForwardOutput(func(line *jobs.ProjectCmdOutputLine) { forwarded <- line })
+	go handler.Handle()
+	defer close(prjCmdOutputChan)
+
+	ctx := createTestProjectCmdContext(t)
+	handler.Send(ctx, "Plan: 1 to add", false)
+	handler.Send(ctx, "", true)
+
+	line := <-forwarded
+	Equals(t, "1234", line.JobID)
+	Equals(t, "Plan: 1 to add", line.Line)
+	Equals(t, "test-project", line.JobInfo.ProjectName)
+	Equals(t, 0, len(forwarded))
+}
+
+func TestRelayOutput(t *testing.T) {
+	workerOutput := make(chan *jobs.ProjectCmdOutputLine)
+	worker := jobs.NewAsyncProjectCommandOutputHandler(workerOutput, logging.NewNoopLogger(t)).(*jobs.AsyncProjectCommandOutputHandler)
+	frontendOutput := make(chan *jobs.ProjectCmdOutputLine)
+	frontend := jobs.NewAsyncProjectCommandOutputHandler(frontendOutput, logging.NewNoopLogger(t)).(*jobs.AsyncProjectCommandOutputHandler)
+	received := make(chan struct{})
+	worker.RelayOutput(func(line *jobs.ProjectCmdOutputLine) {
+		frontend.Receive(line)
+		if line.OperationComplete {
+			close(received)
+		}
+	})
+	go worker.Handle()
+	defer close(workerOutput)
+	go frontend.Handle()
+	defer close(frontendOutput)
+
+	ctx := createTestProjectCmdContext(t)
+	worker.Send(ctx, "Plan: 1 to add", false)
+	worker.Send(ctx, "", true)
+	<-received
+
+	// The frontend serves the output of the job the worker ran.
+	assert.Eventually(t, func() bool {
+		buffer := frontend.GetProjectOutputBuffer(ctx.JobID)
+		return buffer.OperationComplete && len(buffer.Buffer) == 1 && buffer.Buffer[0] == "Plan: 1 to add"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestProjectCommandOutputHandler_RunID(t *testing.T) {
+	prjCmdOutputChan := make(chan *jobs.ProjectCmdOutputLine)
+	handler := jobs.NewAsyncProjectCommandOutputHandler(prjCmdOutputChan, logging.NewNoopLogger(t)).(*jobs.AsyncProjectCommandOutputHandler)
+	forwarded := make(chan *jobs.ProjectCmdOutputLine, 1)
+	handler.ForwardOutput(func(line *jobs.ProjectCmdOutputLine) { forwarded <- line })
+	go handler.Handle()
+	defer close(prjCmdOutputChan)
+
+	ctx := createTestProjectCmdContext(t)
+	ctx.Log = ctx.Log.With(logging.CorrelationIDKey, "0a1b2c3d-4e5f-6789-abcd-ef0123456789")
+	handler.Send(ctx, "Plan: 1 to add", false)
+	<-forwarded
+
+	mappings := handler.GetPullToJobMapping()
+	Equals(t, 1, len(mappings))
+	Equals(t, "0a1b2c3d", mappings[0].JobIDInfos[0].RunID)
+}
@@ -0,0 +1,82 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package logging
+
+import (
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// CorrelationIDKey is the key of the correlation ID in log entries. The
+	// correlation ID is generated when a webhook is received and is logged by
+	// everything done for it, ex. the commands it runs, the comments they
+	// write and their plan summaries, so runs of many projects can be
+	// reconstructed from the logs.
+	CorrelationIDKey = "correlation-id"
+	// CorrelationIDHeader is the response header the correlation ID of a
+	// webhook is returned in, so webhook deliveries can be matched with logs.
+	CorrelationIDHeader = "X-Atlantis-Correlation-ID"
+)
+
+// NewCorrelationID returns a new correlation ID.
+func NewCorrelationID() string {
+	return uuid.NewString()
+}
+
+// runIDLength is the length of run IDs, see RunID.
+const runIDLength = 8
+
+// RunID returns the run ID of what logger logs for, the first characters of
+// its correlation ID, or "" if it has none. It's short enough to be written
+// in comments and still finds the log entries of the run when searched for.
+func RunID(logger SimpleLogging) string {
+	id := CorrelationID(logger)
+	if len(id) < runIDLength {
+		return ""
+	}
+	return id[:runIDLength]
+}
+
+// CorrelationID returns the correlation ID of what logger logs for, or "" if
+// it has none.
+func CorrelationID(logger SimpleLogging) string {
+	l, ok := logger.(*StructuredLogger)
+	if !ok {
+		return ""
+	}
+	return l.correlationID
+}
+
+// correlationIDOf returns the correlation ID in the key value pairs a, or
+// current if there's none.
+func correlationIDOf(current string, a []any) string {
+	for i := 0; i+1 < len(a); i += 2 {
+		if a[i] == CorrelationIDKey {
+			if id, ok := a[i+1].(string); ok {
+				return id
+			}
+		}
+	}
+	return current
+}
+
+// NewStdLogger returns a standard library logger that logs to l at lvl, for
+// libraries that log with the standard library so their logs are structured
+// too.
+func NewStdLogger(l SimpleLogging, lvl LogLevel) *log.Logger {
+	return log.New(stdWriter{logger: l, level: lvl}, "", 0)
+}
+
+type stdWriter struct {
+	logger SimpleLogging
+	level  LogLevel
+}
+
+func (w stdWriter) Write(p []byte) (int, error) {
+	w.logger.Log(w.level, "%s", strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
@@ -14,9 +14,13 @@
 package logging_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"testing"
 
+	. "github.com/petergtz/pegomock/v4"
 	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/logging/mocks"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -32,3 +36,46 @@ func TestStructuredLoggerSavesHistory(t *testing.T) {
 
 	assert.Equal(t, expectedStr, historyLogger.GetHistory())
 }
+
+func TestNewStdLogger(t *testing.T) {
+	RegisterMockTestingT(t)
+	logger := mocks.NewMockSimpleLogging()
+	stdLogger := logging.NewStdLogger(logger, logging.Error)
+
+	stdLogger.Printf("PANIC: %s", "runtime error")
+	logger.VerifyWasCalledOnce().Log(logging.Error, "%s", "PANIC: runtime error")
+}
+
+func TestNewStructuredLoggerFromLevel_Sinks(t *testing.T) {
+	var sink bytes.Buffer
+	logger, err := logging.NewStructuredLoggerFromLevel(logging.Info, &sink)
+	assert.NoError(t, err)
+
+	logger.With("repo", "owner/repo").Info("planning %s", "project")
+	logger.Debug("not logged")
+
+	var entry struct {
+		Level string `json:"level"`
+		Msg   string `json:"msg"`
+		JSON  struct {
+			Repo string `json:"repo"`
+		} `json:"json"`
+	}
+	assert.NoError(t, json.Unmarshal(sink.Bytes(), &entry))
+	assert.Equal(t, "info", entry.Level)
+	assert.Equal(t, "planning project", entry.Msg)
+	assert.Equal(t, "owner/repo", entry.JSON.Repo)
+}
+
+func TestRunID(t *testing.T) {
+	RegisterMockTestingT(t)
+	logger := logging.NewNoopLogger(t)
+	assert.Equal(t, "", logging.RunID(logger))
+
+	correlated := logger.With(logging.CorrelationIDKey, "0a1b2c3d-4e5f-6789-abcd-ef0123456789")
+	assert.Equal(t, "0a1b2c3d", logging.RunID(correlated))
+	// The run ID is kept by the loggers created from it.
+	assert.Equal(t, "0a1b2c3d", logging.RunID(correlated.With("repo", "owner/repo").WithHistory("pull", "1")))
+	assert.Equal(t, "", logging.RunID(mocks.NewMockSimpleLogging()))
+	assert.Equal(t, "0a1b2c3d-4e5f-6789-abcd-ef0123456789", logging.CorrelationID(correlated.With("repo", "owner/repo")))
+}
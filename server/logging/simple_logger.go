@@ -19,6 +19,7 @@ package logging
 import (
 	"bytes"
 	"fmt"
+	"io"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -69,14 +70,32 @@ type StructuredLogger struct {
 	// gives us the ability to query our logs across multiple dimensions
 	// I don't believe we should mix this in with atlantis commands and expose this to the user
 	history bytes.Buffer
+	// correlationID is the correlation ID the logger was created with, see
+	// RunID.
+	correlationID string
 }
 
-func NewStructuredLoggerFromLevel(lvl LogLevel) (SimpleLogging, error) {
+// NewStructuredLoggerFromLevel returns a logger writing to stdout at lvl. The
+// JSON log entries are also written to sinks, one entry per write, ex. to
+// ship them to log services. Sinks are synced when the logger is flushed if
+// they have a Sync() error method.
+func NewStructuredLoggerFromLevel(lvl LogLevel, sinks ...io.Writer) (SimpleLogging, error) {
 	cfg := zap.NewProductionConfig()
 
 	cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 	cfg.Level = zap.NewAtomicLevelAt(lvl.zLevel)
-	return newStructuredLogger(cfg)
+	var opts []zap.Option
+	if len(sinks) > 0 {
+		var syncers []zapcore.WriteSyncer
+		for _, sink := range sinks {
+			syncers = append(syncers, zapcore.AddSync(sink))
+		}
+		sinkCore := zapcore.NewCore(zapcore.NewJSONEncoder(cfg.EncoderConfig), zapcore.NewMultiWriteSyncer(syncers...), cfg.Level)
+		opts = append(opts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(core, sinkCore)
+		}))
+	}
+	return newStructuredLogger(cfg, opts...)
 }
 
 func NewStructuredLogger() (SimpleLogging, error) {
@@ -85,8 +104,8 @@ func NewStructuredLogger() (SimpleLogging, error) {
 	return newStructuredLogger(cfg)
 }
 
-func newStructuredLogger(cfg zap.Config) (*StructuredLogger, error) {
-	baseLogger, err := cfg.Build()
+func newStructuredLogger(cfg zap.Config, opts ...zap.Option) (*StructuredLogger, error) {
+	baseLogger, err := cfg.Build(opts...)
 
 	baseLogger = baseLogger.
 		// ensures that the caller doesn't just say logging/simple_logger each time
@@ -107,15 +126,17 @@ func newStructuredLogger(cfg zap.Config) (*StructuredLogger, error) {
 
 func (l *StructuredLogger) With(a ...any) SimpleLogging {
 	return &StructuredLogger{
-		z:     l.z.With(a...),
-		level: l.level,
+		z:             l.z.With(a...),
+		level:         l.level,
+		correlationID: correlationIDOf(l.correlationID, a),
 	}
 }
 
 func (l *StructuredLogger) WithHistory(a ...any) SimpleLogging {
 	logger := &StructuredLogger{
-		z:     l.z.With(a...),
-		level: l.level,
+		z:             l.z.With(a...),
+		level:         l.level,
+		correlationID: correlationIDOf(l.correlationID, a),
 	}
 
 	// ensure that the history is kept across loggers.
@@ -9,3 +9,10 @@ const (
 	ExecutionErrorMetric   = "execution_error"
 	ExecutionFailureMetric = "execution_failure"
 )
+
+// The metrics of the resources applies change.
+const (
+	ResourcesAddedMetric     = "resources_added"
+	ResourcesChangedMetric   = "resources_changed"
+	ResourcesDestroyedMetric = "resources_destroyed"
+)
@@ -0,0 +1,250 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// The Grafana dashboard and Prometheus rules are generated from the metrics
+// registered in the Prometheus registry rather than from a hand-maintained
+// list, so they match the exact names and labels the server emits as metrics
+// are added or renamed. Metrics are registered as the code paths emitting
+// them first run, so they're more complete once the server handled commands.
+
+// executionSuffixes are the suffixes of the metrics of an operation, see
+// ExecutionTimeMetric etc.
+var executionSuffixes = []string{
+	"_" + ExecutionSuccessMetric,
+	"_" + ExecutionErrorMetric,
+	"_" + ExecutionFailureMetric,
+	"_" + ExecutionTimeMetric,
+}
+
+// ErrorRatioThreshold is the ratio of errored executions of an operation the
+// generated alerts fire at.
+const ErrorRatioThreshold = 0.1
+
+// family is a metric family the server emits.
+type family struct {
+	// name is the name without the namespace.
+	name   string
+	kind   dto.MetricType
+	labels []string
+}
+
+// operation is the execution metrics of an operation, ex. cmd_autoplan.
+type operation struct {
+	name    string
+	metrics map[string]family
+}
+
+// registry is the metrics of the server, grouped by operation.
+type registry struct {
+	namespace  string
+	operations []operation
+	others     []family
+}
+
+func gatherRegistry(gatherer prom.Gatherer, namespace string) (registry, error) {
+	families, err := gatherer.Gather()
+	if err != nil {
+		return registry{}, fmt.Errorf("gathering metrics: %w", err)
+	}
+	r := registry{namespace: namespace}
+	operations := make(map[string]map[string]family)
+	for _, mf := range families {
+		name, ok := strings.CutPrefix(mf.GetName(), namespace+"_")
+		if !ok {
+			continue
+		}
+		f := family{name: name, kind: mf.GetType()}
+		for _, m := range mf.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if !slices.Contains(f.labels, label.GetName()) {
+					f.labels = append(f.labels, label.GetName())
+				}
+			}
+		}
+		slices.Sort(f.labels)
+
+		suffix := slices.IndexFunc(executionSuffixes, func(s string) bool { return strings.HasSuffix(name, s) })
+		if suffix == -1 {
+			r.others = append(r.others, f)
+			continue
+		}
+		op := strings.TrimSuffix(name, executionSuffixes[suffix])
+		if operations[op] == nil {
+			operations[op] = make(map[string]family)
+		}
+		operations[op][executionSuffixes[suffix][1:]] = f
+	}
+	for name, metrics := range operations {
+		r.operations = append(r.operations, operation{name: name, metrics: metrics})
+	}
+	slices.SortFunc(r.operations, func(a, b operation) int { return strings.Compare(a.name, b.name) })
+	slices.SortFunc(r.others, func(a, b family) int { return strings.Compare(a.name, b.name) })
+	return r, nil
+}
+
+func (r registry) fullName(f family) string {
+	return r.namespace + "_" + f.name
+}
+
+// expr returns the PromQL graphing f, percentiles for timers.
+func (r registry) expr(f family, quantile string) string {
+	name := r.fullName(f)
+	switch f.kind {
+	case dto.MetricType_COUNTER:
+		return sumBy(f.labels, fmt.Sprintf("rate(%s[$__rate_interval])", name))
+	case dto.MetricType_SUMMARY:
+		return fmt.Sprintf("max%s (%s{quantile=%q})", by(f.labels), name, quantile)
+	case dto.MetricType_HISTOGRAM:
+		return fmt.Sprintf("histogram_quantile(%s, %s)", quantile, sumBy(append([]string{"le"}, f.labels...), fmt.Sprintf("rate(%s_bucket[$__rate_interval])", name)))
+	default:
+		return name
+	}
+}
+
+func by(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" by (%s)", strings.Join(labels, ", "))
+}
+
+func sumBy(labels []string, expr string) string {
+	return fmt.Sprintf("sum%s (%s)", by(labels), expr)
+}
+
+func legend(prefix string, labels []string) string {
+	var parts []string
+	if prefix != "" {
+		parts = append(parts, prefix)
+	}
+	for _, label := range labels {
+		parts = append(parts, fmt.Sprintf("{{%s}}", label))
+	}
+	return strings.Join(parts, " ")
+}
+
+// section is the first segment of name, ex. cmd, the name of the dashboard
+// row it's in.
+func section(name string) string {
+	s, _, _ := strings.Cut(name, "_")
+	return s
+}
+
+type dashboardTarget struct {
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat"`
+	RefID        string `json:"refId"`
+}
+
+type dashboardPanel struct {
+	ID         int               `json:"id"`
+	Type       string            `json:"type"`
+	Title      string            `json:"title"`
+	GridPos    map[string]int    `json:"gridPos"`
+	Datasource map[string]string `json:"datasource,omitempty"`
+	Targets    []dashboardTarget `json:"targets,omitempty"`
+	FieldCfg   map[string]any    `json:"fieldConfig,omitempty"`
+}
+
+// GrafanaDashboard returns a Grafana dashboard graphing the metrics the server
+// emits, with a row per section of metrics, ex. cmd.
+func GrafanaDashboard(gatherer prom.Gatherer, namespace string) ([]byte, error) {
+	r, err := gatherRegistry(gatherer, namespace)
+	if err != nil {
+		return nil, err
+	}
+	datasource := map[string]string{"type": "prometheus", "uid": "${datasource}"}
+	var panels []dashboardPanel
+	// Panels are laid out two per line, y and col are where the next one goes.
+	y, col := 0, 0
+	currentSection := ""
+	add := func(name string, title string, unit string, targets ...dashboardTarget) {
+		if s := section(name); s != currentSection {
+			currentSection = s
+			if col == 1 {
+				y += 8
+			}
+			col = 0
+			panels = append(panels, dashboardPanel{ID: len(panels) + 1, Type: "row", Title: s, GridPos: map[string]int{"h": 1, "w": 24, "x": 0, "y": y}})
+			y++
+		}
+		for i := range targets {
+			targets[i].RefID = string(rune('A' + i))
+		}
+		panels = append(panels, dashboardPanel{
+			ID:         len(panels) + 1,
+			Type:       "timeseries",
+			Title:      title,
+			GridPos:    map[string]int{"h": 8, "w": 12, "x": 12 * col, "y": y},
+			Datasource: datasource,
+			Targets:    targets,
+			FieldCfg:   map[string]any{"defaults": map[string]any{"unit": unit}},
+		})
+		if col++; col == 2 {
+			y, col = y+8, 0
+		}
+	}
+
+	type panel struct {
+		name, title, unit string
+		targets           []dashboardTarget
+	}
+	var specs []panel
+	for _, op := range r.operations {
+		var targets []dashboardTarget
+		for _, metric := range []string{ExecutionSuccessMetric, ExecutionErrorMetric, ExecutionFailureMetric} {
+			if f, ok := op.metrics[metric]; ok {
+				targets = append(targets, dashboardTarget{Expr: r.expr(f, ""), LegendFormat: legend(strings.TrimPrefix(metric, "execution_"), f.labels)})
+			}
+		}
+		if len(targets) > 0 {
+			specs = append(specs, panel{op.name, op.name + " executions", "ops", targets})
+		}
+		if f, ok := op.metrics[ExecutionTimeMetric]; ok {
+			specs = append(specs, panel{op.name, op.name + " execution time", "s", []dashboardTarget{
+				{Expr: r.expr(f, "0.5"), LegendFormat: legend("p50", f.labels)},
+				{Expr: r.expr(f, "0.99"), LegendFormat: legend("p99", f.labels)},
+			}})
+		}
+	}
+	for _, f := range r.others {
+		unit := "short"
+		if f.kind == dto.MetricType_COUNTER {
+			unit = "ops"
+		}
+		specs = append(specs, panel{f.name, f.name, unit, []dashboardTarget{{Expr: r.expr(f, "0.99"), LegendFormat: legend("", f.labels)}}})
+	}
+	slices.SortStableFunc(specs, func(a, b panel) int { return strings.Compare(section(a.name), section(b.name)) })
+	for _, spec := range specs {
+		add(spec.name, spec.title, spec.unit, spec.targets...)
+	}
+
+	dashboard := map[string]any{
+		"title":         fmt.Sprintf("Atlantis (%s)", namespace),
+		"uid":           "atlantis-" + namespace,
+		"tags":          []string{"atlantis"},
+		"schemaVersion": 39,
+		"time":          map[string]string{"from": "now-6h", "to": "now"},
+		"refresh":       "1m",
+		"templating": map[string]any{"list": []map[string]any{{
+			"name":  "datasource",
+			"label": "Data source",
+			"type":  "datasource",
+			"query": "prometheus",
+		}}},
+		"panels": panels,
+	}
+	return json.MarshalIndent(dashboard, "", "  ")
+}
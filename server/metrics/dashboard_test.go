@@ -0,0 +1,112 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/runatlantis/atlantis/server/metrics"
+	. "github.com/runatlantis/atlantis/testing"
+	tally "github.com/uber-go/tally/v4"
+	tallyprom "github.com/uber-go/tally/v4/prometheus"
+	"gopkg.in/yaml.v3"
+)
+
+// newRegistry returns a registry with the metrics of a few operations emitted
+// the way the server emits them.
+func newRegistry(t *testing.T) *prom.Registry {
+	registry := prom.NewRegistry()
+	scope, closer := tally.NewRootScope(tally.ScopeOptions{
+		Prefix:          "atlantis",
+		CachedReporter:  tallyprom.NewReporter(tallyprom.Options{Registerer: registry}),
+		Separator:       tallyprom.DefaultSeparator,
+		SanitizeOptions: &tallyprom.DefaultSanitizerOpts,
+	}, time.Hour)
+	t.Cleanup(func() { closer.Close() }) // nolint: errcheck
+
+	autoplan := scope.SubScope("cmd").SubScope("autoplan")
+	autoplan.Counter(metrics.ExecutionSuccessMetric).Inc(1)
+	autoplan.Counter(metrics.ExecutionErrorMetric).Inc(1)
+	autoplan.Timer(metrics.ExecutionTimeMetric).Record(time.Second)
+	project := scope.SubScope("project").Tagged(map[string]string{"base_repo": "owner/repo"}).SubScope("plan")
+	project.Counter(metrics.ExecutionSuccessMetric).Inc(1)
+	project.Counter(metrics.ExecutionFailureMetric).Inc(1)
+	scope.SubScope("runtime").SubScope("cpu").Gauge("goroutines").Update(10)
+	return registry
+}
+
+func TestGrafanaDashboard(t *testing.T) {
+	generated, err := metrics.GrafanaDashboard(newRegistry(t), "atlantis")
+	Ok(t, err)
+	var dashboard struct {
+		UID    string
+		Panels []struct {
+			Type    string
+			Title   string
+			GridPos map[string]int
+			Targets []struct {
+				Expr         string
+				LegendFormat string
+			}
+		}
+	}
+	Ok(t, json.Unmarshal(generated, &dashboard))
+	Equals(t, "atlantis-atlantis", dashboard.UID)
+
+	var titles []string
+	for _, panel := range dashboard.Panels {
+		titles = append(titles, panel.Type+" "+panel.Title)
+	}
+	Equals(t, []string{
+		"row cmd",
+		"timeseries cmd_autoplan executions",
+		"timeseries cmd_autoplan execution time",
+		"row project",
+		"timeseries project_plan executions",
+		"row runtime",
+		"timeseries runtime_cpu_goroutines",
+	}, titles)
+
+	Equals(t, "sum (rate(atlantis_cmd_autoplan_execution_success[$__rate_interval]))", dashboard.Panels[1].Targets[0].Expr)
+	Equals(t, "error", dashboard.Panels[1].Targets[1].LegendFormat)
+	Equals(t, `max (atlantis_cmd_autoplan_execution_time{quantile="0.99"})`, dashboard.Panels[2].Targets[1].Expr)
+	Equals(t, map[string]int{"h": 8, "w": 12, "x": 12, "y": 1}, dashboard.Panels[2].GridPos)
+	Equals(t, "sum by (base_repo) (rate(atlantis_project_plan_execution_failure[$__rate_interval]))", dashboard.Panels[4].Targets[1].Expr)
+	Equals(t, "failure {{base_repo}}", dashboard.Panels[4].Targets[1].LegendFormat)
+	Equals(t, map[string]int{"h": 1, "w": 24, "x": 0, "y": 9}, dashboard.Panels[3].GridPos)
+	Equals(t, "atlantis_runtime_cpu_goroutines", dashboard.Panels[6].Targets[0].Expr)
+}
+
+func TestPrometheusRules(t *testing.T) {
+	generated, err := metrics.PrometheusRules(newRegistry(t), "atlantis")
+	Ok(t, err)
+	var rules struct {
+		Groups []struct {
+			Name  string
+			Rules []map[string]any
+		}
+	}
+	Ok(t, yaml.Unmarshal(generated, &rules))
+	Equals(t, 2, len(rules.Groups))
+
+	Equals(t, "atlantis.rules", rules.Groups[0].Name)
+	Equals(t, 2, len(rules.Groups[0].Rules))
+	Equals(t, "atlantis:cmd_autoplan_execution_time:p99", rules.Groups[0].Rules[0]["record"])
+	Equals(t, `max (atlantis_cmd_autoplan_execution_time{quantile="0.99"})`, rules.Groups[0].Rules[0]["expr"])
+	Equals(t, "atlantis:cmd_autoplan_execution_error:ratio_rate5m", rules.Groups[0].Rules[1]["record"])
+	Equals(t, "sum (rate(atlantis_cmd_autoplan_execution_error[5m])) / (sum (rate(atlantis_cmd_autoplan_execution_error[5m])) + sum (rate(atlantis_cmd_autoplan_execution_success[5m])))", rules.Groups[0].Rules[1]["expr"])
+
+	// project_plan has no error metric so it has no alert.
+	Equals(t, "atlantis.alerts", rules.Groups[1].Name)
+	Equals(t, 1, len(rules.Groups[1].Rules))
+	alert := rules.Groups[1].Rules[0]
+	Equals(t, "AtlantisCmdAutoplanErrors", alert["alert"])
+	Equals(t, "atlantis:cmd_autoplan_execution_error:ratio_rate5m > 0.1", alert["expr"])
+	Equals(t, "15m", alert["for"])
+	Assert(t, strings.Contains(alert["annotations"].(map[string]any)["summary"].(string), "More than 10% of the cmd_autoplan executions"), "unexpected summary %v", alert["annotations"])
+}
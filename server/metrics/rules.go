@@ -0,0 +1,106 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/yaml.v3"
+)
+
+type ruleGroups struct {
+	Groups []ruleGroup `yaml:"groups"`
+}
+
+type ruleGroup struct {
+	Name  string `yaml:"name"`
+	Rules []rule `yaml:"rules"`
+}
+
+type rule struct {
+	Record      string            `yaml:"record,omitempty"`
+	Alert       string            `yaml:"alert,omitempty"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// PrometheusRules returns Prometheus recording rules for the error ratio and
+// the 99th percentile execution time of the operations the server emits
+// metrics for, and rules alerting when more than ErrorRatioThreshold of the
+// executions of an operation errored for 15 minutes.
+func PrometheusRules(gatherer prom.Gatherer, namespace string) ([]byte, error) {
+	r, err := gatherRegistry(gatherer, namespace)
+	if err != nil {
+		return nil, err
+	}
+	recording := ruleGroup{Name: namespace + ".rules"}
+	alerts := ruleGroup{Name: namespace + ".alerts"}
+	for _, op := range r.operations {
+		if f, ok := op.metrics[ExecutionTimeMetric]; ok {
+			expr := strings.ReplaceAll(r.expr(f, "0.99"), "$__rate_interval", "5m")
+			recording.Rules = append(recording.Rules, rule{
+				Record: fmt.Sprintf("%s:%s_%s:p99", namespace, op.name, ExecutionTimeMetric),
+				Expr:   expr,
+			})
+		}
+		success, hasSuccess := op.metrics[ExecutionSuccessMetric]
+		errors, hasErrors := op.metrics[ExecutionErrorMetric]
+		if !hasSuccess || !hasErrors {
+			continue
+		}
+		errorRate := sumBy(errors.labels, fmt.Sprintf("rate(%s[5m])", r.fullName(errors)))
+		successRate := sumBy(success.labels, fmt.Sprintf("rate(%s[5m])", r.fullName(success)))
+		ratio := fmt.Sprintf("%s:%s_%s:ratio_rate5m", namespace, op.name, ExecutionErrorMetric)
+		recording.Rules = append(recording.Rules, rule{
+			Record: ratio,
+			Expr:   fmt.Sprintf("%s / (%s + %s)", errorRate, errorRate, successRate),
+		})
+		alerts.Rules = append(alerts.Rules, rule{
+			Alert:  alertName(namespace, op.name),
+			Expr:   fmt.Sprintf("%s > %g", ratio, ErrorRatioThreshold),
+			For:    "15m",
+			Labels: map[string]string{"severity": "warning"},
+			Annotations: map[string]string{
+				"summary": fmt.Sprintf("More than %g%% of the %s executions errored for 15 minutes.", ErrorRatioThreshold*100, op.name),
+			},
+		})
+	}
+	groups := ruleGroups{}
+	for _, group := range []ruleGroup{recording, alerts} {
+		if len(group.Rules) > 0 {
+			groups.Groups = append(groups.Groups, group)
+		}
+	}
+	return yaml.Marshal(groups)
+}
+
+// alertName returns the CamelCase name of the alert of the errors of op, ex.
+// AtlantisCmdAutoplanErrors.
+func alertName(namespace string, op string) string {
+	var name strings.Builder
+	for _, part := range strings.FieldsFunc(namespace+"_"+op, func(r rune) bool { return r == '_' || r == '-' }) {
+		name.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	name.WriteString("Errors")
+	return name.String()
+}
+
+// GeneratedHandler serves what generate generates from the metrics gathered by
+// gatherer, ex. GrafanaDashboard.
+func GeneratedHandler(generate func(prom.Gatherer, string) ([]byte, error), contentType string, gatherer prom.Gatherer, namespace string) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		generated, err := generate(gatherer, namespace)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(generated) // nolint: errcheck
+	}
+}
@@ -17,7 +17,9 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/runatlantis/atlantis/server/core/terraform/registrycache"
 	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/webauth"
 	"github.com/urfave/negroni/v3"
 )
 
@@ -28,6 +30,7 @@ func NewRequestLogger(s *Server) *RequestLogger {
 		s.WebAuthentication,
 		s.WebUsername,
 		s.WebPassword,
+		s.OIDC,
 	}
 }
 
@@ -38,24 +41,36 @@ type RequestLogger struct {
 	WebAuthentication bool
 	WebUsername       string
 	WebPassword       string
+	// OIDC, if set, logs users in with an OIDC provider instead of basic auth.
+	OIDC *webauth.OIDC
 }
 
 // ServeHTTP implements the middleware function. It logs all requests at DEBUG level.
 func (l *RequestLogger) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
 	l.logger.Debug("%s %s – from %s", r.Method, r.URL.RequestURI(), r.RemoteAddr)
 	allowed := false
-	if !l.WebAuthentication ||
+	if (!l.WebAuthentication && l.OIDC == nil) ||
 		r.URL.Path == "/events" ||
 		r.URL.Path == "/healthz" ||
 		r.URL.Path == "/status" ||
-		strings.HasPrefix(r.URL.Path, "/api/") {
+		strings.HasPrefix(r.URL.Path, "/api/") ||
+		// Terraform doesn't authenticate to the registry cache, its routes
+		// check their own token.
+		strings.HasPrefix(r.URL.Path, registrycache.RoutePrefix) ||
+		(l.OIDC != nil && strings.HasPrefix(r.URL.Path, "/auth/")) {
 		allowed = true
+	} else if l.OIDC != nil {
+		if user, ok := l.OIDC.Authenticate(r); ok {
+			r = r.WithContext(webauth.NewContext(r.Context(), user))
+			allowed = true
+		}
 	} else {
 		user, pass, ok := r.BasicAuth()
 		if ok {
 			r.SetBasicAuth(user, pass)
 			if user == l.WebUsername && pass == l.WebPassword {
 				l.logger.Debug("[VALID] log in: >> url: %s", r.URL.RequestURI())
+				r = r.WithContext(webauth.NewContext(r.Context(), &webauth.User{Name: user}))
 				allowed = true
 			} else {
 				allowed = false
@@ -63,11 +78,62 @@ func (l *RequestLogger) ServeHTTP(rw http.ResponseWriter, r *http.Request, next
 			}
 		}
 	}
-	if !allowed {
+	switch {
+	case allowed:
+		next(rw, r)
+	case l.OIDC != nil && r.Method == http.MethodGet && r.Header.Get("Upgrade") == "":
+		l.OIDC.RedirectToLogin(rw, r)
+	case l.OIDC != nil:
+		http.Error(rw, "Unauthorized", http.StatusUnauthorized)
+	default:
 		rw.Header().Set("WWW-Authenticate", `Basic realm="restricted", charset="UTF-8"`)
 		http.Error(rw, "Unauthorized", http.StatusUnauthorized)
-	} else {
-		next(rw, r)
 	}
-	l.logger.Debug("%s %s – respond HTTP %d", r.Method, r.URL.RequestURI(), rw.(negroni.ResponseWriter).Status())
+	logger := l.logger
+	if correlationID := rw.Header().Get(logging.CorrelationIDHeader); correlationID != "" {
+		logger = logger.With(logging.CorrelationIDKey, correlationID)
+	}
+	logger.Debug("%s %s – respond HTTP %d", r.Method, r.URL.RequestURI(), rw.(negroni.ResponseWriter).Status())
+}
+
+// IPAllowlister rejects the requests to the webhook and API endpoints that
+// weren't made from their allowlists. Requests are allowed from anywhere if
+// their allowlist is nil.
+type IPAllowlister struct {
+	Webhooks *IPAllowlist
+	API      *IPAllowlist
+	Logger   logging.SimpleLogging
+}
+
+// ServeHTTP implements the middleware function. The source IP is the address
+// of the connection, X-Forwarded-For isn't trusted.
+func (i *IPAllowlister) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	var allowlist *IPAllowlist
+	switch {
+	case r.URL.Path == "/events":
+		allowlist = i.Webhooks
+	case strings.HasPrefix(r.URL.Path, "/api/"):
+		allowlist = i.API
+	}
+	if allowlist != nil && !allowlist.Allows(r.RemoteAddr) {
+		i.Logger.Warn("rejecting request to %s from %s, which isn't allowlisted", r.URL.Path, r.RemoteAddr)
+		http.Error(rw, "Forbidden", http.StatusForbidden)
+		return
+	}
+	next(rw, r)
+}
+
+// ClientCertRequirer rejects the requests to the webhook and API endpoints
+// that weren't made with a verified client certificate. Other endpoints, ex.
+// the UI and /healthz, don't require one.
+type ClientCertRequirer struct{}
+
+// ServeHTTP implements the middleware function.
+func (c *ClientCertRequirer) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	requiresCert := r.URL.Path == "/events" || strings.HasPrefix(r.URL.Path, "/api/")
+	if requiresCert && (r.TLS == nil || len(r.TLS.VerifiedChains) == 0) {
+		http.Error(rw, "Client certificate required", http.StatusForbidden)
+		return
+	}
+	next(rw, r)
 }
@@ -6,9 +6,11 @@ package server
 import (
 	"fmt"
 	"net/url"
+	"strconv"
 
 	"github.com/gorilla/mux"
 	"github.com/runatlantis/atlantis/server/events/command"
+	"github.com/runatlantis/atlantis/server/events/models"
 )
 
 // Router can be used to retrieve Atlantis URLs. It acts as an intermediary
@@ -22,6 +24,8 @@ type Router struct {
 	LockViewRouteName string
 	// ProjectJobsViewRouteName is the named route for the projects active jobs
 	ProjectJobsViewRouteName string
+	// RunViewRouteName is the named route redirecting to the jobs of a run.
+	RunViewRouteName string
 	// LockViewRouteIDQueryParam is the query parameter needed to construct the
 	// lock view: underlying.Get(LockViewRouteName).URL(LockViewRouteIDQueryParam, "my id").
 	LockViewRouteIDQueryParam string
@@ -65,3 +69,20 @@ func (r *Router) GenerateProjectWorkflowHookURL(hookID string) (string, error) {
 
 	return r.AtlantisURL.String() + jobURL.String(), nil
 }
+
+// GenerateRunURL returns a fully qualified URL to view the jobs of the run
+// runID.
+func (r *Router) GenerateRunURL(runID string) string {
+	runURL, _ := r.Underlying.Get(r.RunViewRouteName).URL("run-id", runID)
+	return r.AtlantisURL.String() + runURL.String()
+}
+
+// GeneratePullURL returns a fully qualified URL to view the projects of the
+// pull request pullNum of repo.
+func (r *Router) GeneratePullURL(repo models.Repo, pullNum int) string {
+	query := url.Values{"repo": {repo.FullName}, "pull": {strconv.Itoa(pullNum)}}
+	if repo.VCSHost.Hostname != "" {
+		query.Set("host", repo.VCSHost.Hostname)
+	}
+	return r.AtlantisURL.String() + "/pull?" + query.Encode()
+}
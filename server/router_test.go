@@ -75,11 +75,13 @@ func setupJobsRouter(t *testing.T) *server.Router {
 
 	underlyingRouter := mux.NewRouter()
 	underlyingRouter.HandleFunc("/jobs/{job-id}", func(_ http.ResponseWriter, _ *http.Request) {}).Methods("GET").Name("project-jobs-detail")
+	underlyingRouter.HandleFunc("/runs/{run-id}", func(_ http.ResponseWriter, _ *http.Request) {}).Methods("GET").Name("run-detail")
 
 	return &server.Router{
 		AtlantisURL:              atlantisURL,
 		Underlying:               underlyingRouter,
 		ProjectJobsViewRouteName: "project-jobs-detail",
+		RunViewRouteName:         "run-detail",
 	}
 }
 
@@ -113,3 +115,14 @@ func TestGenerateProjectJobURL_ShouldReturnErrorWhenJobIDNotSpecified(t *testing
 	require.EqualError(t, err, expectedErrString)
 	Equals(t, "", gotURL)
 }
+
+func TestRouter_GenerateRunURL(t *testing.T) {
+	router := setupJobsRouter(t)
+	Equals(t, "http://localhost:4141/runs/0a1b2c3d", router.GenerateRunURL("0a1b2c3d"))
+}
+
+func TestRouter_GeneratePullURL(t *testing.T) {
+	router := setupJobsRouter(t)
+	repo := models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Hostname: "github.com"}}
+	Equals(t, "http://localhost:4141/pull?host=github.com&pull=1&repo=owner%2Frepo", router.GeneratePullURL(repo, 1))
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	events_controllers "github.com/runatlantis/atlantis/server/controllers/events"
+	"github.com/runatlantis/atlantis/server/core/secrets"
+	"github.com/runatlantis/atlantis/server/events/vcs/github"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// openRouterAPIKeyEnv is the environment variable the plan summarizer reads
+// the OpenRouter API key from on each use.
+const openRouterAPIKeyEnv = "OPENROUTER_API_KEY"
+
+// resolveSecrets replaces the credentials of userConfig and the OpenRouter API
+// key that reference secrets with the secrets they reference.
+func resolveSecrets(userConfig *UserConfig, resolver *secrets.Resolver) error {
+	for flag, field := range userConfig.SecretFields() {
+		secret, err := resolver.Resolve(*field)
+		if err != nil {
+			return fmt.Errorf("resolving --%s: %w", flag, err)
+		}
+		*field = secret
+	}
+	if value := os.Getenv(openRouterAPIKeyEnv); value != "" {
+		secret, err := resolver.Resolve(value)
+		if err != nil {
+			return fmt.Errorf("resolving %s: %w", openRouterAPIKeyEnv, err)
+		}
+		if err := os.Setenv(openRouterAPIKeyEnv, secret); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watchSecrets rotates the webhook secrets of eventsController, the private
+// keys of githubAppCredentials, if set, and the OpenRouter API key when the secrets references, the credentials of
+// userConfig before they were resolved, reference are rotated. The other
+// credentials are used by clients created on startup so Atlantis must be
+// restarted to use their rotated secrets.
+func watchSecrets(resolver *secrets.Resolver, references UserConfig, openRouterAPIKey string, eventsController *events_controllers.VCSEventsController, githubAppCredentials *github.AppCredentials, logger logging.SimpleLogging) {
+	rotated := map[string]func(string){
+		"gh-webhook-secret": func(secret string) {
+			eventsController.RotateSecret(&eventsController.GithubWebhookSecret, []byte(secret))
+		},
+		"gitlab-webhook-secret": func(secret string) {
+			eventsController.RotateSecret(&eventsController.GitlabWebhookSecret, []byte(secret))
+		},
+		"bitbucket-webhook-secret": func(secret string) {
+			eventsController.RotateSecret(&eventsController.BitbucketWebhookSecret, []byte(secret))
+		},
+		"gitea-webhook-secret": func(secret string) {
+			eventsController.RotateSecret(&eventsController.GiteaWebhookSecret, []byte(secret))
+		},
+		"gerrit-webhook-secret": func(secret string) {
+			eventsController.RotateSecret(&eventsController.GerritWebhookSecret, []byte(secret))
+		},
+		"codecommit-webhook-secret": func(secret string) {
+			eventsController.RotateSecret(&eventsController.CodeCommitWebhookSecret, []byte(secret))
+		},
+		"azuredevops-webhook-password": func(secret string) {
+			eventsController.RotateSecret(&eventsController.AzureDevopsWebhookBasicPassword, []byte(secret))
+		},
+	}
+	if githubAppCredentials != nil {
+		rotated["gh-app-key"] = func(secret string) {
+			githubAppCredentials.RotateKey([]byte(secret))
+		}
+	}
+	fields := references.SecretFields()
+	flags := make([]string, 0, len(fields))
+	for flag := range fields {
+		flags = append(flags, flag)
+	}
+	sort.Strings(flags)
+	for _, flag := range flags {
+		onRotate, ok := rotated[flag]
+		if !ok {
+			onRotate = func(string) {
+				logger.Warn("the secret --%s references was rotated, restart Atlantis to use it", flag)
+			}
+		}
+		resolver.Watch(*fields[flag], onRotate)
+	}
+	resolver.Watch(openRouterAPIKey, func(secret string) {
+		if err := os.Setenv(openRouterAPIKeyEnv, secret); err != nil {
+			logger.Err("unable to rotate %s: %s", openRouterAPIKeyEnv, err)
+		}
+	})
+}
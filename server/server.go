@@ -18,17 +18,20 @@ package server
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"embed"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/http/pprof"
 	"net/url"
 	"os"
+	"os/exec"
 	"os/signal"
+	"path"
 	"path/filepath"
 	"slices"
 	"sort"
@@ -38,6 +41,7 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	"github.com/mitchellh/go-homedir"
+	promclient "github.com/prometheus/client_golang/prometheus"
 	tally "github.com/uber-go/tally/v4"
 	prometheus "github.com/uber-go/tally/v4/prometheus"
 	"github.com/urfave/negroni/v3"
@@ -47,28 +51,41 @@ import (
 	"github.com/runatlantis/atlantis/server/core/config/valid"
 	"github.com/runatlantis/atlantis/server/core/db"
 	"github.com/runatlantis/atlantis/server/core/redis"
+	"github.com/runatlantis/atlantis/server/core/terraform/registrycache"
 	"github.com/runatlantis/atlantis/server/core/terraform/tfclient"
+	"github.com/runatlantis/atlantis/server/core/terraform/tfcloud"
+	"github.com/runatlantis/atlantis/server/core/workqueue"
 	"github.com/runatlantis/atlantis/server/jobs"
 	"github.com/runatlantis/atlantis/server/metrics"
 	"github.com/runatlantis/atlantis/server/scheduled"
+	"github.com/runatlantis/atlantis/server/webauth"
 
 	"github.com/gorilla/mux"
+	"github.com/runatlantis/atlantis/server/agents"
 	"github.com/runatlantis/atlantis/server/controllers"
 	events_controllers "github.com/runatlantis/atlantis/server/controllers/events"
 	"github.com/runatlantis/atlantis/server/controllers/web_templates"
 	"github.com/runatlantis/atlantis/server/controllers/websocket"
+	"github.com/runatlantis/atlantis/server/core/kubernetes"
 	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/core/logsink"
 	"github.com/runatlantis/atlantis/server/core/runtime"
+	runtimemodels "github.com/runatlantis/atlantis/server/core/runtime/models"
 	"github.com/runatlantis/atlantis/server/core/runtime/policy"
+	"github.com/runatlantis/atlantis/server/core/secrets"
+	"github.com/runatlantis/atlantis/server/core/servicenow"
 	"github.com/runatlantis/atlantis/server/core/terraform"
 	"github.com/runatlantis/atlantis/server/events"
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/timeline"
 	"github.com/runatlantis/atlantis/server/events/vcs"
 	"github.com/runatlantis/atlantis/server/events/vcs/azuredevops"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketcloud"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketserver"
+	"github.com/runatlantis/atlantis/server/events/vcs/codecommit"
 	"github.com/runatlantis/atlantis/server/events/vcs/common"
+	"github.com/runatlantis/atlantis/server/events/vcs/gerrit"
 	"github.com/runatlantis/atlantis/server/events/vcs/gitea"
 	"github.com/runatlantis/atlantis/server/events/vcs/github"
 	"github.com/runatlantis/atlantis/server/events/vcs/gitlab"
@@ -87,12 +104,49 @@ const (
 	LockViewRouteIDQueryParam = "id"
 	// ProjectJobsViewRouteName is the named route in mux.Router for the log stream view.
 	ProjectJobsViewRouteName = "project-jobs-detail"
+	// RunViewRouteName is the named route in mux.Router redirecting to the
+	// jobs of a run.
+	RunViewRouteName = "run-detail"
 	// binDirName is the name of the directory inside our data dir where
 	// we download binaries.
 	BinDirName = "bin"
 	// terraformPluginCacheDir is the name of the dir inside our data dir
 	// where we tell terraform to cache plugins and modules.
 	TerraformPluginCacheDirName = "plugin-cache"
+	// RegistryCacheDirName is the name of the dir inside our data dir where
+	// the registry cache stores the providers and modules it proxies.
+	RegistryCacheDirName = "registry-cache"
+	// PlanJSONDirName is the name of the dir inside our data dir where the
+	// JSON plans are stored when they're exported.
+	PlanJSONDirName = "plan-json"
+	// artifactRetentionPeriod is how often the retention of artifacts is
+	// enforced.
+	artifactRetentionPeriod = 10 * time.Minute
+	// diskMonitorPeriod is how often the disk usage of the data dir is
+	// measured.
+	diskMonitorPeriod = time.Minute
+	// warmPoolPeriod is how often the warm pools are topped up.
+	warmPoolPeriod = 5 * time.Minute
+	// alertEvaluationPeriod is how often alert rules are evaluated.
+	alertEvaluationPeriod = 30 * time.Second
+	// SSLClientAuthWebhooksAndAPI requires client certificates for the webhook
+	// and API endpoints only, so the UI and health checks are reachable
+	// without them.
+	SSLClientAuthWebhooksAndAPI = "webhooks-and-api"
+	// SSLClientAuthAll requires client certificates for all endpoints.
+	SSLClientAuthAll = "all"
+	// AllServerRole handles webhooks and runs their commands in process.
+	AllServerRole = "all"
+	// FrontendServerRole handles webhooks and dispatches their commands to
+	// the workers through the work queue.
+	FrontendServerRole = "frontend"
+	// WorkerServerRole runs the commands of the work queue.
+	WorkerServerRole = "worker"
+	// RedisWorkQueue, SQSWorkQueue and NATSWorkQueue are the brokers the work
+	// queue can be on.
+	RedisWorkQueue = "redis"
+	SQSWorkQueue   = "sqs"
+	NATSWorkQueue  = "nats"
 )
 
 // Server runs the Atlantis web server.
@@ -107,6 +161,7 @@ type Server struct {
 	Logger                         logging.SimpleLogging
 	StatsScope                     tally.Scope
 	StatsReporter                  tally.BaseStatsReporter
+	StatsNamespace                 string
 	StatsCloser                    io.Closer
 	Locker                         locking.Locker
 	ApplyLocker                    locking.ApplyLocker
@@ -116,6 +171,11 @@ type Server struct {
 	StatusController               *controllers.StatusController
 	JobsController                 *controllers.JobsController
 	APIController                  *controllers.APIController
+	APITokensController            *controllers.APITokensController
+	WebhooksController             *controllers.WebhooksController
+	ProjectsController             *controllers.ProjectsController
+	PullController                 *controllers.PullController
+	AgentsController               *controllers.AgentsController
 	IndexTemplate                  web_templates.TemplateWriter
 	LockDetailTemplate             web_templates.TemplateWriter
 	ProjectJobsTemplate            web_templates.TemplateWriter
@@ -125,15 +185,31 @@ type Server struct {
 	CertLastRefreshTime            time.Time
 	KeyLastRefreshTime             time.Time
 	SSLCert                        *tls.Certificate
+	SSLClientCAs                   *x509.CertPool
+	SSLClientAuth                  string
+	WebhookIPAllowlist             *IPAllowlist
+	APIIPAllowlist                 *IPAllowlist
 	Drainer                        *events.Drainer
+	Worker                         *events.Worker
+	JobOutput                      *redis.JobOutput
+	LeaderElection                 *redis.LeaderElection
+	DeferredCommands               *events.DeferredCommands
 	WebAuthentication              bool
 	WebUsername                    string
 	WebPassword                    string
+	OIDC                           *webauth.OIDC
 	ProjectCmdOutputHandler        jobs.ProjectCommandOutputHandler
 	ScheduledExecutorService       *scheduled.ExecutorService
 	DisableGlobalApplyLock         bool
+	EnableStateForceUnlock         bool
+	StateLocks                     *events.StateLockTracker
 	EnableProfilingAPI             bool
+	RegistryCache                  *registrycache.Proxy
+	Tenants                        *events.Tenants
+	ConfigReloader                 *ConfigReloader
 	database                       db.Database
+	webhooks                       *webhooks.MultiWebhookSender
+	validationChecks               []validationCheck
 }
 
 // Config holds config for server that isn't passed in by the user.
@@ -159,14 +235,54 @@ type WebhookConfig struct {
 	// that is being modified for this event. If the regex matches, we'll
 	// send the webhook, ex. "main.*".
 	BranchRegex string `mapstructure:"branch-regex"`
-	// Kind is the type of webhook we should send, ex. slack or http.
+	// RepoRegex is a regex that is used to match against the full name of
+	// the repo, ex. "acme/infra.*". Together with ProjectRegex it's used
+	// to route notifications for different repos to different channels.
+	RepoRegex string `mapstructure:"repo-regex"`
+	// ProjectRegex is a regex that is used to match against the name of the
+	// project, ex. "prod-.*".
+	ProjectRegex string `mapstructure:"project-regex"`
+	// Kind is the type of webhook we should send, ex. slack, http, teams,
+	// pagerduty, opsgenie, cloudevents, datadog, email or jira.
 	Kind string `mapstructure:"kind"`
 	// Channel is the channel to send this webhook to. It only applies to
 	// slack webhooks. Should be without '#'.
 	Channel string `mapstructure:"channel"`
-	// URL is the URL where to deliver this webhook. It only applies to
-	// http webhooks.
+	// URL is the URL where to deliver this webhook. It applies to http,
+	// teams, cloudevents and email (smtp://) webhooks and to slack webhooks
+	// using an incoming webhook instead of a slack-token.
 	URL string `mapstructure:"url"`
+	// IntegrationKey is the PagerDuty routing key, Opsgenie or Datadog API
+	// key or Jira credentials. It applies to pagerduty, opsgenie, datadog
+	// and jira webhooks.
+	IntegrationKey string `mapstructure:"integration-key"`
+	// Tags are added to the events and metrics of datadog webhooks, ex.
+	// env:production.
+	Tags []string `mapstructure:"tags"`
+	// StatsdAddress is the DogStatsD address metrics of datadog webhooks
+	// are sent to, ex. localhost:8125.
+	StatsdAddress string `mapstructure:"statsd-address"`
+	// Transition is the name of the Jira transition applied to linked
+	// issues after a successful apply, ex. Done.
+	Transition string `mapstructure:"transition"`
+	// From is the sender address of email webhooks.
+	From string `mapstructure:"from"`
+	// To are the recipients of email webhooks.
+	To []string `mapstructure:"to"`
+	// Template is a Go template used to render the message of slack and
+	// email webhooks. If empty, the default message is used.
+	Template string `mapstructure:"template"`
+}
+
+// AlertConfig is nested within UserConfig. It's used to configure the alerts
+// evaluated in-process and sent to the webhooks with the alert event.
+type AlertConfig struct {
+	// Name names the alert in notifications, ex. apply-failures.
+	Name string `mapstructure:"name"`
+	// Condition is when the alert fires, ex. "apply_failure_rate > 0.2".
+	Condition string `mapstructure:"condition"`
+	// Window is how far back plans and applies are counted, ex. 10m.
+	Window string `mapstructure:"window"`
 }
 
 //go:embed static
@@ -177,22 +293,60 @@ var staticAssets embed.FS
 // for the server CLI command because it injects all the dependencies.
 func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	logging.SuppressDefaultLogging()
-	logger, err := logging.NewStructuredLoggerFromLevel(userConfig.ToLogLevel())
+	sinks, err := logsink.ParseList(userConfig.LogSinks)
+	if err != nil {
+		return nil, fmt.Errorf("parsing --log-sinks: %w", err)
+	}
+	var logShippers []*logsink.Shipper
+	var logSinkWriters []io.Writer
+	for _, sink := range sinks {
+		shipper := logsink.NewShipper(sink)
+		logShippers = append(logShippers, shipper)
+		logSinkWriters = append(logSinkWriters, shipper)
+	}
+	logger, err := logging.NewStructuredLoggerFromLevel(userConfig.ToLogLevel(), logSinkWriters...)
 
 	if err != nil {
 		return nil, err
 	}
 
+	// Keep the references to secrets to watch them for rotations once the
+	// credentials are resolved.
+	secretsResolver := secrets.NewResolverFromEnv(logger)
+	secretReferences := userConfig
+	openRouterAPIKeyReference := os.Getenv(openRouterAPIKeyEnv)
+	if err := resolveSecrets(&userConfig, secretsResolver); err != nil {
+		return nil, err
+	}
+
 	var supportedVCSHosts []models.VCSHostType
 	var githubClient github.IGithubClient
+	var deploymentClient events.DeploymentClient
+	var fixSuggestionClient events.FixSuggestionClient
+	var reviewAnnotator runtime.ReviewAnnotator
+	var codeScanningClient events.CodeScanningClient
+	var driftPullRequestClient events.DriftPullRequestClient
+	var progressCommentClient events.ProgressCommentClient
+	var parentPullFinder events.ParentPullFinder
+	var openPullsLister events.OpenPullsLister
+	var commentReactions events.CommentReactionsLister
+	commitVerifiers := map[models.VCSHostType]events.CommitVerifier{}
+	codeOwners := map[models.VCSHostType]events.CodeOwnersClient{}
 	var githubAppEnabled bool
 	var githubConfig github.Config
 	var githubCredentials github.Credentials
 	var gitlabClient *gitlab.Client
+	var gitlabGroups []string
 	var bitbucketCloudClient *bitbucketcloud.Client
+	var bitbucketCloudCredentials bitbucketcloud.Credentials
 	var bitbucketServerClient *bitbucketserver.Client
 	var azuredevopsClient *azuredevops.Client
 	var giteaClient *gitea.Client
+	var gerritClient *gerrit.Client
+	var codecommitClient *codecommit.Client
+	// codecommitRegion is the region of the CodeCommit repos, which defaults
+	// to AWS_REGION.
+	var codecommitRegion string
 
 	policyChecksEnabled := false
 	if userConfig.EnablePolicyChecksFlag {
@@ -244,14 +398,10 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 				TokenFile: userConfig.GithubTokenFile,
 			}
 		} else if userConfig.GithubAppID != 0 && userConfig.GithubAppKeyFile != "" {
-			privateKey, err := os.ReadFile(userConfig.GithubAppKeyFile)
-			if err != nil {
-				return nil, err
-			}
 			githubCredentials = &github.AppCredentials{
 				AppID:          userConfig.GithubAppID,
 				InstallationID: userConfig.GithubAppInstallationID,
-				Key:            privateKey,
+				KeyFiles:       strings.Split(userConfig.GithubAppKeyFile, ","),
 				Hostname:       userConfig.GithubHostname,
 				AppSlug:        userConfig.GithubAppSlug,
 			}
@@ -272,8 +422,28 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		if err != nil {
 			return nil, err
 		}
+		if userConfig.GithubHostname != "github.com" {
+			rawGithubClient.DetectEnterpriseVersion(logger)
+		}
 
 		githubClient = github.NewInstrumentedGithubClient(rawGithubClient, statsScope, logger)
+		if userConfig.GithubDeployments {
+			deploymentClient = rawGithubClient
+		}
+		if userConfig.GithubCodeScanning {
+			codeScanningClient = rawGithubClient
+		}
+		if userConfig.EnableDriftPullRequests {
+			driftPullRequestClient = rawGithubClient
+		}
+		commentReactions = rawGithubClient
+		fixSuggestionClient = rawGithubClient
+		reviewAnnotator = rawGithubClient
+		progressCommentClient = rawGithubClient
+		parentPullFinder = rawGithubClient
+		openPullsLister = rawGithubClient
+		commitVerifiers[models.Github] = rawGithubClient
+		codeOwners[models.Github] = rawGithubClient
 	}
 	if userConfig.GitlabUser != "" {
 		supportedVCSHosts = append(supportedVCSHosts, models.Gitlab)
@@ -284,22 +454,38 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 			return nil, err
 		}
 
-		gitlabGroups := slices.Concat(gitlabGroupAllowlistChecker.AllTeams(), globalCfg.PolicySets.AllTeams())
+		gitlabGroups = slices.Concat(gitlabGroupAllowlistChecker.AllTeams(), globalCfg.PolicySets.AllTeams())
 		slices.Sort(gitlabGroups)
-		gitlabClient, err = gitlab.New(userConfig.GitlabHostname, userConfig.GitlabToken, slices.Compact(gitlabGroups), logger)
+		gitlabGroups = slices.Compact(gitlabGroups)
+		gitlabClient, err = gitlab.New(userConfig.GitlabHostname, userConfig.GitlabToken, gitlabGroups, logger)
 		if err != nil {
 			return nil, err
 		}
 		gitlabClient.StatusRetryEnabled = userConfig.GitlabStatusRetryEnabled
+		commitVerifiers[models.Gitlab] = gitlabClient
+		codeOwners[models.Gitlab] = gitlabClient
 	}
 	if userConfig.BitbucketUser != "" {
 		if userConfig.BitbucketBaseURL == bitbucketcloud.BaseURL {
 			supportedVCSHosts = append(supportedVCSHosts, models.BitbucketCloud)
-			bitbucketCloudClient = bitbucketcloud.New(
+			bitbucketCloudCredentials = &bitbucketcloud.AppPasswordCredentials{
+				Username:    userConfig.BitbucketUser,
+				APIUser:     userConfig.BitbucketApiUser,
+				AppPassword: userConfig.BitbucketToken,
+			}
+			switch {
+			case userConfig.BitbucketAccessToken != "":
+				bitbucketCloudCredentials = &bitbucketcloud.AccessTokenCredentials{Token: userConfig.BitbucketAccessToken}
+			case userConfig.BitbucketOAuthClientID != "":
+				bitbucketCloudCredentials = &bitbucketcloud.OAuthCredentials{
+					HTTPClient:   http.DefaultClient,
+					ClientID:     userConfig.BitbucketOAuthClientID,
+					ClientSecret: userConfig.BitbucketOAuthClientSecret,
+				}
+			}
+			bitbucketCloudClient = bitbucketcloud.NewWithCredentials(
 				http.DefaultClient,
-				userConfig.BitbucketUser,
-				userConfig.BitbucketToken,
-				userConfig.BitbucketApiUser,
+				bitbucketCloudCredentials,
 				userConfig.AtlantisURL)
 		} else {
 			supportedVCSHosts = append(supportedVCSHosts, models.BitbucketServer)
@@ -334,6 +520,24 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		} else {
 			logger.Info("gitea client configured successfully")
 		}
+		commitVerifiers[models.Gitea] = giteaClient
+	}
+	if userConfig.GerritUser != "" {
+		supportedVCSHosts = append(supportedVCSHosts, models.Gerrit)
+
+		gerritClient, err = gerrit.New(http.DefaultClient, userConfig.GerritBaseURL, userConfig.GerritUser, userConfig.GerritPassword, userConfig.GerritVerifiedLabel)
+		if err != nil {
+			return nil, fmt.Errorf("setting up Gerrit client: %w", err)
+		}
+	}
+	if userConfig.CodeCommitGitUser != "" {
+		supportedVCSHosts = append(supportedVCSHosts, models.CodeCommit)
+
+		codecommitClient, err = codecommit.NewFromDefaultConfig(context.Background(), http.DefaultClient, userConfig.CodeCommitRegion)
+		if err != nil {
+			return nil, fmt.Errorf("setting up CodeCommit client: %w", err)
+		}
+		codecommitRegion = codecommitClient.Region
 	}
 
 	var supportedVCSHostsStr []string
@@ -366,7 +570,14 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 			if bitbucketBaseURL == "https://api.bitbucket.org" {
 				bitbucketBaseURL = "bitbucket.org"
 			}
-			if err := common.WriteGitCreds(userConfig.BitbucketUser, userConfig.BitbucketToken, bitbucketBaseURL, home, logger, false); err != nil {
+			gitUser, gitToken := userConfig.BitbucketUser, userConfig.BitbucketToken
+			if bitbucketCloudCredentials != nil {
+				gitUser, gitToken, err = bitbucketCloudCredentials.GitCredentials()
+				if err != nil {
+					return nil, fmt.Errorf("getting Bitbucket Cloud git credentials: %w", err)
+				}
+			}
+			if err := common.WriteGitCreds(gitUser, gitToken, bitbucketBaseURL, home, logger, false); err != nil {
 				return nil, err
 			}
 		}
@@ -380,6 +591,20 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 				return nil, err
 			}
 		}
+		if userConfig.GerritUser != "" {
+			gerritURL, err := url.Parse(userConfig.GerritBaseURL)
+			if err != nil {
+				return nil, fmt.Errorf("parsing --gerrit-base-url: %w", err)
+			}
+			if err := common.WriteGitCreds(userConfig.GerritUser, userConfig.GerritPassword, gerritURL.Host, home, logger, false); err != nil {
+				return nil, err
+			}
+		}
+		if userConfig.CodeCommitGitUser != "" {
+			if err := common.WriteGitCreds(userConfig.CodeCommitGitUser, userConfig.CodeCommitGitPassword, codecommit.GitHostname(codecommitRegion), home, logger, false); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// default the project files used to generate the module index to the autoplan-file-list if autoplan-modules is true
@@ -396,7 +621,16 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 			Event:          c.Event,
 			Kind:           c.Kind,
 			WorkspaceRegex: c.WorkspaceRegex,
+			RepoRegex:      c.RepoRegex,
+			ProjectRegex:   c.ProjectRegex,
 			URL:            c.URL,
+			Template:       c.Template,
+			IntegrationKey: c.IntegrationKey,
+			Tags:           c.Tags,
+			StatsdAddress:  c.StatsdAddress,
+			Transition:     c.Transition,
+			From:           c.From,
+			To:             c.To,
 		}
 		webhooksConfig = append(webhooksConfig, config)
 	}
@@ -407,14 +641,63 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	webhooksManager, err := webhooks.NewMultiWebhookSender(
 		webhooksConfig,
 		webhooks.Clients{
-			Slack: webhooks.NewSlackClient(userConfig.SlackToken),
-			Http:  &webhooks.HttpClient{Client: http.DefaultClient, Headers: webhookHeaders},
+			Slack:   webhooks.NewSlackClient(userConfig.SlackToken),
+			Http:    &webhooks.HttpClient{Client: http.DefaultClient, Headers: webhookHeaders},
+			Logger:  logger,
+			DataDir: userConfig.DataDir,
 		},
 	)
 	if err != nil {
 		return nil, fmt.Errorf("initializing webhooks: %w", err)
 	}
-	vcsClient := vcs.NewClientProxy(githubClient, gitlabClient, bitbucketCloudClient, bitbucketServerClient, azuredevopsClient, giteaClient)
+	var tenants *events.Tenants
+	if userConfig.TenantsConfig != "" {
+		tenants, err = events.LoadTenants(userConfig.TenantsConfig)
+		if err != nil {
+			return nil, fmt.Errorf("loading --tenants-config: %w", err)
+		}
+	}
+	vcsClientProxy := vcs.NewClientProxy(githubClient, gitlabClient, bitbucketCloudClient, bitbucketServerClient, azuredevopsClient, giteaClient, gerritClient, codecommitClient)
+	pullTimeline := timeline.NewStore()
+	vcsClientProxy.Timeline = pullTimeline
+	var vcsClient vcs.Client = vcsClientProxy
+	if tenants != nil {
+		// The tenants with VCS credentials of their own call the VCS with
+		// clients of their own, which comment like the default client.
+		vcsClient = &events.TenantVCSClient{
+			Client:  vcsClientProxy,
+			Tenants: tenants,
+			NewClient: func(vcsHostType models.VCSHostType, credentials events.VCSCredentials) (vcs.Client, error) {
+				var tenantGithubClient, tenantGitlabClient vcs.Client
+				switch vcsHostType {
+				case models.Github:
+					rawClient, err := github.New(userConfig.GithubHostname, &github.UserCredentials{User: credentials.User, Token: credentials.Token}, githubConfig, userConfig.MaxCommentsPerCommand, logger)
+					if err != nil {
+						return nil, err
+					}
+					tenantGithubClient = github.NewInstrumentedGithubClient(rawClient, statsScope, logger)
+				case models.Gitlab:
+					rawClient, err := gitlab.New(userConfig.GitlabHostname, credentials.Token, gitlabGroups, logger)
+					if err != nil {
+						return nil, err
+					}
+					rawClient.StatusRetryEnabled = userConfig.GitlabStatusRetryEnabled
+					tenantGitlabClient = rawClient
+				default:
+					return nil, fmt.Errorf("tenants can't have credentials for %s", vcsHostType)
+				}
+				proxy := vcs.NewClientProxy(tenantGithubClient, tenantGitlabClient, nil, nil, nil, nil, nil, nil)
+				proxy.Timeline = vcsClientProxy.Timeline
+				proxy.RunURLs = vcsClientProxy.RunURLs
+				proxy.PullURLs = vcsClientProxy.PullURLs
+				return proxy, nil
+			},
+			Hostnames: map[models.VCSHostType]string{
+				models.Github: userConfig.GithubHostname,
+				models.Gitlab: vcsHostname(userConfig.GitlabHostname),
+			},
+		}
+	}
 	commitStatusUpdater := &events.DefaultCommitStatusUpdater{Client: vcsClient, StatusName: userConfig.VCSStatusName}
 
 	binDir, err := mkSubDir(userConfig.DataDir, BinDirName)
@@ -429,6 +712,15 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		return nil, err
 	}
 
+	var planJSONs *events.PlanJSONStore
+	if userConfig.ExportPlanJSON {
+		planJSONDir, err := mkSubDir(userConfig.DataDir, PlanJSONDirName)
+		if err != nil {
+			return nil, err
+		}
+		planJSONs = &events.PlanJSONStore{Dir: planJSONDir}
+	}
+
 	parsedURL, err := ParseAtlantisURL(userConfig.AtlantisURL)
 	if err != nil {
 		return nil, fmt.Errorf("parsing --%s flag %q: %w", config.AtlantisURLFlag, userConfig.AtlantisURL, err)
@@ -440,8 +732,11 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		LockViewRouteIDQueryParam: LockViewRouteIDQueryParam,
 		LockViewRouteName:         LockViewRouteName,
 		ProjectJobsViewRouteName:  ProjectJobsViewRouteName,
+		RunViewRouteName:          RunViewRouteName,
 		Underlying:                underlyingRouter,
 	}
+	vcsClientProxy.RunURLs = router
+	vcsClientProxy.PullURLs = router
 
 	var projectCmdOutputHandler jobs.ProjectCommandOutputHandler
 
@@ -454,6 +749,14 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 			projectCmdOutput,
 			logger,
 		)
+		if userConfig.LogSinkJobOutput {
+			projectCmdOutputHandler.(*jobs.AsyncProjectCommandOutputHandler).ForwardOutput(func(line *jobs.ProjectCmdOutputLine) {
+				entry := jobOutputEntry(line)
+				for _, shipper := range logShippers {
+					shipper.Send(entry)
+				}
+			})
+		}
 	}
 
 	distribution := terraform.NewDistribution(userConfig.DefaultTFDistribution)
@@ -477,6 +780,71 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	if err != nil && flag.Lookup("test.v") == nil {
 		return nil, fmt.Errorf("initializing %s: %w", userConfig.DefaultTFDistribution, err)
 	}
+	if userConfig.KubernetesJobImage != "" {
+		kubeClient, err := kubernetes.NewInClusterClient()
+		if err != nil {
+			return nil, fmt.Errorf("initializing the Kubernetes client: %w", err)
+		}
+		if userConfig.KubernetesJobNamespace != "" {
+			kubeClient.Namespace = userConfig.KubernetesJobNamespace
+		}
+		resources, err := kubernetes.ParseResources(userConfig.KubernetesJobResources)
+		if err != nil {
+			return nil, err
+		}
+		nodeSelector, err := kubernetes.ParseNodeSelector(userConfig.KubernetesJobNodeSelector)
+		if err != nil {
+			return nil, err
+		}
+		terraformClient.UseJobRunner(&kubernetes.JobRunner{
+			Client: kubeClient,
+			Config: kubernetes.JobConfig{
+				Image:           userConfig.KubernetesJobImage,
+				ServiceAccount:  userConfig.KubernetesJobServiceAccount,
+				Resources:       resources,
+				NodeSelector:    nodeSelector,
+				DataVolumeClaim: userConfig.KubernetesJobDataVolumeClaim,
+				DataDir:         userConfig.DataDir,
+			},
+			OutputHandler: projectCmdOutputHandler,
+		})
+		logger.Info("running plans and applies as Kubernetes jobs of image %s in namespace %s", userConfig.KubernetesJobImage, kubeClient.Namespace)
+	}
+	agentPools, err := userConfig.ToAgentPools()
+	if err != nil {
+		return nil, err
+	}
+	var agentDispatcher *agents.Dispatcher
+	if len(agentPools) > 0 {
+		agentDispatcher = agents.NewDispatcher(agentPools, projectCmdOutputHandler, logger)
+		terraformClient.UseAgentRunner(agentDispatcher)
+	}
+	var registryCache *registrycache.Proxy
+	if userConfig.EnableRegistryCache {
+		registryCacheDir, err := mkSubDir(userConfig.DataDir, RegistryCacheDirName)
+		if err != nil {
+			return nil, err
+		}
+		var hosts []string
+		for _, host := range strings.Split(userConfig.RegistryCacheHosts, ",") {
+			if host = strings.TrimSpace(host); host != "" {
+				hosts = append(hosts, host)
+			}
+		}
+		registryCache = registrycache.NewProxy(registryCacheDir, parsedURL.String(), hosts, int64(userConfig.RegistryCacheMaxSizeMB)*1024*1024, logger)
+		// Pointing terraform at our CLI configuration replaces ~/.terraformrc,
+		// ex. its TFE credentials, so they're copied into it.
+		home, err := homedir.Dir()
+		if err != nil {
+			return nil, fmt.Errorf("getting home dir to read ~/.terraformrc file: %w", err)
+		}
+		cliConfig := filepath.Join(registryCacheDir, "terraformrc")
+		if err := registryCache.WriteCLIConfig(cliConfig, filepath.Join(home, ".terraformrc")); err != nil {
+			return nil, err
+		}
+		terraformClient.UseCLIConfig(cliConfig)
+		logger.Info("caching the providers and modules of %s at %s%s", strings.Join(hosts, ", "), parsedURL.String(), registrycache.RoutePrefix)
+	}
 	markdownRenderer := events.NewMarkdownRenderer(
 		gitlabClient.SupportsCommonMark(),
 		userConfig.DisableApplyAll,
@@ -503,10 +871,28 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		}
 	case "boltdb":
 		logger.Info("Utilizing BoltDB")
-		database, err = boltdb.New(userConfig.DataDir)
+		boltDB, err := boltdb.New(userConfig.DataDir)
 		if err != nil {
 			return nil, err
 		}
+		if err := enableDBEncryption(boltDB, userConfig); err != nil {
+			boltDB.Close() // nolint: errcheck
+			return nil, err
+		}
+		database = boltDB
+	}
+
+	var leaderElection *redis.LeaderElection
+	if userConfig.LeaderElection {
+		redisDB, ok := database.(*redis.RedisDB)
+		if !ok {
+			return nil, errors.New("--leader-election requires --locking-db-type=redis")
+		}
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("getting the hostname identifying the instance: %w", err)
+		}
+		leaderElection = redisDB.LeaderElection(fmt.Sprintf("%s/%d", hostname, os.Getpid()), logger)
 	}
 
 	noOpLocker := locking.NewNoOpLocker()
@@ -516,23 +902,55 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	} else {
 		lockingClient = locking.NewClient(database)
 	}
+	if len(webhooksConfig) > 0 {
+		lockingClient = webhooks.NewLockingWebhooks(lockingClient, webhooksManager, logger)
+	}
 	disableGlobalApplyLock := userConfig.DisableGlobalApplyLock
 
 	applyLockingClient = locking.NewApplyClient(database, disableApply, disableGlobalApplyLock)
 	workingDirLocker := events.NewDefaultWorkingDirLocker()
 
-	var workingDir events.WorkingDir = &events.FileWorkspace{
+	// The working dir has its own copy of the global config, to be
+	// reloaded with the others, for the repos' checkout strategies.
+	workingDirGlobalCfg := globalCfg
+	fileWorkspace := &events.FileWorkspace{
 		DataDir:          userConfig.DataDir,
 		CheckoutMerge:    userConfig.CheckoutStrategy == "merge",
+		GlobalCfg:        &workingDirGlobalCfg,
 		CheckoutDepth:    userConfig.CheckoutDepth,
 		GithubAppEnabled: githubAppEnabled,
 	}
+	var workingDir events.WorkingDir = fileWorkspace
 
 	scheduledExecutorService := scheduled.NewExecutorService(
 		statsScope,
 		logger,
 	)
 
+	warmPoolSizes, err := userConfig.ToWarmPools()
+	if err != nil {
+		return nil, fmt.Errorf("parsing --warm-pools: %w", err)
+	}
+	if len(warmPoolSizes) > 0 {
+		terraformWarmer := &events.TerraformWarmer{
+			ParserValidator: parserValidator,
+			GlobalCfg:       &workingDirGlobalCfg,
+			TerraformClient: terraformClient,
+		}
+		fileWorkspace.WarmPool = &events.WarmPool{
+			DataDir:       userConfig.DataDir,
+			Sizes:         warmPoolSizes,
+			CheckoutDepth: userConfig.CheckoutDepth,
+			Init:          terraformWarmer.Init,
+			Logger:        logger,
+			StatsScope:    statsScope,
+		}
+		scheduledExecutorService.AddJob(scheduled.JobDefinition{
+			Job:    activeOnlyJob{Job: fileWorkspace.WarmPool, election: leaderElection},
+			Period: warmPoolPeriod,
+		})
+	}
+
 	// provide fresh tokens before clone from the GitHub Apps integration, proxy workingDir
 	if githubAppEnabled {
 		if !userConfig.WriteGitCreds {
@@ -552,6 +970,40 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		scheduledExecutorService.AddJob(tokenJd)
 	}
 
+	// The working dirs of the repos of the tenants are kept in data dirs of
+	// their own, and cloned with their credentials if they have some.
+	if tenants != nil {
+		workingDir = &events.TenantWorkingDir{
+			WorkingDir: workingDir,
+			Tenants:    tenants,
+			DataDir:    userConfig.DataDir,
+			NewWorkingDir: func(dataDir string) events.WorkingDir {
+				// The warm pools are shared by all the repos, they're not
+				// used by the tenants.
+				tenantWorkspace := *fileWorkspace
+				tenantWorkspace.DataDir = dataDir
+				tenantWorkspace.WarmPool = nil
+				if !githubAppEnabled {
+					return &tenantWorkspace
+				}
+				return &events.GithubAppWorkingDir{
+					WorkingDir:     &tenantWorkspace,
+					Credentials:    githubCredentials,
+					GithubHostname: userConfig.GithubHostname,
+				}
+			},
+		}
+	}
+
+	// OAuth tokens expire so they're refreshed in the git credentials.
+	if _, ok := bitbucketCloudCredentials.(*bitbucketcloud.OAuthCredentials); ok && userConfig.WriteGitCreds {
+		tokenJd, err := bitbucketcloud.NewTokenRotator(logger, bitbucketCloudCredentials, home).GenerateJob()
+		if err != nil {
+			return nil, fmt.Errorf("could not write credentials: %w", err)
+		}
+		scheduledExecutorService.AddJob(tokenJd)
+	}
+
 	if userConfig.GithubUser != "" && userConfig.GithubTokenFile != "" && userConfig.WriteGitCreds {
 		githubTokenRotator := github.NewTokenRotator(logger, githubCredentials, userConfig.GithubHostname, userConfig.GithubUser, home)
 		tokenJd, err := githubTokenRotator.GenerateJob()
@@ -565,25 +1017,29 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		Locker:     lockingClient,
 		NoOpLocker: noOpLocker,
 		VCSClient:  vcsClient,
+		Timeline:   pullTimeline,
 	}
 	deleteLockCommand := &events.DefaultDeleteLockCommand{
 		Locker:           lockingClient,
 		WorkingDir:       workingDir,
 		WorkingDirLocker: workingDirLocker,
 		Database:         database,
+		PlanJSONs:        planJSONs,
 	}
 
+	pullCleaner := &events.PullClosedExecutor{
+		Locker:                   lockingClient,
+		WorkingDir:               workingDir,
+		Database:                 database,
+		PullClosedTemplate:       &events.PullClosedEventTemplate{},
+		LogStreamResourceCleaner: projectCmdOutputHandler,
+		VCSClient:                vcsClient,
+		PlanJSONs:                planJSONs,
+	}
 	pullClosedExecutor := events.NewInstrumentedPullClosedExecutor(
 		statsScope,
 		logger,
-		&events.PullClosedExecutor{
-			Locker:                   lockingClient,
-			WorkingDir:               workingDir,
-			Database:                 database,
-			PullClosedTemplate:       &events.PullClosedEventTemplate{},
-			LogStreamResourceCleaner: projectCmdOutputHandler,
-			VCSClient:                vcsClient,
-		},
+		pullCleaner,
 	)
 
 	eventParser := &events.EventParser{
@@ -600,6 +1056,16 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		BitbucketServerURL: userConfig.BitbucketBaseURL,
 		AzureDevopsUser:    userConfig.AzureDevopsUser,
 		AzureDevopsToken:   userConfig.AzureDevopsToken,
+
+		BitbucketCloudCredentials: bitbucketCloudCredentials,
+
+		GerritBaseURL:  userConfig.GerritBaseURL,
+		GerritUser:     userConfig.GerritUser,
+		GerritPassword: userConfig.GerritPassword,
+
+		CodeCommitRegion:      codecommitRegion,
+		CodeCommitGitUser:     userConfig.CodeCommitGitUser,
+		CodeCommitGitPassword: userConfig.CodeCommitGitPassword,
 	}
 	commentParser := events.NewCommentParser(
 		userConfig.GithubUser,
@@ -607,6 +1073,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		userConfig.GiteaUser,
 		userConfig.BitbucketUser,
 		userConfig.AzureDevopsUser,
+		userConfig.GerritUser,
 		userConfig.ExecutableName,
 		allowCommands,
 	)
@@ -621,10 +1088,43 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		ProjectCmdOutputHandler: projectCmdOutputHandler,
 	}
 	drainer := &events.Drainer{}
+	// Frontends dispatch commands to the work queue and workers leave them in
+	// it while draining, so only the commands run in process are deferred.
+	var deferredCommands *events.DeferredCommands
+	var commandJournal *events.CommandJournal
+	if userConfig.ServerRole != FrontendServerRole && userConfig.ServerRole != WorkerServerRole {
+		deferredCommands = &events.DeferredCommands{
+			Path:             filepath.Join(userConfig.DataDir, "deferred-commands.json"),
+			CloneCredentials: eventParser,
+		}
+		commandJournal, err = events.NewCommandJournal(filepath.Join(userConfig.DataDir, "in-progress-commands.json"))
+		if err != nil {
+			return nil, err
+		}
+		commandJournal.CloneCredentials = eventParser
+	}
+	if len(userConfig.Alerts) > 0 {
+		alertEvaluator := &events.AlertEvaluator{Notifier: webhooksManager, Drainer: drainer, Logger: logger}
+		for _, alert := range userConfig.Alerts {
+			rule, err := events.NewAlertRule(alert.Name, alert.Condition, alert.Window)
+			if err != nil {
+				return nil, fmt.Errorf("parsing alerts: %w", err)
+			}
+			alertEvaluator.Rules = append(alertEvaluator.Rules, rule)
+		}
+		// The evaluator records the results of plans and applies sent to the
+		// webhooks.
+		webhooksManager.Webhooks = append(webhooksManager.Webhooks, alertEvaluator)
+		scheduledExecutorService.AddJob(scheduled.JobDefinition{
+			Job:    activeOnlyJob{Job: alertEvaluator, election: leaderElection},
+			Period: alertEvaluationPeriod,
+		})
+	}
 	statusController := &controllers.StatusController{
-		Logger:          logger,
-		Drainer:         drainer,
-		AtlantisVersion: config.AtlantisVersion,
+		Logger:           logger,
+		Drainer:          drainer,
+		AtlantisVersion:  config.AtlantisVersion,
+		DeferredCommands: deferredCommands,
 	}
 	preWorkflowHooksCommandRunner := &events.DefaultPreWorkflowHooksCommandRunner{
 		VCSClient:        vcsClient,
@@ -680,6 +1180,14 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		return nil, fmt.Errorf("initializing show step runner: %w", err)
 	}
 
+	costStepRunner := runtime.NewCostStepRunner(runtimemodels.LocalExec{})
+	if userConfig.AuditAdvisories != "" {
+		if _, err := os.Stat(userConfig.AuditAdvisories); err != nil {
+			return nil, fmt.Errorf("reading --audit-advisories: %w", err)
+		}
+	}
+	auditStepRunner := runtime.NewAuditStepRunner(userConfig.AuditAdvisories)
+
 	policyCheckStepRunner, err := runtime.NewPolicyCheckStepRunner(
 		defaultTfDistribution,
 		defaultTfVersion,
@@ -690,32 +1198,104 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		return nil, fmt.Errorf("initializing policy check step runner: %w", err)
 	}
 
+	var maxPlanAge time.Duration
+	if userConfig.MaxPlanAge != "" {
+		maxPlanAge, err = time.ParseDuration(userConfig.MaxPlanAge)
+		if err != nil {
+			return nil, fmt.Errorf("parsing --max-plan-age: %w", err)
+		}
+	}
+	var planfileEncryptor *runtime.PlanfileEncryptor
+	if userConfig.PlanfileEncryptionKey != "" {
+		planfileEncryptor, err = runtime.NewPlanfileEncryptor(userConfig.PlanfileEncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("parsing --planfile-encryption-key: %w", err)
+		}
+		if planJSONs != nil {
+			planJSONs.Encryptor = planfileEncryptor
+		}
+	}
+	var planfileSigner *runtime.PlanfileSigner
+	if userConfig.PlanfileSigningKey != "" {
+		planfileSigner, err = runtime.NewPlanfileSigner(userConfig.PlanfileSigningKey)
+		if err != nil {
+			return nil, fmt.Errorf("parsing --planfile-signing-key: %w", err)
+		}
+	}
+	var reportSigner *runtime.ReportSigner
+	if userConfig.ApplyReportSigningKey != "" {
+		reportSigner, err = runtime.NewReportSigner(userConfig.ApplyReportSigningKey)
+		if err != nil {
+			return nil, fmt.Errorf("parsing --apply-report-signing-key: %w", err)
+		}
+	}
+	var changeRequests events.ChangeRequestClient
+	if userConfig.ServiceNowURL != "" {
+		changeRequests = servicenow.NewClient(userConfig.ServiceNowURL, userConfig.ServiceNowUser, userConfig.ServiceNowPassword)
+	}
 	applyRequirementHandler := &events.DefaultCommandRequirementHandler{
-		WorkingDir: workingDir,
+		WorkingDir:        workingDir,
+		PlanfileEncryptor: planfileEncryptor,
+		MaxPlanAge:        maxPlanAge,
+		ChangeRequests:    changeRequests,
+		CommitVerifiers:   commitVerifiers,
+		CodeOwners:        codeOwners,
 	}
 
 	cancellationTracker := events.NewCancellationTracker()
 
+	stateLocks := &events.StateLockTracker{
+		TerraformExecutor:     terraformClient,
+		DefaultTFDistribution: defaultTfDistribution,
+		DefaultTFVersion:      defaultTfVersion,
+		WorkingDir:            workingDir,
+		WorkingDirLocker:      workingDirLocker,
+	}
+	if userConfig.EnableStateForceUnlock {
+		stateLocks.UnlockURL = parsedURL.String() + "/#state-locks"
+	}
+	projectStatuses := &events.ProjectStatusTracker{}
+
+	// Projects mapped to a Terraform Cloud workspace are planned and applied
+	// by its runs, they aren't initialized by Atlantis.
+	tfcRuns := &runtime.TFCRuns{CommitStatusUpdater: commitStatusUpdater}
+	if userConfig.TFEToken != "" {
+		tfcRuns.Client = tfcloud.NewClient(userConfig.TFEHostname, userConfig.TFEToken)
+	}
+
 	projectCommandRunner := &events.DefaultProjectCommandRunner{
 		VcsClient:        vcsClient,
 		Locker:           projectLocker,
 		LockURLGenerator: router,
 		Logger:           logger,
-		InitStepRunner: &runtime.InitStepRunner{
+		InitStepRunner: runtime.NewTFCStepRunnerDelegate(&runtime.InitStepRunner{
 			TerraformExecutor:     terraformClient,
 			DefaultTFDistribution: defaultTfDistribution,
 			DefaultTFVersion:      defaultTfVersion,
-		},
-		PlanStepRunner:        runtime.NewPlanStepRunner(terraformClient, defaultTfDistribution, defaultTfVersion, commitStatusUpdater, terraformClient),
-		ShowStepRunner:        showStepRunner,
-		PolicyCheckStepRunner: policyCheckStepRunner,
-		ApplyStepRunner: &runtime.ApplyStepRunner{
+		}, runtime.NullRunner{}),
+		PlanStepRunner: runtime.NewTFCStepRunnerDelegate(
+			runtime.NewPlanStepRunner(terraformClient, defaultTfDistribution, defaultTfVersion, commitStatusUpdater, terraformClient),
+			runtime.TFCPlanStepRunner{TFCRuns: tfcRuns},
+		),
+		ShowStepRunner:  showStepRunner,
+		CostStepRunner:  costStepRunner,
+		AuditStepRunner: auditStepRunner,
+		ValidateStepRunner: &runtime.ValidateStepRunner{
 			TerraformExecutor:     terraformClient,
 			DefaultTFDistribution: defaultTfDistribution,
 			DefaultTFVersion:      defaultTfVersion,
-			CommitStatusUpdater:   commitStatusUpdater,
-			AsyncTFExec:           terraformClient,
+			Annotator:             reviewAnnotator,
 		},
+		PolicyCheckStepRunner: policyCheckStepRunner,
+		ApplyStepRunner: runtime.NewTFCStepRunnerDelegate(&runtime.ApplyStepRunner{
+			TerraformExecutor:      terraformClient,
+			DefaultTFDistribution:  defaultTfDistribution,
+			DefaultTFVersion:       defaultTfVersion,
+			CommitStatusUpdater:    commitStatusUpdater,
+			AsyncTFExec:            terraformClient,
+			StateCheck:             userConfig.ApplyStateCheck,
+			StateDiffResourceTypes: userConfig.ToApplyStateDiffResourceTypes(),
+		}, runtime.TFCApplyStepRunner{TFCRuns: tfcRuns}),
 		RunStepRunner: runStepRunner,
 		EnvStepRunner: &runtime.EnvStepRunner{
 			RunStepRunner: runStepRunner,
@@ -735,16 +1315,106 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		WorkingDirLocker:          workingDirLocker,
 		CommandRequirementHandler: applyRequirementHandler,
 		CancellationTracker:       cancellationTracker,
+		ChangeRequests:            changeRequests,
+		Deployments:               deploymentClient,
+		FixSuggestions:            fixSuggestionClient,
+		CodeScanning:              codeScanningClient,
+		DriftPullRequests:         driftPullRequestClient,
+		PlanfileEncryptor:         planfileEncryptor,
+		PlanfileSigner:            planfileSigner,
+		StateLocks:                stateLocks,
+		ProjectStatuses:           projectStatuses,
+		PlanJSONs:                 planJSONs,
+		PlanGraphs:                userConfig.EnablePlanGraph,
+		ReuseUnchangedPlans:       userConfig.ReuseUnchangedPlans,
+	}
+	if userConfig.RecordResourceChanges {
+		projectCommandRunner.ResourceChanges = database
 	}
 
 	dbUpdater := &events.DBUpdater{
 		Database: database,
 	}
 
+	summaryFeedbackPollInterval, err := events.SummaryFeedbackPollInterval()
+	if err != nil {
+		return nil, err
+	}
+	var summaryFeedback *events.SummaryFeedbackTracker
+	if summaryFeedbackPollInterval > 0 && commentReactions != nil {
+		summaryFeedback = &events.SummaryFeedbackTracker{
+			DB:         database,
+			Reactions:  commentReactions,
+			Logger:     logger,
+			StatsScope: statsScope,
+		}
+		scheduledExecutorService.AddJob(scheduled.JobDefinition{
+			Job:    activeOnlyJob{Job: summaryFeedback, election: leaderElection},
+			Period: summaryFeedbackPollInterval,
+		})
+	}
+
+	profiles := &events.ProfileStore{Dir: filepath.Join(userConfig.DataDir, "profiles"), Logger: logger}
+	if userConfig.ArtifactMaxAge != "" || userConfig.ArtifactRepoQuotaMB > 0 {
+		artifactRetention := &events.ArtifactRetention{
+			Stores:     []events.ArtifactStore{&events.PlanfileArtifacts{DataDir: userConfig.DataDir}, profiles},
+			RepoQuota:  int64(userConfig.ArtifactRepoQuotaMB) * 1024 * 1024,
+			Logger:     logger,
+			StatsScope: statsScope,
+		}
+		if userConfig.ArtifactMaxAge != "" {
+			artifactRetention.MaxAge, err = time.ParseDuration(userConfig.ArtifactMaxAge)
+			if err != nil {
+				return nil, fmt.Errorf("parsing --artifact-max-age: %w", err)
+			}
+		}
+		if planJSONs != nil {
+			artifactRetention.Stores = append(artifactRetention.Stores, planJSONs)
+		}
+		if handler, ok := projectCmdOutputHandler.(*jobs.AsyncProjectCommandOutputHandler); ok {
+			artifactRetention.Stores = append(artifactRetention.Stores, &events.JobLogArtifacts{Handler: handler})
+		}
+		scheduledExecutorService.AddJob(scheduled.JobDefinition{
+			Job:    activeOnlyJob{Job: artifactRetention, election: leaderElection},
+			Period: artifactRetentionPeriod,
+		})
+	}
+
+	diskMonitor := &events.DiskMonitor{
+		DataDir:           userConfig.DataDir,
+		WorkingDirLocker:  workingDirLocker,
+		Locker:            lockingClient,
+		EvictionThreshold: userConfig.DiskEvictionThreshold,
+		CriticalThreshold: userConfig.DiskCriticalThreshold,
+		Logger:            logger,
+		StatsScope:        statsScope,
+	}
+	if fileWorkspace.WarmPool != nil {
+		fileWorkspace.WarmPool.DiskMonitor = diskMonitor
+	}
+	scheduledExecutorService.AddJob(scheduled.JobDefinition{
+		Job:    activeOnlyJob{Job: diskMonitor, election: leaderElection},
+		Period: diskMonitorPeriod,
+	})
+
+	summaries := events.NewSummaryStore()
+	if reportSigner != nil {
+		projectCommandRunner.ApplyReports = &events.ApplyReports{
+			Signer:    reportSigner,
+			Database:  database,
+			Summaries: summaries,
+		}
+	}
 	pullUpdater := &events.PullUpdater{
 		HidePrevPlanComments: userConfig.HidePrevPlanComments,
+		MentionCodeOwners:    userConfig.MentionCodeOwners,
 		VCSClient:            vcsClient,
 		MarkdownRenderer:     markdownRenderer,
+		Webhooks:             webhooksManager,
+		SummarySink:          events.NewSummarySinkFromEnv(),
+		SummaryFeedback:      summaryFeedback,
+		Summaries:            summaries,
+		Timeline:             pullTimeline,
 	}
 
 	autoMerger := &events.AutoMerger{
@@ -752,10 +1422,35 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		GlobalAutomerge: userConfig.Automerge,
 	}
 
+	concurrencyGroups, err := userConfig.ToConcurrencyGroups()
+	if err != nil {
+		return nil, fmt.Errorf("parsing --concurrency-groups: %w", err)
+	}
+	var scheduledProjectCommandRunner events.ProjectCommandRunner = projectCommandRunner
+	if userConfig.MaxConcurrentProjects > 0 || userConfig.MaxConcurrentProjectsPerRepo > 0 || len(concurrencyGroups) > 0 {
+		scheduler := &events.ProjectCommandScheduler{
+			ProjectCommandRunner: projectCommandRunner,
+			JobMessageSender:     projectCmdOutputHandler,
+			MaxConcurrent:        userConfig.MaxConcurrentProjects,
+			MaxConcurrentPerRepo: userConfig.MaxConcurrentProjectsPerRepo,
+			Groups:               concurrencyGroups,
+		}
+		// The concurrency groups are counted across the servers sharing the
+		// Redis locking DB, ex. the workers.
+		if redisDB, ok := database.(*redis.RedisDB); ok && len(concurrencyGroups) > 0 {
+			hostname, err := os.Hostname()
+			if err != nil {
+				return nil, fmt.Errorf("getting the hostname holding concurrency slots: %w", err)
+			}
+			scheduler.SharedSlots = redisDB.ConcurrencySlots(hostname)
+		}
+		scheduledProjectCommandRunner = scheduler
+	}
 	projectOutputWrapper := &events.ProjectOutputWrapper{
 		JobMessageSender:     projectCmdOutputHandler,
-		ProjectCommandRunner: projectCommandRunner,
+		ProjectCommandRunner: scheduledProjectCommandRunner,
 		JobURLSetter:         jobs.NewJobURLSetter(router, commitStatusUpdater),
+		Journal:              commandJournal,
 	}
 	instrumentedProjectCmdRunner := events.NewInstrumentedProjectCommandRunner(
 		statsScope,
@@ -795,6 +1490,8 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		pullReqStatusFetcher,
 		userConfig.PendingApplyStatus,
 	)
+	planCommandRunner.ReuseUnchangedPlans = userConfig.ReuseUnchangedPlans
+	planCommandRunner.PlanJSONs = planJSONs
 
 	applyCommandRunner := events.NewApplyCommandRunner(
 		vcsClient,
@@ -813,6 +1510,22 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		userConfig.SilenceVCSStatusNoProjects,
 		pullReqStatusFetcher,
 	)
+	if userConfig.ApplyConfirmProjects > 0 || userConfig.ApplyConfirmDestroys {
+		applyCommandRunner.Confirmations = &events.ApplyConfirmations{
+			ProjectsThreshold: userConfig.ApplyConfirmProjects,
+			Destroys:          userConfig.ApplyConfirmDestroys,
+			WorkingDir:        workingDir,
+			PlanfileEncryptor: planfileEncryptor,
+		}
+	}
+	applyCommandRunner.Freezes = &events.ChangeFreezes{
+		GlobalCfg:  globalCfg,
+		HTTPClient: http.DefaultClient,
+	}
+	// Applies that don't go through the apply command, ex. of the API or of
+	// previews, are blocked too.
+	projectCommandRunner.Freezes = applyCommandRunner.Freezes
+	applyCommandRunner.Changelog = events.NewApplyChangelogFromEnv()
 
 	approvePoliciesCommandRunner := events.NewApprovePoliciesCommandRunner(
 		commitStatusUpdater,
@@ -856,12 +1569,20 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 
 	cancelCommandRunner := events.NewCancelCommandRunner(
 		vcsClient,
-		projectOutputWrapper.ProjectCommandRunner,
+		projectCommandRunner,
 		pullUpdater,
 		workingDirLocker,
 		userConfig.SilenceNoProjects,
 	)
 
+	summaryCommandRunner := &events.SummaryCommandRunner{
+		VCSClient:             vcsClient,
+		PullUpdater:           pullUpdater,
+		ProjectCommandBuilder: projectCommandBuilder,
+		WorkingDir:            workingDir,
+		PlanfileEncryptor:     planfileEncryptor,
+	}
+
 	commentCommandRunnerByCmd := map[command.Name]events.CommentCommandRunner{
 		command.Plan:            planCommandRunner,
 		command.Apply:           applyCommandRunner,
@@ -871,6 +1592,29 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		command.Import:          importCommandRunner,
 		command.State:           stateCommandRunner,
 		command.Cancel:          cancelCommandRunner,
+		command.Summary:         summaryCommandRunner,
+	}
+
+	previewCommandRunner := &events.PreviewCommandRunner{
+		VCSClient:             vcsClient,
+		ProjectCommandBuilder: projectCommandBuilder,
+		ProjectCommandRunner:  instrumentedProjectCmdRunner,
+		OutputStepRunner:      runtime.NewOutputStepRunner(terraformClient, defaultTfDistribution, defaultTfVersion),
+		WorkingDir:            workingDir,
+		Locker:                lockingClient,
+		Database:              database,
+		MaxEnvironments:       userConfig.MaxPreviewEnvironments,
+		Disabled:              !userConfig.EnablePreviewEnvironments,
+	}
+	commentCommandRunnerByCmd[command.Preview] = previewCommandRunner
+	var previews *events.PreviewCommandRunner
+	if userConfig.EnablePreviewEnvironments {
+		previews = previewCommandRunner
+		pullCleaner.Previews = previews
+		scheduledExecutorService.AddJob(scheduled.JobDefinition{
+			Job:    activeOnlyJob{Job: &events.PreviewExpiryJob{Previews: previews, Logger: logger, StatsScope: statsScope}, election: leaderElection},
+			Period: time.Minute,
+		})
 	}
 
 	var teamAllowlistChecker command.TeamAllowlistChecker
@@ -899,10 +1643,14 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 
 	commandRunner := &events.DefaultCommandRunner{
 		VCSClient:                      vcsClient,
+		Timeline:                       pullTimeline,
+		CommandRecords:                 database,
 		GithubPullGetter:               githubClient,
 		GitlabMergeRequestGetter:       gitlabClient,
 		AzureDevopsPullGetter:          azuredevopsClient,
 		GiteaPullGetter:                giteaClient,
+		GerritChangeGetter:             gerritClient,
+		CodeCommitPullGetter:           codecommitClient,
 		CommentCommandRunnerByCmd:      commentCommandRunnerByCmd,
 		EventParser:                    eventParser,
 		FailOnPreWorkflowHookError:     userConfig.FailOnPreWorkflowHookError,
@@ -917,17 +1665,137 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		DisableAutoplan:                userConfig.DisableAutoplan,
 		DisableAutoplanLabel:           userConfig.DisableAutoplanLabel,
 		Drainer:                        drainer,
+		DeferredCommands:               deferredCommands,
+		Journal:                        commandJournal,
+		DiskMonitor:                    diskMonitor,
 		PreWorkflowHooksCommandRunner:  preWorkflowHooksCommandRunner,
 		PostWorkflowHooksCommandRunner: postWorkflowHooksCommandRunner,
 		PullStatusFetcher:              database,
 		TeamAllowlistChecker:           teamAllowlistChecker,
 		VarFileAllowlistChecker:        varFileAllowlistChecker,
 		CommitStatusUpdater:            commitStatusUpdater,
+		EmojiReactionSuccess:           userConfig.EmojiReactionSuccess,
+		EmojiReactionFailure:           userConfig.EmojiReactionFailure,
+		Tenants:                        tenants,
+		Previews:                       previews,
+	}
+	// Auto-applies are authorized like the applies commented by the user
+	// who triggered the plan.
+	planCommandRunner.AutoApplyRunner = &events.AuthorizedAutoApplyRunner{CommandRunner: commandRunner}
+	if userConfig.EnableProgressComments && progressCommentClient != nil {
+		commandRunner.ProgressCommenter = &events.ProgressCommenter{
+			Client:          progressCommentClient,
+			JobURLGenerator: router,
+			Interval:        30 * time.Second,
+		}
+	}
+	if parentPullFinder != nil {
+		commandRunner.StackedPulls = &events.StackedPulls{Finder: parentPullFinder}
+	}
+	// Frontends dispatch the commands of webhooks and the API to the workers
+	// instead of running them, workers run the commands of the work queue.
+	var dispatcher events.CommandRunner = commandRunner
+	var worker *events.Worker
+	var jobOutput *redis.JobOutput
+	if userConfig.ServerRole == FrontendServerRole || userConfig.ServerRole == WorkerServerRole {
+		redisDB, ok := database.(*redis.RedisDB)
+		if !ok {
+			return nil, fmt.Errorf("--server-role=%s requires --locking-db-type=redis", userConfig.ServerRole)
+		}
+		// Workers consume the Redis stream with their hostname, so the items a
+		// worker didn't finish are delivered again when it restarts with it.
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("getting the hostname naming the worker: %w", err)
+		}
+		var queue events.WorkQueue
+		switch userConfig.WorkQueueType {
+		case SQSWorkQueue:
+			if queue, err = workqueue.NewSQS(context.Background(), userConfig.WorkQueueURL); err != nil {
+				return nil, err
+			}
+		case NATSWorkQueue:
+			if queue, err = workqueue.NewNATS(context.Background(), userConfig.WorkQueueURL); err != nil {
+				return nil, err
+			}
+		default:
+			queue = redisDB.WorkQueue(hostname)
+		}
+		// Workers relay the output of the jobs they run to the frontends, which
+		// serve it in the UI.
+		asyncOutputHandler, relayOutput := projectCmdOutputHandler.(*jobs.AsyncProjectCommandOutputHandler)
+		if userConfig.ServerRole == FrontendServerRole {
+			dispatcher = &events.QueuedCommandRunner{Queue: queue, VCSClient: vcsClient}
+			if relayOutput {
+				jobOutput = redisDB.JobOutput(logger)
+			}
+		} else {
+			worker = &events.Worker{
+				Queue:            queue,
+				PullLocks:        redisDB.PullLocks(hostname),
+				CommandRunner:    commandRunner,
+				Drainer:          drainer,
+				Logger:           logger,
+				Concurrency:      userConfig.WorkerConcurrency,
+				CloneCredentials: eventParser,
+			}
+			if relayOutput {
+				asyncOutputHandler.RelayOutput(redisDB.JobOutput(logger).Publish)
+			}
+		}
+		logger.Info("running as a %s, the work queue is in %s", userConfig.ServerRole, userConfig.WorkQueueType)
+	}
+	if userConfig.RepoRateLimit > 0 || userConfig.PullRateLimit > 0 || userConfig.MaxConcurrentCommands > 0 {
+		commandRunner.Scheduler = &events.CommandScheduler{
+			VCSClient:     vcsClient,
+			RepoRateLimit: userConfig.RepoRateLimit,
+			PullRateLimit: userConfig.PullRateLimit,
+			MaxConcurrent: userConfig.MaxConcurrentCommands,
+		}
 	}
 	repoAllowlist, err := events.NewRepoAllowlistChecker(userConfig.RepoAllowlist)
 	if err != nil {
 		return nil, err
 	}
+	repoAllowlist.Tenants = tenants
+	commandRunner.RepoAllowlistChecker = repoAllowlist
+	if userConfig.CommandAuthzPolicy != "" {
+		if _, err := os.Stat(userConfig.CommandAuthzPolicy); err != nil {
+			return nil, fmt.Errorf("reading --command-authz-policy: %w", err)
+		}
+		opaPath, err := exec.LookPath("opa")
+		if err != nil {
+			return nil, fmt.Errorf("--command-authz-policy requires the opa binary: %w", err)
+		}
+		commandRunner.CommandAuthorizer = &events.CommandAuthorizer{
+			OPAPath:    opaPath,
+			PolicyPath: userConfig.CommandAuthzPolicy,
+		}
+	}
+	var webOIDC *webauth.OIDC
+	if userConfig.WebOIDCIssuerURL != "" {
+		var permissions *webauth.Permissions
+		if userConfig.WebPermissionsConfig != "" {
+			permissions, err = webauth.LoadPermissions(userConfig.WebPermissionsConfig)
+			if err != nil {
+				return nil, fmt.Errorf("loading --web-permissions-config: %w", err)
+			}
+		}
+		webOIDC, err = webauth.NewOIDC(context.Background(), webauth.OIDCConfig{
+			IssuerURL:    userConfig.WebOIDCIssuerURL,
+			ClientID:     userConfig.WebOIDCClientID,
+			ClientSecret: userConfig.WebOIDCClientSecret,
+			Scopes:       strings.Split(userConfig.WebOIDCScopes, ","),
+			GroupsClaim:  userConfig.WebOIDCGroupsClaim,
+			Permissions:  permissions,
+			Tenants:      tenants,
+			AtlantisURL:  parsedURL,
+		}, logger)
+		if err != nil {
+			return nil, err
+		}
+	}
+	webAuthentication := userConfig.WebBasicAuth || webOIDC != nil
 	locksController := &controllers.LocksController{
 		AtlantisVersion:    config.AtlantisVersion,
 		AtlantisURL:        parsedURL,
@@ -940,6 +1808,8 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		WorkingDirLocker:   workingDirLocker,
 		Database:           database,
 		DeleteLockCommand:  deleteLockCommand,
+		StateLocks:         stateLocks,
+		WebAuthentication:  webAuthentication,
 	}
 
 	wsMux := websocket.NewMultiplexor(
@@ -959,8 +1829,32 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		WsMux:                    wsMux,
 		KeyGenerator:             controllers.JobIDKeyGenerator{},
 		StatsScope:               statsScope.SubScope("api"),
+		Canceller:                cancelCommandRunner,
+		OutputHandler:            projectCmdOutputHandler,
+		WorkingDir:               workingDir,
+		PlanfileEncryptor:        planfileEncryptor,
+	}
+
+	webhookIPAllowlist, err := newIPAllowlist(userConfig.WebhookIPAllowlist, userConfig.GithubHostname, logger, scheduledExecutorService)
+	if err != nil {
+		return nil, fmt.Errorf("parsing webhook ip allowlist: %w", err)
+	}
+	apiIPAllowlist, err := newIPAllowlist(userConfig.APIIPAllowlist, userConfig.GithubHostname, logger, scheduledExecutorService)
+	if err != nil {
+		return nil, fmt.Errorf("parsing api ip allowlist: %w", err)
+	}
+
+	var sslClientCAs *x509.CertPool
+	if userConfig.SSLClientCAFile != "" {
+		if sslClientCAs, err = LoadClientCAs(userConfig.SSLClientCAFile); err != nil {
+			return nil, err
+		}
 	}
 
+	apiArtifactTokens, err := userConfig.ToAPIArtifactTokens()
+	if err != nil {
+		return nil, fmt.Errorf("parsing api artifact tokens: %w", err)
+	}
 	apiController := &controllers.APIController{
 		APISecret:                      []byte(userConfig.APISecret),
 		Locker:                         lockingClient,
@@ -979,10 +1873,57 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		WorkingDirLocker:               workingDirLocker,
 		CommitStatusUpdater:            commitStatusUpdater,
 		SilenceVCSStatusNoProjects:     userConfig.SilenceVCSStatusNoProjects,
+		GlobalCfg:                      globalCfg,
+		ParserValidator:                parserValidator,
+		Drainer:                        drainer,
+		Database:                       database,
+		DeleteLockCommand:              deleteLockCommand,
+		ProjectCmdOutputHandler:        projectCmdOutputHandler,
+		Summaries:                      summaries,
+		Timeline:                       pullTimeline,
+		Canceller:                      cancelCommandRunner,
+		CommandRunner:                  dispatcher,
+		PlanJSONs:                      planJSONs,
+		Profiles:                       profiles,
+		ArtifactTokens:                 apiArtifactTokens,
+		PlanfileEncryptor:              planfileEncryptor,
+		DeferredCommands:               deferredCommands,
+	}
+
+	configReloader := &ConfigReloader{
+		Logger:          logger,
+		Drainer:         drainer,
+		ParserValidator: parserValidator,
+		GlobalCfgArgs: valid.GlobalCfgArgs{
+			PolicyCheckEnabled: userConfig.EnablePolicyChecksFlag,
+		},
+		RepoConfigFile: userConfig.RepoConfig,
+		GlobalCfgs: []*valid.GlobalCfg{
+			&commandRunner.GlobalCfg,
+			&preWorkflowHooksCommandRunner.GlobalCfg,
+			&postWorkflowHooksCommandRunner.GlobalCfg,
+			&apiController.GlobalCfg,
+			&workingDirGlobalCfg,
+			&applyCommandRunner.Freezes.GlobalCfg,
+		},
+		TenantsFile: userConfig.TenantsConfig,
+		Tenants:     tenants,
+	}
+	if builder, ok := projectCommandBuilder.ProjectCommandBuilder.(*events.DefaultProjectCommandBuilder); ok {
+		configReloader.GlobalCfgs = append(configReloader.GlobalCfgs, &builder.GlobalCfg)
+	}
+	configReloader.Init()
+	if userConfig.RepoConfig != "" || userConfig.TenantsConfig != "" {
+		apiController.ReloadConfig = configReloader.Reload
+		scheduledExecutorService.AddJob(scheduled.JobDefinition{
+			Job:    configReloader,
+			Period: configReloadPollInterval,
+		})
 	}
 
 	eventsController := &events_controllers.VCSEventsController{
-		CommandRunner:                   commandRunner,
+		CommandRunner:                   dispatcher,
+		Timeline:                        pullTimeline,
 		PullCleaner:                     pullClosedExecutor,
 		Parser:                          eventParser,
 		CommentParser:                   commentParser,
@@ -1004,6 +1945,79 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		AzureDevopsWebhookBasicPassword: []byte(userConfig.AzureDevopsWebhookPassword),
 		AzureDevopsRequestValidator:     &events_controllers.DefaultAzureDevopsRequestValidator{},
 		GiteaWebhookSecret:              []byte(userConfig.GiteaWebhookSecret),
+		GerritWebhookSecret:             []byte(userConfig.GerritWebhookSecret),
+		CodeCommitWebhookSecret:         []byte(userConfig.CodeCommitWebhookSecret),
+		CodeCommitClient:                codecommitClient,
+	}
+	if userConfig.ReplanOnBasePush && openPullsLister != nil {
+		eventsController.BaseBranchReplanner = &events.BaseBranchReplanner{
+			PullsLister:      openPullsLister,
+			Parser:           eventParser,
+			Database:         database,
+			WorkingDir:       workingDir,
+			WorkingDirLocker: workingDirLocker,
+			PlanJSONs:        planJSONs,
+			VCSClient:        vcsClient,
+			CommandRunner:    dispatcher,
+			Logger:           logger,
+			Delay:            time.Minute,
+		}
+	}
+	if secretsResolver.HasReferences() {
+		secretsRefreshInterval, err := time.ParseDuration(userConfig.SecretsRefreshInterval)
+		if err != nil {
+			return nil, fmt.Errorf("parsing --secrets-refresh-interval: %w", err)
+		}
+		githubAppCredentials, _ := githubCredentials.(*github.AppCredentials)
+		watchSecrets(secretsResolver, secretReferences, openRouterAPIKeyReference, eventsController, githubAppCredentials, logger)
+		scheduledExecutorService.AddJob(scheduled.JobDefinition{
+			Job:    secretsResolver,
+			Period: secretsRefreshInterval,
+		})
+	}
+	apiTokensController := &controllers.APITokensController{
+		AtlantisVersion:   config.AtlantisVersion,
+		AtlantisURL:       parsedURL,
+		Database:          database,
+		Logger:            logger,
+		Template:          web_templates.APITokensTemplate,
+		WebAuthentication: webAuthentication,
+	}
+	if userConfig.WebhookHistory > 0 {
+		eventsController.History = events_controllers.NewWebhookHistory(userConfig.WebhookHistory)
+	}
+	webhooksController := &controllers.WebhooksController{
+		AtlantisVersion:   config.AtlantisVersion,
+		AtlantisURL:       parsedURL,
+		History:           eventsController.History,
+		Replayer:          eventsController,
+		Logger:            logger,
+		Template:          web_templates.WebhooksTemplate,
+		WebAuthentication: webAuthentication,
+	}
+	projectsController := &controllers.ProjectsController{
+		AtlantisVersion:  config.AtlantisVersion,
+		AtlantisURL:      parsedURL,
+		Locker:           lockingClient,
+		Statuses:         projectStatuses,
+		LockURLGenerator: router,
+		Logger:           logger,
+		Template:         web_templates.ProjectsTemplate,
+	}
+	pullController := &controllers.PullController{
+		AtlantisVersion:  config.AtlantisVersion,
+		AtlantisURL:      parsedURL,
+		Database:         database,
+		Locker:           lockingClient,
+		OutputHandler:    projectCmdOutputHandler,
+		Statuses:         projectStatuses,
+		LockURLGenerator: router,
+		Logger:           logger,
+		Template:         web_templates.PullTemplate,
+	}
+	agentsController := &controllers.AgentsController{
+		Dispatcher: agentDispatcher,
+		Logger:     logger,
 	}
 	githubAppController := &controllers.GithubAppController{
 		AtlantisURL:         parsedURL,
@@ -1024,6 +2038,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		Logger:                         logger,
 		StatsScope:                     statsScope,
 		StatsReporter:                  statsReporter,
+		StatsNamespace:                 userConfig.StatsNamespace,
 		StatsCloser:                    closer,
 		Locker:                         lockingClient,
 		ApplyLocker:                    applyLockingClient,
@@ -1033,21 +2048,42 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		JobsController:                 jobsController,
 		StatusController:               statusController,
 		APIController:                  apiController,
+		APITokensController:            apiTokensController,
+		WebhooksController:             webhooksController,
+		ProjectsController:             projectsController,
+		PullController:                 pullController,
+		AgentsController:               agentsController,
 		IndexTemplate:                  web_templates.IndexTemplate,
 		LockDetailTemplate:             web_templates.LockTemplate,
 		ProjectJobsTemplate:            web_templates.ProjectJobsTemplate,
 		ProjectJobsErrorTemplate:       web_templates.ProjectJobsErrorTemplate,
 		SSLKeyFile:                     userConfig.SSLKeyFile,
 		SSLCertFile:                    userConfig.SSLCertFile,
+		SSLClientCAs:                   sslClientCAs,
+		SSLClientAuth:                  userConfig.SSLClientAuth,
+		WebhookIPAllowlist:             webhookIPAllowlist,
+		APIIPAllowlist:                 apiIPAllowlist,
 		DisableGlobalApplyLock:         userConfig.DisableGlobalApplyLock,
+		EnableStateForceUnlock:         userConfig.EnableStateForceUnlock,
+		StateLocks:                     stateLocks,
 		Drainer:                        drainer,
+		Worker:                         worker,
+		JobOutput:                      jobOutput,
+		LeaderElection:                 leaderElection,
+		DeferredCommands:               deferredCommands,
 		ProjectCmdOutputHandler:        projectCmdOutputHandler,
 		WebAuthentication:              userConfig.WebBasicAuth,
 		WebUsername:                    userConfig.WebUsername,
 		WebPassword:                    userConfig.WebPassword,
+		OIDC:                           webOIDC,
 		ScheduledExecutorService:       scheduledExecutorService,
 		EnableProfilingAPI:             userConfig.EnableProfilingAPI,
+		RegistryCache:                  registryCache,
+		Tenants:                        tenants,
+		ConfigReloader:                 configReloader,
 		database:                       database,
+		webhooks:                       webhooksManager,
+		validationChecks:               newValidationChecks(userConfig, globalCfg, len(webhooksConfig), database),
 	}
 
 	validate := validator.New(validator.WithRequiredStructEnabled())
@@ -1066,12 +2102,51 @@ func (s *Server) Start() error {
 		return r.URL.Path == "/" || r.URL.Path == "/index.html"
 	})
 	s.Router.HandleFunc("/healthz", s.Healthz).Methods("GET")
+	s.Router.HandleFunc("/active", s.Active).Methods("GET")
 	s.Router.HandleFunc("/status", s.StatusController.Get).Methods("GET")
 	s.Router.PathPrefix("/static/").Handler(http.FileServer(http.FS(staticAssets)))
-	s.Router.HandleFunc("/events", s.VCSEventsController.Post).Methods("POST")
-	s.Router.HandleFunc("/api/plan", s.APIController.Plan).Methods("POST")
-	s.Router.HandleFunc("/api/apply", s.APIController.Apply).Methods("POST")
+	s.Router.HandleFunc("/events", s.activeOnly(s.VCSEventsController.Post)).Methods("POST")
+	s.Router.HandleFunc("/api/plan", s.activeOnly(s.APIController.Plan)).Methods("POST")
+	s.Router.HandleFunc("/api/apply", s.activeOnly(s.APIController.Apply)).Methods("POST")
 	s.Router.HandleFunc("/api/locks", s.APIController.ListLocks).Methods("GET")
+	s.Router.HandleFunc("/api/locks", s.APIController.DeleteLock).Methods("DELETE")
+	s.Router.HandleFunc("/api/jobs", s.APIController.ListJobs).Methods("GET")
+	s.Router.HandleFunc("/api/jobs/{job-id}/logs", s.APIController.JobLogs).Methods("GET")
+	s.Router.HandleFunc("/api/jobs/{job-id}/artifacts/plan", s.APIController.PlanArtifact).Methods("GET")
+	s.Router.HandleFunc("/api/summaries", s.APIController.ListSummaries).Methods("GET")
+	s.Router.HandleFunc("/api/summaries/variants", s.APIController.CompareSummaryVariants).Methods("GET")
+	s.Router.HandleFunc("/api/pulls/{repo:.+}/{pull:[0-9]+}/events", s.APIController.ListPullEvents).Methods("GET")
+	s.Router.HandleFunc("/api/pulls/{repo:.+}/{pull:[0-9]+}/commands", s.APIController.ListPullCommands).Methods("GET")
+	s.Router.HandleFunc("/api/plans", s.APIController.ListPlans).Methods("GET")
+	s.Router.HandleFunc("/api/resource-changes", s.APIController.ListResourceChanges).Methods("GET")
+	s.Router.HandleFunc("/api/apply-reports", s.APIController.ListApplyReports).Methods("GET")
+	s.Router.HandleFunc("/api/apply-reports/{id}", s.APIController.ApplyReport).Methods("GET")
+	s.Router.HandleFunc("/api/cancel", s.APIController.Cancel).Methods("POST")
+	s.Router.HandleFunc("/api/profiles", s.APIController.ListProfiles).Methods("GET")
+	s.Router.HandleFunc("/api/profiles", s.APIController.CaptureProfiles).Methods("POST")
+	s.Router.HandleFunc("/api/profiles/{id}", s.APIController.Profile).Methods("GET")
+	s.Router.HandleFunc("/api/commands/replay", s.activeOnly(s.APIController.ReplayCommand)).Methods("POST")
+	s.Router.HandleFunc("/api/config/inspect", s.APIController.InspectConfig).Methods("POST")
+	s.Router.HandleFunc("/api/config/validate", s.APIController.ValidateConfig).Methods("POST")
+	s.Router.HandleFunc("/api/config/schema", s.APIController.ConfigSchema).Methods("GET")
+	s.Router.HandleFunc("/api/config/reload", s.APIController.ReloadConfigs).Methods("POST")
+	s.Router.HandleFunc("/api/drain", s.APIController.Drain).Methods("POST")
+	s.Router.HandleFunc("/api/drain", s.APIController.Resume).Methods("DELETE")
+	s.Router.HandleFunc("/api/tokens", s.APIController.ListAPITokens).Methods("GET")
+	s.Router.HandleFunc("/api/tokens", s.APIController.CreateAPIToken).Methods("POST")
+	s.Router.HandleFunc("/api/tokens", s.APIController.RevokeAPIToken).Methods("DELETE")
+	s.Router.HandleFunc("/api/agents/jobs/next", s.AgentsController.Next).Methods("POST")
+	s.Router.HandleFunc("/api/agents/jobs/{id}/archive", s.AgentsController.DownloadArchive).Methods("GET")
+	s.Router.HandleFunc("/api/agents/jobs/{id}/archive", s.AgentsController.UploadArchive).Methods("POST")
+	s.Router.HandleFunc("/api/agents/jobs/{id}/output", s.AgentsController.Output).Methods("POST")
+	s.Router.HandleFunc("/api/agents/jobs/{id}/complete", s.AgentsController.Complete).Methods("POST")
+	s.Router.HandleFunc("/api-tokens", webauth.Require(webauth.Admin, s.APITokensController.Get)).Methods("GET")
+	s.Router.HandleFunc("/api-tokens", webauth.Require(webauth.Admin, s.APITokensController.Create)).Methods("POST")
+	s.Router.HandleFunc("/api-tokens", webauth.Require(webauth.Admin, s.APITokensController.Revoke)).Methods("DELETE")
+	s.Router.HandleFunc("/webhooks", webauth.Require(webauth.Admin, s.WebhooksController.Get)).Methods("GET")
+	s.Router.HandleFunc("/projects", s.ProjectsController.Get).Methods("GET")
+	s.Router.HandleFunc("/pull", s.PullController.Get).Methods("GET")
+	s.Router.HandleFunc("/webhooks/replay", webauth.Require(webauth.Admin, s.activeOnly(s.WebhooksController.Replay))).Methods("POST")
 	s.Router.HandleFunc("/github-app/exchange-code", s.GithubAppController.ExchangeCode).Methods("GET")
 	s.Router.HandleFunc("/github-app/setup", s.GithubAppController.New).Methods("GET")
 	s.Router.HandleFunc("/locks", s.LocksController.DeleteLock).Methods("DELETE").Queries("id", "{id:.*}")
@@ -1079,14 +2154,35 @@ func (s *Server) Start() error {
 		Queries(LockViewRouteIDQueryParam, fmt.Sprintf("{%s}", LockViewRouteIDQueryParam)).Name(LockViewRouteName)
 	s.Router.HandleFunc("/jobs/{job-id}", s.JobsController.GetProjectJobs).Methods("GET").Name(ProjectJobsViewRouteName)
 	s.Router.HandleFunc("/jobs/{job-id}/ws", s.JobsController.GetProjectJobsWS).Methods("GET")
+	s.Router.HandleFunc("/jobs/cancel", webauth.RequireSameOrigin(s.JobsController.CancelPull)).Methods("POST")
+	s.Router.HandleFunc("/runs/{run-id}", s.JobsController.GetRun).Methods("GET").Name(RunViewRouteName)
 
 	r, ok := s.StatsReporter.(prometheus.Reporter)
 	if ok {
-		s.Router.Handle(s.CommandRunner.GlobalCfg.Metrics.Prometheus.Endpoint, r.HTTPHandler())
+		endpoint := s.CommandRunner.GlobalCfg.Metrics.Prometheus.Endpoint
+		s.Router.Handle(endpoint, r.HTTPHandler())
+		// The reporter registers the metrics with the default registry.
+		s.Router.HandleFunc(path.Join(endpoint, "grafana-dashboard.json"),
+			metrics.GeneratedHandler(metrics.GrafanaDashboard, "application/json", promclient.DefaultGatherer, s.StatsNamespace)).Methods("GET")
+		s.Router.HandleFunc(path.Join(endpoint, "rules.yaml"),
+			metrics.GeneratedHandler(metrics.PrometheusRules, "application/yaml", promclient.DefaultGatherer, s.StatsNamespace)).Methods("GET")
 	}
 	if !s.DisableGlobalApplyLock {
-		s.Router.HandleFunc("/apply/lock", s.LocksController.LockApply).Methods("POST").Queries()
-		s.Router.HandleFunc("/apply/unlock", s.LocksController.UnlockApply).Methods("DELETE").Queries()
+		s.Router.HandleFunc("/apply/lock", webauth.Require(webauth.Admin, s.LocksController.LockApply)).Methods("POST").Queries()
+		s.Router.HandleFunc("/apply/unlock", webauth.Require(webauth.Admin, s.LocksController.UnlockApply)).Methods("DELETE").Queries()
+	}
+	if s.EnableStateForceUnlock {
+		s.Router.HandleFunc("/state-locks/force-unlock", webauth.RequireSameOrigin(s.LocksController.ForceUnlockState)).Methods("POST").Queries("id", "{id:.*}")
+	}
+
+	if s.OIDC != nil {
+		s.Router.HandleFunc(webauth.LoginPath, s.OIDC.Login).Methods("GET")
+		s.Router.HandleFunc(webauth.CallbackPath, s.OIDC.Callback).Methods("GET")
+		s.Router.HandleFunc(webauth.LogoutPath, s.OIDC.Logout).Methods("GET")
+	}
+
+	if s.RegistryCache != nil {
+		s.Router.PathPrefix(registrycache.RoutePrefix).Handler(s.RegistryCache).Methods("GET", "HEAD")
 	}
 
 	if s.EnableProfilingAPI {
@@ -1097,16 +2193,22 @@ func (s *Server) Start() error {
 			"/symbol":  pprof.Symbol,
 			"/trace":   pprof.Trace,
 		} {
-			s.Router.HandleFunc("/debug/pprof"+p, h).Methods("GET")
+			s.Router.HandleFunc("/debug/pprof"+p, webauth.Require(webauth.Admin, h)).Methods("GET")
 		}
 	}
 
 	n := negroni.New(&negroni.Recovery{
-		Logger:     log.New(os.Stdout, "", log.LstdFlags),
+		Logger:     logging.NewStdLogger(s.Logger, logging.Error),
 		PrintStack: false,
 		StackAll:   false,
 		StackSize:  1024 * 8,
 	}, NewRequestLogger(s))
+	if s.SSLClientCAs != nil && s.SSLClientAuth != SSLClientAuthAll {
+		n.Use(&ClientCertRequirer{})
+	}
+	if s.WebhookIPAllowlist != nil || s.APIIPAllowlist != nil {
+		n.Use(&IPAllowlister{Webhooks: s.WebhookIPAllowlist, API: s.APIIPAllowlist, Logger: s.Logger})
+	}
 	n.UseHandler(s.Router)
 
 	defer s.Logger.Flush()
@@ -1118,11 +2220,70 @@ func (s *Server) Start() error {
 
 	go s.ScheduledExecutorService.Run()
 
+	// Reload the server-side repo config and tenants config on SIGHUPs.
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+	go func() {
+		for range reload {
+			if err := s.ConfigReloader.Reload(); err != nil {
+				s.Logger.Err("unable to reload config, keeping the current config: %s", err)
+			}
+		}
+	}()
+
 	go func() {
 		s.ProjectCmdOutputHandler.Handle()
 	}()
+	if s.JobOutput != nil {
+		go s.JobOutput.Subscribe(context.Background(), s.ProjectCmdOutputHandler.(*jobs.AsyncProjectCommandOutputHandler).Receive)
+	}
+
+	// Recover the commands the previous process didn't complete, and run the
+	// ones deferred while it was draining. With leader election, only the
+	// active instance does, once it's promoted.
+	recoverCommands := func() {
+		s.CommandRunner.RecoverInterruptedCommands()
+		if s.DeferredCommands != nil {
+			if err := s.DeferredCommands.Replay(s.CommandRunner, s.Logger); err != nil {
+				s.Logger.Err("unable to run the deferred commands: %s", err)
+			}
+		}
+	}
+	if s.LeaderElection != nil {
+		s.LeaderElection.OnPromoted = recoverCommands
+	} else {
+		recoverCommands()
+	}
+
+	electionCtx, stopElection := context.WithCancel(context.Background())
+	electionStopped := make(chan struct{})
+	go func() {
+		if s.LeaderElection != nil {
+			s.LeaderElection.Run(electionCtx)
+		}
+		close(electionStopped)
+	}()
+
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	workerStopped := make(chan struct{})
+	go func() {
+		if s.Worker != nil {
+			s.Worker.Run(workerCtx)
+		}
+		close(workerStopped)
+	}()
 
 	tlsConfig := &tls.Config{GetCertificate: s.GetSSLCertificate, MinVersion: tls.VersionTLS12}
+	if s.SSLClientCAs != nil {
+		tlsConfig.ClientCAs = s.SSLClientCAs
+		// Client certificates are verified during the handshake, the endpoints
+		// that require them are enforced by ClientCertRequirer.
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		if s.SSLClientAuth == SSLClientAuthAll {
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
 
 	server := &http.Server{Addr: fmt.Sprintf(":%d", s.Port), Handler: n, TLSConfig: tlsConfig, ReadHeaderTimeout: 10 * time.Second}
 	go func() {
@@ -1142,7 +2303,19 @@ func (s *Server) Start() error {
 	<-stop
 
 	s.Logger.Warn("Received interrupt. Waiting for in-progress operations to complete")
+	// Workers stop taking commands from the work queue first, the ones they
+	// run complete while draining.
+	stopWorker()
+	// The active instance resigns right away so a standby handles the new
+	// webhooks while it drains.
+	stopElection()
 	s.waitForDrain()
+	<-workerStopped
+	<-electionStopped
+
+	if err := s.webhooks.Close(); err != nil {
+		s.Logger.Err("while stopping webhooks: %v", err)
+	}
 
 	// flush stats before shutdown
 	if err := s.StatsCloser.Close(); err != nil {
@@ -1199,16 +2372,23 @@ func (s *Server) closeDatabase(timeout time.Duration) error {
 }
 
 // Index is the / route.
-func (s *Server) Index(w http.ResponseWriter, _ *http.Request) {
+func (s *Server) Index(w http.ResponseWriter, r *http.Request) {
 	locks, err := s.Locker.List()
 	if err != nil {
 		w.WriteHeader(http.StatusServiceUnavailable)
 		fmt.Fprintf(w, "Could not retrieve locks: %s", err)
 		return
 	}
+	// If tenants are configured the index can be filtered to the repos of a
+	// tenant, ex. /?tenant=acme. It only filters what the user can view, the
+	// users of the web_groups of tenants only view the repos of their tenants.
+	tenant := r.URL.Query().Get("tenant")
 
 	var lockResults []web_templates.LockIndexData
 	for id, v := range locks {
+		if !s.visible(r, tenant, v.Project.RepoFullName, v.Pull.BaseRepo.VCSHost.Hostname) {
+			continue
+		}
 		lockURL, _ := s.Router.Get(LockViewRouteName).URL("id", url.QueryEscape(id))
 		lockResults = append(lockResults, web_templates.LockIndexData{
 			// NOTE: must use .String() instead of .Path because we need the
@@ -1241,21 +2421,62 @@ func (s *Server) Index(w http.ResponseWriter, _ *http.Request) {
 	//Sort by date - newest to oldest.
 	sort.SliceStable(lockResults, func(i, j int) bool { return lockResults[i].Time.After(lockResults[j].Time) })
 
+	var stateLocks []web_templates.StateLockIndexData
+	if s.StateLocks != nil {
+		for _, v := range s.StateLocks.List() {
+			if !s.visible(r, tenant, v.BaseRepo.FullName, v.BaseRepo.VCSHost.Hostname) {
+				continue
+			}
+			stateLocks = append(stateLocks, web_templates.StateLockIndexData{
+				ID:            v.Lock.ID,
+				RepoFullName:  v.BaseRepo.FullName,
+				PullNum:       v.PullNum,
+				PullURL:       v.PullURL,
+				Path:          v.RepoRelDir,
+				Workspace:     v.Workspace,
+				Who:           v.Lock.Who,
+				Operation:     v.Lock.Operation,
+				Created:       v.Lock.Created,
+				TimeFormatted: v.DetectedAt.Format("2006-01-02 15:04:05"),
+			})
+		}
+	}
+
 	err = s.IndexTemplate.Execute(w, web_templates.IndexData{
-		Locks:            lockResults,
-		PullToJobMapping: preparePullToJobMappings(s),
-		ApplyLock:        applyLockData,
-		AtlantisVersion:  s.AtlantisVersion,
-		CleanedBasePath:  s.AtlantisURL.Path,
+		Locks:                   lockResults,
+		PullToJobMapping:        preparePullToJobMappings(s, r, tenant),
+		StateLocks:              stateLocks,
+		StateForceUnlockEnabled: s.EnableStateForceUnlock,
+		ApplyLock:               applyLockData,
+		AtlantisVersion:         s.AtlantisVersion,
+		CleanedBasePath:         s.AtlantisURL.Path,
 	})
 	if err != nil {
 		s.Logger.Err(err.Error())
 	}
 }
 
-func preparePullToJobMappings(s *Server) []jobs.PullInfoWithJobIDs {
+// visible returns true if the user of r is allowed to view the repo and it
+// belongs to tenant, or if tenant is empty or no tenants are configured.
+func (s *Server) visible(r *http.Request, tenant string, repoFullName string, vcsHostname string) bool {
+	if !webauth.Allowed(r, webauth.ViewJobs, repoFullName, vcsHostname) {
+		return false
+	}
+	if tenant == "" || s.Tenants == nil {
+		return true
+	}
+	name, ok := s.Tenants.TenantFor(repoFullName, vcsHostname)
+	return ok && name == tenant
+}
+
+func preparePullToJobMappings(s *Server, r *http.Request, tenant string) []jobs.PullInfoWithJobIDs {
 
-	pullToJobMappings := s.ProjectCmdOutputHandler.GetPullToJobMapping()
+	pullToJobMappings := make([]jobs.PullInfoWithJobIDs, 0)
+	for _, m := range s.ProjectCmdOutputHandler.GetPullToJobMapping() {
+		if s.visible(r, tenant, m.Pull.RepoFullName, m.Pull.VCSHostname) {
+			pullToJobMappings = append(pullToJobMappings, m)
+		}
+	}
 
 	for i := range pullToJobMappings {
 		for j := range pullToJobMappings[i].JobIDInfos {
@@ -1287,6 +2508,21 @@ func preparePullToJobMappings(s *Server) []jobs.PullInfoWithJobIDs {
 	return pullToJobMappings
 }
 
+// jobOutputEntry returns the log entry of a line of terraform output of a job.
+func jobOutputEntry(line *jobs.ProjectCmdOutputLine) logsink.Entry {
+	contents, _ := json.Marshal(map[string]any{
+		"job-id":    line.JobID,
+		"repo":      line.JobInfo.RepoFullName,
+		"pull":      line.JobInfo.PullNum,
+		"project":   line.JobInfo.ProjectName,
+		"path":      line.JobInfo.Path,
+		"workspace": line.JobInfo.Workspace,
+		"step":      line.JobInfo.JobStep,
+		"line":      line.Line,
+	})
+	return logsink.Entry{Time: time.Now(), Stream: logsink.StreamJobs, Line: contents}
+}
+
 func mkSubDir(parentDir string, subDir string) (string, error) {
 	fullDir := filepath.Join(parentDir, subDir)
 	if err := os.MkdirAll(fullDir, 0700); err != nil {
@@ -1306,6 +2542,61 @@ var healthzData = []byte(`{
   "status": "ok"
 }`)
 
+// Active responds 200 if this instance is the active one of the leader
+// election, or there's none, and 503 if it's a standby. Load balancers route
+// webhooks to the instances it's healthy on.
+func (s *Server) Active(w http.ResponseWriter, r *http.Request) {
+	active := s.LeaderElection == nil || s.LeaderElection.IsLeader()
+	response := struct {
+		Active bool   `json:"active"`
+		Leader string `json:"leader,omitempty"`
+	}{Active: active}
+	if s.LeaderElection != nil {
+		leader, err := s.LeaderElection.Leader(r.Context())
+		if err != nil {
+			s.Logger.Warn("unable to get the active instance: %s", err)
+		}
+		response.Leader = leader
+	}
+	data, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "Error creating active response: %s", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if !active {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	w.Write(data) // nolint: errcheck
+}
+
+// activeOnlyJob runs Job only on the active instance of the leader election,
+// if any.
+type activeOnlyJob struct {
+	Job      scheduled.Job
+	election *redis.LeaderElection
+}
+
+func (j activeOnlyJob) Run() {
+	if j.election != nil && !j.election.IsLeader() {
+		return
+	}
+	j.Job.Run()
+}
+
+// activeOnly rejects the requests to standbys, so only the active instance
+// runs commands.
+func (s *Server) activeOnly(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.LeaderElection != nil && !s.LeaderElection.IsLeader() {
+			http.Error(w, "this Atlantis instance is a standby, send the request to the active instance", http.StatusServiceUnavailable)
+			return
+		}
+		handler(w, r)
+	}
+}
+
 func (s *Server) GetSSLCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
 	certStat, err := os.Stat(s.SSLCertFile)
 	if err != nil {
@@ -1330,6 +2621,20 @@ func (s *Server) GetSSLCertificate(*tls.ClientHelloInfo) (*tls.Certificate, erro
 	return s.SSLCert, nil
 }
 
+// LoadClientCAs loads the PEM encoded certificates of the CAs client
+// certificates are verified against from path.
+func LoadClientCAs(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path) // nolint: gosec
+	if err != nil {
+		return nil, fmt.Errorf("reading client CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no PEM encoded certificates found in client CA file %s", path)
+	}
+	return pool, nil
+}
+
 // ParseAtlantisURL parses the user-passed atlantis URL to ensure it is valid
 // and we can use it in our templates.
 // It removes any trailing slashes from the path so we can concatenate it
@@ -1347,3 +2652,12 @@ func ParseAtlantisURL(u string) (*url.URL, error) {
 	parsed.Path = strings.TrimSuffix(parsed.Path, "/")
 	return parsed, nil
 }
+
+// vcsHostname returns the hostname of a VCS host flag, which may be a URL, ex.
+// --gitlab-hostname.
+func vcsHostname(hostname string) string {
+	if u, err := url.Parse(withScheme(hostname)); err == nil {
+		return u.Hostname()
+	}
+	return hostname
+}
@@ -14,14 +14,17 @@
 package server
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"testing/synctest"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	. "github.com/petergtz/pegomock/v4"
 	"github.com/runatlantis/atlantis/server/core/db"
 	"github.com/runatlantis/atlantis/server/core/db/mocks"
+	"github.com/runatlantis/atlantis/server/core/redis"
 	"github.com/runatlantis/atlantis/server/logging"
 	"github.com/stretchr/testify/assert"
 )
@@ -98,3 +101,25 @@ func TestServer_CloseDatabase(t *testing.T) {
 		})
 	}
 }
+
+type countingJob struct{ runs int }
+
+func (j *countingJob) Run() { j.runs++ }
+
+func TestActiveOnlyJob(t *testing.T) {
+	mr := miniredis.RunT(t)
+	database, err := redis.New(mr.Host(), mr.Server().Addr().Port, "", false, false, 0)
+	assert.NoError(t, err)
+	active := database.LeaderElection("active", logging.NewNoopLogger(t))
+	active.Campaign(context.Background())
+	standby := database.LeaderElection("standby", logging.NewNoopLogger(t))
+	standby.Campaign(context.Background())
+
+	job := &countingJob{}
+	activeOnlyJob{Job: job, election: active}.Run()
+	activeOnlyJob{Job: job, election: standby}.Run()
+	assert.Equal(t, 1, job.runs, "only the active instance runs the job")
+
+	activeOnlyJob{Job: job}.Run()
+	assert.Equal(t, 2, job.runs, "every instance runs the job without leader election")
+}
@@ -15,15 +15,20 @@ package server_test
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/gorilla/mux"
 	. "github.com/petergtz/pegomock/v4"
 	"github.com/runatlantis/atlantis/cmd"
@@ -31,6 +36,8 @@ import (
 	"github.com/runatlantis/atlantis/server/controllers/web_templates"
 	tMocks "github.com/runatlantis/atlantis/server/controllers/web_templates/mocks"
 	"github.com/runatlantis/atlantis/server/core/locking/mocks"
+	"github.com/runatlantis/atlantis/server/core/redis"
+	"github.com/runatlantis/atlantis/server/events"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/jobs"
 	"github.com/runatlantis/atlantis/server/logging"
@@ -150,6 +157,67 @@ func TestIndex_Success(t *testing.T) {
 	ResponseContains(t, w, http.StatusOK, "")
 }
 
+func TestIndex_Tenant(t *testing.T) {
+	RegisterMockTestingT(t)
+	tenantsPath := filepath.Join(t.TempDir(), "tenants.yaml")
+	Ok(t, os.WriteFile(tenantsPath, []byte("tenants:\n- name: acme\n  repo_allowlist: github.com/acme/*\n- name: globex\n  repo_allowlist: github.com/globex/*\n"), 0600))
+	tenants, err := events.LoadTenants(tenantsPath)
+	Ok(t, err)
+
+	l := mocks.NewMockLocker()
+	al := mocks.NewMockApplyLocker()
+	now := time.Now()
+	github := models.VCSHost{Hostname: "github.com"}
+	locks := map[string]models.ProjectLock{
+		"acme/infra/./default": {
+			Pull:    models.PullRequest{Num: 1, BaseRepo: models.Repo{VCSHost: github}},
+			Project: models.Project{RepoFullName: "acme/infra"},
+			Time:    now,
+		},
+		"globex/infra/./default": {
+			Pull:    models.PullRequest{Num: 2, BaseRepo: models.Repo{VCSHost: github}},
+			Project: models.Project{RepoFullName: "globex/infra"},
+			Time:    now,
+		},
+	}
+	When(l.List()).ThenReturn(locks, nil)
+	it := tMocks.NewMockTemplateWriter()
+	r := mux.NewRouter()
+	r.NewRoute().Path("/lock").
+		Queries("id", "{id}").Name(server.LockViewRouteName)
+	u, err := url.Parse("https://example.com")
+	Ok(t, err)
+	s := server.Server{
+		Locker:                  l,
+		ApplyLocker:             al,
+		IndexTemplate:           it,
+		Router:                  r,
+		AtlantisURL:             u,
+		Logger:                  logging.NewNoopLogger(t),
+		ProjectCmdOutputHandler: &jobs.NoopProjectOutputHandler{},
+		Tenants:                 tenants,
+	}
+	req, _ := http.NewRequest("GET", "/?tenant=globex", bytes.NewBuffer(nil))
+	w := httptest.NewRecorder()
+	s.Index(w, req)
+	it.VerifyWasCalledOnce().Execute(w, web_templates.IndexData{
+		ApplyLock: web_templates.ApplyLockData{
+			TimeFormatted: "0001-01-01 00:00:00",
+		},
+		Locks: []web_templates.LockIndexData{
+			{
+				LockPath:      "/lock?id=globex%252Finfra%252F.%252Fdefault",
+				RepoFullName:  "globex/infra",
+				PullNum:       2,
+				Time:          now,
+				TimeFormatted: now.Format("2006-01-02 15:04:05"),
+			},
+		},
+		PullToJobMapping: []jobs.PullInfoWithJobIDs{},
+	})
+	ResponseContains(t, w, http.StatusOK, "")
+}
+
 func TestHealthz(t *testing.T) {
 	s := server.Server{}
 	req, _ := http.NewRequest("GET", "/healthz", bytes.NewBuffer(nil))
@@ -167,6 +235,25 @@ func TestHealthz(t *testing.T) {
 }`, string(body))
 }
 
+func TestActive(t *testing.T) {
+	mr := miniredis.RunT(t)
+	database, err := redis.New(mr.Host(), mr.Server().Addr().Port, "", false, false, 0)
+	Ok(t, err)
+	active := database.LeaderElection("active", logging.NewNoopLogger(t))
+	active.Campaign(context.Background())
+	standby := server.Server{LeaderElection: database.LeaderElection("standby", logging.NewNoopLogger(t)), Logger: logging.NewNoopLogger(t)}
+	standby.LeaderElection.Campaign(context.Background())
+
+	w := httptest.NewRecorder()
+	standby.Active(w, httptest.NewRequest("GET", "/active", nil))
+	ResponseContains(t, w, http.StatusServiceUnavailable, `"leader": "active"`)
+
+	t.Log("without leader election, every instance is active")
+	w = httptest.NewRecorder()
+	(&server.Server{}).Active(w, httptest.NewRequest("GET", "/active", nil))
+	ResponseContains(t, w, http.StatusOK, `"active": true`)
+}
+
 type mockRW struct{}
 
 var _ http.ResponseWriter = mockRW{}
@@ -210,6 +297,41 @@ func TestGetCertificate(t *testing.T) {
 		"Certificate expected to rotate")
 }
 
+func TestLoadClientCAs(t *testing.T) {
+	pool, err := server.LoadClientCAs("../testdata/cert.pem")
+	Ok(t, err)
+	Assert(t, pool != nil, "exp pool")
+
+	_, err = server.LoadClientCAs("../testdata/key.pem")
+	ErrEquals(t, "no PEM encoded certificates found in client CA file ../testdata/key.pem", err)
+}
+
+func TestClientCertRequirer(t *testing.T) {
+	verified := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}}
+	cases := []struct {
+		path    string
+		tls     *tls.ConnectionState
+		expCode int
+	}{
+		{"/events", nil, http.StatusForbidden},
+		{"/events", &tls.ConnectionState{}, http.StatusForbidden},
+		{"/api/plan", &tls.ConnectionState{}, http.StatusForbidden},
+		{"/events", verified, http.StatusOK},
+		{"/api/plan", verified, http.StatusOK},
+		{"/healthz", &tls.ConnectionState{}, http.StatusOK},
+		{"/", nil, http.StatusOK},
+	}
+	for _, c := range cases {
+		t.Run(c.path, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", c.path, nil)
+			req.TLS = c.tls
+			w := httptest.NewRecorder()
+			(&server.ClientCertRequirer{}).ServeHTTP(w, req, func(http.ResponseWriter, *http.Request) {})
+			Equals(t, c.expCode, w.Result().StatusCode)
+		})
+	}
+}
+
 func TestParseAtlantisURL(t *testing.T) {
 	cases := []struct {
 		In     string
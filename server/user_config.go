@@ -5,9 +5,16 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"path"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/runatlantis/atlantis/server/agents"
+	"github.com/runatlantis/atlantis/server/controllers"
+	"github.com/runatlantis/atlantis/server/events"
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/logging"
 )
@@ -16,9 +23,18 @@ import (
 // The mapstructure tags correspond to flags in cmd/server.go and are used when
 // the config is parsed from a YAML file.
 type UserConfig struct {
+	AgentPools                  string `mapstructure:"agent-pools"`
 	AllowForkPRs                bool   `mapstructure:"allow-fork-prs"`
 	AllowCommands               string `mapstructure:"allow-commands"`
+	ApplyConfirmDestroys        bool   `mapstructure:"apply-confirm-destroys"`
+	ApplyConfirmProjects        int    `mapstructure:"apply-confirm-projects"`
+	ApplyReportSigningKey       string `mapstructure:"apply-report-signing-key"`
+	ApplyStateCheck             string `mapstructure:"apply-state-check"`
+	ApplyStateDiff              string `mapstructure:"apply-state-diff"`
+	ArtifactMaxAge              string `mapstructure:"artifact-max-age"`
+	ArtifactRepoQuotaMB         int    `mapstructure:"artifact-repo-quota-mb"`
 	AtlantisURL                 string `mapstructure:"atlantis-url"`
+	AuditAdvisories             string `mapstructure:"audit-advisories"`
 	AutoDiscoverModeFlag        string `mapstructure:"autodiscover-mode"`
 	Automerge                   bool   `mapstructure:"automerge"`
 	AutoplanFileList            string `mapstructure:"autoplan-file-list"`
@@ -29,14 +45,25 @@ type UserConfig struct {
 	AzureDevopsWebhookPassword  string `mapstructure:"azuredevops-webhook-password"`
 	AzureDevopsWebhookUser      string `mapstructure:"azuredevops-webhook-user"`
 	AzureDevOpsHostname         string `mapstructure:"azuredevops-hostname"`
+	BitbucketAccessToken        string `mapstructure:"bitbucket-access-token"`
 	BitbucketApiUser            string `mapstructure:"bitbucket-api-user"`
 	BitbucketBaseURL            string `mapstructure:"bitbucket-base-url"`
+	BitbucketOAuthClientID      string `mapstructure:"bitbucket-oauth-client-id"`
+	BitbucketOAuthClientSecret  string `mapstructure:"bitbucket-oauth-client-secret"`
 	BitbucketToken              string `mapstructure:"bitbucket-token"`
 	BitbucketUser               string `mapstructure:"bitbucket-user"`
 	BitbucketWebhookSecret      string `mapstructure:"bitbucket-webhook-secret"`
 	CheckoutDepth               int    `mapstructure:"checkout-depth"`
 	CheckoutStrategy            string `mapstructure:"checkout-strategy"`
+	CodeCommitGitPassword       string `mapstructure:"codecommit-git-password"`
+	CodeCommitGitUser           string `mapstructure:"codecommit-git-user"`
+	CodeCommitRegion            string `mapstructure:"codecommit-region"`
+	CodeCommitWebhookSecret     string `mapstructure:"codecommit-webhook-secret"`
+	CommandAuthzPolicy          string `mapstructure:"command-authz-policy"`
+	ConcurrencyGroups           string `mapstructure:"concurrency-groups"`
 	DataDir                     string `mapstructure:"data-dir"`
+	DBEncryptionKey             string `mapstructure:"db-encryption-key"`
+	DBEncryptionKMSKeyID        string `mapstructure:"db-encryption-kms-key-id"`
 	DisableApplyAll             bool   `mapstructure:"disable-apply-all"`
 	DisableAutoplan             bool   `mapstructure:"disable-autoplan"`
 	DisableAutoplanLabel        string `mapstructure:"disable-autoplan-label"`
@@ -45,16 +72,29 @@ type UserConfig struct {
 	DisableGlobalApplyLock      bool   `mapstructure:"disable-global-apply-lock"`
 	DisableUnlockLabel          string `mapstructure:"disable-unlock-label"`
 	DiscardApprovalOnPlanFlag   bool   `mapstructure:"discard-approval-on-plan"`
+	DiskCriticalThreshold       int    `mapstructure:"disk-critical-threshold"`
+	DiskEvictionThreshold       int    `mapstructure:"disk-eviction-threshold"`
 	EmojiReaction               string `mapstructure:"emoji-reaction"`
+	EmojiReactionFailure        string `mapstructure:"emoji-reaction-failure"`
+	EmojiReactionSuccess        string `mapstructure:"emoji-reaction-success"`
 	EnablePolicyChecksFlag      bool   `mapstructure:"enable-policy-checks"`
 	EnableRegExpCmd             bool   `mapstructure:"enable-regexp-cmd"`
+	EnableRegistryCache         bool   `mapstructure:"enable-registry-cache"`
+	EnableStateForceUnlock      bool   `mapstructure:"enable-state-force-unlock"`
 	EnableProfilingAPI          bool   `mapstructure:"enable-profiling-api"`
+	EnableProgressComments      bool   `mapstructure:"enable-progress-comments"`
 	EnableDiffMarkdownFormat    bool   `mapstructure:"enable-diff-markdown-format"`
+	EnableDriftPullRequests     bool   `mapstructure:"enable-drift-pull-requests"`
+	EnablePlanGraph             bool   `mapstructure:"enable-plan-graph"`
+	EnablePreviewEnvironments   bool   `mapstructure:"enable-preview-environments"`
 	ExecutableName              string `mapstructure:"executable-name"`
+	ExportPlanJSON              bool   `mapstructure:"export-plan-json"`
 	// Fail and do not run the Atlantis command request if any of the pre workflow hooks error.
 	FailOnPreWorkflowHookError      bool   `mapstructure:"fail-on-pre-workflow-hook-error"`
 	HideUnchangedPlanComments       bool   `mapstructure:"hide-unchanged-plan-comments"`
 	GithubAllowMergeableBypassApply bool   `mapstructure:"gh-allow-mergeable-bypass-apply"`
+	GithubCodeScanning              bool   `mapstructure:"gh-code-scanning"`
+	GithubDeployments               bool   `mapstructure:"gh-deployments"`
 	GithubHostname                  string `mapstructure:"gh-hostname"`
 	GithubToken                     string `mapstructure:"gh-token"`
 	GithubTokenFile                 string `mapstructure:"gh-token-file"`
@@ -67,6 +107,11 @@ type UserConfig struct {
 	GithubAppSlug                   string `mapstructure:"gh-app-slug"`
 	GithubAppInstallationID         int64  `mapstructure:"gh-app-installation-id"`
 	GithubTeamAllowlist             string `mapstructure:"gh-team-allowlist"`
+	GerritBaseURL                   string `mapstructure:"gerrit-base-url"`
+	GerritPassword                  string `mapstructure:"gerrit-password"`
+	GerritUser                      string `mapstructure:"gerrit-user"`
+	GerritVerifiedLabel             string `mapstructure:"gerrit-verified-label"`
+	GerritWebhookSecret             string `mapstructure:"gerrit-webhook-secret"`
 	GiteaBaseURL                    string `mapstructure:"gitea-base-url"`
 	GiteaToken                      string `mapstructure:"gitea-token"`
 	GiteaUser                       string `mapstructure:"gitea-user"`
@@ -79,30 +124,60 @@ type UserConfig struct {
 	GitlabWebhookSecret             string `mapstructure:"gitlab-webhook-secret"`
 	GitlabStatusRetryEnabled        bool   `mapstructure:"gitlab-status-retry-enabled"`
 	IncludeGitUntrackedFiles        bool   `mapstructure:"include-git-untracked-files"`
+	APIArtifactTokens               string `mapstructure:"api-artifact-tokens"`
+	APIIPAllowlist                  string `mapstructure:"api-ip-allowlist"`
 	APISecret                       string `mapstructure:"api-secret"`
 	HidePrevPlanComments            bool   `mapstructure:"hide-prev-plan-comments"`
+	KubernetesJobDataVolumeClaim    string `mapstructure:"kubernetes-job-data-volume-claim"`
+	KubernetesJobImage              string `mapstructure:"kubernetes-job-image"`
+	KubernetesJobNamespace          string `mapstructure:"kubernetes-job-namespace"`
+	KubernetesJobNodeSelector       string `mapstructure:"kubernetes-job-node-selector"`
+	KubernetesJobResources          string `mapstructure:"kubernetes-job-resources"`
+	KubernetesJobServiceAccount     string `mapstructure:"kubernetes-job-service-account"`
+	LeaderElection                  bool   `mapstructure:"leader-election"`
 	LockingDBType                   string `mapstructure:"locking-db-type"`
 	LogLevel                        string `mapstructure:"log-level"`
+	LogSinkJobOutput                bool   `mapstructure:"log-sink-job-output"`
+	LogSinks                        string `mapstructure:"log-sinks"`
 	MarkdownTemplateOverridesDir    string `mapstructure:"markdown-template-overrides-dir"`
 	MaxCommentsPerCommand           int    `mapstructure:"max-comments-per-command"`
+	MaxConcurrentCommands           int    `mapstructure:"max-concurrent-commands"`
+	MaxConcurrentProjects           int    `mapstructure:"max-concurrent-projects"`
+	MaxConcurrentProjectsPerRepo    int    `mapstructure:"max-concurrent-projects-per-repo"`
+	MaxPlanAge                      string `mapstructure:"max-plan-age"`
+	MaxPreviewEnvironments          int    `mapstructure:"max-preview-environments"`
+	MentionCodeOwners               bool   `mapstructure:"mention-code-owners"`
 	IgnoreVCSStatusNames            string `mapstructure:"ignore-vcs-status-names"`
 	ParallelPoolSize                int    `mapstructure:"parallel-pool-size"`
 	ParallelPlan                    bool   `mapstructure:"parallel-plan"`
 	ParallelApply                   bool   `mapstructure:"parallel-apply"`
 	PendingApplyStatus              bool   `mapstructure:"pending-apply-status"`
+	PlanfileEncryptionKey           string `mapstructure:"planfile-encryption-key"`
+	PlanfileSigningKey              string `mapstructure:"planfile-signing-key"`
+	PullRateLimit                   int    `mapstructure:"pull-rate-limit"`
 	StatsNamespace                  string `mapstructure:"stats-namespace"`
 	PlanDrafts                      bool   `mapstructure:"allow-draft-prs"`
 	Port                            int    `mapstructure:"port"`
 	QuietPolicyChecks               bool   `mapstructure:"quiet-policy-checks"`
+	RecordResourceChanges           bool   `mapstructure:"record-resource-changes"`
 	RedisDB                         int    `mapstructure:"redis-db"`
 	RedisHost                       string `mapstructure:"redis-host"`
 	RedisPassword                   string `mapstructure:"redis-password"`
 	RedisPort                       int    `mapstructure:"redis-port"`
 	RedisTLSEnabled                 bool   `mapstructure:"redis-tls-enabled"`
+	RegistryCacheHosts              string `mapstructure:"registry-cache-hosts"`
+	RegistryCacheMaxSizeMB          int    `mapstructure:"registry-cache-max-size-mb"`
 	RedisInsecureSkipVerify         bool   `mapstructure:"redis-insecure-skip-verify"`
 	RepoConfig                      string `mapstructure:"repo-config"`
 	RepoConfigJSON                  string `mapstructure:"repo-config-json"`
+	ReplanOnBasePush                bool   `mapstructure:"replan-on-base-push"`
 	RepoAllowlist                   string `mapstructure:"repo-allowlist"`
+	RepoRateLimit                   int    `mapstructure:"repo-rate-limit"`
+	SecretsRefreshInterval          string `mapstructure:"secrets-refresh-interval"`
+	ServerRole                      string `mapstructure:"server-role"`
+	ServiceNowPassword              string `mapstructure:"servicenow-password"`
+	ServiceNowURL                   string `mapstructure:"servicenow-url"`
+	ServiceNowUser                  string `mapstructure:"servicenow-user"`
 
 	// SilenceNoProjects is whether Atlantis should respond to a PR if no projects are found.
 	SilenceNoProjects   bool `mapstructure:"silence-no-projects"`
@@ -117,23 +192,41 @@ type UserConfig struct {
 	SkipCloneNoChanges         bool            `mapstructure:"skip-clone-no-changes"`
 	SlackToken                 string          `mapstructure:"slack-token"`
 	SSLCertFile                string          `mapstructure:"ssl-cert-file"`
+	SSLClientAuth              string          `mapstructure:"ssl-client-auth"`
+	SSLClientCAFile            string          `mapstructure:"ssl-client-ca-file"`
 	SSLKeyFile                 string          `mapstructure:"ssl-key-file"`
+	TenantsConfig              string          `mapstructure:"tenants-config"`
 	RestrictFileList           bool            `mapstructure:"restrict-file-list"`
+	ReuseUnchangedPlans        bool            `mapstructure:"reuse-unchanged-plans"`
 	TFDistribution             string          `mapstructure:"tf-distribution"` // deprecated in favor of DefaultTFDistribution
 	TFDownload                 bool            `mapstructure:"tf-download"`
 	TFDownloadURL              string          `mapstructure:"tf-download-url"`
 	TFEHostname                string          `mapstructure:"tfe-hostname"`
 	TFELocalExecutionMode      bool            `mapstructure:"tfe-local-execution-mode"`
 	TFEToken                   string          `mapstructure:"tfe-token"`
+	Validate                   bool            `mapstructure:"validate"`
 	VarFileAllowlist           string          `mapstructure:"var-file-allowlist"`
 	VCSStatusName              string          `mapstructure:"vcs-status-name"`
+	WarmPools                  string          `mapstructure:"warm-pools"`
 	DefaultTFDistribution      string          `mapstructure:"default-tf-distribution"`
 	DefaultTFVersion           string          `mapstructure:"default-tf-version"`
 	Webhooks                   []WebhookConfig `mapstructure:"webhooks" flag:"false"`
+	Alerts                     []AlertConfig   `mapstructure:"alerts" flag:"false"`
+	WebhookHistory             int             `mapstructure:"webhook-history"`
 	WebhookHttpHeaders         string          `mapstructure:"webhook-http-headers"`
+	WebhookIPAllowlist         string          `mapstructure:"webhook-ip-allowlist"`
 	WebBasicAuth               bool            `mapstructure:"web-basic-auth"`
 	WebUsername                string          `mapstructure:"web-username"`
 	WebPassword                string          `mapstructure:"web-password"`
+	WebOIDCIssuerURL           string          `mapstructure:"web-oidc-issuer-url"`
+	WebOIDCClientID            string          `mapstructure:"web-oidc-client-id"`
+	WebOIDCClientSecret        string          `mapstructure:"web-oidc-client-secret"`
+	WebOIDCScopes              string          `mapstructure:"web-oidc-scopes"`
+	WebOIDCGroupsClaim         string          `mapstructure:"web-oidc-groups-claim"`
+	WebPermissionsConfig       string          `mapstructure:"web-permissions-config"`
+	WorkerConcurrency          int             `mapstructure:"worker-concurrency"`
+	WorkQueueType              string          `mapstructure:"work-queue-type"`
+	WorkQueueURL               string          `mapstructure:"work-queue-url"`
 	WriteGitCreds              bool            `mapstructure:"write-git-creds"`
 	WebsocketCheckOrigin       bool            `mapstructure:"websocket-check-origin"`
 	UseTFPluginCache           bool            `mapstructure:"use-tf-plugin-cache"`
@@ -163,6 +256,163 @@ func (u UserConfig) ToAllowCommandNames() ([]command.Name, error) {
 	return allowCommands, nil
 }
 
+// ToApplyStateDiffResourceTypes parses ApplyStateDiff into the resource type
+// patterns of the state diff.
+func (u UserConfig) ToApplyStateDiffResourceTypes() []string {
+	var patterns []string
+	for pattern := range strings.SplitSeq(u.ApplyStateDiff, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// ToAPIArtifactTokens parses APIArtifactTokens, a comma separated list of
+// tokens optionally scoped to repos with token:repo-pattern.
+func (u UserConfig) ToAPIArtifactTokens() ([]controllers.APIArtifactToken, error) {
+	var tokens []controllers.APIArtifactToken
+	for entry := range strings.SplitSeq(u.APIArtifactTokens, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		token := controllers.APIArtifactToken{Token: entry}
+		// Repo names can't contain colons, tokens may.
+		if i := strings.LastIndex(entry, ":"); i != -1 {
+			token.Token, token.RepoPattern = entry[:i], entry[i+1:]
+			if _, err := path.Match(token.RepoPattern, ""); err != nil {
+				return nil, fmt.Errorf("repo pattern %q: %w", token.RepoPattern, err)
+			}
+		}
+		if token.Token == "" {
+			return nil, fmt.Errorf("%q has no token", entry)
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+// ToAgentPools parses AgentPools, a comma separated list of
+// name:token:repo-pattern.
+func (u UserConfig) ToAgentPools() ([]agents.Pool, error) {
+	var pools []agents.Pool
+	names := map[string]bool{}
+	for entry := range strings.SplitSeq(u.AgentPools, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			// The entry isn't part of the error since it may be a token.
+			return nil, errors.New("agent pools must be name:token:repo-pattern")
+		}
+		pool := agents.Pool{Name: parts[0], Token: parts[1], RepoPattern: parts[2]}
+		if _, err := path.Match(pool.RepoPattern, ""); err != nil {
+			return nil, fmt.Errorf("repo pattern %q: %w", pool.RepoPattern, err)
+		}
+		if names[pool.Name] {
+			return nil, fmt.Errorf("agent pool %q is configured twice", pool.Name)
+		}
+		names[pool.Name] = true
+		pools = append(pools, pool)
+	}
+	return pools, nil
+}
+
+// ToConcurrencyGroups parses ConcurrencyGroups, a comma separated list of
+// name:limit optionally limiting only one command with name:limit:command.
+func (u UserConfig) ToConcurrencyGroups() ([]events.ConcurrencyGroup, error) {
+	var groups []events.ConcurrencyGroup
+	seen := map[events.ConcurrencyGroup]bool{}
+	for entry := range strings.SplitSeq(u.ConcurrencyGroups, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+			return nil, fmt.Errorf("concurrency group %q must be name:limit or name:limit:command", entry)
+		}
+		limit, err := strconv.Atoi(parts[1])
+		if err != nil || limit < 1 {
+			return nil, fmt.Errorf("limit of concurrency group %q must be a positive number", parts[0])
+		}
+		group := events.ConcurrencyGroup{Name: parts[0], Limit: limit}
+		if len(parts) == 3 {
+			group.Command = parts[2]
+			if !slices.Contains([]string{command.Plan.String(), command.Apply.String(), command.Import.String(), command.State.String()}, group.Command) {
+				return nil, fmt.Errorf("command of concurrency group %q must be one of plan, apply, import or state", group.Name)
+			}
+		}
+		key := events.ConcurrencyGroup{Name: group.Name, Command: group.Command}
+		if seen[key] {
+			return nil, fmt.Errorf("concurrency group %q is configured twice", entry)
+		}
+		seen[key] = true
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// ToWarmPools parses WarmPools, a comma separated list of owner/repo:size,
+// into the sizes of the warm pools by repo full name.
+func (u UserConfig) ToWarmPools() (map[string]int, error) {
+	sizes := map[string]int{}
+	for entry := range strings.SplitSeq(u.WarmPools, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		repo, sizeStr, ok := strings.Cut(entry, ":")
+		if !ok || !strings.Contains(repo, "/") {
+			return nil, fmt.Errorf("warm pool %q must be owner/repo:size", entry)
+		}
+		size, err := strconv.Atoi(sizeStr)
+		if err != nil || size < 1 {
+			return nil, fmt.Errorf("size of the warm pool of %q must be a positive number", repo)
+		}
+		if _, ok := sizes[repo]; ok {
+			return nil, fmt.Errorf("warm pool of %q is configured twice", repo)
+		}
+		sizes[repo] = size
+	}
+	return sizes, nil
+}
+
+// SecretFields returns the credentials that can reference secrets stored in
+// secret managers, by flag.
+func (u *UserConfig) SecretFields() map[string]*string {
+	return map[string]*string{
+		"agent-pools":                   &u.AgentPools,
+		"api-secret":                    &u.APISecret,
+		"apply-report-signing-key":      &u.ApplyReportSigningKey,
+		"azuredevops-token":             &u.AzureDevopsToken,
+		"azuredevops-webhook-password":  &u.AzureDevopsWebhookPassword,
+		"bitbucket-access-token":        &u.BitbucketAccessToken,
+		"bitbucket-oauth-client-secret": &u.BitbucketOAuthClientSecret,
+		"bitbucket-token":               &u.BitbucketToken,
+		"bitbucket-webhook-secret":      &u.BitbucketWebhookSecret,
+		"codecommit-git-password":       &u.CodeCommitGitPassword,
+		"codecommit-webhook-secret":     &u.CodeCommitWebhookSecret,
+		"db-encryption-key":             &u.DBEncryptionKey,
+		"gerrit-password":               &u.GerritPassword,
+		"gerrit-webhook-secret":         &u.GerritWebhookSecret,
+		"gh-app-key":                    &u.GithubAppKey,
+		"gh-token":                      &u.GithubToken,
+		"gh-webhook-secret":             &u.GithubWebhookSecret,
+		"gitea-token":                   &u.GiteaToken,
+		"gitea-webhook-secret":          &u.GiteaWebhookSecret,
+		"gitlab-token":                  &u.GitlabToken,
+		"gitlab-webhook-secret":         &u.GitlabWebhookSecret,
+		"planfile-encryption-key":       &u.PlanfileEncryptionKey,
+		"planfile-signing-key":          &u.PlanfileSigningKey,
+		"redis-password":                &u.RedisPassword,
+		"servicenow-password":           &u.ServiceNowPassword,
+		"slack-token":                   &u.SlackToken,
+		"tfe-token":                     &u.TFEToken,
+		"web-oidc-client-secret":        &u.WebOIDCClientSecret,
+		"web-password":                  &u.WebPassword,
+	}
+}
+
 // ToWebhookHttpHeaders parses WebhookHttpHeaders into a map of HTTP headers.
 func (u UserConfig) ToWebhookHttpHeaders() (map[string][]string, error) {
 	if u.WebhookHttpHeaders == "" {
@@ -8,6 +8,9 @@ import (
 	"testing"
 
 	"github.com/runatlantis/atlantis/server"
+	"github.com/runatlantis/atlantis/server/agents"
+	"github.com/runatlantis/atlantis/server/controllers"
+	"github.com/runatlantis/atlantis/server/events"
 	"github.com/runatlantis/atlantis/server/events/command"
 	"github.com/runatlantis/atlantis/server/logging"
 	. "github.com/runatlantis/atlantis/testing"
@@ -73,6 +76,73 @@ func TestUserConfig_ToAllowCommandNames(t *testing.T) {
 	}
 }
 
+func TestUserConfig_ToAPIArtifactTokens(t *testing.T) {
+	u := server.UserConfig{APIArtifactTokens: "ci-token:owner/*, reviewer-token,"}
+	tokens, err := u.ToAPIArtifactTokens()
+	Ok(t, err)
+	Equals(t, []controllers.APIArtifactToken{
+		{Token: "ci-token", RepoPattern: "owner/*"},
+		{Token: "reviewer-token"},
+	}, tokens)
+
+	u = server.UserConfig{APIArtifactTokens: ":owner/*"}
+	_, err = u.ToAPIArtifactTokens()
+	ErrEquals(t, `":owner/*" has no token`, err)
+}
+
+func TestUserConfig_ToAgentPools(t *testing.T) {
+	u := server.UserConfig{AgentPools: "aws-prod:token-1:owner/*, gcp-prod:token-2:*/*,"}
+	pools, err := u.ToAgentPools()
+	Ok(t, err)
+	Equals(t, []agents.Pool{
+		{Name: "aws-prod", Token: "token-1", RepoPattern: "owner/*"},
+		{Name: "gcp-prod", Token: "token-2", RepoPattern: "*/*"},
+	}, pools)
+
+	// The repos pick their pool, so the server binds the pools to repos.
+	u = server.UserConfig{AgentPools: "aws-prod:token"}
+	_, err = u.ToAgentPools()
+	ErrEquals(t, "agent pools must be name:token:repo-pattern", err)
+
+	u = server.UserConfig{AgentPools: "aws-prod:token:owner/["}
+	_, err = u.ToAgentPools()
+	ErrEquals(t, `repo pattern "owner/[": syntax error in pattern`, err)
+}
+
+func TestUserConfig_ToConcurrencyGroups(t *testing.T) {
+	u := server.UserConfig{ConcurrencyGroups: "prod:1:apply, prod:3,staging:2"}
+	groups, err := u.ToConcurrencyGroups()
+	Ok(t, err)
+	Equals(t, []events.ConcurrencyGroup{
+		{Name: "prod", Limit: 1, Command: "apply"},
+		{Name: "prod", Limit: 3},
+		{Name: "staging", Limit: 2},
+	}, groups)
+
+	u = server.UserConfig{ConcurrencyGroups: "prod:1:unlock"}
+	_, err = u.ToConcurrencyGroups()
+	ErrEquals(t, `command of concurrency group "prod" must be one of plan, apply, import or state`, err)
+
+	u = server.UserConfig{ConcurrencyGroups: "prod:1,prod:2"}
+	_, err = u.ToConcurrencyGroups()
+	ErrEquals(t, `concurrency group "prod:2" is configured twice`, err)
+}
+
+func TestUserConfig_ToWarmPools(t *testing.T) {
+	u := server.UserConfig{WarmPools: "owner/repo:2, group/sub/repo:1"}
+	sizes, err := u.ToWarmPools()
+	Ok(t, err)
+	Equals(t, map[string]int{"owner/repo": 2, "group/sub/repo": 1}, sizes)
+
+	u = server.UserConfig{WarmPools: "repo:2"}
+	_, err = u.ToWarmPools()
+	ErrEquals(t, `warm pool "repo:2" must be owner/repo:size`, err)
+
+	u = server.UserConfig{WarmPools: "owner/repo:0"}
+	_, err = u.ToWarmPools()
+	ErrEquals(t, `size of the warm pool of "owner/repo" must be a positive number`, err)
+}
+
 func TestUserConfig_ToWebhookHttpHeaders(t *testing.T) {
 	tcs := []struct {
 		name  string
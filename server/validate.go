@@ -0,0 +1,157 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/core/db"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketcloud"
+)
+
+// probeTimeout is the timeout of each connectivity probe.
+const probeTimeout = 10 * time.Second
+
+// validationCheck is a check of atlantis server --validate.
+type validationCheck struct {
+	name string
+	// run returns details about what was checked or an error if the check
+	// failed.
+	run func() (string, error)
+}
+
+// Validate runs the checks of atlantis server --validate, writes a report to
+// out and returns an error if any check failed. Parsing the flags and config
+// files already succeeded since the server was created, the checks report
+// what was loaded and probe connectivity to the services Atlantis depends on.
+func (s *Server) Validate(out io.Writer) error {
+	defer func() {
+		if err := s.closeDatabase(1 * time.Second); err != nil {
+			s.Logger.Err("while closing database: %v", err)
+		}
+	}()
+
+	failed := 0
+	for _, check := range s.validationChecks {
+		details, err := check.run()
+		if err != nil {
+			failed++
+			fmt.Fprintf(out, "FAIL  %s: %s\n", check.name, err) // nolint: errcheck
+			continue
+		}
+		fmt.Fprintf(out, "ok    %s: %s\n", check.name, details) // nolint: errcheck
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(s.validationChecks))
+	}
+	fmt.Fprintf(out, "all %d checks passed\n", len(s.validationChecks)) // nolint: errcheck
+	return nil
+}
+
+// newValidationChecks returns the checks of atlantis server --validate for
+// the loaded config.
+func newValidationChecks(userConfig UserConfig, globalCfg valid.GlobalCfg, webhooks int, database db.Database) []validationCheck {
+	client := &http.Client{Timeout: probeTimeout}
+	checks := []validationCheck{
+		{"flags", func() (string, error) { return "parsed", nil }},
+		{"server-side repo config", func() (string, error) {
+			source := "defaults"
+			if userConfig.RepoConfig != "" {
+				source = userConfig.RepoConfig
+			} else if userConfig.RepoConfigJSON != "" {
+				source = "--repo-config-json"
+			}
+			return fmt.Sprintf("%s, %d repos, %d workflows, %d policy sets", source, len(globalCfg.Repos), len(globalCfg.Workflows), len(globalCfg.PolicySets.PolicySets)), nil
+		}},
+		{"webhooks", func() (string, error) { return fmt.Sprintf("%d configured", webhooks), nil }},
+		{"summarizer", events.ValidateSummarizerConfig},
+	}
+
+	for _, host := range vcsProbeURLs(userConfig) {
+		checks = append(checks, validationCheck{"vcs " + host.name, func() (string, error) {
+			return probeURL(client, host.url)
+		}})
+	}
+
+	checks = append(checks, validationCheck{"locking db " + userConfig.LockingDBType, func() (string, error) {
+		if database == nil {
+			return "", errors.New("no locking db configured")
+		}
+		locks, err := database.List()
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("reachable, %d locks", len(locks)), nil
+	}})
+
+	checks = append(checks, validationCheck{"summarizer provider", func() (string, error) {
+		if err := events.ProbeSummarizer(client); err != nil {
+			return "", err
+		}
+		return "reachable", nil
+	}})
+	return checks
+}
+
+type vcsProbeURL struct {
+	name string
+	url  string
+}
+
+// vcsProbeURLs returns the API URLs of the configured VCS hosts.
+func vcsProbeURLs(userConfig UserConfig) []vcsProbeURL {
+	var urls []vcsProbeURL
+	if userConfig.GithubUser != "" || userConfig.GithubAppID != 0 {
+		url := "https://api.github.com"
+		if userConfig.GithubHostname != "github.com" {
+			url = withScheme(userConfig.GithubHostname) + "/api/v3"
+		}
+		urls = append(urls, vcsProbeURL{"github", url})
+	}
+	if userConfig.GitlabUser != "" {
+		urls = append(urls, vcsProbeURL{"gitlab", withScheme(userConfig.GitlabHostname) + "/api/v4/version"})
+	}
+	if userConfig.BitbucketUser != "" {
+		name := "bitbucket server"
+		if userConfig.BitbucketBaseURL == bitbucketcloud.BaseURL {
+			name = "bitbucket cloud"
+		}
+		urls = append(urls, vcsProbeURL{name, userConfig.BitbucketBaseURL})
+	}
+	if userConfig.AzureDevopsUser != "" {
+		urls = append(urls, vcsProbeURL{"azure devops", withScheme(userConfig.AzureDevOpsHostname)})
+	}
+	if userConfig.GiteaToken != "" {
+		urls = append(urls, vcsProbeURL{"gitea", strings.TrimSuffix(userConfig.GiteaBaseURL, "/") + "/api/v1/version"})
+	}
+	if userConfig.GerritUser != "" {
+		urls = append(urls, vcsProbeURL{"gerrit", strings.TrimSuffix(userConfig.GerritBaseURL, "/") + "/config/server/version"})
+	}
+	return urls
+}
+
+func withScheme(hostname string) string {
+	if strings.Contains(hostname, "://") {
+		return strings.TrimSuffix(hostname, "/")
+	}
+	return "https://" + strings.TrimSuffix(hostname, "/")
+}
+
+// probeURL checks url is reachable. Any HTTP response counts since the probe
+// is unauthenticated, it catches DNS, network and TLS issues.
+func probeURL(client *http.Client, url string) (string, error) {
+	resp, err := client.Get(url) // nolint: gosec
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close() // nolint: errcheck
+	return fmt.Sprintf("%s reachable, HTTP %d", url, resp.StatusCode), nil
+}
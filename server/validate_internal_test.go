@@ -0,0 +1,75 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/petergtz/pegomock/v4"
+	"github.com/runatlantis/atlantis/server/core/config/valid"
+	"github.com/runatlantis/atlantis/server/core/db/mocks"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestServer_Validate(t *testing.T) {
+	t.Setenv("OPENROUTER_API_KEY", "")
+	t.Setenv("TERRAFORM_PLAN_SUMMARIZER_COMMAND", "")
+	gitea := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Equals(t, "/api/v1/version", r.URL.Path)
+		w.Write([]byte(`{"version": "1.21.0"}`)) // nolint: errcheck
+	}))
+	defer gitea.Close()
+
+	userConfig := UserConfig{
+		GiteaToken:    "token",
+		GiteaBaseURL:  gitea.URL,
+		LockingDBType: "redis",
+		RepoConfig:    "repos.yaml",
+	}
+	globalCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})
+
+	t.Run("passing", func(t *testing.T) {
+		database := mocks.NewMockDatabase(WithT(t))
+		When(database.List()).ThenReturn([]models.ProjectLock{{}}, nil)
+		s := &Server{
+			Logger:           logging.NewNoopLogger(t),
+			database:         database,
+			validationChecks: newValidationChecks(userConfig, globalCfg, 2, database),
+		}
+		var out bytes.Buffer
+		Ok(t, s.Validate(&out))
+		Equals(t, strings.Join([]string{
+			"ok    flags: parsed",
+			"ok    server-side repo config: repos.yaml, 1 repos, 1 workflows, 0 policy sets",
+			"ok    webhooks: 2 configured",
+			"ok    summarizer: disabled, OPENROUTER_API_KEY isn't set",
+			"ok    vcs gitea: " + gitea.URL + "/api/v1/version reachable, HTTP 200",
+			"ok    locking db redis: reachable, 1 locks",
+			"ok    summarizer provider: reachable",
+			"all 7 checks passed",
+			"",
+		}, "\n"), out.String())
+		database.VerifyWasCalledOnce().Close()
+	})
+
+	t.Run("failing", func(t *testing.T) {
+		database := mocks.NewMockDatabase(WithT(t))
+		When(database.List()).ThenReturn(nil, errors.New("connection refused"))
+		s := &Server{
+			Logger:           logging.NewNoopLogger(t),
+			database:         database,
+			validationChecks: newValidationChecks(userConfig, globalCfg, 0, database),
+		}
+		var out bytes.Buffer
+		ErrEquals(t, "1 of 7 checks failed", s.Validate(&out))
+		Assert(t, strings.Contains(out.String(), "FAIL  locking db redis: connection refused\n"), "exp failure in report, got %s", out.String())
+	})
+}
@@ -0,0 +1,16 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package webauth
+
+import "net/http"
+
+var crossOriginProtection = http.NewCrossOriginProtection()
+
+// RequireSameOrigin wraps handler to respond with 403 Forbidden to
+// cross-origin requests, ex. a form of another site submitted by the browser
+// of a logged in user. Requests that don't come from browsers, without the
+// Sec-Fetch-Site and Origin headers, are allowed.
+func RequireSameOrigin(handler http.HandlerFunc) http.HandlerFunc {
+	return crossOriginProtection.Handler(handler).ServeHTTP
+}
@@ -0,0 +1,38 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package webauth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/webauth"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestRequireSameOrigin(t *testing.T) {
+	handler := webauth.RequireSameOrigin(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	for _, c := range []struct {
+		headers map[string]string
+		exp     int
+	}{
+		// ex. curl.
+		{nil, http.StatusNoContent},
+		{map[string]string{"Sec-Fetch-Site": "same-origin"}, http.StatusNoContent},
+		{map[string]string{"Origin": "https://atlantis.example.com"}, http.StatusNoContent},
+		{map[string]string{"Sec-Fetch-Site": "cross-site"}, http.StatusForbidden},
+		{map[string]string{"Origin": "https://evil.example.com"}, http.StatusForbidden},
+	} {
+		r := httptest.NewRequest("POST", "https://atlantis.example.com/jobs/cancel?repo=owner/repo&pull=1", nil)
+		for name, value := range c.headers {
+			r.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+		Equals(t, c.exp, w.Code)
+	}
+}
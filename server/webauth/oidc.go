@@ -0,0 +1,319 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package webauth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/logging"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// LoginPath, CallbackPath and LogoutPath are the routes of the OIDC login
+	// flow, relative to the Atlantis URL.
+	LoginPath    = "/auth/login"
+	CallbackPath = "/auth/callback"
+	LogoutPath   = "/auth/logout"
+
+	sessionCookie   = "atlantis_session"
+	stateCookie     = "atlantis_oidc_state"
+	sessionDuration = 12 * time.Hour
+	stateDuration   = 10 * time.Minute
+)
+
+// OIDCConfig configures logging in to the web UI with an OIDC provider.
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// GroupsClaim is the ID token claim listing the user's groups.
+	GroupsClaim string
+	// Permissions map the groups to capabilities. Users are allowed
+	// everything if it's nil.
+	Permissions *Permissions
+	// Tenants, if set, restricts the users to the repos of the tenants of
+	// their groups, unless Permissions grants them admin in all repos.
+	Tenants *events.Tenants
+	// AtlantisURL is the URL the provider redirects back to.
+	AtlantisURL *url.URL
+}
+
+// OIDC logs web UI users in with an OIDC provider and keeps them logged in
+// with a signed session cookie.
+type OIDC struct {
+	oauth2      oauth2.Config
+	verifier    *oidc.IDTokenVerifier
+	groupsClaim string
+	permissions *Permissions
+	tenants     *events.Tenants
+	// sessionKey signs the session and state cookies. It's derived from the
+	// client secret so every replica accepts the sessions of the others.
+	sessionKey []byte
+	basePath   string
+	secure     bool
+	logger     logging.SimpleLogging
+}
+
+// session is the content of the session cookie.
+type session struct {
+	Name    string   `json:"name"`
+	Groups  []string `json:"groups"`
+	Expires int64    `json:"exp"`
+}
+
+// loginState is the content of the state cookie, set while the user logs in
+// with the provider.
+type loginState struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	ReturnTo string `json:"return_to"`
+	Expires  int64  `json:"exp"`
+}
+
+// NewOIDC discovers the provider at cfg.IssuerURL.
+func NewOIDC(ctx context.Context, cfg OIDCConfig, logger logging.SimpleLogging) (*OIDC, error) {
+	provider, err := oidc.NewProvider(ctx, cfg.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("discovering the OIDC provider %s: %w", cfg.IssuerURL, err)
+	}
+	redirectURL := *cfg.AtlantisURL
+	redirectURL.Path = strings.TrimSuffix(redirectURL.Path, "/") + CallbackPath
+	mac := hmac.New(sha256.New, []byte(cfg.ClientSecret))
+	mac.Write([]byte("atlantis web session")) // nolint: errcheck
+	return &OIDC{
+		oauth2: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			Endpoint:     provider.Endpoint(),
+			RedirectURL:  redirectURL.String(),
+			Scopes:       cfg.Scopes,
+		},
+		verifier:    provider.Verifier(&oidc.Config{ClientID: cfg.ClientID}),
+		groupsClaim: cfg.GroupsClaim,
+		permissions: cfg.Permissions,
+		tenants:     cfg.Tenants,
+		sessionKey:  mac.Sum(nil),
+		basePath:    strings.TrimSuffix(cfg.AtlantisURL.Path, "/"),
+		secure:      cfg.AtlantisURL.Scheme == "https",
+		logger:      logger,
+	}, nil
+}
+
+// Authenticate returns the user of r's session cookie, false if there's no
+// valid session.
+func (o *OIDC) Authenticate(r *http.Request) (*User, bool) {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return nil, false
+	}
+	var s session
+	if err := o.decode(cookie.Value, &s); err != nil || time.Now().Unix() > s.Expires {
+		return nil, false
+	}
+	return o.newUser(s.Name, s.Groups), true
+}
+
+// newUser returns the user with groups, restricted to the repos of the
+// tenants of their groups if tenants are configured and they're not admins.
+func (o *OIDC) newUser(name string, groups []string) *User {
+	user := &User{Name: name, Groups: groups, Permissions: o.permissions}
+	if o.tenants != nil && (o.permissions == nil || !o.permissions.Allows(groups, Admin, "", "")) {
+		user.Tenants = o.tenants
+	}
+	return user
+}
+
+// RedirectToLogin redirects the user to log in, then back to the page they
+// requested.
+func (o *OIDC) RedirectToLogin(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, o.basePath+LoginPath+"?return_to="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+}
+
+// Login is the GET /auth/login route. It redirects to the provider.
+func (o *OIDC) Login(w http.ResponseWriter, r *http.Request) {
+	state := loginState{
+		State:    randomString(),
+		Nonce:    randomString(),
+		ReturnTo: r.URL.Query().Get("return_to"),
+		Expires:  time.Now().Add(stateDuration).Unix(),
+	}
+	// Only redirect back to pages of Atlantis.
+	if !strings.HasPrefix(state.ReturnTo, "/") || strings.HasPrefix(state.ReturnTo, "//") || strings.HasPrefix(state.ReturnTo, "/\\") {
+		state.ReturnTo = o.basePath + "/"
+	}
+	value, err := o.encode(state)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	o.setCookie(w, stateCookie, value, stateDuration)
+	http.Redirect(w, r, o.oauth2.AuthCodeURL(state.State, oidc.Nonce(state.Nonce)), http.StatusFound)
+}
+
+// Callback is the GET /auth/callback route the provider redirects to after
+// the user logged in. It starts the user's session.
+func (o *OIDC) Callback(w http.ResponseWriter, r *http.Request) {
+	user, returnTo, err := o.callback(r)
+	if err != nil {
+		o.logger.Warn("OIDC login from %s failed: %s", r.RemoteAddr, err)
+		http.Error(w, "Login failed", http.StatusUnauthorized)
+		return
+	}
+	value, err := o.encode(session{Name: user.Name, Groups: user.Groups, Expires: time.Now().Add(sessionDuration).Unix()})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	o.logger.Info("%s logged in to the web UI with groups %v from %s", user.Name, user.Groups, r.RemoteAddr)
+	o.setCookie(w, stateCookie, "", -1)
+	o.setCookie(w, sessionCookie, value, sessionDuration)
+	http.Redirect(w, r, returnTo, http.StatusFound)
+}
+
+func (o *OIDC) callback(r *http.Request) (*User, string, error) {
+	cookie, err := r.Cookie(stateCookie)
+	if err != nil {
+		return nil, "", errors.New("no login in progress")
+	}
+	var state loginState
+	if err := o.decode(cookie.Value, &state); err != nil {
+		return nil, "", err
+	}
+	if time.Now().Unix() > state.Expires {
+		return nil, "", errors.New("the login expired")
+	}
+	query := r.URL.Query()
+	if errCode := query.Get("error"); errCode != "" {
+		return nil, "", fmt.Errorf("the provider returned %s: %s", errCode, query.Get("error_description"))
+	}
+	if !hmac.Equal([]byte(query.Get("state")), []byte(state.State)) {
+		return nil, "", errors.New("the state doesn't match the login's")
+	}
+	token, err := o.oauth2.Exchange(r.Context(), query.Get("code"))
+	if err != nil {
+		return nil, "", fmt.Errorf("exchanging the code: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, "", errors.New("the provider didn't return an ID token")
+	}
+	idToken, err := o.verifier.Verify(r.Context(), rawIDToken)
+	if err != nil {
+		return nil, "", fmt.Errorf("verifying the ID token: %w", err)
+	}
+	if !hmac.Equal([]byte(idToken.Nonce), []byte(state.Nonce)) {
+		return nil, "", errors.New("the ID token's nonce doesn't match the login's")
+	}
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, "", err
+	}
+	name := idToken.Subject
+	for _, claim := range []string{"preferred_username", "email"} {
+		if n, ok := claims[claim].(string); ok && n != "" {
+			name = n
+			break
+		}
+	}
+	return o.newUser(name, o.groups(claims[o.groupsClaim])), state.ReturnTo, nil
+}
+
+// groups returns the groups in the groups claim. Only the groups that are
+// granted capabilities or are web_groups of tenants are kept so the session
+// cookie stays small.
+func (o *OIDC) groups(claim any) []string {
+	var groups []string
+	switch v := claim.(type) {
+	case string:
+		groups = []string{v}
+	case []any:
+		for _, g := range v {
+			if s, ok := g.(string); ok {
+				groups = append(groups, s)
+			}
+		}
+	}
+	if o.permissions == nil {
+		return groups
+	}
+	granted := o.permissions.Groups()
+	if o.tenants != nil {
+		granted = append(granted, o.tenants.WebGroups()...)
+	}
+	return slices.DeleteFunc(groups, func(g string) bool { return !slices.Contains(granted, g) })
+}
+
+// Logout is the GET /auth/logout route. It ends the user's session.
+func (o *OIDC) Logout(w http.ResponseWriter, r *http.Request) {
+	o.setCookie(w, sessionCookie, "", -1)
+	fmt.Fprintln(w, "Logged out") // nolint: errcheck
+}
+
+func (o *OIDC) setCookie(w http.ResponseWriter, name string, value string, maxAge time.Duration) {
+	path := o.basePath
+	if path == "" {
+		path = "/"
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		MaxAge:   int(maxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   o.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// encode returns v as JSON signed with the session key.
+func (o *OIDC) encode(v any) (string, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + o.sign(encoded), nil
+}
+
+// decode verifies the signature of value and decodes it into v.
+func (o *OIDC) decode(value string, v any) error {
+	encoded, signature, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(o.sign(encoded))) {
+		return errors.New("invalid signature")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(payload, v)
+}
+
+func (o *OIDC) sign(encoded string) string {
+	mac := hmac.New(sha256.New, o.sessionKey)
+	mac.Write([]byte(encoded)) // nolint: errcheck
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	rand.Read(b) // nolint: errcheck
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,239 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package webauth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/webauth"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// fakeProvider is an OIDC provider issuing ID tokens for alice.
+type fakeProvider struct {
+	*httptest.Server
+	key   *rsa.PrivateKey
+	nonce string
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Ok(t, err)
+	p := &fakeProvider{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{ // nolint: errcheck
+			"issuer":                                p.URL,
+			"authorization_endpoint":                p.URL + "/authorize",
+			"token_endpoint":                        p.URL + "/token",
+			"jwks_uri":                              p.URL + "/keys",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{ // nolint: errcheck
+			{Key: &key.PublicKey, KeyID: "key", Algorithm: "RS256", Use: "sig"},
+		}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		Ok(t, r.ParseForm())
+		Equals(t, "code", r.Form.Get("code"))
+		signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: jose.JSONWebKey{Key: key, KeyID: "key"}}, nil)
+		Ok(t, err)
+		idToken, err := jwt.Signed(signer).Claims(map[string]any{
+			"iss":                p.URL,
+			"sub":                "123",
+			"aud":                "atlantis",
+			"exp":                time.Now().Add(time.Hour).Unix(),
+			"iat":                time.Now().Unix(),
+			"nonce":              p.nonce,
+			"preferred_username": "alice",
+			"groups":             []string{"infra", "unrelated"},
+		}).Serialize()
+		Ok(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{ // nolint: errcheck
+			"access_token": "token",
+			"token_type":   "Bearer",
+			"id_token":     idToken,
+		})
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+// login logs in with the provider and returns the user of the session.
+func login(t *testing.T, provider *fakeProvider, o *webauth.OIDC) *webauth.User {
+	t.Helper()
+	// Logging in redirects to the provider.
+	w := httptest.NewRecorder()
+	o.Login(w, httptest.NewRequest("GET", "/auth/login?return_to="+url.QueryEscape("/atlantis/projects"), nil))
+	Equals(t, http.StatusFound, w.Code)
+	authorize, err := url.Parse(w.Header().Get("Location"))
+	Ok(t, err)
+	Equals(t, provider.URL+"/authorize", authorize.Scheme+"://"+authorize.Host+authorize.Path)
+	Equals(t, "https://atlantis.example.com/atlantis/auth/callback", authorize.Query().Get("redirect_uri"))
+	provider.nonce = authorize.Query().Get("nonce")
+	stateCookies := w.Result().Cookies()
+
+	// A callback with another state is rejected.
+	r := httptest.NewRequest("GET", "/auth/callback?code=code&state=other", nil)
+	for _, c := range stateCookies {
+		r.AddCookie(c)
+	}
+	w = httptest.NewRecorder()
+	o.Callback(w, r)
+	Equals(t, http.StatusUnauthorized, w.Code)
+
+	// The callback starts the session and redirects back.
+	r = httptest.NewRequest("GET", "/auth/callback?code=code&state="+authorize.Query().Get("state"), nil)
+	for _, c := range stateCookies {
+		r.AddCookie(c)
+	}
+	w = httptest.NewRecorder()
+	o.Callback(w, r)
+	Equals(t, http.StatusFound, w.Code)
+	Equals(t, "/atlantis/projects", w.Header().Get("Location"))
+
+	r = httptest.NewRequest("GET", "/projects", nil)
+	for _, c := range w.Result().Cookies() {
+		if c.Value != "" {
+			Equals(t, "/atlantis", c.Path)
+			Assert(t, c.HttpOnly && c.Secure, "exp a secure, HTTP only cookie")
+			r.AddCookie(c)
+		}
+	}
+	user, ok := o.Authenticate(r)
+	Assert(t, ok, "exp the session to authenticate")
+	return user
+}
+
+func TestOIDC_Login(t *testing.T) {
+	provider := newFakeProvider(t)
+	permissions, err := webauth.LoadPermissions(writePermissions(t, "groups:\n- name: infra\n  capabilities: [view_jobs]"))
+	Ok(t, err)
+	atlantisURL, err := url.Parse("https://atlantis.example.com/atlantis")
+	Ok(t, err)
+	o, err := webauth.NewOIDC(context.Background(), webauth.OIDCConfig{
+		IssuerURL:    provider.URL,
+		ClientID:     "atlantis",
+		ClientSecret: "secret",
+		Scopes:       []string{"openid", "groups"},
+		GroupsClaim:  "groups",
+		Permissions:  permissions,
+		AtlantisURL:  atlantisURL,
+	}, logging.NewNoopLogger(t))
+	Ok(t, err)
+
+	user := login(t, provider, o)
+	Equals(t, "alice", user.Name)
+	// Only the groups that are granted capabilities are kept.
+	Equals(t, []string{"infra"}, user.Groups)
+	Assert(t, user.Can(webauth.ViewJobs, "acme/app", "github.com"), "exp alice to view jobs")
+	Assert(t, !user.Can(webauth.ReleaseLocks, "acme/app", "github.com"), "exp alice not to release locks")
+
+	// Tampered sessions don't authenticate.
+	r := httptest.NewRequest("GET", "/projects", nil)
+	r.AddCookie(&http.Cookie{Name: "atlantis_session", Value: "eyJuYW1lIjoiYm9iIn0.c2lnbmF0dXJl"})
+	_, ok := o.Authenticate(r)
+	Assert(t, !ok, "exp a tampered session not to authenticate")
+}
+
+func TestOIDC_LoginTenants(t *testing.T) {
+	tenantsPath := filepath.Join(t.TempDir(), "tenants.yaml")
+	Ok(t, os.WriteFile(tenantsPath, []byte(`
+tenants:
+- name: acme
+  repo_allowlist: github.com/acme/*
+  web_groups: [infra]
+- name: initech
+  repo_allowlist: github.com/initech/*
+  web_groups: [initech]
+`), 0600))
+	tenants, err := events.LoadTenants(tenantsPath)
+	Ok(t, err)
+	atlantisURL, err := url.Parse("https://atlantis.example.com/atlantis")
+	Ok(t, err)
+
+	for _, c := range []struct {
+		description string
+		permissions string
+		expGroups   []string
+		expScoped   bool
+	}{
+		{"no permissions", "", []string{"infra", "unrelated"}, true},
+		{"not an admin", "groups:\n- name: infra\n  capabilities: [view_jobs]", []string{"infra"}, true},
+		{"admin", "groups:\n- name: infra\n  capabilities: [admin]", []string{"infra"}, false},
+	} {
+		t.Run(c.description, func(t *testing.T) {
+			var permissions *webauth.Permissions
+			if c.permissions != "" {
+				permissions, err = webauth.LoadPermissions(writePermissions(t, c.permissions))
+				Ok(t, err)
+			}
+			provider := newFakeProvider(t)
+			o, err := webauth.NewOIDC(context.Background(), webauth.OIDCConfig{
+				IssuerURL:    provider.URL,
+				ClientID:     "atlantis",
+				ClientSecret: "secret",
+				Scopes:       []string{"openid", "groups"},
+				GroupsClaim:  "groups",
+				Permissions:  permissions,
+				Tenants:      tenants,
+				AtlantisURL:  atlantisURL,
+			}, logging.NewNoopLogger(t))
+			Ok(t, err)
+
+			user := login(t, provider, o)
+			Equals(t, c.expGroups, user.Groups)
+			Assert(t, user.Can(webauth.ViewJobs, "acme/app", "github.com"), "exp alice to view the jobs of her tenant")
+			Equals(t, !c.expScoped, user.Can(webauth.ViewJobs, "initech/app", "github.com"))
+			Equals(t, !c.expScoped, user.Can(webauth.ViewJobs, "", ""))
+		})
+	}
+}
+
+func TestOIDC_LoginOnlyRedirectsToAtlantis(t *testing.T) {
+	provider := newFakeProvider(t)
+	atlantisURL, err := url.Parse("http://localhost:4141")
+	Ok(t, err)
+	o, err := webauth.NewOIDC(context.Background(), webauth.OIDCConfig{
+		IssuerURL:    provider.URL,
+		ClientID:     "atlantis",
+		ClientSecret: "secret",
+		AtlantisURL:  atlantisURL,
+	}, logging.NewNoopLogger(t))
+	Ok(t, err)
+
+	for _, returnTo := range []string{"https://evil.example.com", "//evil.example.com", "/\\evil.example.com"} {
+		w := httptest.NewRecorder()
+		o.Login(w, httptest.NewRequest("GET", "/auth/login?return_to="+url.QueryEscape(returnTo), nil))
+		authorize, err := url.Parse(w.Header().Get("Location"))
+		Ok(t, err)
+		provider.nonce = authorize.Query().Get("nonce")
+
+		r := httptest.NewRequest("GET", "/auth/callback?code=code&state="+authorize.Query().Get("state"), nil)
+		for _, c := range w.Result().Cookies() {
+			r.AddCookie(c)
+		}
+		w = httptest.NewRecorder()
+		o.Callback(w, r)
+		Equals(t, "/", w.Header().Get("Location"))
+	}
+}
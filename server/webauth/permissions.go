@@ -0,0 +1,134 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package webauth authenticates web UI users and authorizes what they can do
+// in the UI.
+package webauth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"gopkg.in/yaml.v3"
+)
+
+// Capability is something web UI users can be allowed to do.
+type Capability string
+
+const (
+	// ViewJobs allows viewing the locks, jobs, plans, projects and pull
+	// requests of repos.
+	ViewJobs Capability = "view_jobs"
+	// ReleaseLocks allows deleting the Atlantis locks of repos and
+	// force-unlocking their Terraform state locks.
+	ReleaseLocks Capability = "release_locks"
+	// TriggerApplies allows running and cancelling the commands of repos.
+	TriggerApplies Capability = "trigger_applies"
+	// Admin allows everything in every repo, including the global apply lock,
+	// API tokens and the webhook history.
+	Admin Capability = "admin"
+)
+
+var capabilities = []Capability{ViewJobs, ReleaseLocks, TriggerApplies, Admin}
+
+// Permissions map the groups of web UI users to the capabilities they're
+// allowed in the repos the groups are scoped to.
+type Permissions struct {
+	grants []grant
+}
+
+type grant struct {
+	group        string
+	capabilities []Capability
+	// repos is nil if the grant applies to all repos.
+	repos *events.RepoAllowlistChecker
+}
+
+// permissionsConfig is the schema of the permissions config file, ex.
+//
+//	groups:
+//	- name: platform
+//	  capabilities: [admin]
+//	- name: infra
+//	  capabilities: [view_jobs, release_locks, trigger_applies]
+//	  repos: github.com/acme/infra-*
+type permissionsConfig struct {
+	Groups []struct {
+		Name         string       `yaml:"name"`
+		Capabilities []Capability `yaml:"capabilities"`
+		Repos        string       `yaml:"repos"`
+	} `yaml:"groups"`
+}
+
+// LoadPermissions parses the permissions config file at path.
+func LoadPermissions(path string) (*Permissions, error) {
+	contents, err := os.ReadFile(path) // nolint: gosec
+	if err != nil {
+		return nil, err
+	}
+	var cfg permissionsConfig
+	if err := yaml.Unmarshal(contents, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if len(cfg.Groups) == 0 {
+		return nil, fmt.Errorf("%s doesn't grant any groups capabilities", path)
+	}
+	permissions := &Permissions{}
+	for _, g := range cfg.Groups {
+		if g.Name == "" {
+			return nil, errors.New("groups must have a name")
+		}
+		if len(g.Capabilities) == 0 {
+			return nil, fmt.Errorf("group %q must have capabilities", g.Name)
+		}
+		for _, c := range g.Capabilities {
+			if !slices.Contains(capabilities, c) {
+				return nil, fmt.Errorf("group %q has unknown capability %q, must be one of %v", g.Name, c, capabilities)
+			}
+		}
+		grant := grant{group: g.Name, capabilities: g.Capabilities}
+		if g.Repos != "" {
+			if slices.Contains(g.Capabilities, Admin) {
+				return nil, fmt.Errorf("group %q can't be admin of only some repos", g.Name)
+			}
+			grant.repos, err = events.NewRepoAllowlistChecker(g.Repos)
+			if err != nil {
+				return nil, fmt.Errorf("group %q: %w", g.Name, err)
+			}
+		}
+		permissions.grants = append(permissions.grants, grant)
+	}
+	return permissions, nil
+}
+
+// Groups returns the groups that are granted capabilities.
+func (p *Permissions) Groups() []string {
+	var groups []string
+	for _, grant := range p.grants {
+		if !slices.Contains(groups, grant.group) {
+			groups = append(groups, grant.group)
+		}
+	}
+	return groups
+}
+
+// Allows returns true if a member of groups is allowed capability in the
+// repo. An empty repoFullName only matches grants for all repos, ex. for
+// admin pages.
+func (p *Permissions) Allows(groups []string, capability Capability, repoFullName string, vcsHostname string) bool {
+	for _, grant := range p.grants {
+		if !slices.Contains(groups, grant.group) {
+			continue
+		}
+		if !slices.Contains(grant.capabilities, capability) && !slices.Contains(grant.capabilities, Admin) {
+			continue
+		}
+		if grant.repos == nil || (repoFullName != "" && grant.repos.IsAllowlisted(repoFullName, vcsHostname)) {
+			return true
+		}
+	}
+	return false
+}
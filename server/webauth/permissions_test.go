@@ -0,0 +1,101 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package webauth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/webauth"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func writePermissions(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "permissions.yaml")
+	Ok(t, os.WriteFile(path, []byte(contents), 0600))
+	return path
+}
+
+func TestLoadPermissions(t *testing.T) {
+	permissions, err := webauth.LoadPermissions(writePermissions(t, `
+groups:
+- name: platform
+  capabilities: [admin]
+- name: infra
+  capabilities: [view_jobs, release_locks]
+  repos: github.com/acme/infra-*,!github.com/acme/infra-secrets
+- name: viewers
+  capabilities: [view_jobs]
+`))
+	Ok(t, err)
+	Equals(t, []string{"platform", "infra", "viewers"}, permissions.Groups())
+
+	cases := []struct {
+		groups     []string
+		capability webauth.Capability
+		repo       string
+		exp        bool
+	}{
+		{[]string{"platform"}, webauth.Admin, "", true},
+		{[]string{"platform"}, webauth.TriggerApplies, "acme/app", true},
+		{[]string{"infra"}, webauth.ReleaseLocks, "acme/infra-network", true},
+		{[]string{"infra"}, webauth.ReleaseLocks, "acme/infra-secrets", false},
+		{[]string{"infra"}, webauth.ReleaseLocks, "acme/app", false},
+		{[]string{"infra"}, webauth.TriggerApplies, "acme/infra-network", false},
+		// Grants scoped to repos don't allow admin pages.
+		{[]string{"infra"}, webauth.ViewJobs, "", false},
+		{[]string{"viewers"}, webauth.ViewJobs, "", true},
+		{[]string{"viewers", "infra"}, webauth.ReleaseLocks, "acme/infra-network", true},
+		{[]string{"other"}, webauth.ViewJobs, "acme/app", false},
+		{nil, webauth.ViewJobs, "acme/app", false},
+	}
+	for _, c := range cases {
+		Equals(t, c.exp, permissions.Allows(c.groups, c.capability, c.repo, "github.com"))
+	}
+}
+
+func TestLoadPermissions_Errors(t *testing.T) {
+	cases := map[string]string{
+		"groups: []":                                   "doesn't grant any groups capabilities",
+		"groups:\n- capabilities: [admin]":             "groups must have a name",
+		"groups:\n- name: a":                           `group "a" must have capabilities`,
+		"groups:\n- name: a\n  capabilities: [deploy]": `group "a" has unknown capability "deploy"`,
+		"groups:\n- name: a\n  capabilities: [admin]\n  repos: github.com/acme/*": `group "a" can't be admin of only some repos`,
+	}
+	for contents, exp := range cases {
+		_, err := webauth.LoadPermissions(writePermissions(t, contents))
+		ErrContains(t, exp, err)
+	}
+}
+
+func TestRequire(t *testing.T) {
+	handler := webauth.Require(webauth.Admin, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	permissions, err := webauth.LoadPermissions(writePermissions(t, "groups:\n- name: platform\n  capabilities: [admin]"))
+	Ok(t, err)
+
+	for _, c := range []struct {
+		user *webauth.User
+		exp  int
+	}{
+		// Web authentication is disabled.
+		{nil, http.StatusNoContent},
+		// The basic auth user is allowed everything.
+		{&webauth.User{Name: "atlantis"}, http.StatusNoContent},
+		{&webauth.User{Name: "alice", Groups: []string{"platform"}, Permissions: permissions}, http.StatusNoContent},
+		{&webauth.User{Name: "bob", Groups: []string{"infra"}, Permissions: permissions}, http.StatusForbidden},
+	} {
+		r := httptest.NewRequest("GET", "/api-tokens", nil)
+		if c.user != nil {
+			r = r.WithContext(webauth.NewContext(r.Context(), c.user))
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+		Equals(t, c.exp, w.Code)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 The Atlantis Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package webauth
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/runatlantis/atlantis/server/events"
+)
+
+// User is an authenticated web UI user.
+type User struct {
+	Name   string
+	Groups []string
+	// Permissions are what the user's groups allow. The user is allowed
+	// everything if they're nil, ex. the --web-basic-auth user.
+	Permissions *Permissions
+	// Tenants, if set, restricts the user to the repos of the tenants whose
+	// web_groups include one of the user's groups.
+	Tenants *events.Tenants
+}
+
+// Can returns true if the user is allowed capability in the repo, see
+// Permissions.Allows. Users restricted to tenants are never allowed
+// capabilities in all repos.
+func (u *User) Can(capability Capability, repoFullName string, vcsHostname string) bool {
+	if u.Tenants != nil && !u.Tenants.ViewableBy(u.Groups, repoFullName, vcsHostname) {
+		return false
+	}
+	if u.Permissions == nil {
+		return true
+	}
+	return u.Permissions.Allows(u.Groups, capability, repoFullName, vcsHostname)
+}
+
+type userKey struct{}
+
+// NewContext returns a copy of ctx with the authenticated user.
+func NewContext(ctx context.Context, user *User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// FromRequest returns the authenticated user of r, nil if web authentication
+// is disabled.
+func FromRequest(r *http.Request) *User {
+	user, _ := r.Context().Value(userKey{}).(*User)
+	return user
+}
+
+// Allowed returns true if the user of r is allowed capability in the repo.
+// Everything is allowed when web authentication is disabled.
+func Allowed(r *http.Request, capability Capability, repoFullName string, vcsHostname string) bool {
+	user := FromRequest(r)
+	return user == nil || user.Can(capability, repoFullName, vcsHostname)
+}
+
+// Username returns the name of the user of r for audit logs, or a
+// placeholder if web authentication is disabled.
+func Username(r *http.Request) string {
+	if user := FromRequest(r); user != nil {
+		return user.Name
+	}
+	return "an unauthenticated user"
+}
+
+// Require wraps handler to respond with 403 Forbidden unless the user is
+// allowed capability in all repos.
+func Require(capability Capability, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !Allowed(r, capability, "", "") {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		handler(w, r)
+	}
+}